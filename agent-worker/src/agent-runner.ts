@@ -32,6 +32,16 @@ import {
 } from "./tool-output-formatter.js";
 import { GatewayClient } from "./gateway-client.js";
 import { createGatewayCallTool, createListToolsForToolTypeTool, createGetToolDetailTool, createListToolTypesTool, createExecuteScriptTool } from "./gateway-tools.js";
+import {
+  CheckpointStore,
+  CHECKPOINT_PROMPT,
+  FINAL_REPORT_PROMPT,
+  budgetNote,
+  continuationPrompt,
+  formatCheckpoint,
+  parseCheckpoint,
+  type TurnLimits,
+} from "./turn-loop.js";
 
 // ---------------------------------------------------------------------------
 // Tool calling guidelines attached to the bash tool definition via typed
@@ -118,6 +128,28 @@ export interface AgentRunnerConfig {
   mcpGatewayUrl: string;
   /** Directory containing SKILL.md definitions for pi-mono resource loader */
   skillsDir?: string;
+  /**
+   * Run investigations as bounded, checkpointed turns (see turn-loop.ts).
+   * Unset, or either limit 0, runs each prompt as one unbounded session.
+   */
+  turnLimits?: TurnLimits;
+}
+
+/** Per-turn options for runSession. */
+interface TurnOptions {
+  /** Session ID for a new session (default: the incident ID) */
+  sessionId?: string;
+  /** Tool calls after which the session is stopped at the next turn end */
+  toolBudget: number;
+  /** Prompt sent once the budget stopped the session (any tool call ends it) */
+  wrapUpPrompt: string;
+}
+
+interface SessionResult extends ExecuteResult {
+  tool_calls: number;
+  budget_exhausted: boolean;
+  /** Reply to the wrap-up prompt */
+  wrap_up?: string;
 }
 
 // ---------------------------------------------------------------------------
@@ -169,15 +201,32 @@ export function mapThinkingLevel(level: ThinkingLevel): PiThinkingLevel | "off"
 // Model resolution
 // ---------------------------------------------------------------------------
 
+/** Context window assumed for models outside pi-ai's registry. */
+const DEFAULT_CONTEXT_WINDOW = 128_000;
+const DEFAULT_MAX_TOKENS = 16_384;
+
+/**
+ * Context window and output cap for a model outside pi-ai's registry. The
+ * operator-configured window (e.g. a self-hosted vLLM's max_model_len) wins
+ * over the 128k default; the output cap leaves most of a small window for
+ * the prompt.
+ */
+export function customModelLimits(contextWindow?: number): { contextWindow: number; maxTokens: number } {
+  const window = contextWindow && contextWindow > 0 ? contextWindow : DEFAULT_CONTEXT_WINDOW;
+  return { contextWindow: window, maxTokens: Math.min(DEFAULT_MAX_TOKENS, Math.floor(window / 4)) };
+}
+
 /**
  * Resolve a Model object from provider + model ID using pi-ai's registry.
  * Falls back to creating a custom model spec if the model isn't in the
- * built-in registry (e.g. custom endpoints or new models).
+ * built-in registry (e.g. custom endpoints or new models), sized by
+ * contextWindow when the operator configured one.
  */
 export function resolveModel(
   provider: string,
   modelId: string,
   baseUrl?: string,
+  contextWindow?: number,
 ): Model<any> {
   try {
     const builtInModel = getBuiltinModel(provider as any, modelId as any);
@@ -268,8 +317,7 @@ export function resolveModel(
     reasoning: true,
     input: ["text"],
     cost: { input: 0, output: 0, cacheRead: 0, cacheWrite: 0 },
-    contextWindow: 128_000,
-    maxTokens: 16_384,
+    ...customModelLimits(contextWindow),
     ...(compat ? { compat } : {}),
   } as Model<any>;
 }
@@ -498,6 +546,7 @@ function writeCustomProviderModelsJson(
   provider: string,
   model: string,
   baseUrl: string | undefined,
+  contextWindow?: number,
 ): void {
   const agentDir = getAgentDir();
   const modelsPath = path.join(agentDir, "models.json");
@@ -646,8 +695,7 @@ function writeCustomProviderModelsJson(
             name: model,
             reasoning: true,
             input: ["text"],
-            contextWindow: 128000,
-            maxTokens: 16384,
+            ...customModelLimits(contextWindow),
           },
         ],
         [AKMATORI_MANAGED_MARKER]: true,
@@ -703,8 +751,7 @@ function writeCustomProviderModelsJson(
                   name: model,
                   reasoning: true,
                   input: ["text"],
-                  contextWindow: 128000,
-                  maxTokens: 16384,
+                  ...customModelLimits(contextWindow),
                   [AKMATORI_MANAGED_MARKER]: true,
                 },
               ],
@@ -871,35 +918,147 @@ function writeSubagentSettingsFile(
 export class AgentRunner {
   private readonly mcpGatewayUrl: string;
   private readonly skillsDir?: string;
+  private readonly turnLimits?: TurnLimits;
   private activeSessions = new Map<string, AgentSession>();
+  /**
+   * Turn loops in progress, by incident. A loop stops before its next turn
+   * once its token is no longer the incident's entry (cancelled, or
+   * superseded by a newer run).
+   */
+  private activeRuns = new Map<string, symbol>();
 
   constructor(config: AgentRunnerConfig) {
     this.mcpGatewayUrl = config.mcpGatewayUrl;
     this.skillsDir = config.skillsDir;
+    const limits = config.turnLimits;
+    if (limits && limits.maxTurns > 0 && limits.maxToolCallsPerTurn > 0) {
+      this.turnLimits = limits;
+    }
   }
 
   /**
    * Execute a new agent session for an incident.
    */
   async execute(params: ExecuteParams): Promise<ExecuteResult> {
-    return this.runSession(params, params.task, false);
+    return this.runTurns(params, params.task, false);
   }
 
   /**
    * Resume an existing session with a follow-up message.
    */
   async resume(params: ResumeParams): Promise<ExecuteResult> {
-    return this.runSession(params, params.message, true);
+    return this.runTurns(params, params.message, true);
+  }
+
+  /**
+   * Run a prompt as a loop of bounded turns when turn limits are set. A turn
+   * that spends its tool budget ends with a checkpoint, persisted to the
+   * workspace and logged, and the next turn starts a fresh session from the
+   * checkpoints. The last turn ends with the final report. Resumes continue
+   * the incident's latest session for their first turn.
+   */
+  private async runTurns(
+    params: ExecuteParams | ResumeParams,
+    promptText: string,
+    isResume: boolean,
+  ): Promise<ExecuteResult> {
+    const limits = this.turnLimits;
+    if (!limits) {
+      return this.runSession(params, promptText, isResume);
+    }
+
+    const startTime = Date.now();
+    const token = Symbol(params.incidentId);
+    this.activeRuns.set(params.incidentId, token);
+    const checkpoints = new CheckpointStore(params.workDir);
+
+    let fullLog = "";
+    let tokensUsed = 0;
+    let lastSkill: string | undefined;
+    let prompt = promptText + budgetNote(1, limits);
+    let resume = isResume;
+    let sessionId: string | undefined;
+    let result!: SessionResult;
+    let response = "";
+    try {
+      for (let turn = 1; ; turn++) {
+        const last = turn >= limits.maxTurns;
+        result = await this.runSession(
+          // Only the first turn's registration releases the orchestrator's
+          // launch chain.
+          turn === 1 ? params : { ...params, onRegistered: undefined },
+          prompt,
+          resume,
+          {
+            sessionId,
+            toolBudget: limits.maxToolCallsPerTurn,
+            wrapUpPrompt: last ? FINAL_REPORT_PROMPT : CHECKPOINT_PROMPT,
+          },
+        );
+        fullLog += result.full_log;
+        tokensUsed += result.tokens_used;
+        lastSkill = result.last_skill ?? lastSkill;
+        response = result.response;
+
+        if (!result.budget_exhausted || result.error || this.activeRuns.get(params.incidentId) !== token) {
+          break;
+        }
+        if (last) {
+          response = result.wrap_up || result.response;
+          break;
+        }
+
+        const checkpoint = {
+          ...parseCheckpoint(result.wrap_up ?? ""),
+          turn: checkpoints.nextTurn(),
+          session_id: result.session_id,
+          tool_calls: result.tool_calls,
+          tokens_used: result.tokens_used,
+          created_at: new Date().toISOString(),
+        };
+        try {
+          checkpoints.append(checkpoint);
+        } catch (err) {
+          console.warn(`[agent-runner] failed to persist checkpoint for ${params.incidentId}: ${err}`);
+        }
+        const note = formatCheckpoint(checkpoint, limits);
+        params.onOutput(note);
+        fullLog += note;
+
+        prompt = continuationPrompt(promptText, checkpoints.list(), turn + 1, limits);
+        resume = false;
+        sessionId = `${params.incidentId}-turn-${checkpoint.turn + 1}`;
+      }
+    } finally {
+      if (this.activeRuns.get(params.incidentId) === token) {
+        this.activeRuns.delete(params.incidentId);
+      }
+    }
+
+    return {
+      session_id: result.session_id,
+      response,
+      full_log: fullLog,
+      error: result.error,
+      tokens_used: tokensUsed,
+      execution_time_ms: Date.now() - startTime,
+      session_export: result.session_export,
+      last_skill: lastSkill,
+    };
   }
 
   /**
    * Common session setup and execution logic shared by execute() and resume().
+   * With turn options the session stops at the first turn end past the tool
+   * budget and then answers the wrap-up prompt, stopped again at the first
+   * turn that calls a tool.
    */
   private async runSession(
     params: ExecuteParams | ResumeParams,
     promptText: string,
     isResume: boolean,
-  ): Promise<ExecuteResult> {
+    turn?: TurnOptions,
+  ): Promise<SessionResult> {
     const startTime = Date.now();
 
     // Set up proxy env vars before creating session
@@ -924,6 +1083,7 @@ export class AgentRunner {
       params.llmSettings.provider,
       params.llmSettings.model,
       params.llmSettings.base_url,
+      params.llmSettings.context_window,
     );
 
     // Model
@@ -931,6 +1091,7 @@ export class AgentRunner {
       params.llmSettings.provider,
       params.llmSettings.model,
       params.llmSettings.base_url,
+      params.llmSettings.context_window,
     );
     const thinkingLevel = mapThinkingLevel(params.llmSettings.thinking_level);
 
@@ -968,7 +1129,7 @@ export class AgentRunner {
       ? SessionManager.continueRecent(params.workDir, sessionDir)
       : SessionManager.create(params.workDir, sessionDir);
     if (!isResume) {
-      sessionManager.newSession({ id: params.incidentId });
+      sessionManager.newSession({ id: turn?.sessionId ?? params.incidentId });
     }
     const settingsManager = SettingsManager.inMemory({
       retry: { provider: DEFAULT_PROVIDER_RETRY },
@@ -1089,9 +1250,23 @@ export class AgentRunner {
 
     let lastErrorMessage = "";
     let lastSkillName: string | undefined;
+    let toolCalls = 0;
+    let budgetExhausted = false;
+    let wrappingUp = false;
     const unsubscribe = session.subscribe((event: AgentSessionEvent) => {
       params.onEvent?.(event);
 
+      // Tool budget: stop the session at the end of the turn that reached
+      // it (so every tool call has its result), and at the end of any turn
+      // that calls tools while wrapping up.
+      if (turn && event.type === "tool_execution_start") {
+        toolCalls++;
+      }
+      if (turn && event.type === "turn_end" && (wrappingUp ? (event.toolResults?.length ?? 0) > 0 : toolCalls >= turn.toolBudget)) {
+        budgetExhausted = true;
+        session.abort().catch(() => {});
+      }
+
       // Track the last skill the agent consulted: pi-mono skills are invoked
       // by reading <skillsDir>/<name>/SKILL.md with the read tool. Latched on
       // tool_execution_start so even an aborted run keeps the observation.
@@ -1135,7 +1310,22 @@ export class AgentRunner {
     });
 
     try {
-      await session.prompt(promptText);
+      try {
+        await session.prompt(promptText);
+      } catch (err) {
+        if (!budgetExhausted) throw err;
+      }
+
+      let wrapUp: string | undefined;
+      if (turn && budgetExhausted) {
+        wrappingUp = true;
+        try {
+          await session.prompt(turn.wrapUpPrompt);
+          wrapUp = session.getLastAssistantText() ?? "";
+        } catch (err) {
+          console.warn(`[agent-runner] wrap-up prompt failed for ${params.incidentId}: ${err}`);
+        }
+      }
 
       const sessionExportPath = this.exportSession(sessionManager, params.workDir);
 
@@ -1157,6 +1347,9 @@ export class AgentRunner {
         execution_time_ms: Date.now() - startTime,
         session_export: sessionExportPath,
         last_skill: lastSkillName,
+        tool_calls: toolCalls,
+        budget_exhausted: budgetExhausted,
+        wrap_up: wrapUp,
       };
     } catch (err) {
       const sessionExportPath = this.exportSession(sessionManager, params.workDir);
@@ -1170,6 +1363,8 @@ export class AgentRunner {
         execution_time_ms: Date.now() - startTime,
         session_export: sessionExportPath,
         last_skill: lastSkillName,
+        tool_calls: toolCalls,
+        budget_exhausted: budgetExhausted,
       };
     } finally {
       unsubscribe();
@@ -1198,6 +1393,7 @@ export class AgentRunner {
    * still in the map.
    */
   async cancel(incidentId: string): Promise<void> {
+    this.activeRuns.delete(incidentId);
     const session = this.activeSessions.get(incidentId);
     if (session) {
       await session.abort();
@@ -1219,13 +1415,14 @@ export class AgentRunner {
       }
     }
     this.activeSessions.clear();
+    this.activeRuns.clear();
   }
 
   /**
    * Check if an incident has an active session.
    */
   hasActiveSession(incidentId: string): boolean {
-    return this.activeSessions.has(incidentId);
+    return this.activeSessions.has(incidentId) || this.activeRuns.has(incidentId);
   }
 
   // -------------------------------------------------------------------------
@@ -7,6 +7,7 @@
  * Ports the Go agent-worker main.go entry point to Node.js.
  */
 
+import { hostname } from "node:os";
 import { Orchestrator, type OrchestratorConfig } from "./orchestrator.js";
 
 // ---------------------------------------------------------------------------
@@ -17,6 +18,16 @@ const API_WS_URL = process.env.API_WS_URL ?? "ws://akmatori-api:3000/ws/agent";
 const MCP_GATEWAY_URL = process.env.MCP_GATEWAY_URL ?? "http://mcp-gateway:8080";
 const WORKSPACE_DIR = process.env.WORKSPACE_DIR ?? "/workspaces";
 const SKILLS_DIR = process.env.SKILLS_DIR ?? "/akmatori/skills";
+// Identity and concurrent-run capacity advertised to the API's worker pool
+const WORKER_ID = process.env.WORKER_ID || hostname();
+const WORKER_CAPACITY = Math.max(1, parseInt(process.env.WORKER_CAPACITY ?? "4", 10) || 4);
+// Journal of in-progress runs, reported to the API after a restart (empty
+// disables). Per worker, since workers share the workspace volume.
+const JOB_STATE_FILE = process.env.JOB_STATE_FILE ?? `${WORKSPACE_DIR}/.jobs-${WORKER_ID}.json`;
+// Investigations run as up to MAX_TURNS sessions of TOOL_CALLS_PER_TURN tool
+// calls each, checkpointing between turns (0 for either = one unbounded session)
+const INVESTIGATION_MAX_TURNS = Math.max(0, parseInt(process.env.INVESTIGATION_MAX_TURNS ?? "8", 10) || 0);
+const INVESTIGATION_TOOL_CALLS_PER_TURN = Math.max(0, parseInt(process.env.INVESTIGATION_TOOL_CALLS_PER_TURN ?? "25", 10) || 0);
 
 const RECONNECT_DELAY_MS = 5_000;
 
@@ -24,9 +35,17 @@ const RECONNECT_DELAY_MS = 5_000;
 // Logger
 // ---------------------------------------------------------------------------
 
+// JSON lines with the keys the API server and MCP gateway use (time, level,
+// msg), unless LOG_FORMAT=text asks for plain lines.
+const LOG_FORMAT = (process.env.LOG_FORMAT ?? "json").trim().toLowerCase();
+
 function log(msg: string): void {
   const ts = new Date().toISOString();
-  console.log(`[agent-worker] ${ts} ${msg}`);
+  if (LOG_FORMAT === "text") {
+    console.log(`[agent-worker] ${ts} ${msg}`);
+    return;
+  }
+  console.log(JSON.stringify({ time: ts, level: "INFO", msg, service: "agent-worker" }));
 }
 
 // ---------------------------------------------------------------------------
@@ -48,12 +67,19 @@ async function main(): Promise<void> {
   log(`  MCP_GATEWAY_URL: ${MCP_GATEWAY_URL}`);
   log(`  WORKSPACE_DIR:   ${WORKSPACE_DIR}`);
   log(`  SKILLS_DIR:      ${SKILLS_DIR}`);
+  log(`  JOB_STATE_FILE:  ${JOB_STATE_FILE}`);
+  log(`  WORKER_ID:       ${WORKER_ID} (capacity ${WORKER_CAPACITY})`);
+  log(`  TURN LIMITS:     ${INVESTIGATION_MAX_TURNS} turns x ${INVESTIGATION_TOOL_CALLS_PER_TURN} tool calls`);
 
   const config: OrchestratorConfig = {
     apiWsUrl: API_WS_URL,
     mcpGatewayUrl: MCP_GATEWAY_URL,
     workspaceDir: WORKSPACE_DIR,
     skillsDir: SKILLS_DIR,
+    workerId: WORKER_ID,
+    capacity: WORKER_CAPACITY,
+    jobStateFile: JOB_STATE_FILE || undefined,
+    turnLimits: { maxTurns: INVESTIGATION_MAX_TURNS, maxToolCallsPerTurn: INVESTIGATION_TOOL_CALLS_PER_TURN },
     logger: log,
   };
 
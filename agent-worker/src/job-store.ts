@@ -0,0 +1,154 @@
+/**
+ * JobStore - on-disk journal of the runs this worker is executing.
+ *
+ * Each run is recorded when it starts and removed when it ends, so the
+ * records still present when the process starts are the runs a previous
+ * process was killed in the middle of. The orchestrator reports those to the
+ * API (agent_interrupted) instead of leaving their incidents running.
+ *
+ * The journal is one small JSON file rewritten atomically (temp file +
+ * rename). Output offsets change on every streamed chunk, so they are
+ * flushed at most once per flushIntervalMs; starts and finishes are written
+ * immediately.
+ */
+
+import * as fs from "node:fs";
+import * as path from "node:path";
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+export interface JobRecord {
+  incident_id: string;
+  /** API run_id the run was started with */
+  run_id?: string;
+  /** pi-mono session the run writes to (resumable once its file exists) */
+  session_id: string;
+  /** Bytes of output streamed to the API so far */
+  output_offset: number;
+  /** ISO-8601 start time */
+  started_at: string;
+}
+
+export interface JobStoreOptions {
+  /** How often pending output offsets are written (default 1s) */
+  flushIntervalMs?: number;
+  logger?: (msg: string) => void;
+}
+
+// ---------------------------------------------------------------------------
+// JobStore
+// ---------------------------------------------------------------------------
+
+export class JobStore {
+  private readonly file: string;
+  private readonly flushIntervalMs: number;
+  private readonly log: (msg: string) => void;
+  private jobs = new Map<string, JobRecord>();
+  private leftover: JobRecord[];
+  private flushTimer: NodeJS.Timeout | null = null;
+
+  constructor(file: string, options: JobStoreOptions = {}) {
+    this.file = file;
+    this.flushIntervalMs = options.flushIntervalMs ?? 1_000;
+    this.log = options.logger ?? ((msg: string) => console.log(`[job-store] ${msg}`));
+    this.leftover = this.read();
+  }
+
+  /**
+   * Remove and return the runs a previous process left unfinished. Later
+   * calls return an empty list.
+   */
+  takeInterrupted(): JobRecord[] {
+    const jobs = this.leftover;
+    this.leftover = [];
+    if (jobs.length > 0) this.write();
+    return jobs;
+  }
+
+  /** Record a run as started, replacing any earlier run of the incident. */
+  start(incidentId: string, runId: string | undefined, sessionId: string): void {
+    this.jobs.set(incidentId, {
+      incident_id: incidentId,
+      ...(runId ? { run_id: runId } : {}),
+      session_id: sessionId,
+      output_offset: 0,
+      started_at: new Date().toISOString(),
+    });
+    this.write();
+  }
+
+  /** Advance a run's output offset by the size of text. */
+  addOutput(incidentId: string, runId: string | undefined, text: string): void {
+    const job = this.jobs.get(incidentId);
+    if (!job || job.run_id !== runId) return;
+    job.output_offset += Buffer.byteLength(text);
+    if (!this.flushTimer) {
+      this.flushTimer = setTimeout(() => {
+        this.flushTimer = null;
+        this.write();
+      }, this.flushIntervalMs);
+      this.flushTimer.unref();
+    }
+  }
+
+  /** Drop a finished run. A newer run of the same incident is kept. */
+  finish(incidentId: string, runId: string | undefined): void {
+    const job = this.jobs.get(incidentId);
+    if (!job || job.run_id !== runId) return;
+    this.jobs.delete(incidentId);
+    this.write();
+  }
+
+  /** Runs currently recorded (for testing). */
+  list(): JobRecord[] {
+    return [...this.jobs.values()].map((job) => ({ ...job }));
+  }
+
+  /** Write pending offsets and stop the flush timer. */
+  close(): void {
+    if (this.flushTimer) {
+      clearTimeout(this.flushTimer);
+      this.flushTimer = null;
+      this.write();
+    }
+  }
+
+  // -------------------------------------------------------------------------
+  // Persistence
+  // -------------------------------------------------------------------------
+
+  private read(): JobRecord[] {
+    let raw: string;
+    try {
+      raw = fs.readFileSync(this.file, "utf-8");
+    } catch (err) {
+      if ((err as NodeJS.ErrnoException).code !== "ENOENT") {
+        this.log(`Failed to read job journal ${this.file}: ${err}`);
+      }
+      return [];
+    }
+    try {
+      const parsed = JSON.parse(raw) as { jobs?: JobRecord[] };
+      return (parsed.jobs ?? []).filter((job) => typeof job?.incident_id === "string" && job.incident_id !== "");
+    } catch (err) {
+      this.log(`Ignoring corrupt job journal ${this.file}: ${err}`);
+      return [];
+    }
+  }
+
+  private write(): void {
+    // Leftover records stay on disk until reported, so a crash before the
+    // report does not lose them.
+    const jobs = [...this.leftover, ...this.jobs.values()];
+    const tmp = `${this.file}.tmp`;
+    try {
+      fs.mkdirSync(path.dirname(this.file), { recursive: true });
+      fs.writeFileSync(tmp, JSON.stringify({ jobs }, null, 2));
+      fs.renameSync(tmp, this.file);
+    } catch (err) {
+      this.log(`Failed to write job journal ${this.file}: ${err}`);
+    }
+  }
+}
@@ -64,6 +64,7 @@ export async function runOneshotLLM(params: OneshotLLMParams): Promise<string> {
     params.llmSettings.provider,
     params.llmSettings.model,
     params.llmSettings.base_url,
+    params.llmSettings.context_window,
   );
 
   const messages: Message[] = [
@@ -5,9 +5,13 @@
  * methods and streams output/completion/errors back through the WebSocket client.
  */
 
+import * as fs from "node:fs";
+import * as path from "node:path";
 import { WebSocketClient } from "./ws-client.js";
 import { AgentRunner, type ExecuteParams, type ResumeParams } from "./agent-runner.js";
 import { runOneshotLLM } from "./oneshot-llm.js";
+import { JobStore } from "./job-store.js";
+import type { TurnLimits } from "./turn-loop.js";
 import type {
   WebSocketMessage,
   LLMSettings,
@@ -29,6 +33,17 @@ export interface OrchestratorConfig {
   workspaceDir: string;
   /** Directory containing SKILL.md definitions for pi-mono resource loader */
   skillsDir?: string;
+  /** Name this worker reports to the API's worker pool (default: API-assigned) */
+  workerId?: string;
+  /** Concurrent runs this worker advertises to the API's load balancer */
+  capacity?: number;
+  /**
+   * File journaling the runs in progress. Runs a previous process left in
+   * it are reported to the API on start. Unset disables the journal.
+   */
+  jobStateFile?: string;
+  /** Turn and per-turn tool call limits for investigations (unset = unbounded) */
+  turnLimits?: TurnLimits;
   /** Logger function */
   logger?: (msg: string) => void;
 }
@@ -42,6 +57,7 @@ export class Orchestrator {
   private readonly wsClient: WebSocketClient;
   private readonly runner: AgentRunner;
   private readonly log: (msg: string) => void;
+  private readonly jobs: JobStore | undefined;
   private cachedProxyConfig: ProxyConfig | undefined;
   private stopped = false;
   /**
@@ -70,7 +86,12 @@ export class Orchestrator {
     this.runner = new AgentRunner({
       mcpGatewayUrl: config.mcpGatewayUrl,
       skillsDir: config.skillsDir,
+      turnLimits: config.turnLimits,
     });
+
+    if (config.jobStateFile) {
+      this.jobs = new JobStore(config.jobStateFile, { logger: this.log });
+    }
   }
 
   /**
@@ -83,11 +104,13 @@ export class Orchestrator {
 
     await this.wsClient.connect();
 
-    // Send initial "ready" status
-    this.wsClient.send({
-      type: "status",
-      data: { status: "ready" },
-    });
+    // Send initial "ready" status, advertising this worker to the API's pool
+    const data: Record<string, unknown> = { status: "ready" };
+    if (this.config.workerId) data.worker_id = this.config.workerId;
+    if (this.config.capacity) data.capacity = this.config.capacity;
+    this.wsClient.send({ type: "status", data });
+
+    this.reportInterruptedRuns();
 
     this.log("Orchestrator started");
   }
@@ -99,6 +122,7 @@ export class Orchestrator {
     this.log("Stopping orchestrator...");
     this.stopped = true;
     await this.runner.dispose();
+    this.jobs?.close();
     this.wsClient.close();
     this.log("Orchestrator stopped");
   }
@@ -191,6 +215,7 @@ export class Orchestrator {
     const runId = msg.run_id;
 
     const { resolveRegistered, prevLaunch, registered } = this.enterLaunchChain(incidentId);
+    let outputSeq = 0;
 
     const params: ExecuteParams = {
       incidentId,
@@ -201,10 +226,13 @@ export class Orchestrator {
       toolAllowlist: msg.tool_allowlist,
       workDir: `${this.config.workspaceDir}/${incidentId}`,
       onOutput: (text: string) => {
-        this.wsClient.sendOutput(incidentId, runId, text);
+        this.jobs?.addOutput(incidentId, runId, text);
+        this.wsClient.sendOutput(incidentId, runId, text, ++outputSeq);
       },
       onRegistered: resolveRegistered,
     };
+    // New sessions take the incident ID as their session ID (see AgentRunner).
+    this.jobs?.start(incidentId, runId, incidentId);
 
     // Serialize bootstrap per-incident: wait until the prior launch has
     // registered its session (or failed) before running our own
@@ -225,6 +253,7 @@ export class Orchestrator {
         // (createAgentSession threw, etc.) onRegistered was never called.
         // Resolve the chain here so the next launch isn't deadlocked.
         resolveRegistered();
+        if (!this.interruptedByShutdown()) this.jobs?.finish(incidentId, runId);
         if (this.launchChain.get(incidentId) === registered) {
           this.launchChain.delete(incidentId);
         }
@@ -254,6 +283,7 @@ export class Orchestrator {
     const runId = msg.run_id;
 
     const { resolveRegistered, prevLaunch, registered } = this.enterLaunchChain(incidentId);
+    let outputSeq = 0;
 
     const params: ResumeParams = {
       incidentId,
@@ -265,10 +295,12 @@ export class Orchestrator {
       toolAllowlist: msg.tool_allowlist,
       workDir: `${this.config.workspaceDir}/${incidentId}`,
       onOutput: (text: string) => {
-        this.wsClient.sendOutput(incidentId, runId, text);
+        this.jobs?.addOutput(incidentId, runId, text);
+        this.wsClient.sendOutput(incidentId, runId, text, ++outputSeq);
       },
       onRegistered: resolveRegistered,
     };
+    this.jobs?.start(incidentId, runId, params.sessionId || incidentId);
 
     void (async () => {
       try { await prevLaunch; } catch { /* prior launch's failure is its own concern */ }
@@ -279,6 +311,7 @@ export class Orchestrator {
         this.log(`Unhandled error in runResume for ${incidentId}: ${err}`);
       } finally {
         resolveRegistered();
+        if (!this.interruptedByShutdown()) this.jobs?.finish(incidentId, runId);
         if (this.launchChain.get(incidentId) === registered) {
           this.launchChain.delete(incidentId);
         }
@@ -340,6 +373,42 @@ export class Orchestrator {
     }
   }
 
+  /**
+   * Report the runs a previous process of this worker did not finish. A run
+   * whose session was saved to the workspace carries its session_id so the
+   * API can resume it here; one without is reported with no session and the
+   * API fails it.
+   */
+  private reportInterruptedRuns(): void {
+    if (!this.jobs) return;
+    for (const job of this.jobs.takeInterrupted()) {
+      const resumable = this.isValidIncidentId(job.incident_id) && this.hasSavedSession(job.incident_id);
+      this.log(
+        `Reporting interrupted run for ${job.incident_id} (run ${job.run_id ?? "N/A"}, ` +
+          `${job.output_offset} bytes streamed, ${resumable ? "resumable" : "no saved session"})`,
+      );
+      this.wsClient.sendInterrupted(job.incident_id, job.run_id, resumable ? job.session_id : undefined, job.output_offset);
+    }
+  }
+
+  /**
+   * Whether runs ending now were aborted by stop(). With a job journal they
+   * are neither reported failed nor removed, so the next process reports
+   * them for resumption.
+   */
+  private interruptedByShutdown(): boolean {
+    return this.stopped && this.jobs !== undefined;
+  }
+
+  /** Whether the incident's workspace holds a pi-mono session to resume. */
+  private hasSavedSession(incidentId: string): boolean {
+    try {
+      return fs.readdirSync(path.join(this.config.workspaceDir, incidentId, ".sessions")).length > 0;
+    } catch {
+      return false;
+    }
+  }
+
   private handleProxyConfigUpdate(msg: WebSocketMessage): void {
     if (msg.proxy_config) {
       this.cachedProxyConfig = msg.proxy_config;
@@ -422,6 +491,11 @@ export class Orchestrator {
     try {
       const result = await fn();
 
+      if (this.interruptedByShutdown()) {
+        this.log(`Incident ${incidentId} interrupted by shutdown; left in the job journal`);
+        return;
+      }
+
       if (result.error) {
         this.log(`Incident ${incidentId} completed with error: ${result.error}`);
         this.wsClient.sendError(incidentId, runId, result.error);
@@ -442,6 +516,10 @@ export class Orchestrator {
         `Incident ${incidentId} completed (tokens: ${result.tokens_used}, time: ${result.execution_time_ms}ms)`,
       );
     } catch (err) {
+      if (this.interruptedByShutdown()) {
+        this.log(`Incident ${incidentId} interrupted by shutdown; left in the job journal`);
+        return;
+      }
       const errorMsg = (err as Error).message ?? String(err);
       this.log(`Incident ${incidentId} failed: ${errorMsg}`);
       this.wsClient.sendError(incidentId, runId, errorMsg);
@@ -455,7 +533,8 @@ export class Orchestrator {
   /**
    * Extract LLM settings from a WebSocket message.
    *
-   * The Go API sends provider, api_key, model, thinking_level, and base_url fields.
+   * The Go API sends provider, api_key, model, thinking_level, base_url and
+   * context_window fields.
    */
   private extractLLMSettings(msg: WebSocketMessage): LLMSettings | null {
     const apiKey = msg.api_key;
@@ -467,6 +546,7 @@ export class Orchestrator {
       model: msg.model ?? "gpt-5.5",
       thinking_level: this.mapThinkingLevel(msg.thinking_level),
       base_url: msg.base_url,
+      context_window: msg.context_window,
     };
   }
 
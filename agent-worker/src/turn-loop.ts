@@ -0,0 +1,170 @@
+/**
+ * Turn loop - bounded, checkpointed investigations.
+ *
+ * Instead of one open-ended agent session per investigation, AgentRunner
+ * runs it as a series of turns. Each turn is a session with a budget of tool
+ * calls; when the budget is spent the agent is asked for a structured
+ * checkpoint (summary, findings, next steps), which is persisted to the
+ * incident workspace. The next turn starts a fresh session from the task and
+ * the checkpoints so far, so very long investigations never outgrow a single
+ * session's context. The last turn is asked for the final report instead.
+ */
+
+import * as fs from "node:fs";
+import * as path from "node:path";
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+export interface TurnLimits {
+  /** Sessions an investigation may use before it must report */
+  maxTurns: number;
+  /** Tool calls per turn before the agent is asked to checkpoint */
+  maxToolCallsPerTurn: number;
+}
+
+export interface TurnCheckpoint {
+  /** 1-based turn number across all runs of the incident */
+  turn: number;
+  session_id: string;
+  tool_calls: number;
+  tokens_used: number;
+  summary: string;
+  findings: string[];
+  next_steps: string[];
+  /** ISO-8601 time the checkpoint was written */
+  created_at: string;
+}
+
+/** Checkpoint file, relative to the incident workspace */
+export const CHECKPOINT_FILE = "checkpoints.json";
+
+// ---------------------------------------------------------------------------
+// Prompts
+// ---------------------------------------------------------------------------
+
+/** Sent when a turn's tool budget is spent and another turn follows. */
+export const CHECKPOINT_PROMPT = [
+  "Your tool budget for this turn is spent. Do not call any tools.",
+  "Write a checkpoint of the investigation so far as a single JSON object:",
+  '{"summary": "<what you know so far>", "findings": ["<confirmed fact>", ...], "next_steps": ["<what to check next>", ...]}',
+  "The next turn starts a fresh session from this checkpoint, so include every detail it will need (hosts, metrics, timestamps, commands that worked).",
+].join("\n");
+
+/** Sent when the last turn's tool budget is spent. */
+export const FINAL_REPORT_PROMPT = [
+  "Your tool budget for the investigation is spent. Do not call any tools.",
+  "Write your final report now from what you have found, noting anything you could not confirm.",
+].join("\n");
+
+/** Appended to each turn's prompt so the agent can plan its tool calls. */
+export function budgetNote(turn: number, limits: TurnLimits): string {
+  const last = turn >= limits.maxTurns;
+  return (
+    `\n\n[Turn ${turn} of ${limits.maxTurns}: you have ${limits.maxToolCallsPerTurn} tool calls in this turn. ` +
+    (last
+      ? "This is the last turn; finish with your final report."
+      : "If you need more, you will be asked for a checkpoint and continue in a new turn.") +
+    "]"
+  );
+}
+
+/**
+ * Prompt for a turn after the first: the run's original request plus the
+ * checkpoints so far, oldest first.
+ */
+export function continuationPrompt(task: string, checkpoints: TurnCheckpoint[], turn: number, limits: TurnLimits): string {
+  const parts = [task, "", "--- Progress from earlier turns ---"];
+  for (const cp of checkpoints) {
+    parts.push("", `Turn ${cp.turn}: ${cp.summary}`);
+    if (cp.findings.length > 0) {
+      parts.push("Findings:", ...cp.findings.map((f) => `- ${f}`));
+    }
+    if (cp.next_steps.length > 0) {
+      parts.push("Next steps:", ...cp.next_steps.map((s) => `- ${s}`));
+    }
+  }
+  parts.push("", "Continue the investigation from the latest checkpoint. Do not repeat checks that are already done.");
+  return parts.join("\n") + budgetNote(turn, limits);
+}
+
+/**
+ * Parse the agent's checkpoint reply. The first JSON object in the text is
+ * used (fenced or not); a reply without one becomes the summary as is.
+ */
+export function parseCheckpoint(text: string): Pick<TurnCheckpoint, "summary" | "findings" | "next_steps"> {
+  const start = text.indexOf("{");
+  const end = text.lastIndexOf("}");
+  if (start >= 0 && end > start) {
+    try {
+      const parsed = JSON.parse(text.slice(start, end + 1)) as Record<string, unknown>;
+      return {
+        summary: typeof parsed.summary === "string" ? parsed.summary : "",
+        findings: stringList(parsed.findings),
+        next_steps: stringList(parsed.next_steps),
+      };
+    } catch {
+      // Fall through to the raw text.
+    }
+  }
+  return { summary: text.trim(), findings: [], next_steps: [] };
+}
+
+/** Render a checkpoint for the incident log. */
+export function formatCheckpoint(cp: TurnCheckpoint, limits: TurnLimits): string {
+  const lines = [`\n📌 Checkpoint after turn ${cp.turn} (${cp.tool_calls} tool calls, budget ${limits.maxToolCallsPerTurn}/turn)`];
+  if (cp.summary) lines.push(cp.summary);
+  for (const f of cp.findings) lines.push(`- ${f}`);
+  if (cp.next_steps.length > 0) lines.push(`Next: ${cp.next_steps.join("; ")}`);
+  return lines.join("\n") + "\n";
+}
+
+function stringList(value: unknown): string[] {
+  return Array.isArray(value) ? value.filter((v): v is string => typeof v === "string" && v.trim() !== "") : [];
+}
+
+// ---------------------------------------------------------------------------
+// CheckpointStore
+// ---------------------------------------------------------------------------
+
+/**
+ * Checkpoints of one incident, kept in <workDir>/checkpoints.json. The file
+ * is rewritten atomically (temp file + rename) after every turn, so it
+ * always holds the progress up to the last completed turn.
+ */
+export class CheckpointStore {
+  private readonly file: string;
+  private checkpoints: TurnCheckpoint[];
+
+  constructor(workDir: string) {
+    this.file = path.join(workDir, CHECKPOINT_FILE);
+    this.checkpoints = this.read();
+  }
+
+  list(): TurnCheckpoint[] {
+    return [...this.checkpoints];
+  }
+
+  /** Number the next checkpoint would get. */
+  nextTurn(): number {
+    return (this.checkpoints[this.checkpoints.length - 1]?.turn ?? 0) + 1;
+  }
+
+  append(checkpoint: TurnCheckpoint): void {
+    this.checkpoints.push(checkpoint);
+    const tmp = `${this.file}.tmp`;
+    fs.mkdirSync(path.dirname(this.file), { recursive: true });
+    fs.writeFileSync(tmp, JSON.stringify({ checkpoints: this.checkpoints }, null, 2));
+    fs.renameSync(tmp, this.file);
+  }
+
+  private read(): TurnCheckpoint[] {
+    try {
+      const parsed = JSON.parse(fs.readFileSync(this.file, "utf-8")) as { checkpoints?: TurnCheckpoint[] };
+      return Array.isArray(parsed.checkpoints) ? parsed.checkpoints : [];
+    } catch {
+      return [];
+    }
+  }
+}
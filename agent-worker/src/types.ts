@@ -25,7 +25,8 @@ export type WorkerToAPIMessageType =
   | "agent_error"
   | "heartbeat"
   | "status"
-  | "oneshot_llm_response";
+  | "oneshot_llm_response"
+  | "agent_interrupted";
 
 export type MessageType = APIToWorkerMessageType | WorkerToAPIMessageType;
 
@@ -71,6 +72,8 @@ export interface LLMSettings {
   model: string;
   thinking_level: ThinkingLevel;
   base_url?: string;
+  /** Context window in tokens for models outside pi-ai's registry; 0/unset uses 128k. */
+  context_window?: number;
 }
 
 // ---------------------------------------------------------------------------
@@ -118,6 +121,7 @@ export interface WebSocketMessage {
   model?: string;
   thinking_level?: string;
   base_url?: string;
+  context_window?: number;
 
   // Proxy configuration with toggles (sent with new_incident)
   proxy_config?: ProxyConfig;
@@ -141,6 +145,10 @@ export interface WebSocketMessage {
   // agent_output / agent_completed / agent_error frame so the API can drop
   // late frames from a superseded run.
   run_id?: string;
+
+  // Numbers a run's agent_output deltas from 1 so the API can append each
+  // one exactly once.
+  seq?: number;
 }
 
 // ---------------------------------------------------------------------------
@@ -122,16 +122,19 @@ export class WebSocketClient {
   }
 
   /**
-   * Send streaming output for an incident. runId echoes the API-stamped
-   * run identifier so the API can drop late frames from a superseded run.
-   * Pass undefined for synthetic outputs that have no associated run.
+   * Send streaming output for an incident. output is only the text produced
+   * since the previous frame; seq numbers the frames of a run from 1 so the
+   * API appends each delta once. runId echoes the API-stamped run identifier
+   * so the API can drop late frames from a superseded run. Pass undefined
+   * for synthetic outputs that have no associated run.
    */
-  sendOutput(incidentId: string, runId: string | undefined, output: string): void {
+  sendOutput(incidentId: string, runId: string | undefined, output: string, seq?: number): void {
     this.send({
       type: "agent_output",
       incident_id: incidentId,
       output,
       ...(runId ? { run_id: runId } : {}),
+      ...(seq ? { seq } : {}),
     });
   }
 
@@ -175,6 +178,26 @@ export class WebSocketClient {
     });
   }
 
+  /**
+   * Report a run a previous worker process did not finish. sessionId is set
+   * when the run can be resumed from its saved session; outputOffset is how
+   * many bytes of output had been streamed for it.
+   */
+  sendInterrupted(
+    incidentId: string,
+    runId: string | undefined,
+    sessionId: string | undefined,
+    outputOffset: number,
+  ): void {
+    this.send({
+      type: "agent_interrupted",
+      incident_id: incidentId,
+      data: { output_offset: outputOffset },
+      ...(runId ? { run_id: runId } : {}),
+      ...(sessionId ? { session_id: sessionId } : {}),
+    });
+  }
+
   /** Send a one-shot LLM response correlated with the originating request. */
   sendOneshotResponse(requestId: string, summary: string, errorMsg?: string): void {
     this.send({
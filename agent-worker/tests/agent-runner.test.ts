@@ -302,6 +302,16 @@ describe("resolveModel", () => {
     expect(model.baseUrl).toBe("https://my-api.example.com");
   });
 
+  it("should size custom model specs by the configured context window", () => {
+    const model = resolveModel("custom", "qwen3-32b", "http://vllm:8000/v1", 32_768);
+    expect(model.contextWindow).toBe(32_768);
+    expect(model.maxTokens).toBe(8_192);
+
+    const fallback = resolveModel("custom", "qwen3-32b", "http://vllm:8000/v1");
+    expect(fallback.contextWindow).toBe(128_000);
+    expect(fallback.maxTokens).toBe(16_384);
+  });
+
   it("should create custom model spec for openrouter", () => {
     const model = resolveModel("openrouter", "anthropic/claude-3.5-sonnet");
     expect(model.id).toBe("anthropic/claude-3.5-sonnet");
@@ -1939,6 +1949,134 @@ describe("AgentRunner", () => {
     });
   });
 
+  // -----------------------------------------------------------------------
+  // turn loop
+  // -----------------------------------------------------------------------
+
+  describe("turn loop", () => {
+    const limits = { maxTurns: 3, maxToolCallsPerTurn: 2 };
+    let workDir: string;
+
+    beforeEach(async () => {
+      const fs = await import("node:fs");
+      const path = await import("node:path");
+      workDir = fs.mkdtempSync(path.join("/tmp", "agent-turns-"));
+    });
+
+    afterEach(async () => {
+      const fs = await import("node:fs");
+      fs.rmSync(workDir, { recursive: true, force: true });
+    });
+
+    // Scripted prompt: emits `tools` tool calls and a turn end, and sets the
+    // text getLastAssistantText returns afterwards.
+    function script(session: ReturnType<typeof createMockSession>, steps: Array<{ tools: number; reply: string; onPrompt?: () => void }>) {
+      const prompts: string[] = [];
+      let lastText = "";
+      session.getLastAssistantText.mockImplementation(() => lastText);
+      session.prompt.mockImplementation(async (text: string) => {
+        const step = steps[prompts.length];
+        prompts.push(text);
+        step.onPrompt?.();
+        for (let i = 0; i < step.tools; i++) {
+          session._emitEvent({ type: "tool_execution_start", toolCallId: `call-${prompts.length}-${i}`, toolName: "bash", args: {} });
+        }
+        session._emitEvent({
+          type: "turn_end",
+          message: { role: "assistant", usage: { totalTokens: 100 } },
+          toolResults: Array.from({ length: step.tools }, () => ({})),
+        });
+        lastText = step.reply;
+      });
+      return prompts;
+    }
+
+    it("checkpoints a turn that spends its tool budget and continues in a fresh session", async () => {
+      const fs = await import("node:fs");
+      const path = await import("node:path");
+      const { SessionManager } = await import("@earendil-works/pi-coding-agent");
+      const prompts = script(mockSession, [
+        { tools: 2, reply: "" },
+        { tools: 0, reply: '```json\n{"summary": "web-01 CPU pegged by java", "findings": ["pid 4242 at 390%"], "next_steps": ["check GC logs"]}\n```' },
+        { tools: 1, reply: "Root cause: GC thrashing in pid 4242." },
+      ]);
+      runner = new AgentRunner({ mcpGatewayUrl: "http://mcp-gateway:8080", turnLimits: limits });
+      const onOutput = vi.fn();
+
+      const result = await runner.execute(makeExecuteParams({ workDir, onOutput }));
+
+      expect(mockSession.abort).toHaveBeenCalledTimes(1);
+      expect(prompts).toHaveLength(3);
+      expect(prompts[0]).toContain("Investigate high CPU on web-01");
+      expect(prompts[0]).toContain("[Turn 1 of 3: you have 2 tool calls in this turn.");
+      expect(prompts[1]).toContain("tool budget for this turn is spent");
+      expect(prompts[2]).toContain("Investigate high CPU on web-01");
+      expect(prompts[2]).toContain("Turn 1: web-01 CPU pegged by java");
+      expect(prompts[2]).toContain("- pid 4242 at 390%");
+      expect(prompts[2]).toContain("[Turn 2 of 3:");
+
+      const newSessionIds = (SessionManager.create as any).mock.results.map((r: any) => r.value.newSession.mock.calls[0][0].id);
+      expect(newSessionIds).toEqual(["inc-001", "inc-001-turn-2"]);
+
+      expect(result.error).toBeUndefined();
+      expect(result.response).toBe("Root cause: GC thrashing in pid 4242.");
+      expect(result.tokens_used).toBe(300);
+      expect(result.full_log).toContain("📌 Checkpoint after turn 1 (2 tool calls, budget 2/turn)");
+      expect(onOutput).toHaveBeenCalledWith(expect.stringContaining("📌 Checkpoint after turn 1"));
+
+      const saved = JSON.parse(fs.readFileSync(path.join(workDir, "checkpoints.json"), "utf-8"));
+      expect(saved.checkpoints).toHaveLength(1);
+      expect(saved.checkpoints[0]).toMatchObject({
+        turn: 1,
+        tool_calls: 2,
+        tokens_used: 200,
+        summary: "web-01 CPU pegged by java",
+        findings: ["pid 4242 at 390%"],
+        next_steps: ["check GC logs"],
+      });
+    });
+
+    it("asks for the final report when the last turn spends its budget", async () => {
+      const prompts = script(mockSession, [
+        { tools: 2, reply: "" },
+        { tools: 0, reply: "Final report: could not confirm the cause." },
+      ]);
+      runner = new AgentRunner({ mcpGatewayUrl: "http://mcp-gateway:8080", turnLimits: { maxTurns: 1, maxToolCallsPerTurn: 2 } });
+
+      const result = await runner.execute(makeExecuteParams({ workDir }));
+
+      expect(prompts).toHaveLength(2);
+      expect(prompts[0]).toContain("This is the last turn");
+      expect(prompts[1]).toContain("Write your final report now");
+      expect(result.response).toBe("Final report: could not confirm the cause.");
+      expect(createAgentSessionCalls).toHaveLength(1);
+    });
+
+    it("stops after the current turn when the run is cancelled", async () => {
+      const prompts = script(mockSession, [
+        { tools: 2, reply: "" },
+        { tools: 0, reply: '{"summary": "partial"}', onPrompt: () => { runner.cancel("inc-001"); } },
+      ]);
+      runner = new AgentRunner({ mcpGatewayUrl: "http://mcp-gateway:8080", turnLimits: limits });
+
+      await runner.execute(makeExecuteParams({ workDir }));
+
+      expect(prompts).toHaveLength(2);
+      expect(createAgentSessionCalls).toHaveLength(1);
+      expect(runner.hasActiveSession("inc-001")).toBe(false);
+    });
+
+    it("runs one unbounded session without turn limits", async () => {
+      const prompts = script(mockSession, [{ tools: 5, reply: "done" }]);
+
+      const result = await runner.execute(makeExecuteParams({ workDir }));
+
+      expect(prompts).toEqual(["Investigate high CPU on web-01"]);
+      expect(mockSession.abort).not.toHaveBeenCalled();
+      expect(result.response).toBe("done");
+    });
+  });
+
   // -----------------------------------------------------------------------
   // dispose
   // -----------------------------------------------------------------------
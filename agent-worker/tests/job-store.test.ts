@@ -0,0 +1,69 @@
+import { describe, it, expect, beforeEach, afterEach } from "vitest";
+import * as fs from "node:fs";
+import * as os from "node:os";
+import * as path from "node:path";
+import { JobStore } from "../src/job-store.js";
+
+describe("JobStore", () => {
+  let dir: string;
+  let file: string;
+
+  beforeEach(() => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), "akmatori-job-store-test-"));
+    file = path.join(dir, "state", "jobs.json");
+  });
+
+  afterEach(() => {
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  it("returns the runs a previous process left unfinished, once", () => {
+    const before = new JobStore(file, { logger: () => {} });
+    before.start("inc-1", "run-1", "inc-1");
+    before.start("inc-2", "run-2", "inc-2");
+    before.finish("inc-2", "run-2");
+
+    const after = new JobStore(file, { logger: () => {} });
+    const interrupted = after.takeInterrupted();
+    expect(interrupted).toHaveLength(1);
+    expect(interrupted[0]).toMatchObject({ incident_id: "inc-1", run_id: "run-1", session_id: "inc-1", output_offset: 0 });
+    expect(after.takeInterrupted()).toEqual([]);
+
+    // Reported runs are gone from disk too.
+    expect(new JobStore(file, { logger: () => {} }).takeInterrupted()).toEqual([]);
+  });
+
+  it("persists output offsets on close", () => {
+    const store = new JobStore(file, { flushIntervalMs: 60_000, logger: () => {} });
+    store.start("inc-1", "run-1", "inc-1");
+    store.addOutput("inc-1", "run-1", "héllo");
+    store.addOutput("inc-1", "run-1", "!");
+    store.close();
+
+    const [job] = new JobStore(file, { logger: () => {} }).takeInterrupted();
+    expect(job.output_offset).toBe(7);
+  });
+
+  it("keeps a newer run when a superseded run finishes", () => {
+    const store = new JobStore(file, { logger: () => {} });
+    store.start("inc-1", "run-1", "inc-1");
+    store.start("inc-1", "run-2", "inc-1");
+    store.addOutput("inc-1", "run-1", "late output");
+    store.finish("inc-1", "run-1");
+
+    const jobs = store.list();
+    expect(jobs).toHaveLength(1);
+    expect(jobs[0]).toMatchObject({ run_id: "run-2", output_offset: 0 });
+  });
+
+  it("starts empty when the journal is missing or corrupt", () => {
+    const logs: string[] = [];
+    expect(new JobStore(file, { logger: (m) => logs.push(m) }).takeInterrupted()).toEqual([]);
+    expect(logs).toEqual([]);
+
+    fs.mkdirSync(path.dirname(file), { recursive: true });
+    fs.writeFileSync(file, "{not json");
+    expect(new JobStore(file, { logger: (m) => logs.push(m) }).takeInterrupted()).toEqual([]);
+    expect(logs.some((m) => m.includes("corrupt"))).toBe(true);
+  });
+});
@@ -1,5 +1,9 @@
 import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import * as fs from "node:fs";
+import * as os from "node:os";
+import * as path from "node:path";
 import { Orchestrator, type OrchestratorConfig } from "../src/orchestrator.js";
+import { JobStore } from "../src/job-store.js";
 import type { WebSocketMessage, ProxyConfig } from "../src/types.js";
 
 // ---------------------------------------------------------------------------
@@ -278,6 +282,15 @@ describe("Orchestrator", () => {
       expect(statusMsg!.data).toEqual({ status: "ready" });
     });
 
+    it("should advertise worker id and capacity in the ready status", async () => {
+      await orchestrator.stop();
+      orchestrator = new Orchestrator({ ...config, workerId: "worker-a", capacity: 2 });
+      await orchestrator.start();
+
+      const statusMsg = await waitForMessage((m) => m.type === "status");
+      expect(statusMsg!.data).toEqual({ status: "ready", worker_id: "worker-a", capacity: 2 });
+    });
+
     it("should report isStopped false before stop", async () => {
       await orchestrator.start();
       expect(orchestrator.isStopped()).toBe(false);
@@ -1130,6 +1143,65 @@ describe("Orchestrator", () => {
     });
   });
 
+  // -----------------------------------------------------------------------
+  // Job journal
+  // -----------------------------------------------------------------------
+
+  describe("job journal", () => {
+    let stateDir: string;
+
+    beforeEach(() => {
+      stateDir = fs.mkdtempSync(path.join(os.tmpdir(), "akmatori-orchestrator-jobs-"));
+    });
+
+    afterEach(() => {
+      fs.rmSync(stateDir, { recursive: true, force: true });
+    });
+
+    it("reports runs a previous process left unfinished after the ready status", async () => {
+      const jobStateFile = path.join(stateDir, "jobs.json");
+      const workspaceDir = path.join(stateDir, "workspaces");
+      const previous = new JobStore(jobStateFile, { logger: () => {} });
+      previous.start("inc-saved", "run-1", "inc-saved");
+      previous.addOutput("inc-saved", "run-1", "partial output");
+      previous.start("inc-fresh", "run-2", "inc-fresh");
+      previous.close();
+      fs.mkdirSync(path.join(workspaceDir, "inc-saved", ".sessions"), { recursive: true });
+      fs.writeFileSync(path.join(workspaceDir, "inc-saved", ".sessions", "session.jsonl"), "{}\n");
+
+      await orchestrator.stop();
+      orchestrator = new Orchestrator({ ...config, workspaceDir, jobStateFile });
+      await orchestrator.start();
+
+      const saved = await waitForMessage((m) => m.type === "agent_interrupted" && m.incident_id === "inc-saved");
+      expect(saved).toMatchObject({ run_id: "run-1", session_id: "inc-saved", data: { output_offset: 14 } });
+      const fresh = await waitForMessage((m) => m.type === "agent_interrupted" && m.incident_id === "inc-fresh");
+      expect(fresh!.run_id).toBe("run-2");
+      expect(fresh!.session_id).toBeUndefined();
+      expect(allServerMessages.findIndex((m) => m.type === "status")).toBeLessThan(allServerMessages.indexOf(saved!));
+
+      // Reported once: a reconnect does not repeat them.
+      expect(new JobStore(jobStateFile, { logger: () => {} }).takeInterrupted()).toEqual([]);
+    });
+
+    it("journals a run while it executes and drops it when it completes", async () => {
+      const jobStateFile = path.join(stateDir, "jobs.json");
+      await orchestrator.stop();
+      orchestrator = new Orchestrator({ ...config, jobStateFile });
+      await orchestrator.start();
+
+      let journaled: unknown[] = [];
+      mockSession.prompt.mockImplementationOnce(async () => {
+        journaled = JSON.parse(fs.readFileSync(jobStateFile, "utf-8")).jobs;
+      });
+      sendFromServer({ type: "new_incident", incident_id: "inc-1", run_id: "run-1", task: "t", api_key: "sk" });
+
+      await waitForMessage((m) => m.type === "agent_completed" && m.incident_id === "inc-1");
+      expect(journaled).toEqual([expect.objectContaining({ incident_id: "inc-1", run_id: "run-1", session_id: "inc-1" })]);
+      expect(JSON.parse(fs.readFileSync(jobStateFile, "utf-8")).jobs).toEqual([]);
+    });
+  });
+
   // -----------------------------------------------------------------------
   // Graceful shutdown
   // -----------------------------------------------------------------------
@@ -0,0 +1,88 @@
+import { describe, it, expect, beforeEach, afterEach } from "vitest";
+import * as fs from "node:fs";
+import * as os from "node:os";
+import * as path from "node:path";
+import {
+  CheckpointStore,
+  budgetNote,
+  continuationPrompt,
+  parseCheckpoint,
+  type TurnCheckpoint,
+} from "../src/turn-loop.js";
+
+const limits = { maxTurns: 4, maxToolCallsPerTurn: 20 };
+
+function checkpoint(turn: number, overrides: Partial<TurnCheckpoint> = {}): TurnCheckpoint {
+  return {
+    turn,
+    session_id: `inc-1-turn-${turn}`,
+    tool_calls: 20,
+    tokens_used: 1000,
+    summary: `summary ${turn}`,
+    findings: [],
+    next_steps: [],
+    created_at: "2026-01-01T00:00:00.000Z",
+    ...overrides,
+  };
+}
+
+describe("parseCheckpoint", () => {
+  it("reads the JSON object out of a fenced reply", () => {
+    const text = 'Here you go:\n```json\n{"summary": "disk full", "findings": ["/var at 100%", 7], "next_steps": ["rotate logs"]}\n```';
+    expect(parseCheckpoint(text)).toEqual({ summary: "disk full", findings: ["/var at 100%"], next_steps: ["rotate logs"] });
+  });
+
+  it("keeps a reply without JSON as the summary", () => {
+    expect(parseCheckpoint("  Still looking at {the} disks.  ")).toEqual({ summary: "Still looking at {the} disks.", findings: [], next_steps: [] });
+  });
+});
+
+describe("prompts", () => {
+  it("tells the agent its budget and whether the turn is the last", () => {
+    expect(budgetNote(1, limits)).toContain("Turn 1 of 4: you have 20 tool calls");
+    expect(budgetNote(1, limits)).toContain("asked for a checkpoint");
+    expect(budgetNote(4, limits)).toContain("This is the last turn");
+  });
+
+  it("carries the task and every checkpoint into the next turn", () => {
+    const prompt = continuationPrompt(
+      "Investigate disk alert on db-01",
+      [checkpoint(1, { findings: ["/var at 100%"] }), checkpoint(2, { next_steps: ["check logrotate"] })],
+      3,
+      limits,
+    );
+    expect(prompt.startsWith("Investigate disk alert on db-01\n")).toBe(true);
+    expect(prompt).toContain("Turn 1: summary 1\nFindings:\n- /var at 100%");
+    expect(prompt).toContain("Turn 2: summary 2\nNext steps:\n- check logrotate");
+    expect(prompt).toContain("Turn 3 of 4");
+  });
+});
+
+describe("CheckpointStore", () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), "akmatori-checkpoints-test-"));
+  });
+
+  afterEach(() => {
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  it("persists checkpoints across instances and numbers turns on from the last one", () => {
+    const store = new CheckpointStore(dir);
+    expect(store.nextTurn()).toBe(1);
+    store.append(checkpoint(1));
+    store.append(checkpoint(2));
+
+    const reopened = new CheckpointStore(dir);
+    expect(reopened.list().map((cp) => cp.turn)).toEqual([1, 2]);
+    expect(reopened.nextTurn()).toBe(3);
+    expect(fs.existsSync(path.join(dir, "checkpoints.json.tmp"))).toBe(false);
+  });
+
+  it("starts empty when the file is missing or corrupt", () => {
+    fs.writeFileSync(path.join(dir, "checkpoints.json"), "{not json");
+    expect(new CheckpointStore(dir).list()).toEqual([]);
+  });
+});
@@ -179,6 +179,25 @@ describe("WebSocketClient", () => {
 
       const parsed = JSON.parse(mockServer.received[0]);
       expect(parsed).not.toHaveProperty("run_id");
+      expect(parsed).not.toHaveProperty("seq");
+    });
+
+    it("should include seq when provided", async () => {
+      client = new WebSocketClient({
+        url: mockServer.url,
+        heartbeatIntervalMs: 60_000,
+        logger: () => {},
+      });
+
+      await client.connect();
+      await sleep(50);
+
+      client.sendOutput("inc-456", "run-abc", "delta", 3);
+      await sleep(50);
+
+      const parsed = JSON.parse(mockServer.received[0]);
+      expect(parsed.output).toBe("delta");
+      expect(parsed.seq).toBe(3);
     });
   });
 
@@ -248,6 +267,35 @@ describe("WebSocketClient", () => {
     });
   });
 
+  describe("sendInterrupted", () => {
+    it("should send agent_interrupted with the session only when resumable", async () => {
+      client = new WebSocketClient({
+        url: mockServer.url,
+        heartbeatIntervalMs: 60_000,
+        logger: () => {},
+      });
+
+      await client.connect();
+      await sleep(50);
+
+      client.sendInterrupted("inc-1", "run-1", "inc-1", 120);
+      client.sendInterrupted("inc-2", "run-2", undefined, 0);
+      await sleep(50);
+
+      const resumable = JSON.parse(mockServer.received[0]);
+      expect(resumable).toEqual({
+        type: "agent_interrupted",
+        incident_id: "inc-1",
+        run_id: "run-1",
+        session_id: "inc-1",
+        data: { output_offset: 120 },
+      });
+      const lost = JSON.parse(mockServer.received[1]);
+      expect(lost.session_id).toBeUndefined();
+      expect(lost.run_id).toBe("run-2");
+    });
+  });
+
   // -----------------------------------------------------------------------
   // Message serialization matches Go format
   // -----------------------------------------------------------------------
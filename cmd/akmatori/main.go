@@ -13,28 +13,39 @@ import (
 	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts/adapters"
+	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/config"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/faultinject"
 	"github.com/akmatori/akmatori/internal/handlers"
 	"github.com/akmatori/akmatori/internal/logging"
 	"github.com/akmatori/akmatori/internal/messaging"
+	"github.com/akmatori/akmatori/internal/metrics"
 	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/secrets"
 	"github.com/akmatori/akmatori/internal/services"
 	"github.com/akmatori/akmatori/internal/setup"
 	slackutil "github.com/akmatori/akmatori/internal/slack"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 	"gorm.io/gorm/logger"
 )
 
+// corsReloadInterval is how often each replica re-reads the saved CORS
+// settings, so a change made through another replica reaches it.
+const corsReloadInterval = time.Minute
+
 func main() {
+	// Load .env file if it exists (ignore error if file doesn't exist).
+	// Logging is set up afterwards so LOG_LEVEL and LOG_FORMAT may come
+	// from it.
+	envErr := godotenv.Load()
 	logging.Init()
-
-	// Load .env file if it exists (ignore error if file doesn't exist)
-	if err := godotenv.Load(); err != nil {
-		slog.Info("no .env file found or error loading it (this is fine if using environment variables)", "err", err)
+	if envErr != nil {
+		slog.Info("no .env file found or error loading it (this is fine if using environment variables)", "err", envErr)
 	}
 
 	// Load configuration
@@ -46,6 +57,23 @@ func main() {
 
 	slog.Info("starting Akmatori")
 
+	// Secrets in the settings tables are encrypted when a key is configured;
+	// the key must be set before the first database read.
+	keyring, err := secrets.LoadKeyring(cfg.SecretsEncryptionKey, cfg.SecretsEncryptionPreviousKeys)
+	if err != nil {
+		slog.Error("invalid secrets encryption key", "err", err)
+		os.Exit(1)
+	}
+	if keyring == nil {
+		slog.Warn("SECRETS_ENCRYPTION_KEY is not set; credentials are stored in plaintext")
+	}
+	database.SetSecretKeyring(keyring)
+
+	if err := api.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		slog.Error("invalid TRUSTED_PROXIES", "err", err)
+		os.Exit(1)
+	}
+
 	// Step 1: Initialize database connection FIRST (needed for secret resolution)
 	if err := database.Connect(cfg.DatabaseURL, logger.Warn); err != nil {
 		slog.Error("failed to connect to database", "err", err)
@@ -78,6 +106,30 @@ func main() {
 	}
 
 	// Step 5: Create JWT middleware with resolved secrets
+	skipPaths := []string{
+		"/health",
+		"/health/leader", // Leader probe for load balancers
+		"/readyz",        // Dependency readiness probe
+		"/webhook/*",
+		"/i/*", // Short links redirect to the UI, which authenticates
+		"/auth/login",
+		"/auth/setup",
+		"/auth/setup-status",
+		"/ws/agent",         // WebSocket endpoint for Agent worker (internal)
+		"/api/docs",         // Swagger UI (public)
+		"/api/openapi.yaml", // OpenAPI spec (public)
+		"/api/openapi.json", // OpenAPI spec as JSON (public)
+	}
+	// Prometheus scrapes /metrics with METRICS_TOKEN, checked by the metrics
+	// handler; anonymous scrapes need METRICS_PUBLIC. Otherwise it takes a
+	// login like the rest of the API.
+	switch {
+	case cfg.MetricsToken != "":
+		skipPaths = append(skipPaths, "/metrics")
+	case cfg.MetricsPublic:
+		skipPaths = append(skipPaths, "/metrics")
+		slog.Warn("METRICS_PUBLIC is set: /metrics is served without authentication")
+	}
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(&middleware.JWTAuthConfig{
 		Enabled:           true,
 		SetupMode:         setupRequired,
@@ -85,16 +137,7 @@ func main() {
 		AdminPasswordHash: passwordHash,
 		JWTSecret:         jwtSecret,
 		JWTExpiryHours:    cfg.JWTExpiryHours,
-		SkipPaths: []string{
-			"/health",
-			"/webhook/*",
-			"/auth/login",
-			"/auth/setup",
-			"/auth/setup-status",
-			"/ws/agent",         // WebSocket endpoint for Agent worker (internal)
-			"/api/docs",         // Swagger UI (public)
-			"/api/openapi.yaml", // OpenAPI spec (public)
-		},
+		SkipPaths:         skipPaths,
 	})
 	slog.Info("JWT authentication enabled", "user", cfg.AdminUsername)
 
@@ -116,8 +159,22 @@ func main() {
 		slog.Warn("failed to seed improvement-evaluator cron", "err", err)
 	}
 
-	// Data directory for skills and incidents (hardcoded)
-	const dataDir = "/akmatori"
+	// Data directory for skills, context files, incidents, runbooks and
+	// memories. An existing tree from a previous location is moved in
+	// before anything reads it.
+	dataDir := cfg.DataDir
+	if err := services.EnsureDataDir(dataDir); err != nil {
+		slog.Error("data directory unusable", "data_dir", dataDir, "err", err)
+		os.Exit(1)
+	}
+	if cfg.DataDirMigrateFrom != "" {
+		moved, err := services.MigrateDataDir(cfg.DataDirMigrateFrom, dataDir)
+		if err != nil {
+			slog.Error("failed to migrate data directory", "from", cfg.DataDirMigrateFrom, "to", dataDir, "err", err)
+			os.Exit(1)
+		}
+		slog.Info("data directory migrated", "from", cfg.DataDirMigrateFrom, "to", dataDir, "entries_moved", moved)
+	}
 
 	// Initialize context service
 	contextService, err := services.NewContextService(dataDir)
@@ -131,12 +188,57 @@ func main() {
 	// Created before SkillService so it can be wired in as the OneShotLLMCaller
 	// (used by TitleGenerator and any other provider-agnostic LLM call sites).
 	agentWSHandler := handlers.NewAgentWSHandler()
-	slog.Info("agent WebSocket handler initialized")
+	// Investigations that start before the worker has connected (API boot
+	// races the worker container) or during a worker restart wait this long
+	// for it instead of failing.
+	agentWSHandler.SetWorkerConnectWait(time.Duration(cfg.WorkerConnectWaitSeconds) * time.Second)
+	slog.Info("agent WebSocket handler initialized", "worker_connect_wait_seconds", cfg.WorkerConnectWaitSeconds)
+
+	// Failure-injection mode (development only) simulates adapter parse
+	// errors, agent timeouts and Slack 429s at the configured rates.
+	var faults *faultinject.Injector
+	if cfg.FaultInjectionEnabled {
+		faults = faultinject.New(map[faultinject.Fault]float64{
+			faultinject.AdapterParseError: cfg.FaultAdapterParseRate,
+			faultinject.AgentTimeout:      cfg.FaultAgentTimeoutRate,
+			faultinject.SlackRateLimit:    cfg.FaultSlackRateLimitRate,
+		})
+		agentWSHandler.SetFaultInjector(faults)
+		slog.Warn("FAILURE INJECTION ENABLED - do not run in production",
+			"adapter_parse_rate", faults.Rate(faultinject.AdapterParseError),
+			"agent_timeout_rate", faults.Rate(faultinject.AgentTimeout),
+			"slack_rate_limit_rate", faults.Rate(faultinject.SlackRateLimit))
+	}
 
 	// Initialize skill service
 	skillService := services.NewSkillService(dataDir, toolService, contextService, agentWSHandler)
 	slog.Info("skill service initialized", "data_dir", dataDir)
 
+	// Live incident stream: SkillService publishes full_log deltas and status
+	// transitions as it persists them; /ws/incidents/{uuid} fans them out to
+	// the UI so the incident page does not have to poll.
+	// The PagerDuty sync rides the same events to acknowledge, annotate and
+	// resolve the PagerDuty incidents behind alert-sourced investigations
+	// (no-op unless a PagerDuty tool instance enables it), and the timeline
+	// turns them into status-change and command events.
+	incidentStreamHub := services.NewIncidentStreamHub()
+	incidentTimeline := services.NewIncidentTimelineService(database.GetDB())
+	incidentEvents := services.IncidentEventPublishers{incidentStreamHub, services.NewPagerDutySync(database.GetDB()), incidentTimeline}
+	skillService.SetIncidentEventPublisher(incidentEvents)
+	skillService.SetIncidentTimeline(incidentTimeline)
+
+	// Tool approvals: the MCP gateway holds destructive tool calls until a
+	// human approves them; this service posts the requests to Slack and
+	// records decisions. Rejecting one cancels the investigation.
+	approvalService := services.NewApprovalService(database.GetDB())
+	approvalService.SetIncidentCanceller(agentWSHandler)
+	approvalService.SetIncidentTimeline(incidentTimeline)
+	// Two-phase investigations: sources with plan_approval stop after the
+	// diagnosis; approving the proposed plan resumes the agent to run it.
+	remediationPlanService := services.NewRemediationPlanService(database.GetDB())
+	remediationPlanService.SetIncidentTimeline(incidentTimeline)
+	skillService.SetProgressThrottle(time.Duration(cfg.ProgressLogMinIntervalMs)*time.Millisecond, cfg.ProgressLogMinDeltaBytes)
+
 	// Initialize Memory service BEFORE regenerating SKILL.md files.
 	// generateSkillMd embeds the per-scope MEMORY.md manifest into each
 	// SKILL.md it writes; if the on-disk manifests are stale (e.g. memories
@@ -182,8 +284,20 @@ func main() {
 		slog.Warn("failed to initialize alert source types", "err", err)
 	}
 
-	// Initialize Slack manager with hot-reload support
+	// Leader election: with several API replicas behind a load balancer,
+	// exactly one owns Slack Socket Mode, the background sweeps, the cron
+	// scheduler and the agent worker connection. A single replica (or a
+	// non-Postgres database) is always the leader.
+	leaderElector := services.NewLeaderElector(database.GetDB())
+
+	// Initialize Slack manager with hot-reload support. Every replica keeps a
+	// Web API client for posting; Socket Mode is switched on by the leader.
 	slackManager := slackutil.NewManager()
+	slackManager.SetFaultInjector(faults)
+	slackManager.SetSocketModeEnabled(false)
+	approvalNotifier := handlers.NewSlackApprovalNotifier(slackManager)
+	approvalService.SetNotifier(approvalNotifier)
+	remediationPlanService.SetNotifier(approvalNotifier)
 
 	// Get initial Slack settings from database
 	slackSettings, err := database.GetSlackSettings()
@@ -200,9 +314,29 @@ func main() {
 	// race detector flags and that could surface as a torn pointer in production.
 	var slackHandler atomic.Pointer[handlers.SlackHandler]
 
-	// Initialize Alert handler (needed before Slack handler setup)
-	// Initialize channel resolver (will be set when Slack connects)
-	var channelResolver *slackutil.ChannelResolver
+	// Initialize Alert handler (needed before Slack handler setup).
+	// The channel resolver reads the manager's live client, so it keeps
+	// working across Slack credential reloads; its cache is shared with the
+	// Slack handler, which keeps it current from channel rename events.
+	channelResolver := slackutil.NewManagedChannelResolver(slackManager)
+	if cfg.RedisURL != "" {
+		// Shared with the other API replicas and kept across restarts.
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			slog.Error("invalid REDIS_URL", "err", err)
+			os.Exit(1)
+		}
+		rdb := redis.NewClient(opts)
+		pingCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = rdb.Ping(pingCtx).Err()
+		cancel()
+		if err != nil {
+			slog.Error("failed to connect to Redis", "err", err)
+			os.Exit(1)
+		}
+		channelResolver.SetSharedCache(slackutil.NewRedisChannelCache(rdb, "akmatori:api:slack_channels"))
+		slog.Info("shared Redis cache enabled for Slack channel resolution")
+	}
 
 	alertHandler := handlers.NewAlertHandler(
 		cfg,
@@ -213,6 +347,7 @@ func main() {
 		alertService,
 		channelResolver,
 	)
+	alertHandler.SetFaultInjector(faults)
 
 	// Slack summarizer compresses final agent output to fit Slack's byte cap
 	// using the same provider-agnostic worker oneshot path as TitleGenerator.
@@ -240,11 +375,90 @@ func main() {
 	alertHandler.SetChannelService(channelService)
 	alertHandler.SetProviderRegistry(providerRegistry)
 
+	// LLM budgets: once a daily or monthly limit is spent, new alert, Slack,
+	// API and cron investigations are declined as budget_exceeded.
+	budgetService := services.NewBudgetService(database.GetDB())
+	budgetService.SetNotifier(channelService, providerRegistry)
+	alertHandler.SetBudgetGuard(budgetService)
+
+	// Self-monitor: opens a meta incident and notifies the default (or
+	// configured) channel when webhooks fail, the worker stays away, or the
+	// LLM provider keeps rejecting credentials. Wired before the HTTP server
+	// starts; its evaluation loop starts with the other background services.
+	var selfMonitor *services.SelfMonitor
+	if cfg.SelfMonitorEnabled {
+		selfMonitor = services.NewSelfMonitor(database.GetDB(), services.SelfMonitorConfig{
+			WebhookErrorRate:   cfg.SelfMonitorWebhookErrorRate,
+			WebhookMinRequests: cfg.SelfMonitorWebhookMinRequests,
+			WorkerDownAfter:    time.Duration(cfg.SelfMonitorWorkerDownMinutes) * time.Minute,
+			LLMAuthFailures:    cfg.SelfMonitorLLMAuthFailures,
+			ChannelUUID:        cfg.SelfMonitorChannelUUID,
+		}, agentWSHandler)
+		selfMonitor.SetNotifier(channelService, providerRegistry)
+		alertHandler.SetHealthRecorder(selfMonitor)
+		agentWSHandler.SetHealthRecorder(selfMonitor)
+	}
+
 	// Alert correlator reads its config live from GeneralSettings on each call,
 	// so no startup config block is needed. Changes take effect immediately without a restart.
 	alertCorrelator := services.NewAlertCorrelator(agentWSHandler, database.GetDB())
 	alertHandler.SetAlertCorrelator(alertCorrelator)
 	slog.Info("alert correlator ready (live config)")
+	// Repeats of an alert an open incident already has are attached before
+	// the correlator runs (per-source fingerprint_dedup setting).
+	alertHandler.SetAlertDeduplicator(skillService)
+	// Alerts without a usable severity get one from labels and keyword rules,
+	// or the LLM when the source's severity_inference setting is "llm".
+	alertHandler.SetSeverityInferrer(services.NewSeverityInferrer(agentWSHandler))
+
+	// Incident links: manual parent/child/related links plus the correlator's
+	// cascading-failure parents, which keep children out of the channel.
+	incidentLinkService := services.NewIncidentLinkService(database.GetDB())
+	alertHandler.SetIncidentLinker(incidentLinkService)
+	alertHandler.SetIncidentTimeline(incidentTimeline)
+
+	// Investigation scheduler: caps concurrent alert investigations and lets
+	// critical alerts pause the lowest-priority running one. 0 = unlimited.
+	investigationScheduler := services.NewInvestigationScheduler(cfg.InvestigationMaxConcurrent)
+	investigationScheduler.SetMaxPerSource(cfg.InvestigationMaxPerSource)
+	alertHandler.SetInvestigationScheduler(investigationScheduler)
+	if cfg.InvestigationMaxConcurrent > 0 || cfg.InvestigationMaxPerSource > 0 {
+		slog.Info("investigation scheduler enabled", "max_concurrent", cfg.InvestigationMaxConcurrent, "max_per_source", cfg.InvestigationMaxPerSource)
+	}
+
+	// Alert storm protection: coalesces repeats of an alert and groups a
+	// flapping source's alerts into one summary incident.
+	alertHandler.SetAlertStormGuard(services.NewAlertStormGuard(services.AlertStormSettings{
+		CoalesceWindow: time.Duration(cfg.AlertCoalesceWindowSeconds) * time.Second,
+		Threshold:      cfg.AlertStormThreshold,
+		Window:         time.Duration(cfg.AlertStormWindowSeconds) * time.Second,
+	}))
+
+	// Enrichment pipeline: CMDB, recent changes, similar incidents and
+	// runbook matches added to the investigation prompt. Alert sources pick
+	// steps with the enrichment_steps setting.
+	enrichmentPipeline := services.NewEnrichmentPipeline(database.GetDB(), services.DefaultEnrichmentSteps(database.GetDB())...)
+	alertHandler.SetEnrichmentPipeline(enrichmentPipeline)
+	// Maintenance windows: silenced alerts are recorded but spawn nothing.
+	silenceService := services.NewSilenceService(database.GetDB())
+	alertHandler.SetAlertSilencer(silenceService)
+	// Routing rules override channel, skills, priority and action per alert.
+	routingRuleService := services.NewRoutingRuleService(database.GetDB())
+	alertHandler.SetAlertRouter(routingRuleService)
+	alertHandler.SetRemediationPlanner(remediationPlanService)
+	// Confidence scoring: low-confidence results ask for a review, confident
+	// low-severity results close their resolved incident.
+	confidenceService := services.NewConfidenceService(database.GetDB(), skillService)
+	confidenceService.SetIncidentTimeline(incidentTimeline)
+	alertHandler.SetConfidenceRouter(confidenceService)
+	slog.Info("enrichment pipeline ready", "steps", enrichmentPipeline.StepNames())
+
+	// Notification templates: operator overrides of outbound alert message
+	// text, looked up per message so edits apply without a restart.
+	notificationTemplateService := services.NewNotificationTemplateService(database.GetDB())
+	alertHandler.SetNotificationRenderer(notificationTemplateService)
+	promptTemplateService := services.NewPromptTemplateService(database.GetDB())
+	alertHandler.SetPromptRenderer(promptTemplateService)
 
 	// Post-investigation merger: after an alert incident completes, compares
 	// its diagnosed root cause against recent investigated incidents and
@@ -273,12 +487,15 @@ func main() {
 		// Task 6 of the unified-channels plan; LoadListenerChannels reads
 		// from the channels table.
 		handler.SetChannelService(channelService)
+		handler.SetChannelResolver(channelResolver)
 		handler.SetSlackSummarizer(slackSummarizer)
 		handler.SetResponseFormatter(responseFormatter)
 		// Wire LLM-classified Slack feedback capture: thread replies on incident
 		// threads run through the classifier and persist as global feedback memory.
 		handler.SetMemoryManager(memoryService)
 		handler.SetFeedbackClassifier(services.NewFeedbackClassifier(agentWSHandler))
+		handler.SetApprovalManager(approvalService)
+		handler.SetRemediationPlanManager(remediationPlanService)
 
 		// Try to get bot user ID and team ID for self-message filtering and Streaming API
 		if authTest, err := client.AuthTest(); err == nil {
@@ -295,11 +512,14 @@ func main() {
 			slog.Warn("failed to load listener channels", "err", err)
 		}
 
+		handler.SetBudgetGuard(budgetService)
+
 		// Publish the fully-initialised handler atomically so the API
 		// reloader closure observes a complete value (no torn pointer or
 		// partially-wired handler).
 		slackHandler.Store(handler)
 
+		handler.SetSocketStateRecorder(slackManager.SetSocketConnected)
 		handler.HandleSocketMode(socketClient)
 		slog.Info("Slack components initialized (with listener channel support)")
 	})
@@ -317,10 +537,15 @@ func main() {
 	alertHandler.RegisterAdapter(adapters.NewPagerDutyAdapter())
 	alertHandler.RegisterAdapter(adapters.NewGrafanaAdapter())
 	alertHandler.RegisterAdapter(adapters.NewDatadogAdapter())
+	alertHandler.RegisterAdapter(adapters.NewVictorOpsAdapter())
+	alertHandler.RegisterAdapter(adapters.NewCloudWatchAdapter())
+	alertHandler.RegisterAdapter(adapters.NewSentryAdapter())
 	slog.Info("alert adapters registered: alertmanager, zabbix, pagerduty, grafana, datadog")
 
 	// Initialize HTTP handler
 	httpHandler := handlers.NewHTTPHandler(alertHandler)
+	httpHandler.SetLeaderStatus(leaderElector)
+	agentWSHandler.SetLeaderStatus(leaderElector)
 
 	// Initialize API handler for skill communication and management
 	httpConnectorService := services.NewHTTPConnectorService()
@@ -332,6 +557,29 @@ func main() {
 	// /api/integrations and /api/channels.
 	apiHandler.SetChannelManager(channelService)
 	apiHandler.SetProviderRegistry(providerRegistry)
+	apiHandler.SetNotificationTemplateManager(notificationTemplateService)
+	apiHandler.SetPromptTemplateManager(promptTemplateService)
+	apiHandler.SetIncidentLinker(incidentLinkService)
+	apiHandler.SetIncidentTimeline(incidentTimeline)
+	apiHandler.SetApprovalManager(approvalService)
+	apiHandler.SetRemediationPlanManager(remediationPlanService)
+	remediationPlanService.SetExecutor(apiHandler)
+	apiHandler.SetSilenceManager(silenceService)
+	apiHandler.SetRoutingRuleManager(routingRuleService)
+	apiHandler.SetPostmortemReporter(services.NewPostmortemGenerator(agentWSHandler, database.GetDB()))
+	apiHandler.SetSnippetExporter(services.NewSnippetService(database.GetDB(), skillService, contextService))
+	apiHandler.SetZabbixProvisioner(services.NewZabbixProvisioner(database.GetDB()))
+	apiHandler.SetSkillBundler(skillService)
+	apiHandler.SetSkillTester(skillService)
+	if cfg.MarketplaceIndexURL != "" {
+		marketplace, err := services.NewMarketplaceClient(cfg.MarketplaceIndexURL, cfg.MarketplacePublicKeys, skillService)
+		if err != nil {
+			slog.Error("skill marketplace disabled", "err", err)
+		} else {
+			apiHandler.SetMarketplace(marketplace)
+			slog.Info("skill marketplace enabled", "index_url", cfg.MarketplaceIndexURL, "trusted_keys", len(cfg.MarketplacePublicKeys))
+		}
+	}
 
 	// Cron runner: scheduler + CRUD for /api/cron-jobs. Started below after
 	// HTTP routes are registered so the runner only begins ticking once the
@@ -340,6 +588,7 @@ func main() {
 	// WebSocket as alert/Slack flows.
 	cronRunner := services.NewCronRunner(channelService, providerRegistry, skillService, agentWSHandler)
 	cronRunner.SetResponseFormatter(responseFormatter)
+	cronRunner.SetBudgetGuard(budgetService)
 	apiHandler.SetCronJobManager(cronRunner)
 
 	// Self-improvement proposals: apply-on-approve goes through the same
@@ -365,9 +614,40 @@ func main() {
 	}
 	apiHandler.SetGatewayReloader(handlers.GatewayReloadFunc(mcpGatewayURL))
 	apiHandler.SetMCPServerReloader(handlers.GatewayMCPReloadFunc(mcpGatewayURL))
+	apiHandler.SetToolSettingsValidator(services.NewToolSchemaClient(mcpGatewayURL))
+
+	// Dependencies checked by /readyz
+	httpHandler.AddReadinessCheck("database", handlers.DatabaseReadinessCheck(database.GetDB()))
+	httpHandler.AddReadinessCheck("slack", handlers.SlackReadinessCheck(slackManager.SocketState))
+	httpHandler.AddReadinessCheck("agent_worker", handlers.AgentWorkerReadinessCheck(agentWSHandler.IsWorkerConnected, leaderElector))
+	httpHandler.AddReadinessCheck("mcp_gateway", handlers.GatewayReadinessCheck(mcpGatewayURL))
+
+	// Browser-facing live incident log/status stream
+	incidentStreamHandler := handlers.NewIncidentStreamHandler(incidentStreamHub, skillService)
 
-	// Initialize auth handler
+	// Initialize auth handler with brute-force protection: failed logins back
+	// off exponentially per username+IP and lock out at the threshold. Every
+	// attempt lands in the audit log.
+	auditService := services.NewAuditService(database.GetDB())
 	authHandler := handlers.NewAuthHandler(jwtAuthMiddleware)
+	authHandler.SetLoginThrottle(middleware.NewLoginThrottle(middleware.LoginThrottleConfig{
+		LockoutThreshold: cfg.LoginLockoutThreshold,
+		LockoutDuration:  time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+	}))
+	authHandler.SetAuditRecorder(auditService)
+	apiHandler.SetAuditLogReader(auditService)
+	apiHandler.SetUsageReader(services.NewUsageService(database.GetDB()))
+	apiHandler.SetConfidenceStats(confidenceService)
+	alertBaselineService := services.NewAlertBaselineService(database.GetDB())
+	alertBaselineService.SetIncidentTimeline(incidentTimeline)
+	apiHandler.SetAlertBaselines(alertBaselineService)
+	apiHandler.SetComplianceReporter(services.NewComplianceService(database.GetDB()))
+	apiHandler.SetBudgetGuard(budgetService)
+	apiHandler.SetLLMHealthProber(services.NewLLMHealthChecker(database.GetDB()))
+	retentionService := services.NewRetentionService(filepath.Join(dataDir, "incidents"), database.GetDB())
+	retentionService.SetArchiveDir(filepath.Join(dataDir, "archives"))
+	apiHandler.SetRetentionRunner(retentionService)
+	apiHandler.SetIncidentExporter(services.NewIncidentExportService(database.GetDB(), filepath.Join(dataDir, "incidents")))
 
 	// Set up HTTP server routes
 	mux := http.NewServeMux()
@@ -375,11 +655,32 @@ func main() {
 	apiHandler.SetupRoutes(mux)
 	authHandler.SetupRoutes(mux)
 	agentWSHandler.SetupRoutes(mux)
-
-	// Wrap all routes with CORS middleware first, then JWT authentication, then request ID
-	corsMiddleware := middleware.NewCORSMiddleware() // Allow all origins
-	authenticatedHandler := corsMiddleware.Wrap(
-		middleware.RequestIDMiddleware(jwtAuthMiddleware.Wrap(mux)))
+	incidentStreamHandler.SetupRoutes(mux)
+	mux.Handle("GET /metrics", metrics.Handler(cfg.MetricsToken))
+	metrics.RegisterWorkerGauge(agentWSHandler.IsWorkerConnected)
+	metrics.RegisterWorkerCountGauge(agentWSHandler.WorkerCount)
+
+	// Wrap all routes with CORS middleware first, then JWT authentication.
+	// Without CORS_ALLOWED_ORIGINS only same-origin browsers (the bundled UI) can call the API.
+	// Inside authentication, settings/skill/tool changes are written to the audit log.
+	// Request latencies are measured outside that so rejected requests count too, and
+	// the request ID is assigned outermost so every logged request carries one.
+	// CORS settings saved in the UI replace the CORS_* variables once enabled.
+	corsEnv := middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAgeSeconds,
+	}
+	corsMiddleware := middleware.NewCORSMiddlewareWithConfig(corsEnv)
+	apiHandler.SetCORS(corsMiddleware, corsEnv)
+	if err := apiHandler.ReloadCORS(); err != nil {
+		slog.Warn("failed to load CORS settings, using environment", "err", err)
+	}
+	incidentStreamHandler.SetOriginAllowed(corsMiddleware.AllowsOrigin)
+	authenticatedHandler := middleware.RequestIDMiddleware(metrics.InstrumentHTTP(mux, corsMiddleware.Wrap(
+		jwtAuthMiddleware.Wrap(middleware.NewConfigAuditMiddleware(auditService).Wrap(mux)))))
 
 	// Start HTTP server in goroutine
 	httpServer := &http.Server{
@@ -417,45 +718,126 @@ func main() {
 	slog.Info("Bot is running! Press Ctrl+C to exit.")
 	slog.Info("alert webhook endpoint", "url", fmt.Sprintf("http://localhost:%d/webhook/alert/{instance_uuid}", cfg.HTTPPort))
 	slog.Info("health check endpoint", "url", fmt.Sprintf("http://localhost:%d/health", cfg.HTTPPort))
+	slog.Info("readiness check endpoint", "url", fmt.Sprintf("http://localhost:%d/readyz", cfg.HTTPPort))
 	slog.Info("API base URL", "url", fmt.Sprintf("http://localhost:%d/api", cfg.HTTPPort))
 	slog.Info("agent WebSocket endpoint", "url", fmt.Sprintf("ws://localhost:%d/ws/agent", cfg.HTTPPort))
+	slog.Info("incident stream endpoint", "url", fmt.Sprintf("ws://localhost:%d/ws/incidents/{uuid}", cfg.HTTPPort))
 
 	// Create a context for background goroutines
 	ctx, ctxCancel := context.WithCancel(context.Background())
 	defer ctxCancel()
 
-	// Start retention cleanup service
-	retentionService := services.NewRetentionService(filepath.Join(dataDir, "incidents"), database.GetDB())
-	go retentionService.StartBackgroundCleanup(ctx)
-	slog.Info("retention cleanup service started")
+	// CORS settings are saved through one replica; the others pick them up here.
+	go func() {
+		ticker := time.NewTicker(corsReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := apiHandler.ReloadCORS(); err != nil {
+					slog.Warn("failed to reload CORS settings", "err", err)
+				}
+			}
+		}
+	}()
 
-	// Start monitor sweep service: auto-closes incidents whose monitor window
-	// has expired so "monitor" doesn't accumulate indefinitely.
-	monitorSweepService := services.NewMonitorSweepService(database.GetDB())
-	go monitorSweepService.StartBackgroundSweep(ctx)
-	slog.Info("monitor sweep service started")
+	// Everything below runs on the leader replica only and is restarted
+	// there after a failover.
+	leaderElector.RunWhileLeader("retention-cleanup", retentionService.StartBackgroundCleanup)
 
-	// Start watching for Slack settings reload requests
-	go slackManager.WatchForReloads(ctx)
+	// Monitor sweep auto-closes incidents whose monitor window has expired
+	// so "monitor" doesn't accumulate indefinitely.
+	monitorSweepService := services.NewMonitorSweepService(database.GetDB())
+	monitorSweepService.SetIncidentEventPublisher(incidentEvents)
+	leaderElector.RunWhileLeader("monitor-sweep", monitorSweepService.StartBackgroundSweep)
+
+	// Stale incidents left in completed/monitor are closed after the idle
+	// period set in general settings (off by default).
+	staleIncidentCloser := services.NewStaleIncidentCloser(database.GetDB())
+	staleIncidentCloser.SetIncidentEventPublisher(incidentEvents)
+	staleIncidentCloser.SetIncidentTimeline(incidentTimeline)
+	leaderElector.RunWhileLeader("stale-incident-close", staleIncidentCloser.StartBackgroundSweep)
+
+	leaderElector.RunWhileLeader("approval-sweep", approvalService.StartBackgroundSweep)
+
+	// Weekly alert volume baselines; alerts firing far above theirs get a
+	// note on their open incidents.
+	leaderElector.RunWhileLeader("alert-baselines", alertBaselineService.StartBackgroundJob)
+
+	// SKILL.md edits on the shared volume are synced into the database by
+	// one replica.
+	if cfg.SkillsWatchEnabled {
+		leaderElector.RunWhileLeader("skills-watch", services.NewSkillWatcher(skillService).Watch)
+	}
 
-	// Start the cron runner so scheduled jobs begin ticking. Start is a no-op
-	// when called twice; cancellation flows through ctx so SIGTERM shuts the
-	// scheduler down cleanly before the HTTP server exits.
-	if err := cronRunner.Start(ctx); err != nil {
-		slog.Warn("failed to start cron runner", "err", err)
+	// Self-monitor evaluation loop (wired above when enabled)
+	if selfMonitor != nil {
+		leaderElector.RunWhileLeader("self-monitor", selfMonitor.StartBackgroundMonitor)
 	}
 
-	// Start Slack Socket Mode if enabled
+	// Cron runner: jobs must fire once per tick across the deployment. Start
+	// only fails when the jobs cannot be loaded, so keep retrying.
+	leaderElector.RunWhileLeader("cron-runner", func(leaderCtx context.Context) {
+		for {
+			err := cronRunner.Start(leaderCtx)
+			if err == nil {
+				break
+			}
+			slog.Warn("failed to start cron runner", "err", err)
+			select {
+			case <-leaderCtx.Done():
+				return
+			case <-time.After(30 * time.Second):
+			}
+		}
+		<-leaderCtx.Done()
+		cronRunner.Stop()
+	})
+
+	// Slack delivers each event to a single Socket Mode connection, so only
+	// the leader opens one. The connection is tied to leaderCtx and drops
+	// with leadership; the replica then goes back to a Web API client.
+	leaderElector.RunWhileLeader("slack-socket-mode", func(leaderCtx context.Context) {
+		slackManager.SetSocketModeEnabled(true)
+		if err := slackManager.Reload(leaderCtx); err != nil {
+			slog.Warn("failed to start Slack Socket Mode", "err", err)
+		}
+		<-leaderCtx.Done()
+		slackManager.SetSocketModeEnabled(false)
+		if err := slackManager.Reload(ctx); err != nil {
+			slog.Warn("failed to restart Slack client", "err", err)
+		}
+	})
+
+	// The worker is only accepted by the leader. A replica that steps down
+	// drops it so the worker reconnects and finds the new leader.
+	leaderElector.RunWhileLeader("agent-worker", func(leaderCtx context.Context) {
+		<-leaderCtx.Done()
+		agentWSHandler.DisconnectWorker()
+	})
+
+	// Start watching for Slack settings reload requests, both from this
+	// replica's API and from settings saved through another replica
+	go slackManager.WatchForReloads(ctx)
+	go slackManager.WatchSettings(ctx, 30*time.Second)
+
 	if slackEnabled {
 		if err := slackManager.Start(ctx); err != nil {
 			slog.Warn("failed to start Slack", "err", err)
-		} else {
-			slog.Info("Slack Socket Mode is ACTIVE")
 		}
 	} else {
 		slog.Info("running in API-only mode (Slack disabled)")
 	}
 
+	leaderElector.Start(ctx)
+	if leaderElector.IsLeader() {
+		slog.Info("this replica is the leader")
+	} else {
+		slog.Info("this replica is a follower; waiting for leadership")
+	}
+
 	// Keep the main goroutine alive
 	for {
 		time.Sleep(time.Hour)
@@ -16,6 +16,10 @@ type NormalizedAlert struct {
 	Summary     string
 	Description string
 
+	// SeverityMissing is set when the source sent no recognizable severity;
+	// Severity then holds the warning default until it is inferred.
+	SeverityMissing bool
+
 	TargetHost    string
 	TargetService string
 	TargetLabels  map[string]string
@@ -113,18 +117,25 @@ func MergeMappings(defaults, overrides database.JSONB) database.JSONB {
 
 // NormalizeSeverity normalizes severity strings to standard values
 func NormalizeSeverity(severity string, severityMapping map[string][]string) database.AlertSeverity {
-	severity = strings.ToLower(severity)
+	normalized, _ := ParseSeverity(severity, severityMapping)
+	return normalized
+}
+
+// ParseSeverity is NormalizeSeverity that also reports whether severity was
+// recognized. Unrecognized (or empty) values give warning and false.
+func ParseSeverity(severity string, severityMapping map[string][]string) (database.AlertSeverity, bool) {
+	severity = strings.ToLower(strings.TrimSpace(severity))
 
 	// Check direct match first
 	switch severity {
 	case "critical":
-		return database.AlertSeverityCritical
+		return database.AlertSeverityCritical, true
 	case "high":
-		return database.AlertSeverityHigh
+		return database.AlertSeverityHigh, true
 	case "warning":
-		return database.AlertSeverityWarning
+		return database.AlertSeverityWarning, true
 	case "info", "informational":
-		return database.AlertSeverityInfo
+		return database.AlertSeverityInfo, true
 	}
 
 	// Check severity mapping (range over nil map is safe - iterates zero times)
@@ -133,20 +144,20 @@ func NormalizeSeverity(severity string, severityMapping map[string][]string) dat
 			if strings.ToLower(alias) == severity {
 				switch normalized {
 				case "critical":
-					return database.AlertSeverityCritical
+					return database.AlertSeverityCritical, true
 				case "high":
-					return database.AlertSeverityHigh
+					return database.AlertSeverityHigh, true
 				case "warning":
-					return database.AlertSeverityWarning
+					return database.AlertSeverityWarning, true
 				case "info":
-					return database.AlertSeverityInfo
+					return database.AlertSeverityInfo, true
 				}
 			}
 		}
 	}
 
 	// Default to warning if unknown
-	return database.AlertSeverityWarning
+	return database.AlertSeverityWarning, false
 }
 
 // NormalizeStatus normalizes status strings to standard values
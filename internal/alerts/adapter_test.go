@@ -326,6 +326,17 @@ func TestNormalizeSeverity(t *testing.T) {
 	}
 }
 
+func TestParseSeverity_ReportsUnknown(t *testing.T) {
+	if got, ok := ParseSeverity("P1", DefaultSeverityMapping); !ok || got != database.AlertSeverityCritical {
+		t.Errorf("ParseSeverity(P1) = %v, %v; want critical, true", got, ok)
+	}
+	for _, severity := range []string{"", "unknown"} {
+		if got, ok := ParseSeverity(severity, DefaultSeverityMapping); ok || got != database.AlertSeverityWarning {
+			t.Errorf("ParseSeverity(%q) = %v, %v; want warning, false", severity, got, ok)
+		}
+	}
+}
+
 func TestNormalizeSeverity_WithDefaultMapping(t *testing.T) {
 	// Test with the default severity mapping
 	tests := []struct {
@@ -51,18 +51,8 @@ func (a *AlertmanagerAdapter) ValidateWebhookSecret(r *http.Request, instance *d
 		return nil // No secret configured, allow request
 	}
 
-	// Check custom header first
-	secret := r.Header.Get("X-Alertmanager-Secret")
-	if secret == "" {
-		// Also check Authorization header for basic auth style
-		secret = r.Header.Get("Authorization")
-	}
-
-	if secret != instance.WebhookSecret && secret != "Bearer "+instance.WebhookSecret {
-		return fmt.Errorf("invalid webhook secret")
-	}
-
-	return nil
+	// Check custom header first, then the Authorization header
+	return alerts.VerifyToken(r, instance.WebhookSecret, "X-Alertmanager-Secret", "Authorization")
 }
 
 // ParsePayload parses Alertmanager webhook payload into normalized alerts
@@ -132,6 +122,8 @@ func (a *AlertmanagerAdapter) parseAlert(alert AlertmanagerAlert, mappings datab
 		runbookURL = alert.Annotations["runbook_url"]
 	}
 
+	normalizedSeverity, severityKnown := alerts.ParseSeverity(severity, alerts.DefaultSeverityMapping)
+
 	// Parse times
 	var startedAt, endedAt *time.Time
 	if !alert.StartsAt.IsZero() {
@@ -143,7 +135,8 @@ func (a *AlertmanagerAdapter) parseAlert(alert AlertmanagerAlert, mappings datab
 
 	return alerts.NormalizedAlert{
 		AlertName:         alertName,
-		Severity:          alerts.NormalizeSeverity(severity, alerts.DefaultSeverityMapping),
+		Severity:          normalizedSeverity,
+		SeverityMissing:   !severityKnown,
 		Status:            alerts.NormalizeStatus(alert.Status),
 		Summary:           summary,
 		Description:       description,
@@ -252,6 +252,9 @@ func TestAlertmanagerAdapter_ParsePayload_SeverityMapping(t *testing.T) {
 		if alerts[0].Severity != tc.expectedSeverity {
 			t.Errorf("Severity '%s': expected %s, got %s", tc.severity, tc.expectedSeverity, alerts[0].Severity)
 		}
+		if alerts[0].SeverityMissing != (tc.severity == "unknown") {
+			t.Errorf("Severity '%s': SeverityMissing = %v", tc.severity, alerts[0].SeverityMissing)
+		}
 	}
 }
 
@@ -429,10 +432,13 @@ func TestAlertmanagerAdapter_ParsePayload_MissingFields(t *testing.T) {
 		t.Errorf("Expected empty AlertName, got '%s'", alert.AlertName)
 	}
 
-	// Severity should default to warning
+	// Severity should default to warning and be flagged for inference
 	if alert.Severity != database.AlertSeverityWarning {
 		t.Errorf("Expected default severity 'warning', got '%s'", alert.Severity)
 	}
+	if !alert.SeverityMissing {
+		t.Error("Expected SeverityMissing for an alert without a severity label")
+	}
 }
 
 func TestAlertmanagerAdapter_ParsePayload_CustomFieldMappings(t *testing.T) {
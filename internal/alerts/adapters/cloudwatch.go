@@ -0,0 +1,439 @@
+package adapters
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// CloudWatchTopicARNsSettingKey is the AlertSourceInstance.Settings key
+// holding the SNS topic ARNs the instance accepts (a string, comma
+// separated, or a list). Empty accepts any topic. A valid SNS signature only
+// proves the message came from SNS, not from one of your topics, so
+// production instances should set it.
+const CloudWatchTopicARNsSettingKey = "topic_arns"
+
+// snsHostPattern matches the SNS endpoints that serve signing certificates
+// and subscription confirmation URLs.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// CloudWatchAdapter handles AWS CloudWatch alarm notifications delivered by
+// an SNS HTTPS subscription.
+//
+// Every SNS message is signature-verified against the AWS signing
+// certificate. SubscriptionConfirmation messages are confirmed by visiting
+// their SubscribeURL, so pointing an SNS subscription at the webhook URL is
+// the only setup step.
+type CloudWatchAdapter struct {
+	alerts.BaseAdapter
+
+	httpClient *http.Client
+	// trustedHost reports whether a certificate or subscribe URL host is an
+	// SNS endpoint. Overridden in tests.
+	trustedHost func(host string) bool
+
+	certMu sync.Mutex
+	certs  map[string]*x509.Certificate // SigningCertURL -> certificate
+}
+
+// NewCloudWatchAdapter creates a new CloudWatch (SNS) adapter
+func NewCloudWatchAdapter() *CloudWatchAdapter {
+	return &CloudWatchAdapter{
+		BaseAdapter: alerts.BaseAdapter{SourceType: "cloudwatch"},
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		trustedHost: snsHostPattern.MatchString,
+		certs:       make(map[string]*x509.Certificate),
+	}
+}
+
+// snsMessage is the SNS HTTP(S) delivery envelope.
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// ValidateWebhookSecret validates the shared secret. SNS cannot send custom
+// headers, so the secret is taken from HTTP basic auth credentials embedded
+// in the subscription endpoint (https://akmatori:<secret>@host/...) or from a
+// "token" query parameter.
+func (a *CloudWatchAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
+	if instance.WebhookSecret == "" {
+		return nil // No secret configured, allow request
+	}
+
+	secret := r.URL.Query().Get("token")
+	if _, password, ok := r.BasicAuth(); ok {
+		secret = password
+	}
+
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(instance.WebhookSecret)) != 1 {
+		return fmt.Errorf("invalid webhook secret")
+	}
+
+	return nil
+}
+
+// ParsePayload verifies an SNS message and converts CloudWatch alarm
+// notifications into normalized alerts. Subscription handshakes are handled
+// here and produce zero alerts. INSUFFICIENT_DATA transitions are dropped:
+// they say nothing about the monitored resource.
+func (a *CloudWatchAdapter) ParsePayload(body []byte, instance *database.AlertSourceInstance) ([]alerts.NormalizedAlert, error) {
+	var msg snsMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse SNS message: %w", err)
+	}
+	if msg.Type == "" || msg.TopicArn == "" {
+		return nil, fmt.Errorf("failed to parse SNS message: missing Type or TopicArn")
+	}
+
+	if err := a.verifySignature(&msg); err != nil {
+		return nil, fmt.Errorf("SNS signature verification failed: %w", err)
+	}
+	if !topicAllowed(instance, msg.TopicArn) {
+		return nil, fmt.Errorf("SNS topic %s is not allowed for this alert source", msg.TopicArn)
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		if err := a.confirmSubscription(msg.SubscribeURL); err != nil {
+			return nil, err
+		}
+		slog.Info("confirmed SNS subscription", "topic_arn", msg.TopicArn, "instance", instance.Name)
+		return nil, nil
+	case "UnsubscribeConfirmation":
+		slog.Warn("SNS subscription removed", "topic_arn", msg.TopicArn, "instance", instance.Name)
+		return nil, nil
+	case "Notification":
+	default:
+		return nil, fmt.Errorf("unsupported SNS message type %q", msg.Type)
+	}
+
+	var alarm map[string]interface{}
+	if err := json.Unmarshal([]byte(msg.Message), &alarm); err != nil {
+		return nil, fmt.Errorf("SNS notification is not a CloudWatch alarm: %w", err)
+	}
+	if alerts.ExtractString(alarm, "AlarmName") == "" {
+		return nil, fmt.Errorf("SNS notification is not a CloudWatch alarm: missing AlarmName")
+	}
+
+	n, ok := a.parseAlarm(alarm, msg, alerts.MergeMappings(a.GetDefaultMappings(), instance.FieldMappings))
+	if !ok {
+		return nil, nil
+	}
+	return []alerts.NormalizedAlert{n}, nil
+}
+
+func (a *CloudWatchAdapter) parseAlarm(alarm map[string]interface{}, msg snsMessage, mappings database.JSONB) (alerts.NormalizedAlert, bool) {
+	var status database.AlertStatus
+	switch strings.ToUpper(alerts.ExtractString(alarm, "NewStateValue")) {
+	case "ALARM":
+		status = database.AlertStatusFiring
+	case "OK":
+		status = database.AlertStatusResolved
+	default:
+		return alerts.NormalizedAlert{}, false
+	}
+
+	// CloudWatch alarms carry no severity. Default to warning (flagged as
+	// missing, so it can be inferred) unless a mapping points at a field
+	// that does.
+	severity, severityKnown := alerts.ParseSeverity(alerts.ExtractString(alarm, getMapping(mappings, "severity")), alerts.DefaultSeverityMapping)
+
+	labels := map[string]string{
+		"topic_arn": msg.TopicArn,
+	}
+	for _, key := range []string{"AWSAccountId", "Region", "AlarmArn"} {
+		if v := alerts.ExtractString(alarm, key); v != "" {
+			labels[strings.ToLower(key)] = v
+		}
+	}
+	if ns := alerts.ExtractString(alarm, "Trigger.Namespace"); ns != "" {
+		labels["namespace"] = ns
+	}
+	var firstDimension string
+	if trigger, ok := alarm["Trigger"].(map[string]interface{}); ok {
+		dims, _ := trigger["Dimensions"].([]interface{})
+		for _, d := range dims {
+			dim, _ := d.(map[string]interface{})
+			name, _ := dim["name"].(string)
+			value, _ := dim["value"].(string)
+			if name == "" || value == "" {
+				continue
+			}
+			labels[name] = value
+			if firstDimension == "" {
+				firstDimension = value
+			}
+		}
+	}
+
+	alertName := alerts.ExtractString(alarm, getMapping(mappings, "alert_name"))
+	summary := alerts.ExtractString(alarm, getMapping(mappings, "summary"))
+	if summary == "" {
+		summary = msg.Subject
+	}
+	description := alerts.ExtractString(alarm, getMapping(mappings, "description"))
+	if description == "" {
+		description = summary
+	}
+
+	targetHost := alerts.ExtractString(alarm, getMapping(mappings, "target_host"))
+	if targetHost == "" {
+		targetHost = firstDimension
+	}
+
+	var threshold string
+	if v, ok := alerts.ExtractNestedValue(alarm, "Trigger.Threshold").(float64); ok {
+		threshold = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	var startedAt, endedAt *time.Time
+	if t, ok := cloudWatchTime(alerts.ExtractString(alarm, "StateChangeTime")); ok {
+		if status == database.AlertStatusResolved {
+			endedAt = &t
+		} else {
+			startedAt = &t
+		}
+	}
+
+	alarmArn := alerts.ExtractString(alarm, "AlarmArn")
+	if alarmArn == "" {
+		alarmArn = alertName
+	}
+
+	return alerts.NormalizedAlert{
+		AlertName:         alertName,
+		Severity:          severity,
+		SeverityMissing:   !severityKnown,
+		Status:            status,
+		Summary:           summary,
+		Description:       description,
+		TargetHost:        targetHost,
+		TargetService:     alerts.ExtractString(alarm, getMapping(mappings, "target_service")),
+		TargetLabels:      labels,
+		MetricName:        alerts.ExtractString(alarm, getMapping(mappings, "metric_name")),
+		ThresholdValue:    threshold,
+		RunbookURL:        alerts.ExtractString(alarm, getMapping(mappings, "runbook_url")),
+		StartedAt:         startedAt,
+		EndedAt:           endedAt,
+		SourceAlertID:     alerts.ExtractString(alarm, getMapping(mappings, "source_alert_id")),
+		SourceFingerprint: alarmArn,
+		RawPayload:        alarm,
+	}, true
+}
+
+// cloudWatchTime parses StateChangeTime, e.g. "2024-01-15T10:30:00.000+0000".
+func cloudWatchTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// topicAllowed applies the instance's optional topic ARN allowlist.
+func topicAllowed(instance *database.AlertSourceInstance, topicArn string) bool {
+	var allowed []string
+	switch v := instance.Settings[CloudWatchTopicARNsSettingKey].(type) {
+	case string:
+		for _, arn := range strings.Split(v, ",") {
+			if arn = strings.TrimSpace(arn); arn != "" {
+				allowed = append(allowed, arn)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if arn, ok := item.(string); ok && arn != "" {
+				allowed = append(allowed, arn)
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, arn := range allowed {
+		if arn == topicArn {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks the message signature against the SNS signing
+// certificate (SignatureVersion 1 is SHA1withRSA, 2 is SHA256withRSA).
+func (a *CloudWatchAdapter) verifySignature(msg *snsMessage) error {
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported SignatureVersion %q", msg.SignatureVersion)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	cert, err := a.signingCert(msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing certificate does not hold an RSA key")
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(snsStringToSign(msg)))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(snsStringToSign(msg)))
+		digest = sum[:]
+	}
+	return rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+}
+
+// snsStringToSign builds the canonical string SNS signs: selected fields as
+// "Name\nValue\n" pairs in byte order of name.
+func snsStringToSign(msg *snsMessage) string {
+	fields := [][2]string{{"Message", msg.Message}, {"MessageId", msg.MessageID}}
+	if msg.Type == "Notification" {
+		if msg.Subject != "" {
+			fields = append(fields, [2]string{"Subject", msg.Subject})
+		}
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", msg.SubscribeURL})
+	}
+	fields = append(fields, [2]string{"Timestamp", msg.Timestamp})
+	if msg.Type != "Notification" {
+		fields = append(fields, [2]string{"Token", msg.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", msg.TopicArn}, [2]string{"Type", msg.Type})
+
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0])
+		b.WriteByte('\n')
+		b.WriteString(f[1])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// signingCert fetches (and caches) the certificate at certURL after checking
+// it is served by SNS over HTTPS.
+func (a *CloudWatchAdapter) signingCert(certURL string) (*x509.Certificate, error) {
+	if err := a.checkSNSURL(certURL); err != nil {
+		return nil, fmt.Errorf("untrusted SigningCertURL: %w", err)
+	}
+
+	a.certMu.Lock()
+	cert, ok := a.certs[certURL]
+	a.certMu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	resp, err := a.httpClient.Get(certURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing certificate: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing certificate is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+
+	a.certMu.Lock()
+	a.certs[certURL] = cert
+	a.certMu.Unlock()
+	return cert, nil
+}
+
+// confirmSubscription visits the SubscribeURL of a verified
+// SubscriptionConfirmation message.
+func (a *CloudWatchAdapter) confirmSubscription(subscribeURL string) error {
+	if err := a.checkSNSURL(subscribeURL); err != nil {
+		return fmt.Errorf("untrusted SubscribeURL: %w", err)
+	}
+	resp, err := a.httpClient.Get(subscribeURL)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *CloudWatchAdapter) checkSNSURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("scheme must be https")
+	}
+	if !a.trustedHost(u.Hostname()) {
+		return fmt.Errorf("host %q is not an SNS endpoint", u.Hostname())
+	}
+	return nil
+}
+
+// GetDefaultMappings returns the default field mappings for CloudWatch
+// alarms. Paths are relative to the alarm JSON carried in the SNS Message.
+func (a *CloudWatchAdapter) GetDefaultMappings() database.JSONB {
+	return database.JSONB{
+		"alert_name":      "AlarmName",
+		"summary":         "NewStateReason",
+		"description":     "AlarmDescription",
+		"target_service":  "Trigger.Namespace",
+		"metric_name":     "Trigger.MetricName",
+		"source_alert_id": "AlarmArn",
+	}
+}
@@ -0,0 +1,320 @@
+package adapters
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// fakeSNS is a TLS server standing in for the SNS certificate and
+// subscription endpoints, plus the key that signs test messages.
+type fakeSNS struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	confirmed atomic.Int32
+	certHits  atomic.Int32
+}
+
+func newFakeSNS(t *testing.T) *fakeSNS {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	f := &fakeSNS{key: key}
+	f.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cert.pem":
+			f.certHits.Add(1)
+			_, _ = w.Write(certPEM)
+		case "/confirm":
+			f.confirmed.Add(1)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeSNS) adapter() *CloudWatchAdapter {
+	a := NewCloudWatchAdapter()
+	a.httpClient = f.server.Client()
+	a.trustedHost = func(string) bool { return true }
+	return a
+}
+
+// sign fills in the signature fields and returns the JSON body.
+func (f *fakeSNS) sign(t *testing.T, msg snsMessage) []byte {
+	t.Helper()
+	msg.SignatureVersion = "2"
+	msg.SigningCertURL = f.server.URL + "/cert.pem"
+	digest := sha256.Sum256([]byte(snsStringToSign(&msg)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	msg.Signature = base64.StdEncoding.EncodeToString(sig)
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return body
+}
+
+const cloudWatchAlarmJSON = `{
+	"AlarmName": "HighCPU-web-01",
+	"AlarmDescription": "CPU above 80% on web-01",
+	"AWSAccountId": "123456789012",
+	"NewStateValue": "ALARM",
+	"NewStateReason": "Threshold Crossed: 1 datapoint [93.5] was greater than the threshold (80.0).",
+	"StateChangeTime": "2024-01-15T10:30:00.000+0000",
+	"Region": "US East (N. Virginia)",
+	"AlarmArn": "arn:aws:cloudwatch:us-east-1:123456789012:alarm:HighCPU-web-01",
+	"OldStateValue": "OK",
+	"Trigger": {
+		"MetricName": "CPUUtilization",
+		"Namespace": "AWS/EC2",
+		"Statistic": "AVERAGE",
+		"Dimensions": [{"value": "i-0abc123", "name": "InstanceId"}],
+		"Period": 300,
+		"ComparisonOperator": "GreaterThanThreshold",
+		"Threshold": 80.0
+	}
+}`
+
+func notification(message string) snsMessage {
+	return snsMessage{
+		Type:      "Notification",
+		MessageID: "msg-1",
+		TopicArn:  "arn:aws:sns:us-east-1:123456789012:alerts",
+		Subject:   `ALARM: "HighCPU-web-01" in US East (N. Virginia)`,
+		Message:   message,
+		Timestamp: "2024-01-15T10:30:01.000Z",
+	}
+}
+
+func TestNewCloudWatchAdapter(t *testing.T) {
+	adapter := NewCloudWatchAdapter()
+	if adapter.GetSourceType() != "cloudwatch" {
+		t.Errorf("Expected source type 'cloudwatch', got '%s'", adapter.GetSourceType())
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_Alarm(t *testing.T) {
+	sns := newFakeSNS(t)
+	adapter := sns.adapter()
+
+	alerts, err := adapter.ParsePayload(sns.sign(t, notification(cloudWatchAlarmJSON)), &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+
+	if alert.AlertName != "HighCPU-web-01" {
+		t.Errorf("AlertName = %q", alert.AlertName)
+	}
+	if alert.Status != database.AlertStatusFiring || alert.Severity != database.AlertSeverityWarning {
+		t.Errorf("status/severity = %s/%s, want firing/warning", alert.Status, alert.Severity)
+	}
+	if alert.Description != "CPU above 80% on web-01" {
+		t.Errorf("Description = %q", alert.Description)
+	}
+	if alert.TargetHost != "i-0abc123" || alert.TargetService != "AWS/EC2" {
+		t.Errorf("target = %q / %q", alert.TargetHost, alert.TargetService)
+	}
+	if alert.MetricName != "CPUUtilization" || alert.ThresholdValue != "80" {
+		t.Errorf("metric = %q threshold = %q", alert.MetricName, alert.ThresholdValue)
+	}
+	if alert.SourceFingerprint != "arn:aws:cloudwatch:us-east-1:123456789012:alarm:HighCPU-web-01" {
+		t.Errorf("SourceFingerprint = %q", alert.SourceFingerprint)
+	}
+	if alert.StartedAt == nil || !alert.StartedAt.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("StartedAt = %v", alert.StartedAt)
+	}
+	for k, want := range map[string]string{"InstanceId": "i-0abc123", "awsaccountid": "123456789012", "namespace": "AWS/EC2", "topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts"} {
+		if alert.TargetLabels[k] != want {
+			t.Errorf("label %s = %q, want %q", k, alert.TargetLabels[k], want)
+		}
+	}
+
+	// A second message reuses the cached certificate.
+	if _, err := adapter.ParsePayload(sns.sign(t, notification(cloudWatchAlarmJSON)), &database.AlertSourceInstance{}); err != nil {
+		t.Fatalf("second ParsePayload: %v", err)
+	}
+	if got := sns.certHits.Load(); got != 1 {
+		t.Errorf("certificate fetched %d times, want 1", got)
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_StateMapping(t *testing.T) {
+	sns := newFakeSNS(t)
+	adapter := sns.adapter()
+
+	ok := `{"AlarmName":"HighCPU","NewStateValue":"OK","StateChangeTime":"2024-01-15T11:00:00.000+0000"}`
+	alerts, err := adapter.ParsePayload(sns.sign(t, notification(ok)), &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Status != database.AlertStatusResolved {
+		t.Fatalf("OK state = %+v, want one resolved alert", alerts)
+	}
+	if alerts[0].EndedAt == nil || alerts[0].StartedAt != nil {
+		t.Errorf("resolved alert times: started=%v ended=%v", alerts[0].StartedAt, alerts[0].EndedAt)
+	}
+
+	insufficient := `{"AlarmName":"HighCPU","NewStateValue":"INSUFFICIENT_DATA"}`
+	alerts, err = adapter.ParsePayload(sns.sign(t, notification(insufficient)), &database.AlertSourceInstance{})
+	if err != nil || len(alerts) != 0 {
+		t.Errorf("INSUFFICIENT_DATA = %d alerts, err %v; want dropped", len(alerts), err)
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_SubscriptionConfirmation(t *testing.T) {
+	sns := newFakeSNS(t)
+	adapter := sns.adapter()
+
+	body := sns.sign(t, snsMessage{
+		Type:         "SubscriptionConfirmation",
+		MessageID:    "msg-sub",
+		Token:        "tok",
+		TopicArn:     "arn:aws:sns:us-east-1:123456789012:alerts",
+		Message:      "You have chosen to subscribe to the topic",
+		SubscribeURL: sns.server.URL + "/confirm",
+		Timestamp:    "2024-01-15T10:00:00.000Z",
+	})
+	alerts, err := adapter.ParsePayload(body, &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("confirmation produced %d alerts, want 0", len(alerts))
+	}
+	if got := sns.confirmed.Load(); got != 1 {
+		t.Errorf("SubscribeURL visited %d times, want 1", got)
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_RejectsTampering(t *testing.T) {
+	sns := newFakeSNS(t)
+	adapter := sns.adapter()
+
+	var msg snsMessage
+	if err := json.Unmarshal(sns.sign(t, notification(cloudWatchAlarmJSON)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	msg.Message = `{"AlarmName":"Forged","NewStateValue":"ALARM"}`
+	body, _ := json.Marshal(msg)
+	if _, err := adapter.ParsePayload(body, &database.AlertSourceInstance{}); err == nil {
+		t.Error("expected tampered message to fail signature verification")
+	}
+
+	// Certificates must come from an SNS host.
+	strict := NewCloudWatchAdapter()
+	strict.httpClient = sns.server.Client()
+	if _, err := strict.ParsePayload(sns.sign(t, notification(cloudWatchAlarmJSON)), &database.AlertSourceInstance{}); err == nil {
+		t.Error("expected certificate from a non-SNS host to be rejected")
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_TopicAllowlist(t *testing.T) {
+	sns := newFakeSNS(t)
+	adapter := sns.adapter()
+	body := sns.sign(t, notification(cloudWatchAlarmJSON))
+
+	other := &database.AlertSourceInstance{Settings: database.JSONB{CloudWatchTopicARNsSettingKey: "arn:aws:sns:us-east-1:999:other"}}
+	if _, err := adapter.ParsePayload(body, other); err == nil {
+		t.Error("expected topic outside the allowlist to be rejected")
+	}
+
+	listed := &database.AlertSourceInstance{Settings: database.JSONB{
+		CloudWatchTopicARNsSettingKey: []interface{}{"arn:aws:sns:us-east-1:999:other", "arn:aws:sns:us-east-1:123456789012:alerts"},
+	}}
+	if alerts, err := adapter.ParsePayload(body, listed); err != nil || len(alerts) != 1 {
+		t.Errorf("allowlisted topic: %d alerts, err %v", len(alerts), err)
+	}
+}
+
+func TestCloudWatchAdapter_ParsePayload_Invalid(t *testing.T) {
+	adapter := NewCloudWatchAdapter()
+	for _, payload := range []string{`not json`, `{"foo":"bar"}`} {
+		if _, err := adapter.ParsePayload([]byte(payload), &database.AlertSourceInstance{}); err == nil {
+			t.Errorf("ParsePayload(%s) expected error", payload)
+		}
+	}
+}
+
+func TestCloudWatchAdapter_ValidateWebhookSecret(t *testing.T) {
+	adapter := NewCloudWatchAdapter()
+
+	tests := []struct {
+		name    string
+		secret  string
+		target  string
+		user    string
+		pass    string
+		wantErr bool
+	}{
+		{"no secret configured", "", "/webhook", "", "", false},
+		{"basic auth", "s3cret", "/webhook", "akmatori", "s3cret", false},
+		{"query token", "s3cret", "/webhook?token=s3cret", "", "", false},
+		{"wrong basic auth", "s3cret", "/webhook?token=s3cret", "akmatori", "nope", true},
+		{"missing secret", "s3cret", "/webhook", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			err := adapter.ValidateWebhookSecret(req, &database.AlertSourceInstance{WebhookSecret: tt.secret})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSNSHostPattern(t *testing.T) {
+	for host, want := range map[string]bool{
+		"sns.us-east-1.amazonaws.com":      true,
+		"sns.cn-north-1.amazonaws.com.cn":  true,
+		"sns.us-east-1.amazonaws.com.evil": false,
+		"evil.com":                         false,
+		"sns.amazonaws.com":                false,
+	} {
+		if got := snsHostPattern.MatchString(host); got != want {
+			t.Errorf("snsHostPattern(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
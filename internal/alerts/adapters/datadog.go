@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -51,26 +52,22 @@ type DatadogPayload struct {
 	LastUpdated   int64  `json:"last_updated"`
 }
 
-// ValidateWebhookSecret validates the Datadog webhook secret
+// ValidateWebhookSecret validates the Datadog webhook secret. Datadog does
+// not sign webhooks, so the secret is a shared token the webhook sends back:
+// in an X-Datadog-Secret or Authorization (Bearer) custom header, or as the
+// password of the webhook's basic authentication.
 func (a *DatadogAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
 	if instance.WebhookSecret == "" {
 		return nil // No secret configured, allow request
 	}
 
-	// Check custom header or Authorization
-	secret := r.Header.Get("X-Datadog-Signature")
-	if secret == "" {
-		secret = r.Header.Get("DD-API-KEY")
-	}
-	if secret == "" {
-		secret = r.Header.Get("Authorization")
-	}
-
-	if secret != instance.WebhookSecret && secret != "Bearer "+instance.WebhookSecret {
-		return fmt.Errorf("invalid webhook secret")
+	if _, password, ok := r.BasicAuth(); ok {
+		if subtle.ConstantTimeCompare([]byte(password), []byte(instance.WebhookSecret)) != 1 {
+			return alerts.ErrInvalidToken
+		}
+		return nil
 	}
-
-	return nil
+	return alerts.VerifyToken(r, instance.WebhookSecret, "X-Datadog-Secret", "Authorization")
 }
 
 // ParsePayload parses Datadog webhook payload into normalized alerts
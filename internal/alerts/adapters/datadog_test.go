@@ -377,33 +377,38 @@ func TestDatadogAdapter_ValidateWebhookSecret_NoSecret(t *testing.T) {
 	}
 }
 
-func TestDatadogAdapter_ValidateWebhookSecret_DDAPIKey(t *testing.T) {
+func TestDatadogAdapter_ValidateWebhookSecret_CustomHeader(t *testing.T) {
 	adapter := NewDatadogAdapter()
 	instance := &database.AlertSourceInstance{
-		WebhookSecret: "dd-api-key",
+		WebhookSecret: "dd-secret",
 	}
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook/alert", nil)
-	req.Header.Set("DD-API-KEY", "dd-api-key")
+	req.Header.Set("X-Datadog-Secret", "dd-secret")
 
 	err := adapter.ValidateWebhookSecret(req, instance)
 	if err != nil {
-		t.Errorf("Expected no error for valid DD-API-KEY, got: %v", err)
+		t.Errorf("Expected no error for valid X-Datadog-Secret, got: %v", err)
 	}
 }
 
-func TestDatadogAdapter_ValidateWebhookSecret_Signature(t *testing.T) {
+func TestDatadogAdapter_ValidateWebhookSecret_BasicAuth(t *testing.T) {
 	adapter := NewDatadogAdapter()
 	instance := &database.AlertSourceInstance{
 		WebhookSecret: "dd-secret",
 	}
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook/alert", nil)
-	req.Header.Set("X-Datadog-Signature", "dd-secret")
+	req.SetBasicAuth("datadog", "dd-secret")
+	if err := adapter.ValidateWebhookSecret(req, instance); err != nil {
+		t.Errorf("Expected no error for valid basic auth password, got: %v", err)
+	}
 
-	err := adapter.ValidateWebhookSecret(req, instance)
-	if err != nil {
-		t.Errorf("Expected no error for valid signature, got: %v", err)
+	req = httptest.NewRequest(http.MethodPost, "/webhook/alert", nil)
+	req.SetBasicAuth("datadog", "wrong-secret")
+	req.Header.Set("X-Datadog-Secret", "dd-secret")
+	if err := adapter.ValidateWebhookSecret(req, instance); err == nil {
+		t.Error("Expected error for wrong basic auth password, got nil")
 	}
 }
 
@@ -429,7 +434,7 @@ func TestDatadogAdapter_ValidateWebhookSecret_InvalidSecret(t *testing.T) {
 	}
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook/alert", nil)
-	req.Header.Set("DD-API-KEY", "wrong-secret")
+	req.Header.Set("X-Datadog-Secret", "wrong-secret")
 
 	err := adapter.ValidateWebhookSecret(req, instance)
 	if err == nil {
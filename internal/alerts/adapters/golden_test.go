@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+// goldenAlert is the part of a normalized alert pinned by the golden files.
+// Timestamps are left out: adapters fall back to the receive time when a
+// payload has none.
+type goldenAlert struct {
+	AlertName         string                 `json:"alert_name"`
+	Severity          database.AlertSeverity `json:"severity"`
+	SeverityMissing   bool                   `json:"severity_missing,omitempty"`
+	Status            database.AlertStatus   `json:"status"`
+	Summary           string                 `json:"summary"`
+	Description       string                 `json:"description,omitempty"`
+	TargetHost        string                 `json:"target_host"`
+	TargetService     string                 `json:"target_service,omitempty"`
+	TargetLabels      map[string]string      `json:"target_labels,omitempty"`
+	MetricName        string                 `json:"metric_name,omitempty"`
+	MetricValue       string                 `json:"metric_value,omitempty"`
+	ThresholdValue    string                 `json:"threshold_value,omitempty"`
+	RunbookURL        string                 `json:"runbook_url,omitempty"`
+	SourceAlertID     string                 `json:"source_alert_id,omitempty"`
+	SourceFingerprint string                 `json:"source_fingerprint,omitempty"`
+}
+
+// TestAdapters_GoldenPayloads parses the sample webhook payload of every
+// adapter in tests/fixtures/alerts and compares the normalized alerts with
+// tests/fixtures/alerts/golden. Rerun with AKMATORI_UPDATE_GOLDEN=1 after an
+// intended normalization change and review the golden diff.
+func TestAdapters_GoldenPayloads(t *testing.T) {
+	tests := []struct {
+		fixture string
+		parse   func(t *testing.T, payload []byte) ([]alerts.NormalizedAlert, error)
+	}{
+		{"alertmanager_firing", plainParse(NewAlertmanagerAdapter())},
+		{"grafana_alerting", plainParse(NewGrafanaAdapter())},
+		{"datadog_monitor", plainParse(NewDatadogAdapter())},
+		{"pagerduty_trigger", plainParse(NewPagerDutyAdapter())},
+		{"sentry_alert", plainParse(NewSentryAdapter())},
+		{"victorops_alert", plainParse(NewVictorOpsAdapter())},
+		{"zabbix_problem", plainParse(NewZabbixAdapter())},
+		// The CloudWatch fixture is the alarm; SNS delivers it signed.
+		{"cloudwatch_alarm", func(t *testing.T, payload []byte) ([]alerts.NormalizedAlert, error) {
+			sns := newFakeSNS(t)
+			return sns.adapter().ParsePayload(sns.sign(t, notification(string(payload))), &database.AlertSourceInstance{})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			parsed, err := tt.parse(t, testhelpers.LoadFixture(t, "alerts/"+tt.fixture+".json"))
+			if err != nil {
+				t.Fatalf("ParsePayload: %v", err)
+			}
+			if len(parsed) == 0 {
+				t.Fatal("fixture produced no alerts")
+			}
+			got := make([]goldenAlert, 0, len(parsed))
+			for _, a := range parsed {
+				got = append(got, goldenAlert{
+					AlertName:         a.AlertName,
+					Severity:          a.Severity,
+					SeverityMissing:   a.SeverityMissing,
+					Status:            a.Status,
+					Summary:           a.Summary,
+					Description:       a.Description,
+					TargetHost:        a.TargetHost,
+					TargetService:     a.TargetService,
+					TargetLabels:      a.TargetLabels,
+					MetricName:        a.MetricName,
+					MetricValue:       a.MetricValue,
+					ThresholdValue:    a.ThresholdValue,
+					RunbookURL:        a.RunbookURL,
+					SourceAlertID:     a.SourceAlertID,
+					SourceFingerprint: a.SourceFingerprint,
+				})
+			}
+			testhelpers.AssertGoldenJSON(t, "alerts/golden/"+tt.fixture+".json", got)
+		})
+	}
+}
+
+func plainParse(adapter alerts.AlertAdapter) func(*testing.T, []byte) ([]alerts.NormalizedAlert, error) {
+	return func(_ *testing.T, payload []byte) ([]alerts.NormalizedAlert, error) {
+		return adapter.ParsePayload(payload, &database.AlertSourceInstance{})
+	}
+}
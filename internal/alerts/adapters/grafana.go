@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
@@ -40,23 +41,18 @@ type GrafanaAlert struct {
 	GeneratorURL string            `json:"generatorURL"`
 }
 
-// ValidateWebhookSecret validates the Grafana webhook secret header
+// ValidateWebhookSecret verifies the contact point's HMAC signature when
+// Grafana sends one, and the secret header otherwise.
 func (a *GrafanaAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
 	if instance.WebhookSecret == "" {
 		return nil // No secret configured, allow request
 	}
 
-	// Check custom header
-	secret := r.Header.Get("X-Grafana-Secret")
-	if secret == "" {
-		secret = r.Header.Get("Authorization")
+	scheme := alerts.HMACSchemes["grafana"]
+	if r.Header.Get(scheme.Header) != "" {
+		return alerts.VerifyHMAC(r, instance.WebhookSecret, scheme, 0, time.Now())
 	}
-
-	if secret != instance.WebhookSecret && secret != "Bearer "+instance.WebhookSecret {
-		return fmt.Errorf("invalid webhook secret")
-	}
-
-	return nil
+	return alerts.VerifyToken(r, instance.WebhookSecret, "X-Grafana-Secret", "Authorization")
 }
 
 // ParsePayload parses Grafana webhook payload into normalized alerts
@@ -109,7 +105,7 @@ func (a *GrafanaAdapter) parseUnifiedAlert(alert GrafanaAlert, mappings database
 	if severityStr == "" {
 		severityStr = alert.Labels["severity"]
 	}
-	severity := alerts.NormalizeSeverity(severityStr, alerts.DefaultSeverityMapping)
+	severity, severityKnown := alerts.ParseSeverity(severityStr, alerts.DefaultSeverityMapping)
 
 	targetHost := alerts.ExtractString(alertMap, getMapping(mappings, "target_host"))
 	if targetHost == "" {
@@ -129,6 +125,7 @@ func (a *GrafanaAdapter) parseUnifiedAlert(alert GrafanaAlert, mappings database
 	return alerts.NormalizedAlert{
 		AlertName:         alertName,
 		Severity:          severity,
+		SeverityMissing:   !severityKnown,
 		Status:            status,
 		Summary:           alert.Annotations["summary"],
 		Description:       alert.Annotations["description"],
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
@@ -54,29 +55,19 @@ type PagerDutyPayload struct {
 	} `json:"event"`
 }
 
-// ValidateWebhookSecret validates the PagerDuty webhook signature
+// ValidateWebhookSecret verifies the v3 webhook signature (X-PagerDuty-
+// Signature, v1=<hex HMAC-SHA256 of the body>). Without one, the secret may
+// be sent in the Authorization header.
 func (a *PagerDutyAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
 	if instance.WebhookSecret == "" {
 		return nil // No secret configured, allow request
 	}
 
-	// PagerDuty uses HMAC-SHA256 signature
-	signature := r.Header.Get("X-PagerDuty-Signature")
-	if signature == "" {
-		// Also check for custom header
-		signature = r.Header.Get("Authorization")
-		if signature == instance.WebhookSecret || signature == "Bearer "+instance.WebhookSecret {
-			return nil
-		}
-		return fmt.Errorf("missing webhook signature")
+	scheme := alerts.HMACSchemes["pagerduty"]
+	if r.Header.Get(scheme.Header) != "" {
+		return alerts.VerifyHMAC(r, instance.WebhookSecret, scheme, 0, time.Now())
 	}
-
-	// For HMAC validation, we'd need the body - simplified check here
-	if !strings.HasPrefix(signature, "v1=") {
-		return fmt.Errorf("invalid signature format")
-	}
-
-	return nil
+	return alerts.VerifyToken(r, instance.WebhookSecret, "Authorization")
 }
 
 // ParsePayload parses PagerDuty webhook payload into normalized alerts
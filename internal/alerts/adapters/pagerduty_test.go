@@ -1,9 +1,16 @@
 package adapters
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 )
@@ -267,18 +274,41 @@ func TestPagerDutyAdapter_ValidateWebhookSecret_BearerToken(t *testing.T) {
 	}
 }
 
-func TestPagerDutyAdapter_ValidateWebhookSecret_SignatureFormat(t *testing.T) {
+func TestPagerDutyAdapter_ValidateWebhookSecret_Signature(t *testing.T) {
 	adapter := NewPagerDutyAdapter()
 	instance := &database.AlertSourceInstance{
 		WebhookSecret: "pd-secret",
 	}
+	// Unique per run: an accepted signature is not accepted again.
+	body := fmt.Sprintf(`{"event":{"id":"%d"}}`, time.Now().UnixNano())
+	mac := hmac.New(sha256.New, []byte("pd-secret"))
+	mac.Write([]byte(body))
+	signature := "v1=" + hex.EncodeToString(mac.Sum(nil))
 
-	req := httptest.NewRequest(http.MethodPost, "/webhook/alert", nil)
-	req.Header.Set("X-PagerDuty-Signature", "v1=abc123")
+	// During secret rotation PagerDuty sends one signature per secret.
+	req := httptest.NewRequest(http.MethodPost, "/webhook/alert", strings.NewReader(body))
+	req.Header.Set("X-PagerDuty-Signature", "v1=abc123,"+signature)
 
 	err := adapter.ValidateWebhookSecret(req, instance)
 	if err != nil {
-		t.Errorf("Expected no error for valid signature format, got: %v", err)
+		t.Errorf("Expected no error for valid signature, got: %v", err)
+	}
+	if b, _ := io.ReadAll(req.Body); string(b) != body {
+		t.Errorf("body after validation = %q, want it unchanged", b)
+	}
+
+	// A well-formed signature of a different body is rejected.
+	req = httptest.NewRequest(http.MethodPost, "/webhook/alert", strings.NewReader(`{"event":{"id":"forged"}}`))
+	req.Header.Set("X-PagerDuty-Signature", signature)
+	if err := adapter.ValidateWebhookSecret(req, instance); err == nil {
+		t.Error("Expected error for a signature of another body, got nil")
+	}
+
+	// Nor is the same delivery sent twice.
+	req = httptest.NewRequest(http.MethodPost, "/webhook/alert", strings.NewReader(body))
+	req.Header.Set("X-PagerDuty-Signature", signature)
+	if err := adapter.ValidateWebhookSecret(req, instance); err == nil {
+		t.Error("Expected error for a replayed delivery, got nil")
 	}
 }
 
@@ -0,0 +1,335 @@
+package adapters
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// SentryAdapter handles Sentry issue-alert webhooks.
+//
+// Two payload shapes are accepted:
+//   - the legacy Webhooks plugin ("project", "level", "culprit", "event" at
+//     the top level), sent when an issue alert rule fires;
+//   - Sentry integration platform webhooks: "event_alert" (action
+//     "triggered") and "issue" (actions "created", "unresolved" and
+//     "resolved"). Other issue actions such as "assigned" are dropped.
+//
+// Alerts are deduplicated on the Sentry issue ID, so repeated events for one
+// issue attach to the same incident.
+type SentryAdapter struct {
+	alerts.BaseAdapter
+}
+
+// NewSentryAdapter creates a new Sentry adapter
+func NewSentryAdapter() *SentryAdapter {
+	return &SentryAdapter{
+		BaseAdapter: alerts.BaseAdapter{SourceType: "sentry"},
+	}
+}
+
+// ValidateWebhookSecret checks the integration platform signature
+// (Sentry-Hook-Signature, hex HMAC-SHA256 of the body keyed by the client
+// secret). The legacy plugin cannot sign requests, so it may instead pass
+// the secret as ?token= on the webhook URL or as a bearer token.
+func (a *SentryAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
+	if instance.WebhookSecret == "" {
+		return nil // No secret configured, allow request
+	}
+
+	scheme := alerts.HMACSchemes["sentry"]
+	if r.Header.Get(scheme.Header) != "" {
+		return alerts.VerifyHMAC(r, instance.WebhookSecret, scheme, 0, time.Now())
+	}
+
+	secret := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		secret = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(instance.WebhookSecret)) != 1 {
+		return fmt.Errorf("invalid webhook secret")
+	}
+
+	return nil
+}
+
+// ParsePayload parses a Sentry webhook into normalized alerts.
+func (a *SentryAdapter) ParsePayload(body []byte, instance *database.AlertSourceInstance) ([]alerts.NormalizedAlert, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse sentry payload: %w", err)
+	}
+
+	issue, status, ok, err := sentryIssueMap(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	mappings := alerts.MergeMappings(a.GetDefaultMappings(), instance.FieldMappings)
+	return []alerts.NormalizedAlert{a.parseIssue(issue, raw, status, mappings)}, nil
+}
+
+// sentryIssueMap flattens either payload shape into one map with the keys
+// used by GetDefaultMappings: issue_id, title, message, level, culprit,
+// project, environment, url, fingerprint, hashes, timestamp and tags (a
+// key/value map). ok is false for webhooks that should not open or resolve
+// an incident.
+func sentryIssueMap(raw map[string]interface{}) (issue map[string]interface{}, status database.AlertStatus, ok bool, err error) {
+	status = database.AlertStatusFiring
+	data, _ := raw["data"].(map[string]interface{})
+	action, _ := raw["action"].(string)
+
+	switch {
+	case data != nil && data["event"] != nil:
+		// Integration platform event_alert
+		if action != "" && action != "triggered" {
+			return nil, "", false, nil
+		}
+		event, _ := data["event"].(map[string]interface{})
+		issue = sentryEventFields(event)
+		issue["issue_id"] = sentryString(event["issue_id"])
+		issue["url"] = sentryString(event["web_url"])
+		issue["project"] = sentryString(event["project"])
+		if rule := sentryString(data["triggered_rule"]); rule != "" {
+			issue["rule"] = rule
+		}
+
+	case data != nil && data["issue"] != nil:
+		// Integration platform issue resource
+		switch action {
+		case "created", "unresolved":
+		case "resolved":
+			status = database.AlertStatusResolved
+		default:
+			return nil, "", false, nil
+		}
+		src, _ := data["issue"].(map[string]interface{})
+		issue = map[string]interface{}{
+			"issue_id":  sentryString(src["id"]),
+			"title":     sentryString(src["title"]),
+			"level":     sentryString(src["level"]),
+			"culprit":   sentryString(src["culprit"]),
+			"url":       firstNonEmpty(sentryString(src["permalink"]), sentryString(src["web_url"])),
+			"timestamp": sentryString(src["firstSeen"]),
+			"tags":      map[string]interface{}{},
+		}
+		if project, ok := src["project"].(map[string]interface{}); ok {
+			issue["project"] = firstNonEmpty(sentryString(project["slug"]), sentryString(project["name"]))
+		}
+		if meta, ok := src["metadata"].(map[string]interface{}); ok {
+			issue["message"] = sentryString(meta["value"])
+		}
+
+	case raw["project"] != nil || raw["event"] != nil:
+		// Legacy Webhooks plugin
+		event, _ := raw["event"].(map[string]interface{})
+		issue = sentryEventFields(event)
+		issue["issue_id"] = sentryString(raw["id"])
+		issue["url"] = sentryString(raw["url"])
+		issue["project"] = firstNonEmpty(sentryString(raw["project_slug"]), sentryString(raw["project"]))
+		for _, k := range []string{"level", "culprit", "message"} {
+			if v := sentryString(raw[k]); v != "" {
+				issue[k] = v
+			}
+		}
+		if rules, ok := raw["triggering_rules"].([]interface{}); ok && len(rules) > 0 {
+			issue["rule"] = sentryString(rules[0])
+		}
+
+	default:
+		return nil, "", false, fmt.Errorf("failed to parse sentry payload: no event or issue")
+	}
+
+	if sentryString(issue["issue_id"]) == "" {
+		return nil, "", false, fmt.Errorf("failed to parse sentry payload: missing issue ID")
+	}
+	return issue, status, true, nil
+}
+
+// sentryEventFields extracts the fields shared by plugin and event_alert
+// payloads from a Sentry event object.
+func sentryEventFields(event map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{
+		"title":       sentryString(event["title"]),
+		"message":     firstNonEmpty(sentryString(event["message"]), sentryString(event["title"])),
+		"level":       sentryString(event["level"]),
+		"culprit":     sentryString(event["culprit"]),
+		"environment": sentryString(event["environment"]),
+		"fingerprint": sentryJoin(event["fingerprint"]),
+		"hashes":      sentryJoin(event["hashes"]),
+		"timestamp":   firstNonEmpty(sentryString(event["datetime"]), sentryString(event["timestamp"])),
+		"tags":        sentryTags(event["tags"]),
+	}
+	if env := sentryString(out["environment"]); env == "" {
+		if tags, ok := out["tags"].(map[string]interface{}); ok {
+			out["environment"] = sentryString(tags["environment"])
+		}
+	}
+	return out
+}
+
+// sentryTags converts Sentry's tag list, either [["key", "value"], ...] or
+// [{"key": ..., "value": ...}, ...], into a map.
+func sentryTags(v interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		switch t := item.(type) {
+		case []interface{}:
+			if len(t) == 2 {
+				if k := sentryString(t[0]); k != "" {
+					out[k] = sentryString(t[1])
+				}
+			}
+		case map[string]interface{}:
+			if k := sentryString(t["key"]); k != "" {
+				out[k] = sentryString(t["value"])
+			}
+		}
+	}
+	return out
+}
+
+// sentryJoin joins a list of strings (fingerprint entries, hashes) with ",".
+func sentryJoin(v interface{}) string {
+	list, _ := v.([]interface{})
+	parts := make([]string, 0, len(list))
+	for _, item := range list {
+		if s := sentryString(item); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sentryString renders IDs that Sentry sends as either strings or numbers.
+func sentryString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (a *SentryAdapter) parseIssue(issue, raw map[string]interface{}, status database.AlertStatus, mappings database.JSONB) alerts.NormalizedAlert {
+	issueID := sentryString(issue["issue_id"])
+	level := strings.ToLower(alerts.ExtractString(issue, getMapping(mappings, "severity")))
+
+	alertName := alerts.ExtractString(issue, getMapping(mappings, "alert_name"))
+	if alertName == "" {
+		alertName = "Sentry issue " + issueID
+	}
+	summary := alerts.ExtractString(issue, getMapping(mappings, "summary"))
+	if summary == "" {
+		summary = alertName
+	}
+	description := alerts.ExtractString(issue, getMapping(mappings, "description"))
+	if description == "" {
+		description = summary
+	}
+
+	labels := map[string]string{}
+	if tags, ok := issue["tags"].(map[string]interface{}); ok {
+		for k, v := range tags {
+			if s := sentryString(v); s != "" {
+				labels[k] = s
+			}
+		}
+	}
+	// Issue fields win over event tags of the same name.
+	for _, k := range []string{"project", "level", "culprit", "environment", "fingerprint", "hashes", "url", "rule"} {
+		if s := sentryString(issue[k]); s != "" {
+			labels[k] = s
+		}
+	}
+	labels["issue_id"] = issueID
+
+	var startedAt *time.Time
+	if t, ok := sentryTime(issue["timestamp"]); ok {
+		startedAt = &t
+	}
+
+	return alerts.NormalizedAlert{
+		AlertName:         alertName,
+		Severity:          a.mapLevelToSeverity(level),
+		Status:            status,
+		Summary:           summary,
+		Description:       description,
+		TargetHost:        alerts.ExtractString(issue, getMapping(mappings, "target_host")),
+		TargetService:     alerts.ExtractString(issue, getMapping(mappings, "target_service")),
+		TargetLabels:      labels,
+		RunbookURL:        alerts.ExtractString(issue, getMapping(mappings, "runbook_url")),
+		StartedAt:         startedAt,
+		SourceAlertID:     issueID,
+		SourceFingerprint: issueID,
+		RawPayload:        raw,
+	}
+}
+
+// mapLevelToSeverity maps a Sentry event level to a normalized severity.
+func (a *SentryAdapter) mapLevelToSeverity(level string) database.AlertSeverity {
+	switch level {
+	case "fatal":
+		return database.AlertSeverityCritical
+	case "error":
+		return database.AlertSeverityHigh
+	case "info", "debug":
+		return database.AlertSeverityInfo
+	default:
+		return database.AlertSeverityWarning
+	}
+}
+
+// sentryTime parses an RFC 3339 datetime or a Unix timestamp in seconds
+// (Sentry sends fractional seconds as a number or string).
+func sentryTime(v interface{}) (time.Time, bool) {
+	s := sentryString(v)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(secs*float64(time.Second))).UTC(), true
+}
+
+// GetDefaultMappings returns the default field mappings for Sentry. Paths
+// are relative to the flattened issue (see sentryIssueMap); event tags live
+// under "tags.".
+func (a *SentryAdapter) GetDefaultMappings() database.JSONB {
+	return database.JSONB{
+		"alert_name":      "title",
+		"severity":        "level",
+		"summary":         "message",
+		"description":     "culprit",
+		"target_host":     "tags.server_name",
+		"target_service":  "project",
+		"source_alert_id": "issue_id",
+	}
+}
@@ -0,0 +1,212 @@
+package adapters
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+const sentryPluginPayload = `{
+	"id": "4512345678",
+	"project": "checkout-api",
+	"project_name": "Checkout API",
+	"project_slug": "checkout-api",
+	"level": "error",
+	"culprit": "app.payments.charge in submit",
+	"message": "ZeroDivisionError: division by zero",
+	"url": "https://acme.sentry.io/issues/4512345678/",
+	"triggering_rules": ["Page on new errors"],
+	"event": {
+		"event_id": "a1b2c3",
+		"title": "ZeroDivisionError: division by zero",
+		"level": "error",
+		"culprit": "app.payments.charge in submit",
+		"fingerprint": ["{{ default }}"],
+		"hashes": ["c4ca4238a0b923820dcc509a6f75849b"],
+		"datetime": "2026-01-15T10:30:00Z",
+		"tags": [["environment", "production"], ["server_name", "checkout-7f9c"], ["level", "debug"]]
+	}
+}`
+
+func TestNewSentryAdapter(t *testing.T) {
+	adapter := NewSentryAdapter()
+	if adapter.GetSourceType() != "sentry" {
+		t.Errorf("Expected source type 'sentry', got '%s'", adapter.GetSourceType())
+	}
+}
+
+func TestSentryAdapter_ParsePayload_Plugin(t *testing.T) {
+	adapter := NewSentryAdapter()
+
+	alerts, err := adapter.ParsePayload([]byte(sentryPluginPayload), &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+
+	if alert.AlertName != "ZeroDivisionError: division by zero" {
+		t.Errorf("AlertName = %q", alert.AlertName)
+	}
+	if alert.Severity != database.AlertSeverityHigh || alert.Status != database.AlertStatusFiring {
+		t.Errorf("severity/status = %q/%q, want high/firing", alert.Severity, alert.Status)
+	}
+	if alert.Description != "app.payments.charge in submit" {
+		t.Errorf("Description = %q, want the culprit", alert.Description)
+	}
+	if alert.TargetService != "checkout-api" || alert.TargetHost != "checkout-7f9c" {
+		t.Errorf("target = %q / %q", alert.TargetHost, alert.TargetService)
+	}
+	if alert.SourceAlertID != "4512345678" || alert.SourceFingerprint != "4512345678" {
+		t.Errorf("source IDs = %q / %q, want the issue ID", alert.SourceAlertID, alert.SourceFingerprint)
+	}
+	wantLabels := map[string]string{
+		"project":     "checkout-api",
+		"level":       "error",
+		"culprit":     "app.payments.charge in submit",
+		"environment": "production",
+		"fingerprint": "{{ default }}",
+		"hashes":      "c4ca4238a0b923820dcc509a6f75849b",
+		"rule":        "Page on new errors",
+		"issue_id":    "4512345678",
+	}
+	for k, want := range wantLabels {
+		if got := alert.TargetLabels[k]; got != want {
+			t.Errorf("label %s = %q, want %q", k, got, want)
+		}
+	}
+	if alert.StartedAt == nil || !alert.StartedAt.Equal(time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("StartedAt = %v", alert.StartedAt)
+	}
+}
+
+func TestSentryAdapter_ParsePayload_EventAlert(t *testing.T) {
+	adapter := NewSentryAdapter()
+	payload := []byte(`{
+		"action": "triggered",
+		"data": {
+			"event": {
+				"issue_id": 1170820242,
+				"project": 1,
+				"title": "TypeError: cannot read properties of undefined",
+				"level": "fatal",
+				"culprit": "renderCart(cart.js)",
+				"web_url": "https://acme.sentry.io/issues/1170820242/events/abc/",
+				"environment": "staging",
+				"fingerprint": ["cart", "render"],
+				"timestamp": 1705314600.5
+			},
+			"triggered_rule": "Fatal errors"
+		}
+	}`)
+
+	alerts, err := adapter.ParsePayload(payload, &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.SourceFingerprint != "1170820242" {
+		t.Errorf("SourceFingerprint = %q", alert.SourceFingerprint)
+	}
+	if alert.Severity != database.AlertSeverityCritical {
+		t.Errorf("Severity = %q, want critical for fatal", alert.Severity)
+	}
+	if alert.TargetLabels["fingerprint"] != "cart,render" || alert.TargetLabels["environment"] != "staging" {
+		t.Errorf("labels = %v", alert.TargetLabels)
+	}
+	if alert.StartedAt == nil || alert.StartedAt.Unix() != 1705314600 {
+		t.Errorf("StartedAt = %v", alert.StartedAt)
+	}
+}
+
+func TestSentryAdapter_ParsePayload_IssueActions(t *testing.T) {
+	adapter := NewSentryAdapter()
+	issue := func(action string) []byte {
+		return []byte(`{"action":"` + action + `","data":{"issue":{"id":"77","title":"KeyError: 'user'","level":"error","culprit":"auth.login","status":"unresolved","project":{"slug":"auth"},"permalink":"https://acme.sentry.io/issues/77/","metadata":{"type":"KeyError","value":"'user'"}}}}`)
+	}
+
+	alerts, err := adapter.ParsePayload(issue("created"), &database.AlertSourceInstance{})
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("created: %v, %d alerts", err, len(alerts))
+	}
+	if alerts[0].Status != database.AlertStatusFiring || alerts[0].TargetService != "auth" || alerts[0].Summary != "'user'" {
+		t.Errorf("created alert = %+v", alerts[0])
+	}
+
+	alerts, err = adapter.ParsePayload(issue("resolved"), &database.AlertSourceInstance{})
+	if err != nil || len(alerts) != 1 || alerts[0].Status != database.AlertStatusResolved {
+		t.Fatalf("resolved: %v, %+v", err, alerts)
+	}
+	if alerts[0].SourceFingerprint != "77" {
+		t.Errorf("resolved SourceFingerprint = %q, want the issue ID", alerts[0].SourceFingerprint)
+	}
+
+	alerts, err = adapter.ParsePayload(issue("assigned"), &database.AlertSourceInstance{})
+	if err != nil || len(alerts) != 0 {
+		t.Errorf("assigned: want 0 alerts and no error, got %d, %v", len(alerts), err)
+	}
+}
+
+func TestSentryAdapter_ParsePayload_Invalid(t *testing.T) {
+	adapter := NewSentryAdapter()
+	for _, body := range []string{`not json`, `{"foo":"bar"}`, `{"project":"x","event":{}}`} {
+		if _, err := adapter.ParsePayload([]byte(body), &database.AlertSourceInstance{}); err == nil {
+			t.Errorf("ParsePayload(%s) expected error", body)
+		}
+	}
+}
+
+func TestSentryAdapter_ValidateWebhookSecret(t *testing.T) {
+	adapter := NewSentryAdapter()
+	instance := &database.AlertSourceInstance{WebhookSecret: "client-secret"}
+	// Unique per run: an accepted signature is not accepted again.
+	body := fmt.Sprintf(`{"action":"triggered","run":%d}`, time.Now().UnixNano())
+
+	mac := hmac.New(sha256.New, []byte("client-secret"))
+	mac.Write([]byte(body))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest("POST", "/webhook/alert/x", strings.NewReader(body))
+	req.Header.Set("Sentry-Hook-Signature", signature)
+	if err := adapter.ValidateWebhookSecret(req, instance); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	// The body must still be readable by the handler.
+	if got, _ := io.ReadAll(req.Body); string(got) != body {
+		t.Errorf("body after validation = %q", got)
+	}
+
+	req = httptest.NewRequest("POST", "/webhook/alert/x", strings.NewReader(body))
+	req.Header.Set("Sentry-Hook-Signature", strings.Repeat("0", 64))
+	if err := adapter.ValidateWebhookSecret(req, instance); err == nil {
+		t.Error("expected bad signature to fail")
+	}
+
+	req = httptest.NewRequest("POST", "/webhook/alert/x?token=client-secret", strings.NewReader(body))
+	if err := adapter.ValidateWebhookSecret(req, instance); err != nil {
+		t.Errorf("token query rejected: %v", err)
+	}
+
+	req = httptest.NewRequest("POST", "/webhook/alert/x?token=wrong", strings.NewReader(body))
+	if err := adapter.ValidateWebhookSecret(req, instance); err == nil {
+		t.Error("expected wrong token to fail")
+	}
+
+	req = httptest.NewRequest("POST", "/webhook/alert/x", strings.NewReader(body))
+	if err := adapter.ValidateWebhookSecret(req, &database.AlertSourceInstance{}); err != nil {
+		t.Errorf("no secret configured should allow: %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// VictorOpsAdapter handles Splunk On-Call (formerly VictorOps) outgoing
+// webhooks.
+//
+// Splunk On-Call webhook bodies are operator-defined templates, so the
+// adapter accepts the shapes produced by the common templates: alert fields
+// (${{ALERT.*}}) either at the top level or nested under "ALERT", plus an
+// optional "INCIDENT" object (${{INCIDENT.*}}). Keys are matched
+// case-insensitively. Any extra string fields on the alert object (custom
+// fields such as "team") become labels.
+type VictorOpsAdapter struct {
+	alerts.BaseAdapter
+}
+
+// NewVictorOpsAdapter creates a new Splunk On-Call adapter
+func NewVictorOpsAdapter() *VictorOpsAdapter {
+	return &VictorOpsAdapter{
+		BaseAdapter: alerts.BaseAdapter{SourceType: "victorops"},
+	}
+}
+
+// victorOpsReservedFields are alert fields mapped onto dedicated
+// NormalizedAlert fields rather than copied into labels.
+var victorOpsReservedFields = map[string]bool{
+	"message_type":        true,
+	"entity_display_name": true,
+	"state_message":       true,
+	"state_start_time":    true,
+	"timestamp":           true,
+	"host_name":           true,
+	"runbook_url":         true,
+	"incident":            true,
+}
+
+// ValidateWebhookSecret validates the shared secret sent as a custom header
+// on the outgoing webhook.
+func (a *VictorOpsAdapter) ValidateWebhookSecret(r *http.Request, instance *database.AlertSourceInstance) error {
+	if instance.WebhookSecret == "" {
+		return nil // No secret configured, allow request
+	}
+
+	return alerts.VerifyToken(r, instance.WebhookSecret, "X-VictorOps-Secret", "Authorization")
+}
+
+// ParsePayload parses a Splunk On-Call webhook into normalized alerts.
+// Acknowledgements carry no new information for an investigation and are
+// dropped (zero alerts, no error).
+func (a *VictorOpsAdapter) ParsePayload(body []byte, instance *database.AlertSourceInstance) ([]alerts.NormalizedAlert, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse victorops payload: %w", err)
+	}
+
+	mappings := alerts.MergeMappings(a.GetDefaultMappings(), instance.FieldMappings)
+
+	alertMap := victorOpsAlertMap(raw)
+	if alertMap["message_type"] == nil && alertMap["entity_id"] == nil && alertMap["incident"] == nil {
+		return nil, fmt.Errorf("failed to parse victorops payload: no ALERT or INCIDENT fields")
+	}
+
+	n, ok := a.parseAlert(alertMap, raw, mappings)
+	if !ok {
+		return nil, nil
+	}
+	return []alerts.NormalizedAlert{n}, nil
+}
+
+// victorOpsAlertMap flattens the payload into one lowercase-keyed map: the
+// alert object (nested "ALERT" or the top level) with the incident object
+// under "incident".
+func victorOpsAlertMap(raw map[string]interface{}) map[string]interface{} {
+	src := raw
+	var incident map[string]interface{}
+	for k, v := range raw {
+		nested, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(k) {
+		case "alert":
+			src = nested
+		case "incident":
+			incident = nested
+		}
+	}
+
+	out := lowerKeys(src)
+	delete(out, "alert")
+	if incident != nil {
+		out["incident"] = lowerKeys(incident)
+	}
+	return out
+}
+
+func lowerKeys(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
+
+func (a *VictorOpsAdapter) parseAlert(alertMap, raw map[string]interface{}, mappings database.JSONB) (alerts.NormalizedAlert, bool) {
+	messageType := strings.ToUpper(alerts.ExtractString(alertMap, "message_type"))
+	phase := strings.ToUpper(alerts.ExtractString(alertMap, "incident.current_phase"))
+
+	if messageType == "ACKNOWLEDGEMENT" || (messageType == "" && phase == "ACKED") {
+		return alerts.NormalizedAlert{}, false
+	}
+
+	status := database.AlertStatusFiring
+	if messageType == "RECOVERY" || messageType == "OK" || phase == "RESOLVED" {
+		status = database.AlertStatusResolved
+	}
+
+	entityID := alerts.ExtractString(alertMap, "entity_id")
+	if entityID == "" {
+		entityID = alerts.ExtractString(alertMap, "incident.entity_id")
+	}
+
+	alertName := alerts.ExtractString(alertMap, getMapping(mappings, "alert_name"))
+	if alertName == "" {
+		alertName = entityID
+	}
+	if alertName == "" {
+		alertName = alerts.ExtractString(alertMap, "incident.incident_name")
+	}
+
+	summary := alerts.ExtractString(alertMap, getMapping(mappings, "summary"))
+	if summary == "" {
+		summary = alertName
+	}
+	description := alerts.ExtractString(alertMap, getMapping(mappings, "description"))
+	if description == "" {
+		description = summary
+	}
+
+	sourceID := alerts.ExtractString(alertMap, getMapping(mappings, "source_alert_id"))
+	if sourceID == "" {
+		sourceID = entityID
+	}
+
+	labels := make(map[string]string)
+	for k, v := range alertMap {
+		if victorOpsReservedFields[k] {
+			continue
+		}
+		if s, ok := v.(string); ok && s != "" {
+			labels[k] = s
+		}
+	}
+
+	var startedAt, endedAt *time.Time
+	if t, ok := victorOpsTime(alertMap["state_start_time"]); ok {
+		startedAt = &t
+	}
+	if status == database.AlertStatusResolved {
+		if t, ok := victorOpsTime(alertMap["timestamp"]); ok {
+			endedAt = &t
+		}
+	}
+
+	return alerts.NormalizedAlert{
+		AlertName:         alertName,
+		Severity:          a.mapMessageTypeToSeverity(messageType, status),
+		Status:            status,
+		Summary:           summary,
+		Description:       description,
+		TargetHost:        alerts.ExtractString(alertMap, getMapping(mappings, "target_host")),
+		TargetService:     alerts.ExtractString(alertMap, getMapping(mappings, "target_service")),
+		TargetLabels:      labels,
+		RunbookURL:        alerts.ExtractString(alertMap, getMapping(mappings, "runbook_url")),
+		StartedAt:         startedAt,
+		EndedAt:           endedAt,
+		SourceAlertID:     sourceID,
+		SourceFingerprint: entityID,
+		RawPayload:        raw,
+	}, true
+}
+
+// mapMessageTypeToSeverity maps the Splunk On-Call message_type to a
+// normalized severity. Recoveries carry no severity of their own.
+func (a *VictorOpsAdapter) mapMessageTypeToSeverity(messageType string, status database.AlertStatus) database.AlertSeverity {
+	switch messageType {
+	case "CRITICAL":
+		return database.AlertSeverityCritical
+	case "WARNING":
+		return database.AlertSeverityWarning
+	case "INFO":
+		return database.AlertSeverityInfo
+	}
+	if status == database.AlertStatusResolved {
+		return database.AlertSeverityInfo
+	}
+	return database.AlertSeverityWarning
+}
+
+// victorOpsTime parses a Unix timestamp (seconds or milliseconds, as a
+// number or string) or an RFC 3339 string.
+func victorOpsTime(v interface{}) (time.Time, bool) {
+	var n int64
+	switch val := v.(type) {
+	case float64:
+		n = int64(val)
+	case string:
+		if val == "" {
+			return time.Time{}, false
+		}
+		if t, err := time.Parse(time.RFC3339, val); err == nil {
+			return t, true
+		}
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		n = parsed
+	default:
+		return time.Time{}, false
+	}
+	if n <= 0 {
+		return time.Time{}, false
+	}
+	if n > 1e12 {
+		return time.UnixMilli(n).UTC(), true
+	}
+	return time.Unix(n, 0).UTC(), true
+}
+
+// GetDefaultMappings returns the default field mappings for Splunk On-Call.
+// Paths are relative to the lowercased alert object; incident fields live
+// under "incident.".
+func (a *VictorOpsAdapter) GetDefaultMappings() database.JSONB {
+	return database.JSONB{
+		"alert_name":      "entity_display_name",
+		"severity":        "message_type",
+		"status":          "message_type",
+		"summary":         "state_message",
+		"target_host":     "host_name",
+		"target_service":  "service",
+		"runbook_url":     "runbook_url",
+		"source_alert_id": "incident.incident_id",
+	}
+}
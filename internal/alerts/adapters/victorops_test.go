@@ -0,0 +1,237 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func TestNewVictorOpsAdapter(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+	if adapter == nil {
+		t.Fatal("Expected adapter to not be nil")
+	}
+	if adapter.GetSourceType() != "victorops" {
+		t.Errorf("Expected source type 'victorops', got '%s'", adapter.GetSourceType())
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_NestedAlert(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+	instance := &database.AlertSourceInstance{}
+
+	payload := []byte(`{
+		"ALERT": {
+			"message_type": "CRITICAL",
+			"entity_id": "disk/db-01",
+			"entity_display_name": "Disk almost full on db-01",
+			"state_message": "Disk usage at 97% on /var/lib/postgresql",
+			"state_start_time": 1705315800,
+			"host_name": "db-01",
+			"service": "postgres",
+			"monitoring_tool": "nagios",
+			"routing_key": "database",
+			"runbook_url": "https://runbooks.example.com/disk",
+			"team": "storage"
+		},
+		"INCIDENT": {
+			"INCIDENT_ID": "4821",
+			"CURRENT_PHASE": "UNACKED"
+		}
+	}`)
+
+	alerts, err := adapter.ParsePayload(payload, instance)
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+
+	if alert.AlertName != "Disk almost full on db-01" {
+		t.Errorf("AlertName = %q", alert.AlertName)
+	}
+	if alert.Severity != database.AlertSeverityCritical {
+		t.Errorf("Severity = %q, want critical", alert.Severity)
+	}
+	if alert.Status != database.AlertStatusFiring {
+		t.Errorf("Status = %q, want firing", alert.Status)
+	}
+	if alert.Summary != "Disk usage at 97% on /var/lib/postgresql" {
+		t.Errorf("Summary = %q", alert.Summary)
+	}
+	if alert.TargetHost != "db-01" || alert.TargetService != "postgres" {
+		t.Errorf("target = %q / %q", alert.TargetHost, alert.TargetService)
+	}
+	if alert.RunbookURL != "https://runbooks.example.com/disk" {
+		t.Errorf("RunbookURL = %q", alert.RunbookURL)
+	}
+	if alert.SourceAlertID != "4821" {
+		t.Errorf("SourceAlertID = %q, want incident ID 4821", alert.SourceAlertID)
+	}
+	if alert.SourceFingerprint != "disk/db-01" {
+		t.Errorf("SourceFingerprint = %q, want entity_id", alert.SourceFingerprint)
+	}
+	if alert.StartedAt == nil || !alert.StartedAt.Equal(time.Unix(1705315800, 0)) {
+		t.Errorf("StartedAt = %v", alert.StartedAt)
+	}
+	for k, want := range map[string]string{"team": "storage", "routing_key": "database", "monitoring_tool": "nagios", "entity_id": "disk/db-01"} {
+		if alert.TargetLabels[k] != want {
+			t.Errorf("label %s = %q, want %q", k, alert.TargetLabels[k], want)
+		}
+	}
+	if _, ok := alert.TargetLabels["message_type"]; ok {
+		t.Error("message_type should not be copied into labels")
+	}
+	if alert.RawPayload["INCIDENT"] == nil {
+		t.Error("RawPayload should keep the original payload")
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_FlatRecovery(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+	payload := []byte(`{
+		"message_type": "RECOVERY",
+		"entity_id": "cpu/web-01",
+		"state_message": "CPU back to normal",
+		"timestamp": "1705316400000"
+	}`)
+
+	alerts, err := adapter.ParsePayload(payload, &database.AlertSourceInstance{})
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Status != database.AlertStatusResolved {
+		t.Errorf("Status = %q, want resolved", alert.Status)
+	}
+	if alert.Severity != database.AlertSeverityInfo {
+		t.Errorf("Severity = %q, want info", alert.Severity)
+	}
+	if alert.AlertName != "cpu/web-01" {
+		t.Errorf("AlertName = %q, want entity_id fallback", alert.AlertName)
+	}
+	if alert.EndedAt == nil || !alert.EndedAt.Equal(time.UnixMilli(1705316400000)) {
+		t.Errorf("EndedAt = %v", alert.EndedAt)
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_MessageTypeMapping(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+
+	tests := []struct {
+		messageType  string
+		phase        string
+		wantSeverity database.AlertSeverity
+		wantStatus   database.AlertStatus
+	}{
+		{"CRITICAL", "", database.AlertSeverityCritical, database.AlertStatusFiring},
+		{"warning", "", database.AlertSeverityWarning, database.AlertStatusFiring},
+		{"INFO", "", database.AlertSeverityInfo, database.AlertStatusFiring},
+		{"OK", "", database.AlertSeverityInfo, database.AlertStatusResolved},
+		{"", "RESOLVED", database.AlertSeverityInfo, database.AlertStatusResolved},
+		{"CUSTOM", "", database.AlertSeverityWarning, database.AlertStatusFiring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.messageType+tt.phase, func(t *testing.T) {
+			payload := []byte(`{"ALERT":{"message_type":"` + tt.messageType + `","entity_id":"e1"},"INCIDENT":{"CURRENT_PHASE":"` + tt.phase + `"}}`)
+			alerts, err := adapter.ParsePayload(payload, &database.AlertSourceInstance{})
+			if err != nil {
+				t.Fatalf("ParsePayload returned error: %v", err)
+			}
+			if len(alerts) != 1 {
+				t.Fatalf("Expected 1 alert, got %d", len(alerts))
+			}
+			if alerts[0].Severity != tt.wantSeverity || alerts[0].Status != tt.wantStatus {
+				t.Errorf("got %s/%s, want %s/%s", alerts[0].Severity, alerts[0].Status, tt.wantSeverity, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_AcknowledgementIgnored(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+
+	for _, payload := range []string{
+		`{"message_type":"ACKNOWLEDGEMENT","entity_id":"e1"}`,
+		`{"INCIDENT":{"CURRENT_PHASE":"ACKED","ENTITY_ID":"e1"}}`,
+	} {
+		alerts, err := adapter.ParsePayload([]byte(payload), &database.AlertSourceInstance{})
+		if err != nil {
+			t.Fatalf("ParsePayload(%s) returned error: %v", payload, err)
+		}
+		if len(alerts) != 0 {
+			t.Errorf("ParsePayload(%s) = %d alerts, want 0", payload, len(alerts))
+		}
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_FieldMappingOverride(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+	instance := &database.AlertSourceInstance{
+		FieldMappings: database.JSONB{"target_service": "routing_key"},
+	}
+	alerts, err := adapter.ParsePayload([]byte(`{"message_type":"CRITICAL","entity_id":"e1","routing_key":"payments"}`), instance)
+	if err != nil {
+		t.Fatalf("ParsePayload returned error: %v", err)
+	}
+	if alerts[0].TargetService != "payments" {
+		t.Errorf("TargetService = %q, want payments", alerts[0].TargetService)
+	}
+}
+
+func TestVictorOpsAdapter_ParsePayload_Invalid(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+	for _, payload := range []string{`not json`, `{"foo":"bar"}`} {
+		if _, err := adapter.ParsePayload([]byte(payload), &database.AlertSourceInstance{}); err == nil {
+			t.Errorf("ParsePayload(%s) expected error", payload)
+		}
+	}
+}
+
+func TestVictorOpsAdapter_ValidateWebhookSecret(t *testing.T) {
+	adapter := NewVictorOpsAdapter()
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		value   string
+		wantErr bool
+	}{
+		{"no secret configured", "", "", "", false},
+		{"custom header", "s3cret", "X-VictorOps-Secret", "s3cret", false},
+		{"bearer token", "s3cret", "Authorization", "Bearer s3cret", false},
+		{"wrong secret", "s3cret", "X-VictorOps-Secret", "nope", true},
+		{"missing header", "s3cret", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			err := adapter.ValidateWebhookSecret(req, &database.AlertSourceInstance{WebhookSecret: tt.secret})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVictorOpsAdapter_GetDefaultMappings(t *testing.T) {
+	mappings := NewVictorOpsAdapter().GetDefaultMappings()
+	for _, key := range []string{"alert_name", "summary", "target_host", "source_alert_id"} {
+		if _, ok := mappings[key]; !ok {
+			t.Errorf("missing default mapping %q", key)
+		}
+	}
+}
@@ -44,12 +44,7 @@ func (a *ZabbixAdapter) ValidateWebhookSecret(r *http.Request, instance *databas
 		return nil // No secret configured, allow request
 	}
 
-	secret := r.Header.Get("X-Zabbix-Secret")
-	if secret != instance.WebhookSecret {
-		return fmt.Errorf("invalid webhook secret")
-	}
-
-	return nil
+	return alerts.VerifyToken(r, instance.WebhookSecret, "X-Zabbix-Secret")
 }
 
 // ParsePayload parses Zabbix webhook payload into normalized alerts
@@ -102,7 +97,7 @@ func (a *ZabbixAdapter) parseAlert(payload ZabbixPayload, rawFields map[string]i
 	if severityText == "" {
 		severityText = payload.Priority
 	}
-	severity := alerts.NormalizeSeverity(severityText, alerts.DefaultSeverityMapping)
+	severity, severityKnown := alerts.ParseSeverity(severityText, alerts.DefaultSeverityMapping)
 
 	statusText := alerts.ExtractString(payloadMap, getMapping(mappings, "status"))
 	if statusText == "" {
@@ -164,6 +159,7 @@ func (a *ZabbixAdapter) parseAlert(payload ZabbixPayload, rawFields map[string]i
 	return alerts.NormalizedAlert{
 		AlertName:         alertName,
 		Severity:          severity,
+		SeverityMissing:   !severityKnown,
 		Status:            status,
 		Summary:           summary,
 		Description:       fmt.Sprintf("Metric: %s = %s\nTrigger: %s", metricName, metricValue, summary),
@@ -161,7 +161,7 @@ func (e *AlertExtractor) toNormalizedAlert(extracted ExtractedAlert, originalMes
 		alertName = "Slack Alert"
 	}
 
-	severity := alerts.NormalizeSeverity(extracted.Severity, alerts.DefaultSeverityMapping)
+	severity, severityKnown := alerts.ParseSeverity(extracted.Severity, alerts.DefaultSeverityMapping)
 
 	status := database.AlertStatusFiring
 	if strings.ToLower(extracted.Status) == "resolved" {
@@ -179,13 +179,14 @@ func (e *AlertExtractor) toNormalizedAlert(extracted ExtractedAlert, originalMes
 	}
 
 	return &alerts.NormalizedAlert{
-		AlertName:     alertName,
-		Severity:      severity,
-		Status:        status,
-		Summary:       summary,
-		Description:   description,
-		TargetHost:    extracted.TargetHost,
-		TargetService: extracted.TargetService,
+		AlertName:       alertName,
+		Severity:        severity,
+		SeverityMissing: !severityKnown,
+		Status:          status,
+		Summary:         summary,
+		Description:     description,
+		TargetHost:      extracted.TargetHost,
+		TargetService:   extracted.TargetService,
 		TargetLabels: map[string]string{
 			"source_system": extracted.SourceSystem,
 		},
@@ -212,11 +213,12 @@ func (e *AlertExtractor) createFallbackAlert(messageText string) *alerts.Normali
 	}
 
 	return &alerts.NormalizedAlert{
-		AlertName:   alertName,
-		Summary:     truncateMessage(messageText, 100),
-		Description: messageText,
-		Severity:    database.AlertSeverityWarning,
-		Status:      database.AlertStatusFiring,
+		AlertName:       alertName,
+		Summary:         truncateMessage(messageText, 100),
+		Description:     messageText,
+		Severity:        database.AlertSeverityWarning,
+		SeverityMissing: true,
+		Status:          database.AlertStatusFiring,
 		RawPayload: map[string]interface{}{
 			"original_message": messageText,
 			"extraction_mode":  "fallback",
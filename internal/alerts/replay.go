@@ -0,0 +1,76 @@
+package alerts
+
+import (
+	"sync"
+	"time"
+)
+
+// unsignedReplayWindow is how long a body-only signature is remembered.
+// Such a signature stays valid forever, so this only narrows the replay
+// window to requests captured more than a day ago.
+const unsignedReplayWindow = 24 * time.Hour
+
+// maxReplayEntries caps the replay cache. Only requests that passed
+// verification are recorded, so reaching it takes a busy sender; the oldest
+// entries are dropped first.
+const maxReplayEntries = 100_000
+
+// seenDeliveries remembers the webhook deliveries accepted by this process.
+// Replicas keep their own, so a replay sent to another replica inside the
+// window is still caught only by the timestamp check.
+var seenDeliveries = newReplayCache(maxReplayEntries)
+
+type replayEntry struct {
+	key     string
+	expires time.Time
+}
+
+// replayCache is a bounded set of keys that expire.
+type replayCache struct {
+	mu      sync.Mutex
+	max     int
+	expires map[string]time.Time
+	// order holds the entries in insertion order, for eviction. An entry
+	// whose key was claimed again later is stale and skipped.
+	order []replayEntry
+}
+
+func newReplayCache(max int) *replayCache {
+	return &replayCache{max: max, expires: make(map[string]time.Time)}
+}
+
+// claim records keys until now+ttl. It returns false, recording nothing,
+// when any of them is already recorded and unexpired.
+func (c *replayCache) claim(keys []string, now time.Time, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if expires, ok := c.expires[key]; ok && now.Before(expires) {
+			return false
+		}
+	}
+	for _, key := range keys {
+		c.expires[key] = now.Add(ttl)
+		c.order = append(c.order, replayEntry{key: key, expires: now.Add(ttl)})
+	}
+	c.prune(now)
+	return true
+}
+
+// prune drops expired entries from the front of the insertion order, and
+// the oldest entries while the cache is over its cap.
+func (c *replayCache) prune(now time.Time) {
+	for len(c.order) > 0 {
+		oldest := c.order[0]
+		current, ok := c.expires[oldest.key]
+		stale := !ok || !current.Equal(oldest.expires)
+		if !stale && now.Before(oldest.expires) && len(c.expires) <= c.max {
+			return
+		}
+		if !stale {
+			delete(c.expires, oldest.key)
+		}
+		c.order = c.order[1:]
+	}
+}
@@ -0,0 +1,220 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxWebhookBodySize is the webhook handler's body limit. Signature checks
+// read at most this much of the body before the handler does.
+const MaxWebhookBodySize = 10 * 1024 * 1024
+
+// DefaultTimestampTolerance is how far a signed timestamp may be from the
+// current time before a webhook is rejected as a replay.
+const DefaultTimestampTolerance = 5 * time.Minute
+
+var (
+	// ErrMissingCredentials is returned when a webhook carries no token or
+	// signature.
+	ErrMissingCredentials = errors.New("missing webhook secret or signature")
+	// ErrInvalidSignature is returned when no signature matches the body.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidToken is returned when a static token does not match.
+	ErrInvalidToken = errors.New("invalid webhook secret")
+	// ErrStaleTimestamp is returned when a signed timestamp is outside the
+	// tolerance, which is how replayed requests are caught.
+	ErrStaleTimestamp = errors.New("webhook timestamp outside tolerance")
+	// ErrMissingTimestamp is returned when a scheme requires a signed
+	// timestamp and the request has none.
+	ErrMissingTimestamp = errors.New("missing webhook timestamp")
+	// ErrReplayedRequest is returned for a signed request, or a delivery ID,
+	// that was already accepted.
+	ErrReplayedRequest = errors.New("webhook request already received")
+)
+
+// HMACScheme describes how a sender signs its webhooks with HMAC-SHA256.
+type HMACScheme struct {
+	// Header carries the hex signature.
+	Header string
+	// Prefix precedes each signature in the header (e.g. "v1=", "sha256=").
+	// A header may hold several comma-separated signatures, as PagerDuty
+	// sends during secret rotation; any match is accepted.
+	Prefix string
+	// TimestampHeader, when set, carries a Unix timestamp that the sender
+	// signs as <timestamp><TimestampSeparator><body>. Requests without it
+	// are rejected unless TimestampOptional is set, in which case they are
+	// verified over the body alone.
+	TimestampHeader    string
+	TimestampSeparator string
+	TimestampOptional  bool
+	// DeliveryIDHeader, when set, carries the sender's unique delivery ID.
+	// An ID already accepted is rejected as a replay.
+	DeliveryIDHeader string
+}
+
+// HMACSchemes are the signing schemes of the supported senders, by name.
+var HMACSchemes = map[string]HMACScheme{
+	// Grafana webhook contact point with an HMAC secret (and an optional
+	// timestamp header).
+	"grafana": {
+		Header:             "X-Grafana-Alerting-Signature",
+		TimestampHeader:    "X-Grafana-Alerting-Signature-Timestamp",
+		TimestampSeparator: ":",
+	},
+	// PagerDuty v3 webhook subscriptions.
+	"pagerduty": {Header: "X-PagerDuty-Signature", Prefix: "v1=", DeliveryIDHeader: "X-Webhook-Id"},
+	// Sentry integration platform.
+	"sentry": {Header: "Sentry-Hook-Signature", DeliveryIDHeader: "Request-ID"},
+	// Any other sender that can sign the body.
+	"generic": {
+		Header:             "X-Webhook-Signature",
+		Prefix:             "sha256=",
+		TimestampHeader:    "X-Webhook-Timestamp",
+		TimestampSeparator: ".",
+		DeliveryIDHeader:   "X-Webhook-Id",
+	},
+}
+
+// VerifyHMAC checks the request's HMAC-SHA256 signature under scheme. When
+// the request carries a signed timestamp, it must be within tolerance of now
+// (DefaultTimestampTolerance when zero). A signature or delivery ID seen
+// before is rejected as a replay. The body is left readable for the handler.
+func VerifyHMAC(r *http.Request, secret string, scheme HMACScheme, tolerance time.Duration, now time.Time) error {
+	header := r.Header.Get(scheme.Header)
+	if header == "" {
+		return ErrMissingCredentials
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTimestampTolerance
+	}
+
+	var timestamp string
+	if scheme.TimestampHeader != "" {
+		timestamp = strings.TrimSpace(r.Header.Get(scheme.TimestampHeader))
+		if timestamp == "" && !scheme.TimestampOptional {
+			return ErrMissingTimestamp
+		}
+	}
+	if timestamp != "" {
+		sent, err := parseWebhookTimestamp(timestamp)
+		if err != nil {
+			return err
+		}
+		if d := now.Sub(sent); d > tolerance || d < -tolerance {
+			return ErrStaleTimestamp
+		}
+	}
+
+	body, err := PeekBody(r)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	if timestamp != "" {
+		mac.Write([]byte(timestamp + scheme.TimestampSeparator))
+	}
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, sig := range strings.Split(header, ",") {
+		sig = strings.TrimSpace(sig)
+		if scheme.Prefix != "" {
+			if !strings.HasPrefix(sig, scheme.Prefix) {
+				continue
+			}
+			sig = strings.TrimPrefix(sig, scheme.Prefix)
+		}
+		got, err := hex.DecodeString(sig)
+		if err == nil && hmac.Equal(got, expected) {
+			return claimDelivery(r, secret, scheme, expected, timestamp != "", tolerance, now)
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// claimDelivery records an accepted request's signature and delivery ID,
+// failing if either was accepted before. A signed timestamp bounds how long
+// the signature is valid, so it is remembered for the two tolerances around
+// now; a body-only signature never expires and is remembered for
+// unsignedReplayWindow.
+func claimDelivery(r *http.Request, secret string, scheme HMACScheme, signature []byte, timestamped bool, tolerance time.Duration, now time.Time) error {
+	keys := []string{"sig:" + hex.EncodeToString(signature)}
+	if scheme.DeliveryIDHeader != "" {
+		if id := strings.TrimSpace(r.Header.Get(scheme.DeliveryIDHeader)); id != "" {
+			// Keyed by the secret so senders sharing an ID space do not
+			// collide across sources.
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte(id))
+			keys = append(keys, "id:"+hex.EncodeToString(mac.Sum(nil)))
+		}
+	}
+	ttl := unsignedReplayWindow
+	if timestamped {
+		ttl = 2 * tolerance
+	}
+	if !seenDeliveries.claim(keys, now, ttl) {
+		return ErrReplayedRequest
+	}
+	return nil
+}
+
+// parseWebhookTimestamp accepts Unix seconds, Unix milliseconds or RFC 3339.
+func parseWebhookTimestamp(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid webhook timestamp %q", s)
+}
+
+// VerifyToken compares a static token against secret in constant time. The
+// token is taken from the first of headers that is set, with an optional
+// "Bearer " prefix.
+func VerifyToken(r *http.Request, secret string, headers ...string) error {
+	var token string
+	for _, h := range headers {
+		if token = r.Header.Get(h); token != "" {
+			break
+		}
+	}
+	if token == "" {
+		return ErrMissingCredentials
+	}
+	token = strings.TrimPrefix(token, "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// PeekBody reads the request body up to MaxWebhookBodySize and puts it back
+// so the handler can read it again.
+func PeekBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxWebhookBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	return body, nil
+}
@@ -0,0 +1,266 @@
+package alerts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret, content string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// freshReplayCache gives the test an empty replay cache, so signatures
+// accepted by other tests (or an earlier -count run) are not replays.
+func freshReplayCache(t *testing.T) {
+	t.Helper()
+	saved := seenDeliveries
+	seenDeliveries = newReplayCache(maxReplayEntries)
+	t.Cleanup(func() { seenDeliveries = saved })
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	freshReplayCache(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := `{"status":"firing"}`
+	grafana := HMACSchemes["grafana"]
+	generic := HMACSchemes["generic"]
+	bodyOnly := grafana
+	bodyOnly.TimestampOptional = true
+
+	tests := []struct {
+		name    string
+		scheme  HMACScheme
+		headers map[string]string
+		body    string
+		wantErr error
+	}{
+		{
+			name:    "body only",
+			scheme:  bodyOnly,
+			headers: map[string]string{grafana.Header: sign("s3cret", body)},
+		},
+		{
+			name:   "signed timestamp",
+			scheme: grafana,
+			headers: map[string]string{
+				grafana.Header:          sign("s3cret", ts+":"+body),
+				grafana.TimestampHeader: ts,
+			},
+		},
+		{
+			name:   "prefix",
+			scheme: generic,
+			headers: map[string]string{
+				generic.Header:          "sha256=" + sign("s3cret", ts+"."+body),
+				generic.TimestampHeader: ts,
+			},
+		},
+		{
+			name:   "missing prefix",
+			scheme: generic,
+			headers: map[string]string{
+				generic.Header:          sign("s3cret", ts+"."+body),
+				generic.TimestampHeader: ts,
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "wrong secret",
+			scheme:  bodyOnly,
+			headers: map[string]string{grafana.Header: sign("other", body)},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "tampered body",
+			scheme:  bodyOnly,
+			headers: map[string]string{grafana.Header: sign("s3cret", body)},
+			body:    `{"status":"resolved"}`,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:   "replayed timestamp",
+			scheme: grafana,
+			headers: map[string]string{
+				grafana.Header:          sign("s3cret", "1700000000:"+body),
+				grafana.TimestampHeader: "1700000000",
+			},
+			wantErr: ErrStaleTimestamp,
+		},
+		{
+			name:   "timestamp stripped from a signed request",
+			scheme: grafana,
+			headers: map[string]string{
+				grafana.Header: sign("s3cret", ts+":"+body),
+			},
+			wantErr: ErrMissingTimestamp,
+		},
+		{
+			name:   "timestamp stripped where it is optional",
+			scheme: bodyOnly,
+			headers: map[string]string{
+				grafana.Header: sign("s3cret", ts+":"+body),
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "missing timestamp",
+			scheme:  generic,
+			headers: map[string]string{generic.Header: "sha256=" + sign("s3cret", body)},
+			wantErr: ErrMissingTimestamp,
+		},
+		{
+			name:    "no signature",
+			scheme:  grafana,
+			headers: map[string]string{"Authorization": "Bearer s3cret"},
+			wantErr: ErrMissingCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := body
+			if tt.body != "" {
+				sent = tt.body
+			}
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(sent))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			err := VerifyHMAC(req, "s3cret", tt.scheme, 0, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyHMAC() error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := io.ReadAll(req.Body); string(got) != sent {
+				t.Errorf("body after verification = %q, want %q", got, sent)
+			}
+		})
+	}
+}
+
+func TestVerifyHMAC_Tolerance(t *testing.T) {
+	freshReplayCache(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scheme := HMACSchemes["grafana"]
+	ts := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+		req.Header.Set(scheme.Header, sign("s3cret", ts+":{}"))
+		req.Header.Set(scheme.TimestampHeader, ts)
+		return req
+	}
+	if err := VerifyHMAC(newReq(), "s3cret", scheme, 0, now); !errors.Is(err, ErrStaleTimestamp) {
+		t.Errorf("default tolerance: error = %v, want ErrStaleTimestamp", err)
+	}
+	if err := VerifyHMAC(newReq(), "s3cret", scheme, 15*time.Minute, now); err != nil {
+		t.Errorf("15m tolerance: error = %v", err)
+	}
+}
+
+func TestVerifyHMAC_RejectsReplays(t *testing.T) {
+	freshReplayCache(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	generic := HMACSchemes["generic"]
+	sentry := HMACSchemes["sentry"]
+
+	signed := func(body, deliveryID string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set(generic.Header, "sha256="+sign("s3cret", ts+"."+body))
+		req.Header.Set(generic.TimestampHeader, ts)
+		if deliveryID != "" {
+			req.Header.Set(generic.DeliveryIDHeader, deliveryID)
+		}
+		return req
+	}
+	if err := VerifyHMAC(signed(`{"n":1}`, "d-1"), "s3cret", generic, 0, now); err != nil {
+		t.Fatalf("first delivery: %v", err)
+	}
+	// The same signed request, inside the tolerance window.
+	if err := VerifyHMAC(signed(`{"n":1}`, ""), "s3cret", generic, 0, now.Add(time.Minute)); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("replayed signature: error = %v, want ErrReplayedRequest", err)
+	}
+	// A new body under a delivery ID already accepted.
+	if err := VerifyHMAC(signed(`{"n":2}`, "d-1"), "s3cret", generic, 0, now); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("replayed delivery ID: error = %v, want ErrReplayedRequest", err)
+	}
+	// The rejected request claimed nothing, so its signature is still fresh.
+	if err := VerifyHMAC(signed(`{"n":2}`, "d-2"), "s3cret", generic, 0, now); err != nil {
+		t.Errorf("new delivery: %v", err)
+	}
+
+	// Body-only signatures never expire, so they are remembered for a day.
+	body := `{"action":"created"}`
+	bodyOnly := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set(sentry.Header, sign("s3cret", body))
+		return req
+	}
+	if err := VerifyHMAC(bodyOnly(), "s3cret", sentry, 0, now); err != nil {
+		t.Fatalf("body-only delivery: %v", err)
+	}
+	if err := VerifyHMAC(bodyOnly(), "s3cret", sentry, 0, now.Add(time.Hour)); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("replayed body-only signature: error = %v, want ErrReplayedRequest", err)
+	}
+}
+
+func TestReplayCache_Bounded(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newReplayCache(3)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		if !cache.claim([]string{key}, now.Add(time.Duration(i)*time.Second), time.Hour) {
+			t.Fatalf("claim %s refused", key)
+		}
+	}
+	if len(cache.expires) != 3 {
+		t.Errorf("entries = %d, want the cap of 3", len(cache.expires))
+	}
+	if !cache.claim([]string{"a"}, now.Add(5*time.Second), time.Hour) {
+		t.Error("the oldest key should have been evicted")
+	}
+	if cache.claim([]string{"d"}, now.Add(5*time.Second), time.Hour) {
+		t.Error("a recent key should still be remembered")
+	}
+	// Expired keys can be claimed again and are pruned.
+	if !cache.claim([]string{"d"}, now.Add(2*time.Hour), time.Hour) {
+		t.Error("an expired key should be claimable")
+	}
+	if len(cache.expires) != 1 {
+		t.Errorf("entries after expiry = %d, want 1", len(cache.expires))
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr error
+	}{
+		{"first header", map[string]string{"X-Secret": "s3cret"}, nil},
+		{"fallback header with bearer", map[string]string{"Authorization": "Bearer s3cret"}, nil},
+		{"first header wins", map[string]string{"X-Secret": "wrong", "Authorization": "Bearer s3cret"}, ErrInvalidToken},
+		{"missing", map[string]string{"X-Other": "s3cret"}, ErrMissingCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if err := VerifyToken(req, "s3cret", "X-Secret", "Authorization"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -18,7 +18,8 @@ type PaginationParams struct {
 }
 
 // ParsePagination extracts pagination parameters from the request.
-// Defaults: page=1, per_page=50. Maximum per_page is 200.
+// Defaults: page=1, per_page=50. Maximum per_page is 200. "limit" is accepted
+// as an alias for per_page; per_page wins when both are present.
 func ParsePagination(r *http.Request) PaginationParams {
 	p := PaginationParams{
 		Page:    defaultPage,
@@ -31,7 +32,11 @@ func ParsePagination(r *http.Request) PaginationParams {
 		}
 	}
 
-	if v := r.URL.Query().Get("per_page"); v != "" {
+	perPage := r.URL.Query().Get("per_page")
+	if perPage == "" {
+		perPage = r.URL.Query().Get("limit")
+	}
+	if v := perPage; v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			p.PerPage = n
 			if p.PerPage > maxPerPage {
@@ -30,6 +30,18 @@ func TestParsePagination_CustomValues(t *testing.T) {
 	}
 }
 
+func TestParsePagination_LimitAlias(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/test?limit=10", nil)
+	if p := ParsePagination(r); p.PerPage != 10 {
+		t.Errorf("per_page = %d, want 10 from limit", p.PerPage)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/test?limit=10&per_page=30", nil)
+	if p := ParsePagination(r); p.PerPage != 30 {
+		t.Errorf("per_page = %d, want 30 (per_page wins over limit)", p.PerPage)
+	}
+}
+
 func TestParsePagination_MaxPerPage(t *testing.T) {
 	r := httptest.NewRequest(http.MethodGet, "/test?per_page=500", nil)
 	p := ParsePagination(r)
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
+	"reflect"
 	"strings"
+	"sync/atomic"
 )
 
 // MaxBodySize is the maximum allowed request body size (1 MB).
@@ -14,6 +18,8 @@ const MaxBodySize = 1 << 20
 
 // DecodeJSON reads and decodes a JSON request body into dst.
 // It returns user-friendly error messages instead of leaking Go internals.
+// When dst points to a struct, the decoded value is then checked against its
+// validate tags and a *ValidationError is returned if any fail.
 func DecodeJSON(r *http.Request, dst interface{}) error {
 	if r.Body == nil {
 		return errors.New("request body is empty")
@@ -27,7 +33,7 @@ func DecodeJSON(r *http.Request, dst interface{}) error {
 
 	err := dec.Decode(dst)
 	if err == nil {
-		return nil
+		return validateBody(dst)
 	}
 
 	// Translate common JSON errors into friendly messages.
@@ -51,3 +57,93 @@ func DecodeJSON(r *http.Request, dst interface{}) error {
 		return errors.New("invalid JSON in request body")
 	}
 }
+
+// validateBody checks dst against its validate tags when it points to a
+// struct. Maps and other bodies are left to the handler.
+func validateBody(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if fields := Validate(dst); fields != nil {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// trustedProxies holds the networks whose forwarded headers ClientIP
+// honours; see SetTrustedProxies.
+var trustedProxies atomic.Pointer[[]netip.Prefix]
+
+// SetTrustedProxies sets the reverse proxies, as CIDRs or single addresses,
+// whose X-Real-IP and X-Forwarded-For headers ClientIP trusts. With none
+// set, ClientIP ignores those headers.
+func SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	trustedProxies.Store(&prefixes)
+	return nil
+}
+
+// isTrustedProxy reports whether ip is one of the trusted proxies.
+func isTrustedProxy(ip string) bool {
+	prefixes := trustedProxies.Load()
+	if prefixes == nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range *prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the originating client address for r. Forwarded headers
+// are only honoured when the peer is a trusted proxy (see
+// SetTrustedProxies); otherwise anyone could pick the address that login
+// throttling, audit entries and source IP allowlists see. Behind a trusted
+// proxy X-Real-IP wins, as the bundled nginx sets it, then the rightmost
+// X-Forwarded-For hop that is not itself a trusted proxy.
+func ClientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	// Proxies append to X-Forwarded-For, so the hops a client made up come
+	// first; walk back from the nearest one.
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop != "" && !isTrustedProxy(hop) {
+			return hop
+		}
+	}
+	return peer
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"testing"
@@ -111,6 +112,83 @@ func TestDecodeJSON_OversizedBody(t *testing.T) {
 	}
 }
 
+func TestDecodeJSON_ValidatesStructs(t *testing.T) {
+	var dst struct {
+		Name string `json:"name" validate:"required"`
+	}
+	err := DecodeJSON(newRequest(`{}`), &dst)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+	if verr.Fields["name"] != "is required" {
+		t.Errorf("fields = %v, want name required", verr.Fields)
+	}
+	if err.Error() != "name is required" {
+		t.Errorf("error = %q, want %q", err.Error(), "name is required")
+	}
+
+	// Bodies decoded into maps are not validated.
+	var m map[string]interface{}
+	if err := DecodeJSON(newRequest(`{}`), &m); err != nil {
+		t.Errorf("map body: unexpected error %v", err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	if err := SetTrustedProxies([]string{"172.16.0.0/12", "10.0.0.9"}); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	t.Cleanup(func() { _ = SetTrustedProxies(nil) })
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "remote addr host", remoteAddr: "10.0.0.5:51234", want: "10.0.0.5"},
+		{name: "remote addr without port", remoteAddr: "10.0.0.5", want: "10.0.0.5"},
+		{name: "x-real-ip wins", remoteAddr: "172.18.0.2:80", headers: map[string]string{"X-Real-IP": "203.0.113.7", "X-Forwarded-For": "198.51.100.1"}, want: "203.0.113.7"},
+		{name: "first forwarded hop", remoteAddr: "172.18.0.2:80", headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 172.18.0.2"}, want: "198.51.100.1"},
+		{name: "spoofed forwarded hop skipped", remoteAddr: "172.18.0.2:80", headers: map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "single trusted address", remoteAddr: "10.0.0.9:80", headers: map[string]string{"X-Real-IP": "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "untrusted peer headers ignored", remoteAddr: "203.0.113.50:4000", headers: map[string]string{"X-Real-IP": "127.0.0.1", "X-Forwarded-For": "127.0.0.1"}, want: "203.0.113.50"},
+		{name: "only trusted hops", remoteAddr: "172.18.0.2:80", headers: map[string]string{"X-Forwarded-For": "172.18.0.3"}, want: "172.18.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP_NoTrustedProxies(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	r.RemoteAddr = "172.18.0.2:80"
+	r.Header.Set("X-Real-IP", "203.0.113.7")
+	if got := ClientIP(r); got != "172.18.0.2" {
+		t.Errorf("ClientIP() = %q, want the peer address", got)
+	}
+}
+
+func TestSetTrustedProxies_Invalid(t *testing.T) {
+	if err := SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+	if err := SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
+
 // newRequest creates an http.Request with the given JSON body.
 func newRequest(body string) *http.Request {
 	r, _ := http.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
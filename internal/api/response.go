@@ -2,17 +2,76 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 )
 
 // ErrorResponse is the standard error envelope returned by all endpoints.
+// Code is machine-readable (see CodeForStatus) and Message is meant for
+// people; Error carries the same text as Message for clients written before
+// Message existed.
 type ErrorResponse struct {
-	Error   string            `json:"error"`
 	Code    string            `json:"code,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Error   string            `json:"error"`
 	Details map[string]string `json:"details,omitempty"`
 }
 
+// Error codes set by RespondError from the response status. Endpoints with a
+// more specific failure (e.g. "setup_required") pass their own code to
+// RespondErrorWithCode.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_error"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeConflict           = "conflict"
+	CodeGone               = "gone"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeUpstream           = "upstream_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// CodeForStatus returns the error code for an HTTP error status: the status
+// class for codes without one of their own.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
 // RespondJSON writes data as a JSON response with the given status code.
 func RespondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -24,25 +83,50 @@ func RespondJSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
-// RespondError writes a standard error response.
+// RespondError writes a standard error response, coded by status.
 func RespondError(w http.ResponseWriter, status int, message string) {
-	RespondJSON(w, status, ErrorResponse{Error: message})
+	RespondErrorWithCode(w, status, CodeForStatus(status), message)
 }
 
 // RespondErrorWithCode writes an error response with a machine-readable code.
 func RespondErrorWithCode(w http.ResponseWriter, status int, code, message string) {
-	RespondJSON(w, status, ErrorResponse{Error: message, Code: code})
+	RespondJSON(w, status, ErrorResponse{Code: code, Message: message, Error: message})
+}
+
+// RespondServiceError answers a failed service call with status. Below 500
+// the error's text is the message, since services word validation failures
+// for the user. From 500 up the error is logged and message is sent instead,
+// so database and filesystem details stay out of responses.
+func RespondServiceError(w http.ResponseWriter, r *http.Request, status int, err error, message string) {
+	if status < http.StatusInternalServerError {
+		RespondError(w, status, err.Error())
+		return
+	}
+	slog.ErrorContext(r.Context(), message, "path", r.URL.Path, "error", err)
+	RespondError(w, status, message)
 }
 
 // RespondValidationError writes field-level validation errors as a 422 response.
 func RespondValidationError(w http.ResponseWriter, fieldErrors map[string]string) {
 	RespondJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+		Code:    CodeValidation,
+		Message: "Validation failed",
 		Error:   "Validation failed",
-		Code:    "validation_error",
 		Details: fieldErrors,
 	})
 }
 
+// RespondDecodeError answers a DecodeJSON failure: 422 with the failing
+// fields for a *ValidationError, 400 with the decode error otherwise.
+func RespondDecodeError(w http.ResponseWriter, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		RespondValidationError(w, verr.Fields)
+		return
+	}
+	RespondError(w, http.StatusBadRequest, err.Error())
+}
+
 // RespondNoContent writes a 204 No Content response with no body.
 func RespondNoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
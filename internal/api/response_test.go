@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -78,8 +79,57 @@ func TestRespondError(t *testing.T) {
 	if resp.Error != "invalid input" {
 		t.Errorf("error = %q, want %q", resp.Error, "invalid input")
 	}
-	if resp.Code != "" {
-		t.Errorf("code = %q, want empty", resp.Code)
+	if resp.Message != "invalid input" {
+		t.Errorf("message = %q, want %q", resp.Message, "invalid input")
+	}
+	if resp.Code != CodeBadRequest {
+		t.Errorf("code = %q, want %q", resp.Code, CodeBadRequest)
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusBadRequest, "bad_request"},
+		{http.StatusUnprocessableEntity, "validation_error"},
+		{http.StatusNotFound, "not_found"},
+		{http.StatusConflict, "conflict"},
+		{http.StatusTeapot, "bad_request"},
+		{http.StatusInternalServerError, "internal_error"},
+		{http.StatusNotImplemented, "internal_error"},
+		{http.StatusBadGateway, "upstream_error"},
+		{http.StatusServiceUnavailable, "service_unavailable"},
+	}
+	for _, tt := range tests {
+		if got := CodeForStatus(tt.status); got != tt.want {
+			t.Errorf("CodeForStatus(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRespondServiceError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/things", nil)
+
+	w := httptest.NewRecorder()
+	RespondServiceError(w, r, http.StatusBadRequest, errors.New("name is required"), "Failed to save thing")
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || resp.Message != "name is required" {
+		t.Errorf("client error: status %d message %q, want 400 with the error text", w.Code, resp.Message)
+	}
+
+	w = httptest.NewRecorder()
+	RespondServiceError(w, r, http.StatusInternalServerError, errors.New("pq: relation \"things\" does not exist"), "Failed to save thing")
+	resp = ErrorResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusInternalServerError || resp.Message != "Failed to save thing" || resp.Code != CodeInternal {
+		t.Errorf("server error: status %d %+v, want 500 with the fallback message", w.Code, resp)
 	}
 }
 
@@ -132,6 +182,27 @@ func TestRespondValidationError(t *testing.T) {
 	}
 }
 
+func TestRespondDecodeError(t *testing.T) {
+	w := httptest.NewRecorder()
+	RespondDecodeError(w, &ValidationError{Fields: map[string]string{"name": "is required"}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("validation error: status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	w = httptest.NewRecorder()
+	RespondDecodeError(w, errors.New("malformed JSON at position 1"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("decode error: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Message != "malformed JSON at position 1" {
+		t.Errorf("message = %q, want the decode error", resp.Message)
+	}
+}
+
 func TestRespondNoContent(t *testing.T) {
 	w := httptest.NewRecorder()
 	RespondNoContent(w)
@@ -31,6 +31,48 @@ type UpdateScriptRequest struct {
 	Content string `json:"content"`
 }
 
+// SkillTestRequest is the request body for POST /api/skills/:name/test.
+// TimeoutSeconds defaults to 300.
+type SkillTestRequest struct {
+	Alert          SkillTestAlert `json:"alert"`
+	TimeoutSeconds int            `json:"timeout_seconds"`
+}
+
+// SkillTestAlert is the synthetic alert a skill dry run investigates. Only
+// AlertName is required; Severity defaults to warning.
+type SkillTestAlert struct {
+	AlertName     string `json:"alert_name" validate:"notblank"`
+	Severity      string `json:"severity" validate:"omitempty,severity"`
+	TargetHost    string `json:"target_host"`
+	TargetService string `json:"target_service"`
+	Summary       string `json:"summary"`
+	Description   string `json:"description"`
+	MetricName    string `json:"metric_name"`
+	MetricValue   string `json:"metric_value"`
+	RunbookURL    string `json:"runbook_url"`
+}
+
+// SkillTestToolCall is one tool execution of a skill dry run.
+type SkillTestToolCall struct {
+	Command string `json:"command"`
+	Failed  bool   `json:"failed"`
+}
+
+// SkillTestResponse is the response body for POST /api/skills/:name/test.
+// Status is completed, failed or timed_out; Log is the execution log the
+// agent streamed, which is partial for a run that timed out.
+type SkillTestResponse struct {
+	RunID           string              `json:"run_id"`
+	Skill           string              `json:"skill"`
+	Status          string              `json:"status"`
+	Output          string              `json:"output"`
+	Error           string              `json:"error,omitempty"`
+	ToolCalls       []SkillTestToolCall `json:"tool_calls"`
+	Log             string              `json:"log"`
+	TokensUsed      int                 `json:"tokens_used"`
+	ExecutionTimeMs int64               `json:"execution_time_ms"`
+}
+
 // SkillResponse is a skill with its prompt included.
 type SkillResponse struct {
 	database.Skill
@@ -57,8 +99,9 @@ type UpdateToolInstanceRequest struct {
 
 // CreateSSHKeyRequest is the request body for POST /api/tools/:id/ssh-keys.
 type CreateSSHKeyRequest struct {
-	Name       string `json:"name"`
-	PrivateKey string `json:"private_key"`
+	Name       string `json:"name" validate:"required"`
+	PrivateKey string `json:"private_key" validate:"required"`
+	Passphrase string `json:"passphrase"` // Optional; for encrypted private keys
 	IsDefault  bool   `json:"is_default"`
 }
 
@@ -84,6 +127,36 @@ type CreateIncidentResponse struct {
 	Message    string `json:"message"`
 }
 
+// IncidentMessageRequest is the request body for POST /api/incidents/{uuid}/message.
+type IncidentMessageRequest struct {
+	Message string `json:"message" validate:"required"`
+}
+
+// IncidentMessageResponse is the response body for POST /api/incidents/{uuid}/message.
+type IncidentMessageResponse struct {
+	UUID    string `json:"uuid"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// IncidentRetryResponse is the response body for POST /api/incidents/{uuid}/retry.
+type IncidentRetryResponse struct {
+	UUID    string `json:"uuid"`
+	Status  string `json:"status"`
+	Attempt int    `json:"attempt"`
+}
+
+// IncidentLogChunkResponse is the response body for GET
+// /api/incidents/{uuid}/log?offset=. Offsets and Size count characters;
+// NextOffset is the offset to request next, equal to Size once the whole
+// log has been read.
+type IncidentLogChunkResponse struct {
+	Content    string `json:"content"`
+	Offset     int    `json:"offset"`
+	NextOffset int    `json:"next_offset"`
+	Size       int    `json:"size"`
+}
+
 // ========== Settings Types ==========
 
 // CreateLLMSettingsRequest is the request body for POST /api/settings/llm.
@@ -94,6 +167,7 @@ type CreateLLMSettingsRequest struct {
 	Model         string `json:"model"`
 	ThinkingLevel string `json:"thinking_level"`
 	BaseURL       string `json:"base_url"`
+	ContextWindow int    `json:"context_window"`
 }
 
 // UpdateLLMSettingsRequest is the request body for PUT /api/settings/llm/{id}.
@@ -103,6 +177,21 @@ type UpdateLLMSettingsRequest struct {
 	Model         *string `json:"model"`
 	ThinkingLevel *string `json:"thinking_level"`
 	BaseURL       *string `json:"base_url"`
+	ContextWindow *int    `json:"context_window"`
+}
+
+// LLMHealthResponse is the response of POST /api/settings/llm/{id}/health.
+// Models lists what the endpoint serves; ModelAvailable reports whether the
+// configured model is among them. ContextWindow is the model's context
+// window as reported by the endpoint, 0 when it reports none.
+type LLMHealthResponse struct {
+	Healthy        bool     `json:"healthy"`
+	Endpoint       string   `json:"endpoint"`
+	LatencyMs      int64    `json:"latency_ms"`
+	Models         []string `json:"models"`
+	ModelAvailable bool     `json:"model_available"`
+	ContextWindow  int      `json:"context_window"`
+	Error          string   `json:"error,omitempty"`
 }
 
 // UpdateProxySettingsRequest is the request body for PUT /api/settings/proxy.
@@ -140,6 +229,15 @@ type UpdateProxySettingsRequest struct {
 		Jira struct {
 			Enabled bool `json:"enabled"`
 		} `json:"jira"`
+		Prometheus struct {
+			Enabled bool `json:"enabled"`
+		} `json:"prometheus"`
+		LogSearch struct {
+			Enabled bool `json:"enabled"`
+		} `json:"log_search"`
+		HTTPCheck struct {
+			Enabled bool `json:"enabled"`
+		} `json:"http_check"`
 	} `json:"services"`
 }
 
@@ -149,6 +247,12 @@ type UpdateGeneralSettingsRequest struct {
 	AlertCorrelationEnabled  *bool   `json:"alert_correlation_enabled"`
 	AlertMonitorWindowMinutes *int   `json:"alert_monitor_window_minutes"`
 	IncidentMergeEnabled     *bool   `json:"incident_merge_enabled"`
+	NotificationLocale       *string `json:"notification_locale"`
+	IncidentAutoCloseDays    *int    `json:"incident_auto_close_days"`
+	ShortLinksEnabled        *bool   `json:"short_links_enabled"`
+	ConfidenceReviewThreshold    *float64 `json:"confidence_review_threshold"`
+	ConfidenceReviewers          *string  `json:"confidence_reviewers"`
+	ConfidenceAutoCloseThreshold *float64 `json:"confidence_auto_close_threshold"`
 }
 
 // UpdateRetentionSettingsRequest is the request body for PUT /api/settings/retention.
@@ -156,6 +260,83 @@ type UpdateRetentionSettingsRequest struct {
 	Enabled              *bool `json:"enabled"`
 	RetentionDays        *int  `json:"retention_days"`
 	CleanupIntervalHours *int  `json:"cleanup_interval_hours"`
+	WorkspaceMaxAgeDays  *int  `json:"workspace_max_age_days"`
+	WorkspaceMaxTotalMB  *int  `json:"workspace_max_total_mb"`
+	ArchiveWorkspaces    *bool `json:"archive_workspaces"`
+}
+
+// UpdateVocabularySettingsRequest is the request body for PUT
+// /api/settings/vocabulary. A map that is present replaces the stored one;
+// an empty map clears it.
+type UpdateVocabularySettingsRequest struct {
+	SeverityEmoji map[string]string `json:"severity_emoji"`
+	StatusLabels  map[string]string `json:"status_labels"`
+	StatusColors  map[string]string `json:"status_colors"`
+}
+
+// UpdateCORSSettingsRequest is the request body for PUT /api/settings/cors.
+// Omitted fields keep their saved values.
+type UpdateCORSSettingsRequest struct {
+	Enabled          *bool     `json:"enabled"`
+	AllowedOrigins   *[]string `json:"allowed_origins"`
+	AllowedHeaders   *[]string `json:"allowed_headers"`
+	AllowCredentials *bool     `json:"allow_credentials"`
+}
+
+// CORSSettingsResponse is the response for GET and PUT /api/settings/cors:
+// the saved settings and the policy in force.
+type CORSSettingsResponse struct {
+	database.CORSSettings
+	Effective CORSPolicy `json:"effective"`
+}
+
+// CORSPolicy is the CORS policy a replica applies. Source is "settings"
+// when the saved settings are enabled and "environment" when the CORS_*
+// variables apply.
+type CORSPolicy struct {
+	Source           string   `json:"source"`
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+}
+
+// RetentionCleanupResponse is the response for POST /api/settings/retention/cleanup.
+type RetentionCleanupResponse struct {
+	Skipped                 bool     `json:"skipped"`
+	ExpiredIncidentsDeleted int      `json:"expired_incidents_deleted"`
+	ExpiredAlertsDeleted    int      `json:"expired_alerts_deleted"`
+	ExpiredDirsDeleted      int      `json:"expired_dirs_deleted"`
+	ExpiredBytesFreed       int64    `json:"expired_bytes_freed"`
+	OrphanedDirsDeleted     int      `json:"orphaned_dirs_deleted"`
+	OrphanedBytesFreed      int64    `json:"orphaned_bytes_freed"`
+	WorkspacesPruned        int      `json:"workspaces_pruned"`
+	WorkspaceBytesFreed     int64    `json:"workspace_bytes_freed"`
+	WorkspacesArchived      int      `json:"workspaces_archived"`
+	Errors                  []string `json:"errors"`
+}
+
+// UpdateBudgetSettingsRequest is the request body for PUT /api/settings/llm/budget.
+// Limits of 0 are off.
+type UpdateBudgetSettingsRequest struct {
+	Enabled                 *bool    `json:"enabled"`
+	DailyTokenLimit         *int64   `json:"daily_token_limit"`
+	MonthlyTokenLimit       *int64   `json:"monthly_token_limit"`
+	DailyCostLimitUSD       *float64 `json:"daily_cost_limit_usd"`
+	MonthlyCostLimitUSD     *float64 `json:"monthly_cost_limit_usd"`
+	NotificationChannelUUID *string  `json:"notification_channel_uuid"`
+}
+
+// ModelPriceInput is one row of the model price table.
+type ModelPriceInput struct {
+	Model               string  `json:"model"`
+	USDPerMillionTokens float64 `json:"usd_per_million_tokens"`
+}
+
+// UpdateModelPricesRequest is the request body for PUT /api/settings/model-prices.
+// The submitted rows replace the whole table.
+type UpdateModelPricesRequest struct {
+	Prices []ModelPriceInput `json:"prices"`
 }
 
 // CreateFormattingRuleRequest is the request body for POST /api/formatting-rules.
@@ -192,6 +373,137 @@ type UpdateFormattingRuleRequest struct {
 	Temperature         *float64 `json:"temperature"`
 }
 
+// CreateNotificationTemplateRequest is the request body for POST
+// /api/notification-templates. Locale "" is the catch-all override; omitted
+// enabled defaults to true.
+type CreateNotificationTemplateRequest struct {
+	Kind    string `json:"kind"`
+	Locale  string `json:"locale"`
+	Body    string `json:"body"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// CreateIncidentLinkRequest is the request body for POST
+// /api/incidents/{uuid}/links. Kind is relative to the path incident:
+// "parent" makes the target its parent, "child" makes the target its child,
+// "related" links them both ways.
+type CreateIncidentLinkRequest struct {
+	TargetUUID string `json:"target_uuid" validate:"notblank"`
+	Kind       string `json:"kind"`
+	Reason     string `json:"reason"`
+}
+
+// ExportSnippetRequest is the request body for POST
+// /api/incidents/{uuid}/snippets. Kind is "script" (default) or "reference".
+type ExportSnippetRequest struct {
+	SkillName   string `json:"skill_name"`
+	Kind        string `json:"kind"`
+	Filename    string `json:"filename"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	Overwrite   bool   `json:"overwrite"`
+}
+
+// AddTimelineNoteRequest is the request body for POST
+// /api/incidents/{uuid}/timeline.
+type AddTimelineNoteRequest struct {
+	Text string `json:"text"`
+}
+
+// DecideApprovalRequest is the optional request body for POST
+// /api/approvals/{uuid}/approve and /reject, and for the remediation plan
+// decisions under /api/incidents/{uuid}/plan.
+type DecideApprovalRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SilenceRequest is the request body for POST /api/silences and PUT
+// /api/silences/{uuid}. StartsAt defaults to now (or, on update, the
+// current start). The window ends at EndsAt, or DurationMinutes after the
+// start when EndsAt is omitted.
+type SilenceRequest struct {
+	Comment         string            `json:"comment"`
+	SourceUUID      string            `json:"source_uuid"`
+	AlertName       string            `json:"alert_name"`
+	TargetHost      string            `json:"target_host"`
+	Labels          map[string]string `json:"labels"`
+	StartsAt        *time.Time        `json:"starts_at"`
+	EndsAt          *time.Time        `json:"ends_at"`
+	DurationMinutes int               `json:"duration_minutes" validate:"gte=0"`
+}
+
+// RoutingRuleRequest is the request body for POST /api/routing-rules and
+// PUT /api/routing-rules/{uuid}; PUT replaces the whole rule. Enabled
+// defaults to true. NotificationChannelUUID and SkillNames are optional
+// overrides of the alert source's channel and skills.
+type RoutingRuleRequest struct {
+	Name                    string            `json:"name" validate:"notblank"`
+	Description             string            `json:"description"`
+	Enabled                 *bool             `json:"enabled"`
+	Position                int               `json:"position"`
+	SourceUUID              string            `json:"source_uuid"`
+	Severities              []string          `json:"severities" validate:"dive,severity"`
+	AlertName               string            `json:"alert_name"`
+	TargetHost              string            `json:"target_host"`
+	Labels                  map[string]string `json:"labels"`
+	Action                  string            `json:"action" validate:"omitempty,oneof=investigate record"`
+	Priority                string            `json:"priority" validate:"omitempty,severity"`
+	NotificationChannelUUID string            `json:"notification_channel_uuid"`
+	SkillNames              []string          `json:"skill_names"`
+}
+
+// ProvisionZabbixRequest is the request body for POST
+// /api/alert-sources/{uuid}/provision/zabbix. Every field is optional; see
+// services.ZabbixProvisionRequest for the defaults.
+type ProvisionZabbixRequest struct {
+	ToolInstanceID uint   `json:"tool_instance_id"`
+	AkmatoriURL    string `json:"akmatori_url"`
+	ZabbixUser     string `json:"zabbix_user"`
+	MinSeverity    *int   `json:"min_severity"`
+}
+
+// UpdateNotificationTemplateRequest is the request body for PUT
+// /api/notification-templates/{id}. Omitted fields keep their value.
+type UpdateNotificationTemplateRequest struct {
+	Body    *string `json:"body"`
+	Enabled *bool   `json:"enabled"`
+}
+
+// PreviewNotificationTemplateRequest is the request body for POST
+// /api/notification-templates/preview. An empty body previews the template
+// currently in effect for kind and locale.
+type PreviewNotificationTemplateRequest struct {
+	Kind   string `json:"kind"`
+	Locale string `json:"locale"`
+	Body   string `json:"body"`
+}
+
+// CreatePromptTemplateRequest is the request body for POST
+// /api/settings/prompts. Empty source_type and skill match any alert; omitted
+// enabled defaults to true.
+type CreatePromptTemplateRequest struct {
+	SourceType string `json:"source_type"`
+	Skill      string `json:"skill"`
+	Body       string `json:"body"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+// UpdatePromptTemplateRequest is the request body for PUT
+// /api/settings/prompts/{id}. Omitted fields keep their value.
+type UpdatePromptTemplateRequest struct {
+	Body    *string `json:"body"`
+	Enabled *bool   `json:"enabled"`
+}
+
+// PreviewPromptTemplateRequest is the request body for POST
+// /api/settings/prompts/preview. An empty body previews the template
+// currently in effect for source_type and skill.
+type PreviewPromptTemplateRequest struct {
+	SourceType string `json:"source_type"`
+	Skill      string `json:"skill"`
+	Body       string `json:"body"`
+}
+
 // ReorderFormattingRulesRequest is the request body for PUT
 // /api/formatting-rules/reorder. UUIDs must enumerate every existing rule
 // exactly once, in the desired evaluation order.
@@ -204,6 +516,7 @@ type ReorderFormattingRulesRequest struct {
 // CreateAlertSourceRequest is the request body for POST /api/alert-sources.
 // NotificationChannelUUID is optional; when set, the alert source routes
 // outbound posts to the referenced Channel instead of the provider default.
+// SkillNames optionally limits the source's investigations to those skills.
 type CreateAlertSourceRequest struct {
 	SourceTypeName          string         `json:"source_type_name" validate:"required"`
 	Name                    string         `json:"name" validate:"required,min=1"`
@@ -212,12 +525,14 @@ type CreateAlertSourceRequest struct {
 	FieldMappings           database.JSONB `json:"field_mappings"`
 	Settings                database.JSONB `json:"settings"`
 	NotificationChannelUUID *string        `json:"notification_channel_uuid"`
+	SkillNames              []string       `json:"skill_names"`
 }
 
 // UpdateAlertSourceRequest is the request body for PUT /api/alert-sources/:uuid.
 // NotificationChannelUUID is a tri-state: omitted = no change, empty string or
 // JSON null = clear the existing routing override (revert to default), non-empty
-// = set to that Channel UUID.
+// = set to that Channel UUID. SkillNames: omitted = no change, empty list =
+// use every enabled skill, otherwise pin the source to those skills.
 type UpdateAlertSourceRequest struct {
 	Name                    *string         `json:"name"`
 	Description             *string         `json:"description"`
@@ -226,6 +541,7 @@ type UpdateAlertSourceRequest struct {
 	Settings                *database.JSONB `json:"settings"`
 	Enabled                 *bool           `json:"enabled"`
 	NotificationChannelUUID *string         `json:"notification_channel_uuid"`
+	SkillNames              *[]string       `json:"skill_names"`
 }
 
 // ========== Context Types ==========
@@ -235,6 +551,24 @@ type ValidateReferencesRequest struct {
 	Text string `json:"text"`
 }
 
+// ========== Readiness Types ==========
+
+// DependencyStatus is the result of one readiness check. Status is "ok",
+// "disabled" (the dependency is not used by this replica) or "error";
+// Message says why for the latter two.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// ReadinessResponse is the response body for GET /readyz. Status is "ready"
+// when no check failed and "not_ready" otherwise.
+type ReadinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
 // ========== Pagination Types ==========
 
 // PaginationMeta contains pagination metadata for list responses.
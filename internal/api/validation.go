@@ -2,12 +2,86 @@ package api
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/akmatori/akmatori/internal/database"
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report fields by their JSON names, so errors match the request body.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return toSnakeCase(f.Name)
+		}
+		return name
+	})
+	// notblank is required for strings that handlers trim before use.
+	mustRegister(v, "notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	// severity accepts the normalized alert severities in any case, the way
+	// handlers normalize them before storing.
+	mustRegister(v, "severity", func(fl validator.FieldLevel) bool {
+		return ValidSeverity(fl.Field().String())
+	})
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// Severities are the normalized alert severities, most severe first.
+var Severities = []database.AlertSeverity{
+	database.AlertSeverityCritical,
+	database.AlertSeverityHigh,
+	database.AlertSeverityWarning,
+	database.AlertSeverityInfo,
+}
+
+// ValidSeverity reports whether s, trimmed and lowercased, is a normalized
+// alert severity.
+func ValidSeverity(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, sev := range Severities {
+		if s == string(sev) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError is returned by DecodeJSON for a body that decoded but
+// failed its validate tags. Fields maps each failing field, named as in the
+// body (nested fields joined by dots), to what is wrong with it.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + " " + e.Fields[name]
+	}
+	return strings.Join(parts, "; ")
+}
 
 // Validate validates a struct using go-playground/validator tags.
 // Returns nil on success or a map of field-name → error-message.
@@ -24,24 +98,50 @@ func Validate(s interface{}) map[string]string {
 
 	errs := make(map[string]string, len(validationErrors))
 	for _, fe := range validationErrors {
-		field := toSnakeCase(fe.Field())
-		errs[field] = validationMessage(fe)
+		errs[fieldPath(fe)] = validationMessage(fe)
 	}
 	return errs
 }
 
+// fieldPath is the dotted JSON path of fe's field, without the struct name
+// the validator prefixes it with.
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
 // validationMessage returns a human-readable message for a validation error.
 func validationMessage(fe validator.FieldError) string {
 	switch fe.Tag() {
-	case "required":
+	case "required", "notblank":
 		return "is required"
-	case "min":
-		return fmt.Sprintf("must be at least %s characters", fe.Param())
-	case "max":
-		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "min", "gte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("must have at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("must have at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
 	case "oneof":
 		return fmt.Sprintf("must be one of: %s", fe.Param())
-	case "url":
+	case "severity":
+		names := make([]string, len(Severities))
+		for i, sev := range Severities {
+			names[i] = string(sev)
+		}
+		return "must be one of: " + strings.Join(names, " ")
+	case "url", "http_url":
 		return "must be a valid URL"
 	case "email":
 		return "must be a valid email"
@@ -127,6 +127,54 @@ func TestValidate_OmitsEmptyOptional(t *testing.T) {
 	}
 }
 
+type testNestedStruct struct {
+	Alert struct {
+		Name     string `json:"alert_name" validate:"notblank"`
+		Severity string `json:"severity" validate:"omitempty,severity"`
+	} `json:"alert"`
+	Severities []string `json:"severities" validate:"dive,severity"`
+	Tags       []string `json:"tags" validate:"min=1"`
+	Minutes    int      `json:"minutes" validate:"gte=0"`
+}
+
+func TestValidate_JSONNamesAndNestedPaths(t *testing.T) {
+	var s testNestedStruct
+	s.Alert.Name = "  "
+	s.Alert.Severity = "urgent"
+	s.Severities = []string{"CRITICAL", " info ", "page"}
+	s.Minutes = -1
+
+	want := map[string]string{
+		"alert.alert_name": "is required",
+		"alert.severity":   "must be one of: critical high warning info",
+		"severities[2]":    "must be one of: critical high warning info",
+		"tags":             "must have at least 1 items",
+		"minutes":          "must be at least 0",
+	}
+	errs := Validate(s)
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Errorf("%s error = %q, want %q", field, errs[field], msg)
+		}
+	}
+	if len(errs) != len(want) {
+		t.Errorf("errors = %v, want %d", errs, len(want))
+	}
+}
+
+func TestValidSeverity(t *testing.T) {
+	for _, s := range []string{"critical", "High", " warning ", "INFO"} {
+		if !ValidSeverity(s) {
+			t.Errorf("ValidSeverity(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"", "urgent", "crit"} {
+		if ValidSeverity(s) {
+			t.Errorf("ValidSeverity(%q) = true, want false", s)
+		}
+	}
+}
+
 func TestToSnakeCase(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -20,7 +20,7 @@ import (
 // SystemSetting stores key-value pairs for system configuration (JWT secret, admin password hash, etc.)
 type SystemSetting struct {
 	Key       string    `gorm:"primaryKey;size:64" json:"key"`
-	Value     string    `gorm:"type:text;not null" json:"value"`
+	Value     string    `gorm:"type:text;not null;serializer:secret" json:"value"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
@@ -70,6 +70,9 @@ func Connect(dsn string, logLevel logger.LogLevel) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := registerSecretCallbacks(DB); err != nil {
+		return fmt.Errorf("failed to register secret callbacks: %w", err)
+	}
 
 	slog.Info("database connection established")
 	return nil
@@ -160,12 +163,23 @@ func runMigrations(db *gorm.DB) error {
 		&ToolType{},
 		&ToolInstance{},
 		&SkillTool{},
+		&SkillSnippet{},
+		&SSHKnownHost{},
 		&EventSource{},
 		&Incident{},
+		&IncidentLogChunk{},
+		&IncidentLink{},
+		&IncidentEvent{},
+		&IncidentChange{},
+		&IncidentReport{}, &ToolApproval{},
+		&RemediationPlan{},
 		&APIKeySettings{},
 		// Alert source models
 		&AlertSourceType{},
 		&AlertSourceInstance{},
+		&AlertSourceSkill{},
+		&Silence{},
+		&SuppressedAlert{},
 		&GeneralSettings{},
 		&Runbook{},
 		&Memory{},
@@ -174,16 +188,38 @@ func runMigrations(db *gorm.DB) error {
 		&RetentionSettings{},
 		&FormattingSettings{},
 		&FormattingRule{},
+		&CORSSettings{},
 		// Channels & cron (unified channels + cron jobs feature)
 		&Integration{},
 		&Channel{},
 		&CronJob{},
 		&CronJobTool{},
+		&CronJobSkill{},
+		// Per-alert routing overrides for alert sources
+		&RoutingRule{},
+		&RoutingRuleSkill{},
 		// Alerts (first-class alert rows attached to incidents)
 		&Alert{},
 		// Self-improvement proposals + refinement chat transcripts
 		&Proposal{},
 		&ProposalChatMessage{},
+		// Append-only security audit trail
+		&AuditLog{},
+		// Operator overrides for outbound notification text
+		&NotificationTemplate{},
+		// LLM price table for investigation cost estimates
+		&ModelPrice{},
+		// Per-run token usage for the cost dashboard
+		&TokenUsage{},
+		&BudgetSettings{},
+		// Deployment-specific severity and status presentation
+		&VocabularySettings{},
+		// Short /i/{code} links to the UI
+		&ShortLink{},
+		// Weekly alert volume baselines
+		&AlertBaseline{},
+		// Operator overrides of the investigation prompt
+		&PromptTemplate{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -240,6 +276,12 @@ func runMigrations(db *gorm.DB) error {
 		return err
 	}
 
+	// Move logs from the legacy incidents.full_log column into
+	// incident_log_chunks and drop the column.
+	if err := migrateIncidentFullLogToChunks(db); err != nil {
+		return err
+	}
+
 	// Backfill alert rows for pre-existing alert-sourced incidents.
 	if err := migrateBackfillAlerts(db); err != nil {
 		return err
@@ -251,6 +293,12 @@ func runMigrations(db *gorm.DB) error {
 		return err
 	}
 
+	// Encrypt secrets stored before SECRETS_ENCRYPTION_KEY was set, and
+	// re-encrypt those written with a rotated-out key.
+	if err := EncryptSecrets(db); err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+
 	slog.Info("database migrations completed successfully")
 	return nil
 }
@@ -591,6 +639,7 @@ var defaultModelsPerProvider = map[LLMProvider]string{
 	LLMProviderNvidiaNIM:  "meta/llama-3.3-70b-instruct",
 	LLMProviderMiniMax:    "MiniMax-M3",
 	LLMProviderAntLing:    "Ling-2.6-1T",
+	LLMProviderSelfHosted: "",
 }
 
 // seedLLMProviders ensures one row per provider exists in the llm_settings table.
@@ -1270,6 +1319,28 @@ func GetLLMSettings() (*LLMSettings, error) {
 	return &settings, nil
 }
 
+// GetLLMSettingsForSkills returns the LLM settings for a run scoped to the
+// named skills: the configuration pinned by the first of them (by name) whose
+// pinned configuration is usable, otherwise the active one. Callers should
+// only pass skills the run is pinned to, never every enabled skill.
+func GetLLMSettingsForSkills(names []string) (*LLMSettings, error) {
+	if len(names) > 0 {
+		var pinned []LLMSettings
+		if err := DB.Joins("JOIN skills ON skills.llm_settings_id = llm_settings.id").
+			Where("skills.name IN ? AND skills.enabled = ?", names, true).
+			Order("skills.name ASC").
+			Find(&pinned).Error; err != nil {
+			return nil, err
+		}
+		for i := range pinned {
+			if pinned[i].IsActive() {
+				return &pinned[i], nil
+			}
+		}
+	}
+	return GetLLMSettings()
+}
+
 // GetAllLLMSettings returns all LLM configurations ordered by provider then name.
 func GetAllLLMSettings() ([]LLMSettings, error) {
 	var settings []LLMSettings
@@ -1309,7 +1380,10 @@ func SetActiveLLMConfig(id uint) error {
 		if target == nil {
 			return fmt.Errorf("LLM config with id %d not found", id)
 		}
-		if target.APIKey == "" {
+		if !target.IsConfigured() {
+			if target.Provider == LLMProviderSelfHosted {
+				return fmt.Errorf("cannot activate a self-hosted configuration without a base URL and model")
+			}
 			return fmt.Errorf("cannot activate a configuration without an API key")
 		}
 		if err := tx.Model(&LLMSettings{}).Where("active = ?", true).Update("active", false).Error; err != nil {
@@ -1331,7 +1405,9 @@ func CreateLLMSettings(settings *LLMSettings) error {
 
 // UpdateLLMSettings atomically updates an LLM config by ID.
 // Uses SELECT FOR UPDATE to prevent concurrent update/activate races.
-// Returns an error if the update would clear the API key on the active config.
+// Returns an error if the update would clear the API key on the active config,
+// or the base URL or model of an active self-hosted one. Enabled follows
+// IsConfigured whenever the API key, base URL or model changes.
 func UpdateLLMSettings(id uint, updates map[string]interface{}) (*LLMSettings, error) {
 	var result LLMSettings
 	err := DB.Transaction(func(tx *gorm.DB) error {
@@ -1340,12 +1416,6 @@ func UpdateLLMSettings(id uint, updates map[string]interface{}) (*LLMSettings, e
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&settings, id).Error; err != nil {
 			return err
 		}
-		// Prevent clearing the API key on the active config
-		if apiKey, ok := updates["api_key"]; ok {
-			if apiKey == "" && settings.Active {
-				return fmt.Errorf("cannot clear the API key on the active configuration")
-			}
-		}
 		if err := tx.Model(&settings).Updates(updates).Error; err != nil {
 			return err
 		}
@@ -1353,6 +1423,22 @@ func UpdateLLMSettings(id uint, updates map[string]interface{}) (*LLMSettings, e
 		if err := tx.First(&result, id).Error; err != nil {
 			return err
 		}
+		// Prevent unconfiguring the active config; returning rolls back.
+		if result.Active && !result.IsConfigured() {
+			if result.Provider == LLMProviderSelfHosted {
+				return fmt.Errorf("cannot clear the base URL or model of the active configuration")
+			}
+			return fmt.Errorf("cannot clear the API key on the active configuration")
+		}
+		_, keyChanged := updates["api_key"]
+		_, urlChanged := updates["base_url"]
+		_, modelChanged := updates["model"]
+		if (keyChanged || urlChanged || modelChanged) && result.Enabled != result.IsConfigured() {
+			result.Enabled = result.IsConfigured()
+			if err := tx.Model(&result).Update("enabled", result.Enabled).Error; err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -1387,6 +1473,10 @@ func DeleteLLMSettings(id uint) error {
 		if len(allConfigs) <= 1 {
 			return fmt.Errorf("cannot delete the last LLM configuration")
 		}
+		// Skills pinned to the config fall back to the active one.
+		if err := tx.Model(&Skill{}).Where("llm_settings_id = ?", id).Update("llm_settings_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unpin skills: %w", err)
+		}
 		return tx.Delete(&LLMSettings{}, id).Error
 	})
 }
@@ -1503,6 +1593,27 @@ func UpdateRetentionSettings(settings *RetentionSettings) error {
 	return DB.Save(settings).Error
 }
 
+// GetOrCreateCORSSettings retrieves or creates CORS settings (singleton).
+// If FirstOrCreate races with another caller, we fall back to a plain read.
+func GetOrCreateCORSSettings() (*CORSSettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var settings CORSSettings
+	defaults := DefaultCORSSettings()
+	if err := DB.Where(CORSSettings{SingletonKey: "default"}).Attrs(defaults).FirstOrCreate(&settings).Error; err != nil {
+		if rerr := DB.Where(CORSSettings{SingletonKey: "default"}).First(&settings).Error; rerr != nil {
+			return nil, fmt.Errorf("%w (retry: %v)", err, rerr)
+		}
+	}
+	return &settings, nil
+}
+
+// UpdateCORSSettings updates CORS settings in the database
+func UpdateCORSSettings(settings *CORSSettings) error {
+	return DB.Save(settings).Error
+}
+
 // GetOrCreateFormattingSettings retrieves or creates formatting settings (singleton).
 // The row is normally seeded by InitializeDefaults at startup; the create path
 // here is only a fallback. If FirstOrCreate races with another caller (both see
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// incidentLogChunkSize caps the characters stored in one IncidentLogChunk,
+// so a whole-log write becomes several rows and a ranged read only loads
+// the rows it overlaps.
+const incidentLogChunkSize = 64 * 1024
+
+// incidentLogAppendAttempts bounds the retries of an append that raced
+// another append to the same incident for the next sequence number.
+const incidentLogAppendAttempts = 5
+
+// splitIncidentLog cuts log into pieces of at most incidentLogChunkSize
+// characters.
+func splitIncidentLog(log string) []string {
+	var pieces []string
+	for log != "" {
+		end, n := 0, 0
+		for end < len(log) && n < incidentLogChunkSize {
+			_, size := utf8.DecodeRuneInString(log[end:])
+			end += size
+			n++
+		}
+		pieces = append(pieces, log[:end])
+		log = log[end:]
+	}
+	return pieces
+}
+
+// insertIncidentLogChunks stores pieces of log as chunks seq, seq+1, ...
+// starting at character offset start.
+func insertIncidentLogChunks(tx *gorm.DB, incidentUUID string, seq, start int, log string) error {
+	now := time.Now()
+	for _, piece := range splitIncidentLog(log) {
+		length := utf8.RuneCountInString(piece)
+		chunk := IncidentLogChunk{
+			IncidentUUID: incidentUUID,
+			Seq:          seq,
+			StartOffset:  start,
+			Length:       length,
+			Content:      piece,
+			CreatedAt:    now,
+		}
+		if err := tx.Create(&chunk).Error; err != nil {
+			return err
+		}
+		seq++
+		start += length
+	}
+	return nil
+}
+
+// AppendIncidentLog appends delta to an incident's log.
+func AppendIncidentLog(db *gorm.DB, incidentUUID, delta string) error {
+	if delta == "" {
+		return nil
+	}
+	var err error
+	for attempt := 0; attempt < incidentLogAppendAttempts; attempt++ {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var last IncidentLogChunk
+			res := tx.Where("incident_uuid = ?", incidentUUID).Order("seq DESC").Limit(1).Find(&last)
+			if res.Error != nil {
+				return res.Error
+			}
+			seq, start := 0, 0
+			if res.RowsAffected > 0 {
+				seq, start = last.Seq+1, last.StartOffset+last.Length
+			}
+			return insertIncidentLogChunks(tx, incidentUUID, seq, start, delta)
+		})
+		if err == nil {
+			return nil
+		}
+		// A concurrent append took the same sequence number; the unique
+		// index rejected this one, so read the new tail and try again.
+	}
+	return fmt.Errorf("append incident log: %w", err)
+}
+
+// ReplaceIncidentLog stores log as an incident's whole log, dropping the
+// chunks written before.
+func ReplaceIncidentLog(db *gorm.DB, incidentUUID, log string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("incident_uuid = ?", incidentUUID).Delete(&IncidentLogChunk{}).Error; err != nil {
+			return err
+		}
+		return insertIncidentLogChunks(tx, incidentUUID, 0, 0, log)
+	})
+	if err != nil {
+		return fmt.Errorf("replace incident log: %w", err)
+	}
+	return nil
+}
+
+// LoadIncidentLog returns an incident's whole log.
+func LoadIncidentLog(db *gorm.DB, incidentUUID string) (string, error) {
+	var chunks []IncidentLogChunk
+	if err := db.Select("content").Where("incident_uuid = ?", incidentUUID).Order("seq").Find(&chunks).Error; err != nil {
+		return "", fmt.Errorf("load incident log: %w", err)
+	}
+	var b strings.Builder
+	for _, c := range chunks {
+		b.WriteString(c.Content)
+	}
+	return b.String(), nil
+}
+
+// ReadIncidentLogRange returns up to limit characters of an incident's log
+// starting at offset, along with the log's total length in characters. Only
+// the chunks overlapping the window are loaded.
+func ReadIncidentLogRange(db *gorm.DB, incidentUUID string, offset, limit int) (string, int, error) {
+	var last IncidentLogChunk
+	res := db.Select("start_offset", "length").Where("incident_uuid = ?", incidentUUID).Order("seq DESC").Limit(1).Find(&last)
+	if res.Error != nil {
+		return "", 0, fmt.Errorf("read incident log: %w", res.Error)
+	}
+	size := last.StartOffset + last.Length
+	if res.RowsAffected == 0 || offset >= size {
+		return "", size, nil
+	}
+
+	var chunks []IncidentLogChunk
+	if err := db.Where("incident_uuid = ? AND start_offset + length > ? AND start_offset < ?", incidentUUID, offset, offset+limit).
+		Order("seq").Find(&chunks).Error; err != nil {
+		return "", 0, fmt.Errorf("read incident log: %w", err)
+	}
+	if len(chunks) == 0 {
+		return "", size, nil
+	}
+	var b strings.Builder
+	for _, c := range chunks {
+		b.WriteString(c.Content)
+	}
+	window := []rune(b.String())
+	from := offset - chunks[0].StartOffset
+	to := min(from+limit, len(window))
+	return string(window[from:to]), size, nil
+}
+
+// DeleteIncidentLog removes an incident's log.
+func DeleteIncidentLog(db *gorm.DB, incidentUUID string) error {
+	return db.Where("incident_uuid = ?", incidentUUID).Delete(&IncidentLogChunk{}).Error
+}
+
+// migrateIncidentFullLogToChunks moves logs stored in the legacy
+// incidents.full_log column into incident_log_chunks, then drops the
+// column. Each incident is moved and cleared in one transaction, so an
+// interrupted run picks up where it stopped.
+func migrateIncidentFullLogToChunks(db *gorm.DB) error {
+	db = db.Session(&gorm.Session{NewDB: true})
+	if !db.Migrator().HasColumn(&Incident{}, "full_log") {
+		return nil
+	}
+
+	moved := 0
+	for {
+		var rows []struct {
+			UUID    string
+			FullLog string
+		}
+		if err := db.Raw("SELECT uuid, full_log FROM incidents WHERE full_log IS NOT NULL AND full_log <> '' LIMIT 100").
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("migrateIncidentFullLogToChunks: query: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := ReplaceIncidentLog(tx, row.UUID, row.FullLog); err != nil {
+					return err
+				}
+				return tx.Exec("UPDATE incidents SET full_log = NULL WHERE uuid = ?", row.UUID).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migrateIncidentFullLogToChunks: incident %s: %w", row.UUID, err)
+			}
+			moved++
+		}
+	}
+
+	if err := db.Migrator().DropColumn(&Incident{}, "full_log"); err != nil {
+		return fmt.Errorf("migrateIncidentFullLogToChunks: drop column: %w", err)
+	}
+	if moved > 0 {
+		slog.Info("moved incident logs into incident_log_chunks", "incidents", moved)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openIncidentLogTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open in-memory sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every pooled connection to :memory: is its own database.
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestIncidentLog_AppendAndReadRange(t *testing.T) {
+	db := openIncidentLogTestDB(t)
+	if err := db.AutoMigrate(&Incident{}, &IncidentLogChunk{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	if err := db.Create(&Incident{UUID: "inc-1", Source: "test", FullLog: "start\n"}).Error; err != nil {
+		t.Fatalf("create incident: %v", err)
+	}
+
+	// A delta longer than one chunk is split, and multi-byte characters
+	// count once towards offsets.
+	long := strings.Repeat("é", incidentLogChunkSize+10)
+	if err := AppendIncidentLog(db, "inc-1", long); err != nil {
+		t.Fatalf("AppendIncidentLog: %v", err)
+	}
+	if err := AppendIncidentLog(db, "inc-1", "\ndone"); err != nil {
+		t.Fatalf("AppendIncidentLog: %v", err)
+	}
+
+	var chunks []IncidentLogChunk
+	db.Where("incident_uuid = ?", "inc-1").Order("seq").Find(&chunks)
+	if len(chunks) != 4 {
+		t.Fatalf("chunks = %d, want 4", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Seq != i {
+			t.Errorf("chunk %d has seq %d", i, c.Seq)
+		}
+	}
+
+	want := "start\n" + long + "\ndone"
+	got, err := LoadIncidentLog(db, "inc-1")
+	if err != nil || got != want {
+		t.Fatalf("LoadIncidentLog = %d chars, err %v; want %d chars", len(got), err, len(want))
+	}
+
+	size := 6 + incidentLogChunkSize + 10 + 5
+	window, total, err := ReadIncidentLogRange(db, "inc-1", 4, 4)
+	if err != nil || window != "t\néé" || total != size {
+		t.Errorf("head window = %q, size %d, err %v", window, total, err)
+	}
+	// A window straddling the chunk boundary.
+	window, _, _ = ReadIncidentLogRange(db, "inc-1", size-8, 100)
+	if window != "ééé\ndone" {
+		t.Errorf("tail window = %q", window)
+	}
+	window, total, _ = ReadIncidentLogRange(db, "inc-1", size, 10)
+	if window != "" || total != size {
+		t.Errorf("past the end = %q, size %d", window, total)
+	}
+
+	if err := ReplaceIncidentLog(db, "inc-1", "rewritten"); err != nil {
+		t.Fatalf("ReplaceIncidentLog: %v", err)
+	}
+	if got, _ := LoadIncidentLog(db, "inc-1"); got != "rewritten" {
+		t.Errorf("after replace = %q", got)
+	}
+	if _, total, _ := ReadIncidentLogRange(db, "inc-1", 0, 10); total != len("rewritten") {
+		t.Errorf("size after replace = %d", total)
+	}
+}
+
+func TestMigrateIncidentFullLogToChunks(t *testing.T) {
+	db := openIncidentLogTestDB(t)
+	if err := db.AutoMigrate(&Incident{}, &IncidentLogChunk{}); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	// Simulate an upgrade install whose incidents still carry full_log.
+	if err := db.Exec("ALTER TABLE incidents ADD COLUMN `full_log` text").Error; err != nil {
+		t.Fatalf("add legacy column: %v", err)
+	}
+	for _, inc := range []Incident{{UUID: "with-log", Source: "test"}, {UUID: "no-log", Source: "test"}} {
+		if err := db.Create(&inc).Error; err != nil {
+			t.Fatalf("create incident: %v", err)
+		}
+	}
+	db.Exec("UPDATE incidents SET full_log = ? WHERE uuid = ?", "legacy log", "with-log")
+
+	if err := migrateIncidentFullLogToChunks(db); err != nil {
+		t.Fatalf("migrateIncidentFullLogToChunks: %v", err)
+	}
+	if got, _ := LoadIncidentLog(db, "with-log"); got != "legacy log" {
+		t.Errorf("migrated log = %q", got)
+	}
+	if got, _ := LoadIncidentLog(db, "no-log"); got != "" {
+		t.Errorf("incident without a log got %q", got)
+	}
+	if db.Migrator().HasColumn(&Incident{}, "full_log") {
+		t.Error("full_log column should be dropped")
+	}
+
+	// Idempotent: a second run is a no-op.
+	if err := migrateIncidentFullLogToChunks(db); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	var count int64
+	db.Model(&IncidentLogChunk{}).Count(&count)
+	if count != 1 {
+		t.Errorf("chunks after second run = %d, want 1", count)
+	}
+}
@@ -14,7 +14,7 @@ func setupLLMTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
-	if err := db.AutoMigrate(&LLMSettings{}); err != nil {
+	if err := db.AutoMigrate(&LLMSettings{}, &Skill{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	// Point global DB to test DB
@@ -262,6 +262,126 @@ func TestDeleteLLMSettings_LastConfigRejected(t *testing.T) {
 	}
 }
 
+func TestDeleteLLMSettings_UnpinsSkills(t *testing.T) {
+	db := setupLLMTestDB(t)
+
+	active := &LLMSettings{Name: "Active", Provider: LLMProviderOpenAI, APIKey: "sk-a", Enabled: true, Active: true}
+	pinned := &LLMSettings{Name: "Pinned", Provider: LLMProviderAnthropic, APIKey: "sk-b", Enabled: true}
+	for _, s := range []*LLMSettings{active, pinned} {
+		if err := CreateLLMSettings(s); err != nil {
+			t.Fatalf("create %s: %v", s.Name, err)
+		}
+	}
+	skill := Skill{Name: "db-analyst", Enabled: true, LLMSettingsID: &pinned.ID}
+	if err := db.Create(&skill).Error; err != nil {
+		t.Fatalf("create skill: %v", err)
+	}
+
+	if err := DeleteLLMSettings(pinned.ID); err != nil {
+		t.Fatalf("DeleteLLMSettings failed: %v", err)
+	}
+	var got Skill
+	if err := db.First(&got, skill.ID).Error; err != nil {
+		t.Fatalf("reload skill: %v", err)
+	}
+	if got.LLMSettingsID != nil {
+		t.Errorf("expected skill to be unpinned, got llm_settings_id %d", *got.LLMSettingsID)
+	}
+}
+
+func TestGetLLMSettingsForSkills(t *testing.T) {
+	db := setupLLMTestDB(t)
+
+	active := &LLMSettings{Name: "Active", Provider: LLMProviderOpenAI, APIKey: "sk-a", Enabled: true, Active: true}
+	claude := &LLMSettings{Name: "Claude", Provider: LLMProviderAnthropic, APIKey: "sk-b", Enabled: true}
+	nokey := &LLMSettings{Name: "No key", Provider: LLMProviderCustom}
+	for _, s := range []*LLMSettings{active, claude, nokey} {
+		if err := CreateLLMSettings(s); err != nil {
+			t.Fatalf("create %s: %v", s.Name, err)
+		}
+	}
+	skills := []Skill{
+		{Name: "plain", Enabled: true},
+		{Name: "k8s", Enabled: true, LLMSettingsID: &claude.ID},
+		{Name: "broken", Enabled: true, LLMSettingsID: &nokey.ID},
+		{Name: "disabled", Enabled: true, LLMSettingsID: &claude.ID},
+	}
+	for i := range skills {
+		if err := db.Create(&skills[i]).Error; err != nil {
+			t.Fatalf("create skill: %v", err)
+		}
+	}
+	if err := db.Model(&Skill{}).Where("name = ?", "disabled").Update("enabled", false).Error; err != nil {
+		t.Fatalf("disable skill: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		skills []string
+		want   string
+	}{
+		{"no skills", nil, "Active"},
+		{"unpinned skill", []string{"plain"}, "Active"},
+		{"pinned skill", []string{"plain", "k8s"}, "Claude"},
+		{"unusable pin falls back", []string{"broken"}, "Active"},
+		{"unusable pin skipped", []string{"broken", "k8s"}, "Claude"},
+		{"disabled skill ignored", []string{"disabled"}, "Active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLLMSettingsForSkills(tt.skills)
+			if err != nil {
+				t.Fatalf("GetLLMSettingsForSkills: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("got config %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfHostedLLMSettings(t *testing.T) {
+	setupLLMTestDB(t)
+
+	active := &LLMSettings{Name: "Active", Provider: LLMProviderOpenAI, APIKey: "sk-a", Enabled: true, Active: true}
+	local := &LLMSettings{Name: "vLLM", Provider: LLMProviderSelfHosted, BaseURL: "http://vllm:8000/v1"}
+	for _, s := range []*LLMSettings{active, local} {
+		if err := CreateLLMSettings(s); err != nil {
+			t.Fatalf("create %s: %v", s.Name, err)
+		}
+	}
+	if local.IsConfigured() {
+		t.Fatal("self-hosted config without a model should not be configured")
+	}
+	if err := SetActiveLLMConfig(local.ID); err == nil || !strings.Contains(err.Error(), "self-hosted") {
+		t.Fatalf("expected self-hosted activation error, got %v", err)
+	}
+
+	// Setting the model configures it without an API key and enables it.
+	updated, err := UpdateLLMSettings(local.ID, map[string]interface{}{"model": "qwen3-32b"})
+	if err != nil {
+		t.Fatalf("UpdateLLMSettings: %v", err)
+	}
+	if !updated.IsConfigured() || !updated.Enabled {
+		t.Fatalf("expected configured and enabled, got configured=%v enabled=%v", updated.IsConfigured(), updated.Enabled)
+	}
+	if err := SetActiveLLMConfig(local.ID); err != nil {
+		t.Fatalf("SetActiveLLMConfig: %v", err)
+	}
+
+	// The active self-hosted config keeps its endpoint and model.
+	if _, err := UpdateLLMSettings(local.ID, map[string]interface{}{"base_url": ""}); err == nil {
+		t.Fatal("expected error clearing the base URL of the active config")
+	}
+	got, err := GetLLMSettingsByID(local.ID)
+	if err != nil {
+		t.Fatalf("GetLLMSettingsByID: %v", err)
+	}
+	if got.BaseURL != "http://vllm:8000/v1" {
+		t.Errorf("expected base URL to be kept, got %q", got.BaseURL)
+	}
+}
+
 func TestGetAllLLMSettings_OrderByProviderThenName(t *testing.T) {
 	setupLLMTestDB(t)
 
@@ -439,8 +559,8 @@ func TestSeedLLMProviders_SetsNames(t *testing.T) {
 	var rows []LLMSettings
 	db.Order("provider asc").Find(&rows)
 
-	if len(rows) != 8 {
-		t.Fatalf("expected 8 rows, got %d", len(rows))
+	if len(rows) != 9 {
+		t.Fatalf("expected 9 rows, got %d", len(rows))
 	}
 
 	// Verify each row has a non-empty name matching its provider display name
@@ -541,6 +661,7 @@ func TestProviderDisplayName(t *testing.T) {
 		{LLMProviderNvidiaNIM, "NVIDIA NIM"},
 		{LLMProviderMiniMax, "MiniMax"},
 		{LLMProviderAntLing, "Ant Ling"},
+		{LLMProviderSelfHosted, "Self-hosted"},
 		{LLMProvider("unknown"), "unknown"},
 	}
 	for _, tt := range tests {
@@ -60,23 +60,45 @@ type AlertSourceInstance struct {
 	AlertSourceTypeID     uint      `gorm:"not null;index" json:"alert_source_type_id"`
 	Name                  string    `gorm:"uniqueIndex;size:128;not null" json:"name"` // User-friendly name
 	Description           string    `gorm:"type:text" json:"description"`
-	WebhookSecret         string    `gorm:"type:text" json:"webhook_secret"`           // Instance-specific secret
-	FieldMappings         JSONB     `gorm:"type:jsonb" json:"field_mappings"`          // Override default mappings
-	Settings              JSONB     `gorm:"type:jsonb" json:"settings"`                // Additional instance settings
-	NotificationChannelID *uint     `gorm:"index" json:"notification_channel_id"`      // Optional FK to channels.id; nil falls back to provider default
+	WebhookSecret         string    `gorm:"type:text;serializer:secret" json:"webhook_secret"` // Instance-specific secret
+	FieldMappings         JSONB     `gorm:"type:jsonb" json:"field_mappings"`                  // Override default mappings
+	Settings              JSONB     `gorm:"type:jsonb" json:"settings"`                        // Additional instance settings
+	NotificationChannelID *uint     `gorm:"index" json:"notification_channel_id"`              // Optional FK to channels.id; nil falls back to provider default
 	Enabled               bool      `gorm:"default:true" json:"enabled"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 
+	// LastAlertAt is when the source last delivered a payload that parsed.
+	// The self-monitor compares it against the silence_threshold_minutes
+	// setting to notice senders that stopped calling the webhook.
+	LastAlertAt *time.Time `gorm:"index" json:"last_alert_at,omitempty"`
+
 	// Relationships
 	AlertSourceType     AlertSourceType `gorm:"foreignKey:AlertSourceTypeID" json:"alert_source_type,omitempty"`
 	NotificationChannel *Channel        `gorm:"foreignKey:NotificationChannelID" json:"notification_channel,omitempty"`
+
+	// Skills narrows the agent's toolbox for this source's alerts: when
+	// non-empty, only these skills (and the tools assigned to them) are
+	// enabled for the investigation. Empty means every enabled skill.
+	Skills []Skill `gorm:"many2many:alert_source_skills;" json:"skills,omitempty"`
 }
 
 func (AlertSourceInstance) TableName() string {
 	return "alert_source_instances"
 }
 
+// AlertSourceSkill is the many-to-many join row between AlertSourceInstance
+// and Skill, managed by GORM via the many2many:alert_source_skills tag.
+type AlertSourceSkill struct {
+	AlertSourceInstanceID uint      `gorm:"primaryKey" json:"alert_source_instance_id"`
+	SkillID               uint      `gorm:"primaryKey" json:"skill_id"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+func (AlertSourceSkill) TableName() string {
+	return "alert_source_skills"
+}
+
 // GetWebhookURL returns the webhook URL for this instance
 func (a *AlertSourceInstance) GetWebhookURL(baseURL string) string {
 	return baseURL + "/webhook/alert/" + a.UUID
@@ -100,8 +122,14 @@ const (
 	AlertStatusResolved AlertStatus = "resolved"
 )
 
-// GetSeverityEmoji returns an emoji for the alert severity
+// GetSeverityEmoji returns an emoji for the alert severity, honoring the
+// deployment's VocabularySettings.
 func GetSeverityEmoji(severity AlertSeverity) string {
+	return LoadVocabulary().SeverityEmoji(severity)
+}
+
+// defaultSeverityEmoji is the built-in emoji for severity.
+func defaultSeverityEmoji(severity AlertSeverity) string {
 	switch severity {
 	case AlertSeverityCritical:
 		return ":red_circle:"
@@ -0,0 +1,47 @@
+package database
+
+import "time"
+
+// ToolApprovalStatus is where a tool approval request stands.
+type ToolApprovalStatus string
+
+const (
+	ToolApprovalPending  ToolApprovalStatus = "pending"
+	ToolApprovalApproved ToolApprovalStatus = "approved"
+	ToolApprovalRejected ToolApprovalStatus = "rejected"
+	// ToolApprovalExpired is set when nobody decided before ExpiresAt; the
+	// tool call fails as if rejected but the investigation keeps running.
+	ToolApprovalExpired ToolApprovalStatus = "expired"
+)
+
+// ToolApproval is a human-in-the-loop gate on one destructive tool call.
+// The MCP gateway creates the row and holds the call until a human decides
+// (via Slack or the API) or the request expires; the API posts the request
+// to the incident's Slack thread and records the decision.
+type ToolApproval struct {
+	ID           uint               `gorm:"primaryKey" json:"id"`
+	UUID         string             `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
+	IncidentUUID string             `gorm:"size:36;not null;index" json:"incident_uuid"`
+	ToolName     string             `gorm:"size:128;not null" json:"tool_name"` // e.g. ssh.execute_command
+	Target       string             `gorm:"size:512" json:"target"`             // tool instance the call would touch
+	Detail       string             `gorm:"type:text" json:"detail"`            // the call's arguments as JSON
+	Status       ToolApprovalStatus `gorm:"size:16;not null;default:'pending';index" json:"status"`
+	DecidedBy    string             `gorm:"size:128" json:"decided_by,omitempty"`
+	Reason       string             `gorm:"type:text" json:"reason,omitempty"`
+	DecidedAt    *time.Time         `json:"decided_at,omitempty"`
+	ExpiresAt    time.Time          `gorm:"not null" json:"expires_at"`
+
+	// Slack message carrying the Approve/Reject buttons. NotifiedStatus is
+	// the status that message last showed ("" = not posted yet), so the
+	// sweep knows which messages still need updating.
+	SlackChannelID string             `gorm:"size:64" json:"-"`
+	SlackMessageTS string             `gorm:"size:64" json:"-"`
+	NotifiedStatus ToolApprovalStatus `gorm:"size:16" json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ToolApproval) TableName() string {
+	return "tool_approvals"
+}
@@ -0,0 +1,41 @@
+package database
+
+import "time"
+
+// Audit log actions. Actions are namespaced "<area>.<verb>" so the log can be
+// filtered by prefix.
+const (
+	AuditActionLoginSucceeded = "auth.login_succeeded"
+	AuditActionLoginFailed    = "auth.login_failed"
+	AuditActionLoginBlocked   = "auth.login_blocked"
+	AuditActionLoginUnlocked  = "auth.login_unlocked"
+
+	AuditActionConfigCreate = "config.create"
+	AuditActionConfigUpdate = "config.update"
+	AuditActionConfigDelete = "config.delete"
+)
+
+// Audit log outcomes.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// AuditLog is an append-only record of a security-relevant action. Rows are
+// never updated; retention is the operator's responsibility.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Action     string    `gorm:"size:64;not null;index" json:"action"`
+	Actor      string    `gorm:"size:255;index" json:"actor"`                // Username (or attempted username) behind the action
+	RemoteIP   string    `gorm:"size:64" json:"remote_ip"`                   // Client address as seen by api.ClientIP
+	Target     string    `gorm:"size:255;index" json:"target"`               // Entity acted upon, when distinct from the actor
+	EntityType string    `gorm:"size:64;index" json:"entity_type,omitempty"` // Kind of Target for config changes, e.g. "skill" or "llm_settings"
+	Outcome    string    `gorm:"size:16;not null" json:"outcome"`            // success | failure | denied
+	Details    JSONB     `gorm:"type:jsonb" json:"details,omitempty"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
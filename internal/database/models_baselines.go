@@ -0,0 +1,26 @@
+package database
+
+import "time"
+
+// AlertBaseline is the weekly volume baseline of one alert name, recomputed
+// by the alert baseline job. Volumes count alert rows, so they only reach as
+// far back as incident retention keeps alerts.
+type AlertBaseline struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	AlertName string `gorm:"size:255;uniqueIndex;not null" json:"alert_name"`
+	// WeeklyAverage is the mean number of alerts per week over the baseline
+	// weeks before the current one.
+	WeeklyAverage float64 `json:"weekly_average"`
+	BaselineWeeks int     `json:"baseline_weeks"`
+	// CurrentWeek counts the alerts of the last seven days.
+	CurrentWeek int64 `json:"current_week"`
+	// Ratio is CurrentWeek over WeeklyAverage; 0 without a baseline.
+	Ratio float64 `json:"ratio"`
+	// Deviating is set while the current week is far above the baseline.
+	Deviating  bool      `gorm:"index" json:"deviating"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+func (AlertBaseline) TableName() string {
+	return "alert_baselines"
+}
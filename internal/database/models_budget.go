@@ -0,0 +1,52 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// BudgetSettings caps LLM spend (singleton). Each limit counts the
+// token_usage rows of the current UTC day or calendar month; zero leaves
+// that limit off. Once any limit is reached new investigations are declined
+// with status budget_exceeded until the window rolls over. Cost limits only
+// see runs whose model has a price in the model price table.
+type BudgetSettings struct {
+	ID                  uint    `gorm:"primaryKey" json:"id"`
+	SingletonKey        string  `gorm:"uniqueIndex;default:'default';not null" json:"-"`
+	Enabled             bool    `gorm:"default:false" json:"enabled"`
+	DailyTokenLimit     int64   `gorm:"default:0" json:"daily_token_limit"`
+	MonthlyTokenLimit   int64   `gorm:"default:0" json:"monthly_token_limit"`
+	DailyCostLimitUSD   float64 `gorm:"default:0" json:"daily_cost_limit_usd"`
+	MonthlyCostLimitUSD float64 `gorm:"default:0" json:"monthly_cost_limit_usd"`
+	// NotificationChannelUUID receives the "budget exceeded" notice; empty
+	// uses the default Slack post channel.
+	NotificationChannelUUID string    `gorm:"size:36" json:"notification_channel_uuid"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+func (BudgetSettings) TableName() string {
+	return "budget_settings"
+}
+
+// GetOrCreateBudgetSettings retrieves or creates the budget settings
+// (singleton), falling back to a plain read if a concurrent caller inserted
+// the row first.
+func GetOrCreateBudgetSettings() (*BudgetSettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var settings BudgetSettings
+	defaults := &BudgetSettings{SingletonKey: "default"}
+	if err := DB.Where(BudgetSettings{SingletonKey: "default"}).Attrs(defaults).FirstOrCreate(&settings).Error; err != nil {
+		if rerr := DB.Where(BudgetSettings{SingletonKey: "default"}).First(&settings).Error; rerr != nil {
+			return nil, fmt.Errorf("%w (retry: %v)", err, rerr)
+		}
+	}
+	return &settings, nil
+}
+
+// UpdateBudgetSettings saves the budget settings.
+func UpdateBudgetSettings(settings *BudgetSettings) error {
+	return DB.Save(settings).Error
+}
@@ -42,7 +42,7 @@ type Integration struct {
 	UUID        string            `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
 	Provider    MessagingProvider `gorm:"type:varchar(50);not null;index" json:"provider"`
 	Name        string            `gorm:"size:128;not null" json:"name"`
-	Credentials JSONB             `gorm:"type:jsonb" json:"credentials"`
+	Credentials JSONB             `gorm:"type:jsonb;serializer:secret" json:"credentials"`
 	Enabled     bool              `gorm:"default:true" json:"enabled"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
@@ -75,9 +75,96 @@ type Channel struct {
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 
+	// Slack presentation overrides. Empty reactions fall back to the
+	// DefaultSlackReaction* constants; SlackReactionNone disables one.
+	ReactionAlert     string                 `gorm:"size:64" json:"reaction_alert"`
+	ReactionWorking   string                 `gorm:"size:64" json:"reaction_working"`
+	ReactionSuccess   string                 `gorm:"size:64" json:"reaction_success"`
+	ReactionFailure   string                 `gorm:"size:64" json:"reaction_failure"`
+	ProgressVerbosity SlackProgressVerbosity `gorm:"size:16" json:"progress_verbosity"`
+
 	Integration Integration `gorm:"foreignKey:IntegrationID" json:"integration,omitempty"`
 }
 
 func (Channel) TableName() string {
 	return "channels"
 }
+
+// Default Slack reactions, used for any role a channel does not override.
+const (
+	DefaultSlackReactionAlert   = "rotating_light"
+	DefaultSlackReactionWorking = "hourglass_flowing_sand"
+	DefaultSlackReactionSuccess = "white_check_mark"
+	DefaultSlackReactionFailure = "x"
+)
+
+// SlackReactionNone is stored in a reaction override to suppress that
+// reaction entirely.
+const SlackReactionNone = "none"
+
+// SlackReactions is a resolved reaction set. An empty field means "do not
+// react" for that role.
+type SlackReactions struct {
+	Alert   string // on the alert message akmatori posts
+	Working string // on the thread root while the agent runs
+	Success string // on the thread root when the run succeeds
+	Failure string // on the thread root when the run fails
+}
+
+// SlackProgressVerbosity controls how much of a running investigation is
+// mirrored into its Slack thread.
+type SlackProgressVerbosity string
+
+const (
+	// SlackProgressOff shows only the typing banner and reactions.
+	SlackProgressOff SlackProgressVerbosity = "off"
+	// SlackProgressStatus streams the agent's latest reasoning line into the
+	// typing banner. This is the default.
+	SlackProgressStatus SlackProgressVerbosity = "status"
+	// SlackProgressThread additionally posts reasoning lines as thread
+	// replies, throttled so a long run does not flood the thread.
+	SlackProgressThread SlackProgressVerbosity = "thread"
+)
+
+// IsValid reports whether v is a known verbosity. The empty string is valid
+// and means "use the default".
+func (v SlackProgressVerbosity) IsValid() bool {
+	switch v {
+	case "", SlackProgressOff, SlackProgressStatus, SlackProgressThread:
+		return true
+	}
+	return false
+}
+
+// EffectiveSlackReactions resolves the channel's reaction overrides against
+// the defaults. Safe to call on a nil channel, which yields the defaults.
+func (c *Channel) EffectiveSlackReactions() SlackReactions {
+	if c == nil {
+		c = &Channel{}
+	}
+	return SlackReactions{
+		Alert:   resolveSlackReaction(c.ReactionAlert, DefaultSlackReactionAlert),
+		Working: resolveSlackReaction(c.ReactionWorking, DefaultSlackReactionWorking),
+		Success: resolveSlackReaction(c.ReactionSuccess, DefaultSlackReactionSuccess),
+		Failure: resolveSlackReaction(c.ReactionFailure, DefaultSlackReactionFailure),
+	}
+}
+
+// EffectiveProgressVerbosity returns the channel's progress verbosity,
+// defaulting to SlackProgressStatus. Safe to call on a nil channel.
+func (c *Channel) EffectiveProgressVerbosity() SlackProgressVerbosity {
+	if c == nil || c.ProgressVerbosity == "" || !c.ProgressVerbosity.IsValid() {
+		return SlackProgressStatus
+	}
+	return c.ProgressVerbosity
+}
+
+func resolveSlackReaction(override, fallback string) string {
+	switch override {
+	case "":
+		return fallback
+	case SlackReactionNone:
+		return ""
+	}
+	return override
+}
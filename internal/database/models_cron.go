@@ -18,6 +18,9 @@ const (
 // Tools is a per-cron allowlist that overrides the global allowlist used by
 // alert-driven incidents — each cron declares exactly which infrastructure
 // tools its agent run may call.
+//
+// Skills optionally pins operator skills the run may use on top of the
+// cron-agent root skill; their assigned tools join the allowlist.
 type CronJob struct {
 	ID            uint       `gorm:"primaryKey" json:"id"`
 	UUID          string     `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
@@ -40,6 +43,7 @@ type CronJob struct {
 
 	Channel *Channel       `gorm:"foreignKey:ChannelID" json:"channel,omitempty"`
 	Tools   []ToolInstance `gorm:"many2many:cron_job_tools;" json:"tools,omitempty"`
+	Skills  []Skill        `gorm:"many2many:cron_job_skills;" json:"skills,omitempty"`
 }
 
 func (CronJob) TableName() string {
@@ -59,3 +63,15 @@ type CronJobTool struct {
 func (CronJobTool) TableName() string {
 	return "cron_job_tools"
 }
+
+// CronJobSkill is the many-to-many join row between CronJob and Skill,
+// managed by GORM via the many2many:cron_job_skills tag.
+type CronJobSkill struct {
+	CronJobID uint      `gorm:"primaryKey" json:"cron_job_id"`
+	SkillID   uint      `gorm:"primaryKey" json:"skill_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (CronJobSkill) TableName() string {
+	return "cron_job_skills"
+}
@@ -22,6 +22,14 @@ const (
 	// to the survivor referenced by MergedIntoUUID. Merged incidents are
 	// excluded from all correlation candidate pools.
 	IncidentStatusMerged IncidentStatus = "merged"
+	// IncidentStatusBudgetExceeded marks an incident that was declined
+	// without an investigation because an LLM budget (see BudgetSettings)
+	// was spent.
+	IncidentStatusBudgetExceeded IncidentStatus = "budget_exceeded"
+	// IncidentStatusCancelled marks an incident whose investigation an
+	// operator stopped before it finished; the log streamed until then is
+	// kept.
+	IncidentStatusCancelled IncidentStatus = "cancelled"
 )
 
 // IncidentSourceKind enumerates the trigger kinds that can spawn an incident.
@@ -31,6 +39,10 @@ const (
 	IncidentSourceKindSlackMention = "slack_mention"
 	IncidentSourceKindManual       = "manual"
 	IncidentSourceKindProposal     = "proposal"
+	// IncidentSourceKindSelfMonitor marks meta incidents Akmatori opens about
+	// its own health (webhook failures, worker down, LLM auth). They carry no
+	// agent run.
+	IncidentSourceKindSelfMonitor = "self_monitor"
 )
 
 // Incident represents a spawned incident manager session
@@ -46,7 +58,7 @@ type Incident struct {
 	Context         JSONB          `gorm:"type:jsonb" json:"context"` // Event context (message, alert details, etc.)
 	SessionID       string         `gorm:"index" json:"session_id"`   // Agent session ID
 	WorkingDir      string         `json:"working_dir"`               // Path to incident working directory
-	FullLog         string         `gorm:"type:text" json:"full_log"` // Complete agent output log (reasoning, tool calls, etc.)
+	FullLog         string         `gorm:"-" json:"full_log"`         // Complete agent output log (reasoning, tool calls, etc.), stored in incident_log_chunks
 	Response        string         `gorm:"type:text" json:"response"` // Final response/output to user
 	TokensUsed      int            `json:"tokens_used"`               // Total tokens used (input + output)
 	ExecutionTimeMs int64          `json:"execution_time_ms"`         // Execution time in milliseconds
@@ -79,13 +91,63 @@ type Incident struct {
 	// formatting-rule match dimension.
 	LastSkillUsed string `gorm:"size:64" json:"last_skill_used,omitempty"`
 
+	// ToolCalls is the number of tool executions the agent finished during
+	// the investigation, counted from the run's log on completion.
+	ToolCalls int `gorm:"default:0" json:"tool_calls"`
+
+	// Task is the prompt the investigation was started with (guidance
+	// included), recorded when it is sent to the agent worker so a failed
+	// run can be retried.
+	Task string `gorm:"type:text" json:"-"`
+
+	// Attempts counts the investigation's runs: 1 for the original plus one
+	// per retry.
+	Attempts int `gorm:"not null;default:1" json:"attempts"`
+
+	// Confidence is the agent's 0-1 confidence in its result, taken from the
+	// [CONFIDENCE] block of its final response. Nil when it gave none.
+	Confidence       *float64 `json:"confidence,omitempty"`
+	ConfidenceReason string   `gorm:"type:text" json:"confidence_reason,omitempty"`
+	// ConfidenceAction is what the score triggered on an alert
+	// investigation: "review_requested" or "auto_closed". Empty for neither.
+	ConfidenceAction string `gorm:"size:32" json:"confidence_action,omitempty"`
+
+	// EstimatedCostUSD prices TokensUsed with the model price table at
+	// completion time. Nil when the run used no tokens or the active model
+	// has no price configured.
+	EstimatedCostUSD *float64 `json:"estimated_cost_usd,omitempty"`
+
+	// WorkspaceBaseline is the workspace file listing (path → size and
+	// sha256) taken when the incident was spawned; the change manifest is
+	// diffed against it on completion.
+	WorkspaceBaseline JSONB `gorm:"type:jsonb" json:"-"`
+
 	// AlertCount is not stored; populated by API handlers via COUNT query.
 	AlertCount int64 `gorm:"-" json:"alert_count"`
 
+	// Relations is not stored; populated by the detail endpoint from
+	// incident_links.
+	Relations *IncidentRelations `gorm:"-" json:"relations,omitempty"`
+
+	// Changes is not stored; populated by the detail endpoint from
+	// incident_changes.
+	Changes []IncidentChange `gorm:"-" json:"changes,omitempty"`
+
 	// FirstSeen, LastSeen, and Trend are transient; populated by the list endpoint.
 	FirstSeen *time.Time `gorm:"-" json:"first_seen,omitempty"`
 	LastSeen  *time.Time `gorm:"-" json:"last_seen,omitempty"`
 	Trend     []int      `gorm:"-" json:"trend,omitempty"`
+
+	// StatusLabel and StatusColor are transient; set by the list and detail
+	// endpoints when VocabularySettings overrides the status.
+	StatusLabel string `gorm:"-" json:"status_label,omitempty"`
+	StatusColor string `gorm:"-" json:"status_color,omitempty"`
+}
+
+// ApplyVocabulary sets StatusLabel and StatusColor from v.
+func (i *Incident) ApplyVocabulary(v *Vocabulary) {
+	i.StatusLabel = v.StatusLabel(string(i.Status))
+	i.StatusColor = v.StatusColor(string(i.Status))
 }
 
 // BeforeCreate hook to set StartedAt
@@ -96,6 +158,148 @@ func (i *Incident) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// AfterCreate stores a log the incident was created with in
+// incident_log_chunks.
+func (i *Incident) AfterCreate(tx *gorm.DB) error {
+	if i.FullLog == "" {
+		return nil
+	}
+	return ReplaceIncidentLog(tx.Session(&gorm.Session{NewDB: true}), i.UUID, i.FullLog)
+}
+
 func (Incident) TableName() string {
 	return "incidents"
 }
+
+// IncidentLogChunk is one piece of an incident's agent log. The log is kept
+// out of the incidents row so listing and updating incidents never moves it;
+// writers append chunks and readers page through them by character offset.
+type IncidentLogChunk struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	IncidentUUID string    `gorm:"size:36;not null;uniqueIndex:idx_incident_log_chunks_seq,priority:1" json:"incident_uuid"`
+	Seq          int       `gorm:"not null;uniqueIndex:idx_incident_log_chunks_seq,priority:2" json:"seq"`
+	StartOffset  int       `gorm:"not null" json:"start_offset"` // Position of Content in the whole log, in characters
+	Length       int       `gorm:"not null" json:"length"`       // Length of Content in characters
+	Content      string    `gorm:"type:text" json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (IncidentLogChunk) TableName() string {
+	return "incident_log_chunks"
+}
+
+// Incident link kinds.
+const (
+	// IncidentLinkParent makes FromUUID a child of ToUUID, e.g. a downstream
+	// symptom of a cascading failure. An incident has at most one parent.
+	IncidentLinkParent = "parent"
+	// IncidentLinkRelated is a symmetric "see also" link. The pair is stored
+	// once, with the lexically smaller UUID in FromUUID.
+	IncidentLinkRelated = "related"
+)
+
+// Incident link origins.
+const (
+	IncidentLinkOriginManual     = "manual"
+	IncidentLinkOriginCorrelator = "correlator"
+)
+
+// IncidentLink connects two incidents as parent/child or related.
+type IncidentLink struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	FromUUID  string    `gorm:"size:36;not null;uniqueIndex:idx_incident_links_pair" json:"from_uuid"`
+	ToUUID    string    `gorm:"size:36;not null;uniqueIndex:idx_incident_links_pair;index" json:"to_uuid"`
+	Kind      string    `gorm:"size:16;not null" json:"kind"`
+	Origin    string    `gorm:"size:16;not null;default:'manual'" json:"origin"`
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (IncidentLink) TableName() string {
+	return "incident_links"
+}
+
+// IncidentLinkRef is one end of a link as seen from the other incident.
+type IncidentLinkRef struct {
+	UUID      string         `json:"uuid"`
+	Title     string         `json:"title"`
+	Status    IncidentStatus `json:"status"`
+	Origin    string         `json:"origin"`
+	Reason    string         `json:"reason,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// IncidentRelations lists an incident's parent, children and related
+// incidents.
+type IncidentRelations struct {
+	Parent   *IncidentLinkRef  `json:"parent,omitempty"`
+	Children []IncidentLinkRef `json:"children"`
+	Related  []IncidentLinkRef `json:"related"`
+}
+
+// IncidentEventType identifies an incident timeline entry.
+type IncidentEventType string
+
+const (
+	IncidentEventStatusChange  IncidentEventType = "status_change"
+	IncidentEventAlertAttached IncidentEventType = "alert_attached"
+	IncidentEventCommand       IncidentEventType = "command"
+	IncidentEventSlackPost     IncidentEventType = "slack_post"
+	IncidentEventNote          IncidentEventType = "note"
+	IncidentEventApproval      IncidentEventType = "approval"
+)
+
+// IncidentEvent is one entry in an incident's timeline: a structured record
+// of what happened when, next to the free-form full_log.
+type IncidentEvent struct {
+	ID           uint              `gorm:"primaryKey" json:"id"`
+	IncidentUUID string            `gorm:"size:36;not null;index:idx_incident_events_timeline,priority:1" json:"incident_uuid"`
+	Type         IncidentEventType `gorm:"size:32;not null" json:"type"`
+	Summary      string            `gorm:"type:text" json:"summary"`
+	Details      JSONB             `gorm:"type:jsonb" json:"details,omitempty"`
+	Actor        string            `gorm:"size:128" json:"actor,omitempty"` // who caused it: "agent", "system", a username
+	OccurredAt   time.Time         `gorm:"not null;index:idx_incident_events_timeline,priority:2" json:"occurred_at"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+func (IncidentEvent) TableName() string {
+	return "incident_events"
+}
+
+// IncidentChangeAction is what an investigation did to a file or host.
+type IncidentChangeAction string
+
+const (
+	IncidentChangeCreated  IncidentChangeAction = "created"
+	IncidentChangeModified IncidentChangeAction = "modified"
+	IncidentChangeDeleted  IncidentChangeAction = "deleted"
+	// IncidentChangeCommand is a write-capable command run on a remote host;
+	// its effect on the filesystem is unknown, so Path is empty and Detail
+	// holds the command.
+	IncidentChangeCommand IncidentChangeAction = "command"
+)
+
+// IncidentChangeLocationWorkspace is the Location of changes to the
+// incident's own working directory. Remote changes use the host name.
+const IncidentChangeLocationWorkspace = "workspace"
+
+// IncidentChange is one entry of an incident's change manifest: a file the
+// agent created, modified or deleted in its workspace (diffed on
+// completion), or a write on a remote host recorded by the MCP gateway as
+// the tool call ran. Kept for audit and rollback planning.
+type IncidentChange struct {
+	ID           uint                 `gorm:"primaryKey" json:"id"`
+	IncidentUUID string               `gorm:"size:36;not null;index" json:"incident_uuid"`
+	Location     string               `gorm:"size:255;not null" json:"location"`
+	Path         string               `gorm:"type:text" json:"path,omitempty"`
+	Action       IncidentChangeAction `gorm:"size:16;not null" json:"action"`
+	Tool         string               `gorm:"size:64" json:"tool,omitempty"`
+	Detail       string               `gorm:"type:text" json:"detail,omitempty"`
+	SizeBytes    int64                `json:"size_bytes"`
+	SHA256       string               `gorm:"column:sha256;size:64" json:"sha256,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+func (IncidentChange) TableName() string {
+	return "incident_changes"
+}
@@ -0,0 +1,16 @@
+package database
+
+import "time"
+
+// ShortLink maps a short code to a path in the UI, served as /i/{code}. Only
+// the path is stored, so a short link keeps working when the base URL
+// changes.
+type ShortLink struct {
+	Code       string    `gorm:"primaryKey;size:16" json:"code"`
+	TargetPath string    `gorm:"size:512;not null;uniqueIndex" json:"target_path"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (ShortLink) TableName() string {
+	return "short_links"
+}
@@ -0,0 +1,25 @@
+package database
+
+import "time"
+
+// NotificationTemplate overrides the built-in text of one kind of outbound
+// notification (alert banner, resolve note, merge note, ...) for one locale.
+// Body is a Go text/template rendered against services.NotificationData.
+//
+// Locale "" is the catch-all override; a non-empty locale (e.g. "de",
+// "pt-BR") is used when GeneralSettings.NotificationLocale selects it. When
+// no enabled override matches, the built-in template is used.
+type NotificationTemplate struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	Kind   string `gorm:"size:64;not null;uniqueIndex:idx_notification_templates_kind_locale,priority:1" json:"kind"`
+	Locale string `gorm:"size:16;not null;default:'';uniqueIndex:idx_notification_templates_kind_locale,priority:2" json:"locale"`
+	Body   string `gorm:"type:text;not null" json:"body"`
+	// No gorm default tag so an explicit Enabled=false persists.
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
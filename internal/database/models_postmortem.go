@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PostmortemTimelineEntry is one line of a postmortem's timeline.
+type PostmortemTimelineEntry struct {
+	Time  string `json:"time"` // as the model wrote it, usually RFC 3339 or HH:MM UTC
+	Event string `json:"event"`
+}
+
+// PostmortemActionItem is a follow-up the postmortem recommends.
+type PostmortemActionItem struct {
+	Action   string `json:"action"`
+	Owner    string `json:"owner,omitempty"`
+	Priority string `json:"priority,omitempty"` // "high", "medium" or "low"
+}
+
+// PostmortemTimeline is stored as a JSONB array.
+type PostmortemTimeline []PostmortemTimelineEntry
+
+// PostmortemActionItems is stored as a JSONB array.
+type PostmortemActionItems []PostmortemActionItem
+
+// Scan implements the sql.Scanner interface
+func (t *PostmortemTimeline) Scan(value interface{}) error { return scanJSONArray(value, t) }
+
+// Value implements the driver.Valuer interface
+func (t PostmortemTimeline) Value() (driver.Value, error) { return json.Marshal(t) }
+
+// Scan implements the sql.Scanner interface
+func (a *PostmortemActionItems) Scan(value interface{}) error { return scanJSONArray(value, a) }
+
+// Value implements the driver.Valuer interface
+func (a PostmortemActionItems) Value() (driver.Value, error) { return json.Marshal(a) }
+
+func scanJSONArray(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+}
+
+// IncidentReport is the postmortem generated for an incident from its
+// timeline, alerts and final response. There is at most one per incident;
+// regenerating replaces it.
+type IncidentReport struct {
+	ID           uint                  `gorm:"primaryKey" json:"id"`
+	IncidentUUID string                `gorm:"size:36;not null;uniqueIndex" json:"incident_uuid"`
+	Title        string                `gorm:"size:255" json:"title"`
+	Summary      string                `gorm:"type:text" json:"summary"`
+	Impact       string                `gorm:"type:text" json:"impact"`
+	RootCause    string                `gorm:"type:text" json:"root_cause"`
+	Timeline     PostmortemTimeline    `gorm:"type:jsonb" json:"timeline"`
+	ActionItems  PostmortemActionItems `gorm:"type:jsonb" json:"action_items"`
+	GeneratedBy  string                `gorm:"size:128" json:"generated_by,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+func (IncidentReport) TableName() string {
+	return "incident_reports"
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModelPrice is one row of the operator-maintained LLM price table used to
+// estimate what an investigation cost.
+//
+// Model is matched against the active LLM config's model name: an exact
+// (case-insensitive) name wins, then the longest pattern ending in "*" that
+// prefixes it, and a bare "*" row prices any model not otherwise listed.
+// The price is blended per million tokens because the agent worker reports
+// a single token total (input + output) per run.
+type ModelPrice struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	Model               string    `gorm:"size:100;not null;uniqueIndex" json:"model"`
+	USDPerMillionTokens float64   `gorm:"not null" json:"usd_per_million_tokens"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func (ModelPrice) TableName() string {
+	return "model_prices"
+}
+
+// ListModelPrices returns the price table ordered by model pattern.
+func ListModelPrices() ([]ModelPrice, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var prices []ModelPrice
+	if err := DB.Order("model asc").Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// ReplaceModelPrices swaps the whole price table for prices in a single
+// transaction and returns the stored rows.
+func ReplaceModelPrices(prices []ModelPrice) ([]ModelPrice, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&ModelPrice{}).Error; err != nil {
+			return err
+		}
+		if len(prices) == 0 {
+			return nil
+		}
+		return tx.Create(&prices).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ListModelPrices()
+}
+
+// MatchModelPrice picks the price for model from prices using the rules
+// documented on ModelPrice.
+func MatchModelPrice(prices []ModelPrice, model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	var best ModelPrice
+	bestLen := -1
+	for _, p := range prices {
+		pattern := strings.ToLower(strings.TrimSpace(p.Model))
+		if pattern == model && model != "" {
+			return p, true
+		}
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if !ok || !strings.HasPrefix(model, prefix) {
+			continue
+		}
+		if len(prefix) > bestLen {
+			best, bestLen = p, len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// EstimateCostUSD prices tokensUsed at the active LLM model's rate. The
+// boolean is false when there is nothing to price or no table row matches,
+// so callers can omit the estimate rather than report $0.
+func EstimateCostUSD(tokensUsed int) (float64, bool) {
+	return EstimateModelCostUSD(ActiveLLMModel(), tokensUsed)
+}
+
+// EstimateModelCostUSD prices tokensUsed at model's rate, with the same
+// result contract as EstimateCostUSD.
+func EstimateModelCostUSD(model string, tokensUsed int) (float64, bool) {
+	if DB == nil || tokensUsed <= 0 {
+		return 0, false
+	}
+	prices, err := ListModelPrices()
+	if err != nil || len(prices) == 0 {
+		return 0, false
+	}
+	price, ok := MatchModelPrice(prices, model)
+	if !ok {
+		return 0, false
+	}
+	return float64(tokensUsed) / 1_000_000 * price.USDPerMillionTokens, true
+}
+
+// ActiveLLMModel returns the model name of the active LLM config, or ""
+// when none can be loaded.
+func ActiveLLMModel() string {
+	if DB == nil {
+		return ""
+	}
+	settings, err := GetLLMSettings()
+	if err != nil {
+		return ""
+	}
+	return settings.Model
+}
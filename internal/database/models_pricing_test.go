@@ -0,0 +1,44 @@
+package database
+
+import "testing"
+
+func TestMatchModelPrice(t *testing.T) {
+	prices := []ModelPrice{
+		{Model: "*", USDPerMillionTokens: 1},
+		{Model: "gpt-5*", USDPerMillionTokens: 5},
+		{Model: "gpt-5-mini*", USDPerMillionTokens: 0.5},
+		{Model: "claude-sonnet-4-5", USDPerMillionTokens: 6},
+	}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"claude-sonnet-4-5", 6},
+		{"Claude-Sonnet-4-5", 6},
+		{"gpt-5.2", 5},
+		{"gpt-5-mini-2025", 0.5},
+		{"llama3", 1},
+		{"", 1},
+	}
+	for _, tt := range tests {
+		got, ok := MatchModelPrice(prices, tt.model)
+		if !ok || got.USDPerMillionTokens != tt.want {
+			t.Errorf("MatchModelPrice(%q) = %v, %v; want %v", tt.model, got.USDPerMillionTokens, ok, tt.want)
+		}
+	}
+
+	if _, ok := MatchModelPrice(prices[1:], "llama3"); ok {
+		t.Error("expected no match without a fallback row")
+	}
+}
+
+func TestEstimateCostUSD_NilDB(t *testing.T) {
+	origDB := DB
+	DB = nil
+	defer func() { DB = origDB }()
+
+	if _, ok := EstimateCostUSD(1000); ok {
+		t.Error("expected no estimate without a database")
+	}
+}
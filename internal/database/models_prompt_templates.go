@@ -0,0 +1,26 @@
+package database
+
+import "time"
+
+// PromptTemplate replaces the built-in investigation prompt for alerts from
+// one alert source type, for investigations scoped to one skill, or both.
+// Body is a Go text/template rendered against
+// services.InvestigationPromptData; simple {{alert_name}}-style placeholders
+// are accepted as well.
+//
+// SourceType "" and Skill "" match any alert. The most specific enabled
+// template wins; when none matches, the built-in prompt is used.
+type PromptTemplate struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	SourceType string `gorm:"size:64;not null;default:'';uniqueIndex:idx_prompt_templates_scope,priority:1" json:"source_type"`
+	Skill      string `gorm:"size:64;not null;default:'';uniqueIndex:idx_prompt_templates_scope,priority:2" json:"skill"`
+	Body       string `gorm:"type:text;not null" json:"body"`
+	// No gorm default tag so an explicit Enabled=false persists.
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,38 @@
+package database
+
+import "time"
+
+// RemediationPlanStatus is where a proposed remediation plan stands.
+type RemediationPlanStatus string
+
+const (
+	RemediationPlanPending  RemediationPlanStatus = "pending"
+	RemediationPlanApproved RemediationPlanStatus = "approved"
+	RemediationPlanRejected RemediationPlanStatus = "rejected"
+)
+
+// RemediationPlan is the fix an agent proposed at the end of the first phase
+// of a two-phase investigation. The incident waits in "diagnosed" until a
+// human approves the plan (phase two resumes the same agent session to
+// carry it out) or rejects it (nothing is changed).
+type RemediationPlan struct {
+	ID           uint                  `gorm:"primaryKey" json:"id"`
+	UUID         string                `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
+	IncidentUUID string                `gorm:"size:36;not null;index" json:"incident_uuid"`
+	Plan         string                `gorm:"type:text;not null" json:"plan"`
+	Status       RemediationPlanStatus `gorm:"size:16;not null;default:'pending';index" json:"status"`
+	DecidedBy    string                `gorm:"size:128" json:"decided_by,omitempty"`
+	Reason       string                `gorm:"type:text" json:"reason,omitempty"`
+	DecidedAt    *time.Time            `json:"decided_at,omitempty"`
+
+	// Slack message carrying the Approve/Reject buttons, when one was posted.
+	SlackChannelID string `gorm:"size:64" json:"-"`
+	SlackMessageTS string `gorm:"size:64" json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (RemediationPlan) TableName() string {
+	return "remediation_plans"
+}
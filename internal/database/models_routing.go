@@ -0,0 +1,78 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// RoutingAction says what happens to an alert a routing rule matches.
+type RoutingAction string
+
+const (
+	// RoutingActionInvestigate opens an incident and runs the agent (default).
+	RoutingActionInvestigate RoutingAction = "investigate"
+	// RoutingActionRecord opens an incident and notifies, but skips the
+	// investigation.
+	RoutingActionRecord RoutingAction = "record"
+)
+
+// AlertSeverityList is stored as a JSONB array.
+type AlertSeverityList []AlertSeverity
+
+// Scan implements the sql.Scanner interface
+func (l *AlertSeverityList) Scan(value interface{}) error { return scanJSONArray(value, l) }
+
+// Value implements the driver.Valuer interface
+func (l AlertSeverityList) Value() (driver.Value, error) { return json.Marshal(l) }
+
+// RoutingRule picks how a firing alert from an alert source is handled.
+// Enabled rules are evaluated in Position order (then ID) and the first match
+// wins; an alert no rule matches gets the source's own settings.
+//
+// Matchers are ANDed and an empty matcher matches everything. AlertName,
+// TargetHost and Labels use the same case-insensitive globs as silences.
+//
+// Actions left empty fall back to the source: no NotificationChannelID
+// posts to the source's channel, no Skills uses the source's skills, and an
+// empty Priority schedules by the alert's own severity.
+type RoutingRule struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	UUID        string `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
+	Name        string `gorm:"size:128;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Enabled     bool   `gorm:"default:true" json:"enabled"`
+	Position    int    `gorm:"index" json:"position"`
+
+	SourceUUID string            `gorm:"size:36" json:"source_uuid,omitempty"`
+	Severities AlertSeverityList `gorm:"type:jsonb" json:"severities,omitempty"`
+	AlertName  string            `gorm:"size:255" json:"alert_name,omitempty"`
+	TargetHost string            `gorm:"size:255" json:"target_host,omitempty"`
+	Labels     JSONB             `gorm:"type:jsonb" json:"labels,omitempty"`
+
+	Action                RoutingAction `gorm:"size:32;not null;default:'investigate'" json:"action"`
+	Priority              AlertSeverity `gorm:"size:32" json:"priority,omitempty"`
+	NotificationChannelID *uint         `json:"notification_channel_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	NotificationChannel *Channel `gorm:"foreignKey:NotificationChannelID" json:"notification_channel,omitempty"`
+	Skills              []Skill  `gorm:"many2many:routing_rule_skills;" json:"skills,omitempty"`
+}
+
+func (RoutingRule) TableName() string {
+	return "routing_rules"
+}
+
+// RoutingRuleSkill is the many-to-many join row between RoutingRule and
+// Skill, managed by GORM via the many2many:routing_rule_skills tag.
+type RoutingRuleSkill struct {
+	RoutingRuleID uint      `gorm:"primaryKey" json:"routing_rule_id"`
+	SkillID       uint      `gorm:"primaryKey" json:"skill_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (RoutingRuleSkill) TableName() string {
+	return "routing_rule_skills"
+}
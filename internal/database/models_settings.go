@@ -1,6 +1,8 @@
 package database
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"strings"
 	"time"
 )
@@ -14,9 +16,9 @@ import (
 // slack_settings table drop is deferred to a follow-up release.
 type SlackSettings struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
-	BotToken      string    `gorm:"type:text" json:"bot_token"`
-	SigningSecret string    `gorm:"type:text" json:"signing_secret"`
-	AppToken      string    `gorm:"type:text" json:"app_token"`
+	BotToken      string    `gorm:"type:text;serializer:secret" json:"bot_token"`
+	SigningSecret string    `gorm:"type:text;serializer:secret" json:"signing_secret"`
+	AppToken      string    `gorm:"type:text;serializer:secret" json:"app_token"`
 	AlertsChannel string    `gorm:"type:varchar(255)" json:"alerts_channel"` // Deprecated: migrated into channels.
 	Enabled       bool      `gorm:"default:false" json:"enabled"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -49,6 +51,9 @@ const (
 	LLMProviderNvidiaNIM  LLMProvider = "nvidia"
 	LLMProviderMiniMax    LLMProvider = "minimax"
 	LLMProviderAntLing    LLMProvider = "ant-ling"
+	// LLMProviderSelfHosted is an on-prem OpenAI-compatible server such as
+	// vLLM or Ollama. The API key is optional; BaseURL and Model are not.
+	LLMProviderSelfHosted LLMProvider = "self-hosted"
 )
 
 // ValidLLMProviders returns all valid LLM provider values
@@ -62,6 +67,7 @@ func ValidLLMProviders() []LLMProvider {
 		LLMProviderNvidiaNIM,
 		LLMProviderMiniMax,
 		LLMProviderAntLing,
+		LLMProviderSelfHosted,
 	}
 }
 
@@ -84,6 +90,8 @@ func ProviderDisplayName(p LLMProvider) string {
 		return "MiniMax"
 	case LLMProviderAntLing:
 		return "Ant Ling"
+	case LLMProviderSelfHosted:
+		return "Self-hosted"
 	default:
 		return string(p)
 	}
@@ -142,18 +150,23 @@ type LLMSettings struct {
 	ID            uint          `gorm:"primaryKey" json:"id"`
 	Name          string        `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
 	Provider      LLMProvider   `gorm:"type:varchar(50);index;not null" json:"provider"`
-	APIKey        string        `gorm:"type:text" json:"api_key"`
+	APIKey        string        `gorm:"type:text;serializer:secret" json:"api_key"`
 	Model         string        `gorm:"type:varchar(100)" json:"model"`
 	ThinkingLevel ThinkingLevel `gorm:"type:varchar(50);default:'medium'" json:"thinking_level"`
 	BaseURL       string        `gorm:"type:text" json:"base_url"`
+	ContextWindow int           `gorm:"default:0" json:"context_window"` // Model context window in tokens; 0 uses the provider's default
 	Enabled       bool          `gorm:"default:false" json:"enabled"`
 	Active        bool          `gorm:"default:false" json:"active"`
 	CreatedAt     time.Time     `json:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
-// IsConfigured returns true if the LLM provider has an API key set
+// IsConfigured returns true if the LLM provider has an API key set, or, for
+// a self-hosted provider, an endpoint and model.
 func (l *LLMSettings) IsConfigured() bool {
+	if l.Provider == LLMProviderSelfHosted {
+		return l.BaseURL != "" && l.Model != ""
+	}
 	return l.APIKey != ""
 }
 
@@ -181,6 +194,9 @@ type ProxySettings struct {
 	NetBoxEnabled          bool      `gorm:"default:false" json:"netbox_enabled"`                 // Use proxy for NetBox API
 	K8sEnabled             bool      `gorm:"column:k8s_enabled;default:false" json:"k8s_enabled"` // Use proxy for Kubernetes API
 	JiraEnabled            bool      `gorm:"default:false" json:"jira_enabled"`                   // Use proxy for Jira API
+	PrometheusEnabled      bool      `gorm:"default:false" json:"prometheus_enabled"`             // Use proxy for Prometheus API
+	LogSearchEnabled       bool      `gorm:"default:false" json:"log_search_enabled"`             // Use proxy for Loki/Elasticsearch log search
+	HTTPCheckEnabled       bool      `gorm:"default:false" json:"http_check_enabled"`             // Use proxy for HTTP check probes
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
 }
@@ -211,6 +227,62 @@ type GeneralSettings struct {
 	// cause against recent investigated incidents and merges on a confident
 	// match. Nil/false = disabled (default).
 	IncidentMergeEnabled *bool `gorm:"default:null" json:"incident_merge_enabled"`
+
+	// NotificationLocale selects which NotificationTemplate overrides are
+	// used for outbound messages (e.g. "de", "pt-BR"). Empty = default.
+	NotificationLocale string `gorm:"size:16" json:"notification_locale"`
+
+	// IncidentAutoCloseDays closes completed and monitor incidents that have
+	// seen no new alert or human activity for this many days. Nil/0 =
+	// disabled (default).
+	IncidentAutoCloseDays *int `gorm:"default:null" json:"incident_auto_close_days"`
+
+	// ShortLinksEnabled makes outbound incident links (Slack, PagerDuty
+	// notes, notifications) short /i/{code} links. Nil/false = full links
+	// (default).
+	ShortLinksEnabled *bool `gorm:"default:null" json:"short_links_enabled"`
+
+	// ConfidenceReviewThreshold asks a human to review alert investigation
+	// results whose confidence is below it, in the incident's Slack thread.
+	// Nil = 0.5; 0 disables.
+	ConfidenceReviewThreshold *float64 `gorm:"default:null" json:"confidence_review_threshold"`
+
+	// ConfidenceReviewers is mentioned in review requests, e.g.
+	// "<@U012AB3CD> <!subteam^S0614TZR7>". Empty = no mention.
+	ConfidenceReviewers string `gorm:"size:512" json:"confidence_reviewers"`
+
+	// ConfidenceAutoCloseThreshold closes info and warning incidents whose
+	// alerts have resolved when the investigation's confidence is at least
+	// this. Nil/0 = disabled (default).
+	ConfidenceAutoCloseThreshold *float64 `gorm:"default:null" json:"confidence_auto_close_threshold"`
+}
+
+// DefaultConfidenceReviewThreshold is the review threshold used while
+// ConfidenceReviewThreshold is unset.
+const DefaultConfidenceReviewThreshold = 0.5
+
+// GetConfidenceReviewThreshold returns the effective review threshold, or 0
+// when review requests are disabled.
+func (s *GeneralSettings) GetConfidenceReviewThreshold() float64 {
+	if s.ConfidenceReviewThreshold == nil {
+		return DefaultConfidenceReviewThreshold
+	}
+	return *s.ConfidenceReviewThreshold
+}
+
+// GetConfidenceAutoCloseThreshold returns the auto-close threshold, or 0
+// when auto-close is disabled.
+func (s *GeneralSettings) GetConfidenceAutoCloseThreshold() float64 {
+	if s.ConfidenceAutoCloseThreshold == nil {
+		return 0
+	}
+	return *s.ConfidenceAutoCloseThreshold
+}
+
+// GetShortLinksEnabled returns the effective short-link flag, defaulting to
+// false when unset.
+func (s *GeneralSettings) GetShortLinksEnabled() bool {
+	return s.ShortLinksEnabled != nil && *s.ShortLinksEnabled
 }
 
 // GetIncidentMergeEnabled returns the effective merge-gate flag, defaulting
@@ -219,6 +291,15 @@ func (s *GeneralSettings) GetIncidentMergeEnabled() bool {
 	return s.IncidentMergeEnabled != nil && *s.IncidentMergeEnabled
 }
 
+// GetIncidentAutoCloseAfter returns the stale-incident auto-close threshold,
+// or 0 when auto-close is disabled.
+func (s *GeneralSettings) GetIncidentAutoCloseAfter() time.Duration {
+	if s.IncidentAutoCloseDays == nil || *s.IncidentAutoCloseDays <= 0 {
+		return 0
+	}
+	return time.Duration(*s.IncidentAutoCloseDays) * 24 * time.Hour
+}
+
 // GetAlertMonitorWindow returns the configured monitor window duration,
 // defaulting to 60 minutes when nil.
 func (s *GeneralSettings) GetAlertMonitorWindow() time.Duration {
@@ -236,7 +317,7 @@ func (GeneralSettings) TableName() string {
 type APIKeySettings struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Enabled   bool      `gorm:"default:false" json:"enabled"`
-	Keys      JSONB     `gorm:"type:jsonb" json:"keys"` // Array of {key, name, enabled, created_at}
+	Keys      JSONB     `gorm:"type:jsonb;serializer:secret;secret_values" json:"keys"` // Array of {key, name, enabled, created_at}
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -288,13 +369,21 @@ func (APIKeySettings) TableName() string {
 // SingletonKey with a unique index ensures only one row can exist at the DB level,
 // preventing duplicate rows from concurrent FirstOrCreate calls.
 type RetentionSettings struct {
-	ID                   uint      `gorm:"primaryKey" json:"id"`
-	SingletonKey         string    `gorm:"uniqueIndex;default:'default';not null" json:"-"`
-	Enabled              bool      `gorm:"default:true" json:"enabled"`
-	RetentionDays        int       `gorm:"default:90" json:"retention_days"`
-	CleanupIntervalHours int       `gorm:"default:6" json:"cleanup_interval_hours"`
-	CreatedAt            time.Time `json:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at"`
+	ID                   uint   `gorm:"primaryKey" json:"id"`
+	SingletonKey         string `gorm:"uniqueIndex;default:'default';not null" json:"-"`
+	Enabled              bool   `gorm:"default:true" json:"enabled"`
+	RetentionDays        int    `gorm:"default:90" json:"retention_days"`
+	CleanupIntervalHours int    `gorm:"default:6" json:"cleanup_interval_hours"`
+
+	// Workspace limits prune the working directories of finished incidents
+	// while keeping their records. 0 disables a limit. ArchiveWorkspaces
+	// writes a tar.gz of every workspace before it is deleted.
+	WorkspaceMaxAgeDays int  `gorm:"default:0" json:"workspace_max_age_days"`
+	WorkspaceMaxTotalMB int  `gorm:"default:0" json:"workspace_max_total_mb"`
+	ArchiveWorkspaces   bool `gorm:"default:false" json:"archive_workspaces"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (RetentionSettings) TableName() string {
@@ -344,6 +433,43 @@ func IsLegacyDefaultFormattingPrompt(s string) bool {
 	return strings.TrimSpace(s) == strings.TrimSpace(legacyDefaultFormattingPrompt)
 }
 
+// StringList is stored as a JSONB array.
+type StringList []string
+
+// Scan implements the sql.Scanner interface
+func (l *StringList) Scan(value interface{}) error { return scanJSONArray(value, l) }
+
+// Value implements the driver.Valuer interface
+func (l StringList) Value() (driver.Value, error) { return json.Marshal(l) }
+
+// CORSSettings stores the cross-origin policy edited from the UI (singleton).
+// While Enabled is false the CORS_* environment variables apply; once enabled
+// these values replace them. AllowedHeaders empty means the default headers.
+type CORSSettings struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	SingletonKey     string     `gorm:"uniqueIndex;default:'default';not null" json:"-"`
+	Enabled          bool       `gorm:"default:false" json:"enabled"`
+	AllowedOrigins   StringList `gorm:"type:jsonb" json:"allowed_origins"`
+	AllowedHeaders   StringList `gorm:"type:jsonb" json:"allowed_headers"`
+	AllowCredentials bool       `gorm:"default:false" json:"allow_credentials"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (CORSSettings) TableName() string {
+	return "cors_settings"
+}
+
+// DefaultCORSSettings returns the default CORS settings: disabled, so the
+// environment (by default same-origin only) decides.
+func DefaultCORSSettings() *CORSSettings {
+	return &CORSSettings{
+		SingletonKey:   "default",
+		AllowedOrigins: StringList{},
+		AllowedHeaders: StringList{},
+	}
+}
+
 // FormattingSettings stores the global response-formatter prompt that runs as
 // a one-shot LLM call after each incident finishes investigating. The
 // formatted text replaces incident.response, while incident.full_log keeps the
@@ -0,0 +1,51 @@
+package database
+
+import "time"
+
+// Silence suppresses firing alerts that match it while it is active, so
+// planned maintenance does not spawn investigations. Matchers are optional
+// and ANDed: an empty matcher matches everything. AlertName and TargetHost
+// are case-insensitive globs ("*" matches any run of characters); Labels
+// maps target label names to globs the label value must match.
+type Silence struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UUID       string    `gorm:"uniqueIndex;size:36;not null" json:"uuid"`
+	Comment    string    `gorm:"type:text" json:"comment"`
+	CreatedBy  string    `gorm:"size:128" json:"created_by"`
+	SourceUUID string    `gorm:"size:36;index" json:"source_uuid,omitempty"` // empty = every alert source
+	AlertName  string    `gorm:"size:255" json:"alert_name,omitempty"`
+	TargetHost string    `gorm:"size:255" json:"target_host,omitempty"`
+	Labels     JSONB     `gorm:"type:jsonb" json:"labels,omitempty"`
+	StartsAt   time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt     time.Time `gorm:"not null;index" json:"ends_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (Silence) TableName() string {
+	return "silences"
+}
+
+// IsActive reports whether the silence is in effect at now.
+func (s *Silence) IsActive(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// SuppressedAlert is the audit record of one firing alert a silence
+// suppressed. Rows are append-only and outlive the silence itself.
+type SuppressedAlert struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	SilenceUUID  string        `gorm:"size:36;not null;index" json:"silence_uuid"`
+	SourceUUID   string        `gorm:"size:36;index" json:"source_uuid"`
+	AlertName    string        `gorm:"size:255" json:"alert_name"`
+	TargetHost   string        `gorm:"size:255" json:"target_host"`
+	Severity     AlertSeverity `gorm:"size:32" json:"severity"`
+	Summary      string        `gorm:"type:text" json:"summary"`
+	Fingerprint  string        `gorm:"size:255" json:"fingerprint"`
+	TargetLabels JSONB         `gorm:"type:jsonb" json:"target_labels,omitempty"`
+	CreatedAt    time.Time     `gorm:"index" json:"created_at"`
+}
+
+func (SuppressedAlert) TableName() string {
+	return "suppressed_alerts"
+}
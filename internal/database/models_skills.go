@@ -8,14 +8,15 @@ import (
 // Skill represents a skill definition (uses SKILL.md format internally for the agent worker)
 // Skill prompt/instructions are stored in filesystem at /akmatori/skills/{name}/SKILL.md
 type Skill struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `gorm:"uniqueIndex;size:64;not null" json:"name"` // kebab-case name (e.g., "zabbix-analyst")
-	Description string    `gorm:"size:1024" json:"description"`             // Short description for skill discovery
-	Category    string    `gorm:"size:64" json:"category"`                  // Optional category (e.g., "monitoring", "database")
-	IsSystem    bool      `gorm:"default:false" json:"is_system"`           // System skills cannot be deleted and don't connect to tools
-	Enabled     bool      `gorm:"default:true" json:"enabled"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Name          string    `gorm:"uniqueIndex;size:64;not null" json:"name"` // kebab-case name (e.g., "zabbix-analyst")
+	Description   string    `gorm:"size:1024" json:"description"`             // Short description for skill discovery
+	Category      string    `gorm:"size:64" json:"category"`                  // Optional category (e.g., "monitoring", "database")
+	IsSystem      bool      `gorm:"default:false" json:"is_system"`           // System skills cannot be deleted and don't connect to tools
+	Enabled       bool      `gorm:"default:true" json:"enabled"`
+	LLMSettingsID *uint     `gorm:"index" json:"llm_settings_id"` // LLM config for runs scoped to this skill; nil uses the active one
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 
 	// Relationships - tools are symlinked to skills/{name}/scripts/ with imports embedded in SKILL.md
 	Tools []ToolInstance `gorm:"many2many:skill_tools;" json:"tools,omitempty"`
@@ -38,9 +39,9 @@ type ToolType struct {
 type ToolInstance struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	ToolTypeID  uint      `gorm:"not null;index" json:"tool_type_id"`
-	Name        string    `gorm:"uniqueIndex;not null" json:"name"`         // User-friendly name
-	LogicalName string    `gorm:"uniqueIndex;size:128" json:"logical_name"` // Machine-friendly logical name for agent referencing (e.g., "prod-ssh")
-	Settings    JSONB     `gorm:"type:jsonb" json:"settings"`               // Tool-specific settings (URLs, tokens, etc.)
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`             // User-friendly name
+	LogicalName string    `gorm:"uniqueIndex;size:128" json:"logical_name"`     // Machine-friendly logical name for agent referencing (e.g., "prod-ssh")
+	Settings    JSONB     `gorm:"type:jsonb;serializer:secret" json:"settings"` // Tool-specific settings (URLs, tokens, etc.)
 	Enabled     bool      `gorm:"default:true" json:"enabled"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -58,6 +59,45 @@ type SkillTool struct {
 	CreatedAt      time.Time `json:"created_at"`
 }
 
+// SSHKnownHost is a host key pinned for an SSH tool instance. The MCP
+// gateway records a host's key on first connection (ssh_known_hosts_policy
+// "auto_add") and refuses later connections presenting a different key;
+// clearing the row lets the next connection pin the new key.
+type SSHKnownHost struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ToolInstanceID uint      `gorm:"not null;uniqueIndex:idx_ssh_known_hosts_instance_host" json:"tool_instance_id"`
+	Host           string    `gorm:"size:255;not null;uniqueIndex:idx_ssh_known_hosts_instance_host" json:"host"` // known_hosts form: "host" or "[host]:port"
+	KeyType        string    `gorm:"size:64;not null" json:"key_type"`
+	PublicKey      string    `gorm:"type:text;not null" json:"public_key"` // base64 wire-format key
+	Fingerprint    string    `gorm:"size:128;not null" json:"fingerprint"` // SHA256:...
+	CreatedAt      time.Time `json:"created_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+}
+
+func (SSHKnownHost) TableName() string {
+	return "ssh_known_hosts"
+}
+
+// Skill snippet kinds: a script lands in skills/{name}/scripts/, a reference
+// becomes a context file linked from the skill prompt via [[filename]].
+const (
+	SkillSnippetScript    = "script"
+	SkillSnippetReference = "reference"
+)
+
+// SkillSnippet records a command or query promoted from an incident into a
+// skill, so a skill's reusable pieces can be traced back to the
+// investigation they came from.
+type SkillSnippet struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SkillName    string    `gorm:"size:64;not null;index" json:"skill_name"`
+	Kind         string    `gorm:"size:16;not null" json:"kind"`
+	Filename     string    `gorm:"size:255;not null" json:"filename"`
+	IncidentUUID string    `gorm:"size:36;not null;index" json:"incident_uuid"`
+	Description  string    `gorm:"size:1024" json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 // EventSourceType represents the type of event source
 type EventSourceType string
 
@@ -94,6 +134,10 @@ func (SkillTool) TableName() string {
 	return "skill_tools"
 }
 
+func (SkillSnippet) TableName() string {
+	return "skill_snippets"
+}
+
 func (EventSource) TableName() string {
 	return "event_sources"
 }
@@ -316,14 +360,14 @@ const (
 // Each config defines how to connect to an external MCP server and how its tools are namespaced.
 type MCPServerConfig struct {
 	ID              uint               `gorm:"primaryKey" json:"id"`
-	Name            string             `gorm:"uniqueIndex;size:128;not null" json:"name"`  // User-friendly name
-	Transport       MCPServerTransport `gorm:"type:varchar(16);not null" json:"transport"` // "sse" or "stdio"
-	URL             string             `gorm:"size:512" json:"url,omitempty"`              // For SSE transport
-	Command         string             `gorm:"size:512" json:"command,omitempty"`          // For stdio transport
-	Args            JSONB              `gorm:"type:jsonb" json:"args,omitempty"`           // For stdio transport: ["arg1", "arg2"]
-	EnvVars         JSONB              `gorm:"type:jsonb" json:"env_vars,omitempty"`       // For stdio transport: {"KEY": "value"}
-	NamespacePrefix string             `gorm:"size:128;not null" json:"namespace_prefix"`  // e.g., "ext.github"
-	AuthConfig      JSONB              `gorm:"type:jsonb" json:"auth_config,omitempty"`    // Auth to inject into connections
+	Name            string             `gorm:"uniqueIndex;size:128;not null" json:"name"`                            // User-friendly name
+	Transport       MCPServerTransport `gorm:"type:varchar(16);not null" json:"transport"`                           // "sse" or "stdio"
+	URL             string             `gorm:"size:512" json:"url,omitempty"`                                        // For SSE transport
+	Command         string             `gorm:"size:512" json:"command,omitempty"`                                    // For stdio transport
+	Args            JSONB              `gorm:"type:jsonb" json:"args,omitempty"`                                     // For stdio transport: ["arg1", "arg2"]
+	EnvVars         JSONB              `gorm:"type:jsonb;serializer:secret;secret_values" json:"env_vars,omitempty"` // For stdio transport: {"KEY": "value"}
+	NamespacePrefix string             `gorm:"size:128;not null" json:"namespace_prefix"`                            // e.g., "ext.github"
+	AuthConfig      JSONB              `gorm:"type:jsonb;serializer:secret" json:"auth_config,omitempty"`            // Auth to inject into connections
 	Enabled         bool               `gorm:"default:true" json:"enabled"`
 	CreatedAt       time.Time          `json:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at"`
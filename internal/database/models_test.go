@@ -404,8 +404,8 @@ func TestIsValidThinkingLevel(t *testing.T) {
 
 func TestValidLLMProviders(t *testing.T) {
 	providers := ValidLLMProviders()
-	if len(providers) != 8 {
-		t.Errorf("expected 8 providers, got %d", len(providers))
+	if len(providers) != 9 {
+		t.Errorf("expected 9 providers, got %d", len(providers))
 	}
 }
 
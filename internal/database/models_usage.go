@@ -0,0 +1,24 @@
+package database
+
+import "time"
+
+// TokenUsage records the tokens one agent run spent on an incident. A row is
+// written each time a run completes, so a rerun adds a row of its own rather
+// than rewriting the first. Rows carry no foreign key and outlive the
+// incidents they describe: retention cleanup must not rewrite usage history.
+type TokenUsage struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	IncidentUUID string `gorm:"size:36;index" json:"incident_uuid"`
+	SourceKind   string `gorm:"size:32" json:"source_kind"`  // Trigger kind of the incident: "alert" | "cron" | "slack_mention"
+	Model        string `gorm:"size:100;index" json:"model"` // Active LLM model when the run completed
+	Skill        string `gorm:"size:64;index" json:"skill"`  // Last skill the run read; empty when it touched none
+	TokensUsed   int    `gorm:"not null" json:"tokens_used"`
+	// EstimatedCostUSD prices TokensUsed with the model price table at
+	// completion time. Nil when Model has no price configured.
+	EstimatedCostUSD *float64  `json:"estimated_cost_usd,omitempty"`
+	RecordedAt       time.Time `gorm:"not null;index" json:"recorded_at"`
+}
+
+func (TokenUsage) TableName() string {
+	return "token_usage"
+}
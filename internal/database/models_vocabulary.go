@@ -0,0 +1,159 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VocabularySettings lets a deployment present severities and statuses in
+// its own terms (singleton). Each map is keyed by the built-in value
+// ("critical", "diagnosed", "resolved", ...); keys without an entry keep
+// the built-in presentation.
+type VocabularySettings struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	SingletonKey string `gorm:"uniqueIndex;default:'default';not null" json:"-"`
+	// SeverityEmoji maps alert severities to the emoji used in Slack
+	// notifications, e.g. {"critical": ":fire:"}.
+	SeverityEmoji JSONB `gorm:"type:jsonb" json:"severity_emoji"`
+	// StatusLabels maps incident statuses and investigation results
+	// (resolved, unresolved, escalate) to display names.
+	StatusLabels JSONB `gorm:"type:jsonb" json:"status_labels"`
+	// StatusColors maps incident statuses to #rrggbb badge colors.
+	StatusColors JSONB     `gorm:"type:jsonb" json:"status_colors"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (VocabularySettings) TableName() string {
+	return "vocabulary_settings"
+}
+
+// GetOrCreateVocabularySettings retrieves or creates the vocabulary
+// settings (singleton), falling back to a plain read if a concurrent caller
+// inserted the row first.
+func GetOrCreateVocabularySettings() (*VocabularySettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var settings VocabularySettings
+	defaults := &VocabularySettings{SingletonKey: "default"}
+	if err := DB.Where(VocabularySettings{SingletonKey: "default"}).Attrs(defaults).FirstOrCreate(&settings).Error; err != nil {
+		if rerr := DB.Where(VocabularySettings{SingletonKey: "default"}).First(&settings).Error; rerr != nil {
+			return nil, fmt.Errorf("%w (retry: %v)", err, rerr)
+		}
+	}
+	return &settings, nil
+}
+
+// UpdateVocabularySettings saves the vocabulary settings and refreshes the
+// cached Vocabulary of this process.
+func UpdateVocabularySettings(settings *VocabularySettings) error {
+	if err := DB.Save(settings).Error; err != nil {
+		return err
+	}
+	vocabularyCache.Lock()
+	vocabularyCache.db, vocabularyCache.at, vocabularyCache.vocab = DB, time.Now(), newVocabulary(settings)
+	vocabularyCache.Unlock()
+	return nil
+}
+
+// Vocabulary is the presentation overrides in effect. The zero value (and a
+// nil *Vocabulary) is the built-in vocabulary.
+type Vocabulary struct {
+	severityEmoji map[string]string
+	statusLabels  map[string]string
+	statusColors  map[string]string
+}
+
+func newVocabulary(s *VocabularySettings) *Vocabulary {
+	return &Vocabulary{
+		severityEmoji: stringEntries(s.SeverityEmoji),
+		statusLabels:  stringEntries(s.StatusLabels),
+		statusColors:  stringEntries(s.StatusColors),
+	}
+}
+
+// SeverityEmoji returns the Slack emoji for severity.
+func (v *Vocabulary) SeverityEmoji(severity AlertSeverity) string {
+	if v != nil {
+		if emoji := v.severityEmoji[string(severity)]; emoji != "" {
+			return emoji
+		}
+	}
+	return defaultSeverityEmoji(severity)
+}
+
+// StatusLabel returns the configured display name for status, or "" when
+// the built-in wording applies.
+func (v *Vocabulary) StatusLabel(status string) string {
+	if v == nil {
+		return ""
+	}
+	return v.statusLabels[status]
+}
+
+// StatusColor returns the configured badge color for status, or "".
+func (v *Vocabulary) StatusColor(status string) string {
+	if v == nil {
+		return ""
+	}
+	return v.statusColors[status]
+}
+
+// StatusLabels returns the configured display names, keyed by status, for
+// formatters that only need the label map.
+func (v *Vocabulary) StatusLabels() map[string]string {
+	if v == nil {
+		return nil
+	}
+	return v.statusLabels
+}
+
+// vocabularyTTL bounds how stale another replica's vocabulary change can
+// look here. Saves on this replica apply immediately.
+const vocabularyTTL = 30 * time.Second
+
+var vocabularyCache struct {
+	sync.Mutex
+	db    *gorm.DB
+	at    time.Time
+	vocab *Vocabulary
+}
+
+// LoadVocabulary returns the vocabulary in effect. It is cached for
+// vocabularyTTL and never nil: without a database or settings row, or on a
+// read error, the built-in vocabulary is returned.
+func LoadVocabulary() *Vocabulary {
+	db := DB
+	if db == nil {
+		return &Vocabulary{}
+	}
+	vocabularyCache.Lock()
+	defer vocabularyCache.Unlock()
+	if vocabularyCache.db == db && time.Since(vocabularyCache.at) < vocabularyTTL {
+		return vocabularyCache.vocab
+	}
+	// A failed read is cached too, so a broken table costs one query per
+	// TTL rather than one per message.
+	var rows []VocabularySettings
+	vocab := &Vocabulary{}
+	if err := db.Where("singleton_key = ?", "default").Limit(1).Find(&rows).Error; err == nil && len(rows) > 0 {
+		vocab = newVocabulary(&rows[0])
+	}
+	vocabularyCache.db, vocabularyCache.at, vocabularyCache.vocab = db, time.Now(), vocab
+	return vocab
+}
+
+// stringEntries keeps the non-empty string values of m.
+func stringEntries(m JSONB) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if s, ok := v.(string); ok && s != "" {
+			out[k] = s
+		}
+	}
+	return out
+}
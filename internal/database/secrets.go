@@ -0,0 +1,343 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/akmatori/akmatori/internal/secrets"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Columns tagged serializer:secret are encrypted at rest. A string column is
+// encrypted whole; in a JSONB column only the string values under secret
+// keys (see secrets.IsSecretKey) are, so URLs and other settings stay
+// readable in the database. A JSONB column also tagged secret_values, such
+// as environment variables, has every string value encrypted. Reads decrypt
+// transparently.
+func init() {
+	schema.RegisterSerializer("secret", secretSerializer{})
+}
+
+// secretModels are the models with serializer:secret columns, re-encrypted
+// by EncryptSecrets.
+var secretModels = []interface{}{
+	&SystemSetting{}, &SlackSettings{}, &LLMSettings{}, &Integration{}, &ToolInstance{},
+	&AlertSourceInstance{}, &MCPServerConfig{}, &APIKeySettings{},
+}
+
+var secretKeyring atomic.Pointer[secrets.Keyring]
+
+// SetSecretKeyring sets the keyring used for secret columns. Without one,
+// secrets are written in plaintext and encrypted values cannot be read.
+func SetSecretKeyring(k *secrets.Keyring) {
+	secretKeyring.Store(k)
+}
+
+type secretSerializer struct{}
+
+// Scan decrypts a secret column into a string or JSONB field.
+func (secretSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	k := secretKeyring.Load()
+	fieldValue := field.ReflectValueOf(ctx, dst)
+	var raw string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported type %T for secret column %s", dbValue, field.DBName)
+	}
+
+	if fieldValue.Kind() == reflect.String {
+		plaintext, err := k.Decrypt(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+		fieldValue.SetString(plaintext)
+		return nil
+	}
+
+	obj := JSONB{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+	}
+	opened, err := openSecrets(k, map[string]interface{}(obj))
+	if err != nil {
+		return fmt.Errorf("%s: %w", field.DBName, err)
+	}
+	fieldValue.Set(reflect.ValueOf(JSONB(opened.(map[string]interface{}))))
+	return nil
+}
+
+// Value encrypts a string or JSONB field for storage.
+func (secretSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	return sealValue(secretKeyring.Load(), fieldValue, sealsAllValues(field))
+}
+
+// sealValue encrypts a secret column value. With all set every string in a
+// JSONB value is encrypted, not just those under secret keys.
+func sealValue(k *secrets.Keyring, value interface{}, all bool) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if k == nil {
+			return v, nil
+		}
+		if k.Current(v) {
+			return v, nil
+		}
+		plaintext, err := k.Decrypt(v)
+		if err != nil {
+			return nil, err
+		}
+		return k.Encrypt(plaintext)
+	case JSONB:
+		if v == nil {
+			return nil, nil
+		}
+		// Round-trip through JSON so nested typed values such as
+		// []map[string]interface{} are walked like decoded ones.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return nil, err
+		}
+		sealed, _, err := sealSecrets(k, "", obj, all)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(sealed)
+	case map[string]interface{}:
+		return sealValue(k, JSONB(v), all)
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T for secret column", value)
+}
+
+// sealSecrets returns a copy of v with the strings under secret keys (all
+// strings, with all set) encrypted with the primary key and reports whether
+// anything changed. Strings already encrypted with an older key are
+// re-encrypted wherever they are.
+func sealSecrets(k *secrets.Keyring, key string, v interface{}, all bool) (interface{}, bool, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		changed := false
+		for childKey, child := range t {
+			sealed, c, err := sealSecrets(k, childKey, child, all)
+			if err != nil {
+				return nil, false, err
+			}
+			out[childKey], changed = sealed, changed || c
+		}
+		return out, changed, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		changed := false
+		for i, child := range t {
+			sealed, c, err := sealSecrets(k, key, child, all)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i], changed = sealed, changed || c
+		}
+		return out, changed, nil
+	case string:
+		if k == nil || k.Current(t) || (!secrets.IsEncrypted(t) && !all && !secrets.IsSecretKey(key)) {
+			return t, false, nil
+		}
+		plaintext, err := k.Decrypt(t)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		sealed, err := k.Encrypt(plaintext)
+		return sealed, true, err
+	}
+	return v, false, nil
+}
+
+// openSecrets returns a copy of v with every encrypted string decrypted.
+func openSecrets(k *secrets.Keyring, v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, child := range t {
+			opened, err := openSecrets(k, child)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = opened
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			opened, err := openSecrets(k, child)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = opened
+		}
+		return out, nil
+	case string:
+		return k.Decrypt(t)
+	}
+	return v, nil
+}
+
+func isSecretColumn(field *schema.Field) bool {
+	return field.TagSettings["SERIALIZER"] == "secret"
+}
+
+// sealsAllValues reports whether every string in the secret JSONB column is
+// a credential (tag secret_values), rather than only those under secret keys.
+func sealsAllValues(field *schema.Field) bool {
+	_, ok := field.TagSettings["SECRET_VALUES"]
+	return ok
+}
+
+// registerSecretCallbacks makes map updates (Updates(map), Update(column,
+// value)) of secret columns encrypt too; gorm applies field serializers
+// only to struct values.
+func registerSecretCallbacks(db *gorm.DB) error {
+	return db.Callback().Update().Before("gorm:update").Register("akmatori:seal_secret_updates", sealSecretUpdates)
+}
+
+func sealSecretUpdates(db *gorm.DB) {
+	stmt := db.Statement
+	updates, ok := stmt.Dest.(map[string]interface{})
+	if !ok || stmt.Schema == nil {
+		return
+	}
+	var sealed map[string]interface{}
+	for column, value := range updates {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil || !isSecretColumn(field) {
+			continue
+		}
+		v, err := sealValue(secretKeyring.Load(), value, sealsAllValues(field))
+		if err != nil {
+			_ = db.AddError(fmt.Errorf("encrypt %s: %w", column, err))
+			return
+		}
+		if sealed == nil {
+			sealed = make(map[string]interface{}, len(updates))
+			for c, v := range updates {
+				sealed[c] = v
+			}
+		}
+		sealed[column] = v
+	}
+	if sealed != nil {
+		stmt.Dest = sealed
+	}
+}
+
+// EncryptSecrets encrypts plaintext secrets left from before encryption was
+// enabled and re-encrypts those written with a previous key, so a retired
+// key can be dropped from SECRETS_ENCRYPTION_PREVIOUS_KEYS afterwards.
+// Rows already encrypted with the primary key are not touched. It is a
+// no-op without a keyring.
+func EncryptSecrets(db *gorm.DB) error {
+	k := secretKeyring.Load()
+	if k == nil {
+		return nil
+	}
+	for _, model := range secretModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		table, pk := stmt.Schema.Table, stmt.Schema.PrioritizedPrimaryField.DBName
+		var fields []*schema.Field
+		columns := []string{pk}
+		for _, field := range stmt.Schema.Fields {
+			if isSecretColumn(field) {
+				fields = append(fields, field)
+				columns = append(columns, field.DBName)
+			}
+		}
+
+		var rows []map[string]interface{}
+		if err := db.Table(table).Select(columns).Find(&rows).Error; err != nil {
+			return fmt.Errorf("read %s: %w", table, err)
+		}
+		rewritten := 0
+		for _, row := range rows {
+			updates := map[string]interface{}{}
+			for _, field := range fields {
+				v, changed, err := resealColumn(k, field, row[field.DBName])
+				if err != nil {
+					return fmt.Errorf("%s %v: %s: %w", table, row[pk], field.DBName, err)
+				}
+				if changed {
+					updates[field.DBName] = v
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			where := clause.Eq{Column: clause.Column{Name: pk}, Value: row[pk]}
+			if err := db.Table(table).Where(where).UpdateColumns(updates).Error; err != nil {
+				return fmt.Errorf("encrypt %s %v: %w", table, row[pk], err)
+			}
+			rewritten++
+		}
+		if rewritten > 0 {
+			slog.Info("encrypted secrets", "table", table, "rows", rewritten)
+		}
+	}
+	return nil
+}
+
+// resealColumn returns the stored form of a secret column encrypted with the
+// primary key and whether it differs from raw.
+func resealColumn(k *secrets.Keyring, field *schema.Field, raw interface{}) (interface{}, bool, error) {
+	var s string
+	switch v := raw.(type) {
+	case nil:
+		return nil, false, nil
+	case *interface{}:
+		// Columns of a type the driver does not know (SQLite jsonb).
+		return resealColumn(k, field, *v)
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, false, fmt.Errorf("unsupported type %T", raw)
+	}
+	if field.IndirectFieldType.Kind() == reflect.String {
+		if k.Current(s) {
+			return nil, false, nil
+		}
+		sealed, err := sealValue(k, s, false)
+		return sealed, true, err
+	}
+	if s == "" {
+		return nil, false, nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &obj); err != nil {
+		return nil, false, err
+	}
+	sealed, changed, err := sealSecrets(k, "", obj, sealsAllValues(field))
+	if err != nil || !changed {
+		return nil, false, err
+	}
+	b, err := json.Marshal(sealed)
+	return b, true, err
+}
@@ -0,0 +1,265 @@
+package database
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/secrets"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSecretsTestDB(t *testing.T, key byte) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := registerSecretCallbacks(db); err != nil {
+		t.Fatalf("registerSecretCallbacks: %v", err)
+	}
+	if err := db.AutoMigrate(&SystemSetting{}, &SlackSettings{}, &LLMSettings{}, &Integration{}, &ToolType{}, &ToolInstance{},
+		&AlertSourceType{}, &AlertSourceInstance{}, &MCPServerConfig{}, &APIKeySettings{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	setSecretsTestKeys(t, key)
+	t.Cleanup(func() { SetSecretKeyring(nil) })
+	return db
+}
+
+func setSecretsTestKeys(t *testing.T, primary byte, previous ...byte) {
+	t.Helper()
+	var old [][]byte
+	for _, b := range previous {
+		old = append(old, bytes.Repeat([]byte{b}, secrets.KeySize))
+	}
+	k, err := secrets.NewKeyring(bytes.Repeat([]byte{primary}, secrets.KeySize), old...)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	SetSecretKeyring(k)
+}
+
+func rawColumn(t *testing.T, db *gorm.DB, table, column string, id uint) string {
+	t.Helper()
+	var raw string
+	if err := db.Table(table).Select(column).Where("id = ?", id).Row().Scan(&raw); err != nil {
+		t.Fatalf("read %s.%s: %v", table, column, err)
+	}
+	return raw
+}
+
+func TestSecretColumns_EncryptedAtRest(t *testing.T) {
+	db := setupSecretsTestDB(t, 1)
+
+	llm := &LLMSettings{Name: "openai", Provider: LLMProviderOpenAI, APIKey: "sk-live"}
+	if err := db.Create(llm).Error; err != nil {
+		t.Fatalf("create llm settings: %v", err)
+	}
+	if llm.APIKey != "sk-live" {
+		t.Errorf("Create changed the in-memory key to %q", llm.APIKey)
+	}
+	if raw := rawColumn(t, db, "llm_settings", "api_key", llm.ID); !secrets.IsEncrypted(raw) {
+		t.Errorf("api_key stored as %q, want encrypted", raw)
+	}
+
+	// Map updates bypass field serializers and are sealed by the callback.
+	if err := db.Model(&LLMSettings{}).Where("id = ?", llm.ID).Updates(map[string]interface{}{"api_key": "sk-new", "model": "gpt"}).Error; err != nil {
+		t.Fatalf("update llm settings: %v", err)
+	}
+	if raw := rawColumn(t, db, "llm_settings", "api_key", llm.ID); !secrets.IsEncrypted(raw) {
+		t.Errorf("api_key updated to %q, want encrypted", raw)
+	}
+	var got LLMSettings
+	if err := db.First(&got, llm.ID).Error; err != nil {
+		t.Fatalf("read llm settings: %v", err)
+	}
+	if got.APIKey != "sk-new" || got.Model != "gpt" {
+		t.Errorf("read back %q/%q, want sk-new/gpt", got.APIKey, got.Model)
+	}
+
+	tool := &ToolInstance{ToolTypeID: 1, Name: "ssh", Settings: JSONB{
+		"ssh_hosts":      []interface{}{map[string]interface{}{"hostname": "web"}},
+		"ssh_keys":       []map[string]interface{}{{"name": "deploy", "private_key": "-----BEGIN KEY-----"}},
+		"api_token":      "t0ken",
+		"k8s_kubeconfig": "apiVersion: v1\nkind: Config",
+	}}
+	if err := db.Create(tool).Error; err != nil {
+		t.Fatalf("create tool instance: %v", err)
+	}
+	raw := rawColumn(t, db, "tool_instances", "settings", tool.ID)
+	if strings.Contains(raw, "BEGIN KEY") || strings.Contains(raw, "t0ken") || strings.Contains(raw, "kind: Config") {
+		t.Errorf("settings stored with plaintext secrets: %s", raw)
+	}
+	if !strings.Contains(raw, `"hostname":"web"`) || !strings.Contains(raw, `"name":"deploy"`) {
+		t.Errorf("settings stored without their plain fields: %s", raw)
+	}
+	if err := db.Model(&ToolInstance{}).Where("id = ?", tool.ID).Update("settings", JSONB{"api_token": "t1"}).Error; err != nil {
+		t.Fatalf("update tool settings: %v", err)
+	}
+	if raw := rawColumn(t, db, "tool_instances", "settings", tool.ID); strings.Contains(raw, `"t1"`) {
+		t.Errorf("updated settings stored in plaintext: %s", raw)
+	}
+	var gotTool ToolInstance
+	if err := db.First(&gotTool, tool.ID).Error; err != nil {
+		t.Fatalf("read tool instance: %v", err)
+	}
+	if gotTool.Settings["api_token"] != "t1" {
+		t.Errorf("read back settings %v, want api_token decrypted", gotTool.Settings)
+	}
+
+	source := &AlertSourceInstance{UUID: "src-1", AlertSourceTypeID: 1, Name: "grafana", WebhookSecret: "hook-secret"}
+	if err := db.Create(source).Error; err != nil {
+		t.Fatalf("create alert source instance: %v", err)
+	}
+	if raw := rawColumn(t, db, "alert_source_instances", "webhook_secret", source.ID); !secrets.IsEncrypted(raw) {
+		t.Errorf("webhook_secret stored as %q, want encrypted", raw)
+	}
+
+	// Every environment variable is encrypted, whatever its name.
+	server := &MCPServerConfig{Name: "db", Transport: MCPServerTransportStdio, NamespacePrefix: "ext.db",
+		EnvVars: JSONB{"DATABASE_URL": "postgres://app:pw@db/app"}}
+	if err := db.Create(server).Error; err != nil {
+		t.Fatalf("create mcp server config: %v", err)
+	}
+	if raw := rawColumn(t, db, "mcp_server_configs", "env_vars", server.ID); strings.Contains(raw, "postgres://") {
+		t.Errorf("env_vars stored in plaintext: %s", raw)
+	}
+	var gotServer MCPServerConfig
+	if err := db.First(&gotServer, server.ID).Error; err != nil {
+		t.Fatalf("read mcp server config: %v", err)
+	}
+	if gotServer.EnvVars["DATABASE_URL"] != "postgres://app:pw@db/app" {
+		t.Errorf("read back env_vars %v", gotServer.EnvVars)
+	}
+
+	apiKeys := &APIKeySettings{Enabled: true, Keys: JSONB{"keys": []interface{}{
+		map[string]interface{}{"key": "ak-live-123", "name": "ci", "enabled": true},
+	}}}
+	if err := db.Create(apiKeys).Error; err != nil {
+		t.Fatalf("create api key settings: %v", err)
+	}
+	if raw := rawColumn(t, db, "api_key_settings", "keys", apiKeys.ID); strings.Contains(raw, "ak-live-123") {
+		t.Errorf("api keys stored in plaintext: %s", raw)
+	}
+	var gotKeys APIKeySettings
+	if err := db.First(&gotKeys, apiKeys.ID).Error; err != nil {
+		t.Fatalf("read api key settings: %v", err)
+	}
+	if active := gotKeys.GetActiveKeys(); len(active) != 1 || active[0] != "ak-live-123" {
+		t.Errorf("read back active keys %v", active)
+	}
+
+	SetSecretKeyring(nil)
+	if err := db.First(&got, llm.ID).Error; err == nil {
+		t.Error("read an encrypted key without a keyring")
+	}
+}
+
+func TestEncryptSecrets_MigratesAndRotates(t *testing.T) {
+	db := setupSecretsTestDB(t, 1)
+
+	// Rows written before encryption was enabled.
+	SetSecretKeyring(nil)
+	slack := &SlackSettings{BotToken: "xoxb", SigningSecret: "sig", AppToken: ""}
+	integration := &Integration{UUID: "u1", Provider: MessagingProviderSlack, Name: "Slack", Credentials: JSONB{"bot_token": "xoxb", "team": "T1"}}
+	if err := db.Create(slack).Error; err != nil {
+		t.Fatalf("create slack settings: %v", err)
+	}
+	if err := db.Create(integration).Error; err != nil {
+		t.Fatalf("create integration: %v", err)
+	}
+	if err := db.Create(&SystemSetting{Key: SystemSettingJWTSecret, Value: "jwt"}).Error; err != nil {
+		t.Fatalf("create system setting: %v", err)
+	}
+	source := &AlertSourceInstance{UUID: "src-1", AlertSourceTypeID: 1, Name: "grafana", WebhookSecret: "hook-secret"}
+	server := &MCPServerConfig{Name: "db", Transport: MCPServerTransportStdio, NamespacePrefix: "ext.db",
+		EnvVars: JSONB{"DATABASE_URL": "postgres://app:pw@db/app"}}
+	tool := &ToolInstance{ToolTypeID: 1, Name: "k8s", Settings: JSONB{"k8s_kubeconfig": "kind: Config", "k8s_namespace": "prod"}}
+	apiKeys := &APIKeySettings{Enabled: true, Keys: JSONB{"keys": []interface{}{
+		map[string]interface{}{"key": "ak-live-123", "name": "ci", "enabled": true},
+	}}}
+	for _, row := range []interface{}{source, server, tool, apiKeys} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %T: %v", row, err)
+		}
+	}
+
+	setSecretsTestKeys(t, 1)
+	if err := EncryptSecrets(db); err != nil {
+		t.Fatalf("EncryptSecrets: %v", err)
+	}
+	first := rawColumn(t, db, "slack_settings", "bot_token", slack.ID)
+	if !secrets.IsEncrypted(first) {
+		t.Errorf("bot_token = %q after migration, want encrypted", first)
+	}
+	var jwt string
+	if err := db.Table("system_settings").Select("value").Where("key = ?", SystemSettingJWTSecret).Row().Scan(&jwt); err != nil || !secrets.IsEncrypted(jwt) {
+		t.Errorf("jwt_secret = %q, %v after migration, want encrypted", jwt, err)
+	}
+	if raw := rawColumn(t, db, "slack_settings", "app_token", slack.ID); raw != "" {
+		t.Errorf("empty app_token became %q", raw)
+	}
+	creds := rawColumn(t, db, "integrations", "credentials", integration.ID)
+	if strings.Contains(creds, `"xoxb"`) || !strings.Contains(creds, `"team":"T1"`) {
+		t.Errorf("credentials = %s, want bot_token encrypted and team plain", creds)
+	}
+	if raw := rawColumn(t, db, "alert_source_instances", "webhook_secret", source.ID); !secrets.IsEncrypted(raw) {
+		t.Errorf("webhook_secret = %q after migration, want encrypted", raw)
+	}
+	if raw := rawColumn(t, db, "mcp_server_configs", "env_vars", server.ID); strings.Contains(raw, "postgres://") {
+		t.Errorf("env_vars = %s after migration, want encrypted", raw)
+	}
+	if raw := rawColumn(t, db, "api_key_settings", "keys", apiKeys.ID); strings.Contains(raw, "ak-live-123") {
+		t.Errorf("keys = %s after migration, want encrypted", raw)
+	}
+	settings := rawColumn(t, db, "tool_instances", "settings", tool.ID)
+	if strings.Contains(settings, "kind: Config") || !strings.Contains(settings, `"k8s_namespace":"prod"`) {
+		t.Errorf("settings = %s, want k8s_kubeconfig encrypted and namespace plain", settings)
+	}
+
+	if err := EncryptSecrets(db); err != nil {
+		t.Fatalf("EncryptSecrets (re-run): %v", err)
+	}
+	if raw := rawColumn(t, db, "slack_settings", "bot_token", slack.ID); raw != first {
+		t.Error("re-run rewrote a value already under the primary key")
+	}
+
+	setSecretsTestKeys(t, 2, 1)
+	if err := EncryptSecrets(db); err != nil {
+		t.Fatalf("EncryptSecrets (rotation): %v", err)
+	}
+	setSecretsTestKeys(t, 2)
+	var got SlackSettings
+	if err := db.First(&got, slack.ID).Error; err != nil {
+		t.Fatalf("read slack settings with only the new key: %v", err)
+	}
+	if got.BotToken != "xoxb" || got.SigningSecret != "sig" {
+		t.Errorf("read back %+v after rotation", got)
+	}
+	var gotIntegration Integration
+	if err := db.First(&gotIntegration, integration.ID).Error; err != nil {
+		t.Fatalf("read integration with only the new key: %v", err)
+	}
+	if gotIntegration.Credentials["bot_token"] != "xoxb" {
+		t.Errorf("credentials after rotation = %v", gotIntegration.Credentials)
+	}
+	var gotSource AlertSourceInstance
+	if err := db.First(&gotSource, source.ID).Error; err != nil || gotSource.WebhookSecret != "hook-secret" {
+		t.Errorf("webhook_secret after rotation = %q, %v", gotSource.WebhookSecret, err)
+	}
+	var gotServer MCPServerConfig
+	if err := db.First(&gotServer, server.ID).Error; err != nil || gotServer.EnvVars["DATABASE_URL"] != "postgres://app:pw@db/app" {
+		t.Errorf("env_vars after rotation = %v, %v", gotServer.EnvVars, err)
+	}
+	var gotTool ToolInstance
+	if err := db.First(&gotTool, tool.ID).Error; err != nil || gotTool.Settings["k8s_kubeconfig"] != "kind: Config" {
+		t.Errorf("tool settings after rotation = %v, %v", gotTool.Settings, err)
+	}
+	var gotKeys APIKeySettings
+	if err := db.First(&gotKeys, apiKeys.ID).Error; err != nil || !reflect.DeepEqual(gotKeys.GetActiveKeys(), []string{"ak-live-123"}) {
+		t.Errorf("api keys after rotation = %v, %v", gotKeys.Keys, err)
+	}
+}
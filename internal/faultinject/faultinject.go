@@ -0,0 +1,146 @@
+// Package faultinject simulates failures at a few well-known seams so the
+// error handling around them (failed incidents, Slack retries, the alerts the
+// system raises about itself) can be exercised before a production rollout.
+//
+// Injection is a development aid and is off unless FAULT_INJECTION_ENABLED is
+// set. A nil *Injector is valid and never injects, so components hold one
+// unconditionally and callers only wire it up when the toggle is on.
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Fault identifies a failure the injector can simulate.
+type Fault string
+
+const (
+	// AdapterParseError makes an alert webhook fail as if its payload could
+	// not be parsed by the source adapter.
+	AdapterParseError Fault = "adapter_parse_error"
+	// AgentTimeout fails an investigation run as if the agent had timed out.
+	AgentTimeout Fault = "agent_timeout"
+	// SlackRateLimit answers Slack chat.* API calls with HTTP 429.
+	SlackRateLimit Fault = "slack_rate_limit"
+)
+
+// Faults lists every fault the injector knows about.
+var Faults = []Fault{AdapterParseError, AgentTimeout, SlackRateLimit}
+
+// ErrInjected is wrapped by every error the injector produces, so logs and
+// tests can tell a simulated failure from a real one.
+var ErrInjected = errors.New("injected fault")
+
+// Injector decides, per call, whether a fault fires. Rates are probabilities
+// in [0, 1] and are fixed at construction.
+type Injector struct {
+	rates    map[Fault]float64
+	injected map[Fault]*atomic.Int64
+	roll     func() float64
+}
+
+// New returns an injector firing each fault at the given rate. Rates outside
+// [0, 1] are clamped; faults missing from rates never fire.
+func New(rates map[Fault]float64) *Injector {
+	i := &Injector{
+		rates:    make(map[Fault]float64, len(Faults)),
+		injected: make(map[Fault]*atomic.Int64, len(Faults)),
+		roll:     rand.Float64,
+	}
+	for _, f := range Faults {
+		i.rates[f] = min(max(rates[f], 0), 1)
+		i.injected[f] = &atomic.Int64{}
+	}
+	return i
+}
+
+// Rate returns the configured rate for f.
+func (i *Injector) Rate(f Fault) float64 {
+	if i == nil {
+		return 0
+	}
+	return i.rates[f]
+}
+
+// Should reports whether f fires on this call and logs when it does.
+func (i *Injector) Should(f Fault) bool {
+	if i == nil {
+		return false
+	}
+	rate := i.rates[f]
+	if rate <= 0 || i.roll() >= rate {
+		return false
+	}
+	i.injected[f].Add(1)
+	slog.Warn("injecting simulated failure", "fault", f, "rate", rate)
+	return true
+}
+
+// Injected returns how many times f has fired.
+func (i *Injector) Injected(f Fault) int64 {
+	if i == nil || i.injected[f] == nil {
+		return 0
+	}
+	return i.injected[f].Load()
+}
+
+// Error returns an error for an injected f carrying msg. It wraps
+// ErrInjected.
+func Error(f Fault, msg string) error {
+	return &injectedError{fault: f, msg: msg}
+}
+
+type injectedError struct {
+	fault Fault
+	msg   string
+}
+
+func (e *injectedError) Error() string { return e.msg + " (" + string(e.fault) + " injected)" }
+func (e *injectedError) Unwrap() error { return ErrInjected }
+
+// WrapSlackTransport returns base wrapped so that Slack chat.* calls answer
+// 429 with a Retry-After header at the SlackRateLimit rate. Other Slack
+// methods (auth, socket mode connection setup) pass through untouched so the
+// connection itself stays up. A nil injector returns base unchanged.
+func (i *Injector) WrapSlackTransport(base http.RoundTripper) http.RoundTripper {
+	if i == nil || i.rates[SlackRateLimit] <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &slackRateLimitTransport{base: base, injector: i}
+}
+
+type slackRateLimitTransport struct {
+	base     http.RoundTripper
+	injector *Injector
+}
+
+func (t *slackRateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, "/api/chat.") || !t.injector.Should(SlackRateLimit) {
+		return t.base.RoundTrip(req)
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("Retry-After", "1")
+	return &http.Response{
+		Status:     "429 Too Many Requests",
+		StatusCode: http.StatusTooManyRequests,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":false,"error":"ratelimited"}`)),
+		Request:    req,
+	}, nil
+}
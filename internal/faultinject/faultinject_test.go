@@ -0,0 +1,101 @@
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestInjector_NilNeverInjects(t *testing.T) {
+	var i *Injector
+	for _, f := range Faults {
+		if i.Should(f) {
+			t.Errorf("nil injector fired %s", f)
+		}
+		if i.Rate(f) != 0 || i.Injected(f) != 0 {
+			t.Errorf("nil injector reported non-zero stats for %s", f)
+		}
+	}
+	base := http.DefaultTransport
+	if i.WrapSlackTransport(base) != base {
+		t.Error("nil injector should not wrap the transport")
+	}
+}
+
+func TestInjector_RatesAndCounts(t *testing.T) {
+	i := New(map[Fault]float64{AdapterParseError: 0.5, AgentTimeout: 2, SlackRateLimit: -1})
+	if i.Rate(AgentTimeout) != 1 || i.Rate(SlackRateLimit) != 0 {
+		t.Errorf("rates not clamped: timeout=%v slack=%v", i.Rate(AgentTimeout), i.Rate(SlackRateLimit))
+	}
+
+	rolls := []float64{0.1, 0.7, 0.49}
+	i.roll = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+	got := []bool{i.Should(AdapterParseError), i.Should(AdapterParseError), i.Should(AdapterParseError)}
+	if !got[0] || got[1] || !got[2] {
+		t.Errorf("Should = %v, want [true false true]", got)
+	}
+	if i.Injected(AdapterParseError) != 2 {
+		t.Errorf("Injected = %d, want 2", i.Injected(AdapterParseError))
+	}
+	if i.Should(SlackRateLimit) {
+		t.Error("zero-rate fault fired")
+	}
+}
+
+func TestError_WrapsErrInjected(t *testing.T) {
+	err := Error(AgentTimeout, "agent execution timed out")
+	if !errors.Is(err, ErrInjected) {
+		t.Error("expected errors.Is(err, ErrInjected)")
+	}
+	if err.Error() != "agent execution timed out (agent_timeout injected)" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestWrapSlackTransport_RateLimitsChatCalls(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"url":"https://example.slack.com/","team":"T","user":"U","team_id":"T1","user_id":"U1"}`))
+	}))
+	defer server.Close()
+
+	i := New(map[Fault]float64{SlackRateLimit: 1})
+	client := slack.New("xoxb-test",
+		slack.OptionAPIURL(server.URL+"/api/"),
+		slack.OptionHTTPClient(&http.Client{Transport: i.WrapSlackTransport(nil)}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, _, err := client.PostMessageContext(ctx, "C123", slack.MsgOptionText("hello", false))
+	var rateLimited *slack.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("PostMessage err = %v, want *slack.RateLimitedError", err)
+	}
+	if rateLimited.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", rateLimited.RetryAfter)
+	}
+	if hits.Load() != 0 {
+		t.Error("rate-limited chat call should not reach Slack")
+	}
+
+	if _, err := client.AuthTestContext(ctx); err != nil {
+		t.Fatalf("auth.test should pass through: %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("hits = %d, want non-chat call forwarded", hits.Load())
+	}
+}
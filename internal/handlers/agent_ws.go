@@ -6,15 +6,18 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/faultinject"
+	"github.com/akmatori/akmatori/internal/metrics"
 	"github.com/akmatori/akmatori/internal/services"
 	"github.com/akmatori/akmatori/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"gorm.io/gorm"
 )
 
 // AgentMessageType represents the type of WebSocket message
@@ -35,11 +38,36 @@ const (
 	AgentMessageTypeHeartbeat          AgentMessageType = "heartbeat"
 	AgentMessageTypeStatus             AgentMessageType = "status"
 	AgentMessageTypeOneshotLLMResponse AgentMessageType = "oneshot_llm_response"
+	AgentMessageTypeAgentInterrupted   AgentMessageType = "agent_interrupted"
 )
 
 // oneshotLLMDefaultTimeout is used when callers pass a context with no deadline.
 const oneshotLLMDefaultTimeout = 60 * time.Second
 
+// defaultWorkerCapacity is the number of concurrent runs assumed for a worker
+// that has not advertised a capacity in its ready status.
+const defaultWorkerCapacity = 4
+
+// maxRunRedispatches bounds how often one run is resent after the worker
+// running it disconnected, so a run that crashes every worker it lands on
+// eventually fails instead of taking the whole pool down.
+const maxRunRedispatches = 2
+
+// workerRedispatchNote is appended to a re-dispatched run's output so the
+// log explains why the investigation starts over.
+const workerRedispatchNote = "\n\n⚠️ The agent worker running this investigation disconnected; it was restarted on another worker.\n\n"
+
+// workerResumeNote is appended to a run's output when a restarted worker
+// picks it up again from its saved session.
+const workerResumeNote = "\n\n⚠️ The agent worker restarted during this investigation; it was resumed from the saved session.\n\n"
+
+// workerResumePrompt is the follow-up message a resumed run continues with.
+const workerResumePrompt = "The agent worker restarted while you were working on this investigation. Continue from where you left off and give your final answer."
+
+// errWorkerRestarted fails a run its worker lost in a restart with no saved
+// session to resume from.
+const errWorkerRestarted = "agent worker restarted before the investigation finished"
+
 // ProxyConfig holds proxy configuration with per-service toggles
 type ProxyConfig struct {
 	URL                    string `json:"url"`
@@ -76,6 +104,7 @@ type AgentMessage struct {
 	Model         string `json:"model,omitempty"`
 	ThinkingLevel string `json:"thinking_level,omitempty"`
 	BaseURL       string `json:"base_url,omitempty"`
+	ContextWindow int    `json:"context_window,omitempty"`
 
 	// Proxy configuration with toggles (sent with new_incident)
 	ProxyConfig *ProxyConfig `json:"proxy_config,omitempty"`
@@ -108,6 +137,11 @@ type AgentMessage struct {
 	// drops events whose run_id does not match the currently registered
 	// callback so a superseded run cannot leak frames into the new waiter.
 	RunID string `json:"run_id,omitempty"`
+
+	// Seq numbers the agent_output deltas of a run from 1. The API appends
+	// each delta to the incident log once, dropping a frame whose seq it has
+	// already seen; 0 means the sender does not number its frames.
+	Seq int64 `json:"seq,omitempty"`
 }
 
 // LLMSettingsForWorker is re-exported from services so handler code that
@@ -119,18 +153,17 @@ type LLMSettingsForWorker = services.LLMSettingsForWorker
 // pendingOneshotEntry pairs a oneshot response channel with the worker
 // connection that received the request. cleanupWorkerConn uses the conn
 // pointer to signal only entries owned by the disconnecting conn so a
-// reconnect race never fails a replacement-era caller and never strands an
-// A-era caller after B has already taken over workerConn.
+// disconnect never fails a caller waiting on another worker and never
+// strands a caller whose worker went away.
 type pendingOneshotEntry struct {
 	ch   chan *AgentMessage
 	conn *websocket.Conn
 }
 
 // incidentCallbackEntry pairs an incident callback with the worker conn the
-// incident request was sent on. cleanupWorkerConn fails only callbacks owned
-// by the disconnecting conn so a reconnect race never fires OnError on a
-// replacement-era incident and never strands an A-era caller after B has
-// taken over workerConn.
+// incident request was sent on. cleanupWorkerConn re-dispatches or fails
+// only callbacks owned by the disconnecting conn, so runs on other workers
+// are never disturbed and runs on the lost worker never strand.
 //
 // runID identifies the specific Start/Continue call that registered this
 // entry. The worker echoes the same run_id on every agent_output /
@@ -145,23 +178,72 @@ type pendingOneshotEntry struct {
 // this, a newer Start/Continue arriving during finalization could not signal
 // the displaced waiter, and a stale finalize could overwrite the
 // replacement run's result.
+//
+// payload is the frame that started the run. When the owning worker
+// disconnects before the run finishes, the frame is resent unchanged (same
+// run_id) to another worker and conn is switched to it; redispatches counts
+// those resends.
+//
+// lastSeq is the highest agent_output seq delivered for the run. It is a
+// pointer so the dispatch path can advance it under the read lock, and it
+// is replaced whenever the run moves to another worker, whose numbering
+// starts over.
 type incidentCallbackEntry struct {
-	callback  IncidentCallback
-	conn      *websocket.Conn
-	runID     string
-	finalized bool
+	callback     IncidentCallback
+	conn         *websocket.Conn
+	runID        string
+	finalized    bool
+	payload      []byte
+	redispatches int
+	lastSeq      *atomic.Int64
 }
 
-// AgentWSHandler handles WebSocket connections from the agent worker
+// agentWorker is one connected agent worker. Capacity is the number of
+// concurrent runs the worker advertised in its ready status; the load used
+// for balancing is the number of unfinished runs whose callback it owns.
+type agentWorker struct {
+	conn        *websocket.Conn
+	id          string
+	capacity    int
+	connectedAt time.Time
+}
+
+// WorkerStatus describes a connected worker for GET /api/workers.
+type WorkerStatus struct {
+	ID          string    `json:"id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Capacity    int       `json:"capacity"`
+	ActiveRuns  int       `json:"active_runs"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// AgentWSHandler handles WebSocket connections from the agent workers. Any
+// number of workers may be connected at once; each run is dispatched to the
+// least-loaded one and re-dispatched if that worker disconnects mid-run.
 type AgentWSHandler struct {
-	upgrader         websocket.Upgrader
-	mu               sync.RWMutex
-	workerConn       *websocket.Conn
-	workerReady      bool
+	upgrader websocket.Upgrader
+	// mu guards workers and serializes every write to a worker conn
+	// (gorilla/websocket allows one concurrent writer per conn).
+	mu      sync.RWMutex
+	workers map[*websocket.Conn]*agentWorker
+	// workerUp is closed while at least one worker is connected and
+	// replaced with a fresh channel when the last one disconnects, so
+	// WaitForWorker can block on it.
+	workerUp         chan struct{}
+	connectWait      time.Duration                    // how long WaitForWorker blocks (0 = no wait)
 	callbacks        map[string]incidentCallbackEntry // incident_id -> callback + owning conn
 	callbackMu       sync.RWMutex
 	pendingOneshot   map[string]pendingOneshotEntry // request_id -> response channel + owning conn
 	pendingOneshotMu sync.Mutex
+	// faults fails runs with a simulated agent timeout in failure-injection
+	// mode (nil never injects).
+	faults *faultinject.Injector
+	// health is told how each run ended so the self-monitor can spot the LLM
+	// provider rejecting credentials (nil disables).
+	health services.HealthSignalRecorder
+	// leader, when set, keeps the worker on the leader replica: followers
+	// refuse the WebSocket so the worker reconnects until it lands there.
+	leader services.LeaderStatus
 }
 
 // IncidentCallback is re-exported from services so handler code that
@@ -189,11 +271,52 @@ func NewAgentWSHandler() *AgentWSHandler {
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
+		workers:        make(map[*websocket.Conn]*agentWorker),
+		workerUp:       make(chan struct{}),
 		callbacks:      make(map[string]incidentCallbackEntry),
 		pendingOneshot: make(map[string]pendingOneshotEntry),
 	}
 }
 
+// SetWorkerConnectWait sets how long WaitForWorker blocks for a worker to
+// connect. Zero (the default) makes it equivalent to IsWorkerConnected.
+func (h *AgentWSHandler) SetWorkerConnectWait(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.connectWait = d
+}
+
+// SetFaultInjector enables simulated agent timeouts for incident runs.
+func (h *AgentWSHandler) SetFaultInjector(f *faultinject.Injector) {
+	h.faults = f
+}
+
+// SetHealthRecorder reports run outcomes to the self-monitor.
+func (h *AgentWSHandler) SetHealthRecorder(r services.HealthSignalRecorder) {
+	h.health = r
+}
+
+// SetLeaderStatus only accepts the worker WebSocket while this replica is
+// the leader.
+func (h *AgentWSHandler) SetLeaderStatus(leader services.LeaderStatus) {
+	h.leader = leader
+}
+
+// DisconnectWorker closes every worker connection. The workers' reconnect
+// loops then find whichever replica leads now.
+func (h *AgentWSHandler) DisconnectWorker() {
+	h.mu.RLock()
+	conns := make([]*websocket.Conn, 0, len(h.workers))
+	for conn := range h.workers {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+	for _, conn := range conns {
+		slog.Info("disconnecting agent worker", "remote_addr", conn.RemoteAddr().String())
+		conn.Close()
+	}
+}
+
 // SetupRoutes configures WebSocket routes
 func (h *AgentWSHandler) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/ws/agent", h.HandleWebSocket)
@@ -201,23 +324,27 @@ func (h *AgentWSHandler) SetupRoutes(mux *http.ServeMux) {
 
 // HandleWebSocket handles the WebSocket connection from the agent worker
 func (h *AgentWSHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if h.leader != nil && !h.leader.IsLeader() {
+		w.Header().Set("Retry-After", followerRetryAfter)
+		http.Error(w, "this replica is not the leader; reconnect", http.StatusServiceUnavailable)
+		return
+	}
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		slog.Error("failed to upgrade WebSocket", "err", err)
+		slog.ErrorContext(r.Context(), "failed to upgrade WebSocket", "err", err)
 		return
 	}
 
-	slog.Info("agent worker connected", "remote_addr", r.RemoteAddr)
+	metrics.WorkerConnected()
 
-	// Store the worker connection
 	h.mu.Lock()
-	if h.workerConn != nil {
-		// Close existing connection
-		h.workerConn.Close()
+	if len(h.workers) == 0 {
+		close(h.workerUp)
 	}
-	h.workerConn = conn
-	h.workerReady = true
+	h.workers[conn] = &agentWorker{conn: conn, id: r.RemoteAddr, capacity: defaultWorkerCapacity, connectedAt: time.Now()}
+	connected := len(h.workers)
 	h.mu.Unlock()
+	slog.InfoContext(r.Context(), "agent worker connected", "remote_addr", r.RemoteAddr, "workers", connected)
 
 	defer h.cleanupWorkerConn(conn)
 
@@ -226,24 +353,31 @@ func (h *AgentWSHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request)
 		_, data, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				slog.Error("WebSocket read error", "err", err)
+				slog.ErrorContext(r.Context(), "WebSocket read error", "err", err)
 			}
 			return
 		}
 
 		var msg AgentMessage
 		if err := json.Unmarshal(data, &msg); err != nil {
-			slog.Error("failed to parse message", "err", err)
+			slog.ErrorContext(r.Context(), "failed to parse message", "err", err)
 			continue
 		}
 
+		switch msg.Type {
+		case AgentMessageTypeStatus:
+			h.updateWorker(conn, msg.Data)
+		case AgentMessageTypeAgentInterrupted:
+			h.handleAgentInterrupted(conn, msg)
+			continue
+		}
 		h.handleMessage(msg)
 	}
 }
 
 // handleMessage processes incoming messages from the agent worker
 func (h *AgentWSHandler) handleMessage(msg AgentMessage) {
-	slog.Info("received message from worker", "type", msg.Type, "incident_id", msg.IncidentID)
+	slog.Info("received message from worker", "type", msg.Type, "incident_uuid", msg.IncidentID)
 
 	switch msg.Type {
 	case AgentMessageTypeHeartbeat:
@@ -274,30 +408,166 @@ func (h *AgentWSHandler) handleMessage(msg AgentMessage) {
 	}
 }
 
+// updateWorker records the capacity and worker_id a worker advertises in
+// its status frames ({"status":"ready","capacity":4,"worker_id":"..."}).
+func (h *AgentWSHandler) updateWorker(conn *websocket.Conn, data map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	worker, ok := h.workers[conn]
+	if !ok {
+		return
+	}
+	if capacity, ok := data["capacity"].(float64); ok && capacity >= 1 {
+		worker.capacity = int(capacity)
+	}
+	if id, ok := data["worker_id"].(string); ok && id != "" {
+		worker.id = id
+	}
+}
+
 // cleanupWorkerConn runs the per-connection teardown when HandleWebSocket
-// returns. It clears workerConn only if this conn still owns the slot, then
-// always fails pending oneshots and incident callbacks that were registered
-// against this conn — regardless of whether a reconnect has already installed
-// a replacement. Per-conn ownership prevents two reconnect-race orderings
-// from misrouting disconnect signals: (1) cleanup runs while a replacement
-// has just begun registering its own pending entries (those entries belong
-// to B's conn, so A's cleanup leaves them alone); (2) cleanup runs after B
-// has already replaced A in workerConn (A's entries are still owned by A
-// and would otherwise strand until ctx.Done() or, for incident callers, until
-// they block forever on <-done).
+// returns: it removes the worker from the pool, fails pending oneshots sent
+// over this conn, hands its unfinished runs to the remaining workers and
+// fails those that could not be handed over. With a worker connect wait
+// configured the failure is deferred by that long, so a worker that restarts
+// can report the runs it lost (agent_interrupted) and resume them.
+// Everything is keyed by conn, so entries owned by other workers — including
+// one that connected while this one was going away — are never touched.
 func (h *AgentWSHandler) cleanupWorkerConn(conn *websocket.Conn) {
 	h.mu.Lock()
-	if h.workerConn == conn {
-		h.workerConn = nil
-		h.workerReady = false
+	if _, ok := h.workers[conn]; ok {
+		delete(h.workers, conn)
+		if len(h.workers) == 0 {
+			h.workerUp = make(chan struct{})
+		}
 	}
+	remaining := len(h.workers)
 	h.mu.Unlock()
 	conn.Close()
 
 	h.failPendingOneshotForConn(conn, ErrWorkerNotConnected.Error())
-	h.failCallbacksForConn(conn, ErrWorkerNotConnected.Error())
+	h.redispatchCallbacksForConn(conn)
+	h.mu.RLock()
+	wait := h.connectWait
+	h.mu.RUnlock()
+	if wait > 0 {
+		time.AfterFunc(wait, func() { h.failCallbacksForConn(conn, ErrWorkerNotConnected.Error()) })
+	} else {
+		h.failCallbacksForConn(conn, ErrWorkerNotConnected.Error())
+	}
 
-	slog.Info("agent worker disconnected")
+	slog.Info("agent worker disconnected", "workers", remaining)
+}
+
+// redispatchCallbacksForConn resends the unfinished runs owned by a
+// disconnected worker to the least-loaded remaining workers. The resent
+// frame keeps its run_id, so the waiter and its callback carry on; only the
+// run's output starts over. Runs without a stored frame, or already
+// re-dispatched maxRunRedispatches times, are left for failCallbacksForConn.
+func (h *AgentWSHandler) redispatchCallbacksForConn(conn *websocket.Conn) {
+	h.mu.Lock()
+	h.callbackMu.Lock()
+	var moved []IncidentCallback
+	for incidentID, entry := range h.callbacks {
+		if entry.conn != conn || entry.finalized || entry.payload == nil || entry.redispatches >= maxRunRedispatches {
+			continue
+		}
+		target := h.pickWorkerLocked()
+		if target == nil {
+			break
+		}
+		if err := target.conn.WriteMessage(websocket.TextMessage, entry.payload); err != nil {
+			slog.Warn("failed to re-dispatch run", "incident_uuid", incidentID, "worker", target.id, "err", err)
+			continue
+		}
+		entry.conn = target.conn
+		entry.redispatches++
+		entry.lastSeq = new(atomic.Int64)
+		h.callbacks[incidentID] = entry
+		moved = append(moved, entry.callback)
+		slog.Warn("re-dispatched run from disconnected worker",
+			"incident_uuid", incidentID, "run_id", entry.runID, "worker", target.id, "attempt", entry.redispatches)
+	}
+	h.callbackMu.Unlock()
+	h.mu.Unlock()
+
+	for _, cb := range moved {
+		if cb.OnOutput != nil {
+			cb.OnOutput(workerRedispatchNote)
+		}
+	}
+}
+
+// workerLoadLocked counts each worker's unfinished runs. Callers hold
+// callbackMu.
+func (h *AgentWSHandler) workerLoadLocked() map[*websocket.Conn]int {
+	load := make(map[*websocket.Conn]int, len(h.workers))
+	for _, entry := range h.callbacks {
+		if !entry.finalized {
+			load[entry.conn]++
+		}
+	}
+	return load
+}
+
+// pickWorkerLocked returns the worker with the most room: the lowest share
+// of its capacity in use, then the fewest runs, then the longest connected.
+// When every worker is full the least-loaded one still gets the run — the
+// worker queues it rather than the API failing it. Returns nil when no
+// worker is connected. Callers hold mu and callbackMu.
+func (h *AgentWSHandler) pickWorkerLocked() *agentWorker {
+	load := h.workerLoadLocked()
+	var best *agentWorker
+	for _, w := range h.workers {
+		if best == nil {
+			best = w
+			continue
+		}
+		// Compare load/capacity without division.
+		wl, bl := load[w.conn], load[best.conn]
+		wShare, bShare := wl*best.capacity, bl*w.capacity
+		switch {
+		case wShare != bShare:
+			if wShare < bShare {
+				best = w
+			}
+		case wl != bl:
+			if wl < bl {
+				best = w
+			}
+		case w.connectedAt.Before(best.connectedAt):
+			best = w
+		}
+	}
+	return best
+}
+
+// Workers lists the connected workers, longest connected first.
+func (h *AgentWSHandler) Workers() []WorkerStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h.callbackMu.RLock()
+	load := h.workerLoadLocked()
+	h.callbackMu.RUnlock()
+	workers := make([]WorkerStatus, 0, len(h.workers))
+	for conn, w := range h.workers {
+		workers = append(workers, WorkerStatus{
+			ID:          w.id,
+			RemoteAddr:  conn.RemoteAddr().String(),
+			Capacity:    w.capacity,
+			ActiveRuns:  load[conn],
+			ConnectedAt: w.connectedAt,
+		})
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ConnectedAt.Before(workers[j].ConnectedAt) })
+	return workers
+}
+
+// WorkerCount returns the number of connected workers.
+func (h *AgentWSHandler) WorkerCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.workers)
 }
 
 // failCallbacksForConn invokes OnError on every incident callback that was
@@ -412,14 +682,12 @@ func (h *AgentWSHandler) handleAgentOutput(msg AgentMessage) {
 	// still recover data when the message has no run identity.
 	if msg.RunID != "" {
 		slog.Debug("dropping agent_output with no live callback",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID)
 		return
 	}
 
-	if err := database.GetDB().Model(&database.Incident{}).
-		Where("uuid = ?", msg.IncidentID).
-		Update("full_log", gorm.Expr("COALESCE(full_log, '') || ?", msg.Output)).Error; err != nil {
+	if err := database.AppendIncidentLog(database.GetDB(), msg.IncidentID, msg.Output); err != nil {
 		slog.Error("failed to update incident log", "err", err)
 	}
 }
@@ -437,7 +705,7 @@ func (h *AgentWSHandler) dispatchOnOutput(msg AgentMessage) bool {
 	}
 	if entry.runID != "" && msg.RunID != "" && entry.runID != msg.RunID {
 		slog.Debug("dropping agent_output from superseded run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID,
 			"current_run_id", entry.runID)
 		return true
@@ -448,16 +716,45 @@ func (h *AgentWSHandler) dispatchOnOutput(msg AgentMessage) bool {
 		// appending here would race the DB write or leak stale text into
 		// the next run's full_log.
 		slog.Debug("dropping agent_output for finalized run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID)
 		return true
 	}
+	if !acceptOutputSeq(entry.lastSeq, msg) {
+		return true
+	}
 	if entry.callback.OnOutput != nil {
 		entry.callback.OnOutput(msg.Output)
 	}
 	return true
 }
 
+// acceptOutputSeq advances the run's last seen seq, rejecting a frame that
+// repeats one already delivered (a worker resending after a reconnect).
+// Frames without a seq are always accepted. A gap is only logged: the
+// missing delta is lost either way, and the rest of the log is still worth
+// keeping.
+func acceptOutputSeq(lastSeq *atomic.Int64, msg AgentMessage) bool {
+	if lastSeq == nil || msg.Seq <= 0 {
+		return true
+	}
+	for {
+		prev := lastSeq.Load()
+		if msg.Seq <= prev {
+			slog.Debug("dropping duplicate agent_output",
+				"incident_uuid", msg.IncidentID, "run_id", msg.RunID, "seq", msg.Seq, "last_seq", prev)
+			return false
+		}
+		if lastSeq.CompareAndSwap(prev, msg.Seq) {
+			if msg.Seq > prev+1 {
+				slog.Warn("agent_output frames missing",
+					"incident_uuid", msg.IncidentID, "run_id", msg.RunID, "seq", msg.Seq, "last_seq", prev)
+			}
+			return true
+		}
+	}
+}
+
 // handleAgentCompleted handles completion notification from the agent. Drops
 // completion frames from a superseded run (run_id mismatch) so a late
 // completion from run 1 cannot prematurely close run 2's done channel or
@@ -475,7 +772,10 @@ func (h *AgentWSHandler) dispatchOnOutput(msg AgentMessage) bool {
 // and the Slack footer. The DB fallback path below (no live callback) keeps
 // appending metrics directly because there is no formatter step there.
 func (h *AgentWSHandler) handleAgentCompleted(msg AgentMessage) {
-	slog.Info("incident completed", "incident_id", msg.IncidentID, "session_id", msg.SessionID, "tokens_used", msg.TokensUsed, "execution_time_ms", msg.ExecutionTimeMs)
+	slog.Info("incident completed", "incident_uuid", msg.IncidentID, "session_id", msg.SessionID, "tokens_used", msg.TokensUsed, "execution_time_ms", msg.ExecutionTimeMs)
+	if h.health != nil {
+		h.health.RecordAgentResult("")
+	}
 
 	// Persist the last skill BEFORE the completion callback fires: the
 	// finalizer goroutine unblocked by OnCompleted reads the incident row
@@ -486,7 +786,7 @@ func (h *AgentWSHandler) handleAgentCompleted(msg AgentMessage) {
 		if err := database.GetDB().Model(&database.Incident{}).
 			Where("uuid = ?", msg.IncidentID).
 			Update("last_skill_used", msg.LastSkill).Error; err != nil {
-			slog.Warn("failed to persist last skill used", "incident_id", msg.IncidentID, "err", err)
+			slog.Warn("failed to persist last skill used", "incident_uuid", msg.IncidentID, "err", err)
 		}
 	}
 
@@ -502,7 +802,7 @@ func (h *AgentWSHandler) handleAgentCompleted(msg AgentMessage) {
 		// the replacement run's status / response / session_id with stale
 		// values; drop instead.
 		slog.Debug("dropping agent_completed with no live callback",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID)
 		return
 	}
@@ -580,7 +880,7 @@ func (h *AgentWSHandler) dispatchOnCompleted(msg AgentMessage, output string) bo
 		// Late completion from a superseded run. Don't invoke the new
 		// callback's OnCompleted, don't touch the new entry.
 		slog.Debug("dropping agent_completed from superseded run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID,
 			"current_run_id", entry.runID)
 		return true
@@ -589,7 +889,7 @@ func (h *AgentWSHandler) dispatchOnCompleted(msg AgentMessage, output string) bo
 		// Duplicate completion frame for an already-finalized run. Ignore
 		// silently; the waiter has already captured its response.
 		slog.Debug("dropping duplicate agent_completed for finalized run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID)
 		return true
 	}
@@ -610,7 +910,10 @@ func (h *AgentWSHandler) dispatchOnCompleted(msg AgentMessage, output string) bo
 // still held so a concurrent sendIncidentMessage cannot swap the entry and
 // fire OnSuperseded between snapshot and call.
 func (h *AgentWSHandler) handleAgentError(msg AgentMessage) {
-	slog.Error("incident failed", "incident_id", msg.IncidentID, "err", msg.Error)
+	slog.Error("incident failed", "incident_uuid", msg.IncidentID, "err", msg.Error)
+	if h.health != nil {
+		h.health.RecordAgentResult(msg.Error)
+	}
 
 	if h.dispatchOnError(msg) {
 		return
@@ -622,7 +925,7 @@ func (h *AgentWSHandler) handleAgentError(msg AgentMessage) {
 		// (or already-finalized) run — the replacement run, if any, owns
 		// finalization.
 		slog.Debug("dropping agent_error with no live callback",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID,
 			"err", msg.Error)
 		return
@@ -660,7 +963,7 @@ func (h *AgentWSHandler) dispatchOnError(msg AgentMessage) bool {
 	}
 	if entry.runID != "" && msg.RunID != "" && entry.runID != msg.RunID {
 		slog.Debug("dropping agent_error from superseded run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID,
 			"current_run_id", entry.runID)
 		return true
@@ -670,7 +973,7 @@ func (h *AgentWSHandler) dispatchOnError(msg AgentMessage) bool {
 		// Drop it — overwriting the waiter's captured response with the
 		// error text would corrupt finalization.
 		slog.Debug("dropping agent_error for finalized run",
-			"incident_id", msg.IncidentID,
+			"incident_uuid", msg.IncidentID,
 			"msg_run_id", msg.RunID,
 			"err", msg.Error)
 		return true
@@ -683,14 +986,119 @@ func (h *AgentWSHandler) dispatchOnError(msg AgentMessage) bool {
 	return true
 }
 
-// IsWorkerConnected returns whether a worker is connected
+// handleAgentInterrupted handles a worker reporting, after a restart, a run
+// its previous process did not finish. A run the API is still waiting on is
+// resumed on the reporting worker from the saved session (session_id set),
+// or failed when the worker had none. A report for a run that has since
+// finished, been superseded or moved to another worker is ignored. With no
+// waiter at all — the API restarted too — the incident is marked failed if
+// it is still running, so it does not stay running forever.
+func (h *AgentWSHandler) handleAgentInterrupted(conn *websocket.Conn, msg AgentMessage) {
+	slog.Warn("agent worker reported an interrupted run",
+		"incident_uuid", msg.IncidentID, "run_id", msg.RunID, "session_id", msg.SessionID, "output_offset", msg.Data["output_offset"])
+
+	h.mu.Lock()
+	h.callbackMu.Lock()
+	entry, exists := h.callbacks[msg.IncidentID]
+	_, ownerConnected := h.workers[entry.conn]
+	if exists && (entry.finalized || entry.runID != msg.RunID || ownerConnected) {
+		h.callbackMu.Unlock()
+		h.mu.Unlock()
+		slog.Debug("ignoring interrupted run that is no longer waiting",
+			"incident_uuid", msg.IncidentID, "msg_run_id", msg.RunID, "current_run_id", entry.runID)
+		return
+	}
+	resumed := false
+	if exists && msg.SessionID != "" && entry.payload != nil {
+		data, err := resumePayload(entry.payload, msg.SessionID)
+		if err == nil {
+			err = conn.WriteMessage(websocket.TextMessage, data)
+		}
+		if err != nil {
+			slog.Warn("failed to resume interrupted run", "incident_uuid", msg.IncidentID, "err", err)
+		} else {
+			entry.conn = conn
+			entry.lastSeq = new(atomic.Int64)
+			h.callbacks[msg.IncidentID] = entry
+			resumed = true
+		}
+	}
+	h.callbackMu.Unlock()
+	h.mu.Unlock()
+
+	switch {
+	case resumed:
+		slog.Info("resumed interrupted run", "incident_uuid", msg.IncidentID, "run_id", msg.RunID)
+		if entry.callback.OnOutput != nil {
+			entry.callback.OnOutput(workerResumeNote)
+		}
+	case exists:
+		h.dispatchOnError(AgentMessage{Type: AgentMessageTypeAgentError, IncidentID: msg.IncidentID, RunID: msg.RunID, Error: errWorkerRestarted})
+	default:
+		now := time.Now()
+		if err := database.GetDB().Model(&database.Incident{}).
+			Where("uuid = ? AND status = ?", msg.IncidentID, database.IncidentStatusRunning).
+			Updates(map[string]interface{}{
+				"status":       database.IncidentStatusFailed,
+				"response":     errWorkerRestarted,
+				"completed_at": &now,
+			}).Error; err != nil {
+			slog.Error("failed to fail interrupted incident", "incident_uuid", msg.IncidentID, "err", err)
+		}
+	}
+}
+
+// resumePayload turns the frame that started a run into a continue_incident
+// frame for sessionID, keeping the run_id, LLM settings, skills and tool
+// allowlist.
+func resumePayload(payload []byte, sessionID string) ([]byte, error) {
+	var msg AgentMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, err
+	}
+	msg.Type = AgentMessageTypeContinueIncident
+	msg.SessionID = sessionID
+	msg.Task = ""
+	msg.Message = workerResumePrompt
+	return json.Marshal(msg)
+}
+
+// IsWorkerConnected returns whether at least one worker is connected
 func (h *AgentWSHandler) IsWorkerConnected() bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return h.workerReady && h.workerConn != nil
+	return len(h.workers) > 0
+}
+
+// WaitForWorker reports whether a worker is connected, first waiting up to
+// the configured connect wait for one to (re)connect. Investigations call it
+// instead of IsWorkerConnected so an alert that lands while the worker is
+// starting or restarting is held briefly rather than failed.
+func (h *AgentWSHandler) WaitForWorker(ctx context.Context) bool {
+	h.mu.RLock()
+	ready := len(h.workers) > 0
+	up, wait := h.workerUp, h.connectWait
+	h.mu.RUnlock()
+	if ready {
+		return true
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	slog.Info("waiting for agent worker to connect", "timeout", wait)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-up:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return h.IsWorkerConnected()
 }
 
-// SendToWorker sends a message to the agent worker
+// SendToWorker sends a message to every connected worker, returning the
+// first write error. Workers ignore frames about incidents they do not run.
 func (h *AgentWSHandler) SendToWorker(msg AgentMessage) error {
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -699,10 +1107,16 @@ func (h *AgentWSHandler) SendToWorker(msg AgentMessage) error {
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if h.workerConn == nil {
+	if len(h.workers) == 0 {
 		return ErrWorkerNotConnected
 	}
-	return h.workerConn.WriteMessage(websocket.TextMessage, data)
+	var firstErr error
+	for conn := range h.workers {
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
 // StartIncident sends a new incident to the agent worker. Returns the
@@ -725,6 +1139,7 @@ func (h *AgentWSHandler) StartIncident(incidentID, task string, llm *LLMSettings
 		msg.Model = llm.Model
 		msg.ThinkingLevel = llm.ThinkingLevel
 		msg.BaseURL = llm.BaseURL
+		msg.ContextWindow = llm.ContextWindow
 	}
 
 	// Fetch proxy settings from database and include in message
@@ -739,6 +1154,13 @@ func (h *AgentWSHandler) StartIncident(incidentID, task string, llm *LLMSettings
 		}
 	}
 
+	// Record the prompt so POST /api/incidents/{uuid}/retry can re-run it.
+	if db := database.GetDB(); db != nil {
+		if err := db.Model(&database.Incident{}).Where("uuid = ?", incidentID).Update("task", task).Error; err != nil {
+			slog.Warn("failed to record incident task", "incident_uuid", incidentID, "err", err)
+		}
+	}
+
 	return h.sendIncidentMessage(incidentID, callback, msg)
 }
 
@@ -761,6 +1183,7 @@ func (h *AgentWSHandler) ContinueIncident(incidentID, sessionID, message string,
 		msg.Model = llm.Model
 		msg.ThinkingLevel = llm.ThinkingLevel
 		msg.BaseURL = llm.BaseURL
+		msg.ContextWindow = llm.ContextWindow
 	}
 
 	// Fetch proxy settings from database and include in message
@@ -799,14 +1222,18 @@ func (h *AgentWSHandler) ReleaseRun(incidentID, runID string) bool {
 	return true
 }
 
-// sendIncidentMessage atomically captures workerConn, registers the callback
+// sendIncidentMessage atomically picks a worker, registers the callback
 // against THAT conn, and writes the message — all under h.mu. Tying the
 // callback to the conn closes the disconnect-leak window: cleanupWorkerConn
-// for conn A only fails A-owned callbacks, so a concurrently-registered
-// B-era callback is left alone, and A-era callbacks are still failed
+// for conn A only re-dispatches or fails A-owned callbacks, so callbacks on
+// other workers are left alone, and A-era callbacks are still handled
 // promptly when A drops mid-investigation. Without this, callers blocking on
 // <-done would wait forever after the worker disappears.
 //
+// A run for an incident that still has a registered callback goes to the
+// worker that owns it, so that worker can abort the superseded run; any
+// other run goes to the least-loaded worker (see pickWorkerLocked).
+//
 // Each call generates a fresh run_id (UUID) and stamps it on both the
 // outgoing message and the registered callback entry. The worker echoes the
 // run_id on every agent_output / agent_completed / agent_error frame; the
@@ -831,11 +1258,6 @@ func (h *AgentWSHandler) sendIncidentMessage(incidentID string, callback Inciden
 	}
 
 	h.mu.Lock()
-	conn := h.workerConn
-	if conn == nil {
-		h.mu.Unlock()
-		return "", ErrWorkerNotConnected
-	}
 	// Hold callbackMu through the write so the swap and the write succeed or
 	// fail atomically with respect to other goroutines. Two races are closed
 	// at once:
@@ -863,7 +1285,20 @@ func (h *AgentWSHandler) sendIncidentMessage(incidentID string, callback Inciden
 	// already accepts when it holds callbackMu.RLock through OnOutput.
 	h.callbackMu.Lock()
 	previous, hadPrevious := h.callbacks[incidentID]
-	h.callbacks[incidentID] = incidentCallbackEntry{callback: callback, conn: conn, runID: runID}
+	var target *agentWorker
+	if hadPrevious {
+		target = h.workers[previous.conn]
+	}
+	if target == nil {
+		target = h.pickWorkerLocked()
+	}
+	if target == nil {
+		h.callbackMu.Unlock()
+		h.mu.Unlock()
+		return "", ErrWorkerNotConnected
+	}
+	conn := target.conn
+	h.callbacks[incidentID] = incidentCallbackEntry{callback: callback, conn: conn, runID: runID, payload: data, lastSeq: new(atomic.Int64)}
 	if writeErr := conn.WriteMessage(websocket.TextMessage, data); writeErr != nil {
 		// Roll back the swap before any other goroutine can observe Run 2's
 		// entry. The displaced run continues to own its finalization.
@@ -896,9 +1331,28 @@ func (h *AgentWSHandler) sendIncidentMessage(incidentID string, callback Inciden
 			previous.callback.OnError(ErrIncidentSuperseded.Error())
 		}
 	}
+	if h.faults.Should(faultinject.AgentTimeout) {
+		go h.injectAgentTimeout(incidentID, runID)
+	}
 	return runID, nil
 }
 
+// injectAgentTimeout fails a run that was just started as though the agent
+// had timed out: the worker is told to cancel and a synthetic agent_error
+// frame for the run goes through the normal dispatch path, so callers see
+// exactly what a real timeout produces.
+func (h *AgentWSHandler) injectAgentTimeout(incidentID, runID string) {
+	if err := h.CancelIncident(incidentID); err != nil {
+		slog.Warn("failed to cancel run for injected timeout", "incident_uuid", incidentID, "err", err)
+	}
+	h.handleAgentError(AgentMessage{
+		Type:       AgentMessageTypeAgentError,
+		IncidentID: incidentID,
+		RunID:      runID,
+		Error:      faultinject.Error(faultinject.AgentTimeout, "agent execution timed out").Error(),
+	})
+}
+
 // OneShotLLM sends a one-shot LLM request to the agent worker and waits for a response.
 // Correlates request and response via a generated request_id. Returns ErrWorkerNotConnected
 // when no worker is connected. If ctx has no deadline, applies oneshotLLMDefaultTimeout.
@@ -925,6 +1379,7 @@ func (h *AgentWSHandler) OneShotLLM(ctx context.Context, llm *LLMSettingsForWork
 		msg.Model = llm.Model
 		msg.ThinkingLevel = llm.ThinkingLevel
 		msg.BaseURL = llm.BaseURL
+		msg.ContextWindow = llm.ContextWindow
 	}
 
 	// Reuse the same proxy-settings pattern as StartIncident/ContinueIncident.
@@ -944,18 +1399,20 @@ func (h *AgentWSHandler) OneShotLLM(ctx context.Context, llm *LLMSettingsForWork
 		return "", err
 	}
 
-	// Atomically capture the current workerConn, register the pending entry
+	// Atomically pick the least-loaded worker, register the pending entry
 	// against THAT conn, and write the request — all under h.mu. Tying the
-	// entry to the conn closes the reconnect race that a global pendingOneshot
-	// map cannot: cleanup of conn A only signals A-owned entries, so a
-	// concurrently-registered B-era entry is left alone, and A-era entries
-	// are still failed promptly even after B has replaced A in workerConn.
+	// entry to the conn means cleanup of conn A only signals A-owned
+	// entries: requests on other workers are left alone, and A-era entries
+	// are failed promptly when A drops.
 	h.mu.Lock()
-	conn := h.workerConn
-	if conn == nil {
+	h.callbackMu.RLock()
+	worker := h.pickWorkerLocked()
+	h.callbackMu.RUnlock()
+	if worker == nil {
 		h.mu.Unlock()
 		return "", ErrWorkerNotConnected
 	}
+	conn := worker.conn
 	h.pendingOneshotMu.Lock()
 	h.pendingOneshot[requestID] = pendingOneshotEntry{ch: ch, conn: conn}
 	h.pendingOneshotMu.Unlock()
@@ -997,26 +1454,57 @@ func (h *AgentWSHandler) OneShotLLM(ctx context.Context, llm *LLMSettingsForWork
 	}
 }
 
-// CancelIncident sends a cancellation request to the worker
+// CancelIncident sends a cancellation request to the worker running the
+// incident, or to every worker when no run is registered for it.
 func (h *AgentWSHandler) CancelIncident(incidentID string) error {
 	msg := AgentMessage{
 		Type:       AgentMessageTypeCancelIncident,
 		IncidentID: incidentID,
 	}
 
-	return h.SendToWorker(msg)
+	h.callbackMu.RLock()
+	entry, ok := h.callbacks[incidentID]
+	h.callbackMu.RUnlock()
+	if !ok {
+		return h.SendToWorker(msg)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, connected := h.workers[entry.conn]; !connected {
+		return ErrWorkerNotConnected
+	}
+	return entry.conn.WriteMessage(websocket.TextMessage, data)
 }
 
-// BroadcastProxyConfig sends proxy configuration to the connected worker
-func (h *AgentWSHandler) BroadcastProxyConfig(settings *database.ProxySettings) error {
-	h.mu.RLock()
-	conn := h.workerConn
-	h.mu.RUnlock()
+// AbortRun stops the incident's current run for good, as when an operator
+// cancels the investigation. The run's waiter is released through
+// OnSuperseded so it writes no result of its own, frames the worker still
+// sends for the run are dropped, and the worker is told to cancel. It
+// reports whether a run was registered; workers are told to cancel either
+// way, in case one outlived an API restart.
+func (h *AgentWSHandler) AbortRun(incidentID string) (bool, error) {
+	h.callbackMu.Lock()
+	entry, registered := h.callbacks[incidentID]
+	delete(h.callbacks, incidentID)
+	h.callbackMu.Unlock()
 
-	if conn == nil {
-		return ErrWorkerNotConnected
+	if registered {
+		switch {
+		case entry.callback.OnSuperseded != nil:
+			entry.callback.OnSuperseded()
+		case entry.callback.OnError != nil:
+			entry.callback.OnError(services.ErrIncidentNotRunning.Error())
+		}
 	}
+	return registered, h.SendToWorker(AgentMessage{Type: AgentMessageTypeCancelIncident, IncidentID: incidentID})
+}
 
+// BroadcastProxyConfig sends proxy configuration to every connected worker
+func (h *AgentWSHandler) BroadcastProxyConfig(settings *database.ProxySettings) error {
 	msg := AgentMessage{
 		Type: AgentMessageTypeProxyConfigUpdate,
 		ProxyConfig: &ProxyConfig{
@@ -14,7 +14,7 @@ func setupLastSkillDB(t *testing.T, incidentUUID string) *gorm.DB {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate incident: %v", err)
 	}
 	prevDB := database.DB
@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/faultinject"
 	"github.com/gorilla/websocket"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -20,7 +22,7 @@ import (
 // setupOneshotTest connects the handler over an httptest WebSocket server and
 // returns the handler, a connected fake-worker websocket, and a cleanup func.
 //
-// Why a real WebSocket round-trip: AgentWSHandler.workerConn is a concrete
+// Why a real WebSocket round-trip: each pooled worker is a concrete
 // *websocket.Conn that is read in a tight loop in HandleWebSocket. Substituting
 // an interface would change production code only to ease testing, so we mirror
 // production wiring instead.
@@ -70,6 +72,32 @@ func setupOneshotTest(t *testing.T) (*AgentWSHandler, *websocket.Conn, func()) {
 	return handler, conn, cleanup
 }
 
+// waitForServerConn waits until n workers are connected and returns the
+// server side of the one that is not in known.
+func waitForServerConn(t *testing.T, handler *AgentWSHandler, n int, known ...*websocket.Conn) *websocket.Conn {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		handler.mu.RLock()
+		if len(handler.workers) == n {
+			for conn := range handler.workers {
+				isKnown := false
+				for _, k := range known {
+					isKnown = isKnown || k == conn
+				}
+				if !isKnown {
+					handler.mu.RUnlock()
+					return conn
+				}
+			}
+		}
+		handler.mu.RUnlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d connected workers", n)
+	return nil
+}
+
 // readOneshotRequest waits for a oneshot_llm_request frame on the fake worker.
 func readOneshotRequest(t *testing.T, conn *websocket.Conn) AgentMessage {
 	t.Helper()
@@ -348,10 +376,9 @@ func TestOneShotLLM_WorkerDisconnectWakesPending(t *testing.T) {
 // TestCleanupWorkerConn_PerConnRouting pins down the two reconnect-race
 // orderings the per-conn ownership fix has to handle:
 //
-//	(1) A's cleanup runs after B has replaced workerConn. Pending entries
-//	    owned by A MUST still be failed (otherwise A-era callers strand
-//	    until ctx.Done()), and pending entries owned by B MUST NOT be
-//	    touched.
+//	(1) A's cleanup runs while B is connected too. Pending entries owned
+//	    by A MUST still be failed (otherwise A-era callers strand until
+//	    ctx.Done()), and pending entries owned by B MUST NOT be touched.
 //
 //	(2) The mirror case where A's cleanup runs and a B-era entry has been
 //	    registered concurrently in the global map. The B-era entry MUST
@@ -371,38 +398,14 @@ func TestCleanupWorkerConn_PerConnRouting(t *testing.T) {
 		t.Fatalf("dial A: %v", err)
 	}
 	defer connA.Close()
-	deadline := time.Now().Add(2 * time.Second)
-	for !handler.IsWorkerConnected() && time.Now().Before(deadline) {
-		time.Sleep(5 * time.Millisecond)
-	}
-
-	handler.mu.Lock()
-	connAServer := handler.workerConn
-	handler.mu.Unlock()
-	if connAServer == nil {
-		t.Fatal("expected workerConn to be set after dial")
-	}
+	connAServer := waitForServerConn(t, handler, 1)
 
 	connB, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
 		t.Fatalf("dial B: %v", err)
 	}
 	defer connB.Close()
-	deadline = time.Now().Add(2 * time.Second)
-	var connBServer *websocket.Conn
-	for time.Now().Before(deadline) {
-		handler.mu.RLock()
-		current := handler.workerConn
-		handler.mu.RUnlock()
-		if current != nil && current != connAServer {
-			connBServer = current
-			break
-		}
-		time.Sleep(5 * time.Millisecond)
-	}
-	if connBServer == nil {
-		t.Fatal("expected workerConn to flip to B's conn")
-	}
+	connBServer := waitForServerConn(t, handler, 2, connAServer)
 
 	// Plant one A-owned entry (must be failed by A's cleanup) and one B-owned
 	// entry (must NOT be touched). Direct map manipulation models the state
@@ -554,6 +557,50 @@ func TestStartIncident_WorkerDisconnectFiresOnError(t *testing.T) {
 	}
 }
 
+// TestStartIncident_InjectedAgentTimeout checks that a simulated timeout is
+// delivered like a real one: the worker is told to cancel and the run's
+// OnError fires with the timeout text, leaving the entry for ReleaseRun.
+func TestStartIncident_InjectedAgentTimeout(t *testing.T) {
+	handler, conn, cleanup := setupOneshotTest(t)
+	defer cleanup()
+	handler.SetFaultInjector(faultinject.New(map[faultinject.Fault]float64{faultinject.AgentTimeout: 1}))
+
+	errCh := make(chan string, 1)
+	cb := IncidentCallback{
+		OnCompleted: func(string, string, int, int64) { t.Error("injected timeout must not complete the run") },
+		OnError:     func(msg string) { errCh <- msg },
+	}
+
+	runID, err := handler.StartIncident("incident-injected-timeout", "task", nil, nil, nil, cb)
+	if err != nil {
+		t.Fatalf("StartIncident: %v", err)
+	}
+	_ = readNewIncidentRequest(t, conn)
+
+	if err := conn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatalf("set read deadline: %v", err)
+	}
+	var cancel AgentMessage
+	if err := conn.ReadJSON(&cancel); err != nil {
+		t.Fatalf("read cancel frame: %v", err)
+	}
+	if cancel.Type != AgentMessageTypeCancelIncident || cancel.IncidentID != "incident-injected-timeout" {
+		t.Errorf("got %s for %q, want cancel_incident", cancel.Type, cancel.IncidentID)
+	}
+
+	select {
+	case msg := <-errCh:
+		if !strings.Contains(msg, "timed out") {
+			t.Errorf("OnError msg = %q, want a timeout", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnError was not invoked for the injected timeout")
+	}
+	if !handler.ReleaseRun("incident-injected-timeout", runID) {
+		t.Error("ReleaseRun should succeed so the waiter can record the failure")
+	}
+}
+
 // TestStartIncident_NoWorkerReturnsError pins down the pre-condition that
 // makes the per-conn ownership story sound: registration + send happen
 // atomically under h.mu, so a not-yet-connected handler refuses the request
@@ -878,6 +925,34 @@ func TestStartIncident_SupersedingPrefersOnSuperseded(t *testing.T) {
 // through to the blind full_log append. Without this filter, a stale frame
 // from a superseded run that arrives after the replacement run's callback
 // has been deleted would corrupt the incident's full_log.
+func TestHandleAgentOutput_DropsRepeatedSeq(t *testing.T) {
+	handler := NewAgentWSHandler()
+	var got []string
+	handler.callbacks["incident-seq"] = incidentCallbackEntry{
+		callback: IncidentCallback{OnOutput: func(output string) { got = append(got, output) }},
+		runID:    "run-1",
+		lastSeq:  new(atomic.Int64),
+	}
+
+	for _, frame := range []struct {
+		seq    int64
+		output string
+	}{{1, "a"}, {2, "b"}, {2, "b"}, {1, "a"}, {4, "d"}, {0, "unnumbered"}} {
+		handler.handleAgentOutput(AgentMessage{
+			Type:       AgentMessageTypeAgentOutput,
+			IncidentID: "incident-seq",
+			RunID:      "run-1",
+			Output:     frame.output,
+			Seq:        frame.seq,
+		})
+	}
+
+	// Repeats are dropped; a gap and an unnumbered frame still get through.
+	if want := "a,b,d,unnumbered"; strings.Join(got, ",") != want {
+		t.Errorf("delivered = %v, want %s", got, want)
+	}
+}
+
 func TestHandleAgentOutput_NoCallbackWithRunIDDrops(t *testing.T) {
 	// In-memory sqlite so the fallback's UPDATE would succeed if it ran. We
 	// then confirm it did NOT run by reading the row back — full_log must
@@ -886,7 +961,7 @@ func TestHandleAgentOutput_NoCallbackWithRunIDDrops(t *testing.T) {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate incident: %v", err)
 	}
 	prevDB := database.DB
@@ -909,6 +984,7 @@ func TestHandleAgentOutput_NoCallbackWithRunIDDrops(t *testing.T) {
 	if err := db.Where("uuid = ?", "incident-late-output").First(&got).Error; err != nil {
 		t.Fatalf("re-read incident: %v", err)
 	}
+	got.FullLog, _ = database.LoadIncidentLog(db, "incident-late-output")
 	if got.FullLog != "" {
 		t.Errorf("full_log should remain empty when frame has run_id and no callback, got %q", got.FullLog)
 	}
@@ -924,6 +1000,7 @@ func TestHandleAgentOutput_NoCallbackWithRunIDDrops(t *testing.T) {
 	if err := db.Where("uuid = ?", "incident-late-output").First(&got).Error; err != nil {
 		t.Fatalf("re-read incident: %v", err)
 	}
+	got.FullLog, _ = database.LoadIncidentLog(db, "incident-late-output")
 	if got.FullLog != "legacy output" {
 		t.Errorf("legacy fallback should still write full_log, got %q", got.FullLog)
 	}
@@ -938,7 +1015,7 @@ func TestHandleAgentCompleted_NoCallbackWithRunIDDrops(t *testing.T) {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate incident: %v", err)
 	}
 	prevDB := database.DB
@@ -987,7 +1064,7 @@ func TestHandleAgentCompleted_LegacyFallback_EmptyOutputSkipsMetrics(t *testing.
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate incident: %v", err)
 	}
 	prevDB := database.DB
@@ -1028,7 +1105,7 @@ func TestHandleAgentError_NoCallbackWithRunIDDrops(t *testing.T) {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate incident: %v", err)
 	}
 	prevDB := database.DB
@@ -1190,16 +1267,7 @@ func TestFailCallbacksForConn_SkipsFinalizedEntries(t *testing.T) {
 		t.Fatalf("dial: %v", err)
 	}
 	defer wsConn.Close()
-	deadline := time.Now().Add(2 * time.Second)
-	for !handler.IsWorkerConnected() && time.Now().Before(deadline) {
-		time.Sleep(5 * time.Millisecond)
-	}
-	handler.mu.Lock()
-	serverConn := handler.workerConn
-	handler.mu.Unlock()
-	if serverConn == nil {
-		t.Fatal("expected workerConn after dial")
-	}
+	serverConn := waitForServerConn(t, handler, 1)
 
 	errorFired := make(chan string, 1)
 	completedFired := make(chan struct{}, 1)
@@ -1252,38 +1320,14 @@ func TestCleanupWorkerConn_PerConnCallbackRouting(t *testing.T) {
 		t.Fatalf("dial A: %v", err)
 	}
 	defer connA.Close()
-	deadline := time.Now().Add(2 * time.Second)
-	for !handler.IsWorkerConnected() && time.Now().Before(deadline) {
-		time.Sleep(5 * time.Millisecond)
-	}
-
-	handler.mu.Lock()
-	connAServer := handler.workerConn
-	handler.mu.Unlock()
-	if connAServer == nil {
-		t.Fatal("expected workerConn after dial A")
-	}
+	connAServer := waitForServerConn(t, handler, 1)
 
 	connB, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
 		t.Fatalf("dial B: %v", err)
 	}
 	defer connB.Close()
-	deadline = time.Now().Add(2 * time.Second)
-	var connBServer *websocket.Conn
-	for time.Now().Before(deadline) {
-		handler.mu.RLock()
-		current := handler.workerConn
-		handler.mu.RUnlock()
-		if current != nil && current != connAServer {
-			connBServer = current
-			break
-		}
-		time.Sleep(5 * time.Millisecond)
-	}
-	if connBServer == nil {
-		t.Fatal("expected workerConn to flip to B's conn")
-	}
+	connBServer := waitForServerConn(t, handler, 2, connAServer)
 
 	aFiredCh := make(chan string, 1)
 	bFiredCh := make(chan string, 1)
@@ -1326,3 +1370,65 @@ func TestCleanupWorkerConn_PerConnCallbackRouting(t *testing.T) {
 	delete(handler.callbacks, "b-incident")
 	handler.callbackMu.Unlock()
 }
+
+func TestWaitForWorker_NoWaitConfigured(t *testing.T) {
+	handler := NewAgentWSHandler()
+
+	start := time.Now()
+	if handler.WaitForWorker(context.Background()) {
+		t.Fatal("WaitForWorker = true with no worker connected")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("WaitForWorker blocked %v with no connect wait configured", elapsed)
+	}
+}
+
+func TestWaitForWorker_TimesOut(t *testing.T) {
+	handler := NewAgentWSHandler()
+	handler.SetWorkerConnectWait(50 * time.Millisecond)
+
+	if handler.WaitForWorker(context.Background()) {
+		t.Fatal("WaitForWorker = true with no worker connected")
+	}
+}
+
+// TestWaitForWorker_ReturnsWhenWorkerConnects covers an alert arriving while
+// the worker is restarting: the waiter is released by the reconnect, and a
+// subsequent disconnect re-arms the wait.
+func TestWaitForWorker_ReturnsWhenWorkerConnects(t *testing.T) {
+	handler := NewAgentWSHandler()
+	handler.SetWorkerConnectWait(5 * time.Second)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	result := make(chan bool, 1)
+	go func() { result <- handler.WaitForWorker(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial fake worker: %v", err)
+	}
+
+	select {
+	case ok := <-result:
+		if !ok {
+			t.Fatal("WaitForWorker = false after worker connected")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForWorker did not return after worker connected")
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.IsWorkerConnected() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if handler.WaitForWorker(ctx) {
+		t.Error("WaitForWorker = true after worker disconnected")
+	}
+}
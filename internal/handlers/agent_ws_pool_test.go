@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+	"github.com/gorilla/websocket"
+)
+
+// dialPoolWorker connects a fake worker that advertises capacity and waits
+// until the handler has recorded it.
+func dialPoolWorker(t *testing.T, handler *AgentWSHandler, wsURL, id string, capacity int) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", id, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := conn.WriteJSON(AgentMessage{Type: AgentMessageTypeStatus, Data: map[string]interface{}{
+		"status": "ready", "worker_id": id, "capacity": capacity,
+	}}); err != nil {
+		t.Fatalf("send ready: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, w := range handler.Workers() {
+			if w.ID == id {
+				return conn
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("worker %s did not register", id)
+	return nil
+}
+
+func newPoolTestServer(t *testing.T) (*AgentWSHandler, string) {
+	t.Helper()
+	testhelpers.NewGlobalSQLiteDB(t, &database.ProxySettings{})
+	handler := NewAgentWSHandler()
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	t.Cleanup(server.Close)
+	return handler, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestAgentWSHandler_DispatchesToLeastLoadedWorker(t *testing.T) {
+	handler, wsURL := newPoolTestServer(t)
+	small := dialPoolWorker(t, handler, wsURL, "small", 1)
+	large := dialPoolWorker(t, handler, wsURL, "large", 4)
+
+	// Both idle: the longest-connected worker wins the tie. Then "small" is
+	// full, so the next runs go to "large" even though it has runs too.
+	for i, want := range []*websocket.Conn{small, large, large} {
+		incidentID := []string{"inc-1", "inc-2", "inc-3"}[i]
+		if _, err := handler.StartIncident(incidentID, "task", nil, nil, nil, IncidentCallback{}); err != nil {
+			t.Fatalf("StartIncident %s: %v", incidentID, err)
+		}
+		if got := readNewIncidentRequest(t, want); got.IncidentID != incidentID {
+			t.Fatalf("run %d went to the wrong worker: got %q", i, got.IncidentID)
+		}
+	}
+
+	runs := map[string]int{}
+	for _, w := range handler.Workers() {
+		runs[w.ID] = w.ActiveRuns
+	}
+	if runs["small"] != 1 || runs["large"] != 2 {
+		t.Errorf("active runs = %v, want small=1 large=2", runs)
+	}
+}
+
+func TestAgentWSHandler_RedispatchesRunsOfDisconnectedWorker(t *testing.T) {
+	handler, wsURL := newPoolTestServer(t)
+	first := dialPoolWorker(t, handler, wsURL, "first", 4)
+	second := dialPoolWorker(t, handler, wsURL, "second", 4)
+
+	outputs := make(chan string, 4)
+	errs := make(chan string, 1)
+	completed := make(chan string, 1)
+	runID, err := handler.StartIncident("inc-1", "task", nil, nil, nil, IncidentCallback{
+		OnOutput:    func(out string) { outputs <- out },
+		OnError:     func(msg string) { errs <- msg },
+		OnCompleted: func(_, response string, _ int, _ int64) { completed <- response },
+	})
+	if err != nil {
+		t.Fatalf("StartIncident: %v", err)
+	}
+	readNewIncidentRequest(t, first)
+
+	first.Close()
+	resent := readNewIncidentRequest(t, second)
+	if resent.IncidentID != "inc-1" || resent.RunID != runID {
+		t.Fatalf("re-dispatched frame = %+v, want inc-1 with run_id %s", resent, runID)
+	}
+	select {
+	case out := <-outputs:
+		if !strings.Contains(out, "restarted on another worker") {
+			t.Errorf("output note = %q", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no re-dispatch note on the run's output")
+	}
+
+	handler.handleAgentCompleted(AgentMessage{Type: AgentMessageTypeAgentCompleted, IncidentID: "inc-1", RunID: runID, Output: "done"})
+	select {
+	case msg := <-errs:
+		t.Fatalf("run failed instead of moving to the second worker: %s", msg)
+	case got := <-completed:
+		if got != "done" {
+			t.Errorf("completed response = %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("run never completed")
+	}
+}
+
+// readWorkerFrame reads frames from a fake worker until one of type typ.
+func readWorkerFrame(t *testing.T, conn *websocket.Conn, typ AgentMessageType) AgentMessage {
+	t.Helper()
+	if err := conn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatalf("set read deadline: %v", err)
+	}
+	for {
+		var msg AgentMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read %s frame: %v", typ, err)
+		}
+		if msg.Type == typ {
+			return msg
+		}
+	}
+}
+
+func TestAgentWSHandler_ResumesRunReportedByRestartedWorker(t *testing.T) {
+	handler, wsURL := newPoolTestServer(t)
+	handler.SetWorkerConnectWait(5 * time.Second)
+	worker := dialPoolWorker(t, handler, wsURL, "worker", 4)
+
+	outputs := make(chan string, 4)
+	errs := make(chan string, 1)
+	runID, err := handler.StartIncident("inc-1", "task", &LLMSettingsForWorker{Provider: "openai", APIKey: "sk", Model: "m"}, []string{"zabbix"}, nil, IncidentCallback{
+		OnOutput: func(out string) { outputs <- out },
+		OnError:  func(msg string) { errs <- msg },
+	})
+	if err != nil {
+		t.Fatalf("StartIncident: %v", err)
+	}
+	readNewIncidentRequest(t, worker)
+
+	// The worker restarts: the run is held rather than failed, and the new
+	// process reports it with its saved session.
+	worker.Close()
+	testhelpers.AssertEventually(t, 2*time.Second, 5*time.Millisecond, func() bool { return handler.WorkerCount() == 0 }, "old worker is dropped")
+	restarted := dialPoolWorker(t, handler, wsURL, "worker-restarted", 4)
+	if err := restarted.WriteJSON(AgentMessage{Type: AgentMessageTypeAgentInterrupted, IncidentID: "inc-1", RunID: runID,
+		SessionID: "inc-1", Data: map[string]interface{}{"output_offset": 120}}); err != nil {
+		t.Fatalf("send agent_interrupted: %v", err)
+	}
+
+	resumed := readWorkerFrame(t, restarted, AgentMessageTypeContinueIncident)
+	if resumed.IncidentID != "inc-1" || resumed.RunID != runID || resumed.SessionID != "inc-1" || resumed.Message != workerResumePrompt {
+		t.Errorf("resume frame = %+v", resumed)
+	}
+	if resumed.APIKey != "sk" || len(resumed.EnabledSkills) != 1 {
+		t.Errorf("resume frame lost the run's settings: %+v", resumed)
+	}
+	select {
+	case out := <-outputs:
+		if !strings.Contains(out, "resumed from the saved session") {
+			t.Errorf("output note = %q", out)
+		}
+	case msg := <-errs:
+		t.Fatalf("run failed instead of resuming: %s", msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no resume note on the run's output")
+	}
+	if runs := handler.Workers(); len(runs) != 1 || runs[0].ActiveRuns != 1 {
+		t.Errorf("workers = %+v, want the restarted worker owning the run", runs)
+	}
+}
+
+func TestAgentWSHandler_FailsInterruptedRunWithoutSession(t *testing.T) {
+	handler, wsURL := newPoolTestServer(t)
+	handler.SetWorkerConnectWait(5 * time.Second)
+	worker := dialPoolWorker(t, handler, wsURL, "worker", 4)
+
+	errs := make(chan string, 1)
+	runID, err := handler.StartIncident("inc-1", "task", nil, nil, nil, IncidentCallback{OnError: func(msg string) { errs <- msg }})
+	if err != nil {
+		t.Fatalf("StartIncident: %v", err)
+	}
+	readNewIncidentRequest(t, worker)
+
+	worker.Close()
+	testhelpers.AssertEventually(t, 2*time.Second, 5*time.Millisecond, func() bool { return handler.WorkerCount() == 0 }, "old worker is dropped")
+	restarted := dialPoolWorker(t, handler, wsURL, "worker-restarted", 4)
+	if err := restarted.WriteJSON(AgentMessage{Type: AgentMessageTypeAgentInterrupted, IncidentID: "inc-1", RunID: runID}); err != nil {
+		t.Fatalf("send agent_interrupted: %v", err)
+	}
+	select {
+	case msg := <-errs:
+		if msg != errWorkerRestarted {
+			t.Errorf("error = %q, want %q", msg, errWorkerRestarted)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("interrupted run without a session was not failed")
+	}
+}
+
+func TestAgentWSHandler_FailsOrphanedInterruptedIncident(t *testing.T) {
+	handler, wsURL := newPoolTestServer(t)
+	db := database.GetDB()
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&database.Incident{UUID: "inc-running", Status: database.IncidentStatusRunning})
+	db.Create(&database.Incident{UUID: "inc-done", Status: database.IncidentStatusCompleted, Response: "done"})
+
+	// No run is waiting (the API restarted as well): a running incident is
+	// failed, a finished one is left alone.
+	worker := dialPoolWorker(t, handler, wsURL, "worker", 4)
+	for _, id := range []string{"inc-running", "inc-done"} {
+		if err := worker.WriteJSON(AgentMessage{Type: AgentMessageTypeAgentInterrupted, IncidentID: id, RunID: "run-" + id, SessionID: id}); err != nil {
+			t.Fatalf("send agent_interrupted: %v", err)
+		}
+	}
+
+	testhelpers.AssertEventually(t, 2*time.Second, 10*time.Millisecond, func() bool {
+		var incident database.Incident
+		return db.Where("uuid = ?", "inc-running").First(&incident).Error == nil && incident.Status == database.IncidentStatusFailed
+	}, "orphaned running incident is failed")
+	var done database.Incident
+	db.Where("uuid = ?", "inc-done").First(&done)
+	if done.Status != database.IncidentStatusCompleted || done.Response != "done" {
+		t.Errorf("finished incident = %s %q, want it untouched", done.Status, done.Response)
+	}
+}
@@ -72,6 +72,26 @@ func TestBuildLLMSettingsForWorker_ActiveConfig(t *testing.T) {
 	testhelpers.AssertEqual(t, "https://custom.api.example.com", result.BaseURL, "base url")
 }
 
+func TestBuildLLMSettingsForWorker_SelfHosted(t *testing.T) {
+	settings := &database.LLMSettings{
+		Name:          "On-prem vLLM",
+		Provider:      database.LLMProviderSelfHosted,
+		Model:         "qwen3-32b",
+		BaseURL:       "http://vllm:8000/v1",
+		ContextWindow: 32768,
+		Enabled:       true,
+	}
+	result := BuildLLMSettingsForWorker(settings)
+	testhelpers.AssertNotNil(t, result, "self-hosted config without a key should return non-nil")
+	testhelpers.AssertEqual(t, "custom", result.Provider, "self-hosted runs as custom")
+	testhelpers.AssertEqual(t, "self-hosted", result.APIKey, "placeholder api key")
+	testhelpers.AssertEqual(t, "http://vllm:8000/v1", result.BaseURL, "base url")
+	testhelpers.AssertEqual(t, 32768, result.ContextWindow, "context window")
+
+	settings.APIKey = "vllm-key"
+	testhelpers.AssertEqual(t, "vllm-key", BuildLLMSettingsForWorker(settings).APIKey, "configured api key")
+}
+
 func TestBuildLLMSettingsForWorker_AllProviders(t *testing.T) {
 	providers := []struct {
 		provider database.LLMProvider
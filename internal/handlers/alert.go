@@ -6,13 +6,16 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/config"
+	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/faultinject"
+	"github.com/akmatori/akmatori/internal/metrics"
 	"github.com/akmatori/akmatori/internal/services"
 	slackutil "github.com/akmatori/akmatori/internal/slack"
 	"golang.org/x/sync/singleflight"
@@ -50,6 +53,68 @@ type AlertHandler struct {
 	providerRegistry  services.ProviderRegistry
 	alertCorrelator   *services.AlertCorrelator
 
+	// investigationScheduler bounds concurrent alert investigations and
+	// lets critical alerts preempt lower-priority ones (optional).
+	investigationScheduler *services.InvestigationScheduler
+
+	// notificationRenderer applies operator templates to outbound
+	// notification text (optional; built-in templates when nil).
+	notificationRenderer services.NotificationRenderer
+
+	// promptTemplates applies operator investigation prompt templates
+	// (optional; built-in prompt when nil).
+	promptTemplates services.PromptRenderer
+
+	// faults simulates adapter parse errors in failure-injection mode
+	// (optional; nil never injects).
+	faults *faultinject.Injector
+
+	// health receives webhook outcomes for the self-monitor's failure-rate
+	// check (optional).
+	health services.HealthSignalRecorder
+
+	// incidentLinks records correlator-detected parent/child links and
+	// answers whether a new incident's parent is still open (optional).
+	incidentLinks services.IncidentLinker
+
+	// timeline records Slack posts on the incident timeline (optional).
+	timeline services.IncidentTimelineRecorder
+
+	// enrichment adds CMDB, change, history and runbook context to the
+	// investigation prompt (optional).
+	enrichment *services.EnrichmentPipeline
+
+	// silencer suppresses firing alerts that fall in a maintenance window
+	// (optional; nil investigates everything).
+	silencer services.AlertSilencer
+
+	// router applies routing rules to firing alerts (optional; nil gives
+	// every alert its source's settings).
+	router services.AlertRouter
+
+	// planner receives the plans of two-phase investigations (optional;
+	// nil runs every investigation in a single phase).
+	planner services.RemediationPlanner
+
+	// confidence acts on the confidence alert investigations report
+	// (optional; nil ignores it).
+	confidence services.ConfidenceRouter
+
+	// dedup attaches alerts to the open incident that already has their
+	// source fingerprint, before the correlator runs (optional).
+	dedup services.AlertDeduplicator
+
+	// stormGuard coalesces bursts of identical alerts and groups a flapping
+	// source's alerts into one incident (optional).
+	stormGuard *services.AlertStormGuard
+
+	// severityInferrer fills in severities sources omitted (optional).
+	severityInferrer services.AlertSeverityInferrer
+
+	// budget declines investigations once an LLM budget is spent
+	// (optional; nil never declines).
+	budget services.BudgetGuard
+
 	// spawnGroup deduplicates concurrent alerts with the same
 	// (sourceUUID, alertName, targetHost) key so only one incident is created.
 	spawnGroup singleflight.Group
@@ -106,6 +171,16 @@ func (h *AlertHandler) SetResponseFormatter(f *services.ResponseFormatter) {
 	h.responseFormatter = f
 }
 
+// SetFaultInjector enables simulated adapter parse errors on the webhook path.
+func (h *AlertHandler) SetFaultInjector(f *faultinject.Injector) {
+	h.faults = f
+}
+
+// SetHealthRecorder reports webhook outcomes to the self-monitor.
+func (h *AlertHandler) SetHealthRecorder(r services.HealthSignalRecorder) {
+	h.health = r
+}
+
 // SetChannelService wires the ChannelManager used to resolve outbound channels
 // from alert source instances. When unset, outbound Slack posting is skipped.
 func (h *AlertHandler) SetChannelService(c services.ChannelManager) {
@@ -126,6 +201,50 @@ func (h *AlertHandler) SetAlertCorrelator(c *services.AlertCorrelator) {
 	h.alertCorrelator = c
 }
 
+// SetIncidentLinker wires the IncidentLinker used to file cascading alerts
+// under the incident that caused them. Optional — when nil the correlator's
+// parent suggestions are ignored.
+func (h *AlertHandler) SetIncidentLinker(l services.IncidentLinker) {
+	h.incidentLinks = l
+}
+
+// SetIncidentTimeline wires the recorder that puts alert Slack posts on the
+// incident timeline. Optional — when nil nothing is recorded.
+func (h *AlertHandler) SetIncidentTimeline(r services.IncidentTimelineRecorder) {
+	h.timeline = r
+}
+
+// SetEnrichmentPipeline wires the steps that run between alert
+// normalization and the investigation prompt. Optional — when nil the
+// prompt carries only the alert itself.
+func (h *AlertHandler) SetEnrichmentPipeline(p *services.EnrichmentPipeline) {
+	h.enrichment = p
+}
+
+// SetAlertSilencer wires the maintenance-window check run before an alert
+// spawns an incident. Optional.
+func (h *AlertHandler) SetAlertSilencer(s services.AlertSilencer) {
+	h.silencer = s
+}
+
+// silenced reports whether a firing alert falls in an active silence. A
+// failed lookup investigates the alert rather than risk dropping it.
+func (h *AlertHandler) silenced(instance *database.AlertSourceInstance, normalized alerts.NormalizedAlert) bool {
+	if h.silencer == nil {
+		return false
+	}
+	silence, err := h.silencer.SilenceAlert(context.Background(), instance.UUID, normalized)
+	if err != nil {
+		slog.Warn("silence check failed, investigating alert", "alert_name", normalized.AlertName, "err", err)
+		return false
+	}
+	if silence == nil {
+		return false
+	}
+	slog.Info("alert suppressed by silence", "alert_name", normalized.AlertName, "target_host", normalized.TargetHost, "silence_uuid", silence.UUID)
+	return true
+}
+
 // correlate delegates to the wired AlertCorrelator when present; otherwise
 // returns a no-match verdict (fail-open).
 func (h *AlertHandler) correlate(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (services.CorrelationVerdict, error) {
@@ -153,33 +272,53 @@ func (h *AlertHandler) RegisterAdapter(adapter alerts.AlertAdapter) {
 }
 
 // HandleWebhook processes incoming webhook requests
-// Route: /webhook/alert/{instance_uuid}
+// Route: POST /webhook/alert/{uuid}; the instance UUID is read from the uuid
+// path value, so the handler must be registered under a pattern naming it.
 func (h *AlertHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if h.health == nil {
+		h.handleWebhook(w, r)
 		return
 	}
+	rec := &webhookStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.handleWebhook(rec, r)
+	// Unparseable payloads and server errors count as failures. Unknown
+	// instances, bad secrets and allowlist rejections are the sender's
+	// problem, and counting them would let a scanner raise the alarm.
+	h.health.RecordWebhook(rec.status == http.StatusBadRequest || rec.status >= http.StatusInternalServerError)
+}
 
-	// Extract instance UUID from path
-	path := strings.TrimPrefix(r.URL.Path, "/webhook/alert/")
-	instanceUUID := strings.TrimSuffix(path, "/")
+// webhookStatusRecorder captures the status code a webhook was answered with.
+type webhookStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
 
-	if instanceUUID == "" {
-		http.Error(w, "Missing instance UUID", http.StatusBadRequest)
-		return
-	}
+func (r *webhookStatusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (h *AlertHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	instanceUUID := r.PathValue("uuid")
 
 	// Look up instance
 	instance, err := h.alertService.GetInstanceByUUID(instanceUUID)
 	if err != nil {
-		slog.Error("alert instance not found", "instance_uuid", instanceUUID, "err", err)
-		http.Error(w, "Instance not found", http.StatusNotFound)
+		slog.ErrorContext(r.Context(), "alert instance not found", "instance_uuid", instanceUUID, "err", err)
+		api.RespondError(w, http.StatusNotFound, "Instance not found")
 		return
 	}
 
 	if !instance.Enabled {
-		slog.Warn("alert instance disabled", "instance_uuid", instanceUUID)
-		http.Error(w, "Instance disabled", http.StatusForbidden)
+		slog.WarnContext(r.Context(), "alert instance disabled", "instance_uuid", instanceUUID)
+		api.RespondError(w, http.StatusForbidden, "Instance disabled")
+		return
+	}
+
+	// Source-IP allowlist is checked before any adapter code runs
+	if clientIP := api.ClientIP(r); !services.SourceIPAllowed(instance.Settings, clientIP) {
+		slog.WarnContext(r.Context(), "webhook source IP not in allowlist", "instance_uuid", instanceUUID, "remote_ip", clientIP)
+		api.RespondError(w, http.StatusForbidden, "Forbidden")
 		return
 	}
 
@@ -188,15 +327,15 @@ func (h *AlertHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	adapter, ok := h.adapters[instance.AlertSourceType.Name]
 	h.adaptersMu.RUnlock()
 	if !ok {
-		slog.Error("no adapter for source type", "source_type", instance.AlertSourceType.Name)
-		http.Error(w, "Unsupported source type", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "no adapter for source type", "source_type", instance.AlertSourceType.Name)
+		api.RespondError(w, http.StatusBadRequest, "Unsupported source type")
 		return
 	}
 
-	// Validate webhook secret
-	if err := adapter.ValidateWebhookSecret(r, instance); err != nil {
-		slog.Warn("webhook secret validation failed", "instance_uuid", instanceUUID, "err", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	// Verify the webhook secret or signature
+	if err := services.VerifyWebhook(r, instance, adapter); err != nil {
+		slog.WarnContext(r.Context(), "webhook secret validation failed", "instance_uuid", instanceUUID, "err", err)
+		api.RespondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
@@ -205,20 +344,31 @@ func (h *AlertHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
 	if err != nil {
-		slog.Error("failed to read webhook body", "err", err)
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "failed to read webhook body", "err", err)
+		api.RespondError(w, http.StatusBadRequest, "Failed to read request body")
 		return
 	}
 
 	// Parse payload into normalized alerts
 	normalizedAlerts, err := adapter.ParsePayload(body, instance)
+	if err == nil && h.faults.Should(faultinject.AdapterParseError) {
+		normalizedAlerts, err = nil, faultinject.Error(faultinject.AdapterParseError, "simulated "+instance.AlertSourceType.Name+" payload parse failure")
+	}
 	if err != nil {
-		slog.Error("failed to parse alert payload", "err", err)
-		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "failed to parse alert payload", "err", err)
+		metrics.WebhookPayloadError(instance.AlertSourceType.Name)
+		api.RespondError(w, http.StatusBadRequest, "Invalid payload")
 		return
 	}
+	metrics.WebhookAlertsReceived(instance.AlertSourceType.Name, len(normalizedAlerts))
 
-	slog.Info("received alerts", "count", len(normalizedAlerts), "source_type", instance.AlertSourceType.Name, "instance", instance.Name)
+	slog.InfoContext(r.Context(), "received alerts", "count", len(normalizedAlerts), "source_type", instance.AlertSourceType.Name, "instance", instance.Name)
+
+	// Feeds the self-monitor's silent-source check; a failed write only
+	// risks a false "gone quiet" notice, so the alerts are still processed.
+	if err := h.alertService.MarkInstanceAlertReceived(instance.ID, time.Now()); err != nil {
+		slog.WarnContext(r.Context(), "failed to record alert receipt", "instance", instance.Name, "err", err)
+	}
 
 	// Process each alert
 	for _, normalizedAlert := range normalizedAlerts {
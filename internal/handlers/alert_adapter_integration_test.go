@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -226,6 +230,13 @@ func TestAlertAdapters_ConcurrentParsing(t *testing.T) {
 
 // TestWebhookSecretValidation_AllAdapters tests secret validation consistency
 func TestWebhookSecretValidation_AllAdapters(t *testing.T) {
+	// Signed deliveries are refused when replayed, so each run signs a
+	// body of its own.
+	body := fmt.Sprintf(`{"event":{"id":"%d"}}`, time.Now().UnixNano())
+	mac := hmac.New(sha256.New, []byte("pd-token"))
+	mac.Write([]byte(body))
+	pdSignature := "v1=" + hex.EncodeToString(mac.Sum(nil))
+
 	tests := []struct {
 		name        string
 		adapter     alerts.AlertAdapter
@@ -245,15 +256,17 @@ func TestWebhookSecretValidation_AllAdapters(t *testing.T) {
 		{"grafana_bearer", adapters.NewGrafanaAdapter(), "grafana-key", "Authorization", "Bearer grafana-key", true},
 
 		// Datadog variations
-		{"datadog_api_key", adapters.NewDatadogAdapter(), "dd-api-key", "DD-API-KEY", "dd-api-key", true},
-		{"datadog_signature", adapters.NewDatadogAdapter(), "dd-secret", "X-Datadog-Signature", "dd-secret", true},
+		{"datadog_custom_header", adapters.NewDatadogAdapter(), "dd-secret", "X-Datadog-Secret", "dd-secret", true},
+		{"datadog_bearer", adapters.NewDatadogAdapter(), "dd-secret", "Authorization", "Bearer dd-secret", true},
+		{"datadog_api_key_header", adapters.NewDatadogAdapter(), "dd-secret", "DD-API-KEY", "dd-secret", false},
 
 		// Zabbix variations (only supports X-Zabbix-Secret header)
 		{"zabbix_custom_header", adapters.NewZabbixAdapter(), "zabbix-key", "X-Zabbix-Secret", "zabbix-key", true},
 		{"zabbix_wrong_header", adapters.NewZabbixAdapter(), "zabbix-key", "Authorization", "zabbix-key", false},
 
 		// PagerDuty variations (signature format: v1=<hmac> or Bearer token)
-		{"pagerduty_signature", adapters.NewPagerDutyAdapter(), "pd-token", "X-PagerDuty-Signature", "v1=abc123", true},
+		{"pagerduty_signature", adapters.NewPagerDutyAdapter(), "pd-token", "X-PagerDuty-Signature", pdSignature, true},
+		{"pagerduty_wrong_signature", adapters.NewPagerDutyAdapter(), "pd-token", "X-PagerDuty-Signature", "v1=abc123", false},
 		{"pagerduty_bearer", adapters.NewPagerDutyAdapter(), "pd-token", "Authorization", "Bearer pd-token", true},
 	}
 
@@ -263,7 +276,7 @@ func TestWebhookSecretValidation_AllAdapters(t *testing.T) {
 				WebhookSecret: tt.secret,
 			}
 
-			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
 			if tt.headerName != "" {
 				req.Header.Set(tt.headerName, tt.headerValue)
 			}
@@ -295,7 +308,7 @@ func setupWebhookHandlerIntegrationDB(t *testing.T) (*services.AlertService, fun
 	if err := db.AutoMigrate(
 		&database.AlertSourceType{},
 		&database.AlertSourceInstance{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 		&database.SlackSettings{},
 	); err != nil {
@@ -366,7 +379,7 @@ func TestWebhookHandler_AlertmanagerEndpointUsesRealServiceAndAdapter(t *testing
 			req.Header.Set("X-Alertmanager-Secret", tt.secret)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			if w.Code != tt.expectedStatus {
 				t.Fatalf("status = %d, want %d; body=%q", w.Code, tt.expectedStatus, w.Body.String())
@@ -442,7 +455,7 @@ func TestWebhookHandler_AlertmanagerBatchCreatesIncidentsForFiringAlerts(t *test
 	req.Header.Set("X-Alertmanager-Secret", "webhook-secret")
 	w := httptest.NewRecorder()
 
-	h.HandleWebhook(w, req)
+	serveWebhook(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("status = %d, want %d; body=%q", w.Code, http.StatusOK, w.Body.String())
@@ -519,6 +532,8 @@ func TestWebhookHandler_NonAlertmanagerAdaptersCreateIncidents(t *testing.T) {
 	h.RegisterAdapter(adapters.NewDatadogAdapter())
 	h.RegisterAdapter(adapters.NewZabbixAdapter())
 	h.RegisterAdapter(adapters.NewPagerDutyAdapter())
+	h.RegisterAdapter(adapters.NewVictorOpsAdapter())
+	h.RegisterAdapter(adapters.NewSentryAdapter())
 
 	tests := []struct {
 		name           string
@@ -545,7 +560,7 @@ func TestWebhookHandler_NonAlertmanagerAdaptersCreateIncidents(t *testing.T) {
 		{
 			name:           "datadog triggered monitor",
 			sourceType:     "datadog",
-			secretHeader:   "DD-API-KEY",
+			secretHeader:   "X-Datadog-Secret",
 			secretValue:    "datadog-secret",
 			body:           "{\"id\":\"event-123\",\"alert_id\":\"monitor-456\",\"alert_cycle_key\":\"datadog-cycle-1\",\"title\":\"Datadog CPU Monitor\",\"body\":\"CPU is above 90% on checkout host\",\"alert_type\":\"error\",\"alert_status\":\"Triggered\",\"hostname\":\"checkout-01\",\"tags\":[\"service:checkout\",\"env:prod\"]}",
 			wantSourceID:   "datadog-cycle-1",
@@ -575,6 +590,28 @@ func TestWebhookHandler_NonAlertmanagerAdaptersCreateIncidents(t *testing.T) {
 			wantTargetHost: "primary-db",
 			wantSeverity:   database.AlertSeverityCritical,
 		},
+		{
+			name:           "victorops critical alert",
+			sourceType:     "victorops",
+			secretHeader:   "X-VictorOps-Secret",
+			secretValue:    "victorops-secret",
+			body:           "{\"ALERT\":{\"message_type\":\"CRITICAL\",\"entity_id\":\"disk/queue-01\",\"entity_display_name\":\"Queue Disk Full\",\"state_message\":\"Disk usage at 98%\",\"host_name\":\"queue-01\",\"routing_key\":\"ops\"},\"INCIDENT\":{\"INCIDENT_ID\":\"1234\",\"CURRENT_PHASE\":\"UNACKED\"}}",
+			wantSourceID:   "disk/queue-01",
+			wantAlertName:  "Queue Disk Full",
+			wantTargetHost: "queue-01",
+			wantSeverity:   database.AlertSeverityCritical,
+		},
+		{
+			name:           "sentry issue alert",
+			sourceType:     "sentry",
+			secretHeader:   "Authorization",
+			secretValue:    "Bearer sentry-secret",
+			body:           "{\"id\":\"4512345678\",\"project\":\"checkout-api\",\"level\":\"fatal\",\"culprit\":\"app.payments.charge\",\"message\":\"ZeroDivisionError\",\"event\":{\"title\":\"ZeroDivisionError: division by zero\",\"tags\":[[\"server_name\",\"checkout-01\"]]}}",
+			wantSourceID:   "4512345678",
+			wantAlertName:  "ZeroDivisionError: division by zero",
+			wantTargetHost: "checkout-01",
+			wantSeverity:   database.AlertSeverityCritical,
+		},
 	}
 
 	for _, tt := range tests {
@@ -595,7 +632,7 @@ func TestWebhookHandler_NonAlertmanagerAdaptersCreateIncidents(t *testing.T) {
 			req.Header.Set(tt.secretHeader, tt.secretValue)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			if w.Code != http.StatusOK {
 				t.Fatalf("status = %d, want %d; body=%q", w.Code, http.StatusOK, w.Body.String())
@@ -833,7 +870,7 @@ func TestWebhookHandler_ErrorRecovery(t *testing.T) {
 			req := httptest.NewRequest(tt.method, tt.path, nil)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			testhelpers.AssertEqual(t, tt.expectedStatus, w.Code, "status code")
 		})
@@ -908,7 +945,7 @@ func BenchmarkAlertHandler_ConcurrentRequests(b *testing.B) {
 		for pb.Next() {
 			req := httptest.NewRequest(http.MethodGet, "/webhook/alert/test", nil)
 			w := httptest.NewRecorder()
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 		}
 	})
 }
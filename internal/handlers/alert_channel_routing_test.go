@@ -396,7 +396,7 @@ func TestAlertHandler_UpdateSlackWithResult_NoOpWhenChannelEmpty(t *testing.T) {
 	// Intentionally pass nil slackManager via NewAlertHandler — calling
 	// updateSlackWithResult with empty channelID must early-return BEFORE we
 	// try to dereference slackManager.GetClient().
-	h.updateSlackWithResult("", "ts-1", "ignored", false)
-	h.updateSlackWithResult("C123", "", "ignored", false)
+	h.updateSlackWithResult("", "", "ts-1", "ignored", database.SlackReactions{}, false)
+	h.updateSlackWithResult("", "C123", "", "ignored", database.SlackReactions{}, false)
 	// Reaching here without nil-deref means the early-returns held.
 }
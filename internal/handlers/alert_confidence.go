@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/output"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetConfidenceRouter wires confidence scoring for alert investigations.
+// Optional — when unset the agent is not asked for a confidence score and
+// results are posted as they are.
+func (h *AlertHandler) SetConfidenceRouter(r services.ConfidenceRouter) {
+	h.confidence = r
+}
+
+// withConfidenceInstructions asks the agent to end its response with a
+// confidence block when confidence scoring is wired.
+func (h *AlertHandler) withConfidenceInstructions(prompt string) string {
+	if h.confidence == nil {
+		return prompt
+	}
+	return prompt + "\n\n" + services.ConfidenceInstructions()
+}
+
+// routeByConfidence stores the confidence of a finished investigation and
+// returns the Slack message asking for a review, or "" when none is needed.
+func (h *AlertHandler) routeByConfidence(incidentUUID string, confidence *output.Confidence, severity database.AlertSeverity) string {
+	if h.confidence == nil {
+		return ""
+	}
+	decision, err := h.confidence.RouteByConfidence(context.Background(), incidentUUID, confidence, severity)
+	if err != nil {
+		slog.Error("failed to route investigation by confidence", "incident_uuid", incidentUUID, "err", err)
+		return ""
+	}
+	if decision.Action != services.ConfidenceActionNone {
+		slog.Info("routed investigation by confidence", "incident_uuid", incidentUUID, "score", confidence.Score, "action", decision.Action)
+	}
+	return decision.ReviewMessage
+}
@@ -89,7 +89,10 @@ func (s *corrGateSkillService) UpdateIncidentStatus(string, database.IncidentSta
 func (s *corrGateSkillService) UpdateIncidentComplete(string, database.IncidentStatus, string, string, string, int, int64) error {
 	return nil
 }
-func (s *corrGateSkillService) UpdateIncidentLog(string, string) error         { return nil }
+func (s *corrGateSkillService) UpdateIncidentLog(string, string) error { return nil }
+func (s *corrGateSkillService) ReadIncidentLog(string, int, int) (string, int, error) {
+	return "", 0, nil
+}
 func (s *corrGateSkillService) GetIncident(string) (*database.Incident, error) { return nil, nil }
 func (s *corrGateSkillService) AppendSubagentLog(string, string, string) error { return nil }
 func (s *corrGateSkillService) CreateSkill(string, string, string, string) (*database.Skill, error) {
@@ -126,8 +129,15 @@ func (s *corrGateSkillService) UnlinkAlertFromIncident(context.Context, string)
 func (s *corrGateSkillService) MoveAlertToIncident(context.Context, string, string) (string, error) {
 	return "", nil
 }
-func (s *corrGateSkillService) ResolveAlert(context.Context, string) error        { return nil }
-func (s *corrGateSkillService) CloseIncident(context.Context, string, bool) error { return nil }
+func (s *corrGateSkillService) ResolveAlert(context.Context, string) error           { return nil }
+func (s *corrGateSkillService) CloseIncident(context.Context, string, bool) error    { return nil }
+func (s *corrGateSkillService) CancelIncident(context.Context, string, string) error { return nil }
+func (s *corrGateSkillService) BeginFollowUp(string, string) (*database.Incident, error) {
+	return nil, nil
+}
+func (s *corrGateSkillService) BeginRetry(string, string) (*database.Incident, error) {
+	return nil, nil
+}
 
 // corrOneShotLLMCaller is a configurable stub for services.OneShotLLMCaller.
 type corrOneShotLLMCaller struct {
@@ -152,7 +162,7 @@ func (c *corrOneShotLLMCaller) callCount() int {
 func setupCorrelatorHandlerDB(t *testing.T) *gorm.DB {
 	t.Helper()
 	db := testhelpers.NewGlobalSQLiteDB(t,
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 		&database.LLMSettings{},
 		&database.SlackSettings{},
@@ -223,7 +233,7 @@ func newCorrTestAlert() alerts.NormalizedAlert {
 func TestAlertHandler_Singleflight_15ConcurrentAlerts(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
 		&database.SlackSettings{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 
@@ -368,6 +378,48 @@ func TestAlertHandler_BelowThresholdVerdict_Spawns(t *testing.T) {
 	}
 }
 
+// TestAlertHandler_CascadingParent_LinksChild verifies that a confident parent
+// verdict still spawns a new incident and files it under the parent.
+func TestAlertHandler_CascadingParent_LinksChild(t *testing.T) {
+	db := setupCorrelatorHandlerDB(t)
+	if err := db.AutoMigrate(&database.IncidentLink{}); err != nil {
+		t.Fatalf("migrate incident links: %v", err)
+	}
+	seedHandlerIncident(t, db, "db-outage", "Primary database down", "running", 5*time.Minute)
+	seedHandlerIncident(t, db, "child-incident", "API errors", "pending", 0)
+	seedCorrHandlerSettings(t, db)
+
+	caller := &corrOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return `{"correlated":false,"incident_uuid":"","confidence":0.1,"parent_incident_uuid":"db-outage","parent_confidence":0.9,"reasoning":"api depends on db"}`, nil
+	}
+
+	svc := &corrGateSkillService{spawnUUID: "child-incident"}
+	h := NewAlertHandler(nil, nil, nil, nil, svc, nil, nil)
+	h.SetAlertCorrelator(services.NewAlertCorrelator(caller, db))
+	links := services.NewIncidentLinkService(db)
+	h.SetIncidentLinker(links)
+
+	instance := &database.AlertSourceInstance{
+		UUID:            "src-1",
+		Name:            "test-source",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "prometheus", DisplayName: "Prometheus"},
+	}
+	h.processAlert(instance, newCorrTestAlert())
+
+	if svc.getSpawnCount() != 1 || svc.getLinkCount() != 0 {
+		t.Fatalf("expected a new incident, got spawns=%d links=%d", svc.getSpawnCount(), svc.getLinkCount())
+	}
+	rel, err := links.GetRelations(context.Background(), "child-incident")
+	if err != nil {
+		t.Fatalf("GetRelations: %v", err)
+	}
+	if rel.Parent == nil || rel.Parent.UUID != "db-outage" || rel.Parent.Origin != database.IncidentLinkOriginCorrelator {
+		t.Errorf("expected correlator parent link to db-outage, got %+v", rel.Parent)
+	}
+}
+
 // TestAlertHandler_WorkerNotConnected_Spawns verifies that ErrWorkerNotConnected
 // from the correlator is treated as "no correlation" — the alert still spawns
 // a new incident (fail-open behavior).
@@ -412,7 +464,7 @@ func TestAlertHandler_WorkerNotConnected_Spawns(t *testing.T) {
 func TestAlertHandler_NilCorrelator_AlwaysSpawns(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
 		&database.SlackSettings{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetAlertDeduplicator wires the exact source-fingerprint match run before
+// correlation. Optional — when unset every alert goes to the correlator.
+func (h *AlertHandler) SetAlertDeduplicator(d services.AlertDeduplicator) {
+	h.dedup = d
+}
+
+// applyGroupingKey replaces the alert's source fingerprint with the
+// rendering of the source's grouping_key_template, for systems whose own
+// fingerprints are inconsistent. Dedup, storm coalescing and resolve
+// matching all key on the result. A bad or blank rendering keeps the
+// source's fingerprint.
+func applyGroupingKey(instance *database.AlertSourceInstance, normalized *alerts.NormalizedAlert) {
+	template, err := services.ParseGroupingKeyTemplate(instance.Settings)
+	if err != nil {
+		slog.Warn("ignoring invalid grouping_key_template setting", "source", instance.Name, "err", err)
+		return
+	}
+	if template == "" {
+		return
+	}
+	fingerprint, err := services.GroupingFingerprint(template, services.NewAlertTemplateVars(*normalized, instance))
+	if err != nil {
+		slog.Warn("alert source grouping_key_template failed", "source", instance.Name, "err", err)
+		return
+	}
+	if fingerprint != "" {
+		normalized.SourceFingerprint = fingerprint
+	}
+}
+
+// attachDuplicate links the alert to the open incident that already has an
+// alert with its source fingerprint, unless the source turned
+// fingerprint_dedup off. Any failure falls through to correlation.
+func (h *AlertHandler) attachDuplicate(instance *database.AlertSourceInstance, normalized alerts.NormalizedAlert) bool {
+	if h.dedup == nil {
+		return false
+	}
+	enabled, err := services.ParseFingerprintDedup(instance.Settings)
+	if err != nil {
+		slog.Warn("ignoring invalid fingerprint_dedup setting", "source", instance.Name, "err", err)
+		enabled = true
+	}
+	if !enabled {
+		return false
+	}
+
+	ctx := context.Background()
+	incidentUUID, err := h.dedup.FindOpenIncidentBySourceFingerprint(ctx, instance.UUID, normalized)
+	if err != nil {
+		slog.Warn("fingerprint dedup lookup failed, correlating alert", "alert_name", normalized.AlertName, "err", err)
+		return false
+	}
+	if incidentUUID == "" {
+		return false
+	}
+	if err := h.skillService.LinkAlertToIncident(ctx, incidentUUID, instance.UUID, normalized, 1, "identical source fingerprint"); err != nil {
+		slog.Warn("failed to attach duplicate alert, correlating alert", "incident_uuid", incidentUUID, "err", err)
+		return false
+	}
+	slog.Info("alert deduplicated by source fingerprint", "alert_name", normalized.AlertName, "incident_uuid", incidentUUID)
+	h.noteRecurringAlert(incidentUUID, normalized, instance)
+	return true
+}
+
+// noteRecurringAlert posts a best-effort note in the incident's Slack thread
+// that the alert fired again. Skipped when that thread belongs to a silent
+// listener channel.
+func (h *AlertHandler) noteRecurringAlert(incidentUUID string, normalized alerts.NormalizedAlert, instance *database.AlertSourceInstance) {
+	incident, err := h.skillService.GetIncident(incidentUUID)
+	if err != nil || incident == nil || incident.SlackChannelID == "" || incident.SlackMessageTS == "" ||
+		!h.incidentThreadPostable(incident) {
+		return
+	}
+	h.postSlackThreadReply(incident.SlackChannelID, incident.SlackMessageTS,
+		h.renderNotification(services.NotificationAlertRecurring, alertNotificationData(normalized, instance)))
+}
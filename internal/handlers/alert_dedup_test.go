@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+type stubDeduplicator struct{ incidentUUID string }
+
+func (d stubDeduplicator) FindOpenIncidentBySourceFingerprint(context.Context, string, alerts.NormalizedAlert) (string, error) {
+	return d.incidentUUID, nil
+}
+
+func TestAlertHandler_FingerprintDedup(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t,
+		&database.SlackSettings{},
+		&database.Incident{}, &database.IncidentLogChunk{},
+		&database.Alert{},
+	)
+	svc := &corrGateSkillService{}
+	h := NewAlertHandler(nil, nil, nil, nil, svc, nil, nil)
+	h.SetAlertDeduplicator(stubDeduplicator{incidentUUID: "existing"})
+	instance := &database.AlertSourceInstance{
+		UUID:            "src-uuid",
+		Name:            "test-source",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "prometheus"},
+	}
+
+	h.processAlert(instance, newCorrTestAlert())
+	if spawns, links := svc.getSpawnCount(), svc.getLinkCount(); spawns != 0 || links != 1 || svc.linkCalls[0].incidentUUID != "existing" {
+		t.Fatalf("dedup on: spawns=%d links=%v, want the alert attached to existing", spawns, svc.linkCalls)
+	}
+
+	instance.Settings = database.JSONB{services.FingerprintDedupSettingKey: false}
+	h.processAlert(instance, newCorrTestAlert())
+	if spawns, links := svc.getSpawnCount(), svc.getLinkCount(); spawns != 1 || links != 1 {
+		t.Errorf("dedup off: spawns=%d links=%d, want a new incident", spawns, links)
+	}
+}
+
+func TestApplyGroupingKey(t *testing.T) {
+	instance := &database.AlertSourceInstance{Name: "zabbix"}
+	alert := alerts.NormalizedAlert{AlertName: "HighLoad", TargetHost: "web-1", SourceFingerprint: "event-17"}
+
+	applyGroupingKey(instance, &alert)
+	if alert.SourceFingerprint != "event-17" {
+		t.Fatalf("no template changed the fingerprint to %q", alert.SourceFingerprint)
+	}
+
+	instance.Settings = database.JSONB{services.GroupingKeySettingKey: "{{.Host}}/{{.AlertName}}"}
+	refire := alerts.NormalizedAlert{AlertName: "HighLoad", TargetHost: "web-1", SourceFingerprint: "event-93"}
+	applyGroupingKey(instance, &alert)
+	applyGroupingKey(instance, &refire)
+	if alert.SourceFingerprint == "event-17" || alert.SourceFingerprint != refire.SourceFingerprint {
+		t.Errorf("fingerprints = %q and %q, want one grouping fingerprint", alert.SourceFingerprint, refire.SourceFingerprint)
+	}
+}
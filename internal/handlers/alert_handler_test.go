@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,6 +13,19 @@ import (
 	"github.com/akmatori/akmatori/internal/testhelpers"
 )
 
+// webhookRoutes returns a mux serving h's webhook route the way
+// HTTPHandler registers it, so the instance UUID path value is set.
+func webhookRoutes(h *AlertHandler) *http.ServeMux {
+	mux := http.NewServeMux()
+	NewHTTPHandler(h).SetupRoutes(mux)
+	return mux
+}
+
+// serveWebhook serves req through h's webhook route.
+func serveWebhook(h *AlertHandler, w http.ResponseWriter, req *http.Request) {
+	webhookRoutes(h).ServeHTTP(w, req)
+}
+
 // TestAlertHandler_HandleWebhook_MethodValidation tests HTTP method validation
 func TestAlertHandler_HandleWebhook_MethodValidation(t *testing.T) {
 	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
@@ -33,7 +47,7 @@ func TestAlertHandler_HandleWebhook_MethodValidation(t *testing.T) {
 			req := httptest.NewRequest(tt.method, "/webhook/alert/test-uuid", nil)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			if w.Code != tt.expectedStatus {
 				t.Errorf("HandleWebhook(%s) = %d, want %d", tt.method, w.Code, tt.expectedStatus)
@@ -44,52 +58,51 @@ func TestAlertHandler_HandleWebhook_MethodValidation(t *testing.T) {
 
 // TestAlertHandler_HandleWebhook_PathExtraction tests UUID extraction from path
 func TestAlertHandler_HandleWebhook_PathExtraction(t *testing.T) {
-	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
-
-	// Only test cases that don't require alertService (empty UUID check)
 	tests := []struct {
-		name           string
-		path           string
-		expectedStatus int
+		name string
+		path string
+		uuid string
 	}{
-		{
-			name:           "empty UUID with trailing slash",
-			path:           "/webhook/alert/",
-			expectedStatus: http.StatusBadRequest,
-		},
+		{name: "plain UUID", path: "/webhook/alert/test-uuid", uuid: "test-uuid"},
+		{name: "trailing slash", path: "/webhook/alert/test-uuid/", uuid: "test-uuid"},
+		{name: "empty UUID with trailing slash", path: "/webhook/alert/"},
+		{name: "extra segment", path: "/webhook/alert/test-uuid/extra"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			manager := &mockAlertManager{getInstanceErr: errors.New("not found")}
+			h := NewAlertHandler(nil, nil, nil, nil, nil, manager, nil)
 			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("{}"))
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
-			if w.Code != tt.expectedStatus {
-				t.Errorf("HandleWebhook(%s) = %d, want %d. Body: %s",
-					tt.path, w.Code, tt.expectedStatus, w.Body.String())
+			// Routed requests reach the instance lookup; the rest are 404s
+			// from the mux either way.
+			if w.Code != http.StatusNotFound {
+				t.Errorf("HandleWebhook(%s) = %d, want 404. Body: %s", tt.path, w.Code, w.Body.String())
+			}
+			if manager.lastUUID != tt.uuid {
+				t.Errorf("HandleWebhook(%s) looked up %q, want %q", tt.path, manager.lastUUID, tt.uuid)
 			}
 		})
 	}
 }
 
-// TestAlertHandler_HandleWebhook_EmptyUUIDMessage tests error message for empty UUID
-func TestAlertHandler_HandleWebhook_EmptyUUIDMessage(t *testing.T) {
+// TestAlertHandler_HandleWebhook_EmptyUUIDNotRouted tests that a webhook
+// without an instance UUID never reaches the handler
+func TestAlertHandler_HandleWebhook_EmptyUUIDNotRouted(t *testing.T) {
+	// A nil alert service would panic if the handler ran.
 	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook/alert/", strings.NewReader("{}"))
 	w := httptest.NewRecorder()
 
-	h.HandleWebhook(w, req)
+	serveWebhook(h, w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 400, got %d", w.Code)
-	}
-
-	body := w.Body.String()
-	if !strings.Contains(body, "Missing instance UUID") {
-		t.Errorf("expected 'Missing instance UUID' in body, got: %s", body)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -177,6 +190,39 @@ func TestAlertHandler_BuildInvestigationPrompt(t *testing.T) {
 			wantNotContain: []string{
 				"Metric:",
 				"Runbook:",
+				"Additional instructions",
+			},
+		},
+		{
+			name: "source prompt template with labels",
+			alert: alerts.NormalizedAlert{
+				AlertName:    "PodCrashLooping",
+				TargetHost:   "k8s-node-3",
+				Severity:     database.AlertSeverityHigh,
+				TargetLabels: map[string]string{"team": "payments", "namespace": "checkout"},
+			},
+			instance: &database.AlertSourceInstance{
+				Name:            "prod-am",
+				AlertSourceType: database.AlertSourceType{Name: "alertmanager", DisplayName: "Prometheus Alertmanager"},
+				Settings: database.JSONB{
+					"prompt_template": "Escalate to #{{.Labels.team}}-oncall. Start in namespace {{.Labels.namespace}}{{if .Labels.cluster}} on {{.Labels.cluster}}{{end}}.",
+				},
+			},
+			wantContains: []string{
+				"Additional instructions for this alert source:\nEscalate to #payments-oncall. Start in namespace checkout.",
+			},
+		},
+		{
+			name: "broken source prompt template is skipped",
+			alert: alerts.NormalizedAlert{
+				AlertName: "X",
+				Severity:  database.AlertSeverityInfo,
+			},
+			instance: &database.AlertSourceInstance{
+				Settings: database.JSONB{"prompt_template": "{{.Nope}}"},
+			},
+			wantNotContain: []string{
+				"Additional instructions",
 			},
 		},
 	}
@@ -336,14 +382,13 @@ func TestAlertHandler_WithTestHelpers(t *testing.T) {
 
 	// Using HTTPTestContext for cleaner test setup - test method not allowed
 	ctx := testhelpers.NewHTTPTestContext(t, http.MethodGet, "/webhook/alert/test-uuid", nil)
-	ctx.ExecuteFunc(h.HandleWebhook).
+	ctx.ExecuteFunc(webhookRoutes(h).ServeHTTP).
 		AssertStatus(http.StatusMethodNotAllowed)
 
 	// Test POST with empty UUID (trailing slash only)
 	ctx = testhelpers.NewHTTPTestContext(t, http.MethodPost, "/webhook/alert/", strings.NewReader(""))
-	ctx.ExecuteFunc(h.HandleWebhook).
-		AssertStatus(http.StatusBadRequest).
-		AssertBodyContains("Missing instance UUID")
+	ctx.ExecuteFunc(webhookRoutes(h).ServeHTTP).
+		AssertStatus(http.StatusNotFound)
 }
 
 // TestAlertHandler_MockAdapter tests using mock adapter
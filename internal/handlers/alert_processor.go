@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 	"unicode/utf8"
@@ -17,9 +16,9 @@ import (
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/output"
 	"github.com/akmatori/akmatori/internal/services"
 	slackutil "github.com/akmatori/akmatori/internal/slack"
-	"github.com/slack-go/slack"
 	"gorm.io/gorm"
 )
 
@@ -43,12 +42,34 @@ func alertSpawnKey(sourceUUID, alertName, targetHost, fingerprint string) string
 }
 
 func (h *AlertHandler) processAlert(instance *database.AlertSourceInstance, normalized alerts.NormalizedAlert) {
+	applyGroupingKey(instance, &normalized)
+
 	if normalized.Status == database.AlertStatusResolved {
 		slog.Info("processing resolved alert", "alert_name", normalized.AlertName)
+		h.forgetCoalesced(instance.UUID, normalized)
 		go h.processResolvedAlert(instance.UUID, normalized)
 		return
 	}
 
+	// Sources that sent no usable severity get one before silences, routing
+	// and notifications look at it.
+	severityInferredBy := h.inferSeverity(instance.Settings, &normalized)
+
+	if h.silenced(instance, normalized) {
+		return
+	}
+
+	// A matching routing rule may override the channel, skills and priority,
+	// or ask for the alert to be recorded without an investigation.
+	instance, rule := h.routeAlert(instance, normalized)
+
+	// Storm protection: repeats of a just-spawned alert are dropped, and a
+	// source in storm mode has its alerts grouped into one incident.
+	key := alertSpawnKey(instance.UUID, normalized.AlertName, normalized.TargetHost, normalized.SourceFingerprint)
+	if h.coalesced(key, normalized) || h.groupIntoStorm(instance, normalized, routedPriority(rule, normalized)) {
+		return
+	}
+
 	slog.Info("processing firing alert", "alert_name", normalized.AlertName, "severity", normalized.Severity)
 
 	// Convert target labels to JSONB
@@ -94,10 +115,20 @@ func (h *AlertHandler) processAlert(instance *database.AlertSourceInstance, norm
 		},
 		Message: fmt.Sprintf("%s - %s: %s", normalized.AlertName, normalized.TargetHost, normalized.Summary),
 	}
-
-	key := alertSpawnKey(instance.UUID, normalized.AlertName, normalized.TargetHost, normalized.SourceFingerprint)
+	if rule != nil {
+		incidentCtx.Context["routing_rule"] = rule.Name
+	}
+	if severityInferredBy != "" {
+		incidentCtx.Context["severity_inferred_by"] = severityInferredBy
+	}
 
 	_, sfErr, _ := h.spawnGroup.Do(key, func() (interface{}, error) {
+		// Cheap exact match first: a repeat of an alert an open incident
+		// already has needs no LLM verdict.
+		if h.attachDuplicate(instance, normalized) {
+			return nil, nil
+		}
+
 		// Correlation gate: attach to a recent open or monitor incident when confident.
 		verdict, corrErr := h.correlate(context.Background(), instance.UUID, normalized)
 		if corrErr != nil {
@@ -113,14 +144,7 @@ func (h *AlertHandler) processAlert(instance *database.AlertSourceInstance, norm
 				// Fail-open: link failed (incident deleted, DB error, etc.) — spawn new investigation.
 				slog.Warn("failed to link alert to incident, spawning new incident", "incident_uuid", verdict.IncidentUUID, "err", err)
 			} else {
-				// Best-effort Slack thread note on the matched incident's thread.
-				// Skipped when that thread belongs to a silent listener channel.
-				if incident, err := h.skillService.GetIncident(verdict.IncidentUUID); err == nil && incident != nil &&
-					incident.SlackChannelID != "" && incident.SlackMessageTS != "" &&
-					h.incidentThreadPostable(incident) {
-					h.postSlackThreadReply(incident.SlackChannelID, incident.SlackMessageTS,
-						fmt.Sprintf("Recurring alert: %s", normalized.AlertName))
-				}
+				h.noteRecurringAlert(verdict.IncidentUUID, normalized, instance)
 				return nil, nil
 			}
 		}
@@ -162,15 +186,22 @@ func (h *AlertHandler) processAlert(instance *database.AlertSourceInstance, norm
 			return nil, nil
 		}
 
-		slog.Info("created incident for alert", "incident_id", incidentUUID)
+		slog.Info("created incident for alert", "incident_uuid", incidentUUID)
+		h.recordSpawn(instance.UUID, key, incidentUUID)
+
+		// A cascading alert is noted in its parent's thread instead of getting
+		// its own channel post while the parent is still open.
+		suppressed := h.linkCascadingParent(incidentUUID, verdict, normalized, instance)
 
 		// Post to Slack
 		var channelID, threadTS, channelUUID string
-		if h.isSlackEnabled() {
+		if h.isSlackEnabled() && !suppressed {
 			var err error
 			channelID, threadTS, channelUUID, err = h.postAlertToSlack(normalized, instance)
 			if err != nil {
 				slog.Warn("failed to post alert to Slack", "err", err)
+			} else {
+				h.recordSlackPost(incidentUUID, channelID, threadTS, "Alert posted to Slack")
 			}
 		}
 
@@ -180,11 +211,23 @@ func (h *AlertHandler) processAlert(instance *database.AlertSourceInstance, norm
 			}
 		}
 
+		if rule != nil && rule.Action == database.RoutingActionRecord {
+			h.recordRoutedAlert(incidentUUID, rule, channelID, threadTS)
+			return nil, nil
+		}
+
+		if note := declineOverBudget(h.budget, h.skillService, incidentUUID); note != "" {
+			if channelID != "" && threadTS != "" {
+				h.postSlackThreadReply(channelID, threadTS, note)
+			}
+			return nil, nil
+		}
+
 		// Update incident status and run investigation
 		if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
 			slog.Warn("failed to update incident status", "err", err)
 		}
-		go h.runInvestigation(incidentUUID, normalized, instance, channelID, threadTS, channelUUID)
+		go h.runInvestigation(incidentUUID, normalized, routedPriority(rule, normalized), instance, channelID, threadTS, channelUUID)
 
 		return nil, nil
 	})
@@ -215,6 +258,10 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 		return
 	}
 
+	// Channels have no source settings; their alerts get the rules only (the
+	// extractor already asked the LLM for a severity).
+	severityInferredBy := h.inferSeverity(nil, &normalized)
+
 	slog.Info("processing listener channel alert", "alert_name", normalized.AlertName, "severity", normalized.Severity)
 
 	// Convert target labels to JSONB
@@ -273,6 +320,9 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 		},
 		Message: fmt.Sprintf("%s - %s: %s", normalized.AlertName, normalized.TargetHost, normalized.Summary),
 	}
+	if severityInferredBy != "" {
+		incidentCtx.Context["severity_inferred_by"] = severityInferredBy
+	}
 
 	key := alertSpawnKey(channel.UUID, normalized.AlertName, normalized.TargetHost, normalized.SourceFingerprint)
 
@@ -293,7 +343,7 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 				slog.Warn("failed to link alert to incident, spawning new incident", "incident_uuid", verdict.IncidentUUID, "err", err)
 			} else {
 				if channel.CanPost {
-					h.updateSlackChannelReactions(slackChannelID, slackMessageTS, false)
+					h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), false)
 					h.postSlackThreadReply(slackChannelID, slackMessageTS,
 						h.buildAlertMergedMessage(verdict.IncidentUUID))
 				}
@@ -320,9 +370,11 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 		if err != nil {
 			slog.Error("failed to spawn incident manager for listener channel alert", "err", err)
 			if channel.CanPost {
-				h.updateSlackChannelReactions(slackChannelID, slackMessageTS, true)
+				h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), true)
+				data := alertNotificationData(normalized, nil)
+				data.Error = err.Error()
 				h.postSlackThreadReply(slackChannelID, slackMessageTS,
-					fmt.Sprintf("Failed to create incident: %v", err))
+					h.renderNotification(services.NotificationIncidentCreateFailed, data))
 			}
 			return nil, err
 		}
@@ -340,13 +392,24 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 			return nil, nil
 		}
 
-		slog.Info("created incident for listener channel alert", "incident_id", incidentUUID)
+		slog.Info("created incident for listener channel alert", "incident_uuid", incidentUUID)
+
+		// The source message already lives in the channel, so a cascading
+		// alert is only linked (and noted in the parent thread), never hidden.
+		h.linkCascadingParent(incidentUUID, verdict, normalized, nil)
 
 		// Update incident with Slack context for thread replies
 		if err := h.updateIncidentSlackContext(incidentUUID, slackChannelID, slackMessageTS); err != nil {
 			slog.Warn("failed to update incident Slack context", "err", err)
 		}
 
+		if note := declineOverBudget(h.budget, h.skillService, incidentUUID); note != "" {
+			if channel.CanPost {
+				h.postSlackThreadReply(slackChannelID, slackMessageTS, note)
+			}
+			return nil, nil
+		}
+
 		// Update incident status and run investigation
 		if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
 			slog.Warn("failed to update incident status", "err", err)
@@ -364,6 +427,39 @@ func (h *AlertHandler) ProcessAlertFromListenerChannel(
 	// Followers (isLeader==false): singleflight collapsed the burst; the leader owned all work.
 }
 
+// linkCascadingParent files a freshly spawned incident under the parent the
+// correlator identified. When the parent is still open and its thread is
+// postable, a note is posted there and true is returned so the caller skips
+// the child's own channel post. Linking is best-effort; failures only log.
+func (h *AlertHandler) linkCascadingParent(incidentUUID string, verdict services.CorrelationVerdict, alert alerts.NormalizedAlert, instance *database.AlertSourceInstance) bool {
+	if h.incidentLinks == nil || !verdict.IsConfidentParent(h.correlationThreshold()) {
+		return false
+	}
+	ctx := context.Background()
+	if _, err := h.incidentLinks.LinkIncidents(ctx, incidentUUID, verdict.ParentIncidentUUID,
+		database.IncidentLinkParent, database.IncidentLinkOriginCorrelator, verdict.Reasoning); err != nil {
+		slog.Warn("failed to link cascading incident to parent", "incident_uuid", incidentUUID, "parent_uuid", verdict.ParentIncidentUUID, "err", err)
+		return false
+	}
+	slog.Info("linked cascading incident to parent", "incident_uuid", incidentUUID, "parent_uuid", verdict.ParentIncidentUUID, "confidence", verdict.ParentConfidence)
+
+	parent, err := h.incidentLinks.ActiveParent(ctx, incidentUUID)
+	if err != nil {
+		slog.Warn("failed to load parent incident", "incident_uuid", incidentUUID, "err", err)
+		return false
+	}
+	if parent == nil || !h.isSlackEnabled() || parent.SlackChannelID == "" || parent.SlackMessageTS == "" ||
+		!h.incidentThreadPostable(parent) {
+		return false
+	}
+	data := alertNotificationData(alert, instance)
+	data.IncidentUUID = incidentUUID
+	data.IncidentURL = services.IncidentURL(incidentUUID)
+	h.postSlackThreadReply(parent.SlackChannelID, parent.SlackMessageTS,
+		h.renderNotification(services.NotificationAlertCascading, data))
+	return true
+}
+
 // processResolvedAlert finds the matching firing alert row in the alerts table
 // and marks it resolved. When no firing alerts remain for the incident and the
 // incident is in completed or monitor status, the monitor window is shortened to
@@ -440,8 +536,12 @@ func (h *AlertHandler) processResolvedAlert(sourceUUID string, normalized alerts
 	if h.skillService != nil {
 		if incident, err := h.skillService.GetIncident(linkedIncidentUUID); err == nil && incident != nil &&
 			incident.SlackChannelID != "" && incident.SlackMessageTS != "" {
+			data := alertNotificationData(normalized, nil)
+			data.IncidentUUID = incident.UUID
+			data.IncidentTitle = incident.Title
+			data.IncidentURL = services.IncidentURL(incident.UUID)
 			h.postSlackThreadReply(incident.SlackChannelID, incident.SlackMessageTS,
-				fmt.Sprintf("Alert resolved: %s", normalized.AlertName))
+				h.renderNotification(services.NotificationAlertResolved, data))
 		}
 	}
 }
@@ -481,12 +581,49 @@ func extractOriginalMessage(payload map[string]interface{}, maxBytes int) string
 	return s[:cut] + ellipsis
 }
 
-func (h *AlertHandler) buildInvestigationPrompt(alert alerts.NormalizedAlert, instance *database.AlertSourceInstance) string {
-	return h.buildInvestigationPromptWithSource(alert,
+// enrichAlert runs the enrichment pipeline for an alert about to be
+// investigated. settings selects the steps (nil runs the defaults).
+func (h *AlertHandler) enrichAlert(incidentUUID, sourceUUID string, alert alerts.NormalizedAlert, settings map[string]interface{}) []services.EnrichmentSection {
+	if h.enrichment == nil {
+		return nil
+	}
+	return h.enrichment.Run(context.Background(), services.EnrichmentRequest{
+		IncidentUUID: incidentUUID,
+		SourceUUID:   sourceUUID,
+		Alert:        alert,
+	}, settings)
+}
+
+func (h *AlertHandler) buildInvestigationPrompt(alert alerts.NormalizedAlert, instance *database.AlertSourceInstance, enrichment ...services.EnrichmentSection) string {
+	data := investigationPromptData(alert,
 		instance.AlertSourceType.DisplayName,
 		instance.AlertSourceType.Name,
 		instance.Name,
+		enrichment,
 	)
+	// Skill templates only apply to sources pinned to their skill.
+	skills, _, _ := services.SourceSkillScope(instance)
+	prompt := h.renderInvestigationPrompt(data, instance.AlertSourceType.Name, skills)
+	if extra := renderSourcePromptTemplate(alert, instance); extra != "" {
+		prompt += "\n\nAdditional instructions for this alert source:\n" + extra
+	}
+	return prompt
+}
+
+// renderSourcePromptTemplate renders the optional per-source prompt_template
+// setting against the alert's template variables. Render failures are logged
+// and skipped so a bad template never blocks an investigation.
+func renderSourcePromptTemplate(alert alerts.NormalizedAlert, instance *database.AlertSourceInstance) string {
+	body, _ := instance.Settings[services.PromptTemplateSettingKey].(string)
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
+	out, err := services.RenderAlertTemplate(body, services.NewAlertTemplateVars(alert, instance))
+	if err != nil {
+		slog.Warn("alert source prompt_template failed", "source", instance.Name, "err", err)
+		return ""
+	}
+	return strings.TrimSpace(out)
 }
 
 // buildInvestigationPromptForChannel mirrors buildInvestigationPrompt for
@@ -494,7 +631,7 @@ func (h *AlertHandler) buildInvestigationPrompt(alert alerts.NormalizedAlert, in
 // AlertSourceInstance. The header reads "Investigate this Slack channel alert"
 // (sourceDisplay = "Slack channel") and Source identifies the channel by name
 // so the agent can still scope runbook searches.
-func (h *AlertHandler) buildInvestigationPromptForChannel(alert alerts.NormalizedAlert, channel *database.Channel) string {
+func (h *AlertHandler) buildInvestigationPromptForChannel(alert alerts.NormalizedAlert, channel *database.Channel, enrichment ...services.EnrichmentSection) string {
 	provider := string(channel.Integration.Provider)
 	if provider == "" {
 		provider = string(database.MessagingProviderSlack)
@@ -505,7 +642,7 @@ func (h *AlertHandler) buildInvestigationPromptForChannel(alert alerts.Normalize
 	if sourceInstance == "" {
 		sourceInstance = channel.ExternalID
 	}
-	return h.buildInvestigationPromptWithSource(alert, sourceDisplay, sourceTypeID, sourceInstance)
+	return h.renderInvestigationPrompt(investigationPromptData(alert, sourceDisplay, sourceTypeID, sourceInstance, enrichment), sourceTypeID, nil)
 }
 
 // titleProvider capitalizes the first ASCII letter of a provider identifier
@@ -523,27 +660,17 @@ func titleProvider(p string) string {
 	return string(first) + p[1:]
 }
 
-// buildInvestigationPromptWithSource is the common prompt-building core. The
-// three source* parameters drive the header (sourceDisplay) and the "Source:"
-// breadcrumb (sourceTypeID / sourceInstance), so the two call sites
-// (AlertSourceInstance + Channel) stay in sync as the prompt evolves.
-func (h *AlertHandler) buildInvestigationPromptWithSource(alert alerts.NormalizedAlert, sourceDisplay, sourceTypeID, sourceInstanceName string) string {
-	prompt := fmt.Sprintf(`Investigate this %s alert:
-
-Alert: %s
-Host: %s
-Service: %s
-Severity: %s
-Summary: %s
-Description: %s`,
-		sourceDisplay,
-		alert.AlertName,
-		alert.TargetHost,
-		alert.TargetService,
-		alert.Severity,
-		alert.Summary,
-		alert.Description,
-	)
+// investigationPromptData assembles the prompt template context for alert.
+// The three source* parameters drive the header (sourceDisplay) and the
+// "Source:" breadcrumb (sourceTypeID / sourceInstance), so the two call sites
+// (AlertSourceInstance + Channel) stay in sync as the prompt evolves. The
+// enrichment sections go between the alert details and the instructions.
+func investigationPromptData(alert alerts.NormalizedAlert, sourceDisplay, sourceTypeID, sourceInstanceName string, enrichment []services.EnrichmentSection) services.InvestigationPromptData {
+	vars := services.NewAlertTemplateVars(alert, nil)
+	vars.SourceDisplayName = sourceDisplay
+	vars.SourceType = sourceTypeID
+	vars.SourceName = sourceInstanceName
+	data := services.InvestigationPromptData{AlertTemplateVars: vars}
 
 	// Source identifies the upstream alerting system + instance so the agent
 	// can disambiguate which integration a runbook should target. The type
@@ -555,19 +682,11 @@ Description: %s`,
 	sourceInstance := strings.TrimSpace(sourceInstanceName)
 	switch {
 	case sourceType != "" && sourceInstance != "":
-		prompt += fmt.Sprintf("\nSource: %s / %s", sourceType, sourceInstance)
+		data.Source = sourceType + " / " + sourceInstance
 	case sourceType != "":
-		prompt += fmt.Sprintf("\nSource: %s", sourceType)
+		data.Source = sourceType
 	case sourceInstance != "":
-		prompt += fmt.Sprintf("\nSource: %s", sourceInstance)
-	}
-
-	if alert.MetricName != "" {
-		prompt += fmt.Sprintf("\nMetric: %s = %s", alert.MetricName, alert.MetricValue)
-	}
-
-	if alert.RunbookURL != "" {
-		prompt += fmt.Sprintf("\nRunbook: %s", alert.RunbookURL)
+		data.Source = sourceInstance
 	}
 
 	// Always render the labeled "Original alert text:" block when the
@@ -578,30 +697,56 @@ Description: %s`,
 	// Slack-channel fallback path otherwise leaves in Description. Duplicating
 	// the text under both Description and Original alert text is harmless (a
 	// few hundred extra prompt bytes) and keeps the labeled anchor stable.
-	if original := extractOriginalMessage(alert.RawPayload, originalAlertTextMaxBytes); original != "" {
-		prompt += "\n\nOriginal alert text:\n" + original
-	}
+	data.OriginalText = extractOriginalMessage(alert.RawPayload, originalAlertTextMaxBytes)
 
-	prompt += `
+	data.Enrichment = services.RenderEnrichment(enrichment)
+	return data
+}
 
-Please:
-1. Check if this is a known issue or pattern
-2. Analyze available metrics and logs
-3. Identify potential root causes
-4. Suggest remediation steps with priority
-5. Assess urgency and impact
+// SetPromptRenderer wires the operator investigation prompt templates.
+// Optional — when unset the built-in prompt is used.
+func (h *AlertHandler) SetPromptRenderer(r services.PromptRenderer) {
+	h.promptTemplates = r
+}
 
-Be specific and actionable. Reference any relevant data sources or scripts you use.`
+// renderInvestigationPrompt renders data with the operator prompt template
+// for sourceType and skills, or the built-in prompt when none is wired.
+func (h *AlertHandler) renderInvestigationPrompt(data services.InvestigationPromptData, sourceType string, skills []string) string {
+	if h.promptTemplates == nil {
+		return services.RenderDefaultInvestigationPrompt(data)
+	}
+	return h.promptTemplates.RenderPrompt(data, sourceType, skills)
+}
 
-	return prompt
+// investigationSkills returns the skills and tool allowlist for an alert
+// investigation: the source's pinned skills when it has any, otherwise every
+// enabled skill.
+func (h *AlertHandler) investigationSkills(instance *database.AlertSourceInstance) ([]string, []services.ToolAllowlistEntry) {
+	if names, allowlist, ok := services.SourceSkillScope(instance); ok {
+		return names, allowlist
+	}
+	return h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist()
 }
 
-func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.NormalizedAlert, instance *database.AlertSourceInstance, channelID, threadTS, channelUUID string) {
-	slog.Info("starting investigation for alert", "alert_name", alert.AlertName, "incident_id", incidentUUID)
+func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.NormalizedAlert, priority database.AlertSeverity, instance *database.AlertSourceInstance, channelID, threadTS, channelUUID string) {
+	slog.Info("starting investigation for alert", "alert_name", alert.AlertName, "incident_uuid", incidentUUID)
+
+	// Wait for a scheduler slot (no-op when no scheduler is wired).
+	slot := h.acquireInvestigationSlot(incidentUUID, instance.UUID, priority)
+	if slot != nil {
+		defer slot.Release()
+	}
 
 	// Build investigation prompt
-	investigationPrompt := h.buildInvestigationPrompt(alert, instance)
+	enrichment := h.enrichAlert(incidentUUID, instance.UUID, alert, instance.Settings)
+	investigationPrompt := h.buildInvestigationPrompt(alert, instance, enrichment...)
+	twoPhase := h.planApprovalRequired(instance)
+	if twoPhase {
+		investigationPrompt += "\n\n" + services.PlanningInstructions()
+	}
+	investigationPrompt = h.withConfidenceInstructions(investigationPrompt)
 	taskWithGuidance := executor.PrependGuidance(investigationPrompt)
+	skillNames, toolAllowlist := h.investigationSkills(instance)
 
 	// Show "is investigating..." in the alert thread for the duration of the
 	// agent run when Slack is configured. The reaction lands on the bot's own
@@ -612,36 +757,41 @@ func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.Normal
 	// typing.Discard() — without that, the deferred Stop on a displaced run
 	// fires setStatus("") + RemoveReaction against the shared thread and
 	// erases the replacement run's banner + hourglass.
+	//
+	// The channel's reaction set and progress verbosity apply here the same
+	// way they do for listener-channel investigations.
+	notifyChannel := h.notificationChannel(channelUUID)
+	reactions := notifyChannel.EffectiveSlackReactions()
 	var typing slackutil.TypingController
+	var progressStreamer *SlackProgressStreamer
 	if threadTS != "" && channelID != "" {
 		if slackClient := h.slackManager.GetClient(); slackClient != nil {
-			typing = slackutil.NewTypingController(slackutil.TypingControllerConfig{
-				Client:      slackClient,
-				ChannelID:   channelID,
-				ThreadTS:    threadTS,
-				ReactionRef: slack.ItemRef{Channel: channelID, Timestamp: threadTS},
-			})
+			typing = newSlackTypingController(slackClient, channelID, threadTS, reactions)
 			typing.Start(context.Background())
 			defer typing.Stop()
+			progressStreamer = newChannelProgressStreamer(notifyChannel.EffectiveProgressVerbosity(), typing.UpdateLoadingMessage,
+				func(line string) { h.postSlackThreadReply(channelID, threadTS, line) })
 		}
 	}
 
 	// Use WebSocket-based agent worker
-	if h.agentWSHandler != nil && h.agentWSHandler.IsWorkerConnected() {
-		slog.Info("using WebSocket-based agent worker", "incident_id", incidentUUID)
+	if h.agentWSHandler != nil && h.agentWSHandler.WaitForWorker(context.Background()) {
+		slog.Info("using WebSocket-based agent worker", "incident_uuid", incidentUUID)
 
-		// Fetch LLM settings from database
+		// Fetch LLM settings from database. Skills the source is pinned to
+		// may pin their own LLM configuration.
+		pinnedSkills, _, _ := services.SourceSkillScope(instance)
 		var llmSettings *LLMSettingsForWorker
-		if dbSettings, err := database.GetLLMSettings(); err == nil && dbSettings != nil {
+		if dbSettings, err := database.GetLLMSettingsForSkills(pinnedSkills); err == nil && dbSettings != nil {
 			llmSettings = BuildLLMSettingsForWorker(dbSettings)
-			slog.Info("using LLM provider", "provider", dbSettings.Provider, "model", dbSettings.Model)
+			slog.Info("using LLM provider", "provider", dbSettings.Provider, "model", dbSettings.Model, "config", dbSettings.Name)
 		} else {
 			slog.Warn("could not fetch LLM settings", "err", err)
 		}
 
-		// Create channels for async result handling
-		done := make(chan struct{})
-		var closeOnce sync.Once
+		// Completion signal for async result handling; re-armed if the run
+		// is paused for a critical incident and later resumed.
+		run := newPreemptibleRun()
 		var response string
 		var sessionID string
 		var hasError bool
@@ -661,18 +811,19 @@ func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.Normal
 				if err := h.skillService.UpdateIncidentLog(incidentUUID, taskHeader+lastStreamedLog); err != nil {
 					slog.Error("failed to update incident log", "err", err)
 				}
+				progressStreamer.AppendStatus(output)
 			},
 			OnCompleted: func(sid, output string, tokensUsed int, executionTimeMs int64) {
 				sessionID = sid
 				response = output
 				finalTokensUsed = tokensUsed
 				finalExecutionTimeMs = executionTimeMs
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 			OnError: func(errorMsg string) {
 				response = fmt.Sprintf("Error: %s", errorMsg)
 				hasError = true
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 			// If a newer run displaces us for the same incident_id, the
 			// replacement run owns finalization. Unblock and exit silently
@@ -686,34 +837,72 @@ func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.Normal
 				if typing != nil {
 					typing.Discard()
 				}
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 		}
 
-		runID, err := h.agentWSHandler.StartIncident(incidentUUID, taskWithGuidance, llmSettings, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+		runID, err := h.agentWSHandler.StartIncident(incidentUUID, taskWithGuidance, llmSettings, skillNames, toolAllowlist, callback)
 		if err != nil {
 			slog.Error("failed to start incident via WebSocket", "err", err)
 			errorMsg := fmt.Sprintf("Failed to start investigation: %v", err)
 			if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", "", errorMsg, 0, 0); updateErr != nil {
 				slog.Error("failed to update incident status", "err", updateErr)
 			}
-			h.updateSlackWithResult(channelID, threadTS, errorMsg, true)
+			h.updateSlackWithResult(incidentUUID, channelID, threadTS, errorMsg, reactions, true)
 			return
 		}
 
-		// Wait for completion
-		<-done
+		// Wait for completion, pausing and resuming if a critical incident
+		// needs the slot.
+		runID, ok, err := awaitAgentRun(slot, run, runID, agentRunControl{
+			cancel:  func() error { return h.agentWSHandler.CancelIncident(incidentUUID) },
+			release: func(id string) bool { return h.agentWSHandler.ReleaseRun(incidentUUID, id) },
+			pause: func() {
+				response, hasError = "", false
+				lastStreamedLog += preemptionPauseNote
+				if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusPending, "", taskHeader+lastStreamedLog); err != nil {
+					slog.Warn("failed to mark paused incident pending", "incident_uuid", incidentUUID, "err", err)
+				}
+			},
+			resume: func() (string, error) {
+				if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
+					slog.Warn("failed to mark resumed incident running", "incident_uuid", incidentUUID, "err", err)
+				}
+				return h.agentWSHandler.ContinueIncident(incidentUUID, incidentUUID, preemptionResumeMessage, llmSettings, skillNames, toolAllowlist, callback)
+			},
+		})
+		if err != nil {
+			slog.Error("failed to resume preempted investigation", "incident_uuid", incidentUUID, "err", err)
+			errorMsg := fmt.Sprintf("Failed to resume investigation: %v", err)
+			if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", taskHeader+lastStreamedLog, errorMsg, 0, 0); updateErr != nil {
+				slog.Error("failed to update incident status", "err", updateErr)
+			}
+			h.updateSlackWithResult(incidentUUID, channelID, threadTS, errorMsg, reactions, true)
+			return
+		}
+		if !ok {
+			if typing != nil {
+				typing.Discard()
+			}
+			slog.Info("paused alert investigation displaced; leaving finalization to the new run", "incident_uuid", incidentUUID)
+			return
+		}
+		progressStreamer.Flush()
 
 		// Replacement run owns finalization — exit before touching the DB or Slack.
 		if superseded.Load() {
-			slog.Info("alert investigation superseded; leaving finalization to the new run", "incident_id", incidentUUID)
+			slog.Info("alert investigation superseded; leaving finalization to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
+		// Take the confidence block out before formatting so it never
+		// reaches the UI or Slack; full_log keeps the raw response.
+		confidence, result := output.ExtractConfidence(response)
+
 		// Apply the first matching formatting rule before persistence and
 		// Slack posting. Passthrough on error/empty or when no rule
 		// matches the incident's flow.
-		formattedResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, response, taskHeader+lastStreamedLog,
+		formattedResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, result, taskHeader+lastStreamedLog,
 			services.BuildFormatFlow(incidentUUID, channelUUID))
 
 		// Re-attach the metrics footer AFTER formatting so the LLM never
@@ -721,8 +910,9 @@ func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.Normal
 		// Tokens). The deterministic footer lands at the end of the
 		// stored DB response and the Slack final-message body, where
 		// buildSlackFooter extracts it for the trailing metrics line.
-		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, finalExecutionTimeMs, finalTokensUsed, hasError)
-		rawWithMetrics := appendFinalizeMetrics(response, finalExecutionTimeMs, finalTokensUsed, hasError)
+		runMetrics := finalizeRunMetrics(lastStreamedLog, finalExecutionTimeMs, finalTokensUsed)
+		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, runMetrics, hasError)
+		rawWithMetrics := appendFinalizeMetrics(response, runMetrics, hasError)
 
 		// Build full log using the raw response (with metrics) so full_log
 		// preserves the original agent output for debugging.
@@ -764,33 +954,44 @@ func (h *AlertHandler) runInvestigation(incidentUUID string, alert alerts.Normal
 			if typing != nil {
 				typing.Discard()
 			}
-			slog.Info("alert investigation displaced during finalization; leaving DB + Slack post to the new run", "incident_id", incidentUUID)
+			slog.Info("alert investigation displaced during finalization; leaving DB + Slack post to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
 		// Update incident with full results — the formatted response is
-		// what users see in the UI.
+		// what users see in the UI. A two-phase investigation stops at
+		// diagnosed until its plan is decided.
 		finalStatus := database.IncidentStatusCompleted
 		if hasError {
 			finalStatus = database.IncidentStatusFailed
+		} else if twoPhase {
+			finalStatus = database.IncidentStatusDiagnosed
 		}
 		if err := h.skillService.UpdateIncidentComplete(incidentUUID, finalStatus, sessionID, fullLog, formattedWithMetrics, finalTokensUsed, finalExecutionTimeMs); err != nil {
 			slog.Error("failed to update incident complete", "err", err)
 		}
 
-		h.updateSlackWithResult(channelID, threadTS, formattedResp, hasError)
+		h.updateSlackWithResult(incidentUUID, channelID, threadTS, formattedResp, reactions, hasError)
+		if twoPhase && !hasError {
+			h.proposeRemediationPlan(incidentUUID, result)
+		}
+		if !hasError {
+			if review := h.routeByConfidence(incidentUUID, confidence, priority); review != "" && threadTS != "" {
+				h.postSlackThreadReply(channelID, threadTS, review)
+			}
+		}
 
 		slog.Info("investigation completed for alert via WebSocket", "alert_name", alert.AlertName)
 		return
 	}
 
 	// No WebSocket worker available
-	slog.Error("agent worker not connected", "incident_id", incidentUUID)
+	slog.Error("agent worker not connected", "incident_uuid", incidentUUID)
 	errorMsg := "Agent worker not connected. Please check that the agent-worker container is running."
 	if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", "", errorMsg, 0, 0); updateErr != nil {
 		slog.Error("failed to update incident status", "err", updateErr)
 	}
-	h.updateSlackWithResult(channelID, threadTS, errorMsg, true)
+	h.updateSlackWithResult(incidentUUID, channelID, threadTS, errorMsg, reactions, true)
 }
 
 // runListenerChannelInvestigation runs investigation and posts results to the
@@ -803,7 +1004,13 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 	channel *database.Channel,
 	slackChannelID, slackMessageTS string,
 ) {
-	slog.Info("starting investigation for listener channel alert", "alert_name", alert.AlertName, "incident_id", incidentUUID)
+	slog.Info("starting investigation for listener channel alert", "alert_name", alert.AlertName, "incident_uuid", incidentUUID)
+
+	// Wait for a scheduler slot (no-op when no scheduler is wired).
+	slot := h.acquireInvestigationSlot(incidentUUID, channel.UUID, alert.Severity)
+	if slot != nil {
+		defer slot.Release()
+	}
 
 	// can_post=false marks a silent listener: the alert is investigated and
 	// the incident (response + full log) lands in the UI as usual, but
@@ -813,13 +1020,14 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 	canPost := channel.CanPost
 
 	// Build investigation prompt
-	investigationPrompt := h.buildInvestigationPromptForChannel(alert, channel)
+	enrichment := h.enrichAlert(incidentUUID, channel.UUID, alert, nil)
+	investigationPrompt := h.withConfidenceInstructions(h.buildInvestigationPromptForChannel(alert, channel, enrichment...))
 	taskWithGuidance := executor.PrependGuidance(investigationPrompt)
 
 	// Show "is investigating..." in the thread header and put a hourglass
 	// reaction on the original Slack-channel alert message for the duration
 	// of the agent run. defer Stop covers all exit paths since the function
-	// blocks on <-done before returning.
+	// blocks until the run finishes before returning.
 	//
 	// The progress streamer pipes the agent's latest 🤔 reasoning line into
 	// the typing controller's loading_messages, replacing Slack's default
@@ -834,20 +1042,16 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 	var progressStreamer *SlackProgressStreamer
 	var typing slackutil.TypingController
 	if slackClient := h.slackManager.GetClient(); slackClient != nil && canPost {
-		typing = slackutil.NewTypingController(slackutil.TypingControllerConfig{
-			Client:      slackClient,
-			ChannelID:   slackChannelID,
-			ThreadTS:    slackMessageTS,
-			ReactionRef: slack.ItemRef{Channel: slackChannelID, Timestamp: slackMessageTS},
-		})
+		typing = newSlackTypingController(slackClient, slackChannelID, slackMessageTS, channel.EffectiveSlackReactions())
 		typing.Start(context.Background())
 		defer typing.Stop()
-		progressStreamer = NewSlackProgressStreamer(typing.UpdateLoadingMessage, slackAppendInterval)
+		progressStreamer = newChannelProgressStreamer(channel.EffectiveProgressVerbosity(), typing.UpdateLoadingMessage,
+			func(line string) { h.postSlackThreadReply(slackChannelID, slackMessageTS, line) })
 	}
 
 	// Use WebSocket-based agent worker
-	if h.agentWSHandler != nil && h.agentWSHandler.IsWorkerConnected() {
-		slog.Info("using WebSocket-based agent worker for Slack channel incident", "incident_id", incidentUUID)
+	if h.agentWSHandler != nil && h.agentWSHandler.WaitForWorker(context.Background()) {
+		slog.Info("using WebSocket-based agent worker for Slack channel incident", "incident_uuid", incidentUUID)
 
 		// Fetch LLM settings from database
 		var llmSettings *LLMSettingsForWorker
@@ -855,9 +1059,9 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 			llmSettings = BuildLLMSettingsForWorker(dbSettings)
 		}
 
-		// Create channels for async result handling
-		done := make(chan struct{})
-		var closeOnce sync.Once
+		// Completion signal for async result handling; re-armed if the run
+		// is paused for a critical incident and later resumed.
+		run := newPreemptibleRun()
 		var response string
 		var sessionID string
 		var hasError bool
@@ -886,12 +1090,12 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 				response = output
 				finalTokensUsed = tokensUsed
 				finalExecutionTimeMs = executionTimeMs
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 			OnError: func(errorMsg string) {
 				response = fmt.Sprintf("Error: %s", errorMsg)
 				hasError = true
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 			// If a newer run displaces us for the same incident_id, the
 			// replacement run owns finalization — DB update, Slack message,
@@ -905,7 +1109,7 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 				if typing != nil {
 					typing.Discard()
 				}
-				closeOnce.Do(func() { close(done) })
+				run.finish()
 			},
 		}
 
@@ -917,14 +1121,50 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 				slog.Error("failed to update incident status", "err", updateErr)
 			}
 			if canPost {
-				h.updateSlackChannelReactions(slackChannelID, slackMessageTS, true)
+				h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), true)
 				h.postSlackThreadReply(slackChannelID, slackMessageTS, errorMsg)
 			}
 			return
 		}
 
-		// Wait for completion
-		<-done
+		// Wait for completion, pausing and resuming if a critical incident
+		// needs the slot.
+		runID, ok, err := awaitAgentRun(slot, run, runID, agentRunControl{
+			cancel:  func() error { return h.agentWSHandler.CancelIncident(incidentUUID) },
+			release: func(id string) bool { return h.agentWSHandler.ReleaseRun(incidentUUID, id) },
+			pause: func() {
+				response, hasError = "", false
+				lastStreamedLog += preemptionPauseNote
+				if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusPending, "", taskHeader+lastStreamedLog); err != nil {
+					slog.Warn("failed to mark paused incident pending", "incident_uuid", incidentUUID, "err", err)
+				}
+			},
+			resume: func() (string, error) {
+				if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
+					slog.Warn("failed to mark resumed incident running", "incident_uuid", incidentUUID, "err", err)
+				}
+				return h.agentWSHandler.ContinueIncident(incidentUUID, incidentUUID, preemptionResumeMessage, llmSettings, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+			},
+		})
+		if err != nil {
+			slog.Error("failed to resume preempted investigation", "incident_uuid", incidentUUID, "err", err)
+			errorMsg := fmt.Sprintf("Failed to resume investigation: %v", err)
+			if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", taskHeader+lastStreamedLog, errorMsg, 0, 0); updateErr != nil {
+				slog.Error("failed to update incident status", "err", updateErr)
+			}
+			if canPost {
+				h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), true)
+				h.postSlackThreadReply(slackChannelID, slackMessageTS, errorMsg)
+			}
+			return
+		}
+		if !ok {
+			if typing != nil {
+				typing.Discard()
+			}
+			slog.Info("paused slack channel investigation displaced; leaving finalization to the new run", "incident_uuid", incidentUUID)
+			return
+		}
 
 		// Flush any buffered progress lines so the last status is not lost.
 		if progressStreamer != nil {
@@ -935,17 +1175,20 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 		// the DB or channel reactions; the replacement posts its own final
 		// thread reply when it finishes.
 		if superseded.Load() {
-			slog.Info("slack channel investigation superseded; leaving finalization to the new run", "incident_id", incidentUUID)
+			slog.Info("slack channel investigation superseded; leaving finalization to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
-		slog.Info("investigation done", "incident_id", incidentUUID, "has_error", hasError, "response_len", len(response), "session_id", sessionID)
+		slog.Info("investigation done", "incident_uuid", incidentUUID, "has_error", hasError, "response_len", len(response), "session_id", sessionID)
 
 		// Apply the first matching formatting rule before persistence and
 		// Slack posting. Passthrough on error/empty or when no rule
 		// matches the incident's flow. The listener channel is also the
 		// reply destination, so it doubles as the flow's channel identity.
-		dbResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, response, taskHeader+lastStreamedLog,
+		// The confidence block is taken out first so it never reaches the
+		// UI or Slack; full_log keeps the raw response.
+		confidence, result := output.ExtractConfidence(response)
+		dbResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, result, taskHeader+lastStreamedLog,
 			services.BuildFormatFlow(incidentUUID, channel.UUID))
 
 		// Re-attach the metrics footer AFTER formatting so the LLM never
@@ -953,8 +1196,9 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 		// Tokens). The deterministic footer lands at the end of the
 		// stored DB response and the Slack final-message body, where
 		// buildSlackFooter extracts it for the trailing metrics line.
-		dbResponseWithMetrics := appendFinalizeMetrics(dbResponse, finalExecutionTimeMs, finalTokensUsed, hasError)
-		rawWithMetrics := appendFinalizeMetrics(response, finalExecutionTimeMs, finalTokensUsed, hasError)
+		runMetrics := finalizeRunMetrics(lastStreamedLog, finalExecutionTimeMs, finalTokensUsed)
+		dbResponseWithMetrics := appendFinalizeMetrics(dbResponse, runMetrics, hasError)
+		rawWithMetrics := appendFinalizeMetrics(response, runMetrics, hasError)
 
 		// Build full log using the raw response (with metrics) so full_log
 		// preserves the original agent output for debugging.
@@ -1000,7 +1244,7 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 			if typing != nil {
 				typing.Discard()
 			}
-			slog.Info("slack channel investigation displaced during finalization; leaving DB + Slack post to the new run", "incident_id", incidentUUID)
+			slog.Info("slack channel investigation displaced during finalization; leaving DB + Slack post to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
@@ -1020,23 +1264,28 @@ func (h *AlertHandler) runListenerChannelInvestigation(
 		// Silent listeners (can_post=false) stop at the DB update above —
 		// the result is only visible in the UI.
 		if canPost {
-			h.updateSlackChannelReactions(slackChannelID, slackMessageTS, hasError)
-			slog.Info("posting Slack final summary as new thread reply", "response_len", len(formattedResponse), "incident", incidentUUID)
+			h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), hasError)
+			slog.Info("posting Slack final summary as new thread reply", "response_len", len(formattedResponse), "incident_uuid", incidentUUID)
 			h.postSlackThreadReply(slackChannelID, slackMessageTS, formattedResponse)
 		}
+		if !hasError {
+			if review := h.routeByConfidence(incidentUUID, confidence, alert.Severity); review != "" && canPost {
+				h.postSlackThreadReply(slackChannelID, slackMessageTS, review)
+			}
+		}
 
 		slog.Info("investigation completed for Slack channel alert", "alert", alert.AlertName)
 		return
 	}
 
 	// No WebSocket worker available
-	slog.Error("agent worker not connected for Slack channel incident", "incident", incidentUUID)
+	slog.Error("agent worker not connected for Slack channel incident", "incident_uuid", incidentUUID)
 	errorMsg := "Agent worker not connected. Please check that the agent-worker container is running."
 	if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", "", errorMsg, 0, 0); updateErr != nil {
 		slog.Error("failed to update incident status", "err", updateErr)
 	}
 	if canPost {
-		h.updateSlackChannelReactions(slackChannelID, slackMessageTS, true)
+		h.updateSlackChannelReactions(slackChannelID, slackMessageTS, channel.EffectiveSlackReactions(), true)
 		h.postSlackThreadReply(slackChannelID, slackMessageTS, errorMsg)
 	}
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 	"github.com/akmatori/akmatori/internal/testhelpers"
 	"github.com/google/uuid"
 )
@@ -56,7 +57,7 @@ func (s *insertTrackingService) getInsertCount() int {
 func setupResolvedAlertTestDB(t *testing.T) {
 	t.Helper()
 	testhelpers.NewGlobalSQLiteDB(t,
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 		&database.GeneralSettings{},
 		&database.SlackSettings{},
@@ -115,7 +116,7 @@ func seedResolvedAlertTestData(t *testing.T, incidentStatus database.IncidentSta
 func TestProcessAlert_SpawnsIncidentAndInsertsAlertRow(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
 		&database.SlackSettings{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 
@@ -318,3 +319,45 @@ func TestProcessResolvedAlert_NoMatchingAlert_DropsSilently(t *testing.T) {
 		t.Errorf("expected 0 alert rows after silent drop, got %d", count)
 	}
 }
+
+// TestProcessAlert_SilencedAlertSpawnsNothing verifies a firing alert inside
+// an active silence is recorded as suppressed and never reaches
+// SpawnIncidentManager.
+func TestProcessAlert_SilencedAlertSpawnsNothing(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t,
+		&database.SlackSettings{},
+		&database.Incident{}, &database.IncidentLogChunk{},
+		&database.Alert{},
+		&database.Silence{},
+		&database.SuppressedAlert{},
+	)
+	silences := services.NewSilenceService(db)
+	if err := silences.CreateSilence(context.Background(), &database.Silence{TargetHost: "web-*", EndsAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("create silence: %v", err)
+	}
+
+	svc := &insertTrackingService{corrGateSkillService: corrGateSkillService{spawnUUID: "should-not-spawn"}}
+	h := NewAlertHandler(nil, nil, nil, nil, svc, nil, nil)
+	h.SetAlertSilencer(silences)
+
+	instance := &database.AlertSourceInstance{
+		UUID:            "src-silenced",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "prometheus"},
+	}
+	h.processAlert(instance, alerts.NormalizedAlert{
+		AlertName:  "CPUHigh",
+		TargetHost: "web-maint",
+		Status:     database.AlertStatusFiring,
+		Severity:   database.AlertSeverityCritical,
+	})
+
+	if got := svc.getSpawnCount(); got != 0 {
+		t.Errorf("SpawnIncidentManager call count = %d, want 0", got)
+	}
+	var suppressed int64
+	db.Model(&database.SuppressedAlert{}).Count(&suppressed)
+	if suppressed != 1 {
+		t.Errorf("suppressed_alerts rows = %d, want 1", suppressed)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetAlertRouter wires the routing rules applied to firing alerts from alert
+// sources. Optional — when unset, every alert gets its source's settings.
+func (h *AlertHandler) SetAlertRouter(r services.AlertRouter) {
+	h.router = r
+}
+
+// routeAlert returns the routing rule matching a firing alert (nil when none
+// does) and the alert source as the rule sees it: a copy whose notification
+// channel and skills are replaced by the rule's when it sets them. The
+// source row itself is never modified. Routing fails open to the source's
+// settings.
+func (h *AlertHandler) routeAlert(instance *database.AlertSourceInstance, normalized alerts.NormalizedAlert) (*database.AlertSourceInstance, *database.RoutingRule) {
+	if h.router == nil {
+		return instance, nil
+	}
+	rule, err := h.router.MatchRoutingRule(context.Background(), instance.UUID, normalized)
+	if err != nil {
+		slog.Warn("routing rule lookup failed, using alert source settings", "alert_name", normalized.AlertName, "err", err)
+		return instance, nil
+	}
+	if rule == nil {
+		return instance, nil
+	}
+	slog.Info("alert matched routing rule", "alert_name", normalized.AlertName, "rule", rule.Name, "action", rule.Action)
+
+	routed := *instance
+	if rule.NotificationChannelID != nil {
+		routed.NotificationChannelID = rule.NotificationChannelID
+		routed.NotificationChannel = nil
+	}
+	if len(rule.Skills) > 0 {
+		routed.Skills = rule.Skills
+	}
+	return &routed, rule
+}
+
+// routedPriority is the severity an alert's investigation is scheduled at:
+// the rule's priority when it sets one, otherwise the alert's own severity.
+func routedPriority(rule *database.RoutingRule, normalized alerts.NormalizedAlert) database.AlertSeverity {
+	if rule != nil && rule.Priority != "" {
+		return rule.Priority
+	}
+	return normalized.Severity
+}
+
+// recordRoutedAlert closes out an incident whose routing rule asked for the
+// alert to be recorded only. The incident and alert row stay for history and
+// correlation; no agent runs.
+func (h *AlertHandler) recordRoutedAlert(incidentUUID string, rule *database.RoutingRule, channelID, threadTS string) {
+	note := fmt.Sprintf("Recorded by routing rule %q; not investigated.", rule.Name)
+	if err := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCompleted, "", "", note, 0, 0); err != nil {
+		slog.Warn("failed to complete recorded incident", "incident_uuid", incidentUUID, "err", err)
+	}
+	if channelID != "" && threadTS != "" {
+		h.postSlackThreadReply(channelID, threadTS, note)
+	}
+	slog.Info("recorded alert without investigation", "incident_uuid", incidentUUID, "rule", rule.Name)
+}
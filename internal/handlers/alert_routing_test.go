@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+type stubAlertRouter struct {
+	rule *database.RoutingRule
+}
+
+func (s stubAlertRouter) MatchRoutingRule(context.Context, string, alerts.NormalizedAlert) (*database.RoutingRule, error) {
+	return s.rule, nil
+}
+
+func TestRouteAlert_OverridesCopyOfSource(t *testing.T) {
+	sourceChannel, ruleChannel := uint(1), uint(2)
+	instance := &database.AlertSourceInstance{
+		UUID:                  "src-1",
+		NotificationChannelID: &sourceChannel,
+		Skills:                []database.Skill{{Name: "generic"}},
+	}
+	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
+
+	if routed, rule := h.routeAlert(instance, alerts.NormalizedAlert{}); routed != instance || rule != nil {
+		t.Fatalf("without a router: routed = %p, rule = %v; want the source unchanged", routed, rule)
+	}
+
+	h.SetAlertRouter(stubAlertRouter{rule: &database.RoutingRule{
+		Name:                  "databases",
+		NotificationChannelID: &ruleChannel,
+		Skills:                []database.Skill{{Name: "db-triage"}},
+		Priority:              database.AlertSeverityCritical,
+	}})
+	routed, rule := h.routeAlert(instance, alerts.NormalizedAlert{Severity: database.AlertSeverityWarning})
+	if *routed.NotificationChannelID != ruleChannel || routed.Skills[0].Name != "db-triage" {
+		t.Errorf("routed = %+v, want the rule's channel and skills", routed)
+	}
+	if *instance.NotificationChannelID != sourceChannel || instance.Skills[0].Name != "generic" {
+		t.Error("routing modified the alert source itself")
+	}
+	if got := routedPriority(rule, alerts.NormalizedAlert{Severity: database.AlertSeverityWarning}); got != database.AlertSeverityCritical {
+		t.Errorf("priority = %q, want the rule's", got)
+	}
+	if got := routedPriority(nil, alerts.NormalizedAlert{Severity: database.AlertSeverityWarning}); got != database.AlertSeverityWarning {
+		t.Errorf("priority without a rule = %q, want the alert's severity", got)
+	}
+}
+
+// recordingStatusService records incident status transitions.
+type recordingStatusService struct {
+	insertTrackingService
+	statusMu  sync.Mutex
+	statuses  []database.IncidentStatus
+	responses []string
+}
+
+func (s *recordingStatusService) UpdateIncidentStatus(_ string, status database.IncidentStatus, _, _ string) error {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *recordingStatusService) UpdateIncidentComplete(_ string, status database.IncidentStatus, _, _, response string, _ int, _ int64) error {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.statuses = append(s.statuses, status)
+	s.responses = append(s.responses, response)
+	return nil
+}
+
+func TestProcessAlert_RecordRuleSkipsInvestigation(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t,
+		&database.SlackSettings{},
+		&database.Incident{}, &database.IncidentLogChunk{},
+		&database.Alert{},
+		&database.RoutingRule{},
+		&database.RoutingRuleSkill{},
+		&database.Skill{},
+	)
+	router := services.NewRoutingRuleService(db)
+	if err := router.CreateRoutingRule(context.Background(), &database.RoutingRule{
+		Name:       "info noise",
+		Enabled:    true,
+		Severities: database.AlertSeverityList{database.AlertSeverityInfo},
+		Action:     database.RoutingActionRecord,
+	}, "", nil); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	svc := &recordingStatusService{insertTrackingService: insertTrackingService{corrGateSkillService: corrGateSkillService{spawnUUID: "recorded"}}}
+	h := NewAlertHandler(nil, nil, nil, nil, svc, nil, nil)
+	h.SetAlertRouter(router)
+
+	h.processAlert(&database.AlertSourceInstance{
+		UUID:            "src-routed",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "prometheus"},
+	}, alerts.NormalizedAlert{
+		AlertName:  "BackupFinished",
+		TargetHost: "db-01",
+		Status:     database.AlertStatusFiring,
+		Severity:   database.AlertSeverityInfo,
+	})
+
+	if got := svc.getSpawnCount(); got != 1 {
+		t.Fatalf("SpawnIncidentManager call count = %d, want 1", got)
+	}
+	if got := svc.getInsertCount(); got != 1 {
+		t.Errorf("InsertFiringAlert call count = %d, want 1", got)
+	}
+	svc.statusMu.Lock()
+	defer svc.statusMu.Unlock()
+	if len(svc.statuses) != 1 || svc.statuses[0] != database.IncidentStatusCompleted {
+		t.Errorf("status transitions = %v, want only completed", svc.statuses)
+	}
+	if len(svc.responses) != 1 || !strings.Contains(svc.responses[0], "info noise") {
+		t.Errorf("responses = %q, want a note naming the rule", svc.responses)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// preemptionResumeMessage is sent to the agent when a paused investigation is
+// resumed from its saved session.
+const preemptionResumeMessage = "Your investigation was paused so a critical-severity incident could be investigated first. " +
+	"Continue from where you left off and deliver the final report."
+
+// preemptionPauseNote is appended to the incident log while it is paused.
+const preemptionPauseNote = "\n\n⏸️ Paused: preempted by a critical-severity investigation. It will resume automatically.\n\n"
+
+// SetInvestigationScheduler wires the scheduler that bounds concurrent alert
+// investigations and lets critical alerts preempt lower-priority ones.
+// Optional — when unset, investigations start immediately.
+func (h *AlertHandler) SetInvestigationScheduler(s *services.InvestigationScheduler) {
+	h.investigationScheduler = s
+}
+
+// acquireInvestigationSlot waits for a scheduler slot for the incident,
+// counted against sourceUUID's per-source limit. While queued the incident is
+// shown as pending; it flips back to running once the slot is granted.
+// Returns nil when no scheduler is wired.
+func (h *AlertHandler) acquireInvestigationSlot(incidentUUID, sourceUUID string, severity database.AlertSeverity) *services.InvestigationSlot {
+	if h.investigationScheduler == nil {
+		return nil
+	}
+	slot := h.investigationScheduler.EnqueueForSource(incidentUUID, sourceUUID, services.InvestigationPriorityForSeverity(severity))
+	if slot.Granted() {
+		return slot
+	}
+
+	running, waiting := h.investigationScheduler.Stats()
+	slog.Info("investigation queued", "incident_uuid", incidentUUID, "severity", severity, "running", running, "waiting", waiting)
+	if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusPending, "", ""); err != nil {
+		slog.Warn("failed to mark queued incident pending", "incident_uuid", incidentUUID, "err", err)
+	}
+	_ = slot.Wait(context.Background())
+	if err := h.skillService.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
+		slog.Warn("failed to mark dequeued incident running", "incident_uuid", incidentUUID, "err", err)
+	}
+	return slot
+}
+
+// preemptibleRun is the completion signal for an agent run that may be
+// paused and resumed. Callbacks call finish; rearm resets the signal before
+// a resumed run registers its callback.
+type preemptibleRun struct {
+	mu   sync.Mutex
+	done chan struct{}
+	once *sync.Once
+}
+
+func newPreemptibleRun() *preemptibleRun {
+	return &preemptibleRun{done: make(chan struct{}), once: &sync.Once{}}
+}
+
+func (p *preemptibleRun) finish() {
+	p.mu.Lock()
+	done, once := p.done, p.once
+	p.mu.Unlock()
+	once.Do(func() { close(done) })
+}
+
+func (p *preemptibleRun) wait() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+func (p *preemptibleRun) rearm() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = make(chan struct{})
+	p.once = &sync.Once{}
+}
+
+// agentRunControl is the subset of worker operations awaitAgentRun needs.
+type agentRunControl struct {
+	cancel  func() error
+	release func(runID string) bool
+	// pause runs after the cancelled run has been released and before the
+	// slot is requeued; callers reset per-run state and record the pause.
+	pause func()
+	// resume starts the continuation run and returns its run ID.
+	resume func() (string, error)
+}
+
+// awaitAgentRun blocks until the agent run finishes. If the scheduler asks
+// for the slot first, the run is cancelled on the worker, released, and — once
+// a slot is free again — the same agent session is resumed. Returns the run ID
+// that should be finalized, or ok=false when the run was displaced by another
+// Start/Continue while pausing (the replacement then owns finalization). A
+// non-nil error means the resume could not be started.
+func awaitAgentRun(slot *services.InvestigationSlot, run *preemptibleRun, runID string, ctl agentRunControl) (string, bool, error) {
+	for {
+		if slot == nil {
+			<-run.wait()
+			return runID, true, nil
+		}
+
+		select {
+		case <-run.wait():
+			return runID, true, nil
+		case <-slot.Preempted():
+		}
+
+		// The run may have finished while the preemption was being signalled.
+		select {
+		case <-run.wait():
+			return runID, true, nil
+		default:
+		}
+
+		slog.Info("pausing investigation for critical incident", "incident_uuid", slot.IncidentUUID)
+		if err := ctl.cancel(); err != nil {
+			slog.Warn("failed to cancel preempted run", "incident_uuid", slot.IncidentUUID, "err", err)
+		}
+		// Whatever frame the aborted run emits (cancellation error or partial
+		// completion) is discarded; the resumed session produces the result.
+		<-run.wait()
+		if !ctl.release(runID) {
+			return runID, false, nil
+		}
+		ctl.pause()
+
+		if err := slot.Requeue(context.Background()); err != nil {
+			return "", true, err
+		}
+		slog.Info("resuming preempted investigation", "incident_uuid", slot.IncidentUUID)
+
+		run.rearm()
+		newRunID, err := ctl.resume()
+		if err != nil {
+			return "", true, err
+		}
+		runID = newRunID
+	}
+}
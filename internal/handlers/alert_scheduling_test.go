@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// awaitResult captures awaitAgentRun's return values from a goroutine.
+type awaitResult struct {
+	runID string
+	ok    bool
+	err   error
+}
+
+func startAwait(slot *services.InvestigationSlot, run *preemptibleRun, runID string, ctl agentRunControl) <-chan awaitResult {
+	out := make(chan awaitResult, 1)
+	go func() {
+		id, ok, err := awaitAgentRun(slot, run, runID, ctl)
+		out <- awaitResult{id, ok, err}
+	}()
+	return out
+}
+
+func waitAwait(t *testing.T, ch <-chan awaitResult) awaitResult {
+	t.Helper()
+	select {
+	case res := <-ch:
+		return res
+	case <-time.After(2 * time.Second):
+		t.Fatal("awaitAgentRun did not return")
+		return awaitResult{}
+	}
+}
+
+func TestAwaitAgentRun_NoSchedulerWaitsForCompletion(t *testing.T) {
+	run := newPreemptibleRun()
+	ch := startAwait(nil, run, "run-1", agentRunControl{})
+	run.finish()
+
+	res := waitAwait(t, ch)
+	if res.runID != "run-1" || !res.ok || res.err != nil {
+		t.Errorf("got %+v, want run-1/ok", res)
+	}
+}
+
+func TestAwaitAgentRun_PreemptedRunResumes(t *testing.T) {
+	sched := services.NewInvestigationScheduler(1)
+	slot := sched.Enqueue("inc-low", services.InvestigationPriorityLow)
+	run := newPreemptibleRun()
+
+	var cancelled, paused bool
+	var released string
+	resumed := make(chan struct{})
+	ctl := agentRunControl{
+		cancel: func() error {
+			cancelled = true
+			run.finish() // worker reports the cancellation
+			return nil
+		},
+		release: func(runID string) bool {
+			released = runID
+			return true
+		},
+		pause: func() { paused = true },
+		resume: func() (string, error) {
+			close(resumed)
+			return "run-2", nil
+		},
+	}
+	ch := startAwait(slot, run, "run-1", ctl)
+
+	crit := sched.Enqueue("inc-crit", services.InvestigationPriorityCritical)
+	deadline := time.After(2 * time.Second)
+	for !crit.Granted() {
+		select {
+		case <-deadline:
+			t.Fatal("critical slot not granted after preemption")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if !cancelled || !paused || released != "run-1" {
+		t.Fatalf("cancelled=%v paused=%v released=%q", cancelled, paused, released)
+	}
+
+	crit.Release()
+	select {
+	case <-resumed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("paused run was not resumed")
+	}
+	run.finish()
+
+	res := waitAwait(t, ch)
+	if res.runID != "run-2" || !res.ok || res.err != nil {
+		t.Errorf("got %+v, want run-2/ok", res)
+	}
+}
+
+func TestAwaitAgentRun_DisplacedRunStops(t *testing.T) {
+	sched := services.NewInvestigationScheduler(1)
+	slot := sched.Enqueue("inc-low", services.InvestigationPriorityLow)
+	run := newPreemptibleRun()
+
+	ctl := agentRunControl{
+		cancel:  func() error { run.finish(); return nil },
+		release: func(string) bool { return false },
+		pause:   func() { t.Error("pause must not run for a displaced run") },
+		resume: func() (string, error) {
+			t.Error("resume must not run for a displaced run")
+			return "", nil
+		},
+	}
+	ch := startAwait(slot, run, "run-1", ctl)
+	sched.Enqueue("inc-crit", services.InvestigationPriorityCritical)
+
+	res := waitAwait(t, ch)
+	if res.ok || res.err != nil {
+		t.Errorf("got %+v, want ok=false", res)
+	}
+}
+
+func TestAwaitAgentRun_ResumeError(t *testing.T) {
+	sched := services.NewInvestigationScheduler(1)
+	slot := sched.Enqueue("inc-low", services.InvestigationPriorityLow)
+	run := newPreemptibleRun()
+
+	resumeErr := errors.New("worker not connected")
+	ctl := agentRunControl{
+		cancel:  func() error { run.finish(); return nil },
+		release: func(string) bool { return true },
+		pause:   func() {},
+		resume:  func() (string, error) { return "", resumeErr },
+	}
+	ch := startAwait(slot, run, "run-1", ctl)
+
+	crit := sched.Enqueue("inc-crit", services.InvestigationPriorityCritical)
+	deadline := time.After(2 * time.Second)
+	for !crit.Granted() {
+		select {
+		case <-deadline:
+			t.Fatal("critical slot not granted")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	crit.Release()
+
+	res := waitAwait(t, ch)
+	if !errors.Is(res.err, resumeErr) {
+		t.Errorf("err = %v, want %v", res.err, resumeErr)
+	}
+}
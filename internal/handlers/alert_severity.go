@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetSeverityInferrer wires severity inference for alerts whose source sent
+// no usable severity. Optional — when unset such alerts keep the warning
+// default.
+func (h *AlertHandler) SetSeverityInferrer(s services.AlertSeverityInferrer) {
+	h.severityInferrer = s
+}
+
+// inferSeverity replaces the default severity of an alert whose source
+// omitted one, using the given severity_inference settings (nil for listener
+// channels, which get the rules). It runs before silencing and routing so
+// both see the inferred severity. Returns how the severity was decided, or
+// "" when it was left alone.
+func (h *AlertHandler) inferSeverity(settings map[string]interface{}, normalized *alerts.NormalizedAlert) string {
+	if h.severityInferrer == nil || !normalized.SeverityMissing {
+		return ""
+	}
+	mode, err := services.ParseSeverityInference(settings)
+	if err != nil {
+		slog.Warn("ignoring invalid severity_inference setting", "err", err)
+		mode = services.SeverityInferenceRules
+	}
+	if mode == services.SeverityInferenceOff {
+		return ""
+	}
+
+	severity, by, ok := h.severityInferrer.InferSeverity(context.Background(), *normalized, mode == services.SeverityInferenceLLM)
+	if !ok {
+		return ""
+	}
+	slog.Info("inferred alert severity", "alert_name", normalized.AlertName, "severity", severity, "by", by)
+	normalized.Severity = severity
+	normalized.SeverityMissing = false
+	return by
+}
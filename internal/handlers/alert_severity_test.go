@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+type stubSeverityInferrer struct {
+	calls  int
+	useLLM bool
+}
+
+func (s *stubSeverityInferrer) InferSeverity(ctx context.Context, alert alerts.NormalizedAlert, useLLM bool) (database.AlertSeverity, string, bool) {
+	s.calls++
+	s.useLLM = useLLM
+	return database.AlertSeverityCritical, services.SeverityInferredByRules, true
+}
+
+func TestAlertHandler_InferSeverity(t *testing.T) {
+	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
+	missing := func() *alerts.NormalizedAlert {
+		return &alerts.NormalizedAlert{AlertName: "api down", Severity: database.AlertSeverityWarning, SeverityMissing: true}
+	}
+	if by := h.inferSeverity(nil, missing()); by != "" {
+		t.Errorf("unwired inferSeverity = %q, want no-op", by)
+	}
+
+	stub := &stubSeverityInferrer{}
+	h.SetSeverityInferrer(stub)
+
+	alert := missing()
+	if by := h.inferSeverity(map[string]interface{}{"severity_inference": "llm"}, alert); by != services.SeverityInferredByRules {
+		t.Fatalf("inferSeverity = %q, want rules", by)
+	}
+	if alert.Severity != database.AlertSeverityCritical || alert.SeverityMissing || !stub.useLLM {
+		t.Errorf("alert = %+v, useLLM = %v", alert, stub.useLLM)
+	}
+
+	stub.calls = 0
+	h.inferSeverity(map[string]interface{}{"severity_inference": "off"}, missing())
+	h.inferSeverity(nil, &alerts.NormalizedAlert{Severity: database.AlertSeverityInfo})
+	if stub.calls != 0 {
+		t.Errorf("inferrer called %d times for off mode / known severity", stub.calls)
+	}
+
+	h.inferSeverity(map[string]interface{}{"severity_inference": "bogus"}, missing())
+	if stub.calls != 1 || stub.useLLM {
+		t.Errorf("invalid mode should fall back to rules: calls=%d useLLM=%v", stub.calls, stub.useLLM)
+	}
+}
@@ -5,12 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"strings"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
 	"github.com/slack-go/slack"
 )
 
@@ -71,12 +71,18 @@ func (h *AlertHandler) resolveSlackExternalID(externalID string) string {
 	}
 	resolved, err := h.channelResolver.ResolveChannel(externalID)
 	if err != nil {
-		slog.Warn("failed to resolve slack channel", "external_id", externalID, "err", err)
+		slog.Error("failed to resolve slack channel; posting by name", "external_id", externalID, "err", err)
 		return externalID
 	}
 	return resolved
 }
 
+// isSlackChannelNotFound reports whether err is Slack's channel_not_found,
+// possibly wrapped by a messaging provider.
+func isSlackChannelNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "channel_not_found")
+}
+
 // postAlertToSlack posts the initial alert banner and returns the Slack
 // channel ID, the message timestamp, and the resolved Channel row UUID (used
 // for formatting-rule matching; "" when posting was skipped).
@@ -91,50 +97,43 @@ func (h *AlertHandler) postAlertToSlack(alert alerts.NormalizedAlert, instance *
 		return "", "", "", nil
 	}
 
-	// Format alert message
-	emoji := database.GetSeverityEmoji(alert.Severity)
-	message := fmt.Sprintf(`%s *Alert: %s*
-
-:label: *Source:* %s (%s)
-:computer: *Host:* %s
-:gear: *Service:* %s
-:warning: *Severity:* %s
-:memo: *Summary:* %s`,
-		emoji,
-		alert.AlertName,
-		instance.AlertSourceType.DisplayName,
-		instance.Name,
-		alert.TargetHost,
-		alert.TargetService,
-		alert.Severity,
-		alert.Summary,
-	)
-
-	if alert.RunbookURL != "" {
-		message += fmt.Sprintf("\n:book: *Runbook:* %s", alert.RunbookURL)
-	}
+	data := alertNotificationData(alert, instance)
+	message := h.renderNotification(services.NotificationAlertPosted, data)
 
 	// Post message via the messaging provider when available; fall back to
 	// the slack client directly when no provider is registered for this
 	// channel's provider name (keeps tests + legacy boot paths working).
-	ts, err := h.postViaProvider(context.Background(), channel, channelID, message)
-	if err != nil {
-		return "", "", "", err
-	}
-	if ts == "" {
-		_, t, err := slackClient.PostMessage(channelID, slack.MsgOptionText(message, false))
-		if err != nil {
-			return "", "", "", err
+	post := func(target string) (string, error) {
+		ts, err := h.postViaProvider(context.Background(), channel, target, message)
+		if err != nil || ts != "" {
+			return ts, err
+		}
+		_, ts, err = slackClient.PostMessage(target, slack.MsgOptionText(message, false))
+		return ts, err
+	}
+	ts, err := post(channelID)
+	// A cached name -> ID mapping goes stale when a channel is renamed or
+	// recreated while no event reached us; re-resolve once before giving up.
+	if err != nil && isSlackChannelNotFound(err) && h.channelResolver != nil && !slackutil.IsChannelID(channel.ExternalID) {
+		h.channelResolver.Invalidate(channel.ExternalID)
+		if retryID := h.resolveSlackExternalID(channel.ExternalID); retryID != channelID {
+			slog.Info("retrying alert post after channel re-resolution", "external_id", channel.ExternalID, "channel_id", retryID)
+			channelID = retryID
+			ts, err = post(channelID)
 		}
-		ts = t
+	}
+	if err != nil {
+		return "", "", "", fmt.Errorf("post alert to slack channel %q (channel uuid %s): %w", channel.ExternalID, channel.UUID, err)
 	}
 
 	// Add reaction
-	if err := slackClient.AddReaction("rotating_light", slack.ItemRef{
-		Channel:   channelID,
-		Timestamp: ts,
-	}); err != nil {
-		slog.Warn("failed to add reaction", "err", err)
+	if reaction := channel.EffectiveSlackReactions().Alert; reaction != "" {
+		if err := slackClient.AddReaction(reaction, slack.ItemRef{
+			Channel:   channelID,
+			Timestamp: ts,
+		}); err != nil {
+			slog.Warn("failed to add reaction", "err", err)
+		}
 	}
 
 	return channelID, ts, channel.UUID, nil
@@ -186,8 +185,33 @@ func (h *AlertHandler) postSlackThreadReply(channelID, threadTS, message string)
 	}
 }
 
+// notificationChannel loads the channel an alert was posted to so its Slack
+// presentation overrides apply to the investigation. Returns nil — which
+// resolves to the default reactions and verbosity — when the channel is
+// unknown or no channel service is wired.
+func (h *AlertHandler) notificationChannel(channelUUID string) *database.Channel {
+	if channelUUID == "" || h.channelService == nil {
+		return nil
+	}
+	channel, err := h.channelService.GetChannelByUUID(channelUUID)
+	if err != nil {
+		slog.Debug("notification channel lookup failed", "channel_uuid", channelUUID, "err", err)
+		return nil
+	}
+	return channel
+}
+
+// resultReaction picks the terminal reaction for a run; "" when the channel
+// suppresses it.
+func resultReaction(reactions database.SlackReactions, hasError bool) string {
+	if hasError {
+		return reactions.Failure
+	}
+	return reactions.Success
+}
+
 // updateSlackChannelReactions updates reactions on the original Slack message
-func (h *AlertHandler) updateSlackChannelReactions(channelID, messageTS string, hasError bool) {
+func (h *AlertHandler) updateSlackChannelReactions(channelID, messageTS string, reactions database.SlackReactions, hasError bool) {
 	slackClient := h.slackManager.GetClient()
 	if slackClient == nil {
 		return
@@ -197,9 +221,9 @@ func (h *AlertHandler) updateSlackChannelReactions(channelID, messageTS string,
 	// runListenerChannelInvestigation's deferred Stop.
 
 	// Add result reaction
-	reactionName := "white_check_mark"
-	if hasError {
-		reactionName = "x"
+	reactionName := resultReaction(reactions, hasError)
+	if reactionName == "" {
+		return
 	}
 	if err := slackClient.AddReaction(reactionName, slack.ItemRef{
 		Channel:   channelID,
@@ -213,7 +237,7 @@ func (h *AlertHandler) updateSlackChannelReactions(channelID, messageTS string,
 // resolved Slack channel for the alert's destination — typically the same
 // channel that postAlertToSlack posted to. Empty channelID is treated as a
 // no-op so we don't surface a stray reaction on the wrong thread.
-func (h *AlertHandler) updateSlackWithResult(channelID, threadTS, response string, hasError bool) {
+func (h *AlertHandler) updateSlackWithResult(incidentUUID, channelID, threadTS, response string, reactions database.SlackReactions, hasError bool) {
 	if threadTS == "" || channelID == "" {
 		return
 	}
@@ -224,15 +248,13 @@ func (h *AlertHandler) updateSlackWithResult(channelID, threadTS, response strin
 	}
 
 	// Add result reaction
-	reactionName := "white_check_mark"
-	if hasError {
-		reactionName = "x"
-	}
-	if err := slackClient.AddReaction(reactionName, slack.ItemRef{
-		Channel:   channelID,
-		Timestamp: threadTS,
-	}); err != nil {
-		slog.Warn("failed to add reaction", "err", err)
+	if reactionName := resultReaction(reactions, hasError); reactionName != "" {
+		if err := slackClient.AddReaction(reactionName, slack.ItemRef{
+			Channel:   channelID,
+			Timestamp: threadTS,
+		}); err != nil {
+			slog.Warn("failed to add reaction", "err", err)
+		}
 	}
 
 	// Post result summary
@@ -242,7 +264,27 @@ func (h *AlertHandler) updateSlackWithResult(channelID, threadTS, response strin
 		slack.MsgOptionTS(threadTS),
 	); err != nil {
 		slog.Error("failed to post message", "err", err)
+		return
+	}
+	summary := "Investigation result posted to Slack"
+	if hasError {
+		summary = "Investigation failure posted to Slack"
+	}
+	h.recordSlackPost(incidentUUID, channelID, threadTS, summary)
+}
+
+// recordSlackPost puts a Slack post on the incident's timeline.
+func (h *AlertHandler) recordSlackPost(incidentUUID, channelID, threadTS, summary string) {
+	if h.timeline == nil || incidentUUID == "" {
+		return
 	}
+	h.timeline.RecordEvent(database.IncidentEvent{
+		IncidentUUID: incidentUUID,
+		Type:         database.IncidentEventSlackPost,
+		Summary:      summary,
+		Details:      database.JSONB{"channel_id": channelID, "thread_ts": threadTS},
+		Actor:        "system",
+	})
 }
 
 // isSlackEnabled checks if Slack integration is active
@@ -287,15 +329,13 @@ func buildSlackFooter(response, incidentUUID string) (responseWithoutMetrics, fo
 		responseWithoutMetrics = response
 	}
 
-	baseURL := resolveBaseURL()
-
 	var sb strings.Builder
 	sb.WriteString("\n\n———\n")
 	if metricsLine != "" {
 		sb.WriteString(metricsLine)
 		sb.WriteString("\n")
 	}
-	sb.WriteString(fmt.Sprintf("<%s/incidents/%s|View reasoning log>", baseURL, incidentUUID))
+	sb.WriteString(fmt.Sprintf("<%s|View reasoning log>", services.IncidentURL(incidentUUID)))
 	footer = sb.String()
 	return
 }
@@ -380,40 +420,52 @@ func (h *AlertHandler) updateIncidentSlackContext(incidentUUID, channelID, messa
 // cannot be loaded, it degrades to a link with the UUID.
 func (h *AlertHandler) buildAlertMergedMessage(incidentUUID string) string {
 	baseURL := resolveBaseURL()
-	link := fmt.Sprintf("<%s/incidents/%s|incident>", baseURL, incidentUUID)
+	data := services.NotificationData{
+		IncidentUUID: incidentUUID,
+		IncidentURL:  services.IncidentURL(incidentUUID),
+		BaseURL:      baseURL,
+	}
 
 	incident, err := h.skillService.GetIncident(incidentUUID)
-	if err != nil || incident == nil {
-		return fmt.Sprintf("Alert merged into existing %s (ID: %s)", link, incidentUUID)
+	if err == nil && incident != nil {
+		data.IncidentTitle = strings.TrimSpace(incident.Title)
+		if data.IncidentTitle == "" {
+			data.IncidentTitle = "untitled incident"
+		}
+		database.GetDB().Model(&database.Alert{}).
+			Where("incident_uuid = ?", incident.UUID).Count(&data.AlertCount)
 	}
+	return h.renderNotification(services.NotificationAlertMerged, data)
+}
 
-	subject := strings.TrimSpace(incident.Title)
-	if subject == "" {
-		subject = "untitled incident"
-	}
+// SetNotificationRenderer wires the renderer that applies operator
+// notification templates. Optional — when unset, built-in text is used.
+func (h *AlertHandler) SetNotificationRenderer(r services.NotificationRenderer) {
+	h.notificationRenderer = r
+}
 
-	var alertCount int64
-	database.GetDB().Model(&database.Alert{}).
-		Where("incident_uuid = ?", incident.UUID).Count(&alertCount)
+// renderNotification renders an outbound message through the configured
+// renderer, or the built-in template when none is wired.
+func (h *AlertHandler) renderNotification(kind services.NotificationKind, data services.NotificationData) string {
+	if h.notificationRenderer == nil {
+		return services.RenderDefaultNotification(kind, data)
+	}
+	return h.notificationRenderer.RenderNotification(kind, data)
+}
 
-	plural := "alerts"
-	if alertCount == 1 {
-		plural = "alert"
+// alertNotificationData builds the notification template context for a
+// normalized alert. instance may be nil for alerts without a webhook source.
+func alertNotificationData(alert alerts.NormalizedAlert, instance *database.AlertSourceInstance) services.NotificationData {
+	return services.NotificationData{
+		AlertTemplateVars: services.NewAlertTemplateVars(alert, instance),
+		BaseURL:           resolveBaseURL(),
 	}
-	return fmt.Sprintf("Alert merged into existing %s: *%s* — %d %s linked",
-		link, subject, alertCount, plural)
 }
 
 // resolveBaseURL returns the base URL for incident links (package-level helper).
 // Priority: DB GeneralSettings > AKMATORI_BASE_URL env var > fallback.
 func resolveBaseURL() string {
-	if settings, err := database.GetOrCreateGeneralSettings(); err == nil && settings.BaseURL != "" {
-		return strings.TrimRight(settings.BaseURL, "/")
-	}
-	if envURL := os.Getenv("AKMATORI_BASE_URL"); envURL != "" {
-		return envURL
-	}
-	return "http://localhost:3000"
+	return services.BaseURL()
 }
 
 // getBaseURL returns the base URL for incident links.
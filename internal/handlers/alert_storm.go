@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetAlertStormGuard wires burst coalescing and storm mode for firing
+// alerts. Optional — when unset every alert goes through correlation and may
+// spawn its own investigation.
+func (h *AlertHandler) SetAlertStormGuard(g *services.AlertStormGuard) {
+	h.stormGuard = g
+}
+
+// coalesced reports whether an identical alert spawned an incident within
+// the coalesce window, in which case this repeat is dropped.
+func (h *AlertHandler) coalesced(key string, normalized alerts.NormalizedAlert) bool {
+	if h.stormGuard == nil {
+		return false
+	}
+	incidentUUID, ok := h.stormGuard.Coalesced(key)
+	if ok {
+		slog.Info("alert coalesced into recent incident", "alert_name", normalized.AlertName, "target_host", normalized.TargetHost, "incident_uuid", incidentUUID)
+	}
+	return ok
+}
+
+// recordSpawn feeds a newly spawned incident to the storm guard.
+func (h *AlertHandler) recordSpawn(sourceUUID, key, incidentUUID string) {
+	if h.stormGuard != nil {
+		h.stormGuard.RecordSpawn(sourceUUID, key, incidentUUID)
+	}
+}
+
+// forgetCoalesced lets a resolved alert that fires again spawn a new
+// incident straight away.
+func (h *AlertHandler) forgetCoalesced(sourceUUID string, normalized alerts.NormalizedAlert) {
+	if h.stormGuard != nil {
+		h.stormGuard.Forget(alertSpawnKey(sourceUUID, normalized.AlertName, normalized.TargetHost, normalized.SourceFingerprint))
+	}
+}
+
+// groupIntoStorm files the alert under its source's storm summary incident
+// when the source is in storm mode. Critical alerts are never grouped. A
+// failure to group returns false so the alert is investigated as usual.
+func (h *AlertHandler) groupIntoStorm(instance *database.AlertSourceInstance, normalized alerts.NormalizedAlert, priority database.AlertSeverity) bool {
+	if h.stormGuard == nil || priority == database.AlertSeverityCritical {
+		return false
+	}
+	storm, ok := h.stormGuard.Storm(instance.UUID)
+	if !ok {
+		return false
+	}
+
+	incidentUUID := storm.IncidentUUID
+	if incidentUUID == "" {
+		// Concurrent alerts of the same storm share one summary incident.
+		v, err, _ := h.spawnGroup.Do("storm|"+instance.UUID, func() (interface{}, error) {
+			if current, ok := h.stormGuard.Storm(instance.UUID); ok && current.IncidentUUID != "" {
+				return current.IncidentUUID, nil
+			}
+			created, err := h.openStormIncident(instance, normalized)
+			if err != nil {
+				return "", err
+			}
+			h.stormGuard.SetStormIncident(instance.UUID, created)
+			return created, nil
+		})
+		if err != nil {
+			slog.Error("failed to open alert storm incident, investigating alert", "instance", instance.Name, "err", err)
+			return false
+		}
+		incidentUUID = v.(string)
+	}
+
+	if err := h.skillService.LinkAlertToIncident(context.Background(), incidentUUID, instance.UUID, normalized, 1, "alert storm"); err != nil {
+		slog.Warn("failed to group alert into storm incident, investigating alert", "incident_uuid", incidentUUID, "err", err)
+		return false
+	}
+	grouped := h.stormGuard.AddToStorm(instance.UUID)
+	slog.Info("alert grouped into storm incident", "alert_name", normalized.AlertName, "instance", instance.Name, "incident_uuid", incidentUUID, "grouped", grouped)
+	return true
+}
+
+// openStormIncident creates the summary incident for a source's storm. It is
+// completed straight away — its alerts are listed, not investigated — and
+// announced once in Slack.
+func (h *AlertHandler) openStormIncident(instance *database.AlertSourceInstance, trigger alerts.NormalizedAlert) (string, error) {
+	incidentUUID, _, err := h.skillService.SpawnIncidentManager(&services.IncidentContext{
+		Source:     instance.AlertSourceType.Name,
+		SourceKind: database.IncidentSourceKindAlert,
+		SourceUUID: instance.UUID,
+		Context: database.JSONB{
+			"alert_storm":     true,
+			"alert_name":      trigger.AlertName,
+			"severity":        string(trigger.Severity),
+			"source_type":     instance.AlertSourceType.Name,
+			"source_instance": instance.Name,
+		},
+		Message: fmt.Sprintf("Alert storm on %s", instance.Name),
+	})
+	if err != nil {
+		return "", err
+	}
+	slog.Warn("alert storm detected, grouping further alerts", "instance", instance.Name, "incident_uuid", incidentUUID)
+
+	note := fmt.Sprintf("Alert storm: %s is sending alerts faster than they can be investigated. "+
+		"Until the rate drops, its non-critical alerts are grouped into this incident without investigation.", instance.Name)
+
+	var channelID, threadTS string
+	if h.isSlackEnabled() {
+		channelID, threadTS, _, err = h.postAlertToSlack(trigger, instance)
+		if err != nil {
+			slog.Warn("failed to post alert storm to Slack", "err", err)
+		} else if channelID != "" && threadTS != "" {
+			h.recordSlackPost(incidentUUID, channelID, threadTS, "Alert storm posted to Slack")
+			if err := h.updateIncidentSlackContext(incidentUUID, channelID, threadTS); err != nil {
+				slog.Warn("failed to update incident Slack context", "err", err)
+			}
+			h.postSlackThreadReply(channelID, threadTS, note)
+		}
+	}
+	if err := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCompleted, "", "", note, 0, 0); err != nil {
+		slog.Warn("failed to complete alert storm incident", "incident_uuid", incidentUUID, "err", err)
+	}
+	return incidentUUID, nil
+}
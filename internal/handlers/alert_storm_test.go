@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAlertHandler_StormProtection(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t,
+		&database.SlackSettings{},
+		&database.Incident{}, &database.IncidentLogChunk{},
+		&database.Alert{},
+	)
+	svc := &corrGateSkillService{}
+	h := NewAlertHandler(nil, nil, nil, nil, svc, nil, nil)
+	h.SetAlertStormGuard(services.NewAlertStormGuard(services.AlertStormSettings{
+		CoalesceWindow: time.Minute,
+		Threshold:      2,
+		Window:         time.Minute,
+	}))
+	instance := &database.AlertSourceInstance{
+		UUID:            "src-uuid",
+		Name:            "flappy",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "prometheus"},
+	}
+	alert := func(host string, severity database.AlertSeverity) {
+		a := newCorrTestAlert()
+		a.TargetHost, a.Severity = host, severity
+		h.processAlert(instance, a)
+	}
+
+	alert("web01", database.AlertSeverityWarning)
+	alert("web01", database.AlertSeverityWarning) // coalesced
+	if spawns := svc.getSpawnCount(); spawns != 1 {
+		t.Fatalf("spawns after a repeat = %d, want 1", spawns)
+	}
+
+	alert("web02", database.AlertSeverityWarning) // second spawn starts the storm
+	alert("web03", database.AlertSeverityWarning) // opens the summary incident
+	alert("web04", database.AlertSeverityHigh)
+	if spawns, links := svc.getSpawnCount(), svc.getLinkCount(); spawns != 3 || links != 2 {
+		t.Fatalf("during storm: spawns=%d links=%d, want 3 and 2", spawns, links)
+	}
+
+	alert("db01", database.AlertSeverityCritical) // never grouped
+	if spawns := svc.getSpawnCount(); spawns != 4 {
+		t.Errorf("critical alert during storm: spawns = %d, want 4", spawns)
+	}
+}
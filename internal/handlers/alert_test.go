@@ -8,10 +8,13 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/faultinject"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
 // contains checks if s contains substr
@@ -226,7 +229,7 @@ func TestAlertHandler_HandleWebhook_MethodNotAllowed(t *testing.T) {
 			req := httptest.NewRequest(method, "/webhook/alert/test-uuid", nil)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			if w.Code != http.StatusMethodNotAllowed {
 				t.Errorf("HandleWebhook(%s) = %d, want %d", method, w.Code, http.StatusMethodNotAllowed)
@@ -241,10 +244,38 @@ func TestAlertHandler_HandleWebhook_MissingUUID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/webhook/alert/", nil)
 	w := httptest.NewRecorder()
 
-	h.HandleWebhook(w, req)
+	serveWebhook(h, w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("HandleWebhook with empty UUID = %d, want %d", w.Code, http.StatusBadRequest)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("HandleWebhook with empty UUID = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+type recordingHealth struct {
+	webhooks []bool
+}
+
+func (r *recordingHealth) RecordWebhook(failed bool) { r.webhooks = append(r.webhooks, failed) }
+func (r *recordingHealth) RecordAgentResult(string)  {}
+
+func TestAlertHandler_HandleWebhook_RecordsHealth(t *testing.T) {
+	// No adapter is registered for the instance's type, so its webhooks
+	// are answered 400.
+	manager := &mockAlertManager{instance: &database.AlertSourceInstance{
+		UUID:            "x",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "alertmanager"},
+	}}
+	h := NewAlertHandler(nil, nil, nil, nil, nil, manager, nil)
+	health := &recordingHealth{}
+	h.SetHealthRecorder(health)
+
+	serveWebhook(h, httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhook/alert/x", nil))
+	manager.instance, manager.getInstanceErr = nil, errors.New("not found")
+	serveWebhook(h, httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhook/alert/x", nil))
+
+	if len(health.webhooks) != 2 || !health.webhooks[0] || health.webhooks[1] {
+		t.Errorf("recorded = %v, want [true false] (400 counts, 404 does not)", health.webhooks)
 	}
 }
 
@@ -498,6 +529,22 @@ func TestAlertHandler_buildInvestigationPrompt(t *testing.T) {
 		}
 	})
 
+	t.Run("enrichment sections sit between alert details and instructions", func(t *testing.T) {
+		result := h.buildInvestigationPrompt(
+			alerts.NormalizedAlert{AlertName: "DiskFull", RawPayload: map[string]interface{}{"original_message": "disk at 97%"}},
+			&database.AlertSourceInstance{Name: "prod", AlertSourceType: database.AlertSourceType{Name: "zabbix", DisplayName: "Zabbix"}},
+			services.EnrichmentSection{Step: "cmdb", Title: "CMDB record", Body: "Device: web-1"},
+			services.EnrichmentSection{Step: "runbook_match", Title: "Runbooks that may apply", Body: "- Disk full: /akmatori/runbooks/1-disk-full.md\n"},
+		)
+		original := strings.Index(result, "Original alert text:")
+		cmdb := strings.Index(result, "\n\nCMDB record:\nDevice: web-1")
+		runbooks := strings.Index(result, "\n\nRunbooks that may apply:\n- Disk full")
+		please := strings.Index(result, "\n\nPlease:")
+		if original < 0 || cmdb < original || runbooks < cmdb || please < runbooks {
+			t.Errorf("sections out of place: %q", result)
+		}
+	})
+
 	t.Run("source line omitted when both empty", func(t *testing.T) {
 		result := h.buildInvestigationPrompt(
 			alerts.NormalizedAlert{AlertName: "X"},
@@ -950,6 +997,33 @@ func TestAlertHandler_HandleWebhook_ErrorPathsAndSuccess(t *testing.T) {
 			expectedStatus: http.StatusForbidden,
 			wantBody:       "Instance disabled",
 		},
+		{
+			name: "source IP outside allowlist",
+			path: "/webhook/alert/test-uuid",
+			service: &mockAlertManager{instance: &database.AlertSourceInstance{
+				UUID:            "test-uuid",
+				Enabled:         true,
+				Settings:        database.JSONB{"allowed_cidrs": []interface{}{"10.0.0.0/8"}},
+				AlertSourceType: database.AlertSourceType{Name: "alertmanager"},
+			}},
+			adapter:        &mockAlertAdapter{sourceType: "alertmanager", alerts: []alerts.NormalizedAlert{}},
+			expectedStatus: http.StatusForbidden,
+			wantBody:       "Forbidden",
+		},
+		{
+			name: "source IP inside allowlist",
+			path: "/webhook/alert/test-uuid",
+			service: &mockAlertManager{instance: &database.AlertSourceInstance{
+				UUID:            "test-uuid",
+				Enabled:         true,
+				Settings:        database.JSONB{"allowed_cidrs": []interface{}{"10.0.0.0/8", "192.0.2.1"}},
+				AlertSourceType: database.AlertSourceType{Name: "alertmanager"},
+			}},
+			adapter:        &mockAlertAdapter{sourceType: "alertmanager", alerts: []alerts.NormalizedAlert{}},
+			body:           `{"status":"ok"}`,
+			expectedStatus: http.StatusOK,
+			wantBody:       "Received 0 alerts",
+		},
 		{
 			name:           "unsupported source type",
 			path:           "/webhook/alert/test-uuid",
@@ -1009,7 +1083,7 @@ func TestAlertHandler_HandleWebhook_ErrorPathsAndSuccess(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, tt.path, reader)
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			if w.Code != tt.expectedStatus {
 				t.Fatalf("status = %d, want %d; body=%q", w.Code, tt.expectedStatus, w.Body.String())
@@ -1020,10 +1094,20 @@ func TestAlertHandler_HandleWebhook_ErrorPathsAndSuccess(t *testing.T) {
 			if tt.service != nil && tt.service.lastUUID != strings.TrimPrefix(strings.TrimSuffix(tt.path, "/"), "/webhook/alert/") {
 				t.Fatalf("GetInstanceByUUID called with %q, want %q", tt.service.lastUUID, strings.TrimPrefix(strings.TrimSuffix(tt.path, "/"), "/webhook/alert/"))
 			}
+			// Only deliveries that parsed count as the source being alive.
+			if tt.service != nil {
+				wantMarked := 0
+				if tt.expectedStatus == http.StatusOK {
+					wantMarked = 1
+				}
+				if tt.service.alertsMarked != wantMarked {
+					t.Fatalf("MarkInstanceAlertReceived called %d times, want %d", tt.service.alertsMarked, wantMarked)
+				}
+			}
 			if tt.adapter != nil {
-				if tt.wantBody == "Unsupported source type" {
+				if tt.wantBody == "Unsupported source type" || tt.wantBody == "Forbidden" {
 					if tt.adapter.validateCalls != 0 || tt.adapter.parseCalls != 0 {
-						t.Fatalf("unsupported source type should not invoke adapter, got validate=%d parse=%d", tt.adapter.validateCalls, tt.adapter.parseCalls)
+						t.Fatalf("%s should not invoke adapter, got validate=%d parse=%d", tt.name, tt.adapter.validateCalls, tt.adapter.parseCalls)
 					}
 					return
 				}
@@ -1040,6 +1124,33 @@ func TestAlertHandler_HandleWebhook_ErrorPathsAndSuccess(t *testing.T) {
 	}
 }
 
+func TestAlertHandler_HandleWebhook_InjectedParseError(t *testing.T) {
+	instance := &database.AlertSourceInstance{
+		UUID:            "test-uuid",
+		Enabled:         true,
+		AlertSourceType: database.AlertSourceType{Name: "alertmanager"},
+	}
+	adapter := &mockAlertAdapter{sourceType: "alertmanager", alerts: []alerts.NormalizedAlert{{AlertName: "HighCPU"}}}
+	h := NewAlertHandler(nil, nil, nil, nil, nil, &mockAlertManager{instance: instance}, nil)
+	h.RegisterAdapter(adapter)
+	faults := faultinject.New(map[faultinject.Fault]float64{faultinject.AdapterParseError: 1})
+	h.SetFaultInjector(faults)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/alert/test-uuid", strings.NewReader(`{"status":"ok"}`))
+	w := httptest.NewRecorder()
+	serveWebhook(h, w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Invalid payload") {
+		t.Fatalf("status = %d body = %q, want 400 Invalid payload", w.Code, w.Body.String())
+	}
+	if adapter.parseCalls != 1 {
+		t.Errorf("parseCalls = %d, want the real adapter to run first", adapter.parseCalls)
+	}
+	if faults.Injected(faultinject.AdapterParseError) != 1 {
+		t.Errorf("injected = %d, want 1", faults.Injected(faultinject.AdapterParseError))
+	}
+}
+
 func cloneInstance(in *database.AlertSourceInstance) *database.AlertSourceInstance {
 	if in == nil {
 		return nil
@@ -1060,6 +1171,7 @@ type mockAlertManager struct {
 	instance       *database.AlertSourceInstance
 	getInstanceErr error
 	lastUUID       string
+	alertsMarked   int
 }
 
 func (m *mockAlertManager) ListSourceTypes() ([]database.AlertSourceType, error) {
@@ -1105,8 +1217,13 @@ func (m *mockAlertManager) UpdateInstance(uuid string, updates map[string]interf
 func (m *mockAlertManager) UpdateInstanceByID(id uint, name, description, webhookSecret string, fieldMappings, settings database.JSONB, enabled bool) error {
 	return nil
 }
-func (m *mockAlertManager) DeleteInstance(uuid string) error    { return nil }
-func (m *mockAlertManager) DeleteInstanceByID(id uint) error    { return nil }
-func (m *mockAlertManager) InitializeDefaultSourceTypes() error { return nil }
+func (m *mockAlertManager) MarkInstanceAlertReceived(id uint, at time.Time) error {
+	m.alertsMarked++
+	return nil
+}
+func (m *mockAlertManager) SetInstanceSkills(uuid string, skillNames []string) error { return nil }
+func (m *mockAlertManager) DeleteInstance(uuid string) error                         { return nil }
+func (m *mockAlertManager) DeleteInstanceByID(id uint) error                         { return nil }
+func (m *mockAlertManager) InitializeDefaultSourceTypes() error                      { return nil }
 
 // HandleWebhook tests with full dependencies are in integration_test.go
@@ -2,34 +2,63 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/middleware"
 	"github.com/akmatori/akmatori/internal/services"
 	slackutil "github.com/akmatori/akmatori/internal/slack"
 )
 
 // APIHandler handles API endpoints for the UI and skill communication
 type APIHandler struct {
-	skillService         services.SkillIncidentManager
-	toolService          services.ToolManager
-	contextService       services.ContextManager
-	alertService         services.AlertManager
-	agentExecutor        *executor.Executor
-	agentWSHandler       *AgentWSHandler
-	slackManager         *slackutil.Manager
-	runbookService       services.RunbookManager
-	memoryService        services.MemoryManager
-	httpConnectorService services.HTTPConnectorManager
-	mcpServerService     services.MCPServerManager
-	channelService       services.ChannelManager
-	providerRegistry     services.ProviderRegistry
-	cronService          services.CronJobManager
-	proposalService      services.ProposalManager
-	responseFormatter    *services.ResponseFormatter
-	alertChannelReloader func()       // called after alert source create/update/delete to reload Slack channel mappings
-	gatewayReloader      func() error // called after HTTP connector CRUD to reload gateway tools
-	mcpServerReloader    func() error // called after MCP server CRUD to reload gateway MCP proxy tools
+	skillService          services.SkillIncidentManager
+	toolService           services.ToolManager
+	contextService        services.ContextManager
+	alertService          services.AlertManager
+	agentExecutor         *executor.Executor
+	agentWSHandler        *AgentWSHandler
+	slackManager          *slackutil.Manager
+	runbookService        services.RunbookManager
+	memoryService         services.MemoryManager
+	httpConnectorService  services.HTTPConnectorManager
+	mcpServerService      services.MCPServerManager
+	channelService        services.ChannelManager
+	providerRegistry      services.ProviderRegistry
+	cronService           services.CronJobManager
+	proposalService       services.ProposalManager
+	notificationTemplates services.NotificationTemplateManager
+	promptTemplates       services.PromptTemplateManager
+	incidentLinks         services.IncidentLinker
+	snippetExporter       services.SnippetExporter
+	incidentTimeline      services.IncidentTimeline
+	postmortems           services.PostmortemReporter
+	approvals             services.ApprovalManager
+	remediationPlans      services.RemediationPlanManager
+	silences              services.SilenceManager
+	routingRules          services.RoutingRuleManager
+	zabbixProvisioner     services.AlertSourceProvisioner
+	marketplace           services.SkillMarketplace
+	skillBundler          services.SkillBundler
+	skillTester           services.SkillTester
+	auditLog              services.AuditLogReader
+	usage                 services.UsageReader
+	confidenceStats       services.ConfidenceStatsReader
+	alertBaselines        services.AlertBaselineReader
+	retention             services.RetentionRunner
+	incidentExporter      services.IncidentExporter
+	compliance            services.ComplianceReporter
+	budget                services.BudgetGuard
+	llmHealth             services.LLMHealthProber
+	toolSettings          services.ToolSettingsValidator
+	cors                  *middleware.CORSMiddleware
+	corsEnv               middleware.CORSConfig
+	responseFormatter     *services.ResponseFormatter
+	alertChannelReloader  func()       // called after alert source create/update/delete to reload Slack channel mappings
+	gatewayReloader       func() error // called after HTTP connector CRUD to reload gateway tools
+	mcpServerReloader     func() error // called after MCP server CRUD to reload gateway MCP proxy tools
 }
 
 // NewAPIHandler creates a new API handler
@@ -111,28 +140,125 @@ func (h *APIHandler) reloadAlertChannels() {
 	}
 }
 
-// SetupRoutes sets up all API routes
+// SetupRoutes sets up all API routes. Every route names its method, so a
+// known path requested with another method gets 405, and any other /api/
+// path gets a JSON 404 (see handleUnmatchedAPIRoute).
 func (h *APIHandler) SetupRoutes(mux *http.ServeMux) {
+	channels := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireService(func() bool { return h.channelService != nil }, "Channel service is not configured", next)
+	}
+	crons := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireService(func() bool { return h.cronService != nil }, "Cron service is not configured", next)
+	}
+	memories := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireService(func() bool { return h.memoryService != nil }, "memory service not available", next)
+	}
+	notificationTemplates := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireService(func() bool { return h.notificationTemplates != nil }, "Notification templates are not configured", next)
+	}
+	promptTemplates := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireService(func() bool { return h.promptTemplates != nil }, "Prompt templates are not configured", next)
+	}
+
 	// Skills management
-	mux.HandleFunc("/api/skills", h.handleSkills)
-	mux.HandleFunc("/api/skills/", h.handleSkillByName)
-	mux.HandleFunc("/api/skills/sync", h.handleSkillsSync)
+	mux.HandleFunc("GET /api/skills", h.listSkills)
+	mux.HandleFunc("POST /api/skills", h.createSkill)
+	mux.HandleFunc("GET /api/skills/{name}", h.getSkill)
+	mux.HandleFunc("PUT /api/skills/{name}", h.updateSkill)
+	mux.HandleFunc("DELETE /api/skills/{name}", h.deleteSkill)
+	mux.HandleFunc("GET /api/skills/{name}/prompt", h.getSkillPrompt)
+	mux.HandleFunc("PUT /api/skills/{name}/prompt", h.updateSkillPrompt)
+	mux.HandleFunc("GET /api/skills/{name}/tools", h.getSkillTools)
+	mux.HandleFunc("PUT /api/skills/{name}/tools", h.updateSkillTools)
+	mux.HandleFunc("GET /api/skills/{name}/scripts", h.listSkillScripts)
+	mux.HandleFunc("DELETE /api/skills/{name}/scripts", h.clearSkillScripts)
+	mux.HandleFunc("GET /api/skills/{name}/scripts/{filename}", h.getSkillScript)
+	mux.HandleFunc("PUT /api/skills/{name}/scripts/{filename}", h.updateSkillScript)
+	mux.HandleFunc("DELETE /api/skills/{name}/scripts/{filename}", h.deleteSkillScript)
+	mux.HandleFunc("POST /api/skills/sync", h.handleSkillsSync)
+	mux.HandleFunc("POST /api/skills/import", h.handleSkillImport)
+	mux.HandleFunc("GET /api/skills/{name}/export", h.handleSkillExport)
+	mux.HandleFunc("POST /api/skills/{name}/test", h.handleSkillTest)
 
 	// Tool types and instances
-	mux.HandleFunc("/api/tool-types", h.handleToolTypes)
-	mux.HandleFunc("/api/tools", h.handleTools)
-	mux.HandleFunc("/api/tools/", h.handleToolByID)
-
-	// Incidents — exact-method prefix routes resolve before the wildcard catch-all.
-	mux.HandleFunc("/api/incidents", h.handleIncidents)
+	mux.HandleFunc("GET /api/tool-types", h.handleToolTypes)
+	mux.HandleFunc("GET /api/tools", h.listTools)
+	mux.HandleFunc("POST /api/tools", h.createTool)
+	mux.HandleFunc("GET /api/tools/{id}", h.getTool)
+	mux.HandleFunc("PUT /api/tools/{id}", h.updateTool)
+	mux.HandleFunc("DELETE /api/tools/{id}", h.deleteTool)
+	mux.HandleFunc("GET /api/tools/{id}/ssh-keys", h.listSSHKeys)
+	mux.HandleFunc("POST /api/tools/{id}/ssh-keys", h.createSSHKey)
+	mux.HandleFunc("PUT /api/tools/{id}/ssh-keys/{keyID}", h.updateSSHKey)
+	mux.HandleFunc("DELETE /api/tools/{id}/ssh-keys/{keyID}", h.deleteSSHKey)
+	mux.HandleFunc("GET /api/tools/{id}/known-hosts", h.listSSHKnownHosts)
+	mux.HandleFunc("DELETE /api/tools/{id}/known-hosts", h.clearSSHKnownHosts)
+	mux.HandleFunc("DELETE /api/tools/{id}/known-hosts/{hostID}", h.deleteSSHKnownHost)
+
+	// Incidents
+	mux.HandleFunc("GET /api/incidents", h.listIncidents)
+	mux.HandleFunc("POST /api/incidents", h.createIncident)
 	mux.HandleFunc("GET /api/incidents/{uuid}/alerts", h.handleIncidentAlerts)
 	mux.HandleFunc("GET /api/incidents/{uuid}/response", h.handleIncidentResponse)
+	mux.HandleFunc("GET /api/incidents/{uuid}/log", h.handleIncidentLog)
 	mux.HandleFunc("GET /api/incidents/{uuid}", h.handleIncidentByID)
 	mux.HandleFunc("POST /api/incidents/{uuid}/close", h.handleIncidentClose)
+	mux.HandleFunc("POST /api/incidents/{uuid}/cancel", h.handleIncidentCancel)
+	mux.HandleFunc("POST /api/incidents/{uuid}/retry", h.handleIncidentRetry)
+	mux.HandleFunc("POST /api/incidents/{uuid}/message", h.handleIncidentMessage)
+	mux.HandleFunc("GET /api/incidents/{uuid}/conversation", h.handleIncidentConversation)
+	mux.HandleFunc("GET /api/incidents/conversations", h.handleIncidentConversationsExport)
+	mux.HandleFunc("GET /api/incidents/{uuid}/export", h.handleIncidentExport)
+	mux.HandleFunc("GET /api/incidents/export", h.handleIncidentsExport)
+	mux.HandleFunc("GET /api/incidents/{uuid}/links", h.handleIncidentLinks)
+	mux.HandleFunc("POST /api/incidents/{uuid}/links", h.handleIncidentLinks)
+	mux.HandleFunc("DELETE /api/incidents/{uuid}/links/{target}", h.handleIncidentUnlink)
+	mux.HandleFunc("GET /api/incidents/{uuid}/timeline", h.handleIncidentTimeline)
+	mux.HandleFunc("POST /api/incidents/{uuid}/timeline", h.handleIncidentTimeline)
+	mux.HandleFunc("GET /api/incidents/{uuid}/report", h.handleGetIncidentReport)
+	mux.HandleFunc("POST /api/incidents/{uuid}/report", h.handleGenerateIncidentReport)
+	mux.HandleFunc("GET /api/incidents/{uuid}/plan", h.handleGetRemediationPlan)
+	mux.HandleFunc("POST /api/incidents/{uuid}/plan/approve", h.handleApproveRemediationPlan)
+	mux.HandleFunc("POST /api/incidents/{uuid}/plan/reject", h.handleRejectRemediationPlan)
+
+	// Tool approvals
+	mux.HandleFunc("GET /api/approvals", h.handleListApprovals)
+	mux.HandleFunc("POST /api/approvals/{uuid}/approve", h.handleApproveApproval)
+	mux.HandleFunc("POST /api/approvals/{uuid}/reject", h.handleRejectApproval)
+	mux.HandleFunc("GET /api/incidents/{uuid}/snippets", h.handleIncidentSnippets)
+	mux.HandleFunc("POST /api/incidents/{uuid}/snippets", h.handleIncidentSnippets)
+
+	// Maintenance-window silences and the alerts they suppressed
+	mux.HandleFunc("GET /api/silences", h.handleListSilences)
+	mux.HandleFunc("POST /api/silences", h.handleCreateSilence)
+	mux.HandleFunc("GET /api/silences/{uuid}", h.handleGetSilence)
+	mux.HandleFunc("PUT /api/silences/{uuid}", h.handleUpdateSilence)
+	mux.HandleFunc("DELETE /api/silences/{uuid}", h.handleExpireSilence)
+	mux.HandleFunc("GET /api/silences/{uuid}/suppressed", h.handleSuppressedAlerts)
+
+	// Routing rules: per-alert channel, skill, priority and action overrides
+	mux.HandleFunc("GET /api/routing-rules", h.handleListRoutingRules)
+	mux.HandleFunc("POST /api/routing-rules", h.handleCreateRoutingRule)
+	mux.HandleFunc("GET /api/routing-rules/{uuid}", h.handleGetRoutingRule)
+	mux.HandleFunc("PUT /api/routing-rules/{uuid}", h.handleUpdateRoutingRule)
+	mux.HandleFunc("DELETE /api/routing-rules/{uuid}", h.handleDeleteRoutingRule)
+
+	// Audit log: logins and configuration changes
+	mux.HandleFunc("GET /api/audit", h.handleListAuditLog)
+	mux.HandleFunc("GET /api/usage", h.handleUsage)
+	mux.HandleFunc("GET /api/analytics/confidence", h.handleConfidenceCalibration)
+	mux.HandleFunc("GET /api/analytics/alert-baselines", h.handleAlertBaselines)
+	mux.HandleFunc("GET /api/workers", h.handleListWorkers)
+	mux.HandleFunc("GET /api/compliance/actions", h.handleComplianceActions)
+
+	// Skill marketplace
+	mux.HandleFunc("GET /api/marketplace", h.handleMarketplaceIndex)
+	mux.HandleFunc("POST /api/marketplace/skills/{name}/install", h.handleMarketplaceInstall)
 
 	// Alert management: unlink spawns a fresh investigation; move reassigns the
 	// alert to a chosen incident (empty target == unlink); resolve manually
 	// marks a firing alert resolved.
+	mux.HandleFunc("GET /api/alerts/export", h.handleAlertsExport)
 	mux.HandleFunc("POST /api/alerts/{uuid}/unlink", h.handleAlertUnlink)
 	mux.HandleFunc("POST /api/alerts/{uuid}/move", h.handleAlertMove)
 	mux.HandleFunc("POST /api/alerts/{uuid}/resolve", h.handleAlertResolve)
@@ -141,61 +267,123 @@ func (h *APIHandler) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/events", h.handleEvents)
 	mux.HandleFunc("GET /api/events/raw", h.handleEventRaw)
 
+	// Global search across incidents, alerts, skills, context and runbooks
+	mux.HandleFunc("GET /api/search", h.handleSearch)
+
 	// Slack settings (removed; returns 410 Gone — use /api/integrations and
 	// /api/channels). Route kept so clients on the old endpoint see a clear
 	// error instead of a generic 404.
 	mux.HandleFunc("/api/settings/slack", h.handleSlackSettings)
 
 	// Messaging integrations (provider configurations) and Channels
-	mux.HandleFunc("/api/integrations", h.handleIntegrations)
-	mux.HandleFunc("/api/integrations/", h.handleIntegrationByUUID)
-	mux.HandleFunc("/api/channels", h.handleChannels)
-	mux.HandleFunc("/api/channels/", h.handleChannelByUUID)
+	mux.HandleFunc("GET /api/integrations", channels(h.listIntegrations))
+	mux.HandleFunc("POST /api/integrations", channels(h.createIntegration))
+	mux.HandleFunc("GET /api/integrations/{uuid}", channels(h.getIntegration))
+	mux.HandleFunc("PUT /api/integrations/{uuid}", channels(h.updateIntegration))
+	mux.HandleFunc("DELETE /api/integrations/{uuid}", channels(h.deleteIntegration))
+	mux.HandleFunc("GET /api/channels", channels(h.listChannels))
+	mux.HandleFunc("POST /api/channels", channels(h.createChannel))
+	mux.HandleFunc("GET /api/channels/{uuid}", channels(h.getChannel))
+	mux.HandleFunc("PUT /api/channels/{uuid}", channels(h.updateChannel))
+	mux.HandleFunc("DELETE /api/channels/{uuid}", channels(h.deleteChannel))
 
 	// Cron jobs (scheduled LLM or agent runs that post to a Channel)
-	mux.HandleFunc("/api/cron-jobs", h.handleCronJobs)
-	mux.HandleFunc("/api/cron-jobs/", h.handleCronJobByUUID)
+	mux.HandleFunc("GET /api/cron-jobs", crons(h.listCronJobs))
+	mux.HandleFunc("POST /api/cron-jobs", crons(h.createCronJob))
+	mux.HandleFunc("GET /api/cron-jobs/{uuid}", crons(h.getCronJob))
+	mux.HandleFunc("PUT /api/cron-jobs/{uuid}", crons(h.updateCronJob))
+	mux.HandleFunc("DELETE /api/cron-jobs/{uuid}", crons(h.deleteCronJob))
+	mux.HandleFunc("POST /api/cron-jobs/{uuid}/run", crons(h.runCronJob))
 
 	// LLM settings
-	mux.HandleFunc("/api/settings/llm", h.handleLLMSettings)
-	mux.HandleFunc("/api/settings/llm/", h.handleLLMSettingsByID)
+	mux.HandleFunc("GET /api/settings/llm", h.listLLMConfigs)
+	mux.HandleFunc("POST /api/settings/llm", h.createLLMConfig)
+	mux.HandleFunc("GET /api/settings/llm/{id}", h.getLLMConfig)
+	mux.HandleFunc("PUT /api/settings/llm/{id}", h.updateLLMConfig)
+	mux.HandleFunc("DELETE /api/settings/llm/{id}", h.deleteLLMConfig)
+	mux.HandleFunc("PUT /api/settings/llm/{id}/activate", h.activateLLMConfig)
+	mux.HandleFunc("POST /api/settings/llm/{id}/health", h.checkLLMConfigHealth)
+	mux.HandleFunc("GET /api/settings/llm/budget", h.getBudgetSettings)
+	mux.HandleFunc("PUT /api/settings/llm/budget", h.updateBudgetSettings)
 
 	// General settings
-	mux.HandleFunc("/api/settings/general", h.handleGeneralSettings)
+	mux.HandleFunc("GET /api/settings/general", h.getGeneralSettings)
+	mux.HandleFunc("PUT /api/settings/general", h.updateGeneralSettings)
 
 	// Proxy settings
-	mux.HandleFunc("/api/settings/proxy", h.handleProxySettings)
+	mux.HandleFunc("GET /api/settings/proxy", h.GetProxySettings)
+	mux.HandleFunc("PUT /api/settings/proxy", h.UpdateProxySettings)
 
 	// Retention settings
-	mux.HandleFunc("/api/settings/retention", h.handleRetentionSettings)
+	mux.HandleFunc("GET /api/settings/retention", h.getRetentionSettings)
+	mux.HandleFunc("PUT /api/settings/retention", h.updateRetentionSettings)
+	mux.HandleFunc("GET /api/settings/retention/usage", h.handleRetentionUsage)
+	mux.HandleFunc("POST /api/settings/retention/cleanup", h.handleRetentionCleanup)
+
+	// Cross-origin policy
+	mux.HandleFunc("GET /api/settings/cors", h.getCORSSettings)
+	mux.HandleFunc("PUT /api/settings/cors", h.updateCORSSettings)
+
+	// Severity emoji and status names/colors
+	mux.HandleFunc("GET /api/settings/vocabulary", h.getVocabularySettings)
+	mux.HandleFunc("PUT /api/settings/vocabulary", h.updateVocabularySettings)
+
+	// Model price table for investigation cost estimates
+	mux.HandleFunc("GET /api/settings/model-prices", h.getModelPrices)
+	mux.HandleFunc("PUT /api/settings/model-prices", h.updateModelPrices)
+
+	// Investigation prompt templates per alert source type and skill
+	mux.HandleFunc("GET /api/settings/prompts", promptTemplates(h.listPromptTemplates))
+	mux.HandleFunc("POST /api/settings/prompts", promptTemplates(h.createPromptTemplate))
+	mux.HandleFunc("POST /api/settings/prompts/preview", h.handlePromptTemplatePreview)
+	mux.HandleFunc("PUT /api/settings/prompts/{id}", promptTemplates(h.updatePromptTemplate))
+	mux.HandleFunc("DELETE /api/settings/prompts/{id}", promptTemplates(h.deletePromptTemplate))
 
 	// Formatting settings (removed; returns 410 Gone — use /api/formatting-rules)
 	mux.HandleFunc("/api/settings/formatting", h.handleFormattingSettings)
 
 	// Per-flow formatting rules
-	mux.HandleFunc("/api/formatting-rules", h.handleFormattingRules)
+	mux.HandleFunc("GET /api/formatting-rules", h.listFormattingRules)
+	mux.HandleFunc("POST /api/formatting-rules", h.createFormattingRule)
 	mux.HandleFunc("PUT /api/formatting-rules/reorder", h.handleFormattingRulesReorder)
-	mux.HandleFunc("PUT /api/formatting-rules/{uuid}", h.handleFormattingRuleByUUID)
-	mux.HandleFunc("DELETE /api/formatting-rules/{uuid}", h.handleFormattingRuleByUUID)
+	mux.HandleFunc("PUT /api/formatting-rules/{uuid}", h.updateFormattingRule)
+	mux.HandleFunc("DELETE /api/formatting-rules/{uuid}", h.deleteFormattingRule)
+
+	// Notification templates (operator overrides of outbound message text)
+	mux.HandleFunc("GET /api/notification-templates", notificationTemplates(h.listNotificationTemplates))
+	mux.HandleFunc("POST /api/notification-templates", notificationTemplates(h.createNotificationTemplate))
+	mux.HandleFunc("POST /api/notification-templates/preview", h.handleNotificationTemplatePreview)
+	mux.HandleFunc("PUT /api/notification-templates/{id}", notificationTemplates(h.updateNotificationTemplate))
+	mux.HandleFunc("DELETE /api/notification-templates/{id}", notificationTemplates(h.deleteNotificationTemplate))
+	mux.HandleFunc("GET /api/template-variables", h.handleTemplateVariables)
 
 	// Context files
-	mux.HandleFunc("/api/context", h.handleContext)
-	mux.HandleFunc("/api/context/", h.handleContextByID)
-	mux.HandleFunc("/api/context/validate", h.handleContextValidate)
+	mux.HandleFunc("GET /api/context", h.listContextFiles)
+	mux.HandleFunc("POST /api/context", h.uploadContextFile)
+	mux.HandleFunc("GET /api/context/{id}", h.getContextFile)
+	mux.HandleFunc("DELETE /api/context/{id}", h.deleteContextFile)
+	mux.HandleFunc("GET /api/context/{id}/download", h.handleContextDownload)
+	mux.HandleFunc("POST /api/context/validate", h.handleContextValidate)
 
 	// Runbooks
-	mux.HandleFunc("/api/runbooks", h.handleRunbooks)
-	mux.HandleFunc("/api/runbooks/", h.handleRunbookByID)
+	mux.HandleFunc("GET /api/runbooks", h.listRunbooks)
+	mux.HandleFunc("POST /api/runbooks", h.createRunbook)
+	mux.HandleFunc("GET /api/runbooks/{id}", h.getRunbook)
+	mux.HandleFunc("PUT /api/runbooks/{id}", h.updateRunbook)
+	mux.HandleFunc("DELETE /api/runbooks/{id}", h.deleteRunbook)
 
 	// Cross-incident memory
-	mux.HandleFunc("/api/memories", h.handleMemories)
-	mux.HandleFunc("/api/memories/scopes", h.handleMemoryScopes)
-	mux.HandleFunc("/api/memories/", h.handleMemoryByID)
+	mux.HandleFunc("GET /api/memories", memories(h.listMemories))
+	mux.HandleFunc("POST /api/memories", memories(h.createMemory))
+	mux.HandleFunc("GET /api/memories/scopes", memories(h.handleMemoryScopes))
+	mux.HandleFunc("GET /api/memories/{id}", memories(h.getMemory))
+	mux.HandleFunc("PUT /api/memories/{id}", memories(h.updateMemory))
+	mux.HandleFunc("DELETE /api/memories/{id}", memories(h.deleteMemory))
 	mux.HandleFunc("POST /api/incidents/{uuid}/feedback", h.handleIncidentFeedback)
 
 	// Self-improvement proposals (generated by the improvement-evaluator cron,
 	// reviewed/refined/approved by operators)
-	mux.HandleFunc("/api/proposals", h.handleProposals)
+	mux.HandleFunc("GET /api/proposals", h.handleProposals)
 	mux.HandleFunc("GET /api/proposals/count", h.handleProposalsCount)
 	mux.HandleFunc("GET /api/proposals/{uuid}", h.handleProposalByUUID)
 	mux.HandleFunc("POST /api/proposals/{uuid}/approve", h.handleProposalApprove)
@@ -204,43 +392,86 @@ func (h *APIHandler) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/proposals/{uuid}/chat", h.handleProposalChatPost)
 
 	// HTTP connectors
-	mux.HandleFunc("/api/http-connectors", h.handleHTTPConnectors)
-	mux.HandleFunc("/api/http-connectors/", h.handleHTTPConnectorByID)
+	mux.HandleFunc("GET /api/http-connectors", h.listHTTPConnectors)
+	mux.HandleFunc("POST /api/http-connectors", h.createHTTPConnector)
+	mux.HandleFunc("GET /api/http-connectors/{id}", h.getHTTPConnector)
+	mux.HandleFunc("PUT /api/http-connectors/{id}", h.updateHTTPConnector)
+	mux.HandleFunc("DELETE /api/http-connectors/{id}", h.deleteHTTPConnector)
 
 	// MCP servers (admin-only)
-	mux.HandleFunc("/api/mcp-servers", h.handleMCPServers)
-	mux.HandleFunc("/api/mcp-servers/", h.handleMCPServerByID)
+	mux.HandleFunc("GET /api/mcp-servers", h.listMCPServers)
+	mux.HandleFunc("POST /api/mcp-servers", h.createMCPServer)
+	mux.HandleFunc("GET /api/mcp-servers/{id}", h.getMCPServer)
+	mux.HandleFunc("PUT /api/mcp-servers/{id}", h.updateMCPServer)
+	mux.HandleFunc("DELETE /api/mcp-servers/{id}", h.deleteMCPServer)
 
 	// Alert source types and instances
-	mux.HandleFunc("/api/alert-source-types", h.handleAlertSourceTypes)
-	mux.HandleFunc("/api/alert-sources", h.handleAlertSources)
-	mux.HandleFunc("/api/alert-sources/", h.handleAlertSourceByUUID)
+	mux.HandleFunc("GET /api/alert-source-types", h.handleAlertSourceTypes)
+	mux.HandleFunc("GET /api/alert-sources", h.listAlertSources)
+	mux.HandleFunc("POST /api/alert-sources", h.createAlertSource)
+	mux.HandleFunc("GET /api/alert-sources/{uuid}", h.getAlertSource)
+	mux.HandleFunc("PUT /api/alert-sources/{uuid}", h.updateAlertSource)
+	mux.HandleFunc("DELETE /api/alert-sources/{uuid}", h.deleteAlertSource)
+	mux.HandleFunc("GET /api/alert-sources/{uuid}/config-snippet", h.handleAlertSourceConfigSnippet)
+	mux.HandleFunc("POST /api/alert-sources/{uuid}/provision/zabbix", h.handleProvisionZabbix)
 
 	// API documentation (public, no auth required)
 	mux.HandleFunc("GET /api/docs", h.handleDocs)
 	mux.HandleFunc("GET /api/openapi.yaml", h.handleOpenAPISpec)
-}
+	mux.HandleFunc("GET /api/openapi.json", h.handleOpenAPIJSON)
 
-// ========== Utility Functions ==========
+	mux.HandleFunc("/api/", handleUnmatchedAPIRoute(mux))
+}
 
-// splitPath splits a URL path by slashes
-func splitPath(path string) []string {
-	result := []string{}
-	current := ""
-	for _, char := range path {
-		if char == '/' {
-			if current != "" {
-				result = append(result, current)
-				current = ""
+// apiRouteMethods are the methods API routes are registered with.
+var apiRouteMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// handleUnmatchedAPIRoute answers /api/ requests no route matches, as JSON
+// like every other API error: 405 with an Allow header when the path is
+// routed for other methods, 404 otherwise.
+func handleUnmatchedAPIRoute(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range apiRouteMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/api/" {
+				allowed = append(allowed, method)
 			}
-		} else {
-			current += string(char)
 		}
+		if len(allowed) == 0 {
+			api.RespondError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// requireService wraps next to answer 503 with msg while available reports
+// the service behind the route as unset. Setters may run after SetupRoutes,
+// so the check is made per request.
+func requireService(available func() bool, msg string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !available() {
+			api.RespondError(w, http.StatusServiceUnavailable, msg)
+			return
+		}
+		next(w, r)
 	}
-	if current != "" {
-		result = append(result, current)
+}
+
+// ========== Utility Functions ==========
+
+// pathID parses the path parameter name as a numeric ID. On failure it
+// answers 400 "Invalid <what> ID" and returns false.
+func pathID(w http.ResponseWriter, r *http.Request, name, what string) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue(name), 10, 32)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid "+what+" ID")
+		return 0, false
 	}
-	return result
+	return uint(id), true
 }
 
 // containsString checks if a string contains a substring (helper for error matching)
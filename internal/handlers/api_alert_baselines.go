@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetAlertBaselines wires weekly alert volume baselines behind GET
+// /api/analytics/alert-baselines. Optional — when unset the endpoint returns
+// 503.
+func (h *APIHandler) SetAlertBaselines(r services.AlertBaselineReader) {
+	h.alertBaselines = r
+}
+
+// handleAlertBaselines handles GET /api/analytics/alert-baselines: each
+// alert name's weekly baseline and last-seven-days volume, most deviating
+// first. ?deviating=true lists only names firing far more than usual.
+func (h *APIHandler) handleAlertBaselines(w http.ResponseWriter, r *http.Request) {
+	if h.alertBaselines == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Alert baselines are not configured")
+		return
+	}
+	deviating, err := parseBoolQuery(r, "deviating")
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	baselines, err := h.alertBaselines.ListBaselines(r.Context(), deviating)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "analytics: failed to list alert baselines", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list alert baselines")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, baselines)
+}
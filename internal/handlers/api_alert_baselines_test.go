@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAlertBaselinesAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.AlertBaseline{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/analytics/alert-baselines", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	h.SetAlertBaselines(services.NewAlertBaselineService(db))
+	now := time.Now()
+	db.Create(&database.AlertBaseline{AlertName: "HighCPU", WeeklyAverage: 5, BaselineWeeks: 4, CurrentWeek: 5, Ratio: 1, ComputedAt: now})
+	db.Create(&database.AlertBaseline{AlertName: "DiskFull", WeeklyAverage: 3, BaselineWeeks: 4, CurrentWeek: 12, Ratio: 4, Deviating: true, ComputedAt: now})
+
+	for query, want := range map[string]string{
+		"":                "DiskFull,HighCPU",
+		"?deviating=true": "DiskFull",
+	} {
+		rec := serveJSON(mux, http.MethodGet, "/api/analytics/alert-baselines"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var baselines []database.AlertBaseline
+		if err := json.Unmarshal(rec.Body.Bytes(), &baselines); err != nil {
+			t.Fatal(err)
+		}
+		var names string
+		for i, b := range baselines {
+			if i > 0 {
+				names += ","
+			}
+			names += b.AlertName
+		}
+		if names != want {
+			t.Errorf("%q: baselines = %s, want %s", query, names, want)
+		}
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/analytics/alert-baselines?deviating=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad deviating status = %d, want 400", rec.Code)
+	}
+}
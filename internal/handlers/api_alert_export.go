@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// alertExportColumns selects services.AlertExportRow from alerts joined with
+// their incidents.
+const alertExportColumns = `alerts.uuid, alerts.incident_uuid, incidents.title AS incident_title,
+	incidents.source, alerts.source_uuid, LOWER(incidents.context->>'severity') AS severity, alerts.status,
+	alerts.alert_name, alerts.target_host, alerts.fingerprint, alerts.fired_at, alerts.resolved_at,
+	alerts.correlated, alerts.correlation_decision, alerts.correlation_confidence`
+
+// handleAlertsExport handles GET /api/alerts/export. It streams every alert
+// row, oldest first, with its incident's title, source and severity, for
+// capacity planning and BI tools. ?format=csv (default) or ndjson.
+// Filters: from and to (fired_at, RFC3339 or unix seconds), and
+// comma-separated source, source_uuid, severity, status and incident.
+func (h *APIHandler) handleAlertsExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		api.RespondError(w, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+	for _, name := range []string{"from", "to"} {
+		if v := q.Get(name); v != "" && parseTimeQueryParam(v) == nil {
+			api.RespondError(w, http.StatusBadRequest, name+" must be an RFC3339 timestamp or unix seconds")
+			return
+		}
+	}
+	for _, status := range splitCSV(q.Get("status")) {
+		if status != string(database.AlertStatusFiring) && status != string(database.AlertStatusResolved) {
+			api.RespondError(w, http.StatusBadRequest, "status must be firing or resolved")
+			return
+		}
+	}
+
+	db := database.GetDB()
+	rows, err := applyAlertExportFilters(db.Table("alerts").Select(alertExportColumns).
+		Joins("LEFT JOIN incidents ON incidents.uuid = alerts.incident_uuid"), r).
+		Order("alerts.fired_at ASC, alerts.uuid ASC").Rows()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "alert export failed", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to export alerts")
+		return
+	}
+	defer rows.Close()
+
+	var cw *csv.Writer
+	var enc *json.Encoder
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="alerts-export.csv"`)
+		cw = csv.NewWriter(w)
+		err = cw.Write(services.AlertExportCSVHeader)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="alerts-export.ndjson"`)
+		enc = json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+	}
+
+	exported := 0
+	for err == nil && rows.Next() {
+		var row services.AlertExportRow
+		if err = db.ScanRows(rows, &row); err != nil {
+			break
+		}
+		if cw != nil {
+			err = cw.Write(row.CSVRecord())
+		} else {
+			err = enc.Encode(row)
+		}
+		exported++
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if cw != nil {
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+	}
+	if err != nil {
+		// The file is already streaming, so a failure can only truncate it.
+		slog.ErrorContext(r.Context(), "alert export failed", "exported", exported, "err", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "exported alerts", "count", exported, "format", format)
+}
+
+// applyAlertExportFilters applies the GET /api/alerts/export query filters
+// to a query over alerts joined with incidents.
+func applyAlertExportFilters(query *gorm.DB, r *http.Request) *gorm.DB {
+	q := r.URL.Query()
+	if from := parseTimeQueryParam(q.Get("from")); from != nil {
+		query = query.Where("alerts.fired_at >= ?", *from)
+	}
+	if to := parseTimeQueryParam(q.Get("to")); to != nil {
+		query = query.Where("alerts.fired_at <= ?", *to)
+	}
+	if sources := splitCSV(q.Get("source")); len(sources) > 0 {
+		query = query.Where("incidents.source IN ?", sources)
+	}
+	if sourceUUIDs := splitCSV(q.Get("source_uuid")); len(sourceUUIDs) > 0 {
+		query = query.Where("alerts.source_uuid IN ?", sourceUUIDs)
+	}
+	if severities := splitCSV(strings.ToLower(q.Get("severity"))); len(severities) > 0 {
+		query = query.Where("LOWER(incidents.context->>'severity') IN ?", severities)
+	}
+	if statuses := splitCSV(q.Get("status")); len(statuses) > 0 {
+		query = query.Where("alerts.status IN ?", statuses)
+	}
+	if incidents := splitCSV(q.Get("incident")); len(incidents) > 0 {
+		query = query.Where("alerts.incident_uuid IN ?", incidents)
+	}
+	return query
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAlertsExport(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	critical := seedFilterIncident(t, database.Incident{Source: "alertmanager", Title: "Disk full",
+		Context: database.JSONB{"severity": "Critical"}})
+	warning := seedFilterIncident(t, database.Incident{Source: "zabbix", Context: database.JSONB{"severity": "warning"}})
+	now := time.Now().UTC().Truncate(time.Second)
+	resolved := now.Add(-time.Hour)
+	for _, a := range []database.Alert{
+		{UUID: "a-old", IncidentUUID: critical, AlertName: "DiskFull", TargetHost: "db-01", Status: database.AlertStatusResolved,
+			FiredAt: now.Add(-48 * time.Hour), ResolvedAt: &resolved},
+		{UUID: "a-new", IncidentUUID: critical, AlertName: "DiskFull", TargetHost: "db-02", Status: database.AlertStatusFiring, FiredAt: now},
+		{UUID: "b", IncidentUUID: warning, AlertName: "HighCPU", Status: database.AlertStatusFiring, FiredAt: now.Add(-time.Minute)},
+	} {
+		if err := db.Create(&a).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := serveJSON(mux, http.MethodGet, "/api/alerts/export", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("csv status = %d type = %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || strings.Join(records[0], ",") != strings.Join(services.AlertExportCSVHeader, ",") {
+		t.Fatalf("csv = %q", records)
+	}
+	first := records[1]
+	if first[0] != "a-old" || first[2] != "Disk full" || first[3] != "alertmanager" || first[5] != "critical" ||
+		first[12] != strconv.Itoa(47*3600) {
+		t.Errorf("first row = %q", first)
+	}
+	if records[2][0] != "b" || records[3][0] != "a-new" {
+		t.Errorf("rows not ordered by fired_at: %q", records)
+	}
+
+	exported := func(query string) []string {
+		t.Helper()
+		rec := serveJSON(mux, http.MethodGet, "/api/alerts/export?format=ndjson&"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var uuids []string
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var row services.AlertExportRow
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				t.Fatal(err)
+			}
+			uuids = append(uuids, row.UUID)
+		}
+		return uuids
+	}
+	for query, want := range map[string]string{
+		"severity=critical":   "a-old,a-new",
+		"source=zabbix":       "b",
+		"status=firing":       "b,a-new",
+		"incident=" + warning: "b",
+		"from=" + strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10): "b,a-new",
+		"to=" + now.Add(-2*time.Hour).Format(time.RFC3339):            "a-old",
+	} {
+		if got := strings.Join(exported(query), ","); got != want {
+			t.Errorf("%s: exported %q, want %q", query, got, want)
+		}
+	}
+
+	for _, query := range []string{"format=xml", "from=yesterday", "status=open"} {
+		if rec := serveJSON(mux, http.MethodGet, "/api/alerts/export?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetZabbixProvisioner wires the service backing
+// /api/alert-sources/{uuid}/provision/zabbix. Optional — when unset the
+// endpoint returns 503.
+func (h *APIHandler) SetZabbixProvisioner(svc services.AlertSourceProvisioner) {
+	h.zabbixProvisioner = svc
+}
+
+// handleProvisionZabbix handles POST /api/alert-sources/{uuid}/provision/zabbix.
+// It creates the webhook media type and trigger action on the Zabbix server
+// so operators do not have to copy the webhook URL and secret by hand.
+func (h *APIHandler) handleProvisionZabbix(w http.ResponseWriter, r *http.Request) {
+	if h.zabbixProvisioner == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Zabbix provisioning is not configured")
+		return
+	}
+	sourceUUID := r.PathValue("uuid")
+
+	var req api.ProvisionZabbixRequest
+	if r.ContentLength != 0 {
+		if err := api.DecodeJSON(r, &req); err != nil {
+			api.RespondDecodeError(w, err)
+			return
+		}
+	}
+	minSeverity := services.ZabbixDefaultMinSeverity
+	if req.MinSeverity != nil {
+		minSeverity = *req.MinSeverity
+	}
+
+	result, err := h.zabbixProvisioner.ProvisionAlertSource(r.Context(), sourceUUID, services.ZabbixProvisionRequest{
+		ToolInstanceID: req.ToolInstanceID,
+		AkmatoriURL:    req.AkmatoriURL,
+		Username:       req.ZabbixUser,
+		MinSeverity:    minSeverity,
+	})
+	switch {
+	case err == nil:
+		slog.InfoContext(r.Context(), "zabbix provisioned for alert source", "uuid", sourceUUID,
+			"media_type", result.MediaTypeID, "action", result.ActionID, "created", result.ActionCreated)
+		api.RespondJSON(w, http.StatusOK, result)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Alert source not found")
+	case errors.Is(err, services.ErrInvalidZabbixProvision):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrZabbixAPI):
+		slog.WarnContext(r.Context(), "zabbix provisioning failed", "uuid", sourceUUID, "err", err)
+		api.RespondError(w, http.StatusBadGateway, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "zabbix provisioning failed", "uuid", sourceUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to provision Zabbix")
+	}
+}
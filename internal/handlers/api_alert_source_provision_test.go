@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type stubZabbixProvisioner struct {
+	err      error
+	requests []services.ZabbixProvisionRequest
+}
+
+func (s *stubZabbixProvisioner) ProvisionAlertSource(_ context.Context, sourceUUID string, req services.ZabbixProvisionRequest) (*services.ZabbixProvisionResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.requests = append(s.requests, req)
+	return &services.ZabbixProvisionResult{WebhookURL: "https://akmatori.example.com/webhook/alert/" + sourceUUID}, nil
+}
+
+func TestProvisionZabbixAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/alert-sources/src-1/provision/zabbix", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+
+	stub := &stubZabbixProvisioner{}
+	h.SetZabbixProvisioner(stub)
+
+	// An empty body provisions with the defaults.
+	if rec := serveJSON(mux, http.MethodPost, "/api/alert-sources/src-1/provision/zabbix", ""); rec.Code != http.StatusOK {
+		t.Fatalf("default status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := serveJSON(mux, http.MethodPost, "/api/alert-sources/src-1/provision/zabbix",
+		`{"tool_instance_id":3,"zabbix_user":"ops","min_severity":0}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(stub.requests) != 2 {
+		t.Fatalf("requests = %+v", stub.requests)
+	}
+	if stub.requests[0].MinSeverity != services.ZabbixDefaultMinSeverity {
+		t.Errorf("default min severity = %d", stub.requests[0].MinSeverity)
+	}
+	if got := stub.requests[1]; got.ToolInstanceID != 3 || got.Username != "ops" || got.MinSeverity != 0 {
+		t.Errorf("request = %+v", got)
+	}
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/alert-sources/src-1/provision/zabbix", `{"unknown":1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown field status = %d", rec.Code)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound},
+		{fmt.Errorf("%w: not zabbix", services.ErrInvalidZabbixProvision), http.StatusBadRequest},
+		{fmt.Errorf("%w: action.create: denied", services.ErrZabbixAPI), http.StatusBadGateway},
+		{fmt.Errorf("disk on fire"), http.StatusInternalServerError},
+	} {
+		stub.err = tc.err
+		if rec := serveJSON(mux, http.MethodPost, "/api/alert-sources/src-1/provision/zabbix", "{}"); rec.Code != tc.want {
+			t.Errorf("err %v: status = %d, want %d", tc.err, rec.Code, tc.want)
+		}
+	}
+}
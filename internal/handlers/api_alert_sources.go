@@ -7,10 +7,11 @@ import (
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
 )
 
 // alertChannelErr carries an HTTP status + user-facing message produced when
-// resolving a notification_channel_uuid; keeping the pair together avoids
+// resolving a notification_channel_uuid or skill_names; keeping the pair together avoids
 // scattering status decisions across multiple call sites.
 type alertChannelErr struct {
 	status int
@@ -39,6 +40,35 @@ func (h *APIHandler) resolveNotificationChannel(uuidStr string) (*uint, *alertCh
 	return &id, nil
 }
 
+// normalizeSourceSkills trims and de-duplicates the skill names an alert
+// source is pinned to and checks each one is an existing non-system skill, so
+// bad input is rejected before anything is written.
+func (h *APIHandler) normalizeSourceSkills(names []string) ([]string, *alertChannelErr) {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+	if h.skillService == nil {
+		return nil, &alertChannelErr{status: http.StatusServiceUnavailable, msg: "Skill service is not configured"}
+	}
+	for _, name := range out {
+		skill, err := h.skillService.GetSkill(name)
+		if err != nil || skill == nil || skill.IsSystem {
+			return nil, &alertChannelErr{status: http.StatusBadRequest, msg: "skill_names: unknown skill '" + name + "'"}
+		}
+	}
+	return out, nil
+}
+
 // isDuplicateNameErr reports whether err is a database unique-constraint
 // violation on the alert source name. Both Postgres (GORM) and SQLite
 // (used by tests) surface this via distinctive substrings; we match on the
@@ -57,11 +87,6 @@ func isDuplicateNameErr(err error) bool {
 
 // handleAlertSourceTypes handles GET /api/alert-source-types
 func (h *APIHandler) handleAlertSourceTypes(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	sourceTypes, err := h.alertService.ListSourceTypes()
 	if err != nil {
 		api.RespondError(w, http.StatusInternalServerError, "Failed to list source types")
@@ -71,194 +96,264 @@ func (h *APIHandler) handleAlertSourceTypes(w http.ResponseWriter, r *http.Reque
 	api.RespondJSON(w, http.StatusOK, sourceTypes)
 }
 
-// handleAlertSources handles GET /api/alert-sources and POST /api/alert-sources
-func (h *APIHandler) handleAlertSources(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		instances, err := h.alertService.ListInstances()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list alert sources")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, instances)
+// listAlertSources handles GET /api/alert-sources
+func (h *APIHandler) listAlertSources(w http.ResponseWriter, r *http.Request) {
+	instances, err := h.alertService.ListInstances()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list alert sources")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, instances)
+}
 
-	case http.MethodPost:
-		var req api.CreateAlertSourceRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+// createAlertSource handles POST /api/alert-sources
+func (h *APIHandler) createAlertSource(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateAlertSourceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	req.SourceTypeName = strings.TrimSpace(req.SourceTypeName)
+	req.Name = strings.TrimSpace(req.Name)
 
-		req.SourceTypeName = strings.TrimSpace(req.SourceTypeName)
-		req.Name = strings.TrimSpace(req.Name)
+	if req.SourceTypeName == "" || req.Name == "" {
+		api.RespondError(w, http.StatusBadRequest, "source_type_name and name are required")
+		return
+	}
+
+	// Reject creation against deprecated source types. slack_channel is
+	// deprecated as of Task 6 of the unified-channels plan — operators
+	// should configure listener channels under /api/channels instead.
+	// Missing rows (sterr or sourceType==nil) fall through to the
+	// AlertService.CreateInstance call, which surfaces the error in its
+	// own response shape; we only intercept on a definite deprecated row.
+	if sourceType, sterr := h.alertService.GetAlertSourceTypeByName(req.SourceTypeName); sterr == nil && sourceType != nil && sourceType.Deprecated {
+		api.RespondError(w, http.StatusBadRequest, "alert source type '"+req.SourceTypeName+"' is deprecated; configure a Channel under /api/channels instead")
+		return
+	}
 
-		if req.SourceTypeName == "" || req.Name == "" {
-			api.RespondError(w, http.StatusBadRequest, "source_type_name and name are required")
+	// Validate slack_channel settings before creating the instance so the
+	// caller gets a 400 rather than a 201 with a missing channel ID.
+	if req.SourceTypeName == "slack_channel" {
+		channelID, _ := req.Settings["slack_channel_id"].(string)
+		if strings.TrimSpace(channelID) == "" {
+			api.RespondError(w, http.StatusBadRequest, "slack_channel_id is required in settings for slack_channel source type")
 			return
 		}
+	}
+
+	if err := services.ValidateAlertSourceSettings(req.Settings); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-		// Reject creation against deprecated source types. slack_channel is
-		// deprecated as of Task 6 of the unified-channels plan — operators
-		// should configure listener channels under /api/channels instead.
-		// Missing rows (sterr or sourceType==nil) fall through to the
-		// AlertService.CreateInstance call, which surfaces the error in its
-		// own response shape; we only intercept on a definite deprecated row.
-		if sourceType, sterr := h.alertService.GetAlertSourceTypeByName(req.SourceTypeName); sterr == nil && sourceType != nil && sourceType.Deprecated {
-			api.RespondError(w, http.StatusBadRequest, "alert source type '"+req.SourceTypeName+"' is deprecated; configure a Channel under /api/channels instead")
+	// Resolve optional notification_channel_uuid up-front so we can
+	// reject unknown channel UUIDs without creating the alert source.
+	var notifChannelID *uint
+	if req.NotificationChannelUUID != nil && strings.TrimSpace(*req.NotificationChannelUUID) != "" {
+		id, herr := h.resolveNotificationChannel(*req.NotificationChannelUUID)
+		if herr != nil {
+			api.RespondError(w, herr.status, herr.msg)
 			return
 		}
+		notifChannelID = id
+	}
 
-		// Validate slack_channel settings before creating the instance so the
-		// caller gets a 400 rather than a 201 with a missing channel ID.
-		if req.SourceTypeName == "slack_channel" {
-			channelID, _ := req.Settings["slack_channel_id"].(string)
-			if strings.TrimSpace(channelID) == "" {
-				api.RespondError(w, http.StatusBadRequest, "slack_channel_id is required in settings for slack_channel source type")
-				return
-			}
-		}
+	skillNames, herr := h.normalizeSourceSkills(req.SkillNames)
+	if herr != nil {
+		api.RespondError(w, herr.status, herr.msg)
+		return
+	}
 
-		// Resolve optional notification_channel_uuid up-front so we can
-		// reject unknown channel UUIDs without creating the alert source.
-		var notifChannelID *uint
-		if req.NotificationChannelUUID != nil && strings.TrimSpace(*req.NotificationChannelUUID) != "" {
-			id, herr := h.resolveNotificationChannel(*req.NotificationChannelUUID)
-			if herr != nil {
-				api.RespondError(w, herr.status, herr.msg)
-				return
-			}
-			notifChannelID = id
+	instance, err := h.alertService.CreateInstance(req.SourceTypeName, req.Name, req.Description, req.WebhookSecret, req.FieldMappings, req.Settings)
+	if err != nil {
+		if isDuplicateNameErr(err) {
+			api.RespondError(w, http.StatusConflict, "An alert source with that name already exists")
+			return
 		}
+		api.RespondError(w, http.StatusInternalServerError, "Failed to create alert source")
+		return
+	}
 
-		instance, err := h.alertService.CreateInstance(req.SourceTypeName, req.Name, req.Description, req.WebhookSecret, req.FieldMappings, req.Settings)
-		if err != nil {
-			if isDuplicateNameErr(err) {
-				api.RespondError(w, http.StatusConflict, "An alert source with that name already exists")
-				return
-			}
-			api.RespondError(w, http.StatusInternalServerError, "Failed to create alert source")
+	if notifChannelID != nil {
+		if err := h.alertService.UpdateInstance(instance.UUID, map[string]interface{}{
+			"notification_channel_id": *notifChannelID,
+		}); err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to set notification channel")
 			return
 		}
-
-		if notifChannelID != nil {
-			if err := h.alertService.UpdateInstance(instance.UUID, map[string]interface{}{
-				"notification_channel_id": *notifChannelID,
-			}); err != nil {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to set notification channel")
-				return
-			}
-			if refreshed, gerr := h.alertService.GetInstanceByUUID(instance.UUID); gerr == nil {
-				instance = refreshed
-			}
+	}
+	if len(skillNames) > 0 {
+		if err := h.alertService.SetInstanceSkills(instance.UUID, skillNames); err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to set alert source skills")
+			return
+		}
+	}
+	if notifChannelID != nil || len(skillNames) > 0 {
+		if refreshed, gerr := h.alertService.GetInstanceByUUID(instance.UUID); gerr == nil {
+			instance = refreshed
 		}
+	}
 
-		api.RespondJSON(w, http.StatusCreated, instance)
-		h.reloadAlertChannels()
+	api.RespondJSON(w, http.StatusCreated, instance)
+	h.reloadAlertChannels()
+}
+
+// getAlertSource handles GET /api/alert-sources/{uuid}
+func (h *APIHandler) getAlertSource(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	instance, err := h.alertService.GetInstanceByUUID(uuid)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "Alert source not found")
+		return
 	}
+	api.RespondJSON(w, http.StatusOK, instance)
 }
 
-// handleAlertSourceByUUID handles GET/PUT/DELETE /api/alert-sources/{uuid}
-func (h *APIHandler) handleAlertSourceByUUID(w http.ResponseWriter, r *http.Request) {
-	uuid := r.URL.Path[len("/api/alert-sources/"):]
-	if uuid == "" {
-		api.RespondError(w, http.StatusBadRequest, "UUID is required")
+// updateAlertSource handles PUT /api/alert-sources/{uuid}
+func (h *APIHandler) updateAlertSource(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	var req api.UpdateAlertSourceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		instance, err := h.alertService.GetInstanceByUUID(uuid)
-		if err != nil {
-			api.RespondError(w, http.StatusNotFound, "Alert source not found")
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		trimmed := strings.TrimSpace(*req.Name)
+		if trimmed == "" {
+			api.RespondError(w, http.StatusBadRequest, "name cannot be empty")
 			return
 		}
-		api.RespondJSON(w, http.StatusOK, instance)
+		updates["name"] = trimmed
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.WebhookSecret != nil {
+		updates["webhook_secret"] = *req.WebhookSecret
+	}
+	if req.FieldMappings != nil {
+		updates["field_mappings"] = *req.FieldMappings
+	}
+	if req.Settings != nil {
+		updates["settings"] = *req.Settings
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
 
-	case http.MethodPut:
-		var req api.UpdateAlertSourceRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
+	if req.Settings != nil {
+		if err := services.ValidateAlertSourceSettings(*req.Settings); err != nil {
 			api.RespondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-
-		updates := make(map[string]interface{})
-		if req.Name != nil {
-			trimmed := strings.TrimSpace(*req.Name)
-			if trimmed == "" {
-				api.RespondError(w, http.StatusBadRequest, "name cannot be empty")
+		existing, err := h.alertService.GetInstanceByUUID(uuid)
+		if err == nil && existing.AlertSourceType.Name == "slack_channel" {
+			channelID, _ := (*req.Settings)["slack_channel_id"].(string)
+			if strings.TrimSpace(channelID) == "" {
+				api.RespondError(w, http.StatusBadRequest, "slack_channel_id is required in settings for slack_channel source type")
 				return
 			}
-			updates["name"] = trimmed
-		}
-		if req.Description != nil {
-			updates["description"] = *req.Description
-		}
-		if req.WebhookSecret != nil {
-			updates["webhook_secret"] = *req.WebhookSecret
-		}
-		if req.FieldMappings != nil {
-			updates["field_mappings"] = *req.FieldMappings
-		}
-		if req.Settings != nil {
-			updates["settings"] = *req.Settings
-		}
-		if req.Enabled != nil {
-			updates["enabled"] = *req.Enabled
 		}
+	}
 
-		if req.Settings != nil {
-			existing, err := h.alertService.GetInstanceByUUID(uuid)
-			if err == nil && existing.AlertSourceType.Name == "slack_channel" {
-				channelID, _ := (*req.Settings)["slack_channel_id"].(string)
-				if strings.TrimSpace(channelID) == "" {
-					api.RespondError(w, http.StatusBadRequest, "slack_channel_id is required in settings for slack_channel source type")
-					return
-				}
+	// notification_channel_uuid is tri-state: omitted (nil pointer) leaves
+	// the existing FK untouched; explicit empty string clears it; a valid
+	// UUID resolves to a Channel and sets it.
+	if req.NotificationChannelUUID != nil {
+		trimmed := strings.TrimSpace(*req.NotificationChannelUUID)
+		if trimmed == "" {
+			updates["notification_channel_id"] = nil
+		} else {
+			id, herr := h.resolveNotificationChannel(trimmed)
+			if herr != nil {
+				api.RespondError(w, herr.status, herr.msg)
+				return
 			}
+			updates["notification_channel_id"] = *id
 		}
+	}
 
-		// notification_channel_uuid is tri-state: omitted (nil pointer) leaves
-		// the existing FK untouched; explicit empty string clears it; a valid
-		// UUID resolves to a Channel and sets it.
-		if req.NotificationChannelUUID != nil {
-			trimmed := strings.TrimSpace(*req.NotificationChannelUUID)
-			if trimmed == "" {
-				updates["notification_channel_id"] = nil
-			} else {
-				id, herr := h.resolveNotificationChannel(trimmed)
-				if herr != nil {
-					api.RespondError(w, herr.status, herr.msg)
-					return
-				}
-				updates["notification_channel_id"] = *id
-			}
+	var skillNames []string
+	if req.SkillNames != nil {
+		var herr *alertChannelErr
+		if skillNames, herr = h.normalizeSourceSkills(*req.SkillNames); herr != nil {
+			api.RespondError(w, herr.status, herr.msg)
+			return
+		}
+	}
+
+	if err := h.alertService.UpdateInstance(uuid, updates); err != nil {
+		if isDuplicateNameErr(err) {
+			api.RespondError(w, http.StatusConflict, "An alert source with that name already exists")
+			return
 		}
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update alert source")
+		return
+	}
 
-		if err := h.alertService.UpdateInstance(uuid, updates); err != nil {
-			if isDuplicateNameErr(err) {
-				api.RespondError(w, http.StatusConflict, "An alert source with that name already exists")
+	if req.SkillNames != nil {
+		if err := h.alertService.SetInstanceSkills(uuid, skillNames); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				api.RespondError(w, http.StatusNotFound, "Alert source not found")
 				return
 			}
-			api.RespondError(w, http.StatusInternalServerError, "Failed to update alert source")
+			api.RespondError(w, http.StatusInternalServerError, "Failed to set alert source skills")
 			return
 		}
+	}
 
-		instance, _ := h.alertService.GetInstanceByUUID(uuid)
-		api.RespondJSON(w, http.StatusOK, instance)
-		h.reloadAlertChannels()
+	instance, _ := h.alertService.GetInstanceByUUID(uuid)
+	api.RespondJSON(w, http.StatusOK, instance)
+	h.reloadAlertChannels()
+}
 
-	case http.MethodDelete:
-		if err := h.alertService.DeleteInstance(uuid); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to delete alert source")
+// deleteAlertSource handles DELETE /api/alert-sources/{uuid}
+func (h *APIHandler) deleteAlertSource(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	if err := h.alertService.DeleteInstance(uuid); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to delete alert source")
+		return
+	}
+	api.RespondNoContent(w)
+	h.reloadAlertChannels()
+}
+
+// handleAlertSourceConfigSnippet handles GET
+// /api/alert-sources/{uuid}/config-snippet. It renders sender configuration
+// (Alertmanager receiver and route, Grafana contact point, Datadog webhook)
+// carrying the source's webhook URL and secret. base_url overrides the
+// configured Akmatori URL, for senders that reach Akmatori on another
+// address than the UI does.
+func (h *APIHandler) handleAlertSourceConfigSnippet(w http.ResponseWriter, r *http.Request) {
+	instance, err := h.alertService.GetInstanceByUUID(r.PathValue("uuid"))
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "Alert source not found")
+		return
+	}
+
+	baseURL := strings.TrimSpace(r.URL.Query().Get("base_url"))
+	if baseURL == "" {
+		baseURL = resolveBaseURL()
+	} else if !isValidURL(baseURL) {
+		api.RespondError(w, http.StatusBadRequest, "base_url must be an http(s) URL")
+		return
+	}
+
+	snippet, err := services.RenderAlertSourceConfigSnippet(instance, baseURL)
+	if err != nil {
+		if errors.Is(err, services.ErrNoConfigSnippet) {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		api.RespondNoContent(w)
-		h.reloadAlertChannels()
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		api.RespondError(w, http.StatusInternalServerError, "Failed to render configuration snippet")
+		return
 	}
+	api.RespondJSON(w, http.StatusOK, snippet)
 }
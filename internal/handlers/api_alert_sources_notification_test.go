@@ -76,7 +76,7 @@ func TestHandleAlertSources_Create_SetsNotificationChannelFromUUID(t *testing.T)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/alert-sources", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSources(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -118,7 +118,7 @@ func TestHandleAlertSources_Create_RejectsNonPostableChannel(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/alert-sources", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSources(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for non-postable channel, got %d: %s", w.Code, w.Body.String())
@@ -137,7 +137,7 @@ func TestHandleAlertSources_Create_RejectsUnknownChannelUUID(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/alert-sources", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSources(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for unknown channel UUID, got %d: %s", w.Code, w.Body.String())
@@ -162,7 +162,7 @@ func TestHandleAlertSources_Update_ClearsChannelOnEmptyString(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPut, "/api/alert-sources/asi-existing", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSourceByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -196,7 +196,7 @@ func TestHandleAlertSources_Update_SetsChannelFromUUID(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPut, "/api/alert-sources/asi-existing", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSourceByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -239,7 +239,7 @@ func TestHandleAlertSources_Create_RejectsDeprecatedSourceType(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/alert-sources", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-	h.handleAlertSources(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for deprecated source type, got %d: %s", w.Code, w.Body.String())
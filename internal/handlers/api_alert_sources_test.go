@@ -87,7 +87,7 @@ func TestAPIHandler_HandleAlertSourceTypes(t *testing.T) {
 		t.Fatalf("seed alert source type: %v", err)
 	}
 
-	w := performAlertSourceRequest(t, handler.handleAlertSourceTypes, http.MethodGet, "/api/alert-source-types", nil)
+	w := performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodGet, "/api/alert-source-types", nil)
 	if w.Code != http.StatusOK {
 		t.Fatalf("GET status = %d, want 200; body: %s", w.Code, w.Body.String())
 	}
@@ -99,7 +99,7 @@ func TestAPIHandler_HandleAlertSourceTypes(t *testing.T) {
 		t.Fatalf("source types = %+v, want one custom_webhook type", types)
 	}
 
-	w = performAlertSourceRequest(t, handler.handleAlertSourceTypes, http.MethodPost, "/api/alert-source-types", nil)
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-source-types", nil)
 	requireAlertSourceAPIError(t, w, http.StatusMethodNotAllowed, "Method not allowed")
 }
 
@@ -114,22 +114,29 @@ func TestAPIHandler_HandleAlertSources_CreateValidationAndConflict(t *testing.T)
 	reloads := make(chan struct{}, 4)
 	handler.SetAlertChannelReloader(func() { reloads <- struct{}{} })
 
-	w := performAlertSourceRequest(t, handler.handleAlertSources, http.MethodPost, "/api/alert-sources", "{")
+	w := performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", "{")
 	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "invalid JSON in request body")
 
-	w = performAlertSourceRequest(t, handler.handleAlertSources, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
 		SourceTypeName: " ",
 		Name:           "production alerts",
 	})
 	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "source_type_name and name are required")
 
-	w = performAlertSourceRequest(t, handler.handleAlertSources, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
 		SourceTypeName: "slack_channel",
 		Name:           "Slack alerts",
 		Settings:       database.JSONB{"slack_channel_id": "   "},
 	})
 	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "slack_channel_id is required")
 
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
+		SourceTypeName: "custom_webhook",
+		Name:           "Templated alerts",
+		Settings:       database.JSONB{"prompt_template": "Owner: {{.Labels.team"},
+	})
+	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "invalid prompt_template")
+
 	create := api.CreateAlertSourceRequest{
 		SourceTypeName: " custom_webhook ",
 		Name:           " Production alerts ",
@@ -138,7 +145,7 @@ func TestAPIHandler_HandleAlertSources_CreateValidationAndConflict(t *testing.T)
 		FieldMappings:  database.JSONB{"severity": "priority"},
 		Settings:       database.JSONB{"region": "eu"},
 	}
-	w = performAlertSourceRequest(t, handler.handleAlertSources, http.MethodPost, "/api/alert-sources", create)
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", create)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create status = %d, want 201; body: %s", w.Code, w.Body.String())
 	}
@@ -151,7 +158,7 @@ func TestAPIHandler_HandleAlertSources_CreateValidationAndConflict(t *testing.T)
 		t.Fatalf("created name = %q, want trimmed Production alerts", created.Name)
 	}
 
-	w = performAlertSourceRequest(t, handler.handleAlertSources, http.MethodPost, "/api/alert-sources", create)
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", create)
 	requireAlertSourceAPIError(t, w, http.StatusConflict, "already exists")
 }
 
@@ -168,22 +175,22 @@ func TestAPIHandler_HandleAlertSourceByUUID_UpdateAndDelete(t *testing.T) {
 	handler.SetAlertChannelReloader(func() { reloads <- struct{}{} })
 	path := "/api/alert-sources/" + instance.UUID
 
-	w := performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodGet, path, nil)
+	w := performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodGet, path, nil)
 	if w.Code != http.StatusOK {
 		t.Fatalf("GET status = %d, want 200; body: %s", w.Code, w.Body.String())
 	}
 
 	emptyName := "   "
-	w = performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodPut, path, api.UpdateAlertSourceRequest{Name: &emptyName})
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPut, path, api.UpdateAlertSourceRequest{Name: &emptyName})
 	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "name cannot be empty")
 
 	badSettings := database.JSONB{"slack_channel_id": ""}
-	w = performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodPut, path, api.UpdateAlertSourceRequest{Settings: &badSettings})
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPut, path, api.UpdateAlertSourceRequest{Settings: &badSettings})
 	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "slack_channel_id is required")
 
 	newName := "Updated Slack alerts"
 	goodSettings := database.JSONB{"slack_channel_id": "CNEW"}
-	w = performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodPut, path, api.UpdateAlertSourceRequest{
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPut, path, api.UpdateAlertSourceRequest{
 		Name:     &newName,
 		Settings: &goodSettings,
 	})
@@ -199,12 +206,123 @@ func TestAPIHandler_HandleAlertSourceByUUID_UpdateAndDelete(t *testing.T) {
 		t.Fatalf("updated source = %+v, want name %q and channel CNEW", updated, newName)
 	}
 
-	w = performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodDelete, path, nil)
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodDelete, path, nil)
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("DELETE status = %d, want 204; body: %s", w.Code, w.Body.String())
 	}
 	requireReload(t, reloads, "delete alert source")
 
-	w = performAlertSourceRequest(t, handler.handleAlertSourceByUUID, http.MethodGet, path, nil)
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodGet, path, nil)
 	requireAlertSourceAPIError(t, w, http.StatusNotFound, "Alert source not found")
 }
+
+// sourceSkillsSkillService resolves GetSkill against a fixed set of skills.
+type sourceSkillsSkillService struct {
+	corrGateSkillService
+	skills map[string]*database.Skill
+}
+
+func (s *sourceSkillsSkillService) GetSkill(name string) (*database.Skill, error) {
+	if sk, ok := s.skills[name]; ok {
+		return sk, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestAPIHandler_HandleAlertSources_SkillNames(t *testing.T) {
+	handler, service := setupAlertSourceAPIHandler(t)
+	db := database.GetDB()
+	if err := db.AutoMigrate(&database.ToolType{}, &database.ToolInstance{}, &database.SkillTool{}); err != nil {
+		t.Fatalf("migrate skill tables: %v", err)
+	}
+	linux := &database.Skill{Name: "linux", Enabled: true}
+	manager := &database.Skill{Name: "incident-manager", Enabled: true, IsSystem: true}
+	for _, sk := range []*database.Skill{linux, manager} {
+		if err := db.Create(sk).Error; err != nil {
+			t.Fatalf("seed skill: %v", err)
+		}
+	}
+	handler.skillService = &sourceSkillsSkillService{skills: map[string]*database.Skill{"linux": linux, "incident-manager": manager}}
+	if _, err := service.CreateAlertSourceType("zabbix", "Zabbix", "", database.JSONB{}, ""); err != nil {
+		t.Fatalf("seed source type: %v", err)
+	}
+
+	w := performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
+		SourceTypeName: "zabbix", Name: "zabbix-onprem", SkillNames: []string{"linux", "missing"},
+	})
+	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "unknown skill 'missing'")
+	if instances, _ := service.ListInstances(); len(instances) != 0 {
+		t.Fatalf("invalid skill_names must not create the source, got %d", len(instances))
+	}
+
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPost, "/api/alert-sources", api.CreateAlertSourceRequest{
+		SourceTypeName: "zabbix", Name: "zabbix-onprem", SkillNames: []string{" linux ", "linux"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	var created database.AlertSourceInstance
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created source: %v", err)
+	}
+	if len(created.Skills) != 1 || created.Skills[0].Name != "linux" {
+		t.Fatalf("created skills = %+v, want [linux]", created.Skills)
+	}
+
+	path := "/api/alert-sources/" + created.UUID
+	system := []string{"incident-manager"}
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPut, path, api.UpdateAlertSourceRequest{SkillNames: &system})
+	requireAlertSourceAPIError(t, w, http.StatusBadRequest, "unknown skill")
+
+	cleared := []string{}
+	w = performAlertSourceRequest(t, apiRoutes(handler).ServeHTTP, http.MethodPut, path, api.UpdateAlertSourceRequest{SkillNames: &cleared})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var updated database.AlertSourceInstance
+	if err := json.Unmarshal(w.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("decode updated source: %v", err)
+	}
+	if len(updated.Skills) != 0 {
+		t.Fatalf("updated skills = %+v, want none", updated.Skills)
+	}
+}
+
+func TestAlertSourceConfigSnippet(t *testing.T) {
+	handler, service := setupAlertSourceAPIHandler(t)
+	t.Setenv("AKMATORI_BASE_URL", "https://akmatori.example.com")
+	if err := service.InitializeDefaultSourceTypes(); err != nil {
+		t.Fatalf("init source types: %v", err)
+	}
+	am, err := service.CreateInstance("alertmanager", "Prod AM", "", "s3cret", nil, nil)
+	if err != nil {
+		t.Fatalf("create alertmanager source: %v", err)
+	}
+	zbx, err := service.CreateInstance("zabbix", "Prod Zabbix", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("create zabbix source: %v", err)
+	}
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/alert-sources/"+am.UUID+"/config-snippet", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var snippet services.AlertSourceConfigSnippet
+	if err := json.Unmarshal(rec.Body.Bytes(), &snippet); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if snippet.WebhookURL != "https://akmatori.example.com/webhook/alert/"+am.UUID || !strings.Contains(snippet.Snippet, `credentials: "s3cret"`) {
+		t.Errorf("snippet = %+v", snippet)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/alert-sources/"+am.UUID+"/config-snippet?base_url=http://10.0.0.5:3000", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &snippet); err != nil || !strings.HasPrefix(snippet.WebhookURL, "http://10.0.0.5:3000/webhook/alert/") {
+		t.Errorf("base_url override ignored: %+v (%v)", snippet, err)
+	}
+
+	requireAlertSourceAPIError(t, serveJSON(mux, http.MethodGet, "/api/alert-sources/"+am.UUID+"/config-snippet?base_url=ftp://x", ""), http.StatusBadRequest, "base_url")
+	requireAlertSourceAPIError(t, serveJSON(mux, http.MethodGet, "/api/alert-sources/"+zbx.UUID+"/config-snippet", ""), http.StatusBadRequest, "no configuration snippet")
+	requireAlertSourceAPIError(t, serveJSON(mux, http.MethodGet, "/api/alert-sources/missing/config-snippet", ""), http.StatusNotFound, "not found")
+}
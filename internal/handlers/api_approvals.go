@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetApprovalManager wires the service backing /api/approvals.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetApprovalManager(m services.ApprovalManager) {
+	h.approvals = m
+}
+
+// handleListApprovals handles GET /api/approvals, optionally filtered by
+// ?status= and ?incident_uuid=.
+func (h *APIHandler) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	if h.approvals == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Tool approvals are not configured")
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch database.ToolApprovalStatus(status) {
+	case "", database.ToolApprovalPending, database.ToolApprovalApproved, database.ToolApprovalRejected, database.ToolApprovalExpired:
+	default:
+		api.RespondError(w, http.StatusBadRequest, "status must be pending, approved, rejected or expired")
+		return
+	}
+	approvals, err := h.approvals.ListApprovals(r.Context(), status, r.URL.Query().Get("incident_uuid"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "approvals: failed to list", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list approvals")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, approvals)
+}
+
+// handleApproveApproval handles POST /api/approvals/{uuid}/approve.
+func (h *APIHandler) handleApproveApproval(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, true)
+}
+
+// handleRejectApproval handles POST /api/approvals/{uuid}/reject. Rejecting
+// also stops the investigation that asked for the action.
+func (h *APIHandler) handleRejectApproval(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, false)
+}
+
+func (h *APIHandler) decideApproval(w http.ResponseWriter, r *http.Request, approve bool) {
+	if h.approvals == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Tool approvals are not configured")
+		return
+	}
+	var req api.DecideApprovalRequest
+	if r.ContentLength != 0 {
+		if err := api.DecodeJSON(r, &req); err != nil {
+			api.RespondDecodeError(w, err)
+			return
+		}
+	}
+
+	approvalUUID := r.PathValue("uuid")
+	approval, err := h.approvals.Decide(r.Context(), approvalUUID, approve, middleware.GetUserFromContext(r.Context()), req.Reason)
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusOK, approval)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Approval not found")
+	case errors.Is(err, services.ErrApprovalNotPending):
+		api.RespondError(w, http.StatusConflict, "Approval was already decided or has expired")
+	default:
+		slog.ErrorContext(r.Context(), "approvals: failed to decide", "uuid", approvalUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to record decision")
+	}
+}
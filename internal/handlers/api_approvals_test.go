@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type stubApprovalManager struct {
+	decided map[string]bool
+	reasons []string
+}
+
+func (s *stubApprovalManager) ListApprovals(_ context.Context, status, incidentUUID string) ([]database.ToolApproval, error) {
+	return []database.ToolApproval{{UUID: "ap-1", IncidentUUID: incidentUUID, Status: database.ToolApprovalStatus(status)}}, nil
+}
+
+func (s *stubApprovalManager) Decide(_ context.Context, approvalUUID string, approve bool, decidedBy, reason string) (*database.ToolApproval, error) {
+	switch approvalUUID {
+	case "missing":
+		return nil, gorm.ErrRecordNotFound
+	case "done":
+		return &database.ToolApproval{UUID: approvalUUID}, services.ErrApprovalNotPending
+	}
+	s.decided[approvalUUID] = approve
+	s.reasons = append(s.reasons, reason)
+	return &database.ToolApproval{UUID: approvalUUID}, nil
+}
+
+func TestApprovalsAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/approvals", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+
+	stub := &stubApprovalManager{decided: map[string]bool{}}
+	h.SetApprovalManager(stub)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/approvals?status=pending&incident_uuid=inc-1", ""); rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/approvals?status=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad status filter = %d", rec.Code)
+	}
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/approvals/ap-1/approve", ""); rec.Code != http.StatusOK {
+		t.Fatalf("approve status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/approvals/ap-2/reject", `{"reason":"too risky"}`); rec.Code != http.StatusOK {
+		t.Fatalf("reject status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if !stub.decided["ap-1"] || stub.decided["ap-2"] || stub.reasons[1] != "too risky" {
+		t.Errorf("decided = %v, reasons = %v", stub.decided, stub.reasons)
+	}
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/approvals/missing/approve", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing status = %d", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/approvals/done/approve", ""); rec.Code != http.StatusConflict {
+		t.Errorf("already decided status = %d", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/approvals/ap-3/reject", `{"why":1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown field status = %d", rec.Code)
+	}
+}
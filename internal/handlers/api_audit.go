@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetAuditLogReader wires the audit log behind GET /api/audit. Optional —
+// when unset the endpoint returns 503.
+func (h *APIHandler) SetAuditLogReader(r services.AuditLogReader) {
+	h.auditLog = r
+}
+
+// handleListAuditLog handles GET /api/audit: login events and configuration
+// changes, newest first. Filters: user, entity_type, entity_id, action
+// (exact, or a prefix ending in "." such as "config."), since and until
+// (RFC3339 or unix seconds) and limit.
+func (h *APIHandler) handleListAuditLog(w http.ResponseWriter, r *http.Request) {
+	if h.auditLog == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Audit log is not configured")
+		return
+	}
+	q := r.URL.Query()
+	filter := services.AuditLogFilter{
+		Actor:      q.Get("user"),
+		EntityType: q.Get("entity_type"),
+		Target:     q.Get("entity_id"),
+		Action:     q.Get("action"),
+	}
+	for _, param := range []string{"since", "until"} {
+		v := q.Get(param)
+		if v == "" {
+			continue
+		}
+		t := parseTimeQueryParam(v)
+		if t == nil {
+			api.RespondValidationError(w, map[string]string{param: "must be RFC3339 or unix seconds"})
+			return
+		}
+		if param == "since" {
+			filter.Since = *t
+		} else {
+			filter.Until = *t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			api.RespondValidationError(w, map[string]string{"limit": "must be a positive integer"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.auditLog.ListAuditLogs(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "audit: failed to list audit log", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list audit log")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAuditLogAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.AuditLog{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/audit", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	audit := services.NewAuditService(db)
+	h.SetAuditLogReader(audit)
+	_ = audit.Record(&database.AuditLog{Action: database.AuditActionConfigUpdate, Actor: "alice", EntityType: "skill", Target: "pg-triage"})
+	_ = audit.Record(&database.AuditLog{Action: database.AuditActionConfigUpdate, Actor: "bob", EntityType: "tool", Target: "3"})
+
+	rec := serveJSON(mux, http.MethodGet, "/api/audit?user=alice&entity_type=skill&entity_id=pg-triage&action=config.", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var entries []database.AuditLog
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Actor != "alice" {
+		t.Errorf("entries = %+v, want alice's skill change", entries)
+	}
+
+	for _, query := range []string{"since=yesterday", "until=soon", "limit=0", "limit=x"} {
+		if rec := serveJSON(mux, http.MethodGet, "/api/audit?"+query, ""); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", query, rec.Code)
+		}
+	}
+}
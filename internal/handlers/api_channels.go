@@ -47,6 +47,11 @@ type channelResponse struct {
 	ProcessBotMessages   bool                 `json:"process_bot_messages"`
 	ProcessHumanMessages bool                 `json:"process_human_messages"`
 	Enabled              bool                 `json:"enabled"`
+	ReactionAlert        string               `json:"reaction_alert"`
+	ReactionWorking      string               `json:"reaction_working"`
+	ReactionSuccess      string               `json:"reaction_success"`
+	ReactionFailure      string               `json:"reaction_failure"`
+	ProgressVerbosity    string               `json:"progress_verbosity"`
 	CreatedAt            interface{}          `json:"created_at"`
 	UpdatedAt            interface{}          `json:"updated_at"`
 	Integration          *integrationResponse `json:"integration,omitempty"`
@@ -66,6 +71,11 @@ func toChannelResponse(row *database.Channel) channelResponse {
 		ProcessBotMessages:   row.ProcessBotMessages,
 		ProcessHumanMessages: row.ProcessHumanMessages,
 		Enabled:              row.Enabled,
+		ReactionAlert:        row.ReactionAlert,
+		ReactionWorking:      row.ReactionWorking,
+		ReactionSuccess:      row.ReactionSuccess,
+		ReactionFailure:      row.ReactionFailure,
+		ProgressVerbosity:    string(row.ProgressVerbosity),
 		CreatedAt:            row.CreatedAt,
 		UpdatedAt:            row.UpdatedAt,
 	}
@@ -88,8 +98,8 @@ func toChannelResponses(rows []database.Channel) []channelResponse {
 // integration is selected by UUID so callers do not have to expose internal
 // integer IDs to the UI; ExternalID is the provider-specific channel handle.
 type CreateChannelRequest struct {
-	IntegrationUUID      string `json:"integration_uuid"`
-	ExternalID           string `json:"external_id"`
+	IntegrationUUID      string `json:"integration_uuid" validate:"notblank"`
+	ExternalID           string `json:"external_id" validate:"notblank"`
 	DisplayName          string `json:"display_name,omitempty"`
 	CanPost              bool   `json:"can_post"`
 	CanListen            bool   `json:"can_listen"`
@@ -98,6 +108,13 @@ type CreateChannelRequest struct {
 	ProcessBotMessages   *bool  `json:"process_bot_messages,omitempty"`
 	ProcessHumanMessages bool   `json:"process_human_messages,omitempty"`
 	Enabled              *bool  `json:"enabled,omitempty"`
+	// Slack reaction overrides: an emoji name, "none" to suppress the
+	// reaction, or empty for the default.
+	ReactionAlert     string `json:"reaction_alert,omitempty"`
+	ReactionWorking   string `json:"reaction_working,omitempty"`
+	ReactionSuccess   string `json:"reaction_success,omitempty"`
+	ReactionFailure   string `json:"reaction_failure,omitempty"`
+	ProgressVerbosity string `json:"progress_verbosity,omitempty" validate:"omitempty,oneof=off status thread"`
 }
 
 // UpdateChannelRequest is the request body for PUT /api/channels/{uuid}. Every
@@ -114,174 +131,167 @@ type UpdateChannelRequest struct {
 	ProcessBotMessages   *bool   `json:"process_bot_messages,omitempty"`
 	ProcessHumanMessages *bool   `json:"process_human_messages,omitempty"`
 	Enabled              *bool   `json:"enabled,omitempty"`
+	ReactionAlert        *string `json:"reaction_alert,omitempty"`
+	ReactionWorking      *string `json:"reaction_working,omitempty"`
+	ReactionSuccess      *string `json:"reaction_success,omitempty"`
+	ReactionFailure      *string `json:"reaction_failure,omitempty"`
+	ProgressVerbosity    *string `json:"progress_verbosity,omitempty"`
 }
 
-// handleChannels dispatches GET /api/channels and POST /api/channels.
-func (h *APIHandler) handleChannels(w http.ResponseWriter, r *http.Request) {
-	if h.channelService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Channel service is not configured")
+// listChannels handles GET /api/channels.
+func (h *APIHandler) listChannels(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseChannelFilter(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	rows, err := h.channelService.ListChannels(filter)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to list channels")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, toChannelResponses(rows))
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		filter, err := parseChannelFilter(r)
-		if err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		rows, err := h.channelService.ListChannels(filter)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toChannelResponses(rows))
-
-	case http.MethodPost:
-		var req CreateChannelRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		if strings.TrimSpace(req.IntegrationUUID) == "" {
-			api.RespondError(w, http.StatusBadRequest, "integration_uuid is required")
-			return
-		}
-		if strings.TrimSpace(req.ExternalID) == "" {
-			api.RespondError(w, http.StatusBadRequest, "external_id is required")
-			return
-		}
-
-		integration, err := h.channelService.GetIntegrationByUUID(req.IntegrationUUID)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-
-		// Provider-specific create-time validation. Slack channel IDs and
-		// names always have non-empty external IDs; the service-layer guard
-		// already enforces non-empty, but Slack-channel handles also must
-		// not be pure whitespace nor contain commas (which would break
-		// downstream multi-channel parsing in the legacy code path).
-		if err := validateProviderExternalID(integration.Provider, req.ExternalID); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+// createChannel handles POST /api/channels.
+func (h *APIHandler) createChannel(w http.ResponseWriter, r *http.Request) {
+	var req CreateChannelRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	integration, err := h.channelService.GetIntegrationByUUID(req.IntegrationUUID)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to create channel")
+		return
+	}
 
-		enabled := true
-		if req.Enabled != nil {
-			enabled = *req.Enabled
-		}
-		// Omitted process_bot_messages defaults to true — listener channels
-		// have always processed bot messages; opting out is the new behavior.
-		processBotMessages := true
-		if req.ProcessBotMessages != nil {
-			processBotMessages = *req.ProcessBotMessages
-		}
+	// Provider-specific create-time validation. Slack channel IDs and
+	// names always have non-empty external IDs; the service-layer guard
+	// already enforces non-empty, but Slack-channel handles also must
+	// not be pure whitespace nor contain commas (which would break
+	// downstream multi-channel parsing in the legacy code path).
+	if err := validateProviderExternalID(integration.Provider, req.ExternalID); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-		ch := &database.Channel{
-			IntegrationID:        integration.ID,
-			ExternalID:           req.ExternalID,
-			DisplayName:          req.DisplayName,
-			CanPost:              req.CanPost,
-			CanListen:            req.CanListen,
-			IsDefaultPost:        req.IsDefaultPost,
-			ExtractionPrompt:     req.ExtractionPrompt,
-			ProcessBotMessages:   processBotMessages,
-			ProcessHumanMessages: req.ProcessHumanMessages,
-			Enabled:              enabled,
-		}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	// Omitted process_bot_messages defaults to true — listener channels
+	// have always processed bot messages; opting out is the new behavior.
+	processBotMessages := true
+	if req.ProcessBotMessages != nil {
+		processBotMessages = *req.ProcessBotMessages
+	}
 
-		row, err := h.channelService.CreateChannel(ch)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		// Reload Slack listener mappings so new can_listen channels become
-		// active immediately. Safe to call even when the channel only posts;
-		// the loader filters by can_listen on its own.
-		h.reloadAlertChannels()
-		api.RespondJSON(w, http.StatusCreated, toChannelResponse(row))
+	ch := &database.Channel{
+		IntegrationID:        integration.ID,
+		ExternalID:           req.ExternalID,
+		DisplayName:          req.DisplayName,
+		CanPost:              req.CanPost,
+		CanListen:            req.CanListen,
+		IsDefaultPost:        req.IsDefaultPost,
+		ExtractionPrompt:     req.ExtractionPrompt,
+		ProcessBotMessages:   processBotMessages,
+		ProcessHumanMessages: req.ProcessHumanMessages,
+		Enabled:              enabled,
+		ReactionAlert:        req.ReactionAlert,
+		ReactionWorking:      req.ReactionWorking,
+		ReactionSuccess:      req.ReactionSuccess,
+		ReactionFailure:      req.ReactionFailure,
+		ProgressVerbosity:    database.SlackProgressVerbosity(req.ProgressVerbosity),
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	row, err := h.channelService.CreateChannel(ch)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to create channel")
+		return
 	}
+	// Reload Slack listener mappings so new can_listen channels become
+	// active immediately. Safe to call even when the channel only posts;
+	// the loader filters by can_listen on its own.
+	h.reloadAlertChannels()
+	api.RespondJSON(w, http.StatusCreated, toChannelResponse(row))
 }
 
-// handleChannelByUUID dispatches GET/PUT/DELETE /api/channels/{uuid}.
-func (h *APIHandler) handleChannelByUUID(w http.ResponseWriter, r *http.Request) {
-	if h.channelService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Channel service is not configured")
+// getChannel handles GET /api/channels/{uuid}.
+func (h *APIHandler) getChannel(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	row, err := h.channelService.GetChannelByUUID(uuid)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to get channel")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, toChannelResponse(row))
+}
+
+// updateChannel handles PUT /api/channels/{uuid}.
+func (h *APIHandler) updateChannel(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	uuid := strings.TrimPrefix(r.URL.Path, "/api/channels/")
-	if uuid == "" || strings.Contains(uuid, "/") {
-		api.RespondError(w, http.StatusBadRequest, "Invalid channel UUID")
+	var req UpdateChannelRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
 		return
 	}
-
-	switch r.Method {
-	case http.MethodGet:
-		row, err := h.channelService.GetChannelByUUID(uuid)
+	// Mirror the create-time provider-specific external_id check so a
+	// rename can't smuggle in spaces or commas that would break downstream
+	// parsing. Look up the existing channel for its Integration.Provider —
+	// PUT does not re-parent the channel, so the resolved provider is
+	// stable.
+	if req.ExternalID != nil {
+		existing, err := h.channelService.GetChannelByUUID(uuid)
 		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
+			api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to update channel")
 			return
 		}
-		api.RespondJSON(w, http.StatusOK, toChannelResponse(row))
-
-	case http.MethodPut:
-		var req UpdateChannelRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
+		if err := validateProviderExternalID(existing.Integration.Provider, *req.ExternalID); err != nil {
 			api.RespondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		// Mirror the create-time provider-specific external_id check so a
-		// rename can't smuggle in spaces or commas that would break downstream
-		// parsing. Look up the existing channel for its Integration.Provider —
-		// PUT does not re-parent the channel, so the resolved provider is
-		// stable.
-		if req.ExternalID != nil {
-			existing, err := h.channelService.GetChannelByUUID(uuid)
-			if err != nil {
-				api.RespondError(w, integrationErrStatus(err), err.Error())
-				return
-			}
-			if err := validateProviderExternalID(existing.Integration.Provider, *req.ExternalID); err != nil {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-				return
-			}
-		}
-		patch := services.ChannelUpdate{
-			ExternalID:           req.ExternalID,
-			DisplayName:          req.DisplayName,
-			CanPost:              req.CanPost,
-			CanListen:            req.CanListen,
-			IsDefaultPost:        req.IsDefaultPost,
-			ExtractionPrompt:     req.ExtractionPrompt,
-			ProcessBotMessages:   req.ProcessBotMessages,
-			ProcessHumanMessages: req.ProcessHumanMessages,
-			Enabled:              req.Enabled,
-		}
-		row, err := h.channelService.UpdateChannel(uuid, patch)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		h.reloadAlertChannels()
-		api.RespondJSON(w, http.StatusOK, toChannelResponse(row))
+	}
+	patch := services.ChannelUpdate{
+		ExternalID:           req.ExternalID,
+		DisplayName:          req.DisplayName,
+		CanPost:              req.CanPost,
+		CanListen:            req.CanListen,
+		IsDefaultPost:        req.IsDefaultPost,
+		ExtractionPrompt:     req.ExtractionPrompt,
+		ProcessBotMessages:   req.ProcessBotMessages,
+		ProcessHumanMessages: req.ProcessHumanMessages,
+		Enabled:              req.Enabled,
+		ReactionAlert:        req.ReactionAlert,
+		ReactionWorking:      req.ReactionWorking,
+		ReactionSuccess:      req.ReactionSuccess,
+		ReactionFailure:      req.ReactionFailure,
+	}
+	if req.ProgressVerbosity != nil {
+		verbosity := database.SlackProgressVerbosity(*req.ProgressVerbosity)
+		patch.ProgressVerbosity = &verbosity
+	}
+	row, err := h.channelService.UpdateChannel(uuid, patch)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to update channel")
+		return
+	}
+	h.reloadAlertChannels()
+	api.RespondJSON(w, http.StatusOK, toChannelResponse(row))
+}
 
-	case http.MethodDelete:
-		if err := h.channelService.DeleteChannel(uuid); err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		h.reloadAlertChannels()
-		api.RespondNoContent(w)
+// deleteChannel handles DELETE /api/channels/{uuid}.
+func (h *APIHandler) deleteChannel(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.channelService.DeleteChannel(uuid); err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to delete channel")
+		return
 	}
+	h.reloadAlertChannels()
+	api.RespondNoContent(w)
 }
 
 // parseChannelFilter reads ListChannels filter parameters from a request URL.
@@ -31,7 +31,7 @@ func TestHandleChannels_MasksIntegrationCredentials(t *testing.T) {
 	h := newHandlerWithChannelManager(mgr)
 	req := httptest.NewRequest(http.MethodGet, "/api/channels", nil)
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -55,7 +55,7 @@ func TestHandleChannels_List(t *testing.T) {
 	h := newHandlerWithChannelManager(mgr)
 
 	testhelpers.NewHTTPTestContext(t, http.MethodGet, "/api/channels", nil).
-		ExecuteFunc(h.handleChannels).
+		ExecuteFunc(apiRoutes(h).ServeHTTP).
 		AssertStatus(http.StatusOK).
 		AssertJSONContentType().
 		AssertJSONField("0.uuid", "c1").
@@ -75,7 +75,7 @@ func TestHandleChannels_List_FilterByCanPost(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/channels?can_post=true", nil)
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -95,7 +95,7 @@ func TestHandleChannels_List_InvalidBoolean(t *testing.T) {
 	h := newHandlerWithChannelManager(&mockChannelManager{})
 	req := httptest.NewRequest(http.MethodGet, "/api/channels?can_listen=maybe", nil)
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -117,7 +117,7 @@ func TestHandleChannels_Create(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -140,10 +140,10 @@ func TestHandleChannels_Create_RequiresIntegrationUUID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
 	}
 }
 
@@ -157,10 +157,10 @@ func TestHandleChannels_Create_RequiresExternalID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d", w.Code)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
 	}
 }
 
@@ -175,7 +175,7 @@ func TestHandleChannels_Create_RejectsBadSlackExternalID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -191,7 +191,7 @@ func TestHandleChannels_Create_IntegrationMissing(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
@@ -210,7 +210,7 @@ func TestHandleChannels_Create_DuplicateDefault(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/channels", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusConflict {
 		t.Fatalf("expected 409, got %d", w.Code)
 	}
@@ -223,7 +223,7 @@ func TestHandleChannelByUUID_Get(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/channels/c1", nil)
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -236,7 +236,7 @@ func TestHandleChannelByUUID_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/channels/missing", nil)
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
@@ -252,7 +252,7 @@ func TestHandleChannelByUUID_Update(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/channels/c1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -277,7 +277,7 @@ func TestHandleChannelByUUID_Update_RejectsBadSlackExternalID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/channels/c1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for invalid slack external_id, got %d: %s", w.Code, w.Body.String())
 	}
@@ -300,7 +300,7 @@ func TestHandleChannelByUUID_Update_DuplicateDefault(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/channels/c1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusConflict {
 		t.Fatalf("expected 409, got %d", w.Code)
 	}
@@ -313,7 +313,7 @@ func TestHandleChannelByUUID_Delete(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/channels/c1", nil)
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("expected 204, got %d", w.Code)
 	}
@@ -327,7 +327,7 @@ func TestHandleChannelByUUID_MethodNotAllowed(t *testing.T) {
 	h := newHandlerWithChannelManager(&mockChannelManager{channels: []database.Channel{{UUID: "c1"}}})
 	req := httptest.NewRequest(http.MethodPatch, "/api/channels/c1", nil)
 	w := httptest.NewRecorder()
-	h.handleChannelByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -338,7 +338,7 @@ func TestHandleChannels_NotConfigured(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/channels", nil)
 	w := httptest.NewRecorder()
-	h.handleChannels(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("expected 503, got %d", w.Code)
 	}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetComplianceReporter wires the agent write-action report behind GET
+// /api/compliance/actions. Optional — when unset the endpoint returns 503.
+func (h *APIHandler) SetComplianceReporter(c services.ComplianceReporter) {
+	h.compliance = c
+}
+
+// handleComplianceActions handles GET /api/compliance/actions: every write
+// the agents executed on remote hosts in a period, with its approver.
+// Query: since and until (RFC3339 or unix seconds, default the last 30
+// days), format (json|csv, default json).
+func (h *APIHandler) handleComplianceActions(w http.ResponseWriter, r *http.Request) {
+	if h.compliance == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Compliance reporting is not configured")
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		api.RespondError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+	var filter services.ComplianceFilter
+	for _, param := range []string{"since", "until"} {
+		v := q.Get(param)
+		if v == "" {
+			continue
+		}
+		t := parseTimeQueryParam(v)
+		if t == nil {
+			api.RespondValidationError(w, map[string]string{param: "must be RFC3339 or unix seconds"})
+			return
+		}
+		if param == "since" {
+			filter.Since = *t
+		} else {
+			filter.Until = *t
+		}
+	}
+
+	report, err := h.compliance.Actions(r.Context(), filter)
+	if errors.Is(err, services.ErrInvalidComplianceFilter) {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "compliance: failed to list agent actions", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to build compliance report")
+		return
+	}
+
+	if format != "csv" {
+		api.RespondJSON(w, http.StatusOK, report)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+services.ComplianceCSVFilename(report)+`"`)
+	w.WriteHeader(http.StatusOK)
+	if err := services.WriteComplianceCSV(w, report.Actions); err != nil {
+		slog.WarnContext(r.Context(), "compliance: failed to write CSV", "err", err)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestComplianceActionsAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.IncidentChange{}, &database.ToolApproval{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/compliance/actions", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	h.SetComplianceReporter(services.NewComplianceService(db))
+	db.Create(&database.IncidentChange{IncidentUUID: "inc-1", Location: "db-1", Action: database.IncidentChangeCommand,
+		Tool: "ssh.execute_command", Detail: "vacuumdb --all", CreatedAt: time.Now().Add(-time.Hour)})
+
+	rec := serveJSON(mux, http.MethodGet, "/api/compliance/actions?format=csv", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), "agent-actions-") || !strings.Contains(rec.Body.String(), "db-1,command,,vacuumdb --all") {
+		t.Errorf("CSV = %s", rec.Body.String())
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/compliance/actions?format=xlsx", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad format: status = %d, want 400", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/compliance/actions?until=soon", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bad until: status = %d, want 422", rec.Code)
+	}
+}
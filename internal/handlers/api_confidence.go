@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetConfidenceStats wires investigation confidence calibration behind
+// GET /api/analytics/confidence. Optional — when unset the endpoint returns
+// 503.
+func (h *APIHandler) SetConfidenceStats(r services.ConfidenceStatsReader) {
+	h.confidenceStats = r
+}
+
+// handleConfidenceCalibration handles GET /api/analytics/confidence: finished
+// investigations bucketed by the confidence the agent reported, with how
+// often each bucket was retried, sent for review or auto-closed.
+// Query: since and until (RFC3339 or unix seconds).
+func (h *APIHandler) handleConfidenceCalibration(w http.ResponseWriter, r *http.Request) {
+	if h.confidenceStats == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Confidence scoring is not configured")
+		return
+	}
+	q := r.URL.Query()
+	var since, until *time.Time
+	for _, param := range []string{"since", "until"} {
+		v := q.Get(param)
+		if v == "" {
+			continue
+		}
+		t := parseTimeQueryParam(v)
+		if t == nil {
+			api.RespondValidationError(w, map[string]string{param: "must be RFC3339 or unix seconds"})
+			return
+		}
+		if param == "since" {
+			since = t
+		} else {
+			until = t
+		}
+	}
+
+	stats, err := h.confidenceStats.Calibration(r.Context(), since, until)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "analytics: failed to compute confidence calibration", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to compute confidence calibration")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestConfidenceCalibrationAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/analytics/confidence", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	h.SetConfidenceStats(services.NewConfidenceService(db, nil))
+	now := time.Now()
+	score := 0.35
+	db.Create(&database.Incident{UUID: "inc-1", Source: "test", Confidence: &score, Attempts: 2, CompletedAt: &now})
+
+	rec := serveJSON(mux, http.MethodGet, "/api/analytics/confidence?since=2020-01-01T00:00:00Z", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats services.ConfidenceCalibration
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Scored != 1 || len(stats.Buckets) != 5 || stats.Buckets[1].Incidents != 1 || stats.Buckets[1].RetryRate != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/analytics/confidence?until=tomorrow", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bad until: status = %d, want 422", rec.Code)
+	}
+}
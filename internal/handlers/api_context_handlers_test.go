@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -83,10 +84,10 @@ func TestAPIHandler_HandleContextDownload_ServesStoredFile(t *testing.T) {
 		t.Fatalf("SaveFile guide.md: %v", err)
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/context/1/download", nil)
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/context/%d/download", stored.ID), nil)
 	w := httptest.NewRecorder()
 
-	h.handleContextDownload(w, req, stored.ID)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
@@ -39,6 +39,7 @@ type cronJobResponse struct {
 	UpdatedAt     time.Time             `json:"updated_at"`
 	Channel       *channelResponse      `json:"channel,omitempty"`
 	Tools         []toolInstanceSummary `json:"tools"`
+	SkillNames    []string              `json:"skill_names"`
 }
 
 // toolInstanceSummary is the slim, secret-free projection of a
@@ -71,6 +72,10 @@ func toCronJobResponse(row *database.CronJob) cronJobResponse {
 		CreatedAt:     row.CreatedAt,
 		UpdatedAt:     row.UpdatedAt,
 		Tools:         toToolInstanceSummaries(row.Tools),
+		SkillNames:    make([]string, len(row.Skills)),
+	}
+	for i := range row.Skills {
+		resp.SkillNames[i] = row.Skills[i].Name
 	}
 	if row.Channel != nil && row.Channel.ID != 0 {
 		masked := toChannelResponse(row.Channel)
@@ -109,14 +114,18 @@ func toCronJobResponses(rows []database.CronJob) []cronJobResponse {
 // cron-agent runs with no infrastructure tools (memory + runbooks only).
 // The shape matches /api/skills/:name/tools (tool_instance_ids) — tool
 // instances are addressed by integer ID throughout this codebase.
+//
+// SkillNames pins operator skills the run may use alongside the cron-agent
+// root skill, e.g. a certificate-audit skill for a weekly expiry check.
 type CreateCronJobRequest struct {
-	Name            string `json:"name"`
-	Schedule        string `json:"schedule"`
-	Prompt          string `json:"prompt"`
-	ChannelUUID     string `json:"channel_uuid,omitempty"`
-	Enabled         *bool  `json:"enabled,omitempty"`
-	PostResults     *bool  `json:"post_results,omitempty"`
-	ToolInstanceIDs []uint `json:"tool_instance_ids,omitempty"`
+	Name            string   `json:"name"`
+	Schedule        string   `json:"schedule"`
+	Prompt          string   `json:"prompt"`
+	ChannelUUID     string   `json:"channel_uuid,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+	PostResults     *bool    `json:"post_results,omitempty"`
+	ToolInstanceIDs []uint   `json:"tool_instance_ids,omitempty"`
+	SkillNames      []string `json:"skill_names,omitempty"`
 }
 
 // UpdateCronJobRequest is the request body for PUT /api/cron-jobs/{uuid}.
@@ -127,144 +136,133 @@ type CreateCronJobRequest struct {
 // "leave tools alone" (field absent / nil) from "clear the allowlist"
 // (explicit empty slice). Without the indirection a missing field would be
 // indistinguishable from one that explicitly empties the per-cron tools.
+// SkillNames follows the same convention for pinned skills.
 type UpdateCronJobRequest struct {
-	Name            *string `json:"name,omitempty"`
-	Schedule        *string `json:"schedule,omitempty"`
-	Prompt          *string `json:"prompt,omitempty"`
-	ChannelUUID     *string `json:"channel_uuid,omitempty"`
-	Enabled         *bool   `json:"enabled,omitempty"`
-	PostResults     *bool   `json:"post_results,omitempty"`
-	ToolInstanceIDs *[]uint `json:"tool_instance_ids,omitempty"`
+	Name            *string   `json:"name,omitempty"`
+	Schedule        *string   `json:"schedule,omitempty"`
+	Prompt          *string   `json:"prompt,omitempty"`
+	ChannelUUID     *string   `json:"channel_uuid,omitempty"`
+	Enabled         *bool     `json:"enabled,omitempty"`
+	PostResults     *bool     `json:"post_results,omitempty"`
+	ToolInstanceIDs *[]uint   `json:"tool_instance_ids,omitempty"`
+	SkillNames      *[]string `json:"skill_names,omitempty"`
 }
 
-// handleCronJobs dispatches GET /api/cron-jobs and POST /api/cron-jobs.
-func (h *APIHandler) handleCronJobs(w http.ResponseWriter, r *http.Request) {
-	if h.cronService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Cron service is not configured")
+// listCronJobs handles GET /api/cron-jobs.
+func (h *APIHandler) listCronJobs(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.cronService.ListJobs()
+	if err != nil {
+		api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to list cron jobs")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, toCronJobResponses(rows))
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		rows, err := h.cronService.ListJobs()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toCronJobResponses(rows))
-
-	case http.MethodPost:
-		var req CreateCronJobRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		enabled := true
-		if req.Enabled != nil {
-			enabled = *req.Enabled
-		}
-		postResults := true
-		if req.PostResults != nil {
-			postResults = *req.PostResults
-		}
-		row, err := h.cronService.CreateJob(
-			req.Name,
-			req.Schedule,
-			req.Prompt,
-			req.ChannelUUID,
-			enabled,
-			postResults,
-			req.ToolInstanceIDs,
-		)
+// createCronJob handles POST /api/cron-jobs.
+func (h *APIHandler) createCronJob(w http.ResponseWriter, r *http.Request) {
+	var req CreateCronJobRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	postResults := true
+	if req.PostResults != nil {
+		postResults = *req.PostResults
+	}
+	// Check the pinned skills before creating the row so an unknown
+	// skill does not leave a half-configured job behind.
+	skillNames, herr := h.normalizeSourceSkills(req.SkillNames)
+	if herr != nil {
+		api.RespondError(w, herr.status, herr.msg)
+		return
+	}
+	row, err := h.cronService.CreateJob(
+		req.Name,
+		req.Schedule,
+		req.Prompt,
+		req.ChannelUUID,
+		enabled,
+		postResults,
+		req.ToolInstanceIDs,
+	)
+	if err != nil {
+		api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to create cron job")
+		return
+	}
+	if len(skillNames) > 0 {
+		row, err = h.cronService.UpdateJob(row.UUID, services.CronJobUpdate{SkillNames: &skillNames})
 		if err != nil {
-			api.RespondError(w, cronErrStatus(err), err.Error())
+			api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to create cron job")
 			return
 		}
-		api.RespondJSON(w, http.StatusCreated, toCronJobResponse(row))
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
+	api.RespondJSON(w, http.StatusCreated, toCronJobResponse(row))
 }
 
-// handleCronJobByUUID dispatches GET/PUT/DELETE /api/cron-jobs/{uuid} and
-// POST /api/cron-jobs/{uuid}/run. Splitting the run sub-route in here (rather
-// than a dedicated mux handler) keeps the routes table in api.go terse.
-func (h *APIHandler) handleCronJobByUUID(w http.ResponseWriter, r *http.Request) {
-	if h.cronService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Cron service is not configured")
+// getCronJob handles GET /api/cron-jobs/{uuid}.
+func (h *APIHandler) getCronJob(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	row, err := h.cronService.GetJobByUUID(uuid)
+	if err != nil {
+		api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to get cron job")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, toCronJobResponse(row))
+}
 
-	rest := strings.TrimPrefix(r.URL.Path, "/api/cron-jobs/")
-	uuid, sub, hasSub := strings.Cut(rest, "/")
-	if uuid == "" {
-		api.RespondError(w, http.StatusBadRequest, "Invalid cron job UUID")
+// updateCronJob handles PUT /api/cron-jobs/{uuid}.
+func (h *APIHandler) updateCronJob(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	var req UpdateCronJobRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
 		return
 	}
-
-	if hasSub {
-		switch sub {
-		case "run":
-			if r.Method != http.MethodPost {
-				api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-				return
-			}
-			if err := h.cronService.RunNow(uuid); err != nil {
-				api.RespondError(w, cronErrStatus(err), err.Error())
-				return
-			}
-			// 202: the tick was accepted and is running in the background.
-			// Operators poll LastRunStatus / LastRunError on the row for the
-			// outcome.
-			api.RespondJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
-		default:
-			api.RespondError(w, http.StatusNotFound, "Not found")
-		}
+	patch := services.CronJobUpdate{
+		Name:            req.Name,
+		Schedule:        req.Schedule,
+		Prompt:          req.Prompt,
+		ChannelUUID:     req.ChannelUUID,
+		Enabled:         req.Enabled,
+		PostResults:     req.PostResults,
+		ToolInstanceIDs: req.ToolInstanceIDs,
+		SkillNames:      req.SkillNames,
+	}
+	row, err := h.cronService.UpdateJob(uuid, patch)
+	if err != nil {
+		api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to update cron job")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, toCronJobResponse(row))
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		row, err := h.cronService.GetJobByUUID(uuid)
-		if err != nil {
-			api.RespondError(w, cronErrStatus(err), err.Error())
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toCronJobResponse(row))
-
-	case http.MethodPut:
-		var req UpdateCronJobRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		patch := services.CronJobUpdate{
-			Name:            req.Name,
-			Schedule:        req.Schedule,
-			Prompt:          req.Prompt,
-			ChannelUUID:     req.ChannelUUID,
-			Enabled:         req.Enabled,
-			PostResults:     req.PostResults,
-			ToolInstanceIDs: req.ToolInstanceIDs,
-		}
-		row, err := h.cronService.UpdateJob(uuid, patch)
-		if err != nil {
-			api.RespondError(w, cronErrStatus(err), err.Error())
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toCronJobResponse(row))
+// deleteCronJob handles DELETE /api/cron-jobs/{uuid}.
+func (h *APIHandler) deleteCronJob(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	case http.MethodDelete:
-		if err := h.cronService.DeleteJob(uuid); err != nil {
-			api.RespondError(w, cronErrStatus(err), err.Error())
-			return
-		}
-		api.RespondNoContent(w)
+	if err := h.cronService.DeleteJob(uuid); err != nil {
+		api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to delete cron job")
+		return
+	}
+	api.RespondNoContent(w)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// runCronJob handles POST /api/cron-jobs/{uuid}/run.
+func (h *APIHandler) runCronJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.cronService.RunNow(r.PathValue("uuid")); err != nil {
+		api.RespondServiceError(w, r, cronErrStatus(err), err, "Failed to run cron job")
+		return
 	}
+	// 202: the tick was accepted and is running in the background.
+	// Operators poll LastRunStatus / LastRunError on the row for the
+	// outcome.
+	api.RespondJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
 }
 
 // cronErrStatus translates service-layer errors into HTTP status codes. Bad
@@ -311,6 +309,7 @@ func isCronClientError(err error) bool {
 		"create cron job: ",
 		"update cron job: ",
 		"update cron job tools: ",
+		"update cron job skills: ",
 		"delete cron job",
 		"list cron jobs: ",
 		"get cron job ",
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/services"
 )
@@ -86,6 +87,12 @@ func (m *mockCronJobManager) UpdateJob(uuid string, patch services.CronJobUpdate
 			if patch.Schedule != nil {
 				m.jobs[i].Schedule = *patch.Schedule
 			}
+			if patch.SkillNames != nil {
+				m.jobs[i].Skills = nil
+				for _, name := range *patch.SkillNames {
+					m.jobs[i].Skills = append(m.jobs[i].Skills, database.Skill{Name: name})
+				}
+			}
 			out := m.jobs[i]
 			return &out, nil
 		}
@@ -131,7 +138,7 @@ func TestHandleCronJobs_ServiceUnavailable(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("expected 503, got %d", w.Code)
 	}
@@ -143,7 +150,7 @@ func TestHandleCronJobs_List(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -169,7 +176,7 @@ func TestHandleCronJobs_Create(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -191,11 +198,18 @@ func TestHandleCronJobs_Create_InvalidSchedule(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
 	}
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != api.CodeBadRequest || resp.Message != services.ErrInvalidCronSchedule.Error() {
+		t.Errorf("error = %+v, want bad_request with the validation message", resp)
+	}
 }
 
 func TestHandleCronJobs_Create_MissingChannel(t *testing.T) {
@@ -211,7 +225,7 @@ func TestHandleCronJobs_Create_MissingChannel(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for missing channel, got %d", w.Code)
 	}
@@ -223,7 +237,7 @@ func TestHandleCronJobByUUID_Get(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -236,7 +250,7 @@ func TestHandleCronJobByUUID_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/ghost", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
@@ -251,7 +265,7 @@ func TestHandleCronJobByUUID_Update(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -287,7 +301,7 @@ func TestHandleCronJobByUUID_Update_RejectsLegacyModeAndDescription(t *testing.T
 			req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader([]byte(tc.body)))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-			h.handleCronJobByUUID(w, req)
+			serveAPI(h, w, req)
 			if w.Code != http.StatusBadRequest {
 				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
 			}
@@ -304,7 +318,7 @@ func TestHandleCronJobByUUID_Delete(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("expected 204, got %d", w.Code)
@@ -317,7 +331,7 @@ func TestHandleCronJobByUUID_RunNow(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs/u1/run", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusAccepted {
 		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
@@ -333,7 +347,7 @@ func TestHandleCronJobByUUID_RunNow_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs/ghost/run", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
@@ -346,7 +360,7 @@ func TestHandleCronJobByUUID_RunNow_WrongMethod(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/u1/run", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -360,10 +374,17 @@ func TestHandleCronJobs_Create_InternalErrorSurface(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500 for wrapped DB error, got %d", w.Code)
 	}
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != api.CodeInternal || resp.Message != "Failed to create cron job" {
+		t.Errorf("error = %+v, want internal_error without the DB error text", resp)
+	}
 }
 
 // TestHandleCronJobs_Create_ToolErrorSurfaceAs500 pins that DB errors wrapped
@@ -384,7 +405,7 @@ func TestHandleCronJobs_Create_ToolErrorSurfaceAs500(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-			h.handleCronJobs(w, req)
+			serveAPI(h, w, req)
 			if w.Code != http.StatusInternalServerError {
 				t.Fatalf("expected 500, got %d", w.Code)
 			}
@@ -402,7 +423,7 @@ func TestHandleCronJobs_Update_ToolErrorSurfaceAs500(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500, got %d", w.Code)
 	}
@@ -417,7 +438,7 @@ func TestHandleCronJobs_List_ServiceError(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500, got %d", w.Code)
 	}
@@ -429,7 +450,7 @@ func TestHandleCronJobs_Create_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader([]byte("not json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -440,7 +461,7 @@ func TestHandleCronJobs_MethodNotAllowed(t *testing.T) {
 	h := newHandlerWithCronManager(&mockCronJobManager{})
 	req := httptest.NewRequest(http.MethodPatch, "/api/cron-jobs", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -452,7 +473,7 @@ func TestHandleCronJobByUUID_MethodNotAllowed(t *testing.T) {
 	h := newHandlerWithCronManager(&mockCronJobManager{jobs: []database.CronJob{{UUID: "u1"}}})
 	req := httptest.NewRequest(http.MethodPatch, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -464,21 +485,21 @@ func TestHandleCronJobByUUID_UnknownSubpath(t *testing.T) {
 	h := newHandlerWithCronManager(&mockCronJobManager{})
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs/u1/halt", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
-// TestHandleCronJobByUUID_EmptyUUID rejects requests with an empty path
+// TestHandleCronJobByUUID_EmptyUUID finds no route for an empty path
 // segment.
 func TestHandleCronJobByUUID_EmptyUUID(t *testing.T) {
 	h := newHandlerWithCronManager(&mockCronJobManager{})
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d", w.Code)
+	serveAPI(h, w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -488,7 +509,7 @@ func TestHandleCronJobByUUID_Update_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader([]byte("not json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -501,7 +522,7 @@ func TestHandleCronJobByUUID_Delete_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/cron-jobs/ghost", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
@@ -513,7 +534,7 @@ func TestHandleCronJobByUUID_ServiceUnavailable(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("expected 503, got %d", w.Code)
 	}
@@ -552,7 +573,7 @@ func TestHandleCronJobs_Create_WithTools(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -591,7 +612,7 @@ func TestHandleCronJobByUUID_Update_SwapsTools(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -604,6 +625,43 @@ func TestHandleCronJobByUUID_Update_SwapsTools(t *testing.T) {
 	}
 }
 
+func TestHandleCronJobs_Create_PinsSkills(t *testing.T) {
+	mgr := &mockCronJobManager{}
+	h := newHandlerWithCronManager(mgr)
+	h.skillService = &sourceSkillsSkillService{skills: map[string]*database.Skill{
+		"cert-audit":       {Name: "cert-audit", Enabled: true},
+		"incident-manager": {Name: "incident-manager", Enabled: true, IsSystem: true},
+	}}
+
+	post := func(skills []string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(CreateCronJobRequest{Name: "Weekly", Schedule: "0 9 * * 1", Prompt: "Audit certs", SkillNames: skills})
+		req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		serveAPI(h, w, req)
+		return w
+	}
+
+	if w := post([]string{"incident-manager"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("system skill: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(mgr.jobs) != 0 {
+		t.Fatalf("rejected skills must not create the job, got %d", len(mgr.jobs))
+	}
+
+	w := post([]string{" cert-audit ", "cert-audit"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp cronJobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(resp.SkillNames, []string{"cert-audit"}) {
+		t.Fatalf("skill_names = %v, want [cert-audit]", resp.SkillNames)
+	}
+}
+
 // TestHandleCronJobByUUID_Update_LeavesToolsAloneByDefault verifies that a
 // PUT that omits tool_instance_ids leaves CronJobUpdate.ToolInstanceIDs nil
 // (the runner reads nil as "do not touch the per-cron tool allowlist").
@@ -617,7 +675,7 @@ func TestHandleCronJobByUUID_Update_LeavesToolsAloneByDefault(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/cron-jobs/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -641,7 +699,7 @@ func TestHandleCronJobByUUID_Delete_System(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Fatalf("expected 409 for system cron delete, got %d: %s", w.Code, w.Body.String())
@@ -675,7 +733,7 @@ func TestHandleCronJobs_Create_RejectsLegacyModeAndDescription(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/api/cron-jobs", bytes.NewReader([]byte(tc.body)))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-			h.handleCronJobs(w, req)
+			serveAPI(h, w, req)
 			if w.Code != http.StatusBadRequest {
 				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
 			}
@@ -695,7 +753,7 @@ func TestCronJobResponse_OmitsLegacyFields(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -728,7 +786,7 @@ func TestCronJobResponse_ExposesIsSystemAndTools(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -778,7 +836,7 @@ func TestHandleCronJobs_ListMasksIntegrationCredentials(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/cron-jobs", nil)
 	w := httptest.NewRecorder()
-	h.handleCronJobs(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
 	}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
 
 	"github.com/akmatori/akmatori/docs"
+	"github.com/akmatori/akmatori/internal/api"
+	"gopkg.in/yaml.v3"
 )
 
 // handleOpenAPISpec serves the embedded OpenAPI specification file.
@@ -14,6 +20,54 @@ func (h *APIHandler) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// openAPIJSON is the embedded specification converted to JSON, built on
+// first use.
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	var spec interface{}
+	if err := yaml.Unmarshal(docs.OpenAPISpec, &spec); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonCompatible(spec))
+})
+
+// handleOpenAPIJSON serves the embedded OpenAPI specification as JSON, for
+// client generators that do not read YAML.
+func (h *APIHandler) handleOpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	spec, err := openAPIJSON()
+	if err != nil {
+		api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to load OpenAPI spec")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(spec); err != nil {
+		slog.DebugContext(r.Context(), "failed to write OpenAPI spec", "err", err)
+	}
+}
+
+// jsonCompatible converts YAML mappings with non-string keys, which
+// encoding/json cannot marshal, into string-keyed maps.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonCompatible(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonCompatible(e)
+		}
+		return v
+	}
+	return v
+}
+
 // handleDocs serves the Swagger UI HTML page.
 func (h *APIHandler) handleDocs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -114,7 +114,7 @@ func (h *APIHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := alertBaseQ.Session(&gorm.Session{}).Count(&alertCount).Error; err != nil {
-			slog.Error("events: failed to count alert rows", "err", err)
+			slog.ErrorContext(r.Context(), "events: failed to count alert rows", "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to fetch events")
 			return
 		}
@@ -139,7 +139,7 @@ func (h *APIHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
 		}
 		var aRows []alertRow
 		if err := alertQ.Scan(&aRows).Error; err != nil {
-			slog.Warn("events: failed to scan alert rows", "err", err)
+			slog.WarnContext(r.Context(), "events: failed to scan alert rows", "err", err)
 		}
 		for _, a := range aRows {
 			alertItems = append(alertItems, EventFeedItem{
@@ -187,7 +187,7 @@ func (h *APIHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := incBaseQ.Session(&gorm.Session{}).Count(&incidentCount).Error; err != nil {
-			slog.Error("events: failed to count incident rows", "err", err)
+			slog.ErrorContext(r.Context(), "events: failed to count incident rows", "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to fetch events")
 			return
 		}
@@ -207,7 +207,7 @@ func (h *APIHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
 		}
 		var iRows []incRow
 		if err := incQ.Scan(&iRows).Error; err != nil {
-			slog.Warn("events: failed to scan incident rows", "err", err)
+			slog.WarnContext(r.Context(), "events: failed to scan incident rows", "err", err)
 		}
 		for _, inc := range iRows {
 			eventType := inc.SourceKind
@@ -280,7 +280,7 @@ func (h *APIHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
 			Select("uuid, title, status").
 			Where("uuid IN ?", uuids).
 			Scan(&summaries).Error; err != nil {
-			slog.Warn("events: failed to batch-fetch incident summaries", "err", err)
+			slog.WarnContext(r.Context(), "events: failed to batch-fetch incident summaries", "err", err)
 		}
 		incMap := make(map[string]incSummary, len(summaries))
 		for _, s := range summaries {
@@ -329,7 +329,7 @@ func (h *APIHandler) handleEventRaw(w http.ResponseWriter, r *http.Request) {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				api.RespondError(w, http.StatusNotFound, "Event not found")
 			} else {
-				slog.Error("events raw: failed to load alert", "uuid", eventUUID, "err", err)
+				slog.ErrorContext(r.Context(), "events raw: failed to load alert", "uuid", eventUUID, "err", err)
 				api.RespondError(w, http.StatusInternalServerError, "Failed to load event")
 			}
 			return
@@ -346,7 +346,7 @@ func (h *APIHandler) handleEventRaw(w http.ResponseWriter, r *http.Request) {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				api.RespondError(w, http.StatusNotFound, "Event not found")
 			} else {
-				slog.Error("events raw: failed to load incident", "uuid", eventUUID, "err", err)
+				slog.ErrorContext(r.Context(), "events raw: failed to load incident", "uuid", eventUUID, "err", err)
 				api.RespondError(w, http.StatusInternalServerError, "Failed to load event")
 			}
 			return
@@ -88,7 +88,7 @@ func doEventsRequest(t *testing.T, query string) ([]map[string]any, api.Paginati
 // TestHandleEvents_MergedOrderedByOccurredAt verifies that alert and cron rows
 // are merged and returned in occurred_at DESC order.
 func TestHandleEvents_MergedOrderedByOccurredAt(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	now := time.Now().UTC().Truncate(time.Second)
 	older := now.Add(-2 * time.Hour)
@@ -121,7 +121,7 @@ func TestHandleEvents_MergedOrderedByOccurredAt(t *testing.T) {
 
 // TestHandleEvents_TypeFilterAlert verifies that ?type=alert returns only alert rows.
 func TestHandleEvents_TypeFilterAlert(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	now := time.Now().UTC()
 
@@ -143,7 +143,7 @@ func TestHandleEvents_TypeFilterAlert(t *testing.T) {
 
 // TestHandleEvents_TypeFilterCron verifies that ?type=cron returns only cron incident rows.
 func TestHandleEvents_TypeFilterCron(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	now := time.Now().UTC()
 
@@ -163,7 +163,7 @@ func TestHandleEvents_TypeFilterCron(t *testing.T) {
 // TestHandleEvents_DeepPageReturns400 verifies that requesting a page whose offset
 // exceeds eventsMaxRowFetch (10 000) returns 400 rather than silent empty data.
 func TestHandleEvents_DeepPageReturns400(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	mux := http.NewServeMux()
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
@@ -181,7 +181,7 @@ func TestHandleEvents_DeepPageReturns400(t *testing.T) {
 
 // TestHandleEvents_Pagination verifies that page/per_page work correctly.
 func TestHandleEvents_Pagination(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	now := time.Now().UTC()
 
@@ -213,7 +213,7 @@ func TestHandleEvents_Pagination(t *testing.T) {
 // UUID prefixes match alert and incident events; title substrings match too;
 // non-matching terms return an empty page.
 func TestHandleEvents_SearchByUUIDPrefixAndTitle(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	now := time.Now().UTC()
 	alertIncUUID := seedEventIncident(t, database.IncidentSourceKindAlert, now, database.IncidentStatusRunning)
@@ -258,14 +258,17 @@ func TestHandleEvents_SearchByUUIDPrefixAndTitle(t *testing.T) {
 // endpoint returns title/status/response without the heavy fields, and 404s
 // for unknown incidents.
 func TestHandleIncidentResponse_LightweightProjection(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	db := database.GetDB()
 	incUUID := seedEventIncident(t, database.IncidentSourceKindAlert, time.Now().UTC(), database.IncidentStatusCompleted)
 	if err := db.Model(&database.Incident{}).Where("uuid = ?", incUUID).
-		Updates(map[string]interface{}{"response": "root cause: bad deploy", "full_log": "huge log"}).Error; err != nil {
+		Update("response", "root cause: bad deploy").Error; err != nil {
 		t.Fatalf("set response: %v", err)
 	}
+	if err := database.ReplaceIncidentLog(db, incUUID, "huge log"); err != nil {
+		t.Fatalf("set log: %v", err)
+	}
 
 	mux := http.NewServeMux()
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
@@ -31,147 +31,141 @@ var validFormattingSourceKinds = map[string]bool{
 	database.IncidentSourceKindProposal:     true,
 }
 
-// handleFormattingRules handles GET (ordered list) and POST (create) on
-// /api/formatting-rules.
-func (h *APIHandler) handleFormattingRules(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		rules, err := database.ListFormattingRules()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list formatting rules")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, rules)
+// listFormattingRules handles GET /api/formatting-rules.
+func (h *APIHandler) listFormattingRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := database.ListFormattingRules()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list formatting rules")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rules)
+}
 
-	case http.MethodPost:
-		var req api.CreateFormattingRuleRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+// createFormattingRule handles POST /api/formatting-rules.
+func (h *APIHandler) createFormattingRule(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateFormattingRuleRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		rule := database.FormattingRule{
-			UUID:                uuid.New().String(),
-			Name:                strings.TrimSpace(req.Name),
-			Enabled:             true,
-			MatchSourceKind:     strings.TrimSpace(req.MatchSourceKind),
-			MatchSourceUUID:     strings.TrimSpace(req.MatchSourceUUID),
-			MatchChannelUUID:    strings.TrimSpace(req.MatchChannelUUID),
-			MatchLastSkill:      strings.TrimSpace(req.MatchLastSkill),
-			MatchExpression:     strings.TrimSpace(req.MatchExpression),
-			SystemPrompt:        req.SystemPrompt,
-			OutputSchemaExample: req.OutputSchemaExample,
-			MaxTokens:           1500,
-			Temperature:         0.2,
-		}
-		if req.Enabled != nil {
-			rule.Enabled = *req.Enabled
-		}
-		if req.MaxTokens != nil {
-			rule.MaxTokens = *req.MaxTokens
-		}
-		if req.Temperature != nil {
-			rule.Temperature = *req.Temperature
-		}
-		if msg := validateFormattingRule(&rule); msg != "" {
-			api.RespondError(w, http.StatusBadRequest, msg)
-			return
-		}
+	rule := database.FormattingRule{
+		UUID:                uuid.New().String(),
+		Name:                strings.TrimSpace(req.Name),
+		Enabled:             true,
+		MatchSourceKind:     strings.TrimSpace(req.MatchSourceKind),
+		MatchSourceUUID:     strings.TrimSpace(req.MatchSourceUUID),
+		MatchChannelUUID:    strings.TrimSpace(req.MatchChannelUUID),
+		MatchLastSkill:      strings.TrimSpace(req.MatchLastSkill),
+		MatchExpression:     strings.TrimSpace(req.MatchExpression),
+		SystemPrompt:        req.SystemPrompt,
+		OutputSchemaExample: req.OutputSchemaExample,
+		MaxTokens:           1500,
+		Temperature:         0.2,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.MaxTokens != nil {
+		rule.MaxTokens = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		rule.Temperature = *req.Temperature
+	}
+	if msg := validateFormattingRule(&rule); msg != "" {
+		api.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
 
-		if err := database.DB.Transaction(func(tx *gorm.DB) error {
-			var maxPos *int
-			if err := tx.Model(&database.FormattingRule{}).
-				Select("MAX(position)").Scan(&maxPos).Error; err != nil {
-				return err
-			}
-			if maxPos != nil {
-				rule.Position = *maxPos + 1
-			}
-			return tx.Create(&rule).Error
-		}); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to create formatting rule")
-			return
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var maxPos *int
+		if err := tx.Model(&database.FormattingRule{}).
+			Select("MAX(position)").Scan(&maxPos).Error; err != nil {
+			return err
 		}
-		api.RespondJSON(w, http.StatusCreated, rule)
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		if maxPos != nil {
+			rule.Position = *maxPos + 1
+		}
+		return tx.Create(&rule).Error
+	}); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to create formatting rule")
+		return
 	}
+	api.RespondJSON(w, http.StatusCreated, rule)
 }
 
-// handleFormattingRuleByUUID handles PUT (partial update) and DELETE on
-// /api/formatting-rules/{uuid}.
-func (h *APIHandler) handleFormattingRuleByUUID(w http.ResponseWriter, r *http.Request) {
-	ruleUUID := r.PathValue("uuid")
-
+// updateFormattingRule handles PUT /api/formatting-rules/{uuid}.
+func (h *APIHandler) updateFormattingRule(w http.ResponseWriter, r *http.Request) {
 	var rule database.FormattingRule
-	if err := database.DB.Where("uuid = ?", ruleUUID).First(&rule).Error; err != nil {
+	if err := database.DB.Where("uuid = ?", r.PathValue("uuid")).First(&rule).Error; err != nil {
 		api.RespondError(w, http.StatusNotFound, "Formatting rule not found")
 		return
 	}
 
-	switch r.Method {
-	case http.MethodPut:
-		var req api.UpdateFormattingRuleRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	var req api.UpdateFormattingRuleRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		if req.Name != nil {
-			rule.Name = strings.TrimSpace(*req.Name)
-		}
-		if req.Enabled != nil {
-			rule.Enabled = *req.Enabled
-		}
-		if req.MatchSourceKind != nil {
-			rule.MatchSourceKind = strings.TrimSpace(*req.MatchSourceKind)
-		}
-		if req.MatchSourceUUID != nil {
-			rule.MatchSourceUUID = strings.TrimSpace(*req.MatchSourceUUID)
-		}
-		if req.MatchChannelUUID != nil {
-			rule.MatchChannelUUID = strings.TrimSpace(*req.MatchChannelUUID)
-		}
-		if req.MatchLastSkill != nil {
-			rule.MatchLastSkill = strings.TrimSpace(*req.MatchLastSkill)
-		}
-		if req.MatchExpression != nil {
-			rule.MatchExpression = strings.TrimSpace(*req.MatchExpression)
-		}
-		if req.SystemPrompt != nil {
-			rule.SystemPrompt = *req.SystemPrompt
-		}
-		if req.OutputSchemaExample != nil {
-			rule.OutputSchemaExample = *req.OutputSchemaExample
-		}
-		if req.MaxTokens != nil {
-			rule.MaxTokens = *req.MaxTokens
-		}
-		if req.Temperature != nil {
-			rule.Temperature = *req.Temperature
-		}
-		if msg := validateFormattingRule(&rule); msg != "" {
-			api.RespondError(w, http.StatusBadRequest, msg)
-			return
-		}
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.MatchSourceKind != nil {
+		rule.MatchSourceKind = strings.TrimSpace(*req.MatchSourceKind)
+	}
+	if req.MatchSourceUUID != nil {
+		rule.MatchSourceUUID = strings.TrimSpace(*req.MatchSourceUUID)
+	}
+	if req.MatchChannelUUID != nil {
+		rule.MatchChannelUUID = strings.TrimSpace(*req.MatchChannelUUID)
+	}
+	if req.MatchLastSkill != nil {
+		rule.MatchLastSkill = strings.TrimSpace(*req.MatchLastSkill)
+	}
+	if req.MatchExpression != nil {
+		rule.MatchExpression = strings.TrimSpace(*req.MatchExpression)
+	}
+	if req.SystemPrompt != nil {
+		rule.SystemPrompt = *req.SystemPrompt
+	}
+	if req.OutputSchemaExample != nil {
+		rule.OutputSchemaExample = *req.OutputSchemaExample
+	}
+	if req.MaxTokens != nil {
+		rule.MaxTokens = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		rule.Temperature = *req.Temperature
+	}
+	if msg := validateFormattingRule(&rule); msg != "" {
+		api.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
 
-		if err := database.DB.Save(&rule).Error; err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to update formatting rule")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, rule)
+	if err := database.DB.Save(&rule).Error; err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update formatting rule")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rule)
+}
 
-	case http.MethodDelete:
-		if err := database.DB.Delete(&rule).Error; err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to delete formatting rule")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+// deleteFormattingRule handles DELETE /api/formatting-rules/{uuid}.
+func (h *APIHandler) deleteFormattingRule(w http.ResponseWriter, r *http.Request) {
+	var rule database.FormattingRule
+	if err := database.DB.Where("uuid = ?", r.PathValue("uuid")).First(&rule).Error; err != nil {
+		api.RespondError(w, http.StatusNotFound, "Formatting rule not found")
+		return
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := database.DB.Delete(&rule).Error; err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to delete formatting rule")
+		return
 	}
+	api.RespondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
 // handleFormattingRulesReorder handles PUT /api/formatting-rules/reorder.
@@ -180,7 +174,7 @@ func (h *APIHandler) handleFormattingRuleByUUID(w http.ResponseWriter, r *http.R
 func (h *APIHandler) handleFormattingRulesReorder(w http.ResponseWriter, r *http.Request) {
 	var req api.ReorderFormattingRulesRequest
 	if err := api.DecodeJSON(r, &req); err != nil {
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondDecodeError(w, err)
 		return
 	}
 
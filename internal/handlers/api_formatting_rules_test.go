@@ -31,12 +31,7 @@ func setupFormattingRulesTestDB(t *testing.T) {
 }
 
 func formattingRulesMux(h *APIHandler) *http.ServeMux {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/formatting-rules", h.handleFormattingRules)
-	mux.HandleFunc("PUT /api/formatting-rules/reorder", h.handleFormattingRulesReorder)
-	mux.HandleFunc("PUT /api/formatting-rules/{uuid}", h.handleFormattingRuleByUUID)
-	mux.HandleFunc("DELETE /api/formatting-rules/{uuid}", h.handleFormattingRuleByUUID)
-	return mux
+	return apiRoutes(h)
 }
 
 func createRuleViaAPI(t *testing.T, mux *http.ServeMux, body string) database.FormattingRule {
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
@@ -29,143 +29,145 @@ type UpdateHTTPConnectorRequest struct {
 	Enabled      *bool           `json:"enabled"`
 }
 
-// handleHTTPConnectors handles GET /api/http-connectors and POST /api/http-connectors
-func (h *APIHandler) handleHTTPConnectors(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		connectors, err := h.httpConnectorService.ListHTTPConnectors()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list HTTP connectors")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, connectors)
+// listHTTPConnectors handles GET /api/http-connectors
+func (h *APIHandler) listHTTPConnectors(w http.ResponseWriter, r *http.Request) {
+	connectors, err := h.httpConnectorService.ListHTTPConnectors()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list HTTP connectors")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, connectors)
+}
 
-	case http.MethodPost:
-		var req CreateHTTPConnectorRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "Invalid request body")
-			return
-		}
+// createHTTPConnector handles POST /api/http-connectors
+func (h *APIHandler) createHTTPConnector(w http.ResponseWriter, r *http.Request) {
+	var req CreateHTTPConnectorRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		if req.ToolTypeName == "" {
-			api.RespondError(w, http.StatusBadRequest, "tool_type_name is required")
-			return
-		}
-		if req.BaseURLField == "" {
-			api.RespondError(w, http.StatusBadRequest, "base_url_field is required")
-			return
-		}
+	if req.ToolTypeName == "" {
+		api.RespondError(w, http.StatusBadRequest, "tool_type_name is required")
+		return
+	}
+	if req.BaseURLField == "" {
+		api.RespondError(w, http.StatusBadRequest, "base_url_field is required")
+		return
+	}
 
-		connector := &database.HTTPConnector{
-			ToolTypeName: req.ToolTypeName,
-			Description:  req.Description,
-			BaseURLField: req.BaseURLField,
-			AuthConfig:   req.AuthConfig,
-			Tools:        req.Tools,
-		}
+	connector := &database.HTTPConnector{
+		ToolTypeName: req.ToolTypeName,
+		Description:  req.Description,
+		BaseURLField: req.BaseURLField,
+		AuthConfig:   req.AuthConfig,
+		Tools:        req.Tools,
+	}
 
-		result, err := h.httpConnectorService.CreateHTTPConnector(connector)
-		if err != nil {
-			if containsString(err.Error(), "already exists") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else if containsString(err.Error(), "validation failed") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else if containsString(err.Error(), "conflicts with") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to create HTTP connector")
-			}
-			return
+	result, err := h.httpConnectorService.CreateHTTPConnector(connector)
+	if err != nil {
+		if containsString(err.Error(), "already exists") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else if containsString(err.Error(), "validation failed") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else if containsString(err.Error(), "conflicts with") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to create HTTP connector")
 		}
+		return
+	}
 
-		h.triggerGatewayReload()
-		api.RespondJSON(w, http.StatusCreated, result)
+	h.triggerGatewayReload()
+	api.RespondJSON(w, http.StatusCreated, result)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// getHTTPConnector handles GET /api/http-connectors/{id}
+func (h *APIHandler) getHTTPConnector(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "connector")
+	if !ok {
+		return
 	}
-}
 
-// handleHTTPConnectorByID handles GET/PUT/DELETE /api/http-connectors/:id
-func (h *APIHandler) handleHTTPConnectorByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/api/http-connectors/"):]
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	connector, err := h.httpConnectorService.GetHTTPConnector(id)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, "Invalid connector ID")
+		api.RespondError(w, http.StatusNotFound, "HTTP connector not found")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, connector)
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		connector, err := h.httpConnectorService.GetHTTPConnector(uint(id))
-		if err != nil {
-			api.RespondError(w, http.StatusNotFound, "HTTP connector not found")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, connector)
+// updateHTTPConnector handles PUT /api/http-connectors/{id}
+func (h *APIHandler) updateHTTPConnector(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "connector")
+	if !ok {
+		return
+	}
 
-	case http.MethodPut:
-		var req UpdateHTTPConnectorRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "Invalid request body")
-			return
-		}
+	var req UpdateHTTPConnectorRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		updates := make(map[string]interface{})
-		if req.ToolTypeName != nil {
-			updates["tool_type_name"] = *req.ToolTypeName
-		}
-		if req.Description != nil {
-			updates["description"] = *req.Description
-		}
-		if req.BaseURLField != nil {
-			updates["base_url_field"] = *req.BaseURLField
-		}
-		if req.AuthConfig != nil {
-			updates["auth_config"] = database.JSONB(*req.AuthConfig)
-		}
-		if req.Tools != nil {
-			updates["tools"] = database.JSONB(*req.Tools)
-		}
-		if req.Enabled != nil {
-			updates["enabled"] = *req.Enabled
-		}
+	updates := make(map[string]interface{})
+	if req.ToolTypeName != nil {
+		updates["tool_type_name"] = *req.ToolTypeName
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.BaseURLField != nil {
+		updates["base_url_field"] = *req.BaseURLField
+	}
+	if req.AuthConfig != nil {
+		updates["auth_config"] = database.JSONB(*req.AuthConfig)
+	}
+	if req.Tools != nil {
+		updates["tools"] = database.JSONB(*req.Tools)
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
 
-		connector, err := h.httpConnectorService.UpdateHTTPConnector(uint(id), updates)
-		if err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "already exists") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else if containsString(err.Error(), "validation failed") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else if containsString(err.Error(), "conflicts with") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update HTTP connector")
-			}
-			return
+	connector, err := h.httpConnectorService.UpdateHTTPConnector(id, updates)
+	if err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "already exists") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else if containsString(err.Error(), "validation failed") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else if containsString(err.Error(), "conflicts with") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update HTTP connector")
 		}
+		return
+	}
 
-		h.triggerGatewayReload()
-		api.RespondJSON(w, http.StatusOK, connector)
-
-	case http.MethodDelete:
-		if err := h.httpConnectorService.DeleteHTTPConnector(uint(id)); err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to delete HTTP connector")
-			}
-			return
-		}
+	h.triggerGatewayReload()
+	api.RespondJSON(w, http.StatusOK, connector)
+}
 
-		h.triggerGatewayReload()
-		api.RespondNoContent(w)
+// deleteHTTPConnector handles DELETE /api/http-connectors/{id}
+func (h *APIHandler) deleteHTTPConnector(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "connector")
+	if !ok {
+		return
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.httpConnectorService.DeleteHTTPConnector(id); err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to delete HTTP connector")
+		}
+		return
 	}
+
+	h.triggerGatewayReload()
+	api.RespondNoContent(w)
 }
 
 // triggerGatewayReload calls the MCP Gateway reload endpoint asynchronously
@@ -179,32 +181,48 @@ func (h *APIHandler) triggerGatewayReload() {
 	}
 }
 
+// gatewayReloadBackoff is the wait before each retry of a gateway reload.
+// The gateway may still be starting (or restarting) when the API saves a
+// connector, so a refused connection or 5xx is retried a few times.
+var gatewayReloadBackoff = []time.Duration{time.Second, 3 * time.Second, 10 * time.Second}
+
 // GatewayReloadFunc creates a function that triggers the MCP Gateway HTTP connector reload
 func GatewayReloadFunc(gatewayURL string) func() error {
 	return func() error {
-		resp, err := http.Post(gatewayURL+"/reload/http-connectors", "application/json", nil)
-		if err != nil {
-			return fmt.Errorf("gateway reload request failed: %w", err)
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("gateway reload returned status %d", resp.StatusCode)
-		}
-		return nil
+		return postGatewayReload(gatewayURL+"/reload/http-connectors", "gateway reload")
 	}
 }
 
 // GatewayMCPReloadFunc creates a function that triggers the MCP Gateway MCP server proxy reload
 func GatewayMCPReloadFunc(gatewayURL string) func() error {
 	return func() error {
-		resp, err := http.Post(gatewayURL+"/reload/mcp-servers", "application/json", nil)
-		if err != nil {
-			return fmt.Errorf("gateway MCP reload request failed: %w", err)
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("gateway MCP reload returned status %d", resp.StatusCode)
+		return postGatewayReload(gatewayURL+"/reload/mcp-servers", "gateway MCP reload")
+	}
+}
+
+// postGatewayReload POSTs to a gateway reload endpoint, retrying transport
+// errors and 5xx responses per gatewayReloadBackoff.
+func postGatewayReload(url, what string) error {
+	for attempt := 0; ; attempt++ {
+		retryable, err := postGatewayReloadOnce(url, what)
+		if err == nil || !retryable || attempt >= len(gatewayReloadBackoff) {
+			return err
 		}
-		return nil
+		slog.Warn("gateway reload failed, retrying", "url", url, "attempt", attempt+1, "error", err)
+		time.Sleep(gatewayReloadBackoff[attempt])
+	}
+}
+
+// postGatewayReloadOnce makes a single reload request. 4xx responses are
+// not retryable: the gateway is up and a retry won't change its answer.
+func postGatewayReloadOnce(url, what string) (retryable bool, err error) {
+	resp, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		return true, fmt.Errorf("%s request failed: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode >= 500, fmt.Errorf("%s returned status %d", what, resp.StatusCode)
 	}
+	return false, nil
 }
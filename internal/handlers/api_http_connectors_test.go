@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -98,7 +99,7 @@ func TestHandleHTTPConnectors_List(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/http-connectors", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", w.Code)
@@ -126,7 +127,7 @@ func TestHandleHTTPConnectors_ListError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/http-connectors", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", w.Code)
@@ -159,7 +160,7 @@ func TestHandleHTTPConnectors_Create(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Errorf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -202,7 +203,7 @@ func TestHandleHTTPConnectors_Create_MissingFields(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			h.handleHTTPConnectors(w, req)
+			serveAPI(h, w, req)
 
 			if w.Code != http.StatusBadRequest {
 				t.Errorf("expected 400, got %d", w.Code)
@@ -228,7 +229,7 @@ func TestHandleHTTPConnectors_Create_Conflict(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Errorf("expected 409, got %d", w.Code)
@@ -242,7 +243,7 @@ func TestHandleHTTPConnectors_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/http-connectors", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -260,7 +261,7 @@ func TestHandleHTTPConnectorByID_Get(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/http-connectors/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", w.Code)
@@ -285,7 +286,7 @@ func TestHandleHTTPConnectorByID_GetNotFound(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/http-connectors/999", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -299,7 +300,7 @@ func TestHandleHTTPConnectorByID_InvalidID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/http-connectors/abc", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
@@ -324,7 +325,7 @@ func TestHandleHTTPConnectorByID_Update(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -346,7 +347,7 @@ func TestHandleHTTPConnectorByID_UpdateNotFound(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -361,7 +362,7 @@ func TestHandleHTTPConnectorByID_Delete(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/http-connectors/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNoContent {
 		t.Errorf("expected 204, got %d", w.Code)
@@ -378,7 +379,7 @@ func TestHandleHTTPConnectorByID_DeleteNotFound(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/http-connectors/999", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -392,7 +393,7 @@ func TestHandleHTTPConnectorByID_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPatch, "/api/http-connectors/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectorByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -453,6 +454,7 @@ func TestGatewayReloadFunc(t *testing.T) {
 
 // TestGatewayReloadFunc_Error tests the reload function with server error
 func TestGatewayReloadFunc_Error(t *testing.T) {
+	withFastGatewayBackoff(t)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
@@ -465,6 +467,52 @@ func TestGatewayReloadFunc_Error(t *testing.T) {
 	}
 }
 
+func withFastGatewayBackoff(t *testing.T) {
+	t.Helper()
+	orig := gatewayReloadBackoff
+	gatewayReloadBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() { gatewayReloadBackoff = orig })
+}
+
+// TestGatewayReloadFunc_RetriesUntilGatewayUp covers a gateway that is still
+// starting when the first reload is sent.
+func TestGatewayReloadFunc_RetriesUntilGatewayUp(t *testing.T) {
+	withFastGatewayBackoff(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := GatewayMCPReloadFunc(server.URL)(); err != nil {
+		t.Fatalf("expected reload to succeed after retries, got %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestGatewayReloadFunc_ClientErrorNotRetried(t *testing.T) {
+	withFastGatewayBackoff(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := GatewayReloadFunc(server.URL)(); err == nil {
+		t.Error("expected error for 404 response")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 (4xx is not retried)", got)
+	}
+}
+
 // TestHandleHTTPConnectors_CreateValidationError tests create with validation failure
 func TestHandleHTTPConnectors_CreateValidationError(t *testing.T) {
 	mock := &mockHTTPConnectorService{
@@ -482,7 +530,7 @@ func TestHandleHTTPConnectors_CreateValidationError(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleHTTPConnectors(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
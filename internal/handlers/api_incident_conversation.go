@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// conversationExportBatchSize bounds how many incidents (and their full
+// logs) the bulk export holds in memory at once.
+const conversationExportBatchSize = 100
+
+// handleIncidentConversation handles GET /api/incidents/{uuid}/conversation.
+// It returns the incident as one OpenAI fine-tuning conversation. ?system=
+// overrides the default system turn, ?strip_emoji=true removes emoji from
+// every turn and ?anonymize=true replaces hostnames, IPs and usernames with
+// pseudonyms.
+func (h *APIHandler) handleIncidentConversation(w http.ResponseWriter, r *http.Request) {
+	stripEmoji, err := parseStripEmoji(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	anonymize, err := parseAnonymize(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var incident database.Incident
+	err = database.GetDB().Where("uuid = ?", r.PathValue("uuid")).First(&incident).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+	if err == nil {
+		incident.FullLog, err = database.LoadIncidentLog(database.GetDB(), incident.UUID)
+	}
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get incident")
+		return
+	}
+
+	conv, err := services.BuildIncidentConversation(&incident, r.URL.Query().Get("system"))
+	if errors.Is(err, services.ErrNoConversation) {
+		api.RespondError(w, http.StatusConflict, "Incident has no finished investigation to export")
+		return
+	}
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to build conversation")
+		return
+	}
+	if stripEmoji {
+		stripConversationEmoji(conv)
+	}
+	if anonymize {
+		anonymizer := utils.NewAnonymizer()
+		if err := seedIncidentAnonymizer(anonymizer, incident.UUID); err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to load incident alerts")
+			return
+		}
+		services.AnonymizeConversation(anonymizer, conv)
+	}
+	api.RespondJSON(w, http.StatusOK, conv)
+}
+
+// handleIncidentConversationsExport handles GET /api/incidents/conversations.
+// It streams every exportable incident matching the GET /api/incidents
+// filters as a JSONL file in the OpenAI chat fine-tuning format, oldest
+// first. Incidents without a finished exchange are skipped. Accepts the same
+// ?system=, ?strip_emoji= and ?anonymize= options as the single-incident
+// export; pseudonyms are consistent across the whole file.
+func (h *APIHandler) handleIncidentConversationsExport(w http.ResponseWriter, r *http.Request) {
+	stripEmoji, err := parseStripEmoji(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var anonymizer *utils.Anonymizer
+	if anonymize, err := parseAnonymize(r); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if anonymize {
+		anonymizer = utils.NewAnonymizer()
+	}
+	systemPrompt := r.URL.Query().Get("system")
+	query := applyIncidentListFilters(database.GetDB().Model(&database.Incident{}), r).
+		Where("status NOT IN ?", []database.IncidentStatus{
+			database.IncidentStatusPending, database.IncidentStatusRunning, database.IncidentStatusFailed,
+		}).
+		Order("created_at ASC, id ASC")
+
+	w.Header().Set("Content-Type", "application/jsonl")
+	w.Header().Set("Content-Disposition", `attachment; filename="incident-conversations.jsonl"`)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	exported := 0
+	var batch []database.Incident
+	err = query.FindInBatches(&batch, conversationExportBatchSize, func(tx *gorm.DB, _ int) error {
+		if anonymizer != nil {
+			uuids := make([]string, len(batch))
+			for i := range batch {
+				uuids[i] = batch[i].UUID
+			}
+			if err := seedIncidentAnonymizer(anonymizer, uuids...); err != nil {
+				return err
+			}
+		}
+		for i := range batch {
+			fullLog, err := database.LoadIncidentLog(database.GetDB(), batch[i].UUID)
+			if err != nil {
+				return err
+			}
+			batch[i].FullLog = fullLog
+			conv, err := services.BuildIncidentConversation(&batch[i], systemPrompt)
+			if err != nil {
+				continue
+			}
+			if stripEmoji {
+				stripConversationEmoji(conv)
+			}
+			if anonymizer != nil {
+				services.AnonymizeConversation(anonymizer, conv)
+			}
+			if err := enc.Encode(conv); err != nil {
+				return err
+			}
+			exported++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		// Headers are already out once the first line is written, so a
+		// failure can only truncate the file.
+		slog.ErrorContext(r.Context(), "conversation export failed", "exported", exported, "err", err)
+		if exported == 0 {
+			w.Header().Del("Content-Disposition")
+			api.RespondError(w, http.StatusInternalServerError, "Failed to export conversations")
+		}
+		return
+	}
+	slog.InfoContext(r.Context(), "exported incident conversations", "count", exported)
+}
+
+func stripConversationEmoji(conv *services.IncidentConversation) {
+	for i := range conv.Messages {
+		conv.Messages[i].Content = utils.StripEmoji(conv.Messages[i].Content)
+	}
+}
+
+// seedIncidentAnonymizer registers the hosts and users named by the alerts
+// of the given incidents with a.
+func seedIncidentAnonymizer(a *utils.Anonymizer, incidentUUIDs ...string) error {
+	var alerts []database.Alert
+	if err := database.GetDB().Where("incident_uuid IN ?", incidentUUIDs).Find(&alerts).Error; err != nil {
+		return err
+	}
+	services.SeedAnonymizer(a, alerts)
+	return nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestIncidentConversationExport(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	finished := func(task, answer string) string {
+		return task + "\n\n--- Execution Log ---\n\nworking\n\n--- Final Response ---\n\n" + answer
+	}
+	done := seedFilterIncident(t, database.Incident{Source: "slack",
+		FullLog: finished("📨 Slack Message from User <U1>:\nwhy is api slow?", "DB pool exhausted, password=hunter2")})
+	seedFilterIncident(t, database.Incident{Source: "zabbix", FullLog: finished("Alert Investigation: disk", "Disk full")})
+	running := seedFilterIncident(t, database.Incident{Source: "slack", Status: database.IncidentStatusRunning,
+		FullLog: "task\n\n--- Execution Log ---\n\n"})
+
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/incidents/"+done+"/conversation", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("conversation status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var conv services.IncidentConversation
+	if err := json.Unmarshal(rec.Body.Bytes(), &conv); err != nil {
+		t.Fatal(err)
+	}
+	if len(conv.Messages) != 3 || conv.Messages[2].Content != "DB pool exhausted, password=[REDACTED]" {
+		t.Errorf("conversation = %+v", conv.Messages)
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/"+running+"/conversation", ""); rec.Code != http.StatusConflict {
+		t.Errorf("running incident status = %d", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/missing/conversation", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing incident status = %d", rec.Code)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/incidents/conversations?source=slack&system=be+brief", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/jsonl" {
+		t.Fatalf("export status = %d type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var lines []services.IncidentConversation
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		var line services.IncidentConversation
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	// The zabbix incident is filtered out and the running one is skipped.
+	if len(lines) != 1 || lines[0].Messages[0].Content != "be brief" ||
+		!strings.Contains(lines[0].Messages[1].Content, "why is api slow?") {
+		t.Errorf("export = %+v", lines)
+	}
+}
+
+func TestIncidentExports_Anonymize(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	incident := seedFilterIncident(t, database.Incident{Source: "alertmanager", FullLog: "Alert Investigation: disk full on web-01\n\n" +
+		"--- Execution Log ---\n\nssh alice@web-01 df -h\n\n--- Final Response ---\n\n" +
+		"web-01 (10.0.0.5) filled /var; alice's cron job writes /home/alice/dump.sql"})
+	alert := database.Alert{UUID: "a-anon", IncidentUUID: incident, AlertName: "DiskFull web-01", TargetHost: "web-01",
+		RawPayload: database.JSONB{"labels": map[string]interface{}{"instance": "10.0.0.5:9100", "owner": "alice"}}}
+	if err := database.GetDB().Create(&alert).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/incidents/"+incident+"/conversation?anonymize=true", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("conversation status = %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, leaked := range []string{"web-01", "10.0.0.5", "alice"} {
+		if strings.Contains(body, leaked) {
+			t.Errorf("conversation leaks %q: %s", leaked, body)
+		}
+	}
+	if !strings.Contains(body, "host-1 (192.0.2.1) filled /var; user-1's cron job writes /home/user-1/") {
+		t.Errorf("pseudonyms not consistent: %s", body)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/incidents/"+incident+"/alerts?anonymize=true", "")
+	var alerts []database.Alert
+	if err := json.Unmarshal(rec.Body.Bytes(), &alerts); err != nil || len(alerts) != 1 {
+		t.Fatalf("alerts = %s, %v", rec.Body.String(), err)
+	}
+	labels, _ := alerts[0].RawPayload["labels"].(map[string]interface{})
+	if alerts[0].TargetHost != "host-1" || alerts[0].AlertName != "DiskFull host-1" ||
+		labels["instance"] != "192.0.2.1:9100" || labels["owner"] != "user-1" {
+		t.Errorf("anonymized alert = %+v", alerts[0])
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/"+incident+"/alerts?anonymize=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad flag status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// incidentExportBatchSize bounds how many incidents (and their full logs)
+// the bulk archive holds in memory at once.
+const incidentExportBatchSize = 50
+
+// SetIncidentExporter wires the incident export endpoints. Optional — when
+// unset they return 503.
+func (h *APIHandler) SetIncidentExporter(e services.IncidentExporter) {
+	h.incidentExporter = e
+}
+
+// handleIncidentExport handles GET /api/incidents/{uuid}/export. It returns
+// the incident record, its alerts, timeline, workspace changes, links,
+// postmortem and full log for compliance retention. ?format=zip (default)
+// downloads an archive that also holds the workspace files unless
+// ?workspace=false; ?format=json returns the record with a manifest of the
+// workspace files.
+func (h *APIHandler) handleIncidentExport(w http.ResponseWriter, r *http.Request) {
+	if h.incidentExporter == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Incident export is not configured")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "json" {
+		api.RespondError(w, http.StatusBadRequest, "format must be zip or json")
+		return
+	}
+	workspace, err := parseExportWorkspace(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	export, err := h.incidentExporter.Export(r.Context(), r.PathValue("uuid"))
+	if errors.Is(err, services.ErrIncidentNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "incident export failed", "uuid", r.PathValue("uuid"), "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to export incident")
+		return
+	}
+	if format == "json" {
+		api.RespondJSON(w, http.StatusOK, export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="incident-%s.zip"`, export.Incident.UUID))
+	zw := zip.NewWriter(w)
+	if err := h.incidentExporter.WriteArchive(zw, export, workspace); err != nil {
+		// The archive is already streaming, so a failure can only truncate it.
+		slog.ErrorContext(r.Context(), "incident archive failed", "uuid", export.Incident.UUID, "err", err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "incident archive failed", "uuid", export.Incident.UUID, "err", err)
+	}
+}
+
+// handleIncidentsExport handles GET /api/incidents/export. It streams the
+// export of every incident matching the GET /api/incidents filters, oldest
+// first; ?from= and ?to= select the date range by creation time.
+// ?format=zip (default) writes one "<uuid>/" directory per incident like the
+// single-incident archive, ?format=jsonl one export record per line.
+// Workspace files are included in zip archives unless ?workspace=false.
+func (h *APIHandler) handleIncidentsExport(w http.ResponseWriter, r *http.Request) {
+	if h.incidentExporter == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Incident export is not configured")
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "jsonl" {
+		api.RespondError(w, http.StatusBadRequest, "format must be zip or jsonl")
+		return
+	}
+	for _, name := range []string{"from", "to"} {
+		if v := q.Get(name); v != "" && parseTimeQueryParam(v) == nil {
+			api.RespondError(w, http.StatusBadRequest, name+" must be an RFC3339 timestamp or unix seconds")
+			return
+		}
+	}
+	workspace, err := parseExportWorkspace(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := applyIncidentListFilters(database.GetDB().Model(&database.Incident{}), r).
+		Order("created_at ASC, id ASC")
+
+	var zw *zip.Writer
+	var enc *json.Encoder
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="incidents-export.zip"`)
+		zw = zip.NewWriter(w)
+	} else {
+		w.Header().Set("Content-Type", "application/jsonl")
+		w.Header().Set("Content-Disposition", `attachment; filename="incidents-export.jsonl"`)
+		enc = json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+	}
+
+	exported := 0
+	var batch []database.Incident
+	err = query.FindInBatches(&batch, incidentExportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			export, err := h.incidentExporter.ExportIncident(r.Context(), &batch[i])
+			if err != nil {
+				return fmt.Errorf("incident %s: %w", batch[i].UUID, err)
+			}
+			if zw != nil {
+				err = h.incidentExporter.WriteArchive(zw, export, workspace)
+			} else {
+				err = enc.Encode(export)
+			}
+			if err != nil {
+				return err
+			}
+			exported++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		// Headers are already out once the first incident is written, so a
+		// failure can only truncate the file.
+		slog.ErrorContext(r.Context(), "incident bulk export failed", "exported", exported, "err", err)
+		if exported == 0 {
+			w.Header().Del("Content-Disposition")
+			api.RespondError(w, http.StatusInternalServerError, "Failed to export incidents")
+		}
+		return
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			slog.ErrorContext(r.Context(), "incident bulk export failed", "exported", exported, "err", err)
+			return
+		}
+	}
+	slog.InfoContext(r.Context(), "exported incidents", "count", exported, "format", format)
+}
+
+// parseExportWorkspace reads ?workspace=, which defaults to true.
+func parseExportWorkspace(r *http.Request) (bool, error) {
+	if r.URL.Query().Get("workspace") == "" {
+		return true, nil
+	}
+	return parseBoolQuery(r, "workspace")
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestIncidentExportEndpoints(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{}, &database.IncidentEvent{},
+		&database.IncidentChange{}, &database.IncidentLink{}, &database.IncidentReport{})
+
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/export", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+	h.SetIncidentExporter(services.NewIncidentExportService(db, t.TempDir()))
+
+	old := seedFilterIncident(t, database.Incident{Source: "slack", FullLog: "old log", CreatedAt: time.Now().AddDate(0, 0, -30)})
+	recent := seedFilterIncident(t, database.Incident{Source: "slack", FullLog: "recent log"})
+	db.Create(&database.Alert{UUID: "alert-1", IncidentUUID: recent, AlertName: "HighLatency"})
+
+	rec := serveJSON(mux, http.MethodGet, "/api/incidents/"+recent+"/export?format=json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("json export status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var export services.IncidentExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Incident.UUID != recent || export.Incident.FullLog != "recent log" || len(export.Alerts) != 1 {
+		t.Errorf("json export = %+v", export)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/incidents/"+recent+"/export", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("zip export status = %d type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	if !names[recent+"/incident.json"] || !names[recent+"/full_log.txt"] {
+		t.Errorf("zip entries = %v", names)
+	}
+
+	from := strconv.FormatInt(time.Now().AddDate(0, 0, -7).Unix(), 10)
+	rec = serveJSON(mux, http.MethodGet, "/api/incidents/export?format=jsonl&from="+from, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/jsonl" {
+		t.Fatalf("bulk export status = %d type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var exported []string
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var line services.IncidentExport
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		exported = append(exported, line.Incident.UUID)
+	}
+	if len(exported) != 1 || exported[0] != recent {
+		t.Errorf("bulk export = %v, want only %s (not %s)", exported, recent, old)
+	}
+
+	for path, want := range map[string]int{
+		"/api/incidents/missing/export":                   http.StatusNotFound,
+		"/api/incidents/" + recent + "/export?format=tar": http.StatusBadRequest,
+		"/api/incidents/export?from=yesterday":            http.StatusBadRequest,
+		"/api/incidents/export?workspace=maybe":           http.StatusBadRequest,
+	} {
+		if rec := serveJSON(mux, http.MethodGet, path, ""); rec.Code != want {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
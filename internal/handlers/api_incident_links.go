@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetIncidentLinker wires the service backing /api/incidents/{uuid}/links.
+// Optional — when unset the endpoints return 503 and incident detail omits
+// relations.
+func (h *APIHandler) SetIncidentLinker(svc services.IncidentLinker) {
+	h.incidentLinks = svc
+}
+
+// handleIncidentLinks handles GET and POST /api/incidents/{uuid}/links.
+// GET returns the incident's parent, children and related incidents; POST
+// creates a link and returns the updated relations.
+func (h *APIHandler) handleIncidentLinks(w http.ResponseWriter, r *http.Request) {
+	if h.incidentLinks == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Incident links are not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+
+	if r.Method == http.MethodPost {
+		var req api.CreateIncidentLinkRequest
+		if err := api.DecodeJSON(r, &req); err != nil {
+			api.RespondDecodeError(w, err)
+			return
+		}
+		target := strings.TrimSpace(req.TargetUUID)
+		_, err := h.incidentLinks.LinkIncidents(r.Context(), incidentUUID, target, strings.TrimSpace(req.Kind), database.IncidentLinkOriginManual, req.Reason)
+		if err != nil {
+			respondIncidentLinkError(w, incidentUUID, err)
+			return
+		}
+	}
+
+	relations, err := h.incidentLinks.GetRelations(r.Context(), incidentUUID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "incident links: failed to load relations", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to load incident links")
+		return
+	}
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	api.RespondJSON(w, status, relations)
+}
+
+// handleIncidentUnlink handles DELETE /api/incidents/{uuid}/links/{target}.
+func (h *APIHandler) handleIncidentUnlink(w http.ResponseWriter, r *http.Request) {
+	if h.incidentLinks == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Incident links are not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+	if err := h.incidentLinks.UnlinkIncidents(r.Context(), incidentUUID, r.PathValue("target")); err != nil {
+		respondIncidentLinkError(w, incidentUUID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondIncidentLinkError(w http.ResponseWriter, incidentUUID string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+	case errors.Is(err, services.ErrIncidentLinkNotFound):
+		api.RespondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrIncidentLinkExists),
+		errors.Is(err, services.ErrIncidentHasParent),
+		errors.Is(err, services.ErrIncidentLinkCycle):
+		api.RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrIncidentLinkSelf),
+		errors.Is(err, services.ErrInvalidIncidentLink):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error("incident links: update failed", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update incident links")
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func newIncidentLinksMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.IncidentLink{})
+	for _, uuid := range []string{"inc-parent", "inc-child"} {
+		if err := db.Create(&database.Incident{UUID: uuid, Title: uuid, Status: database.IncidentStatusRunning}).Error; err != nil {
+			t.Fatalf("seed incident: %v", err)
+		}
+	}
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetIncidentLinker(services.NewIncidentLinkService(db))
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	return mux
+}
+
+func TestIncidentLinksAPI(t *testing.T) {
+	mux := newIncidentLinksMux(t)
+
+	rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-child/links",
+		`{"target_uuid":"inc-parent","kind":"parent","reason":"db outage"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var rel database.IncidentRelations
+	if err := json.Unmarshal(rec.Body.Bytes(), &rel); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rel.Parent == nil || rel.Parent.UUID != "inc-parent" || rel.Parent.Origin != database.IncidentLinkOriginManual {
+		t.Fatalf("child relations = %+v", rel)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/incidents/inc-parent/links", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rel = database.IncidentRelations{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &rel); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if rel.Parent != nil || len(rel.Children) != 1 || rel.Children[0].UUID != "inc-child" {
+		t.Fatalf("parent relations = %+v", rel)
+	}
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"target_uuid":"inc-parent","kind":"related"}`, http.StatusConflict},
+		{`{"target_uuid":"inc-missing","kind":"related"}`, http.StatusNotFound},
+		{`{"target_uuid":"inc-parent","kind":"sibling"}`, http.StatusBadRequest},
+		{`{"kind":"related"}`, http.StatusUnprocessableEntity},
+	} {
+		if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-child/links", tc.body); rec.Code != tc.want {
+			t.Errorf("POST %s status = %d, want %d", tc.body, rec.Code, tc.want)
+		}
+	}
+
+	if rec := serveJSON(mux, http.MethodDelete, "/api/incidents/inc-parent/links/inc-child", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodDelete, "/api/incidents/inc-parent/links/inc-child", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestIncidentLinksAPI_NotConfigured(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/x/links", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
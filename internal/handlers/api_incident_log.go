@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxLogTailLines bounds ?lines= on the log endpoints.
+const maxLogTailLines = 10000
+
+// Log chunk sizes for GET /api/incidents/{uuid}/log?offset=, in characters.
+const (
+	defaultLogChunkSize = 256 * 1024
+	maxLogChunkSize     = 4 * 1024 * 1024
+)
+
+// logRenderOptions are the query options shared by the plain-text log and
+// the SSE log tail: ?lines=N keeps only the last N lines of the log (the
+// snapshot, for the stream) and ?strip_emoji=true removes emoji.
+type logRenderOptions struct {
+	lines      int
+	stripEmoji bool
+}
+
+func parseLogRenderOptions(r *http.Request) (logRenderOptions, error) {
+	var opts logRenderOptions
+	q := r.URL.Query()
+	if v := q.Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLogTailLines {
+			return opts, errors.New("lines must be between 1 and " + strconv.Itoa(maxLogTailLines))
+		}
+		opts.lines = n
+	}
+	strip, err := parseStripEmoji(r)
+	opts.stripEmoji = strip
+	return opts, err
+}
+
+// parseStripEmoji reads the ?strip_emoji= flag accepted by the log and
+// export endpoints.
+func parseStripEmoji(r *http.Request) (bool, error) {
+	return parseBoolQuery(r, "strip_emoji")
+}
+
+// parseAnonymize reads the ?anonymize= flag accepted by the log, alert and
+// export endpoints.
+func parseAnonymize(r *http.Request) (bool, error) {
+	return parseBoolQuery(r, "anonymize")
+}
+
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New(name + " must be true or false")
+	}
+	return b, nil
+}
+
+// render applies the options to a log (or log delta). The log is sanitized
+// here as well as on write, for rows stored before sanitization existed.
+func (o logRenderOptions) render(log string) string {
+	log = utils.SanitizeLog(log)
+	if o.stripEmoji {
+		log = utils.StripEmoji(log)
+	}
+	return log
+}
+
+// logTail returns the last n lines of log, or all of it when n is 0.
+func logTail(log string, n int) string {
+	if n <= 0 {
+		return log
+	}
+	end := len(strings.TrimRight(log, "\n"))
+	idx := end
+	for i := 0; i < n; i++ {
+		idx = strings.LastIndexByte(log[:idx], '\n')
+		if idx < 0 {
+			return log
+		}
+	}
+	return log[idx+1:]
+}
+
+// handleIncidentLog handles GET /api/incidents/{uuid}/log: the execution
+// log as plain text, for terminals and log tooling. Pass ?download=true to
+// get it as an attachment and ?anonymize=true to replace hostnames, IPs and
+// usernames with pseudonyms for sharing. With ?offset= it instead returns
+// one chunk of the log as JSON (see handleIncidentLogChunk).
+func (h *APIHandler) handleIncidentLog(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseLogRenderOptions(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	anonymize, err := parseAnonymize(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+	if r.URL.Query().Has("offset") {
+		h.handleIncidentLogChunk(w, r, incidentUUID, opts, anonymize)
+		return
+	}
+	incident, err := h.skillService.GetIncident(incidentUUID)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+	text := opts.render(incident.FullLog)
+	if anonymize {
+		// The whole log, not just the tail, so pseudonyms are numbered the
+		// same way as in chunks and exports.
+		anonymizer := utils.NewAnonymizer()
+		if err := seedIncidentAnonymizer(anonymizer, incidentUUID); err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to load incident alerts")
+			return
+		}
+		text = anonymizer.Text(text)
+	}
+	text = logTail(text, opts.lines)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if download, _ := strconv.ParseBool(r.URL.Query().Get("download")); download {
+		w.Header().Set("Content-Disposition", `attachment; filename="incident-`+incidentUUID+`.log"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(text))
+}
+
+// handleIncidentLogChunk serves ?offset=N[&limit=M]: up to limit characters
+// of the log from offset, so a viewer can load a long log piece by piece
+// and poll a running incident for just the new tail. ?strip_emoji= and
+// ?anonymize= apply to the chunk; offsets always refer to the stored log.
+// Anonymized chunks are served by handleAnonymizedLogChunk.
+func (h *APIHandler) handleIncidentLogChunk(w http.ResponseWriter, r *http.Request, incidentUUID string, opts logRenderOptions, anonymize bool) {
+	q := r.URL.Query()
+	if opts.lines > 0 {
+		api.RespondError(w, http.StatusBadRequest, "offset and lines cannot be combined")
+		return
+	}
+	offset, err := strconv.Atoi(q.Get("offset"))
+	if err != nil || offset < 0 {
+		api.RespondError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+	limit := defaultLogChunkSize
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLogChunkSize {
+			api.RespondError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxLogChunkSize))
+			return
+		}
+	}
+
+	if anonymize {
+		h.handleAnonymizedLogChunk(w, r, incidentUUID, opts, offset, limit)
+		return
+	}
+
+	chunk, size, err := h.skillService.ReadIncidentLog(incidentUUID, offset, limit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read incident log", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to read incident log")
+		return
+	}
+
+	resp := api.IncidentLogChunkResponse{
+		Offset:     offset,
+		NextOffset: offset + utf8.RuneCountInString(chunk),
+		Size:       size,
+		Content:    opts.render(chunk),
+	}
+	api.RespondJSON(w, http.StatusOK, resp)
+}
+
+// handleAnonymizedLogChunk serves ?offset= with ?anonymize=true. Pseudonyms
+// are numbered by first appearance, so the mapping is built from the alerts
+// and the whole log before the chunk is rewritten; a fresh mapping per
+// chunk would call the same host host-1 in one chunk and host-3 in the
+// next. The chunk is also moved onto whitespace (see anonymizedChunkBounds)
+// so a hostname or IP cut in two at a boundary cannot slip past the
+// patterns; Offset and NextOffset report where it actually starts and ends.
+func (h *APIHandler) handleAnonymizedLogChunk(w http.ResponseWriter, r *http.Request, incidentUUID string, opts logRenderOptions, offset, limit int) {
+	incident, err := h.skillService.GetIncident(incidentUUID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read incident log", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to read incident log")
+		return
+	}
+	anonymizer := utils.NewAnonymizer()
+	if err := seedIncidentAnonymizer(anonymizer, incidentUUID); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to load incident alerts")
+		return
+	}
+	anonymizer.Text(opts.render(incident.FullLog))
+
+	runes := []rune(incident.FullLog)
+	writing := incident.Status == database.IncidentStatusPending || incident.Status == database.IncidentStatusRunning
+	start, end := anonymizedChunkBounds(runes, offset, limit, writing)
+	api.RespondJSON(w, http.StatusOK, api.IncidentLogChunkResponse{
+		Offset:     start,
+		NextOffset: end,
+		Size:       len(runes),
+		Content:    anonymizer.Text(opts.render(string(runes[start:end]))),
+	})
+}
+
+// logTokenSlack bounds how far anonymizedChunkBounds moves a chunk edge to
+// reach whitespace, so a log without any still comes back in pieces.
+const logTokenSlack = 1024
+
+// anonymizedChunkBounds returns the rune range to serve for the chunk
+// [offset, offset+limit) of log with both edges on whitespace. A chunk
+// starting inside a token skips it, since the chunk before ran on to its
+// end; a chunk ending inside a token runs on to its end. When the log is
+// still being written, a token at the very end may be incomplete and is
+// left for the next poll.
+func anonymizedChunkBounds(log []rune, offset, limit int, writing bool) (int, int) {
+	start := min(offset, len(log))
+	if start > 0 && !unicode.IsSpace(log[start-1]) {
+		for n := 0; start < len(log) && n < logTokenSlack && !unicode.IsSpace(log[start]); n++ {
+			start++
+		}
+	}
+	end := min(start+limit, len(log))
+	if end < len(log) || writing {
+		stop := end
+		for n := 0; end < len(log) && n < logTokenSlack && !unicode.IsSpace(log[end]); n++ {
+			end++
+		}
+		if end == len(log) && writing && end > start && !unicode.IsSpace(log[end-1]) {
+			end = stop
+			for end > start && !unicode.IsSpace(log[end-1]) {
+				end--
+			}
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestLogTail(t *testing.T) {
+	cases := []struct {
+		log  string
+		n    int
+		want string
+	}{
+		{"a\nb\nc", 0, "a\nb\nc"},
+		{"a\nb\nc", 2, "b\nc"},
+		{"a\nb\nc\n", 1, "c\n"},
+		{"a\nb", 5, "a\nb"},
+		{"", 3, ""},
+	}
+	for _, tc := range cases {
+		if got := logTail(tc.log, tc.n); got != tc.want {
+			t.Errorf("logTail(%q, %d) = %q, want %q", tc.log, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestHandleIncidentLog_Chunks(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+	if err := db.Create(&database.Incident{UUID: "inc-log", FullLog: "héllo\nworld ✅\n"}).Error; err != nil {
+		t.Fatalf("seed incident: %v", err)
+	}
+	h := NewAPIHandler(services.NewSkillService(t.TempDir(), nil, nil, nil), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	get := func(query string) (int, api.IncidentLogChunkResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/incidents/inc-log/log?"+query, nil))
+		var resp api.IncidentLogChunkResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode %s: %v", query, err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	// Offsets count characters, so the multi-byte é and ✅ are one each.
+	tests := []struct {
+		query   string
+		content string
+		next    int
+	}{
+		{"offset=0&limit=6", "héllo\n", 6},
+		{"offset=6", "world ✅\n", 14},
+		{"offset=14", "", 14},
+		{"offset=6&limit=7&strip_emoji=true", "world ", 13},
+	}
+	for _, tt := range tests {
+		code, resp := get(tt.query)
+		if code != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.query, code)
+		}
+		if resp.Content != tt.content || resp.NextOffset != tt.next || resp.Size != 14 {
+			t.Errorf("%s: got %+v, want content %q next_offset %d size 14", tt.query, resp, tt.content, tt.next)
+		}
+	}
+
+	for _, query := range []string{"offset=-1", "offset=x", "offset=0&limit=0", "offset=0&lines=5"} {
+		if code, _ := get(query); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/incidents/missing/log?offset=0", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown incident: status %d, want 404", rec.Code)
+	}
+}
+
+func TestHandleIncidentLog_AnonymizedChunks(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+	log := "web-01.prod.acme.com down\nping 10.0.0.5 from db-02.prod.acme.com\nweb-01.prod.acme.com back\n"
+	for _, inc := range []database.Incident{
+		{UUID: "inc-done", Status: database.IncidentStatusCompleted, FullLog: log},
+		{UUID: "inc-live", Status: database.IncidentStatusRunning, FullLog: "up on web-01.prod.acme.com\ncheck db-02.prod.ac"},
+	} {
+		if err := db.Create(&inc).Error; err != nil {
+			t.Fatalf("seed incident: %v", err)
+		}
+	}
+	h := NewAPIHandler(services.NewSkillService(t.TempDir(), nil, nil, nil), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/incidents/inc-done/log?anonymize=true", nil))
+	whole := rec.Body.String()
+	if strings.Contains(whole, "acme") || strings.Contains(whole, "10.0.0.5") {
+		t.Fatalf("full log leaks: %q", whole)
+	}
+
+	chunk := func(uuid string, offset int) api.IncidentLogChunkResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/api/incidents/"+uuid+"/log?anonymize=true&limit=10&offset="+strconv.Itoa(offset), nil))
+		var resp api.IncidentLogChunkResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode chunk at %d: %v (%s)", offset, err, rec.Body.String())
+		}
+		return resp
+	}
+
+	// Ten-character chunks cut every hostname, yet reading them in order
+	// yields the anonymized full log, pseudonyms included.
+	var b strings.Builder
+	for offset := 0; offset < utf8.RuneCountInString(log); {
+		resp := chunk("inc-done", offset)
+		if resp.Offset != offset || resp.NextOffset <= offset {
+			t.Fatalf("chunk at %d: %+v", offset, resp)
+		}
+		b.WriteString(resp.Content)
+		offset = resp.NextOffset
+	}
+	if b.String() != whole {
+		t.Errorf("chunks = %q, want %q", b.String(), whole)
+	}
+
+	// Starting inside a token skips it rather than exposing its tail.
+	if resp := chunk("inc-done", 3); resp.Offset != 20 || strings.Contains(resp.Content, "acme") {
+		t.Errorf("mid-token chunk = %+v", resp)
+	}
+
+	// The last token of a log still being written may be incomplete, so
+	// it waits for the next poll.
+	resp := chunk("inc-live", 27)
+	if resp.Content != "check " || resp.NextOffset != 33 {
+		t.Errorf("live tail chunk = %+v", resp)
+	}
+}
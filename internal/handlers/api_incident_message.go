@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// handleIncidentMessage handles POST /api/incidents/{uuid}/message. It asks a
+// follow-up question in the incident's agent session, the API counterpart
+// of replying in the incident's Slack thread. The run happens in the
+// background: progress streams over /ws/incidents/{uuid}, and the exchange
+// is appended to full_log with the answer stored as the new response.
+func (h *APIHandler) handleIncidentMessage(w http.ResponseWriter, r *http.Request) {
+	incidentUUID := r.PathValue("uuid")
+
+	var req api.IncidentMessageRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		api.RespondError(w, http.StatusBadRequest, "Message is required")
+		return
+	}
+	if h.agentWSHandler == nil || !h.agentWSHandler.IsWorkerConnected() {
+		api.RespondError(w, http.StatusServiceUnavailable, "Agent worker is not connected")
+		return
+	}
+
+	if h.budget != nil {
+		if err := h.budget.CheckBudget(r.Context()); err != nil {
+			api.RespondError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == "" {
+		user = "api"
+	}
+	logHeader := fmt.Sprintf("\n\n--- Follow-up from %s ---\n%s\n\n--- Execution Log ---\n\n", user, message)
+
+	prior, err := h.skillService.BeginFollowUp(incidentUUID, logHeader)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	case errors.Is(err, services.ErrIncidentNotFinished):
+		api.RespondError(w, http.StatusConflict, "The agent is still working on this incident")
+		return
+	default:
+		slog.ErrorContext(r.Context(), "failed to start incident follow-up", "incident_uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to start follow-up")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "incident follow-up via API", "incident_uuid", incidentUUID, "user", user)
+	go h.runIncidentFollowUp(prior, prior.FullLog+logHeader, message)
+
+	api.RespondJSON(w, http.StatusAccepted, api.IncidentMessageResponse{
+		UUID:    incidentUUID,
+		Status:  string(database.IncidentStatusRunning),
+		Message: "Follow-up started",
+	})
+}
+
+// runIncidentFollowUp resumes the incident's agent session with message.
+// Without a stored session ID the incident ID is passed, as the preemption
+// resume does; the worker finds the session through the incident workspace.
+func (h *APIHandler) runIncidentFollowUp(prior *database.Incident, logPrefix, message string) {
+	sessionID := prior.SessionID
+	if sessionID == "" {
+		sessionID = prior.UUID
+	}
+	h.runAgent(prior.UUID, logPrefix, prior, func(llm *LLMSettingsForWorker, callback IncidentCallback) (string, error) {
+		return h.agentWSHandler.ContinueIncident(prior.UUID, sessionID, message, llm, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+	})
+}
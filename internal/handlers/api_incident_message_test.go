@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// followUpSkillService serves BeginFollowUp from a fixed incident and
+// reports the final UpdateIncidentComplete call.
+type followUpSkillService struct {
+	*recordingSkillService
+	prior     database.Incident
+	completed chan followUpResult
+}
+
+type followUpResult struct {
+	status    database.IncidentStatus
+	sessionID string
+	fullLog   string
+	response  string
+	tokens    int
+}
+
+func (s *followUpSkillService) BeginFollowUp(incidentUUID, _ string) (*database.Incident, error) {
+	switch incidentUUID {
+	case "missing":
+		return nil, gorm.ErrRecordNotFound
+	case "busy":
+		return nil, services.ErrIncidentNotFinished
+	}
+	prior := s.prior
+	return &prior, nil
+}
+
+func (s *followUpSkillService) UpdateIncidentComplete(_ string, status database.IncidentStatus, sessionID, fullLog, response string, tokens int, _ int64) error {
+	s.completed <- followUpResult{status, sessionID, fullLog, response, tokens}
+	return nil
+}
+
+func TestIncidentMessageAPI(t *testing.T) {
+	skills := &followUpSkillService{
+		recordingSkillService: &recordingSkillService{},
+		prior: database.Incident{UUID: "inc-1", SessionID: "sess-1", Status: database.IncidentStatusCompleted,
+			FullLog: "original investigation", TokensUsed: 5},
+		completed: make(chan followUpResult, 1),
+	}
+
+	// No worker: refused up front rather than failing the incident.
+	h := NewAPIHandler(skills, nil, nil, nil, nil, NewAgentWSHandler(), nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/message", `{"message":"why?"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no worker status = %d", rec.Code)
+	}
+
+	agentWS, worker, cleanup := setupOneshotTest(t)
+	defer cleanup()
+	h = NewAPIHandler(skills, nil, nil, nil, nil, agentWS, nil, nil, nil, nil, nil)
+	mux = http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	for path, want := range map[string]int{
+		"/api/incidents/missing/message": http.StatusNotFound,
+		"/api/incidents/busy/message":    http.StatusConflict,
+	} {
+		if rec := serveJSON(mux, http.MethodPost, path, `{"message":"why?"}`); rec.Code != want {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/message", `{"message":"  "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("blank message status = %d", rec.Code)
+	}
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/message", `{"message":"why did nginx restart?"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if err := worker.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var msg AgentMessage
+	for msg.Type != AgentMessageTypeContinueIncident {
+		if err := worker.ReadJSON(&msg); err != nil {
+			t.Fatalf("read continue_incident: %v", err)
+		}
+	}
+	if msg.IncidentID != "inc-1" || msg.SessionID != "sess-1" || msg.Message != "why did nginx restart?" {
+		t.Fatalf("continue_incident = %+v", msg)
+	}
+	for _, frame := range []AgentMessage{
+		{Type: AgentMessageTypeAgentOutput, IncidentID: "inc-1", RunID: msg.RunID, Output: "checking journal\n"},
+		{Type: AgentMessageTypeAgentCompleted, IncidentID: "inc-1", RunID: msg.RunID, SessionID: "sess-1", Output: "OOM kill", TokensUsed: 10},
+	} {
+		if err := worker.WriteJSON(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case got := <-skills.completed:
+		if got.status != database.IncidentStatusCompleted || got.sessionID != "sess-1" || got.tokens != 15 {
+			t.Errorf("result = %+v", got)
+		}
+		if !strings.HasPrefix(got.fullLog, "original investigation\n\n--- Follow-up from api ---\nwhy did nginx restart?") ||
+			!strings.Contains(got.fullLog, "checking journal") || !strings.Contains(got.fullLog, "OOM kill") {
+			t.Errorf("full_log = %q", got.fullLog)
+		}
+		if !strings.HasPrefix(got.response, "OOM kill") {
+			t.Errorf("response = %q", got.response)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("follow-up never completed")
+	}
+}
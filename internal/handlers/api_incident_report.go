@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// SetPostmortemReporter wires the service backing /api/incidents/{uuid}/report.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetPostmortemReporter(svc services.PostmortemReporter) {
+	h.postmortems = svc
+}
+
+// handleGenerateIncidentReport handles POST /api/incidents/{uuid}/report.
+// It (re)generates the incident's postmortem through the agent worker and
+// returns it. The call blocks for the length of one LLM round trip.
+func (h *APIHandler) handleGenerateIncidentReport(w http.ResponseWriter, r *http.Request) {
+	if h.postmortems == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Postmortem generation is not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+
+	report, err := h.postmortems.GenerateReport(r.Context(), incidentUUID, middleware.GetUserFromContext(r.Context()))
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusCreated, report)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+	case errors.Is(err, services.ErrIncidentNotFinished):
+		api.RespondError(w, http.StatusConflict, "Incident investigation has not finished")
+	case errors.Is(err, services.ErrWorkerNotConnected):
+		api.RespondError(w, http.StatusServiceUnavailable, "Agent worker is not connected or no LLM is configured")
+	case errors.Is(err, services.ErrInvalidPostmortem):
+		slog.WarnContext(r.Context(), "postmortem: unusable model reply", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusBadGateway, "The model did not return a usable postmortem; try again")
+	default:
+		slog.ErrorContext(r.Context(), "postmortem: generation failed", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to generate postmortem")
+	}
+}
+
+// handleGetIncidentReport handles GET /api/incidents/{uuid}/report. With
+// ?format=markdown the report is served as a Markdown attachment; with
+// ?format=text it is the same document with emoji removed, as plain text.
+func (h *APIHandler) handleGetIncidentReport(w http.ResponseWriter, r *http.Request) {
+	if h.postmortems == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Postmortem generation is not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+
+	report, err := h.postmortems.GetReport(r.Context(), incidentUUID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondError(w, http.StatusNotFound, "No postmortem has been generated for this incident")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "postmortem: failed to load", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to load postmortem")
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		api.RespondJSON(w, http.StatusOK, report)
+	case "markdown", "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="postmortem-%s.md"`, incidentUUID))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(services.RenderPostmortemMarkdown(report)))
+	case "text", "txt":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="postmortem-%s.txt"`, incidentUUID))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(utils.PlainTextLog(services.RenderPostmortemMarkdown(report))))
+	default:
+		api.RespondError(w, http.StatusBadRequest, "format must be json, markdown or text")
+	}
+}
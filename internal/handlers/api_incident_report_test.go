@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type stubPostmortemReporter struct {
+	err    error
+	report *database.IncidentReport
+}
+
+func (s *stubPostmortemReporter) GenerateReport(_ context.Context, incidentUUID, author string) (*database.IncidentReport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.report = &database.IncidentReport{IncidentUUID: incidentUUID, Title: "Disk full", RootCause: "stuck archiver", GeneratedBy: author}
+	return s.report, nil
+}
+
+func (s *stubPostmortemReporter) GetReport(_ context.Context, incidentUUID string) (*database.IncidentReport, error) {
+	if s.report == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.report, nil
+}
+
+func TestIncidentReportAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/report", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+
+	stub := &stubPostmortemReporter{}
+	h.SetPostmortemReporter(stub)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/report", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get before generate status = %d", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/report", ""); rec.Code != http.StatusCreated {
+		t.Fatalf("generate status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/report", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"root_cause":"stuck archiver"`) {
+		t.Fatalf("get status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/report?format=markdown", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("markdown status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="postmortem-inc-1.md"`) {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !strings.Contains(rec.Body.String(), "# Postmortem: Disk full") {
+		t.Errorf("markdown body = %s", rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/report?format=pdf", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format status = %d", rec.Code)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound},
+		{services.ErrIncidentNotFinished, http.StatusConflict},
+		{services.ErrWorkerNotConnected, http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: not json", services.ErrInvalidPostmortem), http.StatusBadGateway},
+		{fmt.Errorf("boom"), http.StatusInternalServerError},
+	} {
+		stub.err = tc.err
+		if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/report", ""); rec.Code != tc.want {
+			t.Errorf("err %v: status = %d, want %d", tc.err, rec.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// handleIncidentRetry handles POST /api/incidents/{uuid}/retry. It re-runs a
+// failed or cancelled investigation with the prompt it was originally
+// started with, in the same workspace, so a bad API key or a transient tool
+// error no longer needs a new alert to recover from. The new attempt is
+// appended to full_log under an attempt header and runs in the background
+// like any other investigation.
+func (h *APIHandler) handleIncidentRetry(w http.ResponseWriter, r *http.Request) {
+	incidentUUID := r.PathValue("uuid")
+
+	if h.agentWSHandler == nil || !h.agentWSHandler.IsWorkerConnected() {
+		api.RespondError(w, http.StatusServiceUnavailable, "Agent worker is not connected")
+		return
+	}
+
+	if h.budget != nil {
+		if err := h.budget.CheckBudget(r.Context()); err != nil {
+			api.RespondError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == "" {
+		user = "api"
+	}
+
+	incident, err := h.skillService.BeginRetry(incidentUUID, user)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	case errors.Is(err, services.ErrIncidentNotRetryable), errors.Is(err, services.ErrNoRecordedTask):
+		api.RespondError(w, http.StatusConflict, err.Error())
+		return
+	default:
+		slog.ErrorContext(r.Context(), "failed to start incident retry", "incident_uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to start retry")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "incident retry via API", "incident_uuid", incidentUUID, "user", user, "attempt", incident.Attempts)
+	task := incident.Task
+	go h.runAgent(incidentUUID, incident.FullLog, incident, func(llm *LLMSettingsForWorker, callback IncidentCallback) (string, error) {
+		return h.agentWSHandler.StartIncident(incidentUUID, task, llm, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+	})
+
+	api.RespondJSON(w, http.StatusAccepted, api.IncidentRetryResponse{
+		UUID:    incidentUUID,
+		Status:  string(database.IncidentStatusRunning),
+		Attempt: incident.Attempts,
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// retrySkillService serves BeginRetry from a fixed incident and reports the
+// final UpdateIncidentComplete call.
+type retrySkillService struct {
+	*followUpSkillService
+}
+
+func (s *retrySkillService) BeginRetry(incidentUUID, retriedBy string) (*database.Incident, error) {
+	switch incidentUUID {
+	case "missing":
+		return nil, gorm.ErrRecordNotFound
+	case "running":
+		return nil, services.ErrIncidentNotRetryable
+	case "no-task":
+		return nil, services.ErrNoRecordedTask
+	}
+	incident := s.prior
+	incident.Status = database.IncidentStatusRunning
+	incident.Attempts++
+	incident.FullLog += "\n\n--- Attempt 2 (retried by " + retriedBy + ") ---\n\n"
+	return &incident, nil
+}
+
+func TestIncidentRetryAPI(t *testing.T) {
+	skills := &retrySkillService{&followUpSkillService{
+		recordingSkillService: &recordingSkillService{},
+		prior: database.Incident{UUID: "inc-1", SessionID: "sess-1", Status: database.IncidentStatusFailed,
+			FullLog: "first attempt", Task: "Investigate disk alert on db-01", Attempts: 1, TokensUsed: 5},
+		completed: make(chan followUpResult, 1),
+	}}
+
+	// No worker: refused up front rather than failing the incident.
+	h := NewAPIHandler(skills, nil, nil, nil, nil, NewAgentWSHandler(), nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/retry", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no worker status = %d", rec.Code)
+	}
+
+	agentWS, worker, cleanup := setupOneshotTest(t)
+	defer cleanup()
+	h = NewAPIHandler(skills, nil, nil, nil, nil, agentWS, nil, nil, nil, nil, nil)
+	mux = http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	for path, want := range map[string]int{
+		"/api/incidents/missing/retry": http.StatusNotFound,
+		"/api/incidents/running/retry": http.StatusConflict,
+		"/api/incidents/no-task/retry": http.StatusConflict,
+	} {
+		if rec := serveJSON(mux, http.MethodPost, path, ""); rec.Code != want {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/retry", "")
+	if rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"attempt":2`) {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if err := worker.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var msg AgentMessage
+	for msg.Type != AgentMessageTypeNewIncident {
+		if err := worker.ReadJSON(&msg); err != nil {
+			t.Fatalf("read new_incident: %v", err)
+		}
+	}
+	if msg.IncidentID != "inc-1" || msg.Task != "Investigate disk alert on db-01" {
+		t.Fatalf("new_incident = %+v", msg)
+	}
+	for _, frame := range []AgentMessage{
+		{Type: AgentMessageTypeAgentOutput, IncidentID: "inc-1", RunID: msg.RunID, Output: "checking df\n"},
+		{Type: AgentMessageTypeAgentCompleted, IncidentID: "inc-1", RunID: msg.RunID, SessionID: "sess-2", Output: "disk full", TokensUsed: 10},
+	} {
+		if err := worker.WriteJSON(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case got := <-skills.completed:
+		if got.status != database.IncidentStatusCompleted || got.sessionID != "sess-2" || got.tokens != 15 {
+			t.Errorf("result = %+v", got)
+		}
+		if !strings.HasPrefix(got.fullLog, "first attempt\n\n--- Attempt 2 (retried by api) ---\n\n") ||
+			!strings.Contains(got.fullLog, "checking df") {
+			t.Errorf("full_log = %q", got.fullLog)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("retry never completed")
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetSnippetExporter wires the service backing /api/incidents/{uuid}/snippets.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetSnippetExporter(svc services.SnippetExporter) {
+	h.snippetExporter = svc
+}
+
+// handleIncidentSnippets handles GET and POST /api/incidents/{uuid}/snippets.
+// POST promotes a command or query from the incident into a skill's scripts
+// or references; GET lists what has already been promoted from it.
+func (h *APIHandler) handleIncidentSnippets(w http.ResponseWriter, r *http.Request) {
+	if h.snippetExporter == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Snippet export is not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+
+	if r.Method == http.MethodGet {
+		snippets, err := h.snippetExporter.ListSnippets(r.Context(), incidentUUID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "snippets: failed to list", "uuid", incidentUUID, "err", err)
+			api.RespondError(w, http.StatusInternalServerError, "Failed to list snippets")
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, snippets)
+		return
+	}
+
+	var req api.ExportSnippetRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	if req.SkillName == "" || req.Filename == "" {
+		api.RespondError(w, http.StatusBadRequest, "skill_name and filename are required")
+		return
+	}
+
+	snippet, err := h.snippetExporter.ExportSnippet(r.Context(), incidentUUID, services.IncidentSnippet{
+		SkillName:   req.SkillName,
+		Kind:        req.Kind,
+		Filename:    req.Filename,
+		Content:     req.Content,
+		Description: req.Description,
+		Overwrite:   req.Overwrite,
+	})
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusCreated, snippet)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+	case errors.Is(err, services.ErrSnippetExists):
+		api.RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidSnippet), errors.Is(err, services.ErrUnknownSkill):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "snippets: export failed", "uuid", incidentUUID, "skill", req.SkillName, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to export snippet")
+	}
+}
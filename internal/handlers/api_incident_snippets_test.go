@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type stubSnippetExporter struct {
+	err      error
+	exported []services.IncidentSnippet
+}
+
+func (s *stubSnippetExporter) ExportSnippet(_ context.Context, incidentUUID string, snippet services.IncidentSnippet) (*database.SkillSnippet, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.exported = append(s.exported, snippet)
+	return &database.SkillSnippet{SkillName: snippet.SkillName, Filename: snippet.Filename, IncidentUUID: incidentUUID}, nil
+}
+
+func (s *stubSnippetExporter) ListSnippets(context.Context, string) ([]database.SkillSnippet, error) {
+	return []database.SkillSnippet{}, nil
+}
+
+func TestIncidentSnippetsAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/snippets", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+
+	stub := &stubSnippetExporter{}
+	h.SetSnippetExporter(stub)
+
+	rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/snippets",
+		`{"skill_name":"pg-triage","filename":"lag.sh","content":"psql -c 'select 1'","kind":"script"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("export status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(stub.exported) != 1 || stub.exported[0].Content != "psql -c 'select 1'" {
+		t.Fatalf("exported = %+v", stub.exported)
+	}
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/snippets", `{"content":"x"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing fields status = %d", rec.Code)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound},
+		{services.ErrSnippetExists, http.StatusConflict},
+		{fmt.Errorf("%w: bad", services.ErrInvalidSnippet), http.StatusBadRequest},
+		{services.ErrUnknownSkill, http.StatusBadRequest},
+	} {
+		stub.err = tc.err
+		rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/snippets", `{"skill_name":"s","filename":"a.sh","content":"x"}`)
+		if rec.Code != tc.want {
+			t.Errorf("err %v: status = %d, want %d", tc.err, rec.Code, tc.want)
+		}
+	}
+}
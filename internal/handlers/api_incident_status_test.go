@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/services"
@@ -15,11 +17,13 @@ import (
 )
 
 // statusSkillService embeds corrGateSkillService for all no-op stubs and
-// overrides ResolveAlert/CloseIncident with configurable hooks.
+// overrides ResolveAlert/CloseIncident/CancelIncident with configurable
+// hooks.
 type statusSkillService struct {
 	corrGateSkillService
 	resolveFn func(ctx context.Context, alertUUID string) error
 	closeFn   func(ctx context.Context, incidentUUID string, confirm bool) error
+	cancelFn  func(ctx context.Context, incidentUUID, cancelledBy string) error
 }
 
 func (s *statusSkillService) ResolveAlert(ctx context.Context, alertUUID string) error {
@@ -36,8 +40,15 @@ func (s *statusSkillService) CloseIncident(ctx context.Context, incidentUUID str
 	return nil
 }
 
+func (s *statusSkillService) CancelIncident(ctx context.Context, incidentUUID, cancelledBy string) error {
+	if s.cancelFn != nil {
+		return s.cancelFn(ctx, incidentUUID, cancelledBy)
+	}
+	return nil
+}
+
 func TestHandleAlertResolve_200_HappyPath(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	var capturedUUID string
 	svc := &statusSkillService{
@@ -63,7 +74,7 @@ func TestHandleAlertResolve_200_HappyPath(t *testing.T) {
 }
 
 func TestHandleAlertResolve_404_NotFound(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	svc := &statusSkillService{
 		resolveFn: func(context.Context, string) error { return gorm.ErrRecordNotFound },
@@ -82,7 +93,7 @@ func TestHandleAlertResolve_404_NotFound(t *testing.T) {
 }
 
 func TestHandleAlertResolve_409_AlreadyResolved(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	svc := &statusSkillService{
 		resolveFn: func(context.Context, string) error { return services.ErrAlertAlreadyResolved },
@@ -101,7 +112,7 @@ func TestHandleAlertResolve_409_AlreadyResolved(t *testing.T) {
 }
 
 func TestHandleIncidentClose_200_HappyPath(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	var capturedUUID string
 	var capturedConfirm bool
@@ -132,7 +143,7 @@ func TestHandleIncidentClose_200_HappyPath(t *testing.T) {
 }
 
 func TestHandleIncidentClose_409_InProgressRequiresConfirmation(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	svc := &statusSkillService{
 		closeFn: func(_ context.Context, _ string, confirm bool) error {
@@ -172,7 +183,7 @@ func TestHandleIncidentClose_409_InProgressRequiresConfirmation(t *testing.T) {
 }
 
 func TestHandleIncidentClose_409_RequiresConfirmation(t *testing.T) {
-	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.Alert{})
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
 
 	svc := &statusSkillService{
 		closeFn: func(_ context.Context, _ string, confirm bool) error {
@@ -213,3 +224,77 @@ func TestHandleIncidentClose_409_RequiresConfirmation(t *testing.T) {
 		t.Fatalf("expected 200 on confirmed retry, got %d: %s", rec2.Code, rec2.Body.String())
 	}
 }
+
+func TestHandleIncidentCancel_StopsTheRun(t *testing.T) {
+	wsHandler, wsURL := newPoolTestServer(t)
+	worker := dialPoolWorker(t, wsHandler, wsURL, "worker", 4)
+
+	superseded := make(chan struct{}, 1)
+	errs := make(chan string, 1)
+	runID, err := wsHandler.StartIncident("inc-1", "task", nil, nil, nil, IncidentCallback{
+		OnError:      func(msg string) { errs <- msg },
+		OnSuperseded: func() { superseded <- struct{}{} },
+	})
+	if err != nil {
+		t.Fatalf("StartIncident: %v", err)
+	}
+	readNewIncidentRequest(t, worker)
+
+	var cancelledBy string
+	svc := &statusSkillService{cancelFn: func(_ context.Context, _ string, by string) error {
+		cancelledBy = by
+		return nil
+	}}
+	h := NewAPIHandler(svc, nil, nil, nil, nil, wsHandler, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/incidents/inc-1/cancel", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if cancelledBy != "api" {
+		t.Errorf("cancelled by %q, want api", cancelledBy)
+	}
+	if msg := readWorkerFrame(t, worker, AgentMessageTypeCancelIncident); msg.IncidentID != "inc-1" {
+		t.Errorf("cancel frame = %+v", msg)
+	}
+	select {
+	case <-superseded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the run's waiter was not released")
+	}
+
+	// The worker's "Execution cancelled" error for the run is dropped rather
+	// than failing the incident.
+	wsHandler.handleAgentError(AgentMessage{Type: AgentMessageTypeAgentError, IncidentID: "inc-1", RunID: runID, Error: "Execution cancelled"})
+	select {
+	case msg := <-errs:
+		t.Errorf("cancelled run reported an error: %s", msg)
+	default:
+	}
+}
+
+func TestHandleIncidentCancel_MapsErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound},
+		{services.ErrIncidentNotRunning, http.StatusConflict},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		svc := &statusSkillService{cancelFn: func(context.Context, string, string) error { return tt.err }}
+		h := NewAPIHandler(svc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		mux := http.NewServeMux()
+		h.SetupRoutes(mux)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/incidents/inc-1/cancel", nil))
+		if rec.Code != tt.want {
+			t.Errorf("%v: got %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetIncidentTimeline wires the service backing /api/incidents/{uuid}/timeline.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetIncidentTimeline(svc services.IncidentTimeline) {
+	h.incidentTimeline = svc
+}
+
+// handleIncidentTimeline handles GET and POST /api/incidents/{uuid}/timeline.
+// GET returns the incident's events oldest first; POST adds an operator note
+// attributed to the authenticated user.
+func (h *APIHandler) handleIncidentTimeline(w http.ResponseWriter, r *http.Request) {
+	if h.incidentTimeline == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Incident timeline is not configured")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+
+	if r.Method == http.MethodGet {
+		events, err := h.incidentTimeline.ListEvents(r.Context(), incidentUUID)
+		switch {
+		case err == nil:
+			api.RespondJSON(w, http.StatusOK, events)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			api.RespondError(w, http.StatusNotFound, "Incident not found")
+		default:
+			slog.ErrorContext(r.Context(), "timeline: failed to list", "uuid", incidentUUID, "err", err)
+			api.RespondError(w, http.StatusInternalServerError, "Failed to load timeline")
+		}
+		return
+	}
+
+	var req api.AddTimelineNoteRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	event, err := h.incidentTimeline.AddNote(r.Context(), incidentUUID, middleware.GetUserFromContext(r.Context()), req.Text)
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusCreated, event)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+	case errors.Is(err, services.ErrInvalidTimelineNote):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "timeline: failed to add note", "uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to add note")
+	}
+}
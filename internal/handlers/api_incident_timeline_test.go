@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type stubIncidentTimeline struct {
+	err   error
+	notes []string
+}
+
+func (s *stubIncidentTimeline) ListEvents(_ context.Context, incidentUUID string) ([]database.IncidentEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []database.IncidentEvent{{IncidentUUID: incidentUUID, Type: database.IncidentEventStatusChange}}, nil
+}
+
+func (s *stubIncidentTimeline) AddNote(_ context.Context, incidentUUID, author, text string) (*database.IncidentEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.notes = append(s.notes, text)
+	return &database.IncidentEvent{IncidentUUID: incidentUUID, Type: database.IncidentEventNote, Actor: author}, nil
+}
+
+func TestIncidentTimelineAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/timeline", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured status = %d", rec.Code)
+	}
+
+	stub := &stubIncidentTimeline{}
+	h.SetIncidentTimeline(stub)
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/timeline", ""); rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/timeline", `{"text":"rolled back"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("note status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(stub.notes) != 1 || stub.notes[0] != "rolled back" {
+		t.Errorf("notes = %v", stub.notes)
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/timeline", `{"note":1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown field status = %d", rec.Code)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound},
+		{fmt.Errorf("%w: text is required", services.ErrInvalidTimelineNote), http.StatusBadRequest},
+		{fmt.Errorf("disk on fire"), http.StatusInternalServerError},
+	} {
+		stub.err = tc.err
+		if rec := serveJSON(mux, http.MethodPost, "/api/incidents/inc-1/timeline", `{"text":"x"}`); rec.Code != tc.want {
+			t.Errorf("err %v: status = %d, want %d", tc.err, rec.Code, tc.want)
+		}
+	}
+	stub.err = gorm.ErrRecordNotFound
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/timeline", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing incident list status = %d", rec.Code)
+	}
+}
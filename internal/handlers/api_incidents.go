@@ -16,206 +16,193 @@ import (
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/middleware"
 	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
 	"gorm.io/gorm"
 )
 
-// handleIncidents handles GET /api/incidents and POST /api/incidents
-func (h *APIHandler) handleIncidents(w http.ResponseWriter, r *http.Request) {
+// listIncidents handles GET /api/incidents
+func (h *APIHandler) listIncidents(w http.ResponseWriter, r *http.Request) {
 	db := database.GetDB()
 
-	switch r.Method {
-	case http.MethodGet:
-		var incidents []database.Incident
-		query := db.Order("created_at DESC")
+	var incidents []database.Incident
 
-		fromParam := r.URL.Query().Get("from")
-		toParam := r.URL.Query().Get("to")
-		statusParam := r.URL.Query().Get("status")
+	// Always use pagination (defaults: page=1, per_page=50)
+	params := api.ParsePagination(r)
 
-		if fromParam != "" {
-			from, err := strconv.ParseInt(fromParam, 10, 64)
-			if err == nil {
-				query = query.Where("created_at >= ?", time.Unix(from, 0))
-			}
-		}
-		if toParam != "" {
-			to, err := strconv.ParseInt(toParam, 10, 64)
-			if err == nil {
-				query = query.Where("created_at <= ?", time.Unix(to, 0))
-			}
-		}
-		if statusParam != "" {
-			query = applyIncidentStatusFilter(query, statusParam)
-		}
+	baseQuery := applyIncidentListFilters(db.Model(&database.Incident{}), r)
 
-		// Always use pagination (defaults: page=1, per_page=50)
-		params := api.ParsePagination(r)
+	var total int64
+	if err := baseQuery.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to count incidents")
+		return
+	}
 
-		var total int64
-		countQuery := db.Model(&database.Incident{})
-		if fromParam != "" {
-			if from, err := strconv.ParseInt(fromParam, 10, 64); err == nil {
-				countQuery = countQuery.Where("created_at >= ?", time.Unix(from, 0))
-			}
+	query := baseQuery.Order("created_at DESC")
+	if err := query.Offset(params.Offset()).Limit(params.PerPage).Find(&incidents).Error; err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get incidents")
+		return
+	}
+
+	// Enrich incidents with alert aggregation fields.
+	if len(incidents) > 0 {
+		uuids := make([]string, len(incidents))
+		for i, inc := range incidents {
+			uuids[i] = inc.UUID
 		}
-		if toParam != "" {
-			if to, err := strconv.ParseInt(toParam, 10, 64); err == nil {
-				countQuery = countQuery.Where("created_at <= ?", time.Unix(to, 0))
-			}
+
+		// Parse trend_window (default "1h", also accept "3h").
+		trendWindowParam := r.URL.Query().Get("trend_window")
+		var trendWindow time.Duration
+		switch trendWindowParam {
+		case "3h":
+			trendWindow = 3 * time.Hour
+		default:
+			trendWindow = time.Hour
 		}
-		if statusParam != "" {
-			countQuery = applyIncidentStatusFilter(countQuery, statusParam)
+
+		// Batch 1: count + first/last seen per incident.
+		type alertAggRow struct {
+			IncidentUUID string
+			Count        int64
+			FirstSeen    *time.Time
+			LastSeen     *time.Time
 		}
-		if err := countQuery.Count(&total).Error; err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to count incidents")
-			return
+		var aggRows []alertAggRow
+		if err := db.Model(&database.Alert{}).
+			Select("incident_uuid, COUNT(*) as count, MIN(fired_at) as first_seen, MAX(fired_at) as last_seen").
+			Where("incident_uuid IN ?", uuids).
+			Group("incident_uuid").
+			Scan(&aggRows).Error; err != nil {
+			slog.WarnContext(r.Context(), "failed to fetch alert aggregates", "err", err)
 		}
-
-		if err := query.Offset(params.Offset()).Limit(params.PerPage).Find(&incidents).Error; err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get incidents")
-			return
+		aggMap := make(map[string]alertAggRow, len(aggRows))
+		for _, row := range aggRows {
+			aggMap[row.IncidentUUID] = row
 		}
 
-		// Enrich incidents with alert aggregation fields.
-		if len(incidents) > 0 {
-			uuids := make([]string, len(incidents))
-			for i, inc := range incidents {
-				uuids[i] = inc.UUID
-			}
-
-			// Parse trend_window (default "1h", also accept "3h").
-			trendWindowParam := r.URL.Query().Get("trend_window")
-			var trendWindow time.Duration
-			switch trendWindowParam {
-			case "3h":
-				trendWindow = 3 * time.Hour
-			default:
-				trendWindow = time.Hour
-			}
+		// Batch 2: timestamps within the trend window for sparkline.
+		windowEnd := time.Now()
+		windowStart := windowEnd.Add(-trendWindow)
+		type alertTsRow struct {
+			IncidentUUID string
+			FiredAt      time.Time
+		}
+		var tsRows []alertTsRow
+		if err := db.Model(&database.Alert{}).
+			Select("incident_uuid, fired_at").
+			Where("incident_uuid IN ? AND fired_at >= ?", uuids, windowStart).
+			Scan(&tsRows).Error; err != nil {
+			slog.WarnContext(r.Context(), "failed to fetch alert timestamps", "err", err)
+		}
+		tsMap := make(map[string][]time.Time, len(incidents))
+		for _, row := range tsRows {
+			tsMap[row.IncidentUUID] = append(tsMap[row.IncidentUUID], row.FiredAt)
+		}
 
-			// Batch 1: count + first/last seen per incident.
-			type alertAggRow struct {
-				IncidentUUID string
-				Count        int64
-				FirstSeen    *time.Time
-				LastSeen     *time.Time
-			}
-			var aggRows []alertAggRow
-			if err := db.Model(&database.Alert{}).
-				Select("incident_uuid, COUNT(*) as count, MIN(fired_at) as first_seen, MAX(fired_at) as last_seen").
-				Where("incident_uuid IN ?", uuids).
-				Group("incident_uuid").
-				Scan(&aggRows).Error; err != nil {
-				slog.Warn("failed to fetch alert aggregates", "err", err)
-			}
-			aggMap := make(map[string]alertAggRow, len(aggRows))
-			for _, row := range aggRows {
-				aggMap[row.IncidentUUID] = row
-			}
+		const trendBuckets = 12
 
-			// Batch 2: timestamps within the trend window for sparkline.
-			windowEnd := time.Now()
-			windowStart := windowEnd.Add(-trendWindow)
-			type alertTsRow struct {
-				IncidentUUID string
-				FiredAt      time.Time
-			}
-			var tsRows []alertTsRow
-			if err := db.Model(&database.Alert{}).
-				Select("incident_uuid, fired_at").
-				Where("incident_uuid IN ? AND fired_at >= ?", uuids, windowStart).
-				Scan(&tsRows).Error; err != nil {
-				slog.Warn("failed to fetch alert timestamps", "err", err)
-			}
-			tsMap := make(map[string][]time.Time, len(incidents))
-			for _, row := range tsRows {
-				tsMap[row.IncidentUUID] = append(tsMap[row.IncidentUUID], row.FiredAt)
+		for i := range incidents {
+			uuid := incidents[i].UUID
+			if agg, ok := aggMap[uuid]; ok {
+				incidents[i].AlertCount = agg.Count
+				incidents[i].FirstSeen = agg.FirstSeen
+				incidents[i].LastSeen = agg.LastSeen
 			}
-
-			const trendBuckets = 12
-
-			for i := range incidents {
-				uuid := incidents[i].UUID
-				if agg, ok := aggMap[uuid]; ok {
-					incidents[i].AlertCount = agg.Count
-					incidents[i].FirstSeen = agg.FirstSeen
-					incidents[i].LastSeen = agg.LastSeen
-				}
-				if ts, ok := tsMap[uuid]; ok {
-					incidents[i].Trend = bucketTimestamps(ts, windowStart, windowEnd, trendBuckets)
-				} else {
-					incidents[i].Trend = make([]int, trendBuckets)
-				}
+			if ts, ok := tsMap[uuid]; ok {
+				incidents[i].Trend = bucketTimestamps(ts, windowStart, windowEnd, trendBuckets)
+			} else {
+				incidents[i].Trend = make([]int, trendBuckets)
 			}
 		}
+	}
 
-		api.RespondJSON(w, http.StatusOK, api.PaginatedResponse{
-			Data: incidents,
-			Pagination: api.PaginationMeta{
-				Page:       params.Page,
-				PerPage:    params.PerPage,
-				Total:      total,
-				TotalPages: params.TotalPages(total),
-			},
-		})
+	vocab := database.LoadVocabulary()
+	for i := range incidents {
+		incidents[i].ApplyVocabulary(vocab)
+	}
 
-	case http.MethodPost:
-		var req api.CreateIncidentRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	api.RespondJSON(w, http.StatusOK, api.PaginatedResponse{
+		Data: incidents,
+		Pagination: api.PaginationMeta{
+			Page:       params.Page,
+			PerPage:    params.PerPage,
+			Total:      total,
+			TotalPages: params.TotalPages(total),
+		},
+	})
+}
 
-		if req.Task == "" {
-			api.RespondError(w, http.StatusBadRequest, "Task is required")
-			return
-		}
+// createIncident handles POST /api/incidents
+func (h *APIHandler) createIncident(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateIncidentRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		incidentContext := &services.IncidentContext{
-			Source:     "api",
-			SourceKind: database.IncidentSourceKindManual,
-			SourceID:   fmt.Sprintf("api-%d", time.Now().UnixNano()),
-			Context: database.JSONB{
-				"task":       req.Task,
-				"created_by": "api",
-			},
-			Message: req.Task,
-		}
+	if req.Task == "" {
+		api.RespondError(w, http.StatusBadRequest, "Task is required")
+		return
+	}
 
-		if req.Context != nil {
-			for k, v := range req.Context {
-				incidentContext.Context[k] = v
-			}
-		}
+	incidentContext := &services.IncidentContext{
+		Source:     "api",
+		SourceKind: database.IncidentSourceKindManual,
+		SourceID:   fmt.Sprintf("api-%d", time.Now().UnixNano()),
+		Context: database.JSONB{
+			"task":       req.Task,
+			"created_by": "api",
+		},
+		Message: req.Task,
+	}
 
-		incidentUUID, workingDir, err := h.skillService.SpawnIncidentManager(incidentContext)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to create incident")
-			return
+	if req.Context != nil {
+		for k, v := range req.Context {
+			incidentContext.Context[k] = v
 		}
+	}
 
-		slog.Info("created incident via API", "incident_id", incidentUUID)
+	incidentUUID, workingDir, err := h.skillService.SpawnIncidentManager(incidentContext)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to create incident")
+		return
+	}
 
-		taskHeader := fmt.Sprintf("📝 API Incident Task:\n%s\n\n--- Execution Log ---\n\n", req.Task)
-		go h.runAgentInvestigation(incidentUUID, taskHeader, req.Task)
+	slog.InfoContext(r.Context(), "created incident via API", "incident_uuid", incidentUUID)
 
+	if note := declineOverBudget(h.budget, h.skillService, incidentUUID); note != "" {
 		api.RespondJSON(w, http.StatusCreated, api.CreateIncidentResponse{
 			UUID:       incidentUUID,
-			Status:     "pending",
+			Status:     string(database.IncidentStatusBudgetExceeded),
 			WorkingDir: workingDir,
-			Message:    "Incident created and processing started",
+			Message:    note,
 		})
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
+
+	taskHeader := fmt.Sprintf("📝 API Incident Task:\n%s\n\n--- Execution Log ---\n\n", req.Task)
+	go h.runAgentInvestigation(incidentUUID, taskHeader, req.Task)
+
+	api.RespondJSON(w, http.StatusCreated, api.CreateIncidentResponse{
+		UUID:       incidentUUID,
+		Status:     "pending",
+		WorkingDir: workingDir,
+		Message:    "Incident created and processing started",
+	})
 }
 
 // handleIncidentAlerts handles GET /api/incidents/{uuid}/alerts — returns the
-// alert rows attached to an incident, ordered by fired_at ASC.
+// alert rows attached to an incident, ordered by fired_at ASC. With
+// ?anonymize=true hostnames, IPs and usernames are replaced by pseudonyms.
 func (h *APIHandler) handleIncidentAlerts(w http.ResponseWriter, r *http.Request) {
 	uuid := r.PathValue("uuid")
+	anonymize, err := parseAnonymize(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	db := database.GetDB()
 
@@ -235,13 +222,29 @@ func (h *APIHandler) handleIncidentAlerts(w http.ResponseWriter, r *http.Request
 		api.RespondError(w, http.StatusInternalServerError, "Failed to get alerts")
 		return
 	}
+	if anonymize {
+		anonymizer := utils.NewAnonymizer()
+		services.SeedAnonymizer(anonymizer, alerts)
+		alerts = services.AnonymizeAlerts(anonymizer, alerts)
+	}
 
 	api.RespondJSON(w, http.StatusOK, alerts)
 }
 
-// handleIncidentByID handles GET /api/incidents/{uuid}
+// handleIncidentByID handles GET /api/incidents/{uuid}. Pass ?log=false to
+// leave full_log out of the response, for clients that page through the log
+// with /api/incidents/{uuid}/log?offset= instead.
 func (h *APIHandler) handleIncidentByID(w http.ResponseWriter, r *http.Request) {
 	uuid := r.PathValue("uuid")
+	includeLog := true
+	if v := r.URL.Query().Get("log"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "log must be true or false")
+			return
+		}
+		includeLog = b
+	}
 
 	incident, err := h.skillService.GetIncident(uuid)
 	if err != nil {
@@ -253,6 +256,27 @@ func (h *APIHandler) handleIncidentByID(w http.ResponseWriter, r *http.Request)
 	var cnt int64
 	db.Model(&database.Alert{}).Where("incident_uuid = ?", incident.UUID).Count(&cnt)
 	incident.AlertCount = cnt
+	incident.ApplyVocabulary(database.LoadVocabulary())
+	if includeLog {
+		incident.FullLog = utils.SanitizeLog(incident.FullLog)
+	} else {
+		incident.FullLog = ""
+	}
+	incident.Response = utils.SanitizeLog(incident.Response)
+
+	if h.incidentLinks != nil {
+		if relations, err := h.incidentLinks.GetRelations(r.Context(), incident.UUID); err == nil {
+			incident.Relations = relations
+		} else {
+			slog.WarnContext(r.Context(), "incident detail: failed to load relations", "uuid", incident.UUID, "err", err)
+		}
+	}
+
+	if changes, err := services.ListIncidentChanges(r.Context(), db, incident.UUID); err == nil {
+		incident.Changes = changes
+	} else {
+		slog.WarnContext(r.Context(), "incident detail: failed to load change manifest", "uuid", incident.UUID, "err", err)
+	}
 
 	api.RespondJSON(w, http.StatusOK, incident)
 }
@@ -278,7 +302,7 @@ func (h *APIHandler) handleIncidentResponse(w http.ResponseWriter, r *http.Reque
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			api.RespondError(w, http.StatusNotFound, "Incident not found")
 		} else {
-			slog.Error("incident response: failed to load", "uuid", incidentUUID, "err", err)
+			slog.ErrorContext(r.Context(), "incident response: failed to load", "uuid", incidentUUID, "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to load incident")
 		}
 		return
@@ -329,11 +353,47 @@ func (h *APIHandler) handleIncidentClose(w http.ResponseWriter, r *http.Request)
 			"in_progress":           confirmErr.InProgress,
 		})
 	default:
-		slog.Error("CloseIncident failed", "incident", incidentUUID, "err", err)
+		slog.ErrorContext(r.Context(), "CloseIncident failed", "incident_uuid", incidentUUID, "err", err)
 		api.RespondError(w, http.StatusInternalServerError, "Failed to close incident")
 	}
 }
 
+// handleIncidentCancel handles POST /api/incidents/{uuid}/cancel. It stops
+// the incident's investigation: the incident is recorded as cancelled with
+// the log streamed so far, and the agent worker is told to abandon the run.
+// Returns 404 if the incident is missing and 409 if it has no investigation
+// in progress.
+func (h *APIHandler) handleIncidentCancel(w http.ResponseWriter, r *http.Request) {
+	incidentUUID := r.PathValue("uuid")
+	user := middleware.GetUserFromContext(r.Context())
+	if user == "" {
+		user = "api"
+	}
+
+	err := h.skillService.CancelIncident(r.Context(), incidentUUID, user)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	case errors.Is(err, services.ErrIncidentNotRunning):
+		api.RespondError(w, http.StatusConflict, "incident has no investigation in progress")
+		return
+	default:
+		slog.ErrorContext(r.Context(), "CancelIncident failed", "incident_uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to cancel incident")
+		return
+	}
+
+	if h.agentWSHandler != nil {
+		if _, err := h.agentWSHandler.AbortRun(incidentUUID); err != nil && !errors.Is(err, ErrWorkerNotConnected) {
+			slog.WarnContext(r.Context(), "failed to tell the agent worker to cancel", "incident_uuid", incidentUUID, "err", err)
+		}
+	}
+	slog.InfoContext(r.Context(), "incident investigation cancelled", "incident_uuid", incidentUUID, "user", user)
+	api.RespondJSON(w, http.StatusOK, map[string]string{"status": string(database.IncidentStatusCancelled)})
+}
+
 // runAgentInvestigation runs a full agent investigation for the given incident.
 // It must be launched as a goroutine by the caller. taskHeader is prepended to
 // all log updates; task is the raw user-facing task text (guidance is added
@@ -344,9 +404,28 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 	}
 
 	taskWithGuidance := executor.PrependGuidance(task)
+	h.runAgent(incidentUUID, taskHeader, nil, func(llm *LLMSettingsForWorker, callback IncidentCallback) (string, error) {
+		return h.agentWSHandler.StartIncident(incidentUUID, taskWithGuidance, llm, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+	})
+}
 
-	if h.agentWSHandler != nil && h.agentWSHandler.IsWorkerConnected() {
-		slog.Info("using WebSocket-based agent worker for API incident", "incident_id", incidentUUID)
+// agentRunStarter hands an agent run to the worker and returns its run ID.
+type agentRunStarter func(llm *LLMSettingsForWorker, callback IncidentCallback) (string, error)
+
+// runAgent drives one agent run to completion and persists the outcome.
+// logPrefix is kept in front of every log update. prior is the incident a
+// follow-up continues (nil for a fresh investigation): its session, token
+// and time totals carry over into the stored result.
+func (h *APIHandler) runAgent(incidentUUID, logPrefix string, prior *database.Incident, start agentRunStarter) {
+	var priorSessionID string
+	var priorTokens int
+	var priorExecutionTimeMs int64
+	if prior != nil {
+		priorSessionID, priorTokens, priorExecutionTimeMs = prior.SessionID, prior.TokensUsed, prior.ExecutionTimeMs
+	}
+
+	if h.agentWSHandler != nil && h.agentWSHandler.WaitForWorker(context.Background()) {
+		slog.Info("using WebSocket-based agent worker for API incident", "incident_uuid", incidentUUID)
 
 		var llmSettings *LLMSettingsForWorker
 		if dbSettings, err := database.GetLLMSettings(); err == nil && dbSettings != nil {
@@ -367,7 +446,7 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 		callback := IncidentCallback{
 			OnOutput: func(output string) {
 				lastStreamedLog += output
-				if err := h.skillService.UpdateIncidentLog(incidentUUID, taskHeader+lastStreamedLog); err != nil {
+				if err := h.skillService.UpdateIncidentLog(incidentUUID, logPrefix+lastStreamedLog); err != nil {
 					slog.Error("failed to update incident log", "err", err)
 				}
 			},
@@ -392,11 +471,11 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 			},
 		}
 
-		runID, err := h.agentWSHandler.StartIncident(incidentUUID, taskWithGuidance, llmSettings, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
+		runID, err := start(llmSettings, callback)
 		if err != nil {
 			slog.Error("failed to start incident via WebSocket", "err", err)
 			errorMsg := fmt.Sprintf("Failed to start incident: %v", err)
-			if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", taskHeader, "❌ "+errorMsg, 0, 0); updateErr != nil {
+			if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, priorSessionID, logPrefix, "❌ "+errorMsg, priorTokens, priorExecutionTimeMs); updateErr != nil {
 				slog.Error("failed to update incident status", "err", updateErr)
 			}
 			return
@@ -406,14 +485,14 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 
 		// Replacement run owns DB finalization — exit silently.
 		if superseded.Load() {
-			slog.Info("API incident superseded; leaving finalization to the new run", "incident_id", incidentUUID)
+			slog.Info("API incident superseded; leaving finalization to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
 		// Apply the first matching formatting rule before persistence.
 		// Passthrough on error/empty or when no rule matches the
 		// incident's flow. Manual runs have no destination channel.
-		formattedResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, response, taskHeader+lastStreamedLog,
+		formattedResponse := applyResponseFormatter(context.Background(), h.responseFormatter, hasError, response, logPrefix+lastStreamedLog,
 			services.BuildFormatFlow(incidentUUID, ""))
 
 		// Re-attach the metrics footer AFTER formatting so the LLM
@@ -423,20 +502,21 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 		// the end of `incident.response`, so the web UI's metrics
 		// line stays correct even when the formatter rewrote the
 		// body.
-		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, finalExecutionTimeMs, finalTokensUsed, hasError)
-		rawWithMetrics := appendFinalizeMetrics(response, finalExecutionTimeMs, finalTokensUsed, hasError)
+		runMetrics := finalizeRunMetrics(lastStreamedLog, finalExecutionTimeMs, finalTokensUsed)
+		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, runMetrics, hasError)
+		rawWithMetrics := appendFinalizeMetrics(response, runMetrics, hasError)
 
 		// Claim ownership of finalization atomically. A second API
 		// call for the same incident_id displaces this run; without
 		// the ReleaseRun guard we'd race the replacement's DB write.
 		if !h.agentWSHandler.ReleaseRun(incidentUUID, runID) {
-			slog.Info("API incident displaced during finalization; leaving DB write to the new run", "incident_id", incidentUUID)
+			slog.Info("API incident displaced during finalization; leaving DB write to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
 		// Build full log using the raw response (with metrics) so
 		// full_log preserves the original agent output for debugging.
-		fullLog := taskHeader + lastStreamedLog
+		fullLog := logPrefix + lastStreamedLog
 		if rawWithMetrics != "" {
 			fullLog += "\n\n--- Final Response ---\n\n" + rawWithMetrics
 		}
@@ -445,17 +525,20 @@ func (h *APIHandler) runAgentInvestigation(incidentUUID, taskHeader, task string
 		if hasError {
 			finalStatus = database.IncidentStatusFailed
 		}
-		if err := h.skillService.UpdateIncidentComplete(incidentUUID, finalStatus, sessionID, fullLog, formattedWithMetrics, finalTokensUsed, finalExecutionTimeMs); err != nil {
+		if sessionID == "" {
+			sessionID = priorSessionID
+		}
+		if err := h.skillService.UpdateIncidentComplete(incidentUUID, finalStatus, sessionID, fullLog, formattedWithMetrics, priorTokens+finalTokensUsed, priorExecutionTimeMs+finalExecutionTimeMs); err != nil {
 			slog.Error("failed to update incident complete", "err", err)
 		}
 
-		slog.Info("API incident completed via WebSocket", "incident_id", incidentUUID)
+		slog.Info("API incident completed via WebSocket", "incident_uuid", incidentUUID)
 		return
 	}
 
-	slog.Error("agent worker not connected for API incident", "incident_id", incidentUUID)
+	slog.Error("agent worker not connected for API incident", "incident_uuid", incidentUUID)
 	errorMsg := "Agent worker not connected. Please check that the agent-worker container is running."
-	if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", taskHeader, "❌ "+errorMsg, 0, 0); updateErr != nil {
+	if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, priorSessionID, logPrefix, "❌ "+errorMsg, priorTokens, priorExecutionTimeMs); updateErr != nil {
 		slog.Error("failed to update incident status", "err", updateErr)
 	}
 }
@@ -499,7 +582,7 @@ func (h *APIHandler) handleAlertResolve(w http.ResponseWriter, r *http.Request)
 		case errors.Is(err, services.ErrAlertAlreadyResolved):
 			api.RespondError(w, http.StatusConflict, "alert is already resolved")
 		default:
-			slog.Error("ResolveAlert failed", "alert", alertUUID, "err", err)
+			slog.ErrorContext(r.Context(), "ResolveAlert failed", "alert", alertUUID, "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to resolve alert")
 		}
 		return
@@ -519,7 +602,7 @@ func (h *APIHandler) moveAlert(w http.ResponseWriter, r *http.Request, alertUUID
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			api.RespondError(w, http.StatusNotFound, "Alert not found")
 		} else {
-			slog.Error("moveAlert: db error loading alert", "alert", alertUUID, "err", err)
+			slog.ErrorContext(r.Context(), "moveAlert: db error loading alert", "alert", alertUUID, "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to load alert")
 		}
 		return
@@ -533,7 +616,7 @@ func (h *APIHandler) moveAlert(w http.ResponseWriter, r *http.Request, alertUUID
 		case errors.Is(err, services.ErrAlertAlreadyMoved):
 			api.RespondError(w, http.StatusConflict, "alert was moved by a concurrent request")
 		default:
-			slog.Error("MoveAlertToIncident failed", "alert", alertUUID, "target", target, "err", err)
+			slog.ErrorContext(r.Context(), "MoveAlertToIncident failed", "alert", alertUUID, "target", target, "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "Failed to move alert")
 		}
 		return
@@ -560,6 +643,72 @@ func (h *APIHandler) moveAlert(w http.ResponseWriter, r *http.Request, alertUUID
 	api.RespondJSON(w, http.StatusOK, map[string]string{"incident_uuid": resultIncidentUUID})
 }
 
+// applyIncidentListFilters applies the GET /api/incidents query filters:
+//
+//   - from / to: created_at bounds as unix seconds or RFC3339
+//   - status: see applyIncidentStatusFilter
+//   - source: comma-separated Incident.Source values (e.g. "alertmanager,slack")
+//   - source_kind: comma-separated trigger kinds ("alert", "cron", ...)
+//   - severity: comma-separated alert severities from the incident context
+//   - search (alias q): UUID prefix, or case-insensitive substring of the
+//     title, final response, or alert summary; % and _ match literally
+//
+// Unparseable values are ignored rather than rejected, matching the
+// behaviour of the other list endpoints.
+func applyIncidentListFilters(query *gorm.DB, r *http.Request) *gorm.DB {
+	q := r.URL.Query()
+
+	if from := parseTimeQueryParam(q.Get("from")); from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to := parseTimeQueryParam(q.Get("to")); to != nil {
+		query = query.Where("created_at <= ?", *to)
+	}
+	if statusParam := q.Get("status"); statusParam != "" {
+		query = applyIncidentStatusFilter(query, statusParam)
+	}
+	if sources := splitCSV(q.Get("source")); len(sources) > 0 {
+		query = query.Where("source IN ?", sources)
+	}
+	if kinds := splitCSV(q.Get("source_kind")); len(kinds) > 0 {
+		query = query.Where("source_kind IN ?", kinds)
+	}
+	if severities := splitCSV(strings.ToLower(q.Get("severity"))); len(severities) > 0 {
+		// ->> extracts JSON text on both PostgreSQL (jsonb) and SQLite 3.38+.
+		query = query.Where("LOWER(context->>'severity') IN ?", severities)
+	}
+
+	search := strings.TrimSpace(q.Get("search"))
+	if search == "" {
+		search = strings.TrimSpace(q.Get("q"))
+	}
+	if search != "" {
+		term := escapeLike(strings.ToLower(search))
+		prefix := term + "%"
+		like := "%" + term + "%"
+		query = query.Where(
+			`LOWER(uuid) LIKE ? ESCAPE '\' OR LOWER(title) LIKE ? ESCAPE '\' OR LOWER(response) LIKE ? ESCAPE '\' OR LOWER(context->>'summary') LIKE ? ESCAPE '\'`,
+			prefix, like, like, like)
+	}
+	return query
+}
+
+// parseTimeQueryParam parses a unix-seconds or RFC3339 timestamp. Returns nil
+// for empty or invalid input.
+func parseTimeQueryParam(v string) *time.Time {
+	if v == "" {
+		return nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		t := time.Unix(secs, 0)
+		return &t
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t
+	}
+	return nil
+}
+
 // applyIncidentStatusFilter applies a comma-separated ?status= filter to an
 // incidents query. Besides the real IncidentStatus values, it recognizes the
 // pseudo-token "alert_active" — an alert-sourced incident only stays
@@ -591,6 +740,15 @@ func applyIncidentStatusFilter(query *gorm.DB, statusParam string) *gorm.DB {
 	return query.Where(strings.Join(conds, " OR "), args...)
 }
 
+// likeEscaper escapes the LIKE wildcards and the escape character itself, for
+// patterns written with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes s match itself literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 // splitCSV splits a comma-separated string into a trimmed, non-empty slice.
 func splitCSV(s string) []string {
 	parts := strings.Split(s, ",")
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+	"github.com/google/uuid"
+)
+
+// seedFilterIncident inserts an incident for the list-filter tests.
+func seedFilterIncident(t *testing.T, inc database.Incident) string {
+	t.Helper()
+	if inc.UUID == "" {
+		inc.UUID = uuid.New().String()
+	}
+	if inc.Status == "" {
+		inc.Status = database.IncidentStatusCompleted
+	}
+	if inc.SourceKind == "" {
+		inc.SourceKind = database.IncidentSourceKindManual
+	}
+	if err := database.GetDB().Create(&inc).Error; err != nil {
+		t.Fatalf("seed incident: %v", err)
+	}
+	return inc.UUID
+}
+
+func incidentUUIDs(rows []map[string]any) map[string]bool {
+	out := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		out[row["uuid"].(string)] = true
+	}
+	return out
+}
+
+func TestHandleIncidents_SourceAndSeverityFilters(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	critical := seedFilterIncident(t, database.Incident{
+		Source: "alertmanager", SourceKind: database.IncidentSourceKindAlert,
+		Context: database.JSONB{"severity": "critical"},
+	})
+	warning := seedFilterIncident(t, database.Incident{
+		Source: "zabbix", SourceKind: database.IncidentSourceKindAlert,
+		Context: database.JSONB{"severity": "warning"},
+	})
+	slack := seedFilterIncident(t, database.Incident{Source: "slack"})
+
+	rows, meta := doIncidentListRequest(t, "source=alertmanager,slack")
+	got := incidentUUIDs(rows)
+	if meta.Total != 2 || !got[critical] || !got[slack] {
+		t.Errorf("source filter: total=%d rows=%v", meta.Total, got)
+	}
+
+	rows, meta = doIncidentListRequest(t, "severity=CRITICAL")
+	got = incidentUUIDs(rows)
+	if meta.Total != 1 || !got[critical] {
+		t.Errorf("severity filter: total=%d rows=%v", meta.Total, got)
+	}
+
+	rows, _ = doIncidentListRequest(t, "source_kind=alert&severity=warning,critical")
+	got = incidentUUIDs(rows)
+	if len(got) != 2 || !got[critical] || !got[warning] {
+		t.Errorf("combined filter: rows=%v", got)
+	}
+}
+
+func TestHandleIncidents_SearchFilter(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	byTitle := seedFilterIncident(t, database.Incident{Source: "api", Title: "Disk full on DB-01"})
+	byResponse := seedFilterIncident(t, database.Incident{Source: "api", Response: "Root cause: disk FULL after log rotation failed"})
+	bySummary := seedFilterIncident(t, database.Incident{
+		Source: "alertmanager", SourceKind: database.IncidentSourceKindAlert,
+		Context: database.JSONB{"summary": "Filesystem /var is 98% full"},
+	})
+	seedFilterIncident(t, database.Incident{Source: "api", Title: "CPU high"})
+
+	rows, meta := doIncidentListRequest(t, "search=full")
+	got := incidentUUIDs(rows)
+	if meta.Total != 3 || !got[byTitle] || !got[byResponse] || !got[bySummary] {
+		t.Errorf("search: total=%d rows=%v", meta.Total, got)
+	}
+
+	rows, _ = doIncidentListRequest(t, "q="+byTitle[:8])
+	got = incidentUUIDs(rows)
+	if len(got) != 1 || !got[byTitle] {
+		t.Errorf("uuid prefix search: rows=%v", got)
+	}
+}
+
+func TestHandleIncidents_SearchFilterMatchesWildcardsLiterally(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	percent := seedFilterIncident(t, database.Incident{Source: "api", Title: "Disk 98% full"})
+	seedFilterIncident(t, database.Incident{Source: "api", Title: "Disk 980 GB full"})
+	underscore := seedFilterIncident(t, database.Incident{Source: "api", Title: "Job nightly_backup failed"})
+	seedFilterIncident(t, database.Incident{Source: "api", Title: "Job nightly-backup failed"})
+	backslash := seedFilterIncident(t, database.Incident{Source: "api", Title: `Path C:\temp is full`})
+
+	for _, tt := range []struct {
+		search string
+		want   string
+	}{
+		{"98%25", percent},
+		{"%25", percent},
+		{"nightly_backup", underscore},
+		{"c:%5Ctemp", backslash},
+	} {
+		rows, meta := doIncidentListRequest(t, "search="+tt.search)
+		got := incidentUUIDs(rows)
+		if meta.Total != 1 || !got[tt.want] {
+			t.Errorf("search=%s: total=%d rows=%v", tt.search, meta.Total, got)
+		}
+	}
+}
+
+func TestHandleIncidents_DateRangeAndLimit(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := seedFilterIncident(t, database.Incident{Source: "api"})
+	recent := seedFilterIncident(t, database.Incident{Source: "api"})
+	seedFilterIncident(t, database.Incident{Source: "api"})
+	if err := db.Model(&database.Incident{}).Where("uuid = ?", old).
+		Update("created_at", now.Add(-48*time.Hour)).Error; err != nil {
+		t.Fatalf("backdate incident: %v", err)
+	}
+	if err := db.Model(&database.Incident{}).Where("uuid = ?", recent).
+		Update("created_at", now.Add(-2*time.Hour)).Error; err != nil {
+		t.Fatalf("backdate incident: %v", err)
+	}
+
+	from := now.Add(-3 * time.Hour).Format(time.RFC3339)
+	to := now.Add(-time.Hour).Format(time.RFC3339)
+	rows, meta := doIncidentListRequest(t, "from="+from+"&to="+to)
+	got := incidentUUIDs(rows)
+	if meta.Total != 1 || !got[recent] {
+		t.Errorf("RFC3339 range: total=%d rows=%v", meta.Total, got)
+	}
+
+	rows, meta = doIncidentListRequest(t, "limit=2&page=2")
+	if len(rows) != 1 || meta.Total != 3 || meta.PerPage != 2 || meta.TotalPages != 2 {
+		t.Errorf("limit pagination: rows=%d meta=%+v", len(rows), meta)
+	}
+}
@@ -18,7 +18,7 @@ import (
 // that have associated alert rows.
 func TestHandleIncidents_TrendEnrichment(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 	db := database.GetDB()
@@ -67,7 +67,7 @@ func TestHandleIncidents_TrendEnrichment(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/incidents?trend_window=1h", nil)
 	rec := httptest.NewRecorder()
-	h.handleIncidents(rec, req)
+	serveAPI(h, rec, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
@@ -128,7 +128,7 @@ func TestHandleIncidents_TrendEnrichment(t *testing.T) {
 // rows get a zero-filled 12-element trend slice.
 func TestHandleIncidents_NoAlerts_ZeroTrend(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 	db := database.GetDB()
@@ -148,7 +148,7 @@ func TestHandleIncidents_NoAlerts_ZeroTrend(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/incidents", nil)
 	rec := httptest.NewRecorder()
-	h.handleIncidents(rec, req)
+	serveAPI(h, rec, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
@@ -189,7 +189,7 @@ func TestHandleIncidents_NoAlerts_ZeroTrend(t *testing.T) {
 // appears in the trend when ?trend_window=3h but not with ?trend_window=1h.
 func TestHandleIncidents_TrendWindow_3h(t *testing.T) {
 	testhelpers.NewGlobalSQLiteDB(t,
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 	)
 	db := database.GetDB()
@@ -225,7 +225,7 @@ func TestHandleIncidents_TrendWindow_3h(t *testing.T) {
 	getSum := func(window string) int {
 		req := httptest.NewRequest(http.MethodGet, "/api/incidents?trend_window="+window, nil)
 		rec := httptest.NewRecorder()
-		h.handleIncidents(rec, req)
+		serveAPI(h, rec, req)
 		if rec.Code != http.StatusOK {
 			t.Fatalf("expected 200 for window=%s, got %d: %s", window, rec.Code, rec.Body.String())
 		}
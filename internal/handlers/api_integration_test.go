@@ -27,30 +27,32 @@ func TestAPIHandler_RequestValidation(t *testing.T) {
 		method         string
 		path           string
 		body           string
-		handler        http.HandlerFunc
 		expectedStatus int
 	}{
 		{
-			name:           "skills sync - method not allowed GET",
-			method:         http.MethodGet,
+			name:           "skills sync - method not allowed PATCH",
+			method:         http.MethodPatch,
 			path:           "/api/skills/sync",
-			handler:        h.handleSkillsSync,
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
 		{
-			name:           "skills sync - method not allowed DELETE",
-			method:         http.MethodDelete,
-			path:           "/api/skills/sync",
-			handler:        h.handleSkillsSync,
+			name:           "tool types - method not allowed POST",
+			method:         http.MethodPost,
+			path:           "/api/tool-types",
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
 		{
-			name:           "skills sync - method not allowed PUT",
-			method:         http.MethodPut,
-			path:           "/api/skills/sync",
-			handler:        h.handleSkillsSync,
+			name:           "proposals - method not allowed DELETE",
+			method:         http.MethodDelete,
+			path:           "/api/proposals",
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
+		{
+			name:           "unknown route",
+			method:         http.MethodGet,
+			path:           "/api/does-not-exist",
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -61,7 +63,7 @@ func TestAPIHandler_RequestValidation(t *testing.T) {
 			}
 			testhelpers.NewHTTPTestContext(t, tt.method, tt.path, body).
 				WithHeader("Content-Type", "application/json").
-				ExecuteFunc(tt.handler).
+				ExecuteFunc(apiRoutes(h).ServeHTTP).
 				AssertStatus(tt.expectedStatus)
 		})
 	}
@@ -282,10 +284,10 @@ func TestHTTPTestContext_Chaining(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	t.Run("method chaining works correctly", func(t *testing.T) {
-		ctx := testhelpers.NewHTTPTestContext(t, http.MethodGet, "/api/skills/sync", nil)
+		ctx := testhelpers.NewHTTPTestContext(t, http.MethodPatch, "/api/skills/sync", nil)
 		ctx.
 			WithHeader("X-Custom-Header", "test-value").
-			ExecuteFunc(h.handleSkillsSync).
+			ExecuteFunc(apiRoutes(h).ServeHTTP).
 			AssertStatus(http.StatusMethodNotAllowed)
 	})
 
@@ -405,39 +407,6 @@ func TestAPIHandler_ErrorResponseFormat(t *testing.T) {
 	}
 }
 
-// ========================================
-// Split Path Edge Cases
-// ========================================
-
-func TestSplitPath_IntegrationEdgeCases(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected []string
-	}{
-		{"URL encoded path", "/api/skills/my%20skill", []string{"api", "skills", "my%20skill"}},
-		{"path with dots", "/api/v1.0/skills", []string{"api", "v1.0", "skills"}},
-		{"path with special chars", "/api/skill-name_v2/test", []string{"api", "skill-name_v2", "test"}},
-		{"numeric segments", "/api/123/456", []string{"api", "123", "456"}},
-		{"mixed case", "/API/Skills/MySkill", []string{"API", "Skills", "MySkill"}},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := splitPath(tt.input)
-			if len(result) != len(tt.expected) {
-				t.Errorf("splitPath(%q) = %v, want %v", tt.input, result, tt.expected)
-				return
-			}
-			for i := range result {
-				if result[i] != tt.expected[i] {
-					t.Errorf("splitPath(%q)[%d] = %q, want %q", tt.input, i, result[i], tt.expected[i])
-				}
-			}
-		})
-	}
-}
-
 // ========================================
 // API Request Helpers Tests
 // ========================================
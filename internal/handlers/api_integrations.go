@@ -110,8 +110,8 @@ func toIntegrationResponses(rows []database.Integration) []integrationResponse {
 // CreateIntegrationRequest is the request body for POST /api/integrations.
 // Credentials are stored verbatim as JSONB; their shape is provider-specific.
 type CreateIntegrationRequest struct {
-	Provider    string         `json:"provider"`
-	Name        string         `json:"name"`
+	Provider    string         `json:"provider" validate:"notblank"`
+	Name        string         `json:"name" validate:"notblank"`
 	Credentials database.JSONB `json:"credentials,omitempty"`
 	Enabled     *bool          `json:"enabled,omitempty"`
 }
@@ -125,125 +125,101 @@ type UpdateIntegrationRequest struct {
 	Enabled     *bool           `json:"enabled,omitempty"`
 }
 
-// handleIntegrations dispatches GET /api/integrations and POST /api/integrations.
-func (h *APIHandler) handleIntegrations(w http.ResponseWriter, r *http.Request) {
-	if h.channelService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Channel service is not configured")
+// listIntegrations handles GET /api/integrations.
+func (h *APIHandler) listIntegrations(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.channelService.ListIntegrations()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list integrations")
 		return
 	}
+	api.RespondJSON(w, http.StatusOK, toIntegrationResponses(rows))
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		rows, err := h.channelService.ListIntegrations()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list integrations")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toIntegrationResponses(rows))
+// createIntegration handles POST /api/integrations.
+func (h *APIHandler) createIntegration(w http.ResponseWriter, r *http.Request) {
+	var req CreateIntegrationRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-	case http.MethodPost:
-		var req CreateIntegrationRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	provider := strings.TrimSpace(req.Provider)
+	if !h.isProviderKnown(database.MessagingProvider(provider)) {
+		api.RespondError(w, http.StatusBadRequest, "provider is not a known messaging provider")
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
 
-		provider := strings.TrimSpace(req.Provider)
-		if provider == "" {
-			api.RespondError(w, http.StatusBadRequest, "provider is required")
-			return
-		}
-		if !h.isProviderKnown(database.MessagingProvider(provider)) {
-			api.RespondError(w, http.StatusBadRequest, "provider is not a known messaging provider")
-			return
-		}
-		if strings.TrimSpace(req.Name) == "" {
-			api.RespondError(w, http.StatusBadRequest, "name is required")
-			return
-		}
-		enabled := true
-		if req.Enabled != nil {
-			enabled = *req.Enabled
-		}
+	row, err := h.channelService.CreateIntegration(database.MessagingProvider(provider), req.Name, req.Credentials, enabled)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to create integration")
+		return
+	}
+	// Credentials and the enabled flag on an Integration drive whether the
+	// Slack manager connects and which listener channels are active. Fire
+	// both reload paths so a freshly-added Slack integration takes effect
+	// without restarting the API.
+	h.afterIntegrationMutation(row.Provider)
+	api.RespondJSON(w, http.StatusCreated, toIntegrationResponse(row))
+}
 
-		row, err := h.channelService.CreateIntegration(database.MessagingProvider(provider), req.Name, req.Credentials, enabled)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		// Credentials and the enabled flag on an Integration drive whether the
-		// Slack manager connects and which listener channels are active. Fire
-		// both reload paths so a freshly-added Slack integration takes effect
-		// without restarting the API.
-		h.afterIntegrationMutation(row.Provider)
-		api.RespondJSON(w, http.StatusCreated, toIntegrationResponse(row))
+// getIntegration handles GET /api/integrations/{uuid}.
+func (h *APIHandler) getIntegration(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	row, err := h.channelService.GetIntegrationByUUID(uuid)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to get integration")
+		return
 	}
+	api.RespondJSON(w, http.StatusOK, toIntegrationResponse(row))
 }
 
-// handleIntegrationByUUID dispatches GET/PUT/DELETE /api/integrations/{uuid}.
-func (h *APIHandler) handleIntegrationByUUID(w http.ResponseWriter, r *http.Request) {
-	if h.channelService == nil {
-		api.RespondError(w, http.StatusServiceUnavailable, "Channel service is not configured")
+// updateIntegration handles PUT /api/integrations/{uuid}.
+func (h *APIHandler) updateIntegration(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
+
+	var req UpdateIntegrationRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
 		return
 	}
-
-	uuid := strings.TrimPrefix(r.URL.Path, "/api/integrations/")
-	if uuid == "" || strings.Contains(uuid, "/") {
-		api.RespondError(w, http.StatusBadRequest, "Invalid integration UUID")
+	var creds database.JSONB
+	if req.Credentials != nil {
+		creds = database.JSONB(*req.Credentials)
+	}
+	row, err := h.channelService.UpdateIntegration(uuid, req.Name, creds, req.Enabled)
+	if err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to update integration")
 		return
 	}
+	// Credential rotations and enabled toggles affect the live Slack
+	// connection and which listener channels are loaded; trigger both
+	// reload paths so the change is observable without restart.
+	h.afterIntegrationMutation(row.Provider)
+	api.RespondJSON(w, http.StatusOK, toIntegrationResponse(row))
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		row, err := h.channelService.GetIntegrationByUUID(uuid)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, toIntegrationResponse(row))
-
-	case http.MethodPut:
-		var req UpdateIntegrationRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		var creds database.JSONB
-		if req.Credentials != nil {
-			creds = database.JSONB(*req.Credentials)
-		}
-		row, err := h.channelService.UpdateIntegration(uuid, req.Name, creds, req.Enabled)
-		if err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		// Credential rotations and enabled toggles affect the live Slack
-		// connection and which listener channels are loaded; trigger both
-		// reload paths so the change is observable without restart.
-		h.afterIntegrationMutation(row.Provider)
-		api.RespondJSON(w, http.StatusOK, toIntegrationResponse(row))
-
-	case http.MethodDelete:
-		// Capture the provider before delete so we can decide whether the
-		// Slack manager needs a reload after the row is gone.
-		row, lookupErr := h.channelService.GetIntegrationByUUID(uuid)
-		if err := h.channelService.DeleteIntegration(uuid); err != nil {
-			api.RespondError(w, integrationErrStatus(err), err.Error())
-			return
-		}
-		var provider database.MessagingProvider
-		if lookupErr == nil && row != nil {
-			provider = row.Provider
-		}
-		h.afterIntegrationMutation(provider)
-		api.RespondNoContent(w)
+// deleteIntegration handles DELETE /api/integrations/{uuid}.
+func (h *APIHandler) deleteIntegration(w http.ResponseWriter, r *http.Request) {
+	uuid := r.PathValue("uuid")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	// Capture the provider before delete so we can decide whether the
+	// Slack manager needs a reload after the row is gone.
+	row, lookupErr := h.channelService.GetIntegrationByUUID(uuid)
+	if err := h.channelService.DeleteIntegration(uuid); err != nil {
+		api.RespondServiceError(w, r, integrationErrStatus(err), err, "Failed to delete integration")
+		return
+	}
+	var provider database.MessagingProvider
+	if lookupErr == nil && row != nil {
+		provider = row.Provider
 	}
+	h.afterIntegrationMutation(provider)
+	api.RespondNoContent(w)
 }
 
 // afterIntegrationMutation fans the post-CRUD signals out so the runtime
@@ -242,7 +242,7 @@ func TestHandleIntegrations_List(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -263,7 +263,7 @@ func TestHandleIntegrations_ListError(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500, got %d", w.Code)
@@ -283,7 +283,7 @@ func TestHandleIntegrations_Create(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -307,7 +307,7 @@ func TestHandleIntegrations_Create_InvalidProvider(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
@@ -330,9 +330,9 @@ func TestHandleIntegrations_Create_RequiredFields(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-			h.handleIntegrations(w, req)
-			if w.Code != http.StatusBadRequest {
-				t.Fatalf("expected 400, got %d", w.Code)
+			serveAPI(h, w, req)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d", w.Code)
 			}
 		})
 	}
@@ -344,7 +344,7 @@ func TestHandleIntegrations_NotConfigured(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("expected 503, got %d", w.Code)
 	}
@@ -357,7 +357,7 @@ func TestHandleIntegrationByUUID_Get(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -370,7 +370,7 @@ func TestHandleIntegrationByUUID_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations/missing", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
@@ -386,7 +386,7 @@ func TestHandleIntegrationByUUID_Update(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/integrations/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -403,7 +403,7 @@ func TestHandleIntegrationByUUID_Delete(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/integrations/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("expected 204, got %d", w.Code)
 	}
@@ -417,7 +417,7 @@ func TestHandleIntegrationByUUID_MethodNotAllowed(t *testing.T) {
 	h := newHandlerWithChannelManager(&mockChannelManager{integrations: []database.Integration{{UUID: "u1"}}})
 	req := httptest.NewRequest(http.MethodPatch, "/api/integrations/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -434,7 +434,7 @@ func TestHandleIntegrations_Create_PropagatesValidationError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400 for plain validation message, got %d", w.Code)
 	}
@@ -458,7 +458,7 @@ func TestHandleIntegrations_Create_TriggersAlertChannelReload(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -485,7 +485,7 @@ func TestHandleIntegrationByUUID_Update_TriggersReload(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/integrations/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -509,7 +509,7 @@ func TestHandleIntegrationByUUID_Delete_TriggersReload(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/integrations/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("expected 204, got %d", w.Code)
@@ -591,7 +591,7 @@ func TestHandleIntegrations_AcceptsRegistryProviderEvenIfModelDoesNot(t *testing
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d", w.Code)
 	}
@@ -603,7 +603,7 @@ func TestHandleIntegrations_Create_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader([]byte("not json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -615,7 +615,7 @@ func TestHandleIntegrations_MethodNotAllowed(t *testing.T) {
 	h := newHandlerWithChannelManager(&mockChannelManager{})
 	req := httptest.NewRequest(http.MethodPatch, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("expected 405, got %d", w.Code)
 	}
@@ -627,21 +627,21 @@ func TestHandleIntegrationByUUID_ServiceUnavailable(t *testing.T) {
 	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations/u1", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("expected 503, got %d", w.Code)
 	}
 }
 
-// TestHandleIntegrationByUUID_InvalidUUID rejects paths with embedded slashes
-// rather than treating them as nested resources.
+// TestHandleIntegrationByUUID_InvalidUUID finds no route for paths with
+// embedded slashes rather than treating them as nested resources.
 func TestHandleIntegrationByUUID_InvalidUUID(t *testing.T) {
 	h := newHandlerWithChannelManager(&mockChannelManager{})
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations/u1/extra", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d", w.Code)
+	serveAPI(h, w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -651,7 +651,7 @@ func TestHandleIntegrationByUUID_Update_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/integrations/u1", bytes.NewReader([]byte("not json")))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
@@ -668,7 +668,7 @@ func TestHandleIntegrationByUUID_Update_PropagatesCredentials(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/integrations/u1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
 	}
@@ -693,7 +693,7 @@ func TestHandleIntegrations_MasksCredentialsInResponses(t *testing.T) {
 	// GET /api/integrations
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("list: expected 200, got %d", w.Code)
 	}
@@ -710,7 +710,7 @@ func TestHandleIntegrations_MasksCredentialsInResponses(t *testing.T) {
 	// GET /api/integrations/u1
 	req = httptest.NewRequest(http.MethodGet, "/api/integrations/u1", nil)
 	w = httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("get: expected 200, got %d", w.Code)
 	}
@@ -727,7 +727,7 @@ func TestHandleIntegrations_MasksCredentialsInResponses(t *testing.T) {
 	req = httptest.NewRequest(http.MethodPost, "/api/integrations", bytes.NewReader(postBody))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
 	}
@@ -753,7 +753,7 @@ func TestHandleIntegrations_MasksUnknownProviderSecrets(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/integrations", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrations(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
@@ -777,7 +777,7 @@ func TestHandleIntegrationByUUID_Delete_PropagatesNotFound(t *testing.T) {
 	h := newHandlerWithChannelManager(mgr)
 	req := httptest.NewRequest(http.MethodDelete, "/api/integrations/ghost", nil)
 	w := httptest.NewRecorder()
-	h.handleIntegrationByUUID(w, req)
+	serveAPI(h, w, req)
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
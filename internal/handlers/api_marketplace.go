@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetMarketplace wires the skill marketplace behind /api/marketplace.
+// Optional — when unset (no index URL configured) the endpoints return 503.
+func (h *APIHandler) SetMarketplace(m services.SkillMarketplace) {
+	h.marketplace = m
+}
+
+// handleMarketplaceIndex handles GET /api/marketplace: the remote index of
+// community skills and tool types, flagged with what is already installed.
+func (h *APIHandler) handleMarketplaceIndex(w http.ResponseWriter, r *http.Request) {
+	if h.marketplace == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Skill marketplace is not configured")
+		return
+	}
+	index, err := h.marketplace.Index(r.Context())
+	if err != nil {
+		h.respondMarketplaceError(w, err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, index)
+}
+
+// handleMarketplaceInstall handles POST /api/marketplace/skills/{name}/install.
+// The bundle is downloaded and its signature verified before anything is
+// written.
+func (h *APIHandler) handleMarketplaceInstall(w http.ResponseWriter, r *http.Request) {
+	if h.marketplace == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Skill marketplace is not configured")
+		return
+	}
+	name := r.PathValue("name")
+	result, err := h.marketplace.InstallSkill(r.Context(), name)
+	if err != nil {
+		h.respondMarketplaceError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "installed skill from marketplace", "skill", name, "version", result.Version, "missing_tool_types", result.MissingToolTypes)
+	api.RespondJSON(w, http.StatusCreated, result)
+}
+
+func (h *APIHandler) respondMarketplaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrMarketplaceSkillNotFound):
+		api.RespondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrSkillExists):
+		api.RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrBundleVerification), errors.Is(err, services.ErrInvalidSkillBundle):
+		api.RespondError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, services.ErrMarketplaceUnavailable):
+		api.RespondError(w, http.StatusBadGateway, err.Error())
+	default:
+		slog.Error("marketplace request failed", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Marketplace request failed")
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+type fakeMarketplace struct {
+	installErr error
+}
+
+func (f *fakeMarketplace) Index(context.Context) (*services.MarketplaceIndex, error) {
+	return &services.MarketplaceIndex{Skills: []services.MarketplaceSkill{{Name: "pg-replica", Version: "1.0.0"}}}, nil
+}
+
+func (f *fakeMarketplace) InstallSkill(_ context.Context, name string) (*services.SkillInstallResult, error) {
+	if f.installErr != nil {
+		return nil, f.installErr
+	}
+	return &services.SkillInstallResult{Skill: &database.Skill{Name: name}, Version: "1.0.0"}, nil
+}
+
+func TestMarketplaceAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/marketplace", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	market := &fakeMarketplace{}
+	h.SetMarketplace(market)
+	if rec := serveJSON(mux, http.MethodGet, "/api/marketplace", ""); rec.Code != http.StatusOK {
+		t.Errorf("index status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/marketplace/skills/pg-replica/install", ""); rec.Code != http.StatusCreated {
+		t.Errorf("install status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for err, want := range map[error]int{
+		services.ErrMarketplaceSkillNotFound: http.StatusNotFound,
+		services.ErrSkillExists:              http.StatusConflict,
+		services.ErrBundleVerification:       http.StatusUnprocessableEntity,
+		services.ErrInvalidSkillBundle:       http.StatusUnprocessableEntity,
+		services.ErrMarketplaceUnavailable:   http.StatusBadGateway,
+	} {
+		market.installErr = fmt.Errorf("%w: detail", err)
+		if rec := serveJSON(mux, http.MethodPost, "/api/marketplace/skills/pg-replica/install", ""); rec.Code != want {
+			t.Errorf("%v: status = %d, want %d", err, rec.Code, want)
+		}
+	}
+}
@@ -86,7 +86,7 @@ func TestHandleMCPServers_List(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/mcp-servers", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", w.Code)
@@ -114,7 +114,7 @@ func TestHandleMCPServers_ListError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/mcp-servers", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", w.Code)
@@ -138,7 +138,7 @@ func TestHandleMCPServers_Create(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Errorf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -174,7 +174,7 @@ func TestHandleMCPServers_Create_StdioTransport(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Errorf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -217,10 +217,10 @@ func TestHandleMCPServers_Create_MissingFields(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			h.handleMCPServers(w, req)
+			serveAPI(h, w, req)
 
-			if w.Code != http.StatusBadRequest {
-				t.Errorf("expected 400, got %d", w.Code)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("expected 422, got %d", w.Code)
 			}
 		})
 	}
@@ -244,7 +244,7 @@ func TestHandleMCPServers_Create_Conflict(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Errorf("expected 409, got %d", w.Code)
@@ -269,7 +269,7 @@ func TestHandleMCPServers_Create_ValidationError(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
@@ -283,7 +283,7 @@ func TestHandleMCPServers_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/mcp-servers", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServers(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -301,7 +301,7 @@ func TestHandleMCPServerByID_Get(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/mcp-servers/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", w.Code)
@@ -326,7 +326,7 @@ func TestHandleMCPServerByID_GetNotFound(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/mcp-servers/999", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -340,7 +340,7 @@ func TestHandleMCPServerByID_InvalidID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/mcp-servers/abc", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
@@ -365,7 +365,7 @@ func TestHandleMCPServerByID_Update(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -387,7 +387,7 @@ func TestHandleMCPServerByID_UpdateNotFound(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -409,7 +409,7 @@ func TestHandleMCPServerByID_UpdateConflict(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Errorf("expected 409, got %d", w.Code)
@@ -424,7 +424,7 @@ func TestHandleMCPServerByID_Delete(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/mcp-servers/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNoContent {
 		t.Errorf("expected 204, got %d", w.Code)
@@ -441,7 +441,7 @@ func TestHandleMCPServerByID_DeleteNotFound(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/mcp-servers/999", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -455,7 +455,7 @@ func TestHandleMCPServerByID_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPatch, "/api/mcp-servers/1", nil)
 	w := httptest.NewRecorder()
 
-	h.handleMCPServerByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -3,7 +3,6 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/akmatori/akmatori/internal/api"
@@ -31,148 +30,127 @@ type IncidentFeedbackRequest struct {
 	Text string `json:"text"`
 }
 
-// handleMemories handles GET (list, with ?scope= and ?type= filters) and POST.
-func (h *APIHandler) handleMemories(w http.ResponseWriter, r *http.Request) {
-	if h.memoryService == nil {
-		api.RespondError(w, http.StatusInternalServerError, "memory service not available")
+// listMemories handles GET /api/memories.
+func (h *APIHandler) listMemories(w http.ResponseWriter, r *http.Request) {
+	scope := r.URL.Query().Get("scope")
+	memType := r.URL.Query().Get("type")
+	if memType != "" && !services.ValidMemoryType(memType) {
+		api.RespondError(w, http.StatusBadRequest, "invalid type filter")
 		return
 	}
-	switch r.Method {
-	case http.MethodGet:
-		scope := r.URL.Query().Get("scope")
-		memType := r.URL.Query().Get("type")
-		if memType != "" && !services.ValidMemoryType(memType) {
-			api.RespondError(w, http.StatusBadRequest, "invalid type filter")
-			return
-		}
-		memories, err := h.memoryService.ListMemories(scope, memType)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "failed to list memories")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, memories)
-
-	case http.MethodPost:
-		var req MemoryRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "invalid request body")
-			return
-		}
-		m := &database.Memory{
-			Scope:        req.Scope,
-			Type:         req.Type,
-			Name:         req.Name,
-			Description:  req.Description,
-			Body:         req.Body,
-			IncidentUUID: req.IncidentUUID,
-			CreatedBy:    req.CreatedBy,
-		}
-		created, err := h.memoryService.CreateMemory(m)
-		if err != nil {
-			respondMemoryWriteError(w, err)
-			return
-		}
-		// Skill-scoped writes must trigger SKILL.md regeneration; the memory
-		// manifest is embedded into SKILL.md at write time, so the existing
-		// file would otherwise still show the pre-create state until restart.
-		h.regenerateSkillForMemoryScope(created.Scope)
-		api.RespondJSON(w, http.StatusCreated, created)
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	memories, err := h.memoryService.ListMemories(scope, memType)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "failed to list memories")
+		return
 	}
+	api.RespondJSON(w, http.StatusOK, memories)
 }
 
-// handleMemoryByID dispatches GET/PUT/DELETE on /api/memories/{id} and the
-// nested /api/memories/scopes endpoint (handled inline so the route table
-// doesn't fight Go's ServeMux precedence rules).
-func (h *APIHandler) handleMemoryByID(w http.ResponseWriter, r *http.Request) {
-	if h.memoryService == nil {
-		api.RespondError(w, http.StatusInternalServerError, "memory service not available")
+// createMemory handles POST /api/memories.
+func (h *APIHandler) createMemory(w http.ResponseWriter, r *http.Request) {
+	var req MemoryRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
 		return
 	}
-	tail := strings.TrimPrefix(r.URL.Path, "/api/memories/")
-	if tail == "scopes" {
-		h.handleMemoryScopes(w, r)
-		return
+	m := &database.Memory{
+		Scope:        req.Scope,
+		Type:         req.Type,
+		Name:         req.Name,
+		Description:  req.Description,
+		Body:         req.Body,
+		IncidentUUID: req.IncidentUUID,
+		CreatedBy:    req.CreatedBy,
 	}
-
-	id, err := strconv.ParseUint(tail, 10, 32)
+	created, err := h.memoryService.CreateMemory(m)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, "invalid memory ID")
+		respondMemoryWriteError(w, r, err)
 		return
 	}
+	// Skill-scoped writes must trigger SKILL.md regeneration; the memory
+	// manifest is embedded into SKILL.md at write time, so the existing
+	// file would otherwise still show the pre-create state until restart.
+	h.regenerateSkillForMemoryScope(created.Scope)
+	api.RespondJSON(w, http.StatusCreated, created)
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		m, err := h.memoryService.GetMemory(uint(id))
-		if err != nil {
-			api.RespondError(w, http.StatusNotFound, "memory not found")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, m)
+// getMemory handles GET /api/memories/{id}.
+func (h *APIHandler) getMemory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "memory")
+	if !ok {
+		return
+	}
 
-	case http.MethodPut:
-		var req MemoryRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "invalid request body")
-			return
-		}
-		// Capture the prior scope BEFORE update so a cross-scope move
-		// (e.g. "redis" → "postgres") can refresh both affected SKILL.mds.
-		var priorScope string
-		if existing, err := h.memoryService.GetMemory(uint(id)); err == nil {
-			priorScope = existing.Scope
-		}
-		m := &database.Memory{
-			Scope:        req.Scope,
-			Type:         req.Type,
-			Name:         req.Name,
-			Description:  req.Description,
-			Body:         req.Body,
-			IncidentUUID: req.IncidentUUID,
-			CreatedBy:    req.CreatedBy,
-		}
-		updated, err := h.memoryService.UpdateMemory(uint(id), m)
-		if err != nil {
-			respondMemoryWriteError(w, err)
-			return
-		}
-		h.regenerateSkillForMemoryScope(priorScope)
-		if updated.Scope != priorScope {
-			h.regenerateSkillForMemoryScope(updated.Scope)
-		}
-		api.RespondJSON(w, http.StatusOK, updated)
+	m, err := h.memoryService.GetMemory(id)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "memory not found")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, m)
+}
 
-	case http.MethodDelete:
-		// Read scope before delete so we can regenerate the right SKILL.md
-		// after the row is gone.
-		var priorScope string
-		if existing, err := h.memoryService.GetMemory(uint(id)); err == nil {
-			priorScope = existing.Scope
-		}
-		if err := h.memoryService.DeleteMemory(uint(id)); err != nil {
-			respondMemoryWriteError(w, err)
-			return
-		}
-		h.regenerateSkillForMemoryScope(priorScope)
-		api.RespondNoContent(w)
+// updateMemory handles PUT /api/memories/{id}.
+func (h *APIHandler) updateMemory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "memory")
+	if !ok {
+		return
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	var req MemoryRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	// Capture the prior scope BEFORE update so a cross-scope move
+	// (e.g. "redis" → "postgres") can refresh both affected SKILL.mds.
+	var priorScope string
+	if existing, err := h.memoryService.GetMemory(id); err == nil {
+		priorScope = existing.Scope
+	}
+	m := &database.Memory{
+		Scope:        req.Scope,
+		Type:         req.Type,
+		Name:         req.Name,
+		Description:  req.Description,
+		Body:         req.Body,
+		IncidentUUID: req.IncidentUUID,
+		CreatedBy:    req.CreatedBy,
+	}
+	updated, err := h.memoryService.UpdateMemory(id, m)
+	if err != nil {
+		respondMemoryWriteError(w, r, err)
+		return
 	}
+	h.regenerateSkillForMemoryScope(priorScope)
+	if updated.Scope != priorScope {
+		h.regenerateSkillForMemoryScope(updated.Scope)
+	}
+	api.RespondJSON(w, http.StatusOK, updated)
 }
 
-// handleMemoryScopes returns the distinct scope strings present in the table.
-func (h *APIHandler) handleMemoryScopes(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+// deleteMemory handles DELETE /api/memories/{id}.
+func (h *APIHandler) deleteMemory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "memory")
+	if !ok {
 		return
 	}
-	if h.memoryService == nil {
-		api.RespondError(w, http.StatusInternalServerError, "memory service not available")
+
+	// Read scope before delete so we can regenerate the right SKILL.md
+	// after the row is gone.
+	var priorScope string
+	if existing, err := h.memoryService.GetMemory(id); err == nil {
+		priorScope = existing.Scope
+	}
+	if err := h.memoryService.DeleteMemory(id); err != nil {
+		respondMemoryWriteError(w, r, err)
 		return
 	}
+	h.regenerateSkillForMemoryScope(priorScope)
+	api.RespondNoContent(w)
+}
+
+// handleMemoryScopes returns the distinct scope strings present in the table.
+func (h *APIHandler) handleMemoryScopes(w http.ResponseWriter, r *http.Request) {
 	scopes, err := h.memoryService.ListAllScopes()
 	if err != nil {
 		api.RespondError(w, http.StatusInternalServerError, "failed to list scopes")
@@ -198,7 +176,7 @@ func (h *APIHandler) handleIncidentFeedback(w http.ResponseWriter, r *http.Reque
 
 	var req IncidentFeedbackRequest
 	if err := api.DecodeJSON(r, &req); err != nil {
-		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		api.RespondDecodeError(w, err)
 		return
 	}
 	text := strings.TrimSpace(req.Text)
@@ -236,7 +214,7 @@ func (h *APIHandler) handleIncidentFeedback(w http.ResponseWriter, r *http.Reque
 	}
 	created, err := h.memoryService.UpsertByName(m)
 	if err != nil {
-		respondMemoryWriteError(w, err)
+		respondMemoryWriteError(w, r, err)
 		return
 	}
 	api.RespondJSON(w, http.StatusCreated, created)
@@ -306,15 +284,13 @@ func (h *APIHandler) regenerateSkillForMemoryScope(scope string) {
 
 // respondMemoryWriteError maps service-layer errors onto HTTP statuses.
 // Validation problems → 400, missing rows → 404, sync issues → 500.
-func respondMemoryWriteError(w http.ResponseWriter, err error) {
-	if services.IsMemoryNotFoundErr(err) {
-		api.RespondError(w, http.StatusNotFound, err.Error())
-		return
-	}
-	msg := err.Error()
-	if strings.Contains(msg, "file sync failed") {
-		api.RespondError(w, http.StatusInternalServerError, msg)
-		return
+func respondMemoryWriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case services.IsMemoryNotFoundErr(err):
+		status = http.StatusNotFound
+	case strings.Contains(err.Error(), "file sync failed"):
+		status = http.StatusInternalServerError
 	}
-	api.RespondError(w, http.StatusBadRequest, msg)
+	api.RespondServiceError(w, r, status, err, "Failed to save memory")
 }
@@ -543,7 +543,10 @@ func (r *recordingSkillService) UpdateIncidentStatus(string, database.IncidentSt
 func (r *recordingSkillService) UpdateIncidentComplete(string, database.IncidentStatus, string, string, string, int, int64) error {
 	return nil
 }
-func (r *recordingSkillService) UpdateIncidentLog(string, string) error         { return nil }
+func (r *recordingSkillService) UpdateIncidentLog(string, string) error { return nil }
+func (r *recordingSkillService) ReadIncidentLog(string, int, int) (string, int, error) {
+	return "", 0, nil
+}
 func (r *recordingSkillService) GetIncident(string) (*database.Incident, error) { return nil, nil }
 func (r *recordingSkillService) AppendSubagentLog(string, string, string) error { return nil }
 func (r *recordingSkillService) InsertFiringAlert(context.Context, string, string, alerts.NormalizedAlert, string, string) error {
@@ -558,8 +561,15 @@ func (r *recordingSkillService) UnlinkAlertFromIncident(context.Context, string)
 func (r *recordingSkillService) MoveAlertToIncident(context.Context, string, string) (string, error) {
 	return "", nil
 }
-func (r *recordingSkillService) ResolveAlert(context.Context, string) error        { return nil }
-func (r *recordingSkillService) CloseIncident(context.Context, string, bool) error { return nil }
+func (r *recordingSkillService) ResolveAlert(context.Context, string) error           { return nil }
+func (r *recordingSkillService) CloseIncident(context.Context, string, bool) error    { return nil }
+func (r *recordingSkillService) CancelIncident(context.Context, string, string) error { return nil }
+func (r *recordingSkillService) BeginFollowUp(string, string) (*database.Incident, error) {
+	return nil, nil
+}
+func (r *recordingSkillService) BeginRetry(string, string) (*database.Incident, error) {
+	return nil, nil
+}
 
 // newMemoryAPIHandlerWithSkill wires both a memory mock and a skill
 // regeneration recorder. Used by tests that need to verify skill-scoped
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetNotificationTemplateManager wires the service backing
+// /api/notification-templates. Optional — when unset the endpoints return
+// 503 and notifications use the built-in text.
+func (h *APIHandler) SetNotificationTemplateManager(svc services.NotificationTemplateManager) {
+	h.notificationTemplates = svc
+}
+
+// listNotificationTemplates handles GET /api/notification-templates.
+func (h *APIHandler) listNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.notificationTemplates.ListTemplates()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list notification templates")
+		return
+	}
+	if templates == nil {
+		templates = []database.NotificationTemplate{}
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"kinds":     services.NotificationKinds(),
+		"templates": templates,
+	})
+}
+
+// createNotificationTemplate handles POST /api/notification-templates.
+func (h *APIHandler) createNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateNotificationTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	tmpl := &database.NotificationTemplate{
+		Kind:    strings.TrimSpace(req.Kind),
+		Locale:  strings.TrimSpace(req.Locale),
+		Body:    req.Body,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	if err := h.notificationTemplates.CreateTemplate(tmpl); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	api.RespondJSON(w, http.StatusCreated, tmpl)
+}
+
+// updateNotificationTemplate handles PUT /api/notification-templates/{id}.
+func (h *APIHandler) updateNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "template")
+	if !ok {
+		return
+	}
+
+	var req api.UpdateNotificationTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	current, err := h.notificationTemplates.GetTemplate(id)
+	if err != nil {
+		respondNotificationTemplateError(w, err)
+		return
+	}
+	body, enabled := current.Body, current.Enabled
+	if req.Body != nil {
+		body = *req.Body
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	updated, err := h.notificationTemplates.UpdateTemplate(id, body, enabled)
+	if err != nil {
+		respondNotificationTemplateError(w, err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, updated)
+}
+
+// deleteNotificationTemplate handles DELETE /api/notification-templates/{id}.
+func (h *APIHandler) deleteNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "template")
+	if !ok {
+		return
+	}
+
+	if err := h.notificationTemplates.DeleteTemplate(id); err != nil {
+		respondNotificationTemplateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleNotificationTemplatePreview handles POST
+// /api/notification-templates/preview: renders a draft body (or the body in
+// effect) against sample alert data without saving anything.
+func (h *APIHandler) handleNotificationTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	var req api.PreviewNotificationTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	kind := services.NotificationKind(strings.TrimSpace(req.Kind))
+	locale := strings.TrimSpace(req.Locale)
+	if !services.IsValidNotificationKind(string(kind)) {
+		api.RespondError(w, http.StatusBadRequest, "Unknown notification kind")
+		return
+	}
+	if !services.IsValidNotificationLocale(locale) {
+		api.RespondError(w, http.StatusBadRequest, "Invalid locale")
+		return
+	}
+
+	body := req.Body
+	if strings.TrimSpace(body) == "" {
+		if h.notificationTemplates != nil {
+			body = h.notificationTemplates.EffectiveBody(kind, locale)
+		} else {
+			body = services.DefaultNotificationBody(kind)
+		}
+	}
+
+	data := services.SampleNotificationData()
+	data.Locale = locale
+	rendered, err := services.RenderNotificationTemplate(body, data)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid template: "+err.Error())
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]string{
+		"kind":     string(kind),
+		"body":     body,
+		"rendered": rendered,
+	})
+}
+
+// handleTemplateVariables handles GET /api/template-variables: the catalog of
+// alert variables and helper functions accepted by notification templates and
+// alert source prompt templates, with a sample rendering of each variable.
+func (h *APIHandler) handleTemplateVariables(w http.ResponseWriter, r *http.Request) {
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"variables": services.TemplateVariables(),
+		"functions": services.TemplateFunctions(),
+		"sample":    services.SampleNotificationData(),
+	})
+}
+
+func respondNotificationTemplateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrNotificationTemplateNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Notification template not found")
+		return
+	}
+	api.RespondError(w, http.StatusBadRequest, err.Error())
+}
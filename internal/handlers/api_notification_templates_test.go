@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func newNotificationTemplateMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.NotificationTemplate{}, &database.GeneralSettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetNotificationTemplateManager(services.NewNotificationTemplateService(db))
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	return mux
+}
+
+func serveJSON(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNotificationTemplatesAPI_CRUD(t *testing.T) {
+	mux := newNotificationTemplateMux(t)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/notification-templates", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Kinds     []services.NotificationKindInfo `json:"kinds"`
+		Templates []database.NotificationTemplate `json:"templates"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list.Kinds) == 0 || list.Kinds[0].DefaultBody == "" || len(list.Templates) != 0 {
+		t.Fatalf("unexpected initial list: %+v", list)
+	}
+
+	rec = serveJSON(mux, http.MethodPost, "/api/notification-templates",
+		`{"kind":"alert_resolved","locale":"de","body":"Behoben: {{.AlertName}}"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created database.NotificationTemplate
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created: %v", err)
+	}
+	if !created.Enabled || created.ID == 0 {
+		t.Errorf("created = %+v, want enabled with ID", created)
+	}
+
+	rec = serveJSON(mux, http.MethodPost, "/api/notification-templates",
+		`{"kind":"alert_resolved","body":"{{.AlertName"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid body create status = %d, want 400", rec.Code)
+	}
+
+	path := "/api/notification-templates/" + strconv.FormatUint(uint64(created.ID), 10)
+	rec = serveJSON(mux, http.MethodPut, path, `{"enabled":false}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"enabled":false`) {
+		t.Errorf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec = serveJSON(mux, http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec = serveJSON(mux, http.MethodDelete, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestNotificationTemplatesAPI_Preview(t *testing.T) {
+	mux := newNotificationTemplateMux(t)
+
+	rec := serveJSON(mux, http.MethodPost, "/api/notification-templates/preview",
+		`{"kind":"alert_posted","body":"[{{upper .Severity}}] {{.AlertName}} team={{.Labels.team}}"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if resp["rendered"] != "[CRITICAL] HighCPUUsage team=platform" {
+		t.Errorf("rendered = %q", resp["rendered"])
+	}
+
+	// Empty body previews the template in effect (built-in here).
+	rec = serveJSON(mux, http.MethodPost, "/api/notification-templates/preview", `{"kind":"alert_resolved"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Alert resolved: HighCPUUsage") {
+		t.Errorf("effective preview = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveJSON(mux, http.MethodPost, "/api/notification-templates/preview", `{"kind":"alert_posted","body":"{{.Nope}}"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("broken preview status = %d, want 400", rec.Code)
+	}
+}
+
+func TestTemplateVariablesAPI(t *testing.T) {
+	mux := newNotificationTemplateMux(t)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/template-variables", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Variables []services.TemplateVariable `json:"variables"`
+		Functions []string                    `json:"functions"`
+		Sample    map[string]interface{}      `json:"sample"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var labels *services.TemplateVariable
+	for i := range resp.Variables {
+		if resp.Variables[i].Name == ".Labels" {
+			labels = &resp.Variables[i]
+		}
+	}
+	if labels == nil || len(labels.Scopes) != 2 {
+		t.Errorf(".Labels entry = %+v, want prompt and notification scopes", labels)
+	}
+	if len(resp.Functions) == 0 || resp.Sample["AlertName"] != "HighCPUUsage" {
+		t.Errorf("unexpected catalog: functions=%v sample=%v", resp.Functions, resp.Sample)
+	}
+}
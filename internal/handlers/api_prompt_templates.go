@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetPromptTemplateManager wires the service backing /api/settings/prompts.
+// Optional — when unset the endpoints return 503 and investigations use the
+// built-in prompt.
+func (h *APIHandler) SetPromptTemplateManager(svc services.PromptTemplateManager) {
+	h.promptTemplates = svc
+}
+
+// listPromptTemplates handles GET /api/settings/prompts.
+func (h *APIHandler) listPromptTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.promptTemplates.ListTemplates()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list prompt templates")
+		return
+	}
+	if templates == nil {
+		templates = []database.PromptTemplate{}
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"default_body": services.DefaultInvestigationPromptBody,
+		"variables":    services.PromptVariables(),
+		"templates":    templates,
+	})
+}
+
+// createPromptTemplate handles POST /api/settings/prompts.
+func (h *APIHandler) createPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	var req api.CreatePromptTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	tmpl := &database.PromptTemplate{
+		SourceType: strings.TrimSpace(req.SourceType),
+		Skill:      strings.TrimSpace(req.Skill),
+		Body:       req.Body,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if err := h.promptTemplates.CreateTemplate(tmpl); err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	api.RespondJSON(w, http.StatusCreated, tmpl)
+}
+
+// updatePromptTemplate handles PUT /api/settings/prompts/{id}.
+func (h *APIHandler) updatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "template")
+	if !ok {
+		return
+	}
+
+	var req api.UpdatePromptTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	current, err := h.promptTemplates.GetTemplate(id)
+	if err != nil {
+		respondPromptTemplateError(w, err)
+		return
+	}
+	body, enabled := current.Body, current.Enabled
+	if req.Body != nil {
+		body = *req.Body
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	updated, err := h.promptTemplates.UpdateTemplate(id, body, enabled)
+	if err != nil {
+		respondPromptTemplateError(w, err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, updated)
+}
+
+// deletePromptTemplate handles DELETE /api/settings/prompts/{id}.
+func (h *APIHandler) deletePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "template")
+	if !ok {
+		return
+	}
+
+	if err := h.promptTemplates.DeleteTemplate(id); err != nil {
+		respondPromptTemplateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePromptTemplatePreview handles POST /api/settings/prompts/preview:
+// renders a draft body (or the body in effect for the scope) against sample
+// alert data without saving anything.
+func (h *APIHandler) handlePromptTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	var req api.PreviewPromptTemplateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	sourceType := strings.TrimSpace(req.SourceType)
+	skill := strings.TrimSpace(req.Skill)
+
+	body := req.Body
+	if strings.TrimSpace(body) == "" {
+		body = services.DefaultInvestigationPromptBody
+		if h.promptTemplates != nil {
+			var skills []string
+			if skill != "" {
+				skills = []string{skill}
+			}
+			body = h.promptTemplates.EffectiveBody(sourceType, skills)
+		}
+	}
+
+	rendered, err := services.RenderInvestigationPrompt(body, services.SampleInvestigationPromptData())
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid template: "+err.Error())
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]string{
+		"body":     body,
+		"rendered": rendered,
+	})
+}
+
+func respondPromptTemplateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrPromptTemplateNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Prompt template not found")
+		return
+	}
+	api.RespondError(w, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestPromptTemplatesAPI(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.PromptTemplate{}, &database.Skill{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/settings/prompts", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	h.SetPromptTemplateManager(services.NewPromptTemplateService(db))
+
+	rec := serveJSON(mux, http.MethodGet, "/api/settings/prompts", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		DefaultBody string                    `json:"default_body"`
+		Variables   []services.PromptVariable `json:"variables"`
+		Templates   []database.PromptTemplate `json:"templates"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.DefaultBody != services.DefaultInvestigationPromptBody || len(list.Variables) == 0 || len(list.Templates) != 0 {
+		t.Fatalf("initial list = %+v", list)
+	}
+
+	for _, body := range []string{
+		`{"source_type":"alertmanager","body":"{{alertname}}"}`,
+		`{"source_type":"alertmanager","body":""}`,
+		`{"skill":"missing","body":"x"}`,
+	} {
+		if rec := serveJSON(mux, http.MethodPost, "/api/settings/prompts", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("create %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec = serveJSON(mux, http.MethodPost, "/api/settings/prompts",
+		`{"source_type":"alertmanager","body":"Look at {{alert_name}} on {{host}} ({{labels.team}})"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created database.PromptTemplate
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if !created.Enabled || created.SourceType != "alertmanager" {
+		t.Errorf("created = %+v", created)
+	}
+	path := "/api/settings/prompts/" + strconv.FormatUint(uint64(created.ID), 10)
+
+	rec = serveJSON(mux, http.MethodPost, "/api/settings/prompts/preview", `{"source_type":"alertmanager"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Look at HighCPUUsage on web-01 (platform)") {
+		t.Errorf("preview status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serveJSON(mux, http.MethodPost, "/api/settings/prompts/preview", `{"body":"{{.Nope}}"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid preview status = %d, want 400", rec.Code)
+	}
+
+	if rec := serveJSON(mux, http.MethodPut, path, `{"body":"{{if}}"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid update status = %d, want 400", rec.Code)
+	}
+	rec = serveJSON(mux, http.MethodPut, path, `{"enabled":false}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"enabled":false`) {
+		t.Errorf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodDelete, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAlertHandler_PromptTemplates(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.PromptTemplate{}, &database.Skill{})
+	db.Create(&database.Skill{Name: "disk-usage"})
+	svc := services.NewPromptTemplateService(db)
+	for _, tmpl := range []database.PromptTemplate{
+		{SourceType: "alertmanager", Body: "AM: {{alert_name}}", Enabled: true},
+		{SourceType: "alertmanager", Skill: "disk-usage", Body: "AM disk: {{alert_name}} on {{host}}", Enabled: true},
+	} {
+		if err := svc.CreateTemplate(&tmpl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
+	h.SetPromptRenderer(svc)
+
+	alert := alerts.NormalizedAlert{AlertName: "DiskFull", TargetHost: "db-01"}
+	instance := &database.AlertSourceInstance{
+		Name:            "prod",
+		AlertSourceType: database.AlertSourceType{Name: "alertmanager", DisplayName: "Prometheus Alertmanager"},
+		Settings:        database.JSONB{services.PromptTemplateSettingKey: "Page the DBA team."},
+	}
+	got := h.buildInvestigationPrompt(alert, instance)
+	if !strings.HasPrefix(got, "AM: DiskFull\n\nAdditional instructions for this alert source:\nPage the DBA team.") {
+		t.Errorf("source template prompt = %q", got)
+	}
+
+	instance.Skills = []database.Skill{{Name: "disk-usage", Enabled: true}}
+	if got := h.buildInvestigationPrompt(alert, instance); !strings.HasPrefix(got, "AM disk: DiskFull on db-01") {
+		t.Errorf("pinned skill prompt = %q", got)
+	}
+
+	instance.AlertSourceType.Name = "zabbix"
+	instance.Skills = nil
+	if got := h.buildInvestigationPrompt(alert, instance); !strings.HasPrefix(got, "Investigate this Prometheus Alertmanager alert:") {
+		t.Errorf("unmatched source prompt = %q", got)
+	}
+}
@@ -24,10 +24,6 @@ func (h *APIHandler) handleProposals(w http.ResponseWriter, r *http.Request) {
 		api.RespondError(w, http.StatusServiceUnavailable, "proposal service not available")
 		return
 	}
-	if r.Method != http.MethodGet {
-		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
-	}
 
 	status := strings.TrimSpace(r.URL.Query().Get("status"))
 	kind := strings.TrimSpace(r.URL.Query().Get("kind"))
@@ -35,7 +31,7 @@ func (h *APIHandler) handleProposals(w http.ResponseWriter, r *http.Request) {
 
 	rows, total, err := h.proposalService.ListProposals(status, kind, params.PerPage, params.Offset())
 	if err != nil {
-		slog.Error("failed to list proposals", "err", err)
+		slog.ErrorContext(r.Context(), "failed to list proposals", "err", err)
 		api.RespondError(w, http.StatusInternalServerError, "failed to list proposals")
 		return
 	}
@@ -109,7 +105,7 @@ func (h *APIHandler) handleProposalApprove(w http.ResponseWriter, r *http.Reques
 		})
 	default:
 		// Apply failure: the row carries status=apply_failed + apply_error.
-		slog.Error("proposal apply failed", "uuid", r.PathValue("uuid"), "err", err)
+		slog.ErrorContext(r.Context(), "proposal apply failed", "uuid", r.PathValue("uuid"), "err", err)
 		api.RespondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
 			"error":    err.Error(),
 			"proposal": p,
@@ -200,7 +196,7 @@ func (h *APIHandler) handleProposalChatPost(w http.ResponseWriter, r *http.Reque
 
 	var req proposalChatRequest
 	if err := api.DecodeJSON(r, &req); err != nil {
-		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		api.RespondDecodeError(w, err)
 		return
 	}
 	message := strings.TrimSpace(req.Message)
@@ -267,12 +263,12 @@ func (h *APIHandler) handleProposalChatPost(w http.ResponseWriter, r *http.Reque
 		var err error
 		chatIncidentUUID, _, err = h.skillService.SpawnAgentInvocation("proposal-editor", incidentCtx)
 		if err != nil {
-			slog.Error("failed to spawn proposal chat incident", "proposal", p.UUID, "err", err)
+			slog.ErrorContext(r.Context(), "failed to spawn proposal chat incident", "proposal", p.UUID, "err", err)
 			api.RespondError(w, http.StatusInternalServerError, "failed to start proposal chat")
 			return
 		}
 		if err := h.proposalService.SetChatIncident(p.UUID, chatIncidentUUID); err != nil {
-			slog.Error("failed to link chat incident to proposal", "proposal", p.UUID, "err", err)
+			slog.ErrorContext(r.Context(), "failed to link chat incident to proposal", "proposal", p.UUID, "err", err)
 		}
 	}
 
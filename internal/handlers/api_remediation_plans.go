@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetRemediationPlanManager wires the service backing
+// /api/incidents/{uuid}/plan. Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetRemediationPlanManager(m services.RemediationPlanManager) {
+	h.remediationPlans = m
+}
+
+// handleGetRemediationPlan handles GET /api/incidents/{uuid}/plan, the most
+// recent plan a two-phase investigation proposed for the incident.
+func (h *APIHandler) handleGetRemediationPlan(w http.ResponseWriter, r *http.Request) {
+	if h.remediationPlans == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Remediation plans are not configured")
+		return
+	}
+	plan, err := h.remediationPlans.GetPlan(r.Context(), r.PathValue("uuid"))
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusOK, plan)
+	case errors.Is(err, services.ErrRemediationPlanNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident has no remediation plan")
+	default:
+		slog.ErrorContext(r.Context(), "remediation plans: failed to get", "incident_uuid", r.PathValue("uuid"), "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get remediation plan")
+	}
+}
+
+// handleApproveRemediationPlan handles POST /api/incidents/{uuid}/plan/approve.
+// The agent session is resumed in the background to carry out the plan.
+func (h *APIHandler) handleApproveRemediationPlan(w http.ResponseWriter, r *http.Request) {
+	h.decideRemediationPlan(w, r, true)
+}
+
+// handleRejectRemediationPlan handles POST /api/incidents/{uuid}/plan/reject.
+func (h *APIHandler) handleRejectRemediationPlan(w http.ResponseWriter, r *http.Request) {
+	h.decideRemediationPlan(w, r, false)
+}
+
+func (h *APIHandler) decideRemediationPlan(w http.ResponseWriter, r *http.Request, approve bool) {
+	if h.remediationPlans == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Remediation plans are not configured")
+		return
+	}
+	var req api.DecideApprovalRequest
+	if r.ContentLength != 0 {
+		if err := api.DecodeJSON(r, &req); err != nil {
+			api.RespondDecodeError(w, err)
+			return
+		}
+	}
+
+	incidentUUID := r.PathValue("uuid")
+	plan, err := h.remediationPlans.DecidePlan(r.Context(), incidentUUID, approve, middleware.GetUserFromContext(r.Context()), req.Reason)
+	switch {
+	case err == nil:
+		api.RespondJSON(w, http.StatusOK, plan)
+	case errors.Is(err, services.ErrRemediationPlanNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Incident has no remediation plan")
+	case errors.Is(err, services.ErrPlanNotPending):
+		api.RespondError(w, http.StatusConflict, "Remediation plan was already decided")
+	case errors.Is(err, services.ErrIncidentNotFinished):
+		api.RespondError(w, http.StatusConflict, "The agent is still working on this incident")
+	case errors.Is(err, services.ErrWorkerNotConnected):
+		api.RespondError(w, http.StatusServiceUnavailable, "Agent worker is not connected")
+	default:
+		slog.ErrorContext(r.Context(), "remediation plans: failed to decide", "incident_uuid", incidentUUID, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to record decision")
+	}
+}
+
+// ExecutePlan implements services.PlanExecutor: it starts phase two of a
+// two-phase investigation by resuming the incident's agent session with the
+// approved plan, the same way a follow-up message does.
+func (h *APIHandler) ExecutePlan(plan *database.RemediationPlan) error {
+	if h.agentWSHandler == nil || !h.agentWSHandler.IsWorkerConnected() {
+		return services.ErrWorkerNotConnected
+	}
+	logHeader := fmt.Sprintf("\n\n--- Remediation plan approved by %s ---\n\n--- Execution Log ---\n\n", plan.DecidedBy)
+	prior, err := h.skillService.BeginFollowUp(plan.IncidentUUID, logHeader)
+	if err != nil {
+		return err
+	}
+	slog.Info("executing approved remediation plan", "incident_uuid", plan.IncidentUUID, "plan", plan.UUID)
+	go h.runIncidentFollowUp(prior, prior.FullLog+logHeader, executePlanMessage(plan))
+	return nil
+}
+
+// executePlanMessage is the phase-two prompt. It keeps the agent to the
+// plan a human signed off on.
+func executePlanMessage(plan *database.RemediationPlan) string {
+	msg := fmt.Sprintf("%s approved your remediation plan. Carry it out now, step by step, then verify that "+
+		"the problem is resolved. Do not go beyond the approved steps: if a step fails or the situation "+
+		"has changed since your diagnosis, stop and report what you found instead.\n\nApproved plan:\n%s",
+		plan.DecidedBy, plan.Plan)
+	if plan.Reason != "" {
+		msg += "\n\nReviewer note: " + plan.Reason
+	}
+	return msg
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/slack-go/slack"
+)
+
+type stubPlanManager struct {
+	decided map[string]bool
+}
+
+func (s *stubPlanManager) GetPlan(_ context.Context, incidentUUID string) (*database.RemediationPlan, error) {
+	if incidentUUID == "missing" {
+		return nil, services.ErrRemediationPlanNotFound
+	}
+	return &database.RemediationPlan{IncidentUUID: incidentUUID, Status: database.RemediationPlanPending}, nil
+}
+
+func (s *stubPlanManager) DecidePlan(_ context.Context, incidentUUID string, approve bool, _, _ string) (*database.RemediationPlan, error) {
+	switch incidentUUID {
+	case "missing":
+		return nil, services.ErrRemediationPlanNotFound
+	case "done":
+		return &database.RemediationPlan{IncidentUUID: incidentUUID}, services.ErrPlanNotPending
+	case "busy":
+		return nil, services.ErrIncidentNotFinished
+	case "offline":
+		return nil, services.ErrWorkerNotConnected
+	}
+	s.decided[incidentUUID] = approve
+	return &database.RemediationPlan{IncidentUUID: incidentUUID}, nil
+}
+
+func TestRemediationPlanAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/incidents/inc-1/plan", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	stub := &stubPlanManager{decided: map[string]bool{}}
+	h.SetRemediationPlanManager(stub)
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/incidents/inc-1/plan", http.StatusOK},
+		{http.MethodGet, "/api/incidents/missing/plan", http.StatusNotFound},
+		{http.MethodPost, "/api/incidents/inc-1/plan/approve", http.StatusOK},
+		{http.MethodPost, "/api/incidents/inc-2/plan/reject", http.StatusOK},
+		{http.MethodPost, "/api/incidents/missing/plan/approve", http.StatusNotFound},
+		{http.MethodPost, "/api/incidents/done/plan/approve", http.StatusConflict},
+		{http.MethodPost, "/api/incidents/busy/plan/approve", http.StatusConflict},
+		{http.MethodPost, "/api/incidents/offline/plan/approve", http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		if rec := serveJSON(mux, tc.method, tc.path, ""); rec.Code != tc.want {
+			t.Errorf("%s %s: status = %d, want %d (%s)", tc.method, tc.path, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+	if len(stub.decided) != 2 || !stub.decided["inc-1"] || stub.decided["inc-2"] {
+		t.Errorf("decided = %v", stub.decided)
+	}
+}
+
+func TestSlackHandler_PlanButtons(t *testing.T) {
+	h := NewSlackHandler(nil, nil, nil, nil, nil)
+	stub := &stubPlanManager{decided: map[string]bool{}}
+	h.SetRemediationPlanManager(stub)
+
+	for _, action := range []*slack.BlockAction{
+		{ActionID: planApproveActionID, Value: "inc-1"},
+		{ActionID: planRejectActionID, Value: "inc-2"},
+		// No approval manager is wired; tool approval clicks are ignored.
+		{ActionID: approvalApproveActionID, Value: "ap-1"},
+	} {
+		h.handleInteraction(slack.InteractionCallback{
+			Type:           slack.InteractionTypeBlockActions,
+			User:           slack.User{ID: "U1", Name: "alice"},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{action}},
+		})
+	}
+	if len(stub.decided) != 2 || !stub.decided["inc-1"] || stub.decided["inc-2"] {
+		t.Errorf("decided = %v", stub.decided)
+	}
+}
+
+func TestPlanApprovalRequired(t *testing.T) {
+	h := NewAlertHandler(nil, nil, nil, nil, nil, nil, nil)
+	source := &database.AlertSourceInstance{Settings: database.JSONB{services.PlanApprovalSettingKey: true}}
+	if h.planApprovalRequired(source) {
+		t.Error("plan approval required without a planner")
+	}
+	h.SetRemediationPlanner(services.NewRemediationPlanService(nil))
+	if !h.planApprovalRequired(source) {
+		t.Error("plan_approval=true not honoured")
+	}
+	if h.planApprovalRequired(&database.AlertSourceInstance{Settings: database.JSONB{services.PlanApprovalSettingKey: "maybe"}}) {
+		t.Error("invalid setting treated as enabled")
+	}
+}
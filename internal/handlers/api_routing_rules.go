@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetRoutingRuleManager wires the service backing /api/routing-rules.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetRoutingRuleManager(m services.RoutingRuleManager) {
+	h.routingRules = m
+}
+
+// handleListRoutingRules handles GET /api/routing-rules. Rules are returned
+// in evaluation order.
+func (h *APIHandler) handleListRoutingRules(w http.ResponseWriter, r *http.Request) {
+	if h.routingRules == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Routing rules are not configured")
+		return
+	}
+	rules, err := h.routingRules.ListRoutingRules(r.Context())
+	if err != nil {
+		h.respondRoutingRuleError(w, "list", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rules)
+}
+
+// handleCreateRoutingRule handles POST /api/routing-rules.
+func (h *APIHandler) handleCreateRoutingRule(w http.ResponseWriter, r *http.Request) {
+	if h.routingRules == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Routing rules are not configured")
+		return
+	}
+	var req api.RoutingRuleRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	rule := routingRuleFromRequest(&req)
+	if err := h.routingRules.CreateRoutingRule(r.Context(), rule, req.NotificationChannelUUID, trimmedNames(req.SkillNames)); err != nil {
+		h.respondRoutingRuleError(w, "create", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusCreated, rule)
+}
+
+// handleGetRoutingRule handles GET /api/routing-rules/{uuid}.
+func (h *APIHandler) handleGetRoutingRule(w http.ResponseWriter, r *http.Request) {
+	if h.routingRules == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Routing rules are not configured")
+		return
+	}
+	rule, err := h.routingRules.GetRoutingRule(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.respondRoutingRuleError(w, "get", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rule)
+}
+
+// handleUpdateRoutingRule handles PUT /api/routing-rules/{uuid}.
+func (h *APIHandler) handleUpdateRoutingRule(w http.ResponseWriter, r *http.Request) {
+	if h.routingRules == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Routing rules are not configured")
+		return
+	}
+	var req api.RoutingRuleRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	rule, err := h.routingRules.UpdateRoutingRule(r.Context(), r.PathValue("uuid"), routingRuleFromRequest(&req),
+		req.NotificationChannelUUID, trimmedNames(req.SkillNames))
+	if err != nil {
+		h.respondRoutingRuleError(w, "update", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteRoutingRule handles DELETE /api/routing-rules/{uuid}.
+func (h *APIHandler) handleDeleteRoutingRule(w http.ResponseWriter, r *http.Request) {
+	if h.routingRules == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Routing rules are not configured")
+		return
+	}
+	if err := h.routingRules.DeleteRoutingRule(r.Context(), r.PathValue("uuid")); err != nil {
+		h.respondRoutingRuleError(w, "delete", err)
+		return
+	}
+	api.RespondNoContent(w)
+}
+
+func (h *APIHandler) respondRoutingRuleError(w http.ResponseWriter, verb string, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoutingRuleNotFound):
+		api.RespondError(w, http.StatusNotFound, "Routing rule not found")
+	case errors.Is(err, services.ErrInvalidRoutingRule):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error("routing rules: failed to "+verb+" routing rule", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to "+verb+" routing rule")
+	}
+}
+
+// routingRuleFromRequest maps a request onto a RoutingRule. The channel and
+// skills are resolved by the service.
+func routingRuleFromRequest(req *api.RoutingRuleRequest) *database.RoutingRule {
+	rule := &database.RoutingRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Position:    req.Position,
+		SourceUUID:  req.SourceUUID,
+		AlertName:   req.AlertName,
+		TargetHost:  req.TargetHost,
+		Action:      database.RoutingAction(strings.TrimSpace(req.Action)),
+		Priority:    database.AlertSeverity(strings.ToLower(strings.TrimSpace(req.Priority))),
+	}
+	for _, severity := range req.Severities {
+		rule.Severities = append(rule.Severities, database.AlertSeverity(strings.ToLower(strings.TrimSpace(severity))))
+	}
+	if len(req.Labels) > 0 {
+		rule.Labels = database.JSONB{}
+		for k, v := range req.Labels {
+			rule.Labels[k] = v
+		}
+	}
+	return rule
+}
+
+// trimmedNames drops blank and duplicate names.
+func trimmedNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
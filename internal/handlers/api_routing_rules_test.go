@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestRoutingRulesAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t,
+		&database.RoutingRule{}, &database.RoutingRuleSkill{}, &database.Skill{},
+		&database.Integration{}, &database.Channel{},
+	)
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/routing-rules", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	h.SetRoutingRuleManager(services.NewRoutingRuleService(db))
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/routing-rules", `{"name":"x","priority":"urgent"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bad priority: status = %d, want 422", rec.Code)
+	}
+
+	rec := serveJSON(mux, http.MethodPost, "/api/routing-rules",
+		`{"name":"info noise","severities":["INFO"],"action":"record"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created database.RoutingRule
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if !created.Enabled || created.Action != database.RoutingActionRecord || len(created.Severities) != 1 || created.Severities[0] != database.AlertSeverityInfo {
+		t.Errorf("created = %+v", created)
+	}
+
+	rec = serveJSON(mux, http.MethodPut, "/api/routing-rules/"+created.UUID,
+		`{"name":"info noise","enabled":false,"target_host":"web-*","priority":"high"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated database.RoutingRule
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	if updated.Enabled || updated.Action != database.RoutingActionInvestigate || updated.Priority != database.AlertSeverityHigh || len(updated.Severities) != 0 {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	rec = serveJSON(mux, http.MethodGet, "/api/routing-rules", "")
+	var rules []database.RoutingRule
+	_ = json.Unmarshal(rec.Body.Bytes(), &rules)
+	if rec.Code != http.StatusOK || len(rules) != 1 {
+		t.Errorf("list status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveJSON(mux, http.MethodDelete, "/api/routing-rules/"+created.UUID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/routing-rules/"+created.UUID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("deleted rule status = %d, want 404", rec.Code)
+	}
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/akmatori/akmatori/internal/api"
@@ -20,90 +19,92 @@ type UpdateRunbookRequest struct {
 	Content string `json:"content"`
 }
 
-// handleRunbooks handles GET /api/runbooks and POST /api/runbooks
-func (h *APIHandler) handleRunbooks(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		runbooks, err := h.runbookService.ListRunbooks()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list runbooks")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, runbooks)
+// listRunbooks handles GET /api/runbooks
+func (h *APIHandler) listRunbooks(w http.ResponseWriter, r *http.Request) {
+	runbooks, err := h.runbookService.ListRunbooks()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list runbooks")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, runbooks)
+}
 
-	case http.MethodPost:
-		var req CreateRunbookRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "Invalid request body")
-			return
-		}
+// createRunbook handles POST /api/runbooks
+func (h *APIHandler) createRunbook(w http.ResponseWriter, r *http.Request) {
+	var req CreateRunbookRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		runbook, err := h.runbookService.CreateRunbook(req.Title, req.Content)
-		if err != nil {
-			status := http.StatusBadRequest
-			if strings.Contains(err.Error(), "file sync failed") {
-				status = http.StatusInternalServerError
-			}
-			api.RespondError(w, status, err.Error())
-			return
+	runbook, err := h.runbookService.CreateRunbook(req.Title, req.Content)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "file sync failed") {
+			status = http.StatusInternalServerError
 		}
+		api.RespondServiceError(w, r, status, err, "Failed to create runbook")
+		return
+	}
 
-		api.RespondJSON(w, http.StatusCreated, runbook)
+	api.RespondJSON(w, http.StatusCreated, runbook)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// getRunbook handles GET /api/runbooks/{id}
+func (h *APIHandler) getRunbook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "runbook")
+	if !ok {
+		return
 	}
-}
 
-// handleRunbookByID handles GET/PUT/DELETE /api/runbooks/{id}
-func (h *APIHandler) handleRunbookByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/api/runbooks/"):]
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	runbook, err := h.runbookService.GetRunbook(id)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, "Invalid runbook ID")
+		api.RespondError(w, http.StatusNotFound, "Runbook not found")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, runbook)
+}
+
+// updateRunbook handles PUT /api/runbooks/{id}
+func (h *APIHandler) updateRunbook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "runbook")
+	if !ok {
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		runbook, err := h.runbookService.GetRunbook(uint(id))
-		if err != nil {
-			api.RespondError(w, http.StatusNotFound, "Runbook not found")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, runbook)
+	var req UpdateRunbookRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-	case http.MethodPut:
-		var req UpdateRunbookRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, "Invalid request body")
-			return
+	runbook, err := h.runbookService.UpdateRunbook(id, req.Title, req.Content)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "file sync failed") {
+			status = http.StatusInternalServerError
 		}
+		api.RespondServiceError(w, r, status, err, "Failed to update runbook")
+		return
+	}
 
-		runbook, err := h.runbookService.UpdateRunbook(uint(id), req.Title, req.Content)
-		if err != nil {
-			status := http.StatusBadRequest
-			if strings.Contains(err.Error(), "file sync failed") {
-				status = http.StatusInternalServerError
-			}
-			api.RespondError(w, status, err.Error())
-			return
-		}
+	api.RespondJSON(w, http.StatusOK, runbook)
+}
 
-		api.RespondJSON(w, http.StatusOK, runbook)
+// deleteRunbook handles DELETE /api/runbooks/{id}
+func (h *APIHandler) deleteRunbook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "runbook")
+	if !ok {
+		return
+	}
 
-	case http.MethodDelete:
-		if err := h.runbookService.DeleteRunbook(uint(id)); err != nil {
-			status := http.StatusNotFound
-			if strings.Contains(err.Error(), "file sync failed") {
-				status = http.StatusInternalServerError
-			}
-			api.RespondError(w, status, err.Error())
-			return
+	if err := h.runbookService.DeleteRunbook(id); err != nil {
+		status := http.StatusNotFound
+		if strings.Contains(err.Error(), "file sync failed") {
+			status = http.StatusInternalServerError
 		}
-		api.RespondNoContent(w)
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		api.RespondServiceError(w, r, status, err, "Failed to delete runbook")
+		return
 	}
+	api.RespondNoContent(w)
 }
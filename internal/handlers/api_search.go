@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// Result types returned by GET /api/search, in the order they are listed.
+const (
+	searchTypeIncident = "incident"
+	searchTypeAlert    = "alert"
+	searchTypeSkill    = "skill"
+	searchTypeContext  = "context"
+	searchTypeRunbook  = "runbook"
+)
+
+var searchTypes = []string{searchTypeIncident, searchTypeAlert, searchTypeSkill, searchTypeContext, searchTypeRunbook}
+
+const (
+	searchDefaultLimit = 5
+	searchMaxLimit     = 25
+	searchSnippetWidth = 160
+)
+
+// SearchResult is one hit from the global search. ID is the entity's UUID,
+// name or numeric ID (as a string) depending on Type.
+type SearchResult struct {
+	Type         string    `json:"type"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Snippet      string    `json:"snippet,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	IncidentUUID string    `json:"incident_uuid,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// SearchResponse is the body of GET /api/search. Counts holds the total
+// number of matches per searched type; Results holds at most limit hits per
+// type, grouped by type and newest first within each group.
+type SearchResponse struct {
+	Query   string           `json:"query"`
+	Results []SearchResult   `json:"results"`
+	Counts  map[string]int64 `json:"counts"`
+}
+
+// handleSearch handles GET /api/search?q=...&types=...&limit=N — one
+// case-insensitive substring search across incidents, alerts, skills, context
+// files and runbooks for the UI's global search bar.
+func (h *APIHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	term := strings.TrimSpace(q.Get("q"))
+	if term == "" {
+		api.RespondValidationError(w, map[string]string{"q": "search term is required"})
+		return
+	}
+
+	types := searchTypes
+	if raw := q.Get("types"); raw != "" {
+		types = nil
+		for _, t := range splitCSV(strings.ToLower(raw)) {
+			if !slices.Contains(searchTypes, t) {
+				api.RespondValidationError(w, map[string]string{"types": "unknown type " + strconv.Quote(t)})
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	limit := searchDefaultLimit
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = min(v, searchMaxLimit)
+	}
+
+	db := database.GetDB()
+	resp := SearchResponse{
+		Query:   term,
+		Results: []SearchResult{},
+		Counts:  make(map[string]int64, len(types)),
+	}
+	for _, t := range searchTypes {
+		if !slices.Contains(types, t) {
+			continue
+		}
+		results, total, err := searchByType(db, t, term, limit)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "search: query failed", "type", t, "err", err)
+			api.RespondError(w, http.StatusInternalServerError, "Failed to search")
+			return
+		}
+		resp.Counts[t] = total
+		resp.Results = append(resp.Results, results...)
+	}
+
+	api.RespondJSON(w, http.StatusOK, resp)
+}
+
+// searchByType runs the count and top-N queries for one result type.
+// UUIDs match by prefix so a copied short ID finds its entity; text columns
+// match by substring. LOWER(...) LIKE keeps the queries portable across
+// PostgreSQL (prod) and SQLite (tests).
+func searchByType(db *gorm.DB, typ, term string, limit int) ([]SearchResult, int64, error) {
+	lowered := strings.ToLower(term)
+	prefix := lowered + "%"
+	like := "%" + lowered + "%"
+
+	var base *gorm.DB
+	switch typ {
+	case searchTypeIncident:
+		base = db.Model(&database.Incident{}).Where(
+			"LOWER(uuid) LIKE ? OR LOWER(title) LIKE ? OR LOWER(response) LIKE ? OR LOWER(context->>'summary') LIKE ?",
+			prefix, like, like, like)
+	case searchTypeAlert:
+		base = db.Model(&database.Alert{}).Where(
+			"LOWER(uuid) LIKE ? OR LOWER(incident_uuid) LIKE ? OR LOWER(alert_name) LIKE ? OR LOWER(target_host) LIKE ?",
+			prefix, prefix, like, like)
+	case searchTypeSkill:
+		base = db.Model(&database.Skill{}).Where(
+			"LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(category) LIKE ?",
+			like, like, like)
+	case searchTypeContext:
+		base = db.Model(&database.ContextFile{}).Where(
+			"LOWER(filename) LIKE ? OR LOWER(original_name) LIKE ? OR LOWER(description) LIKE ?",
+			like, like, like)
+	case searchTypeRunbook:
+		base = db.Model(&database.Runbook{}).Where(
+			"LOWER(title) LIKE ? OR LOWER(content) LIKE ?",
+			like, like)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	var results []SearchResult
+	switch typ {
+	case searchTypeIncident:
+		// Skip full_log: it can be megabytes and is not needed for a hit.
+		var rows []database.Incident
+		if err := base.Select("uuid, title, status, response, context, created_at").Order("created_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, inc := range rows {
+			snippet := searchSnippet(inc.Response, term)
+			if snippet == "" {
+				if summary, ok := inc.Context["summary"].(string); ok {
+					snippet = searchSnippet(summary, term)
+				}
+			}
+			results = append(results, SearchResult{
+				Type: typ, ID: inc.UUID, Title: inc.Title, Snippet: snippet,
+				Status: string(inc.Status), IncidentUUID: inc.UUID, Timestamp: inc.CreatedAt,
+			})
+		}
+	case searchTypeAlert:
+		var rows []database.Alert
+		if err := base.Order("fired_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, a := range rows {
+			results = append(results, SearchResult{
+				Type: typ, ID: a.UUID, Title: a.AlertName, Snippet: a.TargetHost,
+				Status: string(a.Status), IncidentUUID: a.IncidentUUID, Timestamp: a.FiredAt,
+			})
+		}
+	case searchTypeSkill:
+		var rows []database.Skill
+		if err := base.Order("updated_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, s := range rows {
+			status := "enabled"
+			if !s.Enabled {
+				status = "disabled"
+			}
+			results = append(results, SearchResult{
+				Type: typ, ID: s.Name, Title: s.Name, Snippet: searchSnippet(s.Description, term),
+				Status: status, Timestamp: s.UpdatedAt,
+			})
+		}
+	case searchTypeContext:
+		var rows []database.ContextFile
+		if err := base.Order("updated_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, f := range rows {
+			results = append(results, SearchResult{
+				Type: typ, ID: strconv.FormatUint(uint64(f.ID), 10), Title: f.Filename,
+				Snippet: searchSnippet(f.Description, term), Timestamp: f.UpdatedAt,
+			})
+		}
+	case searchTypeRunbook:
+		var rows []database.Runbook
+		if err := base.Order("updated_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, rb := range rows {
+			results = append(results, SearchResult{
+				Type: typ, ID: strconv.FormatUint(uint64(rb.ID), 10), Title: rb.Title,
+				Snippet: searchSnippet(rb.Content, term), Timestamp: rb.UpdatedAt,
+			})
+		}
+	}
+	return results, total, nil
+}
+
+// searchSnippet returns up to searchSnippetWidth runes of text centred on
+// the first case-insensitive occurrence of term, with ellipses marking
+// trimmed ends and newlines flattened. Returns "" when term does not occur.
+func searchSnippet(text, term string) string {
+	runes := []rune(text)
+	needle := []rune(strings.ToLower(term))
+	if len(needle) == 0 || len(runes) < len(needle) {
+		return ""
+	}
+
+	// Lower rune-by-rune so indexes line up with the original text.
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+	idx := strings.Index(string(lowered), string(needle))
+	if idx < 0 {
+		return ""
+	}
+	at := len([]rune(string(lowered)[:idx]))
+
+	start := max(0, at-(searchSnippetWidth-len(needle))/2)
+	end := min(len(runes), start+searchSnippetWidth)
+	start = max(0, end-searchSnippetWidth)
+
+	snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
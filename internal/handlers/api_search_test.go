@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+	"github.com/google/uuid"
+)
+
+func doSearchRequest(t *testing.T, query string, wantStatus int) SearchResponse {
+	t.Helper()
+	mux := http.NewServeMux()
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetupRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?"+query, nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != wantStatus {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, wantStatus, rec.Body.String())
+	}
+	var resp SearchResponse
+	if wantStatus == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return resp
+}
+
+func seedSearchData(t *testing.T) (incidentUUID, alertUUID string) {
+	t.Helper()
+	db := testhelpers.NewGlobalSQLiteDB(t,
+		&database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{}, &database.Skill{},
+		&database.ContextFile{}, &database.Runbook{})
+
+	incidentUUID = uuid.New().String()
+	alertUUID = uuid.New().String()
+	rows := []any{
+		&database.Incident{UUID: incidentUUID, Source: "api", Title: "Postgres replication lag",
+			Status: database.IncidentStatusCompleted, Response: "Replica fell behind after a vacuum"},
+		&database.Incident{UUID: uuid.New().String(), Source: "api", Title: "Disk full",
+			Status: database.IncidentStatusCompleted},
+		&database.Alert{UUID: alertUUID, IncidentUUID: incidentUUID, Status: database.AlertStatusFiring,
+			AlertName: "PostgresReplicationLag", TargetHost: "db-02", FiredAt: time.Now()},
+		&database.Skill{Name: "postgres-analyst", Description: "Diagnoses PostgreSQL replication and locking", Enabled: true},
+		&database.ContextFile{Filename: "pg-topology.md", Description: "Primary/replica layout"},
+		&database.Runbook{Title: "Replication lag", Content: "1. Check pg_stat_replication on the primary.\n2. Restart the replica."},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("seed %T: %v", row, err)
+		}
+	}
+	return incidentUUID, alertUUID
+}
+
+func TestHandleSearch_AllTypes(t *testing.T) {
+	incidentUUID, alertUUID := seedSearchData(t)
+
+	resp := doSearchRequest(t, "q=replica", http.StatusOK)
+
+	wantCounts := map[string]int64{"incident": 1, "alert": 1, "skill": 1, "context": 1, "runbook": 1}
+	for typ, want := range wantCounts {
+		if resp.Counts[typ] != want {
+			t.Errorf("counts[%s] = %d, want %d", typ, resp.Counts[typ], want)
+		}
+	}
+	if len(resp.Results) != 5 {
+		t.Fatalf("results = %d, want 5: %+v", len(resp.Results), resp.Results)
+	}
+
+	// Results are grouped in fixed type order.
+	var order []string
+	for _, r := range resp.Results {
+		order = append(order, r.Type)
+	}
+	if got := strings.Join(order, ","); got != "incident,alert,skill,context,runbook" {
+		t.Errorf("result order = %s", got)
+	}
+
+	inc, alert := resp.Results[0], resp.Results[1]
+	if inc.ID != incidentUUID || inc.Snippet != "Replica fell behind after a vacuum" {
+		t.Errorf("incident result = %+v", inc)
+	}
+	if alert.ID != alertUUID || alert.IncidentUUID != incidentUUID {
+		t.Errorf("alert result = %+v", alert)
+	}
+	if rb := resp.Results[4]; !strings.Contains(rb.Snippet, "pg_stat_replication") {
+		t.Errorf("runbook snippet = %q", rb.Snippet)
+	}
+}
+
+func TestHandleSearch_TypesAndLimit(t *testing.T) {
+	incidentUUID, _ := seedSearchData(t)
+
+	resp := doSearchRequest(t, "q="+incidentUUID[:8]+"&types=incident,alert", http.StatusOK)
+	if len(resp.Counts) != 2 {
+		t.Errorf("counts = %v, want only incident and alert", resp.Counts)
+	}
+	if resp.Counts["incident"] != 1 || resp.Counts["alert"] != 1 {
+		t.Errorf("uuid prefix counts = %v", resp.Counts)
+	}
+
+	resp = doSearchRequest(t, "q=s&types=incident&limit=1", http.StatusOK)
+	if resp.Counts["incident"] != 2 || len(resp.Results) != 1 {
+		t.Errorf("limit: counts=%v results=%d", resp.Counts, len(resp.Results))
+	}
+}
+
+func TestHandleSearch_Validation(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{})
+
+	doSearchRequest(t, "q=%20", http.StatusUnprocessableEntity)
+	doSearchRequest(t, "q=x&types=incident,widgets", http.StatusUnprocessableEntity)
+}
+
+func TestSearchSnippet(t *testing.T) {
+	long := strings.Repeat("a", 200) + " NEEDLE here " + strings.Repeat("b", 200)
+
+	tests := []struct {
+		name, text, term, want string
+	}{
+		{"no match", "nothing to see", "needle", ""},
+		{"short text", "Found the\nNeedle quickly", "needle", "Found the Needle quickly"},
+		{"empty term", "text", "", ""},
+	}
+	for _, tt := range tests {
+		if got := searchSnippet(tt.text, tt.term); got != tt.want {
+			t.Errorf("%s: searchSnippet() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	got := searchSnippet(long, "needle")
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "NEEDLE") {
+		t.Errorf("long snippet = %q", got)
+	}
+	if n := len([]rune(got)); n > searchSnippetWidth+2 {
+		t.Errorf("long snippet has %d runes, want <= %d", n, searchSnippetWidth+2)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetBudgetGuard wires the LLM budget check for API-created incidents and
+// follow-ups, and the spend reported by /api/settings/llm/budget. Optional —
+// when unset, runs are never declined and the endpoint omits status.
+func (h *APIHandler) SetBudgetGuard(g services.BudgetGuard) {
+	h.budget = g
+}
+
+// budgetSettingsResponse is the budget settings plus the current spend.
+type budgetSettingsResponse struct {
+	*database.BudgetSettings
+	Status *services.BudgetStatus `json:"status,omitempty"`
+}
+
+// getBudgetSettings handles GET /api/settings/llm/budget
+func (h *APIHandler) getBudgetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get budget settings")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, h.budgetSettingsResponse(r, settings))
+}
+
+// updateBudgetSettings handles PUT /api/settings/llm/budget
+func (h *APIHandler) updateBudgetSettings(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateBudgetSettingsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	settings, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get budget settings")
+		return
+	}
+
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	for _, limit := range []struct {
+		name  string
+		value *int64
+		dest  *int64
+	}{
+		{"daily_token_limit", req.DailyTokenLimit, &settings.DailyTokenLimit},
+		{"monthly_token_limit", req.MonthlyTokenLimit, &settings.MonthlyTokenLimit},
+	} {
+		if limit.value == nil {
+			continue
+		}
+		if *limit.value < 0 {
+			api.RespondError(w, http.StatusBadRequest, limit.name+" must not be negative")
+			return
+		}
+		*limit.dest = *limit.value
+	}
+	for _, limit := range []struct {
+		name  string
+		value *float64
+		dest  *float64
+	}{
+		{"daily_cost_limit_usd", req.DailyCostLimitUSD, &settings.DailyCostLimitUSD},
+		{"monthly_cost_limit_usd", req.MonthlyCostLimitUSD, &settings.MonthlyCostLimitUSD},
+	} {
+		if limit.value == nil {
+			continue
+		}
+		if *limit.value < 0 || *limit.value > 1_000_000 {
+			api.RespondError(w, http.StatusBadRequest, limit.name+" must be between 0 and 1000000")
+			return
+		}
+		*limit.dest = *limit.value
+	}
+	if req.NotificationChannelUUID != nil {
+		settings.NotificationChannelUUID = strings.TrimSpace(*req.NotificationChannelUUID)
+	}
+
+	if err := database.UpdateBudgetSettings(settings); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update budget settings")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, h.budgetSettingsResponse(r, settings))
+}
+
+func (h *APIHandler) budgetSettingsResponse(r *http.Request, settings *database.BudgetSettings) budgetSettingsResponse {
+	resp := budgetSettingsResponse{BudgetSettings: settings}
+	if h.budget != nil {
+		status, err := h.budget.BudgetStatus(r.Context())
+		if err != nil {
+			slog.WarnContext(r.Context(), "budget: failed to read spend", "err", err)
+		}
+		resp.Status = status
+	}
+	return resp
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+type stubBudgetGuard struct {
+	status *services.BudgetStatus
+}
+
+func (g *stubBudgetGuard) CheckBudget(context.Context) error { return nil }
+
+func (g *stubBudgetGuard) BudgetStatus(context.Context) (*services.BudgetStatus, error) {
+	return g.status, nil
+}
+
+func TestHandleBudgetSettings_PUT_PersistsAndReportsSpend(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.BudgetSettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetBudgetGuard(&stubBudgetGuard{status: &services.BudgetStatus{Enabled: true, Daily: services.BudgetWindow{TokensUsed: 1200}}})
+
+	body := `{"enabled":true,"daily_token_limit":50000,"monthly_cost_limit_usd":250,"notification_channel_uuid":" ch-1 "}`
+	req := httptest.NewRequest(http.MethodPut, "/api/settings/llm/budget", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		database.BudgetSettings
+		Status *services.BudgetStatus `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Status == nil || resp.Status.Daily.TokensUsed != 1200 {
+		t.Errorf("status = %+v, want the guard's spend", resp.Status)
+	}
+
+	saved, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		t.Fatalf("GetOrCreateBudgetSettings: %v", err)
+	}
+	if !saved.Enabled || saved.DailyTokenLimit != 50000 || saved.MonthlyCostLimitUSD != 250 || saved.NotificationChannelUUID != "ch-1" {
+		t.Errorf("saved = %+v", saved)
+	}
+}
+
+func TestHandleBudgetSettings_PUT_Validation(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.BudgetSettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"negative tokens", `{"monthly_token_limit":-1}`, "must not be negative"},
+		{"cost too high", `{"daily_cost_limit_usd":2000000}`, "between 0 and 1000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/llm/budget", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			serveAPI(h, w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("body = %s, want it to mention %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
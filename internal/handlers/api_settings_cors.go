@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+)
+
+// SetCORS wires the CORS middleware that /api/settings/cors reconfigures.
+// env is the policy from the CORS_* environment variables, which applies
+// while the saved settings are disabled. Optional — when unset the endpoints
+// return 503.
+func (h *APIHandler) SetCORS(m *middleware.CORSMiddleware, env middleware.CORSConfig) {
+	h.cors = m
+	h.corsEnv = env
+}
+
+// ReloadCORS applies the saved CORS settings to the middleware. Settings are
+// saved through one replica, so the others call this periodically.
+func (h *APIHandler) ReloadCORS() error {
+	if h.cors == nil {
+		return nil
+	}
+	settings, err := database.GetOrCreateCORSSettings()
+	if err != nil {
+		return err
+	}
+	h.cors.Configure(h.corsConfig(settings))
+	return nil
+}
+
+// corsConfig is the policy settings select: their own origins, headers and
+// credentials when enabled, the environment's otherwise. Methods and max
+// age always come from the environment.
+func (h *APIHandler) corsConfig(settings *database.CORSSettings) middleware.CORSConfig {
+	if !settings.Enabled {
+		return h.corsEnv
+	}
+	cfg := h.corsEnv
+	cfg.AllowedOrigins = settings.AllowedOrigins
+	cfg.AllowedHeaders = settings.AllowedHeaders
+	cfg.AllowCredentials = settings.AllowCredentials
+	return cfg
+}
+
+// getCORSSettings handles GET /api/settings/cors
+func (h *APIHandler) getCORSSettings(w http.ResponseWriter, r *http.Request) {
+	if h.cors == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "CORS settings are not configured")
+		return
+	}
+	settings, err := database.GetOrCreateCORSSettings()
+	if err != nil {
+		api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to get CORS settings")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, h.corsSettingsResponse(settings))
+}
+
+// updateCORSSettings handles PUT /api/settings/cors. The new policy applies
+// on this replica immediately and on the others at their next reload.
+func (h *APIHandler) updateCORSSettings(w http.ResponseWriter, r *http.Request) {
+	if h.cors == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "CORS settings are not configured")
+		return
+	}
+	var req api.UpdateCORSSettingsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	settings, err := database.GetOrCreateCORSSettings()
+	if err != nil {
+		api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to get CORS settings")
+		return
+	}
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.AllowedOrigins != nil {
+		settings.AllowedOrigins = trimmedList(*req.AllowedOrigins)
+	}
+	if req.AllowedHeaders != nil {
+		settings.AllowedHeaders = trimmedList(*req.AllowedHeaders)
+	}
+	if req.AllowCredentials != nil {
+		settings.AllowCredentials = *req.AllowCredentials
+	}
+	if errs := validateCORSSettings(settings); errs != nil {
+		api.RespondValidationError(w, errs)
+		return
+	}
+
+	if err := database.UpdateCORSSettings(settings); err != nil {
+		api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to update CORS settings")
+		return
+	}
+	h.cors.Configure(h.corsConfig(settings))
+	slog.InfoContext(r.Context(), "CORS policy updated", "enabled", settings.Enabled, "origins", []string(settings.AllowedOrigins))
+	api.RespondJSON(w, http.StatusOK, h.corsSettingsResponse(settings))
+}
+
+func (h *APIHandler) corsSettingsResponse(settings *database.CORSSettings) api.CORSSettingsResponse {
+	cfg := h.cors.Config()
+	source := "environment"
+	if settings.Enabled {
+		source = "settings"
+	}
+	return api.CORSSettingsResponse{
+		CORSSettings: *settings,
+		Effective: api.CORSPolicy{
+			Source:           source,
+			AllowedOrigins:   cfg.AllowedOrigins,
+			AllowedMethods:   cfg.AllowedMethods,
+			AllowedHeaders:   cfg.AllowedHeaders,
+			AllowCredentials: cfg.AllowCredentials,
+		},
+	}
+}
+
+// validateCORSSettings checks that every origin is "*" or a bare
+// scheme://host[:port], that headers are header names, and that credentials
+// are not combined with "*".
+func validateCORSSettings(settings *database.CORSSettings) map[string]string {
+	errs := map[string]string{}
+	wildcard := false
+	for i, origin := range settings.AllowedOrigins {
+		if origin == "*" {
+			wildcard = true
+			continue
+		}
+		if !validOrigin(origin) {
+			errs[fmt.Sprintf("allowed_origins[%d]", i)] = "must be * or an origin like https://ui.example.com"
+		}
+	}
+	for i, header := range settings.AllowedHeaders {
+		if !validHeaderName(header) {
+			errs[fmt.Sprintf("allowed_headers[%d]", i)] = "must be a header name"
+		}
+	}
+	if wildcard && settings.AllowCredentials {
+		errs["allow_credentials"] = "cannot be combined with the * origin"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validOrigin(origin string) bool {
+	u, err := url.Parse(strings.TrimRight(origin, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// trimmedList trims each value and drops the empty ones.
+func trimmedList(values []string) database.StringList {
+	out := database.StringList{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestCORSSettingsAPI(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.CORSSettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/settings/cors", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	env := middleware.CORSConfig{AllowedOrigins: []string{"https://env.example.com"}}
+	cors := middleware.NewCORSMiddlewareWithConfig(env)
+	h.SetCORS(cors, env)
+
+	decode := func(body []byte) api.CORSSettingsResponse {
+		t.Helper()
+		var resp api.CORSSettingsResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	rec := serveJSON(mux, http.MethodGet, "/api/settings/cors", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp := decode(rec.Body.Bytes()); resp.Enabled || resp.Effective.Source != "environment" {
+		t.Errorf("defaults = %+v, want disabled with the environment policy", resp)
+	}
+
+	for _, body := range []string{
+		`{"allowed_origins":["https://ui.example.com/app"]}`,
+		`{"allowed_origins":["ui.example.com"]}`,
+		`{"allowed_origins":["*"],"allow_credentials":true}`,
+		`{"allowed_headers":["X Bad"]}`,
+	} {
+		if rec := serveJSON(mux, http.MethodPut, "/api/settings/cors", body); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("PUT %s status = %d, want 422", body, rec.Code)
+		}
+	}
+
+	rec = serveJSON(mux, http.MethodPut, "/api/settings/cors",
+		`{"enabled":true,"allowed_origins":[" https://ui.example.com ",""],"allowed_headers":["Content-Type","Authorization"],"allow_credentials":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put status = %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decode(rec.Body.Bytes())
+	if resp.Effective.Source != "settings" || len(resp.AllowedOrigins) != 1 || !resp.Effective.AllowCredentials {
+		t.Errorf("after update = %+v", resp)
+	}
+	if !cors.AllowsOrigin("https://ui.example.com") || cors.AllowsOrigin("https://env.example.com") {
+		t.Error("saved origins were not applied to the middleware")
+	}
+
+	// Another replica starts from the environment and loads the saved policy.
+	other := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	otherCORS := middleware.NewCORSMiddlewareWithConfig(env)
+	other.SetCORS(otherCORS, env)
+	if err := other.ReloadCORS(); err != nil {
+		t.Fatalf("ReloadCORS: %v", err)
+	}
+	if !otherCORS.AllowsOrigin("https://ui.example.com") {
+		t.Error("ReloadCORS did not apply the saved origins")
+	}
+
+	if rec := serveJSON(mux, http.MethodPut, "/api/settings/cors", `{"enabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("disable status = %d", rec.Code)
+	}
+	if !cors.AllowsOrigin("https://env.example.com") {
+		t.Error("disabling the settings did not restore the environment policy")
+	}
+}
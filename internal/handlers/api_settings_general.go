@@ -2,13 +2,21 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
 const defaultAlertMonitorWindowMinutes = 60
 
+// maxIncidentAutoCloseDays bounds incident_auto_close_days (0 disables).
+const maxIncidentAutoCloseDays = 365
+
+// maxConfidenceReviewersLen is the column size of confidence_reviewers.
+const maxConfidenceReviewersLen = 512
+
 // applyGeneralSettingsDefaults fills nil alert config pointers with effective
 // code defaults so the GET response never contains null. It modifies the struct
 // in-place; callers must not persist the result back to the DB.
@@ -25,66 +33,124 @@ func applyGeneralSettingsDefaults(s *database.GeneralSettings) {
 		v := false
 		s.IncidentMergeEnabled = &v
 	}
+	if s.IncidentAutoCloseDays == nil {
+		v := 0
+		s.IncidentAutoCloseDays = &v
+	}
+	if s.ShortLinksEnabled == nil {
+		v := false
+		s.ShortLinksEnabled = &v
+	}
+	if s.ConfidenceReviewThreshold == nil {
+		v := database.DefaultConfidenceReviewThreshold
+		s.ConfidenceReviewThreshold = &v
+	}
+	if s.ConfidenceAutoCloseThreshold == nil {
+		v := 0.0
+		s.ConfidenceAutoCloseThreshold = &v
+	}
 }
 
-// handleGeneralSettings handles GET/PUT /api/settings/general
-func (h *APIHandler) handleGeneralSettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		settings, err := database.GetOrCreateGeneralSettings()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get general settings")
-			return
-		}
-		// Hydrate nil alert config fields with effective defaults so the
-		// frontend always receives non-null values and can display them
-		// without null guards. The defaults are NOT persisted to the DB.
-		applyGeneralSettingsDefaults(settings)
-		api.RespondJSON(w, http.StatusOK, settings)
+// getGeneralSettings handles GET /api/settings/general
+func (h *APIHandler) getGeneralSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get general settings")
+		return
+	}
+	// Hydrate nil alert config fields with effective defaults so the
+	// frontend always receives non-null values and can display them
+	// without null guards. The defaults are NOT persisted to the DB.
+	applyGeneralSettingsDefaults(settings)
+	api.RespondJSON(w, http.StatusOK, settings)
+}
 
-	case http.MethodPut:
-		var req api.UpdateGeneralSettingsRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+// updateGeneralSettings handles PUT /api/settings/general
+func (h *APIHandler) updateGeneralSettings(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateGeneralSettingsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get general settings")
+		return
+	}
+
+	if req.BaseURL != nil {
+		if *req.BaseURL != "" && !isValidURL(*req.BaseURL) {
+			api.RespondError(w, http.StatusBadRequest, "Invalid base_url: must be a valid HTTP or HTTPS URL")
 			return
 		}
-
-		settings, err := database.GetOrCreateGeneralSettings()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get general settings")
+		settings.BaseURL = *req.BaseURL
+	}
+	if req.AlertCorrelationEnabled != nil {
+		settings.AlertCorrelationEnabled = req.AlertCorrelationEnabled
+	}
+	if req.AlertMonitorWindowMinutes != nil {
+		if *req.AlertMonitorWindowMinutes < 1 || *req.AlertMonitorWindowMinutes > 10080 {
+			api.RespondError(w, http.StatusBadRequest, "alert_monitor_window_minutes must be between 1 and 10080")
 			return
 		}
-
-		if req.BaseURL != nil {
-			if *req.BaseURL != "" && !isValidURL(*req.BaseURL) {
-				api.RespondError(w, http.StatusBadRequest, "Invalid base_url: must be a valid HTTP or HTTPS URL")
-				return
-			}
-			settings.BaseURL = *req.BaseURL
+		settings.AlertMonitorWindowMinutes = req.AlertMonitorWindowMinutes
+	}
+	if req.IncidentMergeEnabled != nil {
+		settings.IncidentMergeEnabled = req.IncidentMergeEnabled
+	}
+	if req.IncidentAutoCloseDays != nil {
+		if *req.IncidentAutoCloseDays < 0 || *req.IncidentAutoCloseDays > maxIncidentAutoCloseDays {
+			api.RespondError(w, http.StatusBadRequest, "incident_auto_close_days must be between 0 and 365")
+			return
 		}
-		if req.AlertCorrelationEnabled != nil {
-			settings.AlertCorrelationEnabled = req.AlertCorrelationEnabled
+		settings.IncidentAutoCloseDays = req.IncidentAutoCloseDays
+	}
+	if req.ShortLinksEnabled != nil {
+		settings.ShortLinksEnabled = req.ShortLinksEnabled
+	}
+	if req.NotificationLocale != nil {
+		locale := strings.TrimSpace(*req.NotificationLocale)
+		if !services.IsValidNotificationLocale(locale) {
+			api.RespondError(w, http.StatusBadRequest, "Invalid notification_locale: use a language tag such as \"de\" or \"pt-BR\"")
+			return
 		}
-		if req.AlertMonitorWindowMinutes != nil {
-			if *req.AlertMonitorWindowMinutes < 1 || *req.AlertMonitorWindowMinutes > 10080 {
-				api.RespondError(w, http.StatusBadRequest, "alert_monitor_window_minutes must be between 1 and 10080")
-				return
-			}
-			settings.AlertMonitorWindowMinutes = req.AlertMonitorWindowMinutes
+		settings.NotificationLocale = locale
+	}
+	if req.ConfidenceReviewThreshold != nil {
+		if !isConfidenceThreshold(*req.ConfidenceReviewThreshold) {
+			api.RespondError(w, http.StatusBadRequest, "confidence_review_threshold must be between 0 and 1")
+			return
 		}
-		if req.IncidentMergeEnabled != nil {
-			settings.IncidentMergeEnabled = req.IncidentMergeEnabled
+		settings.ConfidenceReviewThreshold = req.ConfidenceReviewThreshold
+	}
+	if req.ConfidenceAutoCloseThreshold != nil {
+		if !isConfidenceThreshold(*req.ConfidenceAutoCloseThreshold) {
+			api.RespondError(w, http.StatusBadRequest, "confidence_auto_close_threshold must be between 0 and 1")
+			return
 		}
-
-		if err := database.UpdateGeneralSettings(settings); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to update general settings")
+		settings.ConfidenceAutoCloseThreshold = req.ConfidenceAutoCloseThreshold
+	}
+	if req.ConfidenceReviewers != nil {
+		reviewers := strings.TrimSpace(*req.ConfidenceReviewers)
+		if len(reviewers) > maxConfidenceReviewersLen {
+			api.RespondError(w, http.StatusBadRequest, "confidence_reviewers must be at most 512 characters")
 			return
 		}
+		settings.ConfidenceReviewers = reviewers
+	}
 
-		applyGeneralSettingsDefaults(settings)
-		api.RespondJSON(w, http.StatusOK, settings)
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := database.UpdateGeneralSettings(settings); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update general settings")
+		return
 	}
+
+	applyGeneralSettingsDefaults(settings)
+	api.RespondJSON(w, http.StatusOK, settings)
+}
+
+// isConfidenceThreshold reports whether v is a valid confidence threshold;
+// 0 turns the threshold off.
+func isConfidenceThreshold(v float64) bool {
+	return v >= 0 && v <= 1
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/testhelpers"
@@ -23,7 +24,7 @@ func TestHandleGeneralSettings_GET_NonNilDefaults(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/general", nil)
 	rec := httptest.NewRecorder()
-	h.handleGeneralSettings(rec, req)
+	serveAPI(h, rec, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
@@ -37,6 +38,10 @@ func TestHandleGeneralSettings_GET_NonNilDefaults(t *testing.T) {
 	fields := []string{
 		"alert_correlation_enabled",
 		"alert_monitor_window_minutes",
+		"incident_auto_close_days",
+		"short_links_enabled",
+		"confidence_review_threshold",
+		"confidence_auto_close_threshold",
 	}
 	for _, f := range fields {
 		if _, ok := body[f]; !ok {
@@ -70,7 +75,7 @@ func TestHandleGeneralSettings_AlertMonitorWindowMinutes_PersistAndGet(t *testin
 	putReq := httptest.NewRequest(http.MethodPut, "/api/settings/general", bytes.NewBufferString(body))
 	putReq.Header.Set("Content-Type", "application/json")
 	putRec := httptest.NewRecorder()
-	h.handleGeneralSettings(putRec, putReq)
+	serveAPI(h, putRec, putReq)
 
 	if putRec.Code != http.StatusOK {
 		t.Fatalf("PUT expected 200, got %d: %s", putRec.Code, putRec.Body.String())
@@ -87,7 +92,7 @@ func TestHandleGeneralSettings_AlertMonitorWindowMinutes_PersistAndGet(t *testin
 	// GET to confirm the value was persisted.
 	getReq := httptest.NewRequest(http.MethodGet, "/api/settings/general", nil)
 	getRec := httptest.NewRecorder()
-	h.handleGeneralSettings(getRec, getReq)
+	serveAPI(h, getRec, getReq)
 
 	if getRec.Code != http.StatusOK {
 		t.Fatalf("GET expected 200, got %d", getRec.Code)
@@ -126,7 +131,7 @@ func TestHandleGeneralSettings_AlertMonitorWindowMinutes_InvalidValue(t *testing
 			req := httptest.NewRequest(http.MethodPut, "/api/settings/general", bytes.NewBuffer(b))
 			req.Header.Set("Content-Type", "application/json")
 			rec := httptest.NewRecorder()
-			h.handleGeneralSettings(rec, req)
+			serveAPI(h, rec, req)
 
 			if rec.Code != http.StatusBadRequest {
 				t.Errorf("value=%d: expected 400, got %d: %s", tc.value, rec.Code, rec.Body.String())
@@ -134,3 +139,75 @@ func TestHandleGeneralSettings_AlertMonitorWindowMinutes_InvalidValue(t *testing
 		})
 	}
 }
+
+func TestHandleGeneralSettings_IncidentAutoCloseDays(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t,
+		&database.GeneralSettings{},
+	)
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	put := func(days int) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(map[string]interface{}{"incident_auto_close_days": days})
+		req := httptest.NewRequest(http.MethodPut, "/api/settings/general", bytes.NewBuffer(b))
+		rec := httptest.NewRecorder()
+		serveAPI(h, rec, req)
+		return rec
+	}
+
+	for _, days := range []int{-1, 366} {
+		if rec := put(days); rec.Code != http.StatusBadRequest {
+			t.Errorf("days=%d: expected 400, got %d", days, rec.Code)
+		}
+	}
+	if rec := put(14); rec.Code != http.StatusOK {
+		t.Fatalf("days=14: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.GetIncidentAutoCloseAfter() != 14*24*time.Hour {
+		t.Errorf("auto-close after = %v, want 14 days", settings.GetIncidentAutoCloseAfter())
+	}
+}
+
+func TestHandleGeneralSettings_ConfidenceThresholds(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t,
+		&database.GeneralSettings{},
+	)
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	put := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/api/settings/general", bytes.NewBuffer(b))
+		rec := httptest.NewRecorder()
+		serveAPI(h, rec, req)
+		return rec
+	}
+
+	for _, field := range []string{"confidence_review_threshold", "confidence_auto_close_threshold"} {
+		for _, v := range []float64{-0.1, 1.5} {
+			if rec := put(map[string]interface{}{field: v}); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s=%v: expected 400, got %d", field, v, rec.Code)
+			}
+		}
+	}
+	rec := put(map[string]interface{}{
+		"confidence_review_threshold":     0.4,
+		"confidence_auto_close_threshold": 0.9,
+		"confidence_reviewers":            " <!subteam^S123> ",
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.GetConfidenceReviewThreshold() != 0.4 || settings.GetConfidenceAutoCloseThreshold() != 0.9 {
+		t.Errorf("thresholds = %v/%v, want 0.4/0.9", settings.GetConfidenceReviewThreshold(), settings.GetConfidenceAutoCloseThreshold())
+	}
+	if settings.ConfidenceReviewers != "<!subteam^S123>" {
+		t.Errorf("reviewers = %q", settings.ConfidenceReviewers)
+	}
+}
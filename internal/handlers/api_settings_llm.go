@@ -3,57 +3,22 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
-// handleLLMSettings handles GET /api/settings/llm and POST /api/settings/llm.
-func (h *APIHandler) handleLLMSettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.listLLMConfigs(w, r)
-	case http.MethodPost:
-		h.createLLMConfig(w, r)
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
-
-// handleLLMSettingsByID handles GET/PUT/DELETE /api/settings/llm/{id} and PUT /api/settings/llm/{id}/activate.
-func (h *APIHandler) handleLLMSettingsByID(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path[len("/api/settings/llm/"):]
-	parts := strings.Split(path, "/")
-
-	id, err := strconv.ParseUint(parts[0], 10, 32)
-	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, "Invalid config ID")
-		return
-	}
-
-	// Handle /api/settings/llm/{id}/activate
-	if len(parts) >= 2 && parts[1] == "activate" {
-		if r.Method != http.MethodPut {
-			api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-			return
-		}
-		h.activateLLMConfig(w, r, uint(id))
-		return
-	}
+// maxLLMContextWindow bounds context_window; no model comes close.
+const maxLLMContextWindow = 10_000_000
 
-	switch r.Method {
-	case http.MethodGet:
-		h.getLLMConfig(w, r, uint(id))
-	case http.MethodPut:
-		h.updateLLMConfig(w, r, uint(id))
-	case http.MethodDelete:
-		h.deleteLLMConfig(w, r, uint(id))
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
+// SetLLMHealthProber wires endpoint checks behind
+// POST /api/settings/llm/{id}/health. Optional — when unset the endpoint
+// returns 503.
+func (h *APIHandler) SetLLMHealthProber(p services.LLMHealthProber) {
+	h.llmHealth = p
 }
 
 // listLLMConfigs returns all LLM configurations with the active config ID.
@@ -113,7 +78,7 @@ func (h *APIHandler) listLLMConfigs(w http.ResponseWriter, _ *http.Request) {
 func (h *APIHandler) createLLMConfig(w http.ResponseWriter, r *http.Request) {
 	var req api.CreateLLMSettingsRequest
 	if err := api.DecodeJSON(r, &req); err != nil {
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondDecodeError(w, err)
 		return
 	}
 
@@ -131,7 +96,15 @@ func (h *APIHandler) createLLMConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !database.IsValidLLMProvider(req.Provider) {
-		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid provider: %s. Valid options: openai, anthropic, google, openrouter, nvidia, minimax, ant-ling, custom", req.Provider))
+		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid provider: %s. Valid options: openai, anthropic, google, openrouter, nvidia, minimax, ant-ling, custom, self-hosted", req.Provider))
+		return
+	}
+	if req.Provider == string(database.LLMProviderSelfHosted) && (req.BaseURL == "" || strings.TrimSpace(req.Model) == "") {
+		api.RespondError(w, http.StatusBadRequest, "base_url and model are required for self-hosted configurations")
+		return
+	}
+	if req.ContextWindow < 0 || req.ContextWindow > maxLLMContextWindow {
+		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("context_window must be between 0 and %d", maxLLMContextWindow))
 		return
 	}
 	if req.BaseURL != "" && !isValidURL(req.BaseURL) {
@@ -155,8 +128,9 @@ func (h *APIHandler) createLLMConfig(w http.ResponseWriter, r *http.Request) {
 		Model:         req.Model,
 		ThinkingLevel: thinkingLevel,
 		BaseURL:       req.BaseURL,
-		Enabled:       req.APIKey != "",
+		ContextWindow: req.ContextWindow,
 	}
+	settings.Enabled = settings.IsConfigured()
 
 	if err := database.CreateLLMSettings(settings); err != nil {
 		if containsString(err.Error(), "UNIQUE") || containsString(err.Error(), "duplicate key") || containsString(err.Error(), "unique") {
@@ -171,7 +145,12 @@ func (h *APIHandler) createLLMConfig(w http.ResponseWriter, r *http.Request) {
 }
 
 // getLLMConfig returns a single LLM configuration by ID.
-func (h *APIHandler) getLLMConfig(w http.ResponseWriter, _ *http.Request, id uint) {
+func (h *APIHandler) getLLMConfig(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "config")
+	if !ok {
+		return
+	}
+
 	settings, err := database.GetLLMSettingsByID(id)
 	if err != nil {
 		api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
@@ -181,7 +160,12 @@ func (h *APIHandler) getLLMConfig(w http.ResponseWriter, _ *http.Request, id uin
 }
 
 // updateLLMConfig updates an existing LLM configuration by ID.
-func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id uint) {
+func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "config")
+	if !ok {
+		return
+	}
+
 	// Quick existence check (non-authoritative, just for early 404)
 	if _, err := database.GetLLMSettingsByID(id); err != nil {
 		api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
@@ -190,7 +174,7 @@ func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id
 
 	var req api.UpdateLLMSettingsRequest
 	if err := api.DecodeJSON(r, &req); err != nil {
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondDecodeError(w, err)
 		return
 	}
 
@@ -213,14 +197,18 @@ func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id
 		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid thinking_level: %s. Valid options: off, minimal, low, medium, high, xhigh, max", *req.ThinkingLevel))
 		return
 	}
+	if req.ContextWindow != nil && (*req.ContextWindow < 0 || *req.ContextWindow > maxLLMContextWindow) {
+		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("context_window must be between 0 and %d", maxLLMContextWindow))
+		return
+	}
 
 	updates := make(map[string]interface{})
 	if req.Name != nil {
 		updates["name"] = *req.Name
 	}
 	if req.APIKey != nil {
+		// UpdateLLMSettings keeps enabled in step with the key.
 		updates["api_key"] = *req.APIKey
-		updates["enabled"] = *req.APIKey != ""
 	}
 	if req.Model != nil {
 		updates["model"] = *req.Model
@@ -231,6 +219,9 @@ func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id
 	if req.BaseURL != nil {
 		updates["base_url"] = *req.BaseURL
 	}
+	if req.ContextWindow != nil {
+		updates["context_window"] = *req.ContextWindow
+	}
 
 	if len(updates) == 0 {
 		settings, err := database.GetLLMSettingsByID(id)
@@ -249,7 +240,11 @@ func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id
 		if containsString(errMsg, "not found") || containsString(errMsg, "record not found") {
 			api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
 		} else if containsString(errMsg, "cannot clear") || containsString(errMsg, "active") {
-			api.RespondError(w, http.StatusBadRequest, "Cannot clear the API key on the active configuration")
+			msg := "Cannot clear the API key on the active configuration"
+			if containsString(errMsg, "base URL") {
+				msg = "Cannot clear the base URL or model of the active configuration"
+			}
+			api.RespondError(w, http.StatusBadRequest, msg)
 		} else if containsString(errMsg, "UNIQUE") || containsString(errMsg, "duplicate key") || containsString(errMsg, "unique") {
 			msg := "A configuration with that name already exists"
 			if req.Name != nil {
@@ -265,7 +260,12 @@ func (h *APIHandler) updateLLMConfig(w http.ResponseWriter, r *http.Request, id
 }
 
 // deleteLLMConfig deletes an LLM configuration by ID.
-func (h *APIHandler) deleteLLMConfig(w http.ResponseWriter, _ *http.Request, id uint) {
+func (h *APIHandler) deleteLLMConfig(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "config")
+	if !ok {
+		return
+	}
+
 	if err := database.DeleteLLMSettings(id); err != nil {
 		if containsString(err.Error(), "not found") {
 			api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
@@ -281,12 +281,19 @@ func (h *APIHandler) deleteLLMConfig(w http.ResponseWriter, _ *http.Request, id
 }
 
 // activateLLMConfig sets an LLM configuration as the globally active one.
-func (h *APIHandler) activateLLMConfig(w http.ResponseWriter, _ *http.Request, id uint) {
+func (h *APIHandler) activateLLMConfig(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "config")
+	if !ok {
+		return
+	}
+
 	// All validation (existence, API key) happens inside the locked transaction
 	if err := database.SetActiveLLMConfig(id); err != nil {
 		errMsg := err.Error()
 		if containsString(errMsg, "not found") {
 			api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
+		} else if containsString(errMsg, "self-hosted") {
+			api.RespondError(w, http.StatusBadRequest, "Cannot activate a self-hosted configuration without a base URL and model")
 		} else if containsString(errMsg, "cannot activate") || containsString(errMsg, "API key") {
 			api.RespondError(w, http.StatusBadRequest, "Cannot activate a configuration without an API key")
 		} else {
@@ -304,6 +311,35 @@ func (h *APIHandler) activateLLMConfig(w http.ResponseWriter, _ *http.Request, i
 	api.RespondJSON(w, http.StatusOK, llmConfigResponse(settings))
 }
 
+// checkLLMConfigHealth probes the endpoint of a saved LLM configuration so
+// the UI can validate it before investigations depend on it.
+func (h *APIHandler) checkLLMConfigHealth(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "config")
+	if !ok {
+		return
+	}
+
+	if h.llmHealth == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "LLM health checks are not configured")
+		return
+	}
+	settings, err := database.GetLLMSettingsByID(id)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "LLM configuration not found")
+		return
+	}
+	health := h.llmHealth.CheckLLMHealth(r.Context(), settings)
+	api.RespondJSON(w, http.StatusOK, api.LLMHealthResponse{
+		Healthy:        health.Healthy,
+		Endpoint:       health.Endpoint,
+		LatencyMs:      health.LatencyMs,
+		Models:         health.Models,
+		ModelAvailable: health.ModelAvailable,
+		ContextWindow:  health.ContextWindow,
+		Error:          health.Error,
+	})
+}
+
 // llmConfigResponse builds a standard response map for an LLM config, masking the API key.
 func llmConfigResponse(s *database.LLMSettings) map[string]interface{} {
 	return map[string]interface{}{
@@ -313,8 +349,9 @@ func llmConfigResponse(s *database.LLMSettings) map[string]interface{} {
 		"model":          s.Model,
 		"thinking_level": s.ThinkingLevel,
 		"base_url":       s.BaseURL,
+		"context_window": s.ContextWindow,
 		"api_key":        maskToken(s.APIKey),
-		"is_configured":  s.APIKey != "",
+		"is_configured":  s.IsConfigured(),
 		"enabled":        s.Enabled,
 		"active":         s.Active,
 		"created_at":     s.CreatedAt,
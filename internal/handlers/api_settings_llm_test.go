@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -19,7 +21,7 @@ func setupLLMHandlerTest(t *testing.T) *APIHandler {
 	if err != nil {
 		t.Fatalf("open sqlite db: %v", err)
 	}
-	if err := db.AutoMigrate(&database.LLMSettings{}); err != nil {
+	if err := db.AutoMigrate(&database.LLMSettings{}, &database.Skill{}); err != nil {
 		t.Fatalf("migrate llm_settings: %v", err)
 	}
 	database.DB = db
@@ -48,7 +50,7 @@ func TestHandleLLMSettings_ListEmpty(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/llm", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -74,7 +76,7 @@ func TestHandleLLMSettings_ListWithConfigs(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/llm", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
@@ -106,7 +108,7 @@ func TestHandleLLMSettings_Create(t *testing.T) {
 	body := `{"provider":"openai","name":"My Config","api_key":"sk-123","model":"gpt-4","thinking_level":"high"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/settings/llm", bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -136,7 +138,7 @@ func TestHandleLLMSettings_Create_DefaultThinkingLevel(t *testing.T) {
 	body := `{"provider":"openai","name":"Default TL","api_key":"sk-123","model":"gpt-4"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/settings/llm", bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
@@ -160,18 +162,20 @@ func TestHandleLLMSettings_Create_ValidationErrors(t *testing.T) {
 		want int
 		msg  string
 	}{
-		{"missing provider", `{"name":"test"}`, http.StatusBadRequest, "provider is required"},
-		{"missing name", `{"provider":"openai"}`, http.StatusBadRequest, "name is required"},
+		{"missing provider", `{"name":"test"}`, http.StatusUnprocessableEntity, "provider is required"},
+		{"missing name", `{"provider":"openai"}`, http.StatusUnprocessableEntity, "name is required"},
 		{"invalid provider", `{"provider":"invalid","name":"test"}`, http.StatusBadRequest, "Invalid provider"},
 		{"invalid thinking_level", `{"provider":"openai","name":"test","thinking_level":"ultra"}`, http.StatusBadRequest, "Invalid thinking_level"},
 		{"invalid base_url", `{"provider":"openai","name":"test","base_url":"ftp://bad"}`, http.StatusBadRequest, "Invalid base_url"},
+		{"self-hosted without model", `{"provider":"self-hosted","name":"test","base_url":"http://vllm:8000/v1"}`, http.StatusBadRequest, "base_url and model"},
+		{"negative context_window", `{"provider":"openai","name":"test","context_window":-1}`, http.StatusBadRequest, "context_window"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/api/settings/llm", bytes.NewBufferString(tt.body))
 			w := httptest.NewRecorder()
-			h.handleLLMSettings(w, req)
+			serveAPI(h, w, req)
 
 			if w.Code != tt.want {
 				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
@@ -187,7 +191,7 @@ func TestHandleLLMSettings_Create_NameConflict(t *testing.T) {
 	body := `{"provider":"anthropic","name":"Existing","api_key":"sk-123"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/settings/llm", bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
@@ -200,7 +204,7 @@ func TestHandleLLMSettingsByID_Get(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/settings/llm/%d", c.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -220,7 +224,7 @@ func TestHandleLLMSettingsByID_Get_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/llm/999", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
@@ -234,7 +238,7 @@ func TestHandleLLMSettingsByID_Update(t *testing.T) {
 	body := `{"name":"Updated Name","model":"gpt-5"}`
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d", c.ID), bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -260,7 +264,7 @@ func TestHandleLLMSettingsByID_Update_NameConflict(t *testing.T) {
 	body := `{"name":"Config A"}`
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d", b.ID), bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusConflict {
 		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
@@ -284,7 +288,7 @@ func TestHandleLLMSettingsByID_Update_ValidationErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d", c.ID), bytes.NewBufferString(tt.body))
 			w := httptest.NewRecorder()
-			h.handleLLMSettingsByID(w, req)
+			serveAPI(h, w, req)
 
 			if w.Code != http.StatusBadRequest {
 				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
@@ -301,7 +305,7 @@ func TestHandleLLMSettingsByID_Delete(t *testing.T) {
 	// Delete inactive config should succeed
 	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/settings/llm/%d", inactive.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200 for deleting inactive, got %d: %s", w.Code, w.Body.String())
@@ -312,7 +316,7 @@ func TestHandleLLMSettingsByID_Delete(t *testing.T) {
 	seedLLMConfig(t, "Another", database.LLMProviderGoogle, false)
 	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/settings/llm/%d", active.ID), nil)
 	w = httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for deleting active, got %d: %s", w.Code, w.Body.String())
@@ -325,7 +329,7 @@ func TestHandleLLMSettingsByID_Delete_LastConfig(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/settings/llm/%d", c.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for deleting last config, got %d: %s", w.Code, w.Body.String())
@@ -340,7 +344,7 @@ func TestHandleLLMSettingsByID_Delete_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/settings/llm/999", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
@@ -354,7 +358,7 @@ func TestHandleLLMSettingsByID_Activate(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d/activate", second.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -371,7 +375,7 @@ func TestHandleLLMSettingsByID_Activate(t *testing.T) {
 	// Verify listing shows updated active_id
 	req = httptest.NewRequest(http.MethodGet, "/api/settings/llm", nil)
 	w = httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	var listResp map[string]interface{}
 	if err := json.Unmarshal(w.Body.Bytes(), &listResp); err != nil {
@@ -387,7 +391,7 @@ func TestHandleLLMSettingsByID_Activate_NotFound(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPut, "/api/settings/llm/999/activate", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
@@ -399,7 +403,7 @@ func TestHandleLLMSettings_MethodNotAllowed(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/settings/llm", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -411,7 +415,7 @@ func TestHandleLLMSettingsByID_InvalidID(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/llm/abc", nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
@@ -424,7 +428,7 @@ func TestHandleLLMSettingsByID_Activate_MethodNotAllowed(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/settings/llm/%d/activate", c.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
@@ -448,7 +452,7 @@ func TestHandleLLMSettingsByID_Activate_NoAPIKey(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d/activate", s.ID), nil)
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for activating unconfigured config, got %d: %s", w.Code, w.Body.String())
@@ -462,7 +466,7 @@ func TestHandleLLMSettingsByID_Update_ClearAPIKeyOnActive(t *testing.T) {
 	body := `{"api_key":""}`
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d", c.ID), bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for clearing API key on active config, got %d: %s", w.Code, w.Body.String())
@@ -476,9 +480,82 @@ func TestHandleLLMSettingsByID_Update_ClearAPIKeyOnInactive(t *testing.T) {
 	body := `{"api_key":""}`
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/settings/llm/%d", c.ID), bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	h.handleLLMSettingsByID(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200 for clearing API key on inactive config, got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+type fakeLLMHealthProber struct {
+	got *database.LLMSettings
+}
+
+func (f *fakeLLMHealthProber) CheckLLMHealth(_ context.Context, settings *database.LLMSettings) services.LLMHealth {
+	f.got = settings
+	return services.LLMHealth{Healthy: true, Endpoint: settings.BaseURL + "/models", Models: []string{settings.Model}, ModelAvailable: true, ContextWindow: 32768}
+}
+
+func TestHandleLLMSettings_SelfHosted(t *testing.T) {
+	h := setupLLMHandlerTest(t)
+
+	body := `{"provider":"self-hosted","name":"On-prem","base_url":"http://vllm:8000/v1","model":"qwen3-32b","context_window":32768}`
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/llm", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if created["is_configured"] != true || created["enabled"] != true {
+		t.Errorf("expected a keyless self-hosted config to be configured and enabled, got %v", created)
+	}
+	if created["context_window"] != float64(32768) {
+		t.Errorf("expected context_window 32768, got %v", created["context_window"])
+	}
+	id := uint(created["id"].(float64))
+
+	// Health check is 503 until a prober is wired.
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/settings/llm/%d/health", id), nil)
+	w = httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without prober, got %d", w.Code)
+	}
+
+	prober := &fakeLLMHealthProber{}
+	h.SetLLMHealthProber(prober)
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/settings/llm/%d/health", id), nil)
+	w = httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var health map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if health["healthy"] != true || health["model_available"] != true || health["context_window"] != float64(32768) {
+		t.Errorf("unexpected health response: %v", health)
+	}
+	if prober.got == nil || prober.got.Model != "qwen3-32b" {
+		t.Errorf("expected the saved config to be probed, got %+v", prober.got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/settings/llm/%d/health", id), nil)
+	w = httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET health, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/settings/llm/9999/health", nil)
+	w = httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown config, got %d", w.Code)
+	}
+}
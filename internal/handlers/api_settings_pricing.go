@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// getModelPrices handles GET /api/settings/model-prices
+func (h *APIHandler) getModelPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := database.ListModelPrices()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get model prices")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, prices)
+}
+
+// updateModelPrices handles PUT /api/settings/model-prices
+func (h *APIHandler) updateModelPrices(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateModelPricesRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	prices, err := validateModelPrices(req.Prices)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stored, err := database.ReplaceModelPrices(prices)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update model prices")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, stored)
+}
+
+// validateModelPrices trims and checks submitted price rows. Model patterns
+// must be unique (case-insensitively, matching how they are looked up).
+func validateModelPrices(in []api.ModelPriceInput) ([]database.ModelPrice, error) {
+	prices := make([]database.ModelPrice, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for i, p := range in {
+		model := strings.TrimSpace(p.Model)
+		if model == "" {
+			return nil, fmt.Errorf("prices[%d]: model is required", i)
+		}
+		if len(model) > 100 {
+			return nil, fmt.Errorf("prices[%d]: model must be at most 100 characters", i)
+		}
+		if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return nil, fmt.Errorf("prices[%d]: '*' is only allowed at the end of a model pattern", i)
+		}
+		if p.USDPerMillionTokens < 0 || p.USDPerMillionTokens > 10000 {
+			return nil, fmt.Errorf("prices[%d]: usd_per_million_tokens must be between 0 and 10000", i)
+		}
+		key := strings.ToLower(model)
+		if seen[key] {
+			return nil, fmt.Errorf("prices[%d]: duplicate model %q", i, model)
+		}
+		seen[key] = true
+		prices = append(prices, database.ModelPrice{Model: model, USDPerMillionTokens: p.USDPerMillionTokens})
+	}
+	return prices, nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleModelPrices_PUT_Validation(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"invalid json", `{invalid`, ""},
+		{"empty model", `{"prices":[{"model":" ","usd_per_million_tokens":1}]}`, "model is required"},
+		{"negative price", `{"prices":[{"model":"gpt-5","usd_per_million_tokens":-1}]}`, "between 0 and 10000"},
+		{"inner wildcard", `{"prices":[{"model":"gpt-*-mini","usd_per_million_tokens":1}]}`, "only allowed at the end"},
+		{"duplicate", `{"prices":[{"model":"gpt-5","usd_per_million_tokens":1},{"model":"GPT-5","usd_per_million_tokens":2}]}`, "duplicate model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/model-prices", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			serveAPI(h, w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("body = %s, want it to mention %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleModelPrices_MethodNotAllowed(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/model-prices", nil)
+	w := httptest.NewRecorder()
+
+	serveAPI(h, w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
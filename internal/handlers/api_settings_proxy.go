@@ -9,18 +9,6 @@ import (
 	"github.com/akmatori/akmatori/internal/database"
 )
 
-// handleProxySettings handles GET /api/settings/proxy and PUT /api/settings/proxy
-func (h *APIHandler) handleProxySettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetProxySettings(w, r)
-	case http.MethodPut:
-		h.UpdateProxySettings(w, r)
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
-
 // GetProxySettings returns the current proxy configuration
 func (h *APIHandler) GetProxySettings(w http.ResponseWriter, r *http.Request) {
 	settings, err := database.GetOrCreateProxySettings()
@@ -75,6 +63,18 @@ func (h *APIHandler) GetProxySettings(w http.ResponseWriter, r *http.Request) {
 				"enabled":   settings.JiraEnabled,
 				"supported": true,
 			},
+			"prometheus": map[string]interface{}{
+				"enabled":   settings.PrometheusEnabled,
+				"supported": true,
+			},
+			"log_search": map[string]interface{}{
+				"enabled":   settings.LogSearchEnabled,
+				"supported": true,
+			},
+			"http_check": map[string]interface{}{
+				"enabled":   settings.HTTPCheckEnabled,
+				"supported": true,
+			},
 			"ssh": map[string]interface{}{
 				"enabled":   false,
 				"supported": false,
@@ -89,7 +89,7 @@ func (h *APIHandler) GetProxySettings(w http.ResponseWriter, r *http.Request) {
 func (h *APIHandler) UpdateProxySettings(w http.ResponseWriter, r *http.Request) {
 	var input api.UpdateProxySettingsRequest
 	if err := api.DecodeJSON(r, &input); err != nil {
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondDecodeError(w, err)
 		return
 	}
 
@@ -116,6 +116,9 @@ func (h *APIHandler) UpdateProxySettings(w http.ResponseWriter, r *http.Request)
 	settings.NetBoxEnabled = input.Services.NetBox.Enabled
 	settings.K8sEnabled = input.Services.Kubernetes.Enabled
 	settings.JiraEnabled = input.Services.Jira.Enabled
+	settings.PrometheusEnabled = input.Services.Prometheus.Enabled
+	settings.LogSearchEnabled = input.Services.LogSearch.Enabled
+	settings.HTTPCheckEnabled = input.Services.HTTPCheck.Enabled
 
 	if err := database.UpdateProxySettings(settings); err != nil {
 		api.RespondError(w, http.StatusInternalServerError, "Failed to update proxy settings")
@@ -124,7 +127,7 @@ func (h *APIHandler) UpdateProxySettings(w http.ResponseWriter, r *http.Request)
 
 	if h.agentWSHandler != nil && h.agentWSHandler.IsWorkerConnected() {
 		if err := h.agentWSHandler.BroadcastProxyConfig(settings); err != nil {
-			slog.Warn("failed to broadcast proxy config to agent worker", "err", err)
+			slog.WarnContext(r.Context(), "failed to broadcast proxy config to agent worker", "err", err)
 		}
 	}
 
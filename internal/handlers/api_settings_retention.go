@@ -5,58 +5,126 @@ import (
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
-// handleRetentionSettings handles GET/PUT /api/settings/retention
-func (h *APIHandler) handleRetentionSettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		settings, err := database.GetOrCreateRetentionSettings()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get retention settings")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, settings)
+// SetRetentionRunner wires on-demand cleanup and workspace usage behind
+// /api/settings/retention/{cleanup,usage}. Optional — when unset those
+// endpoints return 503.
+func (h *APIHandler) SetRetentionRunner(r services.RetentionRunner) {
+	h.retention = r
+}
 
-	case http.MethodPut:
-		var req api.UpdateRetentionSettingsRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+// getRetentionSettings handles GET /api/settings/retention
+func (h *APIHandler) getRetentionSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := database.GetOrCreateRetentionSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get retention settings")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, settings)
+}
 
-		settings, err := database.GetOrCreateRetentionSettings()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get retention settings")
-			return
-		}
+// updateRetentionSettings handles PUT /api/settings/retention
+func (h *APIHandler) updateRetentionSettings(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateRetentionSettingsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	settings, err := database.GetOrCreateRetentionSettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get retention settings")
+		return
+	}
 
-		if req.Enabled != nil {
-			settings.Enabled = *req.Enabled
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.RetentionDays != nil {
+		if *req.RetentionDays < 1 || *req.RetentionDays > 3650 {
+			api.RespondError(w, http.StatusBadRequest, "retention_days must be between 1 and 3650")
+			return
 		}
-		if req.RetentionDays != nil {
-			if *req.RetentionDays < 1 || *req.RetentionDays > 3650 {
-				api.RespondError(w, http.StatusBadRequest, "retention_days must be between 1 and 3650")
-				return
-			}
-			settings.RetentionDays = *req.RetentionDays
+		settings.RetentionDays = *req.RetentionDays
+	}
+	if req.CleanupIntervalHours != nil {
+		if *req.CleanupIntervalHours < 1 || *req.CleanupIntervalHours > 8760 {
+			api.RespondError(w, http.StatusBadRequest, "cleanup_interval_hours must be between 1 and 8760")
+			return
 		}
-		if req.CleanupIntervalHours != nil {
-			if *req.CleanupIntervalHours < 1 || *req.CleanupIntervalHours > 8760 {
-				api.RespondError(w, http.StatusBadRequest, "cleanup_interval_hours must be between 1 and 8760")
-				return
-			}
-			settings.CleanupIntervalHours = *req.CleanupIntervalHours
+		settings.CleanupIntervalHours = *req.CleanupIntervalHours
+	}
+	if req.WorkspaceMaxAgeDays != nil {
+		if *req.WorkspaceMaxAgeDays < 0 || *req.WorkspaceMaxAgeDays > 3650 {
+			api.RespondError(w, http.StatusBadRequest, "workspace_max_age_days must be between 0 and 3650")
+			return
 		}
-
-		if err := database.UpdateRetentionSettings(settings); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to update retention settings")
+		settings.WorkspaceMaxAgeDays = *req.WorkspaceMaxAgeDays
+	}
+	if req.WorkspaceMaxTotalMB != nil {
+		if *req.WorkspaceMaxTotalMB < 0 {
+			api.RespondError(w, http.StatusBadRequest, "workspace_max_total_mb must not be negative")
 			return
 		}
+		settings.WorkspaceMaxTotalMB = *req.WorkspaceMaxTotalMB
+	}
+	if req.ArchiveWorkspaces != nil {
+		settings.ArchiveWorkspaces = *req.ArchiveWorkspaces
+	}
+
+	if err := database.UpdateRetentionSettings(settings); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update retention settings")
+		return
+	}
 
-		api.RespondJSON(w, http.StatusOK, settings)
+	api.RespondJSON(w, http.StatusOK, settings)
+}
+
+// handleRetentionUsage handles GET /api/settings/retention/usage: disk used
+// by incident workspaces and archives, with the largest workspaces.
+func (h *APIHandler) handleRetentionUsage(w http.ResponseWriter, r *http.Request) {
+	if h.retention == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Retention is not configured")
+		return
+	}
+	usage, err := h.retention.WorkspaceUsage()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to read workspace usage")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, usage)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// handleRetentionCleanup handles POST /api/settings/retention/cleanup: runs
+// a cleanup now with the saved settings. Per-item failures are reported in
+// errors and do not fail the request.
+func (h *APIHandler) handleRetentionCleanup(w http.ResponseWriter, r *http.Request) {
+	if h.retention == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Retention is not configured")
+		return
+	}
+	result, err := h.retention.RunCleanup()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to run retention cleanup")
+		return
+	}
+	resp := api.RetentionCleanupResponse{
+		Skipped:                 result.Skipped,
+		ExpiredIncidentsDeleted: result.ExpiredIncidentsDeleted,
+		ExpiredAlertsDeleted:    result.ExpiredAlertsDeleted,
+		ExpiredDirsDeleted:      result.ExpiredDirsDeleted,
+		ExpiredBytesFreed:       result.ExpiredBytesFreed,
+		OrphanedDirsDeleted:     result.OrphanedDirsDeleted,
+		OrphanedBytesFreed:      result.OrphanedBytesFreed,
+		WorkspacesPruned:        result.WorkspacesPruned,
+		WorkspaceBytesFreed:     result.WorkspaceBytesFreed,
+		WorkspacesArchived:      result.WorkspacesArchived,
+		Errors:                  make([]string, 0, len(result.Errors)),
+	}
+	for _, e := range result.Errors {
+		resp.Errors = append(resp.Errors, e.Error())
 	}
+	api.RespondJSON(w, http.StatusOK, resp)
 }
@@ -37,7 +37,7 @@ func TestHandleRetentionSettings_GET_ReturnsDefaults(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/settings/retention", nil)
 	w := httptest.NewRecorder()
 
-	h.handleRetentionSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -66,7 +66,7 @@ func TestHandleRetentionSettings_PUT_ValidUpdate(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/settings/retention", strings.NewReader(body))
 	w := httptest.NewRecorder()
 
-	h.handleRetentionSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
@@ -101,15 +101,42 @@ func TestHandleRetentionSettings_PUT_ValidationBounds(t *testing.T) {
 		{"cleanup_interval_zero", `{"cleanup_interval_hours": 0}`},
 		{"cleanup_interval_negative", `{"cleanup_interval_hours": -1}`},
 		{"cleanup_interval_too_high", `{"cleanup_interval_hours": 8761}`},
+		{"workspace_max_age_negative", `{"workspace_max_age_days": -1}`},
+		{"workspace_max_age_too_high", `{"workspace_max_age_days": 3651}`},
+		{"workspace_max_total_negative", `{"workspace_max_total_mb": -1}`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPut, "/api/settings/retention", strings.NewReader(tt.body))
 			w := httptest.NewRecorder()
-			h.handleRetentionSettings(w, req)
+			serveAPI(h, w, req)
 			if w.Code != http.StatusBadRequest {
 				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
 			}
 		})
 	}
 }
+
+func TestHandleRetentionSettings_PUT_WorkspaceLimits(t *testing.T) {
+	setupRetentionHandlerTestDB(t)
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	body := `{"workspace_max_age_days": 14, "workspace_max_total_mb": 2048, "archive_workspaces": true}`
+	req := httptest.NewRequest(http.MethodPut, "/api/settings/retention", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	serveAPI(h, w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	settings, err := database.GetOrCreateRetentionSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.WorkspaceMaxAgeDays != 14 || settings.WorkspaceMaxTotalMB != 2048 || !settings.ArchiveWorkspaces {
+		t.Errorf("settings = %+v", settings)
+	}
+	if settings.RetentionDays != 90 {
+		t.Errorf("RetentionDays = %d, want the default kept", settings.RetentionDays)
+	}
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
 func TestHandleRetentionSettings_MethodNotAllowed(t *testing.T) {
@@ -16,7 +21,7 @@ func TestHandleRetentionSettings_MethodNotAllowed(t *testing.T) {
 			req := httptest.NewRequest(method, "/api/settings/retention", nil)
 			w := httptest.NewRecorder()
 
-			h.handleRetentionSettings(w, req)
+			serveAPI(h, w, req)
 
 			if w.Code != http.StatusMethodNotAllowed {
 				t.Errorf("expected 405, got %d", w.Code)
@@ -31,9 +36,72 @@ func TestHandleRetentionSettings_PUT_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/api/settings/retention", strings.NewReader("{invalid"))
 	w := httptest.NewRecorder()
 
-	h.handleRetentionSettings(w, req)
+	serveAPI(h, w, req)
 
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", w.Code)
 	}
 }
+
+type fakeRetentionRunner struct {
+	result *services.CleanupResult
+	usage  *services.WorkspaceUsage
+}
+
+func (f *fakeRetentionRunner) RunCleanup() (*services.CleanupResult, error) { return f.result, nil }
+func (f *fakeRetentionRunner) WorkspaceUsage() (*services.WorkspaceUsage, error) {
+	return f.usage, nil
+}
+
+func TestHandleRetentionCleanup(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/settings/retention/cleanup", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unset runner: expected 503, got %d", w.Code)
+	}
+
+	h.SetRetentionRunner(&fakeRetentionRunner{result: &services.CleanupResult{
+		WorkspacesPruned:    2,
+		WorkspaceBytesFreed: 2048,
+		Errors:              []error{errors.New("archive abc: disk full")},
+	}})
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/settings/retention/cleanup", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp api.RetentionCleanupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.WorkspacesPruned != 2 || resp.WorkspaceBytesFreed != 2048 || len(resp.Errors) != 1 || resp.Errors[0] != "archive abc: disk full" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestHandleRetentionUsage(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetRetentionRunner(&fakeRetentionRunner{usage: &services.WorkspaceUsage{
+		TotalBytes:     4096,
+		WorkspaceCount: 1,
+		Largest:        []services.WorkspaceEntry{{IncidentUUID: "abc", Bytes: 4096, Status: "completed"}},
+	}})
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings/retention/usage", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{`"total_bytes":4096`, `"incident_uuid":"abc"`, `"status":"completed"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body %s missing %s", body, want)
+		}
+	}
+}
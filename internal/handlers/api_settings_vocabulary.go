@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// maxVocabularyValueLen bounds a single emoji or label.
+const maxVocabularyValueLen = 64
+
+var vocabularyColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var vocabularySeverities = []string{
+	string(database.AlertSeverityCritical),
+	string(database.AlertSeverityHigh),
+	string(database.AlertSeverityWarning),
+	string(database.AlertSeverityInfo),
+}
+
+var vocabularyIncidentStatuses = []string{
+	string(database.IncidentStatusPending),
+	string(database.IncidentStatusRunning),
+	string(database.IncidentStatusDiagnosed),
+	string(database.IncidentStatusCompleted),
+	string(database.IncidentStatusFailed),
+	string(database.IncidentStatusMonitor),
+	string(database.IncidentStatusClosed),
+	string(database.IncidentStatusMerged),
+	string(database.IncidentStatusBudgetExceeded),
+	string(database.IncidentStatusCancelled),
+}
+
+// vocabularyResultStatuses are the [FINAL_RESULT] statuses shown in Slack.
+var vocabularyResultStatuses = []string{"resolved", "unresolved", "escalate"}
+
+// getVocabularySettings handles GET /api/settings/vocabulary
+func (h *APIHandler) getVocabularySettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := database.GetOrCreateVocabularySettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get vocabulary settings")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, settings)
+}
+
+// updateVocabularySettings handles PUT /api/settings/vocabulary
+func (h *APIHandler) updateVocabularySettings(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateVocabularySettingsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	fieldErrors := map[string]string{}
+	severityEmoji := validateVocabularyMap("severity_emoji", req.SeverityEmoji, vocabularySeverities, validateVocabularyEmoji, fieldErrors)
+	statusLabels := validateVocabularyMap("status_labels", req.StatusLabels, slices.Concat(vocabularyIncidentStatuses, vocabularyResultStatuses), validateVocabularyLabel, fieldErrors)
+	statusColors := validateVocabularyMap("status_colors", req.StatusColors, vocabularyIncidentStatuses, validateVocabularyColor, fieldErrors)
+	if len(fieldErrors) > 0 {
+		api.RespondValidationError(w, fieldErrors)
+		return
+	}
+
+	settings, err := database.GetOrCreateVocabularySettings()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get vocabulary settings")
+		return
+	}
+	if req.SeverityEmoji != nil {
+		settings.SeverityEmoji = severityEmoji
+	}
+	if req.StatusLabels != nil {
+		settings.StatusLabels = statusLabels
+	}
+	if req.StatusColors != nil {
+		settings.StatusColors = statusColors
+	}
+
+	if err := database.UpdateVocabularySettings(settings); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update vocabulary settings")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, settings)
+}
+
+// validateVocabularyMap checks that every key of m is one of keys and every
+// value passes check, recording failures in fieldErrors under
+// "<field>.<key>". It returns the trimmed entries.
+func validateVocabularyMap(field string, m map[string]string, keys []string, check func(string) string, fieldErrors map[string]string) database.JSONB {
+	out := database.JSONB{}
+	for k, v := range m {
+		if !slices.Contains(keys, k) {
+			fieldErrors[field+"."+k] = fmt.Sprintf("unknown key; use one of %s", strings.Join(keys, ", "))
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if msg := check(v); msg != "" {
+			fieldErrors[field+"."+k] = msg
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func validateVocabularyEmoji(v string) string {
+	if v == "" || len(v) > maxVocabularyValueLen || strings.ContainsAny(v, " \t\n") {
+		return fmt.Sprintf("must be a Slack emoji code such as :fire: or an emoji, at most %d bytes", maxVocabularyValueLen)
+	}
+	return ""
+}
+
+func validateVocabularyLabel(v string) string {
+	if v == "" || len(v) > maxVocabularyValueLen {
+		return fmt.Sprintf("must be 1-%d bytes", maxVocabularyValueLen)
+	}
+	return ""
+}
+
+func validateVocabularyColor(v string) string {
+	if !vocabularyColorPattern.MatchString(v) {
+		return "must be a #rrggbb color"
+	}
+	return ""
+}
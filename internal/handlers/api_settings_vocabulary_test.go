@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestHandleVocabularySettings_PUT_AppliesOverrides(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.VocabularySettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	body := `{"severity_emoji":{"critical":":fire:"},"status_labels":{"diagnosed":"Triaged","resolved":"Mitigated"},"status_colors":{"diagnosed":"#7C3AED"}}`
+	w := httptest.NewRecorder()
+	serveAPI(h, w, httptest.NewRequest(http.MethodPut, "/api/settings/vocabulary", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if got := database.GetSeverityEmoji(database.AlertSeverityCritical); got != ":fire:" {
+		t.Errorf("critical emoji = %q, want the override", got)
+	}
+	if got := database.GetSeverityEmoji(database.AlertSeverityInfo); got != ":large_blue_circle:" {
+		t.Errorf("info emoji = %q, want the built-in one", got)
+	}
+	incident := database.Incident{Status: database.IncidentStatusDiagnosed}
+	incident.ApplyVocabulary(database.LoadVocabulary())
+	if incident.StatusLabel != "Triaged" || incident.StatusColor != "#7C3AED" {
+		t.Errorf("label, color = %q, %q", incident.StatusLabel, incident.StatusColor)
+	}
+
+	// An absent map is kept; an empty one clears.
+	w = httptest.NewRecorder()
+	serveAPI(h, w, httptest.NewRequest(http.MethodPut, "/api/settings/vocabulary", strings.NewReader(`{"severity_emoji":{}}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := database.GetSeverityEmoji(database.AlertSeverityCritical); got != ":red_circle:" {
+		t.Errorf("critical emoji after clear = %q", got)
+	}
+	if got := database.LoadVocabulary().StatusLabel("resolved"); got != "Mitigated" {
+		t.Errorf("resolved label = %q, want it kept", got)
+	}
+}
+
+func TestHandleVocabularySettings_PUT_Validation(t *testing.T) {
+	testhelpers.NewGlobalSQLiteDB(t, &database.VocabularySettings{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	for _, body := range []string{
+		`{"severity_emoji":{"sev1":":fire:"}}`,
+		`{"severity_emoji":{"critical":"red circle"}}`,
+		`{"status_labels":{"running":"  "}}`,
+		`{"status_colors":{"resolved":"#ff0000"}}`,
+		`{"status_colors":{"failed":"red"}}`,
+	} {
+		w := httptest.NewRecorder()
+		serveAPI(h, w, httptest.NewRequest(http.MethodPut, "/api/settings/vocabulary", strings.NewReader(body)))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetSilenceManager wires the service backing /api/silences.
+// Optional — when unset the endpoints return 503.
+func (h *APIHandler) SetSilenceManager(m services.SilenceManager) {
+	h.silences = m
+}
+
+// handleListSilences handles GET /api/silences, optionally filtered by
+// ?state=active|pending|expired.
+func (h *APIHandler) handleListSilences(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	silences, err := h.silences.ListSilences(r.Context(), r.URL.Query().Get("state"))
+	if err != nil {
+		h.respondSilenceError(w, "list", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, silences)
+}
+
+// handleCreateSilence handles POST /api/silences.
+func (h *APIHandler) handleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	var req api.SilenceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	silence := silenceFromRequest(&req, time.Now())
+	silence.CreatedBy = middleware.GetUserFromContext(r.Context())
+	if err := h.silences.CreateSilence(r.Context(), silence); err != nil {
+		h.respondSilenceError(w, "create", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusCreated, silence)
+}
+
+// handleGetSilence handles GET /api/silences/{uuid}.
+func (h *APIHandler) handleGetSilence(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	silence, err := h.silences.GetSilence(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.respondSilenceError(w, "get", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, silence)
+}
+
+// handleUpdateSilence handles PUT /api/silences/{uuid}. The body replaces
+// the silence's matchers and window.
+func (h *APIHandler) handleUpdateSilence(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	var req api.SilenceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	existing, err := h.silences.GetSilence(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.respondSilenceError(w, "get", err)
+		return
+	}
+	silence, err := h.silences.UpdateSilence(r.Context(), existing.UUID, silenceFromRequest(&req, existing.StartsAt))
+	if err != nil {
+		h.respondSilenceError(w, "update", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, silence)
+}
+
+// handleExpireSilence handles DELETE /api/silences/{uuid}. The silence is
+// expired rather than deleted so its suppressed alerts keep their context.
+func (h *APIHandler) handleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	silence, err := h.silences.ExpireSilence(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.respondSilenceError(w, "expire", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, silence)
+}
+
+// handleSuppressedAlerts handles GET /api/silences/{uuid}/suppressed.
+func (h *APIHandler) handleSuppressedAlerts(w http.ResponseWriter, r *http.Request) {
+	if h.silences == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Silences are not configured")
+		return
+	}
+	silence, err := h.silences.GetSilence(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.respondSilenceError(w, "get", err)
+		return
+	}
+	rows, err := h.silences.ListSuppressedAlerts(r.Context(), silence.UUID)
+	if err != nil {
+		h.respondSilenceError(w, "list suppressed alerts for", err)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, rows)
+}
+
+func (h *APIHandler) respondSilenceError(w http.ResponseWriter, verb string, err error) {
+	switch {
+	case errors.Is(err, services.ErrSilenceNotFound):
+		api.RespondError(w, http.StatusNotFound, "Silence not found")
+	case errors.Is(err, services.ErrInvalidSilence):
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error("silences: failed to "+verb+" silence", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to "+verb+" silence")
+	}
+}
+
+// silenceFromRequest maps a request onto a Silence. defaultStart is used
+// when starts_at is omitted and duration_minutes needs a start to count
+// from.
+func silenceFromRequest(req *api.SilenceRequest, defaultStart time.Time) *database.Silence {
+	silence := &database.Silence{
+		Comment:    req.Comment,
+		SourceUUID: req.SourceUUID,
+		AlertName:  req.AlertName,
+		TargetHost: req.TargetHost,
+		StartsAt:   defaultStart,
+	}
+	if len(req.Labels) > 0 {
+		silence.Labels = database.JSONB{}
+		for k, v := range req.Labels {
+			silence.Labels[k] = v
+		}
+	}
+	if req.StartsAt != nil {
+		silence.StartsAt = *req.StartsAt
+	}
+	switch {
+	case req.EndsAt != nil:
+		silence.EndsAt = *req.EndsAt
+	case req.DurationMinutes > 0:
+		silence.EndsAt = silence.StartsAt.Add(time.Duration(req.DurationMinutes) * time.Minute)
+	}
+	return silence
+}
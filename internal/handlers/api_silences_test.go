@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestSilencesAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Silence{}, &database.SuppressedAlert{})
+	svc := services.NewSilenceService(db)
+
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/silences", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	h.SetSilenceManager(svc)
+
+	if rec := serveJSON(mux, http.MethodPost, "/api/silences", `{"duration_minutes":60}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("silence without matchers: status = %d, want 400", rec.Code)
+	}
+
+	rec := serveJSON(mux, http.MethodPost, "/api/silences",
+		`{"target_host":"db-*","labels":{"env":"prod"},"comment":"patching","duration_minutes":120}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created database.Silence
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if d := created.EndsAt.Sub(created.StartsAt); d != 2*time.Hour {
+		t.Errorf("window = %s, want 2h", d)
+	}
+
+	rec = serveJSON(mux, http.MethodPut, "/api/silences/"+created.UUID,
+		`{"target_host":"db-01","duration_minutes":30}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated database.Silence
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	if updated.TargetHost != "db-01" || len(updated.Labels) != 0 || !updated.StartsAt.Equal(created.StartsAt) {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if _, err := svc.SilenceAlert(t.Context(), "src-1", alerts.NormalizedAlert{AlertName: "HostDown", TargetHost: "db-01"}); err != nil {
+		t.Fatal(err)
+	}
+	rec = serveJSON(mux, http.MethodGet, "/api/silences/"+created.UUID+"/suppressed", "")
+	var suppressed []database.SuppressedAlert
+	_ = json.Unmarshal(rec.Body.Bytes(), &suppressed)
+	if rec.Code != http.StatusOK || len(suppressed) != 1 || suppressed[0].AlertName != "HostDown" {
+		t.Errorf("suppressed status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveJSON(mux, http.MethodDelete, "/api/silences/"+created.UUID, ""); rec.Code != http.StatusOK {
+		t.Errorf("expire status = %d", rec.Code)
+	}
+	rec = serveJSON(mux, http.MethodGet, "/api/silences?state=expired", "")
+	var expired []database.Silence
+	_ = json.Unmarshal(rec.Body.Bytes(), &expired)
+	if len(expired) != 1 {
+		t.Errorf("expired silences = %s", rec.Body.String())
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/silences?state=bogus", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad state status = %d, want 400", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/silences/missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+// defaultSkillBundleVersion is the manifest version of an export that does
+// not name one.
+const defaultSkillBundleVersion = "1.0.0"
+
+var skillBundleVersionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+-]{0,63}$`)
+
+// SetSkillBundler wires skill import and export. Optional — when unset the
+// endpoints return 503.
+func (h *APIHandler) SetSkillBundler(b services.SkillBundler) {
+	h.skillBundler = b
+}
+
+// handleSkillExport handles GET /api/skills/{name}/export. It downloads the
+// skill as a bundle: SKILL.md, scripts, the context files its prompt
+// references and the tool types its tools need. ?format=tar.gz (default) or
+// zip; ?version= sets the manifest version.
+func (h *APIHandler) handleSkillExport(w http.ResponseWriter, r *http.Request) {
+	if h.skillBundler == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Skill bundles are not configured")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = services.SkillBundleTarGz
+	}
+	if format != services.SkillBundleTarGz && format != services.SkillBundleZip {
+		api.RespondError(w, http.StatusBadRequest, "format must be tar.gz or zip")
+		return
+	}
+	version := r.URL.Query().Get("version")
+	if version == "" {
+		version = defaultSkillBundleVersion
+	}
+	if !skillBundleVersionPattern.MatchString(version) {
+		api.RespondError(w, http.StatusBadRequest, "version must be 1-64 letters, digits, '.', '+' or '-'")
+		return
+	}
+
+	name := r.PathValue("name")
+	bundle, err := h.skillBundler.ExportSkillBundle(name, version)
+	if err != nil {
+		h.respondSkillBundleError(w, err)
+		return
+	}
+	// Buffered so a failure still gets a JSON error; bundles are small.
+	var buf bytes.Buffer
+	if err := services.WriteSkillBundle(&buf, bundle, format); err != nil {
+		h.respondSkillBundleError(w, err)
+		return
+	}
+	contentType := "application/gzip"
+	if format == services.SkillBundleZip {
+		contentType = "application/zip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, version, format))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleSkillImport handles POST /api/skills/import. The bundle is the
+// request body, or the "file" field of a multipart form. An existing skill
+// of the same name is left alone (409).
+func (h *APIHandler) handleSkillImport(w http.ResponseWriter, r *http.Request) {
+	if h.skillBundler == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Skill bundles are not configured")
+		return
+	}
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "multipart upload needs a file field")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	bundle, err := services.ReadSkillBundle(body)
+	if err != nil {
+		h.respondSkillBundleError(w, err)
+		return
+	}
+	result, err := h.skillBundler.InstallSkillBundle(bundle)
+	if err != nil {
+		h.respondSkillBundleError(w, err)
+		return
+	}
+	slog.InfoContext(r.Context(), "imported skill bundle", "skill", bundle.Manifest.Name, "version", result.Version,
+		"missing_tool_types", result.MissingToolTypes, "conflicting_references", result.ConflictingReferences)
+	api.RespondJSON(w, http.StatusCreated, result)
+}
+
+func (h *APIHandler) respondSkillBundleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		api.RespondError(w, http.StatusNotFound, "Skill not found")
+	case errors.Is(err, services.ErrSkillExists):
+		api.RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidSkillBundle):
+		api.RespondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		slog.Error("skill bundle request failed", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Skill bundle request failed")
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type fakeSkillBundler struct {
+	installed  *services.SkillBundle
+	installErr error
+}
+
+func (f *fakeSkillBundler) ExportSkillBundle(name, version string) (*services.SkillBundle, error) {
+	if name != "pg-replica" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &services.SkillBundle{
+		Manifest: services.SkillBundleManifest{Name: name, Version: version, Description: "Replica lag triage"},
+		Prompt:   "Check replay lag first.",
+		Scripts:  map[string]string{"lag.sh": "echo lag\n"},
+	}, nil
+}
+
+func (f *fakeSkillBundler) InstallSkillBundle(bundle *services.SkillBundle) (*services.SkillInstallResult, error) {
+	if f.installErr != nil {
+		return nil, f.installErr
+	}
+	f.installed = bundle
+	return &services.SkillInstallResult{Skill: &database.Skill{Name: bundle.Manifest.Name}, Version: bundle.Manifest.Version}, nil
+}
+
+func TestSkillBundleAPI(t *testing.T) {
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/skills/pg-replica/export", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	bundler := &fakeSkillBundler{}
+	h.SetSkillBundler(bundler)
+
+	rec := serveJSON(mux, http.MethodGet, "/api/skills/pg-replica/export?format=zip&version=2.0.0", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("export status = %d type = %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="pg-replica-2.0.0.zip"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	archive := rec.Body.Bytes()
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, _ := mw.CreateFormFile("file", "pg-replica.zip")
+	_, _ = fw.Write(archive)
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/skills/import", &form)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if b := bundler.installed; b == nil || b.Manifest.Version != "2.0.0" || b.Scripts["lag.sh"] != "echo lag\n" {
+		t.Errorf("installed bundle = %+v", bundler.installed)
+	}
+
+	bundler.installErr = fmt.Errorf("%w: pg-replica", services.ErrSkillExists)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/skills/import", bytes.NewReader(archive)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("existing skill status = %d, want 409", rec.Code)
+	}
+
+	for path, want := range map[string]int{
+		"/api/skills/missing/export":                http.StatusNotFound,
+		"/api/skills/pg-replica/export?format=rar":  http.StatusBadRequest,
+		"/api/skills/pg-replica/export?version=a/b": http.StatusBadRequest,
+	} {
+		if rec := serveJSON(mux, http.MethodGet, path, ""); rec.Code != want {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+	if rec := serveJSON(mux, http.MethodPost, "/api/skills/import", "not an archive"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid bundle status = %d, want 422", rec.Code)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/executor"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Skill dry run timeouts. The request is held open for the whole run.
+const (
+	defaultSkillTestTimeout = 5 * time.Minute
+	maxSkillTestTimeout     = 30 * time.Minute
+)
+
+// SetSkillTester wires skill dry runs behind POST /api/skills/{name}/test.
+// Optional — when unset the endpoint returns 503.
+func (h *APIHandler) SetSkillTester(t services.SkillTester) {
+	h.skillTester = t
+}
+
+// handleSkillTest handles POST /api/skills/{name}/test. It investigates a
+// synthetic alert with only the named skill and its tools, in an ephemeral
+// workspace that is removed afterwards, and returns the agent's output and
+// tool calls. No incident is created and nothing is posted to Slack, so
+// skill authors can iterate on a skill without paging anyone. Tools run
+// for real, subject to the usual write approvals.
+func (h *APIHandler) handleSkillTest(w http.ResponseWriter, r *http.Request) {
+	if h.skillTester == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Skill testing is not configured")
+		return
+	}
+	var req api.SkillTestRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	timeout := defaultSkillTestTimeout
+	if req.TimeoutSeconds != 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+		if timeout < 0 || timeout > maxSkillTestTimeout {
+			api.RespondValidationError(w, map[string]string{"timeout_seconds": "must be between 1 and 1800"})
+			return
+		}
+	}
+	if h.agentWSHandler == nil || !h.agentWSHandler.IsWorkerConnected() {
+		api.RespondError(w, http.StatusServiceUnavailable, "Agent worker is not connected")
+		return
+	}
+	if h.budget != nil {
+		if err := h.budget.CheckBudget(r.Context()); err != nil {
+			api.RespondError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+	}
+
+	name := r.PathValue("name")
+	run, err := h.skillTester.PrepareSkillDryRun(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondError(w, http.StatusNotFound, "Skill not found")
+		return
+	}
+	if errors.Is(err, services.ErrSystemSkillDryRun) {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to prepare skill dry run", "skill", name, "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to prepare skill dry run")
+		return
+	}
+	defer func() {
+		if err := run.Cleanup(); err != nil {
+			slog.WarnContext(r.Context(), "failed to remove skill dry run workspace", "dir", run.Dir, "err", err)
+		}
+	}()
+
+	alert := skillTestAlert(req.Alert)
+	// The skill's prompt template applies, so template edits can be tried
+	// out here too.
+	data := investigationPromptData(alert, "synthetic", "", "", nil)
+	prompt := services.RenderDefaultInvestigationPrompt(data)
+	if h.promptTemplates != nil {
+		prompt = h.promptTemplates.RenderPrompt(data, "", []string{name})
+	}
+	task := prompt + "\n\n" + services.SkillDryRunInstructions(name)
+
+	var llmSettings *LLMSettingsForWorker
+	if dbSettings, err := database.GetLLMSettingsForSkills([]string{name}); err == nil && dbSettings != nil {
+		llmSettings = BuildLLMSettingsForWorker(dbSettings)
+	}
+
+	// Callbacks fire on the worker connection's goroutine; the timeout path
+	// reads the log while a run may still be streaming.
+	var mu sync.Mutex
+	var log strings.Builder
+	resp := api.SkillTestResponse{RunID: run.ID, Skill: name}
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	callback := IncidentCallback{
+		OnOutput: func(output string) {
+			mu.Lock()
+			log.WriteString(output)
+			mu.Unlock()
+		},
+		OnCompleted: func(_, output string, tokensUsed int, executionTimeMs int64) {
+			mu.Lock()
+			resp.Status = "completed"
+			resp.Output = output
+			resp.TokensUsed = tokensUsed
+			resp.ExecutionTimeMs = executionTimeMs
+			mu.Unlock()
+			closeOnce.Do(func() { close(done) })
+		},
+		OnError: func(errorMsg string) {
+			mu.Lock()
+			resp.Status = "failed"
+			resp.Error = errorMsg
+			mu.Unlock()
+			closeOnce.Do(func() { close(done) })
+		},
+		OnSuperseded: func() {
+			closeOnce.Do(func() { close(done) })
+		},
+	}
+
+	slog.InfoContext(r.Context(), "starting skill dry run", "skill", name, "run_id", run.ID, "alert_name", alert.AlertName)
+	runID, err := h.agentWSHandler.StartIncident(run.ID, executor.PrependGuidance(task), llmSettings,
+		[]string{name}, run.ToolAllowlist, callback)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to start skill dry run", "skill", name, "err", err)
+		api.RespondError(w, http.StatusServiceUnavailable, fmt.Sprintf("Failed to start agent run: %v", err))
+		return
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		h.agentWSHandler.ReleaseRun(run.ID, runID)
+	case <-timer.C:
+		h.stopSkillDryRun(run.ID)
+		mu.Lock()
+		resp.Status = "timed_out"
+		resp.Error = fmt.Sprintf("Skill dry run did not finish within %s", timeout)
+		mu.Unlock()
+	case <-r.Context().Done():
+		// The author gave up waiting; nobody is left to read the result.
+		h.stopSkillDryRun(run.ID)
+		return
+	}
+
+	mu.Lock()
+	resp.Log = utils.SanitizeLog(log.String())
+	resp.Output = utils.SanitizeLog(resp.Output)
+	mu.Unlock()
+	resp.ToolCalls = make([]api.SkillTestToolCall, 0)
+	for _, call := range utils.ParseToolCalls(resp.Log) {
+		resp.ToolCalls = append(resp.ToolCalls, api.SkillTestToolCall{Command: call.Command, Failed: call.Failed})
+	}
+	api.RespondJSON(w, http.StatusOK, resp)
+}
+
+// stopSkillDryRun cancels a dry run that is still going, dropping whatever
+// the worker sends for it afterwards.
+func (h *APIHandler) stopSkillDryRun(runID string) {
+	if _, err := h.agentWSHandler.AbortRun(runID); err != nil {
+		slog.Warn("failed to cancel skill dry run", "run_id", runID, "err", err)
+	}
+}
+
+// skillTestAlert turns the request's synthetic alert into a normalized one.
+func skillTestAlert(a api.SkillTestAlert) alerts.NormalizedAlert {
+	severity := database.AlertSeverityWarning
+	if a.Severity != "" {
+		severity = alerts.NormalizeSeverity(a.Severity, nil)
+	}
+	return alerts.NormalizedAlert{
+		AlertName:     strings.TrimSpace(a.AlertName),
+		Severity:      severity,
+		Status:        database.AlertStatusFiring,
+		Summary:       a.Summary,
+		Description:   a.Description,
+		TargetHost:    a.TargetHost,
+		TargetService: a.TargetService,
+		MetricName:    a.MetricName,
+		MetricValue:   a.MetricValue,
+		RunbookURL:    a.RunbookURL,
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+	"gorm.io/gorm"
+)
+
+type fakeSkillTester struct {
+	dir string
+}
+
+func (f *fakeSkillTester) PrepareSkillDryRun(name string) (*services.SkillDryRun, error) {
+	switch name {
+	case "missing":
+		return nil, gorm.ErrRecordNotFound
+	case "incident-manager":
+		return nil, services.ErrSystemSkillDryRun
+	}
+	dir := filepath.Join(f.dir, "skill-test-1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &services.SkillDryRun{ID: "skill-test-1", Dir: dir, Skill: name,
+		ToolAllowlist: []services.ToolAllowlistEntry{{InstanceID: 3, LogicalName: "prod-ssh", ToolType: "ssh"}}}, nil
+}
+
+func TestSkillTestAPI(t *testing.T) {
+	tester := &fakeSkillTester{dir: t.TempDir()}
+	body := `{"alert": {"alert_name": "DiskFull", "target_host": "web-01", "severity": "critical"}}`
+
+	h := NewAPIHandler(nil, nil, nil, nil, nil, NewAgentWSHandler(), nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodPost, "/api/skills/disk-usage/test", body); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+	h.SetSkillTester(tester)
+	if rec := serveJSON(mux, http.MethodPost, "/api/skills/disk-usage/test", body); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no worker status = %d, want 503", rec.Code)
+	}
+
+	agentWS, worker, cleanup := setupOneshotTest(t)
+	defer cleanup()
+	h = NewAPIHandler(nil, nil, nil, nil, nil, agentWS, nil, nil, nil, nil, nil)
+	h.SetSkillTester(tester)
+	mux = http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	for _, tc := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/api/skills/missing/test", body, http.StatusNotFound},
+		{"/api/skills/incident-manager/test", body, http.StatusBadRequest},
+		{"/api/skills/disk-usage/test", `{"alert": {}}`, http.StatusUnprocessableEntity},
+		{"/api/skills/disk-usage/test", `{"alert": {"alert_name": "x"}, "timeout_seconds": 7200}`, http.StatusUnprocessableEntity},
+		{"/api/skills/disk-usage/test", `not json`, http.StatusBadRequest},
+	} {
+		if rec := serveJSON(mux, http.MethodPost, tc.path, tc.body); rec.Code != tc.want {
+			t.Errorf("%s %s: status = %d, want %d", tc.path, tc.body, rec.Code, tc.want)
+		}
+	}
+
+	// Play the worker: answer the dry run with output, a tool call and a result.
+	go func() {
+		if err := worker.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+			return
+		}
+		var msg AgentMessage
+		for msg.Type != AgentMessageTypeNewIncident {
+			if err := worker.ReadJSON(&msg); err != nil {
+				return
+			}
+		}
+		if msg.IncidentID != "skill-test-1" || !strings.Contains(msg.Task, "DiskFull") || !strings.Contains(msg.Task, `"disk-usage" skill`) ||
+			len(msg.EnabledSkills) != 1 || msg.EnabledSkills[0] != "disk-usage" {
+			t.Errorf("new_incident = %+v", msg)
+		}
+		for _, frame := range []AgentMessage{
+			{Type: AgentMessageTypeAgentOutput, IncidentID: msg.IncidentID, RunID: msg.RunID, Output: "✅ Ran: df -h\n"},
+			{Type: AgentMessageTypeAgentCompleted, IncidentID: msg.IncidentID, RunID: msg.RunID, Output: "/var is full", TokensUsed: 42},
+		} {
+			if err := worker.WriteJSON(frame); err != nil {
+				return
+			}
+		}
+	}()
+
+	rec := serveJSON(mux, http.MethodPost, "/api/skills/disk-usage/test", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp api.SkillTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "completed" || resp.Output != "/var is full" || resp.TokensUsed != 42 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Command != "df -h" || resp.ToolCalls[0].Failed {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if _, err := os.Stat(filepath.Join(tester.dir, "skill-test-1")); !os.IsNotExist(err) {
+		t.Errorf("workspace not removed: %v", err)
+	}
+}
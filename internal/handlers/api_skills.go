@@ -1,229 +1,227 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
 )
 
-// handleSkills handles GET /api/skills and POST /api/skills
-func (h *APIHandler) handleSkills(w http.ResponseWriter, r *http.Request) {
+// listSkills handles GET /api/skills
+func (h *APIHandler) listSkills(w http.ResponseWriter, r *http.Request) {
 	db := database.GetDB()
 
-	switch r.Method {
-	case http.MethodGet:
-		var skills []database.Skill
-		if err := db.Preload("Tools").Preload("Tools.ToolType").Find(&skills).Error; err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get skills")
-			return
-		}
+	var skills []database.Skill
+	if err := db.Preload("Tools").Preload("Tools.ToolType").Find(&skills).Error; err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get skills")
+		return
+	}
 
-		var response []api.SkillResponse
-		for _, skill := range skills {
-			prompt, _ := h.skillService.GetSkillPrompt(skill.Name)
-			response = append(response, api.SkillResponse{
-				Skill:  skill,
-				Prompt: prompt,
-			})
-		}
+	var response []api.SkillResponse
+	for _, skill := range skills {
+		prompt, _ := h.skillService.GetSkillPrompt(skill.Name)
+		response = append(response, api.SkillResponse{
+			Skill:  skill,
+			Prompt: prompt,
+		})
+	}
 
-		api.RespondJSON(w, http.StatusOK, response)
+	api.RespondJSON(w, http.StatusOK, response)
+}
 
-	case http.MethodPost:
-		var req api.CreateSkillRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+// createSkill handles POST /api/skills
+func (h *APIHandler) createSkill(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateSkillRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-		skill, err := h.skillService.CreateSkill(req.Name, req.Description, req.Category, req.Prompt)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to create skill")
-			return
-		}
+	skill, err := h.skillService.CreateSkill(req.Name, req.Description, req.Category, req.Prompt)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to create skill")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, skill)
+}
+
+// getSkill handles GET /api/skills/{name}
+func (h *APIHandler) getSkill(w http.ResponseWriter, r *http.Request) {
+	db := database.GetDB()
 
-		api.RespondJSON(w, http.StatusCreated, skill)
+	skillName := r.PathValue("name")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	var skill database.Skill
+	if err := db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill).Error; err != nil {
+		api.RespondError(w, http.StatusNotFound, "Skill not found")
+		return
 	}
+
+	prompt, _ := h.skillService.GetSkillPrompt(skill.Name)
+	api.RespondJSON(w, http.StatusOK, api.SkillResponse{Skill: skill, Prompt: prompt})
 }
 
-// handleSkillByName handles GET /api/skills/:name, PUT /api/skills/:name, DELETE /api/skills/:name
-// Also handles /api/skills/:name/prompt, /api/skills/:name/tools, /api/skills/:name/scripts
-func (h *APIHandler) handleSkillByName(w http.ResponseWriter, r *http.Request) {
+// updateSkill handles PUT /api/skills/{name}
+func (h *APIHandler) updateSkill(w http.ResponseWriter, r *http.Request) {
 	db := database.GetDB()
 
-	path := r.URL.Path[len("/api/skills/"):]
-
-	if len(path) > 0 {
-		parts := splitPath(path)
-		if len(parts) >= 2 {
-			skillName := parts[0]
-			subPath := parts[1]
-
-			switch subPath {
-			case "prompt":
-				h.handleSkillPrompt(w, r, skillName)
-				return
-			case "tools":
-				h.handleSkillTools(w, r, skillName)
-				return
-			case "scripts":
-				if len(parts) == 2 {
-					h.handleSkillScripts(w, r, skillName)
-				} else if len(parts) == 3 {
-					h.handleSkillScriptByFilename(w, r, skillName, parts[2])
-				}
-				return
-			}
-		}
+	skillName := r.PathValue("name")
+
+	var updates map[string]interface{}
+	if err := api.DecodeJSON(r, &updates); err != nil {
+		api.RespondDecodeError(w, err)
+		return
 	}
 
-	skillName := path
+	var skill database.Skill
+	if err := db.Where("name = ?", skillName).First(&skill).Error; err != nil {
+		api.RespondError(w, http.StatusNotFound, "Skill not found")
+		return
+	}
 
-	switch r.Method {
-	case http.MethodGet:
-		var skill database.Skill
-		if err := db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill).Error; err != nil {
-			api.RespondError(w, http.StatusNotFound, "Skill not found")
-			return
+	allowedFields := map[string]bool{
+		"description": true,
+		"category":    true,
+		"enabled":     true,
+	}
+	filteredUpdates := make(map[string]interface{})
+	for key, value := range updates {
+		if allowedFields[key] {
+			filteredUpdates[key] = value
 		}
-
-		prompt, _ := h.skillService.GetSkillPrompt(skill.Name)
-		api.RespondJSON(w, http.StatusOK, api.SkillResponse{Skill: skill, Prompt: prompt})
-
-	case http.MethodPut:
-		var updates map[string]interface{}
-		if err := api.DecodeJSON(r, &updates); err != nil {
+	}
+	if raw, ok := updates["llm_settings_id"]; ok {
+		llmSettingsID, err := skillLLMSettingsID(raw)
+		if err != nil {
 			api.RespondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if llmSettingsID == nil {
+			filteredUpdates["llm_settings_id"] = nil
+		} else {
+			filteredUpdates["llm_settings_id"] = *llmSettingsID
+		}
+	}
 
-		var skill database.Skill
-		if err := db.Where("name = ?", skillName).First(&skill).Error; err != nil {
-			api.RespondError(w, http.StatusNotFound, "Skill not found")
+	if len(filteredUpdates) > 0 {
+		if err := db.Model(&database.Skill{}).Where("name = ?", skillName).Updates(filteredUpdates).Error; err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update skill")
 			return
 		}
+	}
 
-		allowedFields := map[string]bool{
-			"description": true,
-			"category":    true,
-			"enabled":     true,
-		}
-		filteredUpdates := make(map[string]interface{})
-		for key, value := range updates {
-			if allowedFields[key] {
-				filteredUpdates[key] = value
-			}
+	if prompt, ok := updates["prompt"].(string); ok {
+		if err := h.skillService.UpdateSkillPrompt(skillName, prompt); err != nil {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update skill prompt")
+			return
 		}
+	}
 
-		if len(filteredUpdates) > 0 {
-			if err := db.Model(&database.Skill{}).Where("name = ?", skillName).Updates(filteredUpdates).Error; err != nil {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update skill")
-				return
-			}
-		}
+	db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill)
+	promptText, _ := h.skillService.GetSkillPrompt(skill.Name)
+	api.RespondJSON(w, http.StatusOK, api.SkillResponse{Skill: skill, Prompt: promptText})
+}
 
-		if prompt, ok := updates["prompt"].(string); ok {
-			if err := h.skillService.UpdateSkillPrompt(skillName, prompt); err != nil {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update skill prompt")
-				return
-			}
-		}
+// deleteSkill handles DELETE /api/skills/{name}
+func (h *APIHandler) deleteSkill(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 
-		db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill)
-		promptText, _ := h.skillService.GetSkillPrompt(skill.Name)
-		api.RespondJSON(w, http.StatusOK, api.SkillResponse{Skill: skill, Prompt: promptText})
-
-	case http.MethodDelete:
-		if err := h.skillService.DeleteSkill(skillName); err != nil {
-			if containsString(err.Error(), "cannot delete system skill") {
-				api.RespondError(w, http.StatusForbidden, err.Error())
-				return
-			}
-			api.RespondError(w, http.StatusInternalServerError, "Failed to delete skill")
+	if err := h.skillService.DeleteSkill(skillName); err != nil {
+		if containsString(err.Error(), "cannot delete system skill") {
+			api.RespondError(w, http.StatusForbidden, err.Error())
 			return
 		}
-		api.RespondNoContent(w)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to delete skill")
+		return
+	}
+	api.RespondNoContent(w)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// skillLLMSettingsID validates the llm_settings_id of a skill update: null
+// clears the pin, otherwise it must name an existing LLM configuration.
+func skillLLMSettingsID(raw interface{}) (*uint, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	f, ok := raw.(float64)
+	if !ok || f <= 0 || f != float64(uint(f)) {
+		return nil, fmt.Errorf("llm_settings_id must be a configuration ID or null")
 	}
+	id := uint(f)
+	if _, err := database.GetLLMSettingsByID(id); err != nil {
+		return nil, fmt.Errorf("LLM configuration %d not found", id)
+	}
+	return &id, nil
 }
 
-// handleSkillPrompt handles GET/PUT /api/skills/:name/prompt
-func (h *APIHandler) handleSkillPrompt(w http.ResponseWriter, r *http.Request, skillName string) {
-	switch r.Method {
-	case http.MethodGet:
-		prompt, err := h.skillService.GetSkillPrompt(skillName)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get prompt")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, map[string]string{"prompt": prompt})
+// getSkillPrompt handles GET /api/skills/{name}/prompt
+func (h *APIHandler) getSkillPrompt(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 
-	case http.MethodPut:
-		var req api.UpdateSkillPromptRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	prompt, err := h.skillService.GetSkillPrompt(skillName)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get prompt")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]string{"prompt": prompt})
+}
 
-		if err := h.skillService.UpdateSkillPrompt(skillName, req.Prompt); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to update prompt")
-			return
-		}
+// updateSkillPrompt handles PUT /api/skills/{name}/prompt
+func (h *APIHandler) updateSkillPrompt(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 
-		api.RespondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	var req api.UpdateSkillPromptRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.skillService.UpdateSkillPrompt(skillName, req.Prompt); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to update prompt")
+		return
 	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
-// handleSkillTools handles GET/PUT /api/skills/:name/tools
-func (h *APIHandler) handleSkillTools(w http.ResponseWriter, r *http.Request, skillName string) {
+// getSkillTools handles GET /api/skills/{name}/tools
+func (h *APIHandler) getSkillTools(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 	db := database.GetDB()
 
-	switch r.Method {
-	case http.MethodGet:
-		var skill database.Skill
-		if err := db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill).Error; err != nil {
-			api.RespondError(w, http.StatusNotFound, "Skill not found")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, skill.Tools)
-
-	case http.MethodPut:
-		var req api.UpdateSkillToolsRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	var skill database.Skill
+	if err := db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill).Error; err != nil {
+		api.RespondError(w, http.StatusNotFound, "Skill not found")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, skill.Tools)
+}
 
-		if err := h.skillService.AssignTools(skillName, req.ToolInstanceIDs); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to assign tools")
-			return
-		}
+// updateSkillTools handles PUT /api/skills/{name}/tools
+func (h *APIHandler) updateSkillTools(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
+	db := database.GetDB()
 
-		var skill database.Skill
-		db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill)
-		api.RespondJSON(w, http.StatusOK, skill)
+	var req api.UpdateSkillToolsRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.skillService.AssignTools(skillName, req.ToolInstanceIDs); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to assign tools")
+		return
 	}
+
+	var skill database.Skill
+	db.Preload("Tools").Preload("Tools.ToolType").Where("name = ?", skillName).First(&skill)
+	api.RespondJSON(w, http.StatusOK, skill)
 }
 
 // handleSkillsSync handles POST /api/skills/sync
 func (h *APIHandler) handleSkillsSync(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	if err := h.skillService.SyncSkillsFromFilesystem(); err != nil {
 		api.RespondError(w, http.StatusInternalServerError, "Failed to sync skills")
 		return
@@ -232,92 +230,96 @@ func (h *APIHandler) handleSkillsSync(w http.ResponseWriter, r *http.Request) {
 	api.RespondJSON(w, http.StatusOK, map[string]string{"status": "ok", "message": "Skills synced from filesystem"})
 }
 
-// handleSkillScripts handles GET/DELETE /api/skills/:name/scripts
-func (h *APIHandler) handleSkillScripts(w http.ResponseWriter, r *http.Request, skillName string) {
-	switch r.Method {
-	case http.MethodGet:
-		scripts, err := h.skillService.ListSkillScripts(skillName)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to list scripts")
-			return
-		}
+// listSkillScripts handles GET /api/skills/{name}/scripts
+func (h *APIHandler) listSkillScripts(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 
-		api.RespondJSON(w, http.StatusOK, map[string]interface{}{
-			"skill_name":  skillName,
-			"scripts_dir": h.skillService.GetSkillScriptsDir(skillName),
-			"scripts":     scripts,
-		})
+	scripts, err := h.skillService.ListSkillScripts(skillName)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list scripts")
+		return
+	}
 
-	case http.MethodDelete:
-		if err := h.skillService.ClearSkillScripts(skillName); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to clear scripts")
-			return
-		}
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"skill_name":  skillName,
+		"scripts_dir": h.skillService.GetSkillScriptsDir(skillName),
+		"scripts":     scripts,
+	})
+}
 
-		api.RespondJSON(w, http.StatusOK, map[string]interface{}{
-			"message":    "Scripts cleared successfully",
-			"skill_name": skillName,
-		})
+// clearSkillScripts handles DELETE /api/skills/{name}/scripts
+func (h *APIHandler) clearSkillScripts(w http.ResponseWriter, r *http.Request) {
+	skillName := r.PathValue("name")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.skillService.ClearSkillScripts(skillName); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to clear scripts")
+		return
 	}
-}
 
-// handleSkillScriptByFilename handles GET/PUT/DELETE /api/skills/:name/scripts/:filename
-func (h *APIHandler) handleSkillScriptByFilename(w http.ResponseWriter, r *http.Request, skillName, filename string) {
-	switch r.Method {
-	case http.MethodGet:
-		scriptInfo, err := h.skillService.GetSkillScript(skillName, filename)
-		if err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "invalid filename") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to get script")
-			}
-			return
-		}
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "Scripts cleared successfully",
+		"skill_name": skillName,
+	})
+}
 
-		api.RespondJSON(w, http.StatusOK, scriptInfo)
+// getSkillScript handles GET /api/skills/{name}/scripts/{filename}
+func (h *APIHandler) getSkillScript(w http.ResponseWriter, r *http.Request) {
+	skillName, filename := r.PathValue("name"), r.PathValue("filename")
 
-	case http.MethodPut:
-		var req api.UpdateScriptRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
+	scriptInfo, err := h.skillService.GetSkillScript(skillName, filename)
+	if err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "invalid filename") {
 			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to get script")
 		}
+		return
+	}
 
-		if err := h.skillService.UpdateSkillScript(skillName, filename, req.Content); err != nil {
-			if containsString(err.Error(), "invalid filename") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update script")
-			}
-			return
-		}
+	api.RespondJSON(w, http.StatusOK, scriptInfo)
+}
 
-		api.RespondJSON(w, http.StatusOK, map[string]interface{}{
-			"success":  true,
-			"filename": filename,
-		})
+// updateSkillScript handles PUT /api/skills/{name}/scripts/{filename}
+func (h *APIHandler) updateSkillScript(w http.ResponseWriter, r *http.Request) {
+	skillName, filename := r.PathValue("name"), r.PathValue("filename")
 
-	case http.MethodDelete:
-		if err := h.skillService.DeleteSkillScript(skillName, filename); err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "invalid filename") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to delete script")
-			}
-			return
+	var req api.UpdateScriptRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	if err := h.skillService.UpdateSkillScript(skillName, filename, req.Content); err != nil {
+		if containsString(err.Error(), "invalid filename") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update script")
 		}
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"filename": filename,
+	})
+}
 
-		api.RespondNoContent(w)
+// deleteSkillScript handles DELETE /api/skills/{name}/scripts/{filename}
+func (h *APIHandler) deleteSkillScript(w http.ResponseWriter, r *http.Request) {
+	skillName, filename := r.PathValue("name"), r.PathValue("filename")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	if err := h.skillService.DeleteSkillScript(skillName, filename); err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "invalid filename") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to delete script")
+		}
+		return
 	}
+
+	api.RespondNoContent(w)
 }
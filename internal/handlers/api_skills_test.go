@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestSkillLLMSettingsID(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.LLMSettings{})
+	cfg := database.LLMSettings{Name: "Claude", Provider: database.LLMProviderAnthropic, APIKey: "sk-test", Enabled: true}
+	if err := db.Create(&cfg).Error; err != nil {
+		t.Fatalf("create config: %v", err)
+	}
+
+	got, err := skillLLMSettingsID(float64(cfg.ID))
+	if err != nil {
+		t.Fatalf("existing config: %v", err)
+	}
+	if got == nil || *got != cfg.ID {
+		t.Errorf("got %v, want %d", got, cfg.ID)
+	}
+
+	if got, err := skillLLMSettingsID(nil); err != nil || got != nil {
+		t.Errorf("null: got %v, %v; want nil, nil", got, err)
+	}
+
+	for _, raw := range []interface{}{"1", 1.5, float64(0), float64(-3), float64(cfg.ID + 100)} {
+		if _, err := skillLLMSettingsID(raw); err == nil {
+			t.Errorf("expected error for %v", raw)
+		}
+	}
+}
@@ -4,72 +4,6 @@ import (
 	"testing"
 )
 
-func TestSplitPath(t *testing.T) {
-	tests := []struct {
-		name     string
-		path     string
-		expected []string
-	}{
-		{
-			name:     "empty path",
-			path:     "",
-			expected: []string{},
-		},
-		{
-			name:     "single segment",
-			path:     "skills",
-			expected: []string{"skills"},
-		},
-		{
-			name:     "two segments",
-			path:     "skills/test-skill",
-			expected: []string{"skills", "test-skill"},
-		},
-		{
-			name:     "three segments",
-			path:     "skills/test-skill/prompt",
-			expected: []string{"skills", "test-skill", "prompt"},
-		},
-		{
-			name:     "trailing slash",
-			path:     "skills/test-skill/",
-			expected: []string{"skills", "test-skill"},
-		},
-		{
-			name:     "leading slash",
-			path:     "/skills/test-skill",
-			expected: []string{"skills", "test-skill"},
-		},
-		{
-			name:     "multiple slashes",
-			path:     "skills//test-skill///prompt",
-			expected: []string{"skills", "test-skill", "prompt"},
-		},
-		{
-			name:     "only slashes",
-			path:     "///",
-			expected: []string{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := splitPath(tt.path)
-			if len(result) != len(tt.expected) {
-				t.Errorf("splitPath(%q) = %v (len %d), want %v (len %d)",
-					tt.path, result, len(result), tt.expected, len(tt.expected))
-				return
-			}
-			for i, v := range result {
-				if v != tt.expected[i] {
-					t.Errorf("splitPath(%q)[%d] = %q, want %q",
-						tt.path, i, v, tt.expected[i])
-				}
-			}
-		})
-	}
-}
-
 func TestMaskToken(t *testing.T) {
 	tests := []struct {
 		name     string
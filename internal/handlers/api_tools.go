@@ -2,20 +2,20 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
-	"strings"
 
 	"github.com/akmatori/akmatori/internal/api"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
+// SetToolSettingsValidator wires the check of tool instance settings against
+// their tool type's schema. Optional — when unset settings are stored as sent.
+func (h *APIHandler) SetToolSettingsValidator(v services.ToolSettingsValidator) {
+	h.toolSettings = v
+}
+
 // handleToolTypes handles GET /api/tool-types
 func (h *APIHandler) handleToolTypes(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	toolTypes, err := h.toolService.ListToolTypes()
 	if err != nil {
 		api.RespondError(w, http.StatusInternalServerError, "Failed to get tool types")
@@ -25,112 +25,144 @@ func (h *APIHandler) handleToolTypes(w http.ResponseWriter, r *http.Request) {
 	api.RespondJSON(w, http.StatusOK, toolTypes)
 }
 
-// handleTools handles GET /api/tools and POST /api/tools
-func (h *APIHandler) handleTools(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		instances, err := h.toolService.ListToolInstances()
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get tools")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, instances)
+// listTools handles GET /api/tools
+func (h *APIHandler) listTools(w http.ResponseWriter, r *http.Request) {
+	instances, err := h.toolService.ListToolInstances()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get tools")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, instances)
+}
 
-	case http.MethodPost:
-		var req api.CreateToolInstanceRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+// createTool handles POST /api/tools
+func (h *APIHandler) createTool(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateToolInstanceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	if h.toolSettings != nil {
+		toolTypes, err := h.toolService.ListToolTypes()
+		if err != nil {
+			api.RespondServiceError(w, r, http.StatusInternalServerError, err, "Failed to create tool instance")
 			return
 		}
-
-		instance, err := h.toolService.CreateToolInstance(req.ToolTypeID, req.Name, req.LogicalName, req.Settings)
-		if err != nil {
-			if containsString(err.Error(), "validation failed") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else if containsString(err.Error(), "already exists") || containsString(err.Error(), "UNIQUE constraint") || containsString(err.Error(), "duplicate key") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to create tool instance")
+		for _, tt := range toolTypes {
+			if tt.ID == req.ToolTypeID && !h.validToolSettings(w, r, tt.Name, req.Settings) {
+				return
 			}
-			return
 		}
+	}
 
-		api.RespondJSON(w, http.StatusCreated, instance)
-
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	instance, err := h.toolService.CreateToolInstance(req.ToolTypeID, req.Name, req.LogicalName, req.Settings)
+	if err != nil {
+		if containsString(err.Error(), "validation failed") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else if containsString(err.Error(), "already exists") || containsString(err.Error(), "UNIQUE constraint") || containsString(err.Error(), "duplicate key") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to create tool instance")
+		}
+		return
 	}
+
+	api.RespondJSON(w, http.StatusCreated, instance)
 }
 
-// handleToolByID handles GET /api/tools/:id, PUT /api/tools/:id, DELETE /api/tools/:id
-// Also handles /api/tools/:id/ssh-keys routes
-func (h *APIHandler) handleToolByID(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path[len("/api/tools/"):]
-	parts := strings.Split(path, "/")
+// getTool handles GET /api/tools/{id}
+func (h *APIHandler) getTool(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
 
-	id, err := strconv.ParseUint(parts[0], 10, 32)
+	instance, err := h.toolService.GetToolInstance(id)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, "Invalid tool ID")
+		api.RespondError(w, http.StatusNotFound, "Tool not found")
 		return
 	}
 
-	if len(parts) >= 2 && parts[1] == "ssh-keys" {
-		if len(parts) == 2 {
-			h.handleSSHKeys(w, r, uint(id))
-		} else if len(parts) == 3 {
-			h.handleSSHKeyByID(w, r, uint(id), parts[2])
-		}
+	h.maskSSHKeys(instance)
+	api.RespondJSON(w, http.StatusOK, instance)
+}
+
+// updateTool handles PUT /api/tools/{id}
+func (h *APIHandler) updateTool(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "tool")
+	if !ok {
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		instance, err := h.toolService.GetToolInstance(uint(id))
+	var req api.UpdateToolInstanceRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+	if h.toolSettings != nil && req.Settings != nil {
+		existing, err := h.toolService.GetToolInstance(id)
 		if err != nil {
 			api.RespondError(w, http.StatusNotFound, "Tool not found")
 			return
 		}
-
-		h.maskSSHKeys(instance)
-		api.RespondJSON(w, http.StatusOK, instance)
-
-	case http.MethodPut:
-		var req api.UpdateToolInstanceRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+		// SSH keys are kept from the stored settings, so check them as stored.
+		settings := make(database.JSONB, len(req.Settings)+1)
+		for k, v := range req.Settings {
+			settings[k] = v
+		}
+		if keys, ok := existing.Settings["ssh_keys"]; ok {
+			settings["ssh_keys"] = keys
+		} else {
+			delete(settings, "ssh_keys")
+		}
+		if !h.validToolSettings(w, r, existing.ToolType.Name, settings) {
 			return
 		}
+	}
 
-		if err := h.toolService.UpdateToolInstance(uint(id), req.Name, req.LogicalName, req.Settings, req.Enabled); err != nil {
-			if containsString(err.Error(), "validation failed") {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
-			} else if containsString(err.Error(), "not found") || containsString(err.Error(), "record not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "already exists") || containsString(err.Error(), "UNIQUE constraint") || containsString(err.Error(), "duplicate key") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update tool")
-			}
-			return
+	if err := h.toolService.UpdateToolInstance(id, req.Name, req.LogicalName, req.Settings, req.Enabled); err != nil {
+		if containsString(err.Error(), "validation failed") {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		} else if containsString(err.Error(), "not found") || containsString(err.Error(), "record not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "already exists") || containsString(err.Error(), "UNIQUE constraint") || containsString(err.Error(), "duplicate key") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update tool")
 		}
+		return
+	}
 
-		instance, _ := h.toolService.GetToolInstance(uint(id))
-		h.maskSSHKeys(instance)
-		api.RespondJSON(w, http.StatusOK, instance)
+	instance, _ := h.toolService.GetToolInstance(id)
+	h.maskSSHKeys(instance)
+	api.RespondJSON(w, http.StatusOK, instance)
+}
 
-	case http.MethodDelete:
-		if err := h.toolService.DeleteToolInstance(uint(id)); err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to delete tool")
-			return
-		}
-		api.RespondNoContent(w)
+// deleteTool handles DELETE /api/tools/{id}
+func (h *APIHandler) deleteTool(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
+
+	if err := h.toolService.DeleteToolInstance(id); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to delete tool")
+		return
+	}
+	api.RespondNoContent(w)
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// validToolSettings answers 422 with the failing settings and returns false
+// when settings do not match the schema of toolType.
+func (h *APIHandler) validToolSettings(w http.ResponseWriter, r *http.Request, toolType string, settings database.JSONB) bool {
+	if errs := h.toolSettings.ValidateToolSettings(r.Context(), toolType, settings); errs != nil {
+		api.RespondValidationError(w, errs)
+		return false
 	}
+	return true
 }
 
-// maskSSHKeys removes private_key from SSH keys in the response
+// maskSSHKeys removes private_key and passphrase from SSH keys in the response
 func (h *APIHandler) maskSSHKeys(instance *database.ToolInstance) {
 	if instance == nil || instance.Settings == nil {
 		return
@@ -140,93 +172,159 @@ func (h *APIHandler) maskSSHKeys(instance *database.ToolInstance) {
 		for _, keyData := range keys {
 			if keyMap, ok := keyData.(map[string]interface{}); ok {
 				delete(keyMap, "private_key")
+				delete(keyMap, "passphrase")
 			}
 		}
 	}
 }
 
-// handleSSHKeys handles GET/POST /api/tools/:id/ssh-keys
-func (h *APIHandler) handleSSHKeys(w http.ResponseWriter, r *http.Request, toolID uint) {
-	switch r.Method {
-	case http.MethodGet:
-		keys, err := h.toolService.GetSSHKeys(toolID)
-		if err != nil {
-			api.RespondError(w, http.StatusInternalServerError, "Failed to get SSH keys")
-			return
-		}
-		api.RespondJSON(w, http.StatusOK, keys)
+// listSSHKeys handles GET /api/tools/{id}/ssh-keys
+func (h *APIHandler) listSSHKeys(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
 
-	case http.MethodPost:
-		var req api.CreateSSHKeyRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
+	keys, err := h.toolService.GetSSHKeys(toolID)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to get SSH keys")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, keys)
+}
 
-		if req.Name == "" {
-			api.RespondError(w, http.StatusBadRequest, "name is required")
-			return
-		}
-		if req.PrivateKey == "" {
-			api.RespondError(w, http.StatusBadRequest, "private_key is required")
-			return
-		}
+// createSSHKey handles POST /api/tools/{id}/ssh-keys
+func (h *APIHandler) createSSHKey(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
 
-		key, err := h.toolService.AddSSHKey(toolID, req.Name, req.PrivateKey, req.IsDefault)
-		if err != nil {
-			if containsString(err.Error(), "already exists") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to add SSH key")
-			}
-			return
+	var req api.CreateSSHKeyRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
+	}
+
+	key, err := h.toolService.AddSSHKey(toolID, req.Name, req.PrivateKey, req.Passphrase, req.IsDefault)
+	if err != nil {
+		if containsString(err.Error(), "already exists") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to add SSH key")
 		}
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, key)
+}
 
-		api.RespondJSON(w, http.StatusCreated, key)
+// updateSSHKey handles PUT /api/tools/{id}/ssh-keys/{keyID}
+func (h *APIHandler) updateSSHKey(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
+	keyID := r.PathValue("keyID")
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	var req api.UpdateSSHKeyRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondDecodeError(w, err)
+		return
 	}
+
+	key, err := h.toolService.UpdateSSHKey(toolID, keyID, req.Name, req.IsDefault)
+	if err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "already exists") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to update SSH key")
+		}
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, key)
 }
 
-// handleSSHKeyByID handles PUT/DELETE /api/tools/:id/ssh-keys/:keyID
-func (h *APIHandler) handleSSHKeyByID(w http.ResponseWriter, r *http.Request, toolID uint, keyID string) {
-	switch r.Method {
-	case http.MethodPut:
-		var req api.UpdateSSHKeyRequest
-		if err := api.DecodeJSON(r, &req); err != nil {
-			api.RespondError(w, http.StatusBadRequest, err.Error())
-			return
+// deleteSSHKey handles DELETE /api/tools/{id}/ssh-keys/{keyID}
+func (h *APIHandler) deleteSSHKey(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
+	keyID := r.PathValue("keyID")
+
+	if err := h.toolService.DeleteSSHKey(toolID, keyID); err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else if containsString(err.Error(), "cannot delete") {
+			api.RespondError(w, http.StatusConflict, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to delete SSH key")
 		}
+		return
+	}
+	api.RespondNoContent(w)
+}
 
-		key, err := h.toolService.UpdateSSHKey(toolID, keyID, req.Name, req.IsDefault)
-		if err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "already exists") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to update SSH key")
-			}
-			return
+// listSSHKnownHosts handles GET /api/tools/{id}/known-hosts
+func (h *APIHandler) listSSHKnownHosts(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
+
+	hosts, err := h.toolService.ListSSHKnownHosts(toolID)
+	if err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, "Tool not found")
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to list pinned host keys")
 		}
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, hosts)
+}
 
-		api.RespondJSON(w, http.StatusOK, key)
+// clearSSHKnownHosts handles DELETE /api/tools/{id}/known-hosts, clearing
+// every pinned key, or only those for ?host= when given.
+func (h *APIHandler) clearSSHKnownHosts(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
 
-	case http.MethodDelete:
-		if err := h.toolService.DeleteSSHKey(toolID, keyID); err != nil {
-			if containsString(err.Error(), "not found") {
-				api.RespondError(w, http.StatusNotFound, err.Error())
-			} else if containsString(err.Error(), "cannot delete") {
-				api.RespondError(w, http.StatusConflict, err.Error())
-			} else {
-				api.RespondError(w, http.StatusInternalServerError, "Failed to delete SSH key")
-			}
-			return
+	deleted, err := h.toolService.ClearSSHKnownHosts(toolID, r.URL.Query().Get("host"))
+	if err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, "Tool not found")
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to clear pinned host keys")
 		}
-		api.RespondNoContent(w)
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, map[string]int64{"deleted": deleted})
+}
 
-	default:
-		api.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// deleteSSHKnownHost handles DELETE /api/tools/{id}/known-hosts/{hostID}
+func (h *APIHandler) deleteSSHKnownHost(w http.ResponseWriter, r *http.Request) {
+	toolID, ok := pathID(w, r, "id", "tool")
+	if !ok {
+		return
+	}
+	knownHostID, ok := pathID(w, r, "hostID", "known host")
+	if !ok {
+		return
+	}
+	if err := h.toolService.DeleteSSHKnownHost(toolID, knownHostID); err != nil {
+		if containsString(err.Error(), "not found") {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		} else {
+			api.RespondError(w, http.StatusInternalServerError, "Failed to delete pinned host key")
+		}
+		return
 	}
+	api.RespondNoContent(w)
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+// stubToolSettingsValidator requires zabbix_url for zabbix tools and records
+// the settings it was asked to check.
+type stubToolSettingsValidator struct {
+	checked database.JSONB
+}
+
+func (v *stubToolSettingsValidator) ValidateToolSettings(ctx context.Context, toolType string, settings database.JSONB) map[string]string {
+	v.checked = settings
+	if toolType == "zabbix" && settings["zabbix_url"] == nil {
+		return map[string]string{"zabbix_url": "is required"}
+	}
+	return nil
+}
+
+func TestToolSettingsValidation(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.ToolType{}, &database.ToolInstance{})
+	zabbix := database.ToolType{Name: "zabbix"}
+	if err := db.Create(&zabbix).Error; err != nil {
+		t.Fatalf("seed tool type: %v", err)
+	}
+	h := NewAPIHandler(nil, services.NewToolService(), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	validator := &stubToolSettingsValidator{}
+	h.SetToolSettingsValidator(validator)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	rec := serveJSON(mux, http.MethodPost, "/api/tools", `{"tool_type_id":1,"name":"zbx","settings":{}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("create without zabbix_url: status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	var errResp api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil || errResp.Details["zabbix_url"] != "is required" {
+		t.Errorf("details = %v (%v), want zabbix_url required", errResp.Details, err)
+	}
+
+	rec = serveJSON(mux, http.MethodPost, "/api/tools", `{"tool_type_id":1,"name":"zbx","settings":{"zabbix_url":"https://zbx"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := db.Model(&database.ToolInstance{}).Where("id = 1").
+		Update("settings", database.JSONB{"zabbix_url": "https://zbx", "ssh_keys": []interface{}{"stored"}}).Error; err != nil {
+		t.Fatalf("seed ssh keys: %v", err)
+	}
+
+	// Updates are checked with the stored SSH keys, which the update keeps.
+	rec = serveJSON(mux, http.MethodPut, "/api/tools/1", `{"name":"zbx","enabled":true,"settings":{"zabbix_url":"https://zbx2","ssh_keys":["sent"]}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if keys, _ := validator.checked["ssh_keys"].([]interface{}); len(keys) != 1 || keys[0] != "stored" {
+		t.Errorf("validated ssh_keys = %v, want the stored keys", validator.checked["ssh_keys"])
+	}
+
+	rec = serveJSON(mux, http.MethodPut, "/api/tools/1", `{"name":"zbx","enabled":true,"settings":{"zabbix_timeout":30}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("update without zabbix_url: status = %d, want 422", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodPut, "/api/tools/99", `{"name":"x","settings":{}}`); rec.Code != http.StatusNotFound {
+		t.Errorf("update unknown tool: status = %d, want 404", rec.Code)
+	}
+}
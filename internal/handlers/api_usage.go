@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetUsageReader wires token usage accounting behind GET /api/usage.
+// Optional — when unset the endpoint returns 503.
+func (h *APIHandler) SetUsageReader(r services.UsageReader) {
+	h.usage = r
+}
+
+// handleUsage handles GET /api/usage: token counts and estimated cost in
+// daily or weekly buckets, plus per-skill and per-model breakdowns.
+// Query: period (day|week, default day), since and until (RFC3339 or unix
+// seconds).
+func (h *APIHandler) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if h.usage == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Usage accounting is not configured")
+		return
+	}
+	q := r.URL.Query()
+	filter := services.UsageFilter{Period: q.Get("period")}
+	for _, param := range []string{"since", "until"} {
+		v := q.Get(param)
+		if v == "" {
+			continue
+		}
+		t := parseTimeQueryParam(v)
+		if t == nil {
+			api.RespondValidationError(w, map[string]string{param: "must be RFC3339 or unix seconds"})
+			return
+		}
+		if param == "since" {
+			filter.Since = *t
+		} else {
+			filter.Until = *t
+		}
+	}
+
+	summary, err := h.usage.Summary(r.Context(), filter)
+	if errors.Is(err, services.ErrInvalidUsageFilter) {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "usage: failed to summarize token usage", "err", err)
+		api.RespondError(w, http.StatusInternalServerError, "Failed to summarize token usage")
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestUsageAPI(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.TokenUsage{})
+	h := NewAPIHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	if rec := serveJSON(mux, http.MethodGet, "/api/usage", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unwired status = %d, want 503", rec.Code)
+	}
+
+	h.SetUsageReader(services.NewUsageService(db))
+	db.Create(&database.TokenUsage{IncidentUUID: "inc-1", Model: "gpt-5.2", Skill: "disk", TokensUsed: 1200, RecordedAt: time.Now().Add(-time.Hour)})
+
+	rec := serveJSON(mux, http.MethodGet, "/api/usage?period=week", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var summary services.UsageSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Period != "week" || summary.Totals.TokensUsed != 1200 || len(summary.BySkill) != 1 || summary.BySkill[0].Skill != "disk" {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	if rec := serveJSON(mux, http.MethodGet, "/api/usage?since=yesterday", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bad since: status = %d, want 422", rec.Code)
+	}
+	if rec := serveJSON(mux, http.MethodGet, "/api/usage?period=month", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad period: status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+)
+
+// handleListWorkers handles GET /api/workers: the connected agent workers
+// with their advertised capacity and current runs.
+func (h *APIHandler) handleListWorkers(w http.ResponseWriter, r *http.Request) {
+	if h.agentWSHandler == nil {
+		api.RespondJSON(w, http.StatusOK, []WorkerStatus{})
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, h.agentWSHandler.Workers())
+}
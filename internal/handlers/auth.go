@@ -2,16 +2,23 @@ package handlers
 
 import (
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/middleware"
+	"github.com/akmatori/akmatori/internal/services"
 	"github.com/akmatori/akmatori/internal/setup"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	jwtAuth *middleware.JWTAuthMiddleware
+	jwtAuth       *middleware.JWTAuthMiddleware
+	loginThrottle *middleware.LoginThrottle
+	auditRecorder services.AuditRecorder
 }
 
 // NewAuthHandler creates a new authentication handler
@@ -21,6 +28,18 @@ func NewAuthHandler(jwtAuth *middleware.JWTAuthMiddleware) *AuthHandler {
 	}
 }
 
+// SetLoginThrottle wires brute-force protection for /auth/login. Optional —
+// when unset, failed logins are logged but never throttled.
+func (h *AuthHandler) SetLoginThrottle(t *middleware.LoginThrottle) {
+	h.loginThrottle = t
+}
+
+// SetAuditRecorder wires the audit log used to record login attempts and
+// lockout changes. Optional — when unset, attempts are only logged via slog.
+func (h *AuthHandler) SetAuditRecorder(r services.AuditRecorder) {
+	h.auditRecorder = r
+}
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Username string `json:"username"`
@@ -46,12 +65,22 @@ type SetupStatusResponse struct {
 	SetupCompleted bool `json:"setup_completed"`
 }
 
+// UnlockRequest represents the admin unlock request body. An empty RemoteIP
+// clears every lockout recorded for Username.
+type UnlockRequest struct {
+	Username string `json:"username"`
+	RemoteIP string `json:"remote_ip"`
+}
+
 // SetupRoutes sets up authentication routes
 func (h *AuthHandler) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/auth/login", h.handleLogin)
 	mux.HandleFunc("/auth/verify", h.handleVerify)
 	mux.HandleFunc("/auth/setup-status", h.handleSetupStatus)
 	mux.HandleFunc("/auth/setup", h.handleSetup)
+	// Lockout administration; not in the JWT skip list, so admin-only.
+	mux.HandleFunc("GET /auth/lockouts", h.handleListLockouts)
+	mux.HandleFunc("POST /auth/unlock", h.handleUnlock)
 }
 
 // handleLogin handles POST /auth/login
@@ -72,20 +101,69 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	clientIP := api.ClientIP(r)
+
+	// Throttled attempts are rejected before the bcrypt comparison so a
+	// locked-out client cannot keep probing the password. An admitted
+	// attempt counts as a failure until it succeeds, so parallel requests
+	// cannot all slip past the check while bcrypt runs.
+	var failWait time.Duration
+	var failLocked bool
+	if h.loginThrottle != nil {
+		wait, locked, ok := h.loginThrottle.Reserve(req.Username, clientIP)
+		if !ok {
+			slog.WarnContext(r.Context(), "throttled login attempt", "username", req.Username, "remote_ip", clientIP, "retry_after", wait)
+			h.recordAudit(&database.AuditLog{
+				Action:   database.AuditActionLoginBlocked,
+				Actor:    req.Username,
+				RemoteIP: clientIP,
+				Outcome:  database.AuditOutcomeDenied,
+				Details:  database.JSONB{"retry_after_seconds": retryAfterSeconds(wait)},
+			})
+			respondLoginThrottled(w, wait)
+			return
+		}
+		failWait, failLocked = wait, locked
+	}
+
 	if !h.jwtAuth.ValidateCredentials(req.Username, req.Password) {
-		slog.Warn("failed login attempt", "username", req.Username, "remote_addr", r.RemoteAddr)
+		slog.WarnContext(r.Context(), "failed login attempt", "username", req.Username, "remote_ip", clientIP)
+		details := database.JSONB{}
+		if h.loginThrottle != nil {
+			details["retry_after_seconds"] = retryAfterSeconds(failWait)
+			details["locked"] = failLocked
+			if failLocked {
+				slog.WarnContext(r.Context(), "login locked out", "username", req.Username, "remote_ip", clientIP, "duration", failWait)
+			}
+		}
+		h.recordAudit(&database.AuditLog{
+			Action:   database.AuditActionLoginFailed,
+			Actor:    req.Username,
+			RemoteIP: clientIP,
+			Outcome:  database.AuditOutcomeFailure,
+			Details:  details,
+		})
 		api.RespondError(w, http.StatusUnauthorized, "Invalid username or password")
 		return
 	}
 
+	if h.loginThrottle != nil {
+		h.loginThrottle.RecordSuccess(req.Username, clientIP)
+	}
+
 	token, err := h.jwtAuth.GenerateToken(req.Username)
 	if err != nil {
-		slog.Error("failed to generate token", "username", req.Username, "err", err)
+		slog.ErrorContext(r.Context(), "failed to generate token", "username", req.Username, "err", err)
 		api.RespondError(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	slog.Info("user logged in successfully", "username", req.Username, "remote_addr", r.RemoteAddr)
+	slog.InfoContext(r.Context(), "user logged in successfully", "username", req.Username, "remote_ip", clientIP)
+	h.recordAudit(&database.AuditLog{
+		Action:   database.AuditActionLoginSucceeded,
+		Actor:    req.Username,
+		RemoteIP: clientIP,
+	})
 
 	api.RespondJSON(w, http.StatusOK, LoginResponse{
 		Token:     token,
@@ -94,6 +172,69 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleListLockouts handles GET /auth/lockouts - lists throttled username+IP pairs
+func (h *AuthHandler) handleListLockouts(w http.ResponseWriter, r *http.Request) {
+	if h.loginThrottle == nil {
+		api.RespondJSON(w, http.StatusOK, []middleware.LoginLockout{})
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, h.loginThrottle.Lockouts())
+}
+
+// handleUnlock handles POST /auth/unlock - clears login lockouts for a username
+func (h *AuthHandler) handleUnlock(w http.ResponseWriter, r *http.Request) {
+	if h.loginThrottle == nil {
+		api.RespondError(w, http.StatusServiceUnavailable, "Login throttling is not enabled")
+		return
+	}
+
+	var req UnlockRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Username == "" {
+		api.RespondValidationError(w, map[string]string{
+			"username": "Username is required",
+		})
+		return
+	}
+
+	cleared := h.loginThrottle.Unlock(req.Username, req.RemoteIP)
+	actor := middleware.GetUserFromContext(r.Context())
+	slog.InfoContext(r.Context(), "login lockout cleared", "username", req.Username, "remote_ip", req.RemoteIP, "cleared", cleared, "by", actor)
+	h.recordAudit(&database.AuditLog{
+		Action:   database.AuditActionLoginUnlocked,
+		Actor:    actor,
+		RemoteIP: api.ClientIP(r),
+		Target:   req.Username,
+		Details:  database.JSONB{"remote_ip": req.RemoteIP, "cleared": cleared},
+	})
+
+	api.RespondJSON(w, http.StatusOK, map[string]int{"cleared": cleared})
+}
+
+// recordAudit appends entry to the audit log, best-effort.
+func (h *AuthHandler) recordAudit(entry *database.AuditLog) {
+	if h.auditRecorder == nil {
+		return
+	}
+	if err := h.auditRecorder.Record(entry); err != nil {
+		slog.Warn("failed to record audit entry", "action", entry.Action, "err", err)
+	}
+}
+
+// retryAfterSeconds rounds wait up to whole seconds for Retry-After.
+func retryAfterSeconds(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// respondLoginThrottled writes a 429 with a Retry-After header.
+func respondLoginThrottled(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+	api.RespondErrorWithCode(w, http.StatusTooManyRequests, "login_throttled", "Too many failed login attempts; try again later")
+}
+
 // handleVerify handles GET /auth/verify - verifies if the current token is valid
 func (h *AuthHandler) handleVerify(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -163,7 +304,7 @@ func (h *AuthHandler) handleSetup(w http.ResponseWriter, r *http.Request) {
 	// Complete setup: hash and store password
 	hash, err := setup.CompleteSetup(req.Password)
 	if err != nil {
-		slog.Error("setup failed", "err", err)
+		slog.ErrorContext(r.Context(), "setup failed", "err", err)
 		api.RespondError(w, http.StatusInternalServerError, "Failed to complete setup")
 		return
 	}
@@ -175,12 +316,12 @@ func (h *AuthHandler) handleSetup(w http.ResponseWriter, r *http.Request) {
 	username := h.jwtAuth.GetAdminUsername()
 	token, err := h.jwtAuth.GenerateToken(username)
 	if err != nil {
-		slog.Error("failed to generate token after setup", "err", err)
+		slog.ErrorContext(r.Context(), "failed to generate token after setup", "err", err)
 		api.RespondError(w, http.StatusInternalServerError, "Setup completed but failed to generate token")
 		return
 	}
 
-	slog.Info("initial setup completed", "remote_addr", r.RemoteAddr)
+	slog.InfoContext(r.Context(), "initial setup completed", "remote_addr", r.RemoteAddr)
 
 	api.RespondJSON(w, http.StatusOK, LoginResponse{
 		Token:     token,
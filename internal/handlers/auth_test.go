@@ -2,10 +2,17 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/middleware"
 )
 
 func TestNewAuthHandler(t *testing.T) {
@@ -380,3 +387,188 @@ func TestAuthHandler_handleVerify_AllMethods(t *testing.T) {
 		})
 	}
 }
+
+type recordingAuditRecorder struct {
+	mu      sync.Mutex
+	entries []*database.AuditLog
+}
+
+func (r *recordingAuditRecorder) Record(entry *database.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *recordingAuditRecorder) actions() []string {
+	out := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		out = append(out, e.Action)
+	}
+	return out
+}
+
+func newThrottledAuthHandler(t *testing.T, threshold int) (*AuthHandler, *recordingAuditRecorder) {
+	t.Helper()
+	hash, err := middleware.HashPassword("correct-password")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	jwtAuth := middleware.NewJWTAuthMiddleware(&middleware.JWTAuthConfig{
+		Enabled:           true,
+		AdminUsername:     "admin",
+		AdminPasswordHash: hash,
+		JWTSecret:         "test-secret",
+		JWTExpiryHours:    1,
+	})
+	h := NewAuthHandler(jwtAuth)
+	h.SetLoginThrottle(middleware.NewLoginThrottle(middleware.LoginThrottleConfig{
+		BaseDelay:        time.Hour,
+		MaxDelay:         time.Hour,
+		LockoutThreshold: threshold,
+		LockoutDuration:  time.Hour,
+	}))
+	audit := &recordingAuditRecorder{}
+	h.SetAuditRecorder(audit)
+	return h, audit
+}
+
+func postLogin(h *AuthHandler, ip, username, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(LoginRequest{Username: username, Password: password})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	req.RemoteAddr = ip + ":40000"
+	w := httptest.NewRecorder()
+	h.handleLogin(w, req)
+	return w
+}
+
+func TestAuthHandler_handleLogin_ThrottlesAfterFailure(t *testing.T) {
+	h, audit := newThrottledAuthHandler(t, 5)
+
+	if w := postLogin(h, "10.0.0.1", "admin", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("first failure = %d, want 401", w.Code)
+	}
+
+	// Even the correct password is rejected while the backoff is active.
+	w := postLogin(h, "10.0.0.1", "admin", "correct-password")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("attempt during backoff = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp["code"] != "login_throttled" {
+		t.Errorf("code = %q, want login_throttled", resp["code"])
+	}
+
+	// A different client IP is unaffected.
+	if w := postLogin(h, "10.0.0.2", "admin", "correct-password"); w.Code != http.StatusOK {
+		t.Fatalf("login from other IP = %d, want 200", w.Code)
+	}
+
+	want := []string{database.AuditActionLoginFailed, database.AuditActionLoginBlocked, database.AuditActionLoginSucceeded}
+	if got := audit.actions(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+	if audit.entries[0].RemoteIP != "10.0.0.1" || audit.entries[0].Actor != "admin" {
+		t.Errorf("unexpected audit entry: %+v", audit.entries[0])
+	}
+}
+
+func TestAuthHandler_handleLogin_ConcurrentAttemptsShareOneBudget(t *testing.T) {
+	h, audit := newThrottledAuthHandler(t, 5)
+
+	// Parallel guesses must not all pass the throttle check while the
+	// first ones are still in bcrypt.
+	const attempts = 10
+	codes := make(chan int, attempts)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			codes <- postLogin(h, "10.0.0.1", "admin", "wrong").Code
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(codes)
+
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusUnauthorized] != 1 || counts[http.StatusTooManyRequests] != attempts-1 {
+		t.Fatalf("status counts = %v, want one 401 and %d 429s", counts, attempts-1)
+	}
+	lockouts := h.loginThrottle.Lockouts()
+	if len(lockouts) != 1 || lockouts[0].Failures != 1 {
+		t.Errorf("lockouts = %+v, want one entry with 1 failure", lockouts)
+	}
+	if n := len(audit.entries); n != attempts {
+		t.Errorf("audit entries = %d, want %d", n, attempts)
+	}
+}
+
+func TestAuthHandler_handleLogin_LockoutAndUnlock(t *testing.T) {
+	h, audit := newThrottledAuthHandler(t, 1)
+
+	postLogin(h, "10.0.0.1", "admin", "wrong")
+	if locked, _ := audit.entries[0].Details["locked"].(bool); !locked {
+		t.Fatalf("expected failed attempt to record lockout, got %+v", audit.entries[0].Details)
+	}
+
+	listReq := httptest.NewRequest(http.MethodGet, "/auth/lockouts", nil)
+	listW := httptest.NewRecorder()
+	h.handleListLockouts(listW, listReq)
+	var lockouts []middleware.LoginLockout
+	if err := json.NewDecoder(listW.Body).Decode(&lockouts); err != nil {
+		t.Fatalf("decode lockouts: %v", err)
+	}
+	if len(lockouts) != 1 || lockouts[0].LockedUntil == nil {
+		t.Fatalf("unexpected lockouts: %+v", lockouts)
+	}
+
+	body := bytes.NewBufferString(`{"username":"admin","remote_ip":"10.0.0.1"}`)
+	unlockReq := httptest.NewRequest(http.MethodPost, "/auth/unlock", body)
+	unlockReq = unlockReq.WithContext(context.WithValue(unlockReq.Context(), middleware.UserContextKey, "admin"))
+	unlockW := httptest.NewRecorder()
+	h.handleUnlock(unlockW, unlockReq)
+	if unlockW.Code != http.StatusOK {
+		t.Fatalf("unlock = %d, want 200", unlockW.Code)
+	}
+
+	if w := postLogin(h, "10.0.0.1", "admin", "correct-password"); w.Code != http.StatusOK {
+		t.Fatalf("login after unlock = %d, want 200", w.Code)
+	}
+
+	unlockEntry := audit.entries[len(audit.entries)-2]
+	if unlockEntry.Action != database.AuditActionLoginUnlocked || unlockEntry.Target != "admin" {
+		t.Errorf("unexpected unlock audit entry: %+v", unlockEntry)
+	}
+}
+
+func TestAuthHandler_handleUnlock_Validation(t *testing.T) {
+	h, _ := newThrottledAuthHandler(t, 5)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/unlock", bytes.NewBufferString(`{"username":""}`))
+	w := httptest.NewRecorder()
+	h.handleUnlock(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unlock without username = %d, want 422", w.Code)
+	}
+
+	plain := NewAuthHandler(nil)
+	req = httptest.NewRequest(http.MethodPost, "/auth/unlock", bytes.NewBufferString(`{"username":"admin"}`))
+	w = httptest.NewRecorder()
+	plain.handleUnlock(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("unlock without throttle = %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetBudgetGuard wires the LLM budget check run before each alert
+// investigation. Optional — when unset, investigations are never declined.
+func (h *AlertHandler) SetBudgetGuard(g services.BudgetGuard) {
+	h.budget = g
+}
+
+// SetBudgetGuard wires the LLM budget check run before each Slack mention
+// investigation. Optional — when unset, investigations are never declined.
+func (h *SlackHandler) SetBudgetGuard(g services.BudgetGuard) {
+	h.budget = g
+}
+
+// declineOverBudget runs the budget check for a freshly spawned incident.
+// When a budget is spent the incident is closed as budget_exceeded without
+// an investigation and the note explaining why is returned for the caller
+// to relay; "" means the investigation may start.
+func declineOverBudget(guard services.BudgetGuard, skills services.SkillIncidentManager, incidentUUID string) string {
+	if guard == nil {
+		return ""
+	}
+	err := guard.CheckBudget(context.Background())
+	if err == nil {
+		return ""
+	}
+	note := services.BudgetDeclineNote(err)
+	if uerr := skills.UpdateIncidentComplete(incidentUUID, database.IncidentStatusBudgetExceeded, "", "", note, 0, 0); uerr != nil {
+		slog.Warn("failed to mark incident budget_exceeded", "incident_uuid", incidentUUID, "err", uerr)
+	}
+	slog.Info("investigation declined over budget", "incident_uuid", incidentUUID, "reason", err)
+	return note
+}
@@ -2,13 +2,25 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
 )
 
+// followerRetryAfter is the Retry-After (seconds) a follower replica sends
+// with requests that only the leader can serve.
+const followerRetryAfter = "5"
+
 // HTTPHandler handles HTTP endpoints
 type HTTPHandler struct {
 	alertHandler *AlertHandler
+	leader       services.LeaderStatus // nil means this is the only replica
+
+	readinessChecks []namedReadinessCheck
 }
 
 // NewHTTPHandler creates a new HTTP handler
@@ -18,12 +30,73 @@ func NewHTTPHandler(alertHandler *AlertHandler) *HTTPHandler {
 	}
 }
 
+// SetLeaderStatus makes a follower replica turn alert webhooks away with a
+// retryable 503: investigations run on the replica holding the agent
+// worker, which is the leader.
+func (h *HTTPHandler) SetLeaderStatus(leader services.LeaderStatus) {
+	h.leader = leader
+}
+
 // SetupRoutes configures all HTTP routes
 func (h *HTTPHandler) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", h.handleHealth)
-	// Alert webhooks: /webhook/alert/{instance_uuid}
+	mux.HandleFunc("/health/leader", h.handleLeaderHealth)
+	mux.HandleFunc("GET /readyz", h.handleReadiness)
+	// Short links to the UI: /i/{code}
+	mux.HandleFunc("GET /i/{code}", h.handleShortLink)
+	// Alert webhooks: /webhook/alert/{uuid}, with or without a trailing slash
 	if h.alertHandler != nil {
-		mux.HandleFunc("/webhook/alert/", h.alertHandler.HandleWebhook)
+		mux.HandleFunc("POST /webhook/alert/{uuid}", h.handleWebhook)
+		mux.HandleFunc("POST /webhook/alert/{uuid}/{$}", h.handleWebhook)
+	}
+}
+
+func (h *HTTPHandler) isLeader() bool {
+	return h.leader == nil || h.leader.IsLeader()
+}
+
+func (h *HTTPHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if !h.isLeader() {
+		w.Header().Set("Retry-After", followerRetryAfter)
+		api.RespondError(w, http.StatusServiceUnavailable, "this replica is not the leader; retry")
+		return
+	}
+	h.alertHandler.HandleWebhook(w, r)
+}
+
+// handleShortLink redirects a short link to the UI path it was created for.
+// The redirect is relative, so it lands on whatever host served the link.
+func (h *HTTPHandler) handleShortLink(w http.ResponseWriter, r *http.Request) {
+	target, err := services.ResolveShortLink(database.GetDB(), r.PathValue("code"))
+	if errors.Is(err, services.ErrShortLinkNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to resolve short link", "code", r.PathValue("code"), "error", err)
+		http.Error(w, "failed to resolve link", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleLeaderHealth answers 200 on the leader and 503 on followers, so a
+// load balancer can use it as the health check of the pool that receives
+// /ws/agent and /webhook/ traffic.
+func (h *HTTPHandler) handleLeaderHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	role, status := "leader", http.StatusOK
+	if !h.isLeader() {
+		role, status = "follower", http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"role": role}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode leader health response", "err", err)
 	}
 }
 
@@ -43,6 +116,6 @@ func (h *HTTPHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("failed to encode health response", "err", err)
+		slog.ErrorContext(r.Context(), "failed to encode health response", "err", err)
 	}
 }
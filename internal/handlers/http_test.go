@@ -2,11 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+	"github.com/akmatori/akmatori/internal/testhelpers"
 )
 
 func TestNewHTTPHandler(t *testing.T) {
@@ -133,3 +140,139 @@ func TestHTTPHandler_SetupRoutes(t *testing.T) {
 	// Suppress unused import warning
 	_ = alerts.NormalizedAlert{}
 }
+
+type stubLeaderStatus struct{ leader bool }
+
+func (s *stubLeaderStatus) IsLeader() bool { return s.leader }
+
+func TestHTTPHandler_LeaderRouting(t *testing.T) {
+	h := NewHTTPHandler(&AlertHandler{
+		adapters:     make(map[string]alerts.AlertAdapter),
+		alertService: &mockAlertManager{getInstanceErr: errors.New("not found")},
+	})
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	agentWS := NewAgentWSHandler()
+	agentWS.SetupRoutes(mux)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		method := http.MethodGet
+		if strings.HasPrefix(path, "/webhook/") {
+			method = http.MethodPost
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// Without an elector the replica is alone and always leads.
+	if w := serve("/health/leader"); w.Code != http.StatusOK {
+		t.Errorf("single replica /health/leader = %d", w.Code)
+	}
+
+	leader := &stubLeaderStatus{}
+	h.SetLeaderStatus(leader)
+	agentWS.SetLeaderStatus(leader)
+	if w := serve("/health/leader"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("follower /health/leader = %d", w.Code)
+	}
+	for _, path := range []string{"/webhook/alert/some-uuid", "/ws/agent"} {
+		w := serve(path)
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+			t.Errorf("follower %s = %d, Retry-After %q", path, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+
+	leader.leader = true
+	if w := serve("/health/leader"); w.Code != http.StatusOK {
+		t.Errorf("leader /health/leader = %d", w.Code)
+	}
+	// The webhook now reaches the alert handler, which finds no instance.
+	if w := serve("/webhook/alert/some-uuid"); w.Code != http.StatusNotFound {
+		t.Errorf("leader webhook = %d, want 404", w.Code)
+	}
+}
+
+func TestHTTPHandler_ShortLinkRedirect(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.ShortLink{})
+	code, err := services.ShortenPath(db, "/incidents/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	NewHTTPHandler(nil).SetupRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/i/"+code, nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/incidents/abc" {
+		t.Errorf("redirect = %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/i/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown code status = %d, want 404", rec.Code)
+	}
+}
+
+func TestHTTPHandler_Readiness(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.SlackSettings{})
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	leader := &stubLeaderStatus{leader: true}
+	workerConnected := false
+	socketState := slackutil.SocketStateDisabled
+	h := NewHTTPHandler(nil)
+	h.AddReadinessCheck("database", DatabaseReadinessCheck(db))
+	h.AddReadinessCheck("slack", SlackReadinessCheck(func() string { return socketState }))
+	h.AddReadinessCheck("agent_worker", AgentWorkerReadinessCheck(func() bool { return workerConnected }, leader))
+	h.AddReadinessCheck("mcp_gateway", GatewayReadinessCheck(gateway.URL))
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+
+	probe := func() (int, api.ReadinessResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp api.ReadinessResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := probe()
+	if code != http.StatusServiceUnavailable || resp.Checks["agent_worker"].Status != "error" {
+		t.Errorf("no worker: %d %+v", code, resp)
+	}
+	if resp.Checks["database"].Status != "ok" || resp.Checks["mcp_gateway"].Status != "ok" {
+		t.Errorf("database/gateway not ok: %+v", resp.Checks)
+	}
+	if resp.Checks["slack"].Status != "disabled" {
+		t.Errorf("unconfigured Slack should be disabled: %+v", resp.Checks["slack"])
+	}
+
+	workerConnected = true
+	if code, resp := probe(); code != http.StatusOK || resp.Status != "ready" {
+		t.Errorf("all up: %d %+v", code, resp)
+	}
+
+	// Slack that is connecting fails readiness; a follower neither owns
+	// Socket Mode nor holds the worker.
+	socketState = slackutil.SocketStateConnecting
+	if code, resp := probe(); code != http.StatusServiceUnavailable || resp.Checks["slack"].Status != "error" {
+		t.Errorf("Slack connecting: %d %+v", code, resp.Checks["slack"])
+	}
+	socketState, workerConnected, leader.leader = slackutil.SocketStateWebAPIOnly, false, false
+	if code, resp := probe(); code != http.StatusOK || resp.Checks["agent_worker"].Status != "disabled" {
+		t.Errorf("follower: %d %+v", code, resp.Checks)
+	}
+
+	gateway.Close()
+	if code, resp := probe(); code != http.StatusServiceUnavailable || resp.Checks["mcp_gateway"].Status != "error" {
+		t.Errorf("gateway down: %d %+v", code, resp.Checks["mcp_gateway"])
+	}
+}
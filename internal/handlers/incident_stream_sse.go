@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
+)
+
+// handleIncidentLogSSE handles GET /api/incidents/{uuid}/log/stream: the
+// same frames as /ws/incidents/{uuid}, as Server-Sent Events for curl and
+// EventSource clients. Each event is named after the frame type and carries
+// the JSON IncidentStreamFrame as data.
+//
+// ?lines=N trims the snapshot to the last N lines; later deltas still carry
+// offsets into the full log, so tailing clients append them. With
+// ?strip_emoji=true offsets are dropped altogether (stripping changes
+// lengths) and "log" deltas must be appended.
+func (h *IncidentStreamHandler) handleIncidentLogSSE(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseLogRenderOptions(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.RespondError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+	incidentUUID := r.PathValue("uuid")
+	incident, err := h.incidents.GetIncident(incidentUUID)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	sub := h.hub.Subscribe(incidentUUID, incident.FullLog)
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop the bundled nginx proxy from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(frame IncidentStreamFrame) bool {
+		if opts.stripEmoji {
+			frame.FullLog = utils.StripEmoji(frame.FullLog)
+			frame.Delta = utils.StripEmoji(frame.Delta)
+			frame.Offset = 0
+		}
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", frame.Type, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	knownLen := len(incident.FullLog)
+	if !send(IncidentStreamFrame{
+		Type:         "snapshot",
+		IncidentUUID: incidentUUID,
+		Status:       incident.Status,
+		FullLog:      logTail(utils.SanitizeLog(incident.FullLog), opts.lines),
+	}) {
+		return
+	}
+
+	ping := time.NewTicker(incidentStreamPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; EventSource reconnects and
+				// receives a fresh snapshot.
+				return
+			}
+			frame, ok, gap := incidentStreamFrameFor(event, &knownLen)
+			if gap {
+				current, err := h.incidents.GetIncident(incidentUUID)
+				if err != nil {
+					return
+				}
+				frame = IncidentStreamFrame{
+					Type:         string(services.IncidentStreamEventLogReset),
+					IncidentUUID: incidentUUID,
+					Delta:        utils.SanitizeLog(current.FullLog),
+				}
+				knownLen = len(current.FullLog)
+			} else if !ok {
+				continue
+			}
+			if !send(frame) {
+				return
+			}
+		}
+	}
+}
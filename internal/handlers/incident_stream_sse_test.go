@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// readSSEFrame reads the next event from an SSE stream, skipping comments.
+func readSSEFrame(t *testing.T, r *bufio.Reader) (string, IncidentStreamFrame) {
+	t.Helper()
+	var event string
+	var frame IncidentStreamFrame
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &frame); err != nil {
+				t.Fatalf("decode frame %q: %v", line, err)
+			}
+		case line == "" && event != "":
+			return event, frame
+		}
+	}
+}
+
+func TestIncidentLogSSE(t *testing.T) {
+	hub, server := newIncidentStreamServer(t, map[string]*database.Incident{
+		"inc-1": {UUID: "inc-1", Status: database.IncidentStatusRunning, FullLog: "step 1\n\x1b[32mstep 2\x1b[0m ✅"},
+	})
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	resp, err := client.Get(server.URL + "/api/incidents/inc-1/log/stream?lines=1&strip_emoji=true")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := bufio.NewReader(resp.Body)
+
+	event, snapshot := readSSEFrame(t, body)
+	if event != "snapshot" || snapshot.FullLog != "step 2 " || snapshot.Status != database.IncidentStatusRunning {
+		t.Fatalf("snapshot = %s %+v", event, snapshot)
+	}
+
+	hub.PublishLog("inc-1", "step 1\n\x1b[32mstep 2\x1b[0m ✅\n🔧 step 3")
+	event, delta := readSSEFrame(t, body)
+	if event != "log" || delta.Delta != "\nstep 3" || delta.Offset != 0 {
+		t.Errorf("delta = %s %+v", event, delta)
+	}
+
+	for _, path := range []string{"/api/incidents/missing/log/stream", "/api/incidents/inc-1/log/stream?lines=0"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Errorf("GET %s succeeded", path)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// incidentStreamWriteTimeout bounds each frame write so a stalled browser
+	// cannot pin the forwarding goroutine.
+	incidentStreamWriteTimeout = 10 * time.Second
+	// incidentStreamPingInterval keeps idle sockets alive through proxies
+	// (nginx closes upgraded connections after 60s without traffic).
+	incidentStreamPingInterval = 30 * time.Second
+)
+
+// IncidentStreamFrame is the JSON frame sent to /ws/incidents/{uuid} clients.
+// The first frame is always a "snapshot" carrying the full log and current
+// status; later frames are "log" deltas (apply Delta at Offset), "log_reset"
+// (replace the log with Delta) and "status" transitions.
+type IncidentStreamFrame struct {
+	Type         string                  `json:"type"`
+	IncidentUUID string                  `json:"incident_uuid"`
+	Status       database.IncidentStatus `json:"status,omitempty"`
+	FullLog      string                  `json:"full_log,omitempty"`
+	Offset       int                     `json:"offset,omitempty"`
+	Delta        string                  `json:"delta,omitempty"`
+}
+
+// IncidentStreamHandler serves live incident log/status updates to the UI
+// over WebSocket so the incident page does not have to poll.
+type IncidentStreamHandler struct {
+	upgrader      websocket.Upgrader
+	hub           *services.IncidentStreamHub
+	incidents     services.IncidentManager
+	originAllowed func(origin string) bool
+}
+
+// NewIncidentStreamHandler creates the handler. Upgrades are accepted from
+// the host serving the API (see sameOriginHost) and from any origin approved
+// via SetOriginAllowed.
+func NewIncidentStreamHandler(hub *services.IncidentStreamHub, incidents services.IncidentManager) *IncidentStreamHandler {
+	h := &IncidentStreamHandler{
+		hub:       hub,
+		incidents: incidents,
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin:     h.checkOrigin,
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+	}
+	return h
+}
+
+// SetOriginAllowed sets an additional check for cross-origin upgrades,
+// typically the CORS middleware's AllowsOrigin so a UI hosted elsewhere can
+// open the stream.
+func (h *IncidentStreamHandler) SetOriginAllowed(fn func(origin string) bool) {
+	h.originAllowed = fn
+}
+
+func (h *IncidentStreamHandler) checkOrigin(r *http.Request) bool {
+	if sameOriginHost(r) {
+		return true
+	}
+	return h.originAllowed != nil && h.originAllowed(r.Header.Get("Origin"))
+}
+
+// SetupRoutes registers the incident stream routes. Browsers cannot set an
+// Authorization header on WebSocket upgrades or EventSource requests, so
+// clients authenticate with the ?token= query parameter accepted by the JWT
+// middleware.
+func (h *IncidentStreamHandler) SetupRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /ws/incidents/{uuid}", h.handleIncidentStream)
+	mux.HandleFunc("GET /api/incidents/{uuid}/log/stream", h.handleIncidentLogSSE)
+}
+
+func (h *IncidentStreamHandler) handleIncidentStream(w http.ResponseWriter, r *http.Request) {
+	incidentUUID := r.PathValue("uuid")
+	incident, err := h.incidents.GetIncident(incidentUUID)
+	if err != nil {
+		api.RespondError(w, http.StatusNotFound, "Incident not found")
+		return
+	}
+
+	// Subscribe before upgrading so no update persisted between the snapshot
+	// read and the first forwarded event is lost; the per-connection offset
+	// check below reconciles any overlap.
+	sub := h.hub.Subscribe(incidentUUID, incident.FullLog)
+	defer sub.Cancel()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "failed to upgrade incident stream", "incident_uuid", incidentUUID, "err", err)
+		return
+	}
+	defer conn.Close()
+
+	// Reader goroutine: the client never sends data, but reading is required
+	// to process close/pong control frames and detect disconnects.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// knownLen is in the hub's coordinates, the log as persisted. Logs are
+	// persisted sanitized, so sanitizing again only changes legacy rows.
+	knownLen := len(incident.FullLog)
+	if err := h.writeFrame(conn, IncidentStreamFrame{
+		Type:         "snapshot",
+		IncidentUUID: incidentUUID,
+		Status:       incident.Status,
+		FullLog:      utils.SanitizeLog(incident.FullLog),
+	}); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(incidentStreamPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(incidentStreamWriteTimeout)); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// receives a fresh snapshot.
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "stream lagged"),
+					time.Now().Add(incidentStreamWriteTimeout))
+				return
+			}
+			frame, send, gap := incidentStreamFrameFor(event, &knownLen)
+			if gap {
+				// Missed part of the log (should not happen): resync from
+				// the persisted row rather than render a corrupted log.
+				current, err := h.incidents.GetIncident(incidentUUID)
+				if err != nil {
+					return
+				}
+				frame = IncidentStreamFrame{
+					Type:         string(services.IncidentStreamEventLogReset),
+					IncidentUUID: incidentUUID,
+					Delta:        utils.SanitizeLog(current.FullLog),
+				}
+				knownLen = len(current.FullLog)
+			} else if !send {
+				continue
+			}
+			if err := h.writeFrame(conn, frame); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// incidentStreamFrameFor converts a hub event into a client frame relative
+// to the log length this connection has already delivered. Overlapping
+// deltas are trimmed and fully-delivered ones are skipped (send=false). A
+// delta starting past the delivered length reports gap=true so the caller
+// can resync from the database.
+func incidentStreamFrameFor(event services.IncidentStreamEvent, knownLen *int) (frame IncidentStreamFrame, send bool, gap bool) {
+	frame = IncidentStreamFrame{
+		Type:         string(event.Type),
+		IncidentUUID: event.IncidentUUID,
+		Status:       event.Status,
+	}
+
+	switch event.Type {
+	case services.IncidentStreamEventLog:
+		end := event.Offset + len(event.Delta)
+		switch {
+		case event.Offset > *knownLen:
+			return frame, false, true
+		case end <= *knownLen:
+			return frame, false, false
+		default:
+			frame.Offset = *knownLen
+			frame.Delta = event.Delta[*knownLen-event.Offset:]
+		}
+		*knownLen = end
+	case services.IncidentStreamEventLogReset:
+		frame.Delta = event.Delta
+		*knownLen = len(event.Delta)
+	}
+	return frame, true, false
+}
+
+// sameOriginHost accepts upgrades without an Origin header (non-browser
+// clients) or whose Origin hostname matches the request's Host. Ports are
+// ignored because the bundled nginx proxy forwards $host without the port,
+// so gorilla's strict default check would reject the UI on :8080.
+func sameOriginHost(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.EqualFold(u.Hostname(), host)
+}
+
+func (h *IncidentStreamHandler) writeFrame(conn *websocket.Conn, frame IncidentStreamFrame) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(incidentStreamWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(frame)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/gorilla/websocket"
+)
+
+// streamIncidentManager serves GetIncident from an in-memory map. Other
+// IncidentManager methods are not used by the stream handler.
+type streamIncidentManager struct {
+	services.IncidentManager
+	mu        sync.Mutex
+	incidents map[string]*database.Incident
+}
+
+func (m *streamIncidentManager) GetIncident(incidentUUID string) (*database.Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inc, ok := m.incidents[incidentUUID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *inc
+	return &copied, nil
+}
+
+func newIncidentStreamServer(t *testing.T, incidents map[string]*database.Incident) (*services.IncidentStreamHub, *httptest.Server) {
+	t.Helper()
+	hub := services.NewIncidentStreamHub()
+	h := NewIncidentStreamHandler(hub, &streamIncidentManager{incidents: incidents})
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return hub, server
+}
+
+func readStreamFrame(t *testing.T, conn *websocket.Conn) IncidentStreamFrame {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var frame IncidentStreamFrame
+	if err := conn.ReadJSON(&frame); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	return frame
+}
+
+func TestIncidentStream_SnapshotThenUpdates(t *testing.T) {
+	hub, server := newIncidentStreamServer(t, map[string]*database.Incident{
+		"inc-1": {UUID: "inc-1", Status: database.IncidentStatusRunning, FullLog: "step 1"},
+	})
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/incidents/inc-1"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	snapshot := readStreamFrame(t, conn)
+	if snapshot.Type != "snapshot" || snapshot.FullLog != "step 1" || snapshot.Status != database.IncidentStatusRunning {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	hub.PublishLog("inc-1", "step 1\nstep 2")
+	hub.PublishStatus("inc-1", database.IncidentStatusCompleted)
+
+	delta := readStreamFrame(t, conn)
+	if delta.Type != "log" || delta.Offset != 6 || delta.Delta != "\nstep 2" {
+		t.Errorf("unexpected delta frame: %+v", delta)
+	}
+	status := readStreamFrame(t, conn)
+	if status.Type != "status" || status.Status != database.IncidentStatusCompleted {
+		t.Errorf("unexpected status frame: %+v", status)
+	}
+}
+
+func TestIncidentStream_UnknownIncident(t *testing.T) {
+	_, server := newIncidentStreamServer(t, map[string]*database.Incident{})
+
+	resp, err := http.Get(server.URL + "/ws/incidents/missing")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestIncidentStreamFrameFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     services.IncidentStreamEvent
+		knownLen  int
+		wantSend  bool
+		wantGap   bool
+		wantFrame IncidentStreamFrame
+		wantLen   int
+	}{
+		{
+			name:      "contiguous delta",
+			event:     services.IncidentStreamEvent{Type: services.IncidentStreamEventLog, Offset: 3, Delta: "def"},
+			knownLen:  3,
+			wantSend:  true,
+			wantFrame: IncidentStreamFrame{Type: "log", Offset: 3, Delta: "def"},
+			wantLen:   6,
+		},
+		{
+			name:      "overlapping delta is trimmed",
+			event:     services.IncidentStreamEvent{Type: services.IncidentStreamEventLog, Offset: 3, Delta: "def"},
+			knownLen:  4,
+			wantSend:  true,
+			wantFrame: IncidentStreamFrame{Type: "log", Offset: 4, Delta: "ef"},
+			wantLen:   6,
+		},
+		{
+			name:     "already delivered delta is skipped",
+			event:    services.IncidentStreamEvent{Type: services.IncidentStreamEventLog, Offset: 0, Delta: "abc"},
+			knownLen: 5,
+			wantLen:  5,
+		},
+		{
+			name:     "delta past known length is a gap",
+			event:    services.IncidentStreamEvent{Type: services.IncidentStreamEventLog, Offset: 8, Delta: "xyz"},
+			knownLen: 5,
+			wantGap:  true,
+			wantLen:  5,
+		},
+		{
+			name:      "reset replaces known length",
+			event:     services.IncidentStreamEvent{Type: services.IncidentStreamEventLogReset, Delta: "new"},
+			knownLen:  10,
+			wantSend:  true,
+			wantFrame: IncidentStreamFrame{Type: "log_reset", Delta: "new"},
+			wantLen:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			knownLen := tt.knownLen
+			frame, send, gap := incidentStreamFrameFor(tt.event, &knownLen)
+			if send != tt.wantSend || gap != tt.wantGap {
+				t.Fatalf("send=%v gap=%v, want send=%v gap=%v", send, gap, tt.wantSend, tt.wantGap)
+			}
+			if send && frame != tt.wantFrame {
+				t.Errorf("frame = %+v, want %+v", frame, tt.wantFrame)
+			}
+			if knownLen != tt.wantLen {
+				t.Errorf("knownLen = %d, want %d", knownLen, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestSameOriginHost(t *testing.T) {
+	tests := []struct {
+		origin string
+		host   string
+		want   bool
+	}{
+		{"", "akmatori.example.com", true},
+		{"http://akmatori.example.com:8080", "akmatori.example.com", true},
+		{"https://AKMATORI.example.com", "akmatori.example.com:443", true},
+		{"https://evil.example.com", "akmatori.example.com", false},
+		{"::not a url", "akmatori.example.com", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/ws/incidents/x", nil)
+		r.Host = tt.host
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := sameOriginHost(r); got != tt.want {
+			t.Errorf("sameOriginHost(origin=%q, host=%q) = %v, want %v", tt.origin, tt.host, got, tt.want)
+		}
+	}
+}
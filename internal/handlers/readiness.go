@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+	"gorm.io/gorm"
+)
+
+// readinessCheckTimeout bounds each readiness check, so a hung dependency
+// fails the probe instead of stalling it past the prober's own timeout.
+const readinessCheckTimeout = 3 * time.Second
+
+// ErrDependencyDisabled is returned (wrapped, with the reason) by a readiness
+// check whose dependency this replica does not use. It is reported as
+// "disabled" and does not make the replica unready.
+var ErrDependencyDisabled = errors.New("disabled")
+
+// ReadinessCheck verifies that one dependency is usable.
+type ReadinessCheck func(ctx context.Context) error
+
+type namedReadinessCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// AddReadinessCheck registers a dependency check run by /readyz. Checks run
+// concurrently on every probe.
+func (h *HTTPHandler) AddReadinessCheck(name string, check ReadinessCheck) {
+	h.readinessChecks = append(h.readinessChecks, namedReadinessCheck{name: name, check: check})
+}
+
+// handleReadiness runs the readiness checks and answers 200 when none failed
+// and 503 otherwise, with the status of every dependency. Unlike /health,
+// which only says the process is serving, it is meant for load balancer and
+// Kubernetes readiness probes.
+func (h *HTTPHandler) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	resp := api.ReadinessResponse{
+		Status: "ready",
+		Checks: make(map[string]api.DependencyStatus, len(h.readinessChecks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.readinessChecks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+			defer cancel()
+			started := time.Now()
+			err := c.check(ctx)
+			result := api.DependencyStatus{Status: "ok", LatencyMs: time.Since(started).Milliseconds()}
+			switch {
+			case errors.Is(err, ErrDependencyDisabled):
+				result.Status = "disabled"
+				result.Message = strings.TrimPrefix(err.Error(), ErrDependencyDisabled.Error()+": ")
+			case err != nil:
+				result.Status, result.Message = "error", err.Error()
+				slog.WarnContext(r.Context(), "readiness check failed", "dependency", c.name, "error", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Checks[c.name] = result
+			if result.Status == "error" {
+				resp.Status = "not_ready"
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if resp.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	api.RespondJSON(w, status, resp)
+}
+
+// DatabaseReadinessCheck pings the database.
+func DatabaseReadinessCheck(db *gorm.DB) ReadinessCheck {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// SlackReadinessCheck requires a live Socket Mode connection while Slack is
+// enabled and this replica owns Socket Mode. socketState is normally
+// slack.Manager.SocketState.
+func SlackReadinessCheck(socketState func() string) ReadinessCheck {
+	return func(ctx context.Context) error {
+		switch socketState() {
+		case slackutil.SocketStateConnected:
+			return nil
+		case slackutil.SocketStateWebAPIOnly:
+			return fmt.Errorf("%w: Socket Mode is owned by the leader", ErrDependencyDisabled)
+		case slackutil.SocketStateConnecting:
+			return errors.New("Socket Mode is not connected")
+		}
+		// Like Manager.Start, settings that fail to load leave Slack off.
+		settings, err := database.GetSlackSettings()
+		if err != nil || !settings.IsActive() {
+			return fmt.Errorf("%w: Slack is not configured", ErrDependencyDisabled)
+		}
+		return errors.New("Slack is enabled but not running")
+	}
+}
+
+// AgentWorkerReadinessCheck requires a connected agent worker. Workers only
+// connect to the leader, so followers report it disabled; leader may be nil
+// for a single replica.
+func AgentWorkerReadinessCheck(connected func() bool, leader services.LeaderStatus) ReadinessCheck {
+	return func(ctx context.Context) error {
+		if leader != nil && !leader.IsLeader() {
+			return fmt.Errorf("%w: the agent worker connects to the leader", ErrDependencyDisabled)
+		}
+		if !connected() {
+			return errors.New("no agent worker connected")
+		}
+		return nil
+	}
+}
+
+// GatewayReadinessCheck requires the MCP gateway's /health to answer 200.
+func GatewayReadinessCheck(gatewayURL string) ReadinessCheck {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, gatewayURL+"/health", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("MCP gateway unreachable: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("MCP gateway health returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+)
+
+// SetRemediationPlanner wires the service two-phase investigations hand
+// their plans to. Optional — when unset, the plan_approval source setting is
+// ignored and every investigation runs in a single phase.
+func (h *AlertHandler) SetRemediationPlanner(p services.RemediationPlanner) {
+	h.planner = p
+}
+
+// planApprovalRequired reports whether investigations for the source stop
+// after diagnosis to have their remediation plan approved. An invalid
+// setting (rejected on save, so only possible for older rows) counts as
+// off.
+func (h *AlertHandler) planApprovalRequired(instance *database.AlertSourceInstance) bool {
+	if h.planner == nil {
+		return false
+	}
+	enabled, err := services.ParsePlanApproval(instance.Settings)
+	if err != nil {
+		slog.Warn("ignoring invalid plan_approval setting", "source", instance.Name, "err", err)
+		return false
+	}
+	return enabled
+}
+
+// proposeRemediationPlan stores the plan from a phase-one response and posts
+// it for approval. On failure the incident stays diagnosed and can still be
+// followed up by hand.
+func (h *AlertHandler) proposeRemediationPlan(incidentUUID, response string) {
+	if _, err := h.planner.ProposePlan(context.Background(), incidentUUID, response); err != nil {
+		slog.Error("failed to propose remediation plan", "incident_uuid", incidentUUID, "err", err)
+	}
+}
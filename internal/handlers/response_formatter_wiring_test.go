@@ -5,9 +5,11 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/services"
+	"github.com/akmatori/akmatori/internal/utils"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -36,7 +38,7 @@ func setupFormatterWiringDB(t *testing.T) func() {
 	if err != nil {
 		t.Fatalf("open sqlite db: %v", err)
 	}
-	if err := db.AutoMigrate(&database.LLMSettings{}, &database.FormattingRule{}, &database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.LLMSettings{}, &database.FormattingRule{}, &database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate sqlite db: %v", err)
 	}
 	database.DB = db
@@ -291,7 +293,7 @@ func TestAPIHandler_SetResponseFormatter(t *testing.T) {
 // web UI shows execution metrics regardless of what the formatter emits.
 func TestAppendFinalizeMetrics_FormattedResponsePreservesFooter(t *testing.T) {
 	formattedBody := `{"status":"resolved","summary":"All clear."}`
-	got := appendFinalizeMetrics(formattedBody, 41_300, 126_028, false)
+	got := appendFinalizeMetrics(formattedBody, utils.RunMetrics{ExecutionTime: 41_300 * time.Millisecond, TokensUsed: 126_028}, false)
 
 	// Body is preserved verbatim — the formatter's structured output is
 	// not rewritten by the metrics-append step.
@@ -319,15 +321,34 @@ func TestAppendFinalizeMetrics_FormattedResponsePreservesFooter(t *testing.T) {
 	}
 }
 
+// TestAppendFinalizeMetrics_ToolCallsAndCostReachSlackFooter checks that
+// the tool-call count and cost estimate ride on the same metrics line, so
+// the Slack completion message shows them alongside time and tokens.
+func TestAppendFinalizeMetrics_ToolCallsAndCostReachSlackFooter(t *testing.T) {
+	cost := 0.42
+	got := appendFinalizeMetrics("Root cause found.", utils.RunMetrics{
+		ExecutionTime: 90 * time.Second,
+		TokensUsed:    52_000,
+		ToolCalls:     7,
+		CostUSD:       &cost,
+	}, false)
+
+	_, footer := buildSlackFooter(got, "uuid-cost")
+	want := "⏱️ Time: 1m 30s | 🎯 Tokens: 52,000 | 🛠️ Tool calls: 7 | 💰 Est. cost: $0.42"
+	if !strings.Contains(footer, want) {
+		t.Errorf("Slack footer = %q, want it to contain %q", footer, want)
+	}
+}
+
 // TestAppendFinalizeMetrics_SkipsErrorsAndEmpty verifies the guard against
 // appending a metrics footer to error / empty responses, where execution
 // time / tokens are not meaningful (the OnError callback path historically
 // produced responses without a metrics line).
 func TestAppendFinalizeMetrics_SkipsErrorsAndEmpty(t *testing.T) {
-	if got := appendFinalizeMetrics("❌ Error: agent crashed", 5_000, 100, true); got != "❌ Error: agent crashed" {
+	if got := appendFinalizeMetrics("❌ Error: agent crashed", utils.RunMetrics{ExecutionTime: 5 * time.Second, TokensUsed: 100}, true); got != "❌ Error: agent crashed" {
 		t.Errorf("error response must not gain a metrics footer: %q", got)
 	}
-	if got := appendFinalizeMetrics("", 5_000, 100, false); got != "" {
+	if got := appendFinalizeMetrics("", utils.RunMetrics{ExecutionTime: 5 * time.Second, TokensUsed: 100}, false); got != "" {
 		t.Errorf("empty response must remain empty: %q", got)
 	}
 }
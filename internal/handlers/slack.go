@@ -11,6 +11,7 @@ import (
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/executor"
 	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
@@ -42,6 +43,9 @@ type SlackHandler struct {
 	alertHandler    *AlertHandler
 	alertService    services.AlertManager
 	channelService  services.ChannelManager
+	// channelResolver maps listener channels configured by name to IDs and
+	// is kept current by channel rename/delete events (optional).
+	channelResolver *slackutil.ChannelResolver
 	botUserID       string // Bot's user ID for self-message filtering
 	teamID          string // Workspace team ID (required for Streaming API)
 
@@ -59,6 +63,21 @@ type SlackHandler struct {
 	// client != nil (mirrors graceful degradation); tests override it to assert
 	// ack call counts without a live client.
 	feedbackAcker feedbackAcker
+
+	// approvals records Approve/Reject clicks on tool approval requests
+	// (optional).
+	approvals services.ApprovalManager
+
+	// plans records Approve/Reject clicks on remediation plans (optional).
+	plans services.RemediationPlanManager
+
+	// recordSocketState is told when the Socket Mode connection goes up or
+	// down, for the readiness probe (optional).
+	recordSocketState func(sc *socketmode.Client, connected bool)
+
+	// budget declines investigations once an LLM budget is spent
+	// (optional; nil never declines).
+	budget services.BudgetGuard
 }
 
 // NewSlackHandler creates a new Slack handler. The supplied caller is forwarded
@@ -139,6 +158,12 @@ func (h *SlackHandler) SetFeedbackClassifier(c *services.FeedbackClassifier) {
 	h.feedbackClassifier = c
 }
 
+// SetSocketStateRecorder sets the function told about Socket Mode
+// connection changes, normally slack.Manager.SetSocketConnected.
+func (h *SlackHandler) SetSocketStateRecorder(record func(sc *socketmode.Client, connected bool)) {
+	h.recordSocketState = record
+}
+
 // SetBotUserID sets the bot's user ID for self-message filtering
 func (h *SlackHandler) SetBotUserID(botUserID string) {
 	h.botUserID = botUserID
@@ -168,11 +193,9 @@ func (h *SlackHandler) LoadListenerChannels() error {
 		return fmt.Errorf("failed to list listener channels: %w", err)
 	}
 
-	h.alertChannelsMu.Lock()
-	defer h.alertChannelsMu.Unlock()
-
 	// Replace the map rather than mutate so a torn read sees the prior
-	// snapshot, not a half-rebuilt one.
+	// snapshot, not a half-rebuilt one. Keys are resolved before taking the
+	// lock because name lookups may call the Slack API.
 	next := make(map[string]*database.Channel, len(channels))
 	for i := range channels {
 		ch := &channels[i]
@@ -183,9 +206,13 @@ func (h *SlackHandler) LoadListenerChannels() error {
 			slog.Warn("listener channel missing external_id, skipping", "uuid", ch.UUID, "display_name", ch.DisplayName)
 			continue
 		}
-		next[ch.ExternalID] = ch
-		slog.Info("loaded listener channel", "channel", ch.ExternalID, "display_name", ch.DisplayName, "provider", ch.Integration.Provider)
+		key := h.listenerChannelKey(ch)
+		next[key] = ch
+		slog.Info("loaded listener channel", "channel", key, "external_id", ch.ExternalID, "display_name", ch.DisplayName, "provider", ch.Integration.Provider)
 	}
+
+	h.alertChannelsMu.Lock()
+	defer h.alertChannelsMu.Unlock()
 	h.alertChannels = next
 
 	slog.Info("loaded listener channels", "count", len(h.alertChannels))
@@ -234,6 +261,9 @@ func (h *SlackHandler) HandleSocketMode(socketClient *socketmode.Client) {
 
 			case socketmode.EventTypeInteractive:
 				socketClient.Ack(*evt.Request)
+				if callback, ok := evt.Data.(slack.InteractionCallback); ok {
+					go h.handleInteraction(callback)
+				}
 
 			case socketmode.EventTypeSlashCommand:
 				socketClient.Ack(*evt.Request)
@@ -243,6 +273,16 @@ func (h *SlackHandler) HandleSocketMode(socketClient *socketmode.Client) {
 				socketmode.EventTypeHello:
 				// Socket Mode lifecycle events - expected, no action needed
 				slog.Info("Socket Mode lifecycle event", "type", evt.Type)
+				if h.recordSocketState != nil && evt.Type != socketmode.EventTypeHello {
+					h.recordSocketState(socketClient, evt.Type == socketmode.EventTypeConnected)
+				}
+
+			case socketmode.EventTypeConnectionError,
+				socketmode.EventTypeInvalidAuth:
+				slog.Warn("Socket Mode connection failed", "type", evt.Type)
+				if h.recordSocketState != nil {
+					h.recordSocketState(socketClient, false)
+				}
 
 			default:
 				slog.Warn("unexpected event type received", "type", evt.Type)
@@ -264,6 +304,18 @@ func (h *SlackHandler) handleEventsAPI(event slackevents.EventsAPIEvent) {
 		case *slackevents.MessageEvent:
 			slog.Info("processing message event", "channel", ev.Channel, "channel_type", ev.ChannelType, "user", ev.User, "subtype", ev.SubType, "bot_id", ev.BotID)
 			h.handleMessage(ev)
+		case *slackevents.ChannelRenameEvent:
+			h.handleChannelRename(ev.Channel.ID, ev.Channel.Name)
+		case *slackevents.GroupRenameEvent:
+			h.handleChannelRename(ev.Channel.ID, ev.Channel.Name)
+		case *slackevents.ChannelDeletedEvent:
+			h.handleChannelGone(ev.Channel)
+		case *slackevents.ChannelArchiveEvent:
+			h.handleChannelGone(ev.Channel)
+		case *slackevents.GroupDeletedEvent:
+			h.handleChannelGone(ev.Channel)
+		case *slackevents.GroupArchiveEvent:
+			h.handleChannelGone(ev.Channel)
 		default:
 			slog.Info("unhandled inner event type", "type", innerEvent.Type)
 		}
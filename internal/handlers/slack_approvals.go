@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+	"github.com/slack-go/slack"
+)
+
+// Block Kit action IDs on approval request messages. The button value is
+// the approval UUID.
+const (
+	approvalApproveActionID = "tool_approval_approve"
+	approvalRejectActionID  = "tool_approval_reject"
+)
+
+// maxApprovalDetailBytes caps the call arguments shown in the Slack
+// message; section text is limited to 3000 characters.
+const maxApprovalDetailBytes = 2000
+
+// SlackApprovalNotifier posts tool approval requests to the incident's Slack
+// thread with Approve/Reject buttons, and replaces the buttons with the
+// outcome once decided. Incidents without a Slack thread are skipped; their
+// approvals are decided in the UI.
+type SlackApprovalNotifier struct {
+	slackManager *slackutil.Manager
+}
+
+// NewSlackApprovalNotifier creates a notifier using the manager's live client.
+func NewSlackApprovalNotifier(slackManager *slackutil.Manager) *SlackApprovalNotifier {
+	return &SlackApprovalNotifier{slackManager: slackManager}
+}
+
+// NotifyApproval implements services.ApprovalNotifier.
+func (n *SlackApprovalNotifier) NotifyApproval(ctx context.Context, approval *database.ToolApproval, incident *database.Incident) (string, string, error) {
+	if incident.SlackChannelID == "" || incident.SlackMessageTS == "" {
+		return "", "", nil
+	}
+	client := n.slackManager.GetClient()
+	if client == nil {
+		return "", "", nil
+	}
+	blocks := append(approvalRequestBlocks(approval),
+		slack.NewActionBlock("tool_approval",
+			slack.NewButtonBlockElement(approvalApproveActionID, approval.UUID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(approvalRejectActionID, approval.UUID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Reject", false, false)).WithStyle(slack.StyleDanger),
+		),
+	)
+	channelID, ts, err := client.PostMessageContext(ctx, incident.SlackChannelID,
+		slack.MsgOptionTS(incident.SlackMessageTS),
+		slack.MsgOptionText(approvalFallbackText(approval), false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("post approval request: %w", err)
+	}
+	return channelID, ts, nil
+}
+
+// ResolveApproval implements services.ApprovalNotifier.
+func (n *SlackApprovalNotifier) ResolveApproval(ctx context.Context, approval *database.ToolApproval) error {
+	client := n.slackManager.GetClient()
+	if client == nil {
+		return errors.New("slack client not available")
+	}
+	outcome := ":hourglass: Expired — nobody decided in time; the command was not run."
+	switch approval.Status {
+	case database.ToolApprovalApproved:
+		outcome = fmt.Sprintf(":white_check_mark: Approved by %s", approval.DecidedBy)
+	case database.ToolApprovalRejected:
+		outcome = fmt.Sprintf(":no_entry: Rejected by %s — investigation stopped", approval.DecidedBy)
+	}
+	if approval.Reason != "" {
+		outcome += ": " + approval.Reason
+	}
+	blocks := append(approvalRequestBlocks(approval),
+		slack.NewContextBlock("tool_approval_outcome", slack.NewTextBlockObject(slack.MarkdownType, outcome, false, false)),
+	)
+	_, _, _, err := client.UpdateMessageContext(ctx, approval.SlackChannelID, approval.SlackMessageTS,
+		slack.MsgOptionText(approvalFallbackText(approval), false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	return err
+}
+
+func approvalRequestBlocks(approval *database.ToolApproval) []slack.Block {
+	text := fmt.Sprintf(":warning: *Approval needed:* `%s` on *%s*\n```%s```",
+		approval.ToolName, approval.Target, truncateForSlack(approval.Detail, maxApprovalDetailBytes))
+	if approval.Status == database.ToolApprovalPending {
+		text += fmt.Sprintf("\nThe investigation is paused until someone decides. Expires <!date^%d^{time}|%s>.",
+			approval.ExpiresAt.Unix(), approval.ExpiresAt.UTC().Format("15:04 UTC"))
+	}
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+	}
+}
+
+func approvalFallbackText(approval *database.ToolApproval) string {
+	return fmt.Sprintf("Approval needed: %s on %s", approval.ToolName, approval.Target)
+}
+
+// SetApprovalManager wires the service Approve/Reject button clicks are
+// recorded through. Optional — when unset, clicks are acknowledged and
+// ignored.
+func (h *SlackHandler) SetApprovalManager(m services.ApprovalManager) {
+	h.approvals = m
+}
+
+// handleInteraction processes Block Kit interactions: the Approve/Reject
+// buttons on tool approval requests and on remediation plans.
+func (h *SlackHandler) handleInteraction(callback slack.InteractionCallback) {
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+	for _, action := range callback.ActionCallback.BlockActions {
+		var approve bool
+		switch action.ActionID {
+		case approvalApproveActionID:
+			approve = true
+		case approvalRejectActionID:
+		case planApproveActionID, planRejectActionID:
+			h.decidePlanFromSlack(callback, action.Value, action.ActionID == planApproveActionID)
+			continue
+		default:
+			continue
+		}
+		if h.approvals == nil {
+			continue
+		}
+		_, err := h.approvals.Decide(context.Background(), action.Value, approve, slackDecider(callback), "")
+		switch {
+		case err == nil:
+		case errors.Is(err, services.ErrApprovalNotPending):
+			h.postEphemeralApprovalNotice(callback, "This request was already decided or has expired.")
+		default:
+			slog.Error("approval: failed to record Slack decision", "approval", action.Value, "err", err)
+			h.postEphemeralApprovalNotice(callback, "Could not record your decision; try again or use the web UI.")
+		}
+	}
+}
+
+// slackDecider names the user who clicked a decision button.
+func slackDecider(callback slack.InteractionCallback) string {
+	decidedBy := callback.User.Name
+	if decidedBy == "" {
+		decidedBy = callback.User.ID
+	}
+	return "slack:" + decidedBy
+}
+
+func (h *SlackHandler) postEphemeralApprovalNotice(callback slack.InteractionCallback, text string) {
+	if h.client == nil {
+		return
+	}
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if ts := callback.Message.ThreadTimestamp; ts != "" {
+		opts = append(opts, slack.MsgOptionTS(ts))
+	}
+	if _, err := h.client.PostEphemeral(callback.Channel.ID, callback.User.ID, opts...); err != nil {
+		slog.Warn("approval: failed to post ephemeral notice", "err", err)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestSlackHandler_ApprovalButtons(t *testing.T) {
+	h := NewSlackHandler(nil, nil, nil, nil, nil)
+	stub := &stubApprovalManager{decided: map[string]bool{}}
+	h.SetApprovalManager(stub)
+
+	click := func(actionID, value string) {
+		h.handleInteraction(slack.InteractionCallback{
+			Type: slack.InteractionTypeBlockActions,
+			User: slack.User{ID: "U1", Name: "alice"},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{
+				{ActionID: actionID, Value: value},
+			}},
+		})
+	}
+	click(approvalApproveActionID, "ap-1")
+	click(approvalRejectActionID, "ap-2")
+	click("some_other_button", "ap-3")
+	// Already decided: reported back to the user, not recorded again.
+	click(approvalApproveActionID, "done")
+
+	if len(stub.decided) != 2 || !stub.decided["ap-1"] || stub.decided["ap-2"] {
+		t.Errorf("decided = %v", stub.decided)
+	}
+}
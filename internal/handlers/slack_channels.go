@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+)
+
+// SetChannelResolver wires the shared channel-name resolver. Optional —
+// when unset, listener channels must be configured by channel ID and
+// rename events are ignored.
+func (h *SlackHandler) SetChannelResolver(r *slackutil.ChannelResolver) {
+	h.channelResolver = r
+}
+
+// listenerChannelKey returns the Slack channel ID events will carry for a
+// listener channel. Channels configured by name ("#alerts") are resolved so
+// they match incoming events; on failure the raw ExternalID is kept and the
+// channel simply never matches until it resolves.
+func (h *SlackHandler) listenerChannelKey(ch *database.Channel) string {
+	if h.channelResolver == nil || ch.Integration.Provider != database.MessagingProviderSlack ||
+		slackutil.IsChannelID(ch.ExternalID) {
+		return ch.ExternalID
+	}
+	id, err := h.channelResolver.ResolveChannel(ch.ExternalID)
+	if err != nil {
+		slog.Warn("could not resolve listener channel name; alerts posted there will be ignored",
+			"uuid", ch.UUID, "external_id", ch.ExternalID, "err", err)
+		return ch.ExternalID
+	}
+	return id
+}
+
+// handleChannelRename processes channel_rename / group_rename. Channel rows
+// that still reference the old name are repointed at the stable channel ID
+// so posting and listening keep working without re-saving settings.
+func (h *SlackHandler) handleChannelRename(channelID, newName string) {
+	if h.channelResolver == nil || channelID == "" {
+		return
+	}
+	oldNames := h.channelResolver.HandleRename(channelID, newName)
+	if len(oldNames) == 0 || h.channelService == nil {
+		return
+	}
+
+	channels, err := h.channelService.ListChannels(services.ListChannelsFilter{})
+	if err != nil {
+		slog.Warn("failed to list channels after rename", "channel_id", channelID, "err", err)
+		return
+	}
+	repointed := 0
+	for _, ch := range channels {
+		if ch.Integration.Provider != database.MessagingProviderSlack {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimSpace(ch.ExternalID), "#")
+		if !slices.Contains(oldNames, name) {
+			continue
+		}
+		id := channelID
+		if _, err := h.channelService.UpdateChannel(ch.UUID, services.ChannelUpdate{ExternalID: &id}); err != nil {
+			slog.Warn("failed to repoint renamed channel", "uuid", ch.UUID, "external_id", ch.ExternalID, "err", err)
+			continue
+		}
+		slog.Info("repointed renamed Slack channel to its ID",
+			"uuid", ch.UUID, "old_external_id", ch.ExternalID, "channel_id", channelID, "new_name", newName)
+		repointed++
+	}
+	if repointed > 0 {
+		h.ReloadListenerChannels()
+	}
+}
+
+// handleChannelGone drops cached names for a deleted or archived channel so
+// a later channel created with the same name resolves to its new ID.
+func (h *SlackHandler) handleChannelGone(channelID string) {
+	if h.channelResolver == nil || channelID == "" {
+		return
+	}
+	h.channelResolver.Forget(channelID)
+}
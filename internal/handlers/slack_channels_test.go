@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+)
+
+// TestSlackHandler_ChannelRename_RepointsNameConfiguredChannels verifies that
+// a channel configured by name keeps listening after a rename: the row is
+// rewritten to the stable channel ID and the listener map is rebuilt.
+func TestSlackHandler_ChannelRename_RepointsNameConfiguredChannels(t *testing.T) {
+	db, cleanup := setupListenerChannelsDB(t)
+	defer cleanup()
+
+	byName := seedListenerChannel(t, db, "#alerts", "alerts", "", false)
+	byID := seedListenerChannel(t, db, "C0OTHER0001", "other", "", false)
+
+	resolver := slackutil.NewChannelResolver(nil)
+	resolver.HandleRename("C0ALERTS001", "alerts") // seed: "alerts" -> C0ALERTS001
+
+	h := NewSlackHandler(nil, nil, nil, nil, nil)
+	h.SetChannelService(services.NewChannelService())
+	h.SetChannelResolver(resolver)
+
+	if err := h.LoadListenerChannels(); err != nil {
+		t.Fatalf("LoadListenerChannels: %v", err)
+	}
+	if _, ok := h.isAlertChannel("C0ALERTS001"); !ok {
+		t.Fatal("name-configured listener should be keyed by its resolved channel ID")
+	}
+
+	h.handleChannelRename("C0ALERTS001", "alerts-prod")
+
+	svc := services.NewChannelService()
+	got, err := svc.GetChannelByUUID(byName.UUID)
+	if err != nil {
+		t.Fatalf("GetChannelByUUID: %v", err)
+	}
+	if got.ExternalID != "C0ALERTS001" {
+		t.Errorf("ExternalID = %q, want repointed to C0ALERTS001", got.ExternalID)
+	}
+	if other, _ := svc.GetChannelByUUID(byID.UUID); other.ExternalID != "C0OTHER0001" {
+		t.Errorf("unrelated channel changed: %q", other.ExternalID)
+	}
+	if _, ok := h.isAlertChannel("C0ALERTS001"); !ok {
+		t.Error("renamed listener channel should still be active")
+	}
+	if id, err := resolver.ResolveChannel("#alerts-prod"); err != nil || id != "C0ALERTS001" {
+		t.Errorf("new name resolves to %q, %v", id, err)
+	}
+	if _, err := resolver.ResolveChannel("#alerts"); err == nil {
+		t.Error("old name should no longer resolve from cache")
+	}
+}
+
+func TestSlackHandler_ChannelGone_ForgetsCachedNames(t *testing.T) {
+	resolver := slackutil.NewChannelResolver(nil)
+	resolver.HandleRename("C0GONE00001", "incidents")
+
+	h := NewSlackHandler(nil, nil, nil, nil, nil)
+	h.SetChannelResolver(resolver)
+	h.handleChannelGone("C0GONE00001")
+
+	if _, err := resolver.ResolveChannel("incidents"); err == nil {
+		t.Error("deleted channel name should not resolve from cache")
+	}
+}
@@ -119,10 +119,10 @@ func (h *SlackHandler) classifyThreadReplyForFeedback(threadTS, text string) (se
 func (h *SlackHandler) persistFeedback(threadTS, originalText string, verdict services.FeedbackVerdict, incident *database.Incident) *database.Memory {
 	mem := buildFeedbackMemory(originalText, verdict, incident.UUID)
 	if _, err := h.memoryManager.UpsertByName(mem); err != nil {
-		slog.Warn("feedback persist failed", "thread", threadTS, "incident", incident.UUID, "err", err)
+		slog.Warn("feedback persist failed", "thread", threadTS, "incident_uuid", incident.UUID, "err", err)
 		return nil
 	}
-	slog.Info("captured slack feedback as memory", "incident", incident.UUID, "name", mem.Name, "confidence", verdict.Confidence)
+	slog.Info("captured slack feedback as memory", "incident_uuid", incident.UUID, "name", mem.Name, "confidence", verdict.Confidence)
 	return mem
 }
 
@@ -125,7 +125,7 @@ func TestLookupIncidentByThread_DMOriginated(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
@@ -152,7 +152,7 @@ func TestLookupIncidentByThread_AlertChannel(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
@@ -180,7 +180,7 @@ func TestLookupIncidentByThread_NoMatch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
@@ -201,7 +201,7 @@ func TestMaybeCaptureSlackFeedback_PersistsConfidentFeedback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}, &database.LLMSettings{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}, &database.LLMSettings{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
@@ -245,7 +245,7 @@ func TestMaybeCaptureSlackFeedback_NotConfidentDoesNothing(t *testing.T) {
 	caller := &fakeOneShotLLMCallerH{response: `{"is_feedback": false, "summary": "casual chat", "confidence": 0.95}`}
 
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	_ = db.AutoMigrate(&database.Incident{}, &database.LLMSettings{})
+	_ = db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}, &database.LLMSettings{})
 	database.DB = db
 	_ = db.Create(&database.LLMSettings{
 		Name: "t", Provider: database.LLMProviderAnthropic, APIKey: "k",
@@ -444,7 +444,7 @@ func newFeedbackAckFixture(t *testing.T, threadTS string, wireAcker bool) *feedb
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}, &database.LLMSettings{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}, &database.LLMSettings{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
@@ -672,7 +672,7 @@ func newRouteFixture(t *testing.T, threadTS string) *routeFixture {
 	if err != nil {
 		t.Fatalf("sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}, &database.LLMSettings{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}, &database.LLMSettings{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	database.DB = db
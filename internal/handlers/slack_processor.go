@@ -13,7 +13,6 @@ import (
 	"github.com/akmatori/akmatori/internal/executor"
 	"github.com/akmatori/akmatori/internal/output"
 	"github.com/akmatori/akmatori/internal/services"
-	slackutil "github.com/akmatori/akmatori/internal/slack"
 	"github.com/akmatori/akmatori/internal/utils"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -34,17 +33,33 @@ func applyResponseFormatter(ctx context.Context, formatter *services.ResponseFor
 // appendFinalizeMetrics re-attaches the execution-time/token footer that
 // the OnCompleted callback path no longer carries (so the configurable
 // response formatter never sees the metrics line). The footer is
-// deterministically derived from tokensUsed/executionTimeMs and lands at
-// the end of the stored DB response and the Slack final-message body, so
+// deterministically derived from the run's metrics and lands at the end of
+// the stored DB response and the Slack final-message body, so
 // buildSlackFooter still extracts ⏱️ Time / 🎯 Tokens correctly even when
 // the formatter LLM rewrote the body. Skipped on error/empty responses
 // where metrics are not meaningful (matching the historical behavior of
 // OnError, which set the response without a metrics line).
-func appendFinalizeMetrics(response string, executionTimeMs int64, tokensUsed int, hasError bool) string {
+func appendFinalizeMetrics(response string, metrics utils.RunMetrics, hasError bool) string {
 	if hasError || response == "" {
 		return response
 	}
-	return utils.AppendMetrics(response, time.Duration(executionTimeMs)*time.Millisecond, tokensUsed)
+	return utils.AppendRunMetrics(response, metrics)
+}
+
+// finalizeRunMetrics collects the completion footer metrics for a run:
+// tool calls are counted from its streamed log and the cost is estimated
+// from the model price table. UpdateIncidentComplete derives the stored
+// tool_calls / estimated_cost_usd columns the same way.
+func finalizeRunMetrics(streamedLog string, executionTimeMs int64, tokensUsed int) utils.RunMetrics {
+	metrics := utils.RunMetrics{
+		ExecutionTime: time.Duration(executionTimeMs) * time.Millisecond,
+		TokensUsed:    tokensUsed,
+		ToolCalls:     utils.CountToolCalls(streamedLog),
+	}
+	if cost, ok := database.EstimateCostUSD(tokensUsed); ok {
+		metrics.CostUSD = &cost
+	}
+	return metrics
 }
 
 // finalizeSlackMessageBody compresses the agent's final response into a
@@ -66,7 +81,9 @@ func finalizeSlackMessageBody(ctx context.Context, summarizer *services.SlackSum
 			return summary + footer
 		}
 	}
-	formatted := output.FormatForSlack(output.Parse(contentOnly))
+	parsed := output.Parse(contentOnly)
+	parsed.StatusLabels = database.LoadVocabulary().StatusLabels()
+	formatted := output.FormatForSlack(parsed)
 	return truncateWithFooter(formatted, footer, slackMaxTextBytes)
 }
 
@@ -75,14 +92,24 @@ func finalizeSlackMessageBody(ctx context.Context, summarizer *services.SlackSum
 // unknown channels or an unwired channel service yield "" so only
 // wildcard-channel rules can match.
 func (h *SlackHandler) channelUUIDForExternalID(externalID string) string {
+	if ch := h.channelForExternalID(externalID); ch != nil {
+		return ch.UUID
+	}
+	return ""
+}
+
+// channelForExternalID returns the Channel row for a Slack channel ID, or nil
+// when it is not registered. A nil channel resolves to the default reactions
+// and progress verbosity.
+func (h *SlackHandler) channelForExternalID(externalID string) *database.Channel {
 	if h.channelService == nil {
-		return ""
+		return nil
 	}
 	ch, err := h.channelService.FindByExternalID(database.MessagingProviderSlack, externalID)
-	if err != nil || ch == nil {
-		return ""
+	if err != nil {
+		return nil
 	}
-	return ch.UUID
+	return ch
 }
 
 // processMessage is the core message processing logic
@@ -104,6 +131,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 	var sessionID string
 	var incidentUUID string
 	var workingDir string
+	spawned := false
 
 	// Check if this is an existing incident (continuation) by looking up in database.
 	// First try by source="slack" (DM-originated incidents), then fall back to
@@ -115,7 +143,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 		incidentUUID = incident.UUID
 		// WorkingDir is stored in DB but session already knows its path from creation
 		_ = incident.WorkingDir
-		slog.Info("resuming session for thread", "session_id", sessionID, "thread_id", threadID, "incident_id", incidentUUID)
+		slog.Info("resuming session for thread", "session_id", sessionID, "thread_id", threadID, "incident_uuid", incidentUUID)
 	} else if threadTS != "" {
 		// Try to find an alert channel incident by slack_message_ts
 		// (when user replies to an alert thread with @Akmatori)
@@ -124,7 +152,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 			incidentUUID = incident.UUID
 			// WorkingDir is stored in DB but session already knows its path from creation
 			_ = incident.WorkingDir
-			slog.Info("resuming alert channel session for thread", "session_id", sessionID, "thread_id", threadID, "incident_id", incidentUUID)
+			slog.Info("resuming alert channel session for thread", "session_id", sessionID, "thread_id", threadID, "incident_uuid", incidentUUID)
 		}
 	}
 
@@ -160,7 +188,25 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 			return
 		}
 
-		slog.Info("spawned incident manager", "incident_id", incidentUUID, "working_dir", workingDir)
+		slog.Info("spawned incident manager", "incident_uuid", incidentUUID, "working_dir", workingDir)
+		spawned = true
+	}
+
+	// Over budget, a new thread's incident is closed as budget_exceeded; a
+	// follow-up in an existing thread is refused and the incident left as is.
+	if h.budget != nil && incidentUUID != "" {
+		var note string
+		if spawned {
+			note = declineOverBudget(h.budget, h.skillService, incidentUUID)
+		} else if err := h.budget.CheckBudget(context.Background()); err != nil {
+			note = services.BudgetDeclineNote(err)
+		}
+		if note != "" {
+			if _, _, err := h.client.PostMessage(channel, slack.MsgOptionText(note, false), slack.MsgOptionTS(threadID)); err != nil {
+				slog.Error("failed to post budget notice to Slack", "err", err)
+			}
+			return
+		}
 	}
 
 	// Update incident status to "running" before execution
@@ -175,12 +221,11 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 	// controller owns both signals' lifecycle; defer Stop covers all exit
 	// paths (success, error, supersede) since the handler blocks on <-done
 	// before returning.
-	typing := slackutil.NewTypingController(slackutil.TypingControllerConfig{
-		Client:      h.client,
-		ChannelID:   channel,
-		ThreadTS:    threadID,
-		ReactionRef: slack.ItemRef{Channel: channel, Timestamp: threadID},
-	})
+	//
+	// The reaction set and progress verbosity come from the channel's
+	// Slack settings when the channel is registered.
+	channelRow := h.channelForExternalID(channel)
+	typing := newSlackTypingController(h.client, channel, threadID, channelRow.EffectiveSlackReactions())
 	typing.Start(context.Background())
 	defer typing.Stop()
 
@@ -192,13 +237,18 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 	// "Thinking..." placeholder message — the typing banner + reaction
 	// are the activity signal; the final result is posted as a fresh
 	// thread reply when the agent finishes.
-	progressStreamer := NewSlackProgressStreamer(typing.UpdateLoadingMessage, slackAppendInterval)
+	progressStreamer := newChannelProgressStreamer(channelRow.EffectiveProgressVerbosity(), typing.UpdateLoadingMessage,
+		func(line string) {
+			if _, _, err := h.client.PostMessage(channel, slack.MsgOptionText(line, false), slack.MsgOptionTS(threadID)); err != nil {
+				slog.Warn("failed to post progress update", "err", err)
+			}
+		})
 
 	taskWithGuidance := executor.PrependGuidance(text)
 
 	// Execute via WebSocket-based agent worker
-	if h.agentWSHandler != nil && h.agentWSHandler.IsWorkerConnected() {
-		slog.Info("using WebSocket-based agent worker", "incident_id", incidentUUID)
+	if h.agentWSHandler != nil && h.agentWSHandler.WaitForWorker(context.Background()) {
+		slog.Info("using WebSocket-based agent worker", "incident_uuid", incidentUUID)
 
 		// Fetch LLM settings from database
 		var llmSettings *LLMSettingsForWorker
@@ -269,7 +319,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 		// Always start a fresh agent session — resuming stale sessions causes
 		// "timeout waiting for child process to exit" errors when the original
 		// agent process is no longer running.
-		slog.Info("starting new agent session for incident", "incident_id", incidentUUID)
+		slog.Info("starting new agent session for incident", "incident_uuid", incidentUUID)
 		runID, wsErr := h.agentWSHandler.StartIncident(incidentUUID, taskWithGuidance, llmSettings, h.skillService.GetEnabledSkillNames(), h.skillService.GetToolAllowlist(), callback)
 		if wsErr != nil {
 			slog.Error("failed to start/continue incident via WebSocket", "err", wsErr)
@@ -290,7 +340,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 		// Exit silently so we do not race the replacement with a failure
 		// update.
 		if superseded.Load() {
-			slog.Info("slack run superseded; leaving finalization to the new run", "incident_id", incidentUUID)
+			slog.Info("slack run superseded; leaving finalization to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
@@ -310,8 +360,9 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 		// Tokens). The deterministic footer lands at the end of the
 		// stored DB response and the Slack final-message body, where
 		// buildSlackFooter extracts it for the trailing metrics line.
-		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, finalExecutionTimeMs, finalTokensUsed, hasError)
-		rawWithMetrics := appendFinalizeMetrics(response, finalExecutionTimeMs, finalTokensUsed, hasError)
+		runMetrics := finalizeRunMetrics(lastStreamedLog, finalExecutionTimeMs, finalTokensUsed)
+		formattedWithMetrics := appendFinalizeMetrics(formattedResponse, runMetrics, hasError)
+		rawWithMetrics := appendFinalizeMetrics(response, runMetrics, hasError)
 
 		// Build full log using the raw response (with metrics) so
 		// `full_log` always preserves the original agent output for
@@ -357,7 +408,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 		// + hourglass.
 		if !h.agentWSHandler.ReleaseRun(incidentUUID, runID) {
 			typing.Discard()
-			slog.Info("slack run displaced during finalization; leaving DB + Slack post to the new run", "incident_id", incidentUUID)
+			slog.Info("slack run displaced during finalization; leaving DB + Slack post to the new run", "incident_uuid", incidentUUID)
 			return
 		}
 
@@ -367,7 +418,7 @@ func (h *SlackHandler) processMessage(channel, threadTS, messageTS, text, user s
 	}
 
 	// No WebSocket worker available
-	slog.Error("agent worker not connected", "incident_id", incidentUUID)
+	slog.Error("agent worker not connected", "incident_uuid", incidentUUID)
 	errMsg := "❌ Agent worker not connected. Please check that the agent-worker container is running."
 	h.finishSlackMessage(channel, threadID, incidentUUID, user, text,
 		errMsg, errMsg, "", true, "", 0, 0)
@@ -384,19 +435,12 @@ func (h *SlackHandler) finishSlackMessage(channel, threadID, incidentUUID, user,
 	// success/error reaction.
 
 	// Add result reaction
-	if hasError {
-		if addErr := h.client.AddReaction("x", slack.ItemRef{
-			Channel:   channel,
-			Timestamp: threadID,
-		}); addErr != nil {
-			slog.Warn("failed to add error reaction", "err", addErr)
-		}
-	} else {
-		if addErr := h.client.AddReaction("white_check_mark", slack.ItemRef{
+	if reaction := resultReaction(h.channelForExternalID(channel).EffectiveSlackReactions(), hasError); reaction != "" {
+		if addErr := h.client.AddReaction(reaction, slack.ItemRef{
 			Channel:   channel,
 			Timestamp: threadID,
 		}); addErr != nil {
-			slog.Warn("failed to add success reaction", "err", addErr)
+			slog.Warn("failed to add result reaction", "err", addErr)
 		}
 	}
 
@@ -417,7 +461,7 @@ func (h *SlackHandler) finishSlackMessage(channel, threadID, incidentUUID, user,
 		if updateErr := h.skillService.UpdateIncidentComplete(incidentUUID, finalStatus, sessionID, fullLogWithContext, dbResponse, tokensUsed, executionTimeMs); updateErr != nil {
 			slog.Warn("failed to update incident", "err", updateErr)
 		} else {
-			slog.Info("updated incident", "incident_id", incidentUUID, "status", finalStatus, "session_id", sessionID)
+			slog.Info("updated incident", "incident_uuid", incidentUUID, "status", finalStatus, "session_id", sessionID)
 		}
 	}
 
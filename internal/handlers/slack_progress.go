@@ -4,6 +4,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+	"github.com/slack-go/slack"
 )
 
 // slackThinkingMaxLen is the per-line cap applied to thinking snippets.
@@ -11,6 +15,58 @@ import (
 // should be short for UX reasons.
 const slackThinkingMaxLen = 500
 
+// slackThreadProgressInterval is the minimum gap between progress replies
+// posted into a thread by channels using database.SlackProgressThread. The
+// banner keeps updating at slackAppendInterval in between.
+const slackThreadProgressInterval = 30 * time.Second
+
+// newSlackTypingController builds the typing controller for a run, using
+// the channel's working reaction. A suppressed reaction leaves ReactionRef
+// empty so the controller only drives the status banner.
+func newSlackTypingController(client slackutil.TypingClient, channelID, threadTS string, reactions database.SlackReactions) slackutil.TypingController {
+	cfg := slackutil.TypingControllerConfig{
+		Client:    client,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Reaction:  reactions.Working,
+	}
+	if reactions.Working != "" {
+		cfg.ReactionRef = slack.ItemRef{Channel: channelID, Timestamp: threadTS}
+	}
+	return slackutil.NewTypingController(cfg)
+}
+
+// newChannelProgressStreamer returns the progress streamer for a run in a
+// channel with the given verbosity, or nil (a safe no-op streamer) when
+// progress is switched off. banner receives every throttled reasoning line;
+// post additionally receives at most one line per
+// slackThreadProgressInterval when the channel asks for thread replies.
+func newChannelProgressStreamer(verbosity database.SlackProgressVerbosity, banner, post func(line string)) *SlackProgressStreamer {
+	switch verbosity {
+	case database.SlackProgressOff:
+		return nil
+	case database.SlackProgressThread:
+		if post == nil {
+			break
+		}
+		var mu sync.Mutex
+		var lastPost time.Time
+		return NewSlackProgressStreamer(func(line string) {
+			banner(line)
+			mu.Lock()
+			due := lastPost.IsZero() || time.Since(lastPost) >= slackThreadProgressInterval
+			if due {
+				lastPost = time.Now()
+			}
+			mu.Unlock()
+			if due {
+				post(line)
+			}
+		}, slackAppendInterval)
+	}
+	return NewSlackProgressStreamer(banner, slackAppendInterval)
+}
+
 // SlackProgressStreamer condenses agent OnOutput deltas into a single status
 // line and forwards the latest reasoning (🤔) line to a sink callback subject
 // to a throttle window. Tool start/end markers are intentionally dropped —
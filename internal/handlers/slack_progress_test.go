@@ -4,6 +4,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
 )
 
 // captureSink is a minimal sink the streamer pushes lines into. It records
@@ -232,3 +234,37 @@ func TestSlackProgressStreamer_Flush_EmitsBufferedTrailingStatus(t *testing.T) {
 		t.Errorf("Flush should emit buffered status, got %+v", got)
 	}
 }
+
+// --- newChannelProgressStreamer ---------------------------------------------
+
+func TestNewChannelProgressStreamer_Off(t *testing.T) {
+	if s := newChannelProgressStreamer(database.SlackProgressOff, func(string) {}, func(string) {}); s != nil {
+		t.Fatalf("off verbosity returned %+v, want nil streamer", s)
+	}
+}
+
+func TestNewChannelProgressStreamer_ThreadPostsAreThrottled(t *testing.T) {
+	banner, posts := &captureSink{}, &captureSink{}
+	s := newChannelProgressStreamer(database.SlackProgressThread, banner.sink, posts.sink)
+
+	s.AppendStatus("🤔 checking disk usage\n")
+	expireThrottle(s)
+	s.AppendStatus("🤔 reading syslog\n")
+
+	if got := banner.snapshot(); len(got) != 2 {
+		t.Errorf("banner lines = %v, want both reasoning lines", got)
+	}
+	if got := posts.snapshot(); len(got) != 1 || got[0] != "🤔 checking disk usage" {
+		t.Errorf("thread posts = %v, want only the first line inside the post interval", got)
+	}
+}
+
+func TestNewChannelProgressStreamer_StatusNeverPosts(t *testing.T) {
+	banner, posts := &captureSink{}, &captureSink{}
+	s := newChannelProgressStreamer("", banner.sink, posts.sink)
+	s.AppendStatus("🤔 checking disk usage\n")
+
+	if len(banner.snapshot()) != 1 || len(posts.snapshot()) != 0 {
+		t.Errorf("banner = %v posts = %v, want banner only", banner.snapshot(), posts.snapshot())
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	"github.com/slack-go/slack"
+)
+
+// Block Kit action IDs on remediation plan messages. The button value is the
+// incident UUID.
+const (
+	planApproveActionID = "remediation_plan_approve"
+	planRejectActionID  = "remediation_plan_reject"
+)
+
+// maxPlanBytes caps the plan shown in the Slack message, leaving room in
+// the 3000-character section for the header. The full plan is in the UI.
+const maxPlanBytes = 2600
+
+// NotifyPlan implements services.PlanNotifier. Incidents without a Slack
+// thread are skipped; their plans are decided in the UI.
+func (n *SlackApprovalNotifier) NotifyPlan(ctx context.Context, plan *database.RemediationPlan, incident *database.Incident) (string, string, error) {
+	if incident.SlackChannelID == "" || incident.SlackMessageTS == "" {
+		return "", "", nil
+	}
+	client := n.slackManager.GetClient()
+	if client == nil {
+		return "", "", nil
+	}
+	blocks := append(planBlocks(plan),
+		slack.NewActionBlock("remediation_plan",
+			slack.NewButtonBlockElement(planApproveActionID, plan.IncidentUUID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Approve plan", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(planRejectActionID, plan.IncidentUUID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Reject", false, false)).WithStyle(slack.StyleDanger),
+		),
+	)
+	channelID, ts, err := client.PostMessageContext(ctx, incident.SlackChannelID,
+		slack.MsgOptionTS(incident.SlackMessageTS),
+		slack.MsgOptionText("Remediation plan awaiting approval", false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("post remediation plan: %w", err)
+	}
+	return channelID, ts, nil
+}
+
+// ResolvePlan implements services.PlanNotifier by replacing the buttons
+// with the decision.
+func (n *SlackApprovalNotifier) ResolvePlan(ctx context.Context, plan *database.RemediationPlan) error {
+	client := n.slackManager.GetClient()
+	if client == nil {
+		return errors.New("slack client not available")
+	}
+	outcome := fmt.Sprintf(":no_entry: Rejected by %s — nothing was changed", plan.DecidedBy)
+	if plan.Status == database.RemediationPlanApproved {
+		outcome = fmt.Sprintf(":white_check_mark: Approved by %s — the agent is carrying out the plan", plan.DecidedBy)
+	}
+	if plan.Reason != "" {
+		outcome += ": " + plan.Reason
+	}
+	blocks := append(planBlocks(plan),
+		slack.NewContextBlock("remediation_plan_outcome", slack.NewTextBlockObject(slack.MarkdownType, outcome, false, false)),
+	)
+	_, _, _, err := client.UpdateMessageContext(ctx, plan.SlackChannelID, plan.SlackMessageTS,
+		slack.MsgOptionText("Remediation plan "+string(plan.Status), false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	return err
+}
+
+func planBlocks(plan *database.RemediationPlan) []slack.Block {
+	text := ":clipboard: *Remediation plan*\n" + truncateForSlack(plan.Plan, maxPlanBytes)
+	if plan.Status == database.RemediationPlanPending {
+		text += "\n\nNothing has been changed yet. Approve to have the agent carry out this plan."
+	}
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+	}
+}
+
+// SetRemediationPlanManager wires the service Approve/Reject clicks on
+// remediation plans are recorded through. Optional — when unset, clicks are
+// acknowledged and ignored.
+func (h *SlackHandler) SetRemediationPlanManager(m services.RemediationPlanManager) {
+	h.plans = m
+}
+
+func (h *SlackHandler) decidePlanFromSlack(callback slack.InteractionCallback, incidentUUID string, approve bool) {
+	if h.plans == nil {
+		return
+	}
+	_, err := h.plans.DecidePlan(context.Background(), incidentUUID, approve, slackDecider(callback), "")
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrPlanNotPending):
+		h.postEphemeralApprovalNotice(callback, "This plan was already decided.")
+	case errors.Is(err, services.ErrIncidentNotFinished):
+		h.postEphemeralApprovalNotice(callback, "The agent is still working on this incident; try again when it finishes.")
+	case errors.Is(err, services.ErrWorkerNotConnected):
+		h.postEphemeralApprovalNotice(callback, "The agent worker is not connected, so the plan cannot run yet. Try again later.")
+	default:
+		slog.Error("remediation plan: failed to record Slack decision", "incident_uuid", incidentUUID, "err", err)
+		h.postEphemeralApprovalNotice(callback, "Could not record your decision; try again or use the web UI.")
+	}
+}
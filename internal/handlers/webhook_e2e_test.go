@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts/adapters"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/services"
+	slackutil "github.com/akmatori/akmatori/internal/slack"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+	"gorm.io/gorm"
+)
+
+// e2eTimeout bounds each asynchronous step of a webhook flow.
+const e2eTimeout = 10 * time.Second
+
+// webhookE2E is the API side of a webhook flow wired the way main.go wires
+// it, against stub Slack and LLM servers and a fake agent worker.
+type webhookE2E struct {
+	db     *gorm.DB
+	server *httptest.Server
+	slack  *testhelpers.SlackServer
+	llm    *testhelpers.OpenAIServer
+	worker *testhelpers.FakeAgentWorker
+}
+
+func newWebhookE2E(t *testing.T) *webhookE2E {
+	t.Helper()
+	db := testhelpers.NewGlobalSQLiteDB(t,
+		&database.SlackSettings{}, &database.LLMSettings{}, &database.ProxySettings{},
+		&database.Integration{}, &database.Channel{},
+		&database.AlertSourceType{}, &database.AlertSourceInstance{},
+		&database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{}, &database.Skill{},
+		&database.TokenUsage{}, &database.IncidentChange{},
+	)
+	e := &webhookE2E{
+		db:    db,
+		slack: testhelpers.NewSlackServer(t),
+		llm:   testhelpers.NewOpenAIServer(t).WithReply("Root cause: disk filled by rotated logs. Cleared them."),
+	}
+
+	db.Create(&database.LLMSettings{Name: "stub", Provider: database.LLMProviderOpenAI, APIKey: "sk-e2e", Model: "gpt-e2e",
+		BaseURL: e.llm.BaseURL(), Enabled: true, Active: true})
+	integration := database.Integration{UUID: "int-slack", Provider: database.MessagingProviderSlack, Name: "Slack", Enabled: true,
+		Credentials: database.JSONB{"bot_token": "xoxb-e2e", "signing_secret": "signing", "app_token": "xapp-e2e"}}
+	db.Create(&integration)
+	db.Create(&database.Channel{UUID: "ch-alerts", IntegrationID: integration.ID, ExternalID: "C0ALERTS",
+		CanPost: true, IsDefaultPost: true, Enabled: true})
+
+	slackManager := slackutil.NewManager()
+	slackManager.SetSocketModeEnabled(false)
+	slackManager.SetAPIURL(e.slack.APIURL())
+	if err := slackManager.Start(context.Background()); err != nil {
+		t.Fatalf("start Slack manager: %v", err)
+	}
+	t.Cleanup(slackManager.Stop)
+
+	agentWS := NewAgentWSHandler()
+	alertHandler := NewAlertHandler(nil, slackManager, nil, agentWS, services.NewSkillService(t.TempDir(), nil, nil, nil), services.NewAlertService(), nil)
+	alertHandler.SetChannelService(services.NewChannelService())
+	alertHandler.RegisterAdapter(adapters.NewAlertmanagerAdapter())
+	alertHandler.RegisterAdapter(adapters.NewGrafanaAdapter())
+	alertHandler.RegisterAdapter(adapters.NewDatadogAdapter())
+	alertHandler.RegisterAdapter(adapters.NewPagerDutyAdapter())
+	alertHandler.RegisterAdapter(adapters.NewSentryAdapter())
+	alertHandler.RegisterAdapter(adapters.NewVictorOpsAdapter())
+	alertHandler.RegisterAdapter(adapters.NewZabbixAdapter())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook/alert/{uuid}", alertHandler.HandleWebhook)
+	mux.HandleFunc("/ws/agent", agentWS.HandleWebSocket)
+	e.server = httptest.NewServer(mux)
+	t.Cleanup(e.server.Close)
+
+	e.worker = testhelpers.DialAgentWorker(t, "ws"+strings.TrimPrefix(e.server.URL, "http")+"/ws/agent")
+	testhelpers.AssertEventually(t, e2eTimeout, 10*time.Millisecond, func() bool { return agentWS.WorkerCount() == 1 }, "fake agent worker registers")
+	return e
+}
+
+// addSource creates an enabled alert source of sourceType and returns its
+// UUID.
+func (e *webhookE2E) addSource(t *testing.T, sourceType string) string {
+	t.Helper()
+	typ := database.AlertSourceType{Name: sourceType, DisplayName: sourceType}
+	if err := e.db.Create(&typ).Error; err != nil {
+		t.Fatalf("create source type: %v", err)
+	}
+	instance := database.AlertSourceInstance{UUID: "src-" + sourceType, AlertSourceTypeID: typ.ID, Name: sourceType + " e2e", Enabled: true}
+	if err := e.db.Create(&instance).Error; err != nil {
+		t.Fatalf("create source instance: %v", err)
+	}
+	return instance.UUID
+}
+
+// post sends payload to the source's webhook and checks it was accepted.
+func (e *webhookE2E) post(t *testing.T, sourceUUID string, payload []byte) {
+	t.Helper()
+	resp, err := http.Post(e.server.URL+"/webhook/alert/"+sourceUUID, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("post webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("webhook status = %d, want 200", resp.StatusCode)
+	}
+}
+
+// waitForIncident waits until the source's incident reaches a final status
+// and returns it.
+func (e *webhookE2E) waitForIncident(t *testing.T, sourceUUID string) database.Incident {
+	t.Helper()
+	var incident database.Incident
+	testhelpers.AssertEventually(t, e2eTimeout, 20*time.Millisecond, func() bool {
+		err := e.db.Where("source_uuid = ? AND status IN ?", sourceUUID,
+			[]database.IncidentStatus{database.IncidentStatusCompleted, database.IncidentStatusFailed}).
+			First(&incident).Error
+		return err == nil
+	}, "incident finishes")
+	if incident.UUID == "" {
+		t.FailNow()
+	}
+	return incident
+}
+
+// TestWebhookE2E_EveryAdapter drives each adapter's sample payload from the
+// webhook through incident creation, the (fake) agent run and the Slack
+// notifications.
+func TestWebhookE2E_EveryAdapter(t *testing.T) {
+	tests := []struct {
+		sourceType string
+		fixture    string
+		alertName  string
+		host       string
+	}{
+		{"alertmanager", "alertmanager_firing", "HighMemoryUsage", ""},
+		{"grafana", "grafana_alerting", "", ""},
+		{"datadog", "datadog_monitor", "", ""},
+		{"pagerduty", "pagerduty_trigger", "Database Connection Pool Exhausted", "db-primary.example.com"},
+		{"sentry", "sentry_alert", "ConnectionError: Unable to connect to upstream service", "api-server-01"},
+		{"victorops", "victorops_alert", "", "db-01"},
+		{"zabbix", "zabbix_problem", "CPU Load High", "db-server-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sourceType, func(t *testing.T) {
+			e := newWebhookE2E(t)
+			sourceUUID := e.addSource(t, tt.sourceType)
+
+			e.post(t, sourceUUID, testhelpers.LoadFixture(t, "alerts/"+tt.fixture+".json"))
+			incident := e.waitForIncident(t, sourceUUID)
+
+			if incident.Status != database.IncidentStatusCompleted {
+				t.Fatalf("incident status = %s, response %q", incident.Status, incident.Response)
+			}
+			if !strings.Contains(incident.Response, "disk filled by rotated logs") {
+				t.Errorf("incident response = %q, want the LLM's answer", incident.Response)
+			}
+
+			var alert database.Alert
+			if err := e.db.Where("incident_uuid = ?", incident.UUID).First(&alert).Error; err != nil {
+				t.Fatalf("alert row: %v", err)
+			}
+			if tt.alertName != "" && alert.AlertName != tt.alertName {
+				t.Errorf("alert name = %q, want %q", alert.AlertName, tt.alertName)
+			}
+			if tt.host != "" && alert.TargetHost != tt.host {
+				t.Errorf("target host = %q, want %q", alert.TargetHost, tt.host)
+			}
+
+			// The worker got the alert with the active LLM settings, and
+			// answered through the configured provider.
+			task := e.worker.WaitForTask(t, incident.UUID, e2eTimeout)
+			if !strings.Contains(task.Prompt(), alert.AlertName) || task.Model != "gpt-e2e" {
+				t.Errorf("task = %+v, want the alert and the active model", task)
+			}
+			if reqs := e.llm.Requests(); len(reqs) == 0 || reqs[0].Authorization != "Bearer sk-e2e" {
+				t.Errorf("LLM requests = %+v, want one authenticated with the configured key", reqs)
+			}
+
+			// Slack: the alert lands in the default channel, the result in
+			// its thread.
+			posted := e.slack.WaitForMessage(t, alert.AlertName, e2eTimeout)
+			if posted.Params.Get("channel") != "C0ALERTS" {
+				t.Errorf("alert posted to %q, want C0ALERTS", posted.Params.Get("channel"))
+			}
+			result := e.slack.WaitForMessage(t, "disk filled by rotated logs", e2eTimeout)
+			if result.Params.Get("thread_ts") == "" || result.Params.Get("thread_ts") != incident.SlackMessageTS {
+				t.Errorf("result thread_ts = %q, want the alert message %q", result.Params.Get("thread_ts"), incident.SlackMessageTS)
+			}
+		})
+	}
+}
+
+// TestWebhookE2E_FailedInvestigation covers an agent error: the incident
+// fails and the error is posted in the alert's thread.
+func TestWebhookE2E_FailedInvestigation(t *testing.T) {
+	e := newWebhookE2E(t)
+	e.worker.WithFailure("model refused the request")
+	sourceUUID := e.addSource(t, "zabbix")
+
+	e.post(t, sourceUUID, testhelpers.LoadFixture(t, "alerts/zabbix_problem.json"))
+	incident := e.waitForIncident(t, sourceUUID)
+
+	if incident.Status != database.IncidentStatusFailed {
+		t.Fatalf("incident status = %s, want failed", incident.Status)
+	}
+	reply := e.slack.WaitForMessage(t, "model refused the request", e2eTimeout)
+	if reply.Params.Get("thread_ts") == "" {
+		t.Errorf("error reply %+v is not in the alert thread", reply)
+	}
+	if len(e.slack.Calls("reactions.add")) == 0 {
+		t.Error("expected a result reaction on the alert message")
+	}
+}
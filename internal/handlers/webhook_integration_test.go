@@ -345,7 +345,7 @@ func TestWebhookFlow_SecretValidation(t *testing.T) {
 	}{
 		{"alertmanager", adapters.NewAlertmanagerAdapter(), "X-Alertmanager-Secret", "am-secret", true},
 		{"grafana", adapters.NewGrafanaAdapter(), "X-Grafana-Secret", "grafana-secret", true},
-		{"datadog", adapters.NewDatadogAdapter(), "X-Datadog-Signature", "dd-secret", true},
+		{"datadog", adapters.NewDatadogAdapter(), "X-Datadog-Secret", "dd-secret", true},
 		{"zabbix", adapters.NewZabbixAdapter(), "X-Zabbix-Secret", "zabbix-secret", false}, // Zabbix doesn't support bearer
 	}
 
@@ -438,13 +438,12 @@ func TestAlertHandler_WebhookEndpoint_HTTPFlow(t *testing.T) {
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
 		{
-			name:           "empty UUID returns bad request",
+			name:           "empty UUID is not routed",
 			method:         http.MethodPost,
 			path:           "/webhook/alert/",
 			body:           "{}",
 			contentType:    "application/json",
-			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Missing instance UUID",
+			expectedStatus: http.StatusNotFound,
 		},
 	}
 
@@ -463,7 +462,7 @@ func TestAlertHandler_WebhookEndpoint_HTTPFlow(t *testing.T) {
 			}
 			w := httptest.NewRecorder()
 
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 
 			testhelpers.AssertEqual(t, tt.expectedStatus, w.Code, "status code")
 			if tt.expectedBody != "" {
@@ -515,7 +514,7 @@ func TestWebhookFlow_ConcurrentMethodValidation(t *testing.T) {
 			// Use GET which should return method not allowed
 			req := httptest.NewRequest(http.MethodGet, "/webhook/alert/test-uuid", nil)
 			w := httptest.NewRecorder()
-			h.HandleWebhook(w, req)
+			serveWebhook(h, w, req)
 			if w.Code != http.StatusMethodNotAllowed {
 				t.Errorf("expected 405, got %d", w.Code)
 			}
@@ -1,15 +1,101 @@
+// Package logging configures the process-wide slog logger. Log lines about
+// an incident carry its UUID under the incident_uuid key and lines logged
+// while serving an API request carry request_id, so both can be followed
+// across services once shipped to a log store.
 package logging
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
 // Init initializes structured logging with slog as the default logger.
-// Output is JSON to stdout for container-friendly log aggregation.
+// Output is JSON to stdout for container-friendly log aggregation; set
+// LOG_FORMAT=text for human-readable lines. LOG_LEVEL (debug, info, warn or
+// error) sets the minimum level and defaults to info.
 func Init() {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})
-	slog.SetDefault(slog.New(handler))
+	level, levelErr := ParseLevel(os.Getenv("LOG_LEVEL"))
+	format := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT")))
+	slog.SetDefault(slog.New(NewHandler(os.Stdout, level, format)))
+	if levelErr != nil {
+		slog.Warn("ignoring LOG_LEVEL", "err", levelErr)
+	}
+	if format != "" && format != "json" && format != "text" {
+		slog.Warn("ignoring LOG_FORMAT, expected json or text", "log_format", format)
+	}
+}
+
+// NewHandler returns the handler Init installs: JSON, or text when format is
+// "text", at the given minimum level, adding the attributes carried by the
+// context of each record (see WithAttrs).
+func NewHandler(w io.Writer, level slog.Level, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return &contextHandler{Handler: h}
+}
+
+// ParseLevel parses a LOG_LEVEL value. Empty means info; on error the
+// returned level is info too.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+type contextKey struct{}
+
+// WithAttrs returns ctx carrying attrs in addition to those it already
+// carries. Records logged with the context (slog.InfoContext and friends)
+// include them, so everything logged on behalf of one request or incident
+// can be found by a single field.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	merged = append(append(merged, prev...), attrs...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// WithRequestID returns ctx whose records carry request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return WithAttrs(ctx, slog.String("request_id", id))
+}
+
+// contextHandler adds the attributes carried by a record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
 }
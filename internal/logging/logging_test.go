@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "DEBUG": slog.LevelDebug, "warning": slog.LevelWarn, " error ": slog.LevelError} {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if got, err := ParseLevel("verbose"); err == nil || got != slog.LevelInfo {
+		t.Errorf("ParseLevel(verbose) = %v, %v; want info and an error", got, err)
+	}
+}
+
+func TestHandler_AddsContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, slog.LevelInfo, "json"))
+
+	ctx := WithAttrs(WithRequestID(context.Background(), "req-1"), slog.String("incident_uuid", "inc-1"))
+	logger.InfoContext(ctx, "hello")
+	logger.Debug("hidden")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	if rec["msg"] != "hello" || rec["request_id"] != "req-1" || rec["incident_uuid"] != "inc-1" {
+		t.Errorf("record = %v", rec)
+	}
+}
@@ -0,0 +1,230 @@
+// Package metrics holds the Prometheus collectors exported on /metrics.
+//
+// Collectors live in a dedicated registry rather than the global default so
+// tests and library code cannot leak series into the endpoint.
+package metrics
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "akmatori"
+
+// Registry is the registry served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	webhookAlerts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_alerts_received_total",
+		Help:      "Alerts parsed from webhook payloads, by alert source type.",
+	}, []string{"source_type"})
+
+	webhookPayloadErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_payload_errors_total",
+		Help:      "Webhook payloads that could not be parsed, by alert source type.",
+	}, []string{"source_type"})
+
+	incidentsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "incidents_created_total",
+		Help:      "Incidents created, by trigger kind (alert, cron, slack_mention, manual, ...).",
+	}, []string{"source_kind"})
+
+	incidentsFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "incidents_finished_total",
+		Help:      "Investigations that reached a terminal state, by status (completed or failed).",
+	}, []string{"status"})
+
+	investigationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "investigation_duration_seconds",
+		Help:      "Agent execution time of finished investigations.",
+		Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"status"})
+
+	investigationTokens = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "investigation_tokens_total",
+		Help:      "LLM tokens used by finished investigations.",
+	})
+
+	workerConnections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "worker_connections_total",
+		Help:      "Agent worker WebSocket connections accepted.",
+	})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		webhookAlerts,
+		webhookPayloadErrors,
+		incidentsCreated,
+		incidentsFinished,
+		investigationDuration,
+		investigationTokens,
+		workerConnections,
+		httpRequestDuration,
+	)
+}
+
+// WebhookAlertsReceived counts n alerts parsed from one sourceType payload.
+func WebhookAlertsReceived(sourceType string, n int) {
+	webhookAlerts.WithLabelValues(sourceType).Add(float64(n))
+}
+
+// WebhookPayloadError counts a sourceType payload that failed to parse.
+func WebhookPayloadError(sourceType string) {
+	webhookPayloadErrors.WithLabelValues(sourceType).Inc()
+}
+
+// IncidentCreated counts a new incident of the given trigger kind.
+func IncidentCreated(sourceKind string) {
+	if sourceKind == "" {
+		sourceKind = "unknown"
+	}
+	incidentsCreated.WithLabelValues(sourceKind).Inc()
+}
+
+// IncidentFinished counts an investigation ending in status. A positive
+// executionTimeMs and tokensUsed are recorded too; callers that fail before
+// the agent ran pass zero.
+func IncidentFinished(status string, executionTimeMs int64, tokensUsed int) {
+	incidentsFinished.WithLabelValues(status).Inc()
+	if executionTimeMs > 0 {
+		investigationDuration.WithLabelValues(status).Observe(float64(executionTimeMs) / 1000)
+	}
+	if tokensUsed > 0 {
+		investigationTokens.Add(float64(tokensUsed))
+	}
+}
+
+// WorkerConnected counts an accepted agent worker connection.
+func WorkerConnected() {
+	workerConnections.Inc()
+}
+
+// RegisterWorkerGauge exports akmatori_worker_connected, read from connected
+// at scrape time.
+func RegisterWorkerGauge(connected func() bool) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_connected",
+		Help:      "1 when an agent worker is connected over WebSocket, else 0.",
+	}, func() float64 {
+		if connected() {
+			return 1
+		}
+		return 0
+	}))
+}
+
+// RegisterWorkerCountGauge exports akmatori_workers_connected, the size of
+// the agent worker pool, read from count at scrape time.
+func RegisterWorkerCountGauge(count func() int) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers_connected",
+		Help:      "Number of agent workers connected over WebSocket.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+// Handler serves the registry in the Prometheus text format. With a
+// non-empty token, scrapes must send it as a bearer token.
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// InstrumentHTTP records the latency of every request served by mux. The
+// route label is the matched ServeMux pattern, so path parameters do not
+// blow up cardinality; unmatched paths share one series. Each request is
+// also logged at debug level.
+func InstrumentHTTP(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+		httpRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Observe(elapsed.Seconds())
+		slog.DebugContext(r.Context(), "http request",
+			"method", r.Method, "route", route, "status", rec.status, "duration_ms", elapsed.Milliseconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(p)
+}
+
+// Flush lets SSE handlers stream through the recorder.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the agent worker's WebSocket upgrade through the recorder.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("metrics: %T does not support hijacking", s.ResponseWriter)
+	}
+	s.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandler_RequiresTokenWhenSet(t *testing.T) {
+	h := Handler("scrape-me")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-me")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "go_goroutines") {
+		t.Fatalf("with token: status = %d, body lacks runtime metrics", rec.Code)
+	}
+}
+
+func TestInstrumentHTTP_LabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/incidents/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	h := InstrumentHTTP(mux, mux)
+
+	for _, id := range []string{"a", "b", "c"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/incidents/"+id, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := map[string]uint64{}
+	for _, mf := range families {
+		if mf.GetName() != "akmatori_http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "route" {
+					routes[l.GetValue()] += m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	if routes["GET /api/incidents/{uuid}"] < 3 || routes["unmatched"] < 1 || len(routes) != 2 {
+		t.Errorf("routes = %v, want the pattern and unmatched only", routes)
+	}
+}
+
+func TestIncidentFinished(t *testing.T) {
+	before := testutil.ToFloat64(incidentsFinished.WithLabelValues("failed"))
+	tokensBefore := testutil.ToFloat64(investigationTokens)
+
+	IncidentFinished("failed", 0, 0)
+	IncidentFinished("failed", 42000, 1500)
+
+	if got := testutil.ToFloat64(incidentsFinished.WithLabelValues("failed")) - before; got != 2 {
+		t.Errorf("failed count delta = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(investigationTokens) - tokensBefore; got != 1500 {
+		t.Errorf("tokens delta = %v, want 1500", got)
+	}
+}
@@ -0,0 +1,344 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/secrets"
+)
+
+// maxAuditSnapshotBytes bounds the before/after bodies kept per change.
+// Larger snapshots are dropped; the change itself is still recorded.
+const maxAuditSnapshotBytes = 64 << 10
+
+// auditRedacted replaces secret values in stored snapshots and diffs.
+const auditRedacted = "[redacted]"
+
+// AuditRecorder appends entries to the audit log.
+type AuditRecorder interface {
+	Record(entry *database.AuditLog) error
+}
+
+// auditedResource maps an API path prefix to the entity type it changes.
+// The first matching prefix wins.
+type auditedResource struct {
+	prefix     string
+	entityType string
+}
+
+var auditedResources = []auditedResource{
+	{"/api/settings/llm", "llm_settings"},
+	{"/api/settings/", "settings"},
+	{"/api/integrations", "integration"},
+	{"/api/channels", "channel"},
+	{"/api/skills", "skill"},
+	{"/api/marketplace/skills/", "skill"},
+	{"/api/tools", "tool"},
+	{"/api/http-connectors", "http_connector"},
+	{"/api/mcp-servers", "mcp_server"},
+	{"/api/alert-sources", "alert_source"},
+}
+
+// ConfigAuditMiddleware records who changed which settings, skill, script or
+// tool. For every successful POST, PUT, PATCH or DELETE on an audited path it
+// snapshots the resource through its own GET endpoint before the change and
+// again afterwards (or keeps the response of a create), then stores both with
+// a field-level diff. GET responses already mask secrets; fields that look
+// like secrets are redacted again before storage.
+//
+// It must run inside JWTAuthMiddleware so the user is known.
+type ConfigAuditMiddleware struct {
+	recorder AuditRecorder
+}
+
+// NewConfigAuditMiddleware creates the middleware.
+func NewConfigAuditMiddleware(recorder AuditRecorder) *ConfigAuditMiddleware {
+	return &ConfigAuditMiddleware{recorder: recorder}
+}
+
+// Wrap wraps next with change auditing.
+func (m *ConfigAuditMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := auditAction(r.Method)
+		entityType, target, ok := auditedEntity(r.URL.Path)
+		if action == "" || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && target != "" {
+			// POST on an existing entity is an action (sync, install, ...).
+			action = database.AuditActionConfigUpdate
+		}
+
+		var before interface{}
+		if target != "" {
+			before = snapshot(next, r)
+		}
+
+		rec := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status < 200 || rec.status >= 300 {
+			return
+		}
+
+		var after interface{}
+		switch {
+		case r.Method == http.MethodDelete:
+		case target != "":
+			after = snapshot(next, r)
+			if after == nil {
+				// Action endpoints have no GET; keep their response.
+				after = decodeSnapshot(rec.body.Bytes(), rec.overflow)
+			}
+		default:
+			after = decodeSnapshot(rec.body.Bytes(), rec.overflow)
+			target = createdTarget(after)
+		}
+
+		details := database.JSONB{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"status": rec.status,
+		}
+		if id := GetRequestID(r.Context()); id != "" {
+			details["request_id"] = id
+		}
+		if changes := auditDiff(before, after); len(changes) > 0 {
+			details["changes"] = changes
+		}
+		if before != nil {
+			details["before"] = redactSecrets(before)
+		}
+		if after != nil {
+			details["after"] = redactSecrets(after)
+		}
+
+		entry := &database.AuditLog{
+			Action:     action,
+			Actor:      GetUserFromContext(r.Context()),
+			RemoteIP:   api.ClientIP(r),
+			Target:     target,
+			EntityType: entityType,
+			Details:    details,
+		}
+		if err := m.recorder.Record(entry); err != nil {
+			slog.Warn("failed to record config audit entry", "path", r.URL.Path, "err", err)
+		}
+	})
+}
+
+func auditAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return database.AuditActionConfigCreate
+	case http.MethodPut, http.MethodPatch:
+		return database.AuditActionConfigUpdate
+	case http.MethodDelete:
+		return database.AuditActionConfigDelete
+	}
+	return ""
+}
+
+// auditedEntity classifies path. target is the entity's key within its type
+// (name, UUID or ID), empty for a collection. Skill scripts are their own
+// type keyed "<skill>/<filename>".
+func auditedEntity(path string) (entityType, target string, ok bool) {
+	for _, res := range auditedResources {
+		rest, found := strings.CutPrefix(path, res.prefix)
+		if !found || (rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasSuffix(res.prefix, "/")) {
+			continue
+		}
+		parts := strings.Split(strings.Trim(rest, "/"), "/")
+		if parts[0] == "" {
+			return res.entityType, "", true
+		}
+		if res.entityType == "skill" && len(parts) == 3 && parts[1] == "scripts" {
+			return "skill_script", parts[0] + "/" + parts[2], true
+		}
+		return res.entityType, parts[0], true
+	}
+	return "", "", false
+}
+
+// snapshot fetches the resource at r's path through its GET endpoint. It
+// returns nil when the resource has no GET or it did not succeed.
+func snapshot(next http.Handler, r *http.Request) interface{} {
+	get, err := http.NewRequestWithContext(r.Context(), http.MethodGet, r.URL.Path, nil)
+	if err != nil {
+		return nil
+	}
+	get.Header = r.Header.Clone()
+	get.RemoteAddr = r.RemoteAddr
+	rec := &auditResponseWriter{ResponseWriter: discardResponseWriter{header: http.Header{}}, status: http.StatusOK}
+	next.ServeHTTP(rec, get)
+	if rec.status != http.StatusOK {
+		return nil
+	}
+	return decodeSnapshot(rec.body.Bytes(), rec.overflow)
+}
+
+func decodeSnapshot(body []byte, overflow bool) interface{} {
+	if overflow || len(body) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// createdTarget picks the key of a created entity from its response.
+func createdTarget(created interface{}) string {
+	obj, ok := created.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"uuid", "name", "id"} {
+		if v, ok := obj[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+// auditDiff lists the fields that differ between two snapshots, keyed by
+// dotted path. Arrays are compared whole.
+func auditDiff(before, after interface{}) []map[string]interface{} {
+	if before == nil && after == nil {
+		return nil
+	}
+	b, a := map[string]interface{}{}, map[string]interface{}{}
+	flattenSnapshot("", before, b)
+	flattenSnapshot("", after, a)
+
+	fields := make(map[string]bool, len(b)+len(a))
+	for k := range b {
+		fields[k] = true
+	}
+	for k := range a {
+		fields[k] = true
+	}
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		if !reflect.DeepEqual(b[k], a[k]) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		change := map[string]interface{}{"field": name, "before": redactSecrets(b[name]), "after": redactSecrets(a[name])}
+		if isSecretField(name) {
+			change["before"], change["after"] = auditRedacted, auditRedacted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func flattenSnapshot(prefix string, v interface{}, out map[string]interface{}) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		if v != nil {
+			out[prefix] = v
+		}
+		return
+	}
+	for k, child := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenSnapshot(key, child, out)
+	}
+}
+
+// redactSecrets returns a copy of v with secret-looking fields replaced.
+func redactSecrets(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			if isSecretField(k) && child != nil && child != "" {
+				out[k] = auditRedacted
+			} else {
+				out[k] = redactSecrets(child)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			out[i] = redactSecrets(child)
+		}
+		return out
+	}
+	return v
+}
+
+// secretValueFields are objects whose every value is a credential, such as
+// an MCP server's environment variables.
+var secretValueFields = map[string]bool{"env_vars": true}
+
+// isSecretField reports whether a (possibly dotted) field holds a secret,
+// using the same key names as encryption at rest (secrets.IsSecretKey).
+func isSecretField(name string) bool {
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		if secretValueFields[part] {
+			return true
+		}
+	}
+	last := parts[len(parts)-1]
+	return secretValueFields[last] || secrets.IsSecretKey(last)
+}
+
+// auditResponseWriter passes the response through while keeping the status
+// and the first maxAuditSnapshotBytes of the body.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if !w.overflow {
+		if w.body.Len()+len(p) > maxAuditSnapshotBytes {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// discardResponseWriter swallows snapshot responses.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header         { return d.header }
+func (d discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (d discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/api"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+type memoryAuditRecorder struct {
+	entries []*database.AuditLog
+}
+
+func (m *memoryAuditRecorder) Record(entry *database.AuditLog) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// newAuditedMux serves a settings document and a skills collection.
+func newAuditedMux() *http.ServeMux {
+	settings := map[string]interface{}{"timezone": "UTC", "api_key": "sk-...old", "proxy": map[string]interface{}{"url": ""}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/settings/general", func(w http.ResponseWriter, r *http.Request) {
+		api.RespondJSON(w, http.StatusOK, settings)
+	})
+	mux.HandleFunc("PUT /api/settings/general", func(w http.ResponseWriter, r *http.Request) {
+		var update map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "bad body")
+			return
+		}
+		for k, v := range update {
+			settings[k] = v
+		}
+		api.RespondJSON(w, http.StatusOK, map[string]string{"message": "saved"})
+	})
+	mux.HandleFunc("POST /api/skills", func(w http.ResponseWriter, r *http.Request) {
+		api.RespondJSON(w, http.StatusCreated, map[string]interface{}{"id": 7, "name": "pg-triage"})
+	})
+	mux.HandleFunc("GET /api/incidents", func(w http.ResponseWriter, r *http.Request) {
+		api.RespondJSON(w, http.StatusOK, []string{})
+	})
+	mux.HandleFunc("POST /api/incidents", func(w http.ResponseWriter, r *http.Request) {
+		api.RespondJSON(w, http.StatusCreated, map[string]string{"uuid": "inc-1"})
+	})
+	return mux
+}
+
+func serveAudited(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), UserContextKey, "alice"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConfigAuditMiddleware_RecordsUpdateDiff(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	h := NewConfigAuditMiddleware(recorder).Wrap(newAuditedMux())
+
+	rec := serveAudited(h, http.MethodPut, "/api/settings/general", `{"timezone":"Europe/Berlin","api_key":"sk-...new","proxy":{"url":"http://proxy:3128"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if len(recorder.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.Action != database.AuditActionConfigUpdate || entry.Actor != "alice" || entry.EntityType != "settings" || entry.Target != "general" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	changes, _ := entry.Details["changes"].([]map[string]interface{})
+	got := map[string]map[string]interface{}{}
+	for _, c := range changes {
+		got[c["field"].(string)] = c
+	}
+	if len(got) != 3 {
+		t.Fatalf("changes = %v, want timezone, api_key and proxy.url", changes)
+	}
+	if got["timezone"]["before"] != "UTC" || got["timezone"]["after"] != "Europe/Berlin" {
+		t.Errorf("timezone change = %v", got["timezone"])
+	}
+	if got["proxy.url"]["after"] != "http://proxy:3128" {
+		t.Errorf("proxy.url change = %v", got["proxy.url"])
+	}
+	if got["api_key"]["before"] != auditRedacted || got["api_key"]["after"] != auditRedacted {
+		t.Errorf("api_key change not redacted: %v", got["api_key"])
+	}
+	after, _ := entry.Details["after"].(map[string]interface{})
+	if after["api_key"] != auditRedacted {
+		t.Errorf("after snapshot api_key = %v, want redacted", after["api_key"])
+	}
+}
+
+func TestConfigAuditMiddleware_CreateTakesTargetFromResponse(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	h := NewConfigAuditMiddleware(recorder).Wrap(newAuditedMux())
+
+	serveAudited(h, http.MethodPost, "/api/skills", `{"name":"pg-triage"}`)
+	if len(recorder.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.Action != database.AuditActionConfigCreate || entry.EntityType != "skill" || entry.Target != "pg-triage" {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestConfigAuditMiddleware_SkipsReadsFailuresAndUnauditedPaths(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	h := NewConfigAuditMiddleware(recorder).Wrap(newAuditedMux())
+
+	serveAudited(h, http.MethodGet, "/api/settings/general", "")
+	serveAudited(h, http.MethodPut, "/api/settings/general", "not json")
+	serveAudited(h, http.MethodPost, "/api/incidents", `{}`)
+	if len(recorder.entries) != 0 {
+		t.Errorf("entries = %+v, want none", recorder.entries)
+	}
+}
+
+func TestAuditedEntity(t *testing.T) {
+	tests := []struct {
+		path, entityType, target string
+		ok                       bool
+	}{
+		{"/api/settings/llm/3", "llm_settings", "3", true},
+		{"/api/settings/proxy", "settings", "proxy", true},
+		{"/api/skills", "skill", "", true},
+		{"/api/skills/pg-triage/prompt", "skill", "pg-triage", true},
+		{"/api/skills/pg-triage/scripts/lag.sh", "skill_script", "pg-triage/lag.sh", true},
+		{"/api/tools/12", "tool", "12", true},
+		{"/api/skillset", "", "", false},
+		{"/api/incidents/abc", "", "", false},
+	}
+	for _, tt := range tests {
+		entityType, target, ok := auditedEntity(tt.path)
+		if entityType != tt.entityType || target != tt.target || ok != tt.ok {
+			t.Errorf("auditedEntity(%q) = %q, %q, %v; want %q, %q, %v", tt.path, entityType, target, ok, tt.entityType, tt.target, tt.ok)
+		}
+	}
+}
+
+func TestIsSecretField(t *testing.T) {
+	for name, want := range map[string]bool{
+		"api_key":                 true,
+		"settings.k8s_kubeconfig": true,
+		"env_vars.DATABASE_URL":   true,
+		"settings.k8s_namespace":  false,
+		"name":                    false,
+	} {
+		if got := isSecretField(name); got != want {
+			t.Errorf("isSecretField(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
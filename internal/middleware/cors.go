@@ -2,35 +2,138 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
-// CORSMiddleware handles Cross-Origin Resource Sharing headers
+// Default CORS values used when CORSConfig leaves a field empty.
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", "X-Requested-With", "X-Request-ID"}
+)
+
+const defaultCORSMaxAge = 86400
+
+// CORSConfig controls which cross-origin browsers may call the API.
+//
+// With no AllowedOrigins, cross-origin requests receive no CORS headers and
+// are therefore blocked by the browser; the bundled UI is served from the same
+// origin via nginx and does not need them. An entry of "*" allows any origin,
+// in which case credentials are never advertised (browsers reject
+// credentialed wildcard responses, and reflecting arbitrary origins with
+// credentials would defeat the point).
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int // preflight cache lifetime in seconds
+}
+
+// CORSMiddleware handles Cross-Origin Resource Sharing headers. Its policy
+// can be replaced while serving with Configure.
 type CORSMiddleware struct {
-	allowedOrigins []string
-	allowAll       bool
+	policy atomic.Pointer[corsPolicy]
+}
+
+// corsPolicy is a CORSConfig prepared for serving.
+type corsPolicy struct {
+	config           CORSConfig
+	allowedOrigins   []string
+	allowAll         bool
+	allowCredentials bool
+	methods          string
+	headers          string
+	maxAge           string
 }
 
-// NewCORSMiddleware creates a new CORS middleware
-// If no origins are specified, all origins are allowed
+// NewCORSMiddleware creates a CORS middleware for the given origins with
+// default methods/headers and credentials enabled. If no origins are
+// specified, cross-origin requests are not allowed.
 func NewCORSMiddleware(allowedOrigins ...string) *CORSMiddleware {
-	return &CORSMiddleware{
-		allowedOrigins: allowedOrigins,
-		allowAll:       len(allowedOrigins) == 0,
+	return NewCORSMiddlewareWithConfig(CORSConfig{
+		AllowedOrigins:   allowedOrigins,
+		AllowCredentials: true,
+	})
+}
+
+// NewCORSMiddlewareWithConfig creates a CORS middleware from cfg, filling
+// empty methods, headers and max age with defaults.
+func NewCORSMiddlewareWithConfig(cfg CORSConfig) *CORSMiddleware {
+	c := &CORSMiddleware{}
+	c.Configure(cfg)
+	return c
+}
+
+// Configure replaces the policy; requests already being served keep the
+// previous one.
+func (c *CORSMiddleware) Configure(cfg CORSConfig) {
+	resolved := CORSConfig{
+		AllowedOrigins:   []string{},
+		AllowedMethods:   orDefault(cfg.AllowedMethods, defaultCORSMethods),
+		AllowedHeaders:   orDefault(cfg.AllowedHeaders, defaultCORSHeaders),
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           defaultCORSMaxAge,
 	}
+	if cfg.MaxAge > 0 {
+		resolved.MaxAge = cfg.MaxAge
+	}
+	p := &corsPolicy{
+		methods: strings.Join(resolved.AllowedMethods, ", "),
+		headers: strings.Join(resolved.AllowedHeaders, ", "),
+		maxAge:  strconv.Itoa(resolved.MaxAge),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		switch origin {
+		case "":
+			continue
+		case "*":
+			p.allowAll = true
+		default:
+			p.allowedOrigins = append(p.allowedOrigins, origin)
+		}
+		resolved.AllowedOrigins = append(resolved.AllowedOrigins, origin)
+	}
+	if p.allowAll {
+		resolved.AllowCredentials = false
+	}
+	p.allowCredentials = resolved.AllowCredentials
+	p.config = resolved
+	c.policy.Store(p)
+}
+
+// Config returns the current policy with defaults filled in and credentials
+// off when any origin is allowed.
+func (c *CORSMiddleware) Config() CORSConfig {
+	return c.policy.Load().config
 }
 
 // Wrap wraps an http.Handler with CORS headers
 func (c *CORSMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
+		p := c.policy.Load()
 
-		// Set CORS headers for all cross-origin requests
-		if origin != "" && (c.allowAll || c.isAllowedOrigin(origin)) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			w.Header().Set("Access-Control-Max-Age", "86400")
+		if origin != "" {
+			// Responses differ per Origin, so caches must key on it.
+			w.Header().Add("Vary", "Origin")
+		}
+
+		// Set CORS headers for allowed cross-origin requests
+		if p.allowsOrigin(origin) {
+			if p.allowAll {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Set("Access-Control-Allow-Methods", p.methods)
+			w.Header().Set("Access-Control-Allow-Headers", p.headers)
+			if p.allowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			w.Header().Set("Access-Control-Max-Age", p.maxAge)
 		}
 
 		// Handle preflight OPTIONS requests
@@ -43,14 +146,37 @@ func (c *CORSMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
-func (c *CORSMiddleware) isAllowedOrigin(origin string) bool {
+// AllowsOrigin reports whether origin may make cross-origin requests.
+// Comparison is case-insensitive and ignores a trailing slash.
+func (c *CORSMiddleware) AllowsOrigin(origin string) bool {
+	return c.policy.Load().allowsOrigin(origin)
+}
+
+func (p *corsPolicy) allowsOrigin(origin string) bool {
 	if origin == "" {
+		return false
+	}
+	if p.allowAll {
 		return true
 	}
-	for _, allowed := range c.allowedOrigins {
-		if allowed == origin || allowed == "*" {
+	origin = strings.TrimRight(origin, "/")
+	for _, allowed := range p.allowedOrigins {
+		if strings.EqualFold(allowed, origin) {
 			return true
 		}
 	}
 	return false
 }
+
+func orDefault(values, defaults []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return defaults
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveCORS(c *CORSMiddleware, method, origin string) (*httptest.ResponseRecorder, bool) {
+	reached := false
+	handler := c.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(method, "/api/incidents", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, reached
+}
+
+func TestCORS_NoOriginsConfiguredBlocksCrossOrigin(t *testing.T) {
+	c := NewCORSMiddlewareWithConfig(CORSConfig{AllowCredentials: true})
+
+	rec, reached := serveCORS(c, http.MethodGet, "https://evil.example.com")
+	if !reached {
+		t.Fatal("expected request to reach the handler")
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+}
+
+func TestCORS_ExplicitOriginWithCredentials(t *testing.T) {
+	c := NewCORSMiddlewareWithConfig(CORSConfig{
+		AllowedOrigins:   []string{"https://ui.example.com/"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+
+	rec, _ := serveCORS(c, http.MethodGet, "https://UI.example.com")
+	h := rec.Header()
+	if got := h.Get("Access-Control-Allow-Origin"); got != "https://UI.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want reflected origin", got)
+	}
+	if got := h.Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+	}
+	if got := h.Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Access-Control-Allow-Methods = %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Headers"); got != "Authorization" {
+		t.Errorf("Access-Control-Allow-Headers = %q", got)
+	}
+	if got := h.Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Access-Control-Max-Age = %q", got)
+	}
+
+	rec, _ = serveCORS(c, http.MethodGet, "https://other.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("unlisted origin got Access-Control-Allow-Origin = %q", got)
+	}
+}
+
+func TestCORS_WildcardNeverAdvertisesCredentials(t *testing.T) {
+	c := NewCORSMiddlewareWithConfig(CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	})
+
+	rec, _ := serveCORS(c, http.MethodGet, "https://anywhere.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want none for wildcard", got)
+	}
+}
+
+func TestCORS_DefaultsAppliedForEmptyLists(t *testing.T) {
+	c := NewCORSMiddleware("https://ui.example.com")
+
+	rec, _ := serveCORS(c, http.MethodGet, "https://ui.example.com")
+	h := rec.Header()
+	if got := h.Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, DELETE, OPTIONS, PATCH" {
+		t.Errorf("Access-Control-Allow-Methods = %q", got)
+	}
+	if got := h.Get("Access-Control-Max-Age"); got != "86400" {
+		t.Errorf("Access-Control-Max-Age = %q", got)
+	}
+	if got := h.Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+	}
+}
+
+func TestCORS_PreflightShortCircuits(t *testing.T) {
+	c := NewCORSMiddleware("https://ui.example.com")
+
+	rec, reached := serveCORS(c, http.MethodOptions, "https://ui.example.com")
+	if reached {
+		t.Error("preflight should not reach the wrapped handler")
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestCORS_AllowsOrigin(t *testing.T) {
+	c := NewCORSMiddlewareWithConfig(CORSConfig{AllowedOrigins: []string{"https://ui.example.com", " "}})
+
+	if !c.AllowsOrigin("https://ui.example.com/") {
+		t.Error("expected configured origin to be allowed")
+	}
+	if c.AllowsOrigin("") {
+		t.Error("empty origin must not be allowed")
+	}
+	if c.AllowsOrigin("https://ui.example.com.evil.test") {
+		t.Error("prefix match must not be allowed")
+	}
+}
+
+func TestCORS_NoArgumentsAllowsNoOrigin(t *testing.T) {
+	c := NewCORSMiddleware()
+
+	if c.AllowsOrigin("https://ui.example.com") {
+		t.Error("NewCORSMiddleware() must not allow cross-origin requests")
+	}
+}
+
+func TestCORS_ConfigureReplacesPolicy(t *testing.T) {
+	c := NewCORSMiddlewareWithConfig(CORSConfig{AllowedOrigins: []string{"https://old.example.com"}})
+	c.Configure(CORSConfig{AllowedOrigins: []string{"https://ui.example.com"}, AllowedHeaders: []string{"Content-Type"}})
+
+	if c.AllowsOrigin("https://old.example.com") {
+		t.Error("previous origin still allowed after Configure")
+	}
+	rec, _ := serveCORS(c, http.MethodGet, "https://ui.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the new origin", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type" {
+		t.Errorf("Access-Control-Allow-Headers = %q, want Content-Type", got)
+	}
+	if got := c.Config().AllowedOrigins; len(got) != 1 || got[0] != "https://ui.example.com" {
+		t.Errorf("Config().AllowedOrigins = %v", got)
+	}
+}
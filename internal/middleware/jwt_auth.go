@@ -164,7 +164,7 @@ func (m *JWTAuthMiddleware) CompleteSetup(adminPasswordHash string) {
 
 // isSetupPath returns true for paths that are allowed during setup mode
 func isSetupPath(path string) bool {
-	return path == "/auth/setup" || path == "/auth/setup-status" || path == "/health"
+	return path == "/auth/setup" || path == "/auth/setup-status" || path == "/health" || path == "/readyz"
 }
 
 // Wrap wraps an http.Handler with JWT authentication
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default login throttle parameters, used when LoginThrottleConfig leaves a
+// field at its zero value.
+const (
+	defaultLoginBaseDelay        = time.Second
+	defaultLoginMaxDelay         = 30 * time.Second
+	defaultLoginLockoutThreshold = 5
+	defaultLoginLockoutDuration  = 15 * time.Minute
+	defaultLoginMaxEntries       = 10000
+)
+
+// LoginThrottleConfig configures brute-force protection for /auth/login.
+type LoginThrottleConfig struct {
+	// BaseDelay is the backoff applied after the first failed attempt. Each
+	// further failure doubles it, capped at MaxDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the exponential backoff between attempts.
+	MaxDelay time.Duration
+
+	// LockoutThreshold is the number of consecutive failures after which the
+	// username+IP pair is locked out for LockoutDuration.
+	LockoutThreshold int
+
+	// LockoutDuration is how long a lockout lasts. It also bounds how long
+	// failure history is remembered after the last failed attempt.
+	LockoutDuration time.Duration
+
+	// MaxEntries caps the number of username+IP pairs tracked. Usernames
+	// come from the client, so without a cap spraying random names grows
+	// the history without limit. At the cap, the pair with the oldest
+	// failure is dropped, preferring pairs that are not locked out.
+	MaxEntries int
+}
+
+// LoginLockout describes the throttle state of one username+IP pair.
+type LoginLockout struct {
+	Username     string     `json:"username"`
+	RemoteIP     string     `json:"remote_ip"`
+	Failures     int        `json:"failures"`
+	LastFailure  time.Time  `json:"last_failure"`
+	BlockedUntil time.Time  `json:"blocked_until"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
+
+// loginAttempts is the per-key failure history.
+type loginAttempts struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// LoginThrottle tracks failed login attempts per username+IP and applies
+// exponential backoff followed by a temporary lockout. State is in-memory:
+// a restart clears all lockouts, which is acceptable for the single-admin
+// login surface it protects.
+type LoginThrottle struct {
+	config LoginThrottleConfig
+	mu     sync.Mutex
+	byKey  map[string]*loginAttempts
+	now    func() time.Time
+}
+
+// NewLoginThrottle creates a login throttle, filling unset config fields with
+// defaults.
+func NewLoginThrottle(config LoginThrottleConfig) *LoginThrottle {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultLoginBaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultLoginMaxDelay
+	}
+	if config.LockoutThreshold <= 0 {
+		config.LockoutThreshold = defaultLoginLockoutThreshold
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = defaultLoginLockoutDuration
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultLoginMaxEntries
+	}
+	return &LoginThrottle{
+		config: config,
+		byKey:  make(map[string]*loginAttempts),
+		now:    time.Now,
+	}
+}
+
+// loginThrottleKey builds the map key for a username+IP pair. Usernames are
+// compared case-insensitively so "Admin" and "admin" share one budget.
+func loginThrottleKey(username, ip string) string {
+	return strings.ToLower(username) + "\x00" + ip
+}
+
+// RetryAfter returns how long the caller must wait before another login
+// attempt for username+ip is evaluated. Zero means the attempt may proceed.
+func (t *LoginThrottle) RetryAfter(username, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.pruneLocked(now)
+	return t.retryAfterLocked(now, loginThrottleKey(username, ip))
+}
+
+// Reserve admits a login attempt for username+ip before its password is
+// checked. A pair that is still blocked gets the remaining wait and ok is
+// false. Otherwise the attempt is counted as a failure right away, so
+// concurrent attempts already see its backoff, and wait and locked describe
+// that backoff; a successful login clears it with RecordSuccess.
+func (t *LoginThrottle) Reserve(username, ip string) (wait time.Duration, locked, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.pruneLocked(now)
+	key := loginThrottleKey(username, ip)
+	if wait := t.retryAfterLocked(now, key); wait > 0 {
+		return wait, false, false
+	}
+	wait, locked = t.recordFailureLocked(now, key)
+	return wait, locked, true
+}
+
+// RecordFailure registers a failed attempt and returns the resulting wait
+// before the next attempt, plus whether the pair is now locked out.
+func (t *LoginThrottle) RecordFailure(username, ip string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.recordFailureLocked(t.now(), loginThrottleKey(username, ip))
+}
+
+// retryAfterLocked returns the remaining block of key. Caller must hold t.mu.
+func (t *LoginThrottle) retryAfterLocked(now time.Time, key string) time.Duration {
+	a, ok := t.byKey[key]
+	if !ok || !now.Before(a.blockedUntil) {
+		return 0
+	}
+	return a.blockedUntil.Sub(now)
+}
+
+// recordFailureLocked counts a failure for key and blocks it accordingly.
+// Caller must hold t.mu.
+func (t *LoginThrottle) recordFailureLocked(now time.Time, key string) (time.Duration, bool) {
+	a, ok := t.byKey[key]
+	if !ok {
+		if len(t.byKey) >= t.config.MaxEntries {
+			t.evictLocked()
+		}
+		a = &loginAttempts{}
+		t.byKey[key] = a
+	}
+	a.failures++
+	a.lastFailure = now
+
+	locked := a.failures >= t.config.LockoutThreshold
+	var wait time.Duration
+	if locked {
+		wait = t.config.LockoutDuration
+	} else {
+		wait = t.config.BaseDelay << (a.failures - 1)
+		if wait <= 0 || wait > t.config.MaxDelay {
+			wait = t.config.MaxDelay
+		}
+	}
+	a.blockedUntil = now.Add(wait)
+	return wait, locked
+}
+
+// RecordSuccess clears the failure history for username+ip.
+func (t *LoginThrottle) RecordSuccess(username, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.byKey, loginThrottleKey(username, ip))
+}
+
+// Unlock clears failure history for username. When ip is empty every pair
+// for that username is cleared. Returns the number of entries removed.
+func (t *LoginThrottle) Unlock(username, ip string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ip != "" {
+		key := loginThrottleKey(username, ip)
+		if _, ok := t.byKey[key]; !ok {
+			return 0
+		}
+		delete(t.byKey, key)
+		return 1
+	}
+
+	prefix := strings.ToLower(username) + "\x00"
+	removed := 0
+	for key := range t.byKey {
+		if strings.HasPrefix(key, prefix) {
+			delete(t.byKey, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Lockouts returns every username+IP pair with recorded failures, most
+// recent failure first.
+func (t *LoginThrottle) Lockouts() []LoginLockout {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.pruneLocked(now)
+
+	result := make([]LoginLockout, 0, len(t.byKey))
+	for key, a := range t.byKey {
+		username, ip, _ := strings.Cut(key, "\x00")
+		entry := LoginLockout{
+			Username:     username,
+			RemoteIP:     ip,
+			Failures:     a.failures,
+			LastFailure:  a.lastFailure,
+			BlockedUntil: a.blockedUntil,
+		}
+		if a.failures >= t.config.LockoutThreshold && now.Before(a.blockedUntil) {
+			lockedUntil := a.blockedUntil
+			entry.LockedUntil = &lockedUntil
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastFailure.After(result[j].LastFailure)
+	})
+	return result
+}
+
+// pruneLocked drops history whose block has expired and whose last failure
+// is older than LockoutDuration. Caller must hold t.mu.
+func (t *LoginThrottle) pruneLocked(now time.Time) {
+	for key, a := range t.byKey {
+		if now.After(a.blockedUntil) && now.Sub(a.lastFailure) > t.config.LockoutDuration {
+			delete(t.byKey, key)
+		}
+	}
+}
+
+// evictLocked drops one pair to make room for a new one: the one with the
+// oldest failure among those not locked out, or the oldest overall when
+// every pair is locked out. Keeping lockouts means a flood of one-off
+// usernames cannot clear the lockout of the pair it targets. Caller must
+// hold t.mu.
+func (t *LoginThrottle) evictLocked() {
+	var victim string
+	var victimLocked bool
+	var victimFailure time.Time
+	for key, a := range t.byKey {
+		locked := a.failures >= t.config.LockoutThreshold
+		better := victim == "" ||
+			(!locked && victimLocked) ||
+			(locked == victimLocked && a.lastFailure.Before(victimFailure))
+		if better {
+			victim, victimLocked, victimFailure = key, locked, a.lastFailure
+		}
+	}
+	delete(t.byKey, victim)
+}
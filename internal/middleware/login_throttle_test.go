@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestLoginThrottle(start time.Time) (*LoginThrottle, *time.Time) {
+	now := start
+	t := NewLoginThrottle(LoginThrottleConfig{
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 4,
+		LockoutDuration:  10 * time.Minute,
+	})
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func TestLoginThrottle_ExponentialBackoff(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		wait, locked := throttle.RecordFailure("admin", "10.0.0.1")
+		if locked {
+			t.Fatalf("failure %d: unexpected lockout", i+1)
+		}
+		if wait != w {
+			t.Errorf("failure %d: wait = %v, want %v", i+1, wait, w)
+		}
+	}
+}
+
+func TestLoginThrottle_BackoffCappedAtMaxDelay(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+	throttle.config.LockoutThreshold = 100
+
+	var wait time.Duration
+	for i := 0; i < 70; i++ {
+		wait, _ = throttle.RecordFailure("admin", "10.0.0.1")
+	}
+	if wait != 4*time.Second {
+		t.Errorf("wait = %v, want capped %v", wait, 4*time.Second)
+	}
+}
+
+func TestLoginThrottle_RetryAfterElapses(t *testing.T) {
+	throttle, now := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	throttle.RecordFailure("admin", "10.0.0.1")
+	if wait := throttle.RetryAfter("admin", "10.0.0.1"); wait != time.Second {
+		t.Fatalf("RetryAfter = %v, want 1s", wait)
+	}
+
+	*now = now.Add(time.Second)
+	if wait := throttle.RetryAfter("admin", "10.0.0.1"); wait != 0 {
+		t.Errorf("RetryAfter after backoff = %v, want 0", wait)
+	}
+}
+
+func TestLoginThrottle_LockoutAtThreshold(t *testing.T) {
+	throttle, now := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	var locked bool
+	var wait time.Duration
+	for i := 0; i < 4; i++ {
+		wait, locked = throttle.RecordFailure("admin", "10.0.0.1")
+		*now = now.Add(5 * time.Second)
+	}
+	if !locked {
+		t.Fatal("expected lockout at threshold")
+	}
+	if wait != 10*time.Minute {
+		t.Errorf("lockout wait = %v, want 10m", wait)
+	}
+
+	if got := throttle.RetryAfter("admin", "10.0.0.1"); got <= 0 {
+		t.Error("expected pair to remain blocked during lockout")
+	}
+
+	lockouts := throttle.Lockouts()
+	if len(lockouts) != 1 || lockouts[0].LockedUntil == nil || lockouts[0].Failures != 4 {
+		t.Fatalf("unexpected lockouts: %+v", lockouts)
+	}
+}
+
+func TestLoginThrottle_KeysAreIndependent(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	throttle.RecordFailure("admin", "10.0.0.1")
+
+	if wait := throttle.RetryAfter("admin", "10.0.0.2"); wait != 0 {
+		t.Errorf("other IP blocked: %v", wait)
+	}
+	if wait := throttle.RetryAfter("operator", "10.0.0.1"); wait != 0 {
+		t.Errorf("other username blocked: %v", wait)
+	}
+	if wait := throttle.RetryAfter("ADMIN", "10.0.0.1"); wait == 0 {
+		t.Error("expected username match to be case-insensitive")
+	}
+}
+
+func TestLoginThrottle_SuccessClearsHistory(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	throttle.RecordFailure("admin", "10.0.0.1")
+	throttle.RecordSuccess("admin", "10.0.0.1")
+
+	if wait := throttle.RetryAfter("admin", "10.0.0.1"); wait != 0 {
+		t.Errorf("RetryAfter after success = %v, want 0", wait)
+	}
+	if n := len(throttle.Lockouts()); n != 0 {
+		t.Errorf("expected no lockouts, got %d", n)
+	}
+}
+
+func TestLoginThrottle_ReserveCountsAttemptUpFront(t *testing.T) {
+	throttle, now := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	wait, locked, ok := throttle.Reserve("admin", "10.0.0.1")
+	if !ok || wait != time.Second || locked {
+		t.Fatalf("first Reserve = %v, %v, %v; want 1s, false, true", wait, locked, ok)
+	}
+	// A second attempt while the first is still being checked is refused.
+	if wait, _, ok := throttle.Reserve("admin", "10.0.0.1"); ok || wait != time.Second {
+		t.Fatalf("concurrent Reserve = %v, %v; want 1s, false", wait, ok)
+	}
+
+	// The first attempt succeeded: its reservation is cleared.
+	throttle.RecordSuccess("admin", "10.0.0.1")
+	if _, _, ok := throttle.Reserve("admin", "10.0.0.1"); !ok {
+		t.Fatal("Reserve after success should be admitted")
+	}
+
+	// Left unresolved, reservations back off and lock out like failures.
+	for i := 2; i <= 4; i++ {
+		*now = now.Add(5 * time.Second)
+		_, locked, ok = throttle.Reserve("admin", "10.0.0.1")
+		if !ok || locked != (i == 4) {
+			t.Fatalf("attempt %d: locked=%v ok=%v", i, locked, ok)
+		}
+	}
+}
+
+func TestLoginThrottle_Unlock(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	throttle.RecordFailure("admin", "10.0.0.1")
+	throttle.RecordFailure("admin", "10.0.0.2")
+	throttle.RecordFailure("other", "10.0.0.1")
+
+	if n := throttle.Unlock("admin", "10.0.0.1"); n != 1 {
+		t.Errorf("Unlock single pair removed %d, want 1", n)
+	}
+	if n := throttle.Unlock("admin", "10.0.0.9"); n != 0 {
+		t.Errorf("Unlock unknown pair removed %d, want 0", n)
+	}
+	throttle.RecordFailure("admin", "10.0.0.3")
+	if n := throttle.Unlock("Admin", ""); n != 2 {
+		t.Errorf("Unlock all for username removed %d, want 2", n)
+	}
+
+	lockouts := throttle.Lockouts()
+	if len(lockouts) != 1 || lockouts[0].Username != "other" {
+		t.Errorf("unexpected remaining lockouts: %+v", lockouts)
+	}
+}
+
+func TestLoginThrottle_PrunesStaleHistory(t *testing.T) {
+	throttle, now := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+
+	throttle.RecordFailure("admin", "10.0.0.1")
+	*now = now.Add(11 * time.Minute)
+
+	if n := len(throttle.Lockouts()); n != 0 {
+		t.Errorf("expected stale history to be pruned, got %d entries", n)
+	}
+}
+
+func TestNewLoginThrottle_Defaults(t *testing.T) {
+	throttle := NewLoginThrottle(LoginThrottleConfig{})
+
+	if throttle.config.BaseDelay != defaultLoginBaseDelay {
+		t.Errorf("BaseDelay = %v", throttle.config.BaseDelay)
+	}
+	if throttle.config.MaxDelay != defaultLoginMaxDelay {
+		t.Errorf("MaxDelay = %v", throttle.config.MaxDelay)
+	}
+	if throttle.config.LockoutThreshold != defaultLoginLockoutThreshold {
+		t.Errorf("LockoutThreshold = %d", throttle.config.LockoutThreshold)
+	}
+	if throttle.config.LockoutDuration != defaultLoginLockoutDuration {
+		t.Errorf("LockoutDuration = %v", throttle.config.LockoutDuration)
+	}
+	if throttle.config.MaxEntries != defaultLoginMaxEntries {
+		t.Errorf("MaxEntries = %d", throttle.config.MaxEntries)
+	}
+}
+
+func TestLoginThrottle_CapsTrackedPairs(t *testing.T) {
+	throttle, now := newTestLoginThrottle(time.Unix(1_700_000_000, 0))
+	throttle.config.MaxEntries = 50
+
+	for i := 0; i < 4; i++ {
+		throttle.RecordFailure("admin", "10.0.0.1")
+	}
+	// Spray far more one-off usernames than the cap from the same address.
+	for i := 0; i < 500; i++ {
+		*now = now.Add(time.Millisecond)
+		throttle.Reserve(fmt.Sprintf("user-%d", i), "10.0.0.1")
+	}
+
+	if n := len(throttle.byKey); n != 50 {
+		t.Errorf("tracked pairs = %d, want the cap of 50", n)
+	}
+	if _, ok := throttle.byKey[loginThrottleKey("user-499", "10.0.0.1")]; !ok {
+		t.Error("the newest pair should be tracked")
+	}
+	if _, ok := throttle.byKey[loginThrottleKey("user-0", "10.0.0.1")]; ok {
+		t.Error("the oldest one-off pair should have been evicted")
+	}
+	if wait := throttle.RetryAfter("admin", "10.0.0.1"); wait <= 0 {
+		t.Error("the spray must not clear the lockout it targets")
+	}
+}
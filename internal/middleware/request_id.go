@@ -5,6 +5,8 @@ import (
 	"crypto/rand"
 	"fmt"
 	"net/http"
+
+	"github.com/akmatori/akmatori/internal/logging"
 )
 
 const (
@@ -17,6 +19,7 @@ type requestIDContextKey struct{}
 
 // RequestIDMiddleware adds an X-Request-ID header to every response.
 // If the client provides one, it is reused; otherwise a new UUID is generated.
+// Records logged with the request context carry it as request_id.
 func RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := r.Header.Get(RequestIDHeader)
@@ -26,7 +29,7 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 
 		w.Header().Set(RequestIDHeader, id)
 
-		ctx := context.WithValue(r.Context(), requestIDContextKey{}, id)
+		ctx := logging.WithRequestID(context.WithValue(r.Context(), requestIDContextKey{}, id), id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
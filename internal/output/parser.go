@@ -2,6 +2,7 @@ package output
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -28,6 +29,13 @@ type Progress struct {
 	FindingsSoFar string
 }
 
+// Confidence represents a parsed [CONFIDENCE] block: how sure the agent is
+// of its result
+type Confidence struct {
+	Score  float64 // 0-1
+	Reason string
+}
+
 // ParsedOutput contains all parsed structured blocks from agent output
 type ParsedOutput struct {
 	// The original raw output
@@ -40,6 +48,11 @@ type ParsedOutput struct {
 	FinalResult *FinalResult
 	Escalation  *Escalation
 	Progress    *Progress
+	Confidence  *Confidence
+
+	// StatusLabels optionally renames result statuses (keyed by the
+	// lowercase status) in the Slack formatters. Parse leaves it nil.
+	StatusLabels map[string]string
 }
 
 // Regex patterns for structured blocks
@@ -47,6 +60,7 @@ var (
 	finalResultPattern  = regexp.MustCompile(`(?s)\[FINAL_RESULT\]\s*(.+?)\s*\[/FINAL_RESULT\]`)
 	escalatePattern     = regexp.MustCompile(`(?s)\[ESCALATE\]\s*(.+?)\s*\[/ESCALATE\]`)
 	progressPattern     = regexp.MustCompile(`(?s)\[PROGRESS\]\s*(.+?)\s*\[/PROGRESS\]`)
+	confidencePattern   = regexp.MustCompile(`(?s)\[CONFIDENCE\]\s*(.+?)\s*\[/CONFIDENCE\]`)
 	multiNewlinePattern = regexp.MustCompile(`\n{3,}`)
 )
 
@@ -71,11 +85,15 @@ func Parse(output string) *ParsedOutput {
 		result.Progress = parseProgressContent(matches[1])
 	}
 
+	// Parse CONFIDENCE
+	result.Confidence, _ = ExtractConfidence(output)
+
 	// Create clean output by removing structured blocks
 	clean := output
 	clean = finalResultPattern.ReplaceAllString(clean, "")
 	clean = escalatePattern.ReplaceAllString(clean, "")
 	clean = progressPattern.ReplaceAllString(clean, "")
+	clean = confidencePattern.ReplaceAllString(clean, "")
 	clean = strings.TrimSpace(clean)
 	clean = multiNewlinePattern.ReplaceAllString(clean, "\n\n")
 	result.CleanOutput = clean
@@ -180,6 +198,56 @@ func parseProgressContent(content string) *Progress {
 	return result
 }
 
+// ExtractConfidence parses the last [CONFIDENCE] block of output and returns
+// it with every such block removed. The score may be written as a fraction
+// (0.85) or a percentage (85%); the confidence is nil when there is no block
+// or its score is missing or outside 0-1 (0-100%).
+func ExtractConfidence(output string) (*Confidence, string) {
+	matches := confidencePattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil, output
+	}
+	rest := strings.TrimSpace(confidencePattern.ReplaceAllString(output, ""))
+	rest = multiNewlinePattern.ReplaceAllString(rest, "\n\n")
+	return parseConfidenceContent(matches[len(matches)-1][1]), rest
+}
+
+// parseConfidenceContent parses the content inside a [CONFIDENCE] block
+func parseConfidenceContent(content string) *Confidence {
+	result := &Confidence{Score: -1}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "score:") {
+			result.Score = parseConfidenceScore(strings.TrimSpace(strings.TrimPrefix(line, "score:")))
+		} else if strings.HasPrefix(line, "reason:") {
+			result.Reason = strings.TrimSpace(strings.TrimPrefix(line, "reason:"))
+		}
+	}
+
+	if result.Score < 0 {
+		return nil
+	}
+	return result
+}
+
+// parseConfidenceScore returns the score as a fraction, or -1 when it is not
+// a number in range.
+func parseConfidenceScore(v string) float64 {
+	percent := strings.HasSuffix(v, "%")
+	f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(v, "%")), 64)
+	if err != nil {
+		return -1
+	}
+	if percent {
+		f /= 100
+	}
+	if !(f >= 0 && f <= 1) { // also rejects NaN
+		return -1
+	}
+	return f
+}
+
 // HasStructuredOutput returns true if any structured blocks were found
 func (p *ParsedOutput) HasStructuredOutput() bool {
 	return p.FinalResult != nil || p.Escalation != nil || p.Progress != nil
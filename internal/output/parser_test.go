@@ -170,6 +170,66 @@ recommendations:
 	}
 }
 
+func TestParse_Confidence(t *testing.T) {
+	input := `The disk filled up with rotated logs; I removed them.
+
+[CONFIDENCE]
+score: 0.85
+reason: Disk usage dropped back to 40% after cleanup
+[/CONFIDENCE]`
+
+	result := Parse(input)
+
+	if result.Confidence == nil {
+		t.Fatal("Confidence should be parsed")
+	}
+	if result.Confidence.Score != 0.85 {
+		t.Errorf("Score = %v, want 0.85", result.Confidence.Score)
+	}
+	if result.Confidence.Reason != "Disk usage dropped back to 40% after cleanup" {
+		t.Errorf("Reason = %q", result.Confidence.Reason)
+	}
+	if result.CleanOutput != "The disk filled up with rotated logs; I removed them." {
+		t.Errorf("CleanOutput = %q, want the block removed", result.CleanOutput)
+	}
+}
+
+func TestExtractConfidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantScore float64 // -1 = no confidence
+		wantRest  string
+	}{
+		{"fraction", "Done.\n[CONFIDENCE]\nscore: 0.4\n[/CONFIDENCE]", 0.4, "Done."},
+		{"percentage", "Done.\n[CONFIDENCE]\nscore: 72%\n[/CONFIDENCE]", 0.72, "Done."},
+		{"last block wins", "[CONFIDENCE]\nscore: 0.2\n[/CONFIDENCE]\nDone.\n[CONFIDENCE]\nscore: 0.9\n[/CONFIDENCE]", 0.9, "Done."},
+		{"out of range", "Done.\n[CONFIDENCE]\nscore: 1.5\n[/CONFIDENCE]", -1, "Done."},
+		{"not a number", "Done.\n[CONFIDENCE]\nscore: high\n[/CONFIDENCE]", -1, "Done."},
+		{"NaN", "Done.\n[CONFIDENCE]\nscore: NaN\n[/CONFIDENCE]", -1, "Done."},
+		{"missing score", "Done.\n[CONFIDENCE]\nreason: looked fine\n[/CONFIDENCE]", -1, "Done."},
+		{"no block", "Done.", -1, "Done."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest := ExtractConfidence(tt.input)
+			if rest != tt.wantRest {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+			if tt.wantScore < 0 {
+				if got != nil {
+					t.Errorf("confidence = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Score != tt.wantScore {
+				t.Errorf("confidence = %+v, want score %v", got, tt.wantScore)
+			}
+		})
+	}
+}
+
 func TestParse_CleanOutputNormalizesNewlines(t *testing.T) {
 	input := `Text before.
 
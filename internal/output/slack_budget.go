@@ -27,7 +27,7 @@ func ShortenForSlackBudget(parsed *ParsedOutput, maxBytes int) string {
 	}
 
 	if parsed != nil && parsed.FinalResult != nil {
-		if condensed := condenseFinalResult(parsed.FinalResult); condensed != "" {
+		if condensed := condenseFinalResult(parsed.FinalResult, parsed.StatusLabels); condensed != "" {
 			if len(condensed) <= maxBytes {
 				return condensed
 			}
@@ -58,7 +58,7 @@ func ShortenForSlackBudget(parsed *ParsedOutput, maxBytes int) string {
 // condenseFinalResult builds a compact Slack-formatted summary from a parsed
 // [FINAL_RESULT] block. Mirrors the heading/section layout used by the full
 // formatter so the shortened view feels consistent.
-func condenseFinalResult(fr *FinalResult) string {
+func condenseFinalResult(fr *FinalResult, labels map[string]string) string {
 	if fr == nil {
 		return ""
 	}
@@ -66,7 +66,9 @@ func condenseFinalResult(fr *FinalResult) string {
 	var sb strings.Builder
 	emoji := getStatusEmoji(fr.Status)
 	status := strings.TrimSpace(fr.Status)
-	if status == "" {
+	if label := labels[strings.ToLower(status)]; label != "" {
+		status = label
+	} else if status == "" {
 		status = "Result"
 	} else {
 		status = titleCaseRune(status)
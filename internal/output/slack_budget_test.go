@@ -181,3 +181,21 @@ func TestShortenForSlackBudget_PreservesUTF8(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusLabels_RenameResultStatus(t *testing.T) {
+	parsed := &ParsedOutput{
+		FinalResult:  &FinalResult{Status: "Resolved", Summary: "Disk cleaned up."},
+		StatusLabels: map[string]string{"resolved": "Mitigated"},
+	}
+	if got := FormatForSlack(parsed); !strings.HasPrefix(got, "✅ *Mitigated*") {
+		t.Errorf("FormatForSlack header = %q", got)
+	}
+	if got := ShortenForSlackBudget(parsed, 1000); !strings.HasPrefix(got, "✅ *Mitigated*") {
+		t.Errorf("ShortenForSlackBudget header = %q", got)
+	}
+
+	parsed.StatusLabels = nil
+	if got := FormatForSlack(parsed); !strings.HasPrefix(got, "✅ *Resolved*") {
+		t.Errorf("built-in header = %q", got)
+	}
+}
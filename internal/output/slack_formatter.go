@@ -32,7 +32,7 @@ var (
 func FormatForSlack(parsed *ParsedOutput) string {
 	// If there's a final result, format it nicely
 	if parsed.FinalResult != nil {
-		return formatFinalResultForSlack(parsed.FinalResult, parsed.CleanOutput)
+		return formatFinalResultForSlack(parsed.FinalResult, parsed.CleanOutput, parsed.StatusLabels)
 	}
 
 	// If there's an escalation, format it with urgency
@@ -54,12 +54,15 @@ func FormatForSlack(parsed *ParsedOutput) string {
 }
 
 // formatFinalResultForSlack formats a FinalResult for Slack
-func formatFinalResultForSlack(result *FinalResult, additionalContext string) string {
+func formatFinalResultForSlack(result *FinalResult, additionalContext string, labels map[string]string) string {
 	var sb strings.Builder
 
 	// Status emoji and header
 	statusEmoji := getStatusEmoji(result.Status)
-	statusText := cases.Title(language.English).String(result.Status)
+	statusText := labels[strings.ToLower(result.Status)]
+	if statusText == "" {
+		statusText = cases.Title(language.English).String(result.Status)
+	}
 	sb.WriteString(fmt.Sprintf("%s *%s*\n\n", statusEmoji, statusText))
 
 	// Summary
@@ -0,0 +1,184 @@
+// Package secrets encrypts credentials stored in the database with AES-256-GCM.
+//
+// An encrypted value is a string of the form enc:v1:<key id>:<base64 of
+// nonce and ciphertext>, so it fits in the text and JSON columns that held the
+// plaintext. Values without the prefix are treated as plaintext, which lets
+// existing rows be read until they are re-encrypted.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length of an encryption key in bytes (AES-256).
+const KeySize = 32
+
+const prefix = "enc:v1:"
+
+// ErrNoKey is returned when an encrypted value is read without a keyring,
+// or with one that does not hold the key it was encrypted with.
+var ErrNoKey = errors.New("secret is encrypted with an unknown key")
+
+// Keyring encrypts with its primary key and decrypts with the primary key or
+// any of the previous keys, so keys can be rotated without downtime.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from a primary key and the keys it replaced.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+	for i, key := range append([][]byte{primary}, previous...) {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := KeyID(key)
+		if i == 0 {
+			k.primary = id
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// ParseKey decodes a base64 (standard or URL alphabet) or hex encoded key.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, decode := range []func(string) ([]byte, error){
+		base64.StdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+		hex.DecodeString,
+	} {
+		if key, err := decode(s); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// KeyID is the short identifier stored with values encrypted by key.
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// IsEncrypted reports whether value is an encrypted secret.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt encrypts plaintext with the primary key. Empty strings stay empty
+// so "not configured" checks keep working on the stored column.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value and returns any other
+// value unchanged. It is safe to call on a nil keyring, which fails only for
+// encrypted values.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted secret")
+	}
+	var aead cipher.AEAD
+	if k != nil {
+		aead = k.keys[id]
+	}
+	if aead == nil {
+		return "", fmt.Errorf("%w %s", ErrNoKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret with key %s: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// Current reports whether value is empty or encrypted with the primary key,
+// i.e. needs no re-encryption.
+func (k *Keyring) Current(value string) bool {
+	return value == "" || strings.HasPrefix(value, prefix+k.primary+":")
+}
+
+// secretKeys are settings keys that hold credentials under names the word
+// list in IsSecretKey does not catch.
+var secretKeys = map[string]bool{
+	"k8s_kubeconfig":  true,
+	"prom_client_key": true,
+}
+
+// IsSecretKey reports whether a settings or credentials key holds a secret:
+// one of the known credential keys, or judged by its name (bot_token,
+// zabbix_password, private_key, ...).
+func IsSecretKey(name string) bool {
+	name = strings.ToLower(name)
+	if secretKeys[name] {
+		return true
+	}
+	for _, word := range []string{"password", "secret", "token", "api_key", "apikey", "private_key", "passphrase"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadKeyring builds a keyring from encoded keys as configured in the
+// environment. It returns nil when primary is empty: encryption is disabled.
+func LoadKeyring(primary string, previous []string) (*Keyring, error) {
+	if strings.TrimSpace(primary) == "" {
+		if len(previous) > 0 {
+			return nil, errors.New("previous encryption keys are set without a primary key")
+		}
+		return nil, nil
+	}
+	key, err := ParseKey(primary)
+	if err != nil {
+		return nil, err
+	}
+	var old [][]byte
+	for i, p := range previous {
+		k, err := ParseKey(p)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %d: %w", i+1, err)
+		}
+		old = append(old, k)
+	}
+	return NewKeyring(key, old...)
+}
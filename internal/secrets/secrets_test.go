@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	k, err := NewKeyring(testKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+
+	enc, err := k.Encrypt("xoxb-123")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "xoxb") {
+		t.Fatalf("Encrypt() = %q, want an opaque encrypted value", enc)
+	}
+	if again, _ := k.Encrypt("xoxb-123"); again == enc {
+		t.Error("Encrypt() reused a nonce")
+	}
+	if got, err := k.Decrypt(enc); err != nil || got != "xoxb-123" {
+		t.Errorf("Decrypt() = %q, %v", got, err)
+	}
+	if got, err := k.Decrypt("plain"); err != nil || got != "plain" {
+		t.Errorf("Decrypt(plaintext) = %q, %v, want it unchanged", got, err)
+	}
+	if enc, _ := k.Encrypt(""); enc != "" {
+		t.Errorf("Encrypt(\"\") = %q, want empty", enc)
+	}
+
+	tampered := enc[:len(enc)-4] + "AAA="
+	if _, err := k.Decrypt(tampered); err == nil {
+		t.Error("Decrypt() accepted a tampered value")
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, _ := NewKeyring(testKey(1))
+	enc, _ := old.Encrypt("secret")
+
+	rotated, err := NewKeyring(testKey(2), testKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	if got, err := rotated.Decrypt(enc); err != nil || got != "secret" {
+		t.Errorf("Decrypt() with previous key = %q, %v", got, err)
+	}
+	if rotated.Current(enc) {
+		t.Error("Current() = true for a value under a previous key")
+	}
+	reenc, _ := rotated.Encrypt("secret")
+	if !rotated.Current(reenc) || !rotated.Current("") {
+		t.Error("Current() = false for a value under the primary key")
+	}
+
+	fresh, _ := NewKeyring(testKey(3))
+	if _, err := fresh.Decrypt(enc); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt() with unknown key error = %v, want ErrNoKey", err)
+	}
+	var none *Keyring
+	if _, err := none.Decrypt(enc); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil keyring Decrypt() error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString(testKey(1))
+	hexKey := strings.Repeat("02", KeySize)
+
+	if k, err := LoadKeyring("", nil); k != nil || err != nil {
+		t.Errorf("LoadKeyring(empty) = %v, %v, want nil, nil", k, err)
+	}
+	if _, err := LoadKeyring("", []string{b64}); err == nil {
+		t.Error("LoadKeyring() accepted previous keys without a primary key")
+	}
+	if _, err := LoadKeyring("too-short", nil); err == nil {
+		t.Error("LoadKeyring() accepted a short key")
+	}
+	k, err := LoadKeyring(hexKey, []string{b64})
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	old, _ := NewKeyring(testKey(1))
+	enc, _ := old.Encrypt("v")
+	if got, err := k.Decrypt(enc); err != nil || got != "v" {
+		t.Errorf("Decrypt() = %q, %v", got, err)
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	for name, want := range map[string]bool{
+		"bot_token":       true,
+		"Zabbix_Password": true,
+		"private_key":     true,
+		"k8s_kubeconfig":  true,
+		"prom_client_key": true,
+		"k8s_namespace":   false,
+		"prom_url":        false,
+		"prom_ca_cert":    false,
+	} {
+		if got := IsSecretKey(name); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
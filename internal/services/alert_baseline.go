@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// alertBaselineInterval is how often AlertBaselineService recomputes the
+	// baselines. They compare whole weeks, so hourly is plenty.
+	alertBaselineInterval = time.Hour
+
+	// alertBaselineWeeks is how many weeks before the current one make up
+	// an alert name's baseline.
+	alertBaselineWeeks = 4
+
+	// alertDeviationRatio is how many times its weekly baseline an alert
+	// must fire in the last seven days to count as deviating.
+	alertDeviationRatio = 2.0
+
+	// alertDeviationMinAlerts keeps rare alerts (one last week, two this
+	// week) from being flagged.
+	alertDeviationMinAlerts = 5
+
+	// alertDeviationReason marks the timeline notes of volume deviations.
+	alertDeviationReason = "alert_volume_deviation"
+)
+
+// alertBaselineWeek is the length of one baseline week.
+const alertBaselineWeek = 7 * 24 * time.Hour
+
+// AlertBaselineService keeps weekly volume baselines per alert name and
+// flags alert names firing far more than usual. Each open incident with an
+// alert of a deviating name gets one informational note on its timeline, so
+// responders see "this alert is firing far more than usual" without going
+// to the analytics.
+type AlertBaselineService struct {
+	db       *gorm.DB
+	timeline IncidentTimelineRecorder // optional; receives the deviation notes
+	now      func() time.Time
+}
+
+// NewAlertBaselineService creates the baseline service.
+func NewAlertBaselineService(db *gorm.DB) *AlertBaselineService {
+	return &AlertBaselineService{db: db, now: time.Now}
+}
+
+// SetIncidentTimeline wires the timeline that receives deviation notes.
+// Optional.
+func (s *AlertBaselineService) SetIncidentTimeline(t IncidentTimelineRecorder) {
+	s.timeline = t
+}
+
+// alertNameCount is one row of a per-name alert count.
+type alertNameCount struct {
+	AlertName string
+	Count     int64
+}
+
+// ComputeBaselines recomputes the baseline of every alert name seen in the
+// last alertBaselineWeeks+1 weeks and returns the deviating ones. A name's
+// baseline spans the weeks since it first fired in that window, so a new
+// alert is not averaged over weeks it did not exist; a name first seen this
+// week has no baseline and never deviates.
+func (s *AlertBaselineService) ComputeBaselines(ctx context.Context) ([]database.AlertBaseline, error) {
+	now := s.now()
+	currentStart := now.Add(-alertBaselineWeek)
+
+	current, err := s.countByName(ctx, currentStart, now)
+	if err != nil {
+		return nil, err
+	}
+	baselines := make(map[string]*database.AlertBaseline, len(current))
+	get := func(name string) *database.AlertBaseline {
+		if b, ok := baselines[name]; ok {
+			return b
+		}
+		b := &database.AlertBaseline{AlertName: name, ComputedAt: now}
+		baselines[name] = b
+		return b
+	}
+	for name, count := range current {
+		get(name).CurrentWeek = count
+	}
+
+	// Week 1 is the week before the current one; a name's baseline runs
+	// back to the oldest week it fired in.
+	totals := make(map[string]int64)
+	for w := 1; w <= alertBaselineWeeks; w++ {
+		end := currentStart.Add(-time.Duration(w-1) * alertBaselineWeek)
+		counts, err := s.countByName(ctx, end.Add(-alertBaselineWeek), end)
+		if err != nil {
+			return nil, err
+		}
+		for name, count := range counts {
+			totals[name] += count
+			get(name).BaselineWeeks = w
+		}
+	}
+
+	var deviating []database.AlertBaseline
+	rows := make([]database.AlertBaseline, 0, len(baselines))
+	for name, b := range baselines {
+		if b.BaselineWeeks > 0 {
+			b.WeeklyAverage = float64(totals[name]) / float64(b.BaselineWeeks)
+			b.Ratio = float64(b.CurrentWeek) / b.WeeklyAverage
+			b.Deviating = b.CurrentWeek >= alertDeviationMinAlerts && b.Ratio >= alertDeviationRatio
+		}
+		rows = append(rows, *b)
+		if b.Deviating {
+			deviating = append(deviating, *b)
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "alert_name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"weekly_average", "baseline_weeks", "current_week", "ratio", "deviating", "computed_at"}),
+			}).Create(&rows).Error; err != nil {
+				return fmt.Errorf("save alert baselines: %w", err)
+			}
+		}
+		// Names that have not fired in the whole window drop out.
+		if err := tx.Where("computed_at < ?", now).Delete(&database.AlertBaseline{}).Error; err != nil {
+			return fmt.Errorf("prune alert baselines: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deviating, nil
+}
+
+// countByName counts alerts per name fired in [from, to).
+func (s *AlertBaselineService) countByName(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	var rows []alertNameCount
+	if err := s.db.WithContext(ctx).Model(&database.Alert{}).
+		Select("alert_name, COUNT(*) AS count").
+		Where("fired_at >= ? AND fired_at < ? AND alert_name <> ''", from, to).
+		Group("alert_name").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("count alerts by name: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		counts[r.AlertName] = r.Count
+	}
+	return counts, nil
+}
+
+// FlagDeviations notes a volume deviation on every open incident with an
+// alert of a deviating name fired in the last seven days, once per incident
+// and alert name. Returns how many notes it added.
+func (s *AlertBaselineService) FlagDeviations(ctx context.Context, deviating []database.AlertBaseline) (int, error) {
+	if s.timeline == nil {
+		return 0, nil
+	}
+	since := s.now().Add(-alertBaselineWeek)
+	flagged := 0
+	for _, b := range deviating {
+		var incidentUUIDs []string
+		if err := s.db.WithContext(ctx).Model(&database.Alert{}).
+			Joins("JOIN incidents ON incidents.uuid = alerts.incident_uuid").
+			Where("alerts.alert_name = ? AND alerts.fired_at >= ?", b.AlertName, since).
+			Where("incidents.status IN ?", []database.IncidentStatus{
+				database.IncidentStatusPending, database.IncidentStatusRunning, database.IncidentStatusDiagnosed,
+				database.IncidentStatusCompleted, database.IncidentStatusMonitor,
+			}).
+			Distinct().Pluck("alerts.incident_uuid", &incidentUUIDs).Error; err != nil {
+			return flagged, fmt.Errorf("list incidents for %s: %w", b.AlertName, err)
+		}
+		for _, incidentUUID := range incidentUUIDs {
+			var noted int64
+			if err := s.db.WithContext(ctx).Model(&database.IncidentEvent{}).
+				Where("incident_uuid = ? AND details->>'reason' = ? AND details->>'alert_name' = ?",
+					incidentUUID, alertDeviationReason, b.AlertName).
+				Count(&noted).Error; err != nil {
+				return flagged, fmt.Errorf("check deviation note on %s: %w", incidentUUID, err)
+			}
+			if noted > 0 {
+				continue
+			}
+			s.timeline.RecordEvent(database.IncidentEvent{
+				IncidentUUID: incidentUUID,
+				Type:         database.IncidentEventNote,
+				Summary: fmt.Sprintf("%s is firing far more than usual: %d times in the last 7 days, %.1fx its weekly average of %.1f",
+					b.AlertName, b.CurrentWeek, b.Ratio, b.WeeklyAverage),
+				Details: database.JSONB{
+					"reason":         alertDeviationReason,
+					"alert_name":     b.AlertName,
+					"current_week":   b.CurrentWeek,
+					"weekly_average": b.WeeklyAverage,
+					"baseline_weeks": b.BaselineWeeks,
+					"ratio":          b.Ratio,
+				},
+				Actor: "system",
+			})
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// ListBaselines returns the stored baselines, most deviating first. With
+// deviatingOnly only names currently flagged are returned.
+func (s *AlertBaselineService) ListBaselines(ctx context.Context, deviatingOnly bool) ([]database.AlertBaseline, error) {
+	query := s.db.WithContext(ctx).Order("ratio DESC, current_week DESC, alert_name ASC")
+	if deviatingOnly {
+		query = query.Where("deviating = ?", true)
+	}
+	baselines := []database.AlertBaseline{}
+	if err := query.Find(&baselines).Error; err != nil {
+		return nil, fmt.Errorf("list alert baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+// run recomputes the baselines and flags deviations once.
+func (s *AlertBaselineService) run(ctx context.Context) error {
+	deviating, err := s.ComputeBaselines(ctx)
+	if err != nil {
+		return err
+	}
+	flagged, err := s.FlagDeviations(ctx, deviating)
+	if flagged > 0 || len(deviating) > 0 {
+		slog.Info("computed alert baselines", "deviating", len(deviating), "incidents_flagged", flagged)
+	}
+	return err
+}
+
+// StartBackgroundJob computes the baselines once at startup, then on a fixed
+// ticker until ctx is cancelled.
+func (s *AlertBaselineService) StartBackgroundJob(ctx context.Context) {
+	slog.Info("starting alert baseline service")
+
+	if err := s.run(ctx); err != nil {
+		slog.Error("initial alert baseline run failed", "error", err)
+	}
+
+	ticker := time.NewTicker(alertBaselineInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("alert baseline service stopped")
+			return
+		case <-ticker.C:
+			if err := s.run(ctx); err != nil {
+				slog.Error("alert baseline run failed", "error", err)
+			}
+		}
+	}
+}
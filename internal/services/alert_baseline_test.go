@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAlertBaselines(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Alert{}, &database.Incident{}, &database.IncidentLogChunk{}, &database.IncidentEvent{}, &database.AlertBaseline{})
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	svc := NewAlertBaselineService(db)
+	svc.now = func() time.Time { return now }
+	svc.SetIncidentTimeline(NewIncidentTimelineService(db))
+
+	for _, inc := range []database.Incident{
+		{UUID: "open", Source: "test", Status: database.IncidentStatusCompleted},
+		{UUID: "closed", Source: "test", Status: database.IncidentStatusClosed},
+	} {
+		if err := db.Create(&inc).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := 0
+	fire := func(name, incident string, count int, at time.Time) {
+		t.Helper()
+		for i := 0; i < count; i++ {
+			n++
+			alert := database.Alert{UUID: fmt.Sprintf("a-%d", n), IncidentUUID: incident, AlertName: name, FiredAt: at.Add(time.Duration(i) * time.Minute)}
+			if err := db.Create(&alert).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	day := 24 * time.Hour
+	// DiskFull: 3 a week for four weeks, then 12 this week.
+	for w := 1; w <= 4; w++ {
+		fire("DiskFull", "closed", 3, now.Add(-time.Duration(w)*7*day-day))
+	}
+	fire("DiskFull", "open", 6, now.Add(-day))
+	fire("DiskFull", "closed", 6, now.Add(-2*day))
+	// HighCPU: first seen two weeks ago, steady.
+	fire("HighCPU", "closed", 5, now.Add(-8*day))
+	fire("HighCPU", "closed", 5, now.Add(-15*day))
+	fire("HighCPU", "open", 6, now.Add(-day))
+	// NewAlert: first seen this week, so no baseline.
+	fire("NewAlert", "open", 10, now.Add(-day))
+	// Rare: one last week, three this week.
+	fire("Rare", "open", 1, now.Add(-8*day))
+	fire("Rare", "open", 3, now.Add(-day))
+	// Old: only before the window.
+	fire("Old", "closed", 4, now.Add(-60*day))
+	if err := db.Create(&database.AlertBaseline{AlertName: "Old", ComputedAt: now.Add(-time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	deviating, err := svc.ComputeBaselines(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviating) != 1 || deviating[0].AlertName != "DiskFull" {
+		t.Fatalf("deviating = %+v, want only DiskFull", deviating)
+	}
+	if d := deviating[0]; d.WeeklyAverage != 3 || d.BaselineWeeks != 4 || d.CurrentWeek != 12 || d.Ratio != 4 {
+		t.Errorf("DiskFull baseline = %+v", d)
+	}
+
+	all, err := svc.ListBaselines(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]database.AlertBaseline{}
+	for _, b := range all {
+		byName[b.AlertName] = b
+	}
+	if len(all) != 4 || all[0].AlertName != "DiskFull" {
+		t.Errorf("baselines = %+v, want 4 with DiskFull first", all)
+	}
+	if _, ok := byName["Old"]; ok {
+		t.Error("baseline of an alert that stopped firing was not pruned")
+	}
+	if b := byName["HighCPU"]; b.BaselineWeeks != 2 || b.WeeklyAverage != 5 || b.Deviating {
+		t.Errorf("HighCPU baseline = %+v", b)
+	}
+	if b := byName["NewAlert"]; b.BaselineWeeks != 0 || b.Ratio != 0 || b.Deviating {
+		t.Errorf("NewAlert baseline = %+v", b)
+	}
+	if b := byName["Rare"]; b.Ratio != 3 || b.Deviating {
+		t.Errorf("Rare baseline = %+v, want below the minimum volume", b)
+	}
+
+	for run := 0; run < 2; run++ {
+		flagged, err := svc.FlagDeviations(ctx, deviating)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 - run; flagged != want {
+			t.Errorf("run %d flagged %d incidents, want %d", run, flagged, want)
+		}
+	}
+	var events []database.IncidentEvent
+	db.Find(&events)
+	if len(events) != 1 || events[0].IncidentUUID != "open" || events[0].Details["alert_name"] != "DiskFull" {
+		t.Errorf("events = %+v, want one note on the open incident", events)
+	}
+
+	deviatingOnly, err := svc.ListBaselines(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviatingOnly) != 1 {
+		t.Errorf("deviating baselines = %d, want 1", len(deviatingOnly))
+	}
+}
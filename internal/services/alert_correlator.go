@@ -18,6 +18,11 @@ const (
 	correlationTimeout       = 15 * time.Second
 	correlationMaxCandidates = 25
 	correlationThreshold     = 0.7
+	// correlationFallbackConfidence is assigned to deterministic fingerprint
+	// matches made when the LLM is unavailable. An identical fingerprint means
+	// the same rule on the same host from the same source, which the prompt
+	// rubric scores 0.9-1.0.
+	correlationFallbackConfidence = 0.9
 )
 
 // CorrelationConfig holds parameters for the AI correlation gate.
@@ -31,6 +36,11 @@ type CorrelationVerdict struct {
 	IncidentUUID string  `json:"incident_uuid"`
 	Confidence   float64 `json:"confidence"`
 	Reasoning    string  `json:"reasoning"`
+	// ParentIncidentUUID names a candidate the alert is a downstream symptom
+	// of (a cascading failure) when it is not a recurrence. The new incident
+	// is linked as that candidate's child. Empty when Correlated is true.
+	ParentIncidentUUID string  `json:"parent_incident_uuid,omitempty"`
+	ParentConfidence   float64 `json:"parent_confidence,omitempty"`
 }
 
 // IsConfident returns true when the verdict indicates a match with confidence
@@ -39,6 +49,12 @@ func (v CorrelationVerdict) IsConfident(threshold float64) bool {
 	return v.Correlated && v.Confidence >= threshold
 }
 
+// IsConfidentParent returns true when the verdict names a parent incident
+// with confidence at or above the supplied threshold.
+func (v CorrelationVerdict) IsConfidentParent(threshold float64) bool {
+	return !v.Correlated && v.ParentIncidentUUID != "" && v.ParentConfidence >= threshold
+}
+
 // AlertCorrelator runs a one-shot LLM call to decide whether an incoming alert
 // is a recurrence of a recent incident rather than a new event.
 type AlertCorrelator struct {
@@ -89,8 +105,10 @@ type candidateRow struct {
 //   - nil caller
 //   - zero candidates (no LLM call made)
 //
-// ErrWorkerNotConnected is returned as-is so callers can fail-open cleanly.
-// Parse failures are logged at debug and treated as "no match".
+// When the LLM cannot be used (settings missing, call error or timeout,
+// unparseable output) the correlator falls back to fingerprintFallbackVerdict.
+// If that finds no match, ErrWorkerNotConnected is returned as-is so callers
+// can fail-open cleanly, and parse failures are treated as "no match".
 func (c *AlertCorrelator) Correlate(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (CorrelationVerdict, error) {
 	noMatch := CorrelationVerdict{}
 
@@ -114,16 +132,28 @@ func (c *AlertCorrelator) Correlate(ctx context.Context, sourceUUID string, aler
 		return noMatch, nil
 	}
 
+	// Any failure to get a usable LLM verdict falls back to an exact
+	// fingerprint match against the candidates; only when that also finds
+	// nothing is the original outcome (error or no-match) returned.
+	fallback := func(cause error) (CorrelationVerdict, error) {
+		if v, ok := fingerprintFallbackVerdict(sourceUUID, alert, candidates); ok {
+			slog.Info("alert correlator: LLM unavailable, matched by fingerprint",
+				"incident_uuid", v.IncidentUUID, "cause", cause)
+			return v, nil
+		}
+		return noMatch, cause
+	}
+
 	settings, err := database.GetLLMSettings()
 	if err != nil {
-		return noMatch, fmt.Errorf("correlate: load llm settings: %w", err)
+		return fallback(fmt.Errorf("correlate: load llm settings: %w", err))
 	}
 	if settings == nil || settings.APIKey == "" {
-		return noMatch, fmt.Errorf("correlate: LLM settings not configured")
+		return fallback(fmt.Errorf("correlate: LLM settings not configured"))
 	}
 	worker := BuildLLMSettingsForWorker(settings)
 	if worker == nil {
-		return noMatch, fmt.Errorf("correlate: could not build LLM worker settings")
+		return fallback(fmt.Errorf("correlate: could not build LLM worker settings"))
 	}
 
 	userPrompt := buildCorrelationUserPrompt(alert, candidates)
@@ -131,39 +161,68 @@ func (c *AlertCorrelator) Correlate(ctx context.Context, sourceUUID string, aler
 	callCtx, cancel := context.WithTimeout(ctx, correlationTimeout)
 	defer cancel()
 
-	raw, err := c.caller.OneShotLLM(callCtx, worker, correlationSystemPrompt, userPrompt, 250, 0.0)
+	raw, err := c.caller.OneShotLLM(callCtx, worker, correlationSystemPrompt, userPrompt, 300, 0.0)
 	if err != nil {
 		if errors.Is(err, ErrWorkerNotConnected) {
-			return noMatch, err
+			return fallback(err)
 		}
-		return noMatch, fmt.Errorf("correlate: llm call: %w", err)
+		return fallback(fmt.Errorf("correlate: llm call: %w", err))
 	}
 
 	verdict, err := parseCorrelationVerdict(raw)
 	if err != nil {
 		slog.Debug("alert correlator: invalid response", "err", err, "raw", raw)
+		if v, _ := fallback(err); v.Correlated {
+			return v, nil
+		}
 		return noMatch, nil
 	}
 
 	// Hallucination guard: reject any UUID the LLM invented that was not in the
 	// candidate set we sent it.
-	if verdict.Correlated {
-		found := false
+	isCandidate := func(uuid string) bool {
 		for _, cand := range candidates {
-			if cand.UUID == verdict.IncidentUUID {
-				found = true
-				break
+			if cand.UUID == uuid {
+				return true
 			}
 		}
-		if !found {
-			slog.Debug("alert correlator: hallucinated UUID rejected", "uuid", verdict.IncidentUUID)
-			return noMatch, nil
-		}
+		return false
+	}
+	if verdict.Correlated && !isCandidate(verdict.IncidentUUID) {
+		slog.Debug("alert correlator: hallucinated UUID rejected", "uuid", verdict.IncidentUUID)
+		return noMatch, nil
+	}
+	if verdict.ParentIncidentUUID != "" && !isCandidate(verdict.ParentIncidentUUID) {
+		slog.Debug("alert correlator: hallucinated parent UUID rejected", "uuid", verdict.ParentIncidentUUID)
+		verdict.ParentIncidentUUID = ""
+		verdict.ParentConfidence = 0
 	}
 
 	return verdict, nil
 }
 
+// fingerprintFallbackVerdict is the deterministic correlator used when the
+// LLM cannot produce a verdict: it matches the most recent candidate whose
+// stored alert fingerprint equals the incoming alert's (same source, alert
+// name and host, case-insensitive). Candidates are ordered newest first.
+func fingerprintFallbackVerdict(sourceUUID string, alert alerts.NormalizedAlert, candidates []candidateRow) (CorrelationVerdict, bool) {
+	if alert.AlertName == "" {
+		return CorrelationVerdict{}, false
+	}
+	fingerprint := ComputeAlertFingerprint(sourceUUID, alert.AlertName, alert.TargetHost)
+	for _, cand := range candidates {
+		if cand.AlertFingerprint == fingerprint {
+			return CorrelationVerdict{
+				Correlated:   true,
+				IncidentUUID: cand.UUID,
+				Confidence:   correlationFallbackConfidence,
+				Reasoning:    "LLM unavailable; identical alert fingerprint (same source, alert and host)",
+			}, true
+		}
+	}
+	return CorrelationVerdict{}, false
+}
+
 // fetchCandidates queries recent alert-sourced incidents that are viable targets
 // for recurrence attachment: active incidents (pending/running/diagnosed),
 // monitor incidents whose monitor window has not yet expired, and completed
@@ -173,21 +232,13 @@ func (c *AlertCorrelator) Correlate(ctx context.Context, sourceUUID string, aler
 // perspective even though status reads "completed", so they must stay
 // eligible until ResolveAlertTx promotes them to monitor.
 func (c *AlertCorrelator) fetchCandidates(ctx context.Context) ([]candidateRow, error) {
-	now := time.Now()
-	activeStatuses := []string{
-		string(database.IncidentStatusPending),
-		string(database.IncidentStatusRunning),
-		string(database.IncidentStatusDiagnosed),
-	}
+	cond, args := openAlertIncidentCondition(time.Now())
 
 	var rows []candidateRow
 	err := c.db.WithContext(ctx).
 		Model(&database.Incident{}).
 		Select("uuid, title, status, response, context, started_at, alert_fingerprint").
-		Where("source_kind = ? AND (status IN ? OR (status = ? AND monitor_until >= ?) OR (status = ? AND EXISTS (SELECT 1 FROM alerts WHERE alerts.incident_uuid = incidents.uuid AND alerts.status = ? AND alerts.resolved_at IS NULL)))",
-			database.IncidentSourceKindAlert, activeStatuses,
-			string(database.IncidentStatusMonitor), now,
-			string(database.IncidentStatusCompleted), string(database.AlertStatusFiring)).
+		Where(cond, args...).
 		Order("started_at DESC").
 		Limit(correlationMaxCandidates).
 		Scan(&rows).Error
@@ -273,8 +324,25 @@ func parseCorrelationVerdict(raw string) (CorrelationVerdict, error) {
 	if v.Confidence > 1 {
 		v.Confidence = 1
 	}
+	if v.ParentConfidence < 0 {
+		v.ParentConfidence = 0
+	}
+	if v.ParentConfidence > 1 {
+		v.ParentConfidence = 1
+	}
 	v.IncidentUUID = strings.TrimSpace(v.IncidentUUID)
-	v.Reasoning = strings.TrimSpace(v.Reasoning)
+	v.ParentIncidentUUID = strings.TrimSpace(v.ParentIncidentUUID)
+	v.Reasoning = truncateForPrompt(strings.TrimSpace(v.Reasoning), 200)
+	if v.Correlated && v.IncidentUUID == "" {
+		return CorrelationVerdict{}, fmt.Errorf("correlated verdict without incident_uuid")
+	}
+	if !v.Correlated {
+		v.IncidentUUID = ""
+	}
+	if v.Correlated || v.ParentIncidentUUID == "" {
+		v.ParentIncidentUUID = ""
+		v.ParentConfidence = 0
+	}
 
 	return v, nil
 }
@@ -282,13 +350,15 @@ func parseCorrelationVerdict(raw string) (CorrelationVerdict, error) {
 const correlationSystemPrompt = `You decide whether an incoming alert is a RECURRENCE of a recent incident rather than a new event that needs its own investigation.
 
 Return STRICT JSON:
-  {"correlated": true|false, "incident_uuid": "<UUID or empty string>", "confidence": <0..1>, "reasoning": "<≤200 char explanation>"}
+  {"correlated": true|false, "incident_uuid": "<UUID or empty string>", "confidence": <0..1>, "reasoning": "<≤200 char explanation>", "parent_incident_uuid": "<UUID or empty string>", "parent_confidence": <0..1>}
 
 Rules:
 - Set correlated=true ONLY when the alert describes the same failure on the same host/service as one of the listed candidates.
 - incident_uuid MUST be one of the UUIDs from the candidate list. If correlated=false, set it to "".
 - Do NOT correlate alerts that have different alert names unless the context makes it unambiguous they are the same root cause.
 - When uncertain, prefer correlated=false (creating a new incident is safe; false deduplication hides real events).
+- When correlated=false but the alert is a likely DOWNSTREAM EFFECT of an active candidate (a cascading failure: e.g. the candidate is a database outage and the alert is API errors on a service that depends on it), set parent_incident_uuid to that candidate and parent_confidence to how sure you are. The alert still gets its own incident, linked as a child. Otherwise set parent_incident_uuid to "" and parent_confidence to 0.
+- parent_incident_uuid MUST be one of the UUIDs from the candidate list and MUST be "" when correlated=true.
 
 Confidence:
   0.9-1.0: identical alert name + host, active incident, timing consistent
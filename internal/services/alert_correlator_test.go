@@ -23,7 +23,7 @@ func setupCorrelatorDB(t *testing.T) *gorm.DB {
 		t.Fatalf("sqlite open: %v", err)
 	}
 	if err := db.AutoMigrate(
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 		&database.LLMSettings{},
 		&database.GeneralSettings{},
@@ -229,6 +229,34 @@ func TestAlertCorrelator_HallucinatedUUID_ForcedFalse(t *testing.T) {
 	}
 }
 
+func TestAlertCorrelator_CascadingParent(t *testing.T) {
+	db := setupCorrelatorDB(t)
+	seedIncident(t, db, "inc-db", "Primary database down", "running", time.Now().Add(-5*time.Minute))
+	seedCorrelationSettings(t, db, true)
+
+	caller := &fakeOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return `{"correlated":false,"incident_uuid":"","confidence":0.2,"parent_incident_uuid":"inc-db","parent_confidence":0.85,"reasoning":"api errors caused by db outage"}`, nil
+	}
+	c := newCorrelator(t, caller, db)
+
+	verdict, err := c.Correlate(context.Background(), "src-1", alerts.NormalizedAlert{AlertName: "APIErrorRate"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.IsConfident(0.7) || !verdict.IsConfidentParent(0.7) || verdict.ParentIncidentUUID != "inc-db" {
+		t.Errorf("expected confident parent inc-db, got %+v", verdict)
+	}
+
+	caller.respond = func(_ context.Context) (string, error) {
+		return `{"correlated":false,"incident_uuid":"","confidence":0.2,"parent_incident_uuid":"inc-invented","parent_confidence":0.9,"reasoning":"hallucinated"}`, nil
+	}
+	verdict, _ = c.Correlate(context.Background(), "src-1", alerts.NormalizedAlert{AlertName: "APIErrorRate"})
+	if verdict.ParentIncidentUUID != "" || verdict.IsConfidentParent(0.7) {
+		t.Errorf("hallucinated parent must be dropped, got %+v", verdict)
+	}
+}
+
 func TestAlertCorrelator_FailedIncidentExcluded(t *testing.T) {
 	db := setupCorrelatorDB(t)
 	// Only a failed incident — should not be a candidate.
@@ -287,6 +315,100 @@ func TestAlertCorrelator_MalformedJSON_Silent(t *testing.T) {
 	}
 }
 
+// seedFingerprintedIncident inserts an active alert incident whose stored
+// fingerprint matches (sourceUUID, alertName, host).
+func seedFingerprintedIncident(t *testing.T, db *gorm.DB, uuid, sourceUUID, alertName, host string, startedAt time.Time) {
+	t.Helper()
+	seedIncident(t, db, uuid, alertName+" on "+host, "running", startedAt)
+	if err := db.Model(&database.Incident{}).Where("uuid = ?", uuid).
+		Update("alert_fingerprint", ComputeAlertFingerprint(sourceUUID, alertName, host)).Error; err != nil {
+		t.Fatalf("set fingerprint: %v", err)
+	}
+}
+
+func TestAlertCorrelator_LLMError_FallsBackToFingerprint(t *testing.T) {
+	db := setupCorrelatorDB(t)
+	seedFingerprintedIncident(t, db, "inc-old", "src-1", "CPUHigh", "web01", time.Now().Add(-30*time.Minute))
+	seedFingerprintedIncident(t, db, "inc-new", "src-1", "CPUHigh", "web01", time.Now().Add(-5*time.Minute))
+	seedFingerprintedIncident(t, db, "inc-other", "src-1", "DiskFull", "web01", time.Now().Add(-time.Minute))
+	seedCorrelationSettings(t, db, true)
+
+	caller := &fakeOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return "", context.DeadlineExceeded
+	}
+	c := newCorrelator(t, caller, db)
+
+	verdict, err := c.Correlate(context.Background(), "src-1", alerts.NormalizedAlert{AlertName: "cpuhigh", TargetHost: "WEB01"})
+	if err != nil {
+		t.Fatalf("fallback match should clear the error, got %v", err)
+	}
+	if !verdict.IsConfident(c.Threshold()) || verdict.IncidentUUID != "inc-new" {
+		t.Errorf("expected confident fallback match to newest incident, got %+v", verdict)
+	}
+}
+
+func TestAlertCorrelator_WorkerNotConnected_FallsBackToFingerprint(t *testing.T) {
+	db := setupCorrelatorDB(t)
+	seedFingerprintedIncident(t, db, "inc-fp", "src-1", "CPUHigh", "web01", time.Now().Add(-5*time.Minute))
+	seedCorrelationSettings(t, db, true)
+
+	caller := &fakeOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return "", ErrWorkerNotConnected
+	}
+	c := newCorrelator(t, caller, db)
+
+	verdict, err := c.Correlate(context.Background(), "src-1", alerts.NormalizedAlert{AlertName: "CPUHigh", TargetHost: "web01"})
+	if err != nil {
+		t.Fatalf("expected nil error on fallback match, got %v", err)
+	}
+	if verdict.IncidentUUID != "inc-fp" {
+		t.Errorf("expected fallback match to inc-fp, got %+v", verdict)
+	}
+}
+
+func TestAlertCorrelator_MalformedJSON_FallsBackToFingerprint(t *testing.T) {
+	db := setupCorrelatorDB(t)
+	seedFingerprintedIncident(t, db, "inc-fp", "src-1", "CPUHigh", "web01", time.Now().Add(-5*time.Minute))
+	seedCorrelationSettings(t, db, true)
+
+	caller := &fakeOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return `{"correlated": true}`, nil
+	}
+	c := newCorrelator(t, caller, db)
+
+	verdict, err := c.Correlate(context.Background(), "src-1", alerts.NormalizedAlert{AlertName: "CPUHigh", TargetHost: "web01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Correlated || verdict.IncidentUUID != "inc-fp" {
+		t.Errorf("expected fallback match to inc-fp, got %+v", verdict)
+	}
+}
+
+func TestAlertCorrelator_LLMError_NoFingerprintMatch(t *testing.T) {
+	db := setupCorrelatorDB(t)
+	seedFingerprintedIncident(t, db, "inc-fp", "src-1", "CPUHigh", "web01", time.Now().Add(-5*time.Minute))
+	seedCorrelationSettings(t, db, true)
+
+	caller := &fakeOneShotLLMCaller{}
+	caller.respond = func(_ context.Context) (string, error) {
+		return "", errors.New("boom")
+	}
+	c := newCorrelator(t, caller, db)
+
+	// Different source: same alert/host on another source must not match.
+	verdict, err := c.Correlate(context.Background(), "src-2", alerts.NormalizedAlert{AlertName: "CPUHigh", TargetHost: "web01"})
+	if err == nil {
+		t.Fatal("expected the LLM error when no fingerprint matches")
+	}
+	if verdict.Correlated {
+		t.Errorf("expected no match, got %+v", verdict)
+	}
+}
+
 // ---- unit tests for helpers ----
 
 func TestParseCorrelationVerdict_StripsCodeFence(t *testing.T) {
@@ -311,6 +433,33 @@ func TestParseCorrelationVerdict_ClampsConfidence(t *testing.T) {
 	}
 }
 
+func TestParseCorrelationVerdict_ParentIgnoredWhenCorrelated(t *testing.T) {
+	v, err := parseCorrelationVerdict(`{"correlated":true,"incident_uuid":"a","confidence":0.9,"parent_incident_uuid":"b","parent_confidence":0.9}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v.ParentIncidentUUID != "" || v.ParentConfidence != 0 {
+		t.Errorf("a correlated verdict must not carry a parent, got %+v", v)
+	}
+	v, _ = parseCorrelationVerdict(`{"correlated":false,"parent_incident_uuid":" b ","parent_confidence":1.7}`)
+	if v.ParentIncidentUUID != "b" || v.ParentConfidence != 1.0 {
+		t.Errorf("expected trimmed parent with clamped confidence, got %+v", v)
+	}
+}
+
+func TestParseCorrelationVerdict_CorrelatedRequiresUUID(t *testing.T) {
+	if _, err := parseCorrelationVerdict(`{"correlated":true,"incident_uuid":"  ","confidence":0.9}`); err == nil {
+		t.Error("expected error for correlated verdict without incident_uuid")
+	}
+	v, err := parseCorrelationVerdict(`{"correlated":false,"incident_uuid":"stray","confidence":0.2}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v.IncidentUUID != "" {
+		t.Errorf("expected incident_uuid cleared on non-correlated verdict, got %q", v.IncidentUUID)
+	}
+}
+
 func TestParseCorrelationVerdict_EmptyInput(t *testing.T) {
 	_, err := parseCorrelationVerdict("")
 	if err == nil {
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// FingerprintDedupSettingKey is the AlertSourceInstance.Settings key that
+// controls fingerprint deduplication for the source. It is on unless set to
+// false.
+const FingerprintDedupSettingKey = "fingerprint_dedup"
+
+// ParseFingerprintDedup reports whether a source deduplicates firing alerts
+// by their source fingerprint before correlation. Absent means enabled.
+func ParseFingerprintDedup(settings map[string]interface{}) (bool, error) {
+	return parseBoolSetting(settings, FingerprintDedupSettingKey, true)
+}
+
+// GroupingKeySettingKey is the AlertSourceInstance.Settings key holding an
+// optional template, e.g. "{{.Host}}/{{.AlertName}}", whose rendering
+// replaces the source fingerprint of the source's alerts.
+const GroupingKeySettingKey = "grouping_key_template"
+
+// maxGroupingKeyTemplateBytes bounds a grouping key template; keys are
+// built from a few alert fields, not prose.
+const maxGroupingKeyTemplateBytes = 500
+
+// ParseGroupingKeyTemplate returns a source's grouping key template, or ""
+// when it has none. The template must render against sample alert data.
+func ParseGroupingKeyTemplate(settings map[string]interface{}) (string, error) {
+	raw, ok := settings[GroupingKeySettingKey]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	body, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", GroupingKeySettingKey)
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", nil
+	}
+	if len(body) > maxGroupingKeyTemplateBytes {
+		return "", fmt.Errorf("%s exceeds %d bytes", GroupingKeySettingKey, maxGroupingKeyTemplateBytes)
+	}
+	if _, err := RenderAlertTemplate(body, SampleAlertTemplateVars()); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", GroupingKeySettingKey, err)
+	}
+	return body, nil
+}
+
+// GroupingFingerprint renders a grouping key template for an alert and
+// returns the fingerprint that stands in for the source's own: the first 32
+// hex characters of the key's sha256, so any key fits the alerts column.
+// A key that renders blank gives "", meaning keep the source fingerprint.
+func GroupingFingerprint(template string, vars AlertTemplateVars) (string, error) {
+	key, err := RenderAlertTemplate(template, vars)
+	if err != nil {
+		return "", err
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", nil
+	}
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])[:32], nil
+}
+
+// parseBoolSetting reads a boolean source setting. The form posts checkboxes
+// as booleans, but "true"/"false" strings are accepted too; absent or blank
+// values give def.
+func parseBoolSetting(settings map[string]interface{}, key string, def bool) (bool, error) {
+	switch v := settings[key].(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return def, nil
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("%s must be true or false", key)
+		}
+		return enabled, nil
+	default:
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+}
+
+// openAlertIncidentCondition selects alert-sourced incidents that are still
+// open from the alerting system's point of view: active, monitored within
+// their window, or completed with an alert still firing (see
+// countFiringAlerts). Incidents declined over budget or cancelled count as
+// completed here, so a re-firing alert joins them instead of opening another
+// one.
+// It expects the incidents table unaliased.
+func openAlertIncidentCondition(now time.Time) (string, []interface{}) {
+	return "incidents.source_kind = ? AND (incidents.status IN ? OR (incidents.status = ? AND incidents.monitor_until >= ?) OR " +
+			"(incidents.status IN ? AND EXISTS (SELECT 1 FROM alerts fa WHERE fa.incident_uuid = incidents.uuid AND fa.status = ? AND fa.resolved_at IS NULL)))",
+		[]interface{}{
+			database.IncidentSourceKindAlert,
+			[]string{
+				string(database.IncidentStatusPending),
+				string(database.IncidentStatusRunning),
+				string(database.IncidentStatusDiagnosed),
+			},
+			string(database.IncidentStatusMonitor), now,
+			[]string{
+				string(database.IncidentStatusCompleted),
+				string(database.IncidentStatusBudgetExceeded),
+				string(database.IncidentStatusCancelled),
+			},
+			string(database.AlertStatusFiring),
+		}
+}
+
+// FindOpenIncidentBySourceFingerprint returns the most recent open incident
+// with an alert from the same source carrying the alert's source
+// fingerprint, or "" when there is none. Alerts without a source
+// fingerprint never match.
+func (s *SkillService) FindOpenIncidentBySourceFingerprint(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (string, error) {
+	if alert.SourceFingerprint == "" {
+		return "", nil
+	}
+	cond, args := openAlertIncidentCondition(time.Now())
+	var incident database.Incident
+	err := s.db.WithContext(ctx).
+		Model(&database.Incident{}).
+		Select("incidents.uuid").
+		Joins("JOIN alerts ON alerts.incident_uuid = incidents.uuid").
+		Where("alerts.source_uuid = ? AND alerts.source_fingerprint = ?", sourceUUID, alert.SourceFingerprint).
+		Where(cond, args...).
+		Order("alerts.fired_at DESC").
+		Limit(1).
+		Take(&incident).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("find incident by source fingerprint: %w", err)
+	}
+	return incident.UUID, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestParseFingerprintDedup(t *testing.T) {
+	cases := []struct {
+		value   interface{}
+		want    bool
+		wantErr bool
+	}{
+		{nil, true, false},
+		{false, false, false},
+		{"false", false, false},
+		{"", true, false},
+		{"nope", false, true},
+	}
+	for _, tc := range cases {
+		got, err := ParseFingerprintDedup(map[string]interface{}{FingerprintDedupSettingKey: tc.value})
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("ParseFingerprintDedup(%v) = %v, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestGroupingKeyTemplate(t *testing.T) {
+	for _, bad := range []interface{}{42, "{{.Nope}}", "{{.Host"} {
+		if _, err := ParseGroupingKeyTemplate(map[string]interface{}{GroupingKeySettingKey: bad}); err == nil {
+			t.Errorf("ParseGroupingKeyTemplate(%v) accepted", bad)
+		}
+	}
+	tmpl, err := ParseGroupingKeyTemplate(map[string]interface{}{GroupingKeySettingKey: "{{.Host}}/{{.AlertName}}"})
+	if err != nil || tmpl == "" {
+		t.Fatalf("ParseGroupingKeyTemplate = %q, %v", tmpl, err)
+	}
+
+	first := NewAlertTemplateVars(alerts.NormalizedAlert{AlertName: "DiskFull", TargetHost: "db-1", SourceFingerprint: "a1"}, nil)
+	again := NewAlertTemplateVars(alerts.NormalizedAlert{AlertName: "DiskFull", TargetHost: "db-1", SourceFingerprint: "b2"}, nil)
+	other := NewAlertTemplateVars(alerts.NormalizedAlert{AlertName: "DiskFull", TargetHost: "db-2", SourceFingerprint: "a1"}, nil)
+	fp1, _ := GroupingFingerprint(tmpl, first)
+	fp2, _ := GroupingFingerprint(tmpl, again)
+	fp3, _ := GroupingFingerprint(tmpl, other)
+	if len(fp1) != 32 || fp1 != fp2 || fp1 == fp3 {
+		t.Errorf("fingerprints = %q, %q, %q; want equal for the same host and alert only", fp1, fp2, fp3)
+	}
+	if fp, err := GroupingFingerprint(`{{index .Labels "job"}}`, first); fp != "" || err != nil {
+		t.Errorf("blank key = %q, %v; want \"\" to keep the source fingerprint", fp, err)
+	}
+}
+
+func TestFindOpenIncidentBySourceFingerprint(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{})
+	svc := &SkillService{db: db}
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	seed := func(incidentUUID string, status database.IncidentStatus, monitorUntil *time.Time, sourceUUID, fingerprint string, firedAt time.Time, alertStatus database.AlertStatus) {
+		t.Helper()
+		if err := db.Create(&database.Incident{
+			UUID: incidentUUID, Status: status, SourceKind: database.IncidentSourceKindAlert, MonitorUntil: monitorUntil,
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&database.Alert{
+			UUID: incidentUUID + "-alert", IncidentUUID: incidentUUID, SourceUUID: sourceUUID,
+			SourceFingerprint: fingerprint, Fingerprint: incidentUUID, Status: alertStatus, FiredAt: firedAt,
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed("closed", database.IncidentStatusClosed, nil, "src", "fp-1", now, database.AlertStatusResolved)
+	seed("expired", database.IncidentStatusMonitor, &expired, "src", "fp-1", now, database.AlertStatusResolved)
+	seed("older", database.IncidentStatusRunning, nil, "src", "fp-1", now.Add(-time.Hour), database.AlertStatusFiring)
+	seed("newer", database.IncidentStatusDiagnosed, nil, "src", "fp-1", now.Add(-time.Minute), database.AlertStatusFiring)
+	seed("other-source", database.IncidentStatusRunning, nil, "src-2", "fp-2", now, database.AlertStatusFiring)
+
+	ctx := context.Background()
+	cases := []struct {
+		source, fingerprint, want string
+	}{
+		{"src", "fp-1", "newer"},
+		{"src", "fp-2", ""},
+		{"src-2", "fp-2", "other-source"},
+		{"src", "", ""},
+	}
+	for _, tc := range cases {
+		got, err := svc.FindOpenIncidentBySourceFingerprint(ctx, tc.source, alerts.NormalizedAlert{SourceFingerprint: tc.fingerprint})
+		if err != nil || got != tc.want {
+			t.Errorf("find(%s, %q) = %q, %v; want %q", tc.source, tc.fingerprint, got, err, tc.want)
+		}
+	}
+}
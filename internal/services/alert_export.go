@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strconv"
+	"time"
+)
+
+// AlertExportRow is one alert in GET /api/alerts/export, flattened with the
+// incident it is attached to. Severity is the incident's normalized
+// severity; alert rows do not store their own.
+type AlertExportRow struct {
+	UUID                  string     `json:"uuid"`
+	IncidentUUID          string     `json:"incident_uuid"`
+	IncidentTitle         string     `json:"incident_title"`
+	Source                string     `json:"source"`
+	SourceUUID            string     `json:"source_uuid"`
+	Severity              string     `json:"severity"`
+	Status                string     `json:"status"`
+	AlertName             string     `json:"alert_name"`
+	TargetHost            string     `json:"target_host"`
+	Fingerprint           string     `json:"fingerprint"`
+	FiredAt               time.Time  `json:"fired_at"`
+	ResolvedAt            *time.Time `json:"resolved_at,omitempty"`
+	Correlated            bool       `json:"correlated"`
+	CorrelationDecision   string     `json:"correlation_decision,omitempty"`
+	CorrelationConfidence *float64   `json:"correlation_confidence,omitempty"`
+}
+
+// AlertExportCSVHeader is the column order of AlertExportRow.CSVRecord.
+var AlertExportCSVHeader = []string{
+	"uuid", "incident_uuid", "incident_title", "source", "source_uuid", "severity", "status",
+	"alert_name", "target_host", "fingerprint", "fired_at", "resolved_at", "duration_seconds",
+	"correlated", "correlation_decision", "correlation_confidence",
+}
+
+// CSVRecord renders the row for AlertExportCSVHeader. Times are RFC3339 in
+// UTC; duration_seconds is empty while the alert is still firing.
+func (a *AlertExportRow) CSVRecord() []string {
+	resolvedAt, duration := "", ""
+	if a.ResolvedAt != nil {
+		resolvedAt = a.ResolvedAt.UTC().Format(time.RFC3339)
+		duration = strconv.FormatInt(int64(a.ResolvedAt.Sub(a.FiredAt).Seconds()), 10)
+	}
+	confidence := ""
+	if a.CorrelationConfidence != nil {
+		confidence = strconv.FormatFloat(*a.CorrelationConfidence, 'f', -1, 64)
+	}
+	return []string{
+		a.UUID, a.IncidentUUID, csvSafe(a.IncidentTitle), a.Source, a.SourceUUID, a.Severity, a.Status,
+		csvSafe(a.AlertName), csvSafe(a.TargetHost), a.Fingerprint, a.FiredAt.UTC().Format(time.RFC3339),
+		resolvedAt, duration, strconv.FormatBool(a.Correlated), a.CorrelationDecision, confidence,
+	}
+}
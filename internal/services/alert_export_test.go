@@ -0,0 +1,33 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAlertExportRowCSVRecord(t *testing.T) {
+	fired := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	resolved := fired.Add(90 * time.Second)
+	confidence := 0.8
+	row := AlertExportRow{
+		UUID: "a1", IncidentUUID: "i1", IncidentTitle: "=cmd()", Source: "alertmanager", Severity: "critical",
+		Status: "resolved", AlertName: "HighCPU", TargetHost: "web-01", FiredAt: fired, ResolvedAt: &resolved,
+		Correlated: true, CorrelationDecision: "linked", CorrelationConfidence: &confidence,
+	}
+	got := row.CSVRecord()
+	if len(got) != len(AlertExportCSVHeader) {
+		t.Fatalf("record has %d columns, header %d", len(got), len(AlertExportCSVHeader))
+	}
+	want := []string{"a1", "i1", "'=cmd()", "alertmanager", "", "critical", "resolved", "HighCPU", "web-01", "",
+		"2026-03-01T10:00:00Z", "2026-03-01T10:01:30Z", "90", "true", "linked", "0.8"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("record = %q\nwant     %q", got, want)
+	}
+
+	row.ResolvedAt, row.CorrelationConfidence = nil, nil
+	got = row.CSVRecord()
+	if got[11] != "" || got[12] != "" || got[15] != "" {
+		t.Errorf("firing alert record = %q, want empty resolved_at, duration and confidence", got)
+	}
+}
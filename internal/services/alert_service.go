@@ -1,14 +1,20 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrUnknownSkill is returned when an alert source is pinned to a skill that
+// does not exist or is a system skill.
+var ErrUnknownSkill = errors.New("unknown skill")
+
 // AlertService manages alert sources, instances, and alerts
 type AlertService struct {
 	db *gorm.DB
@@ -113,7 +119,7 @@ func (s *AlertService) EnsureAlertSourceType(name, displayName, description stri
 // ListInstances returns all alert source instances
 func (s *AlertService) ListInstances() ([]database.AlertSourceInstance, error) {
 	var instances []database.AlertSourceInstance
-	if err := s.db.Preload("AlertSourceType").Find(&instances).Error; err != nil {
+	if err := s.db.Preload("AlertSourceType").Preload("Skills").Find(&instances).Error; err != nil {
 		return nil, err
 	}
 	return instances, nil
@@ -122,7 +128,7 @@ func (s *AlertService) ListInstances() ([]database.AlertSourceInstance, error) {
 // GetInstance retrieves an alert source instance by ID
 func (s *AlertService) GetInstance(id uint) (*database.AlertSourceInstance, error) {
 	var instance database.AlertSourceInstance
-	if err := s.db.Preload("AlertSourceType").First(&instance, id).Error; err != nil {
+	if err := s.db.Preload("AlertSourceType").Preload("Skills.Tools.ToolType").First(&instance, id).Error; err != nil {
 		return nil, err
 	}
 	return &instance, nil
@@ -131,7 +137,7 @@ func (s *AlertService) GetInstance(id uint) (*database.AlertSourceInstance, erro
 // GetInstanceByUUID retrieves an alert source instance by UUID
 func (s *AlertService) GetInstanceByUUID(uuid string) (*database.AlertSourceInstance, error) {
 	var instance database.AlertSourceInstance
-	if err := s.db.Preload("AlertSourceType").Where("uuid = ?", uuid).First(&instance).Error; err != nil {
+	if err := s.db.Preload("AlertSourceType").Preload("Skills.Tools.ToolType").Where("uuid = ?", uuid).First(&instance).Error; err != nil {
 		return nil, err
 	}
 	return &instance, nil
@@ -201,14 +207,64 @@ func (s *AlertService) UpdateInstanceByID(id uint, name, description, webhookSec
 	return s.db.Model(&database.AlertSourceInstance{}).Where("id = ?", id).Updates(updates).Error
 }
 
+// MarkInstanceAlertReceived records that the instance delivered alerts at
+// at. It does not bump updated_at, which tracks configuration edits.
+func (s *AlertService) MarkInstanceAlertReceived(id uint, at time.Time) error {
+	return s.db.Model(&database.AlertSourceInstance{}).Where("id = ?", id).UpdateColumn("last_alert_at", at).Error
+}
+
+// SetInstanceSkills replaces the skills an alert source's investigations are
+// limited to. An empty list clears the restriction. Names must refer to
+// existing non-system skills; ErrUnknownSkill is returned otherwise.
+func (s *AlertService) SetInstanceSkills(uuid string, skillNames []string) error {
+	var instance database.AlertSourceInstance
+	if err := s.db.Where("uuid = ?", uuid).First(&instance).Error; err != nil {
+		return err
+	}
+
+	skills := make([]database.Skill, 0, len(skillNames))
+	if len(skillNames) > 0 {
+		if err := s.db.Where("name IN ? AND is_system = ?", skillNames, false).Find(&skills).Error; err != nil {
+			return err
+		}
+		found := make(map[string]bool, len(skills))
+		for _, sk := range skills {
+			found[sk.Name] = true
+		}
+		for _, name := range skillNames {
+			if !found[name] {
+				return fmt.Errorf("%w: %s", ErrUnknownSkill, name)
+			}
+		}
+	}
+	return s.db.Model(&instance).Association("Skills").Replace(skills)
+}
+
 // DeleteInstance deletes an alert source instance by UUID
 func (s *AlertService) DeleteInstance(uuid string) error {
-	return s.db.Where("uuid = ?", uuid).Delete(&database.AlertSourceInstance{}).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var instance database.AlertSourceInstance
+		if err := tx.Where("uuid = ?", uuid).First(&instance).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Where("alert_source_instance_id = ?", instance.ID).Delete(&database.AlertSourceSkill{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&instance).Error
+	})
 }
 
 // DeleteInstanceByID deletes an alert source instance by ID
 func (s *AlertService) DeleteInstanceByID(id uint) error {
-	return s.db.Delete(&database.AlertSourceInstance{}, id).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("alert_source_instance_id = ?", id).Delete(&database.AlertSourceSkill{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&database.AlertSourceInstance{}, id).Error
+	})
 }
 
 // ========== Initialization ==========
@@ -276,7 +332,7 @@ func (s *AlertService) InitializeDefaultSourceTypes() error {
 			Name:                "datadog",
 			DisplayName:         "Datadog",
 			Description:         "Receive alerts from Datadog",
-			WebhookSecretHeader: "X-Datadog-Signature",
+			WebhookSecretHeader: "X-Datadog-Secret",
 			DefaultMappings: database.JSONB{
 				"alert_name":      "title",
 				"severity":        "priority",
@@ -305,6 +361,51 @@ func (s *AlertService) InitializeDefaultSourceTypes() error {
 				"started_at":      "event_time",
 			},
 		},
+		{
+			Name:                "victorops",
+			DisplayName:         "Splunk On-Call (VictorOps)",
+			Description:         "Receive alerts from Splunk On-Call outgoing webhooks",
+			WebhookSecretHeader: "X-VictorOps-Secret",
+			DefaultMappings: database.JSONB{
+				"alert_name":      "entity_display_name",
+				"severity":        "message_type",
+				"status":          "message_type",
+				"summary":         "state_message",
+				"target_host":     "host_name",
+				"target_service":  "service",
+				"runbook_url":     "runbook_url",
+				"source_alert_id": "incident.incident_id",
+			},
+		},
+		{
+			Name:                "cloudwatch",
+			DisplayName:         "AWS CloudWatch (SNS)",
+			Description:         "Receive CloudWatch alarms through an SNS HTTPS subscription",
+			WebhookSecretHeader: "Authorization",
+			DefaultMappings: database.JSONB{
+				"alert_name":      "AlarmName",
+				"summary":         "NewStateReason",
+				"description":     "AlarmDescription",
+				"target_service":  "Trigger.Namespace",
+				"metric_name":     "Trigger.MetricName",
+				"source_alert_id": "AlarmArn",
+			},
+		},
+		{
+			Name:                "sentry",
+			DisplayName:         "Sentry",
+			Description:         "Receive Sentry issue alerts (webhooks plugin or integration platform)",
+			WebhookSecretHeader: "Sentry-Hook-Signature",
+			DefaultMappings: database.JSONB{
+				"alert_name":      "title",
+				"severity":        "level",
+				"summary":         "message",
+				"description":     "culprit",
+				"target_host":     "tags.server_name",
+				"target_service":  "project",
+				"source_alert_id": "issue_id",
+			},
+		},
 		// slack_channel removed (Task 6 of unified-channels): inbound Slack
 		// listening is now driven by rows in the channels table with
 		// can_listen=true, not by an AlertSourceInstance of this type. The
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -44,8 +45,8 @@ func TestAlertService_InitializeDefaultSourceTypes_IdempotentAndUpdates(t *testi
 	if err := database.DB.Model(&database.AlertSourceType{}).Count(&count).Error; err != nil {
 		t.Fatalf("count source types: %v", err)
 	}
-	if count != 5 {
-		t.Fatalf("source type count after first run = %d, want 5", count)
+	if count != 8 {
+		t.Fatalf("source type count after first run = %d, want 8", count)
 	}
 
 	if err := database.DB.Model(&database.AlertSourceType{}).
@@ -63,8 +64,8 @@ func TestAlertService_InitializeDefaultSourceTypes_IdempotentAndUpdates(t *testi
 	if err := database.DB.Model(&database.AlertSourceType{}).Count(&count).Error; err != nil {
 		t.Fatalf("count source types after second run: %v", err)
 	}
-	if count != 5 {
-		t.Fatalf("source type count after second run = %d, want 5", count)
+	if count != 8 {
+		t.Fatalf("source type count after second run = %d, want 8", count)
 	}
 
 	alertmanager, err := service.GetAlertSourceTypeByName("alertmanager")
@@ -218,6 +219,79 @@ func TestAlertService_InstanceCRUD(t *testing.T) {
 	}
 }
 
+func TestAlertService_SetInstanceSkills(t *testing.T) {
+	svc := setupAlertServiceDB(t)
+	db := database.GetDB()
+	if err := db.AutoMigrate(&database.ToolType{}, &database.ToolInstance{}, &database.SkillTool{}); err != nil {
+		t.Fatalf("migrate skill tables: %v", err)
+	}
+	if _, err := svc.CreateAlertSourceType("zabbix", "Zabbix", "", database.JSONB{}, ""); err != nil {
+		t.Fatalf("seed source type: %v", err)
+	}
+	instance, err := svc.CreateInstance("zabbix", "zabbix-onprem", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("create instance: %v", err)
+	}
+
+	toolType := database.ToolType{Name: "ssh"}
+	db.Create(&toolType)
+	sshTool := database.ToolInstance{ToolTypeID: toolType.ID, Name: "prod-ssh", LogicalName: "prod-ssh", Enabled: true}
+	db.Create(&sshTool)
+	linux := database.Skill{Name: "linux", Enabled: true, Tools: []database.ToolInstance{sshTool}}
+	zabbix := database.Skill{Name: "zabbix", Enabled: true}
+	system := database.Skill{Name: "incident-manager", Enabled: true, IsSystem: true}
+	for _, sk := range []*database.Skill{&linux, &zabbix, &system} {
+		if err := db.Create(sk).Error; err != nil {
+			t.Fatalf("seed skill %s: %v", sk.Name, err)
+		}
+	}
+
+	if err := svc.SetInstanceSkills(instance.UUID, []string{"linux", "incident-manager"}); !errors.Is(err, ErrUnknownSkill) {
+		t.Fatalf("system skill err = %v, want ErrUnknownSkill", err)
+	}
+	if err := svc.SetInstanceSkills(instance.UUID, []string{"linux", "zabbix"}); err != nil {
+		t.Fatalf("SetInstanceSkills: %v", err)
+	}
+	got, err := svc.GetInstanceByUUID(instance.UUID)
+	if err != nil {
+		t.Fatalf("GetInstanceByUUID: %v", err)
+	}
+	names, allowlist, ok := SourceSkillScope(got)
+	if !ok || len(names) != 2 || len(allowlist) != 1 || allowlist[0].LogicalName != "prod-ssh" || allowlist[0].ToolType != "ssh" {
+		t.Fatalf("scope = %v %+v %v", names, allowlist, ok)
+	}
+
+	if err := svc.SetInstanceSkills(instance.UUID, nil); err != nil {
+		t.Fatalf("clear skills: %v", err)
+	}
+	got, _ = svc.GetInstanceByUUID(instance.UUID)
+	if _, _, ok := SourceSkillScope(got); ok || len(got.Skills) != 0 {
+		t.Fatalf("expected no pinned skills, got %+v", got.Skills)
+	}
+
+	if err := svc.SetInstanceSkills(instance.UUID, []string{"zabbix"}); err != nil {
+		t.Fatalf("re-pin: %v", err)
+	}
+	if err := svc.DeleteInstance(instance.UUID); err != nil {
+		t.Fatalf("DeleteInstance: %v", err)
+	}
+	var pins int64
+	db.Model(&database.AlertSourceSkill{}).Count(&pins)
+	if pins != 0 {
+		t.Errorf("expected pins removed with the instance, got %d", pins)
+	}
+}
+
+func TestSourceSkillScope_SkipsDisabledPins(t *testing.T) {
+	instance := &database.AlertSourceInstance{Skills: []database.Skill{{Name: "linux", Enabled: false}}}
+	if _, _, ok := SourceSkillScope(instance); ok {
+		t.Error("a source whose only pinned skill is disabled should fall back to the global set")
+	}
+	if _, _, ok := SourceSkillScope(nil); ok {
+		t.Error("nil instance should fall back to the global set")
+	}
+}
+
 func TestAlertService_CreateInstance_MissingSourceType(t *testing.T) {
 	service := setupAlertServiceDB(t)
 
@@ -248,6 +322,9 @@ func TestDefaultAlertSourceTypes(t *testing.T) {
 		{"grafana", "Grafana Alerting", true},
 		{"datadog", "Datadog", true},
 		{"zabbix", "Zabbix", true},
+		{"victorops", "Splunk On-Call (VictorOps)", true},
+		{"cloudwatch", "AWS CloudWatch (SNS)", true},
+		{"sentry", "Sentry", true},
 	}
 
 	for _, et := range expectedTypes {
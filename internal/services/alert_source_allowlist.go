@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// AllowedCIDRsSettingKey is the alert source setting holding the optional
+// source-IP allowlist for its webhook: a list of CIDRs or bare addresses
+// (a comma-separated string is accepted too). When empty, any address may
+// post to the webhook and only the webhook secret applies.
+const AllowedCIDRsSettingKey = "allowed_cidrs"
+
+// ParseAllowedCIDRs returns the allowlist configured in an alert source's
+// settings. A nil result means no allowlist is configured.
+func ParseAllowedCIDRs(settings map[string]interface{}) ([]netip.Prefix, error) {
+	var entries []string
+	switch v := settings[AllowedCIDRsSettingKey].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		entries = strings.Split(v, ",")
+	case []string:
+		entries = v
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s entries must be strings", AllowedCIDRsSettingKey)
+			}
+			entries = append(entries, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a list of CIDRs", AllowedCIDRsSettingKey)
+	}
+
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q in %s", entry, AllowedCIDRsSettingKey)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q in %s", entry, AllowedCIDRsSettingKey)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// SourceIPAllowed reports whether ip may post to an alert source with the
+// given settings. Sources without an allowlist accept every address; a
+// malformed allowlist or an unparseable ip is rejected so a bad config fails
+// closed.
+func SourceIPAllowed(settings map[string]interface{}, ip string) bool {
+	prefixes, err := ParseAllowedCIDRs(settings)
+	if err != nil {
+		return false
+	}
+	if len(prefixes) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package services
+
+import "testing"
+
+func TestParseAllowedCIDRs(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     int
+		wantErr  bool
+	}{
+		{"unset", map[string]interface{}{}, 0, false},
+		{"list", map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"10.0.0.0/8", "2001:db8::/32", "203.0.113.7"}}, 3, false},
+		{"comma string", map[string]interface{}{AllowedCIDRsSettingKey: "10.0.0.0/8, 192.0.2.1 ,"}, 2, false},
+		{"empty list", map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{}}, 0, false},
+		{"bad cidr", map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"10.0.0.0/33"}}, 0, true},
+		{"bad address", map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"example.com"}}, 0, true},
+		{"non-string entry", map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{42}}, 0, true},
+		{"wrong type", map[string]interface{}{AllowedCIDRsSettingKey: true}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAllowedCIDRs(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("len = %d, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceIPAllowed(t *testing.T) {
+	allow := map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"10.0.0.0/8", "203.0.113.7", "2001:db8::/32"}}
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		ip       string
+		want     bool
+	}{
+		{"no allowlist", nil, "198.51.100.1", true},
+		{"inside cidr", allow, "10.1.2.3", true},
+		{"exact address", allow, "203.0.113.7", true},
+		{"ipv4-mapped ipv6", allow, "::ffff:10.1.2.3", true},
+		{"ipv6 inside", allow, "2001:db8::1", true},
+		{"outside", allow, "203.0.113.8", false},
+		{"unparseable ip", allow, "unknown", false},
+		{"malformed allowlist fails closed", map[string]interface{}{AllowedCIDRsSettingKey: "nope"}, "10.1.2.3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SourceIPAllowed(tt.settings, tt.ip); got != tt.want {
+				t.Errorf("SourceIPAllowed(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAlertSourceSettings_AllowedCIDRs(t *testing.T) {
+	if err := ValidateAlertSourceSettings(map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"10.0.0.0/8"}}); err != nil {
+		t.Errorf("valid allowlist rejected: %v", err)
+	}
+	if err := ValidateAlertSourceSettings(map[string]interface{}{AllowedCIDRsSettingKey: []interface{}{"10.0.0.0/99"}}); err == nil {
+		t.Error("expected invalid allowlist to be rejected")
+	}
+}
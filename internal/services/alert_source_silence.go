@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SilenceThresholdSettingKey is the alert source setting holding how many
+// minutes the source may go without delivering an alert before the
+// self-monitor reports it as silent. Absent or 0 disables the check, which
+// suits sources that are legitimately quiet for long stretches.
+const SilenceThresholdSettingKey = "silence_threshold_minutes"
+
+// maxSilenceThresholdMinutes caps the threshold at 30 days.
+const maxSilenceThresholdMinutes = 30 * 24 * 60
+
+// ParseSilenceThreshold returns the silence threshold configured in an alert
+// source's settings, or 0 when the check is disabled. The form posts the
+// value as a string, so numeric strings are accepted too.
+func ParseSilenceThreshold(settings map[string]interface{}) (time.Duration, error) {
+	var minutes float64
+	switch v := settings[SilenceThresholdSettingKey].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		minutes = v
+	case int:
+		minutes = float64(v)
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number of minutes", SilenceThresholdSettingKey)
+		}
+		minutes = f
+	default:
+		return 0, fmt.Errorf("%s must be a number of minutes", SilenceThresholdSettingKey)
+	}
+	if math.IsNaN(minutes) || minutes < 0 || minutes > maxSilenceThresholdMinutes || minutes != math.Trunc(minutes) {
+		return 0, fmt.Errorf("%s must be a whole number of minutes between 0 and %d", SilenceThresholdSettingKey, maxSilenceThresholdMinutes)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
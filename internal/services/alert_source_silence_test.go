@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSilenceThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     time.Duration
+		wantErr  bool
+	}{
+		{"unset", map[string]interface{}{}, 0, false},
+		{"json number", map[string]interface{}{SilenceThresholdSettingKey: float64(90)}, 90 * time.Minute, false},
+		{"form string", map[string]interface{}{SilenceThresholdSettingKey: " 60 "}, time.Hour, false},
+		{"empty string", map[string]interface{}{SilenceThresholdSettingKey: ""}, 0, false},
+		{"zero disables", map[string]interface{}{SilenceThresholdSettingKey: float64(0)}, 0, false},
+		{"negative", map[string]interface{}{SilenceThresholdSettingKey: float64(-5)}, 0, true},
+		{"fractional", map[string]interface{}{SilenceThresholdSettingKey: 1.5}, 0, true},
+		{"too large", map[string]interface{}{SilenceThresholdSettingKey: float64(maxSilenceThresholdMinutes + 1)}, 0, true},
+		{"not a number", map[string]interface{}{SilenceThresholdSettingKey: "soon"}, 0, true},
+		{"wrong type", map[string]interface{}{SilenceThresholdSettingKey: true}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSilenceThreshold(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("threshold = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAlertSourceSettings_SilenceThreshold(t *testing.T) {
+	if err := ValidateAlertSourceSettings(map[string]interface{}{SilenceThresholdSettingKey: float64(120)}); err != nil {
+		t.Errorf("valid threshold rejected: %v", err)
+	}
+	if err := ValidateAlertSourceSettings(map[string]interface{}{SilenceThresholdSettingKey: "-1"}); err == nil {
+		t.Error("expected negative threshold to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// ErrNoConfigSnippet means there is no ready-made sender configuration for
+// the alert source's type.
+var ErrNoConfigSnippet = errors.New("no configuration snippet is available for this alert source type")
+
+// AlertSourceConfigSnippet is sender-side configuration that points a
+// monitoring system at an alert source's webhook.
+type AlertSourceConfigSnippet struct {
+	SourceType string `json:"source_type"`
+	WebhookURL string `json:"webhook_url"`
+	Format     string `json:"format"` // "yaml" or "json"
+	// Instructions says where the snippet goes.
+	Instructions string `json:"instructions"`
+	Snippet      string `json:"snippet"`
+}
+
+// datadogWebhookPayload is the Datadog webhook payload template matching
+// the fields the Datadog adapter reads. $DATE and $LAST_UPDATED expand to
+// epoch numbers and are left unquoted.
+const datadogWebhookPayload = `{
+  "id": "$ID",
+  "title": "$EVENT_TITLE",
+  "body": "$EVENT_MSG",
+  "alert_type": "$ALERT_TYPE",
+  "event_type": "$EVENT_TYPE",
+  "priority": "$PRIORITY",
+  "alert_id": "$ALERT_ID",
+  "alert_title": "$ALERT_TITLE",
+  "alert_status": "$ALERT_TRANSITION",
+  "alert_cycle_key": "$ALERT_CYCLE_KEY",
+  "alert_metric": "$ALERT_METRIC",
+  "alert_query": "$ALERT_QUERY",
+  "alert_scope": "$ALERT_SCOPE",
+  "hostname": "$HOSTNAME",
+  "org_id": "$ORG_ID",
+  "org_name": "$ORG_NAME",
+  "snapshot": "$SNAPSHOT",
+  "date": $DATE,
+  "last_updated": $LAST_UPDATED
+}`
+
+// RenderAlertSourceConfigSnippet renders configuration for instance's
+// sender (Alertmanager, Grafana or Datadog) that posts to its webhook under
+// baseURL and authenticates with its webhook secret. The secret is sent as
+// a bearer token, which every one of those adapters accepts.
+func RenderAlertSourceConfigSnippet(instance *database.AlertSourceInstance, baseURL string) (*AlertSourceConfigSnippet, error) {
+	snippet := &AlertSourceConfigSnippet{
+		SourceType: instance.AlertSourceType.Name,
+		WebhookURL: instance.GetWebhookURL(strings.TrimRight(baseURL, "/")),
+	}
+	name := "akmatori-" + configSlug(instance.Name)
+
+	var b strings.Builder
+	switch snippet.SourceType {
+	case "alertmanager":
+		snippet.Format = "yaml"
+		snippet.Instructions = "Merge into alertmanager.yml: add the receiver, and the route under your existing route's routes. Adjust the matchers to choose which alerts Akmatori investigates, then reload Alertmanager."
+		fmt.Fprintf(&b, "# Akmatori alert source %s\n", yamlString(instance.Name))
+		b.WriteString("route:\n  routes:\n")
+		fmt.Fprintf(&b, "    - receiver: %s\n", yamlString(name))
+		b.WriteString("      matchers:\n        - severity=~\"critical|warning\"\n")
+		b.WriteString("      # Keep evaluating sibling routes so existing receivers still get the alert.\n")
+		b.WriteString("      continue: true\n")
+		b.WriteString("receivers:\n")
+		fmt.Fprintf(&b, "  - name: %s\n", yamlString(name))
+		b.WriteString("    webhook_configs:\n")
+		fmt.Fprintf(&b, "      - url: %s\n", yamlString(snippet.WebhookURL))
+		b.WriteString("        send_resolved: true\n")
+		if instance.WebhookSecret != "" {
+			b.WriteString("        http_config:\n          authorization:\n            type: Bearer\n")
+			fmt.Fprintf(&b, "            credentials: %s\n", yamlString(instance.WebhookSecret))
+		}
+
+	case "grafana":
+		snippet.Format = "yaml"
+		snippet.Instructions = "Save as a file under Grafana's provisioning/alerting directory and restart Grafana, then route alerts to the contact point from a notification policy."
+		fmt.Fprintf(&b, "# Akmatori alert source %s\n", yamlString(instance.Name))
+		b.WriteString("apiVersion: 1\ncontactPoints:\n  - orgId: 1\n")
+		fmt.Fprintf(&b, "    name: %s\n", yamlString(name))
+		b.WriteString("    receivers:\n")
+		fmt.Fprintf(&b, "      - uid: %s\n", yamlString(grafanaReceiverUID(instance.UUID)))
+		b.WriteString("        type: webhook\n        disableResolveMessage: false\n        settings:\n")
+		fmt.Fprintf(&b, "          url: %s\n", yamlString(snippet.WebhookURL))
+		b.WriteString("          httpMethod: POST\n")
+		if instance.WebhookSecret != "" {
+			b.WriteString("          authorization_scheme: Bearer\n")
+			fmt.Fprintf(&b, "          authorization_credentials: %s\n", yamlString(instance.WebhookSecret))
+		}
+
+	case "datadog":
+		snippet.Format = "json"
+		snippet.Instructions = "POST to Datadog's /api/v1/integration/webhooks/configuration/webhooks (or copy the fields into Integrations > Webhooks), then mention @webhook-" + name + " in the monitors Akmatori should investigate."
+		hook := struct {
+			Name          string `json:"name"`
+			URL           string `json:"url"`
+			EncodeAs      string `json:"encode_as"`
+			Payload       string `json:"payload"`
+			CustomHeaders string `json:"custom_headers,omitempty"`
+		}{Name: name, URL: snippet.WebhookURL, EncodeAs: "json", Payload: datadogWebhookPayload}
+		if instance.WebhookSecret != "" {
+			headers, err := json.Marshal(map[string]string{"Authorization": "Bearer " + instance.WebhookSecret})
+			if err != nil {
+				return nil, err
+			}
+			hook.CustomHeaders = string(headers)
+		}
+		out, err := json.MarshalIndent(hook, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		b.Write(out)
+		b.WriteString("\n")
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNoConfigSnippet, snippet.SourceType)
+	}
+
+	snippet.Snippet = b.String()
+	return snippet, nil
+}
+
+// configSlug lowercases name and collapses everything but letters and digits
+// into single dashes, for receiver and webhook names.
+func configSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "source"
+	}
+	return slug
+}
+
+// grafanaReceiverUID derives a stable contact point receiver uid from the
+// source UUID; Grafana caps uids at 40 characters.
+func grafanaReceiverUID(sourceUUID string) string {
+	uid := "akmatori-" + strings.ReplaceAll(sourceUUID, "-", "")
+	if len(uid) > 40 {
+		uid = uid[:40]
+	}
+	return uid
+}
+
+// yamlString quotes s as a YAML scalar. A JSON string is a valid YAML
+// double-quoted scalar, so secrets and names with special characters
+// survive the paste.
+func yamlString(s string) string {
+	out, _ := json.Marshal(s)
+	return string(out)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gopkg.in/yaml.v3"
+)
+
+func snippetSource(sourceType, secret string) *database.AlertSourceInstance {
+	return &database.AlertSourceInstance{
+		UUID:            "0b6f3c9e-1a2b-4c5d-8e9f-0123456789ab",
+		Name:            "Prod: K8s #1",
+		WebhookSecret:   secret,
+		AlertSourceType: database.AlertSourceType{Name: sourceType},
+	}
+}
+
+func TestRenderAlertSourceConfigSnippet_Alertmanager(t *testing.T) {
+	snippet, err := RenderAlertSourceConfigSnippet(snippetSource("alertmanager", `s"e:c#ret`), "https://akmatori.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snippet.WebhookURL != "https://akmatori.example.com/webhook/alert/0b6f3c9e-1a2b-4c5d-8e9f-0123456789ab" {
+		t.Errorf("webhook url = %q", snippet.WebhookURL)
+	}
+
+	var config struct {
+		Route struct {
+			Routes []struct {
+				Receiver string `yaml:"receiver"`
+				Continue bool   `yaml:"continue"`
+			} `yaml:"routes"`
+		} `yaml:"route"`
+		Receivers []struct {
+			Name           string `yaml:"name"`
+			WebhookConfigs []struct {
+				URL          string `yaml:"url"`
+				SendResolved bool   `yaml:"send_resolved"`
+				HTTPConfig   struct {
+					Authorization struct {
+						Type        string `yaml:"type"`
+						Credentials string `yaml:"credentials"`
+					} `yaml:"authorization"`
+				} `yaml:"http_config"`
+			} `yaml:"webhook_configs"`
+		} `yaml:"receivers"`
+	}
+	if err := yaml.Unmarshal([]byte(snippet.Snippet), &config); err != nil {
+		t.Fatalf("snippet is not valid YAML: %v\n%s", err, snippet.Snippet)
+	}
+	if len(config.Route.Routes) != 1 || config.Route.Routes[0].Receiver != "akmatori-prod-k8s-1" || !config.Route.Routes[0].Continue {
+		t.Errorf("route = %+v", config.Route)
+	}
+	if len(config.Receivers) != 1 || len(config.Receivers[0].WebhookConfigs) != 1 {
+		t.Fatalf("receivers = %+v", config.Receivers)
+	}
+	hook := config.Receivers[0].WebhookConfigs[0]
+	if hook.URL != snippet.WebhookURL || !hook.SendResolved {
+		t.Errorf("webhook config = %+v", hook)
+	}
+	if hook.HTTPConfig.Authorization.Type != "Bearer" || hook.HTTPConfig.Authorization.Credentials != `s"e:c#ret` {
+		t.Errorf("authorization = %+v", hook.HTTPConfig.Authorization)
+	}
+}
+
+func TestRenderAlertSourceConfigSnippet_Grafana(t *testing.T) {
+	snippet, err := RenderAlertSourceConfigSnippet(snippetSource("grafana", ""), "http://localhost:3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		ContactPoints []struct {
+			Name      string `yaml:"name"`
+			Receivers []struct {
+				UID      string            `yaml:"uid"`
+				Type     string            `yaml:"type"`
+				Settings map[string]string `yaml:"settings"`
+			} `yaml:"receivers"`
+		} `yaml:"contactPoints"`
+	}
+	if err := yaml.Unmarshal([]byte(snippet.Snippet), &config); err != nil {
+		t.Fatalf("snippet is not valid YAML: %v\n%s", err, snippet.Snippet)
+	}
+	receiver := config.ContactPoints[0].Receivers[0]
+	if receiver.Type != "webhook" || receiver.Settings["url"] != snippet.WebhookURL || len(receiver.UID) > 40 {
+		t.Errorf("receiver = %+v", receiver)
+	}
+	if _, ok := receiver.Settings["authorization_credentials"]; ok {
+		t.Error("a source without a secret should not configure authorization")
+	}
+}
+
+func TestRenderAlertSourceConfigSnippet_Datadog(t *testing.T) {
+	snippet, err := RenderAlertSourceConfigSnippet(snippetSource("datadog", "tok"), "https://akmatori.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hook struct {
+		Name          string `json:"name"`
+		URL           string `json:"url"`
+		Payload       string `json:"payload"`
+		CustomHeaders string `json:"custom_headers"`
+	}
+	if err := json.Unmarshal([]byte(snippet.Snippet), &hook); err != nil {
+		t.Fatalf("snippet is not valid JSON: %v", err)
+	}
+	if hook.Name != "akmatori-prod-k8s-1" || hook.URL != snippet.WebhookURL {
+		t.Errorf("hook = %+v", hook)
+	}
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(hook.CustomHeaders), &headers); err != nil || headers["Authorization"] != "Bearer tok" {
+		t.Errorf("custom headers = %q (%v)", hook.CustomHeaders, err)
+	}
+}
+
+func TestRenderAlertSourceConfigSnippet_UnsupportedType(t *testing.T) {
+	if _, err := RenderAlertSourceConfigSnippet(snippetSource("zabbix", ""), "https://akmatori.example.com"); !errors.Is(err, ErrNoConfigSnippet) {
+		t.Errorf("err = %v, want ErrNoConfigSnippet", err)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// WebhookVerificationSettingKey is the alert source setting that overrides
+// how the webhook secret is checked. Absent, each adapter applies its own
+// check. Otherwise it is an object:
+//
+//	{"method": "hmac", "scheme": "grafana", "tolerance_seconds": 300}
+//	{"method": "hmac", "scheme": "generic", "header": "X-Hub-Signature-256"}
+//	{"method": "token", "header": "X-Api-Key"}
+//
+// "hmac" verifies an HMAC-SHA256 signature of the body keyed by the webhook
+// secret, using one of alerts.HMACSchemes; "header" and "timestamp_header"
+// override the scheme's headers. Signed timestamps further than
+// tolerance_seconds from now (default 300) are rejected as replays, and so
+// are requests without one when the scheme has a timestamp header, unless
+// "require_timestamp" is false. "token"
+// compares a static token taken from "header" (default Authorization, with
+// or without "Bearer "). Either method needs a webhook secret.
+const WebhookVerificationSettingKey = "webhook_verification"
+
+// maxTimestampToleranceSeconds caps the replay window at one day.
+const maxTimestampToleranceSeconds = 24 * 60 * 60
+
+// WebhookVerification is the parsed webhook_verification setting.
+type WebhookVerification struct {
+	// Method is "hmac", "token", or empty for the adapter's own check.
+	Method string
+	// Scheme is the HMAC scheme, with header overrides applied.
+	Scheme alerts.HMACScheme
+	// TokenHeader carries the static token for the "token" method.
+	TokenHeader string
+	// Tolerance bounds the age of signed timestamps.
+	Tolerance time.Duration
+}
+
+// ParseWebhookVerification returns the webhook verification configured in
+// an alert source's settings. A zero Method means the adapter's check.
+func ParseWebhookVerification(settings map[string]interface{}) (WebhookVerification, error) {
+	var cfg WebhookVerification
+	raw, ok := settings[WebhookVerificationSettingKey]
+	if !ok || raw == nil {
+		return cfg, nil
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return cfg, fmt.Errorf("%s must be an object", WebhookVerificationSettingKey)
+	}
+	str := func(key string) (string, error) {
+		switch v := obj[key].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return strings.TrimSpace(v), nil
+		default:
+			return "", fmt.Errorf("%s.%s must be a string", WebhookVerificationSettingKey, key)
+		}
+	}
+
+	method, err := str("method")
+	if err != nil {
+		return cfg, err
+	}
+	header, err := str("header")
+	if err != nil {
+		return cfg, err
+	}
+	switch strings.ToLower(method) {
+	case "", "adapter":
+		return cfg, nil
+	case "token":
+		cfg.Method = "token"
+		cfg.TokenHeader = header
+		if cfg.TokenHeader == "" {
+			cfg.TokenHeader = "Authorization"
+		}
+		return cfg, nil
+	case "hmac":
+		cfg.Method = "hmac"
+	default:
+		return cfg, fmt.Errorf("%s.method must be hmac or token", WebhookVerificationSettingKey)
+	}
+
+	name, err := str("scheme")
+	if err != nil {
+		return cfg, err
+	}
+	if name == "" {
+		name = "generic"
+	}
+	scheme, ok := alerts.HMACSchemes[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(alerts.HMACSchemes))
+		for n := range alerts.HMACSchemes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return cfg, fmt.Errorf("%s.scheme must be one of %s", WebhookVerificationSettingKey, strings.Join(names, ", "))
+	}
+	if header != "" {
+		scheme.Header = header
+	}
+	timestampHeader, err := str("timestamp_header")
+	if err != nil {
+		return cfg, err
+	}
+	if timestampHeader != "" {
+		scheme.TimestampHeader = timestampHeader
+		if scheme.TimestampSeparator == "" {
+			scheme.TimestampSeparator = "."
+		}
+	}
+	requireTimestamp, err := parseBoolSetting(obj, "require_timestamp", true)
+	if err != nil {
+		return cfg, fmt.Errorf("%s.%w", WebhookVerificationSettingKey, err)
+	}
+	scheme.TimestampOptional = !requireTimestamp
+	cfg.Scheme = scheme
+
+	tolerance, err := parseToleranceSeconds(obj["tolerance_seconds"])
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Tolerance = tolerance
+	return cfg, nil
+}
+
+// parseToleranceSeconds accepts a number or a numeric string, as the form
+// posts it; absent or 0 gives the default tolerance.
+func parseToleranceSeconds(raw interface{}) (time.Duration, error) {
+	invalid := fmt.Errorf("%s.tolerance_seconds must be a whole number of seconds between 0 and %d", WebhookVerificationSettingKey, maxTimestampToleranceSeconds)
+	var seconds float64
+	switch v := raw.(type) {
+	case nil:
+		return alerts.DefaultTimestampTolerance, nil
+	case float64:
+		seconds = v
+	case int:
+		seconds = float64(v)
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return alerts.DefaultTimestampTolerance, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, invalid
+		}
+		seconds = f
+	default:
+		return 0, invalid
+	}
+	if math.IsNaN(seconds) || seconds < 0 || seconds > maxTimestampToleranceSeconds || seconds != math.Trunc(seconds) {
+		return 0, invalid
+	}
+	if seconds == 0 {
+		return alerts.DefaultTimestampTolerance, nil
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// VerifyWebhook authenticates a webhook request for instance: with the
+// method configured in its webhook_verification setting, or with the
+// adapter's own check when none is. A malformed setting rejects every
+// request so a bad config fails closed. The source-IP allowlist is checked
+// separately (SourceIPAllowed).
+func VerifyWebhook(r *http.Request, instance *database.AlertSourceInstance, adapter alerts.AlertAdapter) error {
+	cfg, err := ParseWebhookVerification(instance.Settings)
+	if err != nil {
+		return err
+	}
+	if cfg.Method == "" {
+		return adapter.ValidateWebhookSecret(r, instance)
+	}
+	if instance.WebhookSecret == "" {
+		return errors.New("webhook verification is configured but the instance has no webhook secret")
+	}
+	if cfg.Method == "token" {
+		return alerts.VerifyToken(r, instance.WebhookSecret, cfg.TokenHeader)
+	}
+	return alerts.VerifyHMAC(r, instance.WebhookSecret, cfg.Scheme, cfg.Tolerance, time.Now())
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/alerts/adapters"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func TestParseWebhookVerification(t *testing.T) {
+	setting := func(v interface{}) map[string]interface{} {
+		return map[string]interface{}{WebhookVerificationSettingKey: v}
+	}
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     WebhookVerification
+		wantErr  bool
+	}{
+		{"unset", map[string]interface{}{}, WebhookVerification{}, false},
+		{"adapter", setting(map[string]interface{}{"method": "adapter"}), WebhookVerification{}, false},
+		{"token default header", setting(map[string]interface{}{"method": "token"}),
+			WebhookVerification{Method: "token", TokenHeader: "Authorization"}, false},
+		{"hmac scheme", setting(map[string]interface{}{"method": "hmac", "scheme": "PagerDuty"}),
+			WebhookVerification{Method: "hmac", Scheme: alerts.HMACSchemes["pagerduty"], Tolerance: alerts.DefaultTimestampTolerance}, false},
+		{"hmac overrides", setting(map[string]interface{}{"method": "hmac", "scheme": "sentry", "header": "X-Sig", "timestamp_header": "X-Ts", "tolerance_seconds": "60"}),
+			WebhookVerification{Method: "hmac", Scheme: alerts.HMACScheme{Header: "X-Sig", TimestampHeader: "X-Ts", TimestampSeparator: ".", DeliveryIDHeader: "Request-ID"}, Tolerance: time.Minute}, false},
+		{"optional timestamp", setting(map[string]interface{}{"method": "hmac", "scheme": "grafana", "require_timestamp": "false"}),
+			WebhookVerification{Method: "hmac", Scheme: alerts.HMACScheme{Header: "X-Grafana-Alerting-Signature", TimestampHeader: "X-Grafana-Alerting-Signature-Timestamp", TimestampSeparator: ":", TimestampOptional: true}, Tolerance: alerts.DefaultTimestampTolerance}, false},
+		{"malformed require_timestamp", setting(map[string]interface{}{"method": "hmac", "require_timestamp": "sometimes"}), WebhookVerification{}, true},
+		{"not an object", setting("hmac"), WebhookVerification{}, true},
+		{"unknown method", setting(map[string]interface{}{"method": "mtls"}), WebhookVerification{}, true},
+		{"unknown scheme", setting(map[string]interface{}{"method": "hmac", "scheme": "nagios"}), WebhookVerification{}, true},
+		{"negative tolerance", setting(map[string]interface{}{"method": "hmac", "tolerance_seconds": -1.0}), WebhookVerification{}, true},
+		{"fractional tolerance", setting(map[string]interface{}{"method": "hmac", "tolerance_seconds": 1.5}), WebhookVerification{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebhookVerification(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	// Unique per run: an accepted signature is not accepted again.
+	body := fmt.Sprintf(`{"alert_id":"%d"}`, time.Now().UnixNano())
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("dd-secret"))
+	mac.Write([]byte(ts + "." + body))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	hmacSettings := map[string]interface{}{WebhookVerificationSettingKey: map[string]interface{}{"method": "hmac", "scheme": "generic"}}
+	tokenSettings := map[string]interface{}{WebhookVerificationSettingKey: map[string]interface{}{"method": "token", "header": "X-Api-Key"}}
+
+	tests := []struct {
+		name     string
+		secret   string
+		settings map[string]interface{}
+		headers  map[string]string
+		wantErr  bool
+	}{
+		{"adapter check", "dd-secret", nil, map[string]string{"X-Datadog-Secret": "dd-secret"}, false},
+		{"adapter check without secret", "", nil, nil, false},
+		{"hmac", "dd-secret", hmacSettings, map[string]string{"X-Webhook-Signature": "sha256=" + signature, "X-Webhook-Timestamp": ts}, false},
+		{"hmac rejects the raw secret", "dd-secret", hmacSettings, map[string]string{"X-Webhook-Signature": "dd-secret", "X-Webhook-Timestamp": ts}, true},
+		{"hmac replayed", "dd-secret", hmacSettings, map[string]string{"X-Webhook-Signature": "sha256=" + signature, "X-Webhook-Timestamp": ts}, true},
+		{"hmac without timestamp", "dd-secret", hmacSettings, map[string]string{"X-Webhook-Signature": "sha256=" + signature}, true},
+		{"no datadog hmac scheme", "dd-secret", map[string]interface{}{WebhookVerificationSettingKey: map[string]interface{}{"method": "hmac", "scheme": "datadog"}}, nil, true},
+		{"token", "dd-secret", tokenSettings, map[string]string{"X-Api-Key": "dd-secret"}, false},
+		{"token in another header", "dd-secret", tokenSettings, map[string]string{"X-Datadog-Secret": "dd-secret"}, true},
+		{"configured without secret", "", tokenSettings, map[string]string{"X-Api-Key": ""}, true},
+		{"malformed setting", "", map[string]interface{}{WebhookVerificationSettingKey: "yes"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook/alert/x", strings.NewReader(body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			instance := &database.AlertSourceInstance{WebhookSecret: tt.secret, Settings: tt.settings}
+			err := VerifyWebhook(req, instance, adapters.NewDatadogAdapter())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// AlertStormSettings configures AlertStormGuard. Zero values disable the
+// corresponding protection.
+type AlertStormSettings struct {
+	// CoalesceWindow is how long after an alert spawned an incident that
+	// repeats of the same alert are folded into that incident.
+	CoalesceWindow time.Duration
+	// Threshold is how many incidents one source may spawn within Window
+	// before it is in storm mode.
+	Threshold int
+	Window    time.Duration
+}
+
+// AlertStormGuard protects the agent from flapping alert sources. It folds
+// bursts of an identical alert into the incident the first one spawned and,
+// once a source has spawned Threshold incidents within Window, puts it in
+// storm mode: until the rate drops again its excess alerts are grouped
+// into a single summary incident instead of each getting an investigation.
+//
+// State is in memory; a restart starts every source afresh.
+type AlertStormGuard struct {
+	settings AlertStormSettings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	coalesced map[string]coalescedAlert
+	spawns    map[string][]time.Time // recent spawn times by source UUID
+	storms    map[string]*AlertStorm // active storms by source UUID
+}
+
+type coalescedAlert struct {
+	incidentUUID string
+	expires      time.Time
+}
+
+// AlertStorm is a source's active storm.
+type AlertStorm struct {
+	SourceUUID string
+	Started    time.Time
+	// IncidentUUID is the summary incident, empty until the first grouped
+	// alert has created it.
+	IncidentUUID string
+	// Grouped counts the alerts grouped into the summary incident.
+	Grouped int
+
+	lastGrouped time.Time
+}
+
+// lastActivity is when the storm last grew: its start or its latest grouped
+// alert.
+func (s *AlertStorm) lastActivity() time.Time {
+	if s.lastGrouped.After(s.Started) {
+		return s.lastGrouped
+	}
+	return s.Started
+}
+
+// NewAlertStormGuard creates a guard with the given settings.
+func NewAlertStormGuard(settings AlertStormSettings) *AlertStormGuard {
+	return &AlertStormGuard{
+		settings:  settings,
+		now:       time.Now,
+		coalesced: make(map[string]coalescedAlert),
+		spawns:    make(map[string][]time.Time),
+		storms:    make(map[string]*AlertStorm),
+	}
+}
+
+// Coalesced returns the incident a recent identical alert (same key)
+// spawned, if it is still inside the coalesce window.
+func (g *AlertStormGuard) Coalesced(key string) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.coalesced[key]
+	if !ok {
+		return "", false
+	}
+	if !g.now().Before(c.expires) {
+		delete(g.coalesced, key)
+		return "", false
+	}
+	return c.incidentUUID, true
+}
+
+// RecordSpawn notes that the alert with the given key spawned incidentUUID,
+// for coalescing and the source's storm rate.
+func (g *AlertStormGuard) RecordSpawn(sourceUUID, key, incidentUUID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	if g.settings.CoalesceWindow > 0 {
+		g.coalesced[key] = coalescedAlert{incidentUUID: incidentUUID, expires: now.Add(g.settings.CoalesceWindow)}
+		g.pruneCoalescedLocked(now)
+	}
+	if g.settings.Threshold > 0 {
+		g.spawns[sourceUUID] = append(g.recentSpawnsLocked(sourceUUID, now), now)
+	}
+}
+
+// Forget drops the coalesce entry for key, so the alert firing again after
+// it resolved gets a fresh incident.
+func (g *AlertStormGuard) Forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.coalesced, key)
+}
+
+// Storm reports whether the source is in storm mode, entering or leaving it
+// based on its recent spawn rate. The returned storm is a snapshot; update
+// it through SetStormIncident and AddToStorm.
+func (g *AlertStormGuard) Storm(sourceUUID string) (*AlertStorm, bool) {
+	if g.settings.Threshold <= 0 {
+		return nil, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	recent := g.recentSpawnsLocked(sourceUUID, now)
+	if len(recent) == 0 {
+		delete(g.spawns, sourceUUID)
+	} else {
+		g.spawns[sourceUUID] = recent
+	}
+
+	storm, active := g.storms[sourceUUID]
+	if active {
+		// The storm lasts until a full window passes without it growing.
+		if now.Sub(storm.lastActivity()) < g.settings.Window {
+			return g.copyStorm(storm), true
+		}
+		delete(g.storms, sourceUUID)
+	}
+	if len(recent) < g.settings.Threshold {
+		return nil, false
+	}
+	storm = &AlertStorm{SourceUUID: sourceUUID, Started: now}
+	g.storms[sourceUUID] = storm
+	return g.copyStorm(storm), true
+}
+
+// SetStormIncident records the summary incident of the source's active
+// storm. It is a no-op when the storm has ended.
+func (g *AlertStormGuard) SetStormIncident(sourceUUID, incidentUUID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if storm, ok := g.storms[sourceUUID]; ok {
+		storm.IncidentUUID = incidentUUID
+	}
+}
+
+// AddToStorm counts an alert grouped into the source's storm and returns
+// the new total.
+func (g *AlertStormGuard) AddToStorm(sourceUUID string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	storm, ok := g.storms[sourceUUID]
+	if !ok {
+		return 0
+	}
+	storm.Grouped++
+	storm.lastGrouped = g.now()
+	return storm.Grouped
+}
+
+func (g *AlertStormGuard) copyStorm(storm *AlertStorm) *AlertStorm {
+	c := *storm
+	return &c
+}
+
+func (g *AlertStormGuard) recentSpawnsLocked(sourceUUID string, now time.Time) []time.Time {
+	spawns := g.spawns[sourceUUID]
+	cutoff := now.Add(-g.settings.Window)
+	i := 0
+	for i < len(spawns) && !spawns[i].After(cutoff) {
+		i++
+	}
+	return spawns[i:]
+}
+
+func (g *AlertStormGuard) pruneCoalescedLocked(now time.Time) {
+	for key, c := range g.coalesced {
+		if !now.Before(c.expires) {
+			delete(g.coalesced, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertStormGuard_Coalesce(t *testing.T) {
+	g := NewAlertStormGuard(AlertStormSettings{CoalesceWindow: time.Minute})
+	now := time.Unix(1_700_000_000, 0)
+	g.now = func() time.Time { return now }
+
+	if _, ok := g.Coalesced("k"); ok {
+		t.Fatal("unknown key coalesced")
+	}
+	g.RecordSpawn("src", "k", "inc-1")
+	if inc, ok := g.Coalesced("k"); !ok || inc != "inc-1" {
+		t.Fatalf("Coalesced = %q, %v; want inc-1", inc, ok)
+	}
+	g.Forget("k")
+	if _, ok := g.Coalesced("k"); ok {
+		t.Error("forgotten key still coalesced")
+	}
+	g.RecordSpawn("src", "k", "inc-2")
+	now = now.Add(time.Minute)
+	if _, ok := g.Coalesced("k"); ok {
+		t.Error("key coalesced after the window")
+	}
+}
+
+func TestAlertStormGuard_Storm(t *testing.T) {
+	g := NewAlertStormGuard(AlertStormSettings{Threshold: 3, Window: 5 * time.Minute})
+	now := time.Unix(1_700_000_000, 0)
+	g.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		g.RecordSpawn("src", "k", "inc")
+	}
+	if _, ok := g.Storm("src"); ok {
+		t.Fatal("storm below threshold")
+	}
+	g.RecordSpawn("src", "k", "inc")
+	storm, ok := g.Storm("src")
+	if !ok || storm.IncidentUUID != "" {
+		t.Fatalf("Storm = %+v, %v; want a new storm", storm, ok)
+	}
+	if _, ok := g.Storm("other"); ok {
+		t.Error("storm leaked to another source")
+	}
+
+	g.SetStormIncident("src", "summary")
+	now = now.Add(4 * time.Minute)
+	if n := g.AddToStorm("src"); n != 1 {
+		t.Errorf("AddToStorm = %d, want 1", n)
+	}
+	// The spawns have aged out, but the storm grew 4 minutes in.
+	now = now.Add(4 * time.Minute)
+	if storm, ok := g.Storm("src"); !ok || storm.IncidentUUID != "summary" || storm.Grouped != 1 {
+		t.Fatalf("Storm = %+v, %v; want the ongoing storm", storm, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok := g.Storm("src"); ok {
+		t.Error("storm outlived a quiet window")
+	}
+}
+
+func TestAlertStormGuard_Disabled(t *testing.T) {
+	g := NewAlertStormGuard(AlertStormSettings{})
+	for i := 0; i < 100; i++ {
+		g.RecordSpawn("src", "k", "inc")
+	}
+	if _, ok := g.Coalesced("k"); ok {
+		t.Error("coalesced with no window")
+	}
+	if _, ok := g.Storm("src"); ok {
+		t.Error("storm with no threshold")
+	}
+}
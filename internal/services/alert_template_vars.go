@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// AlertTemplateVars exposes every normalized alert field to operator-written
+// templates. It is embedded in NotificationData and is the context for
+// per-source investigation prompt templates, so `{{.Labels.team}}` means the
+// same thing wherever a template is accepted.
+type AlertTemplateVars struct {
+	AlertName      string
+	Severity       string
+	SeverityEmoji  string
+	Status         string
+	Summary        string
+	Description    string
+	Host           string
+	Service        string
+	Labels         map[string]string
+	MetricName     string
+	MetricValue    string
+	ThresholdValue string
+	RunbookURL     string
+
+	StartedAt         *time.Time
+	EndedAt           *time.Time
+	SourceAlertID     string
+	SourceFingerprint string
+	Payload           map[string]interface{}
+
+	SourceType        string // adapter name, e.g. "alertmanager"
+	SourceDisplayName string // e.g. "Prometheus Alertmanager"
+	SourceName        string // operator-given instance name
+}
+
+// NewAlertTemplateVars builds the template context for alert. instance may
+// be nil for alerts that did not arrive through a webhook source.
+func NewAlertTemplateVars(alert alerts.NormalizedAlert, instance *database.AlertSourceInstance) AlertTemplateVars {
+	labels := alert.TargetLabels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	vars := AlertTemplateVars{
+		AlertName:         alert.AlertName,
+		Severity:          string(alert.Severity),
+		SeverityEmoji:     database.GetSeverityEmoji(alert.Severity),
+		Status:            string(alert.Status),
+		Summary:           alert.Summary,
+		Description:       alert.Description,
+		Host:              alert.TargetHost,
+		Service:           alert.TargetService,
+		Labels:            labels,
+		MetricName:        alert.MetricName,
+		MetricValue:       alert.MetricValue,
+		ThresholdValue:    alert.ThresholdValue,
+		RunbookURL:        alert.RunbookURL,
+		StartedAt:         alert.StartedAt,
+		EndedAt:           alert.EndedAt,
+		SourceAlertID:     alert.SourceAlertID,
+		SourceFingerprint: alert.SourceFingerprint,
+		Payload:           alert.RawPayload,
+	}
+	if instance != nil {
+		vars.SourceType = instance.AlertSourceType.Name
+		vars.SourceDisplayName = instance.AlertSourceType.DisplayName
+		vars.SourceName = instance.Name
+	}
+	return vars
+}
+
+// SampleAlertTemplateVars returns representative alert data for previews
+// and template validation.
+func SampleAlertTemplateVars() AlertTemplateVars {
+	started := time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)
+	return AlertTemplateVars{
+		AlertName:         "HighCPUUsage",
+		Severity:          string(database.AlertSeverityCritical),
+		SeverityEmoji:     database.GetSeverityEmoji(database.AlertSeverityCritical),
+		Status:            string(database.AlertStatusFiring),
+		Summary:           "CPU usage above 95% for 5 minutes",
+		Description:       "Node web-01 has sustained CPU saturation.",
+		Host:              "web-01",
+		Service:           "nginx",
+		Labels:            map[string]string{"team": "platform", "env": "production"},
+		MetricName:        "node_cpu_usage",
+		MetricValue:       "97.2",
+		ThresholdValue:    "95",
+		RunbookURL:        "https://runbooks.example.com/cpu",
+		StartedAt:         &started,
+		SourceAlertID:     "a1b2c3",
+		SourceFingerprint: "5f0c9e2d7a41b3c8",
+		Payload:           map[string]interface{}{"receiver": "akmatori"},
+		SourceType:        "alertmanager",
+		SourceDisplayName: "Prometheus Alertmanager",
+		SourceName:        "prod-alertmanager",
+	}
+}
+
+// Template variable scopes: where a variable can be referenced.
+const (
+	TemplateScopePrompt       = "prompt"
+	TemplateScopeNotification = "notification"
+)
+
+// TemplateVariable documents one variable in the template catalog.
+type TemplateVariable struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Example     string   `json:"example"`
+	Scopes      []string `json:"scopes"`
+}
+
+var (
+	alertVarScopes        = []string{TemplateScopePrompt, TemplateScopeNotification}
+	notificationVarScopes = []string{TemplateScopeNotification}
+)
+
+// templateVariables is the catalog served by GET /api/template-variables.
+// TestTemplateVariables_CoverStructFields keeps it in sync with the structs.
+var templateVariables = []TemplateVariable{
+	{".AlertName", "string", "Alert name", "{{.AlertName}}", alertVarScopes},
+	{".Severity", "string", "Normalized severity: critical, high, warning or info", "{{.Severity}}", alertVarScopes},
+	{".SeverityEmoji", "string", "Slack emoji for the severity", "{{.SeverityEmoji}}", alertVarScopes},
+	{".Status", "string", "firing or resolved", "{{.Status}}", alertVarScopes},
+	{".Summary", "string", "Short alert summary", "{{.Summary}}", alertVarScopes},
+	{".Description", "string", "Full alert description", "{{.Description}}", alertVarScopes},
+	{".Host", "string", "Target host", "{{.Host}}", alertVarScopes},
+	{".Service", "string", "Target service", "{{.Service}}", alertVarScopes},
+	{".Labels", "map[string]string", "All alert labels; missing keys render empty", "{{.Labels.team}}", alertVarScopes},
+	{".MetricName", "string", "Metric that triggered the alert", "{{.MetricName}}", alertVarScopes},
+	{".MetricValue", "string", "Observed metric value", "{{.MetricValue}}", alertVarScopes},
+	{".ThresholdValue", "string", "Alerting threshold", "{{.ThresholdValue}}", alertVarScopes},
+	{".RunbookURL", "string", "Runbook link from the alert", "{{if .RunbookURL}}{{.RunbookURL}}{{end}}", alertVarScopes},
+	{".StartedAt", "time", "When the alert started firing; nil when unknown", `{{if .StartedAt}}{{.StartedAt.Format "2006-01-02 15:04"}}{{end}}`, alertVarScopes},
+	{".EndedAt", "time", "When the alert resolved; nil while firing", `{{if .EndedAt}}{{.EndedAt.Format "15:04"}}{{end}}`, alertVarScopes},
+	{".SourceAlertID", "string", "Alert ID in the upstream system", "{{.SourceAlertID}}", alertVarScopes},
+	{".SourceFingerprint", "string", "Upstream fingerprint used for deduplication", "{{.SourceFingerprint}}", alertVarScopes},
+	{".Payload", "map[string]any", "Raw webhook payload as received", "{{index .Payload \"receiver\"}}", alertVarScopes},
+	{".SourceType", "string", "Adapter name, e.g. alertmanager", "{{.SourceType}}", alertVarScopes},
+	{".SourceDisplayName", "string", "Adapter display name", "{{.SourceDisplayName}}", alertVarScopes},
+	{".SourceName", "string", "Alert source instance name", "{{.SourceName}}", alertVarScopes},
+	{".IncidentUUID", "string", "Incident the alert belongs to", "{{.IncidentUUID}}", notificationVarScopes},
+	{".IncidentTitle", "string", "Incident title", "{{.IncidentTitle}}", notificationVarScopes},
+	{".IncidentURL", "string", "Link to the incident in the UI", "{{.IncidentURL}}", notificationVarScopes},
+	{".AlertCount", "int", "Alerts linked to the incident", "{{.AlertCount}}", notificationVarScopes},
+	{".Error", "string", "Error text for failure notifications", "{{.Error}}", notificationVarScopes},
+	{".BaseURL", "string", "Configured UI base URL", "{{.BaseURL}}", notificationVarScopes},
+	{".Locale", "string", "Notification locale in effect", "{{.Locale}}", notificationVarScopes},
+}
+
+// TemplateVariables returns the documented variable catalog.
+func TemplateVariables() []TemplateVariable {
+	out := make([]TemplateVariable, len(templateVariables))
+	copy(out, templateVariables)
+	return out
+}
+
+// TemplateFunctions lists the helper functions available in every template.
+func TemplateFunctions() []string {
+	return []string{"upper", "lower", "trim", "default", "truncate", "plural", "index"}
+}
+
+// PromptTemplateSettingKey is the AlertSourceInstance.Settings key holding
+// an optional template appended to every investigation prompt for that
+// source.
+const PromptTemplateSettingKey = "prompt_template"
+
+// maxPromptTemplateBytes keeps operator prompt additions from crowding out
+// the alert context.
+const maxPromptTemplateBytes = 4000
+
+// RenderAlertTemplate parses and executes body against vars.
+func RenderAlertTemplate(body string, vars AlertTemplateVars) (string, error) {
+	return renderTemplate(body, vars)
+}
+
+// ValidatePromptTemplate checks that a source prompt template parses and
+// renders against sample alert data. An empty body is valid and disables
+// the template.
+func ValidatePromptTemplate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	if len(body) > maxPromptTemplateBytes {
+		return fmt.Errorf("prompt_template exceeds %d bytes", maxPromptTemplateBytes)
+	}
+	if _, err := RenderAlertTemplate(body, SampleAlertTemplateVars()); err != nil {
+		return fmt.Errorf("invalid prompt_template: %w", err)
+	}
+	return nil
+}
+
+// ValidateAlertSourceSettings validates the template-bearing keys, the
+// source-IP allowlist, the webhook verification, the silence threshold, the
+// plan approval flag, the grouping key and the enrichment step list of an
+// alert source's settings.
+func ValidateAlertSourceSettings(settings map[string]interface{}) error {
+	if _, err := ParseAllowedCIDRs(settings); err != nil {
+		return err
+	}
+	if _, err := ParseWebhookVerification(settings); err != nil {
+		return err
+	}
+	if _, err := ParsePlanApproval(settings); err != nil {
+		return err
+	}
+	if _, err := ParseFingerprintDedup(settings); err != nil {
+		return err
+	}
+	if _, err := ParseGroupingKeyTemplate(settings); err != nil {
+		return err
+	}
+	if _, err := ParseSeverityInference(settings); err != nil {
+		return err
+	}
+	if _, err := ParseSilenceThreshold(settings); err != nil {
+		return err
+	}
+	if _, _, err := ParseEnrichmentSteps(settings); err != nil {
+		return err
+	}
+	raw, ok := settings[PromptTemplateSettingKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	body, ok := raw.(string)
+	if !ok {
+		return errors.New("prompt_template must be a string")
+	}
+	return ValidatePromptTemplate(body)
+}
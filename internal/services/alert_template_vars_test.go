@@ -0,0 +1,100 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func TestTemplateVariables_CoverStructFields(t *testing.T) {
+	documented := map[string]bool{}
+	for _, v := range TemplateVariables() {
+		documented[v.Name] = true
+		if v.Example == "" || v.Description == "" || len(v.Scopes) == 0 {
+			t.Errorf("%s: incomplete catalog entry %+v", v.Name, v)
+		}
+	}
+
+	var walk func(reflect.Type)
+	walk = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Anonymous {
+				walk(f.Type)
+				continue
+			}
+			if !documented["."+f.Name] {
+				t.Errorf("field %s is missing from the template variable catalog", f.Name)
+			}
+			delete(documented, "."+f.Name)
+		}
+	}
+	walk(reflect.TypeOf(NotificationData{}))
+	for name := range documented {
+		t.Errorf("catalog entry %s has no matching field", name)
+	}
+
+	// Every documented example must render against the sample data.
+	for _, v := range TemplateVariables() {
+		if _, err := RenderNotificationTemplate(v.Example, SampleNotificationData()); err != nil {
+			t.Errorf("%s example %q: %v", v.Name, v.Example, err)
+		}
+	}
+}
+
+func TestNewAlertTemplateVars(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	alert := alerts.NormalizedAlert{
+		AlertName:         "DiskFull",
+		Severity:          database.AlertSeverityWarning,
+		Status:            database.AlertStatusFiring,
+		TargetHost:        "db-01",
+		StartedAt:         &started,
+		SourceFingerprint: "fp-1",
+		RawPayload:        map[string]interface{}{"region": "eu-west-1"},
+	}
+	instance := &database.AlertSourceInstance{
+		Name:            "prod",
+		AlertSourceType: database.AlertSourceType{Name: "grafana", DisplayName: "Grafana"},
+	}
+
+	vars := NewAlertTemplateVars(alert, instance)
+	body := `{{.AlertName}}|{{.Host}}|{{.SeverityEmoji}}|{{.SourceType}}/{{.SourceName}}|{{.StartedAt.Format "2006-01-02"}}|{{.SourceFingerprint}}|{{index .Payload "region"}}|{{default "none" .Labels.team}}`
+	got, err := RenderAlertTemplate(body, vars)
+	if err != nil {
+		t.Fatalf("RenderAlertTemplate: %v", err)
+	}
+	want := "DiskFull|db-01|:large_yellow_circle:|grafana/prod|2026-03-01|fp-1|eu-west-1|none"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Without a source instance the source fields stay empty.
+	if v := NewAlertTemplateVars(alert, nil); v.SourceType != "" || v.Labels == nil {
+		t.Errorf("nil instance vars = %+v", v)
+	}
+}
+
+func TestValidateAlertSourceSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  bool
+	}{
+		{"no settings", nil, false},
+		{"other keys only", map[string]interface{}{"slack_channel_id": "C1"}, false},
+		{"empty template", map[string]interface{}{"prompt_template": "  "}, false},
+		{"valid template", map[string]interface{}{"prompt_template": "Page {{.Labels.team}} for {{.AlertName}}"}, false},
+		{"not a string", map[string]interface{}{"prompt_template": 42}, true},
+		{"parse error", map[string]interface{}{"prompt_template": "{{.AlertName"}, true},
+		{"notification-only field", map[string]interface{}{"prompt_template": "{{.IncidentURL}}"}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAlertSourceSettings(tt.settings); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
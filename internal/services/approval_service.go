@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// approvalSweepInterval is how often pending approvals are posted to Slack
+// and stale ones expired. The gateway holds the tool call while it waits, so
+// this is kept short.
+const approvalSweepInterval = 3 * time.Second
+
+// approvalExpiryGrace is how long past ExpiresAt a pending approval may sit
+// before the sweep expires it. The gateway expires its own requests on
+// time; the sweep only catches ones it abandoned (e.g. it restarted).
+const approvalExpiryGrace = time.Minute
+
+var (
+	// ErrApprovalNotPending is returned when deciding an approval that was
+	// already decided or has expired.
+	ErrApprovalNotPending = errors.New("approval is no longer pending")
+)
+
+// ApprovalService records human decisions on the destructive tool calls the
+// MCP gateway holds for approval. A background sweep posts new requests to
+// the incident's Slack thread with Approve/Reject buttons and keeps those
+// messages in step with the decision. Rejecting an approval also cancels the
+// investigation: the agent asked to do something a human refused, so it
+// should stop rather than look for another way to do it.
+type ApprovalService struct {
+	db        *gorm.DB
+	notifier  ApprovalNotifier         // optional; nil = API/UI only
+	canceller IncidentCanceller        // optional; nil = rejection only fails the tool call
+	timeline  IncidentTimelineRecorder // optional
+}
+
+// NewApprovalService creates an approval service.
+func NewApprovalService(db *gorm.DB) *ApprovalService {
+	return &ApprovalService{db: db}
+}
+
+// SetNotifier wires where approval requests are posted. Optional.
+func (s *ApprovalService) SetNotifier(n ApprovalNotifier) {
+	s.notifier = n
+}
+
+// SetIncidentCanceller wires the runner used to abort an investigation
+// whose approval was rejected. Optional.
+func (s *ApprovalService) SetIncidentCanceller(c IncidentCanceller) {
+	s.canceller = c
+}
+
+// SetIncidentTimeline wires the timeline approval decisions are recorded
+// on. Optional.
+func (s *ApprovalService) SetIncidentTimeline(t IncidentTimelineRecorder) {
+	s.timeline = t
+}
+
+// ListApprovals returns approvals newest first, optionally filtered by
+// status and incident.
+func (s *ApprovalService) ListApprovals(ctx context.Context, status, incidentUUID string) ([]database.ToolApproval, error) {
+	q := s.db.WithContext(ctx).Order("created_at DESC, id DESC").Limit(200)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if incidentUUID != "" {
+		q = q.Where("incident_uuid = ?", incidentUUID)
+	}
+	approvals := []database.ToolApproval{}
+	if err := q.Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// Decide approves or rejects a pending approval on behalf of decidedBy.
+// Returns gorm.ErrRecordNotFound for an unknown approval and
+// ErrApprovalNotPending when it was already decided or expired.
+func (s *ApprovalService) Decide(ctx context.Context, approvalUUID string, approve bool, decidedBy, reason string) (*database.ToolApproval, error) {
+	status := database.ToolApprovalRejected
+	if approve {
+		status = database.ToolApprovalApproved
+	}
+	if decidedBy == "" {
+		decidedBy = "user"
+	}
+	now := time.Now()
+
+	// The status guard makes the decision first-writer-wins when two people
+	// click at once, or a click races the gateway's own expiry.
+	res := s.db.WithContext(ctx).Model(&database.ToolApproval{}).
+		Where("uuid = ? AND status = ?", approvalUUID, database.ToolApprovalPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"decided_by": decidedBy,
+			"reason":     strings.TrimSpace(reason),
+			"decided_at": now,
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	var approval database.ToolApproval
+	if err := s.db.WithContext(ctx).Where("uuid = ?", approvalUUID).First(&approval).Error; err != nil {
+		return nil, err
+	}
+	if res.RowsAffected == 0 {
+		return &approval, ErrApprovalNotPending
+	}
+
+	slog.Info("tool approval decided", "approval", approval.UUID, "incident_uuid", approval.IncidentUUID,
+		"tool", approval.ToolName, "status", status, "by", decidedBy)
+	s.recordDecision(&approval)
+	if status == database.ToolApprovalRejected && s.canceller != nil {
+		if err := s.canceller.CancelIncident(approval.IncidentUUID); err != nil {
+			slog.Warn("failed to cancel investigation after rejected approval", "incident_uuid", approval.IncidentUUID, "err", err)
+		}
+	}
+	s.syncNotification(ctx, &approval)
+	return &approval, nil
+}
+
+// RunSweep posts approvals not yet announced, expires ones the gateway
+// abandoned and brings announced messages up to date with their status.
+func (s *ApprovalService) RunSweep(ctx context.Context) error {
+	cutoff := time.Now().Add(-approvalExpiryGrace)
+	if err := s.db.WithContext(ctx).Model(&database.ToolApproval{}).
+		Where("status = ? AND expires_at < ?", database.ToolApprovalPending, cutoff).
+		Update("status", database.ToolApprovalExpired).Error; err != nil {
+		return fmt.Errorf("expire approvals: %w", err)
+	}
+
+	var stale []database.ToolApproval
+	if err := s.db.WithContext(ctx).
+		Where("notified_status IS NULL OR notified_status <> status").
+		Order("id").Limit(50).Find(&stale).Error; err != nil {
+		return fmt.Errorf("load approvals: %w", err)
+	}
+	for i := range stale {
+		s.syncNotification(ctx, &stale[i])
+	}
+	return nil
+}
+
+// StartBackgroundSweep runs RunSweep on a short ticker until ctx is
+// cancelled.
+func (s *ApprovalService) StartBackgroundSweep(ctx context.Context) {
+	slog.Info("starting tool approval sweep")
+	ticker := time.NewTicker(approvalSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("tool approval sweep stopped")
+			return
+		case <-ticker.C:
+			if err := s.RunSweep(ctx); err != nil {
+				slog.Error("tool approval sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// syncNotification posts or updates the approval's Slack message so it
+// shows the current status, then remembers what it showed. With no notifier
+// (or no Slack thread for the incident) there is nothing to show, and the
+// approval is simply marked as handled.
+func (s *ApprovalService) syncNotification(ctx context.Context, approval *database.ToolApproval) {
+	if approval.NotifiedStatus == approval.Status {
+		return
+	}
+	updates := map[string]interface{}{"notified_status": approval.Status}
+	if s.notifier != nil {
+		switch {
+		case approval.NotifiedStatus == "" && approval.Status == database.ToolApprovalPending:
+			var incident database.Incident
+			err := s.db.WithContext(ctx).Where("uuid = ?", approval.IncidentUUID).First(&incident).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break // nothing to post under; the API and UI still show it
+			}
+			if err != nil {
+				slog.Warn("approval: failed to load incident", "incident_uuid", approval.IncidentUUID, "err", err)
+				return
+			}
+			channelID, messageTS, err := s.notifier.NotifyApproval(ctx, approval, &incident)
+			if err != nil {
+				// Retried on the next sweep.
+				slog.Warn("approval: failed to post request", "approval", approval.UUID, "err", err)
+				return
+			}
+			updates["slack_channel_id"] = channelID
+			updates["slack_message_ts"] = messageTS
+			approval.SlackChannelID, approval.SlackMessageTS = channelID, messageTS
+		case approval.SlackMessageTS != "":
+			if err := s.notifier.ResolveApproval(ctx, approval); err != nil {
+				slog.Warn("approval: failed to update request", "approval", approval.UUID, "err", err)
+				return
+			}
+		}
+	}
+	if err := s.db.WithContext(ctx).Model(&database.ToolApproval{}).Where("id = ?", approval.ID).Updates(updates).Error; err != nil {
+		slog.Warn("approval: failed to save notification state", "approval", approval.UUID, "err", err)
+		return
+	}
+	approval.NotifiedStatus = approval.Status
+}
+
+func (s *ApprovalService) recordDecision(approval *database.ToolApproval) {
+	if s.timeline == nil {
+		return
+	}
+	verb := "Approved"
+	if approval.Status == database.ToolApprovalRejected {
+		verb = "Rejected"
+	}
+	summary := fmt.Sprintf("%s %s on %s", verb, approval.ToolName, approval.Target)
+	s.timeline.RecordEvent(database.IncidentEvent{
+		IncidentUUID: approval.IncidentUUID,
+		Type:         database.IncidentEventApproval,
+		Summary:      summary,
+		Details: database.JSONB{
+			"approval_uuid": approval.UUID,
+			"tool":          approval.ToolName,
+			"target":        approval.Target,
+			"detail":        truncateForPrompt(approval.Detail, maxTimelineCommandBytes),
+			"status":        string(approval.Status),
+			"reason":        approval.Reason,
+		},
+		Actor: approval.DecidedBy,
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type stubApprovalNotifier struct {
+	posted   []string
+	resolved []database.ToolApprovalStatus
+}
+
+func (s *stubApprovalNotifier) NotifyApproval(_ context.Context, approval *database.ToolApproval, incident *database.Incident) (string, string, error) {
+	if incident.SlackChannelID == "" {
+		return "", "", nil
+	}
+	s.posted = append(s.posted, approval.UUID)
+	return incident.SlackChannelID, "1700000000.000100", nil
+}
+
+func (s *stubApprovalNotifier) ResolveApproval(_ context.Context, approval *database.ToolApproval) error {
+	s.resolved = append(s.resolved, approval.Status)
+	return nil
+}
+
+type stubIncidentCanceller struct{ cancelled []string }
+
+func (s *stubIncidentCanceller) CancelIncident(incidentID string) error {
+	s.cancelled = append(s.cancelled, incidentID)
+	return nil
+}
+
+type recordingTimeline struct{ events []database.IncidentEvent }
+
+func (r *recordingTimeline) RecordEvent(event database.IncidentEvent) {
+	r.events = append(r.events, event)
+}
+
+func setupApprovalTest(t *testing.T) (*gorm.DB, *ApprovalService, *stubApprovalNotifier, *stubIncidentCanceller, *recordingTimeline) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}, &database.ToolApproval{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&database.Incident{UUID: "inc-slack", Title: "t", Status: database.IncidentStatusRunning,
+		SlackChannelID: "C1", SlackMessageTS: "1699999999.000001"})
+	db.Create(&database.Incident{UUID: "inc-ui", Title: "t", Status: database.IncidentStatusRunning})
+
+	svc := NewApprovalService(db)
+	notifier, canceller, timeline := &stubApprovalNotifier{}, &stubIncidentCanceller{}, &recordingTimeline{}
+	svc.SetNotifier(notifier)
+	svc.SetIncidentCanceller(canceller)
+	svc.SetIncidentTimeline(timeline)
+	return db, svc, notifier, canceller, timeline
+}
+
+func createApproval(t *testing.T, db *gorm.DB, uuid, incidentUUID string, expiresAt time.Time) {
+	t.Helper()
+	if err := db.Create(&database.ToolApproval{
+		UUID: uuid, IncidentUUID: incidentUUID, ToolName: "ssh.execute_command", Target: "web-1",
+		Detail: "systemctl restart nginx", Status: database.ToolApprovalPending, ExpiresAt: expiresAt,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApprovalService_SweepPostsAndApproveResumes(t *testing.T) {
+	db, svc, notifier, canceller, timeline := setupApprovalTest(t)
+	ctx := context.Background()
+	createApproval(t, db, "ap-1", "inc-slack", time.Now().Add(5*time.Minute))
+	createApproval(t, db, "ap-2", "inc-ui", time.Now().Add(5*time.Minute))
+
+	if err := svc.RunSweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RunSweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.posted) != 1 || notifier.posted[0] != "ap-1" {
+		t.Fatalf("posted = %v, want only the incident with a Slack thread, once", notifier.posted)
+	}
+
+	approval, err := svc.Decide(ctx, "ap-1", true, "alice", "")
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if approval.Status != database.ToolApprovalApproved || approval.DecidedBy != "alice" || approval.DecidedAt == nil {
+		t.Errorf("approval = %+v", approval)
+	}
+	if len(notifier.resolved) != 1 || notifier.resolved[0] != database.ToolApprovalApproved {
+		t.Errorf("resolved = %v", notifier.resolved)
+	}
+	if len(canceller.cancelled) != 0 {
+		t.Errorf("approving must not cancel the investigation: %v", canceller.cancelled)
+	}
+	if len(timeline.events) != 1 || timeline.events[0].Type != database.IncidentEventApproval || timeline.events[0].Actor != "alice" {
+		t.Errorf("timeline = %+v", timeline.events)
+	}
+
+	if _, err := svc.Decide(ctx, "ap-1", false, "bob", ""); !errors.Is(err, ErrApprovalNotPending) {
+		t.Errorf("second decision: err = %v", err)
+	}
+	if _, err := svc.Decide(ctx, "missing", true, "bob", ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("unknown approval: err = %v", err)
+	}
+}
+
+func TestApprovalService_RejectCancelsInvestigation(t *testing.T) {
+	db, svc, _, canceller, _ := setupApprovalTest(t)
+	ctx := context.Background()
+	createApproval(t, db, "ap-3", "inc-ui", time.Now().Add(5*time.Minute))
+
+	approval, err := svc.Decide(ctx, "ap-3", false, "", "  not during peak  ")
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if approval.Status != database.ToolApprovalRejected || approval.Reason != "not during peak" || approval.DecidedBy != "user" {
+		t.Errorf("approval = %+v", approval)
+	}
+	if len(canceller.cancelled) != 1 || canceller.cancelled[0] != "inc-ui" {
+		t.Errorf("cancelled = %v", canceller.cancelled)
+	}
+
+	pending, err := svc.ListApprovals(ctx, string(database.ToolApprovalPending), "")
+	if err != nil || len(pending) != 0 {
+		t.Errorf("pending = %v, err %v", pending, err)
+	}
+}
+
+func TestApprovalService_SweepExpiresAbandoned(t *testing.T) {
+	db, svc, notifier, _, _ := setupApprovalTest(t)
+	ctx := context.Background()
+	createApproval(t, db, "ap-4", "inc-slack", time.Now().Add(5*time.Minute))
+	if err := svc.RunSweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The gateway went away and never expired it.
+	db.Model(&database.ToolApproval{}).Where("uuid = ?", "ap-4").Update("expires_at", time.Now().Add(-2*approvalExpiryGrace))
+
+	if err := svc.RunSweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var approval database.ToolApproval
+	db.Where("uuid = ?", "ap-4").First(&approval)
+	if approval.Status != database.ToolApprovalExpired {
+		t.Errorf("status = %s, want expired", approval.Status)
+	}
+	if len(notifier.resolved) != 1 || notifier.resolved[0] != database.ToolApprovalExpired {
+		t.Errorf("resolved = %v", notifier.resolved)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// AuditService persists append-only audit log entries.
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService creates a new audit service.
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record appends entry to the audit log. Outcome defaults to success when
+// left empty.
+func (s *AuditService) Record(entry *database.AuditLog) error {
+	if entry.Action == "" {
+		return fmt.Errorf("audit entry requires an action")
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = database.AuditOutcomeSuccess
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("record audit entry %q: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// Audit log listing bounds.
+const (
+	DefaultAuditLogLimit = 100
+	MaxAuditLogLimit     = 1000
+)
+
+// AuditLogFilter narrows ListAuditLogs. Zero fields match everything.
+type AuditLogFilter struct {
+	Actor      string
+	EntityType string
+	Target     string
+	// Action matches exactly, or by prefix when it ends in "." (e.g. "config.").
+	Action string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// ListAuditLogs returns matching entries, newest first.
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter AuditLogFilter) ([]database.AuditLog, error) {
+	q := s.db.WithContext(ctx).Model(&database.AuditLog{})
+	if filter.Actor != "" {
+		q = q.Where("actor = ?", filter.Actor)
+	}
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.Target != "" {
+		q = q.Where("target = ?", filter.Target)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		q = q.Where("action LIKE ?", filter.Action+"%")
+	} else if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q = q.Where("created_at < ?", filter.Until)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLogLimit
+	}
+	if limit > MaxAuditLogLimit {
+		limit = MaxAuditLogLimit
+	}
+
+	var entries []database.AuditLog
+	if err := q.Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("list audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestAuditService_Record_DefaultsOutcome(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.AuditLog{})
+	svc := NewAuditService(db)
+
+	entry := &database.AuditLog{
+		Action:   database.AuditActionLoginSucceeded,
+		Actor:    "admin",
+		RemoteIP: "203.0.113.7",
+	}
+	if err := svc.Record(entry); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	var stored database.AuditLog
+	if err := db.First(&stored, entry.ID).Error; err != nil {
+		t.Fatalf("load audit row: %v", err)
+	}
+	if stored.Outcome != database.AuditOutcomeSuccess {
+		t.Errorf("Outcome = %q, want %q", stored.Outcome, database.AuditOutcomeSuccess)
+	}
+	if stored.Actor != "admin" || stored.RemoteIP != "203.0.113.7" {
+		t.Errorf("unexpected stored row: %+v", stored)
+	}
+	if stored.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be set")
+	}
+}
+
+func TestAuditService_Record_RequiresAction(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.AuditLog{})
+	svc := NewAuditService(db)
+
+	if err := svc.Record(&database.AuditLog{Actor: "admin"}); err == nil {
+		t.Fatal("expected error for entry without action")
+	}
+
+	var count int64
+	db.Model(&database.AuditLog{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no rows, got %d", count)
+	}
+}
+
+func TestAuditService_ListAuditLogs_Filters(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.AuditLog{})
+	svc := NewAuditService(db)
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []database.AuditLog{
+		{Action: database.AuditActionLoginSucceeded, Actor: "alice"},
+		{Action: database.AuditActionConfigUpdate, Actor: "alice", EntityType: "skill", Target: "pg-triage"},
+		{Action: database.AuditActionConfigDelete, Actor: "bob", EntityType: "tool", Target: "12"},
+		{Action: database.AuditActionConfigCreate, Actor: "alice", EntityType: "skill", Target: "dns"},
+	} {
+		e.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		if err := svc.Record(&e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter AuditLogFilter
+		want   []string // targets, newest first
+	}{
+		{"all", AuditLogFilter{}, []string{"dns", "12", "pg-triage", ""}},
+		{"user", AuditLogFilter{Actor: "bob"}, []string{"12"}},
+		{"entity", AuditLogFilter{EntityType: "skill", Target: "pg-triage"}, []string{"pg-triage"}},
+		{"action prefix", AuditLogFilter{Action: "config.", Actor: "alice"}, []string{"dns", "pg-triage"}},
+		{"time range", AuditLogFilter{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, []string{"12", "pg-triage"}},
+		{"limit", AuditLogFilter{Limit: 1}, []string{"dns"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := svc.ListAuditLogs(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListAuditLogs: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Target)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("targets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
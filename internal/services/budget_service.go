@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// ErrBudgetExceeded is returned by CheckBudget once an LLM budget is spent.
+// The wrapping error names the limit that was reached.
+var ErrBudgetExceeded = errors.New("LLM budget exceeded")
+
+// BudgetWindow is the spend of one budget window and its limits (zero when
+// unset).
+type BudgetWindow struct {
+	Start        time.Time `json:"start"`
+	Resets       time.Time `json:"resets"`
+	TokensUsed   int64     `json:"tokens_used"`
+	CostUSD      float64   `json:"cost_usd"`
+	TokenLimit   int64     `json:"token_limit"`
+	CostLimitUSD float64   `json:"cost_limit_usd"`
+}
+
+// exceeded returns a description of the limit w has reached, or "".
+func (w BudgetWindow) exceeded(name string) string {
+	if w.TokenLimit > 0 && w.TokensUsed >= w.TokenLimit {
+		return fmt.Sprintf("%s token budget of %d reached (%d used)", name, w.TokenLimit, w.TokensUsed)
+	}
+	if w.CostLimitUSD > 0 && w.CostUSD >= w.CostLimitUSD {
+		return fmt.Sprintf("%s cost budget of $%.2f reached ($%.2f spent)", name, w.CostLimitUSD, w.CostUSD)
+	}
+	return ""
+}
+
+// BudgetStatus is the current spend against the configured budgets.
+type BudgetStatus struct {
+	Enabled  bool         `json:"enabled"`
+	Exceeded bool         `json:"exceeded"`
+	Reason   string       `json:"reason,omitempty"`
+	Daily    BudgetWindow `json:"daily"`
+	Monthly  BudgetWindow `json:"monthly"`
+}
+
+// BudgetService enforces BudgetSettings against the token_usage table.
+// Checks fail open: an unreadable settings row or usage query lets the
+// investigation run and is only logged.
+type BudgetService struct {
+	db       *gorm.DB
+	channels ChannelManager
+	registry ProviderRegistry
+	now      func() time.Time
+
+	mu       sync.Mutex
+	notified map[string]bool // windows already announced, e.g. "daily:2026-03-02"
+}
+
+// NewBudgetService creates a budget service.
+func NewBudgetService(db *gorm.DB) *BudgetService {
+	return &BudgetService{db: db, now: time.Now, notified: make(map[string]bool)}
+}
+
+// SetNotifier wires the channel lookup and provider registry used to
+// announce an exceeded budget. Without it investigations are still declined.
+func (s *BudgetService) SetNotifier(channels ChannelManager, registry ProviderRegistry) {
+	s.channels = channels
+	s.registry = registry
+}
+
+// BudgetStatus reports the spend of the current day and month.
+func (s *BudgetService) BudgetStatus(ctx context.Context) (*BudgetStatus, error) {
+	settings, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("load budget settings: %w", err)
+	}
+	return s.status(ctx, settings)
+}
+
+func (s *BudgetService) status(ctx context.Context, settings *database.BudgetSettings) (*BudgetStatus, error) {
+	now := s.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	status := &BudgetStatus{
+		Enabled: settings.Enabled,
+		Daily: BudgetWindow{
+			Start: day, Resets: day.AddDate(0, 0, 1),
+			TokenLimit: settings.DailyTokenLimit, CostLimitUSD: settings.DailyCostLimitUSD,
+		},
+		Monthly: BudgetWindow{
+			Start: month, Resets: month.AddDate(0, 1, 0),
+			TokenLimit: settings.MonthlyTokenLimit, CostLimitUSD: settings.MonthlyCostLimitUSD,
+		},
+	}
+	for _, w := range []*BudgetWindow{&status.Daily, &status.Monthly} {
+		var sum struct {
+			Tokens int64
+			Cost   float64
+		}
+		if err := s.db.WithContext(ctx).Model(&database.TokenUsage{}).
+			Select("COALESCE(SUM(tokens_used), 0) AS tokens, COALESCE(SUM(estimated_cost_usd), 0) AS cost").
+			Where("recorded_at >= ?", w.Start).
+			Scan(&sum).Error; err != nil {
+			return nil, fmt.Errorf("sum token usage: %w", err)
+		}
+		w.TokensUsed, w.CostUSD = sum.Tokens, sum.Cost
+	}
+	if settings.Enabled {
+		if reason := status.Daily.exceeded("daily"); reason != "" {
+			status.Exceeded, status.Reason = true, reason
+		} else if reason := status.Monthly.exceeded("monthly"); reason != "" {
+			status.Exceeded, status.Reason = true, reason
+		}
+	}
+	return status, nil
+}
+
+// CheckBudget returns an error wrapping ErrBudgetExceeded when budgets are
+// enabled and one is spent. The first refusal of each window is announced
+// on the notification channel.
+func (s *BudgetService) CheckBudget(ctx context.Context) error {
+	settings, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		slog.Warn("budget: failed to load settings, allowing run", "err", err)
+		return nil
+	}
+	if !settings.Enabled {
+		return nil
+	}
+	status, err := s.status(ctx, settings)
+	if err != nil {
+		slog.Warn("budget: failed to read spend, allowing run", "err", err)
+		return nil
+	}
+	if !status.Exceeded {
+		return nil
+	}
+	s.announce(ctx, settings, status)
+	return fmt.Errorf("%w: %s", ErrBudgetExceeded, status.Reason)
+}
+
+// announce posts the exceeded notice once per budget window.
+func (s *BudgetService) announce(ctx context.Context, settings *database.BudgetSettings, status *BudgetStatus) {
+	window, resets := "monthly:"+status.Monthly.Start.Format("2006-01"), status.Monthly.Resets
+	if status.Daily.exceeded("daily") != "" {
+		window, resets = "daily:"+status.Daily.Start.Format("2006-01-02"), status.Daily.Resets
+	}
+	s.mu.Lock()
+	if s.notified[window] {
+		s.mu.Unlock()
+		return
+	}
+	s.notified[window] = true
+	s.mu.Unlock()
+
+	slog.Warn("budget: declining new investigations", "reason", status.Reason, "resets", resets)
+	if s.channels == nil || s.registry == nil {
+		return
+	}
+	var channel *database.Channel
+	var err error
+	if settings.NotificationChannelUUID != "" {
+		channel, err = s.channels.GetChannelByUUID(settings.NotificationChannelUUID)
+	} else {
+		channel, err = s.channels.ResolveDefault(database.MessagingProviderSlack)
+	}
+	if err != nil || channel == nil {
+		slog.Debug("budget: no notification channel", "err", err)
+		return
+	}
+	provider, err := s.registry.Get(channel.Integration.Provider)
+	if err != nil {
+		slog.Warn("budget: notification provider unavailable", "provider", channel.Integration.Provider, "err", err)
+		return
+	}
+	text := fmt.Sprintf(":money_with_wings: *Akmatori LLM budget exceeded:* %s. New investigations are declined until %s.",
+		status.Reason, resets.Format("2006-01-02 15:04 MST"))
+	if _, err := provider.PostMessage(ctx, channel, text); err != nil {
+		slog.Warn("budget: failed to post notification", "err", err)
+	}
+}
+
+// BudgetDeclineNote is the incident response of an investigation declined
+// because of err (from CheckBudget).
+func BudgetDeclineNote(err error) string {
+	return fmt.Sprintf("💸 Not investigated: %v. Raise or disable the limit under LLM settings to resume investigations.", err)
+}
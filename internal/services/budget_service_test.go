@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestBudgetService_DeclinesOnceSpentAndAnnouncesOncePerWindow(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.BudgetSettings{}, &database.TokenUsage{})
+	svc := NewBudgetService(db)
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	provider := &recordingProvider{}
+	svc.SetNotifier(&recordingChannelManager{
+		resolveDefault: &database.Channel{UUID: "ch-default", Integration: database.Integration{Provider: database.MessagingProviderSlack}},
+	}, &fakeProviderRegistry{provider: provider})
+	ctx := context.Background()
+
+	cost := 3.0
+	db.Create(&[]database.TokenUsage{
+		{IncidentUUID: "inc-1", TokensUsed: 40_000, EstimatedCostUSD: &cost, RecordedAt: now.Add(-time.Hour)},
+		{IncidentUUID: "inc-0", TokensUsed: 90_000, RecordedAt: now.AddDate(0, 0, -3)}, // earlier this month
+	})
+
+	// Disabled budgets never decline, whatever the limits say.
+	settings, err := database.GetOrCreateBudgetSettings()
+	if err != nil {
+		t.Fatalf("GetOrCreateBudgetSettings: %v", err)
+	}
+	settings.DailyTokenLimit = 10_000
+	if err := database.UpdateBudgetSettings(settings); err != nil {
+		t.Fatalf("UpdateBudgetSettings: %v", err)
+	}
+	if err := svc.CheckBudget(ctx); err != nil {
+		t.Fatalf("disabled budget declined: %v", err)
+	}
+
+	settings.Enabled = true
+	settings.DailyTokenLimit = 50_000
+	settings.MonthlyTokenLimit = 200_000
+	settings.DailyCostLimitUSD = 5
+	if err := database.UpdateBudgetSettings(settings); err != nil {
+		t.Fatalf("UpdateBudgetSettings: %v", err)
+	}
+	if err := svc.CheckBudget(ctx); err != nil {
+		t.Fatalf("budget within limits declined: %v", err)
+	}
+
+	status, err := svc.BudgetStatus(ctx)
+	if err != nil {
+		t.Fatalf("BudgetStatus: %v", err)
+	}
+	if status.Daily.TokensUsed != 40_000 || status.Daily.CostUSD != 3 || status.Monthly.TokensUsed != 130_000 {
+		t.Errorf("status = %+v, want 40000 tokens/$3 today and 130000 this month", status)
+	}
+	if !status.Daily.Resets.Equal(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily window resets at %v", status.Daily.Resets)
+	}
+
+	cost = 2.5
+	db.Create(&database.TokenUsage{IncidentUUID: "inc-2", TokensUsed: 1000, EstimatedCostUSD: &cost, RecordedAt: now})
+	for i := 0; i < 2; i++ {
+		err := svc.CheckBudget(ctx)
+		if !errors.Is(err, ErrBudgetExceeded) || !strings.Contains(err.Error(), "daily cost budget of $5.00") {
+			t.Fatalf("CheckBudget = %v, want the daily cost budget exceeded", err)
+		}
+	}
+	if len(provider.posts) != 1 || !strings.Contains(provider.posts[0].text, "budget exceeded") {
+		t.Fatalf("expected one notification for the window, got %+v", provider.posts)
+	}
+
+	// A new day resets the daily budget.
+	now = now.AddDate(0, 0, 1)
+	if err := svc.CheckBudget(ctx); err != nil {
+		t.Errorf("next day declined: %v", err)
+	}
+}
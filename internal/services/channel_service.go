@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/akmatori/akmatori/internal/database"
@@ -179,6 +180,11 @@ func (s *ChannelService) DeleteIntegration(uuidStr string) error {
 				Update("channel_id", nil).Error; err != nil {
 				return fmt.Errorf("clear cron job channel refs: %w", err)
 			}
+			if err := tx.Model(&database.RoutingRule{}).
+				Where("notification_channel_id IN ?", channelIDs).
+				Update("notification_channel_id", nil).Error; err != nil {
+				return fmt.Errorf("clear routing rule channel refs: %w", err)
+			}
 		}
 		if err := tx.Where("integration_id = ?", row.ID).Delete(&database.Channel{}).Error; err != nil {
 			return fmt.Errorf("delete channels for integration %d: %w", row.ID, err)
@@ -264,6 +270,9 @@ func (s *ChannelService) CreateChannel(c *database.Channel) (*database.Channel,
 	if c.IsDefaultPost && !c.CanPost {
 		return nil, fmt.Errorf("channel marked is_default_post must also have can_post=true")
 	}
+	if err := normalizeChannelPresentation(c); err != nil {
+		return nil, err
+	}
 
 	requestedEnabled := c.Enabled
 	err := s.db.Transaction(func(tx *gorm.DB) error {
@@ -305,6 +314,11 @@ type ChannelUpdate struct {
 	ProcessBotMessages   *bool
 	ProcessHumanMessages *bool
 	Enabled              *bool
+	ReactionAlert        *string
+	ReactionWorking      *string
+	ReactionSuccess      *string
+	ReactionFailure      *string
+	ProgressVerbosity    *database.SlackProgressVerbosity
 }
 
 // UpdateChannel applies the supplied patch to an existing channel.
@@ -345,6 +359,30 @@ func (s *ChannelService) UpdateChannel(uuidStr string, patch ChannelUpdate) (*da
 	if patch.Enabled != nil {
 		updates["enabled"] = *patch.Enabled
 	}
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{
+		{"reaction_alert", patch.ReactionAlert},
+		{"reaction_working", patch.ReactionWorking},
+		{"reaction_success", patch.ReactionSuccess},
+		{"reaction_failure", patch.ReactionFailure},
+	} {
+		if field.value == nil {
+			continue
+		}
+		name, err := NormalizeSlackReaction(*field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.column, err)
+		}
+		updates[field.column] = name
+	}
+	if patch.ProgressVerbosity != nil {
+		if !patch.ProgressVerbosity.IsValid() {
+			return nil, fmt.Errorf("invalid progress_verbosity %q: must be off, status or thread", *patch.ProgressVerbosity)
+		}
+		updates["progress_verbosity"] = *patch.ProgressVerbosity
+	}
 	if len(updates) == 0 {
 		return row, nil
 	}
@@ -384,8 +422,49 @@ func (s *ChannelService) UpdateChannel(uuidStr string, patch ChannelUpdate) (*da
 	return row, nil
 }
 
-// DeleteChannel removes a channel by UUID. AlertSourceInstance and CronJob
-// rows referencing this channel have their FK nulled in the same transaction
+// slackReactionName matches the characters Slack allows in emoji names.
+var slackReactionName = regexp.MustCompile(`^[a-z0-9_+'-]{1,64}$`)
+
+// NormalizeSlackReaction validates a reaction override. Surrounding colons
+// are stripped so ":eyes:" and "eyes" are equivalent; the empty string
+// (use the default) and database.SlackReactionNone pass through unchanged.
+func NormalizeSlackReaction(name string) (string, error) {
+	name = strings.Trim(strings.TrimSpace(name), ":")
+	if name == "" || name == database.SlackReactionNone {
+		return name, nil
+	}
+	if !slackReactionName.MatchString(name) {
+		return "", fmt.Errorf("invalid Slack emoji name %q", name)
+	}
+	return name, nil
+}
+
+// normalizeChannelPresentation validates and normalizes the Slack reaction
+// and progress overrides on a channel about to be created.
+func normalizeChannelPresentation(c *database.Channel) error {
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{
+		{"reaction_alert", &c.ReactionAlert},
+		{"reaction_working", &c.ReactionWorking},
+		{"reaction_success", &c.ReactionSuccess},
+		{"reaction_failure", &c.ReactionFailure},
+	} {
+		name, err := NormalizeSlackReaction(*field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.column, err)
+		}
+		*field.value = name
+	}
+	if !c.ProgressVerbosity.IsValid() {
+		return fmt.Errorf("invalid progress_verbosity %q: must be off, status or thread", c.ProgressVerbosity)
+	}
+	return nil
+}
+
+// DeleteChannel removes a channel by UUID. AlertSourceInstance, CronJob and
+// RoutingRule rows referencing this channel have their FK nulled in the same transaction
 // so the triggers fall back to the per-provider default at runtime rather
 // than carrying a dangling reference.
 func (s *ChannelService) DeleteChannel(uuidStr string) error {
@@ -404,6 +483,11 @@ func (s *ChannelService) DeleteChannel(uuidStr string) error {
 			Update("channel_id", nil).Error; err != nil {
 			return fmt.Errorf("clear cron job channel refs: %w", err)
 		}
+		if err := tx.Model(&database.RoutingRule{}).
+			Where("notification_channel_id = ?", row.ID).
+			Update("notification_channel_id", nil).Error; err != nil {
+			return fmt.Errorf("clear routing rule channel refs: %w", err)
+		}
 		if err := tx.Delete(row).Error; err != nil {
 			return fmt.Errorf("delete channel: %w", err)
 		}
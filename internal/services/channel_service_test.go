@@ -27,6 +27,7 @@ func setupChannelServiceTest(t *testing.T) (*ChannelService, *gorm.DB) {
 		&database.Channel{},
 		&database.CronJob{},
 		&database.CronJobTool{},
+		&database.RoutingRule{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
@@ -282,6 +283,51 @@ func TestChannelService_UpdateChannel_AllowsSelfReSaveAsDefault(t *testing.T) {
 	}
 }
 
+func TestChannelService_UpdateChannel_SlackPresentation(t *testing.T) {
+	svc, db := setupChannelServiceTest(t)
+	integration := seedSlackIntegration(t, db)
+	channel, err := svc.CreateChannel(&database.Channel{
+		IntegrationID: integration.ID,
+		ExternalID:    "C-ops",
+		ReactionAlert: ":fire:",
+		Enabled:       true,
+	})
+	if err != nil {
+		t.Fatalf("seed channel: %v", err)
+	}
+	if channel.ReactionAlert != "fire" {
+		t.Errorf("CreateChannel ReactionAlert = %q, want colons stripped", channel.ReactionAlert)
+	}
+
+	working, success := "eyes", database.SlackReactionNone
+	thread := database.SlackProgressThread
+	updated, err := svc.UpdateChannel(channel.UUID, ChannelUpdate{
+		ReactionWorking:   &working,
+		ReactionSuccess:   &success,
+		ProgressVerbosity: &thread,
+	})
+	if err != nil {
+		t.Fatalf("UpdateChannel: %v", err)
+	}
+	got := updated.EffectiveSlackReactions()
+	want := database.SlackReactions{Alert: "fire", Working: "eyes", Success: "", Failure: database.DefaultSlackReactionFailure}
+	if got != want {
+		t.Errorf("EffectiveSlackReactions = %+v, want %+v", got, want)
+	}
+	if updated.EffectiveProgressVerbosity() != database.SlackProgressThread {
+		t.Errorf("EffectiveProgressVerbosity = %q, want thread", updated.EffectiveProgressVerbosity())
+	}
+
+	bad := "not an emoji"
+	if _, err := svc.UpdateChannel(channel.UUID, ChannelUpdate{ReactionFailure: &bad}); err == nil {
+		t.Error("UpdateChannel accepted an invalid emoji name")
+	}
+	loud := database.SlackProgressVerbosity("verbose")
+	if _, err := svc.UpdateChannel(channel.UUID, ChannelUpdate{ProgressVerbosity: &loud}); err == nil {
+		t.Error("UpdateChannel accepted an unknown progress verbosity")
+	}
+}
+
 func TestChannelService_ResolveDefault_ReturnsConfiguredChannel(t *testing.T) {
 	svc, db := setupChannelServiceTest(t)
 	integration := seedSlackIntegration(t, db)
@@ -500,6 +546,10 @@ func TestChannelService_DeleteIntegration_ClearsTriggerFKs(t *testing.T) {
 	if err := db.Create(cron).Error; err != nil {
 		t.Fatalf("seed cron: %v", err)
 	}
+	rule := &database.RoutingRule{UUID: uuid.New().String(), Name: "rule", NotificationChannelID: &channel.ID}
+	if err := db.Create(rule).Error; err != nil {
+		t.Fatalf("seed routing rule: %v", err)
+	}
 
 	if err := svc.DeleteIntegration(integration.UUID); err != nil {
 		t.Fatalf("DeleteIntegration error = %v", err)
@@ -519,6 +569,13 @@ func TestChannelService_DeleteIntegration_ClearsTriggerFKs(t *testing.T) {
 	if reloadedCron.ChannelID != nil {
 		t.Errorf("CronJob.ChannelID = %v, want nil", *reloadedCron.ChannelID)
 	}
+	var reloadedRule database.RoutingRule
+	if err := db.First(&reloadedRule, rule.ID).Error; err != nil {
+		t.Fatalf("reload routing rule: %v", err)
+	}
+	if reloadedRule.NotificationChannelID != nil {
+		t.Errorf("RoutingRule.NotificationChannelID = %v, want nil", *reloadedRule.NotificationChannelID)
+	}
 }
 
 // TestChannelService_CreateChannel_RejectsDefaultWithoutCanPost asserts the
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// DefaultComplianceDays is the report window when GET
+// /api/compliance/actions is called without since.
+const DefaultComplianceDays = 30
+
+// MaxComplianceWindow bounds since..until of one report.
+const MaxComplianceWindow = 366 * 24 * time.Hour
+
+// ErrInvalidComplianceFilter wraps filter validation failures so the API can
+// answer 400.
+var ErrInvalidComplianceFilter = errors.New("invalid compliance filter")
+
+// ComplianceFilter selects the report window. Zero times default to the last
+// DefaultComplianceDays.
+type ComplianceFilter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ComplianceAction is one write the automation executed on a remote host,
+// with the approval that let it run when the host required one.
+type ComplianceAction struct {
+	ExecutedAt    time.Time  `json:"executed_at"`
+	IncidentUUID  string     `json:"incident_uuid"`
+	IncidentTitle string     `json:"incident_title"`
+	Tool          string     `json:"tool"`
+	Target        string     `json:"target"` // host written to
+	Action        string     `json:"action"` // created | modified | deleted | command
+	Path          string     `json:"path,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+	ApprovalUUID  string     `json:"approval_uuid,omitempty"`
+	Approver      string     `json:"approver,omitempty"`
+	ApprovedAt    *time.Time `json:"approved_at,omitempty"`
+}
+
+// ComplianceReport is the response of GET /api/compliance/actions, oldest
+// action first.
+type ComplianceReport struct {
+	Since      time.Time          `json:"since"`
+	Until      time.Time          `json:"until"`
+	Total      int                `json:"total"`
+	Approved   int                `json:"approved"`
+	Unapproved int                `json:"unapproved"`
+	Actions    []ComplianceAction `json:"actions"`
+}
+
+// ComplianceService reports the writes investigations made outside their own
+// workspace, from the change manifest the MCP gateway records as each write
+// runs, joined with the human decision in tool_approvals.
+type ComplianceService struct {
+	db *gorm.DB
+}
+
+// NewComplianceService creates a new compliance service.
+func NewComplianceService(db *gorm.DB) *ComplianceService {
+	return &ComplianceService{db: db}
+}
+
+// Actions lists the remote writes executed in filter's window.
+func (s *ComplianceService) Actions(ctx context.Context, filter ComplianceFilter) (*ComplianceReport, error) {
+	until := filter.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	until = until.UTC()
+	since := filter.Since
+	if since.IsZero() {
+		since = until.AddDate(0, 0, -DefaultComplianceDays)
+	}
+	since = since.UTC()
+	if !since.Before(until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidComplianceFilter)
+	}
+	if until.Sub(since) > MaxComplianceWindow {
+		return nil, fmt.Errorf("%w: window must not exceed %d days", ErrInvalidComplianceFilter, int(MaxComplianceWindow/(24*time.Hour)))
+	}
+
+	db := s.db.WithContext(ctx)
+	var changes []database.IncidentChange
+	if err := db.
+		Where("location <> ? AND created_at >= ? AND created_at < ?", database.IncidentChangeLocationWorkspace, since, until).
+		Order("created_at asc, id asc").
+		Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("list incident changes: %w", err)
+	}
+
+	report := &ComplianceReport{Since: since, Until: until, Actions: make([]ComplianceAction, 0, len(changes))}
+	if len(changes) == 0 {
+		return report, nil
+	}
+
+	incidentUUIDs := make([]string, 0, len(changes))
+	seen := make(map[string]bool)
+	for _, c := range changes {
+		if !seen[c.IncidentUUID] {
+			seen[c.IncidentUUID] = true
+			incidentUUIDs = append(incidentUUIDs, c.IncidentUUID)
+		}
+	}
+	var incidents []database.Incident
+	if err := db.Select("uuid", "title").Where("uuid IN ?", incidentUUIDs).Find(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("load incidents: %w", err)
+	}
+	titles := make(map[string]string, len(incidents))
+	for _, inc := range incidents {
+		titles[inc.UUID] = inc.Title
+	}
+	var approvals []database.ToolApproval
+	if err := db.
+		Where("incident_uuid IN ? AND status = ?", incidentUUIDs, database.ToolApprovalApproved).
+		Order("decided_at desc").
+		Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("load tool approvals: %w", err)
+	}
+
+	for _, c := range changes {
+		action := ComplianceAction{
+			ExecutedAt:    c.CreatedAt.UTC(),
+			IncidentUUID:  c.IncidentUUID,
+			IncidentTitle: titles[c.IncidentUUID],
+			Tool:          c.Tool,
+			Target:        c.Location,
+			Action:        string(c.Action),
+			Path:          c.Path,
+			Detail:        c.Detail,
+		}
+		if a := approvalFor(approvals, &c); a != nil {
+			action.ApprovalUUID = a.UUID
+			action.Approver = a.DecidedBy
+			action.ApprovedAt = a.DecidedAt
+			report.Approved++
+		} else {
+			report.Unapproved++
+		}
+		report.Actions = append(report.Actions, action)
+	}
+	report.Total = len(report.Actions)
+	return report, nil
+}
+
+// approvalFor returns the latest approval (approvals are newest first) that
+// covers change: same incident, tool and host, decided before the write ran,
+// and for the same command or file. nil means the host did not require
+// approval.
+func approvalFor(approvals []database.ToolApproval, change *database.IncidentChange) *database.ToolApproval {
+	for i := range approvals {
+		a := &approvals[i]
+		if a.IncidentUUID != change.IncidentUUID || a.ToolName != change.Tool || a.DecidedAt == nil || a.DecidedAt.After(change.CreatedAt) {
+			continue
+		}
+		onHost := false
+		for _, host := range strings.Split(a.Target, ",") {
+			if strings.TrimSpace(host) == change.Location {
+				onHost = true
+				break
+			}
+		}
+		if !onHost {
+			continue
+		}
+		// A command approval's detail is the command itself; a file write's
+		// is "write|append N bytes to <path>".
+		switch {
+		case change.Action == database.IncidentChangeCommand && a.Detail != change.Detail:
+			continue
+		case change.Path != "" && !strings.HasSuffix(a.Detail, " to "+change.Path):
+			continue
+		}
+		return a
+	}
+	return nil
+}
+
+// complianceCSVHeader is the column order of WriteComplianceCSV.
+var complianceCSVHeader = []string{
+	"executed_at", "incident_uuid", "incident_title", "tool", "target", "action",
+	"path", "detail", "approval_uuid", "approver", "approved_at",
+}
+
+// WriteComplianceCSV writes actions as CSV with a header row. Times are
+// RFC3339 in UTC; an unapproved action has empty approval columns.
+func WriteComplianceCSV(w io.Writer, actions []ComplianceAction) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(complianceCSVHeader); err != nil {
+		return err
+	}
+	for _, a := range actions {
+		approvedAt := ""
+		if a.ApprovedAt != nil {
+			approvedAt = a.ApprovedAt.UTC().Format(time.RFC3339)
+		}
+		if err := cw.Write([]string{
+			a.ExecutedAt.UTC().Format(time.RFC3339), a.IncidentUUID, csvSafe(a.IncidentTitle), a.Tool, a.Target, a.Action,
+			csvSafe(a.Path), csvSafe(a.Detail), a.ApprovalUUID, csvSafe(a.Approver), approvedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe defuses values a spreadsheet would evaluate as a formula. Titles,
+// commands and approver names all come from outside, so a leading =, +, -
+// or @ is prefixed with a quote.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ComplianceCSVFilename names a report download after its window.
+func ComplianceCSVFilename(report *ComplianceReport) string {
+	return "agent-actions-" + report.Since.Format("20060102") + "-" + report.Until.Format("20060102") + ".csv"
+}
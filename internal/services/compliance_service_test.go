@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestComplianceService_Actions_JoinsApprovers(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.IncidentChange{}, &database.ToolApproval{})
+	svc := NewComplianceService(db)
+
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	decided := at.Add(-time.Minute)
+	db.Create(&database.Incident{UUID: "inc-1", Title: "=disk full on web-1", Status: database.IncidentStatusCompleted})
+	db.Create(&[]database.ToolApproval{
+		{UUID: "ap-1", IncidentUUID: "inc-1", ToolName: "ssh.execute_command", Target: "web-1, web-2", Detail: "systemctl restart nginx",
+			Status: database.ToolApprovalApproved, DecidedBy: "alice", DecidedAt: &decided, ExpiresAt: at},
+		{UUID: "ap-2", IncidentUUID: "inc-1", ToolName: "ssh.write_file", Target: "web-1", Detail: "write 10 bytes to /etc/other.conf",
+			Status: database.ToolApprovalApproved, DecidedBy: "bob", DecidedAt: &decided, ExpiresAt: at},
+	})
+	db.Create(&[]database.IncidentChange{
+		{IncidentUUID: "inc-1", Location: "web-2", Action: database.IncidentChangeCommand, Tool: "ssh.execute_command", Detail: "systemctl restart nginx", CreatedAt: at},
+		{IncidentUUID: "inc-1", Location: "web-1", Path: "/etc/app.conf", Action: database.IncidentChangeModified, Tool: "ssh.write_file", CreatedAt: at.Add(time.Second)},
+		{IncidentUUID: "inc-1", Location: database.IncidentChangeLocationWorkspace, Path: "notes.md", Action: database.IncidentChangeCreated, CreatedAt: at},
+		{IncidentUUID: "inc-1", Location: "web-1", Action: database.IncidentChangeCommand, Tool: "ssh.execute_command", Detail: "rm /tmp/x", CreatedAt: at.AddDate(0, 0, -40)},
+	})
+
+	report, err := svc.Actions(context.Background(), ComplianceFilter{Since: at.AddDate(0, 0, -1), Until: at.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Actions: %v", err)
+	}
+	if report.Total != 2 || report.Approved != 1 || report.Unapproved != 1 {
+		t.Fatalf("report = %+v, want 2 actions, 1 approved", report)
+	}
+	restart, write := report.Actions[0], report.Actions[1]
+	if restart.Target != "web-2" || restart.Approver != "alice" || restart.ApprovalUUID != "ap-1" || restart.IncidentTitle != "=disk full on web-1" {
+		t.Errorf("restart = %+v, want web-2 approved by alice", restart)
+	}
+	if write.Path != "/etc/app.conf" || write.Approver != "" {
+		t.Errorf("write = %+v, want no approver (the approval was for another file)", write)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteComplianceCSV(&buf, report.Actions); err != nil {
+		t.Fatalf("WriteComplianceCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "executed_at" || records[1][2] != "'=disk full on web-1" || records[1][9] != "alice" {
+		t.Errorf("CSV = %q", records)
+	}
+
+	_, err = svc.Actions(context.Background(), ComplianceFilter{Since: at, Until: at.AddDate(2, 0, 0)})
+	if !errors.Is(err, ErrInvalidComplianceFilter) {
+		t.Errorf("two-year window: err = %v, want ErrInvalidComplianceFilter", err)
+	}
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/output"
+	"gorm.io/gorm"
+)
+
+// ConfidenceAction is what an investigation's confidence score triggered.
+type ConfidenceAction string
+
+const (
+	ConfidenceActionNone            ConfidenceAction = ""
+	ConfidenceActionReviewRequested ConfidenceAction = "review_requested"
+	ConfidenceActionAutoClosed      ConfidenceAction = "auto_closed"
+)
+
+// confidenceActor is recorded as the actor of confidence timeline events.
+const confidenceActor = "system"
+
+// ConfidenceInstructions is appended to alert investigation prompts. The
+// block it asks for is parsed by output.ExtractConfidence.
+func ConfidenceInstructions() string {
+	return "End your final response with a confidence block rating how sure you are that your " +
+		"diagnosis and any fix are correct:\n\n[CONFIDENCE]\nscore: <0.0-1.0>\n" +
+		"reason: <one sentence on what the score rests on>\n[/CONFIDENCE]\n\n" +
+		"Score 0.9 or higher only when the root cause is confirmed by data and any fix was verified; " +
+		"0.5-0.8 when the cause is likely but not confirmed; below 0.5 when you are guessing or " +
+		"could not reach the systems involved."
+}
+
+// ConfidenceDecision is the outcome of routing an investigation result by
+// its confidence.
+type ConfidenceDecision struct {
+	Action ConfidenceAction
+	// ReviewMessage asks for a human review in the incident's Slack thread;
+	// set with ConfidenceActionReviewRequested.
+	ReviewMessage string
+}
+
+// IncidentCloser closes incidents. Satisfied by *SkillService.
+type IncidentCloser interface {
+	CloseIncident(ctx context.Context, incidentUUID string, confirm bool) error
+}
+
+// ConfidenceService stores the confidence of alert investigation results
+// and acts on it: results below the review threshold ask a human to review
+// them, and confident results for low-severity alerts that have resolved
+// close the incident. Thresholds come from GeneralSettings.
+type ConfidenceService struct {
+	db       *gorm.DB
+	closer   IncidentCloser
+	timeline IncidentTimelineRecorder // optional
+}
+
+// NewConfidenceService creates a confidence service that closes incidents
+// through closer.
+func NewConfidenceService(db *gorm.DB, closer IncidentCloser) *ConfidenceService {
+	return &ConfidenceService{db: db, closer: closer}
+}
+
+// SetIncidentTimeline wires the timeline review requests and auto-closes
+// are recorded on. Optional.
+func (s *ConfidenceService) SetIncidentTimeline(t IncidentTimelineRecorder) {
+	s.timeline = t
+}
+
+// RouteByConfidence stores confidence (nil clears a previous run's score) on
+// the incident and decides what follows from it. Auto-close only applies to
+// info and warning alerts whose incident reached monitor, i.e. every linked
+// alert has resolved; a result that still needs a decision (diagnosed) or
+// has firing alerts is left open.
+func (s *ConfidenceService) RouteByConfidence(ctx context.Context, incidentUUID string, confidence *output.Confidence, severity database.AlertSeverity) (ConfidenceDecision, error) {
+	var decision ConfidenceDecision
+	updates := map[string]interface{}{
+		"confidence":        nil,
+		"confidence_reason": "",
+		"confidence_action": "",
+	}
+	if confidence == nil {
+		return decision, s.db.WithContext(ctx).Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error
+	}
+	updates["confidence"] = confidence.Score
+	updates["confidence_reason"] = confidence.Reason
+
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil || settings == nil {
+		slog.Warn("confidence routing: could not load settings, using defaults", "err", err)
+		settings = &database.GeneralSettings{}
+	}
+	var incident database.Incident
+	if err := s.db.WithContext(ctx).Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return decision, err
+	}
+
+	review := settings.GetConfidenceReviewThreshold()
+	autoClose := settings.GetConfidenceAutoCloseThreshold()
+	switch {
+	case review > 0 && confidence.Score < review:
+		decision.Action = ConfidenceActionReviewRequested
+		decision.ReviewMessage = reviewRequestMessage(confidence, settings.ConfidenceReviewers)
+	case autoClose > 0 && confidence.Score >= autoClose && isLowSeverity(severity) &&
+		incident.Status == database.IncidentStatusMonitor:
+		decision.Action = ConfidenceActionAutoClosed
+	}
+	updates["confidence_action"] = string(decision.Action)
+	if err := s.db.WithContext(ctx).Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error; err != nil {
+		return ConfidenceDecision{}, err
+	}
+
+	switch decision.Action {
+	case ConfidenceActionReviewRequested:
+		s.record(incidentUUID, fmt.Sprintf("Review requested: confidence %s is below %s", formatConfidence(confidence.Score), formatConfidence(review)), confidence)
+	case ConfidenceActionAutoClosed:
+		err := s.closer.CloseIncident(ctx, incidentUUID, false)
+		if err != nil {
+			// Something changed since the run finished (an alert fired
+			// again, or an operator closed it); leave it as it is.
+			var confirm *ErrConfirmationRequired
+			if errors.Is(err, ErrIncidentAlreadyClosed) || errors.As(err, &confirm) {
+				err = nil
+			}
+			s.db.WithContext(ctx).Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Update("confidence_action", "")
+			return ConfidenceDecision{}, err
+		}
+		s.record(incidentUUID, fmt.Sprintf("Auto-closed: confidence %s on a %s alert", formatConfidence(confidence.Score), severity), confidence)
+	}
+	return decision, nil
+}
+
+func (s *ConfidenceService) record(incidentUUID, summary string, confidence *output.Confidence) {
+	if s.timeline == nil {
+		return
+	}
+	s.timeline.RecordEvent(database.IncidentEvent{
+		IncidentUUID: incidentUUID,
+		Type:         database.IncidentEventNote,
+		Summary:      summary,
+		Details: database.JSONB{
+			"confidence": confidence.Score,
+			"reason":     confidence.Reason,
+		},
+		Actor:      confidenceActor,
+		OccurredAt: time.Now(),
+	})
+}
+
+// isLowSeverity reports whether results for alerts of severity may close
+// their incident without a human.
+func isLowSeverity(severity database.AlertSeverity) bool {
+	return severity == database.AlertSeverityInfo || severity == database.AlertSeverityWarning
+}
+
+func reviewRequestMessage(confidence *output.Confidence, reviewers string) string {
+	var sb strings.Builder
+	sb.WriteString(":mag: *Low confidence (")
+	sb.WriteString(formatConfidence(confidence.Score))
+	sb.WriteString(")* in this result")
+	if reviewers = strings.TrimSpace(reviewers); reviewers != "" {
+		sb.WriteString(" — ")
+		sb.WriteString(reviewers)
+		sb.WriteString(" please review it")
+	} else {
+		sb.WriteString("; please review it before acting on it")
+	}
+	sb.WriteString(".")
+	if confidence.Reason != "" {
+		sb.WriteString("\n>")
+		sb.WriteString(confidence.Reason)
+	}
+	return sb.String()
+}
+
+func formatConfidence(score float64) string {
+	return fmt.Sprintf("%.0f%%", score*100)
+}
+
+// confidenceBuckets is how many equal-width buckets calibration stats split
+// 0-1 into.
+const confidenceBuckets = 5
+
+// ConfidenceBucket aggregates the scored investigations whose confidence
+// falls in [Min, Max) (the last bucket includes 1).
+type ConfidenceBucket struct {
+	Min           float64 `json:"min"`
+	Max           float64 `json:"max"`
+	Incidents     int     `json:"incidents"`
+	AvgConfidence float64 `json:"avg_confidence"`
+	// Retried counts incidents an operator re-ran, a sign the result was
+	// wrong; RetryRate is Retried / Incidents.
+	Retried         int     `json:"retried"`
+	RetryRate       float64 `json:"retry_rate"`
+	ReviewRequested int     `json:"review_requested"`
+	AutoClosed      int     `json:"auto_closed"`
+}
+
+// ConfidenceCalibration compares investigation confidence with outcomes: a
+// well-calibrated agent's retry rate falls as its confidence rises.
+type ConfidenceCalibration struct {
+	Since    *time.Time         `json:"since,omitempty"`
+	Until    *time.Time         `json:"until,omitempty"`
+	Scored   int                `json:"scored"`
+	Unscored int                `json:"unscored"`
+	Buckets  []ConfidenceBucket `json:"buckets"`
+}
+
+// Calibration aggregates finished investigations created in [since, until)
+// (either bound may be nil) by confidence.
+func (s *ConfidenceService) Calibration(ctx context.Context, since, until *time.Time) (*ConfidenceCalibration, error) {
+	query := s.db.WithContext(ctx).Model(&database.Incident{}).Where("completed_at IS NOT NULL")
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	if until != nil {
+		query = query.Where("created_at < ?", *until)
+	}
+
+	var unscored int64
+	if err := query.Session(&gorm.Session{}).Where("confidence IS NULL").Count(&unscored).Error; err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Confidence       float64
+		Attempts         int
+		ConfidenceAction string
+	}
+	if err := query.Session(&gorm.Session{}).Where("confidence IS NOT NULL").
+		Select("confidence, attempts, confidence_action").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := &ConfidenceCalibration{Since: since, Until: until, Scored: len(rows), Unscored: int(unscored), Buckets: make([]ConfidenceBucket, confidenceBuckets)}
+	for i := range result.Buckets {
+		result.Buckets[i].Min = float64(i) / confidenceBuckets
+		result.Buckets[i].Max = float64(i+1) / confidenceBuckets
+	}
+	for _, row := range rows {
+		i := min(int(row.Confidence*confidenceBuckets), confidenceBuckets-1)
+		b := &result.Buckets[i]
+		b.Incidents++
+		b.AvgConfidence += row.Confidence
+		if row.Attempts > 1 {
+			b.Retried++
+		}
+		switch ConfidenceAction(row.ConfidenceAction) {
+		case ConfidenceActionReviewRequested:
+			b.ReviewRequested++
+		case ConfidenceActionAutoClosed:
+			b.AutoClosed++
+		}
+	}
+	for i := range result.Buckets {
+		if b := &result.Buckets[i]; b.Incidents > 0 {
+			b.AvgConfidence /= float64(b.Incidents)
+			b.RetryRate = float64(b.Retried) / float64(b.Incidents)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/output"
+	"gorm.io/gorm"
+)
+
+type fakeIncidentCloser struct {
+	closed []string
+	err    error
+}
+
+func (f *fakeIncidentCloser) CloseIncident(_ context.Context, incidentUUID string, confirm bool) error {
+	if confirm {
+		panic("auto-close must not confirm")
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.closed = append(f.closed, incidentUUID)
+	return nil
+}
+
+type recordedEvents struct{ events []database.IncidentEvent }
+
+func (r *recordedEvents) RecordEvent(event database.IncidentEvent) {
+	r.events = append(r.events, event)
+}
+
+func setupConfidenceTest(t *testing.T, settings database.GeneralSettings) (*gorm.DB, *ConfidenceService, *fakeIncidentCloser, *recordedEvents) {
+	t.Helper()
+	db := setupSkillTestDB(t)
+	if err := db.AutoMigrate(&database.GeneralSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&settings).Error; err != nil {
+		t.Fatal(err)
+	}
+	closer := &fakeIncidentCloser{}
+	timeline := &recordedEvents{}
+	svc := NewConfidenceService(db, closer)
+	svc.SetIncidentTimeline(timeline)
+	return db, svc, closer, timeline
+}
+
+func createConfidenceIncident(t *testing.T, db *gorm.DB, uuid string, status database.IncidentStatus) {
+	t.Helper()
+	if err := db.Create(&database.Incident{UUID: uuid, Source: "test", Status: status}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouteByConfidence(t *testing.T) {
+	autoClose := 0.9
+	db, svc, closer, timeline := setupConfidenceTest(t, database.GeneralSettings{
+		ConfidenceReviewers:          "<@U123>",
+		ConfidenceAutoCloseThreshold: &autoClose,
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		status     database.IncidentStatus
+		confidence *output.Confidence
+		severity   database.AlertSeverity
+		want       ConfidenceAction
+	}{
+		{"low confidence asks for review", database.IncidentStatusMonitor, &output.Confidence{Score: 0.3, Reason: "could not reach the host"}, database.AlertSeverityInfo, ConfidenceActionReviewRequested},
+		{"medium confidence does nothing", database.IncidentStatusMonitor, &output.Confidence{Score: 0.7}, database.AlertSeverityInfo, ConfidenceActionNone},
+		{"confident warning auto-closes", database.IncidentStatusMonitor, &output.Confidence{Score: 0.95}, database.AlertSeverityWarning, ConfidenceActionAutoClosed},
+		{"confident critical stays open", database.IncidentStatusMonitor, &output.Confidence{Score: 0.95}, database.AlertSeverityCritical, ConfidenceActionNone},
+		{"confident with firing alerts stays open", database.IncidentStatusCompleted, &output.Confidence{Score: 0.95}, database.AlertSeverityInfo, ConfidenceActionNone},
+		{"diagnosed stays open", database.IncidentStatusDiagnosed, &output.Confidence{Score: 1}, database.AlertSeverityInfo, ConfidenceActionNone},
+		{"no score", database.IncidentStatusMonitor, nil, database.AlertSeverityInfo, ConfidenceActionNone},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid := "inc-" + string(rune('a'+i))
+			createConfidenceIncident(t, db, uuid, tt.status)
+			decision, err := svc.RouteByConfidence(ctx, uuid, tt.confidence, tt.severity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decision.Action != tt.want {
+				t.Errorf("action = %q, want %q", decision.Action, tt.want)
+			}
+			var incident database.Incident
+			db.Where("uuid = ?", uuid).First(&incident)
+			if tt.confidence == nil {
+				if incident.Confidence != nil {
+					t.Errorf("confidence = %v, want nil", *incident.Confidence)
+				}
+				return
+			}
+			if incident.Confidence == nil || *incident.Confidence != tt.confidence.Score {
+				t.Errorf("stored confidence = %v, want %v", incident.Confidence, tt.confidence.Score)
+			}
+			if incident.ConfidenceAction != string(tt.want) {
+				t.Errorf("stored action = %q, want %q", incident.ConfidenceAction, tt.want)
+			}
+		})
+	}
+
+	if len(closer.closed) != 1 || closer.closed[0] != "inc-c" {
+		t.Errorf("closed = %v, want only the confident warning", closer.closed)
+	}
+	if len(timeline.events) != 2 {
+		t.Fatalf("timeline events = %d, want review request and auto-close", len(timeline.events))
+	}
+
+	var incident database.Incident
+	db.Where("uuid = ?", "inc-a").First(&incident)
+	if incident.ConfidenceReason != "could not reach the host" {
+		t.Errorf("reason = %q", incident.ConfidenceReason)
+	}
+}
+
+func TestRouteByConfidence_ReviewMessage(t *testing.T) {
+	db, svc, _, _ := setupConfidenceTest(t, database.GeneralSettings{ConfidenceReviewers: "<!subteam^S1>"})
+	createConfidenceIncident(t, db, "inc-1", database.IncidentStatusCompleted)
+
+	decision, err := svc.RouteByConfidence(context.Background(), "inc-1", &output.Confidence{Score: 0.2, Reason: "guessing"}, database.AlertSeverityCritical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"20%", "<!subteam^S1>", "guessing"} {
+		if !strings.Contains(decision.ReviewMessage, want) {
+			t.Errorf("review message %q does not contain %q", decision.ReviewMessage, want)
+		}
+	}
+}
+
+func TestRouteByConfidence_ReviewDisabled(t *testing.T) {
+	off := 0.0
+	db, svc, _, _ := setupConfidenceTest(t, database.GeneralSettings{ConfidenceReviewThreshold: &off})
+	createConfidenceIncident(t, db, "inc-1", database.IncidentStatusCompleted)
+
+	decision, err := svc.RouteByConfidence(context.Background(), "inc-1", &output.Confidence{Score: 0.1}, database.AlertSeverityCritical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != ConfidenceActionNone {
+		t.Errorf("action = %q, want none with the review threshold at 0", decision.Action)
+	}
+}
+
+func TestRouteByConfidence_CloseRefused(t *testing.T) {
+	autoClose := 0.8
+	db, svc, closer, timeline := setupConfidenceTest(t, database.GeneralSettings{ConfidenceAutoCloseThreshold: &autoClose})
+	closer.err = &ErrConfirmationRequired{FiringAlertCount: 1}
+	createConfidenceIncident(t, db, "inc-1", database.IncidentStatusMonitor)
+
+	decision, err := svc.RouteByConfidence(context.Background(), "inc-1", &output.Confidence{Score: 0.9}, database.AlertSeverityInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != ConfidenceActionNone {
+		t.Errorf("action = %q, want none when the close is refused", decision.Action)
+	}
+	var incident database.Incident
+	db.Where("uuid = ?", "inc-1").First(&incident)
+	if incident.ConfidenceAction != "" {
+		t.Errorf("stored action = %q, want cleared", incident.ConfidenceAction)
+	}
+	if len(timeline.events) != 0 {
+		t.Errorf("timeline events = %d, want none", len(timeline.events))
+	}
+}
+
+func TestConfidenceCalibration(t *testing.T) {
+	db, svc, _, _ := setupConfidenceTest(t, database.GeneralSettings{})
+	now := time.Now()
+	score := func(f float64) *float64 { return &f }
+	for _, inc := range []database.Incident{
+		{UUID: "a", Confidence: score(0.1), Attempts: 2, ConfidenceAction: "review_requested", CompletedAt: &now},
+		{UUID: "b", Confidence: score(0.15), Attempts: 1, ConfidenceAction: "review_requested", CompletedAt: &now},
+		{UUID: "c", Confidence: score(0.95), Attempts: 1, ConfidenceAction: "auto_closed", CompletedAt: &now},
+		{UUID: "d", Confidence: score(1), Attempts: 1, CompletedAt: &now},
+		{UUID: "e", Attempts: 1, CompletedAt: &now},
+		{UUID: "f", Confidence: score(0.5)}, // still running
+	} {
+		inc.Source = "test"
+		if err := db.Create(&inc).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := svc.Calibration(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Scored != 4 || stats.Unscored != 1 {
+		t.Errorf("scored/unscored = %d/%d, want 4/1", stats.Scored, stats.Unscored)
+	}
+	if len(stats.Buckets) != 5 {
+		t.Fatalf("buckets = %d, want 5", len(stats.Buckets))
+	}
+	low, high := stats.Buckets[0], stats.Buckets[4]
+	if low.Incidents != 2 || low.Retried != 1 || low.RetryRate != 0.5 || low.ReviewRequested != 2 {
+		t.Errorf("low bucket = %+v", low)
+	}
+	if high.Incidents != 2 || high.AutoClosed != 1 || high.RetryRate != 0 || high.AvgConfidence != 0.975 {
+		t.Errorf("high bucket = %+v", high)
+	}
+
+	future := now.Add(time.Hour)
+	stats, err = svc.Calibration(context.Background(), &future, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Scored != 0 || stats.Unscored != 0 {
+		t.Errorf("future range scored/unscored = %d/%d, want 0/0", stats.Scored, stats.Unscored)
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+)
+
+// DefaultConversationSystemPrompt is the system turn of exported
+// conversations when the caller does not supply one. The agent's real
+// instructions (the per-incident AGENTS.md) are not stored with the
+// incident, so the export uses a short stand-in.
+const DefaultConversationSystemPrompt = "You are an SRE agent investigating production incidents. " +
+	"Diagnose the problem from the alert or request, explain the root cause and recommend remediation."
+
+// ErrNoConversation is returned for an incident with no finished exchange to
+// export: it is still running, it failed, or its log has no final response.
+var ErrNoConversation = errors.New("incident has no exportable conversation")
+
+// Markers written into Incident.FullLog by the investigation paths.
+const (
+	executionLogMarker  = "--- Execution Log ---"
+	finalResponseMarker = "--- Final Response ---"
+)
+
+// followUpMarker separates follow-up exchanges appended by the incident
+// message API.
+var followUpMarker = regexp.MustCompile(`\n*--- Follow-up from [^\n]* ---\n`)
+
+// ConversationMessage is one chat turn in the OpenAI fine-tuning format.
+type ConversationMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// IncidentConversation is one line of an OpenAI chat fine-tuning JSONL file.
+type IncidentConversation struct {
+	Messages []ConversationMessage `json:"messages"`
+}
+
+// BuildIncidentConversation turns a finished incident into a
+// system/user/assistant conversation: the task that started the
+// investigation and its final response, then each follow-up question and
+// answer. Tool calls and reasoning from the execution log are left out, as
+// is the metrics footer. Every turn goes through utils.RedactSecrets.
+//
+// The conversation stops before the first exchange without a usable answer,
+// so it always ends with an assistant turn. Returns ErrNoConversation when
+// not even the first exchange has one.
+func BuildIncidentConversation(incident *database.Incident, systemPrompt string) (*IncidentConversation, error) {
+	switch incident.Status {
+	case database.IncidentStatusPending, database.IncidentStatusRunning, database.IncidentStatusFailed:
+		return nil, ErrNoConversation
+	}
+	if systemPrompt == "" {
+		systemPrompt = DefaultConversationSystemPrompt
+	}
+
+	messages := []ConversationMessage{{Role: "system", Content: utils.RedactSecrets(systemPrompt)}}
+	for _, exchange := range followUpMarker.Split(utils.SanitizeLog(incident.FullLog), -1) {
+		prompt, answer, ok := splitConversationExchange(exchange)
+		if !ok {
+			break
+		}
+		messages = append(messages,
+			ConversationMessage{Role: "user", Content: utils.RedactSecrets(prompt)},
+			ConversationMessage{Role: "assistant", Content: utils.RedactSecrets(answer)},
+		)
+	}
+	if len(messages) == 1 {
+		return nil, ErrNoConversation
+	}
+	return &IncidentConversation{Messages: messages}, nil
+}
+
+// splitConversationExchange extracts the prompt (the header before the
+// execution log) and the final response from one run's slice of full_log.
+func splitConversationExchange(exchange string) (prompt, answer string, ok bool) {
+	logStart := strings.Index(exchange, executionLogMarker)
+	responseStart := strings.LastIndex(exchange, finalResponseMarker)
+	if logStart < 0 || responseStart < logStart {
+		return "", "", false
+	}
+	prompt = strings.TrimSpace(exchange[:logStart])
+	answer = strings.TrimSpace(exchange[responseStart+len(finalResponseMarker):])
+	if idx := strings.LastIndex(answer, "\n---\n⏱️"); idx >= 0 {
+		answer = strings.TrimSpace(answer[:idx])
+	}
+	if prompt == "" || answer == "" || strings.HasPrefix(answer, "❌") {
+		return "", "", false
+	}
+	return prompt, answer, true
+}
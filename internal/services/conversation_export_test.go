@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func TestBuildIncidentConversation(t *testing.T) {
+	fullLog := "📝 API Incident Task:\nCheck disk on web-1\n\n--- Execution Log ---\n\n✅ Ran: ssh df -h\n" +
+		"\n\n--- Final Response ---\n\nDisk is 97% full; token=abc123 found in /etc/app.env\n\n---\n⏱️ Time: 1m 2s | 🎯 Tokens: 1,200" +
+		"\n\n--- Follow-up from alice ---\nWhat filled it?\n\n--- Execution Log ---\n\nchecking\n" +
+		"\n\n--- Final Response ---\n\nOld nginx logs.\n\n---\n⏱️ Time: 10s" +
+		"\n\n--- Follow-up from bob ---\nClean it up\n\n--- Execution Log ---\n\n" +
+		"\n\n--- Final Response ---\n\n❌ Error: worker disconnected"
+
+	incident := &database.Incident{Status: database.IncidentStatusCompleted, FullLog: fullLog}
+	conv, err := BuildIncidentConversation(incident, "")
+	if err != nil {
+		t.Fatalf("BuildIncidentConversation: %v", err)
+	}
+
+	want := []ConversationMessage{
+		{Role: "system", Content: DefaultConversationSystemPrompt},
+		{Role: "user", Content: "📝 API Incident Task:\nCheck disk on web-1"},
+		{Role: "assistant", Content: "Disk is 97% full; token=[REDACTED] found in /etc/app.env"},
+		{Role: "user", Content: "What filled it?"},
+		{Role: "assistant", Content: "Old nginx logs."},
+	}
+	if len(conv.Messages) != len(want) {
+		t.Fatalf("got %d messages: %+v", len(conv.Messages), conv.Messages)
+	}
+	for i := range want {
+		if conv.Messages[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, conv.Messages[i], want[i])
+		}
+	}
+
+	custom, err := BuildIncidentConversation(incident, "custom system")
+	if err != nil || custom.Messages[0].Content != "custom system" {
+		t.Errorf("custom system prompt: %v %+v", err, custom)
+	}
+}
+
+func TestBuildIncidentConversation_NotExportable(t *testing.T) {
+	cases := map[string]database.Incident{
+		"running":     {Status: database.IncidentStatusRunning, FullLog: "task\n--- Execution Log ---\n--- Final Response ---\nok"},
+		"failed":      {Status: database.IncidentStatusFailed, FullLog: "task\n--- Execution Log ---\n--- Final Response ---\nok"},
+		"no response": {Status: database.IncidentStatusCompleted, FullLog: "task\n--- Execution Log ---\nworking"},
+		"no header":   {Status: database.IncidentStatusCompleted, FullLog: "legacy log without markers"},
+	}
+	for name, incident := range cases {
+		if _, err := BuildIncidentConversation(&incident, ""); !errors.Is(err, ErrNoConversation) {
+			t.Errorf("%s: err = %v, want ErrNoConversation", name, err)
+		}
+	}
+}
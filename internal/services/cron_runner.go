@@ -52,6 +52,10 @@ const cronChannelPostTimeout = 30 * time.Second
 // default rather than crashing the runner.
 const cronProviderResolveDefault = database.MessagingProviderSlack
 
+// cronResyncInterval is how often a started runner reconciles its scheduler
+// with the cron_jobs table (see Resync).
+const cronResyncInterval = 30 * time.Second
+
 // CronJobUpdate is the patch shape applied to UpdateJob. Pointer fields make
 // partial updates explicit so the handler can submit just the operator-edited
 // columns rather than re-sending the entire row.
@@ -68,6 +72,9 @@ type CronJobUpdate struct {
 	Enabled         *bool
 	PostResults     *bool
 	ToolInstanceIDs *[]uint
+	// SkillNames replaces the pinned skills when non-nil; an empty slice
+	// unpins them all.
+	SkillNames *[]string
 }
 
 // cronScheduler is the slice of robfig/cron/v3.*Cron the runner depends on so
@@ -99,10 +106,13 @@ type CronRunner struct {
 	scheduler cronScheduler
 	parser    cron.Parser
 	formatter *ResponseFormatter
+	budget    BudgetGuard
 
 	mu       sync.Mutex
 	entries  map[uint]cron.EntryID // cronJob.ID -> scheduler entry
+	specs    map[uint]string       // cronJob.ID -> schedule the entry was registered with
 	started  bool
+	run      uint64 // bumped by every Start so a stale shutdown goroutine cannot stop a newer run
 	stopFn   context.CancelFunc
 	inflight sync.WaitGroup // tracks ticks dispatched by RunNow so tests + Stop can join
 }
@@ -120,6 +130,12 @@ func (r *CronRunner) SetResponseFormatter(f *ResponseFormatter) {
 	r.formatter = f
 }
 
+// SetBudgetGuard wires the LLM budget check run before each cron agent run.
+// Optional: a nil guard never declines a run.
+func (r *CronRunner) SetBudgetGuard(g BudgetGuard) {
+	r.budget = g
+}
+
 // newCronRunnerWithDeps is the test seam: injects the DB, the scheduler, and
 // the dependencies so unit tests can drive the runner without touching the
 // global DB / wall-clock cron.
@@ -133,6 +149,7 @@ func newCronRunnerWithDeps(db *gorm.DB, channels ChannelManager, registry Provid
 		scheduler: scheduler,
 		parser:    cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
 		entries:   map[uint]cron.EntryID{},
+		specs:     map[uint]string{},
 	}
 }
 
@@ -178,15 +195,39 @@ func (r *CronRunner) Start(parent context.Context) error {
 	ctx, cancel := context.WithCancel(parent)
 	r.mu.Lock()
 	r.stopFn = cancel
+	r.run++
+	run := r.run
 	r.mu.Unlock()
 
 	go func() {
-		<-ctx.Done()
-		r.Stop()
+		ticker := time.NewTicker(cronResyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				r.stopRun(run)
+				return
+			case <-ticker.C:
+				if err := r.Resync(); err != nil {
+					slog.Warn("cron resync failed", "err", err)
+				}
+			}
+		}
 	}()
 	return nil
 }
 
+// stopRun stops the scheduler only if run is still the current one; the
+// runner may have been stopped and started again since.
+func (r *CronRunner) stopRun(run uint64) {
+	r.mu.Lock()
+	current := r.run == run
+	r.mu.Unlock()
+	if current {
+		r.Stop()
+	}
+}
+
 // Stop halts the scheduler. Safe to call multiple times.
 func (r *CronRunner) Stop() {
 	r.mu.Lock()
@@ -217,6 +258,7 @@ func (r *CronRunner) registerLocked(job database.CronJob) error {
 	if prior, ok := r.entries[job.ID]; ok {
 		r.scheduler.Remove(prior)
 		delete(r.entries, job.ID)
+		delete(r.specs, job.ID)
 	}
 	if !job.Enabled {
 		return nil
@@ -233,6 +275,7 @@ func (r *CronRunner) registerLocked(job database.CronJob) error {
 		return fmt.Errorf("schedule cron job: %w", err)
 	}
 	r.entries[job.ID] = entry
+	r.specs[job.ID] = job.Schedule
 
 	// Update NextRunAt so the API can render the next firing time without
 	// having to re-parse the schedule on every read. Use the scheduler's
@@ -257,6 +300,7 @@ func (r *CronRunner) Reload(jobID uint) error {
 		if prior, ok := r.entries[jobID]; ok {
 			r.scheduler.Remove(prior)
 			delete(r.entries, jobID)
+			delete(r.specs, jobID)
 		}
 		return nil
 	}
@@ -266,6 +310,39 @@ func (r *CronRunner) Reload(jobID uint) error {
 	return r.registerLocked(job)
 }
 
+// Resync reconciles the scheduler with the cron_jobs table. Reload only runs
+// on the replica that served the CRUD call, so the replica running the
+// scheduler resyncs periodically to pick up jobs created, rescheduled,
+// toggled or deleted elsewhere. Unchanged jobs keep their entries.
+func (r *CronRunner) Resync() error {
+	var jobs []database.CronJob
+	if err := r.db.Find(&jobs).Error; err != nil {
+		return fmt.Errorf("load cron jobs: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	present := make(map[uint]bool, len(jobs))
+	for _, job := range jobs {
+		present[job.ID] = true
+		_, registered := r.entries[job.ID]
+		if registered == job.Enabled && (!job.Enabled || r.specs[job.ID] == job.Schedule) {
+			continue
+		}
+		if err := r.registerLocked(job); err != nil {
+			slog.Warn("failed to resync cron job", "uuid", job.UUID, "err", err)
+		}
+	}
+	for id, entry := range r.entries {
+		if !present[id] {
+			r.scheduler.Remove(entry)
+			delete(r.entries, id)
+			delete(r.specs, id)
+		}
+	}
+	return nil
+}
+
 // fire executes a single tick for the supplied job ID. Reloads the row each
 // time so an in-flight edit (channel change, prompt change, disable) takes
 // effect on the very next firing. Preloads the per-cron tool allowlist
@@ -273,7 +350,7 @@ func (r *CronRunner) Reload(jobID uint) error {
 // the hot path.
 func (r *CronRunner) fire(jobID uint) {
 	var job database.CronJob
-	if err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").First(&job, jobID).Error; err != nil {
+	if err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Preload("Skills.Tools.ToolType").First(&job, jobID).Error; err != nil {
 		slog.Warn("cron tick: job vanished", "id", jobID, "err", err)
 		return
 	}
@@ -291,7 +368,7 @@ func (r *CronRunner) fire(jobID uint) {
 // outcome.
 func (r *CronRunner) RunNow(uuidStr string) error {
 	var job database.CronJob
-	err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Where("uuid = ?", uuidStr).First(&job).Error
+	err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Preload("Skills.Tools.ToolType").Where("uuid = ?", uuidStr).First(&job).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrCronJobNotFound
@@ -362,7 +439,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 			return
 		}
 	}
-	if !r.runner.IsWorkerConnected() {
+	if !r.runner.WaitForWorker(context.Background()) {
 		r.recordResult(job, database.CronJobRunStatusError, "agent worker not connected")
 		return
 	}
@@ -390,20 +467,33 @@ func (r *CronRunner) execute(job *database.CronJob) {
 		r.recordResult(job, database.CronJobRunStatusError, fmt.Sprintf("spawn incident: %v", err))
 		return
 	}
+	if r.budget != nil {
+		if err := r.budget.CheckBudget(context.Background()); err != nil {
+			if uerr := r.skills.UpdateIncidentComplete(incidentUUID, database.IncidentStatusBudgetExceeded, "", "", BudgetDeclineNote(err), 0, 0); uerr != nil {
+				slog.Warn("cron agent: failed to mark incident budget_exceeded", "incident_uuid", incidentUUID, "err", uerr)
+			}
+			r.recordResult(job, database.CronJobRunStatusError, err.Error())
+			return
+		}
+	}
 	if err := r.skills.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", ""); err != nil {
-		slog.Warn("cron agent: failed to update incident status", "incident", incidentUUID, "err", err)
+		slog.Warn("cron agent: failed to update incident status", "incident_uuid", incidentUUID, "err", err)
 	}
 
+	// Only the cron-agent root skill and the job's pinned skills are enabled
+	// for the run. The global enabled-skills set (incident-manager + operator
+	// skills) is intentionally NOT forwarded — a scheduled cron run should not
+	// pick up the alert-driven incident-manager prompt nor inherit unrelated
+	// operator skills.
+	skillNames := []string{cronAgentSkillName}
+	toolAllowlist := buildCronToolAllowlist(job.Tools)
+	skillNames, toolAllowlist = withCronPinnedSkills(job.Skills, skillNames, toolAllowlist)
+
+	// A pinned skill (or the cron-agent skill) may pin the LLM config.
 	var llmSettings *LLMSettingsForWorker
-	if dbSettings, err := database.GetLLMSettings(); err == nil && dbSettings != nil {
+	if dbSettings, err := database.GetLLMSettingsForSkills(skillNames); err == nil && dbSettings != nil {
 		llmSettings = BuildLLMSettingsForWorker(dbSettings)
 	}
-	// Only the cron-agent root skill is enabled for the run. The global
-	// enabled-skills set (incident-manager + operator skills) is intentionally
-	// NOT forwarded — a scheduled cron run should not pick up the alert-driven
-	// incident-manager prompt nor inherit unrelated operator skills.
-	skillNames := []string{cronAgentSkillName}
-	toolAllowlist := buildCronToolAllowlist(job.Tools)
 
 	taskHeader := fmt.Sprintf("Cron Investigation: %s\nSchedule: %s\n\n--- Execution Log ---\n\n", job.Name, job.Schedule)
 
@@ -425,7 +515,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 		OnOutput: func(output string) {
 			lastStreamedLog += output
 			if err := r.skills.UpdateIncidentLog(incidentUUID, taskHeader+lastStreamedLog); err != nil {
-				slog.Warn("cron agent: failed to update incident log", "incident", incidentUUID, "err", err)
+				slog.Warn("cron agent: failed to update incident log", "incident_uuid", incidentUUID, "err", err)
 			}
 		},
 		OnCompleted: func(sid, output string, tokensUsed int, executionTimeMs int64) {
@@ -466,7 +556,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 	if err != nil {
 		errStr := fmt.Sprintf("start incident: %v", err)
 		if updateErr := r.skills.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", "", errStr, 0, 0); updateErr != nil {
-			slog.Warn("cron agent: failed to update incident on start error", "incident", incidentUUID, "err", updateErr)
+			slog.Warn("cron agent: failed to update incident on start error", "incident_uuid", incidentUUID, "err", updateErr)
 		}
 		r.recordResult(job, database.CronJobRunStatusError, errStr)
 		return
@@ -477,7 +567,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 	// A superseded run hands ownership to the replacement; exit silently so
 	// the replacement run owns the DB finalize + channel post.
 	if supersededFlag.Load() {
-		slog.Info("cron agent: investigation superseded; leaving finalization to the new run", "incident", incidentUUID)
+		slog.Info("cron agent: investigation superseded; leaving finalization to the new run", "incident_uuid", incidentUUID)
 		return
 	}
 
@@ -485,7 +575,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 	// between OnCompleted and here invalidates this run — return without
 	// touching the DB or posting to the channel.
 	if !r.runner.ReleaseRun(incidentUUID, runID) {
-		slog.Info("cron agent: investigation displaced during finalization", "incident", incidentUUID)
+		slog.Info("cron agent: investigation displaced during finalization", "incident_uuid", incidentUUID)
 		return
 	}
 
@@ -513,7 +603,7 @@ func (r *CronRunner) execute(job *database.CronJob) {
 		finalStatus = database.IncidentStatusFailed
 	}
 	if err := r.skills.UpdateIncidentComplete(incidentUUID, finalStatus, sessionID, fullLog, formattedResponse, finalTokensUsed, finalExecutionTimeMs); err != nil {
-		slog.Warn("cron agent: failed to update incident complete", "incident", incidentUUID, "err", err)
+		slog.Warn("cron agent: failed to update incident complete", "incident_uuid", incidentUUID, "err", err)
 	}
 
 	// Post the final summary to the cron's channel as a fresh message. On
@@ -674,6 +764,35 @@ func buildCronToolAllowlist(tools []database.ToolInstance) []ToolAllowlistEntry
 	return entries
 }
 
+// withCronPinnedSkills adds a cron's pinned skills to the run: each enabled
+// operator skill joins skillNames and its enabled tools join the allowlist,
+// deduplicated against the tools the cron already declares. Disabled and
+// system skills are skipped, as for alert-source pins.
+func withCronPinnedSkills(skills []database.Skill, skillNames []string, allowlist []ToolAllowlistEntry) ([]string, []ToolAllowlistEntry) {
+	seen := make(map[uint]bool, len(allowlist))
+	for _, entry := range allowlist {
+		seen[entry.InstanceID] = true
+	}
+	for _, sk := range skills {
+		if !sk.Enabled || sk.IsSystem {
+			continue
+		}
+		skillNames = append(skillNames, sk.Name)
+		for _, tool := range sk.Tools {
+			if !tool.Enabled || seen[tool.ID] {
+				continue
+			}
+			seen[tool.ID] = true
+			allowlist = append(allowlist, ToolAllowlistEntry{
+				InstanceID:  tool.ID,
+				LogicalName: tool.LogicalName,
+				ToolType:    tool.ToolType.Name,
+			})
+		}
+	}
+	return skillNames, allowlist
+}
+
 // cronChannelMaxMessageBytes caps the byte size of an outbound cron message.
 // Slack's chat.postMessage hard limit is ~40,000 bytes; we keep the cap at
 // 8000 to match the alert/Slack flow's slackMaxTextBytes so cron messages
@@ -740,7 +859,7 @@ func (r *CronRunner) recordResult(job *database.CronJob, status string, errMsg s
 // allowlist for the UI tool picker.
 func (r *CronRunner) ListJobs() ([]database.CronJob, error) {
 	var jobs []database.CronJob
-	if err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Order("name asc").Find(&jobs).Error; err != nil {
+	if err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Preload("Skills.Tools.ToolType").Order("name asc").Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("list cron jobs: %w", err)
 	}
 	return jobs, nil
@@ -752,7 +871,7 @@ func (r *CronRunner) ListJobs() ([]database.CronJob, error) {
 // without an extra round trip.
 func (r *CronRunner) GetJobByUUID(uuidStr string) (*database.CronJob, error) {
 	var job database.CronJob
-	err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Where("uuid = ?", uuidStr).First(&job).Error
+	err := r.db.Preload("Channel.Integration").Preload("Tools.ToolType").Preload("Skills.Tools.ToolType").Where("uuid = ?", uuidStr).First(&job).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrCronJobNotFound
@@ -883,6 +1002,31 @@ func (r *CronRunner) resolveToolInstances(ids []uint) ([]database.ToolInstance,
 	return tools, nil
 }
 
+// resolveSkills loads the operator skills a cron is pinned to by name. An
+// unknown or system skill is rejected so a typo does not silently leave the
+// cron with fewer skills than the operator asked for.
+func (r *CronRunner) resolveSkills(names []string) ([]database.Skill, error) {
+	skills := make([]database.Skill, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		var skill database.Skill
+		err := r.db.Where("name = ?", name).First(&skill).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && skill.IsSystem) {
+			return nil, fmt.Errorf("skill %q not found", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load cron job skills: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
+
 // UpdateJob applies the supplied patch. Re-registers the scheduler entry so a
 // schedule change, channel change, or enable/disable takes effect on the next
 // firing without an API restart. System rows can be patched (operators must
@@ -948,7 +1092,17 @@ func (r *CronRunner) UpdateJob(uuidStr string, patch CronJobUpdate) (*database.C
 		}
 		newTools = resolved
 	}
-	if len(updates) == 0 && !replaceTools {
+	var newSkills []database.Skill
+	replaceSkills := false
+	if patch.SkillNames != nil {
+		replaceSkills = true
+		resolved, rerr := r.resolveSkills(*patch.SkillNames)
+		if rerr != nil {
+			return nil, rerr
+		}
+		newSkills = resolved
+	}
+	if len(updates) == 0 && !replaceTools && !replaceSkills {
 		return job, nil
 	}
 	if err := r.db.Transaction(func(tx *gorm.DB) error {
@@ -962,6 +1116,11 @@ func (r *CronRunner) UpdateJob(uuidStr string, patch CronJobUpdate) (*database.C
 				return fmt.Errorf("update cron job tools: %w", err)
 			}
 		}
+		if replaceSkills {
+			if err := tx.Model(&database.CronJob{ID: job.ID}).Association("Skills").Replace(newSkills); err != nil {
+				return fmt.Errorf("update cron job skills: %w", err)
+			}
+		}
 		return nil
 	}); err != nil {
 		return nil, err
@@ -990,6 +1149,9 @@ func (r *CronRunner) DeleteJob(uuidStr string) error {
 		if err := tx.Model(&database.CronJob{ID: job.ID}).Association("Tools").Clear(); err != nil {
 			return fmt.Errorf("clear tools: %w", err)
 		}
+		if err := tx.Where("cron_job_id = ?", job.ID).Delete(&database.CronJobSkill{}).Error; err != nil {
+			return fmt.Errorf("clear skills: %w", err)
+		}
 		if err := tx.Delete(&database.CronJob{}, job.ID).Error; err != nil {
 			return fmt.Errorf("delete: %w", err)
 		}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
@@ -251,7 +253,10 @@ func (f *fakeSkillIncidentManager) UpdateIncidentComplete(uuid string, status da
 	f.updates = append(f.updates, fakeIncidentUpdate{uuid: uuid, status: status, response: response, fullLog: fullLog})
 	return f.updateCompleteErr
 }
-func (f *fakeSkillIncidentManager) UpdateIncidentLog(string, string) error         { return nil }
+func (f *fakeSkillIncidentManager) UpdateIncidentLog(string, string) error { return nil }
+func (f *fakeSkillIncidentManager) ReadIncidentLog(string, int, int) (string, int, error) {
+	return "", 0, nil
+}
 func (f *fakeSkillIncidentManager) GetIncident(string) (*database.Incident, error) { return nil, nil }
 func (f *fakeSkillIncidentManager) AppendSubagentLog(string, string, string) error { return nil }
 func (f *fakeSkillIncidentManager) InsertFiringAlert(context.Context, string, string, alerts.NormalizedAlert, string, string) error {
@@ -266,8 +271,15 @@ func (f *fakeSkillIncidentManager) UnlinkAlertFromIncident(context.Context, stri
 func (f *fakeSkillIncidentManager) MoveAlertToIncident(context.Context, string, string) (string, error) {
 	return "", nil
 }
-func (f *fakeSkillIncidentManager) ResolveAlert(context.Context, string) error        { return nil }
-func (f *fakeSkillIncidentManager) CloseIncident(context.Context, string, bool) error { return nil }
+func (f *fakeSkillIncidentManager) ResolveAlert(context.Context, string) error           { return nil }
+func (f *fakeSkillIncidentManager) CloseIncident(context.Context, string, bool) error    { return nil }
+func (f *fakeSkillIncidentManager) CancelIncident(context.Context, string, string) error { return nil }
+func (f *fakeSkillIncidentManager) BeginFollowUp(string, string) (*database.Incident, error) {
+	panic("not implemented")
+}
+func (f *fakeSkillIncidentManager) BeginRetry(string, string) (*database.Incident, error) {
+	panic("not implemented")
+}
 
 func (f *fakeSkillIncidentManager) CreateSkill(string, string, string, string) (*database.Skill, error) {
 	panic("not implemented")
@@ -350,6 +362,10 @@ func (f *fakeIncidentRunner) IsWorkerConnected() bool {
 	return f.connected
 }
 
+func (f *fakeIncidentRunner) WaitForWorker(context.Context) bool {
+	return f.IsWorkerConnected()
+}
+
 func (f *fakeIncidentRunner) StartIncident(incidentID, task string, llm *LLMSettingsForWorker, enabledSkills []string, toolAllowlist []ToolAllowlistEntry, callback IncidentCallback) (string, error) {
 	f.mu.Lock()
 	if f.startErr != nil {
@@ -393,10 +409,13 @@ func setupCronRunnerTest(t *testing.T) (*CronRunner, *gorm.DB, *fakeScheduler, *
 		&database.Channel{},
 		&database.CronJob{},
 		&database.CronJobTool{},
+		&database.CronJobSkill{},
+		&database.Skill{},
+		&database.SkillTool{},
 		&database.ToolType{},
 		&database.ToolInstance{},
 		&database.LLMSettings{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.FormattingRule{},
 	); err != nil {
 		t.Fatalf("automigrate: %v", err)
@@ -894,6 +913,79 @@ func TestCronRunner_AgentTick_UsesCronAgentSkillAndPerCronTools(t *testing.T) {
 	}
 }
 
+// TestCronRunner_AgentTick_RunsPinnedSkills covers a cron designated to run
+// an operator skill: the skill joins cron-agent and its tools join the cron's
+// own allowlist without duplicates.
+func TestCronRunner_AgentTick_RunsPinnedSkills(t *testing.T) {
+	runner, db, _, chMgr, _ := setupCronRunnerTest(t)
+	agentRunner := runner.runner.(*fakeIncidentRunner)
+
+	toolType := database.ToolType{Name: "ssh"}
+	if err := db.Create(&toolType).Error; err != nil {
+		t.Fatalf("seed tool type: %v", err)
+	}
+	shared := database.ToolInstance{ToolTypeID: toolType.ID, Name: "prod-ssh", LogicalName: "prod-ssh", Enabled: true}
+	certs := database.ToolInstance{ToolTypeID: toolType.ID, Name: "edge-ssh", LogicalName: "edge-ssh", Enabled: true}
+	for _, tool := range []*database.ToolInstance{&shared, &certs} {
+		if err := db.Create(tool).Error; err != nil {
+			t.Fatalf("seed tool: %v", err)
+		}
+	}
+	audit := database.Skill{Name: "cert-audit", Enabled: true, Tools: []database.ToolInstance{shared, certs}}
+	manager := database.Skill{Name: "incident-manager", Enabled: true, IsSystem: true}
+	for _, sk := range []*database.Skill{&audit, &manager} {
+		if err := db.Create(sk).Error; err != nil {
+			t.Fatalf("seed skill: %v", err)
+		}
+	}
+
+	job, err := runner.CreateJob("cert expiry", "0 9 * * 1", "Audit certificates", chMgr.channels[0].UUID, true, true, []uint{shared.ID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := runner.UpdateJob(job.UUID, CronJobUpdate{SkillNames: &[]string{"incident-manager"}}); err == nil {
+		t.Fatal("pinning a system skill must fail")
+	}
+	updated, err := runner.UpdateJob(job.UUID, CronJobUpdate{SkillNames: &[]string{"cert-audit"}})
+	if err != nil {
+		t.Fatalf("pin skill: %v", err)
+	}
+	if len(updated.Skills) != 1 || updated.Skills[0].Name != "cert-audit" {
+		t.Fatalf("pinned skills = %+v, want [cert-audit]", updated.Skills)
+	}
+
+	if err := runner.RunNow(job.UUID); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	runner.WaitForInflight()
+
+	agentRunner.mu.Lock()
+	defer agentRunner.mu.Unlock()
+	if len(agentRunner.startCalls) != 1 {
+		t.Fatalf("expected one StartIncident call, got %d", len(agentRunner.startCalls))
+	}
+	call := agentRunner.startCalls[0]
+	if !reflect.DeepEqual(call.skills, []string{"cron-agent", "cert-audit"}) {
+		t.Errorf("StartIncident skills = %v, want [cron-agent cert-audit]", call.skills)
+	}
+	var ids []uint
+	for _, e := range call.tools {
+		ids = append(ids, e.InstanceID)
+	}
+	if !reflect.DeepEqual(ids, []uint{shared.ID, certs.ID}) {
+		t.Errorf("StartIncident tool ids = %v, want [%d %d]", ids, shared.ID, certs.ID)
+	}
+
+	if err := runner.DeleteJob(job.UUID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var pins int64
+	db.Model(&database.CronJobSkill{}).Count(&pins)
+	if pins != 0 {
+		t.Errorf("cron_job_skills rows after delete = %d, want 0", pins)
+	}
+}
+
 // TestCronRunner_CreateJob_RejectsUnknownToolID guards the resolveToolInstances
 // validation: an unknown tool ID surfaces as a typed error rather than
 // silently dropping the tool from the allowlist.
@@ -1884,3 +1976,79 @@ func TestCronRunner_AgentTick_NoRuleMatchKeepsRawOutput(t *testing.T) {
 		t.Errorf("expected 0 LLM calls, got %d", caller.callCount())
 	}
 }
+
+// TestCronRunner_Resync_PicksUpOutOfBandChanges covers jobs edited through
+// another API replica: the rows change without this runner's Reload being
+// called.
+func TestCronRunner_Resync_PicksUpOutOfBandChanges(t *testing.T) {
+	runner, db, sched, chMgr, _ := setupCronRunnerTest(t)
+	channel := chMgr.channels[0]
+	kept, err := runner.CreateJob("kept", "0 9 * * *", "p", channel.UUID, true, true, nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	gone, err := runner.CreateJob("gone", "0 10 * * *", "p", channel.UUID, true, true, nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	addsBefore := sched.addCalls
+
+	added := database.CronJob{UUID: uuid.New().String(), Name: "added", Schedule: "*/5 * * * *", Prompt: "p", ChannelID: &channel.ID, Enabled: true}
+	if err := db.Create(&added).Error; err != nil {
+		t.Fatalf("seed added: %v", err)
+	}
+	if err := db.Unscoped().Delete(&database.CronJob{}, gone.ID).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if err := runner.Resync(); err != nil {
+		t.Fatalf("Resync: %v", err)
+	}
+	if sched.entryCount() != 2 {
+		t.Errorf("entry count = %d, want 2", sched.entryCount())
+	}
+	if sched.addCalls != addsBefore+1 {
+		t.Errorf("AddFunc calls = %d, want only the new job registered", sched.addCalls-addsBefore)
+	}
+	if _, ok := runner.entries[kept.ID]; !ok {
+		t.Error("unchanged job lost its entry")
+	}
+
+	if err := db.Model(&database.CronJob{}).Where("id = ?", kept.ID).Update("schedule", "0 11 * * *").Error; err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	if err := db.Model(&database.CronJob{}).Where("id = ?", added.ID).Update("enabled", false).Error; err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if err := runner.Resync(); err != nil {
+		t.Fatalf("Resync: %v", err)
+	}
+	if sched.entryCount() != 1 || runner.specs[kept.ID] != "0 11 * * *" {
+		t.Errorf("entries = %d, spec = %q", sched.entryCount(), runner.specs[kept.ID])
+	}
+}
+
+// TestCronRunner_RestartAfterCancel covers a replica losing and regaining
+// leadership: the first run's shutdown must not stop the second run.
+func TestCronRunner_RestartAfterCancel(t *testing.T) {
+	runner, _, _, _, _ := setupCronRunnerTest(t)
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	if err := runner.Start(first); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	runner.Stop()
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	defer runner.Stop()
+	cancelFirst()
+	time.Sleep(20 * time.Millisecond)
+
+	runner.mu.Lock()
+	started := runner.started
+	runner.mu.Unlock()
+	if !started {
+		t.Error("cancelling the first run's context stopped the restarted runner")
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// EnsureDataDir creates the data directory if needed and checks that files
+// can be written to it, so a read-only or foreign-owned mount fails at
+// startup instead of on the first skill edit or investigation.
+func EnsureDataDir(dir string) error {
+	if dir == "" {
+		return errors.New("data directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	return nil
+}
+
+// MigrateDataDir moves the contents of a previous data directory into dir
+// and returns how many top-level entries were moved. Entries that already
+// exist in dir with content are left in place (and logged) rather than
+// merged, so running the migration twice, or after a partial run, is safe.
+// A missing from directory means there is nothing to migrate.
+func MigrateDataDir(from, dir string) (int, error) {
+	src, err := filepath.Abs(from)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+	if src == dst {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create data directory %s: %w", dst, err)
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+		if existing, err := os.ReadDir(to); err == nil && len(existing) == 0 {
+			// Empty directories (e.g. created by an init container) are
+			// placeholders, not data.
+			if err := os.Remove(to); err != nil {
+				return moved, fmt.Errorf("failed to replace %s: %w", to, err)
+			}
+		} else if _, err := os.Lstat(to); err == nil {
+			slog.Warn("data dir migration: destination already exists, leaving source in place", "source", from, "destination", to)
+			continue
+		}
+		if err := moveTree(from, to); err != nil {
+			return moved, fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// moveTree renames src to dst, falling back to copy-and-delete when they
+// are on different filesystems.
+func moveTree(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies files, directories and symlinks (as links) from src to dst,
+// keeping permission bits.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			slog.Warn("data dir migration: skipping special file", "path", path)
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
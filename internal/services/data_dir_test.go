@@ -0,0 +1,110 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := EnsureDataDir(dir); err != nil {
+		t.Fatalf("EnsureDataDir: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("write check left files behind: %v", entries)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureDataDir(filepath.Join(file, "data")); err == nil {
+		t.Error("expected an error for a data dir under a regular file")
+	}
+	if err := EnsureDataDir(""); err == nil {
+		t.Error("expected an error for an empty data dir")
+	}
+}
+
+func TestMigrateDataDir_MovesEntriesAndKeepsExistingData(t *testing.T) {
+	root := t.TempDir()
+	from := filepath.Join(root, "old")
+	to := filepath.Join(root, "new")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(from, "context", "runbook.md"), "old context")
+	writeFile(filepath.Join(from, "skills", "disk", "SKILL.md"), "old skill")
+	writeFile(filepath.Join(from, "memory", "MEMORY.md"), "old memory")
+	if err := os.MkdirAll(filepath.Join(from, "skills", "disk", "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../../context/runbook.md", filepath.Join(from, "skills", "disk", "assets", "runbook.md")); err != nil {
+		t.Fatal(err)
+	}
+	// An init container already created an empty skills dir; memory has data.
+	if err := os.MkdirAll(filepath.Join(to, "skills"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(filepath.Join(to, "memory", "MEMORY.md"), "new memory")
+
+	moved, err := MigrateDataDir(from, to)
+	if err != nil {
+		t.Fatalf("MigrateDataDir: %v", err)
+	}
+	if moved != 2 {
+		t.Errorf("moved = %d, want 2 (context, skills)", moved)
+	}
+	if b, err := os.ReadFile(filepath.Join(to, "skills", "disk", "assets", "runbook.md")); err != nil || string(b) != "old context" {
+		t.Errorf("asset through relative symlink = %q, %v", b, err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(to, "memory", "MEMORY.md")); string(b) != "new memory" {
+		t.Errorf("memory = %q, want the destination's copy kept", b)
+	}
+	if _, err := os.Stat(filepath.Join(from, "memory", "MEMORY.md")); err != nil {
+		t.Errorf("skipped source entry should stay in place: %v", err)
+	}
+
+	// Nothing left to move: a second run is a no-op.
+	if moved, err := MigrateDataDir(from, to); err != nil || moved != 0 {
+		t.Errorf("second run = %d, %v; want 0, nil", moved, err)
+	}
+	if moved, err := MigrateDataDir(filepath.Join(root, "missing"), to); err != nil || moved != 0 {
+		t.Errorf("missing source = %d, %v; want 0, nil", moved, err)
+	}
+	if moved, err := MigrateDataDir(to, to); err != nil || moved != 0 {
+		t.Errorf("same dir = %d, %v; want 0, nil", moved, err)
+	}
+}
+
+func TestCopyTree_KeepsModesAndSymlinks(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := os.MkdirAll(filepath.Join(src, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "scripts", "check.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("scripts/check.sh", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "scripts", "check.sh"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("script = %v, %v; want mode 0755", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "link")); err != nil || link != "scripts/check.sh" {
+		t.Errorf("link = %q, %v", link, err)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// EnrichmentStepsSettingKey is the alert source setting listing which
+// enrichment steps run before an investigation, in order. Absent runs every
+// registered step; an empty list (or "none" from the form) turns enrichment
+// off for the source.
+const EnrichmentStepsSettingKey = "enrichment_steps"
+
+// enrichmentStepTimeout bounds each step. Steps run before the agent starts,
+// so a slow CMDB must not hold the investigation up for long.
+const enrichmentStepTimeout = 5 * time.Second
+
+// enrichmentContextKey is the Incident.Context key the pipeline stores its
+// sections under, so the UI and API show what the agent was given.
+const enrichmentContextKey = "enrichment"
+
+// EnrichmentRequest is the alert an enrichment step works from.
+type EnrichmentRequest struct {
+	IncidentUUID string
+	// SourceUUID is the alert source instance or listener channel UUID.
+	SourceUUID string
+	Alert      alerts.NormalizedAlert
+}
+
+// EnrichmentSection is the context one step adds. Body is rendered into the
+// investigation prompt under Title; Data is the same information in
+// structured form, stored on the incident.
+type EnrichmentSection struct {
+	Step  string      `json:"step"`
+	Title string      `json:"title"`
+	Body  string      `json:"body"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// EnrichmentStep adds context about an alert before it is investigated.
+// Enrich returns nil when it has nothing to add. Errors are logged and the
+// step skipped; they never block the investigation.
+type EnrichmentStep interface {
+	Name() string
+	Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error)
+}
+
+// EnrichmentPipeline runs the enrichment steps that sit between alert
+// normalization and the investigation prompt.
+type EnrichmentPipeline struct {
+	db          *gorm.DB
+	steps       []EnrichmentStep
+	stepTimeout time.Duration
+}
+
+// NewEnrichmentPipeline creates a pipeline. The order of steps is the order
+// they run in for sources that do not configure enrichment_steps.
+func NewEnrichmentPipeline(db *gorm.DB, steps ...EnrichmentStep) *EnrichmentPipeline {
+	return &EnrichmentPipeline{db: db, steps: steps, stepTimeout: enrichmentStepTimeout}
+}
+
+// DefaultEnrichmentSteps returns the built-in steps: CMDB lookup, recent
+// changes on the host, similar past incidents and matching runbooks.
+func DefaultEnrichmentSteps(db *gorm.DB) []EnrichmentStep {
+	return []EnrichmentStep{
+		NewCMDBEnrichmentStep(db),
+		NewRecentChangesEnrichmentStep(db),
+		NewSimilarIncidentsEnrichmentStep(db),
+		NewRunbookEnrichmentStep(db),
+	}
+}
+
+// Register appends a step. Call before the pipeline is in use.
+func (p *EnrichmentPipeline) Register(step EnrichmentStep) {
+	p.steps = append(p.steps, step)
+}
+
+// StepNames lists the registered steps in default order.
+func (p *EnrichmentPipeline) StepNames() []string {
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.Name()
+	}
+	return names
+}
+
+// Run executes the steps selected by the source settings and returns the
+// sections they produced, in step order. The sections are also stored on
+// the incident's context.
+func (p *EnrichmentPipeline) Run(ctx context.Context, req EnrichmentRequest, settings map[string]interface{}) []EnrichmentSection {
+	steps, err := p.selectSteps(settings)
+	if err != nil {
+		slog.Warn("enrichment: ignoring invalid source setting", "source_uuid", req.SourceUUID, "err", err)
+		steps = p.steps
+	}
+
+	var sections []EnrichmentSection
+	for _, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, p.stepTimeout)
+		section, err := step.Enrich(stepCtx, req)
+		cancel()
+		if err != nil {
+			slog.Warn("enrichment step failed", "step", step.Name(), "incident_uuid", req.IncidentUUID, "err", err)
+			continue
+		}
+		if section == nil || strings.TrimSpace(section.Body) == "" {
+			continue
+		}
+		section.Step = step.Name()
+		sections = append(sections, *section)
+	}
+
+	if req.IncidentUUID != "" && len(sections) > 0 {
+		if err := p.record(ctx, req.IncidentUUID, sections); err != nil {
+			slog.Warn("enrichment: failed to store sections on incident", "incident_uuid", req.IncidentUUID, "err", err)
+		}
+	}
+	return sections
+}
+
+// selectSteps resolves enrichment_steps against the registered steps.
+// Unknown names are skipped with a warning so a step removed from the build
+// does not break sources that still list it.
+func (p *EnrichmentPipeline) selectSteps(settings map[string]interface{}) ([]EnrichmentStep, error) {
+	names, configured, err := ParseEnrichmentSteps(settings)
+	if err != nil || !configured {
+		return p.steps, err
+	}
+	byName := make(map[string]EnrichmentStep, len(p.steps))
+	for _, step := range p.steps {
+		byName[step.Name()] = step
+	}
+	steps := make([]EnrichmentStep, 0, len(names))
+	for _, name := range names {
+		step, ok := byName[name]
+		if !ok {
+			slog.Warn("enrichment: unknown step in source settings", "step", name)
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (p *EnrichmentPipeline) record(ctx context.Context, incidentUUID string, sections []EnrichmentSection) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var incident database.Incident
+		if err := tx.Select("id", "context").Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+			return err
+		}
+		if incident.Context == nil {
+			incident.Context = database.JSONB{}
+		}
+		incident.Context[enrichmentContextKey] = sections
+		return tx.Model(&database.Incident{}).Where("id = ?", incident.ID).Update("context", incident.Context).Error
+	})
+}
+
+// RenderEnrichment formats sections for the investigation prompt.
+func RenderEnrichment(sections []EnrichmentSection) string {
+	var b strings.Builder
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n\n%s:\n%s", s.Title, strings.TrimSpace(s.Body))
+	}
+	return b.String()
+}
+
+// ParseEnrichmentSteps returns the step names configured in an alert
+// source's settings. configured is false when the key is absent or blank,
+// meaning every registered step runs. The form posts a comma-separated
+// string, so that is accepted too.
+func ParseEnrichmentSteps(settings map[string]interface{}) (names []string, configured bool, err error) {
+	raw, ok := settings[EnrichmentStepsSettingKey]
+	if !ok || raw == nil {
+		return nil, false, nil
+	}
+	var entries []string
+	switch v := raw.(type) {
+	case string:
+		switch strings.TrimSpace(v) {
+		case "":
+			return nil, false, nil
+		case "none":
+			return []string{}, true, nil
+		}
+		for _, entry := range strings.Split(v, ",") {
+			if strings.TrimSpace(entry) != "" {
+				entries = append(entries, entry)
+			}
+		}
+	case []interface{}:
+		for _, entry := range v {
+			s, ok := entry.(string)
+			if !ok {
+				return nil, false, fmt.Errorf("%s entries must be strings", EnrichmentStepsSettingKey)
+			}
+			entries = append(entries, s)
+		}
+	case []string:
+		entries = v
+	default:
+		return nil, false, errors.New(EnrichmentStepsSettingKey + " must be a list of step names")
+	}
+
+	names = make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry)
+		if name == "" {
+			return nil, false, fmt.Errorf("%s entries must not be empty", EnrichmentStepsSettingKey)
+		}
+		if seen[name] {
+			return nil, false, fmt.Errorf("step %q is listed twice in %s", name, EnrichmentStepsSettingKey)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, true, nil
+}
@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// Built-in enrichment step names, as listed in enrichment_steps.
+const (
+	EnrichmentStepCMDB             = "cmdb"
+	EnrichmentStepRecentChanges    = "recent_changes"
+	EnrichmentStepSimilarIncidents = "similar_incidents"
+	EnrichmentStepRunbooks         = "runbook_match"
+)
+
+// Limits for the built-in steps. They keep each section to a few prompt
+// lines; the agent can dig further with its own tools.
+const (
+	recentChangesWindow     = 24 * time.Hour
+	recentChangesLimit      = 10
+	similarIncidentsWindow  = 30 * 24 * time.Hour
+	similarIncidentsLimit   = 5
+	similarIncidentRunes    = 200
+	runbookMatchLimit       = 3
+	cmdbMaxResponseBytes    = 1 << 20
+	runbookMinTokenLength   = 3
+	runbookTitleTokenWeight = 3
+)
+
+// enrichmentHost strips a ":port" suffix from an alert's target host, as
+// Prometheus instance labels carry one.
+func enrichmentHost(target string) string {
+	host := strings.TrimSpace(target)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
+
+// --- CMDB ---
+
+// cmdbEnrichmentStep looks the alert's host up in NetBox, using the first
+// enabled NetBox tool instance, as a device or else a virtual machine.
+type cmdbEnrichmentStep struct {
+	db *gorm.DB
+}
+
+// NewCMDBEnrichmentStep creates the "cmdb" step. It adds nothing when no
+// NetBox tool is configured.
+func NewCMDBEnrichmentStep(db *gorm.DB) EnrichmentStep {
+	return &cmdbEnrichmentStep{db: db}
+}
+
+func (s *cmdbEnrichmentStep) Name() string { return EnrichmentStepCMDB }
+
+func (s *cmdbEnrichmentStep) Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error) {
+	host := enrichmentHost(req.Alert.TargetHost)
+	if host == "" {
+		return nil, nil
+	}
+	var tools []database.ToolInstance
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN tool_types ON tool_types.id = tool_instances.tool_type_id").
+		Where("tool_types.name = ? AND tool_instances.enabled = ?", "netbox", true).
+		Order("tool_instances.id ASC").Limit(1).Find(&tools).Error; err != nil {
+		return nil, fmt.Errorf("load netbox tool: %w", err)
+	}
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	tool := tools[0]
+	baseURL, _ := tool.Settings["netbox_url"].(string)
+	token, _ := tool.Settings["netbox_api_token"].(string)
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" || token == "" {
+		return nil, nil
+	}
+
+	client := s.httpClient(ctx, &tool)
+	kind := "Device"
+	record, err := s.lookup(ctx, client, baseURL, token, "/api/dcim/devices/", host)
+	if err == nil && record == nil {
+		kind = "Virtual machine"
+		record, err = s.lookup(ctx, client, baseURL, token, "/api/virtualization/virtual-machines/", host)
+	}
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	fields := []struct{ label, value string }{
+		{"Status", nestedString(record, "status", "label")},
+		{"Role", firstNonEmpty(nestedString(record, "role", "name"), nestedString(record, "device_role", "name"))},
+		{"Site", nestedString(record, "site", "name")},
+		{"Rack", nestedString(record, "rack", "name")},
+		{"Cluster", nestedString(record, "cluster", "name")},
+		{"Tenant", nestedString(record, "tenant", "name")},
+		{"Platform", nestedString(record, "platform", "name")},
+		{"Model", nestedString(record, "device_type", "model")},
+		{"Primary IP", nestedString(record, "primary_ip", "address")},
+		{"Description", nestedString(record, "description")},
+	}
+	var b strings.Builder
+	data := map[string]interface{}{"kind": strings.ToLower(kind), "name": nestedString(record, "name"), "tool": tool.Name}
+	fmt.Fprintf(&b, "%s: %s (NetBox %q)", kind, nestedString(record, "name"), tool.Name)
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s: %s", f.label, f.value)
+		data[strings.ToLower(strings.ReplaceAll(f.label, " ", "_"))] = f.value
+	}
+	return &EnrichmentSection{Title: "CMDB record", Body: b.String(), Data: data}, nil
+}
+
+// httpClient honours the tool's TLS setting and the NetBox proxy setting,
+// as the gateway does for the same tool.
+func (s *cmdbEnrichmentStep) httpClient(ctx context.Context, tool *database.ToolInstance) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if verify, ok := tool.Settings["netbox_verify_ssl"].(bool); ok && !verify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- operator opted out per tool
+	}
+	var proxies []database.ProxySettings
+	if err := s.db.WithContext(ctx).Limit(1).Find(&proxies).Error; err == nil && len(proxies) > 0 &&
+		proxies[0].NetBoxEnabled && proxies[0].ProxyURL != "" {
+		if proxyURL, err := url.Parse(proxies[0].ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	return &http.Client{Timeout: enrichmentStepTimeout, Transport: transport}
+}
+
+// lookup returns the first NetBox object named host at path, or nil.
+func (s *cmdbEnrichmentStep) lookup(ctx context.Context, client *http.Client, baseURL, token, path, host string) (map[string]interface{}, error) {
+	query := url.Values{"name": {host}, "limit": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("netbox request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("netbox %s returned HTTP %d", path, resp.StatusCode)
+	}
+	var page struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, cmdbMaxResponseBytes)).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decode netbox response: %w", err)
+	}
+	if len(page.Results) == 0 {
+		return nil, nil
+	}
+	return page.Results[0], nil
+}
+
+// nestedString walks NetBox's nested objects ({"site": {"name": ...}}).
+func nestedString(obj map[string]interface{}, keys ...string) string {
+	var cur interface{} = obj
+	for _, key := range keys {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = m[key]
+	}
+	s, _ := cur.(string)
+	return strings.TrimSpace(s)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// --- Recent changes ---
+
+// recentChangesEnrichmentStep lists writes earlier investigations made on
+// the alert's host, from their change manifests.
+type recentChangesEnrichmentStep struct {
+	db *gorm.DB
+}
+
+// NewRecentChangesEnrichmentStep creates the "recent_changes" step.
+func NewRecentChangesEnrichmentStep(db *gorm.DB) EnrichmentStep {
+	return &recentChangesEnrichmentStep{db: db}
+}
+
+func (s *recentChangesEnrichmentStep) Name() string { return EnrichmentStepRecentChanges }
+
+func (s *recentChangesEnrichmentStep) Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error) {
+	host := enrichmentHost(req.Alert.TargetHost)
+	if host == "" {
+		return nil, nil
+	}
+	var changes []database.IncidentChange
+	if err := s.db.WithContext(ctx).
+		Where("LOWER(location) = ? AND incident_uuid <> ? AND created_at >= ?",
+			strings.ToLower(host), req.IncidentUUID, time.Now().Add(-recentChangesWindow)).
+		Order("created_at DESC").Limit(recentChangesLimit).Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("load incident changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	for _, c := range changes {
+		target := c.Path
+		if c.Action == database.IncidentChangeCommand {
+			target = truncateForPrompt(c.Detail, similarIncidentRunes)
+		}
+		fmt.Fprintf(&b, "- %s %s %s (incident %s)\n", c.CreatedAt.UTC().Format(time.RFC3339), c.Action, target, c.IncidentUUID)
+	}
+	return &EnrichmentSection{
+		Title: fmt.Sprintf("Changes made on %s by earlier investigations (last 24h)", host),
+		Body:  b.String(),
+		Data:  changes,
+	}, nil
+}
+
+// --- Similar incidents ---
+
+// similarIncidentsEnrichmentStep finds recent finished incidents for the
+// same alert, listing those on the same host first.
+type similarIncidentsEnrichmentStep struct {
+	db *gorm.DB
+}
+
+// NewSimilarIncidentsEnrichmentStep creates the "similar_incidents" step.
+func NewSimilarIncidentsEnrichmentStep(db *gorm.DB) EnrichmentStep {
+	return &similarIncidentsEnrichmentStep{db: db}
+}
+
+func (s *similarIncidentsEnrichmentStep) Name() string { return EnrichmentStepSimilarIncidents }
+
+type similarIncident struct {
+	UUID      string    `json:"uuid"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Host      string    `json:"host"`
+	CreatedAt time.Time `json:"created_at"`
+	Outcome   string    `json:"outcome"`
+}
+
+func (s *similarIncidentsEnrichmentStep) Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Alert.AlertName))
+	if name == "" {
+		return nil, nil
+	}
+	host := strings.ToLower(enrichmentHost(req.Alert.TargetHost))
+
+	var incidents []database.Incident
+	// ->> extracts JSON text on both PostgreSQL (jsonb) and SQLite 3.38+.
+	if err := s.db.WithContext(ctx).
+		Select("uuid", "title", "status", "context", "response", "created_at").
+		Where("LOWER(context->>'alert_name') = ? AND uuid <> ? AND created_at >= ?",
+			name, req.IncidentUUID, time.Now().Add(-similarIncidentsWindow)).
+		Where("status NOT IN ?", []database.IncidentStatus{
+			database.IncidentStatusPending, database.IncidentStatusRunning, database.IncidentStatusMerged, database.IncidentStatusBudgetExceeded,
+			database.IncidentStatusCancelled,
+		}).
+		Order("created_at DESC").Limit(similarIncidentsLimit * 4).Find(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("load similar incidents: %w", err)
+	}
+	if len(incidents) == 0 {
+		return nil, nil
+	}
+
+	similar := make([]similarIncident, 0, len(incidents))
+	for _, inc := range incidents {
+		incHost, _ := inc.Context["target_host"].(string)
+		similar = append(similar, similarIncident{
+			UUID:      inc.UUID,
+			Title:     inc.Title,
+			Status:    string(inc.Status),
+			Host:      incHost,
+			CreatedAt: inc.CreatedAt,
+			Outcome:   incidentOutcomeLine(inc.Response),
+		})
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return host != "" && strings.EqualFold(enrichmentHost(similar[i].Host), host) &&
+			!strings.EqualFold(enrichmentHost(similar[j].Host), host)
+	})
+	if len(similar) > similarIncidentsLimit {
+		similar = similar[:similarIncidentsLimit]
+	}
+
+	var b strings.Builder
+	for _, inc := range similar {
+		fmt.Fprintf(&b, "- %s %s [%s]", inc.CreatedAt.UTC().Format(time.RFC3339), inc.UUID, inc.Status)
+		if inc.Host != "" {
+			fmt.Fprintf(&b, " on %s", inc.Host)
+		}
+		if inc.Title != "" {
+			fmt.Fprintf(&b, ": %s", inc.Title)
+		}
+		if inc.Outcome != "" {
+			fmt.Fprintf(&b, "\n  Outcome: %s", inc.Outcome)
+		}
+		b.WriteString("\n")
+	}
+	return &EnrichmentSection{Title: "Similar past incidents", Body: b.String(), Data: similar}, nil
+}
+
+// incidentOutcomeLine is the first line of a final response, without the
+// metrics footer, short enough for a list entry.
+func incidentOutcomeLine(response string) string {
+	if idx := strings.LastIndex(response, "\n---\n⏱️"); idx >= 0 {
+		response = response[:idx]
+	}
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#*-> "))
+		if line != "" {
+			return truncateForPrompt(line, similarIncidentRunes)
+		}
+	}
+	return ""
+}
+
+// --- Runbook match ---
+
+// runbookEnrichmentStep ranks runbooks by how many words of the alert name
+// and service they share, weighting title hits over body hits.
+type runbookEnrichmentStep struct {
+	db *gorm.DB
+}
+
+// NewRunbookEnrichmentStep creates the "runbook_match" step.
+func NewRunbookEnrichmentStep(db *gorm.DB) EnrichmentStep {
+	return &runbookEnrichmentStep{db: db}
+}
+
+func (s *runbookEnrichmentStep) Name() string { return EnrichmentStepRunbooks }
+
+type runbookMatch struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Path  string `json:"path"`
+	score int
+}
+
+func (s *runbookEnrichmentStep) Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error) {
+	tokens := runbookTokens(req.Alert.AlertName + " " + req.Alert.TargetService)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	var runbooks []database.Runbook
+	if err := s.db.WithContext(ctx).Find(&runbooks).Error; err != nil {
+		return nil, fmt.Errorf("load runbooks: %w", err)
+	}
+
+	var matches []runbookMatch
+	for _, rb := range runbooks {
+		title := strings.ToLower(rb.Title)
+		content := strings.ToLower(rb.Content)
+		score, titleHit := 0, false
+		for _, tok := range tokens {
+			if strings.Contains(title, tok) {
+				score += runbookTitleTokenWeight
+				titleHit = true
+			} else if strings.Contains(content, tok) {
+				score++
+			}
+		}
+		// A single body hit on a common word is noise.
+		if !titleHit && score < 2 {
+			continue
+		}
+		matches = append(matches, runbookMatch{
+			ID:    rb.ID,
+			Title: rb.Title,
+			Path:  fmt.Sprintf("/akmatori/runbooks/%d-%s.md", rb.ID, slugify(rb.Title)),
+			score: score,
+		})
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	if len(matches) > runbookMatchLimit {
+		matches = matches[:runbookMatchLimit]
+	}
+
+	var b strings.Builder
+	for _, m := range matches {
+		fmt.Fprintf(&b, "- %s: %s\n", m.Title, m.Path)
+	}
+	return &EnrichmentSection{Title: "Runbooks that may apply", Body: b.String(), Data: matches}, nil
+}
+
+// runbookTokens splits text into distinct lowercase words long enough to
+// be meaningful. CamelCase alert names ("DiskFull") split into words too.
+func runbookTokens(text string) []string {
+	var spaced strings.Builder
+	prevLower := false
+	for _, r := range text {
+		isUpper := r >= 'A' && r <= 'Z'
+		if isUpper && prevLower {
+			spaced.WriteByte(' ')
+		}
+		spaced.WriteRune(r)
+		prevLower = r >= 'a' && r <= 'z' || r >= '0' && r <= '9'
+	}
+	words := strings.FieldsFunc(strings.ToLower(spaced.String()), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	seen := make(map[string]bool, len(words))
+	var tokens []string
+	for _, w := range words {
+		if len(w) < runbookMinTokenLength || seen[w] {
+			continue
+		}
+		seen[w] = true
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
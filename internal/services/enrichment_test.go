@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+type fakeEnrichmentStep struct {
+	name    string
+	body    string
+	err     error
+	block   bool
+	ran     int
+	lastReq EnrichmentRequest
+}
+
+func (s *fakeEnrichmentStep) Name() string { return s.name }
+
+func (s *fakeEnrichmentStep) Enrich(ctx context.Context, req EnrichmentRequest) (*EnrichmentSection, error) {
+	s.ran++
+	s.lastReq = req
+	if s.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if s.err != nil || s.body == "" {
+		return nil, s.err
+	}
+	return &EnrichmentSection{Title: strings.ToUpper(s.name), Body: s.body, Data: map[string]string{"from": s.name}}, nil
+}
+
+func TestEnrichmentPipeline_Run(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{})
+	if err := db.Create(&database.Incident{UUID: "inc-1", Source: "test", Context: database.JSONB{"alert_name": "HighCPU"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	a := &fakeEnrichmentStep{name: "a", body: "from a"}
+	b := &fakeEnrichmentStep{name: "b", body: "from b"}
+	empty := &fakeEnrichmentStep{name: "empty"}
+	broken := &fakeEnrichmentStep{name: "broken", err: errors.New("cmdb down")}
+	slow := &fakeEnrichmentStep{name: "slow", block: true}
+	p := NewEnrichmentPipeline(db, a, empty, broken)
+	p.Register(slow)
+	p.Register(b)
+	p.stepTimeout = 10 * time.Millisecond
+
+	req := EnrichmentRequest{IncidentUUID: "inc-1", Alert: alerts.NormalizedAlert{AlertName: "HighCPU"}}
+
+	// Default: every step in registration order; failures and empty results
+	// are dropped, a slow step is cut off.
+	sections := p.Run(context.Background(), req, nil)
+	if len(sections) != 2 || sections[0].Step != "a" || sections[1].Step != "b" {
+		t.Fatalf("sections = %+v", sections)
+	}
+	if slow.ran != 1 || broken.ran != 1 {
+		t.Errorf("slow ran %d, broken ran %d", slow.ran, broken.ran)
+	}
+	if a.lastReq.Alert.AlertName != "HighCPU" {
+		t.Errorf("step got request %+v", a.lastReq)
+	}
+
+	var incident database.Incident
+	db.Where("uuid = ?", "inc-1").First(&incident)
+	stored, ok := incident.Context["enrichment"].([]interface{})
+	if !ok || len(stored) != 2 || incident.Context["alert_name"] != "HighCPU" {
+		t.Errorf("incident context = %+v", incident.Context)
+	}
+
+	// Configured order wins; unknown names are skipped.
+	sections = p.Run(context.Background(), req, map[string]interface{}{
+		EnrichmentStepsSettingKey: []interface{}{"b", "gone", "a"},
+	})
+	if len(sections) != 2 || sections[0].Step != "b" || sections[1].Step != "a" {
+		t.Errorf("configured sections = %+v", sections)
+	}
+
+	// An empty list turns enrichment off.
+	if sections := p.Run(context.Background(), req, map[string]interface{}{EnrichmentStepsSettingKey: []interface{}{}}); len(sections) != 0 {
+		t.Errorf("disabled sections = %+v", sections)
+	}
+
+	rendered := RenderEnrichment([]EnrichmentSection{{Title: "A", Body: "from a\n"}, {Title: "B", Body: "from b"}})
+	if rendered != "\n\nA:\nfrom a\n\nB:\nfrom b" {
+		t.Errorf("RenderEnrichment = %q", rendered)
+	}
+}
+
+func TestParseEnrichmentSteps(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          interface{}
+		want           []string
+		wantConfigured bool
+		wantErr        bool
+	}{
+		{name: "absent", value: nil},
+		{name: "list", value: []interface{}{" cmdb", "runbook_match"}, want: []string{"cmdb", "runbook_match"}, wantConfigured: true},
+		{name: "empty list", value: []interface{}{}, want: []string{}, wantConfigured: true},
+		{name: "comma-separated", value: "runbook_match, cmdb", want: []string{"runbook_match", "cmdb"}, wantConfigured: true},
+		{name: "blank string", value: " "},
+		{name: "none", value: "none", want: []string{}, wantConfigured: true},
+		{name: "not a list", value: 3, wantErr: true},
+		{name: "non-string entry", value: []interface{}{1}, wantErr: true},
+		{name: "blank entry", value: []interface{}{" "}, wantErr: true},
+		{name: "duplicate", value: []interface{}{"cmdb", "cmdb"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]interface{}{}
+			if tt.value != nil {
+				settings[EnrichmentStepsSettingKey] = tt.value
+			}
+			got, configured, err := ParseEnrichmentSteps(settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if configured != tt.wantConfigured || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v configured=%v", got, configured)
+			}
+		})
+	}
+	if err := ValidateAlertSourceSettings(map[string]interface{}{EnrichmentStepsSettingKey: "cmdb,,cmdb"}); err == nil {
+		t.Error("ValidateAlertSourceSettings accepted a malformed enrichment_steps")
+	}
+}
+
+func TestSimilarIncidentsEnrichmentStep(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{})
+	now := time.Now()
+	seed := func(uuid, name, host string, status database.IncidentStatus, age time.Duration, response string) {
+		t.Helper()
+		inc := database.Incident{UUID: uuid, Source: "test", Status: status, Title: "title " + uuid, Response: response,
+			Context: database.JSONB{"alert_name": name, "target_host": host}}
+		if err := db.Create(&inc).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&database.Incident{}).Where("uuid = ?", uuid).Update("created_at", now.Add(-age))
+	}
+	seed("current", "DiskFull", "web-1", database.IncidentStatusRunning, 0, "")
+	seed("other-host", "DiskFull", "web-2", database.IncidentStatusCompleted, time.Hour, "Logs rotated")
+	seed("same-host", "DiskFull", "web-1", database.IncidentStatusCompleted, 2*time.Hour, "## Root cause\nnginx logs filled /var\n\n---\n⏱️ Time: 1m")
+	seed("old", "DiskFull", "web-1", database.IncidentStatusCompleted, 40*24*time.Hour, "too old")
+	seed("unrelated", "HighCPU", "web-1", database.IncidentStatusCompleted, time.Hour, "cpu")
+	seed("in-flight", "DiskFull", "web-3", database.IncidentStatusRunning, time.Hour, "")
+
+	section, err := NewSimilarIncidentsEnrichmentStep(db).Enrich(context.Background(), EnrichmentRequest{
+		IncidentUUID: "current",
+		Alert:        alerts.NormalizedAlert{AlertName: "diskfull", TargetHost: "web-1:9100"},
+	})
+	if err != nil || section == nil {
+		t.Fatalf("Enrich = %+v, %v", section, err)
+	}
+	similar := section.Data.([]similarIncident)
+	if len(similar) != 2 || similar[0].UUID != "same-host" || similar[1].UUID != "other-host" {
+		t.Fatalf("similar = %+v", similar)
+	}
+	if similar[0].Outcome != "Root cause" || !strings.Contains(section.Body, "same-host [completed] on web-1: title same-host") {
+		t.Errorf("body = %q", section.Body)
+	}
+}
+
+func TestRecentChangesEnrichmentStep(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.IncidentChange{})
+	for _, c := range []database.IncidentChange{
+		{IncidentUUID: "earlier", Location: "WEB-1", Path: "/etc/nginx/nginx.conf", Action: database.IncidentChangeModified, Tool: "ssh"},
+		{IncidentUUID: "earlier", Location: "web-1", Action: database.IncidentChangeCommand, Detail: "systemctl restart nginx"},
+		{IncidentUUID: "current", Location: "web-1", Path: "/tmp/x", Action: database.IncidentChangeCreated},
+		{IncidentUUID: "earlier", Location: "web-2", Path: "/etc/hosts", Action: database.IncidentChangeModified},
+		{IncidentUUID: "earlier", Location: database.IncidentChangeLocationWorkspace, Path: "notes.md", Action: database.IncidentChangeCreated},
+	} {
+		if err := db.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	step := NewRecentChangesEnrichmentStep(db)
+	section, err := step.Enrich(context.Background(), EnrichmentRequest{IncidentUUID: "current", Alert: alerts.NormalizedAlert{TargetHost: "web-1"}})
+	if err != nil || section == nil {
+		t.Fatalf("Enrich = %+v, %v", section, err)
+	}
+	if changes := section.Data.([]database.IncidentChange); len(changes) != 2 {
+		t.Errorf("changes = %+v", changes)
+	}
+	if !strings.Contains(section.Body, "modified /etc/nginx/nginx.conf (incident earlier)") ||
+		!strings.Contains(section.Body, "command systemctl restart nginx") {
+		t.Errorf("body = %q", section.Body)
+	}
+
+	if section, _ := step.Enrich(context.Background(), EnrichmentRequest{Alert: alerts.NormalizedAlert{TargetHost: "db-1"}}); section != nil {
+		t.Errorf("unchanged host got %+v", section)
+	}
+}
+
+func TestRunbookEnrichmentStep(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Runbook{})
+	for _, rb := range []database.Runbook{
+		{Title: "Disk full on web servers", Content: "Rotate nginx logs."},
+		{Title: "Database failover", Content: "When postgres replication lags or the disk fills, promote the replica."},
+		{Title: "Certificate renewal", Content: "Renew with certbot."},
+	} {
+		if err := db.Create(&rb).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	section, err := NewRunbookEnrichmentStep(db).Enrich(context.Background(), EnrichmentRequest{
+		Alert: alerts.NormalizedAlert{AlertName: "DiskFull", TargetService: "postgres"},
+	})
+	if err != nil || section == nil {
+		t.Fatalf("Enrich = %+v, %v", section, err)
+	}
+	matches := section.Data.([]runbookMatch)
+	// "disk" and "full" hit the first title; "postgres" and "disk" only the
+	// failover runbook's body.
+	if len(matches) != 2 || matches[0].Title != "Disk full on web servers" || matches[1].Title != "Database failover" {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if !strings.Contains(section.Body, "/akmatori/runbooks/1-disk-full-on-web-servers.md") {
+		t.Errorf("body = %q", section.Body)
+	}
+}
+
+func TestCMDBEnrichmentStep(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+		if r.Header.Get("Authorization") != "Token nb-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/dcim/devices/" {
+			_, _ = w.Write([]byte(`{"count":0,"results":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"count":1,"results":[{"name":"web-1","status":{"value":"active","label":"Active"},
+			"cluster":{"name":"k8s-prod"},"role":{"name":"Web"},"primary_ip":{"address":"10.0.0.5/24"}}]}`))
+	}))
+	defer srv.Close()
+
+	db := testhelpers.NewSQLiteDB(t, &database.ToolType{}, &database.ToolInstance{}, &database.ProxySettings{})
+	step := NewCMDBEnrichmentStep(db)
+	req := EnrichmentRequest{Alert: alerts.NormalizedAlert{TargetHost: "web-1:9100"}}
+
+	// No NetBox tool: nothing to add, no error.
+	if section, err := step.Enrich(context.Background(), req); section != nil || err != nil {
+		t.Fatalf("without netbox: %+v, %v", section, err)
+	}
+
+	toolType := database.ToolType{Name: "netbox"}
+	db.Create(&toolType)
+	db.Create(&database.ToolInstance{ToolTypeID: toolType.ID, Name: "NetBox", LogicalName: "netbox", Enabled: true,
+		Settings: database.JSONB{"netbox_url": srv.URL + "/", "netbox_api_token": "nb-token"}})
+
+	section, err := step.Enrich(context.Background(), req)
+	if err != nil || section == nil {
+		t.Fatalf("Enrich = %+v, %v", section, err)
+	}
+	want := "Virtual machine: web-1 (NetBox \"NetBox\")\nStatus: Active\nRole: Web\nCluster: k8s-prod\nPrimary IP: 10.0.0.5/24"
+	if section.Body != want {
+		t.Errorf("body = %q, want %q", section.Body, want)
+	}
+	if len(paths) != 2 || paths[0] != "/api/dcim/devices/?limit=1&name=web-1" {
+		t.Errorf("requests = %v", paths)
+	}
+}
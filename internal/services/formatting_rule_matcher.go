@@ -83,7 +83,7 @@ func BuildFormatFlow(incidentUUID, channelUUID string) FormatFlow {
 	var incident database.Incident
 	if err := database.GetDB().Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
 		slog.Warn("format flow: failed to load incident, matching on channel only",
-			"incident", incidentUUID, "err", err)
+			"incident_uuid", incidentUUID, "err", err)
 		return flow
 	}
 	flow.SourceKind = incident.SourceKind
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+)
+
+// Alert payload keys whose values name a host or a user. Bare names like
+// "web-01" are only anonymized once registered, so they are collected from
+// the payloads before anything is rewritten.
+var (
+	anonymizeHostKeys = map[string]bool{
+		"host": true, "hostname": true, "host_name": true, "instance": true, "node": true,
+		"nodename": true, "server": true, "target_host": true, "fqdn": true, "device": true,
+	}
+	anonymizeUserKeys = map[string]bool{
+		"user": true, "username": true, "user_name": true, "login": true, "owner": true,
+		"assignee": true, "account": true,
+	}
+)
+
+// SeedAnonymizer registers the hosts and users named by alerts (target host
+// and host/user-like payload fields) so a finds them in free text too.
+func SeedAnonymizer(a *utils.Anonymizer, alerts []database.Alert) {
+	for i := range alerts {
+		a.AddHost(alerts[i].TargetHost)
+		seedAnonymizerFromPayload(a, map[string]interface{}(alerts[i].RawPayload))
+	}
+}
+
+func seedAnonymizerFromPayload(a *utils.Anonymizer, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if s, ok := val.(string); ok {
+				key := strings.ToLower(k)
+				switch {
+				case anonymizeHostKeys[key]:
+					a.AddHost(s)
+				case anonymizeUserKeys[key]:
+					a.AddUser(s)
+				}
+				continue
+			}
+			seedAnonymizerFromPayload(a, val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			seedAnonymizerFromPayload(a, val)
+		}
+	}
+}
+
+// anonymizeAlertIDFields are the Alert string fields left alone: generated
+// identifiers, which name nothing and which links and lookups depend on.
+var anonymizeAlertIDFields = map[string]bool{
+	"UUID": true, "IncidentUUID": true, "SourceUUID": true, "Fingerprint": true,
+}
+
+// AnonymizeAlerts returns copies of alerts with hosts, IPs and users in
+// every text field and the raw payload replaced by a's pseudonyms. Fields
+// are found by reflection so one added to Alert later is covered too.
+func AnonymizeAlerts(a *utils.Anonymizer, alerts []database.Alert) []database.Alert {
+	out := make([]database.Alert, len(alerts))
+	for i, alert := range alerts {
+		v := reflect.ValueOf(&alert).Elem()
+		for j := 0; j < v.NumField(); j++ {
+			if f := v.Field(j); f.Kind() == reflect.String && !anonymizeAlertIDFields[v.Type().Field(j).Name] {
+				f.SetString(a.Text(f.String()))
+			}
+		}
+		if alert.RawPayload != nil {
+			payload, _ := a.Value(map[string]interface{}(alert.RawPayload)).(map[string]interface{})
+			alert.RawPayload = database.JSONB(payload)
+		}
+		out[i] = alert
+	}
+	return out
+}
+
+// AnonymizeConversation rewrites every turn of conv with a's pseudonyms.
+func AnonymizeConversation(a *utils.Anonymizer, conv *IncidentConversation) {
+	for i := range conv.Messages {
+		conv.Messages[i].Content = a.Text(conv.Messages[i].Content)
+	}
+}
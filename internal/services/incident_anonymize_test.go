@@ -0,0 +1,59 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+)
+
+func TestAnonymizeAlerts_AllTextFields(t *testing.T) {
+	alerts := []database.Alert{{
+		UUID:                 "alert-1",
+		IncidentUUID:         "incident-1",
+		SourceUUID:           "source-1",
+		Fingerprint:          "0123456789abcdef",
+		Status:               database.AlertStatusFiring,
+		SourceFingerprint:    "web-01.prod.acme/DiskFull",
+		AlertName:            "DiskFull on 10.0.0.5",
+		TargetHost:           "web-01.prod.acme",
+		CorrelationReasoning: "same rack as db-02.prod.acme, paged jsmith",
+		CorrelationDecision:  "web-01.prod.acme",
+		RawPayload: database.JSONB{
+			"labels": map[string]interface{}{"owner": "jsmith", "peer": "cache-3.dc1.acme"},
+		},
+	}}
+	a := utils.NewAnonymizer()
+	SeedAnonymizer(a, alerts)
+	got := AnonymizeAlerts(a, alerts)[0]
+
+	// No string field, whichever it is, may still name the TLD, host,
+	// IP or user.
+	v := reflect.ValueOf(got)
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.String {
+			for _, leaked := range []string{"acme", "10.0.0.5", "jsmith"} {
+				if strings.Contains(f.String(), leaked) {
+					t.Errorf("%s = %q leaks %q", v.Type().Field(i).Name, f.String(), leaked)
+				}
+			}
+		}
+	}
+	labels := got.RawPayload["labels"].(map[string]interface{})
+	if labels["owner"] != "user-1" || labels["peer"] != "host-3" {
+		t.Errorf("labels = %v", labels)
+	}
+	if got.TargetHost != "host-1" || got.CorrelationDecision != "host-1" ||
+		got.CorrelationReasoning != "same rack as host-2, paged user-1" {
+		t.Errorf("anonymized alert = %+v", got)
+	}
+	if got.UUID != "alert-1" || got.IncidentUUID != "incident-1" || got.SourceUUID != "source-1" ||
+		got.Fingerprint != "0123456789abcdef" || got.Status != database.AlertStatusFiring {
+		t.Errorf("identifiers changed: %+v", got)
+	}
+	if alerts[0].TargetHost != "web-01.prod.acme" {
+		t.Errorf("input alert modified: %+v", alerts[0])
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// staleIncidentSweepInterval is how often StaleIncidentCloser looks for
+// stale incidents. The threshold is in days, so hourly is plenty.
+const staleIncidentSweepInterval = time.Hour
+
+// staleIncidentBatch bounds how many candidates one sweep examines; the
+// rest are picked up by the next run.
+const staleIncidentBatch = 200
+
+// staleIncidentActor is the timeline actor of auto-close entries. Events by
+// "system" and "agent" do not count as human activity.
+const staleIncidentActor = "system"
+
+// StaleIncidentCloser closes incidents that sit in completed or monitor with
+// no new alert and no human activity for GeneralSettings.IncidentAutoCloseDays,
+// so the open-incident list only shows incidents someone still cares about.
+// Activity is the latest of: the investigation finishing, a linked alert
+// arriving or changing, and a timeline event by a user (notes, approvals,
+// follow-up messages). Each close resolves any alert still firing on the
+// incident and leaves a note on the timeline saying why.
+type StaleIncidentCloser struct {
+	db        *gorm.DB
+	publisher IncidentEventPublisher   // optional; notified of each closed incident
+	timeline  IncidentTimelineRecorder // optional; receives the auto-close note
+	now       func() time.Time
+}
+
+// NewStaleIncidentCloser creates the closer.
+func NewStaleIncidentCloser(db *gorm.DB) *StaleIncidentCloser {
+	return &StaleIncidentCloser{db: db, now: time.Now}
+}
+
+// SetIncidentEventPublisher wires the publisher told about closed incidents.
+// Optional.
+func (s *StaleIncidentCloser) SetIncidentEventPublisher(p IncidentEventPublisher) {
+	s.publisher = p
+}
+
+// SetIncidentTimeline wires the timeline that receives the auto-close note.
+// Optional.
+func (s *StaleIncidentCloser) SetIncidentTimeline(t IncidentTimelineRecorder) {
+	s.timeline = t
+}
+
+// CloseStale closes every stale incident and returns how many it closed. It
+// does nothing while auto-close is disabled.
+func (s *StaleIncidentCloser) CloseStale(ctx context.Context) (int, error) {
+	var settings database.GeneralSettings
+	if err := s.db.WithContext(ctx).Limit(1).Find(&settings).Error; err != nil {
+		return 0, fmt.Errorf("load general settings: %w", err)
+	}
+	after := settings.GetIncidentAutoCloseAfter()
+	if after <= 0 {
+		return 0, nil
+	}
+	now := s.now()
+	cutoff := now.Add(-after)
+
+	var candidates []database.Incident
+	if err := s.db.WithContext(ctx).
+		Select("uuid", "status", "created_at", "completed_at").
+		Where("status IN ? AND COALESCE(completed_at, created_at) < ?",
+			[]database.IncidentStatus{database.IncidentStatusCompleted, database.IncidentStatusMonitor}, cutoff).
+		Order("id").Limit(staleIncidentBatch).
+		Find(&candidates).Error; err != nil {
+		return 0, fmt.Errorf("list stale incident candidates: %w", err)
+	}
+
+	closed := 0
+	for _, incident := range candidates {
+		last, err := s.lastActivity(ctx, &incident)
+		if err != nil {
+			return closed, err
+		}
+		if !last.Before(cutoff) {
+			continue
+		}
+		ok, resolved, err := s.close(ctx, incident.UUID, now)
+		if err != nil {
+			return closed, err
+		}
+		if !ok {
+			continue
+		}
+		closed++
+		s.recordClose(incident.UUID, settings.IncidentAutoCloseDays, last, resolved, now)
+	}
+	if closed > 0 {
+		slog.Info("closed stale incidents", "count", closed, "idle_days", *settings.IncidentAutoCloseDays)
+	}
+	return closed, nil
+}
+
+// lastActivity returns when incident last saw an alert or human activity.
+func (s *StaleIncidentCloser) lastActivity(ctx context.Context, incident *database.Incident) (time.Time, error) {
+	last := incident.CreatedAt
+	if incident.CompletedAt != nil && incident.CompletedAt.After(last) {
+		last = *incident.CompletedAt
+	}
+
+	var alertAt, eventAt []time.Time
+	if err := s.db.WithContext(ctx).Model(&database.Alert{}).
+		Where("incident_uuid = ?", incident.UUID).
+		Order("updated_at DESC").Limit(1).
+		Pluck("updated_at", &alertAt).Error; err != nil {
+		return last, fmt.Errorf("latest alert for %s: %w", incident.UUID, err)
+	}
+	if err := s.db.WithContext(ctx).Model(&database.IncidentEvent{}).
+		Where("incident_uuid = ? AND actor NOT IN ?", incident.UUID, []string{"", "agent", staleIncidentActor}).
+		Order("occurred_at DESC").Limit(1).
+		Pluck("occurred_at", &eventAt).Error; err != nil {
+		return last, fmt.Errorf("latest activity for %s: %w", incident.UUID, err)
+	}
+	for _, ts := range append(alertAt, eventAt...) {
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	return last, nil
+}
+
+// close moves one incident to closed and resolves its firing alerts. ok is
+// false when the incident left completed/monitor since it was listed.
+func (s *StaleIncidentCloser) close(ctx context.Context, incidentUUID string, now time.Time) (ok bool, resolvedAlerts int64, err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&database.Incident{}).
+			Where("uuid = ? AND status IN ?", incidentUUID,
+				[]database.IncidentStatus{database.IncidentStatusCompleted, database.IncidentStatusMonitor}).
+			Updates(map[string]interface{}{
+				"status":        database.IncidentStatusClosed,
+				"resolved_at":   &now,
+				"monitor_until": nil,
+			})
+		if update.Error != nil {
+			return fmt.Errorf("close stale incident %s: %w", incidentUUID, update.Error)
+		}
+		if update.RowsAffected == 0 {
+			return nil
+		}
+		ok = true
+
+		resolve := tx.Model(&database.Alert{}).
+			Where("incident_uuid = ? AND status = ? AND resolved_at IS NULL", incidentUUID, string(database.AlertStatusFiring)).
+			Updates(map[string]interface{}{
+				"status":      string(database.AlertStatusResolved),
+				"resolved_at": now,
+			})
+		if resolve.Error != nil {
+			return fmt.Errorf("resolve alerts of stale incident %s: %w", incidentUUID, resolve.Error)
+		}
+		resolvedAlerts = resolve.RowsAffected
+		return nil
+	})
+	return ok, resolvedAlerts, err
+}
+
+func (s *StaleIncidentCloser) recordClose(incidentUUID string, idleDays *int, lastActivity time.Time, resolvedAlerts int64, now time.Time) {
+	if s.timeline != nil {
+		summary := fmt.Sprintf("Auto-closed: no new alerts or activity for %d days", *idleDays)
+		if resolvedAlerts > 0 {
+			summary += fmt.Sprintf("; %d firing alert(s) resolved", resolvedAlerts)
+		}
+		s.timeline.RecordEvent(database.IncidentEvent{
+			IncidentUUID: incidentUUID,
+			Type:         database.IncidentEventNote,
+			Summary:      summary,
+			Details: database.JSONB{
+				"reason":          "stale",
+				"idle_days":       *idleDays,
+				"last_activity":   lastActivity.UTC().Format(time.RFC3339),
+				"resolved_alerts": resolvedAlerts,
+			},
+			Actor:      staleIncidentActor,
+			OccurredAt: now,
+		})
+	}
+	if s.publisher != nil {
+		s.publisher.PublishStatus(incidentUUID, database.IncidentStatusClosed)
+	}
+}
+
+// StartBackgroundSweep runs CloseStale once at startup, then on a fixed
+// ticker until ctx is cancelled.
+func (s *StaleIncidentCloser) StartBackgroundSweep(ctx context.Context) {
+	slog.Info("starting stale incident auto-close service")
+
+	if _, err := s.CloseStale(ctx); err != nil {
+		slog.Error("initial stale incident sweep failed", "error", err)
+	}
+
+	ticker := time.NewTicker(staleIncidentSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("stale incident auto-close service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.CloseStale(ctx); err != nil {
+				slog.Error("stale incident sweep failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+	"gorm.io/gorm"
+)
+
+func setupStaleCloseTest(t *testing.T, days int) (*gorm.DB, *StaleIncidentCloser, *recordingTimeline) {
+	t.Helper()
+	db := testhelpers.NewSQLiteDB(t, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{}, &database.IncidentEvent{}, &database.GeneralSettings{})
+	if err := db.Create(&database.GeneralSettings{IncidentAutoCloseDays: &days}).Error; err != nil {
+		t.Fatalf("seed settings: %v", err)
+	}
+	timeline := &recordingTimeline{}
+	closer := NewStaleIncidentCloser(db)
+	closer.SetIncidentTimeline(timeline)
+	return db, closer, timeline
+}
+
+func seedStaleIncident(t *testing.T, db *gorm.DB, status database.IncidentStatus, completedAgo time.Duration) string {
+	t.Helper()
+	incUUID := seedSweepIncident(t, db, status, nil)
+	completed := time.Now().Add(-completedAgo)
+	if err := db.Model(&database.Incident{}).Where("uuid = ?", incUUID).
+		Updates(map[string]interface{}{"completed_at": completed, "created_at": completed}).Error; err != nil {
+		t.Fatalf("age incident: %v", err)
+	}
+	return incUUID
+}
+
+func incidentStatus(t *testing.T, db *gorm.DB, incUUID string) database.IncidentStatus {
+	t.Helper()
+	var incident database.Incident
+	if err := db.Where("uuid = ?", incUUID).First(&incident).Error; err != nil {
+		t.Fatalf("load incident: %v", err)
+	}
+	return incident.Status
+}
+
+func TestStaleIncidentCloser_ClosesIdleIncidents(t *testing.T) {
+	db, closer, timeline := setupStaleCloseTest(t, 7)
+	day := 24 * time.Hour
+
+	stale := seedStaleIncident(t, db, database.IncidentStatusCompleted, 10*day)
+	fresh := seedStaleIncident(t, db, database.IncidentStatusMonitor, 2*day)
+	running := seedStaleIncident(t, db, database.IncidentStatusRunning, 10*day)
+
+	recentAlert := seedStaleIncident(t, db, database.IncidentStatusCompleted, 10*day)
+	db.Create(&database.Alert{UUID: "alert-recent", IncidentUUID: recentAlert, AlertName: "disk", Status: database.AlertStatusFiring, FiredAt: time.Now()})
+
+	recentNote := seedStaleIncident(t, db, database.IncidentStatusCompleted, 10*day)
+	db.Create(&database.IncidentEvent{IncidentUUID: recentNote, Type: database.IncidentEventNote, Actor: "alice", OccurredAt: time.Now().Add(-day)})
+
+	systemOnly := seedStaleIncident(t, db, database.IncidentStatusCompleted, 10*day)
+	db.Create(&database.IncidentEvent{IncidentUUID: systemOnly, Type: database.IncidentEventStatusChange, Actor: "system", OccurredAt: time.Now()})
+	oldAlert := database.Alert{UUID: "alert-old", IncidentUUID: systemOnly, AlertName: "cpu", Status: database.AlertStatusFiring, FiredAt: time.Now().Add(-9 * day)}
+	db.Create(&oldAlert)
+	db.Model(&database.Alert{}).Where("uuid = ?", oldAlert.UUID).UpdateColumn("updated_at", time.Now().Add(-9*day))
+
+	closed, err := closer.CloseStale(context.Background())
+	if err != nil {
+		t.Fatalf("CloseStale: %v", err)
+	}
+	if closed != 2 {
+		t.Errorf("closed = %d, want 2", closed)
+	}
+	for incUUID, want := range map[string]database.IncidentStatus{
+		stale:       database.IncidentStatusClosed,
+		systemOnly:  database.IncidentStatusClosed,
+		fresh:       database.IncidentStatusMonitor,
+		running:     database.IncidentStatusRunning,
+		recentAlert: database.IncidentStatusCompleted,
+		recentNote:  database.IncidentStatusCompleted,
+	} {
+		if got := incidentStatus(t, db, incUUID); got != want {
+			t.Errorf("incident %s status = %q, want %q", incUUID, got, want)
+		}
+	}
+
+	var alert database.Alert
+	db.Where("uuid = ?", "alert-old").First(&alert)
+	if alert.Status != database.AlertStatusResolved || alert.ResolvedAt == nil {
+		t.Errorf("firing alert on closed incident = %q, want resolved", alert.Status)
+	}
+	if len(timeline.events) != 2 {
+		t.Fatalf("timeline events = %d, want one note per closed incident", len(timeline.events))
+	}
+	for _, ev := range timeline.events {
+		if ev.Type != database.IncidentEventNote || ev.Actor != "system" || ev.Details["reason"] != "stale" {
+			t.Errorf("timeline event = %+v", ev)
+		}
+	}
+}
+
+func TestStaleIncidentCloser_DisabledByDefault(t *testing.T) {
+	db, closer, _ := setupStaleCloseTest(t, 0)
+	incUUID := seedStaleIncident(t, db, database.IncidentStatusCompleted, 400*24*time.Hour)
+
+	if closed, err := closer.CloseStale(context.Background()); err != nil || closed != 0 {
+		t.Fatalf("CloseStale = %d, %v; want 0 while disabled", closed, err)
+	}
+	if got := incidentStatus(t, db, incUUID); got != database.IncidentStatusCompleted {
+		t.Errorf("status = %q, want completed", got)
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// ErrIncidentNotFound is returned by IncidentExportService.Export for an
+// unknown incident UUID.
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// maxExportWorkspaceBytes caps how much of one incident's workspace goes
+// into an archive. Files past the cap are listed in the manifest with
+// skipped set, so the archive still records that they existed.
+const maxExportWorkspaceBytes = 256 * 1024 * 1024
+
+// ExportedFile is one workspace file in an incident export manifest. Path
+// is slash-separated and relative to the workspace root.
+type ExportedFile struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time"`
+	Skipped   bool      `json:"skipped,omitempty"`
+}
+
+// IncidentExport is everything recorded about one incident, for compliance
+// retention outside the database.
+type IncidentExport struct {
+	ExportedAt     time.Time                 `json:"exported_at"`
+	Incident       database.Incident         `json:"incident"`
+	Alerts         []database.Alert          `json:"alerts"`
+	Timeline       []database.IncidentEvent  `json:"timeline"`
+	Changes        []database.IncidentChange `json:"changes"`
+	Links          []database.IncidentLink   `json:"links"`
+	Postmortem     *database.IncidentReport  `json:"postmortem,omitempty"`
+	WorkspaceFiles []ExportedFile            `json:"workspace_files"`
+
+	// workspaceDir is the resolved workspace the manifest was built from;
+	// empty when the incident has none on disk.
+	workspaceDir string
+}
+
+// IncidentExportService assembles incident exports and writes them as zip
+// archives.
+type IncidentExportService struct {
+	db           *gorm.DB
+	incidentsDir string
+}
+
+// NewIncidentExportService creates an export service. Workspace files are
+// only read from incident working directories inside incidentsDir.
+func NewIncidentExportService(db *gorm.DB, incidentsDir string) *IncidentExportService {
+	return &IncidentExportService{db: db, incidentsDir: incidentsDir}
+}
+
+// Export loads the incident with the given UUID and everything attached to
+// it. Returns ErrIncidentNotFound if there is no such incident.
+func (s *IncidentExportService) Export(ctx context.Context, incidentUUID string) (*IncidentExport, error) {
+	var incident database.Incident
+	err := s.db.WithContext(ctx).Where("uuid = ?", incidentUUID).First(&incident).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.ExportIncident(ctx, &incident)
+}
+
+// ExportIncident collects the alerts, timeline, workspace changes, links,
+// postmortem and workspace manifest of an already loaded incident, and its
+// log. The incident's log and response are sanitized as they are when
+// served by the API.
+func (s *IncidentExportService) ExportIncident(ctx context.Context, incident *database.Incident) (*IncidentExport, error) {
+	db := s.db.WithContext(ctx)
+	fullLog, err := database.LoadIncidentLog(db, incident.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("load log: %w", err)
+	}
+	export := &IncidentExport{
+		ExportedAt:     time.Now().UTC(),
+		Incident:       *incident,
+		Alerts:         []database.Alert{},
+		Timeline:       []database.IncidentEvent{},
+		Links:          []database.IncidentLink{},
+		WorkspaceFiles: []ExportedFile{},
+	}
+	export.Incident.FullLog = utils.SanitizeLog(fullLog)
+	export.Incident.Response = utils.SanitizeLog(incident.Response)
+
+	if err := db.Where("incident_uuid = ?", incident.UUID).Order("created_at, uuid").Find(&export.Alerts).Error; err != nil {
+		return nil, fmt.Errorf("load alerts: %w", err)
+	}
+	if err := db.Where("incident_uuid = ?", incident.UUID).Order("occurred_at, id").Find(&export.Timeline).Error; err != nil {
+		return nil, fmt.Errorf("load timeline: %w", err)
+	}
+	changes, err := ListIncidentChanges(ctx, s.db, incident.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("load changes: %w", err)
+	}
+	export.Changes = changes
+	if err := db.Where("from_uuid = ? OR to_uuid = ?", incident.UUID, incident.UUID).Order("id").Find(&export.Links).Error; err != nil {
+		return nil, fmt.Errorf("load links: %w", err)
+	}
+	var reports []database.IncidentReport
+	if err := db.Where("incident_uuid = ?", incident.UUID).Limit(1).Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("load postmortem: %w", err)
+	}
+	if len(reports) > 0 {
+		export.Postmortem = &reports[0]
+	}
+
+	if dir := s.resolveWorkspace(incident); dir != "" {
+		files, err := listWorkspaceFiles(dir)
+		if err != nil {
+			return nil, fmt.Errorf("list workspace: %w", err)
+		}
+		export.WorkspaceFiles = files
+		export.workspaceDir = dir
+	}
+	return export, nil
+}
+
+// resolveWorkspace returns the incident's working directory with symlinks
+// resolved, or "" when it is unset, gone, or outside incidentsDir.
+func (s *IncidentExportService) resolveWorkspace(incident *database.Incident) string {
+	if incident.WorkingDir == "" || s.incidentsDir == "" {
+		return ""
+	}
+	root, err := filepath.EvalSymlinks(s.incidentsDir)
+	if err != nil {
+		return ""
+	}
+	dir, err := filepath.EvalSymlinks(incident.WorkingDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to resolve incident workspace for export", "uuid", incident.UUID, "error", err)
+		}
+		return ""
+	}
+	if !strings.HasPrefix(dir, root+string(os.PathSeparator)) {
+		slog.Warn("incident workspace is outside the incidents directory, not exporting it", "uuid", incident.UUID, "dir", incident.WorkingDir)
+		return ""
+	}
+	return dir
+}
+
+// listWorkspaceFiles lists the regular files under dir, in walk order.
+// Symlinks and other special files are left out.
+func listWorkspaceFiles(dir string) ([]ExportedFile, error) {
+	files := []ExportedFile{}
+	var total int64
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		f := ExportedFile{Path: filepath.ToSlash(rel), SizeBytes: info.Size(), ModTime: info.ModTime().UTC()}
+		if total+f.SizeBytes > maxExportWorkspaceBytes {
+			f.Skipped = true
+		} else {
+			total += f.SizeBytes
+		}
+		files = append(files, f)
+		return nil
+	})
+	return files, err
+}
+
+// WriteArchive adds export to zw under a "<uuid>/" directory:
+// incident.json (the export, without the full log), full_log.txt and, when
+// workspace is set, the workspace files under workspace/.
+func (s *IncidentExportService) WriteArchive(zw *zip.Writer, export *IncidentExport, workspace bool) error {
+	prefix := export.Incident.UUID + "/"
+
+	record := *export
+	record.Incident.FullLog = ""
+	if !workspace {
+		record.WorkspaceFiles = []ExportedFile{}
+	}
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: prefix + "incident.json", Method: zip.Deflate, Modified: export.ExportedAt})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(record); err != nil {
+		return err
+	}
+
+	w, err = zw.CreateHeader(&zip.FileHeader{Name: prefix + "full_log.txt", Method: zip.Deflate, Modified: export.ExportedAt})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, export.Incident.FullLog); err != nil {
+		return err
+	}
+
+	if !workspace || export.workspaceDir == "" {
+		return nil
+	}
+	for _, f := range export.WorkspaceFiles {
+		if f.Skipped {
+			continue
+		}
+		if err := addZipFile(zw, filepath.Join(export.workspaceDir, filepath.FromSlash(f.Path)), path.Join(prefix+"workspace", f.Path), f.ModTime); err != nil {
+			return fmt.Errorf("workspace file %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// addZipFile copies the file at src into zw as name. A file removed or
+// replaced by a non-regular file since the manifest was built is left out.
+func addZipFile(zw *zip.Writer, src, name string, modified time.Time) error {
+	if info, err := os.Lstat(src); err != nil || !info.Mode().IsRegular() {
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	f, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
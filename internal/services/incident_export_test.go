@@ -0,0 +1,117 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func readZip(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestIncidentExport_BundlesRecordsAndWorkspace(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	incidentsDir := t.TempDir()
+	id := createWorkspace(t, db, incidentsDir, database.IncidentStatusCompleted, 1, 0)
+	workDir := filepath.Join(incidentsDir, id)
+	if err := os.MkdirAll(filepath.Join(workDir, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "notes", "findings.md"), []byte("disk full"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("not for export"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ReplaceIncidentLog(db, id, "task\n\x1b[31mred\x1b[0m"); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&database.Alert{UUID: "a-" + id, IncidentUUID: id, AlertName: "DiskFull", Status: database.AlertStatusFiring})
+	db.Create(&database.IncidentEvent{IncidentUUID: id, Type: "note", Summary: "looking", OccurredAt: time.Now()})
+	db.Create(&database.IncidentReport{IncidentUUID: id, Title: "Disk full"})
+
+	svc := NewIncidentExportService(db, incidentsDir)
+	export, err := svc.Export(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(export.Alerts) != 1 || len(export.Timeline) != 1 || export.Postmortem == nil || export.Postmortem.Title != "Disk full" {
+		t.Errorf("export = %+v", export)
+	}
+	if export.Incident.FullLog != "task\nred" {
+		t.Errorf("full log = %q, want it sanitized", export.Incident.FullLog)
+	}
+	if len(export.WorkspaceFiles) != 2 {
+		t.Errorf("workspace files = %+v, want output.log and notes/findings.md only", export.WorkspaceFiles)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := svc.WriteArchive(zw, export, true); err != nil {
+		t.Fatalf("WriteArchive failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files[id+"/full_log.txt"] != "task\nred" || files[id+"/workspace/notes/findings.md"] != "disk full" {
+		t.Errorf("archive entries = %v", files)
+	}
+	if _, ok := files[id+"/workspace/link"]; ok {
+		t.Error("archive followed a symlink out of the workspace")
+	}
+	var record IncidentExport
+	if err := json.Unmarshal([]byte(files[id+"/incident.json"]), &record); err != nil {
+		t.Fatalf("incident.json: %v", err)
+	}
+	if record.Incident.UUID != id || record.Incident.FullLog != "" || len(record.Alerts) != 1 {
+		t.Errorf("incident.json = %+v", record)
+	}
+
+	if _, err := svc.Export(context.Background(), "missing"); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("missing incident err = %v, want ErrIncidentNotFound", err)
+	}
+}
+
+func TestIncidentExport_IgnoresWorkspaceOutsideIncidentsDir(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	otherDir := t.TempDir()
+	id := createWorkspace(t, db, otherDir, database.IncidentStatusCompleted, 1, 10)
+
+	export, err := NewIncidentExportService(db, t.TempDir()).Export(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(export.WorkspaceFiles) != 0 {
+		t.Errorf("workspace files = %+v, want none", export.WorkspaceFiles)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// Incident link errors surfaced to the API as 4xx responses.
+var (
+	ErrIncidentLinkSelf     = errors.New("an incident cannot be linked to itself")
+	ErrIncidentLinkExists   = errors.New("these incidents are already linked")
+	ErrIncidentHasParent    = errors.New("incident already has a parent; unlink it first")
+	ErrIncidentLinkCycle    = errors.New("link would make an incident its own ancestor")
+	ErrIncidentLinkNotFound = errors.New("incident link not found")
+	ErrInvalidIncidentLink  = errors.New("kind must be parent, child or related")
+)
+
+// incidentActiveStatuses are the statuses in which a parent incident
+// suppresses its children's channel notifications.
+var incidentActiveStatuses = []database.IncidentStatus{
+	database.IncidentStatusPending,
+	database.IncidentStatusRunning,
+	database.IncidentStatusDiagnosed,
+	database.IncidentStatusMonitor,
+}
+
+// IncidentLinkService manages parent/child and related links between
+// incidents. Parent links are created manually or by the alert correlator
+// when a new alert is a downstream symptom of an open incident.
+type IncidentLinkService struct {
+	db *gorm.DB
+}
+
+// NewIncidentLinkService creates an incident link service.
+func NewIncidentLinkService(db *gorm.DB) *IncidentLinkService {
+	return &IncidentLinkService{db: db}
+}
+
+// LinkIncidents links incidentUUID to targetUUID. kind is relative to
+// incidentUUID: "parent" makes target its parent, "child" makes target its
+// child, "related" links them symmetrically.
+func (s *IncidentLinkService) LinkIncidents(ctx context.Context, incidentUUID, targetUUID, kind, origin, reason string) (*database.IncidentLink, error) {
+	if incidentUUID == targetUUID {
+		return nil, ErrIncidentLinkSelf
+	}
+	if origin == "" {
+		origin = database.IncidentLinkOriginManual
+	}
+
+	link := &database.IncidentLink{Origin: origin, Reason: strings.TrimSpace(reason)}
+	switch kind {
+	case "parent":
+		link.Kind, link.FromUUID, link.ToUUID = database.IncidentLinkParent, incidentUUID, targetUUID
+	case "child":
+		link.Kind, link.FromUUID, link.ToUUID = database.IncidentLinkParent, targetUUID, incidentUUID
+	case database.IncidentLinkRelated:
+		link.Kind, link.FromUUID, link.ToUUID = database.IncidentLinkRelated, incidentUUID, targetUUID
+		if link.FromUUID > link.ToUUID {
+			link.FromUUID, link.ToUUID = link.ToUUID, link.FromUUID
+		}
+	default:
+		return nil, ErrInvalidIncidentLink
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.Incident{}).Where("uuid IN ?", []string{incidentUUID, targetUUID}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&database.IncidentLink{}).
+			Where("(from_uuid = ? AND to_uuid = ?) OR (from_uuid = ? AND to_uuid = ?)", incidentUUID, targetUUID, targetUUID, incidentUUID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrIncidentLinkExists
+		}
+
+		if link.Kind == database.IncidentLinkParent {
+			if err := tx.Model(&database.IncidentLink{}).
+				Where("from_uuid = ? AND kind = ?", link.FromUUID, database.IncidentLinkParent).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrIncidentHasParent
+			}
+			// Walk up from the new parent; reaching the child means a cycle.
+			ancestor := link.ToUUID
+			for depth := 0; ancestor != "" && depth < 100; depth++ {
+				if ancestor == link.FromUUID {
+					return ErrIncidentLinkCycle
+				}
+				var up database.IncidentLink
+				err := tx.Where("from_uuid = ? AND kind = ?", ancestor, database.IncidentLinkParent).First(&up).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				ancestor = up.ToUUID
+			}
+		}
+
+		return tx.Create(link).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// UnlinkIncidents removes the link between two incidents, whichever its kind
+// or direction.
+func (s *IncidentLinkService) UnlinkIncidents(ctx context.Context, incidentUUID, targetUUID string) error {
+	res := s.db.WithContext(ctx).
+		Where("(from_uuid = ? AND to_uuid = ?) OR (from_uuid = ? AND to_uuid = ?)", incidentUUID, targetUUID, targetUUID, incidentUUID).
+		Delete(&database.IncidentLink{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrIncidentLinkNotFound
+	}
+	return nil
+}
+
+// GetRelations returns the parent, children and related incidents of
+// incidentUUID. Links whose other incident no longer exists are skipped.
+func (s *IncidentLinkService) GetRelations(ctx context.Context, incidentUUID string) (*database.IncidentRelations, error) {
+	var links []database.IncidentLink
+	if err := s.db.WithContext(ctx).
+		Where("from_uuid = ? OR to_uuid = ?", incidentUUID, incidentUUID).
+		Order("created_at ASC").
+		Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("load incident links: %w", err)
+	}
+
+	relations := &database.IncidentRelations{
+		Children: []database.IncidentLinkRef{},
+		Related:  []database.IncidentLinkRef{},
+	}
+	if len(links) == 0 {
+		return relations, nil
+	}
+
+	others := make([]string, 0, len(links))
+	for _, l := range links {
+		if l.FromUUID == incidentUUID {
+			others = append(others, l.ToUUID)
+		} else {
+			others = append(others, l.FromUUID)
+		}
+	}
+	var rows []struct {
+		UUID   string
+		Title  string
+		Status database.IncidentStatus
+	}
+	if err := s.db.WithContext(ctx).Model(&database.Incident{}).
+		Select("uuid, title, status").
+		Where("uuid IN ?", others).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("load linked incidents: %w", err)
+	}
+	byUUID := make(map[string]int, len(rows))
+	for i, r := range rows {
+		byUUID[r.UUID] = i
+	}
+
+	for i, l := range links {
+		idx, ok := byUUID[others[i]]
+		if !ok {
+			continue
+		}
+		ref := database.IncidentLinkRef{
+			UUID:      rows[idx].UUID,
+			Title:     rows[idx].Title,
+			Status:    rows[idx].Status,
+			Origin:    l.Origin,
+			Reason:    l.Reason,
+			CreatedAt: l.CreatedAt,
+		}
+		switch {
+		case l.Kind == database.IncidentLinkParent && l.FromUUID == incidentUUID:
+			relations.Parent = &ref
+		case l.Kind == database.IncidentLinkParent:
+			relations.Children = append(relations.Children, ref)
+		default:
+			relations.Related = append(relations.Related, ref)
+		}
+	}
+	return relations, nil
+}
+
+// ActiveParent returns the parent of incidentUUID when it is still open, or
+// nil. Used to keep a child's notifications in its parent's thread instead of
+// posting them to the channel.
+func (s *IncidentLinkService) ActiveParent(ctx context.Context, incidentUUID string) (*database.Incident, error) {
+	var link database.IncidentLink
+	err := s.db.WithContext(ctx).
+		Where("from_uuid = ? AND kind = ?", incidentUUID, database.IncidentLinkParent).
+		First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var parent database.Incident
+	err = s.db.WithContext(ctx).
+		Where("uuid = ? AND status IN ?", link.ToUUID, incidentActiveStatuses).
+		First(&parent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &parent, nil
+}
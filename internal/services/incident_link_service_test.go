@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+func newTestIncidentLinkService(t *testing.T, incidents map[string]database.IncidentStatus) *IncidentLinkService {
+	t.Helper()
+	db := setupIncidentTestDB(t)
+	if err := db.AutoMigrate(&database.IncidentLink{}); err != nil {
+		t.Fatalf("migrate incident links: %v", err)
+	}
+	for uuid, status := range incidents {
+		if err := db.Create(&database.Incident{UUID: uuid, Title: "Incident " + uuid, Status: status}).Error; err != nil {
+			t.Fatalf("seed incident %s: %v", uuid, err)
+		}
+	}
+	return NewIncidentLinkService(db)
+}
+
+func TestIncidentLinkService_LinkAndRelations(t *testing.T) {
+	svc := newTestIncidentLinkService(t, map[string]database.IncidentStatus{
+		"db": database.IncidentStatusRunning, "api": database.IncidentStatusRunning,
+		"web": database.IncidentStatusRunning, "cdn": database.IncidentStatusCompleted,
+	})
+	ctx := context.Background()
+
+	if _, err := svc.LinkIncidents(ctx, "api", "db", "parent", database.IncidentLinkOriginCorrelator, "db outage"); err != nil {
+		t.Fatalf("link parent: %v", err)
+	}
+	if _, err := svc.LinkIncidents(ctx, "db", "web", "child", "", ""); err != nil {
+		t.Fatalf("link child: %v", err)
+	}
+	if _, err := svc.LinkIncidents(ctx, "web", "cdn", database.IncidentLinkRelated, "", ""); err != nil {
+		t.Fatalf("link related: %v", err)
+	}
+
+	rel, err := svc.GetRelations(ctx, "db")
+	if err != nil {
+		t.Fatalf("GetRelations: %v", err)
+	}
+	if rel.Parent != nil || len(rel.Children) != 2 || len(rel.Related) != 0 {
+		t.Fatalf("db relations = %+v", rel)
+	}
+	if rel.Children[0].UUID != "api" || rel.Children[0].Origin != database.IncidentLinkOriginCorrelator || rel.Children[0].Reason != "db outage" {
+		t.Errorf("first child = %+v", rel.Children[0])
+	}
+
+	rel, _ = svc.GetRelations(ctx, "web")
+	if rel.Parent == nil || rel.Parent.UUID != "db" || len(rel.Related) != 1 || rel.Related[0].UUID != "cdn" {
+		t.Fatalf("web relations = %+v", rel)
+	}
+	rel, _ = svc.GetRelations(ctx, "cdn")
+	if len(rel.Related) != 1 || rel.Related[0].UUID != "web" || rel.Related[0].Origin != database.IncidentLinkOriginManual {
+		t.Fatalf("related link must show from both sides, got %+v", rel)
+	}
+
+	if err := svc.UnlinkIncidents(ctx, "cdn", "web"); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+	if err := svc.UnlinkIncidents(ctx, "cdn", "web"); !errors.Is(err, ErrIncidentLinkNotFound) {
+		t.Errorf("second unlink err = %v, want ErrIncidentLinkNotFound", err)
+	}
+}
+
+func TestIncidentLinkService_RejectsInvalidLinks(t *testing.T) {
+	svc := newTestIncidentLinkService(t, map[string]database.IncidentStatus{
+		"a": database.IncidentStatusRunning, "b": database.IncidentStatusRunning, "c": database.IncidentStatusRunning,
+	})
+	ctx := context.Background()
+
+	if _, err := svc.LinkIncidents(ctx, "b", "a", "parent", "", ""); err != nil {
+		t.Fatalf("link b under a: %v", err)
+	}
+	if _, err := svc.LinkIncidents(ctx, "c", "b", "parent", "", ""); err != nil {
+		t.Fatalf("link c under b: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		from, to, kind string
+		want           error
+	}{
+		{"self", "a", "a", "related", ErrIncidentLinkSelf},
+		{"bad kind", "a", "b", "sibling", ErrInvalidIncidentLink},
+		{"duplicate either direction", "a", "b", "related", ErrIncidentLinkExists},
+		{"reverse of existing parent link", "b", "c", "parent", ErrIncidentLinkExists},
+		{"already has parent", "c", "a", "parent", ErrIncidentHasParent},
+		{"cycle", "a", "c", "parent", ErrIncidentLinkCycle},
+		{"missing incident", "a", "nope", "related", gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := svc.LinkIncidents(ctx, tt.from, tt.to, tt.kind, "", ""); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestIncidentLinkService_ActiveParent(t *testing.T) {
+	svc := newTestIncidentLinkService(t, map[string]database.IncidentStatus{
+		"parent": database.IncidentStatusRunning, "child": database.IncidentStatusPending, "orphan": database.IncidentStatusPending,
+	})
+	ctx := context.Background()
+
+	if p, err := svc.ActiveParent(ctx, "orphan"); err != nil || p != nil {
+		t.Fatalf("orphan parent = %v, %v", p, err)
+	}
+	if _, err := svc.LinkIncidents(ctx, "child", "parent", "parent", "", ""); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	p, err := svc.ActiveParent(ctx, "child")
+	if err != nil || p == nil || p.UUID != "parent" {
+		t.Fatalf("active parent = %v, %v", p, err)
+	}
+
+	svc.db.Model(&database.Incident{}).Where("uuid = ?", "parent").Update("status", database.IncidentStatusCompleted)
+	if p, err := svc.ActiveParent(ctx, "child"); err != nil || p != nil {
+		t.Fatalf("closed parent should not be active, got %v, %v", p, err)
+	}
+}
@@ -268,7 +268,7 @@ func (m *IncidentMerger) notifyMerged(ctx context.Context, merged *database.Inci
 	text := fmt.Sprintf(":twisted_rightwards_arrows: This incident was merged into *%s* — same root cause: %s",
 		survivorLabel, verdict.Reasoning)
 	if _, err := provider.PostThreadReply(ctx, &channel, merged.SlackMessageTS, text); err != nil {
-		slog.Warn("incident merger: merge note failed", "incident", merged.UUID, "err", err)
+		slog.Warn("incident merger: merge note failed", "incident_uuid", merged.UUID, "err", err)
 	}
 }
 
@@ -12,6 +12,8 @@ import (
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/metrics"
+	"github.com/akmatori/akmatori/internal/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -49,6 +51,9 @@ func (s *SkillService) InsertFiringAlert(ctx context.Context, incidentUUID strin
 		// Unique constraint fired: another process already claimed this alert.
 		return ErrAlertAlreadyClaimed
 	}
+	if s.timeline != nil {
+		s.timeline.RecordEvent(alertAttachedEvent(incidentUUID, row.UUID, row.AlertName, row.TargetHost, decision))
+	}
 	return nil
 }
 
@@ -62,7 +67,8 @@ func (s *SkillService) InsertFiringAlert(ctx context.Context, incidentUUID strin
 // would strand the alert on a hidden incident with no monitor extension, so
 // the link follows merged_into_uuid to the live survivor first.
 func (s *SkillService) LinkAlertToIncident(ctx context.Context, incidentUUID string, sourceUUID string, alert alerts.NormalizedAlert, confidence float64, reasoning string) error {
-	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	var linked *database.Alert
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		incident, err := loadLinkTargetTx(tx, incidentUUID)
 		if err != nil {
 			return err
@@ -99,6 +105,7 @@ func (s *SkillService) LinkAlertToIncident(ctx context.Context, incidentUUID str
 			// Duplicate alert already linked; do not extend the monitor window.
 			return nil
 		}
+		linked = &row
 
 		if incident.Status == database.IncidentStatusMonitor {
 			var settings database.GeneralSettings
@@ -116,6 +123,10 @@ func (s *SkillService) LinkAlertToIncident(ctx context.Context, incidentUUID str
 
 		return nil
 	})
+	if err == nil && linked != nil && s.timeline != nil {
+		s.timeline.RecordEvent(alertAttachedEvent(linked.IncidentUUID, linked.UUID, linked.AlertName, linked.TargetHost, linked.CorrelationDecision))
+	}
+	return err
 }
 
 // linkRedirectMaxHops bounds how far loadLinkTargetTx follows the
@@ -141,7 +152,7 @@ func loadLinkTargetTx(tx *gorm.DB, incidentUUID string) (*database.Incident, err
 		}
 		if hop >= linkRedirectMaxHops {
 			slog.Warn("LinkAlertToIncident: merged_into_uuid chain exceeds hop cap; attaching to merged row",
-				"incident", incidentUUID, "stopped_at", uuid)
+				"incident_uuid", incidentUUID, "stopped_at", uuid)
 			return &incident, nil
 		}
 		slog.Info("LinkAlertToIncident: redirecting link from merged incident to survivor",
@@ -257,7 +268,7 @@ func (s *SkillService) ResolveAlert(ctx context.Context, alertUUID string) error
 // linked firing alerts as part of the close regardless of investigation
 // status.
 func (s *SkillService) CloseIncident(ctx context.Context, incidentUUID string, confirm bool) error {
-	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var incident database.Incident
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
@@ -299,6 +310,63 @@ func (s *SkillService) CloseIncident(ctx context.Context, incidentUUID string, c
 		}
 		return nil
 	})
+	if err == nil && s.eventPublisher != nil {
+		s.eventPublisher.PublishStatus(incidentUUID, database.IncidentStatusClosed)
+	}
+	return err
+}
+
+// CancelIncident records that cancelledBy stopped the incident's
+// investigation: a pending or running incident moves to cancelled, keeping
+// the log streamed so far (any throttled progress is written first) with a
+// note of who cancelled it appended. Returns gorm.ErrRecordNotFound when the
+// incident does not exist and ErrIncidentNotRunning when no investigation is
+// in progress. Stopping the agent run itself is up to the caller.
+func (s *SkillService) CancelIncident(ctx context.Context, incidentUUID, cancelledBy string) error {
+	if s.progressLog != nil {
+		s.progressLog.settle(incidentUUID, true)
+	}
+	var incident database.Incident
+	if err := s.db.WithContext(ctx).Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return fmt.Errorf("CancelIncident: load incident: %w", err)
+	}
+	if incident.Status != database.IncidentStatusPending && incident.Status != database.IncidentStatusRunning {
+		return ErrIncidentNotRunning
+	}
+
+	note := fmt.Sprintf("🛑 Investigation cancelled by %s.", cancelledBy)
+	response := note
+	if incident.Response != "" {
+		// A cancelled follow-up keeps the answer the previous run gave.
+		response = incident.Response + "\n\n" + note
+	}
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&database.Incident{}).
+			Where("uuid = ? AND status = ?", incidentUUID, incident.Status).
+			Updates(map[string]interface{}{
+				"status":       database.IncidentStatusCancelled,
+				"response":     response,
+				"completed_at": &now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("CancelIncident: update incident: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrIncidentNotRunning
+		}
+		return database.AppendIncidentLog(tx, incidentUUID, "\n\n--- Cancelled ---\n\n"+note)
+	})
+	if err != nil {
+		return err
+	}
+	metrics.IncidentFinished(string(database.IncidentStatusCancelled), 0, 0)
+
+	if s.eventPublisher != nil {
+		s.publishStoredLog(incidentUUID)
+		s.eventPublisher.PublishStatus(incidentUUID, database.IncidentStatusCancelled)
+	}
+	return nil
 }
 
 // UnlinkAlertFromIncident detaches an alert from its current incident and
@@ -388,6 +456,9 @@ func (s *SkillService) MoveAlertToIncident(ctx context.Context, alertUUID, targe
 			}
 			return "", fmt.Errorf("MoveAlertToIncident: relink alert: %w", txErr)
 		}
+		if s.timeline != nil {
+			s.timeline.RecordEvent(alertAttachedEvent(targetIncidentUUID, alertUUID, alert.AlertName, alert.TargetHost, "moved"))
+		}
 		return targetIncidentUUID, nil
 	}
 
@@ -422,10 +493,10 @@ func (s *SkillService) MoveAlertToIncident(ctx context.Context, alertUUID, targe
 		if markErr := s.db.Model(&database.Incident{}).
 			Where("uuid = ?", newIncidentUUID).
 			Updates(map[string]interface{}{"status": database.IncidentStatusFailed}).Error; markErr != nil {
-			slog.Error("MoveAlertToIncident: failed to mark orphaned incident as failed", "incident", newIncidentUUID, "err", markErr)
+			slog.Error("MoveAlertToIncident: failed to mark orphaned incident as failed", "incident_uuid", newIncidentUUID, "err", markErr)
 		}
 		if removeErr := os.RemoveAll(filepath.Join(s.incidentsDir, newIncidentUUID)); removeErr != nil {
-			slog.Error("MoveAlertToIncident: failed to remove orphaned incident dir", "incident", newIncidentUUID, "err", removeErr)
+			slog.Error("MoveAlertToIncident: failed to remove orphaned incident dir", "incident_uuid", newIncidentUUID, "err", removeErr)
 		}
 	}
 
@@ -461,6 +532,9 @@ func (s *SkillService) MoveAlertToIncident(ctx context.Context, alertUUID, targe
 		}
 		return "", fmt.Errorf("MoveAlertToIncident: repoint alert: %w", txErr)
 	}
+	if s.timeline != nil {
+		s.timeline.RecordEvent(alertAttachedEvent(newIncidentUUID, alertUUID, alert.AlertName, alert.TargetHost, "new_incident"))
+	}
 
 	return newIncidentUUID, nil
 }
@@ -539,26 +613,30 @@ func (s *SkillService) SpawnAgentInvocation(rootSkillName string, ctx *IncidentC
 		Context:          ctx.Context,
 		WorkingDir:       incidentDir, // Working dir is incident root
 		AlertFingerprint: alertFingerprint,
+		// Taken once the spawn-time files exist so they are not reported
+		// as the agent's changes.
+		WorkspaceBaseline: captureWorkspaceBaseline(incidentDir),
 	}
 
 	if err := s.db.Create(incident).Error; err != nil {
 		return "", "", fmt.Errorf("failed to create incident record: %w", err)
 	}
+	metrics.IncidentCreated(ctx.SourceKind)
 
 	// Generate LLM title in background and update DB when ready
 	if ctx.Message != "" && len(ctx.Message) >= 10 {
 		go func() {
 			generatedTitle, err := titleGen.GenerateTitle(ctx.Message, ctx.Source)
 			if err != nil {
-				slog.Warn("background title generation failed", "incident", incidentUUID, "err", err)
+				slog.Warn("background title generation failed", "incident_uuid", incidentUUID, "err", err)
 				return
 			}
 			if generatedTitle != "" && generatedTitle != title {
 				if err := s.db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).
 					Update("title", generatedTitle).Error; err != nil {
-					slog.Warn("failed to update incident title", "incident", incidentUUID, "err", err)
+					slog.Warn("failed to update incident title", "incident_uuid", incidentUUID, "err", err)
 				} else {
-					slog.Info("updated incident title", "incident", incidentUUID, "title", generatedTitle)
+					slog.Info("updated incident title", "incident_uuid", incidentUUID, "title", generatedTitle)
 				}
 			}
 		}()
@@ -630,15 +708,16 @@ func rootSkillHeader(rootSkillName string) string {
 // UpdateIncidentStatus updates the status of an incident.
 // Only sets session_id and full_log when non-empty to avoid overwriting existing values.
 func (s *SkillService) UpdateIncidentStatus(incidentUUID string, status database.IncidentStatus, sessionID string, fullLog string) error {
+	fullLog = utils.SanitizeLog(fullLog)
+	if s.progressLog != nil {
+		s.progressLog.settle(incidentUUID, fullLog == "")
+	}
 	updates := map[string]interface{}{
 		"status": status,
 	}
 	if sessionID != "" {
 		updates["session_id"] = sessionID
 	}
-	if fullLog != "" {
-		updates["full_log"] = fullLog
-	}
 
 	// Set completed_at timestamp when incident is completed or failed
 	if status == database.IncidentStatusCompleted || status == database.IncidentStatusFailed {
@@ -646,36 +725,176 @@ func (s *SkillService) UpdateIncidentStatus(incidentUUID string, status database
 		updates["completed_at"] = &now
 	}
 
-	if err := s.db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if fullLog != "" {
+			return database.ReplaceIncidentLog(tx, incidentUUID, fullLog)
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update incident status: %w", err)
 	}
+	if status == database.IncidentStatusCompleted || status == database.IncidentStatusFailed {
+		metrics.IncidentFinished(string(status), 0, 0)
+	}
+
+	if s.eventPublisher != nil {
+		if fullLog != "" {
+			s.eventPublisher.PublishLog(incidentUUID, fullLog)
+		}
+		s.eventPublisher.PublishStatus(incidentUUID, status)
+	}
 
 	return nil
 }
 
+// BeginFollowUp claims a finished incident for a follow-up agent run: it
+// moves the incident back to running in a conditional update, so two
+// follow-ups cannot resume the same session at once, and appends logHeader
+// to its log in the same transaction. It returns the incident as it was
+// before the update, with its log, gorm.ErrRecordNotFound when it does not
+// exist and ErrIncidentNotFinished while an agent run is still pending or
+// running.
+func (s *SkillService) BeginFollowUp(incidentUUID string, logHeader string) (*database.Incident, error) {
+	var incident database.Incident
+	if err := s.db.Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return nil, err
+	}
+	if incident.Status == database.IncidentStatusPending || incident.Status == database.IncidentStatusRunning {
+		return nil, ErrIncidentNotFinished
+	}
+	fullLog, err := database.LoadIncidentLog(s.db, incidentUUID)
+	if err != nil {
+		return nil, err
+	}
+	incident.FullLog = fullLog
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&database.Incident{}).
+			Where("uuid = ? AND status = ?", incidentUUID, incident.Status).
+			Updates(map[string]interface{}{
+				"status":       database.IncidentStatusRunning,
+				"completed_at": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to start follow-up: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrIncidentNotFinished
+		}
+		return database.AppendIncidentLog(tx, incidentUUID, utils.SanitizeLog(logHeader))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishLog(incidentUUID, fullLog+utils.SanitizeLog(logHeader))
+		s.eventPublisher.PublishStatus(incidentUUID, database.IncidentStatusRunning)
+	}
+	return &incident, nil
+}
+
+// BeginRetry re-opens a failed or cancelled incident for another run of its
+// recorded investigation prompt. The attempt count is bumped, an attempt
+// header is appended to full_log and the incident is marked running, with
+// the previous response cleared. The returned incident reflects the update
+// and carries the log;
+// its session and token/time totals are the ones the retry carries over.
+// Returns ErrIncidentNotRetryable for other statuses (including a concurrent
+// retry) and ErrNoRecordedTask when there is no prompt to re-run.
+func (s *SkillService) BeginRetry(incidentUUID, retriedBy string) (*database.Incident, error) {
+	var incident database.Incident
+	if err := s.db.Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return nil, err
+	}
+	if incident.Status != database.IncidentStatusFailed && incident.Status != database.IncidentStatusCancelled {
+		return nil, ErrIncidentNotRetryable
+	}
+	if strings.TrimSpace(incident.Task) == "" {
+		return nil, ErrNoRecordedTask
+	}
+
+	attempt := max(incident.Attempts, 1) + 1
+	header := utils.SanitizeLog(fmt.Sprintf("\n\n--- Attempt %d (retried by %s) ---\n\n", attempt, retriedBy))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&database.Incident{}).
+			Where("uuid = ? AND status = ?", incidentUUID, incident.Status).
+			Updates(map[string]interface{}{
+				"status":       database.IncidentStatusRunning,
+				"response":     "",
+				"attempts":     attempt,
+				"completed_at": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to start retry: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrIncidentNotRetryable
+		}
+		return database.AppendIncidentLog(tx, incidentUUID, header)
+	})
+	if err != nil {
+		return nil, err
+	}
+	fullLog, err := database.LoadIncidentLog(s.db, incidentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	incident.Status = database.IncidentStatusRunning
+	incident.FullLog = fullLog
+	incident.Response = ""
+	incident.Attempts = attempt
+	incident.CompletedAt = nil
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishLog(incidentUUID, fullLog)
+		s.eventPublisher.PublishStatus(incidentUUID, database.IncidentStatusRunning)
+	}
+	return &incident, nil
+}
+
 // UpdateIncidentComplete updates the incident with final status, log, and response.
 // When the incident transitions to "completed" and a memory ingester is wired,
 // the on-disk memory directory is re-ingested into Postgres in a detached
 // goroutine. The agent's memory-writer subagent has already produced the
 // files; ingest reconciles them with the DB so the REST API and Slack/UI
 // surfaces see fresh entries without restarting the API.
+//
+// tokensUsed is the incident's running total (reruns pass prior + new), so
+// the growth over the stored total is what this run spent; it is appended
+// to the token_usage table.
 func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status database.IncidentStatus, sessionID string, fullLog string, response string, tokensUsed int, executionTimeMs int64) error {
+	fullLog, response = utils.SanitizeLog(fullLog), utils.SanitizeLog(response)
+	if s.progressLog != nil {
+		s.progressLog.settle(incidentUUID, false)
+	}
 	now := time.Now()
 	updates := map[string]interface{}{
 		"status":            status,
 		"session_id":        sessionID,
-		"full_log":          fullLog,
 		"response":          response,
 		"tokens_used":       tokensUsed,
 		"execution_time_ms": executionTimeMs,
 		"completed_at":      &now,
+		"tool_calls":        utils.CountToolCalls(fullLog),
+		// Cleared rather than left stale when a rerun has nothing to price.
+		"estimated_cost_usd": nil,
+	}
+	model := database.ActiveLLMModel()
+	cost, priced := database.EstimateModelCostUSD(model, tokensUsed)
+	if priced {
+		updates["estimated_cost_usd"] = cost
 	}
 
 	// effectiveStatus tracks what actually gets written to "status" (which
 	// may differ from the requested status below) so the memory-ingest check
 	// after the transaction reflects the real outcome.
 	effectiveStatus := status
-	sourceKind := ""
+	sourceKind, skill, priorTokens := "", "", 0
 
 	txErr := s.db.Transaction(func(tx *gorm.DB) error {
 		var incident database.Incident
@@ -684,6 +903,7 @@ func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status databa
 			return err
 		}
 		sourceKind = incident.SourceKind
+		priorTokens, skill = incident.TokensUsed, incident.LastSkillUsed
 
 		// Alert-sourced incidents transition to monitor status on completion,
 		// but only once every linked alert has resolved — otherwise the
@@ -711,11 +931,45 @@ func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status databa
 			}
 		}
 
-		return tx.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error
+		if err := tx.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return database.ReplaceIncidentLog(tx, incidentUUID, fullLog)
 	})
 	if txErr != nil {
 		return fmt.Errorf("failed to update incident: %w", txErr)
 	}
+	// Labelled with the requested status: a completed alert investigation
+	// promoted to monitor still counts as completed.
+	metrics.IncidentFinished(string(status), executionTimeMs, tokensUsed)
+
+	if spent := tokensUsed - priorTokens; spent > 0 {
+		usage := database.TokenUsage{
+			IncidentUUID: incidentUUID,
+			SourceKind:   sourceKind,
+			Model:        model,
+			Skill:        skill,
+			TokensUsed:   spent,
+			RecordedAt:   now,
+		}
+		if priced {
+			// Prices are per token, so the run's share is proportional.
+			runCost := cost * float64(spent) / float64(tokensUsed)
+			usage.EstimatedCostUSD = &runCost
+		}
+		if err := s.db.Create(&usage).Error; err != nil {
+			slog.Warn("failed to record token usage", "incident_uuid", incidentUUID, "err", err)
+		}
+	}
+
+	if err := s.recordWorkspaceChanges(incidentUUID); err != nil {
+		slog.Warn("failed to record workspace change manifest", "incident_uuid", incidentUUID, "err", err)
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishLog(incidentUUID, fullLog)
+		s.eventPublisher.PublishStatus(incidentUUID, effectiveStatus)
+	}
 
 	// Fire memory ingest for all terminal states: completed (including alert
 	// incidents that are promoted to monitor below), failed, and monitor if a
@@ -731,7 +985,7 @@ func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status databa
 		go func() {
 			ctx := context.Background()
 			if err := ingester.IngestFromDisk(ctx); err != nil {
-				slog.Warn("memory ingest from disk failed", "incident", uuid, "err", err)
+				slog.Warn("memory ingest from disk failed", "incident_uuid", uuid, "err", err)
 			}
 		}()
 	}
@@ -747,7 +1001,7 @@ func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status databa
 		uuid := incidentUUID
 		go func() {
 			if err := merger.EvaluateAndMerge(context.Background(), uuid); err != nil {
-				slog.Warn("post-investigation merge pass failed", "incident", uuid, "err", err)
+				slog.Warn("post-investigation merge pass failed", "incident_uuid", uuid, "err", err)
 			}
 		}()
 	}
@@ -755,23 +1009,80 @@ func (s *SkillService) UpdateIncidentComplete(incidentUUID string, status databa
 	return nil
 }
 
-// UpdateIncidentLog updates only the full_log field of an incident (for progress tracking)
+// UpdateIncidentLog replaces only the log of an incident (for progress tracking).
+// With a progress throttle configured the write may be deferred and batched.
+// Like the other log writers it stores the log sanitized (see
+// utils.SanitizeLog).
 func (s *SkillService) UpdateIncidentLog(incidentUUID string, fullLog string) error {
-	if err := s.db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Update("full_log", fullLog).Error; err != nil {
+	fullLog = utils.SanitizeLog(fullLog)
+	if s.progressLog != nil {
+		return s.progressLog.update(incidentUUID, fullLog)
+	}
+	return s.writeIncidentLog(incidentUUID, fullLog)
+}
+
+func (s *SkillService) writeIncidentLog(incidentUUID string, fullLog string) error {
+	if err := database.ReplaceIncidentLog(s.db, incidentUUID, fullLog); err != nil {
 		return fmt.Errorf("failed to update incident log: %w", err)
 	}
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishLog(incidentUUID, fullLog)
+	}
+	return nil
+}
+
+// appendIncidentLog appends delta to the stored log as new chunks; fullLog
+// is the log it completes, which live subscribers receive.
+func (s *SkillService) appendIncidentLog(incidentUUID, delta, fullLog string) error {
+	if err := database.AppendIncidentLog(s.db, incidentUUID, delta); err != nil {
+		return fmt.Errorf("failed to append incident log: %w", err)
+	}
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishLog(incidentUUID, fullLog)
+	}
 	return nil
 }
 
-// GetIncident retrieves an incident by UUID
+// ReadIncidentLog returns up to limit characters of an incident's log
+// starting at offset, along with the log's total length in characters. Only
+// the log chunks overlapping the window are loaded, so reading the tail of
+// a long log does not load the rest of it.
+func (s *SkillService) ReadIncidentLog(incidentUUID string, offset, limit int) (string, int, error) {
+	var count int64
+	if err := s.db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Count(&count).Error; err != nil {
+		return "", 0, fmt.Errorf("failed to read incident log: %w", err)
+	}
+	if count == 0 {
+		return "", 0, fmt.Errorf("failed to read incident log: %w", gorm.ErrRecordNotFound)
+	}
+	return database.ReadIncidentLogRange(s.db, incidentUUID, offset, limit)
+}
+
+// GetIncident retrieves an incident by UUID, with its log.
 func (s *SkillService) GetIncident(incidentUUID string) (*database.Incident, error) {
 	var incident database.Incident
 	if err := s.db.Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
 		return nil, fmt.Errorf("incident not found: %w", err)
 	}
+	fullLog, err := database.LoadIncidentLog(s.db, incidentUUID)
+	if err != nil {
+		return nil, err
+	}
+	incident.FullLog = fullLog
 	return &incident, nil
 }
 
+// publishStoredLog sends an incident's stored log to the live subscribers,
+// for writers that appended to it without holding the whole log.
+func (s *SkillService) publishStoredLog(incidentUUID string) {
+	fullLog, err := database.LoadIncidentLog(s.db, incidentUUID)
+	if err != nil {
+		slog.Warn("failed to load incident log for subscribers", "incident_uuid", incidentUUID, "err", err)
+		return
+	}
+	s.eventPublisher.PublishLog(incidentUUID, fullLog)
+}
+
 // SubagentSummaryInput contains the outcome of a subagent execution for context management
 type SubagentSummaryInput struct {
 	SkillName     string
@@ -818,17 +1129,20 @@ Note: The full reasoning log is stored but not shown here to keep context clean.
 `, result.SkillName, errorSummary, result.SkillName)
 }
 
-// AppendSubagentLog appends a subagent's reasoning log to the incident's full_log
+// AppendSubagentLog appends a subagent's reasoning log to the incident's log
 // This stores the FULL log in the database for debugging/review purposes
-// Uses SQL concatenation to avoid race conditions when multiple subagents complete concurrently
+// Appends a new log chunk, so subagents completing concurrently cannot lose each other's logs
 func (s *SkillService) AppendSubagentLog(incidentUUID string, skillName string, subagentLog string) error {
 	// Format subagent log with markers
 	formattedLog := fmt.Sprintf("\n\n--- Subagent [%s] Reasoning Log ---\n%s\n--- End Subagent [%s] Reasoning Log ---\n",
 		skillName, subagentLog, skillName)
 
-	// Use SQL concatenation to atomically append without read-modify-write race
-	if err := s.db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).
-		Update("full_log", gorm.Expr("COALESCE(full_log, '') || ?", formattedLog)).Error; err != nil {
+	// Land any batched progress write first so it cannot clobber the append.
+	if s.progressLog != nil {
+		s.progressLog.settle(incidentUUID, true)
+	}
+
+	if err := database.AppendIncidentLog(s.db, incidentUUID, formattedLog); err != nil {
 		return fmt.Errorf("failed to append subagent log: %w", err)
 	}
 
@@ -29,10 +29,13 @@ func setupIncidentTestDB(t *testing.T) *gorm.DB {
 		&database.ToolType{},
 		&database.ToolInstance{},
 		&database.SkillTool{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
 		&database.LLMSettings{},
 		&database.GeneralSettings{},
+		&database.ModelPrice{},
+		&database.IncidentChange{},
+		&database.TokenUsage{},
 	)
 	if err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
@@ -778,6 +781,94 @@ func TestUpdateIncidentComplete_CronSourced_DoesNotSetMonitor(t *testing.T) {
 	}
 }
 
+func TestUpdateIncidentComplete_RecordsToolCallsAndCost(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	db.Exec("DELETE FROM model_prices")
+	db.Exec("DELETE FROM llm_settings")
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM model_prices")
+		db.Exec("DELETE FROM llm_settings")
+	})
+	svc := newIncidentTestService(t, db)
+
+	if err := db.Create(&database.LLMSettings{Provider: database.LLMProviderOpenAI, Name: "default", Model: "gpt-5.2", Active: true, Enabled: true}).Error; err != nil {
+		t.Fatalf("create llm settings: %v", err)
+	}
+	if _, err := database.ReplaceModelPrices([]database.ModelPrice{{Model: "gpt-5*", USDPerMillionTokens: 4}}); err != nil {
+		t.Fatalf("ReplaceModelPrices: %v", err)
+	}
+
+	incidentUUID, _, err := svc.SpawnIncidentManager(&IncidentContext{Source: "api", SourceID: "cost-1", Message: "check disk"})
+	if err != nil {
+		t.Fatalf("SpawnIncidentManager failed: %v", err)
+	}
+	fullLog := "🛠️ Running: ssh\n\n✅ Ran: ssh\n\n❌ Failed: zabbix\n"
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCompleted, "sid", fullLog, "done", 250_000, 1000); err != nil {
+		t.Fatalf("UpdateIncidentComplete failed: %v", err)
+	}
+
+	var incident database.Incident
+	if err := db.Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		t.Fatalf("load incident: %v", err)
+	}
+	if incident.ToolCalls != 2 {
+		t.Errorf("ToolCalls = %d, want 2", incident.ToolCalls)
+	}
+	if incident.EstimatedCostUSD == nil || *incident.EstimatedCostUSD != 1.0 {
+		t.Errorf("EstimatedCostUSD = %v, want 1.0", incident.EstimatedCostUSD)
+	}
+}
+
+func TestUpdateIncidentComplete_RecordsTokenUsagePerRun(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	db.Exec("DELETE FROM model_prices")
+	db.Exec("DELETE FROM llm_settings")
+	db.Exec("DELETE FROM token_usage")
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM model_prices")
+		db.Exec("DELETE FROM llm_settings")
+		db.Exec("DELETE FROM token_usage")
+	})
+	svc := newIncidentTestService(t, db)
+
+	if err := db.Create(&database.LLMSettings{Provider: database.LLMProviderOpenAI, Name: "default", Model: "gpt-5.2", Active: true, Enabled: true}).Error; err != nil {
+		t.Fatalf("create llm settings: %v", err)
+	}
+	if _, err := database.ReplaceModelPrices([]database.ModelPrice{{Model: "gpt-5*", USDPerMillionTokens: 4}}); err != nil {
+		t.Fatalf("ReplaceModelPrices: %v", err)
+	}
+
+	incidentUUID, _, err := svc.SpawnIncidentManager(&IncidentContext{Source: "api", SourceID: "usage-1", Message: "check disk"})
+	if err != nil {
+		t.Fatalf("SpawnIncidentManager failed: %v", err)
+	}
+	db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Update("last_skill_used", "disk-triage")
+
+	// First run, then a rerun that reports the running total.
+	for _, total := range []int{250_000, 750_000} {
+		if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCompleted, "sid", "log", "done", total, 1000); err != nil {
+			t.Fatalf("UpdateIncidentComplete(%d) failed: %v", total, err)
+		}
+	}
+
+	var rows []database.TokenUsage
+	if err := db.Order("id asc").Find(&rows).Error; err != nil {
+		t.Fatalf("load token usage: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d usage rows, want 2", len(rows))
+	}
+	if rows[0].TokensUsed != 250_000 || rows[1].TokensUsed != 500_000 {
+		t.Errorf("tokens = %d, %d; want 250000, 500000", rows[0].TokensUsed, rows[1].TokensUsed)
+	}
+	if rows[1].Model != "gpt-5.2" || rows[1].Skill != "disk-triage" || rows[1].IncidentUUID != incidentUUID {
+		t.Errorf("unexpected usage row: %+v", rows[1])
+	}
+	if rows[1].EstimatedCostUSD == nil || *rows[1].EstimatedCostUSD != 2.0 {
+		t.Errorf("EstimatedCostUSD = %v, want 2.0", rows[1].EstimatedCostUSD)
+	}
+}
+
 func TestUpdateIncidentComplete_AlertSourced_FiringAlert_StaysCompleted(t *testing.T) {
 	db := setupIncidentTestDB(t)
 	svc := newIncidentTestService(t, db)
@@ -1001,6 +1092,50 @@ func seedCorrelatedAlert(t *testing.T, db *gorm.DB, incidentUUID string) string
 	return alertUUID
 }
 
+// --- CancelIncident Tests ---
+
+func TestCancelIncident_RunningKeepsPartialLog(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	svc := newIncidentTestService(t, db)
+	incidentUUID := spawnAlertIncident(t, svc)
+	if err := svc.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", "Checked disk usage on db-01"); err != nil {
+		t.Fatalf("UpdateIncidentStatus: %v", err)
+	}
+
+	if err := svc.CancelIncident(context.Background(), incidentUUID, "alice"); err != nil {
+		t.Fatalf("CancelIncident: %v", err)
+	}
+
+	var incident database.Incident
+	db.Where("uuid = ?", incidentUUID).First(&incident)
+	incident.FullLog, _ = database.LoadIncidentLog(db, incidentUUID)
+	if incident.Status != database.IncidentStatusCancelled || incident.CompletedAt == nil {
+		t.Errorf("status = %q completed_at = %v, want cancelled with a completion time", incident.Status, incident.CompletedAt)
+	}
+	if !strings.HasPrefix(incident.FullLog, "Checked disk usage on db-01") || !strings.Contains(incident.FullLog, "cancelled by alice") {
+		t.Errorf("full_log = %q, want the partial log followed by the cancel note", incident.FullLog)
+	}
+	if !strings.Contains(incident.Response, "cancelled by alice") {
+		t.Errorf("response = %q", incident.Response)
+	}
+}
+
+func TestCancelIncident_RejectsFinishedAndMissingIncidents(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	svc := newIncidentTestService(t, db)
+	incidentUUID := spawnAlertIncident(t, svc)
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "", "log", "boom", 0, 0); err != nil {
+		t.Fatalf("UpdateIncidentComplete: %v", err)
+	}
+
+	if err := svc.CancelIncident(context.Background(), incidentUUID, "alice"); !errors.Is(err, ErrIncidentNotRunning) {
+		t.Errorf("finished incident: err = %v, want ErrIncidentNotRunning", err)
+	}
+	if err := svc.CancelIncident(context.Background(), "missing", "alice"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("missing incident: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
 func TestUnlinkAlertFromIncident_HappyPath(t *testing.T) {
 	db := setupIncidentTestDB(t)
 	svc := newIncidentTestService(t, db)
@@ -1222,3 +1357,95 @@ func TestLinkAlertToIncident_MergedWithoutPointer_AttachesInPlace(t *testing.T)
 		t.Errorf("expected alert attached in place, got %d rows", count)
 	}
 }
+
+// --- BeginFollowUp Tests ---
+
+func TestBeginFollowUp(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	svc := newIncidentTestService(t, db)
+	incidentUUID := spawnAlertIncident(t, svc) // starts pending
+
+	if _, err := svc.BeginFollowUp(incidentUUID, "\nfollow-up"); !errors.Is(err, ErrIncidentNotFinished) {
+		t.Fatalf("pending incident: err = %v, want ErrIncidentNotFinished", err)
+	}
+	if _, err := svc.BeginFollowUp("missing", "\nfollow-up"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing incident: err = %v", err)
+	}
+
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "sid-1", "log", "response", 100, 500); err != nil {
+		t.Fatalf("UpdateIncidentComplete failed: %v", err)
+	}
+	prior, err := svc.BeginFollowUp(incidentUUID, "\nfollow-up")
+	if err != nil {
+		t.Fatalf("BeginFollowUp: %v", err)
+	}
+	if prior.Status != database.IncidentStatusFailed || prior.SessionID != "sid-1" || prior.TokensUsed != 100 {
+		t.Errorf("prior = %+v", prior)
+	}
+
+	if prior.FullLog != "log" {
+		t.Errorf("prior full_log = %q, want the log before the follow-up header", prior.FullLog)
+	}
+
+	var incident database.Incident
+	db.Where("uuid = ?", incidentUUID).First(&incident)
+	incident.FullLog, _ = database.LoadIncidentLog(db, incidentUUID)
+	if incident.Status != database.IncidentStatusRunning || incident.FullLog != "log\nfollow-up" || incident.CompletedAt != nil {
+		t.Errorf("incident status=%s full_log=%q completed_at=%v", incident.Status, incident.FullLog, incident.CompletedAt)
+	}
+
+	// A second follow-up must wait for the first.
+	if _, err := svc.BeginFollowUp(incidentUUID, "\nagain"); !errors.Is(err, ErrIncidentNotFinished) {
+		t.Errorf("concurrent follow-up: err = %v", err)
+	}
+}
+
+func TestBeginRetry(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	svc := newIncidentTestService(t, db)
+	incidentUUID := spawnAlertIncident(t, svc) // starts pending
+
+	if _, err := svc.BeginRetry(incidentUUID, "alice"); !errors.Is(err, ErrIncidentNotRetryable) {
+		t.Fatalf("pending incident: err = %v, want ErrIncidentNotRetryable", err)
+	}
+	if _, err := svc.BeginRetry("missing", "alice"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing incident: err = %v", err)
+	}
+
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusFailed, "sid-1", "log", "❌ bad key", 100, 500); err != nil {
+		t.Fatalf("UpdateIncidentComplete failed: %v", err)
+	}
+	if _, err := svc.BeginRetry(incidentUUID, "alice"); !errors.Is(err, ErrNoRecordedTask) {
+		t.Fatalf("no task: err = %v, want ErrNoRecordedTask", err)
+	}
+
+	db.Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Update("task", "Investigate disk alert")
+	incident, err := svc.BeginRetry(incidentUUID, "alice")
+	if err != nil {
+		t.Fatalf("BeginRetry: %v", err)
+	}
+	if incident.Task != "Investigate disk alert" || incident.Attempts != 2 || incident.TokensUsed != 100 || incident.SessionID != "sid-1" {
+		t.Errorf("incident = %+v", incident)
+	}
+
+	var stored database.Incident
+	db.Where("uuid = ?", incidentUUID).First(&stored)
+	stored.FullLog, _ = database.LoadIncidentLog(db, incidentUUID)
+	if stored.Status != database.IncidentStatusRunning || stored.Attempts != 2 || stored.Response != "" || stored.CompletedAt != nil ||
+		stored.FullLog != "log\n\n--- Attempt 2 (retried by alice) ---\n\n" || stored.FullLog != incident.FullLog {
+		t.Errorf("stored status=%s attempts=%d response=%q full_log=%q completed_at=%v",
+			stored.Status, stored.Attempts, stored.Response, stored.FullLog, stored.CompletedAt)
+	}
+
+	// A running retry cannot be retried again.
+	if _, err := svc.BeginRetry(incidentUUID, "bob"); !errors.Is(err, ErrIncidentNotRetryable) {
+		t.Errorf("concurrent retry: err = %v", err)
+	}
+
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCancelled, "sid-2", stored.FullLog, "", 150, 900); err != nil {
+		t.Fatalf("UpdateIncidentComplete failed: %v", err)
+	}
+	if incident, err := svc.BeginRetry(incidentUUID, "bob"); err != nil || incident.Attempts != 3 {
+		t.Errorf("retry after cancel = %+v, %v", incident, err)
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// IncidentStreamEventType identifies the kind of live incident update.
+type IncidentStreamEventType string
+
+const (
+	// IncidentStreamEventLog carries a full_log delta. Offset is the byte
+	// position in full_log where Delta starts, so clients can detect gaps.
+	IncidentStreamEventLog IncidentStreamEventType = "log"
+	// IncidentStreamEventLogReset replaces the client's copy of full_log with
+	// Delta (sent when the new log is not an extension of the previous one).
+	IncidentStreamEventLogReset IncidentStreamEventType = "log_reset"
+	// IncidentStreamEventStatus signals a status transition.
+	IncidentStreamEventStatus IncidentStreamEventType = "status"
+)
+
+// incidentStreamBuffer bounds each subscriber's queue. A subscriber that falls
+// this far behind is dropped rather than blocking the publisher (which runs on
+// the agent streaming path).
+const incidentStreamBuffer = 256
+
+// IncidentStreamEvent is one live update for an incident.
+type IncidentStreamEvent struct {
+	Type         IncidentStreamEventType `json:"type"`
+	IncidentUUID string                  `json:"incident_uuid"`
+	Offset       int                     `json:"offset,omitempty"`
+	Delta        string                  `json:"delta,omitempty"`
+	Status       database.IncidentStatus `json:"status,omitempty"`
+}
+
+// IncidentEventPublisher receives incident log and status changes from
+// SkillService. Satisfied by *IncidentStreamHub.
+type IncidentEventPublisher interface {
+	PublishLog(incidentUUID, fullLog string)
+	PublishStatus(incidentUUID string, status database.IncidentStatus)
+}
+
+// IncidentEventPublishers fans incident events out to several publishers,
+// e.g. the live stream hub and the PagerDuty sync.
+type IncidentEventPublishers []IncidentEventPublisher
+
+// PublishLog forwards a full_log update to every publisher.
+func (ps IncidentEventPublishers) PublishLog(incidentUUID, fullLog string) {
+	for _, p := range ps {
+		p.PublishLog(incidentUUID, fullLog)
+	}
+}
+
+// PublishStatus forwards a status transition to every publisher.
+func (ps IncidentEventPublishers) PublishStatus(incidentUUID string, status database.IncidentStatus) {
+	for _, p := range ps {
+		p.PublishStatus(incidentUUID, status)
+	}
+}
+
+// IncidentStreamSubscription is a live feed of events for one incident.
+// Events is closed when the subscription is cancelled or the subscriber is
+// dropped for falling behind.
+type IncidentStreamSubscription struct {
+	Events <-chan IncidentStreamEvent
+	cancel func()
+}
+
+// Cancel stops delivery and closes Events. Safe to call more than once.
+func (s *IncidentStreamSubscription) Cancel() {
+	s.cancel()
+}
+
+type incidentStreamSubscriber struct {
+	ch     chan IncidentStreamEvent
+	closed bool
+}
+
+// IncidentStreamHub fans incident updates out to live subscribers (the UI's
+// /ws/incidents/{uuid} sockets). It remembers the last published log per
+// incident only while someone is subscribed, so log deltas cost nothing when
+// no browser is watching.
+type IncidentStreamHub struct {
+	mu      sync.Mutex
+	subs    map[string]map[*incidentStreamSubscriber]struct{}
+	lastLog map[string]string
+}
+
+// NewIncidentStreamHub creates an empty hub.
+func NewIncidentStreamHub() *IncidentStreamHub {
+	return &IncidentStreamHub{
+		subs:    make(map[string]map[*incidentStreamSubscriber]struct{}),
+		lastLog: make(map[string]string),
+	}
+}
+
+// Subscribe registers a subscriber for incidentUUID. currentLog seeds the
+// delta baseline so the first published log after subscribing is sent as a
+// delta relative to what the caller already showed the client.
+func (h *IncidentStreamHub) Subscribe(incidentUUID, currentLog string) *IncidentStreamSubscription {
+	sub := &incidentStreamSubscriber{ch: make(chan IncidentStreamEvent, incidentStreamBuffer)}
+
+	h.mu.Lock()
+	set, ok := h.subs[incidentUUID]
+	if !ok {
+		set = make(map[*incidentStreamSubscriber]struct{})
+		h.subs[incidentUUID] = set
+		h.lastLog[incidentUUID] = currentLog
+	}
+	set[sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return &IncidentStreamSubscription{
+		Events: sub.ch,
+		cancel: func() {
+			once.Do(func() {
+				h.mu.Lock()
+				h.removeLocked(incidentUUID, sub)
+				h.mu.Unlock()
+			})
+		},
+	}
+}
+
+// SubscriberCount returns the number of live subscribers for incidentUUID.
+func (h *IncidentStreamHub) SubscriberCount(incidentUUID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[incidentUUID])
+}
+
+// PublishLog sends the difference between fullLog and the previously
+// published log. When fullLog does not extend the previous value (the caller
+// rewrote the log) a log_reset event carrying the whole log is sent instead.
+func (h *IncidentStreamHub) PublishLog(incidentUUID, fullLog string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.subs[incidentUUID]) == 0 {
+		return
+	}
+
+	prev := h.lastLog[incidentUUID]
+	if fullLog == prev {
+		return
+	}
+	h.lastLog[incidentUUID] = fullLog
+
+	event := IncidentStreamEvent{IncidentUUID: incidentUUID}
+	if strings.HasPrefix(fullLog, prev) {
+		event.Type = IncidentStreamEventLog
+		event.Offset = len(prev)
+		event.Delta = fullLog[len(prev):]
+	} else {
+		event.Type = IncidentStreamEventLogReset
+		event.Delta = fullLog
+	}
+	h.broadcastLocked(incidentUUID, event)
+}
+
+// PublishStatus sends a status transition.
+func (h *IncidentStreamHub) PublishStatus(incidentUUID string, status database.IncidentStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.subs[incidentUUID]) == 0 {
+		return
+	}
+	h.broadcastLocked(incidentUUID, IncidentStreamEvent{
+		Type:         IncidentStreamEventStatus,
+		IncidentUUID: incidentUUID,
+		Status:       status,
+	})
+}
+
+// broadcastLocked delivers event without blocking; subscribers whose buffer
+// is full are dropped. Caller must hold h.mu.
+func (h *IncidentStreamHub) broadcastLocked(incidentUUID string, event IncidentStreamEvent) {
+	for sub := range h.subs[incidentUUID] {
+		select {
+		case sub.ch <- event:
+		default:
+			h.removeLocked(incidentUUID, sub)
+		}
+	}
+}
+
+// removeLocked unregisters sub and closes its channel. Caller must hold h.mu.
+func (h *IncidentStreamHub) removeLocked(incidentUUID string, sub *incidentStreamSubscriber) {
+	set := h.subs[incidentUUID]
+	if _, ok := set[sub]; !ok {
+		return
+	}
+	delete(set, sub)
+	if !sub.closed {
+		sub.closed = true
+		close(sub.ch)
+	}
+	if len(set) == 0 {
+		delete(h.subs, incidentUUID)
+		delete(h.lastLog, incidentUUID)
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func receiveStreamEvent(t *testing.T, sub *IncidentStreamSubscription) IncidentStreamEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.Events:
+		if !ok {
+			t.Fatal("subscription closed unexpectedly")
+		}
+		return ev
+	default:
+		t.Fatal("expected a buffered event")
+	}
+	return IncidentStreamEvent{}
+}
+
+func TestIncidentStreamHub_PublishLogSendsDeltas(t *testing.T) {
+	hub := NewIncidentStreamHub()
+	sub := hub.Subscribe("inc-1", "hello")
+	defer sub.Cancel()
+
+	hub.PublishLog("inc-1", "hello world")
+	ev := receiveStreamEvent(t, sub)
+	if ev.Type != IncidentStreamEventLog || ev.Offset != 5 || ev.Delta != " world" {
+		t.Fatalf("unexpected delta event: %+v", ev)
+	}
+
+	// Unchanged log publishes nothing.
+	hub.PublishLog("inc-1", "hello world")
+	if len(sub.Events) != 0 {
+		t.Fatalf("expected no event for unchanged log, got %d", len(sub.Events))
+	}
+
+	// A rewrite that does not extend the previous log is a reset.
+	hub.PublishLog("inc-1", "rewritten")
+	ev = receiveStreamEvent(t, sub)
+	if ev.Type != IncidentStreamEventLogReset || ev.Delta != "rewritten" {
+		t.Fatalf("unexpected reset event: %+v", ev)
+	}
+}
+
+func TestIncidentStreamHub_PublishStatus(t *testing.T) {
+	hub := NewIncidentStreamHub()
+	sub := hub.Subscribe("inc-1", "")
+	defer sub.Cancel()
+
+	hub.PublishStatus("inc-1", database.IncidentStatusCompleted)
+	ev := receiveStreamEvent(t, sub)
+	if ev.Type != IncidentStreamEventStatus || ev.Status != database.IncidentStatusCompleted {
+		t.Fatalf("unexpected status event: %+v", ev)
+	}
+}
+
+func TestIncidentStreamHub_IsolatesIncidents(t *testing.T) {
+	hub := NewIncidentStreamHub()
+	sub := hub.Subscribe("inc-1", "")
+	defer sub.Cancel()
+
+	hub.PublishLog("inc-2", "other incident")
+	hub.PublishStatus("inc-2", database.IncidentStatusRunning)
+	if len(sub.Events) != 0 {
+		t.Fatalf("expected no events from another incident, got %d", len(sub.Events))
+	}
+}
+
+func TestIncidentStreamHub_CancelClosesAndForgetsState(t *testing.T) {
+	hub := NewIncidentStreamHub()
+	sub := hub.Subscribe("inc-1", "base")
+
+	sub.Cancel()
+	sub.Cancel() // idempotent
+
+	if _, ok := <-sub.Events; ok {
+		t.Fatal("expected Events to be closed after Cancel")
+	}
+	if n := hub.SubscriberCount("inc-1"); n != 0 {
+		t.Errorf("SubscriberCount = %d, want 0", n)
+	}
+	if _, ok := hub.lastLog["inc-1"]; ok {
+		t.Error("expected last-log baseline to be dropped with the last subscriber")
+	}
+}
+
+func TestIncidentStreamHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewIncidentStreamHub()
+	slow := hub.Subscribe("inc-1", "")
+	defer slow.Cancel()
+
+	for i := 0; i <= incidentStreamBuffer; i++ {
+		hub.PublishStatus("inc-1", database.IncidentStatusRunning)
+	}
+
+	drained := 0
+	for range slow.Events {
+		drained++
+	}
+	if drained != incidentStreamBuffer {
+		t.Errorf("drained %d events, want %d before close", drained, incidentStreamBuffer)
+	}
+	if n := hub.SubscriberCount("inc-1"); n != 0 {
+		t.Errorf("SubscriberCount = %d, want slow subscriber removed", n)
+	}
+}
+
+func TestSkillService_PublishesIncidentUpdates(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	svc := newIncidentTestService(t, db)
+	hub := NewIncidentStreamHub()
+	svc.SetIncidentEventPublisher(hub)
+
+	incidentUUID, _, err := svc.SpawnIncidentManager(&IncidentContext{
+		Source:     "manual",
+		SourceID:   "stream-1",
+		SourceKind: database.IncidentSourceKindManual,
+		Message:    "stream test",
+	})
+	if err != nil {
+		t.Fatalf("SpawnIncidentManager failed: %v", err)
+	}
+
+	sub := hub.Subscribe(incidentUUID, "")
+	defer sub.Cancel()
+
+	if err := svc.UpdateIncidentStatus(incidentUUID, database.IncidentStatusRunning, "", "step 1"); err != nil {
+		t.Fatalf("UpdateIncidentStatus failed: %v", err)
+	}
+	if err := svc.UpdateIncidentLog(incidentUUID, "step 1\nstep 2"); err != nil {
+		t.Fatalf("UpdateIncidentLog failed: %v", err)
+	}
+	if err := svc.UpdateIncidentComplete(incidentUUID, database.IncidentStatusCompleted, "sid", "step 1\nstep 2\ndone", "resp", 10, 20); err != nil {
+		t.Fatalf("UpdateIncidentComplete failed: %v", err)
+	}
+
+	want := []IncidentStreamEvent{
+		{Type: IncidentStreamEventLog, Offset: 0, Delta: "step 1"},
+		{Type: IncidentStreamEventStatus, Status: database.IncidentStatusRunning},
+		{Type: IncidentStreamEventLog, Offset: 6, Delta: "\nstep 2"},
+		{Type: IncidentStreamEventLog, Offset: 13, Delta: "\ndone"},
+		{Type: IncidentStreamEventStatus, Status: database.IncidentStatusCompleted},
+	}
+	for i, w := range want {
+		got := receiveStreamEvent(t, sub)
+		w.IncidentUUID = incidentUUID
+		if got != w {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
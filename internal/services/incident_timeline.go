@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Timeline limits: summaries are one-liners for the UI; the full command is
+// kept in details up to maxTimelineCommandBytes.
+const (
+	maxTimelineSummaryRunes = 200
+	maxTimelineCommandBytes = 4096
+	MaxTimelineNoteBytes    = 8192
+)
+
+// ErrInvalidTimelineNote is returned for an empty or oversized note.
+var ErrInvalidTimelineNote = errors.New("invalid timeline note")
+
+// IncidentTimelineService keeps the incident_events timeline: a structured
+// record of status changes, alerts attached, commands the agent ran, Slack
+// posts and operator notes.
+//
+// It is wired as an IncidentEventPublisher so every status transition and
+// log update SkillService persists lands on the timeline; SkillService and
+// AlertHandler record alert attachments and Slack posts through RecordEvent.
+// Recording is best-effort: failures are logged and never reach the caller.
+type IncidentTimelineService struct {
+	db *gorm.DB
+
+	// mu serialises the read-then-insert in PublishLog and PublishStatus so
+	// a progress flush racing the final log update cannot record the same
+	// command or transition twice.
+	mu sync.Mutex
+}
+
+// NewIncidentTimelineService creates a timeline service.
+func NewIncidentTimelineService(db *gorm.DB) *IncidentTimelineService {
+	return &IncidentTimelineService{db: db}
+}
+
+// RecordEvent appends event to its incident's timeline. OccurredAt defaults
+// to now.
+func (s *IncidentTimelineService) RecordEvent(event database.IncidentEvent) {
+	if event.IncidentUUID == "" {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	event.Summary = truncateForPrompt(event.Summary, maxTimelineSummaryRunes)
+	if err := s.db.Create(&event).Error; err != nil {
+		slog.Warn("failed to record incident timeline event", "incident_uuid", event.IncidentUUID, "type", event.Type, "err", err)
+	}
+}
+
+// PublishStatus records a status transition, skipping repeats of the
+// incident's current status (e.g. a resumed run re-marked running).
+func (s *IncidentTimelineService) PublishStatus(incidentUUID string, status database.IncidentStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last []database.IncidentEvent
+	if err := s.db.Where("incident_uuid = ? AND type = ?", incidentUUID, database.IncidentEventStatusChange).
+		Order("occurred_at DESC, id DESC").Limit(1).Find(&last).Error; err != nil {
+		slog.Warn("failed to load incident timeline", "incident_uuid", incidentUUID, "err", err)
+		return
+	}
+	if len(last) > 0 && last[0].Details["status"] == string(status) {
+		return
+	}
+	s.RecordEvent(database.IncidentEvent{
+		IncidentUUID: incidentUUID,
+		Type:         database.IncidentEventStatusChange,
+		Summary:      "Status changed to " + string(status),
+		Details:      database.JSONB{"status": string(status)},
+		Actor:        "system",
+	})
+}
+
+// PublishLog records a command event for every tool call in fullLog that is
+// not on the timeline yet. Each call is timestamped when its log line is
+// first seen, which is as precise as the progress updates are frequent.
+func (s *IncidentTimelineService) PublishLog(incidentUUID, fullLog string) {
+	calls := utils.ParseToolCalls(fullLog)
+	if len(calls) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recorded int64
+	if err := s.db.Model(&database.IncidentEvent{}).
+		Where("incident_uuid = ? AND type = ?", incidentUUID, database.IncidentEventCommand).
+		Count(&recorded).Error; err != nil {
+		slog.Warn("failed to load incident timeline", "incident_uuid", incidentUUID, "err", err)
+		return
+	}
+	for _, call := range calls[min(int(recorded), len(calls)):] {
+		summary := "Ran " + call.Command
+		if call.Failed {
+			summary = "Failed " + call.Command
+		}
+		command := call.Command
+		if len(command) > maxTimelineCommandBytes {
+			command = command[:maxTimelineCommandBytes]
+		}
+		s.RecordEvent(database.IncidentEvent{
+			IncidentUUID: incidentUUID,
+			Type:         database.IncidentEventCommand,
+			Summary:      summary,
+			Details:      database.JSONB{"command": command, "failed": call.Failed},
+			Actor:        "agent",
+		})
+	}
+}
+
+// ListEvents returns the incident's timeline, oldest first. It returns
+// gorm.ErrRecordNotFound when the incident does not exist.
+func (s *IncidentTimelineService) ListEvents(ctx context.Context, incidentUUID string) ([]database.IncidentEvent, error) {
+	if err := s.requireIncident(ctx, incidentUUID); err != nil {
+		return nil, err
+	}
+	events := []database.IncidentEvent{}
+	if err := s.db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).
+		Order("occurred_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("list incident events: %w", err)
+	}
+	return events, nil
+}
+
+// AddNote appends an operator note to the incident's timeline.
+func (s *IncidentTimelineService) AddNote(ctx context.Context, incidentUUID, author, text string) (*database.IncidentEvent, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidTimelineNote)
+	}
+	if len(text) > MaxTimelineNoteBytes {
+		return nil, fmt.Errorf("%w: text exceeds %d bytes", ErrInvalidTimelineNote, MaxTimelineNoteBytes)
+	}
+	if err := s.requireIncident(ctx, incidentUUID); err != nil {
+		return nil, err
+	}
+	if author == "" {
+		author = "user"
+	}
+	event := database.IncidentEvent{
+		IncidentUUID: incidentUUID,
+		Type:         database.IncidentEventNote,
+		Summary:      truncateForPrompt(firstLine(text), maxTimelineSummaryRunes),
+		Details:      database.JSONB{"text": text},
+		Actor:        author,
+		OccurredAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&event).Error; err != nil {
+		return nil, fmt.Errorf("add timeline note: %w", err)
+	}
+	return &event, nil
+}
+
+func (s *IncidentTimelineService) requireIncident(ctx context.Context, incidentUUID string) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&database.Incident{}).Where("uuid = ?", incidentUUID).Count(&count).Error; err != nil {
+		return fmt.Errorf("load incident: %w", err)
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// alertAttachedEvent is the timeline entry for an alert recorded against an
+// incident; decision is the correlator's verdict ("new_incident", "linked",
+// "moved", ...).
+func alertAttachedEvent(incidentUUID, alertUUID, alertName, targetHost, decision string) database.IncidentEvent {
+	summary := "Alert " + alertName
+	if targetHost != "" {
+		summary += " on " + targetHost
+	}
+	return database.IncidentEvent{
+		IncidentUUID: incidentUUID,
+		Type:         database.IncidentEventAlertAttached,
+		Summary:      summary + " attached",
+		Details:      database.JSONB{"alert_uuid": alertUUID, "alert_name": alertName, "target_host": targetHost, "decision": decision},
+		Actor:        "system",
+	}
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+func newTestTimelineService(t *testing.T, incidentUUIDs ...string) (*IncidentTimelineService, *gorm.DB) {
+	t.Helper()
+	db := setupIncidentTestDB(t)
+	if err := db.AutoMigrate(&database.IncidentEvent{}); err != nil {
+		t.Fatalf("migrate incident events: %v", err)
+	}
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&database.IncidentEvent{})
+	for _, id := range incidentUUIDs {
+		db.Where("uuid = ?", id).Delete(&database.Incident{})
+		if err := db.Create(&database.Incident{UUID: id, Title: "Incident " + id, Status: database.IncidentStatusPending}).Error; err != nil {
+			t.Fatalf("seed incident %s: %v", id, err)
+		}
+	}
+	return NewIncidentTimelineService(db), db
+}
+
+func timelineTypes(events []database.IncidentEvent) []string {
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = string(e.Type)
+	}
+	return types
+}
+
+func TestIncidentTimeline_StatusAndCommands(t *testing.T) {
+	svc, _ := newTestTimelineService(t, "tl-run")
+	ctx := context.Background()
+
+	svc.PublishStatus("tl-run", database.IncidentStatusRunning)
+	svc.PublishStatus("tl-run", database.IncidentStatusRunning) // resumed run: not a transition
+	svc.PublishLog("tl-run", "Thinking...\n✅ Ran: kubectl get pods\n")
+	svc.PublishLog("tl-run", "Thinking...\n✅ Ran: kubectl get pods\n❌ Failed: kubectl logs api-0\n")
+	svc.PublishLog("tl-run", "Thinking...\n✅ Ran: kubectl get pods\n❌ Failed: kubectl logs api-0\nDone.")
+	svc.PublishStatus("tl-run", database.IncidentStatusCompleted)
+
+	events, err := svc.ListEvents(ctx, "tl-run")
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	want := "status_change,command,command,status_change"
+	if got := strings.Join(timelineTypes(events), ","); got != want {
+		t.Fatalf("event types = %s, want %s", got, want)
+	}
+	if events[1].Summary != "Ran kubectl get pods" || events[1].Actor != "agent" {
+		t.Errorf("first command = %+v", events[1])
+	}
+	if events[2].Details["command"] != "kubectl logs api-0" || events[2].Details["failed"] != true {
+		t.Errorf("failed command details = %+v", events[2].Details)
+	}
+	if events[3].Details["status"] != string(database.IncidentStatusCompleted) {
+		t.Errorf("final status details = %+v", events[3].Details)
+	}
+}
+
+func TestIncidentTimeline_Notes(t *testing.T) {
+	svc, _ := newTestTimelineService(t, "tl-note")
+	ctx := context.Background()
+
+	event, err := svc.AddNote(ctx, "tl-note", "alice", "  Rolled back deploy 42\nwatching error rate  ")
+	if err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	if event.Type != database.IncidentEventNote || event.Actor != "alice" || event.Summary != "Rolled back deploy 42" {
+		t.Errorf("note = %+v", event)
+	}
+	if event.Details["text"] != "Rolled back deploy 42\nwatching error rate" {
+		t.Errorf("note text = %q", event.Details["text"])
+	}
+	if event, _ := svc.AddNote(ctx, "tl-note", "", "anonymous"); event.Actor != "user" {
+		t.Errorf("default actor = %q", event.Actor)
+	}
+
+	if _, err := svc.AddNote(ctx, "tl-note", "alice", "   "); !errors.Is(err, ErrInvalidTimelineNote) {
+		t.Errorf("empty note err = %v", err)
+	}
+	if _, err := svc.AddNote(ctx, "tl-note", "alice", strings.Repeat("x", MaxTimelineNoteBytes+1)); !errors.Is(err, ErrInvalidTimelineNote) {
+		t.Errorf("oversized note err = %v", err)
+	}
+	if _, err := svc.AddNote(ctx, "tl-missing", "alice", "hi"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("missing incident note err = %v", err)
+	}
+	if _, err := svc.ListEvents(ctx, "tl-missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("missing incident list err = %v", err)
+	}
+}
+
+func TestIncidentTimeline_RecordsAttachedAlerts(t *testing.T) {
+	svc, db := newTestTimelineService(t, "tl-alerts")
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&database.Alert{})
+	skills := newIncidentTestService(t, db)
+	skills.SetIncidentTimeline(svc)
+	ctx := context.Background()
+
+	first := alerts.NormalizedAlert{AlertName: "HighCPU", TargetHost: "web-1"}
+	if err := skills.InsertFiringAlert(ctx, "tl-alerts", "src-tl", first, "new_incident", ""); err != nil {
+		t.Fatalf("InsertFiringAlert: %v", err)
+	}
+	second := alerts.NormalizedAlert{AlertName: "HighLoad", TargetHost: "web-1"}
+	if err := skills.LinkAlertToIncident(ctx, "tl-alerts", "src-tl", second, 0.9, "same host"); err != nil {
+		t.Fatalf("LinkAlertToIncident: %v", err)
+	}
+
+	events, err := svc.ListEvents(ctx, "tl-alerts")
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].Summary != "Alert HighCPU on web-1 attached" || events[0].Details["decision"] != "new_incident" {
+		t.Errorf("first alert event = %+v", events[0])
+	}
+	if events[1].Details["alert_name"] != "HighLoad" || events[1].Details["decision"] != "linked" {
+		t.Errorf("linked alert event = %+v", events[1])
+	}
+}
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"archive/zip"
 	"context"
 	"io"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/alerts"
 	"github.com/akmatori/akmatori/internal/database"
 	"github.com/akmatori/akmatori/internal/messaging"
+	"github.com/akmatori/akmatori/internal/output"
 )
 
 // SkillManager defines the interface for skill CRUD and lifecycle operations.
@@ -40,6 +43,7 @@ type IncidentManager interface {
 	UpdateIncidentStatus(incidentUUID string, status database.IncidentStatus, sessionID string, fullLog string) error
 	UpdateIncidentComplete(incidentUUID string, status database.IncidentStatus, sessionID string, fullLog string, response string, tokensUsed int, executionTimeMs int64) error
 	UpdateIncidentLog(incidentUUID string, fullLog string) error
+	ReadIncidentLog(incidentUUID string, offset, limit int) (string, int, error)
 	GetIncident(incidentUUID string) (*database.Incident, error)
 	AppendSubagentLog(incidentUUID string, skillName string, subagentLog string) error
 	InsertFiringAlert(ctx context.Context, incidentUUID string, sourceUUID string, alert alerts.NormalizedAlert, decision, reasoning string) error
@@ -48,6 +52,9 @@ type IncidentManager interface {
 	MoveAlertToIncident(ctx context.Context, alertUUID, targetIncidentUUID string) (string, error)
 	ResolveAlert(ctx context.Context, alertUUID string) error
 	CloseIncident(ctx context.Context, incidentUUID string, confirm bool) error
+	CancelIncident(ctx context.Context, incidentUUID, cancelledBy string) error
+	BeginFollowUp(incidentUUID string, logHeader string) (*database.Incident, error)
+	BeginRetry(incidentUUID, retriedBy string) (*database.Incident, error)
 }
 
 // SkillIncidentManager combines SkillManager and IncidentManager for handlers
@@ -67,9 +74,12 @@ type ToolManager interface {
 	ListToolInstances() ([]database.ToolInstance, error)
 	EnsureToolTypes() error
 	GetSSHKeys(toolInstanceID uint) ([]SSHKeyEntry, error)
-	AddSSHKey(toolInstanceID uint, name string, privateKey string, setAsDefault bool) (*SSHKeyEntry, error)
+	AddSSHKey(toolInstanceID uint, name string, privateKey string, passphrase string, setAsDefault bool) (*SSHKeyEntry, error)
 	UpdateSSHKey(toolInstanceID uint, keyID string, name *string, setAsDefault *bool) (*SSHKeyEntry, error)
 	DeleteSSHKey(toolInstanceID uint, keyID string) error
+	ListSSHKnownHosts(toolInstanceID uint) ([]database.SSHKnownHost, error)
+	ClearSSHKnownHosts(toolInstanceID uint, host string) (int64, error)
+	DeleteSSHKnownHost(toolInstanceID uint, knownHostID uint) error
 }
 
 // AlertManager defines the interface for alert source operations.
@@ -87,6 +97,8 @@ type AlertManager interface {
 	CreateInstanceByTypeID(sourceTypeID uint, name, description, webhookSecret string, fieldMappings, settings database.JSONB) (*database.AlertSourceInstance, error)
 	UpdateInstance(uuid string, updates map[string]interface{}) error
 	UpdateInstanceByID(id uint, name, description, webhookSecret string, fieldMappings, settings database.JSONB, enabled bool) error
+	MarkInstanceAlertReceived(id uint, at time.Time) error
+	SetInstanceSkills(uuid string, skillNames []string) error
 	DeleteInstance(uuid string) error
 	DeleteInstanceByID(id uint) error
 	InitializeDefaultSourceTypes() error
@@ -209,6 +221,282 @@ type ProposalManager interface {
 	ChatToolAllowlist() []ToolAllowlistEntry
 }
 
+// AuditRecorder appends entries to the security audit log. Satisfied by
+// *AuditService; handlers record best-effort and never fail a request on a
+// recorder error.
+type AuditRecorder interface {
+	Record(entry *database.AuditLog) error
+}
+
+// AlertSeverityInferrer assigns a severity to alerts that arrived without
+// one. Satisfied by *SeverityInferrer.
+type AlertSeverityInferrer interface {
+	InferSeverity(ctx context.Context, alert alerts.NormalizedAlert, useLLM bool) (database.AlertSeverity, string, bool)
+}
+
+// AuditLogReader queries the audit log for GET /api/audit. Satisfied by
+// *AuditService.
+type AuditLogReader interface {
+	ListAuditLogs(ctx context.Context, filter AuditLogFilter) ([]database.AuditLog, error)
+}
+
+// UsageReader rolls up recorded token usage for GET /api/usage. Satisfied
+// by *UsageService.
+type UsageReader interface {
+	Summary(ctx context.Context, filter UsageFilter) (*UsageSummary, error)
+}
+
+// RetentionRunner runs retention cleanup on demand and reports workspace
+// disk usage for /api/settings/retention. Satisfied by *RetentionService.
+type RetentionRunner interface {
+	RunCleanup() (*CleanupResult, error)
+	WorkspaceUsage() (*WorkspaceUsage, error)
+}
+
+// SkillBundler packages skills as portable bundles for GET
+// /api/skills/{name}/export and POST /api/skills/import. Satisfied by
+// *SkillService.
+type SkillBundler interface {
+	ExportSkillBundle(name, version string) (*SkillBundle, error)
+	InstallSkillBundle(bundle *SkillBundle) (*SkillInstallResult, error)
+}
+
+// SkillTester prepares the ephemeral workspaces of skill dry runs. Satisfied
+// by *SkillService.
+type SkillTester interface {
+	PrepareSkillDryRun(name string) (*SkillDryRun, error)
+}
+
+// IncidentExporter assembles incident exports for GET
+// /api/incidents/{uuid}/export and the bulk archive. Satisfied by
+// *IncidentExportService.
+type IncidentExporter interface {
+	Export(ctx context.Context, incidentUUID string) (*IncidentExport, error)
+	ExportIncident(ctx context.Context, incident *database.Incident) (*IncidentExport, error)
+	WriteArchive(zw *zip.Writer, export *IncidentExport, workspace bool) error
+}
+
+// ComplianceReporter lists the remote writes agents executed for GET
+// /api/compliance/actions. Satisfied by *ComplianceService.
+type ComplianceReporter interface {
+	Actions(ctx context.Context, filter ComplianceFilter) (*ComplianceReport, error)
+}
+
+// BudgetGuard decides whether a new agent run fits the LLM budgets.
+// Satisfied by *BudgetService.
+type BudgetGuard interface {
+	// CheckBudget returns an error wrapping ErrBudgetExceeded once a budget
+	// is spent; nil otherwise, including when the check itself fails.
+	CheckBudget(ctx context.Context) error
+	BudgetStatus(ctx context.Context) (*BudgetStatus, error)
+}
+
+// HealthSignalRecorder receives the signals the self-monitor judges Akmatori's
+// own health by. Satisfied by *SelfMonitor; handlers record best-effort and
+// never block on it.
+type HealthSignalRecorder interface {
+	RecordWebhook(failed bool)
+	RecordAgentResult(errMsg string)
+}
+
+// IncidentLinker manages parent/child and related links between incidents.
+// Satisfied by *IncidentLinkService.
+type IncidentLinker interface {
+	LinkIncidents(ctx context.Context, incidentUUID, targetUUID, kind, origin, reason string) (*database.IncidentLink, error)
+	UnlinkIncidents(ctx context.Context, incidentUUID, targetUUID string) error
+	GetRelations(ctx context.Context, incidentUUID string) (*database.IncidentRelations, error)
+	ActiveParent(ctx context.Context, incidentUUID string) (*database.Incident, error)
+}
+
+// SnippetExporter promotes commands and queries from an incident into a
+// skill. Satisfied by *SnippetService.
+type SnippetExporter interface {
+	ExportSnippet(ctx context.Context, incidentUUID string, snippet IncidentSnippet) (*database.SkillSnippet, error)
+	ListSnippets(ctx context.Context, incidentUUID string) ([]database.SkillSnippet, error)
+}
+
+// IncidentTimelineRecorder appends events to an incident's timeline.
+// Satisfied by *IncidentTimelineService; recording is best-effort.
+type IncidentTimelineRecorder interface {
+	RecordEvent(event database.IncidentEvent)
+}
+
+// IncidentTimeline reads an incident's timeline and adds operator notes.
+// Satisfied by *IncidentTimelineService.
+type IncidentTimeline interface {
+	ListEvents(ctx context.Context, incidentUUID string) ([]database.IncidentEvent, error)
+	AddNote(ctx context.Context, incidentUUID, author, text string) (*database.IncidentEvent, error)
+}
+
+// PostmortemReporter generates and reads incident postmortems.
+// Satisfied by *PostmortemGenerator.
+type PostmortemReporter interface {
+	GenerateReport(ctx context.Context, incidentUUID, author string) (*database.IncidentReport, error)
+	GetReport(ctx context.Context, incidentUUID string) (*database.IncidentReport, error)
+}
+
+// ApprovalManager lists tool approvals and records decisions on them.
+// Satisfied by *ApprovalService.
+type ApprovalManager interface {
+	ListApprovals(ctx context.Context, status, incidentUUID string) ([]database.ToolApproval, error)
+	Decide(ctx context.Context, approvalUUID string, approve bool, decidedBy, reason string) (*database.ToolApproval, error)
+}
+
+// RemediationPlanManager reads an incident's proposed remediation plan and
+// records the decision on it. Satisfied by *RemediationPlanService.
+type RemediationPlanManager interface {
+	GetPlan(ctx context.Context, incidentUUID string) (*database.RemediationPlan, error)
+	DecidePlan(ctx context.Context, incidentUUID string, approve bool, decidedBy, reason string) (*database.RemediationPlan, error)
+}
+
+// RemediationPlanner stores the plan a two-phase investigation proposed and
+// asks for approval. Satisfied by *RemediationPlanService.
+type RemediationPlanner interface {
+	ProposePlan(ctx context.Context, incidentUUID, response string) (*database.RemediationPlan, error)
+}
+
+// ConfidenceRouter stores an investigation result's confidence and decides
+// whether it needs a human review or can close the incident. Satisfied by
+// *ConfidenceService.
+type ConfidenceRouter interface {
+	RouteByConfidence(ctx context.Context, incidentUUID string, confidence *output.Confidence, severity database.AlertSeverity) (ConfidenceDecision, error)
+}
+
+// ConfidenceStatsReader reports how investigation confidence compares with
+// outcomes. Satisfied by *ConfidenceService.
+type ConfidenceStatsReader interface {
+	Calibration(ctx context.Context, since, until *time.Time) (*ConfidenceCalibration, error)
+}
+
+// AlertBaselineReader lists weekly alert volume baselines. Satisfied by
+// *AlertBaselineService.
+type AlertBaselineReader interface {
+	ListBaselines(ctx context.Context, deviatingOnly bool) ([]database.AlertBaseline, error)
+}
+
+// LLMHealthProber probes an LLM configuration's endpoint. Satisfied by
+// *LLMHealthChecker.
+type LLMHealthProber interface {
+	CheckLLMHealth(ctx context.Context, settings *database.LLMSettings) LLMHealth
+}
+
+// SilenceManager manages maintenance-window silences and lists the alerts
+// they suppressed. Satisfied by *SilenceService.
+type SilenceManager interface {
+	ListSilences(ctx context.Context, state string) ([]database.Silence, error)
+	GetSilence(ctx context.Context, silenceUUID string) (*database.Silence, error)
+	CreateSilence(ctx context.Context, silence *database.Silence) error
+	UpdateSilence(ctx context.Context, silenceUUID string, update *database.Silence) (*database.Silence, error)
+	ExpireSilence(ctx context.Context, silenceUUID string) (*database.Silence, error)
+	ListSuppressedAlerts(ctx context.Context, silenceUUID string) ([]database.SuppressedAlert, error)
+}
+
+// AlertSilencer decides whether a firing alert falls in a maintenance
+// window. Satisfied by *SilenceService.
+type AlertSilencer interface {
+	SilenceAlert(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (*database.Silence, error)
+}
+
+// AlertDeduplicator finds the open incident a firing alert repeats by its
+// source fingerprint. Satisfied by *SkillService.
+type AlertDeduplicator interface {
+	FindOpenIncidentBySourceFingerprint(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (string, error)
+}
+
+// RoutingRuleManager manages the rules that route firing alerts. Satisfied
+// by *RoutingRuleService.
+type RoutingRuleManager interface {
+	ListRoutingRules(ctx context.Context) ([]database.RoutingRule, error)
+	GetRoutingRule(ctx context.Context, ruleUUID string) (*database.RoutingRule, error)
+	CreateRoutingRule(ctx context.Context, rule *database.RoutingRule, channelUUID string, skillNames []string) error
+	UpdateRoutingRule(ctx context.Context, ruleUUID string, update *database.RoutingRule, channelUUID string, skillNames []string) (*database.RoutingRule, error)
+	DeleteRoutingRule(ctx context.Context, ruleUUID string) error
+}
+
+// AlertRouter picks the routing rule that applies to a firing alert.
+// Satisfied by *RoutingRuleService.
+type AlertRouter interface {
+	MatchRoutingRule(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (*database.RoutingRule, error)
+}
+
+// ApprovalNotifier posts approval requests where a human can act on them
+// and updates them once decided. NotifyApproval returns the channel and
+// message the request was posted as; empty when the incident has nowhere to
+// post.
+type ApprovalNotifier interface {
+	NotifyApproval(ctx context.Context, approval *database.ToolApproval, incident *database.Incident) (channelID, messageTS string, err error)
+	ResolveApproval(ctx context.Context, approval *database.ToolApproval) error
+}
+
+// PlanNotifier posts remediation plans for approval and updates the post
+// once decided. NotifyPlan returns the channel and message the plan was
+// posted as; empty when the incident has nowhere to post.
+type PlanNotifier interface {
+	NotifyPlan(ctx context.Context, plan *database.RemediationPlan, incident *database.Incident) (channelID, messageTS string, err error)
+	ResolvePlan(ctx context.Context, plan *database.RemediationPlan) error
+}
+
+// PlanExecutor starts the second phase of a two-phase investigation: it
+// resumes the incident's agent session and asks it to carry out the
+// approved plan. Satisfied by *handlers.APIHandler.
+type PlanExecutor interface {
+	ExecutePlan(plan *database.RemediationPlan) error
+}
+
+// IncidentCanceller stops a running investigation. Satisfied by
+// *handlers.AgentWSHandler.
+type IncidentCanceller interface {
+	CancelIncident(incidentID string) error
+}
+
+// LeaderStatus reports whether this API replica is the elected leader, the
+// one the agent worker and alert webhooks must reach. Satisfied by
+// *LeaderElector.
+type LeaderStatus interface {
+	IsLeader() bool
+}
+
+// AlertSourceProvisioner configures a Zabbix server to deliver alerts to a
+// Zabbix alert source. Satisfied by *ZabbixProvisioner.
+type AlertSourceProvisioner interface {
+	ProvisionAlertSource(ctx context.Context, sourceUUID string, req ZabbixProvisionRequest) (*ZabbixProvisionResult, error)
+}
+
+// NotificationTemplateManager defines the interface for notification
+// template override CRUD. Consumed by the API handler.
+type NotificationTemplateManager interface {
+	ListTemplates() ([]database.NotificationTemplate, error)
+	GetTemplate(id uint) (*database.NotificationTemplate, error)
+	CreateTemplate(t *database.NotificationTemplate) error
+	UpdateTemplate(id uint, body string, enabled bool) (*database.NotificationTemplate, error)
+	DeleteTemplate(id uint) error
+	EffectiveBody(kind NotificationKind, locale string) string
+}
+
+// PromptTemplateManager manages investigation prompt templates for
+// /api/settings/prompts. Satisfied by *PromptTemplateService.
+type PromptTemplateManager interface {
+	ListTemplates() ([]database.PromptTemplate, error)
+	GetTemplate(id uint) (*database.PromptTemplate, error)
+	CreateTemplate(t *database.PromptTemplate) error
+	UpdateTemplate(id uint, body string, enabled bool) (*database.PromptTemplate, error)
+	DeleteTemplate(id uint) error
+	EffectiveBody(sourceType string, skills []string) string
+	PromptRenderer
+}
+
+// PromptRenderer renders investigation prompts, applying any operator
+// prompt template. Satisfied by *PromptTemplateService.
+type PromptRenderer interface {
+	RenderPrompt(data InvestigationPromptData, sourceType string, skills []string) string
+}
+
+// NotificationRenderer renders outbound notification text, applying any
+// operator template override. Satisfied by *NotificationTemplateService.
+type NotificationRenderer interface {
+	RenderNotification(kind NotificationKind, data NotificationData) string
+}
+
 // MCPServerManager defines the interface for MCP server configuration CRUD operations.
 type MCPServerManager interface {
 	CreateMCPServer(config *database.MCPServerConfig) (*database.MCPServerConfig, error)
@@ -217,3 +505,17 @@ type MCPServerManager interface {
 	DeleteMCPServer(id uint) error
 	ListMCPServers() ([]database.MCPServerConfig, error)
 }
+
+// ToolSettingsValidator checks tool instance settings against the settings
+// schema of their tool type, returning the failing settings or nil.
+// Satisfied by *ToolSchemaClient.
+type ToolSettingsValidator interface {
+	ValidateToolSettings(ctx context.Context, toolType string, settings database.JSONB) map[string]string
+}
+
+// SkillMarketplace browses a remote skill index and installs signed bundles
+// from it. Satisfied by *MarketplaceClient.
+type SkillMarketplace interface {
+	Index(ctx context.Context) (*MarketplaceIndex, error)
+	InstallSkill(ctx context.Context, name string) (*SkillInstallResult, error)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// InvestigationPriority orders queued alert investigations. Higher values run
+// first; only InvestigationPriorityCritical may preempt running work.
+type InvestigationPriority int
+
+const (
+	InvestigationPriorityLow InvestigationPriority = iota
+	InvestigationPriorityNormal
+	InvestigationPriorityHigh
+	InvestigationPriorityCritical
+)
+
+// InvestigationPriorityForSeverity maps a normalized alert severity to a
+// scheduling priority. Unknown and informational alerts are lowest.
+func InvestigationPriorityForSeverity(severity database.AlertSeverity) InvestigationPriority {
+	switch severity {
+	case database.AlertSeverityCritical:
+		return InvestigationPriorityCritical
+	case database.AlertSeverityHigh:
+		return InvestigationPriorityHigh
+	case database.AlertSeverityWarning:
+		return InvestigationPriorityNormal
+	default:
+		return InvestigationPriorityLow
+	}
+}
+
+// InvestigationScheduler bounds how many alert investigations run on the
+// agent worker at once. Excess work waits in a priority queue (priority, then
+// arrival order). When every slot is taken and a critical investigation is
+// waiting, the lowest-priority running investigation is asked to pause via
+// its slot's Preempted channel; the holder cancels its run, calls Requeue,
+// and resumes its agent session once a slot frees up again.
+//
+// A limit of zero or less disables queueing: every slot is granted
+// immediately and nothing is ever preempted.
+//
+// An optional per-source limit (SetMaxPerSource) additionally caps how many
+// investigations one alert source runs at once, so a flapping source cannot
+// take every slot. Its excess waits without preempting anything.
+type InvestigationScheduler struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxPerSource  int
+	seq           uint64
+	dispatches    uint64
+	running       map[*InvestigationSlot]struct{}
+	waiting       []*InvestigationSlot
+	perSource     map[string]int // running slots by source UUID
+}
+
+// NewInvestigationScheduler creates a scheduler allowing maxConcurrent
+// simultaneous investigations (<= 0 means unlimited).
+func NewInvestigationScheduler(maxConcurrent int) *InvestigationScheduler {
+	return &InvestigationScheduler{
+		maxConcurrent: maxConcurrent,
+		running:       make(map[*InvestigationSlot]struct{}),
+		perSource:     make(map[string]int),
+	}
+}
+
+// SetMaxPerSource caps concurrent investigations per alert source
+// (<= 0 means no per-source cap).
+func (s *InvestigationScheduler) SetMaxPerSource(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPerSource = n
+	s.rebalanceLocked()
+}
+
+// InvestigationSlot is one investigation's claim on the scheduler.
+type InvestigationSlot struct {
+	IncidentUUID string
+	SourceUUID   string
+	Priority     InvestigationPriority
+
+	s         *InvestigationScheduler
+	seq       uint64 // arrival order, kept across Requeue
+	started   uint64 // dispatch order of the current run
+	granted   chan struct{}
+	preempted chan struct{}
+	pausing   bool // preemption requested and not yet requeued
+	released  bool
+}
+
+// Enqueue registers an investigation and returns its slot without blocking.
+// The slot may already be granted; use Wait to block until it is.
+func (s *InvestigationScheduler) Enqueue(incidentUUID string, priority InvestigationPriority) *InvestigationSlot {
+	return s.EnqueueForSource(incidentUUID, "", priority)
+}
+
+// EnqueueForSource is Enqueue for an investigation started by the given
+// alert source, which counts against the per-source limit. An empty
+// sourceUUID is never capped.
+func (s *InvestigationScheduler) EnqueueForSource(incidentUUID, sourceUUID string, priority InvestigationPriority) *InvestigationSlot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	slot := &InvestigationSlot{
+		IncidentUUID: incidentUUID,
+		SourceUUID:   sourceUUID,
+		Priority:     priority,
+		s:            s,
+		seq:          s.seq,
+		granted:      make(chan struct{}),
+		preempted:    make(chan struct{}),
+	}
+	s.waiting = append(s.waiting, slot)
+	s.rebalanceLocked()
+	return slot
+}
+
+// Stats returns the number of running and waiting investigations.
+func (s *InvestigationScheduler) Stats() (running, waiting int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.running), len(s.waiting)
+}
+
+// Granted reports whether the slot currently holds a run permit.
+func (slot *InvestigationSlot) Granted() bool {
+	select {
+	case <-slot.grantedChan():
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the slot is granted or ctx is done. On ctx cancellation
+// the slot is released and ctx.Err() is returned.
+func (slot *InvestigationSlot) Wait(ctx context.Context) error {
+	select {
+	case <-slot.grantedChan():
+		return nil
+	case <-ctx.Done():
+		slot.Release()
+		return ctx.Err()
+	}
+}
+
+// Preempted is closed when the scheduler wants this running investigation
+// paused to make room for a critical one. The channel is replaced on
+// Requeue, so read it again after resuming.
+func (slot *InvestigationSlot) Preempted() <-chan struct{} {
+	slot.s.mu.Lock()
+	defer slot.s.mu.Unlock()
+	return slot.preempted
+}
+
+// Requeue gives up the slot's run permit and waits for a new one. The slot
+// keeps its original arrival order so a paused investigation resumes ahead
+// of newer work at the same priority.
+func (slot *InvestigationSlot) Requeue(ctx context.Context) error {
+	s := slot.s
+	s.mu.Lock()
+	if slot.released {
+		s.mu.Unlock()
+		return context.Canceled
+	}
+	s.stopRunningLocked(slot)
+	slot.granted = make(chan struct{})
+	slot.preempted = make(chan struct{})
+	slot.pausing = false
+	s.waiting = append(s.waiting, slot)
+	s.rebalanceLocked()
+	s.mu.Unlock()
+
+	return slot.Wait(ctx)
+}
+
+// Release frees the slot (running or waiting). Safe to call more than once.
+func (slot *InvestigationSlot) Release() {
+	s := slot.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slot.released {
+		return
+	}
+	slot.released = true
+	s.stopRunningLocked(slot)
+	for i, w := range s.waiting {
+		if w == slot {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			break
+		}
+	}
+	s.rebalanceLocked()
+}
+
+func (slot *InvestigationSlot) grantedChan() <-chan struct{} {
+	slot.s.mu.Lock()
+	defer slot.s.mu.Unlock()
+	return slot.granted
+}
+
+// rebalanceLocked grants free permits to the best waiters, then requests
+// enough preemptions to make room for every waiting critical investigation.
+// Caller must hold s.mu.
+func (s *InvestigationScheduler) rebalanceLocked() {
+	for s.maxConcurrent <= 0 || len(s.running) < s.maxConcurrent {
+		best := s.bestWaiterLocked()
+		if best < 0 {
+			break
+		}
+		slot := s.waiting[best]
+		s.waiting = append(s.waiting[:best], s.waiting[best+1:]...)
+		s.running[slot] = struct{}{}
+		s.dispatches++
+		slot.started = s.dispatches
+		if slot.SourceUUID != "" {
+			s.perSource[slot.SourceUUID]++
+		}
+		close(slot.granted)
+	}
+	if s.maxConcurrent <= 0 {
+		return
+	}
+
+	criticalWaiting, pausing := 0, 0
+	for _, w := range s.waiting {
+		if w.Priority == InvestigationPriorityCritical && !s.sourceFullLocked(w) {
+			criticalWaiting++
+		}
+	}
+	for r := range s.running {
+		if r.pausing {
+			pausing++
+		}
+	}
+	for ; pausing < criticalWaiting; pausing++ {
+		victim := s.preemptionVictimLocked()
+		if victim == nil {
+			return
+		}
+		victim.pausing = true
+		close(victim.preempted)
+	}
+}
+
+// bestWaiterLocked returns the index of the highest-priority, earliest waiter
+// whose source is below its limit, or -1 when no waiter can start.
+func (s *InvestigationScheduler) bestWaiterLocked() int {
+	best := -1
+	for i, w := range s.waiting {
+		if s.sourceFullLocked(w) {
+			continue
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		b := s.waiting[best]
+		if w.Priority > b.Priority || (w.Priority == b.Priority && w.seq < b.seq) {
+			best = i
+		}
+	}
+	return best
+}
+
+// sourceFullLocked reports whether the slot's source already runs its
+// per-source limit of investigations.
+func (s *InvestigationScheduler) sourceFullLocked(slot *InvestigationSlot) bool {
+	return s.maxPerSource > 0 && slot.SourceUUID != "" && s.perSource[slot.SourceUUID] >= s.maxPerSource
+}
+
+// stopRunningLocked removes the slot from the running set, if present.
+func (s *InvestigationScheduler) stopRunningLocked(slot *InvestigationSlot) {
+	if _, ok := s.running[slot]; !ok {
+		return
+	}
+	delete(s.running, slot)
+	if slot.SourceUUID != "" {
+		if s.perSource[slot.SourceUUID]--; s.perSource[slot.SourceUUID] <= 0 {
+			delete(s.perSource, slot.SourceUUID)
+		}
+	}
+}
+
+// preemptionVictimLocked picks the running, non-critical investigation with
+// the lowest priority, preferring the most recently started among equals so
+// the least work is interrupted. Start order, not arrival order: a slot held
+// back by its source limit or resumed after a pause can start well after
+// newer work. Returns nil when none qualifies.
+func (s *InvestigationScheduler) preemptionVictimLocked() *InvestigationSlot {
+	var victim *InvestigationSlot
+	for r := range s.running {
+		if r.pausing || r.Priority >= InvestigationPriorityCritical {
+			continue
+		}
+		if victim == nil || r.Priority < victim.Priority || (r.Priority == victim.Priority && r.started > victim.started) {
+			victim = r
+		}
+	}
+	return victim
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func isPreempted(slot *InvestigationSlot) bool {
+	select {
+	case <-slot.Preempted():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestInvestigationPriorityForSeverity(t *testing.T) {
+	tests := []struct {
+		severity database.AlertSeverity
+		want     InvestigationPriority
+	}{
+		{database.AlertSeverityCritical, InvestigationPriorityCritical},
+		{database.AlertSeverityHigh, InvestigationPriorityHigh},
+		{database.AlertSeverityWarning, InvestigationPriorityNormal},
+		{database.AlertSeverityInfo, InvestigationPriorityLow},
+		{"", InvestigationPriorityLow},
+	}
+	for _, tt := range tests {
+		if got := InvestigationPriorityForSeverity(tt.severity); got != tt.want {
+			t.Errorf("InvestigationPriorityForSeverity(%q) = %d, want %d", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestInvestigationScheduler_UnlimitedGrantsImmediately(t *testing.T) {
+	s := NewInvestigationScheduler(0)
+	for i := 0; i < 5; i++ {
+		slot := s.Enqueue("inc", InvestigationPriorityLow)
+		if !slot.Granted() {
+			t.Fatalf("slot %d not granted with unlimited scheduler", i)
+		}
+	}
+	crit := s.Enqueue("crit", InvestigationPriorityCritical)
+	if !crit.Granted() {
+		t.Fatal("critical slot not granted")
+	}
+	if running, waiting := s.Stats(); running != 6 || waiting != 0 {
+		t.Errorf("Stats() = %d, %d; want 6, 0", running, waiting)
+	}
+}
+
+func TestInvestigationScheduler_QueueOrder(t *testing.T) {
+	s := NewInvestigationScheduler(1)
+	first := s.Enqueue("first", InvestigationPriorityLow)
+	low := s.Enqueue("low", InvestigationPriorityLow)
+	high1 := s.Enqueue("high-1", InvestigationPriorityHigh)
+	high2 := s.Enqueue("high-2", InvestigationPriorityHigh)
+
+	if !first.Granted() || low.Granted() || high1.Granted() || high2.Granted() {
+		t.Fatal("only the first slot should be granted")
+	}
+	if isPreempted(first) {
+		t.Fatal("non-critical arrivals must not preempt")
+	}
+
+	first.Release()
+	if !high1.Granted() || high2.Granted() || low.Granted() {
+		t.Fatal("expected earliest high-priority waiter to be granted next")
+	}
+	high1.Release()
+	if !high2.Granted() || low.Granted() {
+		t.Fatal("expected second high-priority waiter before low")
+	}
+	high2.Release()
+	if !low.Granted() {
+		t.Fatal("expected low waiter to be granted last")
+	}
+}
+
+func TestInvestigationScheduler_CriticalPreemptsLowestNewest(t *testing.T) {
+	s := NewInvestigationScheduler(3)
+	lowOld := s.Enqueue("low-old", InvestigationPriorityLow)
+	high := s.Enqueue("high", InvestigationPriorityHigh)
+	lowNew := s.Enqueue("low-new", InvestigationPriorityLow)
+
+	crit := s.Enqueue("crit", InvestigationPriorityCritical)
+	if crit.Granted() {
+		t.Fatal("critical slot should wait for a preempted slot")
+	}
+	if !isPreempted(lowNew) {
+		t.Fatal("expected newest low-priority slot to be preempted")
+	}
+	if isPreempted(lowOld) || isPreempted(high) {
+		t.Fatal("only one slot should be preempted per waiting critical")
+	}
+
+	// A second rebalance must not pick another victim for the same critical.
+	other := s.Enqueue("normal", InvestigationPriorityNormal)
+	if isPreempted(lowOld) {
+		t.Fatal("a non-critical arrival must not cause extra preemption")
+	}
+
+	requeued := make(chan error, 1)
+	go func() { requeued <- lowNew.Requeue(context.Background()) }()
+
+	deadline := time.After(time.Second)
+	for !crit.Granted() {
+		select {
+		case <-deadline:
+			t.Fatal("critical slot not granted after requeue")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Priority still wins over arrival order: the newer normal waiter is
+	// granted before the paused low-priority slot.
+	high.Release()
+	if !other.Granted() {
+		t.Fatal("expected normal waiter to be granted before requeued low slot")
+	}
+	lowOld.Release()
+	select {
+	case err := <-requeued:
+		if err != nil {
+			t.Fatalf("Requeue() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("requeued slot not granted")
+	}
+	if isPreempted(lowNew) {
+		t.Error("requeued slot should get a fresh Preempted channel")
+	}
+}
+
+func TestInvestigationScheduler_PreemptsMostRecentlyStarted(t *testing.T) {
+	s := NewInvestigationScheduler(2)
+	s.SetMaxPerSource(1)
+	first := s.EnqueueForSource("first", "src", InvestigationPriorityLow)
+	heldBack := s.EnqueueForSource("held-back", "src", InvestigationPriorityLow)
+	later := s.Enqueue("later", InvestigationPriorityLow)
+	if heldBack.Granted() || !later.Granted() {
+		t.Fatal("expected held-back to wait on its source while later runs")
+	}
+
+	// held-back arrived before later but starts after it.
+	first.Release()
+	if !heldBack.Granted() {
+		t.Fatal("held-back should start once its source frees up")
+	}
+
+	s.Enqueue("crit", InvestigationPriorityCritical)
+	if !isPreempted(heldBack) {
+		t.Error("expected the most recently started slot to be preempted")
+	}
+	if isPreempted(later) {
+		t.Error("the slot that started first should keep running")
+	}
+}
+
+func TestInvestigationScheduler_CriticalNeverPreemptsCritical(t *testing.T) {
+	s := NewInvestigationScheduler(1)
+	running := s.Enqueue("crit-1", InvestigationPriorityCritical)
+	waiting := s.Enqueue("crit-2", InvestigationPriorityCritical)
+
+	if waiting.Granted() {
+		t.Fatal("second critical should queue")
+	}
+	if isPreempted(running) {
+		t.Fatal("critical investigations must never be preempted")
+	}
+	running.Release()
+	if !waiting.Granted() {
+		t.Fatal("queued critical should be granted after release")
+	}
+}
+
+func TestInvestigationScheduler_RequeueKeepsArrivalOrder(t *testing.T) {
+	s := NewInvestigationScheduler(1)
+	paused := s.Enqueue("paused", InvestigationPriorityNormal)
+	crit := s.Enqueue("crit", InvestigationPriorityCritical)
+	newer := s.Enqueue("newer", InvestigationPriorityNormal)
+
+	if !isPreempted(paused) {
+		t.Fatal("expected running slot to be preempted")
+	}
+	requeued := make(chan error, 1)
+	go func() { requeued <- paused.Requeue(context.Background()) }()
+
+	deadline := time.After(time.Second)
+	for !crit.Granted() {
+		select {
+		case <-deadline:
+			t.Fatal("critical slot not granted")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	crit.Release()
+
+	select {
+	case err := <-requeued:
+		if err != nil {
+			t.Fatalf("Requeue() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("paused slot should resume before newer same-priority work")
+	}
+	if newer.Granted() {
+		t.Error("newer slot should still be waiting")
+	}
+}
+
+func TestInvestigationSlot_WaitContextCancelReleases(t *testing.T) {
+	s := NewInvestigationScheduler(1)
+	holder := s.Enqueue("holder", InvestigationPriorityLow)
+	waiter := s.Enqueue("waiter", InvestigationPriorityLow)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want context.Canceled", err)
+	}
+	if _, waiting := s.Stats(); waiting != 0 {
+		t.Errorf("waiting = %d after cancelled Wait, want 0", waiting)
+	}
+
+	holder.Release()
+	holder.Release() // idempotent
+	if running, _ := s.Stats(); running != 0 {
+		t.Errorf("running = %d after release, want 0", running)
+	}
+	if err := holder.Requeue(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Requeue() on released slot error = %v, want context.Canceled", err)
+	}
+}
+
+func TestInvestigationScheduler_PerSourceLimit(t *testing.T) {
+	s := NewInvestigationScheduler(3)
+	s.SetMaxPerSource(1)
+	a1 := s.EnqueueForSource("a-1", "src-a", InvestigationPriorityLow)
+	a2 := s.EnqueueForSource("a-2", "src-a", InvestigationPriorityCritical)
+	b1 := s.EnqueueForSource("b-1", "src-b", InvestigationPriorityLow)
+	manual := s.Enqueue("manual", InvestigationPriorityLow)
+
+	if !a1.Granted() || a2.Granted() || !b1.Granted() || !manual.Granted() {
+		t.Fatal("expected one slot per source plus the unsourced slot")
+	}
+	if isPreempted(a1) || isPreempted(b1) {
+		t.Fatal("a waiter held back by its source limit must not preempt")
+	}
+	a1.Release()
+	if !a2.Granted() {
+		t.Fatal("expected the source's next waiter once its slot freed")
+	}
+	if running, waiting := s.Stats(); running != 3 || waiting != 0 {
+		t.Errorf("Stats() = %d, %d; want 3, 0", running, waiting)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// leaderLockKey is the Postgres advisory lock held by the leader replica.
+// It sits next to the migration lock (742819001) in the same key space.
+const leaderLockKey int64 = 742819002
+
+// leaderCheckInterval is how often a follower retries the lock and the
+// leader re-checks the session holding it. It bounds failover time after the
+// leader dies or loses its database connection.
+const leaderCheckInterval = 5 * time.Second
+
+// leaderLock is the mutual-exclusion primitive behind a LeaderElector.
+type leaderLock interface {
+	// TryAcquire attempts to take the lock without blocking.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Check reports an error once a held lock can no longer be trusted.
+	Check(ctx context.Context) error
+	// Release gives the lock up. Safe to call when it is not held.
+	Release()
+}
+
+// LeaderElector picks one API replica to own the work that must not run
+// twice: Slack Socket Mode, the background sweeps and the cron scheduler.
+// Leadership is a Postgres session advisory lock on a dedicated connection,
+// so it is released by the database as soon as the leader's session dies.
+// On other databases (SQLite in tests and single-node setups) the process is
+// always the leader.
+//
+// Tasks registered with RunWhileLeader start when leadership is won and have
+// their context cancelled when it is lost; they start again on re-election.
+type LeaderElector struct {
+	lock     leaderLock
+	interval time.Duration
+
+	mu      sync.Mutex
+	tasks   []leaderTask
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+
+	leader atomic.Bool
+}
+
+type leaderTask struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// NewLeaderElector creates an elector backed by db.
+func NewLeaderElector(db *gorm.DB) *LeaderElector {
+	var lock leaderLock = alwaysLeaderLock{}
+	if db != nil && db.Dialector.Name() == "postgres" {
+		lock = &pgAdvisoryLock{db: db, key: leaderLockKey}
+	}
+	return newLeaderElector(lock, leaderCheckInterval)
+}
+
+func newLeaderElector(lock leaderLock, interval time.Duration) *LeaderElector {
+	return &LeaderElector{lock: lock, interval: interval}
+}
+
+// RunWhileLeader registers a task to run for as long as this replica leads.
+// fn must return promptly once ctx is cancelled. Register tasks before Start.
+func (e *LeaderElector) RunWhileLeader(name string, fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, leaderTask{name: name, run: fn})
+}
+
+// IsLeader reports whether this replica currently holds leadership.
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Start runs the first election round synchronously, so a lone replica is
+// leader by the time Start returns, and keeps campaigning in the background
+// until ctx is cancelled. On shutdown leadership is released so another
+// replica can take over without waiting for the session to time out.
+func (e *LeaderElector) Start(ctx context.Context) {
+	e.step(ctx)
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				e.resign()
+				return
+			case <-ticker.C:
+				e.step(ctx)
+			}
+		}
+	}()
+}
+
+// step runs one election round: followers try to take the lock, the leader
+// verifies it still holds it.
+func (e *LeaderElector) step(ctx context.Context) {
+	if e.leader.Load() {
+		if err := e.lock.Check(ctx); err != nil {
+			slog.Warn("leader election: lost leadership", "err", err)
+			e.resign()
+		}
+		return
+	}
+	acquired, err := e.lock.TryAcquire(ctx)
+	if err != nil {
+		slog.Warn("leader election: lock attempt failed", "err", err)
+		return
+	}
+	if acquired {
+		e.lead(ctx)
+	}
+}
+
+// lead marks this replica leader and starts every registered task.
+func (e *LeaderElector) lead(parent context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ctx, cancel := context.WithCancel(parent)
+	e.cancel = cancel
+	e.leader.Store(true)
+	slog.Info("leader election: this replica is now the leader", "tasks", len(e.tasks))
+	for _, task := range e.tasks {
+		e.running.Add(1)
+		go func(task leaderTask) {
+			defer e.running.Done()
+			task.run(ctx)
+			slog.Debug("leader task stopped", "task", task.name)
+		}(task)
+	}
+}
+
+// resign stops the leader tasks, waits for them to return and only then
+// releases the lock, so a new leader never overlaps with this one.
+func (e *LeaderElector) resign() {
+	e.mu.Lock()
+	cancel := e.cancel
+	e.cancel = nil
+	e.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	e.leader.Store(false)
+	cancel()
+	e.running.Wait()
+	e.lock.Release()
+	slog.Info("leader election: stepped down")
+}
+
+// alwaysLeaderLock is used where there is nothing to coordinate with.
+type alwaysLeaderLock struct{}
+
+func (alwaysLeaderLock) TryAcquire(context.Context) (bool, error) { return true, nil }
+func (alwaysLeaderLock) Check(context.Context) error              { return nil }
+func (alwaysLeaderLock) Release()                                 {}
+
+// pgAdvisoryLock holds a session-level advisory lock on a connection taken
+// out of the pool for as long as the lock is held.
+type pgAdvisoryLock struct {
+	db   *gorm.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func (l *pgAdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("reserve connection: %w", err)
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		discardConn(conn)
+		return false, fmt.Errorf("pg_try_advisory_lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *pgAdvisoryLock) Check(ctx context.Context) error {
+	if l.conn == nil {
+		return errors.New("lock not held")
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, leaderCheckInterval)
+	defer cancel()
+	return l.conn.PingContext(checkCtx)
+}
+
+// Release unlocks and then throws the connection away rather than returning
+// it to the pool: if the unlock did not go through, closing the session is
+// what frees the lock.
+func (l *pgAdvisoryLock) Release() {
+	if l.conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+		slog.Warn("leader election: unlock failed, closing session instead", "err", err)
+	}
+	discardConn(l.conn)
+	l.conn = nil
+}
+
+// discardConn closes the underlying driver connection instead of returning
+// it to the pool.
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
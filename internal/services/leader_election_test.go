@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLeaderLock struct {
+	mu       sync.Mutex
+	free     bool
+	held     bool
+	broken   bool
+	released int
+}
+
+func (l *fakeLeaderLock) TryAcquire(context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.free {
+		return false, nil
+	}
+	l.free, l.held, l.broken = false, true, false
+	return true, nil
+}
+
+func (l *fakeLeaderLock) Check(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.broken {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (l *fakeLeaderLock) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = false
+	l.released++
+}
+
+func (l *fakeLeaderLock) set(fn func(*fakeLeaderLock)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(l)
+}
+
+func TestLeaderElector_TasksFollowLeadership(t *testing.T) {
+	lock := &fakeLeaderLock{}
+	e := newLeaderElector(lock, time.Hour)
+
+	var mu sync.Mutex
+	starts, stops := 0, 0
+	e.RunWhileLeader("test", func(ctx context.Context) {
+		mu.Lock()
+		starts++
+		mu.Unlock()
+		<-ctx.Done()
+		mu.Lock()
+		stops++
+		mu.Unlock()
+	})
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return starts, stops
+	}
+	waitFor := func(wantStarts, wantStops int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if s, p := counts(); s == wantStarts && p == wantStops {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		s, p := counts()
+		t.Fatalf("starts=%d stops=%d, want %d/%d", s, p, wantStarts, wantStops)
+	}
+	ctx := context.Background()
+
+	// Another replica holds the lock.
+	e.step(ctx)
+	if e.IsLeader() {
+		t.Fatal("became leader without the lock")
+	}
+
+	lock.set(func(l *fakeLeaderLock) { l.free = true })
+	e.step(ctx)
+	if !e.IsLeader() {
+		t.Fatal("did not take the free lock")
+	}
+	waitFor(1, 0)
+
+	// Still healthy: nothing restarts.
+	e.step(ctx)
+	waitFor(1, 0)
+
+	lock.set(func(l *fakeLeaderLock) { l.broken = true })
+	e.step(ctx)
+	if e.IsLeader() {
+		t.Fatal("kept leadership after the lock check failed")
+	}
+	// resign waits for tasks before releasing the lock.
+	waitFor(1, 1)
+	if lock.released != 1 {
+		t.Errorf("released = %d, want 1", lock.released)
+	}
+
+	lock.set(func(l *fakeLeaderLock) { l.free = true })
+	e.step(ctx)
+	waitFor(2, 1)
+	e.resign()
+	waitFor(2, 2)
+}
+
+func TestLeaderElector_SingleNodeLeadsImmediately(t *testing.T) {
+	e := NewLeaderElector(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ran := make(chan struct{})
+	e.RunWhileLeader("test", func(ctx context.Context) {
+		close(ran)
+		<-ctx.Done()
+	})
+	e.Start(ctx)
+	if !e.IsLeader() {
+		t.Fatal("a lone replica must be leader once Start returns")
+	}
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("leader task did not start")
+	}
+}
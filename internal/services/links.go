@@ -0,0 +1,127 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// defaultBaseURL is used for links when no base URL is configured.
+const defaultBaseURL = "http://localhost:3000"
+
+// ShortLinkPrefix is the path under which short links are served.
+const ShortLinkPrefix = "/i/"
+
+// ErrShortLinkNotFound is returned by ResolveShortLink for an unknown code.
+var ErrShortLinkNotFound = errors.New("short link not found")
+
+const (
+	shortLinkAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	shortLinkLength   = 8
+)
+
+// ConfiguredBaseURL returns the externally reachable Akmatori URL without a
+// trailing slash: the general settings' base URL, else AKMATORI_BASE_URL,
+// else "".
+func ConfiguredBaseURL() string {
+	if settings, err := database.GetOrCreateGeneralSettings(); err == nil && settings.BaseURL != "" {
+		return strings.TrimRight(settings.BaseURL, "/")
+	}
+	return strings.TrimRight(os.Getenv("AKMATORI_BASE_URL"), "/")
+}
+
+// BaseURL returns ConfiguredBaseURL, falling back to the local UI address.
+func BaseURL() string {
+	if base := ConfiguredBaseURL(); base != "" {
+		return base
+	}
+	return defaultBaseURL
+}
+
+// IncidentURL returns the UI link for an incident. It is a short /i/{code}
+// link when short links are enabled in the general settings.
+func IncidentURL(incidentUUID string) string {
+	return AppURL("/incidents/" + incidentUUID)
+}
+
+// AppURL returns the absolute UI link for path, shortened when short links
+// are enabled. Shortening is best-effort: on a database error the full link
+// is returned.
+func AppURL(path string) string {
+	base := BaseURL()
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil || !settings.GetShortLinksEnabled() {
+		return base + path
+	}
+	code, err := ShortenPath(database.GetDB(), path)
+	if err != nil {
+		slog.Warn("failed to create short link, using the full link", "path", path, "error", err)
+		return base + path
+	}
+	return base + ShortLinkPrefix + code
+}
+
+// ShortenPath returns the short code for path, creating it on first use. A
+// path always maps to the same code.
+func ShortenPath(db *gorm.DB, path string) (string, error) {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return "", fmt.Errorf("short link target must be a local path, got %q", path)
+	}
+	var existing database.ShortLink
+	if err := db.Where("target_path = ?", path).Take(&existing).Error; err == nil {
+		return existing.Code, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	var lastErr error
+	for range 3 {
+		code, err := newShortCode()
+		if err != nil {
+			return "", err
+		}
+		link := database.ShortLink{Code: code, TargetPath: path}
+		if lastErr = db.Create(&link).Error; lastErr == nil {
+			return code, nil
+		}
+		// A concurrent caller may have shortened the same path first.
+		if err := db.Where("target_path = ?", path).Take(&existing).Error; err == nil {
+			return existing.Code, nil
+		}
+	}
+	return "", lastErr
+}
+
+// ResolveShortLink returns the target path of code, or
+// ErrShortLinkNotFound.
+func ResolveShortLink(db *gorm.DB, code string) (string, error) {
+	var link database.ShortLink
+	err := db.Where("code = ?", code).Take(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrShortLinkNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return link.TargetPath, nil
+}
+
+func newShortCode() (string, error) {
+	b := make([]byte, shortLinkLength)
+	size := big.NewInt(int64(len(shortLinkAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		b[i] = shortLinkAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestIncidentURL(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.GeneralSettings{}, &database.ShortLink{})
+	t.Setenv("AKMATORI_BASE_URL", "")
+
+	if got := IncidentURL("abc"); got != "http://localhost:3000/incidents/abc" {
+		t.Errorf("unconfigured IncidentURL = %q", got)
+	}
+	if got := ConfiguredBaseURL(); got != "" {
+		t.Errorf("ConfiguredBaseURL = %q, want empty", got)
+	}
+
+	t.Setenv("AKMATORI_BASE_URL", "https://env.example.com/")
+	if got := IncidentURL("abc"); got != "https://env.example.com/incidents/abc" {
+		t.Errorf("env IncidentURL = %q", got)
+	}
+
+	settings, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := true
+	settings.BaseURL = "https://akmatori.example.com/"
+	settings.ShortLinksEnabled = &enabled
+	if err := database.UpdateGeneralSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	short := IncidentURL("abc")
+	code, ok := strings.CutPrefix(short, "https://akmatori.example.com/i/")
+	if !ok || len(code) != shortLinkLength {
+		t.Fatalf("short IncidentURL = %q", short)
+	}
+	if again := IncidentURL("abc"); again != short {
+		t.Errorf("second IncidentURL = %q, want the same link %q", again, short)
+	}
+	if other := IncidentURL("def"); other == short {
+		t.Error("different incidents share a short link")
+	}
+
+	target, err := ResolveShortLink(db, code)
+	if err != nil || target != "/incidents/abc" {
+		t.Errorf("ResolveShortLink = %q, %v", target, err)
+	}
+	if _, err := ResolveShortLink(db, "missing"); !errors.Is(err, ErrShortLinkNotFound) {
+		t.Errorf("unknown code err = %v, want ErrShortLinkNotFound", err)
+	}
+	if _, err := ShortenPath(db, "//evil.example.com"); err == nil {
+		t.Error("ShortenPath accepted a protocol-relative URL")
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// llmHealthTimeout bounds one LLM endpoint probe.
+	llmHealthTimeout = 10 * time.Second
+
+	// llmHealthMaxModels caps the model IDs returned by a probe; hosted
+	// providers list hundreds.
+	llmHealthMaxModels = 200
+)
+
+// llmDefaultBaseURLs are the model-listing API roots of the hosted providers
+// whose endpoint is known. Providers missing here (custom, self-hosted and
+// the rest) can only be probed with a base URL.
+var llmDefaultBaseURLs = map[database.LLMProvider]string{
+	database.LLMProviderOpenAI:     "https://api.openai.com/v1",
+	database.LLMProviderAnthropic:  "https://api.anthropic.com/v1",
+	database.LLMProviderGoogle:     "https://generativelanguage.googleapis.com/v1beta/openai",
+	database.LLMProviderOpenRouter: "https://openrouter.ai/api/v1",
+	database.LLMProviderNvidiaNIM:  "https://integrate.api.nvidia.com/v1",
+}
+
+// LLMHealth is the result of probing an LLM configuration's endpoint.
+type LLMHealth struct {
+	// Healthy is true when the endpoint answered the model listing with the
+	// configured credentials. For a self-hosted configuration the model must
+	// also be served, since a typo there fails every investigation.
+	Healthy   bool
+	Endpoint  string
+	LatencyMs int64
+	// Models are the model IDs the endpoint lists, capped at 200.
+	Models         []string
+	ModelAvailable bool
+	// ContextWindow is the context window the endpoint reports for the
+	// configured model (vLLM's max_model_len); 0 when it reports none.
+	ContextWindow int
+	Error         string
+}
+
+// LLMHealthChecker probes LLM endpoints by listing their models, the
+// cheapest request that exercises connectivity and credentials without
+// spending tokens. The probe runs from the API server, honouring the LLM
+// proxy setting, so it reaches the endpoint the way the agent worker does
+// when both share a network.
+type LLMHealthChecker struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// NewLLMHealthChecker creates an LLM health checker.
+func NewLLMHealthChecker(db *gorm.DB) *LLMHealthChecker {
+	return &LLMHealthChecker{db: db, timeout: llmHealthTimeout}
+}
+
+// llmModelList is the OpenAI-style model listing, which Anthropic, vLLM
+// and Ollama's OpenAI-compatible API also return.
+type llmModelList struct {
+	Data []struct {
+		ID          string `json:"id"`
+		MaxModelLen int    `json:"max_model_len"`
+	} `json:"data"`
+}
+
+// CheckLLMHealth lists the models of the configuration's endpoint.
+func (c *LLMHealthChecker) CheckLLMHealth(ctx context.Context, settings *database.LLMSettings) LLMHealth {
+	base := strings.TrimRight(settings.BaseURL, "/")
+	if base == "" {
+		base = llmDefaultBaseURLs[settings.Provider]
+	}
+	if base == "" {
+		return LLMHealth{Error: fmt.Sprintf("%s configurations need a base URL to be checked", database.ProviderDisplayName(settings.Provider))}
+	}
+	health := LLMHealth{Endpoint: base + "/models"}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, health.Endpoint, nil)
+	if err != nil {
+		health.Error = fmt.Sprintf("invalid endpoint: %v", err)
+		return health
+	}
+	if settings.APIKey != "" {
+		if settings.Provider == database.LLMProviderAnthropic {
+			req.Header.Set("x-api-key", settings.APIKey)
+			req.Header.Set("anthropic-version", "2023-06-01")
+		} else {
+			req.Header.Set("Authorization", "Bearer "+settings.APIKey)
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient(ctx).Do(req)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = fmt.Sprintf("endpoint unreachable: %v", err)
+		return health
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		health.Error = fmt.Sprintf("endpoint rejected the credentials (HTTP %d)", resp.StatusCode)
+		return health
+	case resp.StatusCode == http.StatusNotFound:
+		health.Error = "endpoint has no model listing (HTTP 404); the base URL usually ends in /v1"
+		return health
+	case resp.StatusCode >= 300:
+		health.Error = fmt.Sprintf("endpoint returned HTTP %d", resp.StatusCode)
+		return health
+	}
+
+	var list llmModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		health.Error = "endpoint did not return an OpenAI-compatible model list"
+		return health
+	}
+	health.Models = make([]string, 0, min(len(list.Data), llmHealthMaxModels))
+	for _, m := range list.Data {
+		if len(health.Models) < llmHealthMaxModels {
+			health.Models = append(health.Models, m.ID)
+		}
+		// Ollama lists the implicit ":latest" tag explicitly.
+		if settings.Model != "" && (m.ID == settings.Model || m.ID == settings.Model+":latest") {
+			health.ModelAvailable = true
+			health.ContextWindow = m.MaxModelLen
+		}
+	}
+
+	health.Healthy = true
+	if settings.Provider == database.LLMProviderSelfHosted && !health.ModelAvailable {
+		health.Healthy = false
+		health.Error = fmt.Sprintf("model %q is not served by the endpoint", settings.Model)
+	}
+	return health
+}
+
+// httpClient honours the LLM proxy setting, as the agent worker does.
+func (c *LLMHealthChecker) httpClient(ctx context.Context) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	var proxies []database.ProxySettings
+	if c.db != nil {
+		if err := c.db.WithContext(ctx).Limit(1).Find(&proxies).Error; err == nil && len(proxies) > 0 &&
+			proxies[0].LLMEnabled && proxies[0].ProxyURL != "" {
+			if proxyURL, err := url.Parse(proxies[0].ProxyURL); err == nil {
+				transport.Proxy = http.ProxyURL(proxyURL)
+			}
+		}
+	}
+	return &http.Client{Timeout: c.timeout, Transport: transport}
+}
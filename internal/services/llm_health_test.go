@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func newModelsServer(t *testing.T, wantKey string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		if wantKey != "" && r.Header.Get("Authorization") != "Bearer "+wantKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen3-32b","max_model_len":32768},{"id":"llama3:latest"}]}`))
+	}))
+}
+
+func TestLLMHealthChecker_SelfHosted(t *testing.T) {
+	srv := newModelsServer(t, "")
+	defer srv.Close()
+	checker := NewLLMHealthChecker(nil)
+
+	tests := []struct {
+		name          string
+		baseURL       string
+		model         string
+		healthy       bool
+		contextWindow int
+		errContains   string
+	}{
+		{"served model", srv.URL + "/v1", "qwen3-32b", true, 32768, ""},
+		{"ollama latest tag", srv.URL + "/v1/", "llama3", true, 0, ""},
+		{"unknown model", srv.URL + "/v1", "mistral", false, 0, "not served"},
+		{"missing /v1", srv.URL, "qwen3-32b", false, 0, "/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := checker.CheckLLMHealth(context.Background(), &database.LLMSettings{
+				Provider: database.LLMProviderSelfHosted,
+				BaseURL:  tt.baseURL,
+				Model:    tt.model,
+			})
+			if health.Healthy != tt.healthy {
+				t.Errorf("healthy = %v, want %v (error %q)", health.Healthy, tt.healthy, health.Error)
+			}
+			if health.ContextWindow != tt.contextWindow {
+				t.Errorf("context window = %d, want %d", health.ContextWindow, tt.contextWindow)
+			}
+			if tt.errContains != "" && !strings.Contains(health.Error, tt.errContains) {
+				t.Errorf("error %q does not contain %q", health.Error, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestLLMHealthChecker_Credentials(t *testing.T) {
+	srv := newModelsServer(t, "secret")
+	defer srv.Close()
+	checker := NewLLMHealthChecker(nil)
+
+	settings := &database.LLMSettings{Provider: database.LLMProviderCustom, BaseURL: srv.URL + "/v1", APIKey: "wrong", Model: "gpt-oss"}
+	health := checker.CheckLLMHealth(context.Background(), settings)
+	if health.Healthy || !strings.Contains(health.Error, "credentials") {
+		t.Errorf("expected credentials error, got healthy=%v error=%q", health.Healthy, health.Error)
+	}
+
+	// A custom endpoint is healthy once it answers, even without the model.
+	settings.APIKey = "secret"
+	health = checker.CheckLLMHealth(context.Background(), settings)
+	if !health.Healthy || health.ModelAvailable {
+		t.Errorf("expected healthy without the model, got healthy=%v model_available=%v", health.Healthy, health.ModelAvailable)
+	}
+	if len(health.Models) != 2 {
+		t.Errorf("expected 2 models, got %v", health.Models)
+	}
+}
+
+func TestLLMHealthChecker_NoEndpoint(t *testing.T) {
+	health := NewLLMHealthChecker(nil).CheckLLMHealth(context.Background(), &database.LLMSettings{Provider: database.LLMProviderMiniMax})
+	if health.Healthy || !strings.Contains(health.Error, "base URL") {
+		t.Errorf("expected base URL error, got healthy=%v error=%q", health.Healthy, health.Error)
+	}
+}
@@ -41,6 +41,20 @@ var ErrAlertAlreadyResolved = errors.New("alert is already resolved")
 // already closed. The caller should surface this as HTTP 409.
 var ErrIncidentAlreadyClosed = errors.New("incident is already closed")
 
+// ErrIncidentNotRunning is returned by CancelIncident when the incident has
+// no investigation in progress. The caller should surface this as HTTP 409.
+var ErrIncidentNotRunning = errors.New("incident has no investigation in progress")
+
+// ErrIncidentNotRetryable is returned by BeginRetry when the incident's
+// investigation neither failed nor was cancelled. The caller should surface
+// this as HTTP 409.
+var ErrIncidentNotRetryable = errors.New("only failed or cancelled investigations can be retried")
+
+// ErrNoRecordedTask is returned by BeginRetry when the incident has no
+// recorded investigation prompt (it never reached the agent worker). The
+// caller should surface this as HTTP 409.
+var ErrNoRecordedTask = errors.New("incident has no recorded investigation prompt")
+
 // ErrConfirmationRequired is returned by CloseIncident when closing would
 // have a side effect the caller did not explicitly confirm: the incident
 // still has firing alerts linked (they get resolved as part of the close),
@@ -75,21 +89,37 @@ type LLMSettingsForWorker struct {
 	Model         string
 	ThinkingLevel string
 	BaseURL       string
+	ContextWindow int
 }
 
+// selfHostedPlaceholderKey is sent for self-hosted configurations without an
+// API key: the worker needs one, and vLLM and Ollama ignore it unless
+// started with a key.
+const selfHostedPlaceholderKey = "self-hosted"
+
 // BuildLLMSettingsForWorker creates LLMSettingsForWorker from database LLMSettings.
-// Returns nil if settings are nil, disabled, or missing an API key.
+// Returns nil if settings are nil, disabled, or not configured. Self-hosted
+// endpoints speak the OpenAI-compatible API, so the worker runs them as
+// "custom".
 func BuildLLMSettingsForWorker(dbSettings *database.LLMSettings) *LLMSettingsForWorker {
 	if dbSettings == nil || !dbSettings.IsActive() {
 		return nil
 	}
-	return &LLMSettingsForWorker{
+	out := &LLMSettingsForWorker{
 		Provider:      string(dbSettings.Provider),
 		APIKey:        dbSettings.APIKey,
 		Model:         dbSettings.Model,
 		ThinkingLevel: string(dbSettings.ThinkingLevel),
 		BaseURL:       dbSettings.BaseURL,
+		ContextWindow: dbSettings.ContextWindow,
+	}
+	if dbSettings.Provider == database.LLMProviderSelfHosted {
+		out.Provider = string(database.LLMProviderCustom)
+		if out.APIKey == "" {
+			out.APIKey = selfHostedPlaceholderKey
+		}
 	}
+	return out
 }
 
 // OneShotLLMCaller issues a one-shot, provider-agnostic LLM completion through
@@ -123,6 +153,9 @@ type IncidentCallback struct {
 // without spinning up a real WebSocket).
 type IncidentRunner interface {
 	IsWorkerConnected() bool
+	// WaitForWorker is IsWorkerConnected with a bounded grace period for a
+	// worker that is still (re)connecting.
+	WaitForWorker(ctx context.Context) bool
 	StartIncident(incidentID, task string, llm *LLMSettingsForWorker, enabledSkills []string, toolAllowlist []ToolAllowlistEntry, callback IncidentCallback) (string, error)
 	ReleaseRun(incidentID, runID string) bool
 }
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+const (
+	marketplaceFetchTimeout = 30 * time.Second
+	marketplaceIndexTTL     = 5 * time.Minute
+	maxMarketplaceIndexSize = 1 << 20
+)
+
+// Marketplace errors, mapped to 4xx/5xx responses by the handlers.
+var (
+	ErrMarketplaceSkillNotFound = errors.New("skill not found in marketplace index")
+	ErrMarketplaceUnavailable   = errors.New("marketplace index unavailable")
+	ErrBundleVerification       = errors.New("bundle verification failed")
+)
+
+// MarketplaceIndex is the static JSON document a marketplace publishes.
+type MarketplaceIndex struct {
+	Skills    []MarketplaceSkill    `json:"skills"`
+	ToolTypes []MarketplaceToolType `json:"tool_types"`
+}
+
+// MarketplaceSkill is an installable skill bundle listed in the index.
+type MarketplaceSkill struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Category    string   `json:"category,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	ToolTypes   []string `json:"tool_types,omitempty"`
+	// BundleURL may be relative to the index URL.
+	BundleURL string `json:"bundle_url"`
+	SHA256    string `json:"sha256,omitempty"`
+	// Signature is the base64 ed25519 signature of the bundle bytes.
+	Signature string `json:"signature"`
+	// Installed is set locally when a skill of this name exists.
+	Installed bool `json:"installed"`
+}
+
+// MarketplaceToolType is a community tool type listed for reference. Tool
+// types ship with the MCP gateway, so they are browsed, not installed.
+type MarketplaceToolType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Homepage    string `json:"homepage,omitempty"`
+	Installed   bool   `json:"installed"`
+}
+
+// MarketplaceClient browses a remote skill index and installs its bundles
+// through SkillService.InstallSkillBundle. Bundles are only installed when
+// signed by one of the trusted ed25519 keys.
+type MarketplaceClient struct {
+	indexURL   *url.URL
+	publicKeys []ed25519.PublicKey
+	skills     *SkillService
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	cached    *MarketplaceIndex
+	fetchedAt time.Time
+}
+
+// NewMarketplaceClient creates a client for the index at indexURL trusting
+// the given base64-encoded ed25519 public keys.
+func NewMarketplaceClient(indexURL string, publicKeys []string, skills *SkillService) (*MarketplaceClient, error) {
+	u, err := url.Parse(indexURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("marketplace index URL must be an absolute http(s) URL")
+	}
+	keys := make([]ed25519.PublicKey, 0, len(publicKeys))
+	for _, encoded := range publicKeys {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("marketplace public key %q is not a base64 ed25519 key", encoded)
+		}
+		keys = append(keys, ed25519.PublicKey(raw))
+	}
+	return &MarketplaceClient{
+		indexURL:   u,
+		publicKeys: keys,
+		skills:     skills,
+		httpClient: &http.Client{Timeout: marketplaceFetchTimeout},
+		now:        time.Now,
+	}, nil
+}
+
+// Index returns the marketplace index with local install state filled in.
+// The remote document is cached for a few minutes.
+func (c *MarketplaceClient) Index(ctx context.Context) (*MarketplaceIndex, error) {
+	remote, err := c.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := &MarketplaceIndex{
+		Skills:    append([]MarketplaceSkill(nil), remote.Skills...),
+		ToolTypes: append([]MarketplaceToolType(nil), remote.ToolTypes...),
+	}
+
+	db := c.skills.db.WithContext(ctx)
+	var skillNames, toolTypeNames []string
+	if err := db.Model(&database.Skill{}).Pluck("name", &skillNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+	if err := db.Model(&database.ToolType{}).Pluck("name", &toolTypeNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tool types: %w", err)
+	}
+	installedSkills := stringSet(skillNames)
+	installedToolTypes := stringSet(toolTypeNames)
+	for i := range index.Skills {
+		index.Skills[i].Installed = installedSkills[index.Skills[i].Name]
+	}
+	for i := range index.ToolTypes {
+		index.ToolTypes[i].Installed = installedToolTypes[index.ToolTypes[i].Name]
+	}
+	return index, nil
+}
+
+// InstallSkill downloads the named skill's bundle, verifies it against the
+// index entry and the trusted keys, and installs it.
+func (c *MarketplaceClient) InstallSkill(ctx context.Context, name string) (*SkillInstallResult, error) {
+	remote, err := c.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entry *MarketplaceSkill
+	for i := range remote.Skills {
+		if remote.Skills[i].Name == name {
+			entry = &remote.Skills[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMarketplaceSkillNotFound, name)
+	}
+
+	bundleURL, err := c.indexURL.Parse(entry.BundleURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad bundle URL for %s", ErrMarketplaceUnavailable, name)
+	}
+	data, err := c.get(ctx, bundleURL.String(), maxSkillBundleBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyBundle(entry, data); err != nil {
+		return nil, err
+	}
+
+	bundle, err := ReadSkillBundle(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Manifest.Name != entry.Name {
+		return nil, fmt.Errorf("%w: bundle is for %q, index lists %q", ErrInvalidSkillBundle, bundle.Manifest.Name, entry.Name)
+	}
+	return c.skills.InstallSkillBundle(bundle)
+}
+
+// verifyBundle checks the bundle's digest (when the index lists one) and
+// requires a valid signature from a trusted key.
+func (c *MarketplaceClient) verifyBundle(entry *MarketplaceSkill, data []byte) error {
+	if entry.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), entry.SHA256) {
+			return fmt.Errorf("%w: sha256 mismatch", ErrBundleVerification)
+		}
+	}
+	if len(c.publicKeys) == 0 {
+		return fmt.Errorf("%w: no trusted marketplace keys are configured", ErrBundleVerification)
+	}
+	sig, err := base64.StdEncoding.DecodeString(entry.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: bundle is not signed", ErrBundleVerification)
+	}
+	for _, key := range c.publicKeys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature does not match a trusted key", ErrBundleVerification)
+}
+
+func (c *MarketplaceClient) fetchIndex(ctx context.Context) (*MarketplaceIndex, error) {
+	c.mu.Lock()
+	if c.cached != nil && c.now().Sub(c.fetchedAt) < marketplaceIndexTTL {
+		cached := c.cached
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+
+	data, err := c.get(ctx, c.indexURL.String(), maxMarketplaceIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	var index MarketplaceIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("%w: malformed index: %v", ErrMarketplaceUnavailable, err)
+	}
+
+	c.mu.Lock()
+	c.cached = &index
+	c.fetchedAt = c.now()
+	c.mu.Unlock()
+	return &index, nil
+}
+
+func (c *MarketplaceClient) get(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMarketplaceUnavailable, err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMarketplaceUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: GET %s returned %d", ErrMarketplaceUnavailable, rawURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMarketplaceUnavailable, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrMarketplaceUnavailable, rawURL, limit)
+	}
+	return data, nil
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
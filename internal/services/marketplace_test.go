@@ -0,0 +1,219 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// buildSkillBundle packs files into a gzipped tar.
+func buildSkillBundle(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func validBundleFiles() map[string]string {
+	return map[string]string{
+		"skill.json":         `{"format_version":1,"name":"pg-replica","version":"1.0.0","description":"Replica lag triage","tool_types":["ssh","pgbouncer"]}`,
+		"SKILL.md":           "---\nname: pg-replica\ndescription: Replica lag triage\n---\n\nCheck replay lag first.\n",
+		"scripts/lag.sh":     "#!/bin/sh\necho lag\n",
+		"scripts/explain.py": "print('ok')\n",
+	}
+}
+
+func TestReadSkillBundle_Valid(t *testing.T) {
+	bundle, err := ReadSkillBundle(bytes.NewReader(buildSkillBundle(t, validBundleFiles())))
+	if err != nil {
+		t.Fatalf("ReadSkillBundle: %v", err)
+	}
+	if bundle.Manifest.Name != "pg-replica" || bundle.Manifest.Version != "1.0.0" {
+		t.Errorf("manifest = %+v", bundle.Manifest)
+	}
+	if bundle.Prompt != "Check replay lag first." {
+		t.Errorf("prompt = %q", bundle.Prompt)
+	}
+	if len(bundle.Scripts) != 2 || bundle.Scripts["lag.sh"] == "" {
+		t.Errorf("scripts = %v", bundle.Scripts)
+	}
+}
+
+func TestReadSkillBundle_Invalid(t *testing.T) {
+	tests := map[string]func(files map[string]string){
+		"path traversal":     func(f map[string]string) { f["scripts/../../evil.sh"] = "x" },
+		"unexpected file":    func(f map[string]string) { f["notes.txt"] = "x" },
+		"missing manifest":   func(f map[string]string) { delete(f, "skill.json") },
+		"missing SKILL.md":   func(f map[string]string) { delete(f, "SKILL.md") },
+		"bad skill name":     func(f map[string]string) { f["skill.json"] = `{"format_version":1,"name":"Bad Name"}` },
+		"unsupported format": func(f map[string]string) { f["skill.json"] = `{"format_version":3,"name":"pg-replica"}` },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := validBundleFiles()
+			mutate(files)
+			_, err := ReadSkillBundle(bytes.NewReader(buildSkillBundle(t, files)))
+			if !errors.Is(err, ErrInvalidSkillBundle) {
+				t.Errorf("err = %v, want ErrInvalidSkillBundle", err)
+			}
+		})
+	}
+}
+
+type marketplaceFixture struct {
+	client *MarketplaceClient
+	skills *SkillService
+}
+
+func newMarketplaceFixture(t *testing.T, mutate func(*MarketplaceSkill)) *marketplaceFixture {
+	t.Helper()
+	db := setupSkillTestDB(t)
+	if err := db.AutoMigrate(&database.ToolType{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&database.ToolType{Name: "ssh"})
+	skills := newTestSkillService(t, db)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := buildSkillBundle(t, validBundleFiles())
+	sum := sha256.Sum256(bundle)
+	entry := MarketplaceSkill{
+		Name:      "pg-replica",
+		Version:   "1.0.0",
+		BundleURL: "bundles/pg-replica-1.0.0.tar.gz",
+		SHA256:    hex.EncodeToString(sum[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, bundle)),
+	}
+	if mutate != nil {
+		mutate(&entry)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/skills/index.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(MarketplaceIndex{
+			Skills:    []MarketplaceSkill{entry},
+			ToolTypes: []MarketplaceToolType{{Name: "ssh"}, {Name: "pgbouncer"}},
+		})
+	})
+	mux.HandleFunc("/skills/bundles/pg-replica-1.0.0.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bundle)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client, err := NewMarketplaceClient(srv.URL+"/skills/index.json", []string{base64.StdEncoding.EncodeToString(pub)}, skills)
+	if err != nil {
+		t.Fatalf("NewMarketplaceClient: %v", err)
+	}
+	return &marketplaceFixture{client: client, skills: skills}
+}
+
+func TestMarketplaceClient_InstallSkill(t *testing.T) {
+	f := newMarketplaceFixture(t, nil)
+	ctx := context.Background()
+
+	result, err := f.client.InstallSkill(ctx, "pg-replica")
+	if err != nil {
+		t.Fatalf("InstallSkill: %v", err)
+	}
+	if result.Skill.Name != "pg-replica" || result.Version != "1.0.0" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.MissingToolTypes) != 1 || result.MissingToolTypes[0] != "pgbouncer" {
+		t.Errorf("MissingToolTypes = %v, want [pgbouncer]", result.MissingToolTypes)
+	}
+	prompt, err := f.skills.GetSkillPrompt("pg-replica")
+	if err != nil || prompt != "Check replay lag first." {
+		t.Errorf("prompt = %q (%v)", prompt, err)
+	}
+	if _, err := os.Stat(filepath.Join(f.skills.GetSkillScriptsDir("pg-replica"), "lag.sh")); err != nil {
+		t.Errorf("script not installed: %v", err)
+	}
+
+	index, err := f.client.Index(ctx)
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if !index.Skills[0].Installed {
+		t.Error("installed skill not flagged in index")
+	}
+	if !index.ToolTypes[0].Installed || index.ToolTypes[1].Installed {
+		t.Errorf("tool types = %+v, want only ssh installed", index.ToolTypes)
+	}
+
+	if _, err := f.client.InstallSkill(ctx, "pg-replica"); !errors.Is(err, ErrSkillExists) {
+		t.Errorf("second install err = %v, want ErrSkillExists", err)
+	}
+}
+
+func TestMarketplaceClient_RejectsUnverifiedBundles(t *testing.T) {
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	tests := map[string]func(*MarketplaceSkill){
+		"unsigned": func(e *MarketplaceSkill) { e.Signature = "" },
+		"untrusted key": func(e *MarketplaceSkill) {
+			e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, []byte("x")))
+		},
+		"digest mismatch": func(e *MarketplaceSkill) { e.SHA256 = hex.EncodeToString(make([]byte, 32)) },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newMarketplaceFixture(t, mutate)
+			_, err := f.client.InstallSkill(context.Background(), "pg-replica")
+			if !errors.Is(err, ErrBundleVerification) {
+				t.Fatalf("err = %v, want ErrBundleVerification", err)
+			}
+			if _, err := f.skills.GetSkill("pg-replica"); err == nil {
+				t.Error("unverified bundle was installed")
+			}
+		})
+	}
+}
+
+func TestMarketplaceClient_UnknownSkill(t *testing.T) {
+	f := newMarketplaceFixture(t, nil)
+	if _, err := f.client.InstallSkill(context.Background(), "nope"); !errors.Is(err, ErrMarketplaceSkillNotFound) {
+		t.Errorf("err = %v, want ErrMarketplaceSkillNotFound", err)
+	}
+}
+
+func TestNewMarketplaceClient_RejectsBadConfig(t *testing.T) {
+	if _, err := NewMarketplaceClient("index.json", nil, nil); err == nil {
+		t.Error("relative index URL accepted")
+	}
+	if _, err := NewMarketplaceClient("https://example.com/index.json", []string{"not-a-key"}, nil); err == nil {
+		t.Error("malformed public key accepted")
+	}
+}
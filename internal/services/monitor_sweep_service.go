@@ -22,7 +22,8 @@ const monitorSweepInterval = 15 * time.Minute
 // passes with no recurrence, the incident is done being watched and should
 // move to "closed" rather than sit in "monitor" indefinitely.
 type MonitorSweepService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	publisher IncidentEventPublisher // optional; notified of each closed incident
 }
 
 // NewMonitorSweepService creates a new monitor sweep service.
@@ -30,6 +31,12 @@ func NewMonitorSweepService(db *gorm.DB) *MonitorSweepService {
 	return &MonitorSweepService{db: db}
 }
 
+// SetIncidentEventPublisher wires the publisher told about incidents the
+// sweep closes. Optional.
+func (s *MonitorSweepService) SetIncidentEventPublisher(p IncidentEventPublisher) {
+	s.publisher = p
+}
+
 // SweepResult holds statistics from a sweep run.
 type SweepResult struct {
 	IncidentsClosed int
@@ -44,14 +51,20 @@ func (s *MonitorSweepService) RunSweep() (*SweepResult, error) {
 	result := &SweepResult{}
 	now := time.Now()
 
+	var closed []string
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		expiredMonitorIncidents := tx.Model(&database.Incident{}).
-			Select("uuid").
-			Where("status = ? AND monitor_until < ?", database.IncidentStatusMonitor, now)
+		if err := tx.Model(&database.Incident{}).
+			Where("status = ? AND monitor_until < ?", database.IncidentStatusMonitor, now).
+			Pluck("uuid", &closed).Error; err != nil {
+			return fmt.Errorf("list expired-monitor incidents: %w", err)
+		}
+		if len(closed) == 0 {
+			return nil
+		}
 
 		if err := tx.Model(&database.Alert{}).
 			Where("status = ? AND resolved_at IS NULL AND incident_uuid IN (?)",
-				string(database.AlertStatusFiring), expiredMonitorIncidents).
+				string(database.AlertStatusFiring), closed).
 			Updates(map[string]interface{}{
 				"status":      string(database.AlertStatusResolved),
 				"resolved_at": now,
@@ -60,7 +73,7 @@ func (s *MonitorSweepService) RunSweep() (*SweepResult, error) {
 		}
 
 		update := tx.Model(&database.Incident{}).
-			Where("status = ? AND monitor_until < ?", database.IncidentStatusMonitor, now).
+			Where("status = ? AND monitor_until < ? AND uuid IN ?", database.IncidentStatusMonitor, now, closed).
 			Updates(map[string]interface{}{
 				"status":        database.IncidentStatusClosed,
 				"resolved_at":   &now,
@@ -70,6 +83,15 @@ func (s *MonitorSweepService) RunSweep() (*SweepResult, error) {
 			return fmt.Errorf("close expired-monitor incidents: %w", update.Error)
 		}
 		result.IncidentsClosed = int(update.RowsAffected)
+		if result.IncidentsClosed != len(closed) {
+			// An alert recurrence moved some incidents out of monitor between
+			// the listing and the update; report only the ones really closed.
+			candidates := closed
+			closed = nil
+			return tx.Model(&database.Incident{}).
+				Where("status = ? AND uuid IN ?", database.IncidentStatusClosed, candidates).
+				Pluck("uuid", &closed).Error
+		}
 		return nil
 	})
 	if err != nil {
@@ -78,6 +100,11 @@ func (s *MonitorSweepService) RunSweep() (*SweepResult, error) {
 
 	if result.IncidentsClosed > 0 {
 		slog.Info("monitor sweep closed expired incidents", "count", result.IncidentsClosed)
+		if s.publisher != nil {
+			for _, uuid := range closed {
+				s.publisher.PublishStatus(uuid, database.IncidentStatusClosed)
+			}
+		}
 	}
 	return result, nil
 }
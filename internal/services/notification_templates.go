@@ -0,0 +1,368 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// NotificationKind identifies one outbound notification message.
+type NotificationKind string
+
+const (
+	// NotificationAlertPosted is the banner posted when an alert opens an incident.
+	NotificationAlertPosted NotificationKind = "alert_posted"
+	// NotificationAlertRecurring is the thread note for an alert correlated into an existing incident.
+	NotificationAlertRecurring NotificationKind = "alert_recurring"
+	// NotificationAlertMerged is the thread note for a listener-channel alert merged into an existing incident.
+	NotificationAlertMerged NotificationKind = "alert_merged"
+	// NotificationAlertCascading is the parent-thread note for an alert that opened a child incident.
+	NotificationAlertCascading NotificationKind = "alert_cascading"
+	// NotificationAlertResolved is the thread note posted when a linked alert resolves.
+	NotificationAlertResolved NotificationKind = "alert_resolved"
+	// NotificationIncidentCreateFailed is the thread note posted when an incident cannot be created.
+	NotificationIncidentCreateFailed NotificationKind = "incident_create_failed"
+)
+
+// NotificationKindInfo describes a notification kind and its built-in template.
+type NotificationKindInfo struct {
+	Kind        NotificationKind `json:"kind"`
+	Description string           `json:"description"`
+	DefaultBody string           `json:"default_body"`
+}
+
+// notificationKinds lists every kind in display order. Built-in bodies
+// reproduce the messages that were hard-coded before templates existed.
+var notificationKinds = []NotificationKindInfo{
+	{
+		Kind:        NotificationAlertPosted,
+		Description: "Channel message posted when an alert opens a new incident",
+		DefaultBody: `{{.SeverityEmoji}} *Alert: {{.AlertName}}*
+
+:label: *Source:* {{.SourceDisplayName}} ({{.SourceName}})
+:computer: *Host:* {{.Host}}
+:gear: *Service:* {{.Service}}
+:warning: *Severity:* {{.Severity}}
+:memo: *Summary:* {{.Summary}}{{if .RunbookURL}}
+:book: *Runbook:* {{.RunbookURL}}{{end}}`,
+	},
+	{
+		Kind:        NotificationAlertRecurring,
+		Description: "Thread reply when an alert is correlated into an existing incident",
+		DefaultBody: `Recurring alert: {{.AlertName}}`,
+	},
+	{
+		Kind:        NotificationAlertMerged,
+		Description: "Thread reply when a listener-channel alert is merged into an existing incident",
+		DefaultBody: `{{if .IncidentTitle}}Alert merged into existing <{{.IncidentURL}}|incident>: *{{.IncidentTitle}}* — {{.AlertCount}} {{plural .AlertCount "alert" "alerts"}} linked` +
+			`{{else}}Alert merged into existing <{{.IncidentURL}}|incident> (ID: {{.IncidentUUID}}){{end}}`,
+	},
+	{
+		Kind:        NotificationAlertCascading,
+		Description: "Thread reply in the parent incident when an alert opens a child incident (cascading failure)",
+		DefaultBody: `:link: Cascading alert *{{.AlertName}}*{{if .Host}} on {{.Host}}{{end}} opened a child <{{.IncidentURL}}|incident>; its channel post is suppressed while this incident is open`,
+	},
+	{
+		Kind:        NotificationAlertResolved,
+		Description: "Thread reply when a linked alert resolves",
+		DefaultBody: `Alert resolved: {{.AlertName}}`,
+	},
+	{
+		Kind:        NotificationIncidentCreateFailed,
+		Description: "Thread reply when an incident cannot be created for an alert",
+		DefaultBody: `Failed to create incident: {{.Error}}`,
+	},
+}
+
+// maxNotificationTemplateBytes caps template bodies well under Slack's
+// 40k-character message limit.
+const maxNotificationTemplateBytes = 8000
+
+var notificationLocalePattern = regexp.MustCompile(`^[a-z]{2,3}([-_][A-Za-z0-9]{2,8})?$`)
+
+// NotificationData is the template context for every notification kind:
+// the alert variables plus incident and delivery details. Fields that do not
+// apply to a kind are empty.
+type NotificationData struct {
+	AlertTemplateVars
+
+	IncidentUUID  string
+	IncidentTitle string
+	IncidentURL   string
+	AlertCount    int64
+
+	Error   string
+	BaseURL string
+	Locale  string
+}
+
+// NotificationKinds returns every notification kind with its built-in body.
+func NotificationKinds() []NotificationKindInfo {
+	out := make([]NotificationKindInfo, len(notificationKinds))
+	copy(out, notificationKinds)
+	return out
+}
+
+// IsValidNotificationKind reports whether kind is a known notification kind.
+func IsValidNotificationKind(kind string) bool {
+	_, ok := defaultNotificationBody(NotificationKind(kind))
+	return ok
+}
+
+// DefaultNotificationBody returns the built-in template body for kind, or ""
+// for an unknown kind.
+func DefaultNotificationBody(kind NotificationKind) string {
+	body, _ := defaultNotificationBody(kind)
+	return body
+}
+
+func defaultNotificationBody(kind NotificationKind) (string, bool) {
+	for _, k := range notificationKinds {
+		if k.Kind == kind {
+			return k.DefaultBody, true
+		}
+	}
+	return "", false
+}
+
+// SampleNotificationData returns representative data for template previews
+// and validation.
+func SampleNotificationData() NotificationData {
+	return NotificationData{
+		AlertTemplateVars: SampleAlertTemplateVars(),
+		IncidentUUID:      "3f2b6c1e-8d4a-4c8e-9f1a-2b7d5e6a9c01",
+		IncidentTitle:     "CPU saturation on web-01",
+		IncidentURL:       "http://localhost:3000/incidents/3f2b6c1e-8d4a-4c8e-9f1a-2b7d5e6a9c01",
+		AlertCount:        2,
+		Error:             "database unavailable",
+		BaseURL:           "http://localhost:3000",
+	}
+}
+
+var notificationTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"default": func(def, v string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	},
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if n < 0 || len(r) <= n {
+			return s
+		}
+		return string(r[:n]) + "…"
+	},
+	"plural": func(n int64, singular, plural string) string {
+		if n == 1 {
+			return singular
+		}
+		return plural
+	},
+}
+
+// ParseNotificationTemplate parses a template body with the notification
+// function set (upper, lower, trim, default, truncate, plural). Missing map
+// keys render as the zero value, so `{{.Labels.team}}` is empty rather than
+// "<no value>" on alerts without that label.
+func ParseNotificationTemplate(body string) (*template.Template, error) {
+	return template.New("notification").Funcs(notificationTemplateFuncs).Option("missingkey=zero").Parse(body)
+}
+
+// RenderNotificationTemplate parses and executes body against data.
+func RenderNotificationTemplate(body string, data NotificationData) (string, error) {
+	return renderTemplate(body, data)
+}
+
+func renderTemplate(body string, data any) (string, error) {
+	tmpl, err := ParseNotificationTemplate(body)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// RenderDefaultNotification renders the built-in template for kind.
+func RenderDefaultNotification(kind NotificationKind, data NotificationData) string {
+	body, ok := defaultNotificationBody(kind)
+	if !ok {
+		return ""
+	}
+	out, err := RenderNotificationTemplate(body, data)
+	if err != nil {
+		// Built-in templates are covered by tests; this is a programming error.
+		slog.Error("built-in notification template failed", "kind", kind, "err", err)
+		return ""
+	}
+	return out
+}
+
+// ValidateNotificationTemplate checks that kind is known, locale is well
+// formed and body parses and renders against sample data.
+func ValidateNotificationTemplate(kind, locale, body string) error {
+	if !IsValidNotificationKind(kind) {
+		return fmt.Errorf("unknown notification kind %q", kind)
+	}
+	if locale != "" && !notificationLocalePattern.MatchString(locale) {
+		return fmt.Errorf("invalid locale %q: use a language tag such as \"de\" or \"pt-BR\"", locale)
+	}
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body cannot be empty")
+	}
+	if len(body) > maxNotificationTemplateBytes {
+		return fmt.Errorf("body exceeds %d bytes", maxNotificationTemplateBytes)
+	}
+	if _, err := RenderNotificationTemplate(body, SampleNotificationData()); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+// IsValidNotificationLocale reports whether locale is empty or a
+// well-formed language tag.
+func IsValidNotificationLocale(locale string) bool {
+	return locale == "" || notificationLocalePattern.MatchString(locale)
+}
+
+// ErrNotificationTemplateNotFound is returned when a template ID does not exist.
+var ErrNotificationTemplateNotFound = errors.New("notification template not found")
+
+// NotificationTemplateService manages operator template overrides and
+// renders notifications with them.
+type NotificationTemplateService struct {
+	db *gorm.DB
+}
+
+// NewNotificationTemplateService creates a new notification template service.
+func NewNotificationTemplateService(db *gorm.DB) *NotificationTemplateService {
+	return &NotificationTemplateService{db: db}
+}
+
+// ListTemplates returns all overrides ordered by kind then locale.
+func (s *NotificationTemplateService) ListTemplates() ([]database.NotificationTemplate, error) {
+	var out []database.NotificationTemplate
+	if err := s.db.Order("kind ASC, locale ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTemplate returns one override by ID.
+func (s *NotificationTemplateService) GetTemplate(id uint) (*database.NotificationTemplate, error) {
+	var t database.NotificationTemplate
+	if err := s.db.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationTemplateNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateTemplate validates and stores a new override. At most one override
+// exists per (kind, locale); the unique index rejects duplicates.
+func (s *NotificationTemplateService) CreateTemplate(t *database.NotificationTemplate) error {
+	if err := ValidateNotificationTemplate(t.Kind, t.Locale, t.Body); err != nil {
+		return err
+	}
+	var existing int64
+	if err := s.db.Model(&database.NotificationTemplate{}).
+		Where("kind = ? AND locale = ?", t.Kind, t.Locale).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("a template for kind %q and locale %q already exists", t.Kind, t.Locale)
+	}
+	return s.db.Create(t).Error
+}
+
+// UpdateTemplate replaces the body and enabled flag of an override.
+func (s *NotificationTemplateService) UpdateTemplate(id uint, body string, enabled bool) (*database.NotificationTemplate, error) {
+	t, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateNotificationTemplate(t.Kind, t.Locale, body); err != nil {
+		return nil, err
+	}
+	t.Body = body
+	t.Enabled = enabled
+	if err := s.db.Save(t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTemplate removes an override, restoring the built-in text.
+func (s *NotificationTemplateService) DeleteTemplate(id uint) error {
+	res := s.db.Delete(&database.NotificationTemplate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotificationTemplateNotFound
+	}
+	return nil
+}
+
+// EffectiveBody returns the template body used for kind in locale: an exact
+// locale override, then the language-only override ("pt" for "pt-BR"), then
+// the catch-all override, then the built-in template.
+func (s *NotificationTemplateService) EffectiveBody(kind NotificationKind, locale string) string {
+	def := DefaultNotificationBody(kind)
+	if s.db == nil {
+		return def
+	}
+
+	candidates := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, "")
+
+	var rows []database.NotificationTemplate
+	if err := s.db.Where("kind = ? AND enabled = ? AND locale IN ?", string(kind), true, candidates).
+		Find(&rows).Error; err != nil {
+		slog.Warn("failed to load notification templates", "kind", kind, "err", err)
+		return def
+	}
+	for _, want := range candidates {
+		for _, row := range rows {
+			if row.Locale == want {
+				return row.Body
+			}
+		}
+	}
+	return def
+}
+
+// RenderNotification renders kind with the override selected by the
+// configured NotificationLocale. A broken override is logged and the
+// built-in template is used instead, so a bad edit never drops a message.
+func (s *NotificationTemplateService) RenderNotification(kind NotificationKind, data NotificationData) string {
+	if data.Locale == "" {
+		if gs, err := database.GetOrCreateGeneralSettings(); err == nil {
+			data.Locale = gs.NotificationLocale
+		}
+	}
+	body := s.EffectiveBody(kind, data.Locale)
+	out, err := RenderNotificationTemplate(body, data)
+	if err != nil {
+		slog.Warn("notification template failed, using built-in", "kind", kind, "locale", data.Locale, "err", err)
+		return RenderDefaultNotification(kind, data)
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestDefaultNotificationTemplates_MatchLegacyText(t *testing.T) {
+	data := SampleNotificationData()
+
+	wantBanner := ":red_circle: *Alert: HighCPUUsage*\n\n" +
+		":label: *Source:* Prometheus Alertmanager (prod-alertmanager)\n" +
+		":computer: *Host:* web-01\n" +
+		":gear: *Service:* nginx\n" +
+		":warning: *Severity:* critical\n" +
+		":memo: *Summary:* CPU usage above 95% for 5 minutes\n" +
+		":book: *Runbook:* https://runbooks.example.com/cpu"
+	if got := RenderDefaultNotification(NotificationAlertPosted, data); got != wantBanner {
+		t.Errorf("alert_posted =\n%s\nwant\n%s", got, wantBanner)
+	}
+
+	noRunbook := data
+	noRunbook.RunbookURL = ""
+	if got := RenderDefaultNotification(NotificationAlertPosted, noRunbook); strings.Contains(got, "Runbook") {
+		t.Errorf("alert_posted without runbook should omit the runbook line: %q", got)
+	}
+
+	tests := []struct {
+		kind NotificationKind
+		data NotificationData
+		want string
+	}{
+		{NotificationAlertRecurring, data, "Recurring alert: HighCPUUsage"},
+		{NotificationAlertResolved, data, "Alert resolved: HighCPUUsage"},
+		{NotificationIncidentCreateFailed, data, "Failed to create incident: database unavailable"},
+		{NotificationAlertMerged, data,
+			"Alert merged into existing <" + data.IncidentURL + "|incident>: *CPU saturation on web-01* — 2 alerts linked"},
+		{NotificationAlertMerged, NotificationData{IncidentUUID: "abc", IncidentURL: "http://x/incidents/abc"},
+			"Alert merged into existing <http://x/incidents/abc|incident> (ID: abc)"},
+		{NotificationAlertMerged, NotificationData{IncidentTitle: "t", IncidentURL: "u", AlertCount: 1},
+			"Alert merged into existing <u|incident>: *t* — 1 alert linked"},
+	}
+	for _, tt := range tests {
+		if got := RenderDefaultNotification(tt.kind, tt.data); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNotificationTemplate(t *testing.T) {
+	tests := []struct {
+		name, kind, locale, body string
+		wantErr                  bool
+	}{
+		{"valid", "alert_posted", "", "{{.AlertName}} team={{.Labels.team}}", false},
+		{"valid locale", "alert_resolved", "pt-BR", "Alerta resolvido: {{.AlertName}}", false},
+		{"funcs", "alert_posted", "de", `{{upper .Severity}} {{default "n/a" .Service}} {{truncate 3 .Summary}}`, false},
+		{"unknown kind", "digest", "", "x", true},
+		{"bad locale", "alert_posted", "english!", "x", true},
+		{"empty body", "alert_posted", "", "  ", true},
+		{"parse error", "alert_posted", "", "{{.AlertName", true},
+		{"unknown field", "alert_posted", "", "{{.Nope}}", true},
+		{"too long", "alert_posted", "", strings.Repeat("x", maxNotificationTemplateBytes+1), true},
+	}
+	for _, tt := range tests {
+		err := ValidateNotificationTemplate(tt.kind, tt.locale, tt.body)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNotificationTemplateService_LocaleFallback(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.NotificationTemplate{}, &database.GeneralSettings{})
+	svc := NewNotificationTemplateService(db)
+
+	for _, tmpl := range []*database.NotificationTemplate{
+		{Kind: "alert_resolved", Locale: "", Body: "ACME: resolved {{.AlertName}}", Enabled: true},
+		{Kind: "alert_resolved", Locale: "pt", Body: "Resolvido: {{.AlertName}}", Enabled: true},
+		{Kind: "alert_resolved", Locale: "de", Body: "Behoben: {{.AlertName}}", Enabled: false},
+	} {
+		if err := svc.CreateTemplate(tmpl); err != nil {
+			t.Fatalf("CreateTemplate(%s): %v", tmpl.Locale, err)
+		}
+	}
+	if err := svc.CreateTemplate(&database.NotificationTemplate{Kind: "alert_resolved", Locale: "pt", Body: "dup", Enabled: true}); err == nil {
+		t.Error("expected duplicate (kind, locale) to be rejected")
+	}
+
+	data := NotificationData{AlertTemplateVars: AlertTemplateVars{AlertName: "DiskFull"}}
+	tests := []struct {
+		locale, want string
+	}{
+		{"pt-BR", "Resolvido: DiskFull"}, // language-only fallback
+		{"pt", "Resolvido: DiskFull"},
+		{"de", "ACME: resolved DiskFull"}, // disabled override skipped
+		{"", "ACME: resolved DiskFull"},
+	}
+	for _, tt := range tests {
+		data.Locale = tt.locale
+		if got := svc.RenderNotification(NotificationAlertResolved, data); got != tt.want {
+			t.Errorf("locale %q: got %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	// Kinds without an override keep the built-in text.
+	if got := svc.RenderNotification(NotificationAlertRecurring, data); got != "Recurring alert: DiskFull" {
+		t.Errorf("built-in fallback = %q", got)
+	}
+
+	// The configured locale is picked up from general settings.
+	gs, err := database.GetOrCreateGeneralSettings()
+	if err != nil {
+		t.Fatalf("general settings: %v", err)
+	}
+	gs.NotificationLocale = "pt-BR"
+	if err := database.UpdateGeneralSettings(gs); err != nil {
+		t.Fatalf("update general settings: %v", err)
+	}
+	data.Locale = ""
+	if got := svc.RenderNotification(NotificationAlertResolved, data); got != "Resolvido: DiskFull" {
+		t.Errorf("settings locale: got %q", got)
+	}
+}
+
+func TestNotificationTemplateService_BrokenOverrideFallsBack(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.NotificationTemplate{}, &database.GeneralSettings{})
+	svc := NewNotificationTemplateService(db)
+
+	// Bypass validation to simulate a row that fails at render time.
+	if err := db.Create(&database.NotificationTemplate{Kind: "alert_resolved", Body: "{{.Missing}}", Enabled: true}).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	got := svc.RenderNotification(NotificationAlertResolved, NotificationData{AlertTemplateVars: AlertTemplateVars{AlertName: "X"}})
+	if got != "Alert resolved: X" {
+		t.Errorf("got %q, want built-in text", got)
+	}
+}
+
+func TestNotificationTemplateService_UpdateAndDelete(t *testing.T) {
+	db := testhelpers.NewGlobalSQLiteDB(t, &database.NotificationTemplate{})
+	svc := NewNotificationTemplateService(db)
+
+	tmpl := &database.NotificationTemplate{Kind: "alert_posted", Body: "{{.AlertName}}", Enabled: true}
+	if err := svc.CreateTemplate(tmpl); err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	if _, err := svc.UpdateTemplate(tmpl.ID, "{{.AlertName", true); err == nil {
+		t.Error("expected invalid body to be rejected on update")
+	}
+	updated, err := svc.UpdateTemplate(tmpl.ID, "[{{.Severity}}] {{.AlertName}}", false)
+	if err != nil {
+		t.Fatalf("UpdateTemplate: %v", err)
+	}
+	if updated.Enabled || updated.Body != "[{{.Severity}}] {{.AlertName}}" {
+		t.Errorf("updated = %+v", updated)
+	}
+	if err := svc.DeleteTemplate(tmpl.ID); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	if err := svc.DeleteTemplate(tmpl.ID); err != ErrNotificationTemplateNotFound {
+		t.Errorf("second delete err = %v, want ErrNotificationTemplateNotFound", err)
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	pagerDutyDefaultURL   = "https://api.pagerduty.com"
+	pagerDutySyncTimeout  = 30 * time.Second
+	pagerDutyNoteMaxChars = 2000
+)
+
+// errPagerDutySyncDisabled means no enabled PagerDuty tool instance has
+// pagerduty_sync_incidents turned on.
+var errPagerDutySyncDisabled = errors.New("pagerduty sync is not enabled")
+
+// pagerDutySyncConfig is read from the first enabled "pagerduty" tool
+// instance with pagerduty_sync_incidents set.
+type pagerDutySyncConfig struct {
+	URL       string
+	APIToken  string
+	FromEmail string
+}
+
+// PagerDutySync mirrors an Akmatori incident's lifecycle onto the PagerDuty
+// incidents whose webhooks created it: the PagerDuty incident is
+// acknowledged when the investigation starts, receives the findings as a
+// note when it finishes, and is resolved when the Akmatori incident closes.
+//
+// It is wired as an IncidentEventPublisher next to the live stream hub, so
+// it sees every status transition SkillService publishes. PagerDuty calls
+// run in the background and failures are only logged.
+type PagerDutySync struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewPagerDutySync creates a PagerDuty sync publisher.
+func NewPagerDutySync(db *gorm.DB) *PagerDutySync {
+	return &PagerDutySync{
+		db:     db,
+		client: &http.Client{Timeout: pagerDutySyncTimeout},
+	}
+}
+
+// PublishLog is a no-op; only status transitions are mirrored.
+func (p *PagerDutySync) PublishLog(string, string) {}
+
+// PublishStatus mirrors status onto the incident's PagerDuty incidents in
+// the background.
+func (p *PagerDutySync) PublishStatus(incidentUUID string, status database.IncidentStatus) {
+	switch status {
+	case database.IncidentStatusRunning, database.IncidentStatusCompleted, database.IncidentStatusMonitor,
+		database.IncidentStatusFailed, database.IncidentStatusClosed:
+	default:
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*pagerDutySyncTimeout)
+		defer cancel()
+		if err := p.Sync(ctx, incidentUUID, status); err != nil && !errors.Is(err, errPagerDutySyncDisabled) {
+			slog.Warn("pagerduty sync failed", "incident_uuid", incidentUUID, "status", status, "err", err)
+		}
+	}()
+}
+
+// Sync applies status to every PagerDuty incident linked to incidentUUID.
+// Incidents without PagerDuty alerts are skipped.
+func (p *PagerDutySync) Sync(ctx context.Context, incidentUUID string, status database.IncidentStatus) error {
+	cfg, err := p.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	pdIDs, err := p.linkedPagerDutyIncidents(ctx, incidentUUID)
+	if err != nil {
+		return fmt.Errorf("load linked pagerduty incidents: %w", err)
+	}
+	if len(pdIDs) == 0 {
+		return nil
+	}
+
+	var note string
+	switch status {
+	case database.IncidentStatusCompleted, database.IncidentStatusMonitor, database.IncidentStatusFailed:
+		note, err = p.findingsNote(ctx, incidentUUID, status)
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, pdID := range pdIDs {
+		switch status {
+		case database.IncidentStatusRunning:
+			err = p.updateStatus(ctx, cfg, pdID, "acknowledged")
+		case database.IncidentStatusClosed:
+			err = p.updateStatus(ctx, cfg, pdID, "resolved")
+		default:
+			err = p.addNote(ctx, cfg, pdID, note)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pdID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *PagerDutySync) loadConfig(ctx context.Context) (*pagerDutySyncConfig, error) {
+	var instances []database.ToolInstance
+	err := p.db.WithContext(ctx).
+		Joins("JOIN tool_types ON tool_types.id = tool_instances.tool_type_id").
+		Where("tool_types.name = ? AND tool_instances.enabled = ?", "pagerduty", true).
+		Order("tool_instances.id ASC").
+		Find(&instances).Error
+	if err != nil {
+		return nil, fmt.Errorf("load pagerduty tool instances: %w", err)
+	}
+	for _, inst := range instances {
+		if enabled, _ := inst.Settings["pagerduty_sync_incidents"].(bool); !enabled {
+			continue
+		}
+		token, _ := inst.Settings["pagerduty_api_token"].(string)
+		from, _ := inst.Settings["pagerduty_from_email"].(string)
+		if token == "" || strings.TrimSpace(from) == "" {
+			slog.Warn("pagerduty sync enabled without api token or from email", "tool_instance", inst.Name)
+			continue
+		}
+		cfg := &pagerDutySyncConfig{URL: pagerDutyDefaultURL, APIToken: token, FromEmail: strings.TrimSpace(from)}
+		if u, ok := inst.Settings["pagerduty_url"].(string); ok && u != "" {
+			cfg.URL = strings.TrimRight(u, "/")
+		}
+		return cfg, nil
+	}
+	return nil, errPagerDutySyncDisabled
+}
+
+// linkedPagerDutyIncidents returns the PagerDuty incident IDs of alerts that
+// arrived through PagerDuty alert sources and are attached to incidentUUID.
+// The PagerDuty adapter stores the PagerDuty incident ID as the alert's
+// source fingerprint.
+func (p *PagerDutySync) linkedPagerDutyIncidents(ctx context.Context, incidentUUID string) ([]string, error) {
+	var ids []string
+	err := p.db.WithContext(ctx).Model(&database.Alert{}).
+		Distinct("alerts.source_fingerprint").
+		Joins("JOIN alert_source_instances ON alert_source_instances.uuid = alerts.source_uuid").
+		Joins("JOIN alert_source_types ON alert_source_types.id = alert_source_instances.alert_source_type_id").
+		Where("alerts.incident_uuid = ? AND alert_source_types.name = ? AND alerts.source_fingerprint <> ''", incidentUUID, "pagerduty").
+		Pluck("alerts.source_fingerprint", &ids).Error
+	return ids, err
+}
+
+func (p *PagerDutySync) findingsNote(ctx context.Context, incidentUUID string, status database.IncidentStatus) (string, error) {
+	var incident database.Incident
+	if err := p.db.WithContext(ctx).Select("uuid", "title", "response").
+		Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return "", fmt.Errorf("load incident: %w", err)
+	}
+
+	var b strings.Builder
+	if status == database.IncidentStatusFailed {
+		b.WriteString("Akmatori investigation failed.")
+	} else {
+		b.WriteString("Akmatori investigation finished.")
+	}
+	response := strings.TrimSpace(strings.NewReplacer("[FINAL_RESULT]", "", "[/FINAL_RESULT]", "").Replace(incident.Response))
+	if response != "" {
+		runes := []rune(response)
+		if len(runes) > pagerDutyNoteMaxChars {
+			response = string(runes[:pagerDutyNoteMaxChars]) + "…"
+		}
+		b.WriteString("\n\n")
+		b.WriteString(response)
+	}
+	if link := akmatoriIncidentLink(incidentUUID); link != "" {
+		b.WriteString("\n\n")
+		b.WriteString(link)
+	}
+	return b.String(), nil
+}
+
+func (p *PagerDutySync) updateStatus(ctx context.Context, cfg *pagerDutySyncConfig, pdID, status string) error {
+	body := map[string]interface{}{
+		"incident": map[string]interface{}{"type": "incident_reference", "status": status},
+	}
+	return p.do(ctx, cfg, http.MethodPut, "/incidents/"+url.PathEscape(pdID), body)
+}
+
+func (p *PagerDutySync) addNote(ctx context.Context, cfg *pagerDutySyncConfig, pdID, content string) error {
+	body := map[string]interface{}{"note": map[string]interface{}{"content": content}}
+	return p.do(ctx, cfg, http.MethodPost, "/incidents/"+url.PathEscape(pdID)+"/notes", body)
+}
+
+func (p *PagerDutySync) do(ctx context.Context, cfg *pagerDutySyncConfig, method, path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token token="+cfg.APIToken)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("From", cfg.FromEmail)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pagerduty %s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// akmatoriIncidentLink builds the UI link for an incident, or returns ""
+// when no base URL is configured (a localhost link is of no use to a
+// PagerDuty responder).
+func akmatoriIncidentLink(incidentUUID string) string {
+	if ConfiguredBaseURL() == "" {
+		return ""
+	}
+	return IncidentURL(incidentUUID)
+}
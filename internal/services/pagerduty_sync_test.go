@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+type pagerDutyCall struct {
+	Method string
+	Path   string
+	From   string
+	Body   map[string]interface{}
+}
+
+func setupPagerDutySync(t *testing.T, syncEnabled bool) (*PagerDutySync, *[]pagerDutyCall, *gorm.DB) {
+	t.Helper()
+	db := setupIncidentTestDB(t)
+	if err := db.AutoMigrate(&database.AlertSourceType{}, &database.AlertSourceInstance{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	// The shared in-memory database outlives each test.
+	for _, model := range []interface{}{&database.Alert{}, &database.Incident{}, &database.IncidentLogChunk{}, &database.AlertSourceInstance{},
+		&database.AlertSourceType{}, &database.ToolInstance{}, &database.ToolType{}} {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model)
+	}
+	t.Setenv("AKMATORI_BASE_URL", "https://akmatori.example.com")
+
+	var (
+		mu    sync.Mutex
+		calls []pagerDutyCall
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		calls = append(calls, pagerDutyCall{Method: r.Method, Path: r.URL.Path, From: r.Header.Get("From"), Body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	toolType := database.ToolType{Name: "pagerduty"}
+	db.Create(&toolType)
+	db.Create(&database.ToolInstance{
+		ToolTypeID:  toolType.ID,
+		Name:        "pd",
+		LogicalName: "pd",
+		Enabled:     true,
+		Settings: database.JSONB{
+			"pagerduty_api_token":      "tok",
+			"pagerduty_url":            server.URL,
+			"pagerduty_from_email":     "akmatori@example.com",
+			"pagerduty_sync_incidents": syncEnabled,
+		},
+	})
+
+	sourceType := database.AlertSourceType{Name: "pagerduty", DisplayName: "PagerDuty"}
+	db.Create(&sourceType)
+	db.Create(&database.AlertSourceInstance{UUID: "src-pd", AlertSourceTypeID: sourceType.ID, Name: "PD"})
+	db.Create(&database.Incident{UUID: "inc-1", Title: "API 5xx", Response: "[FINAL_RESULT]Bad deploy, rolled back.[/FINAL_RESULT]"})
+	db.Create(&database.Alert{UUID: "a-1", IncidentUUID: "inc-1", SourceUUID: "src-pd", SourceFingerprint: "PD1"})
+	db.Create(&database.Alert{UUID: "a-2", IncidentUUID: "inc-1", SourceUUID: "src-pd", SourceFingerprint: "PD1"})
+
+	return NewPagerDutySync(db), &calls, db
+}
+
+func TestPagerDutySync_MirrorsLifecycle(t *testing.T) {
+	pd, calls, _ := setupPagerDutySync(t, true)
+	ctx := context.Background()
+
+	for _, status := range []database.IncidentStatus{
+		database.IncidentStatusRunning,
+		database.IncidentStatusCompleted,
+		database.IncidentStatusClosed,
+	} {
+		if err := pd.Sync(ctx, "inc-1", status); err != nil {
+			t.Fatalf("Sync(%s): %v", status, err)
+		}
+	}
+
+	if len(*calls) != 3 {
+		t.Fatalf("calls = %+v, want 3 (one per status, alerts deduplicated)", *calls)
+	}
+	ack, note, resolve := (*calls)[0], (*calls)[1], (*calls)[2]
+
+	if ack.Method != http.MethodPut || ack.Path != "/incidents/PD1" || ack.From != "akmatori@example.com" {
+		t.Errorf("ack call = %+v", ack)
+	}
+	if got := ack.Body["incident"].(map[string]interface{})["status"]; got != "acknowledged" {
+		t.Errorf("ack status = %v", got)
+	}
+
+	if note.Method != http.MethodPost || note.Path != "/incidents/PD1/notes" {
+		t.Errorf("note call = %+v", note)
+	}
+	content, _ := note.Body["note"].(map[string]interface{})["content"].(string)
+	if !strings.Contains(content, "Bad deploy, rolled back.") || strings.Contains(content, "[FINAL_RESULT]") ||
+		!strings.Contains(content, "https://akmatori.example.com/incidents/inc-1") {
+		t.Errorf("note content = %q", content)
+	}
+
+	if got := resolve.Body["incident"].(map[string]interface{})["status"]; got != "resolved" {
+		t.Errorf("resolve status = %v", got)
+	}
+}
+
+func TestPagerDutySync_Disabled(t *testing.T) {
+	pd, calls, _ := setupPagerDutySync(t, false)
+
+	err := pd.Sync(context.Background(), "inc-1", database.IncidentStatusRunning)
+	if !errors.Is(err, errPagerDutySyncDisabled) {
+		t.Fatalf("err = %v, want errPagerDutySyncDisabled", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("calls = %+v, want none", *calls)
+	}
+}
+
+func TestPagerDutySync_SkipsNonPagerDutyIncidents(t *testing.T) {
+	pd, calls, db := setupPagerDutySync(t, true)
+	db.Create(&database.Incident{UUID: "inc-2", Title: "Disk full"})
+	db.Create(&database.Alert{UUID: "a-3", IncidentUUID: "inc-2", SourceUUID: "src-other", SourceFingerprint: "abc"})
+
+	if err := pd.Sync(context.Background(), "inc-2", database.IncidentStatusRunning); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("calls = %+v, want none", *calls)
+	}
+}
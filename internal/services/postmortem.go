@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Postmortem generation bounds. The call runs while the operator waits on
+// the request, so it gets more room than the Slack-path one-shots but not
+// an investigation's worth.
+const (
+	postmortemTimeout        = 2 * time.Minute
+	postmortemMaxTokens      = 3000
+	postmortemMaxEvents      = 200
+	postmortemMaxAlerts      = 50
+	postmortemResponseRunes  = 12000
+	postmortemSummaryRunes   = 300
+	postmortemMaxActionItems = 20
+)
+
+var (
+	// ErrIncidentNotFinished is returned when a postmortem is requested for
+	// an incident whose investigation is still pending or running.
+	ErrIncidentNotFinished = errors.New("incident investigation has not finished")
+	// ErrInvalidPostmortem is returned when the model's reply is not a
+	// usable postmortem.
+	ErrInvalidPostmortem = errors.New("model returned an invalid postmortem")
+)
+
+// PostmortemGenerator writes a structured postmortem (impact, root cause,
+// timeline, action items) for a finished incident by feeding its timeline,
+// alerts and final response through a one-shot LLM call, and stores it in
+// incident_reports.
+type PostmortemGenerator struct {
+	caller OneShotLLMCaller
+	db     *gorm.DB
+}
+
+// NewPostmortemGenerator creates a generator. A nil caller makes GenerateReport
+// return ErrWorkerNotConnected.
+func NewPostmortemGenerator(caller OneShotLLMCaller, db *gorm.DB) *PostmortemGenerator {
+	return &PostmortemGenerator{caller: caller, db: db}
+}
+
+// GenerateReport writes (or rewrites) the incident's postmortem. author is
+// recorded as generated_by. Returns gorm.ErrRecordNotFound for an unknown
+// incident and ErrIncidentNotFinished while it is still being investigated.
+func (g *PostmortemGenerator) GenerateReport(ctx context.Context, incidentUUID, author string) (*database.IncidentReport, error) {
+	var incident database.Incident
+	if err := g.db.WithContext(ctx).Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return nil, err
+	}
+	if incident.Status == database.IncidentStatusPending || incident.Status == database.IncidentStatusRunning {
+		return nil, ErrIncidentNotFinished
+	}
+	if g.caller == nil {
+		return nil, ErrWorkerNotConnected
+	}
+	settings, err := database.GetLLMSettings()
+	if err != nil {
+		return nil, fmt.Errorf("load llm settings: %w", err)
+	}
+	if settings == nil || settings.APIKey == "" {
+		return nil, ErrWorkerNotConnected
+	}
+	worker := BuildLLMSettingsForWorker(settings)
+	if worker == nil {
+		return nil, ErrWorkerNotConnected
+	}
+
+	var alerts []database.Alert
+	if err := g.db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).
+		Order("fired_at").Limit(postmortemMaxAlerts).Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("load alerts: %w", err)
+	}
+	var events []database.IncidentEvent
+	if err := g.db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).
+		Order("occurred_at, id").Limit(postmortemMaxEvents).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("load timeline: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, postmortemTimeout)
+	defer cancel()
+	raw, err := g.caller.OneShotLLM(callCtx, worker, postmortemSystemPrompt,
+		buildPostmortemPrompt(&incident, alerts, events), postmortemMaxTokens, 0.2)
+	if err != nil {
+		if errors.Is(err, ErrWorkerNotConnected) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("postmortem llm call: %w", err)
+	}
+
+	report, err := parsePostmortem(raw)
+	if err != nil {
+		return nil, err
+	}
+	report.IncidentUUID = incidentUUID
+	report.Title = incident.Title
+	report.GeneratedBy = author
+
+	if err := g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "incident_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"title", "summary", "impact", "root_cause", "timeline", "action_items", "generated_by", "updated_at",
+		}),
+	}).Create(report).Error; err != nil {
+		return nil, fmt.Errorf("store postmortem: %w", err)
+	}
+	return g.GetReport(ctx, incidentUUID)
+}
+
+// GetReport returns the incident's stored postmortem, or
+// gorm.ErrRecordNotFound when none has been generated.
+func (g *PostmortemGenerator) GetReport(ctx context.Context, incidentUUID string) (*database.IncidentReport, error) {
+	var report database.IncidentReport
+	if err := g.db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+const postmortemSystemPrompt = `You write blameless post-incident reports (postmortems) for an SRE team.
+
+You are given an incident's title, its alerts, a timeline of what happened (status changes, alerts attached, commands the investigating agent ran, Slack posts, operator notes) and the investigation's final response.
+
+Return STRICT JSON:
+  {
+    "summary": "<2-3 sentence overview>",
+    "impact": "<who or what was affected, how badly, for how long>",
+    "root_cause": "<the underlying cause; say plainly if it is unconfirmed>",
+    "timeline": [{"time": "<timestamp as given>", "event": "<what happened>"}],
+    "action_items": [{"action": "<concrete follow-up>", "owner": "<team or role, optional>", "priority": "high|medium|low"}]
+  }
+
+Rules:
+  - Use only facts present in the input. Do not invent hosts, metrics or times.
+  - The timeline keeps the key moments only (detection, diagnosis, mitigation, resolution), oldest first, with timestamps copied from the input.
+  - Action items prevent recurrence or speed up detection; 1-8 items.
+  - Output JSON only. No code fences.`
+
+func buildPostmortemPrompt(incident *database.Incident, alerts []database.Alert, events []database.IncidentEvent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Incident: %s\nStatus: %s\nStarted: %s\n", incident.Title, incident.Status, incident.StartedAt.UTC().Format(time.RFC3339))
+	if incident.CompletedAt != nil {
+		fmt.Fprintf(&b, "Investigation completed: %s\n", incident.CompletedAt.UTC().Format(time.RFC3339))
+	}
+
+	b.WriteString("\nAlerts:\n")
+	if len(alerts) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for _, a := range alerts {
+		fmt.Fprintf(&b, "- %s %s", a.FiredAt.UTC().Format(time.RFC3339), a.AlertName)
+		if a.TargetHost != "" {
+			fmt.Fprintf(&b, " on %s", a.TargetHost)
+		}
+		fmt.Fprintf(&b, " [%s]", a.Status)
+		if a.ResolvedAt != nil {
+			fmt.Fprintf(&b, " resolved %s", a.ResolvedAt.UTC().Format(time.RFC3339))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nTimeline:\n")
+	if len(events) == 0 {
+		b.WriteString("(no events recorded)\n")
+	}
+	for _, e := range events {
+		summary := truncateForPrompt(e.Summary, postmortemSummaryRunes)
+		if e.Type == database.IncidentEventNote {
+			if text, ok := e.Details["text"].(string); ok {
+				summary = truncateForPrompt(text, postmortemSummaryRunes)
+			}
+		}
+		fmt.Fprintf(&b, "- %s [%s] %s: %s\n", e.OccurredAt.UTC().Format(time.RFC3339), e.Type, e.Actor, summary)
+	}
+
+	response := strings.TrimSpace(incident.Response)
+	if response == "" {
+		response = "(no final response)"
+	}
+	fmt.Fprintf(&b, "\nFinal response:\n%s\n", truncateForPrompt(response, postmortemResponseRunes))
+	return b.String()
+}
+
+func parsePostmortem(raw string) (*database.IncidentReport, error) {
+	cleaned := strings.TrimSpace(raw)
+	cleaned = strings.TrimPrefix(cleaned, "```json")
+	cleaned = strings.TrimPrefix(cleaned, "```")
+	cleaned = strings.TrimSuffix(cleaned, "```")
+	cleaned = strings.TrimSpace(cleaned)
+
+	var out struct {
+		Summary     string                             `json:"summary"`
+		Impact      string                             `json:"impact"`
+		RootCause   string                             `json:"root_cause"`
+		Timeline    []database.PostmortemTimelineEntry `json:"timeline"`
+		ActionItems []database.PostmortemActionItem    `json:"action_items"`
+	}
+	if err := json.Unmarshal([]byte(cleaned), &out); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPostmortem, err)
+	}
+	if strings.TrimSpace(out.RootCause) == "" && strings.TrimSpace(out.Summary) == "" {
+		return nil, fmt.Errorf("%w: no summary or root cause", ErrInvalidPostmortem)
+	}
+	if len(out.ActionItems) > postmortemMaxActionItems {
+		out.ActionItems = out.ActionItems[:postmortemMaxActionItems]
+	}
+	return &database.IncidentReport{
+		Summary:     strings.TrimSpace(out.Summary),
+		Impact:      strings.TrimSpace(out.Impact),
+		RootCause:   strings.TrimSpace(out.RootCause),
+		Timeline:    database.PostmortemTimeline(out.Timeline),
+		ActionItems: database.PostmortemActionItems(out.ActionItems),
+	}, nil
+}
+
+// RenderPostmortemMarkdown renders a stored postmortem as a Markdown
+// document for download.
+func RenderPostmortemMarkdown(report *database.IncidentReport) string {
+	var b strings.Builder
+	title := report.Title
+	if title == "" {
+		title = report.IncidentUUID
+	}
+	fmt.Fprintf(&b, "# Postmortem: %s\n\n", title)
+	fmt.Fprintf(&b, "_Incident %s — generated %s_\n\n", report.IncidentUUID, report.UpdatedAt.UTC().Format("2006-01-02 15:04 MST"))
+
+	section := func(heading, body string) {
+		if body == "" {
+			body = "_Not determined._"
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", heading, body)
+	}
+	section("Summary", report.Summary)
+	section("Impact", report.Impact)
+	section("Root cause", report.RootCause)
+
+	b.WriteString("## Timeline\n\n")
+	if len(report.Timeline) == 0 {
+		b.WriteString("_No timeline._\n\n")
+	} else {
+		b.WriteString("| Time | Event |\n| --- | --- |\n")
+		for _, e := range report.Timeline {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCell(e.Time), markdownCell(e.Event))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Action items\n\n")
+	if len(report.ActionItems) == 0 {
+		b.WriteString("_None._\n")
+	}
+	for _, item := range report.ActionItems {
+		fmt.Fprintf(&b, "- [ ] %s", item.Action)
+		var meta []string
+		if item.Priority != "" {
+			meta = append(meta, "priority: "+item.Priority)
+		}
+		if item.Owner != "" {
+			meta = append(meta, "owner: "+item.Owner)
+		}
+		if len(meta) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(meta, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// markdownCell keeps a value on one table row.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPostmortemTest(t *testing.T) (*gorm.DB, *fakeOneShotLLMCaller) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&database.LLMSettings{}, &database.Incident{}, &database.IncidentLogChunk{}, &database.Alert{},
+		&database.IncidentEvent{}, &database.IncidentReport{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	database.DB = db
+	if err := db.Create(&database.LLMSettings{
+		Name:     "test",
+		Provider: database.LLMProviderAnthropic,
+		APIKey:   "test-key",
+		Model:    "claude-sonnet-4-6",
+		Active:   true,
+		Enabled:  true,
+	}).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db, &fakeOneShotLLMCaller{}
+}
+
+func TestPostmortemGenerator_GenerateAndRegenerate(t *testing.T) {
+	db, caller := setupPostmortemTest(t)
+	started := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	db.Create(&database.Incident{UUID: "pm-1", Title: "Disk full on db-1", Status: database.IncidentStatusCompleted,
+		StartedAt: started, Response: "Cleared WAL archive; root cause was a stuck archive_command."})
+	db.Create(&database.Alert{UUID: "pm-a1", IncidentUUID: "pm-1", AlertName: "DiskFull", TargetHost: "db-1",
+		Status: database.AlertStatusFiring, FiredAt: started})
+	db.Create(&database.IncidentEvent{IncidentUUID: "pm-1", Type: database.IncidentEventNote, Actor: "alice",
+		Summary: "paged dba", Details: database.JSONB{"text": "paged the DBA on call"}, OccurredAt: started.Add(time.Minute)})
+
+	caller.respond = func(ctx context.Context) (string, error) {
+		return "```json\n" + `{"summary":"db-1 ran out of disk.","impact":"Writes failed for 20m.","root_cause":"archive_command was stuck.",
+"timeline":[{"time":"10:00","event":"DiskFull fired"}],"action_items":[{"action":"Alert on archive lag","owner":"dba","priority":"high"}]}` + "\n```", nil
+	}
+	g := NewPostmortemGenerator(caller, db)
+
+	report, err := g.GenerateReport(context.Background(), "pm-1", "alice")
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report.RootCause != "archive_command was stuck." || report.Title != "Disk full on db-1" || report.GeneratedBy != "alice" {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Timeline) != 1 || len(report.ActionItems) != 1 || report.ActionItems[0].Priority != "high" {
+		t.Errorf("timeline/action items not stored: %+v", report)
+	}
+	for _, want := range []string{"DiskFull on db-1", "alice: paged the DBA on call", "stuck archive_command"} {
+		if !strings.Contains(caller.lastUser, want) {
+			t.Errorf("prompt missing %q:\n%s", want, caller.lastUser)
+		}
+	}
+
+	caller.respond = func(ctx context.Context) (string, error) {
+		return `{"summary":"s","root_cause":"revised"}`, nil
+	}
+	if _, err := g.GenerateReport(context.Background(), "pm-1", "bob"); err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	var count int64
+	db.Model(&database.IncidentReport{}).Where("incident_uuid = ?", "pm-1").Count(&count)
+	stored, err := g.GetReport(context.Background(), "pm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || stored.RootCause != "revised" || stored.GeneratedBy != "bob" || len(stored.ActionItems) != 0 {
+		t.Errorf("regenerate should replace the report: count=%d %+v", count, stored)
+	}
+}
+
+func TestPostmortemGenerator_Errors(t *testing.T) {
+	db, caller := setupPostmortemTest(t)
+	db.Create(&database.Incident{UUID: "pm-running", Title: "t", Status: database.IncidentStatusRunning})
+	db.Create(&database.Incident{UUID: "pm-done", Title: "t", Status: database.IncidentStatusFailed})
+	g := NewPostmortemGenerator(caller, db)
+	ctx := context.Background()
+
+	if _, err := g.GenerateReport(ctx, "missing", ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("missing incident: err = %v", err)
+	}
+	if _, err := g.GenerateReport(ctx, "pm-running", ""); !errors.Is(err, ErrIncidentNotFinished) {
+		t.Errorf("running incident: err = %v", err)
+	}
+	caller.respond = func(ctx context.Context) (string, error) { return "I could not write this.", nil }
+	if _, err := g.GenerateReport(ctx, "pm-done", ""); !errors.Is(err, ErrInvalidPostmortem) {
+		t.Errorf("unparseable reply: err = %v", err)
+	}
+	if _, err := g.GetReport(ctx, "pm-done"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("failed generation must not store a report: err = %v", err)
+	}
+	if _, err := NewPostmortemGenerator(nil, db).GenerateReport(ctx, "pm-done", ""); !errors.Is(err, ErrWorkerNotConnected) {
+		t.Errorf("nil caller: err = %v", err)
+	}
+}
+
+func TestRenderPostmortemMarkdown(t *testing.T) {
+	md := RenderPostmortemMarkdown(&database.IncidentReport{
+		IncidentUUID: "pm-1",
+		Title:        "Disk full",
+		Summary:      "db-1 ran out of disk.",
+		Timeline:     database.PostmortemTimeline{{Time: "10:00", Event: "fired | paged\noncall"}},
+		ActionItems:  database.PostmortemActionItems{{Action: "Alert on archive lag", Owner: "dba", Priority: "high"}},
+	})
+	for _, want := range []string{
+		"# Postmortem: Disk full",
+		"## Root cause\n\n_Not determined._",
+		`| 10:00 | fired \| paged oncall |`,
+		"- [ ] Alert on archive lag (priority: high, owner: dba)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressLogThrottle batches the full_log progress writes an investigation
+// makes on every agent output event. A write goes through immediately when
+// at least minInterval has passed since the previous one and the log grew
+// by at least minDelta bytes; otherwise the latest log is held and flushed
+// by a timer, so the stored log trails the agent by at most about one
+// interval and the last chunk before a pause is never lost.
+//
+// Each update carries the complete log, so only the most recent pending
+// value matters — intermediate ones are simply dropped. When appendLog is
+// set and the log only grew since the previous write, just the new tail is
+// sent to it, so a long investigation does not rewrite its whole log on
+// every flush.
+type progressLogThrottle struct {
+	minInterval time.Duration
+	minDelta    int
+	write       func(incidentUUID, fullLog string) error
+	appendLog   func(incidentUUID, delta, fullLog string) error
+
+	mu      sync.Mutex
+	entries map[string]*progressLogEntry
+}
+
+type progressLogEntry struct {
+	// mu is held across the write itself so a timer flush can never land
+	// after settle has handed the incident over to its final status update.
+	mu         sync.Mutex
+	lastWrite  time.Time
+	lastLen    int
+	last       string
+	pending    string
+	hasPending bool
+	timer      *time.Timer
+}
+
+func newProgressLogThrottle(minInterval time.Duration, minDelta int, write func(incidentUUID, fullLog string) error) *progressLogThrottle {
+	return &progressLogThrottle{
+		minInterval: minInterval,
+		minDelta:    minDelta,
+		write:       write,
+		entries:     make(map[string]*progressLogEntry),
+	}
+}
+
+// update records fullLog for incidentUUID, writing it now or scheduling a
+// batched flush.
+func (t *progressLogThrottle) update(incidentUUID, fullLog string) error {
+	t.mu.Lock()
+	e, ok := t.entries[incidentUUID]
+	if !ok {
+		e = &progressLogEntry{}
+		t.entries[incidentUUID] = e
+	}
+	t.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	since := time.Since(e.lastWrite)
+	if e.lastWrite.IsZero() || (since >= t.minInterval && len(fullLog)-e.lastLen >= t.minDelta) {
+		if e.timer != nil {
+			e.timer.Stop()
+			e.timer = nil
+		}
+		e.pending, e.hasPending = "", false
+		return t.writeLocked(e, incidentUUID, fullLog)
+	}
+
+	e.pending, e.hasPending = fullLog, true
+	if e.timer == nil {
+		wait := t.minInterval - since
+		if wait <= 0 {
+			// The interval has passed but the delta is still below
+			// minDelta; give it one more interval to grow.
+			wait = t.minInterval
+		}
+		e.timer = time.AfterFunc(wait, func() { t.flush(incidentUUID) })
+	}
+	return nil
+}
+
+// flush writes the pending log for incidentUUID, if any. Called by the
+// batch timer.
+func (t *progressLogThrottle) flush(incidentUUID string) {
+	t.mu.Lock()
+	e, ok := t.entries[incidentUUID]
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timer = nil
+	if !e.hasPending {
+		return
+	}
+	fullLog := e.pending
+	e.pending, e.hasPending = "", false
+	if err := t.writeLocked(e, incidentUUID, fullLog); err != nil {
+		slog.Error("failed to flush incident progress log", "incident_uuid", incidentUUID, "err", err)
+	}
+}
+
+// settle stops tracking incidentUUID ahead of a status update. With keep
+// set the pending log is written first; otherwise it is dropped because the
+// caller is about to store a more complete one.
+func (t *progressLogThrottle) settle(incidentUUID string, keep bool) {
+	t.mu.Lock()
+	e, ok := t.entries[incidentUUID]
+	delete(t.entries, incidentUUID)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if keep && e.hasPending {
+		if err := t.writeLocked(e, incidentUUID, e.pending); err != nil {
+			slog.Error("failed to flush incident progress log", "incident_uuid", incidentUUID, "err", err)
+		}
+	}
+	e.pending, e.hasPending = "", false
+}
+
+func (t *progressLogThrottle) writeLocked(e *progressLogEntry, incidentUUID, fullLog string) error {
+	prev := e.last
+	e.lastWrite = time.Now()
+	e.lastLen = len(fullLog)
+	e.last = fullLog
+
+	var err error
+	switch {
+	case prev != "" && fullLog == prev:
+		return nil
+	case t.appendLog != nil && prev != "" && strings.HasPrefix(fullLog, prev):
+		err = t.appendLog(incidentUUID, fullLog[len(prev):], fullLog)
+	default:
+		err = t.write(incidentUUID, fullLog)
+	}
+	if err != nil {
+		// The stored log is unknown now; the next write replaces it whole.
+		e.last = ""
+	}
+	return err
+}
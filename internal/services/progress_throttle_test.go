@@ -0,0 +1,107 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedLogWrites struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (r *recordedLogWrites) write(_, fullLog string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, fullLog)
+	return nil
+}
+
+func (r *recordedLogWrites) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.writes...)
+}
+
+func TestProgressLogThrottle_BatchesWithinInterval(t *testing.T) {
+	rec := &recordedLogWrites{}
+	throttle := newProgressLogThrottle(50*time.Millisecond, 0, rec.write)
+
+	for _, log := range []string{"a", "ab", "abc", "abcd"} {
+		if err := throttle.update("inc-1", log); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	if got := rec.snapshot(); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("writes before flush = %v, want only the first update", got)
+	}
+
+	time.Sleep(120 * time.Millisecond)
+	if got := rec.snapshot(); len(got) != 2 || got[1] != "abcd" {
+		t.Fatalf("writes after flush = %v, want the latest log batched into one write", got)
+	}
+}
+
+func TestProgressLogThrottle_MinDeltaHoldsSmallGrowth(t *testing.T) {
+	rec := &recordedLogWrites{}
+	throttle := newProgressLogThrottle(time.Millisecond, 10, rec.write)
+
+	_ = throttle.update("inc-1", "start")
+	time.Sleep(5 * time.Millisecond)
+	_ = throttle.update("inc-1", "start+")
+	if got := rec.snapshot(); len(got) != 1 {
+		t.Fatalf("writes = %v, want small delta held back", got)
+	}
+	_ = throttle.update("inc-1", "start"+strings.Repeat("x", 20))
+	if got := rec.snapshot(); len(got) != 2 {
+		t.Fatalf("writes = %v, want large delta written immediately", got)
+	}
+}
+
+func TestProgressLogThrottle_Settle(t *testing.T) {
+	rec := &recordedLogWrites{}
+	throttle := newProgressLogThrottle(time.Hour, 0, rec.write)
+
+	_ = throttle.update("keep", "k1")
+	_ = throttle.update("keep", "k2")
+	throttle.settle("keep", true)
+
+	_ = throttle.update("drop", "d1")
+	_ = throttle.update("drop", "d2")
+	throttle.settle("drop", false)
+
+	got := rec.snapshot()
+	want := []string{"k1", "k2", "d1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+	if len(throttle.entries) != 0 {
+		t.Errorf("entries = %d after settle, want 0", len(throttle.entries))
+	}
+}
+
+func TestProgressLogThrottle_AppendsGrowth(t *testing.T) {
+	rec := &recordedLogWrites{}
+	var appended []string
+	throttle := newProgressLogThrottle(time.Hour, 0, rec.write)
+	throttle.appendLog = func(_, delta, _ string) error {
+		appended = append(appended, delta)
+		return nil
+	}
+
+	_ = throttle.update("inc-1", "header\n")
+	_ = throttle.update("inc-1", "header\nstep 1\n")
+	throttle.settle("inc-1", true)
+	_ = throttle.update("inc-2", "x")
+	_ = throttle.update("inc-2", "rewritten")
+	throttle.settle("inc-2", true)
+
+	if got, want := rec.snapshot(), []string{"header\n", "x", "rewritten"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("full writes = %q, want %q", got, want)
+	}
+	if len(appended) != 1 || appended[0] != "step 1\n" {
+		t.Errorf("appended = %q, want only the new tail", appended)
+	}
+}
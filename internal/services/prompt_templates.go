@@ -0,0 +1,306 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// DefaultInvestigationPromptBody is the built-in investigation prompt, used
+// when no prompt template matches an alert.
+const DefaultInvestigationPromptBody = `Investigate this {{.SourceDisplayName}} alert:
+
+Alert: {{.AlertName}}
+Host: {{.Host}}
+Service: {{.Service}}
+Severity: {{.Severity}}
+Summary: {{.Summary}}
+Description: {{.Description}}{{if .Source}}
+Source: {{.Source}}{{end}}{{if .MetricName}}
+Metric: {{.MetricName}} = {{.MetricValue}}{{end}}{{if .RunbookURL}}
+Runbook: {{.RunbookURL}}{{end}}{{if .OriginalText}}
+
+Original alert text:
+{{.OriginalText}}{{end}}{{.Enrichment}}
+
+Please:
+1. Check if this is a known issue or pattern
+2. Analyze available metrics and logs
+3. Identify potential root causes
+4. Suggest remediation steps with priority
+5. Assess urgency and impact
+
+Be specific and actionable. Reference any relevant data sources or scripts you use.`
+
+// maxInvestigationPromptBytes caps prompt template bodies. The rendered
+// prompt also carries alert text and enrichment.
+const maxInvestigationPromptBytes = 16000
+
+// InvestigationPromptData is the template context of investigation prompts:
+// the alert variables plus the parts of the prompt Akmatori assembles.
+type InvestigationPromptData struct {
+	AlertTemplateVars
+
+	// Source is the "type / instance" breadcrumb naming the integration the
+	// alert came through; empty when neither is known.
+	Source string
+	// OriginalText is the raw alert text kept by the extractor, if any.
+	OriginalText string
+	// Enrichment is the rendered enrichment sections (CMDB, changes,
+	// history, runbooks), each preceded by a blank line.
+	Enrichment string
+}
+
+// PromptVariable documents one {{name}} placeholder of prompt templates.
+type PromptVariable struct {
+	Name        string `json:"name"`
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// promptVariables maps the simple placeholders accepted in prompt templates
+// to the template fields they stand for. {{labels.<key>}} is handled apart.
+var promptVariables = []PromptVariable{
+	{"alert_name", ".AlertName", "Alert name"},
+	{"severity", ".Severity", "Normalized severity"},
+	{"status", ".Status", "firing or resolved"},
+	{"summary", ".Summary", "Short alert summary"},
+	{"description", ".Description", "Full alert description"},
+	{"host", ".Host", "Target host"},
+	{"service", ".Service", "Target service"},
+	{"metric_name", ".MetricName", "Metric that triggered the alert"},
+	{"metric_value", ".MetricValue", "Observed metric value"},
+	{"threshold_value", ".ThresholdValue", "Alerting threshold"},
+	{"runbook_url", ".RunbookURL", "Runbook link from the alert"},
+	{"source_type", ".SourceType", "Alert source type, e.g. alertmanager"},
+	{"source_display_name", ".SourceDisplayName", "Alert source type display name"},
+	{"source_name", ".SourceName", "Alert source instance name"},
+	{"source", ".Source", "Source breadcrumb: type / instance"},
+	{"original_text", ".OriginalText", "Raw alert text kept by the extractor"},
+	{"enrichment", ".Enrichment", "Enrichment sections (CMDB, changes, history, runbooks)"},
+	{"labels.<key>", ".Labels", "One alert label; empty when the alert lacks it"},
+}
+
+// PromptVariables returns the placeholders accepted in prompt templates.
+func PromptVariables() []PromptVariable {
+	out := make([]PromptVariable, len(promptVariables))
+	copy(out, promptVariables)
+	return out
+}
+
+var promptPlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-z][a-z_]*)(?:\.([A-Za-z0-9_.\-/]+))?\s*\}\}`)
+
+// expandPromptPlaceholders rewrites {{alert_name}}-style placeholders into
+// template actions. Anything else, including template keywords such as
+// {{end}}, is left for the template parser.
+func expandPromptPlaceholders(body string) string {
+	return promptPlaceholderPattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := promptPlaceholderPattern.FindStringSubmatch(m)
+		name, key := sub[1], sub[2]
+		if name == "labels" && key != "" {
+			return "{{index .Labels " + strconv.Quote(key) + "}}"
+		}
+		if key != "" {
+			return m
+		}
+		for _, v := range promptVariables {
+			if v.Name == name {
+				return "{{" + v.Field + "}}"
+			}
+		}
+		return m
+	})
+}
+
+// RenderInvestigationPrompt renders a prompt template body against data.
+func RenderInvestigationPrompt(body string, data InvestigationPromptData) (string, error) {
+	return renderTemplate(expandPromptPlaceholders(body), data)
+}
+
+// RenderDefaultInvestigationPrompt renders the built-in investigation prompt.
+func RenderDefaultInvestigationPrompt(data InvestigationPromptData) string {
+	out, err := RenderInvestigationPrompt(DefaultInvestigationPromptBody, data)
+	if err != nil {
+		// The built-in template is covered by tests; this is a programming error.
+		slog.Error("built-in investigation prompt failed", "err", err)
+	}
+	return out
+}
+
+// SampleInvestigationPromptData returns representative data for prompt
+// previews and validation.
+func SampleInvestigationPromptData() InvestigationPromptData {
+	return InvestigationPromptData{
+		AlertTemplateVars: SampleAlertTemplateVars(),
+		Source:            "alertmanager / prod-alertmanager",
+		OriginalText:      "[FIRING:1] HighCPUUsage web-01 (critical)",
+		Enrichment:        "\n\nRecent changes:\n- nginx 1.25.4 deployed to web-01 2 hours ago",
+	}
+}
+
+var promptScopePattern = regexp.MustCompile(`^[a-z0-9_-]*$`)
+
+// ValidateInvestigationPromptTemplate checks a prompt template's scope and
+// that its body parses and renders against sample alert data.
+func ValidateInvestigationPromptTemplate(sourceType, skill, body string) error {
+	if len(sourceType) > 64 || !promptScopePattern.MatchString(sourceType) {
+		return fmt.Errorf("invalid source_type %q: use an alert source type name such as \"alertmanager\"", sourceType)
+	}
+	if len(skill) > 64 || !promptScopePattern.MatchString(skill) {
+		return fmt.Errorf("invalid skill %q", skill)
+	}
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body cannot be empty")
+	}
+	if len(body) > maxInvestigationPromptBytes {
+		return fmt.Errorf("body exceeds %d bytes", maxInvestigationPromptBytes)
+	}
+	if _, err := RenderInvestigationPrompt(body, SampleInvestigationPromptData()); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+// ErrPromptTemplateNotFound is returned when a prompt template ID does not
+// exist.
+var ErrPromptTemplateNotFound = errors.New("prompt template not found")
+
+// PromptTemplateService manages operator investigation prompt templates and
+// renders prompts with them.
+type PromptTemplateService struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateService creates a new prompt template service.
+func NewPromptTemplateService(db *gorm.DB) *PromptTemplateService {
+	return &PromptTemplateService{db: db}
+}
+
+// ListTemplates returns all prompt templates ordered by source type then
+// skill.
+func (s *PromptTemplateService) ListTemplates() ([]database.PromptTemplate, error) {
+	var out []database.PromptTemplate
+	if err := s.db.Order("source_type ASC, skill ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTemplate returns one prompt template by ID.
+func (s *PromptTemplateService) GetTemplate(id uint) (*database.PromptTemplate, error) {
+	var t database.PromptTemplate
+	if err := s.db.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromptTemplateNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateTemplate validates and stores a new prompt template. At most one
+// template exists per (source type, skill), and the skill must exist.
+func (s *PromptTemplateService) CreateTemplate(t *database.PromptTemplate) error {
+	if err := ValidateInvestigationPromptTemplate(t.SourceType, t.Skill, t.Body); err != nil {
+		return err
+	}
+	if t.Skill != "" {
+		var skills int64
+		if err := s.db.Model(&database.Skill{}).Where("name = ?", t.Skill).Count(&skills).Error; err != nil {
+			return err
+		}
+		if skills == 0 {
+			return fmt.Errorf("skill %q does not exist", t.Skill)
+		}
+	}
+	var existing int64
+	if err := s.db.Model(&database.PromptTemplate{}).
+		Where("source_type = ? AND skill = ?", t.SourceType, t.Skill).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("a template for source type %q and skill %q already exists", t.SourceType, t.Skill)
+	}
+	return s.db.Create(t).Error
+}
+
+// UpdateTemplate replaces the body and enabled flag of a prompt template.
+func (s *PromptTemplateService) UpdateTemplate(id uint, body string, enabled bool) (*database.PromptTemplate, error) {
+	t, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateInvestigationPromptTemplate(t.SourceType, t.Skill, body); err != nil {
+		return nil, err
+	}
+	t.Body = body
+	t.Enabled = enabled
+	if err := s.db.Save(t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTemplate removes a prompt template.
+func (s *PromptTemplateService) DeleteTemplate(id uint) error {
+	res := s.db.Delete(&database.PromptTemplate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPromptTemplateNotFound
+	}
+	return nil
+}
+
+// EffectiveBody returns the prompt template body for an alert from
+// sourceType investigated with skills: a template for both the source type
+// and one of the skills, then one for the source type, then one for a
+// skill, then the catch-all, then the built-in prompt. skills should only
+// name skills the investigation is scoped to; with every skill enabled,
+// pass nil so skill templates do not apply to every alert.
+func (s *PromptTemplateService) EffectiveBody(sourceType string, skills []string) string {
+	if s.db == nil {
+		return DefaultInvestigationPromptBody
+	}
+	var rows []database.PromptTemplate
+	if err := s.db.Where("enabled = ? AND source_type IN ? AND skill IN ?",
+		true, []string{sourceType, ""}, append([]string{""}, skills...)).
+		Order("id ASC").Find(&rows).Error; err != nil {
+		slog.Warn("failed to load prompt templates", "source_type", sourceType, "err", err)
+		return DefaultInvestigationPromptBody
+	}
+	best, bestScore := DefaultInvestigationPromptBody, -1
+	for _, row := range rows {
+		score := 0
+		if row.SourceType != "" {
+			score += 2
+		}
+		if row.Skill != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = row.Body, score
+		}
+	}
+	return best
+}
+
+// RenderPrompt renders the investigation prompt with the template selected
+// by EffectiveBody. A broken template is logged and the built-in prompt is
+// used instead, so a bad edit never blocks an investigation.
+func (s *PromptTemplateService) RenderPrompt(data InvestigationPromptData, sourceType string, skills []string) string {
+	body := s.EffectiveBody(sourceType, skills)
+	out, err := RenderInvestigationPrompt(body, data)
+	if err != nil {
+		slog.Warn("prompt template failed, using built-in", "source_type", sourceType, "err", err)
+		return RenderDefaultInvestigationPrompt(data)
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestRenderInvestigationPrompt_Placeholders(t *testing.T) {
+	body := "Check {{alert_name}} on {{ host }} for team {{labels.team}}{{if .Labels.env}} in {{labels.env}}{{end}}; missing: [{{labels.owner}}]"
+	got, err := RenderInvestigationPrompt(body, SampleInvestigationPromptData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Check HighCPUUsage on web-01 for team platform in production; missing: []"
+	if got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+}
+
+func TestRenderDefaultInvestigationPrompt(t *testing.T) {
+	data := SampleInvestigationPromptData()
+	got := RenderDefaultInvestigationPrompt(data)
+	for _, want := range []string{
+		"Investigate this Prometheus Alertmanager alert:",
+		"Source: alertmanager / prod-alertmanager",
+		"Metric: node_cpu_usage = 97.2",
+		"Original alert text:\n[FIRING:1]",
+		"Recent changes:",
+		"5. Assess urgency and impact",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("default prompt missing %q:\n%s", want, got)
+		}
+	}
+
+	data.Source, data.MetricName, data.RunbookURL, data.OriginalText, data.Enrichment = "", "", "", "", ""
+	got = RenderDefaultInvestigationPrompt(data)
+	for _, unwanted := range []string{"Source:", "Metric:", "Runbook:", "Original alert text:"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("default prompt contains %q without the data:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestValidateInvestigationPromptTemplate(t *testing.T) {
+	tests := []struct {
+		name, sourceType, skill, body string
+		wantErr                       bool
+	}{
+		{"placeholders", "alertmanager", "", "Investigate {{alert_name}}", false},
+		{"go template", "", "disk-usage", "{{if .RunbookURL}}{{.RunbookURL}}{{end}}", false},
+		{"empty", "", "", "  ", true},
+		{"unknown placeholder", "", "", "{{alertname}}", true},
+		{"unknown field", "", "", "{{.Nope}}", true},
+		{"bad source type", "Alert Manager", "", "x", true},
+		{"too long", "", "", strings.Repeat("x", maxInvestigationPromptBytes+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInvestigationPromptTemplate(tt.sourceType, tt.skill, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPromptTemplateService_EffectiveBody(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.PromptTemplate{}, &database.Skill{})
+	db.Create(&database.Skill{Name: "disk-usage"})
+	svc := NewPromptTemplateService(db)
+
+	if got := svc.EffectiveBody("alertmanager", nil); got != DefaultInvestigationPromptBody {
+		t.Fatalf("no templates: got %q", got)
+	}
+	for _, tmpl := range []database.PromptTemplate{
+		{Body: "catch-all", Enabled: true},
+		{Skill: "disk-usage", Body: "skill", Enabled: true},
+		{SourceType: "alertmanager", Body: "source", Enabled: true},
+		{SourceType: "alertmanager", Skill: "disk-usage", Body: "both", Enabled: true},
+		{SourceType: "zabbix", Body: "disabled", Enabled: false},
+	} {
+		if err := svc.CreateTemplate(&tmpl); err != nil {
+			t.Fatalf("create %q: %v", tmpl.Body, err)
+		}
+	}
+	if err := svc.CreateTemplate(&database.PromptTemplate{SourceType: "alertmanager", Body: "dup"}); err == nil {
+		t.Error("duplicate scope was accepted")
+	}
+	if err := svc.CreateTemplate(&database.PromptTemplate{Skill: "missing", Body: "x"}); err == nil {
+		t.Error("template for an unknown skill was accepted")
+	}
+
+	for _, tt := range []struct {
+		sourceType string
+		skills     []string
+		want       string
+	}{
+		{"alertmanager", []string{"disk-usage"}, "both"},
+		{"alertmanager", nil, "source"},
+		{"grafana", []string{"disk-usage"}, "skill"},
+		{"grafana", nil, "catch-all"},
+		{"zabbix", nil, "catch-all"},
+	} {
+		if got := svc.EffectiveBody(tt.sourceType, tt.skills); got != tt.want {
+			t.Errorf("EffectiveBody(%q, %v) = %q, want %q", tt.sourceType, tt.skills, got, tt.want)
+		}
+	}
+}
+
+func TestPromptTemplateService_RenderPromptFallsBack(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.PromptTemplate{})
+	// Written around validation, as an older release might have.
+	db.Create(&database.PromptTemplate{Body: "{{.Nope}}", Enabled: true})
+	svc := NewPromptTemplateService(db)
+
+	got := svc.RenderPrompt(SampleInvestigationPromptData(), "alertmanager", nil)
+	if !strings.HasPrefix(got, "Investigate this Prometheus Alertmanager alert:") {
+		t.Errorf("broken template did not fall back to the built-in prompt:\n%s", got)
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PlanApprovalSettingKey is the AlertSourceInstance.Settings key that turns
+// on two-phase investigations for the source: the agent diagnoses and
+// proposes a fix, and only carries it out once a human approves.
+const PlanApprovalSettingKey = "plan_approval"
+
+var (
+	// ErrRemediationPlanNotFound is returned when an incident has no plan.
+	ErrRemediationPlanNotFound = errors.New("remediation plan not found")
+	// ErrPlanNotPending is returned when deciding a plan that was already
+	// decided.
+	ErrPlanNotPending = errors.New("remediation plan is no longer pending")
+)
+
+// remediationPlanHeading marks the start of the plan in the agent's
+// phase-one response. PlanningInstructions asks for it.
+const remediationPlanHeading = "Proposed remediation plan"
+
+// ParsePlanApproval reports whether an alert source's settings ask for
+// two-phase investigations. The form posts checkboxes as booleans, but
+// "true"/"false" strings are accepted too.
+func ParsePlanApproval(settings map[string]interface{}) (bool, error) {
+	return parseBoolSetting(settings, PlanApprovalSettingKey, false)
+}
+
+// PlanningInstructions is appended to the phase-one prompt of a two-phase
+// investigation.
+func PlanningInstructions() string {
+	return "Remediation for this alert requires human approval. Diagnose the problem using read-only " +
+		"actions only: do not restart services, edit files or change any system. End your response " +
+		"with a section headed \"" + remediationPlanHeading + "\" listing, in order, the exact steps " +
+		"you would take to fix the problem and how you would verify the fix. You will be asked to " +
+		"carry the plan out once a human approves it."
+}
+
+// ExtractRemediationPlan returns the plan section of a phase-one response,
+// or the whole response when the agent did not use the requested heading.
+func ExtractRemediationPlan(response string) string {
+	if i := strings.Index(strings.ToLower(response), strings.ToLower(remediationPlanHeading)); i >= 0 {
+		start := strings.LastIndex(response[:i], "\n") + 1
+		return strings.TrimSpace(response[start:])
+	}
+	return strings.TrimSpace(response)
+}
+
+// RemediationPlanService stores the plans two-phase investigations propose
+// and records the human decision on them. Approving a plan hands it to the
+// executor, which resumes the agent session to carry it out; rejecting it
+// leaves the incident diagnosed with nothing changed.
+type RemediationPlanService struct {
+	db       *gorm.DB
+	notifier PlanNotifier             // optional; nil = API/UI only
+	executor PlanExecutor             // required to approve plans
+	timeline IncidentTimelineRecorder // optional
+}
+
+// NewRemediationPlanService creates a remediation plan service.
+func NewRemediationPlanService(db *gorm.DB) *RemediationPlanService {
+	return &RemediationPlanService{db: db}
+}
+
+// SetNotifier wires where plans are posted for approval. Optional.
+func (s *RemediationPlanService) SetNotifier(n PlanNotifier) {
+	s.notifier = n
+}
+
+// SetExecutor wires the runner that carries out approved plans.
+func (s *RemediationPlanService) SetExecutor(e PlanExecutor) {
+	s.executor = e
+}
+
+// SetIncidentTimeline wires the timeline plan decisions are recorded on.
+// Optional.
+func (s *RemediationPlanService) SetIncidentTimeline(t IncidentTimelineRecorder) {
+	s.timeline = t
+}
+
+// ProposePlan stores the plan found in a phase-one response and posts it
+// for approval. A plan still pending from an earlier run of the same
+// incident is superseded. Posting is best-effort; the plan can always be
+// decided through the API.
+func (s *RemediationPlanService) ProposePlan(ctx context.Context, incidentUUID, response string) (*database.RemediationPlan, error) {
+	text := ExtractRemediationPlan(response)
+	if text == "" {
+		return nil, errors.New("response contains no remediation plan")
+	}
+
+	var superseded []database.RemediationPlan
+	if err := s.db.WithContext(ctx).
+		Where("incident_uuid = ? AND status = ?", incidentUUID, database.RemediationPlanPending).
+		Find(&superseded).Error; err != nil {
+		return nil, err
+	}
+	for i := range superseded {
+		old, err := s.decide(ctx, &superseded[i], database.RemediationPlanRejected, "system", "Superseded by a newer plan")
+		if errors.Is(err, ErrPlanNotPending) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.resolveNotification(ctx, old)
+	}
+
+	plan := &database.RemediationPlan{
+		UUID:         uuid.New().String(),
+		IncidentUUID: incidentUUID,
+		Plan:         text,
+		Status:       database.RemediationPlanPending,
+	}
+	if err := s.db.WithContext(ctx).Create(plan).Error; err != nil {
+		return nil, err
+	}
+	slog.Info("remediation plan proposed", "plan", plan.UUID, "incident_uuid", incidentUUID)
+	s.notify(ctx, plan)
+	return plan, nil
+}
+
+// GetPlan returns the incident's most recent plan.
+func (s *RemediationPlanService) GetPlan(ctx context.Context, incidentUUID string) (*database.RemediationPlan, error) {
+	var plan database.RemediationPlan
+	err := s.db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).Order("id DESC").First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRemediationPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// DecidePlan approves or rejects the incident's pending plan on behalf of
+// decidedBy. An approved plan is handed to the executor; if it cannot
+// start (the worker is down, or the agent is busy with the incident) the
+// plan goes back to pending and the executor's error is returned.
+func (s *RemediationPlanService) DecidePlan(ctx context.Context, incidentUUID string, approve bool, decidedBy, reason string) (*database.RemediationPlan, error) {
+	if approve && s.executor == nil {
+		return nil, errors.New("remediation plan executor is not configured")
+	}
+	plan, err := s.GetPlan(ctx, incidentUUID)
+	if err != nil {
+		return nil, err
+	}
+	status := database.RemediationPlanRejected
+	if approve {
+		status = database.RemediationPlanApproved
+	}
+	if decidedBy == "" {
+		decidedBy = "user"
+	}
+	if plan, err = s.decide(ctx, plan, status, decidedBy, reason); err != nil {
+		return plan, err
+	}
+
+	if approve {
+		if err := s.executor.ExecutePlan(plan); err != nil {
+			if rerr := s.db.WithContext(ctx).Model(&database.RemediationPlan{}).
+				Where("id = ? AND status = ?", plan.ID, database.RemediationPlanApproved).
+				Updates(map[string]interface{}{"status": database.RemediationPlanPending, "decided_by": "", "reason": "", "decided_at": nil}).Error; rerr != nil {
+				slog.Error("failed to reopen remediation plan after execution error", "plan", plan.UUID, "err", rerr)
+			}
+			return nil, err
+		}
+	}
+
+	slog.Info("remediation plan decided", "plan", plan.UUID, "incident_uuid", plan.IncidentUUID, "status", status, "by", decidedBy)
+	s.recordDecision(plan)
+	s.resolveNotification(ctx, plan)
+	return plan, nil
+}
+
+// decide moves a pending plan to status. The status guard makes the
+// decision first-writer-wins when two people click at once.
+func (s *RemediationPlanService) decide(ctx context.Context, plan *database.RemediationPlan, status database.RemediationPlanStatus, decidedBy, reason string) (*database.RemediationPlan, error) {
+	res := s.db.WithContext(ctx).Model(&database.RemediationPlan{}).
+		Where("id = ? AND status = ?", plan.ID, database.RemediationPlanPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"decided_by": decidedBy,
+			"reason":     strings.TrimSpace(reason),
+			"decided_at": time.Now(),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	var updated database.RemediationPlan
+	if err := s.db.WithContext(ctx).First(&updated, plan.ID).Error; err != nil {
+		return nil, err
+	}
+	if res.RowsAffected == 0 {
+		return &updated, ErrPlanNotPending
+	}
+	return &updated, nil
+}
+
+func (s *RemediationPlanService) notify(ctx context.Context, plan *database.RemediationPlan) {
+	if s.notifier == nil {
+		return
+	}
+	var incident database.Incident
+	if err := s.db.WithContext(ctx).Where("uuid = ?", plan.IncidentUUID).First(&incident).Error; err != nil {
+		slog.Warn("remediation plan: failed to load incident", "incident_uuid", plan.IncidentUUID, "err", err)
+		return
+	}
+	channelID, messageTS, err := s.notifier.NotifyPlan(ctx, plan, &incident)
+	if err != nil {
+		slog.Warn("remediation plan: failed to post plan", "plan", plan.UUID, "err", err)
+		return
+	}
+	if messageTS == "" {
+		return
+	}
+	if err := s.db.WithContext(ctx).Model(plan).Updates(map[string]interface{}{
+		"slack_channel_id": channelID,
+		"slack_message_ts": messageTS,
+	}).Error; err != nil {
+		slog.Warn("remediation plan: failed to save Slack message", "plan", plan.UUID, "err", err)
+	}
+	plan.SlackChannelID, plan.SlackMessageTS = channelID, messageTS
+}
+
+func (s *RemediationPlanService) resolveNotification(ctx context.Context, plan *database.RemediationPlan) {
+	if s.notifier == nil || plan.SlackMessageTS == "" {
+		return
+	}
+	if err := s.notifier.ResolvePlan(ctx, plan); err != nil {
+		slog.Warn("remediation plan: failed to update Slack message", "plan", plan.UUID, "err", err)
+	}
+}
+
+func (s *RemediationPlanService) recordDecision(plan *database.RemediationPlan) {
+	if s.timeline == nil {
+		return
+	}
+	summary := "Rejected the remediation plan"
+	if plan.Status == database.RemediationPlanApproved {
+		summary = "Approved the remediation plan"
+	}
+	s.timeline.RecordEvent(database.IncidentEvent{
+		IncidentUUID: plan.IncidentUUID,
+		Type:         database.IncidentEventApproval,
+		Summary:      summary,
+		Details: database.JSONB{
+			"plan_uuid": plan.UUID,
+			"status":    string(plan.Status),
+			"reason":    plan.Reason,
+		},
+		Actor: plan.DecidedBy,
+	})
+}
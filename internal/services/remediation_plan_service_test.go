@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+type stubPlanExecutor struct {
+	err      error
+	executed []string
+}
+
+func (e *stubPlanExecutor) ExecutePlan(plan *database.RemediationPlan) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.executed = append(e.executed, plan.UUID)
+	return nil
+}
+
+type stubPlanNotifier struct {
+	resolved []database.RemediationPlanStatus
+}
+
+func (n *stubPlanNotifier) NotifyPlan(_ context.Context, plan *database.RemediationPlan, _ *database.Incident) (string, string, error) {
+	return "C1", "ts-" + plan.UUID, nil
+}
+
+func (n *stubPlanNotifier) ResolvePlan(_ context.Context, plan *database.RemediationPlan) error {
+	n.resolved = append(n.resolved, plan.Status)
+	return nil
+}
+
+func TestParsePlanApproval(t *testing.T) {
+	cases := []struct {
+		value   interface{}
+		want    bool
+		wantErr bool
+	}{
+		{nil, false, false},
+		{true, true, false},
+		{"true", true, false},
+		{" ", false, false},
+		{"sometimes", false, true},
+		{1.0, false, true},
+	}
+	for _, tc := range cases {
+		got, err := ParsePlanApproval(map[string]interface{}{PlanApprovalSettingKey: tc.value})
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("ParsePlanApproval(%v) = %v, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestExtractRemediationPlan(t *testing.T) {
+	response := "Disk on db-01 is full because of old WAL files.\n\n## Proposed Remediation Plan\n1. Remove WAL files older than 7 days\n"
+	if got := ExtractRemediationPlan(response); got != "## Proposed Remediation Plan\n1. Remove WAL files older than 7 days" {
+		t.Errorf("plan = %q", got)
+	}
+	if got := ExtractRemediationPlan("  Restart nginx.\n"); got != "Restart nginx." {
+		t.Errorf("plan without heading = %q", got)
+	}
+}
+
+func TestRemediationPlanService(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.RemediationPlan{}, &database.Incident{}, &database.IncidentLogChunk{})
+	if err := db.Create(&database.Incident{UUID: "inc-1", Status: database.IncidentStatusDiagnosed}).Error; err != nil {
+		t.Fatal(err)
+	}
+	svc := NewRemediationPlanService(db)
+	notifier := &stubPlanNotifier{}
+	executor := &stubPlanExecutor{}
+	svc.SetNotifier(notifier)
+	ctx := context.Background()
+
+	if _, err := svc.GetPlan(ctx, "inc-1"); !errors.Is(err, ErrRemediationPlanNotFound) {
+		t.Errorf("GetPlan before proposing: err = %v", err)
+	}
+	first, err := svc.ProposePlan(ctx, "inc-1", "Proposed remediation plan\n1. Restart nginx")
+	if err != nil || first.SlackMessageTS != "ts-"+first.UUID {
+		t.Fatalf("ProposePlan = %+v, %v", first, err)
+	}
+	second, err := svc.ProposePlan(ctx, "inc-1", "Proposed remediation plan\n1. Reload nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.resolved) != 1 || notifier.resolved[0] != database.RemediationPlanRejected {
+		t.Errorf("superseded plan resolutions = %v", notifier.resolved)
+	}
+
+	if _, err := svc.DecidePlan(ctx, "inc-1", true, "alice", ""); err == nil {
+		t.Error("approving without an executor succeeded")
+	}
+	svc.SetExecutor(executor)
+
+	executor.err = ErrIncidentNotFinished
+	if _, err := svc.DecidePlan(ctx, "inc-1", true, "alice", ""); !errors.Is(err, ErrIncidentNotFinished) {
+		t.Fatalf("approve while busy: err = %v", err)
+	}
+	if plan, _ := svc.GetPlan(ctx, "inc-1"); plan.Status != database.RemediationPlanPending || plan.DecidedBy != "" {
+		t.Errorf("plan after failed execution = %+v, want pending again", plan)
+	}
+
+	executor.err = nil
+	plan, err := svc.DecidePlan(ctx, "inc-1", true, "alice", "go ahead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.UUID != second.UUID || plan.Status != database.RemediationPlanApproved || plan.Reason != "go ahead" {
+		t.Errorf("approved plan = %+v", plan)
+	}
+	if len(executor.executed) != 1 || executor.executed[0] != second.UUID {
+		t.Errorf("executed = %v", executor.executed)
+	}
+	if _, err := svc.DecidePlan(ctx, "inc-1", false, "bob", ""); !errors.Is(err, ErrPlanNotPending) {
+		t.Errorf("second decision: err = %v, want ErrPlanNotPending", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
@@ -16,16 +17,24 @@ import (
 
 // RetentionService handles automatic cleanup of old incident data.
 type RetentionService struct {
-	dataDir string
-	db      *gorm.DB
+	dataDir    string
+	archiveDir string
+	db         *gorm.DB
+
+	// mu serializes runs, so a cleanup triggered over the API never
+	// overlaps the background one on the same replica.
+	mu sync.Mutex
 }
 
 // NewRetentionService creates a new retention service.
 // dataDir is the incidents directory (e.g., /akmatori/incidents).
+// Archives go to an "archives" directory next to it unless SetArchiveDir
+// says otherwise.
 func NewRetentionService(dataDir string, db *gorm.DB) *RetentionService {
 	return &RetentionService{
-		dataDir: dataDir,
-		db:      db,
+		dataDir:    dataDir,
+		archiveDir: filepath.Join(filepath.Dir(dataDir), "archives"),
+		db:         db,
 	}
 }
 
@@ -37,11 +46,20 @@ type CleanupResult struct {
 	ExpiredBytesFreed       int64
 	OrphanedDirsDeleted     int
 	OrphanedBytesFreed      int64
-	Errors                  []error
+	WorkspacesPruned        int
+	WorkspaceBytesFreed     int64
+	WorkspacesArchived      int
+	// Skipped is set when retention is disabled and nothing was done.
+	Skipped bool
+	Errors  []error
 }
 
-// RunCleanup executes both cleanup phases: expired incidents and orphaned directories.
+// RunCleanup executes the cleanup phases: expired incidents, orphaned
+// directories and workspaces past the workspace limits.
 func (s *RetentionService) RunCleanup() (*CleanupResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	settings, err := s.getRetentionSettings()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get retention settings: %w", err)
@@ -49,16 +67,19 @@ func (s *RetentionService) RunCleanup() (*CleanupResult, error) {
 
 	if !settings.Enabled {
 		slog.Info("retention cleanup skipped: disabled")
-		return &CleanupResult{}, nil
+		return &CleanupResult{Skipped: true}, nil
 	}
 
 	result := &CleanupResult{}
 
 	// Phase 1: Delete expired incidents
-	s.cleanupExpiredIncidents(settings.RetentionDays, result)
+	s.cleanupExpiredIncidents(settings.RetentionDays, settings.ArchiveWorkspaces, result)
 
 	// Phase 2: Delete orphaned directories
-	s.cleanupOrphanedDirectories(result)
+	s.cleanupOrphanedDirectories(settings.ArchiveWorkspaces, result)
+
+	// Phase 3: Prune workspaces of finished incidents past the age and size limits
+	s.pruneWorkspaces(settings, result)
 
 	logAttrs := []any{
 		"expired_incidents_deleted", result.ExpiredIncidentsDeleted,
@@ -67,6 +88,9 @@ func (s *RetentionService) RunCleanup() (*CleanupResult, error) {
 		"expired_bytes_freed", result.ExpiredBytesFreed,
 		"orphaned_dirs_deleted", result.OrphanedDirsDeleted,
 		"orphaned_bytes_freed", result.OrphanedBytesFreed,
+		"workspaces_pruned", result.WorkspacesPruned,
+		"workspace_bytes_freed", result.WorkspaceBytesFreed,
+		"workspaces_archived", result.WorkspacesArchived,
 		"errors", len(result.Errors),
 	}
 	if len(result.Errors) > 0 {
@@ -79,7 +103,7 @@ func (s *RetentionService) RunCleanup() (*CleanupResult, error) {
 }
 
 // cleanupExpiredIncidents finds and removes incidents older than retentionDays.
-func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, result *CleanupResult) {
+func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, archive bool, result *CleanupResult) {
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 
 	var incidents []database.Incident
@@ -107,7 +131,7 @@ func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, result *Cl
 	}
 
 	for _, incident := range incidents {
-		dirRemoved := s.removeIncidentDir(incident, absDataDir, result)
+		dirRemoved := s.removeIncidentDir(incident, absDataDir, archive, result)
 
 		// Only delete the DB record if the directory was successfully removed (or didn't exist)
 		if !dirRemoved {
@@ -116,7 +140,9 @@ func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, result *Cl
 
 		// Delete linked alerts in the same transaction as the incident so a
 		// deleted incident never leaves orphaned Alert rows behind (they'd be
-		// unreachable by any resolve path — no incident left to close).
+		// unreachable by any resolve path — no incident left to close). Its
+		// log, parent/related links, timeline, change manifest, postmortem
+		// and tool approvals go with it.
 		var alertsDeleted int64
 		if err := s.db.Transaction(func(tx *gorm.DB) error {
 			del := tx.Where("incident_uuid = ?", incident.UUID).Delete(&database.Alert{})
@@ -124,6 +150,24 @@ func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, result *Cl
 				return fmt.Errorf("delete linked alerts: %w", del.Error)
 			}
 			alertsDeleted = del.RowsAffected
+			if err := tx.Where("from_uuid = ? OR to_uuid = ?", incident.UUID, incident.UUID).Delete(&database.IncidentLink{}).Error; err != nil {
+				return fmt.Errorf("delete incident links: %w", err)
+			}
+			if err := database.DeleteIncidentLog(tx, incident.UUID); err != nil {
+				return fmt.Errorf("delete incident log: %w", err)
+			}
+			if err := tx.Where("incident_uuid = ?", incident.UUID).Delete(&database.IncidentEvent{}).Error; err != nil {
+				return fmt.Errorf("delete incident events: %w", err)
+			}
+			if err := tx.Where("incident_uuid = ?", incident.UUID).Delete(&database.IncidentChange{}).Error; err != nil {
+				return fmt.Errorf("delete incident changes: %w", err)
+			}
+			if err := tx.Where("incident_uuid = ?", incident.UUID).Delete(&database.IncidentReport{}).Error; err != nil {
+				return fmt.Errorf("delete incident report: %w", err)
+			}
+			if err := tx.Where("incident_uuid = ?", incident.UUID).Delete(&database.ToolApproval{}).Error; err != nil {
+				return fmt.Errorf("delete tool approvals: %w", err)
+			}
 			return tx.Delete(&incident).Error
 		}); err != nil {
 			slog.Error("failed to delete incident record", "uuid", incident.UUID, "error", err)
@@ -135,9 +179,10 @@ func (s *RetentionService) cleanupExpiredIncidents(retentionDays int, result *Cl
 	}
 }
 
-// removeIncidentDir removes an incident's working directory from disk.
+// removeIncidentDir removes an incident's working directory from disk,
+// archiving it first when archive is set.
 // Returns true if the directory was successfully removed or didn't exist.
-func (s *RetentionService) removeIncidentDir(incident database.Incident, absDataDir string, result *CleanupResult) bool {
+func (s *RetentionService) removeIncidentDir(incident database.Incident, absDataDir string, archive bool, result *CleanupResult) bool {
 	if incident.WorkingDir == "" {
 		return true
 	}
@@ -165,6 +210,9 @@ func (s *RetentionService) removeIncidentDir(incident database.Incident, absData
 		bytesFreed = 0
 	}
 
+	if archive && !s.archiveWorkspace(absWorkDir, incident.UUID, result) {
+		return false
+	}
 	if err := os.RemoveAll(absWorkDir); err != nil {
 		slog.Error("failed to remove incident directory", "uuid", incident.UUID, "dir", absWorkDir, "error", err)
 		result.Errors = append(result.Errors, fmt.Errorf("remove dir %s: %w", incident.UUID, err))
@@ -177,7 +225,7 @@ func (s *RetentionService) removeIncidentDir(incident database.Incident, absData
 }
 
 // cleanupOrphanedDirectories removes directories in dataDir with no matching incident record.
-func (s *RetentionService) cleanupOrphanedDirectories(result *CleanupResult) {
+func (s *RetentionService) cleanupOrphanedDirectories(archive bool, result *CleanupResult) {
 	entries, err := os.ReadDir(s.dataDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -203,8 +251,9 @@ func (s *RetentionService) cleanupOrphanedDirectories(result *CleanupResult) {
 
 		dirName := entry.Name()
 
-		// Only consider directories with valid UUID names
-		if _, err := uuid.Parse(dirName); err != nil {
+		// Only consider directories with valid UUID names, and the
+		// workspaces skill dry runs left behind (never in the database)
+		if _, err := uuid.Parse(strings.TrimPrefix(dirName, skillDryRunPrefix)); err != nil {
 			continue
 		}
 
@@ -258,6 +307,9 @@ func (s *RetentionService) cleanupOrphanedDirectories(result *CleanupResult) {
 			bytesFreed = 0
 		}
 
+		if archive && !s.archiveWorkspace(c.path, c.name, result) {
+			continue
+		}
 		if err := os.RemoveAll(c.path); err != nil {
 			slog.Error("failed to remove orphaned directory", "dir", c.path, "error", err)
 			result.Errors = append(result.Errors, fmt.Errorf("remove orphan %s: %w", c.name, err))
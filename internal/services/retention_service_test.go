@@ -20,8 +20,13 @@ func setupRetentionTestDB(t *testing.T) *gorm.DB {
 	}
 
 	err = db.AutoMigrate(
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.Alert{},
+		&database.IncidentLink{},
+		&database.IncidentEvent{},
+		&database.IncidentChange{},
+		&database.IncidentReport{},
+		&database.ToolApproval{},
 		&database.RetentionSettings{},
 	)
 	if err != nil {
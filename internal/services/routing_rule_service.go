@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrRoutingRuleNotFound is returned when no routing rule has the requested UUID.
+var ErrRoutingRuleNotFound = errors.New("routing rule not found")
+
+// ErrInvalidRoutingRule wraps validation failures so the API can answer 400.
+var ErrInvalidRoutingRule = errors.New("invalid routing rule")
+
+// RoutingRuleService stores alert routing rules and picks the rule that
+// applies to a firing alert.
+type RoutingRuleService struct {
+	db *gorm.DB
+}
+
+// NewRoutingRuleService creates a routing rule service.
+func NewRoutingRuleService(db *gorm.DB) *RoutingRuleService {
+	return &RoutingRuleService{db: db}
+}
+
+func (s *RoutingRuleService) preloaded(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Preload("NotificationChannel").Preload("Skills")
+}
+
+// ListRoutingRules returns every rule in evaluation order.
+func (s *RoutingRuleService) ListRoutingRules(ctx context.Context) ([]database.RoutingRule, error) {
+	rules := []database.RoutingRule{}
+	if err := s.preloaded(ctx).Order("position ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRoutingRule returns the rule with the given UUID.
+func (s *RoutingRuleService) GetRoutingRule(ctx context.Context, ruleUUID string) (*database.RoutingRule, error) {
+	var rule database.RoutingRule
+	err := s.preloaded(ctx).Where("uuid = ?", ruleUUID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoutingRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRoutingRule validates and stores a new rule. channelUUID, when set,
+// names the channel matching alerts are posted to; skillNames pins the
+// investigation to those skills.
+func (s *RoutingRuleService) CreateRoutingRule(ctx context.Context, rule *database.RoutingRule, channelUUID string, skillNames []string) error {
+	if err := validateRoutingRule(rule); err != nil {
+		return err
+	}
+	channelID, err := s.resolveChannel(ctx, channelUUID)
+	if err != nil {
+		return err
+	}
+	skills, err := s.resolveSkills(ctx, skillNames)
+	if err != nil {
+		return err
+	}
+	rule.ID = 0
+	rule.UUID = uuid.New().String()
+	rule.NotificationChannelID = channelID
+	rule.NotificationChannel = nil
+	rule.Skills = nil
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Enabled has a database default, so a false value must be written
+		// explicitly after the insert.
+		enabled := rule.Enabled
+		if err := tx.Create(rule).Error; err != nil {
+			return err
+		}
+		if !enabled {
+			if err := tx.Model(rule).Update("enabled", false).Error; err != nil {
+				return err
+			}
+			rule.Enabled = false
+		}
+		if len(skills) > 0 {
+			return tx.Model(rule).Association("Skills").Replace(skills)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	created, err := s.GetRoutingRule(ctx, rule.UUID)
+	if err != nil {
+		return err
+	}
+	*rule = *created
+	return nil
+}
+
+// UpdateRoutingRule replaces the matchers and actions of an existing rule.
+func (s *RoutingRuleService) UpdateRoutingRule(ctx context.Context, ruleUUID string, update *database.RoutingRule, channelUUID string, skillNames []string) (*database.RoutingRule, error) {
+	existing, err := s.GetRoutingRule(ctx, ruleUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoutingRule(update); err != nil {
+		return nil, err
+	}
+	channelID, err := s.resolveChannel(ctx, channelUUID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.resolveSkills(ctx, skillNames)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.RoutingRule{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"name":                    update.Name,
+			"description":             update.Description,
+			"enabled":                 update.Enabled,
+			"position":                update.Position,
+			"source_uuid":             update.SourceUUID,
+			"severities":              update.Severities,
+			"alert_name":              update.AlertName,
+			"target_host":             update.TargetHost,
+			"labels":                  update.Labels,
+			"action":                  update.Action,
+			"priority":                update.Priority,
+			"notification_channel_id": channelID,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(existing).Association("Skills").Replace(skills)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRoutingRule(ctx, ruleUUID)
+}
+
+// DeleteRoutingRule removes a rule and its skill pins.
+func (s *RoutingRuleService) DeleteRoutingRule(ctx context.Context, ruleUUID string) error {
+	rule, err := s.GetRoutingRule(ctx, ruleUUID)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("routing_rule_id = ?", rule.ID).Delete(&database.RoutingRuleSkill{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&database.RoutingRule{}, rule.ID).Error
+	})
+}
+
+// MatchRoutingRule returns the first enabled rule matching a firing alert
+// from the given source, or nil when none does. Skills are preloaded with
+// their tools so the caller can build the investigation's allowlist.
+func (s *RoutingRuleService) MatchRoutingRule(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (*database.RoutingRule, error) {
+	var rules []database.RoutingRule
+	if err := s.db.WithContext(ctx).
+		Preload("Skills.Tools.ToolType").
+		Where("enabled = ?", true).
+		Where("source_uuid = '' OR source_uuid IS NULL OR source_uuid = ?", sourceUUID).
+		Order("position ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if RoutingRuleMatches(&rules[i], sourceUUID, alert) {
+			return &rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RoutingRuleMatches reports whether a rule's matchers all match the alert.
+// It ignores Enabled.
+func RoutingRuleMatches(rule *database.RoutingRule, sourceUUID string, alert alerts.NormalizedAlert) bool {
+	if rule.SourceUUID != "" && rule.SourceUUID != sourceUUID {
+		return false
+	}
+	if len(rule.Severities) > 0 {
+		matched := false
+		for _, severity := range rule.Severities {
+			if severity == alert.Severity {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return alertFieldsMatch(rule.AlertName, rule.TargetHost, rule.Labels, alert)
+}
+
+func (s *RoutingRuleService) resolveChannel(ctx context.Context, channelUUID string) (*uint, error) {
+	channelUUID = strings.TrimSpace(channelUUID)
+	if channelUUID == "" {
+		return nil, nil
+	}
+	var channel database.Channel
+	err := s.db.WithContext(ctx).Where("uuid = ?", channelUUID).First(&channel).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: notification channel %q not found", ErrInvalidRoutingRule, channelUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !channel.CanPost {
+		return nil, fmt.Errorf("%w: notification channel %q cannot post", ErrInvalidRoutingRule, channelUUID)
+	}
+	return &channel.ID, nil
+}
+
+func (s *RoutingRuleService) resolveSkills(ctx context.Context, names []string) ([]database.Skill, error) {
+	skills := make([]database.Skill, 0, len(names))
+	for _, name := range names {
+		var skill database.Skill
+		err := s.db.WithContext(ctx).Where("name = ? AND is_system = ?", name, false).First(&skill).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %w: %s", ErrInvalidRoutingRule, ErrUnknownSkill, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
+
+func validRuleSeverity(severity database.AlertSeverity) bool {
+	switch severity {
+	case database.AlertSeverityCritical, database.AlertSeverityHigh,
+		database.AlertSeverityWarning, database.AlertSeverityInfo:
+		return true
+	}
+	return false
+}
+
+// validateRoutingRule trims and checks a rule's fields. Unlike a silence, a
+// rule with no matchers is allowed: placed last, it acts as the default.
+func validateRoutingRule(rule *database.RoutingRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.SourceUUID = strings.TrimSpace(rule.SourceUUID)
+	rule.AlertName = strings.TrimSpace(rule.AlertName)
+	rule.TargetHost = strings.TrimSpace(rule.TargetHost)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRoutingRule)
+	}
+	if rule.Action == "" {
+		rule.Action = database.RoutingActionInvestigate
+	}
+	if rule.Action != database.RoutingActionInvestigate && rule.Action != database.RoutingActionRecord {
+		return fmt.Errorf("%w: action must be investigate or record", ErrInvalidRoutingRule)
+	}
+	if rule.Priority != "" && !validRuleSeverity(rule.Priority) {
+		return fmt.Errorf("%w: priority must be critical, high, warning or info", ErrInvalidRoutingRule)
+	}
+	for _, severity := range rule.Severities {
+		if !validRuleSeverity(severity) {
+			return fmt.Errorf("%w: unknown severity %q", ErrInvalidRoutingRule, severity)
+		}
+	}
+	if !labelMatchersValid(rule.Labels) {
+		return fmt.Errorf("%w: labels must map label names to non-empty strings", ErrInvalidRoutingRule)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestRoutingRuleMatches(t *testing.T) {
+	alert := alerts.NormalizedAlert{
+		AlertName:    "DiskSpaceLow",
+		TargetHost:   "db-01.prod",
+		Severity:     database.AlertSeverityWarning,
+		TargetLabels: map[string]string{"team": "storage"},
+	}
+	cases := []struct {
+		name string
+		rule database.RoutingRule
+		want bool
+	}{
+		{"no matchers", database.RoutingRule{}, true},
+		{"severity listed", database.RoutingRule{Severities: database.AlertSeverityList{"critical", "warning"}}, true},
+		{"severity not listed", database.RoutingRule{Severities: database.AlertSeverityList{"critical"}}, false},
+		{"host and label globs", database.RoutingRule{TargetHost: "db-*", Labels: database.JSONB{"team": "stor*"}}, true},
+		{"other source", database.RoutingRule{SourceUUID: "other"}, false},
+	}
+	for _, tc := range cases {
+		if got := RoutingRuleMatches(&tc.rule, "src-1", alert); got != tc.want {
+			t.Errorf("%s: RoutingRuleMatches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRoutingRuleService(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t,
+		&database.RoutingRule{}, &database.RoutingRuleSkill{},
+		&database.Skill{}, &database.SkillTool{}, &database.ToolInstance{}, &database.ToolType{},
+		&database.Integration{}, &database.Channel{},
+	)
+	svc := NewRoutingRuleService(db)
+	ctx := context.Background()
+
+	db.Create(&database.Skill{Name: "db-triage", Enabled: true})
+	integration := database.Integration{UUID: "int-1", Provider: database.MessagingProviderSlack, Name: "slack", Enabled: true}
+	db.Create(&integration)
+	channel := database.Channel{UUID: "ch-db", IntegrationID: integration.ID, ExternalID: "C_DB", CanPost: true, Enabled: true}
+	db.Create(&channel)
+
+	if err := svc.CreateRoutingRule(ctx, &database.RoutingRule{Name: "bad", Action: "page"}, "", nil); !errors.Is(err, ErrInvalidRoutingRule) {
+		t.Errorf("unknown action: err = %v, want ErrInvalidRoutingRule", err)
+	}
+	if err := svc.CreateRoutingRule(ctx, &database.RoutingRule{Name: "bad"}, "", []string{"missing"}); !errors.Is(err, ErrInvalidRoutingRule) {
+		t.Errorf("unknown skill: err = %v, want ErrInvalidRoutingRule", err)
+	}
+	if err := svc.CreateRoutingRule(ctx, &database.RoutingRule{Name: "bad"}, "ch-missing", nil); !errors.Is(err, ErrInvalidRoutingRule) {
+		t.Errorf("unknown channel: err = %v, want ErrInvalidRoutingRule", err)
+	}
+
+	dbRule := &database.RoutingRule{Name: "databases", Enabled: true, Position: 10, TargetHost: "db-*", Priority: database.AlertSeverityCritical}
+	if err := svc.CreateRoutingRule(ctx, dbRule, "ch-db", []string{"db-triage"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if dbRule.Action != database.RoutingActionInvestigate || !dbRule.Enabled || len(dbRule.Skills) != 1 || dbRule.NotificationChannel == nil {
+		t.Errorf("created rule = %+v", dbRule)
+	}
+	catchAll := &database.RoutingRule{Name: "info only", Enabled: true, Position: 20, Severities: database.AlertSeverityList{"info"}, Action: database.RoutingActionRecord}
+	if err := svc.CreateRoutingRule(ctx, catchAll, "", nil); err != nil {
+		t.Fatalf("create catch-all: %v", err)
+	}
+	disabled := &database.RoutingRule{Name: "disabled", Enabled: false, Position: 0}
+	if err := svc.CreateRoutingRule(ctx, disabled, "", nil); err != nil || disabled.Enabled {
+		t.Fatalf("create disabled: %+v, %v", disabled, err)
+	}
+
+	rule, err := svc.MatchRoutingRule(ctx, "src-1", alerts.NormalizedAlert{TargetHost: "db-01", Severity: database.AlertSeverityInfo})
+	if err != nil || rule == nil || rule.UUID != dbRule.UUID {
+		t.Fatalf("match = %+v, %v; want the databases rule (first enabled match)", rule, err)
+	}
+	if len(rule.Skills) != 1 || rule.Skills[0].Name != "db-triage" {
+		t.Errorf("matched rule skills = %+v", rule.Skills)
+	}
+	if rule, _ := svc.MatchRoutingRule(ctx, "src-1", alerts.NormalizedAlert{TargetHost: "web-01", Severity: database.AlertSeverityInfo}); rule == nil || rule.UUID != catchAll.UUID {
+		t.Errorf("match = %+v, want the info-only rule", rule)
+	}
+	if rule, _ := svc.MatchRoutingRule(ctx, "src-1", alerts.NormalizedAlert{TargetHost: "web-01", Severity: database.AlertSeverityHigh}); rule != nil {
+		t.Errorf("match = %+v, want none", rule)
+	}
+
+	updated, err := svc.UpdateRoutingRule(ctx, dbRule.UUID, &database.RoutingRule{Name: "databases", Enabled: true, TargetHost: "db-*"}, "", nil)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.NotificationChannelID != nil || len(updated.Skills) != 0 || updated.Priority != "" {
+		t.Errorf("update did not replace the overrides: %+v", updated)
+	}
+
+	if err := svc.DeleteRoutingRule(ctx, dbRule.UUID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.GetRoutingRule(ctx, dbRule.UUID); !errors.Is(err, ErrRoutingRuleNotFound) {
+		t.Errorf("deleted rule: err = %v", err)
+	}
+	rules, _ := svc.ListRoutingRules(ctx)
+	if len(rules) != 2 || rules[0].UUID != disabled.UUID {
+		t.Errorf("rules = %+v, want disabled then info only", rules)
+	}
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/messaging"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Self-monitor checks. Each check has at most one open meta incident; its
+// name is stored as the incident's SourceID so an open incident survives an
+// API restart and is closed once the condition clears.
+const (
+	SelfCheckWebhookErrors      = "webhook_errors"
+	SelfCheckWorkerDisconnected = "worker_disconnected"
+	SelfCheckLLMAuth            = "llm_auth"
+	// SelfCheckSourceSilentPrefix is followed by the alert source instance
+	// UUID; each source with a silence threshold is its own check.
+	SelfCheckSourceSilentPrefix = "source_silent:"
+)
+
+const (
+	// selfMonitorInterval is how often the conditions are evaluated.
+	selfMonitorInterval = 30 * time.Second
+	// selfMonitorWebhookWindow is the sliding window for the webhook failure rate.
+	selfMonitorWebhookWindow = 5 * time.Minute
+	// selfMonitorSource is the Incident.Source of meta incidents.
+	selfMonitorSource = "akmatori"
+)
+
+// SelfMonitorConfig holds the thresholds for each check. A zero threshold
+// disables that check.
+type SelfMonitorConfig struct {
+	WebhookErrorRate   float64       // Failed fraction of webhooks within the window
+	WebhookMinRequests int           // Minimum webhooks in the window before the rate is judged
+	WorkerDownAfter    time.Duration // How long the worker may stay disconnected
+	LLMAuthFailures    int           // Consecutive runs failing with an LLM auth error
+	ChannelUUID        string        // Notification channel; empty uses the default Slack post channel
+}
+
+// WorkerStatus reports whether the agent worker is connected. Satisfied by
+// *handlers.AgentWSHandler.
+type WorkerStatus interface {
+	IsWorkerConnected() bool
+}
+
+// SelfMonitor watches Akmatori's own pipeline and opens a meta incident, plus
+// a channel notification when messaging is configured, when it degrades:
+// alert webhooks failing, the agent worker gone, the LLM provider rejecting
+// credentials, or an alert source going quiet for longer than its
+// silence_threshold_minutes setting allows. Without it those failures only show up as missing
+// investigations. Handlers feed it through the HealthSignalRecorder methods.
+type SelfMonitor struct {
+	db       *gorm.DB
+	cfg      SelfMonitorConfig
+	worker   WorkerStatus
+	channels ChannelManager
+	registry ProviderRegistry
+	now      func() time.Time
+
+	mu              sync.Mutex
+	webhooks        []webhookOutcome
+	workerDownSince time.Time
+	authFailures    int
+	lastAuthError   string
+	lastRunOK       bool
+	open            map[string]*selfMonitorIncident
+	loaded          bool
+}
+
+type webhookOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+type selfMonitorIncident struct {
+	uuid      string
+	channel   *database.Channel
+	messageID string
+}
+
+// NewSelfMonitor creates a self-monitor. worker may be nil, which disables
+// the worker check.
+func NewSelfMonitor(db *gorm.DB, cfg SelfMonitorConfig, worker WorkerStatus) *SelfMonitor {
+	return &SelfMonitor{
+		db:     db,
+		cfg:    cfg,
+		worker: worker,
+		now:    time.Now,
+		open:   make(map[string]*selfMonitorIncident),
+	}
+}
+
+// SetNotifier wires the channel lookup and provider registry used to post
+// notifications. Without it meta incidents are still recorded.
+func (m *SelfMonitor) SetNotifier(channels ChannelManager, registry ProviderRegistry) {
+	m.channels = channels
+	m.registry = registry
+}
+
+// RecordWebhook records the outcome of one alert webhook delivery.
+func (m *SelfMonitor) RecordWebhook(failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.webhooks = append(m.webhooks, webhookOutcome{at: now, failed: failed})
+	m.pruneWebhooksLocked(now)
+}
+
+// RecordAgentResult records how an agent run ended; errMsg is empty on
+// success. A success resets the LLM auth failure streak; errors other than
+// auth failures leave it unchanged.
+func (m *SelfMonitor) RecordAgentResult(errMsg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if errMsg == "" {
+		m.authFailures = 0
+		m.lastRunOK = true
+		return
+	}
+	if isLLMAuthError(errMsg) {
+		m.authFailures++
+		m.lastAuthError = errMsg
+		m.lastRunOK = false
+	}
+}
+
+// llmAuthErrorMarkers are substrings of provider errors that mean the
+// configured credentials were rejected rather than a transient failure.
+var llmAuthErrorMarkers = []string{
+	"unauthorized",
+	"invalid api key",
+	"invalid_api_key",
+	"incorrect api key",
+	"invalid x-api-key",
+	"authentication_error",
+	"authentication failed",
+	"permission_denied",
+}
+
+func isLLMAuthError(msg string) bool {
+	lower := strings.ToLower(msg)
+	for _, marker := range llmAuthErrorMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *SelfMonitor) pruneWebhooksLocked(now time.Time) {
+	cutoff := now.Add(-selfMonitorWebhookWindow)
+	i := 0
+	for i < len(m.webhooks) && m.webhooks[i].at.Before(cutoff) {
+		i++
+	}
+	m.webhooks = m.webhooks[i:]
+}
+
+// selfCheckResult is the state of one check after an evaluation. A check can
+// be neither degraded nor healthy when there is not enough evidence either
+// way, e.g. too few webhooks in the window; its incident then stays as is.
+type selfCheckResult struct {
+	check    string
+	degraded bool
+	healthy  bool
+	title    string
+	details  string
+	label    string // Names the check in the recovery notice; defaults to check
+}
+
+// snapshot evaluates every check against the recorded signals.
+func (m *SelfMonitor) snapshot() []selfCheckResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var results []selfCheckResult
+
+	if m.cfg.WebhookErrorRate > 0 {
+		m.pruneWebhooksLocked(now)
+		failed := 0
+		for _, w := range m.webhooks {
+			if w.failed {
+				failed++
+			}
+		}
+		total := len(m.webhooks)
+		r := selfCheckResult{check: SelfCheckWebhookErrors}
+		switch {
+		case total == 0 || total < m.cfg.WebhookMinRequests:
+		case float64(failed)/float64(total) < m.cfg.WebhookErrorRate:
+			r.healthy = true
+		default:
+			r.degraded = true
+			r.title = fmt.Sprintf("Alert webhook failure rate at %d%% (%d of %d in %s)", failed*100/total, failed, total, selfMonitorWebhookWindow)
+			r.details = fmt.Sprintf("%d of the last %d alert webhook deliveries were rejected or failed. Check the API logs for payload parse errors and server errors; alerts from affected sources are not being investigated.", failed, total)
+		}
+		results = append(results, r)
+	}
+
+	if m.worker != nil && m.cfg.WorkerDownAfter > 0 {
+		r := selfCheckResult{check: SelfCheckWorkerDisconnected}
+		if m.worker.IsWorkerConnected() {
+			m.workerDownSince = time.Time{}
+			r.healthy = true
+		} else {
+			if m.workerDownSince.IsZero() {
+				m.workerDownSince = now
+			}
+			if down := now.Sub(m.workerDownSince); down >= m.cfg.WorkerDownAfter {
+				r.degraded = true
+				r.title = fmt.Sprintf("Agent worker disconnected for %s", down.Round(time.Minute))
+				r.details = fmt.Sprintf("No agent worker has been connected since %s. Investigations cannot run until the worker reconnects to /ws/agent.", m.workerDownSince.UTC().Format(time.RFC3339))
+			}
+		}
+		results = append(results, r)
+	}
+
+	if m.cfg.LLMAuthFailures > 0 {
+		r := selfCheckResult{check: SelfCheckLLMAuth}
+		switch {
+		case m.lastRunOK:
+			r.healthy = true
+		case m.authFailures >= m.cfg.LLMAuthFailures:
+			r.degraded = true
+			r.title = fmt.Sprintf("LLM provider rejecting credentials (%d consecutive runs)", m.authFailures)
+			r.details = "Agent runs are failing with an authentication error from the LLM provider. Check the API key in Settings → LLM. Last error: " + truncateForLog(m.lastAuthError, 500)
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// silentSources judges every enabled alert source that has a silence
+// threshold by when it last delivered alerts. A source that has never
+// delivered is not judged, as there is no normal traffic to fall silent
+// from. Open incidents whose source was deleted, disabled or lost its
+// threshold are reported healthy so they close.
+func (m *SelfMonitor) silentSources(ctx context.Context) []selfCheckResult {
+	var instances []database.AlertSourceInstance
+	if err := m.db.WithContext(ctx).Where("enabled = ?", true).Find(&instances).Error; err != nil {
+		slog.Warn("self-monitor: failed to load alert sources", "err", err)
+		return nil
+	}
+	now := m.now()
+	judged := make(map[string]bool)
+	var results []selfCheckResult
+	for _, inst := range instances {
+		threshold, err := ParseSilenceThreshold(inst.Settings)
+		if err != nil || threshold <= 0 {
+			continue
+		}
+		r := selfCheckResult{
+			check: SelfCheckSourceSilentPrefix + inst.UUID,
+			label: "alert source " + inst.Name,
+		}
+		judged[r.check] = true
+		switch {
+		case inst.LastAlertAt == nil:
+		case now.Sub(*inst.LastAlertAt) < threshold:
+			r.healthy = true
+		default:
+			r.degraded = true
+			r.title = fmt.Sprintf("Alert source %s silent for %s", inst.Name, now.Sub(*inst.LastAlertAt).Round(time.Minute))
+			r.details = fmt.Sprintf("No alerts have been received from %s since %s, beyond its %s silence threshold. The sender may have lost its webhook configuration: check the webhook URL, secret and network path from the sender to Akmatori.", inst.Name, inst.LastAlertAt.UTC().Format(time.RFC3339), threshold)
+		}
+		results = append(results, r)
+	}
+	for check := range m.open {
+		if strings.HasPrefix(check, SelfCheckSourceSilentPrefix) && !judged[check] {
+			results = append(results, selfCheckResult{
+				check:   check,
+				healthy: true,
+				label:   "alert source " + strings.TrimPrefix(check, SelfCheckSourceSilentPrefix),
+			})
+		}
+	}
+	return results
+}
+
+func truncateForLog(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// loadOpen adopts meta incidents left open by a previous process so a
+// restart neither duplicates them nor leaves them open forever.
+func (m *SelfMonitor) loadOpen() {
+	if m.loaded {
+		return
+	}
+	var rows []database.Incident
+	if err := m.db.Where("source_kind = ? AND status = ?", database.IncidentSourceKindSelfMonitor, database.IncidentStatusDiagnosed).
+		Find(&rows).Error; err != nil {
+		slog.Warn("self-monitor: failed to load open meta incidents", "err", err)
+		return
+	}
+	for _, row := range rows {
+		m.open[row.SourceID] = &selfMonitorIncident{uuid: row.UUID}
+		// Keep the outage clock running across the restart so the adopted
+		// incident is not closed while the worker is still away.
+		if row.SourceID == SelfCheckWorkerDisconnected {
+			m.mu.Lock()
+			m.workerDownSince = row.CreatedAt.Add(-m.cfg.WorkerDownAfter)
+			m.mu.Unlock()
+		}
+	}
+	m.loaded = true
+}
+
+// Evaluate runs every check once, opening a meta incident for a newly
+// degraded check and closing the incident of a recovered one.
+func (m *SelfMonitor) Evaluate(ctx context.Context) {
+	m.loadOpen()
+	results := append(m.snapshot(), m.silentSources(ctx)...)
+	for _, r := range results {
+		inc, isOpen := m.open[r.check]
+		switch {
+		case r.degraded && !isOpen:
+			m.openIncident(ctx, r)
+		case r.healthy && isOpen:
+			m.resolveIncident(ctx, r, inc)
+		}
+	}
+}
+
+func (m *SelfMonitor) openIncident(ctx context.Context, r selfCheckResult) {
+	incident := &database.Incident{
+		UUID:       uuid.New().String(),
+		Source:     selfMonitorSource,
+		SourceID:   r.check,
+		SourceKind: database.IncidentSourceKindSelfMonitor,
+		Title:      r.title,
+		Status:     database.IncidentStatusDiagnosed,
+		Context:    database.JSONB{"check": r.check},
+		Response:   r.details,
+	}
+	if err := m.db.WithContext(ctx).Create(incident).Error; err != nil {
+		slog.Error("self-monitor: failed to open meta incident", "check", r.check, "err", err)
+		return
+	}
+	slog.Warn("self-monitor: degraded", "check", r.check, "incident_uuid", incident.UUID, "title", r.title)
+
+	open := &selfMonitorIncident{uuid: incident.UUID}
+	m.open[r.check] = open
+
+	channel, provider := m.resolveNotifier()
+	if provider == nil {
+		return
+	}
+	posted, err := provider.PostMessage(ctx, channel, fmt.Sprintf(":rotating_light: *Akmatori self-monitor:* %s\n%s", r.title, r.details))
+	if err != nil {
+		slog.Warn("self-monitor: failed to post notification", "check", r.check, "err", err)
+		return
+	}
+	open.channel = channel
+	if posted != nil {
+		open.messageID = posted.MessageID
+	}
+}
+
+func (m *SelfMonitor) resolveIncident(ctx context.Context, r selfCheckResult, inc *selfMonitorIncident) {
+	check := r.check
+	now := m.now()
+	if err := m.db.WithContext(ctx).Model(&database.Incident{}).
+		Where("uuid = ?", inc.uuid).
+		Updates(map[string]interface{}{
+			"status":       database.IncidentStatusClosed,
+			"resolved_at":  &now,
+			"completed_at": &now,
+		}).Error; err != nil {
+		slog.Error("self-monitor: failed to close meta incident", "check", check, "incident_uuid", inc.uuid, "err", err)
+		return
+	}
+	delete(m.open, check)
+	slog.Info("self-monitor: recovered", "check", check, "incident_uuid", inc.uuid)
+
+	// Reply in the original thread when this process posted the alert. After
+	// a restart the thread is unknown, and some providers cannot reply in
+	// threads, so fall back to a fresh message.
+	label := r.label
+	if label == "" {
+		label = strings.ReplaceAll(check, "_", " ")
+	}
+	text := fmt.Sprintf(":white_check_mark: *Akmatori self-monitor:* %s recovered", label)
+	if inc.channel != nil && inc.messageID != "" && m.registry != nil {
+		if provider, err := m.registry.Get(inc.channel.Integration.Provider); err == nil {
+			if _, err := provider.PostThreadReply(ctx, inc.channel, inc.messageID, text); err == nil {
+				return
+			}
+		}
+	}
+	channel, provider := m.resolveNotifier()
+	if provider == nil {
+		return
+	}
+	if _, err := provider.PostMessage(ctx, channel, text); err != nil {
+		slog.Warn("self-monitor: failed to post recovery", "check", check, "err", err)
+	}
+}
+
+// resolveNotifier returns the notification channel and its provider, or nil
+// when notifications are not configured.
+func (m *SelfMonitor) resolveNotifier() (*database.Channel, messaging.Provider) {
+	if m.channels == nil || m.registry == nil {
+		return nil, nil
+	}
+	var channel *database.Channel
+	var err error
+	if m.cfg.ChannelUUID != "" {
+		channel, err = m.channels.GetChannelByUUID(m.cfg.ChannelUUID)
+	} else {
+		channel, err = m.channels.ResolveDefault(database.MessagingProviderSlack)
+	}
+	if err != nil || channel == nil {
+		slog.Debug("self-monitor: no notification channel", "err", err)
+		return nil, nil
+	}
+	provider, err := m.registry.Get(channel.Integration.Provider)
+	if err != nil {
+		slog.Warn("self-monitor: notification provider unavailable", "provider", channel.Integration.Provider, "err", err)
+		return nil, nil
+	}
+	return channel, provider
+}
+
+// StartBackgroundMonitor evaluates the checks every selfMonitorInterval
+// until ctx is cancelled.
+func (m *SelfMonitor) StartBackgroundMonitor(ctx context.Context) {
+	slog.Info("starting self-monitor background service")
+
+	ticker := time.NewTicker(selfMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("self-monitor background service stopped")
+			return
+		case <-ticker.C:
+			m.Evaluate(ctx)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+type stubWorkerStatus struct{ connected bool }
+
+func (s *stubWorkerStatus) IsWorkerConnected() bool { return s.connected }
+
+// newTestSelfMonitor returns a monitor over a fresh test DB with a
+// controllable clock.
+func newTestSelfMonitor(t *testing.T, cfg SelfMonitorConfig, worker WorkerStatus) (*SelfMonitor, *gorm.DB, *time.Time) {
+	t.Helper()
+	db := setupIncidentTestDB(t)
+	db.Where("source_kind = ?", database.IncidentSourceKindSelfMonitor).Delete(&database.Incident{})
+	if err := db.AutoMigrate(&database.AlertSourceType{}, &database.AlertSourceInstance{}); err != nil {
+		t.Fatalf("migrate alert sources: %v", err)
+	}
+	db.Where("1 = 1").Delete(&database.AlertSourceInstance{})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewSelfMonitor(db, cfg, worker)
+	m.now = func() time.Time { return now }
+	return m, db, &now
+}
+
+func selfMonitorIncidents(t *testing.T, db *gorm.DB, check string) []database.Incident {
+	t.Helper()
+	var rows []database.Incident
+	if err := db.Where("source_kind = ? AND source_id = ?", database.IncidentSourceKindSelfMonitor, check).
+		Find(&rows).Error; err != nil {
+		t.Fatalf("query incidents: %v", err)
+	}
+	return rows
+}
+
+func TestSelfMonitor_WebhookErrorRate(t *testing.T) {
+	m, db, now := newTestSelfMonitor(t, SelfMonitorConfig{WebhookErrorRate: 0.5, WebhookMinRequests: 4}, nil)
+	ctx := context.Background()
+
+	// Below the minimum sample size nothing fires, even at 100% failures.
+	for i := 0; i < 3; i++ {
+		m.RecordWebhook(true)
+	}
+	m.Evaluate(ctx)
+	if got := selfMonitorIncidents(t, db, SelfCheckWebhookErrors); len(got) != 0 {
+		t.Fatalf("expected no incident below min requests, got %d", len(got))
+	}
+
+	m.RecordWebhook(false)
+	m.Evaluate(ctx)
+	m.Evaluate(ctx)
+	rows := selfMonitorIncidents(t, db, SelfCheckWebhookErrors)
+	if len(rows) != 1 {
+		t.Fatalf("expected one incident, got %d", len(rows))
+	}
+	if rows[0].Status != database.IncidentStatusDiagnosed || rows[0].Source != "akmatori" {
+		t.Errorf("incident = status %q source %q", rows[0].Status, rows[0].Source)
+	}
+	if !strings.Contains(rows[0].Title, "75%") {
+		t.Errorf("title = %q, want failure rate", rows[0].Title)
+	}
+
+	// An empty window is not evidence of recovery; the incident stays open
+	// until enough healthy deliveries arrive.
+	*now = now.Add(selfMonitorWebhookWindow + time.Second)
+	m.Evaluate(ctx)
+	if rows = selfMonitorIncidents(t, db, SelfCheckWebhookErrors); rows[0].Status != database.IncidentStatusDiagnosed {
+		t.Fatalf("incident closed without traffic: %q", rows[0].Status)
+	}
+	for i := 0; i < 4; i++ {
+		m.RecordWebhook(false)
+	}
+	m.Evaluate(ctx)
+	rows = selfMonitorIncidents(t, db, SelfCheckWebhookErrors)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusClosed {
+		t.Fatalf("expected incident closed, got %+v", rows)
+	}
+	if rows[0].ResolvedAt == nil {
+		t.Error("expected resolved_at to be set")
+	}
+}
+
+func TestSelfMonitor_WorkerDisconnected(t *testing.T) {
+	worker := &stubWorkerStatus{}
+	m, db, now := newTestSelfMonitor(t, SelfMonitorConfig{WorkerDownAfter: 5 * time.Minute}, worker)
+	ctx := context.Background()
+
+	m.Evaluate(ctx)
+	*now = now.Add(4 * time.Minute)
+	m.Evaluate(ctx)
+	if got := selfMonitorIncidents(t, db, SelfCheckWorkerDisconnected); len(got) != 0 {
+		t.Fatalf("expected no incident before threshold, got %d", len(got))
+	}
+
+	*now = now.Add(time.Minute)
+	m.Evaluate(ctx)
+	rows := selfMonitorIncidents(t, db, SelfCheckWorkerDisconnected)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusDiagnosed {
+		t.Fatalf("expected one open incident, got %+v", rows)
+	}
+
+	worker.connected = true
+	m.Evaluate(ctx)
+	rows = selfMonitorIncidents(t, db, SelfCheckWorkerDisconnected)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusClosed {
+		t.Fatalf("expected incident closed after reconnect, got %+v", rows)
+	}
+}
+
+func TestSelfMonitor_LLMAuthStreak(t *testing.T) {
+	m, db, _ := newTestSelfMonitor(t, SelfMonitorConfig{LLMAuthFailures: 2}, nil)
+	ctx := context.Background()
+
+	m.RecordAgentResult("Error: 401 Unauthorized - Incorrect API key provided")
+	m.RecordAgentResult("context deadline exceeded")
+	m.Evaluate(ctx)
+	if got := selfMonitorIncidents(t, db, SelfCheckLLMAuth); len(got) != 0 {
+		t.Fatalf("non-auth errors must not count, got %d incidents", len(got))
+	}
+
+	m.RecordAgentResult(`{"type":"authentication_error","message":"invalid x-api-key"}`)
+	m.Evaluate(ctx)
+	rows := selfMonitorIncidents(t, db, SelfCheckLLMAuth)
+	if len(rows) != 1 {
+		t.Fatalf("expected one incident, got %d", len(rows))
+	}
+	if !strings.Contains(rows[0].Response, "invalid x-api-key") {
+		t.Errorf("response should include last error, got %q", rows[0].Response)
+	}
+
+	m.RecordAgentResult("")
+	m.Evaluate(ctx)
+	rows = selfMonitorIncidents(t, db, SelfCheckLLMAuth)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusClosed {
+		t.Fatalf("expected incident closed after a successful run, got %+v", rows)
+	}
+}
+
+func TestSelfMonitor_AdoptsOpenIncidentAfterRestart(t *testing.T) {
+	worker := &stubWorkerStatus{}
+	m, db, now := newTestSelfMonitor(t, SelfMonitorConfig{WorkerDownAfter: time.Minute}, worker)
+	ctx := context.Background()
+
+	m.Evaluate(ctx)
+	*now = now.Add(2 * time.Minute)
+	m.Evaluate(ctx)
+
+	restarted := NewSelfMonitor(db, SelfMonitorConfig{WorkerDownAfter: time.Minute}, worker)
+	restarted.now = m.now
+	restarted.Evaluate(ctx)
+	*now = now.Add(2 * time.Minute)
+	restarted.Evaluate(ctx)
+	if rows := selfMonitorIncidents(t, db, SelfCheckWorkerDisconnected); len(rows) != 1 {
+		t.Fatalf("restart should adopt the open incident, got %d rows", len(rows))
+	}
+
+	worker.connected = true
+	restarted.Evaluate(ctx)
+	rows := selfMonitorIncidents(t, db, SelfCheckWorkerDisconnected)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusClosed {
+		t.Fatalf("adopted incident should close on recovery, got %+v", rows)
+	}
+}
+
+func TestSelfMonitor_PostsNotifications(t *testing.T) {
+	worker := &stubWorkerStatus{}
+	m, _, now := newTestSelfMonitor(t, SelfMonitorConfig{WorkerDownAfter: time.Minute, ChannelUUID: "ch-ops"}, worker)
+	provider := &recordingProvider{}
+	m.SetNotifier(&recordingChannelManager{
+		channels: []database.Channel{{
+			UUID:        "ch-ops",
+			Integration: database.Integration{Provider: database.MessagingProviderSlack},
+		}},
+	}, &fakeProviderRegistry{provider: provider})
+	ctx := context.Background()
+
+	m.Evaluate(ctx)
+	*now = now.Add(time.Minute)
+	m.Evaluate(ctx)
+	if len(provider.posts) != 1 {
+		t.Fatalf("expected one notification, got %d", len(provider.posts))
+	}
+	if provider.posts[0].channel.UUID != "ch-ops" || !strings.Contains(provider.posts[0].text, "Agent worker disconnected") {
+		t.Errorf("unexpected notification: %+v", provider.posts[0])
+	}
+
+	// recordingProvider does not implement thread replies, so recovery is
+	// reported as a fresh message.
+	worker.connected = true
+	m.Evaluate(ctx)
+	if len(provider.posts) != 2 || !strings.Contains(provider.posts[1].text, "worker disconnected recovered") {
+		t.Fatalf("expected recovery notification, got %+v", provider.posts)
+	}
+}
+
+func TestSelfMonitor_SilentAlertSource(t *testing.T) {
+	m, db, now := newTestSelfMonitor(t, SelfMonitorConfig{}, nil)
+	ctx := context.Background()
+
+	sourceType := database.AlertSourceType{Name: "silence-test-type"}
+	if err := db.Where(&sourceType).FirstOrCreate(&sourceType).Error; err != nil {
+		t.Fatalf("create source type: %v", err)
+	}
+	chatty := database.AlertSourceInstance{
+		UUID:              "src-chatty",
+		AlertSourceTypeID: sourceType.ID,
+		Name:              "prod-alertmanager",
+		Enabled:           true,
+		Settings:          database.JSONB{SilenceThresholdSettingKey: float64(60)},
+	}
+	unconfigured := database.AlertSourceInstance{
+		UUID:              "src-quiet",
+		AlertSourceTypeID: sourceType.ID,
+		Name:              "rarely-fires",
+		Enabled:           true,
+	}
+	for _, inst := range []*database.AlertSourceInstance{&chatty, &unconfigured} {
+		if err := db.Create(inst).Error; err != nil {
+			t.Fatalf("create instance: %v", err)
+		}
+	}
+	check := SelfCheckSourceSilentPrefix + chatty.UUID
+	markReceived := func(at time.Time) {
+		t.Helper()
+		for _, id := range []uint{chatty.ID, unconfigured.ID} {
+			if err := db.Model(&database.AlertSourceInstance{}).Where("id = ?", id).UpdateColumn("last_alert_at", at).Error; err != nil {
+				t.Fatalf("mark received: %v", err)
+			}
+		}
+	}
+
+	// A source that has never delivered has no normal to fall silent from.
+	*now = now.Add(24 * time.Hour)
+	m.Evaluate(ctx)
+	if got := selfMonitorIncidents(t, db, check); len(got) != 0 {
+		t.Fatalf("expected no incident before first delivery, got %d", len(got))
+	}
+
+	markReceived(*now)
+	*now = now.Add(59 * time.Minute)
+	m.Evaluate(ctx)
+	if got := selfMonitorIncidents(t, db, check); len(got) != 0 {
+		t.Fatalf("expected no incident within threshold, got %d", len(got))
+	}
+
+	*now = now.Add(2 * time.Minute)
+	m.Evaluate(ctx)
+	m.Evaluate(ctx)
+	rows := selfMonitorIncidents(t, db, check)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusDiagnosed {
+		t.Fatalf("expected one open incident, got %+v", rows)
+	}
+	if !strings.Contains(rows[0].Title, "prod-alertmanager") {
+		t.Errorf("title = %q, want source name", rows[0].Title)
+	}
+	if got := selfMonitorIncidents(t, db, SelfCheckSourceSilentPrefix+unconfigured.UUID); len(got) != 0 {
+		t.Errorf("source without a threshold must not be judged, got %d incidents", len(got))
+	}
+
+	markReceived(*now)
+	m.Evaluate(ctx)
+	rows = selfMonitorIncidents(t, db, check)
+	if len(rows) != 1 || rows[0].Status != database.IncidentStatusClosed {
+		t.Fatalf("expected incident closed after a delivery, got %+v", rows)
+	}
+
+	// Disabling a silent source closes its incident rather than leaving it
+	// open forever.
+	*now = now.Add(2 * time.Hour)
+	m.Evaluate(ctx)
+	if err := db.Model(&database.AlertSourceInstance{}).Where("id = ?", chatty.ID).UpdateColumn("enabled", false).Error; err != nil {
+		t.Fatalf("disable source: %v", err)
+	}
+	m.Evaluate(ctx)
+	rows = selfMonitorIncidents(t, db, check)
+	if len(rows) != 2 || rows[0].Status != database.IncidentStatusClosed || rows[1].Status != database.IncidentStatusClosed {
+		t.Fatalf("expected both incidents closed, got %+v", rows)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// SeverityInferenceSettingKey is the AlertSourceInstance.Settings key that
+// picks how alerts without a usable severity get one: "rules" (default),
+// "llm" (rules, then a one-shot LLM call when no rule matches) or "off".
+const SeverityInferenceSettingKey = "severity_inference"
+
+// Severity inference modes.
+const (
+	SeverityInferenceRules = "rules"
+	SeverityInferenceLLM   = "llm"
+	SeverityInferenceOff   = "off"
+)
+
+// How an inferred severity was decided, recorded in the incident context.
+const (
+	SeverityInferredByLabel = "label"
+	SeverityInferredByRules = "rules"
+	SeverityInferredByLLM   = "llm"
+)
+
+// severityInferenceTimeout caps the LLM fallback. It runs before routing, so
+// a slow provider delays the alert; on timeout the default severity stands.
+const severityInferenceTimeout = 10 * time.Second
+
+// ParseSeverityInference returns the source's inference mode.
+func ParseSeverityInference(settings map[string]interface{}) (string, error) {
+	raw, ok := settings[SeverityInferenceSettingKey]
+	if !ok || raw == nil {
+		return SeverityInferenceRules, nil
+	}
+	mode, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be rules, llm or off", SeverityInferenceSettingKey)
+	}
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return SeverityInferenceRules, nil
+	case SeverityInferenceRules, SeverityInferenceLLM, SeverityInferenceOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%s must be rules, llm or off", SeverityInferenceSettingKey)
+}
+
+// severityLabelKeys are labels that commonly carry a severity under another
+// name when the source's severity field is empty.
+var severityLabelKeys = []string{"severity", "priority", "level", "urgency", "criticality", "impact"}
+
+// severityRules are checked in order against the alert name, summary and
+// description; the first tier with a matching phrase wins, so a message that
+// mentions both "down" and "warning" is critical.
+var severityRules = []struct {
+	severity database.AlertSeverity
+	pattern  *regexp.Regexp
+}{
+	{database.AlertSeverityCritical, severityPattern(
+		"down", "outage", "unreachable", "not responding", "crash(ed|ing)?", "fatal", "panic",
+		"emergency", "data loss", "corrupt(ed|ion)?", "out of memory", "oom( ?killed)?",
+		"disk full", "no space left", "split.brain", "service unavailable")},
+	{database.AlertSeverityHigh, severityPattern(
+		"error(s)?", "fail(ed|ure|ing)?", "timed? ?out", "timeout(s)?", "5\\d\\d", "degraded",
+		"refused", "exception", "unhealthy", "lag(ging)?", "restart(ed|ing)? loop", "crashloop(backoff)?")},
+	{database.AlertSeverityWarning, severityPattern(
+		"warn(ing)?", "high", "slow", "latency", "threshold", "approaching", "nearly full",
+		"above", "exceed(ed|s)?", "elevated", "spike")},
+	{database.AlertSeverityInfo, severityPattern(
+		"info(rmational)?", "notice", "test(ing)?", "heartbeat", "deploy(ed|ment)?",
+		"completed", "succeeded", "started", "scheduled")},
+}
+
+func severityPattern(phrases ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(phrases, "|") + `)\b`)
+}
+
+// SeverityInferrer assigns a severity to alerts whose source omitted one:
+// from severity-like labels, then keyword rules, then optionally a cheap
+// one-shot LLM call. Provider-agnostic via OneShotLLMCaller.
+type SeverityInferrer struct {
+	caller OneShotLLMCaller
+}
+
+// NewSeverityInferrer returns an inferrer. caller may be nil, which limits it
+// to labels and rules.
+func NewSeverityInferrer(caller OneShotLLMCaller) *SeverityInferrer {
+	return &SeverityInferrer{caller: caller}
+}
+
+// InferSeverity returns the inferred severity and how it was decided. ok is
+// false when nothing matched (or the LLM was unavailable); the alert then
+// keeps its default.
+func (s *SeverityInferrer) InferSeverity(ctx context.Context, alert alerts.NormalizedAlert, useLLM bool) (severity database.AlertSeverity, by string, ok bool) {
+	if severity, ok := severityFromLabels(alert.TargetLabels); ok {
+		return severity, SeverityInferredByLabel, true
+	}
+	if severity, ok := severityFromRules(alert); ok {
+		return severity, SeverityInferredByRules, true
+	}
+	if !useLLM {
+		return "", "", false
+	}
+	severity, err := s.severityFromLLM(ctx, alert)
+	if err != nil {
+		return "", "", false
+	}
+	return severity, SeverityInferredByLLM, true
+}
+
+func severityFromLabels(labels map[string]string) (database.AlertSeverity, bool) {
+	for _, key := range severityLabelKeys {
+		for k, v := range labels {
+			if strings.EqualFold(k, key) {
+				if severity, ok := alerts.ParseSeverity(v, alerts.DefaultSeverityMapping); ok {
+					return severity, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func severityFromRules(alert alerts.NormalizedAlert) (database.AlertSeverity, bool) {
+	text := strings.Join([]string{alert.AlertName, alert.Summary, alert.Description}, "\n")
+	for _, rule := range severityRules {
+		if rule.pattern.MatchString(text) {
+			return rule.severity, true
+		}
+	}
+	return "", false
+}
+
+func (s *SeverityInferrer) severityFromLLM(ctx context.Context, alert alerts.NormalizedAlert) (database.AlertSeverity, error) {
+	if s == nil || s.caller == nil {
+		return "", ErrWorkerNotConnected
+	}
+	settings, err := database.GetLLMSettings()
+	if err != nil {
+		return "", fmt.Errorf("infer severity: load llm settings: %w", err)
+	}
+	worker := BuildLLMSettingsForWorker(settings)
+	if settings == nil || settings.APIKey == "" || worker == nil {
+		return "", ErrWorkerNotConnected
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, severityInferenceTimeout)
+	defer cancel()
+	raw, err := s.caller.OneShotLLM(callCtx, worker, severityInferenceSystemPrompt, buildSeverityUserPrompt(alert), 30, 0.0)
+	if err != nil {
+		return "", fmt.Errorf("infer severity: llm call: %w", err)
+	}
+	return parseSeverityVerdict(raw)
+}
+
+const severityInferenceSystemPrompt = `You assign a severity to a monitoring alert that arrived without one.
+
+Severities:
+  - critical: a service or host is down, data is at risk, or users are broadly affected now
+  - high: errors or failures with user impact likely soon, or a degraded production service
+  - warning: a threshold is approaching or a non-urgent anomaly needs a look
+  - info: informational, tests, deploy notices, nothing to fix
+
+Return STRICT JSON: {"severity": "critical" | "high" | "warning" | "info"}
+Output JSON only. No code fences.`
+
+func buildSeverityUserPrompt(alert alerts.NormalizedAlert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Alert: %s\n", truncateForPrompt(alert.AlertName, 200))
+	if alert.TargetHost != "" {
+		fmt.Fprintf(&b, "Host: %s\n", alert.TargetHost)
+	}
+	if alert.TargetService != "" {
+		fmt.Fprintf(&b, "Service: %s\n", alert.TargetService)
+	}
+	if alert.Summary != "" {
+		fmt.Fprintf(&b, "Summary: %s\n", truncateForPrompt(alert.Summary, 500))
+	}
+	if alert.Description != "" && alert.Description != alert.Summary {
+		fmt.Fprintf(&b, "Description: %s\n", truncateForPrompt(alert.Description, 1500))
+	}
+	if len(alert.TargetLabels) > 0 {
+		keys := make([]string, 0, len(alert.TargetLabels))
+		for k := range alert.TargetLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("Labels:\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  %s=%s\n", k, truncateForPrompt(alert.TargetLabels[k], 200))
+		}
+	}
+	return b.String()
+}
+
+func parseSeverityVerdict(raw string) (database.AlertSeverity, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "```")
+	var verdict struct {
+		Severity string `json:"severity"`
+	}
+	if err := json.Unmarshal([]byte(raw), &verdict); err != nil {
+		return "", fmt.Errorf("infer severity: parse verdict: %w", err)
+	}
+	severity, ok := alerts.ParseSeverity(verdict.Severity, nil)
+	if !ok {
+		return "", errors.New("infer severity: unknown severity " + verdict.Severity)
+	}
+	return severity, nil
+}
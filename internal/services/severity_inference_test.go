@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func TestParseSeverityInference(t *testing.T) {
+	tests := []struct {
+		settings map[string]interface{}
+		want     string
+		wantErr  bool
+	}{
+		{nil, SeverityInferenceRules, false},
+		{map[string]interface{}{"severity_inference": ""}, SeverityInferenceRules, false},
+		{map[string]interface{}{"severity_inference": " LLM "}, SeverityInferenceLLM, false},
+		{map[string]interface{}{"severity_inference": "off"}, SeverityInferenceOff, false},
+		{map[string]interface{}{"severity_inference": "always"}, "", true},
+		{map[string]interface{}{"severity_inference": true}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSeverityInference(tt.settings)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSeverityInference(%v) = %q, %v; want %q, err=%v", tt.settings, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSeverityInferrer_LabelsAndRules(t *testing.T) {
+	s := NewSeverityInferrer(nil)
+	tests := []struct {
+		name  string
+		alert alerts.NormalizedAlert
+		want  database.AlertSeverity
+		by    string
+	}{
+		{"priority label", alerts.NormalizedAlert{AlertName: "Disk usage", TargetLabels: map[string]string{"Priority": "P1"}}, database.AlertSeverityCritical, SeverityInferredByLabel},
+		{"critical beats warning", alerts.NormalizedAlert{AlertName: "Warning: api-7 is down"}, database.AlertSeverityCritical, SeverityInferredByRules},
+		{"high", alerts.NormalizedAlert{AlertName: "Checkout", Summary: "payment requests failing with 503"}, database.AlertSeverityHigh, SeverityInferredByRules},
+		{"warning", alerts.NormalizedAlert{AlertName: "Queue latency above threshold"}, database.AlertSeverityWarning, SeverityInferredByRules},
+		{"info", alerts.NormalizedAlert{AlertName: "Nightly backup completed"}, database.AlertSeverityInfo, SeverityInferredByRules},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, by, ok := s.InferSeverity(context.Background(), tt.alert, false)
+			if !ok || got != tt.want || by != tt.by {
+				t.Errorf("InferSeverity = %q, %q, %v; want %q, %q", got, by, ok, tt.want, tt.by)
+			}
+		})
+	}
+
+	if _, _, ok := s.InferSeverity(context.Background(), alerts.NormalizedAlert{AlertName: "Custom webhook"}, false); ok {
+		t.Error("expected no inference for an alert without severity hints")
+	}
+}
+
+func TestSeverityInferrer_LLMFallback(t *testing.T) {
+	caller := setupClassifierTest(t)
+	caller.respond = func(ctx context.Context) (string, error) {
+		return "```json\n{\"severity\": \"high\"}\n```", nil
+	}
+	s := NewSeverityInferrer(caller)
+	alert := alerts.NormalizedAlert{AlertName: "Custom webhook", TargetHost: "db-1", TargetLabels: map[string]string{"team": "storage"}}
+
+	got, by, ok := s.InferSeverity(context.Background(), alert, true)
+	if !ok || got != database.AlertSeverityHigh || by != SeverityInferredByLLM {
+		t.Fatalf("InferSeverity = %q, %q, %v; want high by llm", got, by, ok)
+	}
+	if !strings.Contains(caller.lastUser, "Host: db-1") || !strings.Contains(caller.lastUser, "team=storage") {
+		t.Errorf("user prompt missing alert context: %q", caller.lastUser)
+	}
+
+	caller.respond = func(ctx context.Context) (string, error) { return "", errors.New("provider down") }
+	if _, _, ok := s.InferSeverity(context.Background(), alert, true); ok {
+		t.Error("expected no inference when the LLM call fails")
+	}
+}
+
+func TestParseSeverityVerdict(t *testing.T) {
+	if got, err := parseSeverityVerdict(`{"severity":"CRITICAL"}`); err != nil || got != database.AlertSeverityCritical {
+		t.Errorf("parseSeverityVerdict = %q, %v", got, err)
+	}
+	for _, raw := range []string{`{"severity":"meh"}`, `critical`, `{}`} {
+		if _, err := parseSeverityVerdict(raw); err == nil {
+			t.Errorf("parseSeverityVerdict(%q) succeeded, want error", raw)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrSilenceNotFound is returned when no silence has the requested UUID.
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// ErrInvalidSilence wraps validation failures so the API can answer 400.
+var ErrInvalidSilence = errors.New("invalid silence")
+
+// maxSilenceDuration caps a single silence. Longer maintenance is better
+// handled by disabling the alert source than by a silence nobody remembers.
+const maxSilenceDuration = 90 * 24 * time.Hour
+
+// Silence states accepted by ListSilences.
+const (
+	SilenceStateActive  = "active"
+	SilenceStatePending = "pending"
+	SilenceStateExpired = "expired"
+)
+
+// SilenceService manages maintenance-window silences and decides whether a
+// firing alert is suppressed by one. Suppressed alerts are recorded in
+// suppressed_alerts so operators can see what a window swallowed.
+type SilenceService struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewSilenceService creates a silence service.
+func NewSilenceService(db *gorm.DB) *SilenceService {
+	return &SilenceService{db: db, now: time.Now}
+}
+
+// ListSilences returns silences newest first, optionally filtered to one
+// state (active, pending or expired).
+func (s *SilenceService) ListSilences(ctx context.Context, state string) ([]database.Silence, error) {
+	now := s.now()
+	q := s.db.WithContext(ctx).Order("starts_at DESC, id DESC").Limit(500)
+	switch state {
+	case "":
+	case SilenceStateActive:
+		q = q.Where("starts_at <= ? AND ends_at > ?", now, now)
+	case SilenceStatePending:
+		q = q.Where("starts_at > ?", now)
+	case SilenceStateExpired:
+		q = q.Where("ends_at <= ?", now)
+	default:
+		return nil, fmt.Errorf("%w: state must be active, pending or expired", ErrInvalidSilence)
+	}
+	silences := []database.Silence{}
+	if err := q.Find(&silences).Error; err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// GetSilence returns the silence with the given UUID.
+func (s *SilenceService) GetSilence(ctx context.Context, silenceUUID string) (*database.Silence, error) {
+	var silence database.Silence
+	err := s.db.WithContext(ctx).Where("uuid = ?", silenceUUID).First(&silence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSilenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// CreateSilence validates and stores a new silence. A zero StartsAt means
+// "now".
+func (s *SilenceService) CreateSilence(ctx context.Context, silence *database.Silence) error {
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = s.now()
+	}
+	if err := validateSilence(silence); err != nil {
+		return err
+	}
+	if !silence.EndsAt.After(s.now()) {
+		return fmt.Errorf("%w: ends_at must be in the future", ErrInvalidSilence)
+	}
+	silence.ID = 0
+	silence.UUID = uuid.New().String()
+	return s.db.WithContext(ctx).Create(silence).Error
+}
+
+// UpdateSilence replaces the matchers, window and comment of an existing
+// silence. CreatedBy and UUID are kept.
+func (s *SilenceService) UpdateSilence(ctx context.Context, silenceUUID string, update *database.Silence) (*database.Silence, error) {
+	existing, err := s.GetSilence(ctx, silenceUUID)
+	if err != nil {
+		return nil, err
+	}
+	if update.StartsAt.IsZero() {
+		update.StartsAt = existing.StartsAt
+	}
+	if err := validateSilence(update); err != nil {
+		return nil, err
+	}
+	existing.Comment = update.Comment
+	existing.SourceUUID = update.SourceUUID
+	existing.AlertName = update.AlertName
+	existing.TargetHost = update.TargetHost
+	existing.Labels = update.Labels
+	existing.StartsAt = update.StartsAt
+	existing.EndsAt = update.EndsAt
+	if err := s.db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// ExpireSilence ends a silence now. The row is kept so its suppressed
+// alerts still point at it; expiring an already expired silence is a no-op.
+func (s *SilenceService) ExpireSilence(ctx context.Context, silenceUUID string) (*database.Silence, error) {
+	silence, err := s.GetSilence(ctx, silenceUUID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !silence.EndsAt.After(now) {
+		return silence, nil
+	}
+	silence.EndsAt = now
+	if silence.StartsAt.After(now) {
+		silence.StartsAt = now
+	}
+	if err := s.db.WithContext(ctx).Model(silence).Updates(map[string]interface{}{
+		"starts_at": silence.StartsAt,
+		"ends_at":   silence.EndsAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// ListSuppressedAlerts returns the alerts a silence suppressed, newest
+// first. An empty silenceUUID lists across all silences.
+func (s *SilenceService) ListSuppressedAlerts(ctx context.Context, silenceUUID string) ([]database.SuppressedAlert, error) {
+	q := s.db.WithContext(ctx).Order("created_at DESC, id DESC").Limit(500)
+	if silenceUUID != "" {
+		q = q.Where("silence_uuid = ?", silenceUUID)
+	}
+	rows := []database.SuppressedAlert{}
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// SilenceAlert returns the active silence matching a firing alert, or nil
+// when the alert should be investigated. A match is recorded in
+// suppressed_alerts before returning.
+func (s *SilenceService) SilenceAlert(ctx context.Context, sourceUUID string, alert alerts.NormalizedAlert) (*database.Silence, error) {
+	now := s.now()
+	var active []database.Silence
+	if err := s.db.WithContext(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Where("source_uuid = '' OR source_uuid IS NULL OR source_uuid = ?", sourceUUID).
+		Order("starts_at ASC, id ASC").
+		Find(&active).Error; err != nil {
+		return nil, err
+	}
+	for i := range active {
+		if !SilenceMatches(&active[i], sourceUUID, alert) {
+			continue
+		}
+		labels := database.JSONB{}
+		for k, v := range alert.TargetLabels {
+			labels[k] = v
+		}
+		record := database.SuppressedAlert{
+			SilenceUUID:  active[i].UUID,
+			SourceUUID:   sourceUUID,
+			AlertName:    alert.AlertName,
+			TargetHost:   alert.TargetHost,
+			Severity:     alert.Severity,
+			Summary:      alert.Summary,
+			Fingerprint:  alert.SourceFingerprint,
+			TargetLabels: labels,
+		}
+		if err := s.db.WithContext(ctx).Create(&record).Error; err != nil {
+			// The alert is still suppressed; losing the audit row is better
+			// than investigating during maintenance.
+			slog.Warn("silences: failed to record suppressed alert", "silence_uuid", active[i].UUID, "err", err)
+		}
+		return &active[i], nil
+	}
+	return nil, nil
+}
+
+// SilenceMatches reports whether a silence's matchers all match the alert.
+// It does not check the silence's time window.
+func SilenceMatches(silence *database.Silence, sourceUUID string, alert alerts.NormalizedAlert) bool {
+	if silence.SourceUUID != "" && silence.SourceUUID != sourceUUID {
+		return false
+	}
+	return alertFieldsMatch(silence.AlertName, silence.TargetHost, silence.Labels, alert)
+}
+
+// alertFieldsMatch applies the glob matchers shared by silences and routing
+// rules. Empty matchers match everything.
+func alertFieldsMatch(alertName, targetHost string, labels database.JSONB, alert alerts.NormalizedAlert) bool {
+	if alertName != "" && !globMatch(alertName, alert.AlertName) {
+		return false
+	}
+	if targetHost != "" && !globMatch(targetHost, alert.TargetHost) {
+		return false
+	}
+	for name, raw := range labels {
+		pattern, _ := raw.(string)
+		value, ok := alert.TargetLabels[name]
+		if !ok || !globMatch(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// globMatch matches s against a case-insensitive pattern in which "*"
+// matches any run of characters and everything else is literal.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// validateSilence checks the window and matchers. A silence with no
+// matchers at all would swallow every alert, so at least one is required.
+func validateSilence(silence *database.Silence) error {
+	silence.Comment = strings.TrimSpace(silence.Comment)
+	silence.SourceUUID = strings.TrimSpace(silence.SourceUUID)
+	silence.AlertName = strings.TrimSpace(silence.AlertName)
+	silence.TargetHost = strings.TrimSpace(silence.TargetHost)
+	if silence.EndsAt.IsZero() {
+		return fmt.Errorf("%w: ends_at is required", ErrInvalidSilence)
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSilence)
+	}
+	if silence.EndsAt.Sub(silence.StartsAt) > maxSilenceDuration {
+		return fmt.Errorf("%w: a silence may last at most %d days", ErrInvalidSilence, int(maxSilenceDuration.Hours()/24))
+	}
+	if !labelMatchersValid(silence.Labels) {
+		return fmt.Errorf("%w: labels must map label names to non-empty strings", ErrInvalidSilence)
+	}
+	if silence.SourceUUID == "" && silence.AlertName == "" && silence.TargetHost == "" && len(silence.Labels) == 0 {
+		return fmt.Errorf("%w: at least one of source_uuid, alert_name, target_host or labels is required", ErrInvalidSilence)
+	}
+	return nil
+}
+
+// labelMatchersValid reports whether every label matcher maps a non-empty
+// label name to a non-empty glob.
+func labelMatchersValid(labels database.JSONB) bool {
+	for name, raw := range labels {
+		value, ok := raw.(string)
+		if strings.TrimSpace(name) == "" || !ok || strings.TrimSpace(value) == "" {
+			return false
+		}
+	}
+	return true
+}
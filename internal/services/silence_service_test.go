@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/alerts"
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestSilenceMatches(t *testing.T) {
+	alert := alerts.NormalizedAlert{
+		AlertName:    "DiskSpaceLow",
+		TargetHost:   "db-01.prod",
+		TargetLabels: map[string]string{"env": "prod", "team": "storage"},
+	}
+	cases := []struct {
+		name    string
+		silence database.Silence
+		want    bool
+	}{
+		{"alert name glob is case-insensitive", database.Silence{AlertName: "disk*"}, true},
+		{"host glob", database.Silence{TargetHost: "db-*.prod"}, true},
+		{"host mismatch", database.Silence{TargetHost: "web-*"}, false},
+		{"labels match", database.Silence{Labels: database.JSONB{"env": "prod", "team": "stor*"}}, true},
+		{"missing label", database.Silence{Labels: database.JSONB{"region": "*"}}, false},
+		{"other source", database.Silence{SourceUUID: "other", AlertName: "*"}, false},
+		{"same source", database.Silence{SourceUUID: "src-1", AlertName: "*"}, true},
+		{"matchers are ANDed", database.Silence{AlertName: "DiskSpaceLow", TargetHost: "db-02.prod"}, false},
+		{"regex characters are literal", database.Silence{TargetHost: "db-01?prod"}, false},
+	}
+	for _, tc := range cases {
+		if got := SilenceMatches(&tc.silence, "src-1", alert); got != tc.want {
+			t.Errorf("%s: SilenceMatches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSilenceService(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.Silence{}, &database.SuppressedAlert{})
+	svc := NewSilenceService(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := svc.CreateSilence(ctx, &database.Silence{EndsAt: now.Add(time.Hour)}); !errors.Is(err, ErrInvalidSilence) {
+		t.Errorf("silence without matchers: err = %v, want ErrInvalidSilence", err)
+	}
+	if err := svc.CreateSilence(ctx, &database.Silence{AlertName: "x", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}); !errors.Is(err, ErrInvalidSilence) {
+		t.Errorf("silence ending in the past: err = %v, want ErrInvalidSilence", err)
+	}
+
+	window := &database.Silence{TargetHost: "db-*", Comment: "kernel upgrade", EndsAt: now.Add(time.Hour)}
+	if err := svc.CreateSilence(ctx, window); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	planned := &database.Silence{TargetHost: "web-*", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	if err := svc.CreateSilence(ctx, planned); err != nil {
+		t.Fatalf("create planned: %v", err)
+	}
+
+	firing := alerts.NormalizedAlert{AlertName: "HostDown", TargetHost: "db-01", SourceFingerprint: "fp-1"}
+	silence, err := svc.SilenceAlert(ctx, "src-1", firing)
+	if err != nil || silence == nil || silence.UUID != window.UUID {
+		t.Fatalf("SilenceAlert = %+v, %v; want the active window", silence, err)
+	}
+	// The planned window has not started yet.
+	if silence, _ := svc.SilenceAlert(ctx, "src-1", alerts.NormalizedAlert{AlertName: "HostDown", TargetHost: "web-01"}); silence != nil {
+		t.Errorf("pending silence suppressed an alert: %+v", silence)
+	}
+
+	suppressed, err := svc.ListSuppressedAlerts(ctx, window.UUID)
+	if err != nil || len(suppressed) != 1 || suppressed[0].Fingerprint != "fp-1" || suppressed[0].SourceUUID != "src-1" {
+		t.Fatalf("suppressed = %+v, %v", suppressed, err)
+	}
+
+	active, _ := svc.ListSilences(ctx, SilenceStateActive)
+	pending, _ := svc.ListSilences(ctx, SilenceStatePending)
+	if len(active) != 1 || len(pending) != 1 {
+		t.Errorf("active = %d, pending = %d; want 1 and 1", len(active), len(pending))
+	}
+
+	if _, err := svc.ExpireSilence(ctx, window.UUID); err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if silence, _ := svc.SilenceAlert(ctx, "src-1", firing); silence != nil {
+		t.Errorf("expired silence still suppresses: %+v", silence)
+	}
+	if expired, _ := svc.ListSilences(ctx, SilenceStateExpired); len(expired) != 1 {
+		t.Errorf("expired = %d, want 1", len(expired))
+	}
+
+	if _, err := svc.GetSilence(ctx, "missing"); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("missing silence: err = %v", err)
+	}
+}
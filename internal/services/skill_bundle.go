@@ -0,0 +1,462 @@
+package services
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gopkg.in/yaml.v3"
+)
+
+// SkillBundleFormatVersion is the newest bundle layout this build reads and
+// writes. Version 2 added references/; bundles without references are
+// still written as version 1 so older installs can read them.
+const SkillBundleFormatVersion = 2
+
+// Bundle archive formats.
+const (
+	SkillBundleTarGz = "tar.gz"
+	SkillBundleZip   = "zip"
+)
+
+// Bundles are small text archives; anything larger is rejected rather than
+// unpacked.
+const (
+	maxSkillBundleBytes     = 5 << 20
+	maxSkillBundleFileBytes = 1 << 20
+)
+
+// Skill bundle errors, mapped to 4xx responses by the handlers.
+var (
+	ErrInvalidSkillBundle = errors.New("invalid skill bundle")
+	ErrSkillExists        = errors.New("skill already exists")
+)
+
+// SkillBundleManifest is skill.json at the root of a bundle.
+type SkillBundleManifest struct {
+	FormatVersion int      `json:"format_version"`
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	Description   string   `json:"description"`
+	Category      string   `json:"category,omitempty"`
+	ToolTypes     []string `json:"tool_types,omitempty"` // tool types the skill's tools need, e.g. "zabbix"
+}
+
+// SkillBundle is a portable skill: a gzipped tar or zip holding skill.json,
+// SKILL.md, any scripts/ files and the context files the prompt references
+// under references/.
+type SkillBundle struct {
+	Manifest   SkillBundleManifest
+	Prompt     string            // SKILL.md body, frontmatter removed
+	Scripts    map[string]string // filename -> content
+	References map[string][]byte // context filename -> content
+}
+
+// SkillInstallResult describes an installed bundle.
+type SkillInstallResult struct {
+	Skill   *database.Skill `json:"skill"`
+	Version string          `json:"version"`
+	// MissingToolTypes lists required tool types this instance does not
+	// have; the skill installs, but its tools must be added by hand.
+	MissingToolTypes []string `json:"missing_tool_types,omitempty"`
+	// ConflictingReferences lists bundled context files that already exist
+	// here with different content; the existing files are kept.
+	ConflictingReferences []string `json:"conflicting_references,omitempty"`
+}
+
+// ReadSkillBundle parses and validates a bundle archive, gzipped tar or zip.
+func ReadSkillBundle(r io.Reader) (*SkillBundle, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSkillBundleBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSkillBundle, err)
+	}
+	if len(data) > maxSkillBundleBytes {
+		return nil, fmt.Errorf("%w: archive is larger than %d bytes", ErrInvalidSkillBundle, maxSkillBundleBytes)
+	}
+
+	bundle := &SkillBundle{Scripts: make(map[string]string), References: make(map[string][]byte)}
+	var manifest, skillMd []byte
+	add := func(name string, content []byte) error {
+		switch {
+		case name == "skill.json":
+			manifest = content
+		case name == "SKILL.md":
+			skillMd = content
+		case path.Dir(name) == "scripts":
+			filename := path.Base(name)
+			if err := ValidateScriptFilename(filename); err != nil {
+				return err
+			}
+			bundle.Scripts[filename] = string(content)
+		case path.Dir(name) == "references":
+			filename := path.Base(name)
+			if err := validateReferenceFilename(filename); err != nil {
+				return err
+			}
+			bundle.References[filename] = content
+		default:
+			return fmt.Errorf("unexpected file %s", name)
+		}
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		err = readZipBundle(data, add)
+	} else {
+		err = readTarGzBundle(data, add)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSkillBundle, err)
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: skill.json is missing", ErrInvalidSkillBundle)
+	}
+	if err := json.Unmarshal(manifest, &bundle.Manifest); err != nil {
+		return nil, fmt.Errorf("%w: skill.json: %v", ErrInvalidSkillBundle, err)
+	}
+	if v := bundle.Manifest.FormatVersion; v < 1 || v > SkillBundleFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format_version %d", ErrInvalidSkillBundle, v)
+	}
+	if err := ValidateSkillName(bundle.Manifest.Name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSkillBundle, err)
+	}
+	if skillMd == nil {
+		return nil, fmt.Errorf("%w: SKILL.md is missing", ErrInvalidSkillBundle)
+	}
+	bundle.Prompt = skillMdBody(string(skillMd))
+	return bundle, nil
+}
+
+func readTarGzBundle(data []byte, add func(name string, content []byte) error) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("not a gzip or zip archive")
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%s is not a regular file", hdr.Name)
+		}
+		content, err := readBundleFile(hdr.Name, tr)
+		if err != nil {
+			return err
+		}
+		if err := add(path.Clean(strings.TrimPrefix(hdr.Name, "./")), content); err != nil {
+			return err
+		}
+	}
+}
+
+func readZipBundle(data []byte, add func(name string, content []byte) error) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := readBundleFile(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := add(path.Clean(strings.TrimPrefix(f.Name, "./")), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBundleFile reads one archive entry, enforcing the per-file limit
+// whatever size the header claims.
+func readBundleFile(name string, r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxSkillBundleFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxSkillBundleFileBytes {
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	return content, nil
+}
+
+// validateReferenceFilename applies the context file naming rules to a
+// bundled reference.
+func validateReferenceFilename(filename string) error {
+	if len(filename) > 255 || !FilenamePattern.MatchString(filename) {
+		return fmt.Errorf("invalid reference filename %q", filename)
+	}
+	ext := strings.ToLower(path.Ext(filename))
+	if !slices.Contains(AllowedExtensions, ext) {
+		return fmt.Errorf("reference %s: file type %q not allowed", filename, ext)
+	}
+	return nil
+}
+
+type bundleFile struct {
+	name    string
+	content []byte
+}
+
+// WriteSkillBundle writes bundle as a SkillBundleTarGz or SkillBundleZip
+// archive. Manifest.FormatVersion is set from the bundle's contents.
+func WriteSkillBundle(w io.Writer, bundle *SkillBundle, format string) error {
+	manifest := bundle.Manifest
+	manifest.FormatVersion = 1
+	if len(bundle.References) > 0 {
+		manifest.FormatVersion = 2
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	frontmatter, err := yaml.Marshal(SkillFrontmatter{Name: manifest.Name, Description: manifest.Description})
+	if err != nil {
+		return err
+	}
+
+	files := []bundleFile{
+		{"skill.json", append(manifestJSON, '\n')},
+		{"SKILL.md", []byte("---\n" + string(frontmatter) + "---\n\n" + bundle.Prompt + "\n")},
+	}
+	for _, filename := range slices.Sorted(maps.Keys(bundle.Scripts)) {
+		files = append(files, bundleFile{"scripts/" + filename, []byte(bundle.Scripts[filename])})
+	}
+	for _, filename := range slices.Sorted(maps.Keys(bundle.References)) {
+		files = append(files, bundleFile{"references/" + filename, bundle.References[filename]})
+	}
+
+	switch format {
+	case SkillBundleZip:
+		zw := zip.NewWriter(w)
+		for _, f := range files {
+			fw, err := zw.Create(f.name)
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(f.content); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	case SkillBundleTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		for _, f := range files {
+			mode := int64(0644)
+			if strings.HasPrefix(f.name, "scripts/") {
+				mode = 0755
+			}
+			if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: mode, Size: int64(len(f.content)), Typeflag: tar.TypeReg}); err != nil {
+				return err
+			}
+			if _, err := tw.Write(f.content); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	default:
+		return fmt.Errorf("unsupported bundle format %q", format)
+	}
+}
+
+// ExportSkillBundle packages a skill: its prompt, scripts, the context files
+// its prompt references, and the tool types of its assigned tools. version
+// is recorded in the manifest.
+func (s *SkillService) ExportSkillBundle(name, version string) (*SkillBundle, error) {
+	var skill database.Skill
+	if err := s.db.Preload("Tools.ToolType").Where("name = ?", name).First(&skill).Error; err != nil {
+		return nil, err
+	}
+	prompt, err := s.GetSkillPrompt(name)
+	if err != nil {
+		return nil, err
+	}
+
+	toolTypes := []string{}
+	for _, tool := range skill.Tools {
+		if tool.ToolType.Name != "" && !slices.Contains(toolTypes, tool.ToolType.Name) {
+			toolTypes = append(toolTypes, tool.ToolType.Name)
+		}
+	}
+	sort.Strings(toolTypes)
+
+	bundle := &SkillBundle{
+		Manifest: SkillBundleManifest{
+			Name:        skill.Name,
+			Version:     version,
+			Description: skill.Description,
+			Category:    skill.Category,
+			ToolTypes:   toolTypes,
+		},
+		Prompt:     prompt,
+		Scripts:    make(map[string]string),
+		References: make(map[string][]byte),
+	}
+
+	filenames, err := s.ListSkillScripts(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		script, err := s.GetSkillScript(name, filename)
+		if err != nil {
+			// Subdirectories and names the bundle format cannot carry.
+			slog.Warn("skipping skill script in export", "skill", name, "script", filename, "err", err)
+			continue
+		}
+		if script.Size > maxSkillBundleFileBytes {
+			return nil, fmt.Errorf("%w: script %s is larger than %d bytes", ErrInvalidSkillBundle, filename, maxSkillBundleFileBytes)
+		}
+		bundle.Scripts[filename] = script.Content
+	}
+
+	if s.contextService != nil {
+		for _, filename := range s.contextService.ParseReferences(prompt) {
+			content, err := os.ReadFile(s.contextService.GetFilePath(filename))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(content) > maxSkillBundleFileBytes {
+				return nil, fmt.Errorf("%w: reference %s is larger than %d bytes", ErrInvalidSkillBundle, filename, maxSkillBundleFileBytes)
+			}
+			bundle.References[filename] = content
+		}
+	}
+	return bundle, nil
+}
+
+// InstallSkillBundle creates the bundle's skill with its prompt and scripts.
+// An existing skill of the same name is left alone (ErrSkillExists).
+func (s *SkillService) InstallSkillBundle(bundle *SkillBundle) (*SkillInstallResult, error) {
+	m := bundle.Manifest
+	var count int64
+	if err := s.db.Model(&database.Skill{}).Where("name = ?", m.Name).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check skill: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, m.Name)
+	}
+
+	// References go first: CreateSkill links the ones its prompt names.
+	conflicts, err := s.installBundleReferences(m.Name, bundle.References)
+	if err != nil {
+		return nil, err
+	}
+
+	skill, err := s.CreateSkill(m.Name, m.Description, m.Category, bundle.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	filenames := make([]string, 0, len(bundle.Scripts))
+	for filename := range bundle.Scripts {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		if err := s.UpdateSkillScript(m.Name, filename, bundle.Scripts[filename]); err != nil {
+			_ = s.DeleteSkill(m.Name)
+			return nil, fmt.Errorf("failed to install script %s: %w", filename, err)
+		}
+	}
+
+	missing, err := s.missingToolTypes(m.ToolTypes)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillInstallResult{Skill: skill, Version: m.Version, MissingToolTypes: missing, ConflictingReferences: conflicts}, nil
+}
+
+// installBundleReferences saves bundled context files that do not exist
+// yet. It returns the names that exist with different content, which are
+// left as they are.
+func (s *SkillService) installBundleReferences(skillName string, references map[string][]byte) ([]string, error) {
+	if len(references) == 0 {
+		return nil, nil
+	}
+	if s.contextService == nil {
+		return nil, errors.New("context files are not available")
+	}
+	var conflicts []string
+	for _, filename := range slices.Sorted(maps.Keys(references)) {
+		content := references[filename]
+		if s.contextService.FileExists(filename) {
+			existing, err := os.ReadFile(s.contextService.GetFilePath(filename))
+			if err != nil || !bytes.Equal(existing, content) {
+				conflicts = append(conflicts, filename)
+			}
+			continue
+		}
+		mimeType := mime.TypeByExtension(path.Ext(filename))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		if _, err := s.contextService.SaveFile(filename, filename, mimeType, "Imported with skill "+skillName,
+			int64(len(content)), bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to install reference %s: %w", filename, err)
+		}
+	}
+	return conflicts, nil
+}
+
+// missingToolTypes returns the names in required that are not registered
+// tool types.
+func (s *SkillService) missingToolTypes(required []string) ([]string, error) {
+	if len(required) == 0 {
+		return nil, nil
+	}
+	var known []string
+	if err := s.db.Model(&database.ToolType{}).Where("name IN ?", required).Pluck("name", &known).Error; err != nil {
+		return nil, fmt.Errorf("failed to check tool types: %w", err)
+	}
+	have := make(map[string]bool, len(known))
+	for _, name := range known {
+		have[name] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !have[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// newBundleTestSkillService is a skill service on its own database with
+// context files enabled.
+func newBundleTestSkillService(t *testing.T) (*SkillService, *gorm.DB) {
+	t.Helper()
+	db := setupSkillTestDB(t)
+	if err := db.AutoMigrate(&database.ContextFile{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return newTestSkillService(t, db), db
+}
+
+func TestSkillBundle_ExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{SkillBundleTarGz, SkillBundleZip} {
+		t.Run(format, func(t *testing.T) {
+			src, srcDB := newBundleTestSkillService(t)
+			runbook := "# Replica lag\nPromote the standby.\n"
+			if _, err := src.contextService.SaveFile("runbook.md", "runbook.md", "text/markdown", "", int64(len(runbook)), strings.NewReader(runbook)); err != nil {
+				t.Fatal(err)
+			}
+			skill, err := src.CreateSkill("pg-replica", "Replica lag triage", "database", "Check replay lag first. See [[runbook.md]].")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := src.UpdateSkillScript("pg-replica", "lag.sh", "#!/bin/sh\necho lag\n"); err != nil {
+				t.Fatal(err)
+			}
+			toolType := database.ToolType{Name: "ssh"}
+			srcDB.Create(&toolType)
+			tool := database.ToolInstance{ToolTypeID: toolType.ID, Name: "bastion", Enabled: true}
+			srcDB.Create(&tool)
+			if err := srcDB.Model(skill).Association("Tools").Append(&tool); err != nil {
+				t.Fatal(err)
+			}
+
+			bundle, err := src.ExportSkillBundle("pg-replica", "2.1.0")
+			if err != nil {
+				t.Fatalf("ExportSkillBundle: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteSkillBundle(&buf, bundle, format); err != nil {
+				t.Fatalf("WriteSkillBundle: %v", err)
+			}
+
+			read, err := ReadSkillBundle(&buf)
+			if err != nil {
+				t.Fatalf("ReadSkillBundle: %v", err)
+			}
+			m := read.Manifest
+			if m.FormatVersion != 2 || m.Version != "2.1.0" || m.Category != "database" || !slices.Equal(m.ToolTypes, []string{"ssh"}) {
+				t.Errorf("manifest = %+v", m)
+			}
+
+			dst, _ := newBundleTestSkillService(t)
+			result, err := dst.InstallSkillBundle(read)
+			if err != nil {
+				t.Fatalf("InstallSkillBundle: %v", err)
+			}
+			if !slices.Equal(result.MissingToolTypes, []string{"ssh"}) {
+				t.Errorf("missing tool types = %v", result.MissingToolTypes)
+			}
+			prompt, _ := dst.GetSkillPrompt("pg-replica")
+			if !strings.Contains(prompt, "Check replay lag first.") {
+				t.Errorf("prompt = %q", prompt)
+			}
+			script, err := dst.GetSkillScript("pg-replica", "lag.sh")
+			if err != nil || script.Content != "#!/bin/sh\necho lag\n" {
+				t.Errorf("script = %+v, %v", script, err)
+			}
+			got, err := os.ReadFile(dst.contextService.GetFilePath("runbook.md"))
+			if err != nil || string(got) != runbook {
+				t.Errorf("reference = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSkillBundle_KeepsConflictingReferences(t *testing.T) {
+	svc, _ := newBundleTestSkillService(t)
+	local := "local notes"
+	if _, err := svc.contextService.SaveFile("runbook.md", "runbook.md", "text/markdown", "", int64(len(local)), strings.NewReader(local)); err != nil {
+		t.Fatal(err)
+	}
+	bundle := &SkillBundle{
+		Manifest:   SkillBundleManifest{Name: "pg-replica", Version: "1.0.0"},
+		Prompt:     "See [[runbook.md]].",
+		References: map[string][]byte{"runbook.md": []byte("bundled notes")},
+	}
+	result, err := svc.InstallSkillBundle(bundle)
+	if err != nil {
+		t.Fatalf("InstallSkillBundle: %v", err)
+	}
+	if !slices.Equal(result.ConflictingReferences, []string{"runbook.md"}) {
+		t.Errorf("conflicting references = %v", result.ConflictingReferences)
+	}
+	if got, _ := os.ReadFile(svc.contextService.GetFilePath("runbook.md")); string(got) != local {
+		t.Errorf("reference = %q, want the local file kept", got)
+	}
+}
+
+func TestWriteSkillBundle_WithoutReferencesIsFormatVersion1(t *testing.T) {
+	var buf bytes.Buffer
+	bundle := &SkillBundle{Manifest: SkillBundleManifest{Name: "disk-triage", Version: "1.0.0"}, Prompt: "Check df."}
+	if err := WriteSkillBundle(&buf, bundle, SkillBundleTarGz); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSkillBundle(&buf)
+	if err != nil {
+		t.Fatalf("ReadSkillBundle: %v", err)
+	}
+	if read.Manifest.FormatVersion != 1 || read.Prompt != "Check df." {
+		t.Errorf("bundle = %+v", read)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+)
+
+// ErrSystemSkillDryRun is returned by PrepareSkillDryRun for a system skill,
+// which only runs as the root of an investigation.
+var ErrSystemSkillDryRun = errors.New("system skills cannot be tested on their own")
+
+// skillDryRunPrefix marks the run IDs (and workspace names) of skill dry
+// runs, so they are never mistaken for incident UUIDs.
+const skillDryRunPrefix = "skill-test-"
+
+// SkillDryRun is the ephemeral workspace a skill is tested in. It has no
+// incident record: the agent worker only needs the workspace, and ID stands
+// in for the incident ID of the run.
+type SkillDryRun struct {
+	ID            string
+	Dir           string
+	Skill         string
+	ToolAllowlist []ToolAllowlistEntry
+}
+
+// PrepareSkillDryRun creates a workspace for testing the named skill on its
+// own: the incident-manager AGENTS.md, with only the skill and its tools
+// made available. Disabled skills can be tested; system skills cannot.
+// Returns an error wrapping gorm.ErrRecordNotFound for an unknown skill.
+func (s *SkillService) PrepareSkillDryRun(name string) (*SkillDryRun, error) {
+	if isSystemSkillName(name) {
+		return nil, ErrSystemSkillDryRun
+	}
+	tools, err := s.skillToolAllowlist(name)
+	if err != nil {
+		return nil, err
+	}
+
+	id := skillDryRunPrefix + uuid.New().String()
+	run := &SkillDryRun{ID: id, Dir: filepath.Join(s.incidentsDir, id), Skill: name, ToolAllowlist: tools}
+	// 0777 like incident workspaces, so the agent worker (UID 1001) can
+	// write to it.
+	if err := os.MkdirAll(run.Dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create dry run workspace: %w", err)
+	}
+	if err := os.Chmod(run.Dir, 0777); err != nil {
+		slog.Error("failed to chmod dry run workspace", "dir", run.Dir, "err", err)
+	}
+	if err := s.generateAgentsMd(filepath.Join(run.Dir, "AGENTS.md"), "incident-manager", id); err != nil {
+		os.RemoveAll(run.Dir)
+		return nil, err
+	}
+	return run, nil
+}
+
+// Cleanup removes the dry run's workspace. One left behind (e.g. by a
+// restart mid-run) is removed by retention as an orphaned directory.
+func (r *SkillDryRun) Cleanup() error {
+	return os.RemoveAll(r.Dir)
+}
+
+// skillToolAllowlist returns the enabled tools of the named skill.
+func (s *SkillService) skillToolAllowlist(name string) ([]ToolAllowlistEntry, error) {
+	var skill database.Skill
+	if err := s.db.Preload("Tools.ToolType").Where("name = ?", name).First(&skill).Error; err != nil {
+		return nil, fmt.Errorf("skill not found: %w", err)
+	}
+	entries := make([]ToolAllowlistEntry, 0, len(skill.Tools))
+	for _, tool := range skill.Tools {
+		if !tool.Enabled {
+			continue
+		}
+		entries = append(entries, ToolAllowlistEntry{
+			InstanceID:  tool.ID,
+			LogicalName: tool.LogicalName,
+			ToolType:    tool.ToolType.Name,
+		})
+	}
+	return entries, nil
+}
+
+// SkillDryRunInstructions is appended to the alert prompt of a dry run.
+func SkillDryRunInstructions(skillName string) string {
+	return fmt.Sprintf("This is a dry run of the %q skill against a synthetic alert, started by the skill's author. "+
+		"Investigate it with that skill as you would a real alert. No incident exists for it, so do not "+
+		"record memories; nothing you write will be posted anywhere.", skillName)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+func TestPrepareSkillDryRun(t *testing.T) {
+	db := setupSkillTestDB(t)
+	svc := newTestSkillService(t, db)
+
+	toolType := database.ToolType{Name: "ssh"}
+	db.Create(&toolType)
+	enabled := database.ToolInstance{ToolTypeID: toolType.ID, Name: "prod ssh", LogicalName: "prod-ssh", Enabled: true}
+	disabled := database.ToolInstance{ToolTypeID: toolType.ID, Name: "old ssh", LogicalName: "old-ssh"}
+	db.Create(&enabled)
+	db.Create(&disabled)
+	db.Model(&disabled).Update("enabled", false)
+	skill := database.Skill{Name: "disk-usage", Enabled: false}
+	db.Create(&skill)
+	db.Model(&skill).Association("Tools").Append(&enabled, &disabled)
+
+	run, err := svc.PrepareSkillDryRun("disk-usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(run.ID, skillDryRunPrefix) || filepath.Dir(run.Dir) != svc.incidentsDir {
+		t.Errorf("run = %+v", run)
+	}
+	if len(run.ToolAllowlist) != 1 || run.ToolAllowlist[0].LogicalName != "prod-ssh" || run.ToolAllowlist[0].ToolType != "ssh" {
+		t.Errorf("allowlist = %+v, want only the enabled tool", run.ToolAllowlist)
+	}
+	if _, err := os.Stat(filepath.Join(run.Dir, "AGENTS.md")); err != nil {
+		t.Errorf("AGENTS.md: %v", err)
+	}
+	var incidents int64
+	db.Model(&database.Incident{}).Count(&incidents)
+	if incidents != 0 {
+		t.Errorf("incidents = %d, want none", incidents)
+	}
+
+	if err := run.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(run.Dir); !os.IsNotExist(err) {
+		t.Errorf("workspace still exists after cleanup: %v", err)
+	}
+
+	if _, err := svc.PrepareSkillDryRun("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("unknown skill err = %v, want ErrRecordNotFound", err)
+	}
+	if _, err := svc.PrepareSkillDryRun("incident-manager"); !errors.Is(err, ErrSystemSkillDryRun) {
+		t.Errorf("system skill err = %v, want ErrSystemSkillDryRun", err)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/akmatori/akmatori/internal/database"
 	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
 )
 
 // EnsureSkillDirectories creates the skill's directory structure
@@ -37,7 +39,8 @@ func (s *SkillService) EnsureSkillScriptsDir(skillName string) error {
 }
 
 // SyncSkillAssets creates symlinks in the skill's assets directory for [[filename]] references
-// Symlinks point to /akmatori/context/{filename} which is shared between API and agent containers
+// Symlinks are relative (../../../context/{filename}) so they resolve wherever the data
+// directory is mounted, in the API and agent containers alike
 // It removes stale symlinks and adds new ones based on the current prompt
 func (s *SkillService) SyncSkillAssets(skillName string, prompt string) error {
 	assetsDir := s.GetSkillAssetsDir(skillName)
@@ -83,9 +86,12 @@ func (s *SkillService) SyncSkillAssets(skillName string, prompt string) error {
 			os.Remove(dstPath)
 		}
 
-		// Create symlink pointing to /akmatori/context/{filename}
-		// This path is shared between API and agent containers
-		symlinkTarget := filepath.Join("/akmatori/context", filename)
+		// Link relative to the assets directory: the data directory may be
+		// mounted at a different path in the agent container
+		symlinkTarget, err := filepath.Rel(assetsDir, srcPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve symlink for %s: %w", filename, err)
+		}
 		if err := os.Symlink(symlinkTarget, dstPath); err != nil {
 			return fmt.Errorf("failed to create symlink for %s: %w", filename, err)
 		}
@@ -260,53 +266,76 @@ func (s *SkillService) SyncSkillsFromFilesystem() error {
 	}
 
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || isSystemSkillName(e.Name()) {
 			continue
 		}
-		name := e.Name()
-
-		// Skip if skill already exists in database
-		var count int64
-		s.db.Model(&database.Skill{}).Where("name = ?", name).Count(&count)
-		if count > 0 {
-			continue
+		if _, err := s.SyncSkillFromFilesystem(e.Name()); err != nil {
+			slog.Warn("failed to sync skill from filesystem", "skill", e.Name(), "err", err)
 		}
+	}
 
-		// Read SKILL.md to get metadata
-		skillPath := filepath.Join(s.skillsDir, name, "SKILL.md")
-		content, err := os.ReadFile(skillPath)
-		if err != nil {
-			slog.Warn("no SKILL.md for skill", "skill", name, "err", err)
-			continue
-		}
+	return nil
+}
 
-		// Parse frontmatter
-		parts := strings.SplitN(string(content), "---", 3)
-		if len(parts) < 3 {
-			slog.Warn("invalid SKILL.md format for skill", "skill", name)
-			continue
-		}
+// SyncSkillFromFilesystem creates the database record of the skill in the
+// named directory from its SKILL.md, or updates the description of an
+// existing one. The directory name must be a valid skill name and match the
+// frontmatter name when one is set. It reports whether the record changed.
+func (s *SkillService) SyncSkillFromFilesystem(name string) (bool, error) {
+	if err := ValidateSkillName(name); err != nil {
+		return false, err
+	}
+	if isSystemSkillName(name) {
+		return false, fmt.Errorf("%s is a system skill", name)
+	}
 
-		var frontmatter SkillFrontmatter
-		if err := yaml.Unmarshal([]byte(parts[1]), &frontmatter); err != nil {
-			slog.Warn("failed to parse frontmatter for skill", "skill", name, "err", err)
-			continue
-		}
+	content, err := os.ReadFile(filepath.Join(s.skillsDir, name, "SKILL.md"))
+	if err != nil {
+		return false, err
+	}
+	parts := strings.SplitN(string(content), "---", 3)
+	if len(parts) < 3 {
+		return false, fmt.Errorf("invalid SKILL.md format: missing frontmatter")
+	}
+	var frontmatter SkillFrontmatter
+	if err := yaml.Unmarshal([]byte(parts[1]), &frontmatter); err != nil {
+		return false, fmt.Errorf("failed to parse SKILL.md frontmatter: %w", err)
+	}
+	if frontmatter.Name != "" && frontmatter.Name != name {
+		return false, fmt.Errorf("SKILL.md name %q does not match directory %q", frontmatter.Name, name)
+	}
 
-		// Create database record
-		skill := &database.Skill{
+	var skill database.Skill
+	err = s.db.Where("name = ?", name).First(&skill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		skill = database.Skill{
 			Name:        name,
 			Description: frontmatter.Description,
 			Enabled:     true,
 		}
-		if err := s.db.Create(skill).Error; err != nil {
-			slog.Warn("failed to sync skill from filesystem", "skill", name, "err", err)
-		} else {
-			slog.Info("synced skill from filesystem", "skill", name)
+		if err := s.db.Create(&skill).Error; err != nil {
+			return false, fmt.Errorf("failed to create skill record: %w", err)
 		}
+		slog.Info("synced skill from filesystem", "skill", name)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if skill.Description == frontmatter.Description {
+		return false, nil
 	}
+	if err := s.db.Model(&skill).Update("description", frontmatter.Description).Error; err != nil {
+		return false, fmt.Errorf("failed to update skill record: %w", err)
+	}
+	slog.Info("synced skill description from filesystem", "skill", name)
+	return true, nil
+}
 
-	return nil
+// isSystemSkillName reports whether name is one of the system skills whose
+// prompts are hardcoded rather than read from SKILL.md.
+func isSystemSkillName(name string) bool {
+	return name == "incident-manager" || name == "cron-agent" || name == "proposal-editor"
 }
 
 // RegenerateSkillMd rewrites a single skill's SKILL.md from the current
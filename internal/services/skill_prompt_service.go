@@ -41,17 +41,22 @@ func (s *SkillService) GetSkillPrompt(name string) (string, error) {
 		return "", fmt.Errorf("failed to read SKILL.md: %w", err)
 	}
 
-	// Parse and extract body (after frontmatter)
-	parts := strings.SplitN(string(content), "---", 3)
+	return skillMdBody(string(content)), nil
+}
+
+// skillMdBody returns the user-defined prompt of a SKILL.md document: the
+// body after its frontmatter, without auto-generated sections.
+func skillMdBody(content string) string {
+	parts := strings.SplitN(content, "---", 3)
 	if len(parts) >= 3 {
 		body := strings.TrimLeft(parts[2], " \t\n\r")
 		// Strip auto-generated resource instructions section if present
 		body = stripAutoGeneratedSections(body)
 		// Remove the single trailing newline added by generateSkillMd file format
 		body = strings.TrimSuffix(body, "\n")
-		return body, nil
+		return body
 	}
-	return string(content), nil
+	return content
 }
 
 // stripAutoGeneratedSections removes auto-generated sections from the skill body
@@ -408,7 +413,9 @@ For CPU core count use ` + "`nproc`" + ` or ` + "`lscpu`" + ` (not /proc/cpuinfo
 gateway_call("ssh.execute_command", {"command": "uptime"}, "%s")
 gateway_call("ssh.execute_command", {"command": "df -h", "servers": ["hostname"]}, "%s")
 gateway_call("ssh.test_connectivity", {}, "%s")
-gateway_call("ssh.get_server_info", {}, "%s")`, logicalName, logicalName, logicalName, logicalName)
+gateway_call("ssh.get_server_info", {}, "%s")
+gateway_call("ssh.read_file", {"server": "hostname", "path": "/var/log/syslog", "tail": true}, "%s")
+gateway_call("ssh.list_dir", {"server": "hostname", "path": "/etc/nginx"}, "%s")`, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName)
 		}
 
 		var adhocExample string
@@ -417,15 +424,21 @@ gateway_call("ssh.get_server_info", {}, "%s")`, logicalName, logicalName, logica
 # Ad-hoc: connect to any server by hostname/FQDN/IP
 gateway_call("ssh.execute_command", {"command": "uptime", "servers": ["<hostname-or-ip>"]}, "%s")
 gateway_call("ssh.test_connectivity", {"servers": ["<server1>", "<server2>"]}, "%s")
-gateway_call("ssh.get_server_info", {"servers": ["<hostname-or-ip>"]}, "%s")`, logicalName, logicalName, logicalName)
+gateway_call("ssh.get_server_info", {"servers": ["<hostname-or-ip>"]}, "%s")
+gateway_call("ssh.read_file", {"server": "<hostname-or-ip>", "path": "/etc/hosts"}, "%s")`, logicalName, logicalName, logicalName, logicalName)
 		}
 
 		return fmt.Sprintf(`
 **Parameters:**
-- `+"`execute_command`"+`: command* | servers
+- `+"`execute_command`"+`: command* | servers, timeout_seconds (max 600, for long-running commands)
 - `+"`test_connectivity`"+`: servers
 - `+"`get_server_info`"+`: servers
+- `+"`read_file`"+`: server*, path* | offset, max_bytes, tail
+- `+"`write_file`"+` **(write)**: server*, path*, content* | mode, append — only on hosts that allow write commands
+- `+"`list_dir`"+`: server* | path
 (* = required)
+Use `+"`read_file`"+` instead of `+"`cat`"+` for config files and logs; it returns 256KB per call, so page with `+"`offset`"+` or read the end of a log with `+"`tail`"+`.
+Command output is capped per server; check `+"`stdout_truncated`"+` and narrow the command (grep, head, tail) when it is set. A timed-out command still returns the output it produced.
 
 Usage (via gateway_call):
 `+"```"+`
@@ -474,6 +487,54 @@ gateway_call("victoria_metrics.series", {"match": "up"}, "%s")
 gateway_call("victoria_metrics.api_request", {"path": "/api/v1/status/tsdb"}, "%s")
 `+"```"+`
 `, logicalName, logicalName, logicalName, logicalName, logicalName)
+	case "prometheus":
+		return fmt.Sprintf(`
+**Parameters:**
+- `+"`query`"+`: query* | time, timeout
+- `+"`query_range`"+`: query*, start*, end*, step* | timeout
+- `+"`series`"+`: match* (selector or list) | start, end, limit
+- `+"`label_values`"+`: label_name* | match, start, end, limit
+(* = required)
+
+Usage (via gateway_call):
+`+"```"+`
+gateway_call("prometheus.query", {"query": "up == 0"}, "%s")
+gateway_call("prometheus.query_range", {"query": "sum by (code) (rate(http_requests_total[5m]))", "start": "2024-01-15T10:00:00Z", "end": "2024-01-15T12:00:00Z", "step": "1m"}, "%s")
+gateway_call("prometheus.series", {"match": ["up{job=\"api\"}"]}, "%s")
+gateway_call("prometheus.label_values", {"label_name": "job"}, "%s")
+`+"```"+`
+`, logicalName, logicalName, logicalName, logicalName)
+	case "log_search":
+		return fmt.Sprintf(`
+**Parameters:**
+- `+"`loki_query`"+`: query* (LogQL) | start, end, limit, direction
+- `+"`loki_labels`"+`: label_name, query, start, end
+- `+"`elasticsearch_search`"+`: query (DSL object or query string), index, start, end, limit
+(* = required; times are RFC3339, Unix seconds, or a lookback like "30m"; start defaults to 1h before end)
+
+Each instance is backed by either Loki or Elasticsearch; call the functions for its backend. Results are capped per instance (result count and time range) and long lines are truncated, so narrow the query rather than widening the range.
+
+Usage (via gateway_call):
+`+"```"+`
+gateway_call("log_search.loki_query", {"query": "{app=\"api\"} |= \"error\"", "start": "30m"}, "%s")
+gateway_call("log_search.loki_labels", {"label_name": "app"}, "%s")
+gateway_call("log_search.elasticsearch_search", {"index": "logs-*", "query": "level:error AND service:api", "start": "1h"}, "%s")
+`+"```"+`
+`, logicalName, logicalName, logicalName)
+	case "http_check":
+		return fmt.Sprintf(`
+**Parameters:**
+- `+"`probe`"+`: url* | method (GET/HEAD/POST), body, content_type, headers, expect_status (list), expect_body_contains, expect_body_regex, max_latency_ms
+(* = required)
+
+Only URLs on the instance's allowlist can be probed, and redirects must stay on it. The result has `+"`ok`"+`, status, latency with a DNS/connect/TLS/first-byte breakdown, TLS certificate expiry, and each assertion's outcome; connection failures come back in `+"`error`"+` (dns/connect/timeout/tls) rather than failing the call. Probe from more than one angle (health endpoint and a real page) before concluding an endpoint is down.
+
+Usage (via gateway_call):
+`+"```"+`
+gateway_call("http_check.probe", {"url": "https://www.example.com/healthz", "expect_status": [200], "max_latency_ms": 2000}, "%s")
+gateway_call("http_check.probe", {"url": "https://api.example.com/v1/login", "method": "POST", "body": "{}", "expect_status": [400, 401]}, "%s")
+`+"```"+`
+`, logicalName, logicalName)
 	case "postgresql":
 		return fmt.Sprintf(`
 **Parameters:**
@@ -515,8 +576,9 @@ gateway_call("postgresql.get_database_stats", {}, "%s")
 - `+"`query_data_source`"+`: datasource_uid*, queries* | from, to
 - `+"`query_prometheus`"+`: datasource_uid*, expr* | start, end, step, instant, range, from, to
 - `+"`query_loki`"+`: datasource_uid*, expr* | limit, direction, start, end, from, to
-- `+"`create_annotation`"+` **(write)**: text* | dashboard_id, panel_id, tags, time, time_end
-- `+"`get_annotations`"+`: from, to, dashboard_id, panel_id, tags, limit, type
+- `+"`create_annotation`"+` **(write)**: text* | dashboard_id, dashboard_uid, panel_id, tags, time, time_end (tagged with the incident UUID)
+- `+"`get_annotations`"+`: from, to, dashboard_id, panel_id, tags, limit, type, incident_only
+- `+"`render_panel`"+`: dashboard_uid*, panel_id* | from, to, width, height, theme, include_image
 (* = required)
 **(write)** marks methods that mutate state — only call after confirming intent.
 
@@ -530,8 +592,9 @@ gateway_call("grafana.query_prometheus", {"datasource_uid": "prom-uid", "expr":
 gateway_call("grafana.query_loki", {"datasource_uid": "loki-uid", "expr": "{app=\"api\"} |= \"error\"", "limit": 100}, "%s")
 gateway_call("grafana.get_alert_rules", {}, "%s")
 gateway_call("grafana.get_alert_instances", {"active": true}, "%s")
+gateway_call("grafana.render_panel", {"dashboard_uid": "abc123", "panel_id": 4, "from": "now-6h"}, "%s")
 `+"```"+`
-`, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName)
+`, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName)
 	case "catchpoint":
 		return fmt.Sprintf(`
 **Parameters:**
@@ -573,12 +636,13 @@ gateway_call("catchpoint.acknowledge_alerts", {"alert_ids": "12345", "action": "
 - `+"`get_on_calls`"+`: schedule_ids, escalation_policy_ids, since, until
 - `+"`get_escalation_policies`"+`: query, limit, offset
 - `+"`list_recent_changes`"+`: since, until, limit, offset
-- `+"`acknowledge_incident`"+` **(write)**: incident_id*, requester_email*
-- `+"`resolve_incident`"+` **(write)**: incident_id*, requester_email*
-- `+"`reassign_incident`"+` **(write)**: incident_id*, requester_email*, assignee_ids* | escalation_policy_id
-- `+"`add_incident_note`"+` **(write)**: incident_id*, requester_email*, content*
+- `+"`acknowledge_incident`"+` **(write)**: incident_id*, requester_email
+- `+"`resolve_incident`"+` **(write)**: incident_id*, requester_email
+- `+"`reassign_incident`"+` **(write)**: incident_id*, requester_email, assignee_ids* | escalation_policy_id
+- `+"`add_incident_note`"+` **(write)**: incident_id*, requester_email, content*
 - `+"`send_event`"+` **(write)**: routing_key*, event_action* | dedup_key, summary, severity, source, component, group, class, custom_details — `+"`summary`"+` is required when `+"`event_action=\"trigger\"`"+`; `+"`dedup_key`"+` is required when `+"`event_action=\"acknowledge\"`"+` or `+"`\"resolve\"`"+`
 (* = required)
+`+"`requester_email`"+` defaults to the instance's configured From email.
 **(write)** marks methods that mutate state — only call after confirming intent.
 
 Usage (via gateway_call):
@@ -680,9 +744,11 @@ gateway_call("netbox.api_request", {"path": "dcim/power-feeds/", "query_params":
 - `+"`get_configmaps`"+`: namespace* | label_selector, field_selector, limit
 - `+"`get_ingresses`"+`: namespace* | label_selector, field_selector, limit
 - `+"`api_request`"+`: path* | params
+- `+"`describe_resource`"+`: kind*, name* | namespace (required for namespaced kinds) — the object plus events referencing it
+- `+"`restart_deployment`"+` **(write)**: namespace*, name*
 (* = required)
 
-All endpoints are read-only GET requests.
+All endpoints are read-only GET requests except **(write)** methods, which return an error unless `+"`k8s_allow_writes=true`"+` is set on the instance.
 
 Usage (via gateway_call):
 `+"```"+`
@@ -694,8 +760,9 @@ gateway_call("kubernetes.get_events", {"namespace": "default", "field_selector":
 gateway_call("kubernetes.get_deployments", {"namespace": "default"}, "%s")
 gateway_call("kubernetes.get_nodes", {}, "%s")
 gateway_call("kubernetes.get_node_detail", {"name": "node-1"}, "%s")
+gateway_call("kubernetes.describe_resource", {"kind": "deployment", "namespace": "default", "name": "api"}, "%s")
 `+"```"+`
-`, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName)
+`, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName, logicalName)
 	case "jira":
 		return fmt.Sprintf(`
 **Parameters:**
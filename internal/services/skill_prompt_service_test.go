@@ -566,7 +566,7 @@ func TestGenerateToolUsageExample_NewToolTypes(t *testing.T) {
 				"pagerduty.add_incident_note",
 				"pagerduty.send_event",
 			},
-			expectRequired: []string{"incident_id*", "requester_email*", "routing_key*", "event_action*"},
+			expectRequired: []string{"incident_id*", "content*", "routing_key*", "event_action*"},
 			expectLiteral: []string{
 				`"summary": "Disk usage > 90%"`,
 				"`acknowledge_incident` **(write)**",
@@ -574,6 +574,7 @@ func TestGenerateToolUsageExample_NewToolTypes(t *testing.T) {
 				"`reassign_incident` **(write)**",
 				"`add_incident_note` **(write)**",
 				"`send_event` **(write)**",
+				"defaults to the instance's configured From email",
 				"`summary` is required when `event_action=\"trigger\"`",
 				"`dedup_key` is required when `event_action=\"acknowledge\"`",
 			},
@@ -622,9 +623,10 @@ func TestGenerateToolUsageExample_NewToolTypes(t *testing.T) {
 				"kubernetes.get_deployments",
 				"kubernetes.get_nodes",
 				"kubernetes.get_node_detail",
+				"kubernetes.describe_resource",
 			},
-			expectRequired: []string{"namespace*", "name*", "path*"},
-			expectFooter:   "All endpoints are read-only GET requests.",
+			expectRequired: []string{"namespace*", "name*", "path*", "kind*"},
+			expectFooter:   "All endpoints are read-only GET requests except **(write)** methods",
 			expectLiteral: []string{
 				"`container` is required for multi-container pods",
 			},
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
 	"gorm.io/gorm"
@@ -16,15 +17,18 @@ import (
 // Skills use SKILL.md format with YAML frontmatter and user prompt body
 type SkillService struct {
 	db               *gorm.DB
-	dataDir          string // /akmatori - base data directory
-	incidentsDir     string // /akmatori/incidents - incident working directories
-	skillsDir        string // /akmatori/skills - skill definitions with SKILL.md
-	memoryDir        string // /akmatori/memory - cross-incident memory mirror
+	dataDir          string // base data directory (AKMATORI_DATA_DIR, /akmatori by default)
+	incidentsDir     string // <dataDir>/incidents - incident working directories
+	skillsDir        string // <dataDir>/skills - skill definitions with SKILL.md
+	memoryDir        string // <dataDir>/memory - cross-incident memory mirror
 	toolService      *ToolService
 	contextService   *ContextService
-	oneShotLLMCaller OneShotLLMCaller      // optional; nil = title generation falls back deterministically
-	memoryIngester   MemoryIngester        // optional; nil = post-investigation file ingest is a no-op
-	incidentMerger   IncidentMergeEvaluator // optional; nil = post-investigation merge pass is a no-op
+	oneShotLLMCaller OneShotLLMCaller         // optional; nil = title generation falls back deterministically
+	memoryIngester   MemoryIngester           // optional; nil = post-investigation file ingest is a no-op
+	incidentMerger   IncidentMergeEvaluator   // optional; nil = post-investigation merge pass is a no-op
+	eventPublisher   IncidentEventPublisher   // optional; nil = no live log/status streaming
+	progressLog      *progressLogThrottle     // optional; nil = every progress update is written
+	timeline         IncidentTimelineRecorder // optional; nil = attached alerts are not put on the timeline
 }
 
 // SetMemoryIngester wires the post-investigation memory file ingester that
@@ -63,6 +67,35 @@ func (s *SkillService) SetIncidentMerger(m IncidentMergeEvaluator) {
 	s.incidentMerger = m
 }
 
+// SetIncidentEventPublisher wires the live stream that receives full_log
+// deltas and status transitions as they are persisted. Optional — when unset,
+// clients fall back to polling the incident.
+func (s *SkillService) SetIncidentEventPublisher(p IncidentEventPublisher) {
+	s.eventPublisher = p
+}
+
+// SetIncidentTimeline wires the recorder that puts alerts attached to an
+// incident on its timeline. Optional — when unset, nothing is recorded.
+func (s *SkillService) SetIncidentTimeline(r IncidentTimelineRecorder) {
+	s.timeline = r
+}
+
+// SetProgressThrottle batches UpdateIncidentLog writes: a progress update is
+// persisted (and published) at most once per minInterval and only once the
+// log has grown by minDelta bytes, with the latest log flushed in the
+// background otherwise. A batch that only extends the stored log is appended
+// rather than rewriting it. Final status updates always store the complete
+// log.
+// Zero for both disables throttling.
+func (s *SkillService) SetProgressThrottle(minInterval time.Duration, minDelta int) {
+	if minInterval <= 0 && minDelta <= 0 {
+		s.progressLog = nil
+		return
+	}
+	s.progressLog = newProgressLogThrottle(minInterval, minDelta, s.writeIncidentLog)
+	s.progressLog.appendLog = s.appendIncidentLog
+}
+
 // IncidentMergeEvaluator represents the post-investigation merge check.
 // Narrow interface so SkillService can be tested without the full
 // IncidentMerger (and its LLM dependency).
@@ -192,6 +225,18 @@ func (s *SkillService) DeleteSkill(name string) error {
 		return fmt.Errorf("cannot delete system skill: %s", name)
 	}
 
+	// Drop alert-source, cron and routing-rule pins first; the join tables have no ON DELETE
+	// CASCADE.
+	if err := s.db.Where("skill_id = ?", skill.ID).Delete(&database.AlertSourceSkill{}).Error; err != nil {
+		return fmt.Errorf("failed to unpin skill from alert sources: %w", err)
+	}
+	if err := s.db.Where("skill_id = ?", skill.ID).Delete(&database.CronJobSkill{}).Error; err != nil {
+		return fmt.Errorf("failed to unpin skill from cron jobs: %w", err)
+	}
+	if err := s.db.Where("skill_id = ?", skill.ID).Delete(&database.RoutingRuleSkill{}).Error; err != nil {
+		return fmt.Errorf("failed to unpin skill from routing rules: %w", err)
+	}
+
 	// Delete from database
 	if err := s.db.Where("name = ?", name).Delete(&database.Skill{}).Error; err != nil {
 		return fmt.Errorf("failed to delete skill from database: %w", err)
@@ -240,6 +285,40 @@ func (s *SkillService) GetEnabledSkillNames() []string {
 	return names
 }
 
+// SourceSkillScope narrows an investigation to the skills an alert source is
+// pinned to, returning their names and the deduplicated allowlist of their
+// enabled tools. ok is false when the source pins no skills, or none of its
+// pins is still enabled, in which case callers use the global skill set.
+// instance.Skills must be preloaded with Tools.ToolType.
+func SourceSkillScope(instance *database.AlertSourceInstance) (names []string, allowlist []ToolAllowlistEntry, ok bool) {
+	if instance == nil {
+		return nil, nil, false
+	}
+	seen := make(map[uint]bool)
+	allowlist = make([]ToolAllowlistEntry, 0)
+	for _, sk := range instance.Skills {
+		if !sk.Enabled || sk.IsSystem {
+			continue
+		}
+		names = append(names, sk.Name)
+		for _, tool := range sk.Tools {
+			if !tool.Enabled || seen[tool.ID] {
+				continue
+			}
+			seen[tool.ID] = true
+			allowlist = append(allowlist, ToolAllowlistEntry{
+				InstanceID:  tool.ID,
+				LogicalName: tool.LogicalName,
+				ToolType:    tool.ToolType.Name,
+			})
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil, false
+	}
+	return names, allowlist, true
+}
+
 // ToolAllowlistEntry represents one authorized tool instance for an incident.
 type ToolAllowlistEntry struct {
 	InstanceID  uint   `json:"instance_id"`
@@ -24,7 +24,7 @@ func setupSkillTestDB(t *testing.T) *gorm.DB {
 		&database.ToolType{},
 		&database.ToolInstance{},
 		&database.SkillTool{},
-		&database.Incident{},
+		&database.Incident{}, &database.IncidentLogChunk{},
 		&database.LLMSettings{},
 	)
 	if err != nil {
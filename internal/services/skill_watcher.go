@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// skillWatchDebounce is how long a skill's files must be quiet before the
+// skill is synced. Editors and copies write SKILL.md in several steps.
+const skillWatchDebounce = 500 * time.Millisecond
+
+// SkillWatcher keeps the skills table in step with SKILL.md files edited on
+// the data volume, so new and changed skills don't need a manual
+// POST /api/skills/sync.
+type SkillWatcher struct {
+	skills   *SkillService
+	debounce time.Duration
+}
+
+// NewSkillWatcher creates a watcher for the skills directory of skills.
+func NewSkillWatcher(skills *SkillService) *SkillWatcher {
+	return &SkillWatcher{skills: skills, debounce: skillWatchDebounce}
+}
+
+// Watch syncs every skill whose SKILL.md is created or written, once its
+// directory has been quiet for the debounce period, until ctx is done. It
+// syncs the whole directory first to pick up changes made while nothing was
+// watching. Removed skills are left in the database.
+func (w *SkillWatcher) Watch(ctx context.Context) {
+	root := w.skills.skillsDir
+	if err := os.MkdirAll(root, 0755); err != nil {
+		slog.Warn("skills watcher disabled", "err", err)
+		return
+	}
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("skills watcher disabled", "err", err)
+		return
+	}
+	defer fw.Close()
+
+	// fsnotify is not recursive: watch the root for new skill directories
+	// and each skill directory for its SKILL.md.
+	if err := fw.Add(root); err != nil {
+		slog.Warn("skills watcher disabled", "dir", root, "err", err)
+		return
+	}
+	if entries, err := os.ReadDir(root); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				w.addSkillDir(fw, filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	if err := w.skills.SyncSkillsFromFilesystem(); err != nil {
+		slog.Warn("failed to sync skills from filesystem", "err", err)
+	}
+	slog.Info("watching skills directory", "dir", root)
+
+	pending := map[string]struct{}{}
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if name := w.changedSkill(fw, root, ev); name != "" {
+				pending[name] = struct{}{}
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("skills watcher error", "err", err)
+		case <-timer.C:
+			for name := range pending {
+				w.sync(name)
+			}
+			clear(pending)
+		}
+	}
+}
+
+// changedSkill returns the skill an event may have changed, or "". A new
+// skill directory counts as a change because its SKILL.md can be written
+// before the directory is watched.
+func (w *SkillWatcher) changedSkill(fw *fsnotify.Watcher, root string, ev fsnotify.Event) string {
+	if !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Write) {
+		return ""
+	}
+	rel, err := filepath.Rel(root, ev.Name)
+	if err != nil {
+		return ""
+	}
+	name, file, nested := strings.Cut(filepath.ToSlash(rel), "/")
+	if isSystemSkillName(name) || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	if !nested {
+		if info, err := os.Stat(ev.Name); err != nil || !info.IsDir() {
+			return ""
+		}
+		w.addSkillDir(fw, ev.Name)
+		return name
+	}
+	if file != "SKILL.md" {
+		return ""
+	}
+	return name
+}
+
+func (w *SkillWatcher) addSkillDir(fw *fsnotify.Watcher, dir string) {
+	if err := fw.Add(dir); err != nil {
+		slog.Warn("failed to watch skill directory", "dir", dir, "err", err)
+	}
+}
+
+func (w *SkillWatcher) sync(name string) {
+	if _, err := w.skills.SyncSkillFromFilesystem(name); err != nil {
+		if os.IsNotExist(err) {
+			// A directory without SKILL.md yet, or one being removed.
+			return
+		}
+		slog.Warn("skipping skill changed on disk", "skill", name, "err", err)
+	}
+}
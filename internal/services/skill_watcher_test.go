@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+func writeTestSkillMd(t *testing.T, svc *SkillService, dir, frontmatter string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(svc.skillsDir, dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\n" + frontmatter + "---\n\nCheck the basics first.\n"
+	if err := os.WriteFile(filepath.Join(svc.skillsDir, dir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncSkillFromFilesystem(t *testing.T) {
+	db := setupSkillTestDB(t)
+	svc := newTestSkillService(t, db)
+
+	writeTestSkillMd(t, svc, "disk-usage", "name: disk-usage\ndescription: Finds full disks\n")
+	if changed, err := svc.SyncSkillFromFilesystem("disk-usage"); err != nil || !changed {
+		t.Fatalf("first sync = %v, %v; want created", changed, err)
+	}
+	if changed, err := svc.SyncSkillFromFilesystem("disk-usage"); err != nil || changed {
+		t.Fatalf("unchanged sync = %v, %v; want no change", changed, err)
+	}
+
+	writeTestSkillMd(t, svc, "disk-usage", "name: disk-usage\ndescription: Finds full disks and inode exhaustion\n")
+	if changed, err := svc.SyncSkillFromFilesystem("disk-usage"); err != nil || !changed {
+		t.Fatalf("edited sync = %v, %v; want updated", changed, err)
+	}
+	skill, err := svc.GetSkill("disk-usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Description != "Finds full disks and inode exhaustion" || !skill.Enabled {
+		t.Errorf("skill = %q enabled=%v, want edited description and enabled", skill.Description, skill.Enabled)
+	}
+
+	for dir, frontmatter := range map[string]string{
+		"Disk_Usage":  "description: bad name\n",
+		"wrong-name":  "name: other-skill\ndescription: mismatch\n",
+		"broken-yaml": "name: [unterminated\n",
+	} {
+		writeTestSkillMd(t, svc, dir, frontmatter)
+		if _, err := svc.SyncSkillFromFilesystem(dir); err == nil {
+			t.Errorf("SyncSkillFromFilesystem(%q) succeeded, want validation error", dir)
+		}
+	}
+	var count int64
+	db.Model(&database.Skill{}).Count(&count)
+	if count != 1 {
+		t.Errorf("skills = %d, want only the valid one", count)
+	}
+}
+
+func TestSkillWatcher_SyncsNewAndEditedSkills(t *testing.T) {
+	db := setupSkillTestDB(t)
+	// The watcher queries from its own goroutine; keep every query on the
+	// one in-memory database.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	svc := newTestSkillService(t, db)
+
+	// Present before the watcher starts: picked up by the initial sync.
+	writeTestSkillMd(t, svc, "existing-skill", "name: existing-skill\ndescription: On disk at startup\n")
+
+	w := NewSkillWatcher(svc)
+	w.debounce = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Watch(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitForDescription(t, svc, "existing-skill", "On disk at startup")
+
+	writeTestSkillMd(t, svc, "new-skill", "name: new-skill\ndescription: Copied onto the volume\n")
+	waitForDescription(t, svc, "new-skill", "Copied onto the volume")
+
+	writeTestSkillMd(t, svc, "new-skill", "name: new-skill\ndescription: Edited in place\n")
+	waitForDescription(t, svc, "new-skill", "Edited in place")
+}
+
+func waitForDescription(t *testing.T, svc *SkillService, name, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var got string
+	for time.Now().Before(deadline) {
+		if skill, err := svc.GetSkill(name); err == nil {
+			if got = skill.Description; got == want {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("skill %s description = %q, want %q", name, got, want)
+}
@@ -66,6 +66,7 @@ func (s *SlackSummarizer) SummarizeForSlack(ctx context.Context, content string,
 	}
 
 	parsed := output.Parse(content)
+	parsed.StatusLabels = database.LoadVocabulary().StatusLabels()
 	formatted := output.FormatForSlack(parsed)
 
 	if output.WithinSlackBudget(formatted, maxBytes) {
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// MaxSnippetSize caps the content promoted from an incident into a skill.
+// Snippets are commands and queries, not log dumps.
+const MaxSnippetSize = 64 * 1024
+
+// Snippet export errors surfaced to the API as 4xx responses.
+var (
+	ErrSnippetExists  = errors.New("a file with this name already exists for the skill")
+	ErrInvalidSnippet = errors.New("invalid snippet")
+)
+
+// IncidentSnippet is a command or query to promote from an incident into a
+// skill.
+type IncidentSnippet struct {
+	SkillName   string
+	Kind        string // database.SkillSnippetScript (default) or database.SkillSnippetReference
+	Filename    string
+	Content     string
+	Description string
+	Overwrite   bool // replace an existing script; references are never replaced
+}
+
+// SnippetService promotes commands and queries the agent ran during an
+// incident into a skill's scripts or context references, recording where
+// each one came from.
+type SnippetService struct {
+	db       *gorm.DB
+	skills   SkillManager
+	contexts ContextManager
+}
+
+// NewSnippetService creates a snippet service.
+func NewSnippetService(db *gorm.DB, skills SkillManager, contexts ContextManager) *SnippetService {
+	return &SnippetService{db: db, skills: skills, contexts: contexts}
+}
+
+// ExportSnippet saves snippet into the skill and records its provenance.
+// Scripts are written to the skill's scripts directory with a header naming
+// the source incident; references are stored as context files and linked
+// from the skill prompt with [[filename]].
+func (s *SnippetService) ExportSnippet(ctx context.Context, incidentUUID string, snippet IncidentSnippet) (*database.SkillSnippet, error) {
+	snippet.SkillName = strings.TrimSpace(snippet.SkillName)
+	snippet.Filename = strings.TrimSpace(snippet.Filename)
+	snippet.Description = strings.TrimSpace(snippet.Description)
+	if snippet.Kind == "" {
+		snippet.Kind = database.SkillSnippetScript
+	}
+	if snippet.Kind != database.SkillSnippetScript && snippet.Kind != database.SkillSnippetReference {
+		return nil, fmt.Errorf("%w: kind must be script or reference", ErrInvalidSnippet)
+	}
+	if strings.TrimSpace(snippet.Content) == "" {
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidSnippet)
+	}
+	if len(snippet.Content) > MaxSnippetSize {
+		return nil, fmt.Errorf("%w: content exceeds %d bytes", ErrInvalidSnippet, MaxSnippetSize)
+	}
+
+	var incident database.Incident
+	if err := s.db.WithContext(ctx).Select("id", "uuid").Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return nil, err
+	}
+
+	skill, err := s.skills.GetSkill(snippet.SkillName)
+	if err != nil || skill == nil || skill.IsSystem {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSkill, snippet.SkillName)
+	}
+
+	switch snippet.Kind {
+	case database.SkillSnippetScript:
+		err = s.exportScript(incidentUUID, snippet)
+	default:
+		err = s.exportReference(incidentUUID, snippet)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := &database.SkillSnippet{
+		SkillName:    snippet.SkillName,
+		Kind:         snippet.Kind,
+		Filename:     snippet.Filename,
+		IncidentUUID: incidentUUID,
+		Description:  snippet.Description,
+	}
+	if err := s.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("record snippet: %w", err)
+	}
+	return record, nil
+}
+
+func (s *SnippetService) exportScript(incidentUUID string, snippet IncidentSnippet) error {
+	if err := ValidateScriptFilename(snippet.Filename); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnippet, err)
+	}
+	if !snippet.Overwrite {
+		path := filepath.Join(s.skills.GetSkillScriptsDir(snippet.SkillName), snippet.Filename)
+		if _, err := os.Lstat(path); err == nil {
+			return ErrSnippetExists
+		}
+	}
+	content := withProvenanceHeader(snippet.Filename, snippet.Content, incidentUUID, snippet.Description)
+	return s.skills.UpdateSkillScript(snippet.SkillName, snippet.Filename, content)
+}
+
+func (s *SnippetService) exportReference(incidentUUID string, snippet IncidentSnippet) error {
+	if err := s.contexts.ValidateFilename(snippet.Filename); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnippet, err)
+	}
+	if err := s.contexts.ValidateFileType(snippet.Filename); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnippet, err)
+	}
+	if s.contexts.FileExists(snippet.Filename) {
+		return ErrSnippetExists
+	}
+
+	prompt, err := s.skills.GetSkillPrompt(snippet.SkillName)
+	if err != nil {
+		return fmt.Errorf("read skill prompt: %w", err)
+	}
+
+	description := fmt.Sprintf("Exported from incident %s", incidentUUID)
+	if snippet.Description != "" {
+		description += ": " + snippet.Description
+	}
+	content := withProvenanceHeader(snippet.Filename, snippet.Content, incidentUUID, snippet.Description)
+	if _, err := s.contexts.SaveFile(snippet.Filename, snippet.Filename, "text/plain", description, int64(len(content)), strings.NewReader(content)); err != nil {
+		return fmt.Errorf("save reference: %w", err)
+	}
+
+	for _, ref := range s.contexts.ParseReferences(prompt) {
+		if ref == snippet.Filename {
+			return nil
+		}
+	}
+	line := "[[" + snippet.Filename + "]]"
+	if snippet.Description != "" {
+		line = "- " + line + " — " + snippet.Description
+	}
+	return s.skills.UpdateSkillPrompt(snippet.SkillName, strings.TrimRight(prompt, "\n")+"\n\n"+line)
+}
+
+// ListSnippets returns the snippets exported from an incident, newest first.
+func (s *SnippetService) ListSnippets(ctx context.Context, incidentUUID string) ([]database.SkillSnippet, error) {
+	var snippets []database.SkillSnippet
+	if err := s.db.WithContext(ctx).
+		Where("incident_uuid = ?", incidentUUID).
+		Order("created_at DESC").
+		Find(&snippets).Error; err != nil {
+		return nil, err
+	}
+	return snippets, nil
+}
+
+// withProvenanceHeader prefixes content with a comment naming the incident
+// it came from, in the comment syntax of the file's extension. A leading
+// shebang stays on the first line. Formats without comments are returned
+// unchanged.
+func withProvenanceHeader(filename, content, incidentUUID, description string) string {
+	header := "Exported from incident " + incidentUUID
+	if description != "" {
+		header += ": " + description
+	}
+
+	var line string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".sh", ".bash", ".py", ".rb", ".pl", ".yaml", ".yml", ".conf", ".cfg", ".ini", ".txt", ".log", ".promql", ".logql":
+		line = "# " + header
+	case ".sql":
+		line = "-- " + header
+	case ".js", ".ts", ".go":
+		line = "// " + header
+	case ".md", ".xml":
+		line = "<!-- " + header + " -->"
+	default:
+		return content
+	}
+
+	if strings.HasPrefix(content, "#!") {
+		shebang, rest, _ := strings.Cut(content, "\n")
+		return shebang + "\n" + line + "\n" + rest
+	}
+	return line + "\n" + content
+}
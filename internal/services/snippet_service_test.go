@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+func newTestSnippetService(t *testing.T) (*SnippetService, *SkillService, *gorm.DB) {
+	t.Helper()
+	db := setupSkillTestDB(t)
+	if err := db.AutoMigrate(&database.SkillSnippet{}, &database.ContextFile{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	skills := newTestSkillService(t, db)
+	skills.contextService.db = db
+	if _, err := skills.CreateSkill("pg-triage", "Postgres triage", "", "Check replication first."); err != nil {
+		t.Fatalf("CreateSkill: %v", err)
+	}
+	db.Create(&database.Skill{Name: "incident-manager", IsSystem: true})
+	db.Create(&database.Incident{UUID: "inc-1", Title: "Replica lag"})
+	return NewSnippetService(db, skills, skills.contextService), skills, db
+}
+
+func TestSnippetService_ExportScript(t *testing.T) {
+	svc, skills, _ := newTestSnippetService(t)
+	ctx := context.Background()
+
+	record, err := svc.ExportSnippet(ctx, "inc-1", IncidentSnippet{
+		SkillName:   "pg-triage",
+		Filename:    "replica_lag.sh",
+		Content:     "#!/bin/sh\npsql -c 'select now() - pg_last_xact_replay_timestamp()'\n",
+		Description: "replica lag",
+	})
+	if err != nil {
+		t.Fatalf("ExportSnippet: %v", err)
+	}
+	if record.Kind != database.SkillSnippetScript || record.IncidentUUID != "inc-1" {
+		t.Errorf("record = %+v", record)
+	}
+
+	script, err := skills.GetSkillScript("pg-triage", "replica_lag.sh")
+	if err != nil {
+		t.Fatalf("GetSkillScript: %v", err)
+	}
+	want := "#!/bin/sh\n# Exported from incident inc-1: replica lag\npsql"
+	if !strings.HasPrefix(script.Content, want) {
+		t.Errorf("script content = %q", script.Content)
+	}
+
+	_, err = svc.ExportSnippet(ctx, "inc-1", IncidentSnippet{SkillName: "pg-triage", Filename: "replica_lag.sh", Content: "echo"})
+	if !errors.Is(err, ErrSnippetExists) {
+		t.Fatalf("expected ErrSnippetExists, got %v", err)
+	}
+	if _, err := svc.ExportSnippet(ctx, "inc-1", IncidentSnippet{SkillName: "pg-triage", Filename: "replica_lag.sh", Content: "echo", Overwrite: true}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+
+	snippets, err := svc.ListSnippets(ctx, "inc-1")
+	if err != nil || len(snippets) != 2 {
+		t.Fatalf("ListSnippets = %v, %v", snippets, err)
+	}
+}
+
+func TestSnippetService_ExportReferenceLinksPrompt(t *testing.T) {
+	svc, skills, _ := newTestSnippetService(t)
+
+	_, err := svc.ExportSnippet(context.Background(), "inc-1", IncidentSnippet{
+		SkillName:   "pg-triage",
+		Kind:        database.SkillSnippetReference,
+		Filename:    "slow-queries.sql",
+		Content:     "select * from pg_stat_activity",
+		Description: "active queries",
+	})
+	if !errors.Is(err, ErrInvalidSnippet) {
+		t.Fatalf("expected .sql to be rejected as a context file, got %v", err)
+	}
+
+	_, err = svc.ExportSnippet(context.Background(), "inc-1", IncidentSnippet{
+		SkillName:   "pg-triage",
+		Kind:        database.SkillSnippetReference,
+		Filename:    "slow-queries.md",
+		Content:     "select * from pg_stat_activity",
+		Description: "active queries",
+	})
+	if err != nil {
+		t.Fatalf("ExportSnippet: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(skills.contextService.GetContextDir(), "slow-queries.md"))
+	if err != nil || !strings.HasPrefix(string(data), "<!-- Exported from incident inc-1") {
+		t.Fatalf("context file = %q, %v", data, err)
+	}
+	prompt, err := skills.GetSkillPrompt("pg-triage")
+	if err != nil {
+		t.Fatalf("GetSkillPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Check replication first.") || !strings.Contains(prompt, "slow-queries.md") {
+		t.Errorf("prompt = %q", prompt)
+	}
+}
+
+func TestSnippetService_RejectsInvalidTargets(t *testing.T) {
+	svc, _, _ := newTestSnippetService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		incident string
+		snippet  IncidentSnippet
+		want     error
+	}{
+		{"unknown incident", "missing", IncidentSnippet{SkillName: "pg-triage", Filename: "a.sh", Content: "x"}, gorm.ErrRecordNotFound},
+		{"unknown skill", "inc-1", IncidentSnippet{SkillName: "nope", Filename: "a.sh", Content: "x"}, ErrUnknownSkill},
+		{"system skill", "inc-1", IncidentSnippet{SkillName: "incident-manager", Filename: "a.sh", Content: "x"}, ErrUnknownSkill},
+		{"path traversal", "inc-1", IncidentSnippet{SkillName: "pg-triage", Filename: "../a.sh", Content: "x"}, ErrInvalidSnippet},
+		{"empty content", "inc-1", IncidentSnippet{SkillName: "pg-triage", Filename: "a.sh", Content: "  "}, ErrInvalidSnippet},
+		{"bad kind", "inc-1", IncidentSnippet{SkillName: "pg-triage", Kind: "asset", Filename: "a.sh", Content: "x"}, ErrInvalidSnippet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.ExportSnippet(ctx, tt.incident, tt.snippet); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -12,10 +12,11 @@ import (
 
 // SSHKeyEntry represents an SSH key without the private key content (for listing)
 type SSHKeyEntry struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	IsDefault bool   `json:"is_default"`
-	CreatedAt string `json:"created_at"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	IsDefault     bool   `json:"is_default"`
+	HasPassphrase bool   `json:"has_passphrase"`
+	CreatedAt     string `json:"created_at"`
 }
 
 // SSHKeyFull represents an SSH key with all fields (for internal use)
@@ -23,6 +24,7 @@ type SSHKeyFull struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
 	PrivateKey string `json:"private_key"`
+	Passphrase string `json:"passphrase,omitempty"`
 	IsDefault  bool   `json:"is_default"`
 	CreatedAt  string `json:"created_at"`
 }
@@ -136,6 +138,9 @@ func (s *ToolService) DeleteToolInstance(id uint) error {
 		if err := tx.Where("tool_instance_id = ?", id).Delete(&database.SkillTool{}).Error; err != nil {
 			return fmt.Errorf("failed to delete tool instance: clear skill assignments: %w", err)
 		}
+		if err := tx.Where("tool_instance_id = ?", id).Delete(&database.SSHKnownHost{}).Error; err != nil {
+			return fmt.Errorf("failed to delete tool instance: clear pinned host keys: %w", err)
+		}
 		if err := tx.Delete(&database.ToolInstance{}, id).Error; err != nil {
 			return fmt.Errorf("failed to delete tool instance: %w", err)
 		}
@@ -174,6 +179,9 @@ func (s *ToolService) EnsureToolTypes() error {
 		{Name: "clickhouse", Description: "ClickHouse read-only query and OLAP diagnostics integration"},
 		{Name: "netbox", Description: "NetBox CMDB integration for DCIM, IPAM, circuits, virtualization, and tenancy"},
 		{Name: "kubernetes", Description: "Kubernetes read-only diagnostics for pods, deployments, nodes, services, events, and logs"},
+		{Name: "prometheus", Description: "Prometheus HTTP API integration for PromQL queries, series, and label values"},
+		{Name: "log_search", Description: "Log search over Loki (LogQL) or Elasticsearch (query DSL) with time-range and result limits"},
+		{Name: "http_check", Description: "Synthetic HTTP probes against allow-listed URLs with latency, status, TLS expiry, and body assertions"},
 		{Name: "jira", Description: "Jira issue tracking integration (Cloud and Server/Data Center) for searching, viewing, commenting, and transitioning issues"},
 		{Name: "incidents", Description: "Read-only access to Akmatori's own incidents (list and get) for digests and reporting"},
 		{Name: "proposals", Description: "Create, inspect, and revise self-improvement proposals reviewed by operators in the Proposals tab"},
@@ -246,18 +254,20 @@ func (s *ToolService) GetSSHKeys(toolInstanceID uint) ([]SSHKeyEntry, error) {
 	result := make([]SSHKeyEntry, 0, len(keys))
 	for _, key := range keys {
 		result = append(result, SSHKeyEntry{
-			ID:        key.ID,
-			Name:      key.Name,
-			IsDefault: key.IsDefault,
-			CreatedAt: key.CreatedAt,
+			ID:            key.ID,
+			Name:          key.Name,
+			IsDefault:     key.IsDefault,
+			HasPassphrase: key.Passphrase != "",
+			CreatedAt:     key.CreatedAt,
 		})
 	}
 
 	return result, nil
 }
 
-// AddSSHKey adds a new SSH key to a tool instance
-func (s *ToolService) AddSSHKey(toolInstanceID uint, name string, privateKey string, setAsDefault bool) (*SSHKeyEntry, error) {
+// AddSSHKey adds a new SSH key to a tool instance. passphrase is stored
+// alongside an encrypted private key and may be empty.
+func (s *ToolService) AddSSHKey(toolInstanceID uint, name string, privateKey string, passphrase string, setAsDefault bool) (*SSHKeyEntry, error) {
 	instance, err := s.GetToolInstance(toolInstanceID)
 	if err != nil {
 		return nil, err
@@ -276,6 +286,7 @@ func (s *ToolService) AddSSHKey(toolInstanceID uint, name string, privateKey str
 		ID:         uuid.New().String(),
 		Name:       name,
 		PrivateKey: privateKey,
+		Passphrase: passphrase,
 		IsDefault:  setAsDefault || len(existingKeys) == 0, // First key is always default
 		CreatedAt:  time.Now().Format(time.RFC3339),
 	}
@@ -301,10 +312,11 @@ func (s *ToolService) AddSSHKey(toolInstanceID uint, name string, privateKey str
 	}
 
 	return &SSHKeyEntry{
-		ID:        newKey.ID,
-		Name:      newKey.Name,
-		IsDefault: newKey.IsDefault,
-		CreatedAt: newKey.CreatedAt,
+		ID:            newKey.ID,
+		Name:          newKey.Name,
+		IsDefault:     newKey.IsDefault,
+		HasPassphrase: newKey.Passphrase != "",
+		CreatedAt:     newKey.CreatedAt,
 	}, nil
 }
 
@@ -359,10 +371,11 @@ func (s *ToolService) UpdateSSHKey(toolInstanceID uint, keyID string, name *stri
 	}
 
 	return &SSHKeyEntry{
-		ID:        targetKey.ID,
-		Name:      targetKey.Name,
-		IsDefault: targetKey.IsDefault,
-		CreatedAt: targetKey.CreatedAt,
+		ID:            targetKey.ID,
+		Name:          targetKey.Name,
+		IsDefault:     targetKey.IsDefault,
+		HasPassphrase: targetKey.Passphrase != "",
+		CreatedAt:     targetKey.CreatedAt,
 	}, nil
 }
 
@@ -427,6 +440,50 @@ func (s *ToolService) DeleteSSHKey(toolInstanceID uint, keyID string) error {
 	return nil
 }
 
+// ListSSHKnownHosts returns the host keys the MCP gateway has pinned for an
+// SSH tool instance, ordered by host.
+func (s *ToolService) ListSSHKnownHosts(toolInstanceID uint) ([]database.SSHKnownHost, error) {
+	if _, err := s.GetToolInstance(toolInstanceID); err != nil {
+		return nil, err
+	}
+	var hosts []database.SSHKnownHost
+	if err := s.db.Where("tool_instance_id = ?", toolInstanceID).Order("host ASC").Find(&hosts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pinned host keys: %w", err)
+	}
+	return hosts, nil
+}
+
+// ClearSSHKnownHosts removes pinned host keys for a tool instance so the
+// next connection pins whatever key the host presents. host may be a
+// known_hosts entry ("web-1", "[web-1]:2222") to clear one host; empty clears
+// them all. Returns the number of keys removed.
+func (s *ToolService) ClearSSHKnownHosts(toolInstanceID uint, host string) (int64, error) {
+	if _, err := s.GetToolInstance(toolInstanceID); err != nil {
+		return 0, err
+	}
+	query := s.db.Where("tool_instance_id = ?", toolInstanceID)
+	if host != "" {
+		query = query.Where("host = ?", host)
+	}
+	result := query.Delete(&database.SSHKnownHost{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to clear pinned host keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteSSHKnownHost removes a single pinned host key.
+func (s *ToolService) DeleteSSHKnownHost(toolInstanceID uint, knownHostID uint) error {
+	result := s.db.Where("tool_instance_id = ? AND id = ?", toolInstanceID, knownHostID).Delete(&database.SSHKnownHost{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pinned host key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pinned host key %d not found", knownHostID)
+	}
+	return nil
+}
+
 // extractSSHKeys extracts SSH keys from tool instance settings
 func (s *ToolService) extractSSHKeys(settings database.JSONB) []SSHKeyFull {
 	var keys []SSHKeyFull
@@ -452,6 +509,9 @@ func (s *ToolService) extractSSHKeys(settings database.JSONB) []SSHKeyFull {
 		if privateKey, ok := keyMap["private_key"].(string); ok {
 			key.PrivateKey = privateKey
 		}
+		if passphrase, ok := keyMap["passphrase"].(string); ok {
+			key.Passphrase = passphrase
+		}
 		if isDefault, ok := keyMap["is_default"].(bool); ok {
 			key.IsDefault = isDefault
 		}
@@ -471,13 +531,17 @@ func (s *ToolService) extractSSHKeys(settings database.JSONB) []SSHKeyFull {
 func (s *ToolService) keysToInterface(keys []SSHKeyFull) []interface{} {
 	result := make([]interface{}, len(keys))
 	for i, key := range keys {
-		result[i] = map[string]interface{}{
+		entry := map[string]interface{}{
 			"id":          key.ID,
 			"name":        key.Name,
 			"private_key": key.PrivateKey,
 			"is_default":  key.IsDefault,
 			"created_at":  key.CreatedAt,
 		}
+		if key.Passphrase != "" {
+			entry["passphrase"] = key.Passphrase
+		}
+		result[i] = entry
 	}
 	return result
 }
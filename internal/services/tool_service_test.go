@@ -288,3 +288,44 @@ func TestUpdateToolInstance_HonorsProvidedLogicalName(t *testing.T) {
 		t.Errorf("expected logical_name 'custom-logical', got %q", updated.LogicalName)
 	}
 }
+
+func TestSSHKnownHosts_ListClearAndCascade(t *testing.T) {
+	db := setupToolTestDB(t)
+	if err := db.AutoMigrate(&database.SSHKnownHost{}, &database.SkillTool{}, &database.CronJobTool{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	toolType := database.ToolType{Name: "ssh"}
+	db.Create(&toolType)
+	svc := &ToolService{db: db}
+	instance, err := svc.CreateToolInstance(toolType.ID, "Prod SSH", "", nil)
+	if err != nil {
+		t.Fatalf("CreateToolInstance: %v", err)
+	}
+	for _, host := range []string{"web-2", "[web-1]:2222", "db-1"} {
+		db.Create(&database.SSHKnownHost{ToolInstanceID: instance.ID, Host: host, KeyType: "ssh-ed25519", PublicKey: "AAAA", Fingerprint: "SHA256:x"})
+	}
+
+	hosts, err := svc.ListSSHKnownHosts(instance.ID)
+	if err != nil || len(hosts) != 3 || hosts[0].Host != "[web-1]:2222" {
+		t.Fatalf("ListSSHKnownHosts = %+v, %v; want 3 hosts ordered by host", hosts, err)
+	}
+
+	if n, err := svc.ClearSSHKnownHosts(instance.ID, "web-2"); err != nil || n != 1 {
+		t.Fatalf("ClearSSHKnownHosts(web-2) = %d, %v; want 1", n, err)
+	}
+	if err := svc.DeleteSSHKnownHost(instance.ID, hosts[0].ID); err != nil {
+		t.Fatalf("DeleteSSHKnownHost: %v", err)
+	}
+	if err := svc.DeleteSSHKnownHost(instance.ID, hosts[0].ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("second DeleteSSHKnownHost err = %v, want not found", err)
+	}
+
+	if err := svc.DeleteToolInstance(instance.ID); err != nil {
+		t.Fatalf("DeleteToolInstance: %v", err)
+	}
+	var remaining int64
+	db.Model(&database.SSHKnownHost{}).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("pinned host keys after instance delete = %d, want 0", remaining)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+// toolSchemaTTL is how long schemas fetched from the MCP gateway are reused.
+// They only change when the gateway is upgraded.
+const toolSchemaTTL = 5 * time.Minute
+
+// toolSettingsSchema mirrors the settings_schema the MCP gateway publishes
+// for each tool type at GET /tools. Properties and array items have the same
+// shape.
+type toolSettingsSchema struct {
+	Type       string                        `json:"type"`
+	Required   []string                      `json:"required"`
+	Properties map[string]toolSettingsSchema `json:"properties"`
+	Items      *toolSettingsSchema           `json:"items"`
+	Enum       []string                      `json:"enum"`
+	Minimum    *float64                      `json:"minimum"`
+	Maximum    *float64                      `json:"maximum"`
+	MinItems   *int                          `json:"minItems"`
+}
+
+// ToolSchemaClient checks tool instance settings against the settings
+// schema of their tool type, as published by the MCP gateway that runs the
+// tools. Schemas are cached for toolSchemaTTL. When the gateway cannot be
+// reached settings are accepted unchecked, so an unavailable gateway does not
+// block editing tools.
+type ToolSchemaClient struct {
+	gatewayURL string
+	client     *http.Client
+
+	mu      sync.Mutex
+	schemas map[string]toolSettingsSchema
+	fetched time.Time
+}
+
+// NewToolSchemaClient creates a client that reads schemas from the
+// MCP gateway at gatewayURL.
+func NewToolSchemaClient(gatewayURL string) *ToolSchemaClient {
+	return &ToolSchemaClient{
+		gatewayURL: strings.TrimRight(gatewayURL, "/"),
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// ValidateToolSettings returns the settings that do not match the schema of
+// toolType, keyed by setting name (array items as name[i].field), or nil if
+// they all do. Tool types without a published schema are not checked.
+func (v *ToolSchemaClient) ValidateToolSettings(ctx context.Context, toolType string, settings database.JSONB) map[string]string {
+	schemas, err := v.loadSchemas(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "tool settings not validated: gateway schemas unavailable", "tool_type", toolType, "error", err)
+		return nil
+	}
+	schema, ok := schemas[toolType]
+	if !ok {
+		return nil
+	}
+	errs := map[string]string{}
+	validateSettingsObject(errs, "", schema, settings)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (v *ToolSchemaClient) loadSchemas(ctx context.Context) (map[string]toolSettingsSchema, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.schemas != nil && time.Since(v.fetched) < toolSchemaTTL {
+		return v.schemas, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.gatewayURL+"/tools", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gateway returned status %d", resp.StatusCode)
+	}
+	var types map[string]struct {
+		SettingsSchema toolSettingsSchema `json:"settings_schema"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&types); err != nil {
+		return nil, fmt.Errorf("decode tool schemas: %w", err)
+	}
+	schemas := make(map[string]toolSettingsSchema, len(types))
+	for name, t := range types {
+		schemas[name] = t.SettingsSchema
+	}
+	v.schemas, v.fetched = schemas, time.Now()
+	return schemas, nil
+}
+
+// validateSettingsObject checks the required keys and known properties of
+// obj. Keys the schema does not describe are left alone: the gateway ignores
+// them and older instances may still carry them.
+func validateSettingsObject(errs map[string]string, prefix string, schema toolSettingsSchema, obj map[string]interface{}) {
+	for _, key := range schema.Required {
+		if isEmptySetting(obj[key]) {
+			errs[prefix+key] = "is required"
+		}
+	}
+	for key, value := range obj {
+		prop, ok := schema.Properties[key]
+		if !ok || value == nil {
+			continue
+		}
+		validateSettingValue(errs, prefix+key, prop, value)
+	}
+}
+
+func validateSettingValue(errs map[string]string, path string, prop toolSettingsSchema, value interface{}) {
+	switch prop.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			errs[path] = "must be a string"
+			return
+		}
+		if len(prop.Enum) > 0 && s != "" && !slices.Contains(prop.Enum, s) {
+			errs[path] = "must be one of: " + strings.Join(prop.Enum, " ")
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			errs[path] = "must be a number"
+			return
+		}
+		if prop.Type == "integer" && n != math.Trunc(n) {
+			errs[path] = "must be an integer"
+			return
+		}
+		if prop.Minimum != nil && n < *prop.Minimum {
+			errs[path] = fmt.Sprintf("must be at least %g", *prop.Minimum)
+		} else if prop.Maximum != nil && n > *prop.Maximum {
+			errs[path] = fmt.Sprintf("must be at most %g", *prop.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs[path] = "must be a boolean"
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			errs[path] = "must be an array"
+			return
+		}
+		if prop.MinItems != nil && len(items) < *prop.MinItems {
+			errs[path] = fmt.Sprintf("must have at least %d items", *prop.MinItems)
+			return
+		}
+		if prop.Items == nil {
+			return
+		}
+		for i, item := range items {
+			validateSettingValue(errs, fmt.Sprintf("%s[%d]", path, i), *prop.Items, item)
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			errs[path] = "must be an object"
+			return
+		}
+		validateSettingsObject(errs, path+".", prop, obj)
+	}
+}
+
+// isEmptySetting reports whether a required setting counts as unset: absent,
+// null or a blank string, which is what the UI sends for an untouched field.
+func isEmptySetting(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+)
+
+const testToolSchemas = `{
+  "zabbix": {"name": "zabbix", "settings_schema": {
+    "type": "object",
+    "required": ["zabbix_url"],
+    "properties": {
+      "zabbix_url": {"type": "string"},
+      "zabbix_timeout": {"type": "integer", "minimum": 5, "maximum": 300},
+      "zabbix_verify_ssl": {"type": "boolean"},
+      "zabbix_auth": {"type": "string", "enum": ["token", "password"]}
+    }
+  }},
+  "ssh": {"name": "ssh", "settings_schema": {
+    "type": "object",
+    "properties": {
+      "ssh_hosts": {"type": "array", "items": {
+        "type": "object",
+        "required": ["hostname", "address"],
+        "properties": {"port": {"type": "integer"}}
+      }}
+    }
+  }}
+}`
+
+func TestToolSchemaClient_ValidateToolSettings(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tools" {
+			http.NotFound(w, r)
+			return
+		}
+		fetches.Add(1)
+		_, _ = w.Write([]byte(testToolSchemas))
+	}))
+	defer srv.Close()
+	client := NewToolSchemaClient(srv.URL + "/")
+
+	tests := []struct {
+		name     string
+		toolType string
+		settings database.JSONB
+		want     map[string]string
+	}{
+		{"valid", "zabbix", database.JSONB{"zabbix_url": "https://zbx", "zabbix_timeout": float64(30), "extra": 1}, nil},
+		{"missing required", "zabbix", database.JSONB{"zabbix_url": " "}, map[string]string{"zabbix_url": "is required"}},
+		{"wrong types and bounds", "zabbix", database.JSONB{
+			"zabbix_url":        "https://zbx",
+			"zabbix_timeout":    float64(1),
+			"zabbix_verify_ssl": "yes",
+			"zabbix_auth":       "oauth",
+		}, map[string]string{
+			"zabbix_timeout":    "must be at least 5",
+			"zabbix_verify_ssl": "must be a boolean",
+			"zabbix_auth":       "must be one of: token password",
+		}},
+		{"not an integer", "zabbix", database.JSONB{"zabbix_url": "u", "zabbix_timeout": 7.5}, map[string]string{"zabbix_timeout": "must be an integer"}},
+		{"array items", "ssh", database.JSONB{"ssh_hosts": []interface{}{
+			map[string]interface{}{"hostname": "web", "address": "10.0.0.1", "port": float64(22)},
+			map[string]interface{}{"hostname": "db", "port": "22"},
+		}}, map[string]string{"ssh_hosts[1].address": "is required", "ssh_hosts[1].port": "must be a number"}},
+		{"unknown tool type", "custom", database.JSONB{"anything": true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := client.ValidateToolSettings(context.Background(), tt.toolType, tt.settings)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateToolSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("gateway fetched %d times, want 1 (cached)", n)
+	}
+}
+
+func TestToolSchemaClient_GatewayDownAcceptsSettings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	client := NewToolSchemaClient(srv.URL)
+
+	if got := client.ValidateToolSettings(context.Background(), "zabbix", database.JSONB{}); got != nil {
+		t.Errorf("ValidateToolSettings() = %v, want nil while the gateway is down", got)
+	}
+}
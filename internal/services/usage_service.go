@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// Usage rollup periods.
+const (
+	UsagePeriodDay  = "day"
+	UsagePeriodWeek = "week"
+)
+
+// Default report windows when GET /api/usage is called without since.
+const (
+	DefaultUsageDays  = 30
+	DefaultUsageWeeks = 12
+	// MaxUsageWindow bounds since..until so a daily rollup stays a few
+	// hundred buckets.
+	MaxUsageWindow = 366 * 24 * time.Hour
+)
+
+// ErrInvalidUsageFilter wraps filter validation failures so the API can
+// answer 400.
+var ErrInvalidUsageFilter = errors.New("invalid usage filter")
+
+// UsageService aggregates the token_usage table for the cost dashboard.
+type UsageService struct {
+	db *gorm.DB
+}
+
+// NewUsageService creates a new usage service.
+func NewUsageService(db *gorm.DB) *UsageService {
+	return &UsageService{db: db}
+}
+
+// UsageFilter selects the window and bucket size of a usage summary. Zero
+// times default to the last DefaultUsageDays days (or DefaultUsageWeeks
+// weeks) up to now.
+type UsageFilter struct {
+	Period string
+	Since  time.Time
+	Until  time.Time
+}
+
+// UsageTotals sums the runs of one bucket or breakdown row. Cost only
+// covers runs whose model had a price; their tokens are counted in
+// UnpricedTokens as well as TokensUsed.
+type UsageTotals struct {
+	Runs             int     `json:"runs"`
+	Incidents        int     `json:"incidents"`
+	TokensUsed       int64   `json:"tokens_used"`
+	UnpricedTokens   int64   `json:"unpriced_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// UsageBucket is the usage of one day or week, starting at Start (UTC
+// midnight; weeks start on Monday).
+type UsageBucket struct {
+	Start time.Time `json:"start"`
+	UsageTotals
+}
+
+// SkillUsage is the usage attributed to one skill. Runs that read no skill
+// are grouped under an empty name.
+type SkillUsage struct {
+	Skill string `json:"skill"`
+	UsageTotals
+}
+
+// ModelUsage is the usage of one LLM model.
+type ModelUsage struct {
+	Model string `json:"model"`
+	UsageTotals
+}
+
+// UsageSummary is the response of GET /api/usage. Buckets cover the whole
+// window, empty ones included; breakdowns are ordered by tokens, highest
+// first.
+type UsageSummary struct {
+	Period  string        `json:"period"`
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	Totals  UsageTotals   `json:"totals"`
+	Buckets []UsageBucket `json:"buckets"`
+	BySkill []SkillUsage  `json:"by_skill"`
+	ByModel []ModelUsage  `json:"by_model"`
+}
+
+// usageAccumulator builds one UsageTotals, counting incidents once.
+type usageAccumulator struct {
+	totals    UsageTotals
+	incidents map[string]bool
+}
+
+func (a *usageAccumulator) add(row *database.TokenUsage) {
+	if a.incidents == nil {
+		a.incidents = make(map[string]bool)
+	}
+	a.totals.Runs++
+	a.totals.TokensUsed += int64(row.TokensUsed)
+	if row.EstimatedCostUSD != nil {
+		a.totals.EstimatedCostUSD += *row.EstimatedCostUSD
+	} else {
+		a.totals.UnpricedTokens += int64(row.TokensUsed)
+	}
+	if !a.incidents[row.IncidentUUID] {
+		a.incidents[row.IncidentUUID] = true
+		a.totals.Incidents++
+	}
+}
+
+// Summary rolls up the usage recorded in filter's window.
+func (s *UsageService) Summary(ctx context.Context, filter UsageFilter) (*UsageSummary, error) {
+	period := filter.Period
+	if period == "" {
+		period = UsagePeriodDay
+	}
+	if period != UsagePeriodDay && period != UsagePeriodWeek {
+		return nil, fmt.Errorf("%w: period must be day or week", ErrInvalidUsageFilter)
+	}
+	until := filter.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	until = until.UTC()
+	since := filter.Since
+	if since.IsZero() {
+		if period == UsagePeriodWeek {
+			since = until.AddDate(0, 0, -7*DefaultUsageWeeks)
+		} else {
+			since = until.AddDate(0, 0, -DefaultUsageDays)
+		}
+	}
+	since = since.UTC()
+	if !since.Before(until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidUsageFilter)
+	}
+	if until.Sub(since) > MaxUsageWindow {
+		return nil, fmt.Errorf("%w: window must not exceed %d days", ErrInvalidUsageFilter, int(MaxUsageWindow/(24*time.Hour)))
+	}
+
+	var rows []database.TokenUsage
+	if err := s.db.WithContext(ctx).
+		Where("recorded_at >= ? AND recorded_at < ?", since, until).
+		Order("recorded_at asc").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list token usage: %w", err)
+	}
+
+	var total usageAccumulator
+	buckets := make(map[time.Time]*usageAccumulator)
+	skills := make(map[string]*usageAccumulator)
+	models := make(map[string]*usageAccumulator)
+	accumulate := func(m map[string]*usageAccumulator, key string, row *database.TokenUsage) {
+		acc, ok := m[key]
+		if !ok {
+			acc = &usageAccumulator{}
+			m[key] = acc
+		}
+		acc.add(row)
+	}
+	for i := range rows {
+		row := &rows[i]
+		total.add(row)
+		start := usageBucketStart(row.RecordedAt, period)
+		acc, ok := buckets[start]
+		if !ok {
+			acc = &usageAccumulator{}
+			buckets[start] = acc
+		}
+		acc.add(row)
+		accumulate(skills, row.Skill, row)
+		accumulate(models, row.Model, row)
+	}
+
+	summary := &UsageSummary{
+		Period:  period,
+		Since:   since,
+		Until:   until,
+		Totals:  total.totals,
+		Buckets: []UsageBucket{},
+		BySkill: make([]SkillUsage, 0, len(skills)),
+		ByModel: make([]ModelUsage, 0, len(models)),
+	}
+	for start := usageBucketStart(since, period); start.Before(until); start = nextUsageBucket(start, period) {
+		bucket := UsageBucket{Start: start}
+		if acc, ok := buckets[start]; ok {
+			bucket.UsageTotals = acc.totals
+		}
+		summary.Buckets = append(summary.Buckets, bucket)
+	}
+	for skill, acc := range skills {
+		summary.BySkill = append(summary.BySkill, SkillUsage{Skill: skill, UsageTotals: acc.totals})
+	}
+	sort.Slice(summary.BySkill, func(i, j int) bool {
+		a, b := summary.BySkill[i], summary.BySkill[j]
+		if a.TokensUsed != b.TokensUsed {
+			return a.TokensUsed > b.TokensUsed
+		}
+		return a.Skill < b.Skill
+	})
+	for model, acc := range models {
+		summary.ByModel = append(summary.ByModel, ModelUsage{Model: model, UsageTotals: acc.totals})
+	}
+	sort.Slice(summary.ByModel, func(i, j int) bool {
+		a, b := summary.ByModel[i], summary.ByModel[j]
+		if a.TokensUsed != b.TokensUsed {
+			return a.TokensUsed > b.TokensUsed
+		}
+		return a.Model < b.Model
+	})
+	return summary, nil
+}
+
+// usageBucketStart truncates t to the start of its UTC day or ISO week.
+func usageBucketStart(t time.Time, period string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if period != UsagePeriodWeek {
+		return day
+	}
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return day.AddDate(0, 0, -offset)
+}
+
+func nextUsageBucket(start time.Time, period string) time.Time {
+	if period == UsagePeriodWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/testhelpers"
+)
+
+func TestUsageService_Summary_RollsUpByPeriodSkillAndModel(t *testing.T) {
+	db := testhelpers.NewSQLiteDB(t, &database.TokenUsage{})
+	svc := NewUsageService(db)
+
+	cost := func(v float64) *float64 { return &v }
+	// 2026-03-02 is a Monday.
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	rows := []database.TokenUsage{
+		{IncidentUUID: "inc-1", Model: "gpt-5.2", Skill: "disk", TokensUsed: 1000, EstimatedCostUSD: cost(0.5), RecordedAt: day},
+		{IncidentUUID: "inc-1", Model: "gpt-5.2", Skill: "disk", TokensUsed: 500, EstimatedCostUSD: cost(0.25), RecordedAt: day.Add(time.Hour)},
+		{IncidentUUID: "inc-2", Model: "llama3", Skill: "", TokensUsed: 3000, RecordedAt: day.AddDate(0, 0, 8)},
+		{IncidentUUID: "inc-3", Model: "gpt-5.2", Skill: "disk", TokensUsed: 9999, RecordedAt: day.AddDate(0, 0, -30)}, // outside the window
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("seed usage: %v", err)
+	}
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Summary(context.Background(), UsageFilter{Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.Totals.Runs != 3 || summary.Totals.Incidents != 2 || summary.Totals.TokensUsed != 4500 {
+		t.Errorf("totals = %+v, want 3 runs, 2 incidents, 4500 tokens", summary.Totals)
+	}
+	if summary.Totals.UnpricedTokens != 3000 || summary.Totals.EstimatedCostUSD != 0.75 {
+		t.Errorf("totals = %+v, want 3000 unpriced tokens and $0.75", summary.Totals)
+	}
+	if len(summary.Buckets) != 14 {
+		t.Fatalf("got %d daily buckets, want 14", len(summary.Buckets))
+	}
+	if b := summary.Buckets[1]; !b.Start.Equal(day.Truncate(24*time.Hour)) || b.Runs != 2 || b.Incidents != 1 {
+		t.Errorf("bucket[1] = %+v, want both inc-1 runs on %s", b, day.Format("2006-01-02"))
+	}
+	if len(summary.BySkill) != 2 || summary.BySkill[0].Skill != "" || summary.BySkill[0].TokensUsed != 3000 {
+		t.Errorf("by_skill = %+v, want the skill-less run first", summary.BySkill)
+	}
+	if len(summary.ByModel) != 2 || summary.ByModel[1].Model != "gpt-5.2" || summary.ByModel[1].EstimatedCostUSD != 0.75 {
+		t.Errorf("by_model = %+v", summary.ByModel)
+	}
+
+	weekly, err := svc.Summary(context.Background(), UsageFilter{Period: UsagePeriodWeek, Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("weekly Summary: %v", err)
+	}
+	// Sunday Mar 1 falls in the week of Feb 23; Mar 2 and Mar 9 start the next two.
+	if len(weekly.Buckets) != 3 || weekly.Buckets[1].TokensUsed != 1500 || weekly.Buckets[2].TokensUsed != 3000 {
+		t.Errorf("weekly buckets = %+v", weekly.Buckets)
+	}
+
+	for _, filter := range []UsageFilter{
+		{Period: "month"},
+		{Since: until, Until: since},
+		{Since: since.AddDate(-2, 0, 0), Until: until},
+	} {
+		if _, err := svc.Summary(context.Background(), filter); !errors.Is(err, ErrInvalidUsageFilter) {
+			t.Errorf("Summary(%+v) err = %v, want ErrInvalidUsageFilter", filter, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// Workspace snapshot bounds. Files above maxWorkspaceHashBytes are compared
+// by size only; past maxWorkspaceFiles the walk stops and the manifest is
+// partial rather than stalling completion on a runaway workspace.
+const (
+	maxWorkspaceHashBytes = 32 << 20
+	maxWorkspaceFiles     = 5000
+)
+
+// workspaceBookkeeping lists top-level workspace entries the agent worker
+// maintains itself (pi settings, session files, spilled tool output). They
+// change on every run and are not the agent's work, so the diff skips them.
+var workspaceBookkeeping = map[string]bool{
+	".pi":                  true,
+	".sessions":            true,
+	"session_export.jsonl": true,
+	"tool_outputs":         true,
+}
+
+// workspaceFile is one regular file in a workspace snapshot.
+type workspaceFile struct {
+	Size   int64
+	SHA256 string // empty when the file is too large to hash
+}
+
+// snapshotWorkspace lists the regular files under dir by slash-separated
+// relative path. Symlinks and the worker's bookkeeping entries are skipped.
+func snapshotWorkspace(dir string) (map[string]workspaceFile, error) {
+	files := make(map[string]workspaceFile)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // unreadable entry: leave it out rather than fail the snapshot
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if workspaceBookkeeping[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) >= maxWorkspaceFiles {
+			return fs.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		file := workspaceFile{Size: info.Size()}
+		if info.Size() <= maxWorkspaceHashBytes {
+			file.SHA256, _ = hashFile(path)
+		}
+		files[rel] = file
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// workspaceBaselineJSONB encodes a snapshot for Incident.WorkspaceBaseline.
+func workspaceBaselineJSONB(files map[string]workspaceFile) database.JSONB {
+	out := make(database.JSONB, len(files))
+	for path, f := range files {
+		out[path] = map[string]interface{}{"size": f.Size, "sha256": f.SHA256}
+	}
+	return out
+}
+
+// parseWorkspaceBaseline decodes Incident.WorkspaceBaseline. Sizes are
+// float64 once the column has been through JSON, int64 before.
+func parseWorkspaceBaseline(baseline database.JSONB) map[string]workspaceFile {
+	files := make(map[string]workspaceFile, len(baseline))
+	for path, raw := range baseline {
+		entry, _ := raw.(map[string]interface{})
+		file := workspaceFile{}
+		switch size := entry["size"].(type) {
+		case float64:
+			file.Size = int64(size)
+		case int64:
+			file.Size = size
+		}
+		file.SHA256, _ = entry["sha256"].(string)
+		files[path] = file
+	}
+	return files
+}
+
+// diffWorkspace returns the changes between two snapshots, ordered by path.
+func diffWorkspace(incidentUUID string, before, after map[string]workspaceFile) []database.IncidentChange {
+	var changes []database.IncidentChange
+	add := func(path string, action database.IncidentChangeAction, f workspaceFile) {
+		changes = append(changes, database.IncidentChange{
+			IncidentUUID: incidentUUID,
+			Location:     database.IncidentChangeLocationWorkspace,
+			Path:         path,
+			Action:       action,
+			SizeBytes:    f.Size,
+			SHA256:       f.SHA256,
+		})
+	}
+	for path, now := range after {
+		was, existed := before[path]
+		switch {
+		case !existed:
+			add(path, database.IncidentChangeCreated, now)
+		case was.Size != now.Size || was.SHA256 != now.SHA256:
+			add(path, database.IncidentChangeModified, now)
+		}
+	}
+	for path, was := range before {
+		if _, ok := after[path]; !ok {
+			add(path, database.IncidentChangeDeleted, workspaceFile{Size: was.Size})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// captureWorkspaceBaseline snapshots a freshly spawned incident's workspace
+// for the completion diff. A failed snapshot is logged and leaves the
+// baseline empty, so every file then reads as created.
+func captureWorkspaceBaseline(incidentDir string) database.JSONB {
+	files, err := snapshotWorkspace(incidentDir)
+	if err != nil {
+		slog.Warn("failed to snapshot incident workspace", "dir", incidentDir, "err", err)
+		return database.JSONB{}
+	}
+	return workspaceBaselineJSONB(files)
+}
+
+// recordWorkspaceChanges diffs the incident's workspace against its spawn
+// baseline and replaces the workspace part of its change manifest. Remote
+// entries recorded by the gateway are left alone. Rerunning is idempotent,
+// so a resumed investigation's second completion just refreshes the diff.
+func (s *SkillService) recordWorkspaceChanges(incidentUUID string) error {
+	var incident database.Incident
+	if err := s.db.Select("uuid", "working_dir", "workspace_baseline").
+		Where("uuid = ?", incidentUUID).First(&incident).Error; err != nil {
+		return fmt.Errorf("load incident: %w", err)
+	}
+	if incident.WorkingDir == "" {
+		return nil
+	}
+	after, err := snapshotWorkspace(incident.WorkingDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("snapshot workspace: %w", err)
+	}
+	changes := diffWorkspace(incidentUUID, parseWorkspaceBaseline(incident.WorkspaceBaseline), after)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("incident_uuid = ? AND location = ?", incidentUUID, database.IncidentChangeLocationWorkspace).
+			Delete(&database.IncidentChange{}).Error; err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Create(&changes).Error
+	})
+}
+
+// ListIncidentChanges returns the incident's change manifest: workspace
+// changes first (by path), then remote writes in the order they ran.
+func ListIncidentChanges(ctx context.Context, db *gorm.DB, incidentUUID string) ([]database.IncidentChange, error) {
+	var changes []database.IncidentChange
+	if err := db.WithContext(ctx).Where("incident_uuid = ?", incidentUUID).
+		Order("id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Location == database.IncidentChangeLocationWorkspace &&
+			changes[j].Location != database.IncidentChangeLocationWorkspace
+	})
+	return changes, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+func writeWorkspaceFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFile(t, dir, "AGENTS.md", "prompt")
+	writeWorkspaceFile(t, dir, "notes/keep.txt", "same")
+	writeWorkspaceFile(t, dir, "notes/edit.txt", "v1")
+	writeWorkspaceFile(t, dir, "gone.txt", "bye")
+
+	before, err := snapshotWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Round-trip through the stored form, as the completion diff does.
+	before = parseWorkspaceBaseline(workspaceBaselineJSONB(before))
+
+	writeWorkspaceFile(t, dir, "notes/edit.txt", "v2")
+	writeWorkspaceFile(t, dir, "scripts/fix.sh", "#!/bin/sh\n")
+	writeWorkspaceFile(t, dir, ".sessions/s1.jsonl", "{}")
+	writeWorkspaceFile(t, dir, "tool_outputs/out.txt", "big")
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := snapshotWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := diffWorkspace("inc-1", before, after)
+	want := []struct {
+		path   string
+		action database.IncidentChangeAction
+	}{
+		{"gone.txt", database.IncidentChangeDeleted},
+		{"notes/edit.txt", database.IncidentChangeModified},
+		{"scripts/fix.sh", database.IncidentChangeCreated},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Path != w.path || c.Action != w.action || c.Location != database.IncidentChangeLocationWorkspace {
+			t.Errorf("change %d = %+v, want %s %s", i, c, w.action, w.path)
+		}
+	}
+	if changes[2].SizeBytes != 10 || len(changes[2].SHA256) != 64 {
+		t.Errorf("created file = %+v", changes[2])
+	}
+}
+
+func TestRecordWorkspaceChanges(t *testing.T) {
+	db := setupIncidentTestDB(t)
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&database.IncidentChange{})
+	svc := newIncidentTestService(t, db)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	writeWorkspaceFile(t, dir, "AGENTS.md", "prompt")
+	incident := database.Incident{
+		UUID: "ws-changes", Title: "t", Status: database.IncidentStatusRunning,
+		WorkingDir: dir, WorkspaceBaseline: captureWorkspaceBaseline(dir),
+	}
+	db.Where("uuid = ?", incident.UUID).Delete(&database.Incident{})
+	if err := db.Create(&incident).Error; err != nil {
+		t.Fatal(err)
+	}
+	// A remote write the gateway recorded while the agent ran.
+	if err := db.Create(&database.IncidentChange{
+		IncidentUUID: "ws-changes", Location: "web-1", Path: "/etc/app.conf",
+		Action: database.IncidentChangeModified, Tool: "ssh.write_file",
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	writeWorkspaceFile(t, dir, "report.md", "findings")
+	if err := svc.recordWorkspaceChanges("ws-changes"); err != nil {
+		t.Fatalf("recordWorkspaceChanges: %v", err)
+	}
+	// A second completion refreshes the workspace entries instead of duplicating them.
+	if err := svc.recordWorkspaceChanges("ws-changes"); err != nil {
+		t.Fatalf("recordWorkspaceChanges rerun: %v", err)
+	}
+
+	changes, err := ListIncidentChanges(ctx, db, "ws-changes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if changes[0].Path != "report.md" || changes[0].Action != database.IncidentChangeCreated {
+		t.Errorf("workspace change = %+v", changes[0])
+	}
+	if changes[1].Location != "web-1" || changes[1].Path != "/etc/app.conf" {
+		t.Errorf("remote change = %+v", changes[1])
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+)
+
+// workspaceUsageTop is how many of the largest workspaces WorkspaceUsage lists.
+const workspaceUsageTop = 10
+
+// activeWorkspaceStatuses are the statuses whose workspaces an agent may
+// still write to; they are never pruned.
+var activeWorkspaceStatuses = map[database.IncidentStatus]bool{
+	database.IncidentStatusPending: true,
+	database.IncidentStatusRunning: true,
+	database.IncidentStatusMonitor: true,
+}
+
+// WorkspaceUsage is the disk usage of incident workspaces and archives.
+type WorkspaceUsage struct {
+	TotalBytes     int64            `json:"total_bytes"`
+	WorkspaceCount int              `json:"workspace_count"`
+	ArchiveBytes   int64            `json:"archive_bytes"`
+	ArchiveCount   int              `json:"archive_count"`
+	Largest        []WorkspaceEntry `json:"largest"`
+}
+
+// WorkspaceEntry is one incident workspace on disk. Status is empty for a
+// workspace without an incident record.
+type WorkspaceEntry struct {
+	IncidentUUID string                  `json:"incident_uuid"`
+	Bytes        int64                   `json:"bytes"`
+	Status       database.IncidentStatus `json:"status,omitempty"`
+	ModifiedAt   time.Time               `json:"modified_at"`
+
+	path        string
+	completedAt *time.Time
+}
+
+// finishedAt is when the workspace stopped changing: the incident's
+// completion time, or the directory's mtime when it has none.
+func (e WorkspaceEntry) finishedAt() time.Time {
+	if e.completedAt != nil {
+		return *e.completedAt
+	}
+	return e.ModifiedAt
+}
+
+// SetArchiveDir sets where workspaces are archived before deletion. It
+// must be outside the incidents directory.
+func (s *RetentionService) SetArchiveDir(dir string) {
+	s.archiveDir = dir
+}
+
+// WorkspaceUsage reports the space taken by incident workspaces, with the
+// largest ones first, and by workspace archives.
+func (s *RetentionService) WorkspaceUsage() (*WorkspaceUsage, error) {
+	entries, err := s.scanWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	usage := &WorkspaceUsage{WorkspaceCount: len(entries), Largest: []WorkspaceEntry{}}
+	for _, e := range entries {
+		usage.TotalBytes += e.Bytes
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Bytes > entries[j].Bytes })
+	if len(entries) > workspaceUsageTop {
+		entries = entries[:workspaceUsageTop]
+	}
+	usage.Largest = append(usage.Largest, entries...)
+
+	archives, err := os.ReadDir(s.archiveDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read archive dir: %w", err)
+	}
+	for _, a := range archives {
+		if a.IsDir() || !strings.HasSuffix(a.Name(), ".tar.gz") {
+			continue
+		}
+		if info, err := a.Info(); err == nil {
+			usage.ArchiveBytes += info.Size()
+			usage.ArchiveCount++
+		}
+	}
+	return usage, nil
+}
+
+// pruneWorkspaces removes the workspaces of finished incidents that are
+// older than WorkspaceMaxAgeDays, then the oldest remaining ones until the
+// total is under WorkspaceMaxTotalMB. Incident records are kept.
+func (s *RetentionService) pruneWorkspaces(settings *database.RetentionSettings, result *CleanupResult) {
+	if settings.WorkspaceMaxAgeDays <= 0 && settings.WorkspaceMaxTotalMB <= 0 {
+		return
+	}
+	entries, err := s.scanWorkspaces()
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+		return
+	}
+
+	var total int64
+	var candidates []WorkspaceEntry
+	for _, e := range entries {
+		total += e.Bytes
+		// Orphans are left to the orphan phase and its grace period.
+		if e.Status != "" && !activeWorkspaceStatuses[e.Status] {
+			candidates = append(candidates, e)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].finishedAt().Before(candidates[j].finishedAt())
+	})
+
+	var cutoff time.Time
+	if settings.WorkspaceMaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -settings.WorkspaceMaxAgeDays)
+	}
+	maxBytes := int64(settings.WorkspaceMaxTotalMB) * 1024 * 1024
+	for _, e := range candidates {
+		expired := !cutoff.IsZero() && e.finishedAt().Before(cutoff)
+		overCap := maxBytes > 0 && total > maxBytes
+		if !expired && !overCap {
+			// Candidates are oldest first, so later ones are neither.
+			break
+		}
+		if settings.ArchiveWorkspaces && !s.archiveWorkspace(e.path, e.IncidentUUID, result) {
+			continue
+		}
+		if err := os.RemoveAll(e.path); err != nil {
+			slog.Error("failed to prune incident workspace", "uuid", e.IncidentUUID, "dir", e.path, "error", err)
+			result.Errors = append(result.Errors, fmt.Errorf("prune workspace %s: %w", e.IncidentUUID, err))
+			continue
+		}
+		total -= e.Bytes
+		result.WorkspacesPruned++
+		result.WorkspaceBytesFreed += e.Bytes
+	}
+}
+
+// scanWorkspaces lists the UUID-named workspace directories under dataDir
+// with their size and, when they have one, their incident's status.
+func (s *RetentionService) scanWorkspaces() ([]WorkspaceEntry, error) {
+	dirEntries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read data dir: %w", err)
+	}
+
+	var entries []WorkspaceEntry
+	index := make(map[string]int)
+	for _, d := range dirEntries {
+		if !d.IsDir() {
+			continue
+		}
+		if _, err := uuid.Parse(d.Name()); err != nil {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(s.dataDir, d.Name())
+		size, err := dirSize(path)
+		if err != nil {
+			slog.Warn("failed to calculate workspace size", "dir", d.Name(), "error", err)
+		}
+		index[d.Name()] = len(entries)
+		entries = append(entries, WorkspaceEntry{IncidentUUID: d.Name(), Bytes: size, ModifiedAt: info.ModTime(), path: path})
+	}
+
+	uuids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		uuids = append(uuids, e.IncidentUUID)
+	}
+	const batchSize = 500
+	for i := 0; i < len(uuids); i += batchSize {
+		end := min(i+batchSize, len(uuids))
+		var found []database.Incident
+		if err := s.db.Select("uuid, status, completed_at").Where("uuid IN ?", uuids[i:end]).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("look up workspace incidents: %w", err)
+		}
+		for _, inc := range found {
+			e := &entries[index[inc.UUID]]
+			e.Status = inc.Status
+			e.completedAt = inc.CompletedAt
+		}
+	}
+	return entries, nil
+}
+
+// archiveWorkspace writes dir to <archiveDir>/<name>-<timestamp>.tar.gz and
+// reports whether it succeeded; on failure the error is recorded and the
+// workspace must be kept.
+func (s *RetentionService) archiveWorkspace(dir, name string, result *CleanupResult) bool {
+	dest := filepath.Join(s.archiveDir, fmt.Sprintf("%s-%s.tar.gz", name, time.Now().UTC().Format("20060102T150405Z")))
+	if err := writeTarGz(dir, name, dest); err != nil {
+		slog.Error("failed to archive incident workspace", "dir", dir, "error", err)
+		result.Errors = append(result.Errors, fmt.Errorf("archive %s: %w", name, err))
+		return false
+	}
+	result.WorkspacesArchived++
+	return true
+}
+
+// writeTarGz archives the tree at src under the top-level directory name.
+// It writes to a temp file and renames it into place, so dest is never a
+// partial archive.
+func writeTarGz(src, name, dest string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".archive-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	err = filepath.Walk(src, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		var link string
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case !info.IsDir() && !info.Mode().IsRegular():
+			return nil // sockets, pipes and devices are not worth keeping
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(filepath.Join(name, rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// createWorkspace creates an incident with a workspace holding size bytes,
+// finished daysOld days ago.
+func createWorkspace(t *testing.T, db *gorm.DB, dataDir string, status database.IncidentStatus, daysOld, size int) string {
+	t.Helper()
+	id := uuid.NewString()
+	workDir := filepath.Join(dataDir, id)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "output.log"), make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	completedAt := time.Now().AddDate(0, 0, -daysOld)
+	if err := db.Create(&database.Incident{UUID: id, Source: "test", Status: status, WorkingDir: workDir, CompletedAt: &completedAt}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRunCleanup_PrunesWorkspacesByAgeAndSize(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	dataDir := t.TempDir()
+	db.Create(&database.RetentionSettings{Enabled: true, RetentionDays: 365, CleanupIntervalHours: 6, WorkspaceMaxAgeDays: 30, WorkspaceMaxTotalMB: 1})
+
+	const mb = 1024 * 1024
+	old := createWorkspace(t, db, dataDir, database.IncidentStatusCompleted, 40, 100)
+	older := createWorkspace(t, db, dataDir, database.IncidentStatusFailed, 20, mb/2)
+	newer := createWorkspace(t, db, dataDir, database.IncidentStatusDiagnosed, 10, mb/2)
+	running := createWorkspace(t, db, dataDir, database.IncidentStatusRunning, 50, mb/2)
+
+	result, err := NewRetentionService(dataDir, db).RunCleanup()
+	if err != nil {
+		t.Fatalf("RunCleanup failed: %v", err)
+	}
+	// old is past the age limit; older goes to bring 1.5MB under the 1MB cap.
+	if result.WorkspacesPruned != 2 {
+		t.Errorf("WorkspacesPruned = %d, want 2 (errors: %v)", result.WorkspacesPruned, result.Errors)
+	}
+	for id, wantKept := range map[string]bool{old: false, older: false, newer: true, running: true} {
+		_, err := os.Stat(filepath.Join(dataDir, id))
+		if kept := err == nil; kept != wantKept {
+			t.Errorf("workspace %s kept = %v, want %v", id, kept, wantKept)
+		}
+	}
+	var count int64
+	db.Model(&database.Incident{}).Count(&count)
+	if count != 4 {
+		t.Errorf("incident records = %d, want all 4 kept", count)
+	}
+}
+
+func TestRunCleanup_ArchivesWorkspacesBeforeDeleting(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	dataDir := t.TempDir()
+	archiveDir := t.TempDir()
+	db.Create(&database.RetentionSettings{Enabled: true, RetentionDays: 365, CleanupIntervalHours: 6, WorkspaceMaxAgeDays: 30, ArchiveWorkspaces: true})
+	id := createWorkspace(t, db, dataDir, database.IncidentStatusCompleted, 40, 0)
+	if err := os.WriteFile(filepath.Join(dataDir, id, "output.log"), []byte("agent output"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewRetentionService(dataDir, db)
+	svc.SetArchiveDir(archiveDir)
+	result, err := svc.RunCleanup()
+	if err != nil {
+		t.Fatalf("RunCleanup failed: %v", err)
+	}
+	if result.WorkspacesArchived != 1 || result.WorkspacesPruned != 1 {
+		t.Fatalf("archived=%d pruned=%d, want 1 and 1 (errors: %v)", result.WorkspacesArchived, result.WorkspacesPruned, result.Errors)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(archiveDir, id+"-*.tar.gz"))
+	if len(matches) != 1 {
+		t.Fatalf("archives = %v, want one for %s", matches, id)
+	}
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(b)
+	}
+	if got := files[id+"/output.log"]; got != "agent output" {
+		t.Errorf("archived output.log = %q; entries %v", got, files)
+	}
+
+	usage, err := svc.WorkspaceUsage()
+	if err != nil {
+		t.Fatalf("WorkspaceUsage failed: %v", err)
+	}
+	if usage.ArchiveCount != 1 || usage.ArchiveBytes == 0 || usage.WorkspaceCount != 0 {
+		t.Errorf("usage = %+v, want one archive and no workspaces", usage)
+	}
+}
+
+func TestWorkspaceUsage_ListsLargestFirst(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	dataDir := t.TempDir()
+	small := createWorkspace(t, db, dataDir, database.IncidentStatusCompleted, 1, 10)
+	large := createWorkspace(t, db, dataDir, database.IncidentStatusRunning, 1, 1000)
+	orphan := uuid.NewString()
+	if err := os.MkdirAll(filepath.Join(dataDir, orphan), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := NewRetentionService(dataDir, db).WorkspaceUsage()
+	if err != nil {
+		t.Fatalf("WorkspaceUsage failed: %v", err)
+	}
+	if usage.TotalBytes != 1010 || usage.WorkspaceCount != 3 {
+		t.Errorf("total=%d count=%d, want 1010 and 3", usage.TotalBytes, usage.WorkspaceCount)
+	}
+	if len(usage.Largest) != 3 || usage.Largest[0].IncidentUUID != large || usage.Largest[1].IncidentUUID != small {
+		t.Fatalf("largest = %+v", usage.Largest)
+	}
+	if usage.Largest[0].Status != database.IncidentStatusRunning || usage.Largest[2].Status != "" {
+		t.Errorf("statuses = %q, %q; want running and empty for the orphan", usage.Largest[0].Status, usage.Largest[2].Status)
+	}
+}
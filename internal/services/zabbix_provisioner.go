@@ -0,0 +1,514 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// ZabbixDefaultMinSeverity is the lowest trigger severity the provisioned
+// action forwards when the request does not choose one (2 = Warning).
+const ZabbixDefaultMinSeverity = 2
+
+const (
+	zabbixProvisionTimeout = 30 * time.Second
+	// zabbixMediaSendTo is the "send to" value of the provisioned user media.
+	// The webhook ignores it, but Zabbix requires one.
+	zabbixMediaSendTo = "akmatori"
+)
+
+// Zabbix provisioning errors surfaced to the API: invalid requests as 400,
+// failures talking to the Zabbix server as 502.
+var (
+	ErrInvalidZabbixProvision = errors.New("invalid zabbix provisioning request")
+	ErrZabbixAPI              = errors.New("zabbix api error")
+)
+
+// zabbixWebhookScript runs inside Zabbix for every problem and recovery
+// event. It turns the media type parameters into the payload the Zabbix
+// alert adapter parses; url and secret only address the request, and the
+// event date ("yyyy.mm.dd") and time are joined into event_time.
+const zabbixWebhookScript = `var params = JSON.parse(value);
+var payload = {};
+Object.keys(params).forEach(function (key) {
+    if (['url', 'secret', 'event_date', 'event_clock'].indexOf(key) === -1) {
+        payload[key] = params[key];
+    }
+});
+payload.event_time = params.event_date.replace(/\./g, '-') + ' ' + params.event_clock;
+
+var req = new HttpRequest();
+req.addHeader('Content-Type: application/json');
+if (params.secret) {
+    req.addHeader('X-Zabbix-Secret: ' + params.secret);
+}
+var resp = req.post(params.url, JSON.stringify(payload));
+if (req.getStatus() < 200 || req.getStatus() >= 300) {
+    throw 'Akmatori responded with HTTP ' + req.getStatus() + ': ' + resp;
+}
+return 'OK';`
+
+// zabbixWebhookParameters maps each payload field to the Zabbix macro that
+// fills it.
+var zabbixWebhookParameters = [][2]string{
+	{"alert_name", "{EVENT.NAME}"},
+	{"severity", "{EVENT.SEVERITY}"},
+	{"priority", "{EVENT.NSEVERITY}"},
+	{"metric_name", "{ITEM.NAME}"},
+	{"metric_value", "{ITEM.LASTVALUE}"},
+	{"trigger_expression", "{TRIGGER.EXPRESSION}"},
+	{"pending_duration", "{EVENT.AGE}"},
+	{"event_id", "{EVENT.ID}"},
+	{"hardware", "{HOST.NAME}"},
+	{"event_status", "{EVENT.STATUS}"},
+	{"runbook_url", "{TRIGGER.URL}"},
+	{"event_date", "{EVENT.DATE}"},
+	{"event_clock", "{EVENT.TIME}"},
+}
+
+// ZabbixProvisionRequest selects the Zabbix tool and how alerts are routed
+// to Akmatori.
+type ZabbixProvisionRequest struct {
+	// ToolInstanceID is the Zabbix tool whose credentials are used; 0 picks
+	// the only enabled one.
+	ToolInstanceID uint
+	// AkmatoriURL is the base URL Zabbix reaches Akmatori on. Empty falls
+	// back to the configured base URL.
+	AkmatoriURL string
+	// Username is the Zabbix user the media is attached to and the action
+	// notifies. Empty falls back to the tool's zabbix_user.
+	Username string
+	// MinSeverity is the lowest trigger severity forwarded, 0 (Not
+	// classified) to 5 (Disaster).
+	MinSeverity int
+}
+
+// ZabbixProvisionResult describes what was created or updated in Zabbix.
+type ZabbixProvisionResult struct {
+	ZabbixVersion    string `json:"zabbix_version"`
+	WebhookURL       string `json:"webhook_url"`
+	MediaTypeID      string `json:"media_type_id"`
+	MediaTypeName    string `json:"media_type_name"`
+	MediaTypeCreated bool   `json:"media_type_created"`
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	MediaAdded       bool   `json:"media_added"`
+	ActionID         string `json:"action_id"`
+	ActionName       string `json:"action_name"`
+	ActionCreated    bool   `json:"action_created"`
+}
+
+// ZabbixProvisioner configures a Zabbix server to deliver its problems to a
+// Zabbix alert source: a webhook media type posting to the source's webhook
+// URL, that media on a Zabbix user, and a trigger action sending problems
+// and recoveries through it. It logs in with a Zabbix tool's credentials.
+//
+// Everything is looked up by name before being created, so provisioning the
+// same source again updates the existing objects (a new webhook URL, secret
+// or severity threshold) instead of duplicating them.
+type ZabbixProvisioner struct {
+	db *gorm.DB
+}
+
+// NewZabbixProvisioner creates a Zabbix provisioner.
+func NewZabbixProvisioner(db *gorm.DB) *ZabbixProvisioner {
+	return &ZabbixProvisioner{db: db}
+}
+
+// ProvisionAlertSource sets up the Zabbix server to send alerts to the
+// alert source identified by sourceUUID. It returns gorm.ErrRecordNotFound
+// when the source does not exist.
+func (p *ZabbixProvisioner) ProvisionAlertSource(ctx context.Context, sourceUUID string, req ZabbixProvisionRequest) (*ZabbixProvisionResult, error) {
+	if req.MinSeverity < 0 || req.MinSeverity > 5 {
+		return nil, fmt.Errorf("%w: min_severity must be between 0 and 5", ErrInvalidZabbixProvision)
+	}
+
+	var source database.AlertSourceInstance
+	if err := p.db.WithContext(ctx).Preload("AlertSourceType").
+		Where("uuid = ?", sourceUUID).First(&source).Error; err != nil {
+		return nil, err
+	}
+	if source.AlertSourceType.Name != "zabbix" {
+		return nil, fmt.Errorf("%w: alert source %q is a %s source, not zabbix", ErrInvalidZabbixProvision, source.Name, source.AlertSourceType.Name)
+	}
+
+	baseURL := strings.TrimSpace(req.AkmatoriURL)
+	if baseURL == "" {
+		baseURL = ConfiguredBaseURL()
+	}
+	if u, err := url.Parse(baseURL); baseURL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: an http(s) akmatori_url is required when no base URL is configured", ErrInvalidZabbixProvision)
+	}
+
+	tool, err := p.zabbixTool(ctx, req.ToolInstanceID)
+	if err != nil {
+		return nil, err
+	}
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		username, _ = tool.Settings["zabbix_user"].(string)
+	}
+	if username == "" {
+		return nil, fmt.Errorf("%w: zabbix_user is required when the Zabbix tool authenticates with an API token", ErrInvalidZabbixProvision)
+	}
+
+	rpc, err := p.connect(ctx, tool)
+	if err != nil {
+		return nil, err
+	}
+	defer rpc.logout(ctx)
+
+	result := &ZabbixProvisionResult{
+		ZabbixVersion: rpc.version,
+		WebhookURL:    source.GetWebhookURL(strings.TrimRight(baseURL, "/")),
+		MediaTypeName: "Akmatori: " + source.Name,
+		ActionName:    "Akmatori: " + source.Name,
+		Username:      username,
+	}
+	if err := p.ensureMediaType(ctx, rpc, &source, result); err != nil {
+		return nil, err
+	}
+	if err := p.ensureUserMedia(ctx, rpc, result); err != nil {
+		return nil, err
+	}
+	if err := p.ensureAction(ctx, rpc, req.MinSeverity, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// zabbixTool loads the Zabbix tool instance to authenticate with.
+func (p *ZabbixProvisioner) zabbixTool(ctx context.Context, id uint) (*database.ToolInstance, error) {
+	query := p.db.WithContext(ctx).Preload("ToolType").
+		Joins("JOIN tool_types ON tool_types.id = tool_instances.tool_type_id").
+		Where("tool_types.name = ?", "zabbix")
+	if id != 0 {
+		var tool database.ToolInstance
+		if err := query.Where("tool_instances.id = ?", id).First(&tool).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: tool instance %d is not a Zabbix tool", ErrInvalidZabbixProvision, id)
+			}
+			return nil, err
+		}
+		return &tool, nil
+	}
+
+	var tools []database.ToolInstance
+	if err := query.Where("tool_instances.enabled = ?", true).Order("tool_instances.id ASC").Find(&tools).Error; err != nil {
+		return nil, fmt.Errorf("load zabbix tool instances: %w", err)
+	}
+	switch len(tools) {
+	case 0:
+		return nil, fmt.Errorf("%w: no enabled Zabbix tool is configured", ErrInvalidZabbixProvision)
+	case 1:
+		return &tools[0], nil
+	default:
+		return nil, fmt.Errorf("%w: several Zabbix tools are configured, choose one with tool_instance_id", ErrInvalidZabbixProvision)
+	}
+}
+
+// connect opens an authenticated JSON-RPC session with the tool's settings,
+// honouring the Zabbix proxy setting the gateway uses for the same tool.
+func (p *ZabbixProvisioner) connect(ctx context.Context, tool *database.ToolInstance) (*zabbixRPC, error) {
+	baseURL, _ := tool.Settings["zabbix_url"].(string)
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("%w: Zabbix tool %q has no zabbix_url", ErrInvalidZabbixProvision, tool.Name)
+	}
+	if !strings.HasSuffix(baseURL, "/api_jsonrpc.php") {
+		baseURL += "/api_jsonrpc.php"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if verify, ok := tool.Settings["zabbix_verify_ssl"].(bool); ok && !verify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- operator opted out per tool
+	}
+	var proxy database.ProxySettings
+	if err := p.db.WithContext(ctx).First(&proxy).Error; err == nil && proxy.ZabbixEnabled && proxy.ProxyURL != "" {
+		if proxyURL, err := url.Parse(proxy.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	rpc := &zabbixRPC{
+		url:    baseURL,
+		client: &http.Client{Timeout: zabbixProvisionTimeout, Transport: transport},
+	}
+	if err := rpc.call(ctx, "apiinfo.version", []string{}, &rpc.version); err != nil {
+		return nil, err
+	}
+	major, minor := parseZabbixVersion(rpc.version)
+	if major < 5 || (major == 5 && minor < 4) {
+		return nil, fmt.Errorf("%w: Zabbix 5.4 or newer is required, the server runs %s", ErrInvalidZabbixProvision, rpc.version)
+	}
+	// 6.4 takes API tokens as a bearer header and 7.2 no longer accepts them
+	// in the request body.
+	rpc.bearer = major > 6 || (major == 6 && minor >= 4)
+
+	if token, _ := tool.Settings["zabbix_token"].(string); token != "" {
+		rpc.auth = token
+		return rpc, nil
+	}
+	user, _ := tool.Settings["zabbix_user"].(string)
+	password, _ := tool.Settings["zabbix_password"].(string)
+	if user == "" || password == "" {
+		return nil, fmt.Errorf("%w: Zabbix tool %q has neither zabbix_token nor zabbix_user and zabbix_password", ErrInvalidZabbixProvision, tool.Name)
+	}
+	if err := rpc.call(ctx, "user.login", map[string]string{"username": user, "password": password}, &rpc.auth); err != nil {
+		return nil, err
+	}
+	rpc.session = true
+	return rpc, nil
+}
+
+func (p *ZabbixProvisioner) ensureMediaType(ctx context.Context, rpc *zabbixRPC, source *database.AlertSourceInstance, result *ZabbixProvisionResult) error {
+	var existing []struct {
+		MediaTypeID string `json:"mediatypeid"`
+	}
+	if err := rpc.call(ctx, "mediatype.get", map[string]interface{}{
+		"output": []string{"mediatypeid"},
+		"filter": map[string]interface{}{"name": result.MediaTypeName},
+	}, &existing); err != nil {
+		return err
+	}
+
+	parameters := []map[string]string{
+		{"name": "url", "value": result.WebhookURL},
+		{"name": "secret", "value": source.WebhookSecret},
+	}
+	for _, param := range zabbixWebhookParameters {
+		parameters = append(parameters, map[string]string{"name": param[0], "value": param[1]})
+	}
+	params := map[string]interface{}{
+		"name":        result.MediaTypeName,
+		"type":        4, // webhook
+		"status":      0,
+		"script":      zabbixWebhookScript,
+		"timeout":     "30s",
+		"parameters":  parameters,
+		"description": fmt.Sprintf("Sends problems to the Akmatori alert source %q. Managed by Akmatori; changes are overwritten when the source is provisioned again.", source.Name),
+		"message_templates": []map[string]interface{}{
+			{"eventsource": 0, "recovery": 0, "subject": "Problem: {EVENT.NAME}", "message": "Problem started at {EVENT.TIME} on {EVENT.DATE}\nHost: {HOST.NAME}\nSeverity: {EVENT.SEVERITY}"},
+			{"eventsource": 0, "recovery": 1, "subject": "Resolved: {EVENT.NAME}", "message": "Problem resolved at {EVENT.RECOVERY.TIME} on {EVENT.RECOVERY.DATE}\nHost: {HOST.NAME}"},
+		},
+	}
+
+	var ids struct {
+		MediaTypeIDs []string `json:"mediatypeids"`
+	}
+	method := "mediatype.create"
+	if len(existing) > 0 {
+		method = "mediatype.update"
+		params["mediatypeid"] = existing[0].MediaTypeID
+	}
+	if err := rpc.call(ctx, method, params, &ids); err != nil {
+		return err
+	}
+	if len(ids.MediaTypeIDs) == 0 {
+		return fmt.Errorf("%w: %s returned no media type id", ErrZabbixAPI, method)
+	}
+	result.MediaTypeID = ids.MediaTypeIDs[0]
+	result.MediaTypeCreated = len(existing) == 0
+	return nil
+}
+
+// ensureUserMedia attaches the media type to the user unless it already is.
+// user.update replaces the whole media list, so the user's other media are
+// sent back unchanged.
+func (p *ZabbixProvisioner) ensureUserMedia(ctx context.Context, rpc *zabbixRPC, result *ZabbixProvisionResult) error {
+	var users []struct {
+		UserID string                   `json:"userid"`
+		Medias []map[string]interface{} `json:"medias"`
+	}
+	if err := rpc.call(ctx, "user.get", map[string]interface{}{
+		"output":       []string{"userid"},
+		"filter":       map[string]interface{}{"username": result.Username},
+		"selectMedias": []string{"mediatypeid", "sendto", "active", "severity", "period"},
+	}, &users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("%w: Zabbix user %q not found", ErrInvalidZabbixProvision, result.Username)
+	}
+	result.UserID = users[0].UserID
+
+	medias := users[0].Medias
+	for _, media := range medias {
+		if fmt.Sprint(media["mediatypeid"]) == result.MediaTypeID {
+			return nil
+		}
+	}
+	medias = append(medias, map[string]interface{}{
+		"mediatypeid": result.MediaTypeID,
+		"sendto":      zabbixMediaSendTo,
+		"active":      0,
+		"severity":    63, // every severity; the action applies the threshold
+		"period":      "1-7,00:00-24:00",
+	})
+	if err := rpc.call(ctx, "user.update", map[string]interface{}{"userid": result.UserID, "medias": medias}, nil); err != nil {
+		return err
+	}
+	result.MediaAdded = true
+	return nil
+}
+
+// ensureAction creates or updates the trigger action that sends problems at
+// or above minSeverity through the media type, and recoveries to everyone
+// notified about the problem.
+func (p *ZabbixProvisioner) ensureAction(ctx context.Context, rpc *zabbixRPC, minSeverity int, result *ZabbixProvisionResult) error {
+	var existing []struct {
+		ActionID string `json:"actionid"`
+	}
+	if err := rpc.call(ctx, "action.get", map[string]interface{}{
+		"output": []string{"actionid"},
+		"filter": map[string]interface{}{"name": result.ActionName, "eventsource": 0},
+	}, &existing); err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"name":       result.ActionName,
+		"status":     0,
+		"esc_period": "1h",
+		"filter": map[string]interface{}{
+			"evaltype": 0,
+			"conditions": []map[string]interface{}{
+				// Trigger severity (4) is greater than or equal (5).
+				{"conditiontype": 4, "operator": 5, "value": strconv.Itoa(minSeverity)},
+			},
+		},
+		"operations": []map[string]interface{}{{
+			"operationtype": 0, // send message
+			"esc_step_from": 1,
+			"esc_step_to":   1,
+			"opmessage":     map[string]interface{}{"default_msg": 1, "mediatypeid": result.MediaTypeID},
+			"opmessage_usr": []map[string]string{{"userid": result.UserID}},
+		}},
+		"recovery_operations": []map[string]interface{}{{
+			"operationtype": 11, // notify all involved
+			"opmessage":     map[string]interface{}{"default_msg": 1},
+		}},
+	}
+
+	var ids struct {
+		ActionIDs []string `json:"actionids"`
+	}
+	method := "action.create"
+	if len(existing) > 0 {
+		method = "action.update"
+		params["actionid"] = existing[0].ActionID
+	} else {
+		params["eventsource"] = 0 // triggers; cannot be changed on update
+	}
+	if err := rpc.call(ctx, method, params, &ids); err != nil {
+		return err
+	}
+	if len(ids.ActionIDs) == 0 {
+		return fmt.Errorf("%w: %s returned no action id", ErrZabbixAPI, method)
+	}
+	result.ActionID = ids.ActionIDs[0]
+	result.ActionCreated = len(existing) == 0
+	return nil
+}
+
+// parseZabbixVersion returns the major and minor parts of a version such as
+// "6.4.12", or zeros when it cannot be parsed.
+func parseZabbixVersion(version string) (int, int) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0
+	}
+	return major, minor
+}
+
+// zabbixRPC is a minimal Zabbix JSON-RPC client for provisioning.
+type zabbixRPC struct {
+	url     string
+	client  *http.Client
+	version string
+	auth    string
+	bearer  bool
+	session bool // auth is a user.login session to close with user.logout
+	id      int
+}
+
+func (c *zabbixRPC) call(ctx context.Context, method string, params, out interface{}) error {
+	c.id++
+	body := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params, "id": c.id}
+	if c.auth != "" && !c.bearer {
+		body["auth"] = c.auth
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json-rpc")
+	if c.auth != "" && c.bearer {
+		req.Header.Set("Authorization", "Bearer "+c.auth)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrZabbixAPI, method, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrZabbixAPI, method, err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s: HTTP %d", ErrZabbixAPI, method, resp.StatusCode)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("%w: %s: invalid response: %v", ErrZabbixAPI, method, err)
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("%w: %s: %s %s", ErrZabbixAPI, method, envelope.Error.Message, envelope.Error.Data)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("%w: %s: unexpected result: %v", ErrZabbixAPI, method, err)
+	}
+	return nil
+}
+
+// logout ends a user.login session; API tokens are left alone.
+func (c *zabbixRPC) logout(ctx context.Context) {
+	if c.session {
+		_ = c.call(ctx, "user.logout", []string{}, nil)
+	}
+}
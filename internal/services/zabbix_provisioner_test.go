@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/akmatori/akmatori/internal/database"
+	"gorm.io/gorm"
+)
+
+// fakeZabbix is an in-memory Zabbix JSON-RPC API holding just enough state
+// for provisioning to be run twice.
+type fakeZabbix struct {
+	t          *testing.T
+	version    string
+	mu         sync.Mutex
+	methods    []string
+	bearer     []string
+	mediaTypes map[string]map[string]interface{}
+	actions    map[string]map[string]interface{}
+	userMedias []interface{}
+	nextID     int
+}
+
+func newFakeZabbix(t *testing.T, version string) (*fakeZabbix, *httptest.Server) {
+	f := &fakeZabbix{
+		t:          t,
+		version:    version,
+		mediaTypes: map[string]map[string]interface{}{},
+		actions:    map[string]map[string]interface{}{},
+		userMedias: []interface{}{map[string]interface{}{"mediatypeid": "1", "sendto": []string{"ops@example.com"}}},
+		nextID:     100,
+	}
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeZabbix) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if r.URL.Path != "/api_jsonrpc.php" {
+		f.t.Errorf("unexpected path %s", r.URL.Path)
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.methods = append(f.methods, req.Method)
+	f.bearer = append(f.bearer, r.Header.Get("Authorization"))
+
+	var result interface{}
+	switch req.Method {
+	case "apiinfo.version":
+		result = f.version
+	case "user.login":
+		result = "session-1"
+	case "user.logout":
+		result = true
+	case "mediatype.get":
+		result = f.find(f.mediaTypes, req.Params, "mediatypeid")
+	case "mediatype.create", "mediatype.update":
+		result = map[string]interface{}{"mediatypeids": []string{f.store(f.mediaTypes, req.Params, "mediatypeid")}}
+	case "action.get":
+		result = f.find(f.actions, req.Params, "actionid")
+	case "action.create", "action.update":
+		result = map[string]interface{}{"actionids": []string{f.store(f.actions, req.Params, "actionid")}}
+	case "user.get":
+		result = []interface{}{map[string]interface{}{"userid": "7", "medias": f.userMedias}}
+	case "user.update":
+		f.userMedias = req.Params["medias"].([]interface{})
+		result = map[string]interface{}{"userids": []string{"7"}}
+	default:
+		f.t.Errorf("unexpected method %s", req.Method)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": result, "id": 1})
+}
+
+func (f *fakeZabbix) find(objects map[string]map[string]interface{}, params map[string]interface{}, idField string) []interface{} {
+	name := params["filter"].(map[string]interface{})["name"]
+	for id, obj := range objects {
+		if obj["name"] == name {
+			return []interface{}{map[string]interface{}{idField: id}}
+		}
+	}
+	return []interface{}{}
+}
+
+func (f *fakeZabbix) store(objects map[string]map[string]interface{}, params map[string]interface{}, idField string) string {
+	id, ok := params[idField].(string)
+	if !ok {
+		f.nextID++
+		id = strconv.Itoa(f.nextID)
+	}
+	objects[id] = params
+	return id
+}
+
+func setupZabbixProvisioner(t *testing.T, toolSettings database.JSONB) (*ZabbixProvisioner, *gorm.DB) {
+	t.Helper()
+	db := setupIncidentTestDB(t)
+	if err := db.AutoMigrate(&database.AlertSourceType{}, &database.AlertSourceInstance{}, &database.ProxySettings{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	// The shared in-memory database outlives each test.
+	for _, model := range []interface{}{&database.AlertSourceInstance{}, &database.AlertSourceType{},
+		&database.ToolInstance{}, &database.ToolType{}, &database.ProxySettings{}} {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model)
+	}
+
+	toolType := database.ToolType{Name: "zabbix"}
+	db.Create(&toolType)
+	db.Create(&database.ToolInstance{ToolTypeID: toolType.ID, Name: "zbx", LogicalName: "zbx", Enabled: true, Settings: toolSettings})
+
+	zabbixType := database.AlertSourceType{Name: "zabbix", DisplayName: "Zabbix"}
+	db.Create(&zabbixType)
+	db.Create(&database.AlertSourceInstance{UUID: "src-zbx", AlertSourceTypeID: zabbixType.ID, Name: "Prod Zabbix", WebhookSecret: "s3cret"})
+	grafanaType := database.AlertSourceType{Name: "grafana", DisplayName: "Grafana"}
+	db.Create(&grafanaType)
+	db.Create(&database.AlertSourceInstance{UUID: "src-graf", AlertSourceTypeID: grafanaType.ID, Name: "Grafana"})
+
+	return NewZabbixProvisioner(db), db
+}
+
+func TestZabbixProvisioner_CreatesThenUpdates(t *testing.T) {
+	fake, server := newFakeZabbix(t, "7.0.5")
+	p, _ := setupZabbixProvisioner(t, database.JSONB{"zabbix_url": server.URL + "/", "zabbix_token": "tok"})
+	ctx := context.Background()
+	req := ZabbixProvisionRequest{AkmatoriURL: "https://akmatori.example.com/", Username: "Admin", MinSeverity: 3}
+
+	first, err := p.ProvisionAlertSource(ctx, "src-zbx", req)
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if !first.MediaTypeCreated || !first.MediaAdded || !first.ActionCreated {
+		t.Errorf("first run should create everything: %+v", first)
+	}
+	if first.WebhookURL != "https://akmatori.example.com/webhook/alert/src-zbx" {
+		t.Errorf("webhook url = %q", first.WebhookURL)
+	}
+	for _, auth := range fake.bearer[1:] {
+		if auth != "Bearer tok" {
+			t.Errorf("7.0 calls should authenticate with a bearer token, got %q", auth)
+		}
+	}
+
+	mediaType := fake.mediaTypes[first.MediaTypeID]
+	params := map[string]string{}
+	for _, p := range mediaType["parameters"].([]interface{}) {
+		m := p.(map[string]interface{})
+		params[m["name"].(string)] = m["value"].(string)
+	}
+	if params["url"] != first.WebhookURL || params["secret"] != "s3cret" || params["alert_name"] != "{EVENT.NAME}" {
+		t.Errorf("media type parameters = %v", params)
+	}
+	if len(fake.userMedias) != 2 {
+		t.Errorf("user medias = %v, want the existing email media plus the webhook", fake.userMedias)
+	}
+	condition := fake.actions[first.ActionID]["filter"].(map[string]interface{})["conditions"].([]interface{})[0].(map[string]interface{})
+	if condition["value"] != "3" {
+		t.Errorf("severity condition = %v", condition)
+	}
+
+	req.MinSeverity = 4
+	second, err := p.ProvisionAlertSource(ctx, "src-zbx", req)
+	if err != nil {
+		t.Fatalf("re-provision: %v", err)
+	}
+	if second.MediaTypeCreated || second.MediaAdded || second.ActionCreated {
+		t.Errorf("second run should only update: %+v", second)
+	}
+	if second.MediaTypeID != first.MediaTypeID || second.ActionID != first.ActionID {
+		t.Errorf("ids changed: %+v vs %+v", first, second)
+	}
+	if len(fake.mediaTypes) != 1 || len(fake.actions) != 1 || len(fake.userMedias) != 2 {
+		t.Errorf("objects duplicated: %d media types, %d actions, %d medias", len(fake.mediaTypes), len(fake.actions), len(fake.userMedias))
+	}
+	condition = fake.actions[first.ActionID]["filter"].(map[string]interface{})["conditions"].([]interface{})[0].(map[string]interface{})
+	if condition["value"] != "4" {
+		t.Errorf("severity condition not updated: %v", condition)
+	}
+}
+
+func TestZabbixProvisioner_PasswordLoginOnOlderServer(t *testing.T) {
+	fake, server := newFakeZabbix(t, "6.0.20")
+	p, _ := setupZabbixProvisioner(t, database.JSONB{"zabbix_url": server.URL, "zabbix_user": "Admin", "zabbix_password": "zabbix"})
+
+	result, err := p.ProvisionAlertSource(context.Background(), "src-zbx", ZabbixProvisionRequest{AkmatoriURL: "http://akmatori:3000"})
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if result.Username != "Admin" {
+		t.Errorf("username = %q, want the tool's zabbix_user", result.Username)
+	}
+	if fake.methods[1] != "user.login" || fake.methods[len(fake.methods)-1] != "user.logout" {
+		t.Errorf("methods = %v, want a login session closed at the end", fake.methods)
+	}
+	for _, auth := range fake.bearer {
+		if auth != "" {
+			t.Errorf("6.0 should authenticate in the request body, got header %q", auth)
+		}
+	}
+}
+
+func TestZabbixProvisioner_RejectsInvalidRequests(t *testing.T) {
+	_, server := newFakeZabbix(t, "5.0.40")
+	p, _ := setupZabbixProvisioner(t, database.JSONB{"zabbix_url": server.URL, "zabbix_token": "tok"})
+	ctx := context.Background()
+	valid := ZabbixProvisionRequest{AkmatoriURL: "https://akmatori.example.com", Username: "Admin"}
+
+	if _, err := p.ProvisionAlertSource(ctx, "missing", valid); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("missing source: err = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		req    ZabbixProvisionRequest
+	}{
+		{"not a zabbix source", "src-graf", valid},
+		{"severity out of range", "src-zbx", ZabbixProvisionRequest{AkmatoriURL: valid.AkmatoriURL, Username: "Admin", MinSeverity: 6}},
+		{"relative akmatori url", "src-zbx", ZabbixProvisionRequest{AkmatoriURL: "/akmatori", Username: "Admin"}},
+		{"no user with token auth", "src-zbx", ZabbixProvisionRequest{AkmatoriURL: valid.AkmatoriURL}},
+		{"server too old", "src-zbx", valid},
+		{"wrong tool instance", "src-zbx", ZabbixProvisionRequest{ToolInstanceID: 9999, AkmatoriURL: valid.AkmatoriURL, Username: "Admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.ProvisionAlertSource(ctx, tt.source, tt.req); !errors.Is(err, ErrInvalidZabbixProvision) {
+				t.Errorf("err = %v, want ErrInvalidZabbixProvision", err)
+			}
+		})
+	}
+}
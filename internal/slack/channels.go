@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -9,21 +10,89 @@ import (
 	"github.com/slack-go/slack"
 )
 
-// ChannelResolver resolves channel names to IDs
+// ConversationLister is the subset of *slack.Client the resolver needs.
+// Defined as an interface so unit tests can supply a fake.
+type ConversationLister interface {
+	GetConversations(params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	GetConversationsForUser(params *slack.GetConversationsForUserParameters) ([]slack.Channel, string, error)
+}
+
+// maxConversationPages bounds pagination so a huge workspace cannot stall
+// an alert post indefinitely (1000 channels per page).
+const maxConversationPages = 20
+
+// ChannelResolver resolves channel names to IDs. Lookups are cached;
+// HandleRename and Forget keep the cache in step with channel_rename /
+// channel_deleted events so a rename never leaves a stale mapping behind.
 type ChannelResolver struct {
-	client *slack.Client
+	client ConversationLister
 	cache  map[string]string // name -> id
 	mu     sync.RWMutex
+
+	// clientFn, when set, supplies the live client on every lookup
+	// (NewManagedChannelResolver). The cache is dropped whenever the client
+	// changes because a credential reload may point at another workspace.
+	clientFn   func() *slack.Client
+	lastClient *slack.Client
+
+	// shared, when set, replaces cache (SetSharedCache).
+	shared SharedChannelCache
 }
 
 // NewChannelResolver creates a new channel resolver
 func NewChannelResolver(client *slack.Client) *ChannelResolver {
+	r := &ChannelResolver{cache: make(map[string]string)}
+	if client != nil {
+		r.client = client
+	}
+	return r
+}
+
+// NewManagedChannelResolver creates a resolver that always uses the
+// manager's current client, so it survives Slack credential reloads.
+func NewManagedChannelResolver(m *Manager) *ChannelResolver {
 	return &ChannelResolver{
-		client: client,
-		cache:  make(map[string]string),
+		cache:    make(map[string]string),
+		clientFn: m.GetClient,
 	}
 }
 
+// SetSharedCache makes the resolver keep its name -> ID map in shared
+// instead of in-process, so it survives restarts and every API replica sees
+// the renames and deletions handled by one of them. Call before first use.
+func (r *ChannelResolver) SetSharedCache(shared SharedChannelCache) {
+	r.shared = shared
+}
+
+// withShared runs op against the shared cache with a bounded context.
+func (r *ChannelResolver) withShared(op func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedChannelTimeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// cached returns the cached ID for a channel name. Shared cache errors
+// count as misses so resolution falls back to the Slack API.
+func (r *ChannelResolver) cached(name string) (string, bool) {
+	if r.shared != nil {
+		var id string
+		var ok bool
+		err := r.withShared(func(ctx context.Context) (err error) {
+			id, ok, err = r.shared.Lookup(ctx, name)
+			return err
+		})
+		if err != nil {
+			slog.Warn("shared channel cache lookup failed", "channel_name", name, "error", err)
+			return "", false
+		}
+		return id, ok
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.cache[name]
+	return id, ok
+}
+
 // ResolveChannel resolves a channel name or ID to a channel ID
 // Accepts:
 // - Channel ID (C01234567890 or G01234567890)
@@ -43,79 +112,223 @@ func (r *ChannelResolver) ResolveChannel(nameOrID string) (string, error) {
 	// Remove # prefix if present
 	channelName := strings.TrimPrefix(nameOrID, "#")
 
+	client := r.currentClient()
+
 	// Check cache first
-	r.mu.RLock()
-	if id, ok := r.cache[channelName]; ok {
-		r.mu.RUnlock()
-		slog.Info("Resolved channel (cached)", "channel_name", channelName, "channel_id", id)
+	if id, ok := r.cached(channelName); ok {
+		slog.Debug("Resolved channel (cached)", "channel_name", channelName, "channel_id", id)
 		return id, nil
 	}
-	r.mu.RUnlock()
+
+	if client == nil {
+		return "", fmt.Errorf("cannot resolve channel '%s': slack client not available", channelName)
+	}
 
 	// Not in cache, look it up via Slack API
-	id, err := r.lookupChannel(channelName)
+	id, err := r.lookupChannel(client, channelName)
 	if err != nil {
 		return "", err
 	}
 
-	// Cache the result
-	r.mu.Lock()
-	r.cache[channelName] = id
-	r.mu.Unlock()
-
 	slog.Info("Resolved channel", "channel_name", channelName, "channel_id", id)
 	return id, nil
 }
 
-// lookupChannel looks up a channel by name using the Slack API
-func (r *ChannelResolver) lookupChannel(name string) (string, error) {
-	// Try public channels first
-	channels, _, err := r.client.GetConversations(&slack.GetConversationsParameters{
-		ExcludeArchived: true,
-		Limit:           1000,
-		Types:           []string{"public_channel"},
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to list public channels: %w", err)
+// currentClient returns the client to use for lookups, resetting the cache
+// when a managed resolver observes a new client.
+func (r *ChannelResolver) currentClient() ConversationLister {
+	if r.clientFn == nil {
+		return r.client
+	}
+	c := r.clientFn()
+	r.mu.Lock()
+	reloaded := false
+	if c != r.lastClient {
+		if r.lastClient != nil {
+			slog.Info("Slack client changed, clearing channel resolution cache")
+			reloaded = true
+		}
+		r.cache = make(map[string]string)
+		r.lastClient = c
 	}
+	r.mu.Unlock()
+	// The shared map outlives this process, so only a reload seen here
+	// (not the first client after startup) clears it.
+	if reloaded && r.shared != nil {
+		if err := r.withShared(r.shared.Clear); err != nil {
+			slog.Warn("failed to clear shared channel cache", "error", err)
+		}
+	}
+	if c == nil {
+		return nil
+	}
+	return c
+}
 
-	// Search for matching name in public channels
-	for _, channel := range channels {
-		if channel.Name == name {
-			return channel.ID, nil
+// lookupChannel looks up a channel by name using the Slack API. Public
+// channels come from conversations.list; private channels come from
+// users.conversations, which returns exactly the private channels the bot
+// is a member of (the only ones it can post to). Every name seen while
+// paging is cached so later lookups skip the API.
+func (r *ChannelResolver) lookupChannel(client ConversationLister, name string) (string, error) {
+	cursor := ""
+	for page := 0; page < maxConversationPages; page++ {
+		channels, next, err := client.GetConversations(&slack.GetConversationsParameters{
+			Cursor:          cursor,
+			ExcludeArchived: true,
+			Limit:           1000,
+			Types:           []string{"public_channel"},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list public channels: %w", err)
 		}
+		if id, ok := r.cacheChannels(channels, name); ok {
+			return id, nil
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
 	}
 
-	// Try private channels if not found in public
-	privateChannels, _, err := r.client.GetConversations(&slack.GetConversationsParameters{
-		ExcludeArchived: true,
-		Limit:           1000,
-		Types:           []string{"private_channel"},
-	})
-	if err != nil {
-		slog.Warn("Failed to list private channels", "error", err)
-		// Don't fail, just return not found for public channels
-		return "", fmt.Errorf("channel '%s' not found", name)
+	cursor = ""
+	for page := 0; page < maxConversationPages; page++ {
+		channels, next, err := client.GetConversationsForUser(&slack.GetConversationsForUserParameters{
+			Cursor:          cursor,
+			ExcludeArchived: true,
+			Limit:           1000,
+			Types:           []string{"private_channel"},
+		})
+		if err != nil {
+			// Missing groups:read scope surfaces here; public lookup already
+			// failed, so report not-found with the cause attached.
+			slog.Warn("Failed to list private channels", "error", err)
+			return "", fmt.Errorf("channel '%s' not found (private channel lookup failed: %v)", name, err)
+		}
+		if id, ok := r.cacheChannels(channels, name); ok {
+			return id, nil
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	return "", fmt.Errorf("channel '%s' not found (for private channels, invite the bot first)", name)
+}
+
+// cacheChannels records every channel name -> ID in the page and reports
+// whether want was among them.
+func (r *ChannelResolver) cacheChannels(channels []slack.Channel, want string) (string, bool) {
+	page := make(map[string]string, len(channels))
+	var found string
+	for _, ch := range channels {
+		if ch.Name == "" || ch.ID == "" {
+			continue
+		}
+		page[ch.Name] = ch.ID
+		if ch.Name == want {
+			found = ch.ID
+		}
+	}
+	if r.shared != nil {
+		if err := r.withShared(func(ctx context.Context) error { return r.shared.Store(ctx, page) }); err != nil {
+			slog.Warn("failed to update shared channel cache", "error", err)
+		}
+		return found, found != ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, id := range page {
+		r.cache[name] = id
+	}
+	return found, found != ""
+}
+
+// HandleRename updates the cache after channel id was renamed to newName
+// and returns the names it was previously cached under, so callers can
+// repoint configuration that still references an old name.
+func (r *ChannelResolver) HandleRename(id, newName string) []string {
+	newName = strings.TrimPrefix(strings.TrimSpace(newName), "#")
+	if r.shared != nil {
+		var old []string
+		err := r.withShared(func(ctx context.Context) (err error) {
+			old, err = r.shared.Rename(ctx, id, newName)
+			return err
+		})
+		if err != nil {
+			slog.Warn("failed to apply channel rename to shared cache", "channel_id", id, "error", err)
+		}
+		slog.Info("Channel renamed", "channel_id", id, "new_name", newName, "old_names", old)
+		return old
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var old []string
+	for name, cached := range r.cache {
+		if cached == id && name != newName {
+			old = append(old, name)
+			delete(r.cache, name)
+		}
 	}
+	if newName != "" {
+		r.cache[newName] = id
+	}
+	slog.Info("Channel renamed", "channel_id", id, "new_name", newName, "old_names", old)
+	return old
+}
 
-	// Search for matching name in private channels
-	for _, channel := range privateChannels {
-		if channel.Name == name {
-			return channel.ID, nil
+// Forget drops every cached name for channel id (deleted or archived).
+func (r *ChannelResolver) Forget(id string) {
+	if r.shared != nil {
+		if err := r.withShared(func(ctx context.Context) error { return r.shared.Forget(ctx, id) }); err != nil {
+			slog.Warn("failed to forget channel in shared cache", "channel_id", id, "error", err)
+		}
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, cached := range r.cache {
+		if cached == id {
+			delete(r.cache, name)
 		}
 	}
+}
 
-	return "", fmt.Errorf("channel '%s' not found", name)
+// Invalidate drops the cached mapping for a channel name so the next
+// ResolveChannel call asks Slack again. IDs are ignored.
+func (r *ChannelResolver) Invalidate(nameOrID string) {
+	name := strings.TrimPrefix(strings.TrimSpace(nameOrID), "#")
+	if r.shared != nil {
+		if err := r.withShared(func(ctx context.Context) error { return r.shared.Delete(ctx, name) }); err != nil {
+			slog.Warn("failed to invalidate channel in shared cache", "channel_name", name, "error", err)
+		}
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cache, name)
 }
 
 // ClearCache clears the channel name resolution cache
 func (r *ChannelResolver) ClearCache() {
+	if r.shared != nil {
+		if err := r.withShared(r.shared.Clear); err != nil {
+			slog.Warn("failed to clear shared channel cache", "error", err)
+		}
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.cache = make(map[string]string)
 	slog.Info("Cleared channel resolution cache")
 }
 
+// IsChannelID reports whether s looks like a Slack channel ID rather than
+// a channel name.
+func IsChannelID(s string) bool {
+	return isChannelID(s)
+}
+
 // isChannelID checks if a string looks like a Slack channel ID.
 // Public channel IDs start with C; private channel IDs start with G.
 func isChannelID(s string) bool {
@@ -1,7 +1,12 @@
 package slack
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/slack-go/slack"
 )
 
 // --- isChannelID tests ---
@@ -253,3 +258,153 @@ func TestChannelResolver_ResolveChannel_HashPrefixVariations(t *testing.T) {
 		})
 	}
 }
+
+// --- ChannelResolver tests with a fake Slack API ---
+
+type fakeConversationLister struct {
+	public      [][]slack.Channel // pages
+	private     [][]slack.Channel // pages returned by users.conversations
+	publicCalls int
+	userCalls   int
+	privateErr  error
+}
+
+func (f *fakeConversationLister) GetConversations(p *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	f.publicCalls++
+	return pageAt(f.public, p.Cursor)
+}
+
+func (f *fakeConversationLister) GetConversationsForUser(p *slack.GetConversationsForUserParameters) ([]slack.Channel, string, error) {
+	f.userCalls++
+	if f.privateErr != nil {
+		return nil, "", f.privateErr
+	}
+	return pageAt(f.private, p.Cursor)
+}
+
+func pageAt(pages [][]slack.Channel, cursor string) ([]slack.Channel, string, error) {
+	i := 0
+	if cursor != "" {
+		i, _ = strconv.Atoi(cursor)
+	}
+	if i >= len(pages) {
+		return nil, "", nil
+	}
+	next := ""
+	if i+1 < len(pages) {
+		next = strconv.Itoa(i + 1)
+	}
+	return pages[i], next, nil
+}
+
+func namedChannel(id, name string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	return ch
+}
+
+func TestChannelResolver_LookupPaginatesAndWarmsCache(t *testing.T) {
+	fake := &fakeConversationLister{
+		public: [][]slack.Channel{
+			{namedChannel("C0000000001", "general")},
+			{namedChannel("C0000000002", "alerts"), namedChannel("C0000000003", "random")},
+		},
+	}
+	resolver := &ChannelResolver{client: fake, cache: make(map[string]string)}
+
+	id, err := resolver.ResolveChannel("#alerts")
+	if err != nil || id != "C0000000002" {
+		t.Fatalf("ResolveChannel = %q, %v", id, err)
+	}
+	if fake.publicCalls != 2 {
+		t.Errorf("publicCalls = %d, want 2 pages", fake.publicCalls)
+	}
+
+	// Every name on the fetched pages is now cached.
+	if id, _ := resolver.ResolveChannel("general"); id != "C0000000001" {
+		t.Errorf("general = %q", id)
+	}
+	if fake.publicCalls != 2 {
+		t.Errorf("cached lookup hit the API (publicCalls = %d)", fake.publicCalls)
+	}
+}
+
+func TestChannelResolver_ResolvesPrivateMemberChannels(t *testing.T) {
+	fake := &fakeConversationLister{
+		public:  [][]slack.Channel{{namedChannel("C0000000001", "general")}},
+		private: [][]slack.Channel{{namedChannel("G0000000009", "sec-incidents")}},
+	}
+	resolver := &ChannelResolver{client: fake, cache: make(map[string]string)}
+
+	id, err := resolver.ResolveChannel("sec-incidents")
+	if err != nil || id != "G0000000009" {
+		t.Fatalf("ResolveChannel = %q, %v", id, err)
+	}
+	if fake.userCalls != 1 {
+		t.Errorf("userCalls = %d, want users.conversations lookup", fake.userCalls)
+	}
+
+	_, err = resolver.ResolveChannel("missing")
+	if err == nil || !strings.Contains(err.Error(), "invite the bot") {
+		t.Errorf("missing channel err = %v", err)
+	}
+
+	fake.privateErr = errors.New("missing_scope")
+	_, err = resolver.ResolveChannel("other")
+	if err == nil || !strings.Contains(err.Error(), "missing_scope") {
+		t.Errorf("private lookup failure should surface the cause, got %v", err)
+	}
+}
+
+func TestChannelResolver_RenameForgetInvalidate(t *testing.T) {
+	resolver := NewChannelResolver(nil)
+	resolver.HandleRename("C0000000002", "alerts")
+
+	old := resolver.HandleRename("C0000000002", "#alerts-prod")
+	if len(old) != 1 || old[0] != "alerts" {
+		t.Errorf("old names = %v, want [alerts]", old)
+	}
+	if id, err := resolver.ResolveChannel("alerts-prod"); err != nil || id != "C0000000002" {
+		t.Errorf("new name = %q, %v", id, err)
+	}
+	if _, err := resolver.ResolveChannel("alerts"); err == nil {
+		t.Error("old name should be evicted")
+	}
+
+	resolver.Forget("C0000000002")
+	if _, err := resolver.ResolveChannel("alerts-prod"); err == nil {
+		t.Error("forgotten channel should be evicted")
+	}
+
+	resolver.HandleRename("C0000000003", "ops")
+	resolver.Invalidate("#ops")
+	if _, err := resolver.ResolveChannel("ops"); err == nil {
+		t.Error("invalidated name should be evicted")
+	}
+}
+
+func TestChannelResolver_ManagedClearsCacheOnClientChange(t *testing.T) {
+	current := slack.New("xoxb-one")
+	resolver := &ChannelResolver{
+		cache:    make(map[string]string),
+		clientFn: func() *slack.Client { return current },
+	}
+	resolver.currentClient()
+	resolver.HandleRename("C0000000002", "alerts")
+
+	if id, err := resolver.ResolveChannel("alerts"); err != nil || id != "C0000000002" {
+		t.Fatalf("same client should keep cache: %q, %v", id, err)
+	}
+
+	current = nil
+	if _, err := resolver.ResolveChannel("alerts"); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("nil client err = %v", err)
+	}
+	resolver.mu.RLock()
+	n := len(resolver.cache)
+	resolver.mu.RUnlock()
+	if n != 0 {
+		t.Errorf("cache should be cleared after client change, has %d entries", n)
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/akmatori/akmatori/internal/database"
+	"github.com/akmatori/akmatori/internal/faultinject"
 	"github.com/gorilla/websocket"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
@@ -34,15 +36,52 @@ type Manager struct {
 
 	// State
 	running bool
+
+	// socketConnected is whether the current Socket Mode client last
+	// reported a live connection (see SetSocketConnected).
+	socketConnected bool
+
+	// socketMode controls whether Start/Reload open a Socket Mode
+	// connection. With it off only the Web API client is built, which is
+	// what every replica but the leader runs with.
+	socketMode bool
+
+	// faults answers chat.* calls with simulated 429s in failure-injection
+	// mode (nil never injects)
+	faults *faultinject.Injector
+
+	// apiURL overrides the Web API base URL (empty uses slack.com)
+	apiURL string
+
+	// Reconnect backoff after Socket Mode exits on its own. Consecutive
+	// failures double the delay from reconnectBase up to reconnectMax.
+	reconnectBase     time.Duration
+	reconnectMax      time.Duration
+	reconnectAttempts atomic.Int32
 }
 
+// reconnectResetAfter is how long a connection must stay up before a later
+// drop starts the backoff from reconnectBase again.
+const reconnectResetAfter = 5 * time.Minute
+
 // NewManager creates a new Slack manager
 func NewManager() *Manager {
 	return &Manager{
-		reloadChan: make(chan struct{}, 1),
+		reloadChan:    make(chan struct{}, 1),
+		socketMode:    true,
+		reconnectBase: 5 * time.Second,
+		reconnectMax:  5 * time.Minute,
 	}
 }
 
+// SetFaultInjector enables simulated Slack rate limiting. It applies from the
+// next (re)start of the client.
+func (m *Manager) SetFaultInjector(f *faultinject.Injector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = f
+}
+
 // GetClient returns the current Slack client (may be nil if not configured)
 func (m *Manager) GetClient() *slack.Client {
 	m.mu.RLock()
@@ -57,13 +96,66 @@ func (m *Manager) GetSocketClient() *socketmode.Client {
 	return m.socketClient
 }
 
-// IsRunning returns true if Socket Mode is currently active
+// SetSocketModeEnabled turns Socket Mode ownership on or off (on by
+// default). Slack delivers each event to one Socket Mode connection of the
+// app, so in a multi-replica deployment only the leader enables it; the
+// change applies from the next Start or Reload.
+func (m *Manager) SetSocketModeEnabled(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.socketMode = enabled
+}
+
+// SetAPIURL points the Web API client at another base URL, such as a
+// Slack-compatible gateway or a test stub. It applies from the next
+// Start/Reload.
+func (m *Manager) SetAPIURL(apiURL string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiURL = apiURL
+}
+
+// IsRunning returns true if the Slack client is currently active
 func (m *Manager) IsRunning() bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.running
 }
 
+// Socket Mode states reported by SocketState.
+const (
+	SocketStateDisabled   = "disabled"   // Slack is not running
+	SocketStateWebAPIOnly = "web_api"    // Socket Mode is owned by another replica
+	SocketStateConnecting = "connecting" // Socket Mode is (re)connecting
+	SocketStateConnected  = "connected"
+)
+
+// SocketState reports the state of the Socket Mode connection.
+func (m *Manager) SocketState() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	switch {
+	case !m.running:
+		return SocketStateDisabled
+	case m.socketClient == nil:
+		return SocketStateWebAPIOnly
+	case m.socketConnected:
+		return SocketStateConnected
+	}
+	return SocketStateConnecting
+}
+
+// SetSocketConnected records a connection state change reported on the
+// event stream of sc. Reports from a client replaced by a reload are
+// ignored.
+func (m *Manager) SetSocketConnected(sc *socketmode.Client, connected bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sc == m.socketClient {
+		m.socketConnected = connected
+	}
+}
+
 // SetEventHandler sets the function that will handle socket mode events
 // The handler receives both the socket mode client and the regular Slack client
 func (m *Manager) SetEventHandler(handler func(*socketmode.Client, *slack.Client)) {
@@ -104,26 +196,42 @@ func (m *Manager) startWithSettings(ctx context.Context, settings *database.Slac
 		slack.OptionDebug(false),
 		slack.OptionAppLevelToken(settings.AppToken),
 	)
+	if m.apiURL != "" {
+		options = append(options, slack.OptionAPIURL(m.apiURL))
+	}
 
 	// Check proxy settings for Slack
+	var transport http.RoundTripper
 	if proxySettings, err := database.GetOrCreateProxySettings(); err == nil && proxySettings != nil {
 		if proxySettings.ProxyURL != "" && proxySettings.SlackEnabled {
 			proxyURL, parseErr := url.Parse(proxySettings.ProxyURL)
 			if parseErr == nil {
-				httpClient := &http.Client{
-					Transport: &http.Transport{
-						Proxy: http.ProxyURL(proxyURL),
-					},
+				transport = &http.Transport{
+					Proxy: http.ProxyURL(proxyURL),
 				}
-				options = append(options, slack.OptionHTTPClient(httpClient))
 				slog.Info("SlackManager: using proxy", "proxy_url", proxySettings.ProxyURL)
 			}
 		}
 	}
+	if wrapped := m.faults.WrapSlackTransport(transport); wrapped != transport {
+		transport = wrapped
+		slog.Warn("SlackManager: failure injection enabled", "rate_limit_rate", m.faults.Rate(faultinject.SlackRateLimit))
+	}
+	// Outermost so injected 429s exercise the same retry path as real ones.
+	options = append(options, slack.OptionHTTPClient(&http.Client{
+		Transport: NewThrottledTransport(transport, DefaultThrottleConfig),
+	}))
 
 	// Create new Slack client
 	m.client = slack.New(settings.BotToken, options...)
 
+	if !m.socketMode {
+		m.doneChan = nil
+		m.running = true
+		slog.Info("SlackManager: Slack Web API client is active (Socket Mode owned by another replica)")
+		return nil
+	}
+
 	// Build Socket Mode options
 	socketOptions := []socketmode.Option{
 		socketmode.OptionDebug(false),
@@ -147,6 +255,7 @@ func (m *Manager) startWithSettings(ctx context.Context, settings *database.Slac
 
 	// Create Socket Mode client
 	m.socketClient = socketmode.New(m.client, socketOptions...)
+	m.socketConnected = false
 
 	// Create a child context so we can cancel just this connection's RunContext
 	connCtx, connCancel := context.WithCancel(ctx)
@@ -170,14 +279,20 @@ func (m *Manager) startWithSettings(ctx context.Context, settings *database.Slac
 		defer close(doneChan)
 		slog.Info("SlackManager: starting Socket Mode connection")
 
-		if err := sc.RunContext(connCtx); err != nil {
-			// Check if context was cancelled (graceful shutdown)
-			if connCtx.Err() != nil {
-				slog.Info("SlackManager: Socket Mode stopped gracefully")
-			} else {
-				slog.Error("SlackManager: Socket Mode error", "error", err)
-			}
+		started := time.Now()
+		err := sc.RunContext(connCtx)
+		// Check if context was cancelled (graceful shutdown or reload)
+		if connCtx.Err() != nil {
+			slog.Info("SlackManager: Socket Mode stopped gracefully")
+			return
 		}
+		if err != nil {
+			slog.Error("SlackManager: Socket Mode error", "error", err)
+		}
+		// Socket Mode gave up on its own (bad token, Slack unreachable at
+		// boot, ...). Reconnect through the normal reload path so the event
+		// handler is rebuilt against fresh clients.
+		go m.scheduleReconnect(connCtx, time.Since(started))
 	}()
 
 	m.running = true
@@ -216,6 +331,7 @@ func (m *Manager) stopLocked() {
 	}
 
 	m.running = false
+	m.socketConnected = false
 	m.cancelFunc = nil
 	m.client = nil
 	m.socketClient = nil
@@ -242,6 +358,40 @@ func (m *Manager) Reload(ctx context.Context) error {
 	return m.startWithSettings(ctx, settings)
 }
 
+// reconnectDelay returns the backoff before the given (zero-based)
+// consecutive reconnect attempt.
+func (m *Manager) reconnectDelay(attempt int) time.Duration {
+	delay := m.reconnectBase
+	for i := 0; i < attempt && delay < m.reconnectMax; i++ {
+		delay *= 2
+	}
+	if delay > m.reconnectMax {
+		delay = m.reconnectMax
+	}
+	return delay
+}
+
+// scheduleReconnect waits out the backoff and then triggers a reload. ctx is
+// the dead connection's context: a reload or Stop in the meantime cancels it
+// and the pending reconnect is dropped.
+func (m *Manager) scheduleReconnect(ctx context.Context, uptime time.Duration) {
+	if uptime >= reconnectResetAfter {
+		m.reconnectAttempts.Store(0)
+	}
+	attempt := int(m.reconnectAttempts.Add(1)) - 1
+	delay := m.reconnectDelay(attempt)
+	slog.Warn("SlackManager: Socket Mode disconnected, scheduling reconnect", "attempt", attempt+1, "delay", delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+	m.TriggerReload()
+}
+
 // TriggerReload signals that a reload is needed (non-blocking)
 func (m *Manager) TriggerReload() {
 	select {
@@ -290,3 +440,32 @@ func (m *Manager) WatchForReloads(ctx context.Context) {
 		}
 	}
 }
+
+// WatchSettings polls the Slack settings row and triggers a reload when it
+// changes. TriggerReload only reaches the replica that served the settings
+// update, so this is how the other replicas pick up new credentials.
+func (m *Manager) WatchSettings(ctx context.Context, interval time.Duration) {
+	var last time.Time
+	if settings, err := database.GetSlackSettings(); err == nil {
+		last = settings.UpdatedAt
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settings, err := database.GetSlackSettings()
+			if err != nil {
+				continue
+			}
+			if !settings.UpdatedAt.Equal(last) {
+				last = settings.UpdatedAt
+				slog.Info("SlackManager: settings changed on another replica")
+				m.TriggerReload()
+			}
+		}
+	}
+}
@@ -200,3 +200,60 @@ func TestManager_StateConsistency(t *testing.T) {
 		t.Error("manager should have nil client after Stop")
 	}
 }
+
+func TestManager_ReconnectDelay(t *testing.T) {
+	m := NewManager()
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 10 * time.Second},
+		{3, 40 * time.Second},
+		{6, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := m.reconnectDelay(tt.attempt); got != tt.want {
+			t.Errorf("reconnectDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestManager_ScheduleReconnect_TriggersReload(t *testing.T) {
+	m := NewManager()
+	m.reconnectBase = time.Millisecond
+
+	m.scheduleReconnect(context.Background(), 0)
+
+	select {
+	case <-m.reloadChan:
+	default:
+		t.Fatal("expected scheduleReconnect to trigger a reload")
+	}
+	if got := m.reconnectAttempts.Load(); got != 1 {
+		t.Errorf("reconnectAttempts = %d, want 1", got)
+	}
+
+	// A connection that stayed up long enough resets the backoff.
+	m.reconnectAttempts.Store(4)
+	m.scheduleReconnect(context.Background(), reconnectResetAfter)
+	if got := m.reconnectAttempts.Load(); got != 1 {
+		t.Errorf("reconnectAttempts after long-lived connection = %d, want 1", got)
+	}
+}
+
+func TestManager_ScheduleReconnect_DroppedOnCancel(t *testing.T) {
+	m := NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m.scheduleReconnect(ctx, 0)
+
+	select {
+	case <-m.reloadChan:
+		t.Error("reconnect should be dropped once the connection context is cancelled")
+	default:
+	}
+}
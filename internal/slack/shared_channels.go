@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sharedChannelTimeout bounds each Redis round trip so a slow Redis
+// degrades channel resolution to a Slack API lookup instead of stalling an
+// alert post.
+const sharedChannelTimeout = 500 * time.Millisecond
+
+// sharedChannelTTL bounds how long the shared map lives without a write.
+// Renames and deletions keep it current while events arrive; the TTL covers
+// changes made while no replica was listening.
+const sharedChannelTTL = 24 * time.Hour
+
+// SharedChannelCache is a channel name -> ID map shared by API replicas.
+// When a resolver has one it replaces the in-process map, so a rename seen
+// by the replica holding the Slack socket is visible to every replica.
+type SharedChannelCache interface {
+	Lookup(ctx context.Context, name string) (id string, ok bool, err error)
+	Store(ctx context.Context, names map[string]string) error
+	// Rename maps newName to id and drops, and returns, the other names
+	// id was cached under.
+	Rename(ctx context.Context, id, newName string) ([]string, error)
+	Forget(ctx context.Context, id string) error
+	Delete(ctx context.Context, name string) error
+	Clear(ctx context.Context) error
+}
+
+// RedisChannelCache keeps the channel map in a single Redis hash.
+type RedisChannelCache struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisChannelCache stores the channel map in the hash at key.
+func NewRedisChannelCache(client *redis.Client, key string) *RedisChannelCache {
+	return &RedisChannelCache{client: client, key: key}
+}
+
+// Lookup implements SharedChannelCache.
+func (c *RedisChannelCache) Lookup(ctx context.Context, name string) (string, bool, error) {
+	id, err := c.client.HGet(ctx, c.key, name).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return id, true, nil
+}
+
+// Store implements SharedChannelCache.
+func (c *RedisChannelCache) Store(ctx context.Context, names map[string]string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, c.key, names)
+	pipe.Expire(ctx, c.key, sharedChannelTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Rename implements SharedChannelCache.
+func (c *RedisChannelCache) Rename(ctx context.Context, id, newName string) ([]string, error) {
+	old, err := c.namesFor(ctx, id, newName)
+	if err != nil {
+		return nil, err
+	}
+	pipe := c.client.TxPipeline()
+	if len(old) > 0 {
+		pipe.HDel(ctx, c.key, old...)
+	}
+	if newName != "" {
+		pipe.HSet(ctx, c.key, newName, id)
+		pipe.Expire(ctx, c.key, sharedChannelTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// Forget implements SharedChannelCache.
+func (c *RedisChannelCache) Forget(ctx context.Context, id string) error {
+	names, err := c.namesFor(ctx, id, "")
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	return c.client.HDel(ctx, c.key, names...).Err()
+}
+
+// Delete implements SharedChannelCache.
+func (c *RedisChannelCache) Delete(ctx context.Context, name string) error {
+	return c.client.HDel(ctx, c.key, name).Err()
+}
+
+// Clear implements SharedChannelCache.
+func (c *RedisChannelCache) Clear(ctx context.Context) error {
+	return c.client.Del(ctx, c.key).Err()
+}
+
+// namesFor returns the names mapped to id, except keep.
+func (c *RedisChannelCache) namesFor(ctx context.Context, id, keep string) ([]string, error) {
+	all, err := c.client.HGetAll(ctx, c.key).Result()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, cached := range all {
+		if cached == id && name != keep {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
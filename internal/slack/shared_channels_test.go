@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/slack-go/slack"
+)
+
+func newSharedResolvers(t *testing.T, lister ConversationLister) (*ChannelResolver, *ChannelResolver, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	a := &ChannelResolver{client: lister, cache: make(map[string]string)}
+	a.SetSharedCache(NewRedisChannelCache(rdb, "test:slack_channels"))
+	b := &ChannelResolver{client: lister, cache: make(map[string]string)}
+	b.SetSharedCache(NewRedisChannelCache(rdb, "test:slack_channels"))
+	return a, b, mr
+}
+
+func TestChannelResolver_SharedCacheAcrossReplicas(t *testing.T) {
+	fake := &fakeConversationLister{
+		public: [][]slack.Channel{{namedChannel("C0000000001", "general"), namedChannel("C0000000002", "alerts")}},
+	}
+	a, b, mr := newSharedResolvers(t, fake)
+
+	if id, err := a.ResolveChannel("#alerts"); err != nil || id != "C0000000002" {
+		t.Fatalf("ResolveChannel = %q, %v", id, err)
+	}
+	if mr.TTL("test:slack_channels") <= 0 {
+		t.Error("shared channel map should expire")
+	}
+
+	// The other replica resolves from the shared map without calling Slack.
+	if id, err := b.ResolveChannel("general"); err != nil || id != "C0000000001" {
+		t.Fatalf("replica b = %q, %v", id, err)
+	}
+	if fake.publicCalls != 1 {
+		t.Errorf("publicCalls = %d, want 1", fake.publicCalls)
+	}
+
+	// A rename handled by one replica is seen by the other.
+	if old := a.HandleRename("C0000000002", "alerts-prod"); len(old) != 1 || old[0] != "alerts" {
+		t.Errorf("old names = %v, want [alerts]", old)
+	}
+	if id, _ := b.ResolveChannel("alerts-prod"); id != "C0000000002" {
+		t.Errorf("renamed channel on b = %q", id)
+	}
+	if mr.HGet("test:slack_channels", "alerts") != "" {
+		t.Error("old name should be evicted from the shared map")
+	}
+
+	b.Forget("C0000000002")
+	b.Invalidate("#general")
+	if keys, _ := mr.HKeys("test:slack_channels"); len(keys) != 0 {
+		t.Errorf("shared map after forget/invalidate = %v", keys)
+	}
+
+	a.HandleRename("C0000000003", "ops")
+	a.ClearCache()
+	if mr.Exists("test:slack_channels") {
+		t.Error("ClearCache should drop the shared map")
+	}
+}
+
+func TestChannelResolver_SharedCacheDownFallsBackToSlack(t *testing.T) {
+	fake := &fakeConversationLister{
+		public: [][]slack.Channel{{namedChannel("C0000000002", "alerts")}},
+	}
+	a, _, mr := newSharedResolvers(t, fake)
+	mr.Close()
+
+	if id, err := a.ResolveChannel("alerts"); err != nil || id != "C0000000002" {
+		t.Fatalf("ResolveChannel = %q, %v", id, err)
+	}
+	if fake.publicCalls != 1 {
+		t.Errorf("publicCalls = %d, want a Slack lookup", fake.publicCalls)
+	}
+}
@@ -0,0 +1,243 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleConfig tunes the Slack Web API transport built by
+// NewThrottledTransport.
+type ThrottleConfig struct {
+	// MaxRetries is how many times a call is retried after a 429 (or, for
+	// read methods, a 5xx or network error) before the failure is returned.
+	MaxRetries int
+	// BaseBackoff and MaxBackoff bound the exponential backoff used when
+	// Slack sends no Retry-After. Each wait gets up to 50% random jitter.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxWait caps the total time one call may spend queued and backing off.
+	MaxWait time.Duration
+}
+
+// DefaultThrottleConfig rides out the rate limiting Slack applies during an
+// alert storm without holding any single message for more than a few minutes.
+var DefaultThrottleConfig = ThrottleConfig{
+	MaxRetries:  5,
+	BaseBackoff: time.Second,
+	MaxBackoff:  30 * time.Second,
+	MaxWait:     3 * time.Minute,
+}
+
+// ThrottledTransport is an http.RoundTripper for the slack-go client that
+// absorbs Slack rate limiting instead of surfacing it to callers:
+//
+//   - A 429 pauses every call to that API method until Retry-After has
+//     passed, then the call is retried. Slack rate-limits per method, so
+//     other methods keep flowing.
+//   - Write methods (chat.*, reactions.*, files.*) go through a per-method
+//     send queue, one call in flight at a time, so a burst of notifications
+//     is sent in order rather than racing into the limit.
+//   - Read methods are also retried on 5xx and network errors. Writes are
+//     not, since Slack may have applied them.
+//
+// Callers keep using *slack.Client unchanged; a call only fails once its
+// retries or MaxWait are spent, with the last Slack response.
+type ThrottledTransport struct {
+	next http.RoundTripper
+	cfg  ThrottleConfig
+
+	mu      sync.Mutex
+	methods map[string]*methodThrottle
+
+	// sleep waits for d or until ctx is done; replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+type methodThrottle struct {
+	queue       chan struct{} // send queue for write methods; nil for reads
+	pausedUntil time.Time     // guarded by ThrottledTransport.mu
+}
+
+// NewThrottledTransport wraps next (http.DefaultTransport when nil). Zero
+// fields in cfg take their DefaultThrottleConfig values.
+func NewThrottledTransport(next http.RoundTripper, cfg ThrottleConfig) *ThrottledTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultThrottleConfig.MaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultThrottleConfig.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultThrottleConfig.MaxBackoff
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = DefaultThrottleConfig.MaxWait
+	}
+	return &ThrottledTransport{
+		next:    next,
+		cfg:     cfg,
+		methods: make(map[string]*methodThrottle),
+		sleep:   sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ThrottledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := slackAPIMethod(req.URL.Path)
+	throttle := t.throttle(method)
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.cfg.MaxWait)
+	defer cancel()
+
+	body, err := bufferBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if throttle.queue != nil {
+		select {
+		case throttle.queue <- struct{}{}:
+			defer func() { <-throttle.queue }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.sleep(ctx, t.pauseRemaining(throttle)); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req.Clone(req.Context())
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+
+		wait, retry := t.retryAfter(method, throttle, resp, err, attempt)
+		if !retry || attempt >= t.cfg.MaxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		slog.Warn("slack: retrying throttled call", "method", method, "attempt", attempt+1, "wait", wait, "err", err)
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter decides whether a finished attempt is retried and how long to
+// wait first. A 429 also pauses the whole method.
+func (t *ThrottledTransport) retryAfter(method string, throttle *methodThrottle, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	switch {
+	case err != nil:
+		return t.backoff(attempt), !isSlackWriteMethod(method)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		wait := t.backoff(attempt)
+		if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs >= 0 {
+			wait = time.Duration(secs)*time.Second + jitter(time.Second)
+		}
+		t.pause(throttle, wait)
+		return 0, true
+	case resp.StatusCode >= 500:
+		return t.backoff(attempt), !isSlackWriteMethod(method)
+	}
+	return 0, false
+}
+
+func (t *ThrottledTransport) backoff(attempt int) time.Duration {
+	d := t.cfg.BaseBackoff << attempt
+	if d <= 0 || d > t.cfg.MaxBackoff {
+		d = t.cfg.MaxBackoff
+	}
+	return d + jitter(d/2)
+}
+
+func (t *ThrottledTransport) throttle(method string) *methodThrottle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	mt, ok := t.methods[method]
+	if !ok {
+		mt = &methodThrottle{}
+		if isSlackWriteMethod(method) {
+			mt.queue = make(chan struct{}, 1)
+		}
+		t.methods[method] = mt
+	}
+	return mt
+}
+
+func (t *ThrottledTransport) pause(mt *methodThrottle, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(mt.pausedUntil) {
+		mt.pausedUntil = until
+	}
+}
+
+func (t *ThrottledTransport) pauseRemaining(mt *methodThrottle) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Until(mt.pausedUntil)
+}
+
+// slackAPIMethod returns the Web API method of a request path, e.g.
+// "chat.postMessage" for /api/chat.postMessage.
+func slackAPIMethod(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+func isSlackWriteMethod(method string) bool {
+	for _, prefix := range []string{"chat.", "reactions.", "files."} {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func bufferBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
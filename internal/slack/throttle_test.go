@@ -0,0 +1,139 @@
+package slack
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func slackResponse(status int, retryAfter string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(`{"ok":true}`))}
+}
+
+// newTestThrottle returns a transport whose waits are recorded, not slept.
+func newTestThrottle(next roundTripFunc) (*ThrottledTransport, *[]time.Duration) {
+	var mu sync.Mutex
+	var waits []time.Duration
+	tr := NewThrottledTransport(next, ThrottleConfig{MaxRetries: 2})
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		if d > 0 {
+			mu.Lock()
+			waits = append(waits, d)
+			mu.Unlock()
+		}
+		return ctx.Err()
+	}
+	return tr, &waits
+}
+
+func postForm(t *testing.T, tr http.RoundTripper, method, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, "https://slack.com/api/"+method, strings.NewReader(body))
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	return resp
+}
+
+func TestThrottledTransport_RetriesRateLimitWithBody(t *testing.T) {
+	var bodies []string
+	tr, waits := newTestThrottle(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			return slackResponse(http.StatusTooManyRequests, "3"), nil
+		}
+		return slackResponse(http.StatusOK, ""), nil
+	})
+
+	resp := postForm(t, tr, "chat.postMessage", "channel=C1&text=hi")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after retry", resp.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[1] != "channel=C1&text=hi" {
+		t.Errorf("bodies = %q, want the form replayed", bodies)
+	}
+	if len(*waits) != 1 || (*waits)[0] < 2*time.Second || (*waits)[0] > 4*time.Second {
+		t.Errorf("waits = %v, want ~3s from Retry-After", *waits)
+	}
+	if tr.pauseRemaining(tr.throttle("reactions.add")) > 0 {
+		t.Error("a 429 on chat.postMessage paused reactions.add")
+	}
+}
+
+func TestThrottledTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	tr, _ := newTestThrottle(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return slackResponse(http.StatusTooManyRequests, "1"), nil
+	})
+	if resp := postForm(t, tr, "reactions.add", ""); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want the last 429", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 1 + 2 retries", calls)
+	}
+}
+
+func TestThrottledTransport_RetriesServerErrorsOnlyForReads(t *testing.T) {
+	calls := map[string]int{}
+	tr, _ := newTestThrottle(func(req *http.Request) (*http.Response, error) {
+		method := slackAPIMethod(req.URL.Path)
+		calls[method]++
+		if calls[method] == 1 {
+			return slackResponse(http.StatusBadGateway, ""), nil
+		}
+		return slackResponse(http.StatusOK, ""), nil
+	})
+
+	if resp := postForm(t, tr, "conversations.list", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("read status = %d, want 200 after retry", resp.StatusCode)
+	}
+	if resp := postForm(t, tr, "chat.update", ""); resp.StatusCode != http.StatusBadGateway || calls["chat.update"] != 1 {
+		t.Errorf("write: status = %d after %d calls, want 502 without retry", resp.StatusCode, calls["chat.update"])
+	}
+}
+
+func TestThrottledTransport_QueuesWrites(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	tr, _ := newTestThrottle(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return slackResponse(http.StatusOK, ""), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			postForm(t, tr, "chat.postMessage", "")
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 1 {
+		t.Errorf("max in flight = %d, want 1", maxInFlight)
+	}
+}
@@ -0,0 +1,249 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ========================================
+// Fake Agent Worker
+// ========================================
+
+// AgentTask is an investigation run the API dispatched to a FakeAgentWorker.
+type AgentTask struct {
+	Type          string   `json:"type"` // new_incident | continue_incident
+	IncidentID    string   `json:"incident_id"`
+	RunID         string   `json:"run_id"`
+	Task          string   `json:"task"`
+	Message       string   `json:"message"`
+	Provider      string   `json:"provider"`
+	Model         string   `json:"model"`
+	APIKey        string   `json:"api_key"`
+	BaseURL       string   `json:"base_url"`
+	EnabledSkills []string `json:"enabled_skills"`
+}
+
+// Prompt returns the text the run was started with: the task of a new
+// incident or the message of a continuation.
+func (a AgentTask) Prompt() string {
+	if a.Task != "" {
+		return a.Task
+	}
+	return a.Message
+}
+
+// agentFrame is the subset of the /ws/agent wire format the fake worker
+// reads and writes.
+type agentFrame struct {
+	AgentTask
+	Output          string                 `json:"output,omitempty"`
+	SessionID       string                 `json:"session_id,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	Data            map[string]interface{} `json:"data,omitempty"`
+	TokensUsed      int                    `json:"tokens_used,omitempty"`
+	ExecutionTimeMs int64                  `json:"execution_time_ms,omitempty"`
+	RequestID       string                 `json:"request_id,omitempty"`
+	System          string                 `json:"system,omitempty"`
+	User            string                 `json:"user,omitempty"`
+	Summary         string                 `json:"summary,omitempty"`
+}
+
+// FakeAgentWorker connects to the API's /ws/agent endpoint the way the
+// agent-worker container does and completes every run it is given. A run
+// is answered by the OpenAI-compatible provider named in its LLM settings
+// (see OpenAIServer), so the LLM settings plumbing is exercised too; runs
+// without a base URL complete with a fixed response. One-shot LLM requests
+// are answered the same way.
+type FakeAgentWorker struct {
+	t    *testing.T
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	tasks   []AgentTask
+	failure string
+	done    chan struct{}
+}
+
+// DialAgentWorker connects a fake worker to wsURL (ws://.../ws/agent) and
+// announces it ready. The connection is closed at test cleanup.
+func DialAgentWorker(t *testing.T, wsURL string) *FakeAgentWorker {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("fake agent worker: dial %s: %v", wsURL, err)
+	}
+	w := &FakeAgentWorker{t: t, conn: conn, done: make(chan struct{})}
+	w.send(agentFrame{AgentTask: AgentTask{Type: "status"}, Data: map[string]interface{}{
+		"status": "ready", "worker_id": "fake-worker", "capacity": 4,
+	}})
+	go w.readLoop()
+	t.Cleanup(w.Close)
+	return w
+}
+
+// WithFailure makes every later run end with agent_error msg.
+func (w *FakeAgentWorker) WithFailure(msg string) *FakeAgentWorker {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failure = msg
+	return w
+}
+
+// Tasks returns the runs dispatched so far, oldest first.
+func (w *FakeAgentWorker) Tasks() []AgentTask {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]AgentTask(nil), w.tasks...)
+}
+
+// WaitForTask waits until a run for incidentID has been dispatched.
+func (w *FakeAgentWorker) WaitForTask(t *testing.T, incidentID string, timeout time.Duration) AgentTask {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, task := range w.Tasks() {
+			if task.IncidentID == incidentID {
+				return task
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fake agent worker: no run for incident %s within %v", incidentID, timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Close disconnects the worker.
+func (w *FakeAgentWorker) Close() {
+	w.conn.Close()
+	<-w.done
+}
+
+func (w *FakeAgentWorker) readLoop() {
+	defer close(w.done)
+	for {
+		var msg agentFrame
+		if err := w.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case "new_incident", "continue_incident":
+			w.mu.Lock()
+			w.tasks = append(w.tasks, msg.AgentTask)
+			failure := w.failure
+			w.mu.Unlock()
+			go w.run(msg.AgentTask, failure)
+		case "oneshot_llm_request":
+			go w.oneshot(msg)
+		}
+	}
+}
+
+func (w *FakeAgentWorker) run(task AgentTask, failure string) {
+	start := time.Now()
+	base := agentFrame{AgentTask: AgentTask{IncidentID: task.IncidentID, RunID: task.RunID}}
+
+	progress := base
+	progress.Type = "agent_output"
+	progress.Output = "Investigating with the fake agent worker...\n"
+	w.send(progress)
+
+	result := base
+	if failure != "" {
+		result.Type = "agent_error"
+		result.Error = failure
+		w.send(result)
+		return
+	}
+	output, tokens := "Investigation complete.", 0
+	if task.BaseURL != "" {
+		var err error
+		if output, tokens, err = complete(task.BaseURL, task.APIKey, task.Model, "", task.Prompt()); err != nil {
+			result.Type = "agent_error"
+			result.Error = err.Error()
+			w.send(result)
+			return
+		}
+	}
+	result.Type = "agent_completed"
+	result.SessionID = "session-" + task.IncidentID
+	result.Output = output
+	result.TokensUsed = tokens
+	result.ExecutionTimeMs = time.Since(start).Milliseconds()
+	w.send(result)
+}
+
+func (w *FakeAgentWorker) oneshot(req agentFrame) {
+	resp := agentFrame{AgentTask: AgentTask{Type: "oneshot_llm_response"}, RequestID: req.RequestID}
+	if req.BaseURL == "" {
+		resp.Error = "no LLM configured"
+	} else if summary, _, err := complete(req.BaseURL, req.APIKey, req.Model, req.System, req.User); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Summary = summary
+	}
+	w.send(resp)
+}
+
+func (w *FakeAgentWorker) send(frame agentFrame) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if err := w.conn.WriteJSON(frame); err != nil {
+		w.t.Logf("fake agent worker: write %s: %v", frame.Type, err)
+	}
+}
+
+// complete runs one chat completion against an OpenAI-compatible baseURL
+// and returns the reply and total tokens.
+func complete(baseURL, apiKey, model, system, user string) (string, int, error) {
+	var messages []ChatMessage
+	if system != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: system})
+	}
+	messages = append(messages, ChatMessage{Role: "user", Content: user})
+	body, err := json.Marshal(map[string]interface{}{"model": model, "messages": messages})
+	if err != nil {
+		return "", 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("LLM provider returned HTTP %d", resp.StatusCode)
+	}
+	var out struct {
+		Choices []struct {
+			Message ChatMessage `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", 0, err
+	}
+	if len(out.Choices) == 0 {
+		return "", 0, fmt.Errorf("LLM provider returned no choices")
+	}
+	return out.Choices[0].Message.Content, out.Usage.TotalTokens, nil
+}
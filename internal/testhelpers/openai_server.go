@@ -0,0 +1,99 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ========================================
+// OpenAI-compatible LLM Stub
+// ========================================
+
+// ChatMessage is one message of a chat completion request.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest is a chat completion request received by an OpenAIServer.
+type ChatRequest struct {
+	Model         string        `json:"model"`
+	Messages      []ChatMessage `json:"messages"`
+	Authorization string        `json:"-"`
+}
+
+// OpenAIServer is an httptest server answering POST /v1/chat/completions
+// like an OpenAI-compatible provider, with a fixed reply. Configure LLM
+// settings with BaseURL() as the provider base URL.
+type OpenAIServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	reply      string
+	tokensUsed int
+	requests   []ChatRequest
+}
+
+// NewOpenAIServer starts an LLM stub that is closed at test cleanup.
+func NewOpenAIServer(t *testing.T) *OpenAIServer {
+	t.Helper()
+	s := &OpenAIServer{reply: "Root cause: stub analysis.", tokensUsed: 42}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// BaseURL returns the OpenAI-style base URL, ending in /v1.
+func (s *OpenAIServer) BaseURL() string {
+	return s.URL + "/v1"
+}
+
+// WithReply sets the assistant message every completion returns.
+func (s *OpenAIServer) WithReply(reply string) *OpenAIServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reply = reply
+	return s
+}
+
+// Requests returns the completion requests received, oldest first.
+func (s *OpenAIServer) Requests() []ChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChatRequest(nil), s.requests...)
+}
+
+func (s *OpenAIServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		http.Error(w, `{"error":{"message":"not found"}}`, http.StatusNotFound)
+		return
+	}
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":{"message":"invalid JSON"}}`, http.StatusBadRequest)
+		return
+	}
+	req.Authorization = r.Header.Get("Authorization")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	reply, tokens := s.reply, s.tokensUsed
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     "chatcmpl-stub",
+		"object": "chat.completion",
+		"model":  req.Model,
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"message":       map[string]string{"role": "assistant", "content": reply},
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]int{"prompt_tokens": tokens / 2, "completion_tokens": tokens - tokens/2, "total_tokens": tokens},
+	})
+}
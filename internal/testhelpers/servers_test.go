@@ -0,0 +1,106 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackServer_RecordsCallsAndAnswersPostMessage(t *testing.T) {
+	s := NewSlackServer(t)
+
+	resp, err := http.PostForm(s.APIURL()+"chat.postMessage", url.Values{"channel": {"C1"}, "text": {"disk full on db-01"}})
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["ok"] != true || body["channel"] != "C1" || body["ts"] == "" {
+		t.Errorf("response = %v, want ok with channel and ts", body)
+	}
+
+	call := s.WaitForMessage(t, "db-01", time.Second)
+	if call.Params.Get("channel") != "C1" {
+		t.Errorf("channel = %q, want C1", call.Params.Get("channel"))
+	}
+	if len(s.Calls("reactions.add")) != 0 {
+		t.Error("expected no reactions.add calls")
+	}
+}
+
+func TestOpenAIServer_RepliesAndRecordsRequests(t *testing.T) {
+	s := NewOpenAIServer(t).WithReply("it was DNS")
+
+	reply, tokens, err := complete(s.BaseURL(), "sk-test", "gpt-test", "be brief", "why?")
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if reply != "it was DNS" || tokens != 42 {
+		t.Errorf("reply, tokens = %q, %d", reply, tokens)
+	}
+	reqs := s.Requests()
+	if len(reqs) != 1 || reqs[0].Model != "gpt-test" || reqs[0].Authorization != "Bearer sk-test" || len(reqs[0].Messages) != 2 {
+		t.Errorf("requests = %+v", reqs)
+	}
+}
+
+func TestZabbixServer_DispatchesJSONRPC(t *testing.T) {
+	s := NewZabbixServer(t, "7.0.0").
+		HandleResult("host.get", []map[string]string{{"hostid": "10084"}}).
+		Handle("host.delete", func(json.RawMessage) (interface{}, error) { return nil, errors.New("no permissions") })
+
+	call := func(method string) map[string]interface{} {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": map[string]string{}, "id": 1})
+		req, _ := http.NewRequest(http.MethodPost, s.APIURL(), bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		defer resp.Body.Close()
+		var out map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("%s: decode: %v", method, err)
+		}
+		return out
+	}
+
+	if got := call("apiinfo.version"); got["result"] != "7.0.0" {
+		t.Errorf("apiinfo.version = %v", got)
+	}
+	if got := call("host.get"); got["result"] == nil {
+		t.Errorf("host.get = %v, want a result", got)
+	}
+	if got := call("host.delete"); !strings.Contains(toJSON(t, got["error"]), "no permissions") {
+		t.Errorf("host.delete = %v, want the handler error", got)
+	}
+	if got := call("item.get"); !strings.Contains(toJSON(t, got["error"]), "-32601") {
+		t.Errorf("item.get = %v, want method not found", got)
+	}
+
+	calls := s.Calls("host.get")
+	if len(calls) != 1 || calls[0].Auth != "Bearer token" {
+		t.Errorf("host.get calls = %+v", calls)
+	}
+	if n := len(s.Calls("")); n != 4 {
+		t.Errorf("total calls = %d, want 4", n)
+	}
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,136 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ========================================
+// Slack Web API Stub
+// ========================================
+
+// SlackCall is one Web API request received by a SlackServer.
+type SlackCall struct {
+	Method string     // e.g. "chat.postMessage"
+	Params url.Values // form fields, or the top-level fields of a JSON body
+}
+
+// SlackServer is an httptest server standing in for the Slack Web API. Every
+// method answers {"ok":true}; chat.postMessage and chat.update also return
+// the channel and a fresh message ts, so threads can be followed. Point a
+// slack client at it with slack.OptionAPIURL(server.APIURL()).
+type SlackServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	calls     []SlackCall
+	responses map[string]map[string]interface{}
+	nextTS    int
+}
+
+// NewSlackServer starts a Slack stub that is closed at test cleanup.
+func NewSlackServer(t *testing.T) *SlackServer {
+	t.Helper()
+	s := &SlackServer{responses: make(map[string]map[string]interface{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// APIURL returns the base URL to configure Slack clients with.
+func (s *SlackServer) APIURL() string {
+	return s.URL + "/api/"
+}
+
+// WithResponse sets the fields method answers with, merged over {"ok":true}.
+func (s *SlackServer) WithResponse(method string, fields map[string]interface{}) *SlackServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method] = fields
+	return s
+}
+
+// Calls returns the requests received for method, oldest first. An empty
+// method returns every request.
+func (s *SlackServer) Calls(method string) []SlackCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []SlackCall
+	for _, c := range s.calls {
+		if method == "" || c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// WaitForMessage waits until a chat.postMessage whose text contains substr
+// arrives and returns it.
+func (s *SlackServer) WaitForMessage(t *testing.T, substr string, timeout time.Duration) SlackCall {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, c := range s.Calls("chat.postMessage") {
+			if strings.Contains(c.Params.Get("text"), substr) {
+				return c
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no Slack message containing %q within %v; got %+v", substr, timeout, s.Calls("chat.postMessage"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *SlackServer) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	params := url.Values{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var body map[string]interface{}
+		data, _ := io.ReadAll(r.Body)
+		if json.Unmarshal(data, &body) == nil {
+			for k, v := range body {
+				if str, ok := v.(string); ok {
+					params.Set(k, str)
+				} else if encoded, err := json.Marshal(v); err == nil {
+					params.Set(k, string(encoded))
+				}
+			}
+		}
+	} else {
+		_ = r.ParseForm()
+		params = r.Form
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, SlackCall{Method: method, Params: params})
+	resp := map[string]interface{}{"ok": true}
+	switch method {
+	case "chat.postMessage", "chat.update":
+		ts := params.Get("ts")
+		if method == "chat.postMessage" {
+			s.nextTS++
+			ts = fmt.Sprintf("1700000000.%06d", s.nextTS)
+		}
+		resp["channel"] = params.Get("channel")
+		resp["ts"] = ts
+	case "auth.test":
+		resp["user_id"] = "UBOT"
+		resp["team_id"] = "T0001"
+	}
+	for k, v := range s.responses[method] {
+		resp[k] = v
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -351,6 +351,41 @@ func LoadJSONFixture(t testing.TB, path string, v interface{}) {
 	}
 }
 
+// UpdateGoldenEnv names the environment variable that makes AssertGoldenJSON
+// rewrite golden files instead of comparing against them.
+const UpdateGoldenEnv = "AKMATORI_UPDATE_GOLDEN"
+
+// AssertGoldenJSON compares v, encoded as indented JSON, with the golden file
+// at path under tests/fixtures/. Run the test with AKMATORI_UPDATE_GOLDEN=1
+// to write the current output as the new golden file.
+func AssertGoldenJSON(t testing.TB, path string, v interface{}) {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode golden value for %s: %v", path, err)
+	}
+	got = append(got, '\n')
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		goldenFile, err := fixturePath(path)
+		if err != nil {
+			t.Fatalf("failed to resolve golden file %s: %v", path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(goldenFile, got, 0o644); err != nil {
+			t.Fatalf("failed to write golden file %s: %v", path, err)
+		}
+		fixtureCache.Delete(goldenFile)
+		return
+	}
+
+	if want := LoadFixture(t, path); !bytes.Equal(want, got) {
+		t.Errorf("output differs from golden file %s (rerun with %s=1 to update)\n--- want\n%s\n--- got\n%s", path, UpdateGoldenEnv, want, got)
+	}
+}
+
 // ========================================
 // Sample Data Builders
 // ========================================
@@ -0,0 +1,115 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// ========================================
+// Zabbix JSON-RPC Stub
+// ========================================
+
+// ZabbixCall is one JSON-RPC request received by a ZabbixServer.
+type ZabbixCall struct {
+	Method string
+	Params json.RawMessage
+	Auth   string // Authorization header or the legacy "auth" field
+}
+
+// ZabbixHandler answers one JSON-RPC method. A non-nil error is returned to
+// the client as a JSON-RPC error.
+type ZabbixHandler func(params json.RawMessage) (interface{}, error)
+
+// ZabbixServer is an httptest server speaking the Zabbix JSON-RPC API.
+// apiinfo.version, user.login and user.logout answer out of the box; other
+// methods need a handler from Handle and otherwise fail with "method not
+// found".
+type ZabbixServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]ZabbixHandler
+	calls    []ZabbixCall
+}
+
+// NewZabbixServer starts a Zabbix stub reporting version that is closed at
+// test cleanup.
+func NewZabbixServer(t *testing.T, version string) *ZabbixServer {
+	t.Helper()
+	s := &ZabbixServer{handlers: map[string]ZabbixHandler{
+		"apiinfo.version": func(json.RawMessage) (interface{}, error) { return version, nil },
+		"user.login":      func(json.RawMessage) (interface{}, error) { return "zabbix-session", nil },
+		"user.logout":     func(json.RawMessage) (interface{}, error) { return true, nil },
+	}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// APIURL returns the JSON-RPC endpoint, /api_jsonrpc.php.
+func (s *ZabbixServer) APIURL() string {
+	return s.URL + "/api_jsonrpc.php"
+}
+
+// Handle sets the handler for method.
+func (s *ZabbixServer) Handle(method string, handler ZabbixHandler) *ZabbixServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+	return s
+}
+
+// HandleResult makes method always return result.
+func (s *ZabbixServer) HandleResult(method string, result interface{}) *ZabbixServer {
+	return s.Handle(method, func(json.RawMessage) (interface{}, error) { return result, nil })
+}
+
+// Calls returns the requests received for method, oldest first. An empty
+// method returns every request.
+func (s *ZabbixServer) Calls(method string) []ZabbixCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []ZabbixCall
+	for _, c := range s.calls {
+		if method == "" || c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (s *ZabbixServer) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Auth   string          `json:"auth"`
+		ID     interface{}     `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		auth = req.Auth
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, ZabbixCall{Method: req.Method, Params: req.Params, Auth: auth})
+	handler := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if handler == nil {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found.", "data": req.Method}
+	} else if result, err := handler(req.Params); err != nil {
+		resp["error"] = map[string]interface{}{"code": -32500, "message": "Application error.", "data": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json-rpc")
+	_ = json.NewEncoder(w).Encode(resp)
+}
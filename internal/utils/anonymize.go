@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hostTLDs are the final labels that make a dotted name a hostname. A fixed
+// list keeps file names (app.log, nginx.conf) and metric keys
+// (system.cpu.util) out of the host pseudonyms; AddHost adds the final label
+// of each registered hostname, so private domains outside the list are
+// caught too.
+var hostTLDs = []string{
+	"com", "net", "org", "io", "dev", "app", "cloud", "tech", "info", "biz", "co", "ai",
+	"local", "localdomain", "internal", "lan", "corp", "intra", "intranet", "private", "home", "svc", "arpa",
+	"us", "uk", "eu", "de", "fr", "nl", "ru", "ca", "au", "jp", "cn", "br", "ch", "se", "pl", "es", "it",
+}
+
+// anonymizePatterns is the fixed part of the Anonymizer pattern. Each
+// alternative is one capture group (two for user@host and home dirs), so
+// the first matching alternative decides how a match is replaced.
+var anonymizePatterns = []string{
+	`\b([a-z_][a-z0-9._-]*)@([a-z0-9][a-z0-9.-]*[a-z0-9])`, // user@host, emails
+	`(/home/|/Users/)([a-z_][a-z0-9._-]*)`,                 // home directories
+	`\b((?:\d{1,3}\.){3}\d{1,3})\b`,                        // IPv4
+	`\b([0-9a-f]{1,4}(?::[0-9a-f]{0,4}){2,7})\b`,           // IPv6 (validated on match)
+}
+
+// hostPattern is the dotted-hostname alternative that follows
+// anonymizePatterns, for the given final labels.
+func hostPattern(tlds []string) string {
+	return `\b((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+(?:` + strings.Join(tlds, "|") + `))\b`
+}
+
+// tldPattern matches final labels AddHost may add to hostTLDs. Top-level
+// domains are letters only; allowing digits would turn versions (1.v2)
+// into hosts.
+var tldPattern = regexp.MustCompile(`^[a-z]{2,63}$`)
+
+// genericNames are account and host names too common to identify anyone;
+// replacing them would mangle text like "root cause".
+var genericNames = map[string]bool{
+	"root": true, "admin": true, "administrator": true, "nobody": true, "www-data": true,
+	"postgres": true, "ubuntu": true, "ec2-user": true, "centos": true, "debian": true,
+	"localhost": true, "localhost.localdomain": true,
+}
+
+// Anonymizer replaces hostnames, IP addresses and usernames with consistent
+// pseudonyms (host-1, 192.0.2.1, user-1), so a transcript shared outside
+// the company still reads coherently without revealing infrastructure.
+// Dotted hostnames, IPs, user@host pairs, emails and home directories are
+// found by pattern; bare names such as "web-01" only when registered with
+// AddHost or AddUser, and names under an unlisted top-level domain only once
+// a host under it was. Loopback and unspecified addresses are kept. One
+// Anonymizer should cover everything shared together so a host gets the
+// same pseudonym everywhere. Safe for concurrent use.
+type Anonymizer struct {
+	mu      sync.Mutex
+	hosts   map[string]string
+	users   map[string]string
+	ips     map[string]string
+	known   map[string]bool // registered bare names, lower-cased
+	tlds    map[string]bool // final labels of registered hosts not in hostTLDs
+	pattern *regexp.Regexp  // nil when known changed since the last build
+	nextIP4 int
+	nextIP6 int
+}
+
+// NewAnonymizer returns an empty Anonymizer.
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{
+		hosts: make(map[string]string),
+		users: make(map[string]string),
+		ips:   make(map[string]string),
+		known: make(map[string]bool),
+		tlds:  make(map[string]bool),
+	}
+}
+
+// AddHost registers a hostname to replace wherever it appears as a whole
+// word, and its top-level domain as one that makes dotted names hosts. A
+// trailing :port is ignored; IP addresses need no registration.
+func (a *Anonymizer) AddHost(name string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if h, _, err := net.SplitHostPort(name); err == nil {
+		name = h
+	}
+	if !a.registrable(name) || net.ParseIP(name) != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pseudonym(a.hosts, "host", name)
+	a.addKnown(name)
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		if tld := name[i+1:]; tldPattern.MatchString(tld) && !a.tlds[tld] && !slices.Contains(hostTLDs, tld) {
+			a.tlds[tld] = true
+			a.pattern = nil
+		}
+	}
+}
+
+// AddUser registers a username to replace wherever it appears as a whole
+// word.
+func (a *Anonymizer) AddUser(name string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !a.registrable(name) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pseudonym(a.users, "user", name)
+	a.addKnown(name)
+}
+
+func (a *Anonymizer) registrable(name string) bool {
+	return len(name) >= 3 && !genericNames[name]
+}
+
+func (a *Anonymizer) addKnown(name string) {
+	if !a.known[name] {
+		a.known[name] = true
+		a.pattern = nil
+	}
+}
+
+// Text returns text with every hostname, IP and username replaced.
+func (a *Anonymizer) Text(text string) string {
+	if text == "" {
+		return text
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pattern := a.compile()
+	matches := pattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m[0]])
+		b.WriteString(a.replace(text, m))
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Value anonymizes every string in a decoded JSON value (maps, slices and
+// strings; keys are kept) and returns the copy.
+func (a *Anonymizer) Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return a.Text(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = a.Value(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = a.Value(val)
+		}
+		return out
+	}
+	return v
+}
+
+// compile returns the pattern, rebuilding it after new names were added.
+// Registered names go last and longest first, so "web-01.prod" wins over
+// "web-01".
+func (a *Anonymizer) compile() *regexp.Regexp {
+	if a.pattern != nil {
+		return a.pattern
+	}
+	tlds := append([]string(nil), hostTLDs...)
+	for tld := range a.tlds {
+		tlds = append(tlds, regexp.QuoteMeta(tld))
+	}
+	sort.Strings(tlds[len(hostTLDs):])
+	alternatives := append(append([]string(nil), anonymizePatterns...), hostPattern(tlds))
+	if len(a.known) > 0 {
+		names := make([]string, 0, len(a.known))
+		for name := range a.known {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if len(names[i]) != len(names[j]) {
+				return len(names[i]) > len(names[j])
+			}
+			return names[i] < names[j]
+		})
+		alternatives = append(alternatives, `\b(`+strings.Join(names, "|")+`)\b`)
+	}
+	a.pattern = regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+	return a.pattern
+}
+
+// replace returns the pseudonym text for one match; m holds the submatch
+// indexes in anonymizePatterns order, then the hostname and the registered
+// names.
+func (a *Anonymizer) replace(text string, m []int) string {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return text[m[2*i]:m[2*i+1]]
+	}
+	switch {
+	case group(1) != "": // user@host
+		return a.user(group(1)) + "@" + a.host(group(2))
+	case group(3) != "": // home directory
+		return group(3) + a.user(group(4))
+	case group(5) != "":
+		return a.ip(group(5))
+	case group(6) != "":
+		return a.ip(group(6))
+	case group(7) != "":
+		return a.host(group(7))
+	}
+	name := strings.ToLower(group(8))
+	if p, ok := a.users[name]; ok {
+		return p
+	}
+	return a.host(name)
+}
+
+func (a *Anonymizer) host(name string) string {
+	name = strings.ToLower(name)
+	if genericNames[name] {
+		return name
+	}
+	return a.pseudonym(a.hosts, "host", name)
+}
+
+func (a *Anonymizer) user(name string) string {
+	name = strings.ToLower(name)
+	if genericNames[name] {
+		return name
+	}
+	return a.pseudonym(a.users, "user", name)
+}
+
+func (a *Anonymizer) pseudonym(names map[string]string, prefix, name string) string {
+	if p, ok := names[name]; ok {
+		return p
+	}
+	p := fmt.Sprintf("%s-%d", prefix, len(names)+1)
+	names[name] = p
+	return p
+}
+
+// ip maps addresses into the documentation ranges (RFC 5737, RFC 3849), so
+// pseudonyms stay valid addresses that cannot belong to anyone.
+func (a *Anonymizer) ip(addr string) string {
+	parsed := net.ParseIP(addr)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsUnspecified() || parsed.Equal(net.IPv4bcast) {
+		return addr
+	}
+	key := parsed.String()
+	if p, ok := a.ips[key]; ok {
+		return p
+	}
+	var p string
+	if parsed.To4() != nil {
+		a.nextIP4++
+		n := a.nextIP4
+		switch {
+		case n <= 254:
+			p = fmt.Sprintf("192.0.2.%d", n)
+		case n <= 508:
+			p = fmt.Sprintf("198.51.100.%d", n-254)
+		case n <= 762:
+			p = fmt.Sprintf("203.0.113.%d", n-508)
+		default:
+			p = fmt.Sprintf("ip-%d", n)
+		}
+	} else {
+		a.nextIP6++
+		p = fmt.Sprintf("2001:db8::%x", a.nextIP6)
+	}
+	a.ips[key] = p
+	return p
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnonymizer_Text(t *testing.T) {
+	a := NewAnonymizer()
+	a.AddHost("web-01:9100")
+	a.AddUser("jsmith")
+	a.AddUser("root") // too generic to register
+
+	got := a.Text("ssh deploy@web-01 from 10.1.2.3; WEB-01 pinged 10.1.2.3 and db-01.prod.example.com\n" +
+		"mail jsmith@corp.example.com, read /home/jsmith/app.log and nginx.conf (system.cpu.util)\n" +
+		"root cause at 12:30:45 on 127.0.0.1, ::1 and fe80::1ff:fe23:4567:890a")
+
+	want := "ssh user-2@host-1 from 192.0.2.1; host-1 pinged 192.0.2.1 and host-2\n" +
+		"mail user-1@host-3, read /home/user-1/app.log and nginx.conf (system.cpu.util)\n" +
+		"root cause at 12:30:45 on 127.0.0.1, ::1 and 2001:db8::1"
+	if got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAnonymizer_ConsistentAcrossCalls(t *testing.T) {
+	a := NewAnonymizer()
+	first := a.Text("10.0.0.1 then 10.0.0.2")
+	second := a.Value(map[string]interface{}{
+		"labels": map[string]interface{}{"instance": "10.0.0.2:9100"},
+		"hosts":  []interface{}{"10.0.0.1", 42.0},
+	}).(map[string]interface{})
+
+	if first != "192.0.2.1 then 192.0.2.2" {
+		t.Errorf("first = %q", first)
+	}
+	if second["labels"].(map[string]interface{})["instance"] != "192.0.2.2:9100" {
+		t.Errorf("labels = %v", second["labels"])
+	}
+	if hosts := second["hosts"].([]interface{}); hosts[0] != "192.0.2.1" || hosts[1] != 42.0 {
+		t.Errorf("hosts = %v", hosts)
+	}
+}
+
+func TestAnonymizer_LongestKnownNameWins(t *testing.T) {
+	a := NewAnonymizer()
+	a.AddHost("api")
+	a.AddHost("api-gateway")
+	if got := a.Text("api-gateway calls api"); strings.Count(got, "host-") != 2 || got == "host-1 calls host-1" {
+		t.Errorf("Text() = %q, want distinct pseudonyms", got)
+	}
+}
+
+func TestAnonymizer_RegisteredHostDomain(t *testing.T) {
+	a := NewAnonymizer()
+	if got := a.Text("db-02.zone.acme and app.log"); got != "db-02.zone.acme and app.log" {
+		t.Fatalf("unlisted TLD matched before registration: %q", got)
+	}
+	a.AddHost("web-01.prod.acme")
+	a.AddHost("build-7.v2") // a final label with digits is no TLD
+	got := a.Text("web-01.prod.acme called db-02.zone.acme and acme.example.acme; read app.log, release 1.v2")
+	want := "host-1 called host-3 and host-4; read app.log, release 1.v2"
+	if got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
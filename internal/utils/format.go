@@ -130,16 +130,74 @@ func CleanLLMResponse(response string) string {
 	return response
 }
 
+// RunMetrics is what the completion footer reports about an investigation.
+// ToolCalls and CostUSD are optional; a nil CostUSD means no price applied.
+type RunMetrics struct {
+	ExecutionTime time.Duration
+	TokensUsed    int
+	ToolCalls     int
+	CostUSD       *float64
+}
+
 // AppendMetrics adds execution metrics to the end of a response
 func AppendMetrics(response string, executionTime time.Duration, tokensUsed int) string {
-	timeStr := FormatDuration(executionTime)
+	return AppendRunMetrics(response, RunMetrics{ExecutionTime: executionTime, TokensUsed: tokensUsed})
+}
 
-	var metricsLine string
-	if tokensUsed > 0 {
-		metricsLine = fmt.Sprintf("\n\n---\n⏱️ Time: %s | 🎯 Tokens: %s", timeStr, FormatNumber(tokensUsed))
-	} else {
-		metricsLine = fmt.Sprintf("\n\n---\n⏱️ Time: %s", timeStr)
+// AppendRunMetrics adds the metrics footer to the end of a response.
+// Example: "⏱️ Time: 2m 5s | 🎯 Tokens: 48,210 | 🛠️ Tool calls: 12 | 💰 Est. cost: $0.31"
+func AppendRunMetrics(response string, m RunMetrics) string {
+	parts := []string{"⏱️ Time: " + FormatDuration(m.ExecutionTime)}
+	if m.TokensUsed > 0 {
+		parts = append(parts, "🎯 Tokens: "+FormatNumber(m.TokensUsed))
+	}
+	if m.ToolCalls > 0 {
+		parts = append(parts, "🛠️ Tool calls: "+FormatNumber(m.ToolCalls))
+	}
+	if m.CostUSD != nil {
+		parts = append(parts, "💰 Est. cost: "+FormatCostUSD(*m.CostUSD))
 	}
+	return response + "\n\n---\n" + strings.Join(parts, " | ")
+}
+
+// FormatCostUSD formats a dollar estimate with cent precision, showing
+// amounts below a cent as "<$0.01".
+func FormatCostUSD(cost float64) string {
+	if cost > 0 && cost < 0.005 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
 
-	return response + metricsLine
+// CountToolCalls counts the finished tool executions in an agent log. The
+// agent worker writes one "✅ Ran: <tool>" or "❌ Failed: <tool>" line per
+// tool call.
+func CountToolCalls(log string) int {
+	count := 0
+	for _, line := range strings.Split(log, "\n") {
+		if strings.HasPrefix(line, "✅ Ran: ") || strings.HasPrefix(line, "❌ Failed: ") {
+			count++
+		}
+	}
+	return count
+}
+
+// ToolCall is one finished tool execution recorded in an agent log.
+type ToolCall struct {
+	Command string
+	Failed  bool
+}
+
+// ParseToolCalls returns the finished tool executions in an agent log in
+// order, from the same "✅ Ran:" / "❌ Failed:" lines CountToolCalls counts.
+func ParseToolCalls(log string) []ToolCall {
+	var calls []ToolCall
+	for _, line := range strings.Split(log, "\n") {
+		if cmd, ok := strings.CutPrefix(line, "✅ Ran: "); ok {
+			calls = append(calls, ToolCall{Command: cmd})
+		} else if cmd, ok := strings.CutPrefix(line, "❌ Failed: "); ok {
+			calls = append(calls, ToolCall{Command: cmd, Failed: true})
+		}
+	}
+	return calls
 }
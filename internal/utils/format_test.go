@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -223,3 +224,37 @@ func TestAppendMetrics(t *testing.T) {
 		}
 	})
 }
+
+func TestAppendRunMetrics(t *testing.T) {
+	cost := 0.3142
+	result := AppendRunMetrics("output", RunMetrics{ExecutionTime: 5 * time.Second, TokensUsed: 48210, ToolCalls: 12, CostUSD: &cost})
+	want := "output\n\n---\n⏱️ Time: 5.0s | 🎯 Tokens: 48,210 | 🛠️ Tool calls: 12 | 💰 Est. cost: $0.31"
+	if result != want {
+		t.Errorf("AppendRunMetrics() = %q; want %q", result, want)
+	}
+
+	tiny := 0.001
+	result = AppendRunMetrics("output", RunMetrics{ExecutionTime: 5 * time.Second, TokensUsed: 100, CostUSD: &tiny})
+	if !strings.HasSuffix(result, "🎯 Tokens: 100 | 💰 Est. cost: <$0.01") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
+
+func TestCountToolCalls(t *testing.T) {
+	log := "🛠️ Running: ssh\n\n✅ Ran: ssh\nArgs:\n{}\nOutput:\nok\n\n❌ Failed: zabbix\n\n✅ Ran: read\nsaid ✅ Ran: inline\n"
+	if got := CountToolCalls(log); got != 3 {
+		t.Errorf("CountToolCalls() = %d; want 3", got)
+	}
+	if got := CountToolCalls(""); got != 0 {
+		t.Errorf("CountToolCalls(\"\") = %d; want 0", got)
+	}
+}
+
+func TestParseToolCalls(t *testing.T) {
+	log := "🛠️ Running: ssh\n\n✅ Ran: ssh uptime\nOutput:\nok\n\n❌ Failed: zabbix\nsaid ✅ Ran: inline\n"
+	got := ParseToolCalls(log)
+	want := []ToolCall{{Command: "ssh uptime"}, {Command: "zabbix", Failed: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseToolCalls() = %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ansiPattern matches terminal escape sequences: CSI (colours, cursor
+// movement), OSC (window titles, hyperlinks; terminated by BEL or ST) and
+// the two-byte escapes.
+var ansiPattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// SanitizeLog normalizes agent output before it is stored or served. Logs
+// arrive from the agent worker as whatever the tools printed: terminal
+// colour codes, carriage-return progress bars, the odd invalid byte, and
+// emoji decoded with the wrong charset somewhere upstream (📋 showing up as
+// "üìã" or "ðŸ“‹"). Mis-decoded emoji and punctuation are repaired,
+// invalid UTF-8 becomes U+FFFD, terminal escape sequences are removed,
+// carriage returns become newlines, and other control characters except tab
+// are dropped. SanitizeLog is idempotent.
+func SanitizeLog(s string) string {
+	if isCleanLog(s) {
+		return s
+	}
+	s = strings.ToValidUTF8(s, "�")
+	s = ansiPattern.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = repairMojibake(s)
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isCleanLog is the fast path for the common case of printable ASCII.
+func isCleanLog(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf || (c < 0x20 && c != '\n' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// StripEmoji removes emoji (with their modifiers and joiners) from s, along
+// with one space following each, so "✅ Done" becomes "Done".
+func StripEmoji(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	skipSpace := false
+	for _, r := range s {
+		if IsEmoji(r) {
+			skipSpace = true
+			continue
+		}
+		if skipSpace && r == ' ' {
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// PlainTextLog is SanitizeLog followed by StripEmoji, for exports read
+// outside a browser.
+func PlainTextLog(s string) string {
+	return StripEmoji(SanitizeLog(s))
+}
+
+// IsEmoji reports whether r is an emoji or part of an emoji sequence
+// (variation selector, zero-width joiner, keycap, skin tone, tag).
+func IsEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, flags, skin tones
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // misc symbols and dingbats (✅ ❌ ⚠)
+		return true
+	case r >= 0x231A && r <= 0x23FF && r != 0x2328 && (r <= 0x231B || r >= 0x23E9): // ⌚ ⏱ ⏳
+		return true
+	case r == 0x2B50 || r == 0x2B55 || r == 0x2B1B || r == 0x2B1C: // ⭐ ⭕ ⬛ ⬜
+		return true
+	case r == 0x200D || r == 0xFE0F || r == 0xFE0E || r == 0x20E3:
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tag sequences (subdivision flags)
+		return true
+	}
+	return false
+}
+
+// mojibakeCharsets are the single-byte charsets UTF-8 text is most often
+// mis-decoded as: Windows-1252 ("ðŸ“‹") and Mac OS Roman ("üìã").
+var mojibakeCharsets = []*charmap.Charmap{charmap.Windows1252, charmap.Macintosh}
+
+// repairMojibake re-encodes runs of characters that are really the bytes of
+// a three- or four-byte UTF-8 sequence (emoji, typographic punctuation,
+// arrows) and decodes them properly. Two-byte sequences are left alone:
+// "Ã©" is rare but legitimate text, and accented letters are what a
+// false repair would damage.
+func repairMojibake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	changed := false
+	for i := 0; i < len(runes); {
+		if r, n := decodeMojibake(runes[i:]); n > 0 {
+			b.WriteRune(r)
+			i += n
+			changed = true
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	if !changed {
+		return s
+	}
+	return b.String()
+}
+
+// decodeMojibake returns the rune encoded by the mis-decoded bytes at the
+// start of runes and how many runes they span, or n=0 when there is none.
+func decodeMojibake(runes []rune) (rune, int) {
+	for _, cm := range mojibakeCharsets {
+		lead, ok := cm.EncodeRune(runes[0])
+		if !ok || lead < 0xE0 || lead > 0xF4 {
+			continue
+		}
+		size := 3
+		if lead >= 0xF0 {
+			size = 4
+		}
+		if len(runes) < size {
+			continue
+		}
+		buf := []byte{lead}
+		for _, r := range runes[1:size] {
+			c, ok := cm.EncodeRune(r)
+			if !ok || c < 0x80 || c > 0xBF {
+				break
+			}
+			buf = append(buf, c)
+		}
+		if len(buf) != size {
+			continue
+		}
+		if r, n := utf8.DecodeRune(buf); r != utf8.RuneError && n == size {
+			return r, size
+		}
+	}
+	return 0, 0
+}
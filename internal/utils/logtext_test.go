@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestSanitizeLog(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"ascii is untouched", "plain log\n\tindented", "plain log\n\tindented"},
+		{"ansi colours", "\x1b[1;31mERROR\x1b[0m disk full", "ERROR disk full"},
+		{"osc hyperlink", "\x1b]8;;https://x\x07link\x1b]8;;\x07", "link"},
+		{"crlf and progress", "a\r\nb\rc", "a\nb\nc"},
+		{"control characters", "bell\x07 nul\x00", "bell nul"},
+		{"invalid utf-8", "bad \xff byte", "bad � byte"},
+		{"windows-1252 emoji", "ðŸ“‹ Plan", "📋 Plan"},
+		{"mac roman emoji", "üìã Plan", "📋 Plan"},
+		{"windows-1252 quote", "itâ€™s", "it’s"},
+		{"accented text is kept", "São Paulo, naïve, Ã©", "São Paulo, naïve, Ã©"},
+		{"real emoji are kept", "✅ done 🎯", "✅ done 🎯"},
+	}
+	for _, tc := range cases {
+		got := SanitizeLog(tc.in)
+		if got != tc.want {
+			t.Errorf("%s: SanitizeLog(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+		if again := SanitizeLog(got); again != got {
+			t.Errorf("%s: SanitizeLog is not idempotent: %q -> %q", tc.name, got, again)
+		}
+	}
+}
+
+func TestStripEmoji(t *testing.T) {
+	cases := map[string]string{
+		"✅ Done":                     "Done",
+		"⏱️ Time: 3s | 🎯 Tokens: 10": "Time: 3s | Tokens: 10",
+		"👩‍💻 on call":                "on call",
+		"no emoji → arrow":           "no emoji → arrow",
+	}
+	for in, want := range cases {
+		if got := StripEmoji(in); got != want {
+			t.Errorf("StripEmoji(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import "regexp"
+
+// RedactedPlaceholder replaces every secret RedactSecrets finds.
+const RedactedPlaceholder = "[REDACTED]"
+
+// secretPatterns match credentials that commonly end up in agent logs:
+// provider tokens with a recognizable shape, private key blocks, URL
+// userinfo and key=value assignments. Patterns with a capture group keep
+// their groups (the key name, the URL scheme and user) and redact only the
+// value between them.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                   // AWS access key ID
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bgithub_pat_[A-Za-z0-9_]{22,}\b`), // GitHub
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                                 // Slack
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                         // OpenAI / Anthropic
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                                       // Google API key
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), // JWT
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/=-]{16,}`),
+	regexp.MustCompile(`(\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:)[^\s@/]+(@)`),
+	regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|secret[_-]?key|client[_-]?secret|auth[_-]?token)["']?\s*[:=]\s*["']?)[^\s"',;]+`),
+}
+
+// RedactSecrets replaces credentials in text with RedactedPlaceholder. It is
+// pattern based, so it catches well-known token formats and labelled
+// values, not arbitrary secrets.
+func RedactSecrets(text string) string {
+	for _, pattern := range secretPatterns {
+		if pattern.NumSubexp() > 0 {
+			text = pattern.ReplaceAllString(text, "${1}"+RedactedPlaceholder+"${2}")
+		} else {
+			text = pattern.ReplaceAllString(text, RedactedPlaceholder)
+		}
+	}
+	return text
+}
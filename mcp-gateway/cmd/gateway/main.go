@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,10 +12,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/akmatori/mcp-gateway/internal/approvals"
 	"github.com/akmatori/mcp-gateway/internal/auth"
+	"github.com/akmatori/mcp-gateway/internal/cache"
+	"github.com/akmatori/mcp-gateway/internal/credentials"
 	"github.com/akmatori/mcp-gateway/internal/database"
 	"github.com/akmatori/mcp-gateway/internal/mcp"
 	"github.com/akmatori/mcp-gateway/internal/mcpproxy"
+	"github.com/akmatori/mcp-gateway/internal/metrics"
+	"github.com/akmatori/mcp-gateway/internal/requestid"
+	"github.com/akmatori/mcp-gateway/internal/sandbox"
+	"github.com/akmatori/mcp-gateway/internal/secretrefs"
+	"github.com/akmatori/mcp-gateway/internal/secrets"
 	"github.com/akmatori/mcp-gateway/internal/tools"
 	"gorm.io/gorm/logger"
 )
@@ -24,9 +34,18 @@ const (
 )
 
 func main() {
-	// Setup structured logging
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	slog.SetDefault(slog.New(handler))
+	// Setup structured logging, JSON unless LOG_FORMAT=text, at LOG_LEVEL.
+	// Records logged with a request context carry its request_id.
+	level, levelErr := logLevel(os.Getenv("LOG_LEVEL"))
+	logOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, logOpts)
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("LOG_FORMAT")), "text") {
+		handler = slog.NewTextHandler(os.Stdout, logOpts)
+	}
+	slog.SetDefault(slog.New(requestid.NewLogHandler(handler)))
+	if levelErr != nil {
+		slog.Warn("ignoring LOG_LEVEL", "err", levelErr)
+	}
 
 	slog.Info("starting MCP Gateway")
 
@@ -42,6 +61,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Tool credentials are stored encrypted when the API has an encryption
+	// key; the gateway needs the same keys to read them
+	encryptionKey, err := envOrFile("SECRETS_ENCRYPTION_KEY")
+	if err != nil {
+		slog.Error("failed to read encryption key", "err", err)
+		os.Exit(1)
+	}
+	var previousKeys []string
+	for _, key := range strings.Split(os.Getenv("SECRETS_ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			previousKeys = append(previousKeys, key)
+		}
+	}
+	keyring, err := secrets.LoadKeyring(encryptionKey, previousKeys)
+	if err != nil {
+		slog.Error("invalid secrets encryption key", "err", err)
+		os.Exit(1)
+	}
+	database.SetSecretKeyring(keyring)
+
 	// Connect to database
 	slog.Info("connecting to database")
 	if err := database.Connect(databaseURL, logger.Warn); err != nil {
@@ -50,6 +89,23 @@ func main() {
 	}
 	slog.Info("database connected")
 
+	// Optional Redis: tool response, MCP schema and allowlist caches write
+	// through to it so they survive restarts and are shared by replicas.
+	// Credential caches always stay in-process.
+	var sharedStore cache.Store
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		store, err := cache.NewRedisStore(ctx, redisURL, "akmatori:gateway:")
+		cancel()
+		if err != nil {
+			slog.Error("failed to connect to Redis", "err", err)
+			os.Exit(1)
+		}
+		cache.SetSharedStore(store)
+		sharedStore = store
+		slog.Info("shared Redis cache enabled")
+	}
+
 	// Bridge slog to *log.Logger for internal packages that still accept it
 	stdLogger := slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo)
 
@@ -73,14 +129,51 @@ func main() {
 	// Start periodic schema refresh for MCP proxy connections (every 5 min)
 	proxyHandler.StartSchemaRefreshLoop(mcpproxy.DefaultSchemaRefreshInterval)
 
+	// Write calls to destructive tools wait for a human to approve them
+	approver := approvals.NewApprover(stdLogger)
+	server.SetToolApprover(approver.Approve, approvals.MaxWait)
+
+	// Sandbox mode: answer tool calls from fixtures instead of real systems
+	if sandboxEnabled(os.Getenv("MCP_SANDBOX")) {
+		fixtureDir := os.Getenv("MCP_SANDBOX_FIXTURES")
+		fixtures, err := sandbox.Load(fixtureDir)
+		if err != nil {
+			slog.Error("failed to load sandbox fixtures", "dir", fixtureDir, "err", err)
+			os.Exit(1)
+		}
+		server.SetToolInterceptor(fixtures.Intercept)
+		slog.Warn("sandbox mode: tool calls return canned fixture results", "fixture_dir", fixtureDir, "fixtures", len(fixtures.Tools()))
+	}
+
 	// Wire up tool discovery (search/detail JSON-RPC methods)
 	server.SetDiscoverer(registry)
 	server.SetInstanceLookup(tools.BuildInstanceLookup())
 
 	// Wire up per-incident tool authorization with 1-hour TTL (matches typical incident lifetime)
 	authorizer := auth.NewAuthorizer(1 * time.Hour)
+	if sharedStore != nil {
+		authorizer.SetStore(sharedStore)
+	}
 	server.SetAuthorizer(authorizer)
 
+	// Ephemeral credentials: instances with a credential_provider get leases
+	// minted on demand and revoked once their incidents finish
+	credManager := credentials.NewManager(database.IsIncidentActive)
+	credManager.Register(credentials.NewPostgresRoleProvider())
+	credManager.Register(credentials.NewBrokerProvider(nil))
+	credManager.Start(time.Minute)
+	database.SetCredentialMinter(credManager)
+
+	// Secret references (vault:..., aws-sm:...) in tool settings are
+	// resolved at call time from the stores configured here
+	secretResolver, err := newSecretResolver()
+	if err != nil {
+		slog.Error("failed to configure secret stores", "err", err)
+		os.Exit(1)
+	}
+	secretResolver.Start(time.Minute)
+	database.SetSecretResolver(secretResolver)
+
 	// Setup HTTP handlers
 	mux := http.NewServeMux()
 
@@ -97,6 +190,9 @@ func main() {
 		w.Write([]byte(`{"status":"healthy"}`))
 	})
 
+	// Prometheus metrics: tool calls, cache hit ratios, rate limiting, HTTP latency
+	mux.Handle("/metrics", metrics.Handler())
+
 	// MCP proxy connections health check
 	mux.HandleFunc("/health/mcp-connections", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -203,11 +299,89 @@ func main() {
 		authorizer.Stop()
 		proxyHandler.GracefulShutdown()
 		registry.Stop()
+		revokeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		credManager.Stop(revokeCtx)
+		secretResolver.Stop()
+		cancel()
 		os.Exit(0)
 	}()
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
+	if err := http.ListenAndServe(addr, metrics.InstrumentHTTP(mux, requestid.Middleware(mux))); err != nil {
 		slog.Error("server error", "err", err)
 		os.Exit(1)
 	}
 }
+
+// logLevel parses LOG_LEVEL, the same values the API server accepts. Empty
+// means info; on error the returned level is info too.
+func logLevel(v string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", v)
+}
+
+// sandboxEnabled reports whether MCP_SANDBOX turns sandbox mode on.
+func sandboxEnabled(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// envOrFile returns the environment variable name, or the trimmed contents
+// of the file named by name_FILE when the variable is unset.
+func envOrFile(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// newSecretResolver registers the secret stores configured in the
+// environment: Vault with VAULT_ADDR and VAULT_TOKEN (or VAULT_TOKEN_FILE),
+// and AWS Secrets Manager with static AWS_* credentials.
+func newSecretResolver() (*secretrefs.Resolver, error) {
+	resolver := secretrefs.NewResolver()
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		token, err := envOrFile("VAULT_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("VAULT_ADDR is set without VAULT_TOKEN")
+		}
+		resolver.Register("vault", secretrefs.NewVaultBackend(addr, token, os.Getenv("VAULT_NAMESPACE"), nil))
+		slog.Info("vault secret references enabled", "addr", addr)
+	}
+	if keyID := os.Getenv("AWS_ACCESS_KEY_ID"); keyID != "" {
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		creds := secretrefs.AWSCredentials{
+			AccessKeyID:     keyID,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		resolver.Register("aws-sm", secretrefs.NewAWSSecretsManagerBackend(region, os.Getenv("AWS_SECRETS_MANAGER_ENDPOINT"), creds, nil))
+		slog.Info("AWS Secrets Manager secret references enabled", "region", region)
+	}
+	return resolver, nil
+}
@@ -0,0 +1,203 @@
+// Package approvals holds write calls to destructive tools until a human
+// approves or rejects them in the incident's Slack thread or the web UI.
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+	"github.com/google/uuid"
+)
+
+// Approval waits. The tool call is held for the whole wait, so the server
+// adds MaxWait to the timeout of destructive calls.
+const (
+	DefaultWait = 5 * time.Minute
+	MaxWait     = 10 * time.Minute
+	minWait     = 30 * time.Second
+
+	pollInterval = 2 * time.Second
+)
+
+// Gate holds a destructive call until a human approves or rejects it, or
+// wait runs out. It returns the request as finally decided; a request
+// nobody decided comes back with status "expired".
+type Gate interface {
+	Await(ctx context.Context, req *database.ToolApproval, wait time.Duration) (*database.ToolApproval, error)
+}
+
+// dbGate records the request in the main API's tool_approvals table (which
+// posts it to Slack) and polls it for the decision.
+type dbGate struct{}
+
+func (dbGate) Await(ctx context.Context, req *database.ToolApproval, wait time.Duration) (*database.ToolApproval, error) {
+	if database.DB == nil {
+		return nil, errors.New("approval gate unavailable: no database connection")
+	}
+	req.UUID = uuid.NewString()
+	req.Status = "pending"
+	req.ExpiresAt = time.Now().Add(wait)
+	if err := database.CreateToolApproval(ctx, req); err != nil {
+		return nil, fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// The call is gone (timeout or the run was cancelled); make sure
+			// nobody approves something that will never run.
+			_, _ = database.ExpireToolApproval(context.WithoutCancel(ctx), req.UUID)
+			return nil, ctx.Err()
+		case <-deadline.C:
+			expired, err := database.ExpireToolApproval(ctx, req.UUID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to expire approval request: %w", err)
+			}
+			if expired {
+				req.Status = "expired"
+				return req, nil
+			}
+			// A decision landed just before the deadline.
+			return database.GetToolApproval(ctx, req.UUID)
+		case <-ticker.C:
+			current, err := database.GetToolApproval(ctx, req.UUID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read approval request: %w", err)
+			}
+			if current.Status != "pending" {
+				return current, nil
+			}
+		}
+	}
+}
+
+// InstanceLookup finds the tool instance a call addresses.
+type InstanceLookup func(ctx context.Context, incidentID, toolType string, instanceID *uint, logicalName string) (*database.ToolCredentials, error)
+
+// Approver implements mcp.ToolApprover. Whether a call is held depends on
+// the require_write_approval setting of the instance it addresses, which is
+// on unless the instance turns it off.
+type Approver struct {
+	logger *log.Logger
+	gate   Gate
+	lookup InstanceLookup
+}
+
+// NewApprover creates an Approver backed by the tool_approvals table.
+func NewApprover(logger *log.Logger) *Approver {
+	return &Approver{
+		logger: logger,
+		gate:   dbGate{},
+		lookup: database.LookupToolCredentials,
+	}
+}
+
+// Approve holds a write call until it is decided. It returns nil when the
+// call may go ahead and otherwise an error the agent sees as the tool result.
+func (a *Approver) Approve(ctx context.Context, tool mcp.Tool, incidentID string, args map[string]interface{}) error {
+	toolType, _ := mcp.ParseToolName(tool.Name)
+	var instanceID *uint
+	if v, ok := args["tool_instance_id"].(float64); ok && v > 0 {
+		id := uint(v)
+		instanceID = &id
+	}
+	logicalName, _ := args["logical_name"].(string)
+	instance, err := a.lookup(ctx, incidentID, toolType, instanceID, logicalName)
+	if err != nil {
+		return fmt.Errorf("approval check failed: %w", err)
+	}
+	if !requiresApproval(instance.Settings) {
+		return nil
+	}
+	if incidentID == "" {
+		return errors.New("this write requires human approval, which is only available inside an incident")
+	}
+
+	target := instance.LogicalName
+	if target == "" {
+		target = instance.ToolName
+	}
+	wait := approvalWait(instance.Settings)
+	mcp.ReportProgress(ctx, fmt.Sprintf("Waiting up to %s for human approval to run %s on %s", wait, tool.Name, target))
+	a.logger.Printf("Holding %s on %s for approval (incident %s)", tool.Name, target, incidentID)
+
+	decision, err := a.gate.Await(ctx, &database.ToolApproval{
+		IncidentUUID: incidentID,
+		ToolName:     tool.Name,
+		Target:       target,
+		Detail:       describeCall(args),
+	}, wait)
+	if err != nil {
+		return fmt.Errorf("approval required but could not be obtained: %w", err)
+	}
+	switch decision.Status {
+	case "approved":
+		a.logger.Printf("%s on %s approved by %s (incident %s)", tool.Name, target, decision.DecidedBy, incidentID)
+		return nil
+	case "rejected":
+		msg := fmt.Sprintf("rejected by %s", decision.DecidedBy)
+		if decision.Reason != "" {
+			msg += ": " + decision.Reason
+		}
+		return fmt.Errorf("%s. The action was not performed. Do not retry it or work around it; report that it was rejected", msg)
+	default:
+		return fmt.Errorf("nobody approved this within %s, so it was not performed. Do not retry it; list it in your findings as a recommended manual action", wait)
+	}
+}
+
+// requiresApproval reports whether an instance with settings holds write
+// calls for approval. Only an explicit require_write_approval=false opts
+// out.
+func requiresApproval(settings map[string]interface{}) bool {
+	require, ok := settings["require_write_approval"].(bool)
+	return !ok || require
+}
+
+// approvalWait returns the instance's approval_timeout_seconds, clamped to
+// what the tool call timeout leaves room for.
+func approvalWait(settings map[string]interface{}) time.Duration {
+	var seconds float64
+	switch v := settings["approval_timeout_seconds"].(type) {
+	case float64:
+		seconds = v
+	case int:
+		seconds = float64(v)
+	}
+	wait := time.Duration(seconds) * time.Second
+	switch {
+	case wait <= 0:
+		return DefaultWait
+	case wait < minWait:
+		return minWait
+	case wait > MaxWait:
+		return MaxWait
+	}
+	return wait
+}
+
+// describeCall renders the call arguments for the approver, without the
+// instance routing hints.
+func describeCall(args map[string]interface{}) string {
+	shown := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k == "logical_name" || k == "tool_instance_id" {
+			continue
+		}
+		shown[k] = v
+	}
+	detail, err := json.Marshal(shown)
+	if err != nil {
+		return fmt.Sprintf("%v", shown)
+	}
+	return string(detail)
+}
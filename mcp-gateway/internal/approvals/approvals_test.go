@@ -0,0 +1,90 @@
+package approvals
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+)
+
+type stubGate struct {
+	requests []database.ToolApproval
+	waits    []time.Duration
+	decision database.ToolApproval
+}
+
+func (s *stubGate) Await(_ context.Context, req *database.ToolApproval, wait time.Duration) (*database.ToolApproval, error) {
+	s.requests = append(s.requests, *req)
+	s.waits = append(s.waits, wait)
+	decision := s.decision
+	return &decision, nil
+}
+
+func newTestApprover(gate Gate, settings map[string]interface{}) *Approver {
+	return &Approver{
+		logger: log.New(io.Discard, "", 0),
+		gate:   gate,
+		lookup: func(_ context.Context, _, toolType string, _ *uint, logicalName string) (*database.ToolCredentials, error) {
+			return &database.ToolCredentials{ToolType: toolType, ToolName: "Prod cluster", LogicalName: logicalName, Settings: settings}, nil
+		},
+	}
+}
+
+func TestApprove(t *testing.T) {
+	ctx := context.Background()
+	restart := mcp.Tool{Name: "kubernetes.restart_deployment", Destructive: true}
+	args := map[string]interface{}{"namespace": "web", "name": "api", "logical_name": "prod"}
+
+	gate := &stubGate{}
+	if err := newTestApprover(gate, map[string]interface{}{"require_write_approval": false}).Approve(ctx, restart, "inc-1", args); err != nil || len(gate.requests) != 0 {
+		t.Fatalf("opted out: err=%v requests=%d", err, len(gate.requests))
+	}
+
+	// Instances that never set require_write_approval are gated.
+	approver := newTestApprover(gate, map[string]interface{}{"approval_timeout_seconds": float64(120)})
+	if err := approver.Approve(ctx, restart, "", args); err == nil {
+		t.Fatal("a gated write outside an incident must be refused")
+	}
+
+	gate.decision = database.ToolApproval{Status: "approved", DecidedBy: "alice"}
+	if err := approver.Approve(ctx, restart, "inc-1", args); err != nil {
+		t.Fatalf("approved: %v", err)
+	}
+	req := gate.requests[0]
+	if req.IncidentUUID != "inc-1" || req.ToolName != "kubernetes.restart_deployment" || req.Target != "prod" ||
+		req.Detail != `{"name":"api","namespace":"web"}` || gate.waits[0] != 2*time.Minute {
+		t.Errorf("request = %+v, wait %s", req, gate.waits[0])
+	}
+
+	gate.decision = database.ToolApproval{Status: "rejected", DecidedBy: "bob", Reason: "not during peak"}
+	err := approver.Approve(ctx, restart, "inc-1", args)
+	if err == nil || !strings.Contains(err.Error(), "rejected by bob: not during peak") {
+		t.Errorf("rejected: err = %v", err)
+	}
+
+	gate.decision = database.ToolApproval{Status: "expired"}
+	if err := approver.Approve(ctx, restart, "inc-1", args); err == nil || !strings.Contains(err.Error(), "not performed") {
+		t.Errorf("expired: err = %v", err)
+	}
+}
+
+func TestApprovalWait(t *testing.T) {
+	for seconds, want := range map[float64]time.Duration{
+		0:    DefaultWait,
+		5:    minWait,
+		90:   90 * time.Second,
+		3600: MaxWait,
+	} {
+		if got := approvalWait(map[string]interface{}{"approval_timeout_seconds": seconds}); got != want {
+			t.Errorf("approvalWait(%v) = %s, want %s", seconds, got, want)
+		}
+	}
+	if got := approvalWait(nil); got != DefaultWait {
+		t.Errorf("approvalWait(unset) = %s", got)
+	}
+}
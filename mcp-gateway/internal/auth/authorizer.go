@@ -1,10 +1,18 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/cache"
 )
 
+// storeTimeout bounds shared store round trips for allowlists.
+const storeTimeout = 250 * time.Millisecond
+
 // AllowlistEntry represents one authorized tool instance for an incident.
 type AllowlistEntry struct {
 	InstanceID  uint   `json:"instance_id"`
@@ -38,6 +46,7 @@ type Authorizer struct {
 	allowlists map[string]*incidentAllowlist
 	ttl        time.Duration
 	stopCh     chan struct{}
+	store      cache.Store // optional; shares allowlists across replicas
 }
 
 // NewAuthorizer creates an Authorizer with the given TTL for allowlist entries.
@@ -52,15 +61,69 @@ func NewAuthorizer(ttl time.Duration) *Authorizer {
 	return a
 }
 
+// SetStore makes allowlists write through to store, so an allowlist set on
+// one gateway replica is enforced by all of them and survives restarts.
+// Call before serving requests.
+func (a *Authorizer) SetStore(store cache.Store) {
+	a.store = store
+}
+
 // SetAllowlist stores or updates the allowlist for an incident.
 // Each call resets the TTL.
 func (a *Authorizer) SetAllowlist(incidentID string, entries []AllowlistEntry) {
+	a.setLocal(incidentID, entries, a.ttl)
+
+	if a.store != nil {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			slog.Warn("failed to encode allowlist", "incident_uuid", incidentID, "err", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := a.store.Set(ctx, allowlistKey(incidentID), data, a.ttl); err != nil {
+			slog.Warn("failed to share allowlist", "incident_uuid", incidentID, "err", err)
+		}
+	}
+}
+
+func (a *Authorizer) setLocal(incidentID string, entries []AllowlistEntry, ttl time.Duration) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.allowlists[incidentID] = &incidentAllowlist{
 		entries:   entries,
-		expiresAt: time.Now().Add(a.ttl),
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// loadShared fetches an incident's allowlist from the shared store and
+// keeps it locally for the rest of its TTL. Returns nil when there is none.
+func (a *Authorizer) loadShared(incidentID string) []AllowlistEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	data, ttl, ok, err := a.store.Get(ctx, allowlistKey(incidentID))
+	if err != nil {
+		slog.Warn("failed to load shared allowlist", "incident_uuid", incidentID, "err", err)
+		return nil
 	}
+	if !ok || ttl <= 0 {
+		return nil
+	}
+	var entries []AllowlistEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		slog.Warn("failed to decode shared allowlist", "incident_uuid", incidentID, "err", err)
+		return nil
+	}
+	if entries == nil {
+		// A stored allowlist, even an empty one, rejects rather than allows.
+		entries = []AllowlistEntry{}
+	}
+	a.setLocal(incidentID, entries, ttl)
+	return entries
+}
+
+func allowlistKey(incidentID string) string {
+	return "auth_allowlist:" + incidentID
 }
 
 // IsAuthorized checks whether a tool call is permitted for the given incident.
@@ -86,10 +149,10 @@ func (a *Authorizer) GetAllowlist(incidentID string) []AllowlistEntry {
 	al, exists := a.allowlists[incidentID]
 	a.mu.RUnlock()
 
-	if !exists {
-		return nil
-	}
-	if time.Now().After(al.expiresAt) {
+	if !exists || time.Now().After(al.expiresAt) {
+		if a.store != nil {
+			return a.loadShared(incidentID)
+		}
 		return nil
 	}
 	// Return a copy so callers get a true snapshot that is safe to mutate.
@@ -169,8 +232,16 @@ func IsCredentiallessNamespace(toolType string) bool {
 // RemoveAllowlist removes the allowlist for an incident.
 func (a *Authorizer) RemoveAllowlist(incidentID string) {
 	a.mu.Lock()
-	defer a.mu.Unlock()
 	delete(a.allowlists, incidentID)
+	a.mu.Unlock()
+
+	if a.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := a.store.Delete(ctx, allowlistKey(incidentID)); err != nil {
+			slog.Warn("failed to remove shared allowlist", "incident_uuid", incidentID, "err", err)
+		}
+	}
 }
 
 // Stop terminates the background cleanup goroutine.
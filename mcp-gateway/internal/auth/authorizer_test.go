@@ -1,9 +1,13 @@
 package auth
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/cache"
+	"github.com/alicebob/miniredis/v2"
 )
 
 func TestAuthorizer_NoAllowlist_AllowsAll(t *testing.T) {
@@ -437,3 +441,38 @@ func TestAuthorizer_CleanupRemovesExpired(t *testing.T) {
 		t.Error("expected expired allowlist to be cleaned up")
 	}
 }
+
+func TestAuthorizer_SharedStoreAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store, err := cache.NewRedisStore(context.Background(), "redis://"+mr.Addr(), "test:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	a := NewAuthorizer(time.Hour)
+	defer a.Stop()
+	a.SetStore(store)
+	b := NewAuthorizer(time.Hour)
+	defer b.Stop()
+	b.SetStore(store)
+
+	a.SetAllowlist("incident-1", []AllowlistEntry{{InstanceID: 1, ToolType: "ssh"}})
+	a.SetAllowlist("incident-2", nil)
+
+	// The other replica enforces the allowlist instead of allowing all.
+	if !b.IsAuthorized("incident-1", "ssh", 1, "") || b.IsAuthorized("incident-1", "zabbix", 2, "") {
+		t.Error("replica b should enforce incident-1's allowlist")
+	}
+	if b.IsAuthorized("incident-2", "ssh", 0, "") {
+		t.Error("a shared empty allowlist should reject")
+	}
+
+	a.RemoveAllowlist("incident-1")
+	c := NewAuthorizer(time.Hour)
+	defer c.Stop()
+	c.SetStore(store)
+	if c.GetAllowlist("incident-1") != nil {
+		t.Error("removed allowlist should be gone from the store")
+	}
+}
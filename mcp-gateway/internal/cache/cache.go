@@ -1,8 +1,14 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/metrics"
 )
 
 // Entry represents a cached item with expiration
@@ -24,6 +30,8 @@ type Cache struct {
 	cleanupTick time.Duration
 	stopCleanup chan struct{}
 	stopped     bool
+	name        string  // metrics label; empty disables hit/miss counting
+	decode      Decoder // set by Shared; nil keeps the cache in-process
 }
 
 // New creates a new cache with the specified default TTL and cleanup interval
@@ -41,6 +49,32 @@ func New(defaultTTL, cleanupInterval time.Duration) *Cache {
 	return c
 }
 
+// Named sets the name the cache reports its hits and misses under on
+// /metrics and returns the cache. Unnamed caches are not counted.
+func (c *Cache) Named(name string) *Cache {
+	c.name = name
+	return c
+}
+
+// Shared makes the cache write through to the shared store installed with
+// SetSharedStore, under "<name>:<key>", and fall back to it on local misses
+// so entries survive restarts and are seen by every gateway replica. decode
+// restores values read from the store to the type callers assert on. The
+// cache must be Named first. Only share caches whose values are safe to
+// leave the process: credentials and TLS configs stay in-process.
+func (c *Cache) Shared(decode Decoder) *Cache {
+	c.decode = decode
+	return c
+}
+
+// store returns the shared store when this cache is shared and one is set.
+func (c *Cache) store() Store {
+	if c.decode == nil || c.name == "" {
+		return nil
+	}
+	return SharedStore()
+}
+
 // cleanupLoop periodically removes expired entries
 func (c *Cache) cleanupLoop() {
 	ticker := time.NewTicker(c.cleanupTick)
@@ -71,6 +105,17 @@ func (c *Cache) cleanup() {
 
 // Get retrieves a value from the cache. Returns nil and false if not found or expired.
 func (c *Cache) Get(key string) (interface{}, bool) {
+	value, ok := c.get(key)
+	if !ok {
+		value, ok = c.getShared(key)
+	}
+	if c.name != "" {
+		metrics.CacheLookup(c.name, ok)
+	}
+	return value, ok
+}
+
+func (c *Cache) get(key string) (interface{}, bool) {
 	c.mu.RLock()
 	entry, exists := c.entries[key]
 	c.mu.RUnlock()
@@ -87,6 +132,32 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	return entry.Value, true
 }
 
+// getShared looks key up in the shared store and keeps a hit locally for
+// the rest of its TTL. Store errors count as misses.
+func (c *Cache) getShared(key string) (interface{}, bool) {
+	store := c.store()
+	if store == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	data, ttl, ok, err := store.Get(ctx, c.name+":"+key)
+	if err != nil {
+		slog.Warn("shared cache lookup failed", "cache", c.name, "err", err)
+		return nil, false
+	}
+	if !ok || ttl <= 0 {
+		return nil, false
+	}
+	value, err := c.decode(data)
+	if err != nil {
+		slog.Warn("shared cache entry could not be decoded", "cache", c.name, "err", err)
+		return nil, false
+	}
+	c.setLocal(key, value, ttl)
+	return value, true
+}
+
 // Set stores a value in the cache with the default TTL
 func (c *Cache) Set(key string, value interface{}) {
 	c.SetWithTTL(key, value, c.defaultTTL)
@@ -94,6 +165,19 @@ func (c *Cache) Set(key string, value interface{}) {
 
 // SetWithTTL stores a value in the cache with a custom TTL
 func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.setLocal(key, value, ttl)
+
+	if store := c.store(); store != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			slog.Warn("shared cache entry could not be encoded", "cache", c.name, "err", err)
+			return
+		}
+		c.withStore(store, func(ctx context.Context) error { return store.Set(ctx, c.name+":"+key, data, ttl) })
+	}
+}
+
+func (c *Cache) setLocal(key string, value interface{}, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -103,32 +187,51 @@ func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
 	}
 }
 
+// withStore runs a shared store write, logging (not returning) failures:
+// the local cache already holds the change.
+func (c *Cache) withStore(store Store, op func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := op(ctx); err != nil {
+		slog.Warn("shared cache update failed", "cache", c.name, "err", err)
+	}
+}
+
 // Delete removes a specific key from the cache
 func (c *Cache) Delete(key string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	delete(c.entries, key)
+	c.mu.Unlock()
+
+	if store := c.store(); store != nil {
+		c.withStore(store, func(ctx context.Context) error { return store.Delete(ctx, c.name+":"+key) })
+	}
 }
 
 // DeleteByPrefix removes all keys that start with the given prefix
 func (c *Cache) DeleteByPrefix(prefix string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	for key := range c.entries {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+		if strings.HasPrefix(key, prefix) {
 			delete(c.entries, key)
 		}
 	}
+	c.mu.Unlock()
+
+	if store := c.store(); store != nil {
+		c.withStore(store, func(ctx context.Context) error { return store.DeleteByPrefix(ctx, c.name+":"+prefix) })
+	}
 }
 
 // Clear removes all entries from the cache
 func (c *Cache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	c.entries = make(map[string]Entry)
+	c.mu.Unlock()
+
+	if store := c.store(); store != nil {
+		c.withStore(store, func(ctx context.Context) error { return store.DeleteByPrefix(ctx, c.name+":") })
+	}
 }
 
 // Stop stops the background cleanup goroutine
@@ -1,9 +1,14 @@
 package cache
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/metrics"
 )
 
 func TestNew(t *testing.T) {
@@ -364,3 +369,23 @@ func TestCache_DeleteByPrefix_NoMatch(t *testing.T) {
 		t.Errorf("Expected 2 keys to remain, got %d", c.Len())
 	}
 }
+
+func TestCache_NamedReportsLookups(t *testing.T) {
+	c := New(time.Minute, time.Minute).Named("test_named")
+	defer c.Stop()
+
+	c.Set("key", "value")
+	c.Get("key")
+	c.Get("missing")
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`mcp_gateway_cache_lookups_total{cache="test_named",result="hit"}`,
+		`mcp_gateway_cache_lookups_total{cache="test_named",result="miss"}`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("/metrics lacks %s", want)
+		}
+	}
+}
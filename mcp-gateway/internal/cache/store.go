@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// storeTimeout bounds every shared store round trip. The cache API has no
+// context, and a slow or unreachable Redis must never hold up a tool call:
+// on timeout the lookup is treated as a miss.
+const storeTimeout = 250 * time.Millisecond
+
+// Store is a shared key/value backend that caches write through to, so
+// entries survive gateway restarts and are visible to every replica.
+type Store interface {
+	// Get returns the value and its remaining TTL, or ok=false on a miss.
+	Get(ctx context.Context, key string) (value []byte, ttl time.Duration, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	DeleteByPrefix(ctx context.Context, prefix string) error
+}
+
+var sharedStore atomic.Pointer[Store]
+
+// SetSharedStore installs the store used by shared caches (see
+// Cache.Shared). Passing nil reverts them to in-process only.
+func SetSharedStore(s Store) {
+	if s == nil {
+		sharedStore.Store(nil)
+		return
+	}
+	sharedStore.Store(&s)
+}
+
+// SharedStore returns the installed shared store, or nil.
+func SharedStore() Store {
+	if s := sharedStore.Load(); s != nil {
+		return *s
+	}
+	return nil
+}
+
+// Decoder turns a value read from the shared store back into the type the
+// cache's callers assert on.
+type Decoder func(data []byte) (interface{}, error)
+
+// JSON returns a Decoder for values of type T. Shared values are encoded
+// with encoding/json, so T must round-trip through it.
+func JSON[T any]() Decoder {
+	return func(data []byte) (interface{}, error) {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+// RedisStore is a Store backed by Redis. All keys are prefixed so several
+// deployments (or the API and the gateway) can share one Redis.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore connects to the Redis server at url (redis:// or rediss://)
+// and checks that it answers.
+func NewRedisStore(ctx context.Context, url, prefix string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &RedisStore{client: client, prefix: prefix}, nil
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, time.Duration, bool, error) {
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, s.prefix+key)
+	ttl := pipe.PTTL(ctx, s.prefix+key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, err
+	}
+	value, err := get.Bytes()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return value, ttl.Val(), true, nil
+}
+
+// Set implements Store.
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// Delete implements Store.
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// DeleteByPrefix implements Store. Keys are found with SCAN, so it does not
+// block Redis on large keyspaces.
+func (s *RedisStore) DeleteByPrefix(ctx context.Context, prefix string) error {
+	iter := s.client.Scan(ctx, 0, s.prefix+escapeGlob(prefix)+"*", 500).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 500 {
+			if err := s.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return s.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// Close closes the Redis connection pool.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// escapeGlob escapes the SCAN MATCH metacharacters in s.
+func escapeGlob(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store, err := NewRedisStore(context.Background(), "redis://"+mr.Addr(), "test:")
+	if err != nil {
+		t.Fatalf("NewRedisStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, mr
+}
+
+func TestNewRedisStore_RejectsBadURLAndDeadServer(t *testing.T) {
+	if _, err := NewRedisStore(context.Background(), "http://localhost", "test:"); err == nil {
+		t.Error("expected an error for a non-redis URL")
+	}
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+	if _, err := NewRedisStore(context.Background(), "redis://"+addr, "test:"); err == nil {
+		t.Error("expected an error when Redis does not answer")
+	}
+}
+
+func TestRedisStore_RoundTripAndPrefixDelete(t *testing.T) {
+	store, mr := newTestRedisStore(t)
+	ctx := context.Background()
+
+	if _, _, ok, err := store.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("missing key = %v, %v", ok, err)
+	}
+	for _, key := range []string{"a:1", "a:2", "a*:3", "b:1"} {
+		if err := store.Set(ctx, key, []byte(key), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	value, ttl, ok, err := store.Get(ctx, "a:1")
+	if err != nil || !ok || string(value) != "a:1" || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("Get = %q, %v, %v, %v", value, ttl, ok, err)
+	}
+	if !mr.Exists("test:a:1") {
+		t.Error("keys should carry the store prefix")
+	}
+
+	// The glob metacharacter in the prefix is matched literally.
+	if err := store.DeleteByPrefix(ctx, "a*:"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("test:a*:3") || !mr.Exists("test:a:1") {
+		t.Errorf("keys after deleting a*: = %v", mr.Keys())
+	}
+	if err := store.DeleteByPrefix(ctx, "a:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "b:1"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys left = %v", keys)
+	}
+}
+
+func TestCache_SharedWritesThroughAndSurvivesRestart(t *testing.T) {
+	store, mr := newTestRedisStore(t)
+	SetSharedStore(store)
+	defer SetSharedStore(nil)
+
+	c := New(time.Minute, time.Minute).Named("shared_test").Shared(JSON[json.RawMessage]())
+	c.Set("incident:1:q", json.RawMessage(`{"up":1}`))
+	c.Stop()
+	if !mr.Exists("test:shared_test:incident:1:q") {
+		t.Fatalf("entry not written through: %v", mr.Keys())
+	}
+
+	// A fresh cache (a restarted or second replica) finds the entry and
+	// gets it back as the type callers assert on.
+	restarted := New(time.Minute, time.Minute).Named("shared_test").Shared(JSON[json.RawMessage]())
+	defer restarted.Stop()
+	value, ok := restarted.Get("incident:1:q")
+	if raw, isRaw := value.(json.RawMessage); !ok || !isRaw || string(raw) != `{"up":1}` {
+		t.Fatalf("Get = %#v, %v", value, ok)
+	}
+	if restarted.Len() != 1 {
+		t.Error("shared hit should be kept locally")
+	}
+
+	restarted.DeleteByPrefix("incident:1:")
+	if mr.Exists("test:shared_test:incident:1:q") {
+		t.Error("DeleteByPrefix should reach the shared store")
+	}
+
+	// Unshared caches never touch the store.
+	local := New(time.Minute, time.Minute).Named("local_test")
+	defer local.Stop()
+	local.Set("creds", "secret")
+	if mr.Exists("test:local_test:creds") {
+		t.Error("unshared cache wrote to the store")
+	}
+}
+
+func TestCache_SharedFallsBackWhenStoreIsDown(t *testing.T) {
+	store, mr := newTestRedisStore(t)
+	SetSharedStore(store)
+	defer SetSharedStore(nil)
+	mr.Close()
+
+	c := New(time.Minute, time.Minute).Named("down_test").Shared(JSON[string]())
+	defer c.Stop()
+	c.Set("key", "value")
+	if value, ok := c.Get("key"); !ok || value != "value" {
+		t.Errorf("Get = %v, %v; local entry should still serve", value, ok)
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Error("unreachable store should count as a miss")
+	}
+}
@@ -0,0 +1,142 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Settings keys for the HTTP broker provider.
+const (
+	BrokerURLSettingKey   = "credential_broker_url"
+	BrokerTokenSettingKey = "credential_broker_token"
+)
+
+// BrokerProvider delegates minting to an external credential broker over
+// HTTP, for credentials the gateway cannot mint itself (AWS STS sessions,
+// Vault dynamic secrets, ...).
+//
+// Mint sends POST {broker_url}/leases with
+//
+//	{"incident_id", "tool_type", "instance_id", "logical_name", "ttl_seconds"}
+//
+// and expects {"lease_id", "settings", "expires_at"} back; "settings" is
+// overlaid on the instance settings. Revoke sends DELETE
+// {broker_url}/leases/{lease_id}. Both carry the broker token as a bearer
+// token when one is configured.
+type BrokerProvider struct {
+	client *http.Client
+}
+
+// NewBrokerProvider creates a broker provider. A nil client uses a client
+// with a 30 second timeout.
+func NewBrokerProvider(client *http.Client) *BrokerProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &BrokerProvider{client: client}
+}
+
+// Name implements Provider.
+func (p *BrokerProvider) Name() string { return "http_broker" }
+
+type brokerMintRequest struct {
+	IncidentID  string `json:"incident_id"`
+	ToolType    string `json:"tool_type"`
+	InstanceID  uint   `json:"instance_id"`
+	LogicalName string `json:"logical_name,omitempty"`
+	TTLSeconds  int    `json:"ttl_seconds"`
+}
+
+type brokerMintResponse struct {
+	LeaseID   string                 `json:"lease_id"`
+	Settings  map[string]interface{} `json:"settings"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// Mint implements Provider.
+func (p *BrokerProvider) Mint(ctx context.Context, req MintRequest) (*Lease, error) {
+	base, err := brokerURL(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(brokerMintRequest{
+		IncidentID:  req.IncidentID,
+		ToolType:    req.ToolType,
+		InstanceID:  req.InstanceID,
+		LogicalName: req.LogicalName,
+		TTLSeconds:  int(req.TTL / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp brokerMintResponse
+	if err := p.do(ctx, http.MethodPost, base+"/leases", req.Settings, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.LeaseID == "" || len(resp.Settings) == 0 {
+		return nil, fmt.Errorf("broker response is missing lease_id or settings")
+	}
+	expires := resp.ExpiresAt
+	if expires.IsZero() {
+		expires = time.Now().Add(req.TTL)
+	}
+	return &Lease{ID: resp.LeaseID, Settings: resp.Settings, ExpiresAt: expires}, nil
+}
+
+// Revoke implements Provider.
+func (p *BrokerProvider) Revoke(ctx context.Context, settings map[string]interface{}, lease *Lease) error {
+	base, err := brokerURL(settings)
+	if err != nil {
+		return err
+	}
+	return p.do(ctx, http.MethodDelete, base+"/leases/"+url.PathEscape(lease.ID), settings, nil, nil)
+}
+
+func (p *BrokerProvider) do(ctx context.Context, method, target string, settings map[string]interface{}, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token, _ := settings[BrokerTokenSettingKey].(string); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("broker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodDelete {
+		return nil // already gone
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("broker returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid broker response: %w", err)
+		}
+	}
+	return nil
+}
+
+func brokerURL(settings map[string]interface{}) (string, error) {
+	raw, _ := settings[BrokerURLSettingKey].(string)
+	u, err := url.Parse(raw)
+	if raw == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%s must be an http(s) URL", BrokerURLSettingKey)
+	}
+	return strings.TrimRight(raw, "/"), nil
+}
@@ -0,0 +1,84 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBrokerProvider_MintAndRevoke(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer broker-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/leases":
+			var req brokerMintRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IncidentID != "inc-1" || req.TTLSeconds != 1800 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"lease_id":   "sts-123",
+				"settings":   map[string]interface{}{"aws_session_token": "tok"},
+				"expires_at": "2026-01-01T12:30:00Z",
+			})
+		case r.Method == http.MethodDelete && r.URL.Path == "/leases/sts-123":
+			deleted = "sts-123"
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	settings := map[string]interface{}{
+		BrokerURLSettingKey:   server.URL + "/",
+		BrokerTokenSettingKey: "broker-token",
+	}
+	p := NewBrokerProvider(server.Client())
+
+	lease, err := p.Mint(context.Background(), MintRequest{IncidentID: "inc-1", ToolType: "aws", Settings: settings, TTL: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("Mint: %v", err)
+	}
+	if lease.ID != "sts-123" || lease.Settings["aws_session_token"] != "tok" {
+		t.Errorf("lease = %+v", lease)
+	}
+	if !lease.ExpiresAt.Equal(time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("ExpiresAt = %v", lease.ExpiresAt)
+	}
+
+	if err := p.Revoke(context.Background(), settings, lease); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if deleted != "sts-123" {
+		t.Error("expected DELETE /leases/sts-123")
+	}
+
+	// A lease the broker no longer knows counts as revoked.
+	if err := p.Revoke(context.Background(), settings, &Lease{ID: "gone"}); err != nil {
+		t.Errorf("Revoke of unknown lease: %v", err)
+	}
+}
+
+func TestBrokerProvider_Errors(t *testing.T) {
+	p := NewBrokerProvider(nil)
+	if _, err := p.Mint(context.Background(), MintRequest{Settings: map[string]interface{}{BrokerURLSettingKey: "ftp://x"}}); err == nil {
+		t.Error("expected invalid broker URL to fail")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"lease_id":"x"}`))
+	}))
+	defer server.Close()
+	_, err := NewBrokerProvider(server.Client()).Mint(context.Background(), MintRequest{Settings: map[string]interface{}{BrokerURLSettingKey: server.URL}})
+	if err == nil {
+		t.Error("expected a response without settings to fail")
+	}
+}
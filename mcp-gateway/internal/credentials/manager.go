@@ -0,0 +1,346 @@
+// Package credentials mints short-lived credentials for tool instances on
+// demand and revokes them once the incidents using them are finished.
+//
+// A tool instance opts in by setting "credential_provider" in its settings.
+// Its stored settings then act as the minting identity (e.g. a Postgres role
+// allowed to CREATE ROLE, or a broker token); tools only ever see the
+// settings overlaid by the lease.
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+)
+
+// Settings keys read from a tool instance's settings.
+const (
+	ProviderSettingKey = "credential_provider"
+	TTLSettingKey      = "credential_ttl_minutes"
+
+	// settingPrefix marks minter-only keys; they are stripped before the
+	// settings reach a tool.
+	settingPrefix = "credential_"
+)
+
+const (
+	DefaultTTL = time.Hour
+	// MinTTL leaves room for RenewBefore plus a tool's config cache.
+	MinTTL = 15 * time.Minute
+	// RenewBefore is how close to expiry a lease stops being handed out.
+	// It must exceed the tools' 5 minute config cache so a cached lease is
+	// never used past its expiry.
+	RenewBefore = 10 * time.Minute
+	// RevokeGrace is how long a lease stays valid after its last use once its
+	// incidents are finished, again covering the tools' config cache.
+	RevokeGrace = 10 * time.Minute
+
+	maxRevokeAttempts = 5
+	revokeTimeout     = 30 * time.Second
+)
+
+// MintRequest describes the credentials a provider is asked to mint.
+type MintRequest struct {
+	IncidentID  string
+	ToolType    string
+	InstanceID  uint
+	LogicalName string
+	// Settings are the instance's stored settings, including the
+	// credential_* keys that configure the provider.
+	Settings map[string]interface{}
+	TTL      time.Duration
+}
+
+// Lease is a set of minted credentials.
+type Lease struct {
+	// ID identifies the lease to the provider for revocation.
+	ID string
+	// Settings are overlaid on the instance settings, e.g. pg_username and
+	// pg_password for a minted database role.
+	Settings  map[string]interface{}
+	ExpiresAt time.Time
+	// RevokeData carries whatever else the provider needs to revoke.
+	RevokeData map[string]string
+}
+
+// Provider mints and revokes credentials of one kind.
+type Provider interface {
+	Name() string
+	Mint(ctx context.Context, req MintRequest) (*Lease, error)
+	Revoke(ctx context.Context, settings map[string]interface{}, lease *Lease) error
+}
+
+// activeLease is a minted lease plus the bookkeeping needed to decide when
+// it can be revoked.
+type activeLease struct {
+	lease      *Lease
+	provider   Provider
+	instanceID uint
+	settings   map[string]interface{} // instance settings at mint time, for Revoke
+	incidents  map[string]struct{}
+	lastUsed   time.Time
+	superseded bool
+	attempts   int
+}
+
+// Manager hands out leases per tool instance and revokes them in the
+// background. One lease per instance is shared by concurrent incidents: tools
+// cache credentials by logical name, so per-incident leases could be revoked
+// under another incident's cached config.
+type Manager struct {
+	mu        sync.Mutex
+	providers map[string]Provider
+	current   map[uint]*activeLease // instance ID -> lease being handed out
+	leases    []*activeLease        // every unrevoked lease
+
+	mintMu   sync.Mutex
+	minting  map[uint]*sync.Mutex
+	isActive func(ctx context.Context, incidentID string) (bool, error)
+	now      func() time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewManager creates a Manager. isActive reports whether an incident is still
+// running; database.IsIncidentActive in production.
+func NewManager(isActive func(ctx context.Context, incidentID string) (bool, error)) *Manager {
+	return &Manager{
+		providers: make(map[string]Provider),
+		current:   make(map[uint]*activeLease),
+		minting:   make(map[uint]*sync.Mutex),
+		isActive:  isActive,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Register adds a provider, replacing any provider with the same name.
+func (m *Manager) Register(p Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providers[p.Name()] = p
+}
+
+// Apply implements database.CredentialMinter.
+func (m *Manager) Apply(ctx context.Context, incidentID string, creds *database.ToolCredentials) (*database.ToolCredentials, error) {
+	if creds == nil {
+		return nil, nil
+	}
+	name, _ := creds.Settings[ProviderSettingKey].(string)
+	if name == "" {
+		return creds, nil
+	}
+
+	m.mu.Lock()
+	provider, ok := m.providers[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("tool instance %q uses unknown credential provider %q", creds.ToolName, name)
+	}
+
+	al, err := m.leaseFor(ctx, incidentID, creds, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint %s credentials for %q: %w", name, creds.ToolName, err)
+	}
+
+	out := *creds
+	out.Settings = make(map[string]interface{}, len(creds.Settings)+len(al.lease.Settings))
+	for k, v := range creds.Settings {
+		if !strings.HasPrefix(k, settingPrefix) {
+			out.Settings[k] = v
+		}
+	}
+	for k, v := range al.lease.Settings {
+		out.Settings[k] = v
+	}
+	return &out, nil
+}
+
+// leaseFor returns the instance's current lease, minting a new one when
+// there is none or it is about to expire.
+func (m *Manager) leaseFor(ctx context.Context, incidentID string, creds *database.ToolCredentials, provider Provider) (*activeLease, error) {
+	// Serialize minting per instance so concurrent tool calls share a lease.
+	mu := m.mintLock(creds.InstanceID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := m.now()
+	m.mu.Lock()
+	if al := m.current[creds.InstanceID]; al != nil && al.provider == provider && now.Add(RenewBefore).Before(al.lease.ExpiresAt) {
+		al.incidents[incidentID] = struct{}{}
+		al.lastUsed = now
+		m.mu.Unlock()
+		return al, nil
+	}
+	m.mu.Unlock()
+
+	lease, err := provider.Mint(ctx, MintRequest{
+		IncidentID:  incidentID,
+		ToolType:    creds.ToolType,
+		InstanceID:  creds.InstanceID,
+		LogicalName: creds.LogicalName,
+		Settings:    creds.Settings,
+		TTL:         leaseTTL(creds.Settings),
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("minted ephemeral credentials", "provider", provider.Name(), "instance_id", creds.InstanceID, "lease_id", lease.ID, "expires_at", lease.ExpiresAt)
+
+	al := &activeLease{
+		lease:      lease,
+		provider:   provider,
+		instanceID: creds.InstanceID,
+		settings:   creds.Settings,
+		incidents:  map[string]struct{}{incidentID: {}},
+		lastUsed:   now,
+	}
+	m.mu.Lock()
+	if prev := m.current[creds.InstanceID]; prev != nil {
+		prev.superseded = true
+	}
+	m.current[creds.InstanceID] = al
+	m.leases = append(m.leases, al)
+	m.mu.Unlock()
+	return al, nil
+}
+
+func (m *Manager) mintLock(instanceID uint) *sync.Mutex {
+	m.mintMu.Lock()
+	defer m.mintMu.Unlock()
+	mu, ok := m.minting[instanceID]
+	if !ok {
+		mu = &sync.Mutex{}
+		m.minting[instanceID] = mu
+	}
+	return mu
+}
+
+// leaseTTL reads credential_ttl_minutes, clamped to MinTTL.
+func leaseTTL(settings map[string]interface{}) time.Duration {
+	ttl := DefaultTTL
+	if v, ok := settings[TTLSettingKey].(float64); ok && v > 0 {
+		ttl = time.Duration(v * float64(time.Minute))
+	}
+	if ttl < MinTTL {
+		ttl = MinTTL
+	}
+	return ttl
+}
+
+// Sweep revokes leases that have expired, or that have gone unused for
+// RevokeGrace and are either superseded or only referenced by finished
+// incidents.
+func (m *Manager) Sweep(ctx context.Context) {
+	now := m.now()
+
+	type candidate struct {
+		al         *activeLease
+		mustRevoke bool // expired or superseded
+	}
+	m.mu.Lock()
+	var candidates []candidate
+	for _, al := range m.leases {
+		expired := !now.Before(al.lease.ExpiresAt)
+		if expired || now.Sub(al.lastUsed) >= RevokeGrace {
+			candidates = append(candidates, candidate{al: al, mustRevoke: expired || al.superseded})
+		}
+	}
+	m.mu.Unlock()
+
+	for _, c := range candidates {
+		if !c.mustRevoke && m.anyIncidentActive(ctx, c.al) {
+			continue
+		}
+		m.revoke(ctx, c.al)
+	}
+}
+
+func (m *Manager) anyIncidentActive(ctx context.Context, al *activeLease) bool {
+	m.mu.Lock()
+	ids := make([]string, 0, len(al.incidents))
+	for id := range al.incidents {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		active, err := m.isActive(ctx, id)
+		if err != nil {
+			// Fail towards keeping access; the lease still expires on its own.
+			slog.Warn("could not check incident status for credential lease", "incident_uuid", id, "err", err)
+			return true
+		}
+		if active {
+			return true
+		}
+	}
+	return false
+}
+
+// revoke revokes al and forgets it. Failed revocations are retried on later
+// sweeps, up to maxRevokeAttempts.
+func (m *Manager) revoke(ctx context.Context, al *activeLease) {
+	m.mu.Lock()
+	if m.current[al.instanceID] == al {
+		delete(m.current, al.instanceID)
+	}
+	m.mu.Unlock()
+
+	rctx, cancel := context.WithTimeout(ctx, revokeTimeout)
+	err := al.provider.Revoke(rctx, al.settings, al.lease)
+	cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		al.attempts++
+		if al.attempts < maxRevokeAttempts {
+			slog.Warn("failed to revoke ephemeral credentials, will retry", "provider", al.provider.Name(), "lease_id", al.lease.ID, "attempt", al.attempts, "err", err)
+			return
+		}
+		slog.Error("giving up revoking ephemeral credentials", "provider", al.provider.Name(), "lease_id", al.lease.ID, "err", err)
+	} else {
+		slog.Info("revoked ephemeral credentials", "provider", al.provider.Name(), "lease_id", al.lease.ID)
+	}
+	for i, l := range m.leases {
+		if l == al {
+			m.leases = append(m.leases[:i], m.leases[i+1:]...)
+			break
+		}
+	}
+}
+
+// Start runs Sweep every interval until Stop is called.
+func (m *Manager) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				m.Sweep(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop ends the sweep loop and revokes every outstanding lease, so a
+// gateway shutdown does not leave standing credentials behind.
+func (m *Manager) Stop(ctx context.Context) {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+
+	m.mu.Lock()
+	leases := append([]*activeLease(nil), m.leases...)
+	m.mu.Unlock()
+	for _, al := range leases {
+		m.revoke(ctx, al)
+	}
+}
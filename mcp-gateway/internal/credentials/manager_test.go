@@ -0,0 +1,219 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+)
+
+type fakeProvider struct {
+	mu      sync.Mutex
+	minted  int
+	revoked []string
+	now     func() time.Time
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Mint(_ context.Context, req MintRequest) (*Lease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.minted++
+	id := fmt.Sprintf("lease-%d", p.minted)
+	return &Lease{
+		ID:        id,
+		Settings:  map[string]interface{}{"token": id},
+		ExpiresAt: p.now().Add(req.TTL),
+	}, nil
+}
+
+func (p *fakeProvider) Revoke(_ context.Context, _ map[string]interface{}, lease *Lease) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.revoked = append(p.revoked, lease.ID)
+	return nil
+}
+
+func (p *fakeProvider) revokedIDs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.revoked...)
+}
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newTestManager(active map[string]bool) (*Manager, *fakeProvider, *testClock) {
+	clock := &testClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	m := NewManager(func(_ context.Context, id string) (bool, error) { return active[id], nil })
+	m.now = clock.now
+	p := &fakeProvider{now: clock.now}
+	m.Register(p)
+	return m, p, clock
+}
+
+func ephemeralCreds() *database.ToolCredentials {
+	return &database.ToolCredentials{
+		ToolType:   "victoria_metrics",
+		ToolName:   "prod-vm",
+		InstanceID: 7,
+		Settings: map[string]interface{}{
+			"vm_url":           "https://vm.example.com",
+			ProviderSettingKey: "fake",
+			TTLSettingKey:      float64(30),
+		},
+	}
+}
+
+func TestManager_Apply_PassesThroughStandingCredentials(t *testing.T) {
+	m, p, _ := newTestManager(nil)
+	creds := &database.ToolCredentials{Settings: map[string]interface{}{"token": "static"}}
+
+	got, err := m.Apply(context.Background(), "inc-1", creds)
+	if err != nil || got != creds {
+		t.Fatalf("Apply = %v, %v; want the same credentials back", got, err)
+	}
+	if p.minted != 0 {
+		t.Errorf("minted = %d, want 0", p.minted)
+	}
+}
+
+func TestManager_Apply_MintsAndSharesLease(t *testing.T) {
+	m, p, _ := newTestManager(nil)
+
+	got, err := m.Apply(context.Background(), "inc-1", ephemeralCreds())
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got.Settings["token"] != "lease-1" || got.Settings["vm_url"] != "https://vm.example.com" {
+		t.Errorf("settings = %v", got.Settings)
+	}
+	for k := range got.Settings {
+		if strings.HasPrefix(k, settingPrefix) {
+			t.Errorf("minter key %q leaked to the tool", k)
+		}
+	}
+
+	if _, err := m.Apply(context.Background(), "inc-2", ephemeralCreds()); err != nil {
+		t.Fatalf("second Apply: %v", err)
+	}
+	if p.minted != 1 {
+		t.Errorf("minted = %d, want one lease shared by both incidents", p.minted)
+	}
+}
+
+func TestManager_Apply_UnknownProvider(t *testing.T) {
+	m, _, _ := newTestManager(nil)
+	creds := ephemeralCreds()
+	creds.Settings[ProviderSettingKey] = "nope"
+	if _, err := m.Apply(context.Background(), "inc-1", creds); err == nil {
+		t.Error("expected unknown provider to fail")
+	}
+}
+
+func TestManager_Apply_RenewsNearExpiry(t *testing.T) {
+	m, p, clock := newTestManager(map[string]bool{"inc-1": true})
+
+	if _, err := m.Apply(context.Background(), "inc-1", ephemeralCreds()); err != nil {
+		t.Fatal(err)
+	}
+	// 30 minute TTL: inside RenewBefore of expiry the lease is replaced.
+	clock.t = clock.t.Add(25 * time.Minute)
+	got, err := m.Apply(context.Background(), "inc-1", ephemeralCreds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Settings["token"] != "lease-2" || p.minted != 2 {
+		t.Fatalf("token = %v minted = %d, want a renewed lease", got.Settings["token"], p.minted)
+	}
+
+	// The superseded lease is revoked once unused for RevokeGrace, even
+	// though its incident is still running.
+	clock.t = clock.t.Add(RevokeGrace)
+	m.Sweep(context.Background())
+	if ids := p.revokedIDs(); len(ids) != 1 || ids[0] != "lease-1" {
+		t.Errorf("revoked = %v, want [lease-1]", ids)
+	}
+}
+
+func TestManager_Sweep_RevokesAfterIncidentsFinish(t *testing.T) {
+	active := map[string]bool{"inc-1": true}
+	m, p, clock := newTestManager(active)
+
+	if _, err := m.Apply(context.Background(), "inc-1", ephemeralCreds()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.t = clock.t.Add(RevokeGrace)
+	m.Sweep(context.Background())
+	if ids := p.revokedIDs(); len(ids) != 0 {
+		t.Fatalf("revoked %v while the incident is running", ids)
+	}
+
+	active["inc-1"] = false
+	m.Sweep(context.Background())
+	if ids := p.revokedIDs(); len(ids) != 1 {
+		t.Fatalf("revoked = %v, want the lease revoked after the incident finished", ids)
+	}
+
+	// The next call mints a fresh lease.
+	if _, err := m.Apply(context.Background(), "inc-2", ephemeralCreds()); err != nil {
+		t.Fatal(err)
+	}
+	if p.minted != 2 {
+		t.Errorf("minted = %d, want 2", p.minted)
+	}
+}
+
+func TestManager_Sweep_RevokesExpired(t *testing.T) {
+	m, p, clock := newTestManager(map[string]bool{"inc-1": true})
+	if _, err := m.Apply(context.Background(), "inc-1", ephemeralCreds()); err != nil {
+		t.Fatal(err)
+	}
+	clock.t = clock.t.Add(31 * time.Minute)
+	m.Sweep(context.Background())
+	if ids := p.revokedIDs(); len(ids) != 1 {
+		t.Errorf("revoked = %v, want the expired lease revoked", ids)
+	}
+}
+
+func TestManager_Stop_RevokesOutstanding(t *testing.T) {
+	m, p, _ := newTestManager(map[string]bool{"inc-1": true})
+	if _, err := m.Apply(context.Background(), "inc-1", ephemeralCreds()); err != nil {
+		t.Fatal(err)
+	}
+	m.Stop(context.Background())
+	if ids := p.revokedIDs(); len(ids) != 1 {
+		t.Errorf("revoked = %v, want every lease revoked on stop", ids)
+	}
+}
+
+func TestLeaseTTL(t *testing.T) {
+	tests := []struct {
+		settings map[string]interface{}
+		want     time.Duration
+	}{
+		{map[string]interface{}{}, DefaultTTL},
+		{map[string]interface{}{TTLSettingKey: float64(120)}, 2 * time.Hour},
+		{map[string]interface{}{TTLSettingKey: float64(1)}, MinTTL},
+	}
+	for _, tt := range tests {
+		if got := leaseTTL(tt.settings); got != tt.want {
+			t.Errorf("leaseTTL(%v) = %v, want %v", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestCreateRoleSQL(t *testing.T) {
+	got := createRoleSQL("akmatori_tmp_ab12", "s3cret", time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC), []string{"pg_read_all_data", `weird"role`})
+	want := `CREATE ROLE "akmatori_tmp_ab12" LOGIN PASSWORD 's3cret' VALID UNTIL '2026-01-01T13:00:00Z' IN ROLE "pg_read_all_data", "weird""role"`
+	if got != want {
+		t.Errorf("createRoleSQL =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/tools/postgresql"
+	"github.com/jackc/pgx/v5"
+)
+
+// GrantRolesSettingKey lists the roles (comma separated) granted to each
+// minted Postgres role, e.g. "pg_read_all_data".
+const GrantRolesSettingKey = "credential_grant_roles"
+
+// PostgresRoleProvider mints a temporary login role per lease on a
+// postgresql tool instance. The instance's pg_username/pg_password must
+// belong to a role with CREATEROLE; minted roles get a VALID UNTIL matching
+// the lease and are dropped (after terminating their sessions) on revoke.
+type PostgresRoleProvider struct {
+	connect func(ctx context.Context, settings map[string]interface{}) (*pgx.Conn, error)
+}
+
+// NewPostgresRoleProvider creates the Postgres role provider.
+func NewPostgresRoleProvider() *PostgresRoleProvider {
+	return &PostgresRoleProvider{connect: postgresql.ConnectAdmin}
+}
+
+// Name implements Provider.
+func (p *PostgresRoleProvider) Name() string { return "postgres_role" }
+
+// Mint implements Provider.
+func (p *PostgresRoleProvider) Mint(ctx context.Context, req MintRequest) (*Lease, error) {
+	grants := grantRoles(req.Settings)
+	if len(grants) == 0 {
+		return nil, fmt.Errorf("%s is required for the postgres_role provider", GrantRolesSettingKey)
+	}
+	suffix, err := randomHex(6)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	role := "akmatori_tmp_" + suffix
+	expires := time.Now().Add(req.TTL)
+
+	conn, err := p.connect(ctx, req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, createRoleSQL(role, password, expires, grants)); err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	return &Lease{
+		ID:         role,
+		Settings:   map[string]interface{}{"pg_username": role, "pg_password": password},
+		ExpiresAt:  expires,
+		RevokeData: map[string]string{"role": role},
+	}, nil
+}
+
+// Revoke implements Provider.
+func (p *PostgresRoleProvider) Revoke(ctx context.Context, settings map[string]interface{}, lease *Lease) error {
+	role := lease.RevokeData["role"]
+	if role == "" {
+		return nil
+	}
+	conn, err := p.connect(ctx, settings)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1", role); err != nil {
+		return fmt.Errorf("failed to terminate sessions of %s: %w", role, err)
+	}
+	if _, err := conn.Exec(ctx, "DROP ROLE IF EXISTS "+pgx.Identifier{role}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to drop role %s: %w", role, err)
+	}
+	return nil
+}
+
+// createRoleSQL builds the CREATE ROLE statement. DDL cannot take bind
+// parameters; role names are quoted as identifiers and the password and
+// timestamp are generated here, never taken from input.
+func createRoleSQL(role, password string, validUntil time.Time, grants []string) string {
+	quoted := make([]string, len(grants))
+	for i, g := range grants {
+		quoted[i] = pgx.Identifier{g}.Sanitize()
+	}
+	return fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD '%s' VALID UNTIL '%s' IN ROLE %s",
+		pgx.Identifier{role}.Sanitize(), password, validUntil.UTC().Format(time.RFC3339), strings.Join(quoted, ", "))
+}
+
+func grantRoles(settings map[string]interface{}) []string {
+	raw, _ := settings[GrantRolesSettingKey].(string)
+	var roles []string
+	for _, r := range strings.Split(raw, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -61,7 +62,7 @@ type ToolInstance struct {
 	ToolTypeID  uint      `gorm:"not null;index" json:"tool_type_id"`
 	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
 	LogicalName string    `gorm:"uniqueIndex;size:128" json:"logical_name"`
-	Settings    JSONB     `gorm:"type:jsonb" json:"settings"`
+	Settings    JSONB     `gorm:"type:jsonb;serializer:secret" json:"settings"`
 	Enabled     bool      `gorm:"default:true" json:"enabled"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -105,7 +106,6 @@ type Incident struct {
 	WorkingDir      string     `json:"working_dir"`
 	SlackChannelID  string     `gorm:"column:slack_channel_id" json:"slack_channel_id"`
 	SlackMessageTS  string     `gorm:"column:slack_message_ts" json:"slack_message_ts"`
-	FullLog         string     `gorm:"type:text" json:"full_log"`
 	Response        string     `gorm:"type:text" json:"response"`
 	TokensUsed      int        `json:"tokens_used"`
 	ExecutionTimeMs int64      `json:"execution_time_ms"`
@@ -119,6 +119,22 @@ func (Incident) TableName() string {
 	return "incidents"
 }
 
+// IncidentLogChunk is one piece of an incident's agent log, which the API
+// stores outside the incidents row. Chunks are read in Seq order.
+type IncidentLogChunk struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	IncidentUUID string    `gorm:"size:36;not null;uniqueIndex:idx_incident_log_chunks_seq,priority:1" json:"incident_uuid"`
+	Seq          int       `gorm:"not null;uniqueIndex:idx_incident_log_chunks_seq,priority:2" json:"seq"`
+	StartOffset  int       `gorm:"not null" json:"start_offset"`
+	Length       int       `gorm:"not null" json:"length"`
+	Content      string    `gorm:"type:text" json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (IncidentLogChunk) TableName() string {
+	return "incident_log_chunks"
+}
+
 // Connect establishes a database connection
 func Connect(dsn string, logLevel logger.LogLevel) error {
 	config := &gorm.Config{
@@ -248,18 +264,91 @@ func GetToolCredentialsByLogicalName(ctx context.Context, logicalName string, ex
 	}, nil
 }
 
+// CredentialMinter exchanges an instance's stored settings for short-lived
+// credentials minted for the incident. Instances that are not configured for
+// ephemeral credentials are returned unchanged.
+type CredentialMinter interface {
+	Apply(ctx context.Context, incidentID string, creds *ToolCredentials) (*ToolCredentials, error)
+}
+
+var credentialMinter CredentialMinter
+
+// SetCredentialMinter installs the minter applied by ResolveToolCredentials.
+// Passing nil disables ephemeral credentials.
+func SetCredentialMinter(m CredentialMinter) {
+	credentialMinter = m
+}
+
+// SecretResolver replaces references to external secret stores (e.g.
+// vault:secret/data/zabbix#token) in an instance's settings with the
+// secrets' values.
+type SecretResolver interface {
+	Resolve(ctx context.Context, settings map[string]interface{}) (map[string]interface{}, error)
+}
+
+var secretResolver SecretResolver
+
+// SetSecretResolver installs the resolver applied by ResolveToolCredentials.
+// Passing nil leaves references in settings unresolved.
+func SetSecretResolver(r SecretResolver) {
+	secretResolver = r
+}
+
+// LookupToolCredentials picks the instance a call addresses, with the
+// priority described at ResolveToolCredentials, and returns its stored
+// settings as they are: secret references stay unresolved and no
+// credentials are minted.
+func LookupToolCredentials(ctx context.Context, incidentID string, toolType string, instanceID *uint, logicalName string) (*ToolCredentials, error) {
+	switch {
+	case instanceID != nil && *instanceID > 0:
+		return GetToolCredentialsByInstanceID(ctx, *instanceID, toolType)
+	case logicalName != "":
+		return GetToolCredentialsByLogicalName(ctx, logicalName, toolType)
+	default:
+		return GetToolCredentialsForIncident(ctx, incidentID, toolType)
+	}
+}
+
 // ResolveToolCredentials resolves tool credentials with priority:
 // 1. Explicit instance ID (if provided and > 0)
 // 2. Logical name (if provided and non-empty)
 // 3. First enabled instance of the given tool type
+//
+// Secret references in the settings are then resolved by the SecretResolver
+// and the result passes through the CredentialMinter, if they are set.
 func ResolveToolCredentials(ctx context.Context, incidentID string, toolType string, instanceID *uint, logicalName string) (*ToolCredentials, error) {
-	if instanceID != nil && *instanceID > 0 {
-		return GetToolCredentialsByInstanceID(ctx, *instanceID, toolType)
+	creds, err := LookupToolCredentials(ctx, incidentID, toolType, instanceID, logicalName)
+	if err != nil {
+		return nil, err
 	}
-	if logicalName != "" {
-		return GetToolCredentialsByLogicalName(ctx, logicalName, toolType)
+	if secretResolver != nil {
+		settings, err := secretResolver.Resolve(ctx, creds.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secrets for %q: %w", creds.ToolName, err)
+		}
+		creds.Settings = settings
+	}
+	if credentialMinter == nil {
+		return creds, nil
 	}
-	return GetToolCredentialsForIncident(ctx, incidentID, toolType)
+	return credentialMinter.Apply(ctx, incidentID, creds)
+}
+
+// IsIncidentActive reports whether the incident is still pending or running.
+// Unknown incidents are reported as inactive.
+func IsIncidentActive(ctx context.Context, incidentUUID string) (bool, error) {
+	if incidentUUID == "" {
+		return false, nil
+	}
+	var incident Incident
+	err := DB.WithContext(ctx).Select("status").Where("uuid = ?", incidentUUID).First(&incident).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return incident.Status == "pending" || incident.Status == "running", nil
 }
 
 // GetToolInstanceByType returns a specific tool instance by type name
@@ -277,6 +366,120 @@ func GetToolInstanceByType(ctx context.Context, typeName string) (*ToolInstance,
 	return &instance, nil
 }
 
+// SSHKnownHost is a pinned SSH host key (mirrors main app model). The
+// gateway inserts rows when trusting a host on first use; the main API lists
+// and clears them.
+type SSHKnownHost struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ToolInstanceID uint      `json:"tool_instance_id"`
+	Host           string    `json:"host"`
+	KeyType        string    `json:"key_type"`
+	PublicKey      string    `json:"public_key"`
+	Fingerprint    string    `json:"fingerprint"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+}
+
+func (SSHKnownHost) TableName() string {
+	return "ssh_known_hosts"
+}
+
+// GetSSHKnownHost returns the pinned key for host on a tool instance, or nil
+// when none is pinned.
+func GetSSHKnownHost(ctx context.Context, toolInstanceID uint, host string) (*SSHKnownHost, error) {
+	var row SSHKnownHost
+	err := DB.WithContext(ctx).Where("tool_instance_id = ? AND host = ?", toolInstanceID, host).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &row, nil
+}
+
+// PinSSHKnownHost stores row unless a key is already pinned for the same
+// instance and host, in which case the existing pin wins.
+func PinSSHKnownHost(ctx context.Context, row *SSHKnownHost) error {
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error
+}
+
+// TouchSSHKnownHost records that a pinned key was just seen.
+func TouchSSHKnownHost(ctx context.Context, id uint) error {
+	return DB.WithContext(ctx).Model(&SSHKnownHost{}).Where("id = ?", id).Update("last_seen_at", time.Now()).Error
+}
+
+// IncidentChange is one entry of an incident's change manifest (mirrors
+// main app model). The gateway records remote writes as the tool call runs;
+// the main API adds workspace changes on completion and serves both.
+type IncidentChange struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	IncidentUUID string    `json:"incident_uuid"`
+	Location     string    `json:"location"`
+	Path         string    `json:"path,omitempty"`
+	Action       string    `json:"action"`
+	Tool         string    `json:"tool,omitempty"`
+	Detail       string    `json:"detail,omitempty"`
+	SizeBytes    int64     `json:"size_bytes"`
+	SHA256       string    `gorm:"column:sha256" json:"sha256,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (IncidentChange) TableName() string {
+	return "incident_changes"
+}
+
+// RecordIncidentChange appends row to its incident's change manifest.
+func RecordIncidentChange(ctx context.Context, row *IncidentChange) error {
+	return DB.WithContext(ctx).Create(row).Error
+}
+
+// ToolApproval is a human-in-the-loop gate on one destructive tool call
+// (mirrors main app model). The gateway creates it and polls for the
+// decision; the main API posts it to Slack and records the decision.
+type ToolApproval struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UUID         string     `json:"uuid"`
+	IncidentUUID string     `json:"incident_uuid"`
+	ToolName     string     `json:"tool_name"`
+	Target       string     `json:"target"`
+	Detail       string     `json:"detail"`
+	Status       string     `json:"status"`
+	DecidedBy    string     `json:"decided_by,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (ToolApproval) TableName() string {
+	return "tool_approvals"
+}
+
+// CreateToolApproval inserts a pending approval request.
+func CreateToolApproval(ctx context.Context, approval *ToolApproval) error {
+	return DB.WithContext(ctx).Create(approval).Error
+}
+
+// GetToolApproval loads an approval by UUID.
+func GetToolApproval(ctx context.Context, uuid string) (*ToolApproval, error) {
+	var approval ToolApproval
+	if err := DB.WithContext(ctx).Where("uuid = ?", uuid).First(&approval).Error; err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// ExpireToolApproval marks a still-pending approval expired. It reports
+// whether it did; false means a decision landed first.
+func ExpireToolApproval(ctx context.Context, uuid string) (bool, error) {
+	res := DB.WithContext(ctx).Model(&ToolApproval{}).
+		Where("uuid = ? AND status = ?", uuid, "pending").
+		Update("status", "expired")
+	return res.RowsAffected > 0, res.Error
+}
+
 // HTTPConnector represents a declarative HTTP connector definition (mirrors main app model)
 type HTTPConnector struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
@@ -318,6 +521,9 @@ type ProxySettings struct {
 	NetBoxEnabled          bool      `gorm:"default:false" json:"netbox_enabled"`
 	K8sEnabled             bool      `gorm:"column:k8s_enabled;default:false" json:"k8s_enabled"`
 	JiraEnabled            bool      `gorm:"default:false" json:"jira_enabled"`
+	PrometheusEnabled      bool      `gorm:"default:false" json:"prometheus_enabled"`
+	LogSearchEnabled       bool      `gorm:"default:false" json:"log_search_enabled"`
+	HTTPCheckEnabled       bool      `gorm:"default:false" json:"http_check_enabled"`
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
 }
@@ -344,9 +550,9 @@ type MCPServerConfig struct {
 	URL             string    `gorm:"size:512" json:"url,omitempty"`
 	Command         string    `gorm:"size:512" json:"command,omitempty"`
 	Args            JSONB     `gorm:"type:jsonb" json:"args,omitempty"`
-	EnvVars         JSONB     `gorm:"type:jsonb" json:"env_vars,omitempty"`
+	EnvVars         JSONB     `gorm:"type:jsonb;serializer:secret" json:"env_vars,omitempty"`
 	NamespacePrefix string    `gorm:"size:128;not null" json:"namespace_prefix"`
-	AuthConfig      JSONB     `gorm:"type:jsonb" json:"auth_config,omitempty"`
+	AuthConfig      JSONB     `gorm:"type:jsonb;serializer:secret" json:"auth_config,omitempty"`
 	Enabled         bool      `gorm:"default:true" json:"enabled"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
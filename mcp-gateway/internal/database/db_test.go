@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/akmatori/mcp-gateway/internal/secrets"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -142,3 +144,64 @@ func TestResolveToolCredentials_FallbackToTypeDefault(t *testing.T) {
 		t.Errorf("expected tool_type 'ssh', got %q", creds.ToolType)
 	}
 }
+
+func TestGetToolCredentials_DecryptsSettings(t *testing.T) {
+	setupTestDB(t)
+	inst := seedToolInstance(t, "Zabbix", "zabbix", "zabbix", true)
+	// Written by the API with key bytes 0..31.
+	encrypted := `{"zabbix_url": "https://zbx", "zabbix_token": "enc:v1:630dcd29:h8G0wbewEnEvlZr+YuTvFX2OmZ72nvUEm2ph/HwBydMOShohVyIj2w=="}`
+	if err := DB.Exec("UPDATE tool_instances SET settings = ? WHERE id = ?", encrypted, inst.ID).Error; err != nil {
+		t.Fatalf("failed to store encrypted settings: %v", err)
+	}
+	ctx := context.Background()
+
+	SetSecretKeyring(nil)
+	if _, err := GetToolCredentialsByLogicalName(ctx, "zabbix", "zabbix"); !errors.Is(err, secrets.ErrNoKey) {
+		t.Fatalf("expected ErrNoKey without a keyring, got %v", err)
+	}
+
+	keyring, err := secrets.LoadKeyring("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", nil)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	SetSecretKeyring(keyring)
+	t.Cleanup(func() { SetSecretKeyring(nil) })
+
+	creds, err := GetToolCredentialsByLogicalName(ctx, "zabbix", "zabbix")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Settings["zabbix_token"] != "s3cret-token" || creds.Settings["zabbix_url"] != "https://zbx" {
+		t.Errorf("settings = %v, want the token decrypted", creds.Settings)
+	}
+}
+
+func TestGetMCPServerConfigByID_DecryptsEnvVars(t *testing.T) {
+	setupTestDB(t)
+	if err := DB.AutoMigrate(&MCPServerConfig{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	cfg := MCPServerConfig{Name: "github", Transport: "stdio", Command: "github-mcp", NamespacePrefix: "ext.github"}
+	if err := DB.Create(&cfg).Error; err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	// Written by the API with key bytes 0..31; every env var is encrypted.
+	encrypted := `{"GITHUB_PAT": "enc:v1:630dcd29:h8G0wbewEnEvlZr+YuTvFX2OmZ72nvUEm2ph/HwBydMOShohVyIj2w=="}`
+	if err := DB.Exec("UPDATE mcp_server_configs SET env_vars = ? WHERE id = ?", encrypted, cfg.ID).Error; err != nil {
+		t.Fatalf("failed to store encrypted env vars: %v", err)
+	}
+	keyring, err := secrets.LoadKeyring("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", nil)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	SetSecretKeyring(keyring)
+	t.Cleanup(func() { SetSecretKeyring(nil) })
+
+	got, err := GetMCPServerConfigByID(context.Background(), cfg.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.EnvVars["GITHUB_PAT"] != "s3cret-token" {
+		t.Errorf("env_vars = %v, want the token decrypted", got.EnvVars)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/akmatori/mcp-gateway/internal/secrets"
+	"gorm.io/gorm/schema"
+)
+
+// Tool instance settings and MCP server environment and auth hold
+// credentials the API encrypts at rest (serializer:secret); they are
+// decrypted here as they are read.
+func init() {
+	schema.RegisterSerializer("secret", secretSerializer{})
+}
+
+var secretKeyring atomic.Pointer[secrets.Keyring]
+
+// SetSecretKeyring sets the keyring for encrypted tool settings. Without one
+// only plaintext settings can be read.
+func SetSecretKeyring(k *secrets.Keyring) {
+	secretKeyring.Store(k)
+}
+
+type secretSerializer struct{}
+
+// Scan decodes a JSONB column and decrypts the secrets in it.
+func (secretSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	obj := map[string]interface{}{}
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		if err := json.Unmarshal(v, &obj); err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+	case string:
+		if err := json.Unmarshal([]byte(v), &obj); err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+	default:
+		return fmt.Errorf("unsupported type %T for secret column %s", dbValue, field.DBName)
+	}
+	opened, err := secretKeyring.Load().DecryptTree(obj)
+	if err != nil {
+		return fmt.Errorf("%s: %w", field.DBName, err)
+	}
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(JSONB(opened.(map[string]interface{}))))
+	return nil
+}
+
+// Value stores the settings as they are. The API owns these columns and
+// encrypts what it writes; the gateway itself only writes them when seeding
+// test data.
+func (secretSerializer) Value(_ context.Context, _ *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	if v, ok := fieldValue.(JSONB); ok {
+		return v.Value()
+	}
+	return json.Marshal(fieldValue)
+}
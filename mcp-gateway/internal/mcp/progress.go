@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressReporter receives incremental output from a running tool call.
+type ProgressReporter func(message string)
+
+type progressReporterKey struct{}
+type notifierKey struct{}
+
+// notifier delivers a server-initiated notification on the connection that
+// carried the request. Only streaming transports (SSE) install one.
+type notifier func(Notification)
+
+// WithProgressReporter returns a context whose tool calls report progress to fn.
+func WithProgressReporter(ctx context.Context, fn ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, fn)
+}
+
+// ReportProgress sends message as a progress update for the tool call running
+// under ctx. It reports whether anyone is listening, so tools can skip
+// building messages for callers that did not ask for progress.
+func ReportProgress(ctx context.Context, message string) bool {
+	fn, ok := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	if !ok || fn == nil {
+		return false
+	}
+	fn(message)
+	return true
+}
+
+// ProgressEnabled reports whether the tool call running under ctx has a
+// progress listener.
+func ProgressEnabled(ctx context.Context) bool {
+	fn, ok := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return ok && fn != nil
+}
+
+func withNotifier(ctx context.Context, fn notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, fn)
+}
+
+// progressContext wires MCP progress notifications for a tools/call whose
+// params carry a progressToken, when the transport can deliver them.
+func progressContext(ctx context.Context, params *CallToolParams) context.Context {
+	if params.Meta == nil || params.Meta.ProgressToken == nil {
+		return ctx
+	}
+	notify, ok := ctx.Value(notifierKey{}).(notifier)
+	if !ok || notify == nil {
+		return ctx
+	}
+
+	token := params.Meta.ProgressToken
+	var mu sync.Mutex
+	var progress float64
+	return WithProgressReporter(ctx, func(message string) {
+		// Progress must increase with every notification; tools may report
+		// from several goroutines at once.
+		mu.Lock()
+		progress++
+		n := progress
+		mu.Unlock()
+		notify(NewNotification("notifications/progress", ProgressParams{
+			ProgressToken: token,
+			Progress:      n,
+			Message:       message,
+		}))
+	})
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sseMessages returns the JSON payloads of the "message" events in an SSE body.
+func sseMessages(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var msgs []map[string]interface{}
+	for _, event := range strings.Split(body, "\n\n") {
+		if !strings.HasPrefix(event, "event: message\n") {
+			continue
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(event, "event: message\ndata: ")), &msg); err != nil {
+			t.Fatalf("invalid SSE payload %q: %v", event, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestSSE_ToolProgressNotifications(t *testing.T) {
+	s := newTestServer()
+	s.RegisterTool(Tool{Name: "ssh.execute_command", InputSchema: InputSchema{Type: "object"}},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			ReportProgress(ctx, "line 1\n")
+			ReportProgress(ctx, "line 2\n")
+			return "done", nil
+		})
+
+	call := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"ssh.execute_command","_meta":{"progressToken":"tok-1"}}}`
+	req := httptest.NewRequest("POST", "/sse", strings.NewReader(call+"\n"))
+	w := httptest.NewRecorder()
+	s.HandleHTTP(w, req)
+
+	msgs := sseMessages(t, w.Body.String())
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 2 progress notifications and the result: %s", len(msgs), w.Body.String())
+	}
+	for i, want := range []string{"line 1\n", "line 2\n"} {
+		if msgs[i]["method"] != "notifications/progress" {
+			t.Fatalf("message %d = %v, want a progress notification", i, msgs[i])
+		}
+		params := msgs[i]["params"].(map[string]interface{})
+		if params["progressToken"] != "tok-1" || params["message"] != want || params["progress"] != float64(i+1) {
+			t.Errorf("progress %d params = %v", i, params)
+		}
+	}
+	if msgs[2]["id"] != float64(7) || msgs[2]["result"] == nil {
+		t.Errorf("final message = %v, want the tools/call result", msgs[2])
+	}
+}
+
+func TestReportProgress_WithoutTokenOrTransport(t *testing.T) {
+	var reported bool
+	s := newTestServer()
+	s.RegisterTool(Tool{Name: "ssh.execute_command", InputSchema: InputSchema{Type: "object"}},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			reported = ReportProgress(ctx, "chunk")
+			return "done", nil
+		})
+
+	// Plain HTTP cannot carry notifications, even with a progress token.
+	resp := sendJSONRPCWithHeaders(t, s, "tools/call",
+		CallToolParams{Name: "ssh.execute_command", Meta: &RequestMeta{ProgressToken: 1}}, nil)
+	if resp.Error != nil {
+		t.Fatalf("call failed: %s", resp.Error.Message)
+	}
+	if reported {
+		t.Error("ReportProgress reported a listener on a non-streaming transport")
+	}
+}
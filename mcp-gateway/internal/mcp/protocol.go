@@ -1,6 +1,9 @@
 package mcp
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // JSON-RPC 2.0 message types for MCP protocol
 
@@ -20,6 +23,13 @@ type Response struct {
 	Error   *Error      `json:"error,omitempty"`
 }
 
+// Notification represents a JSON-RPC 2.0 notification (a message without an ID)
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // Error represents a JSON-RPC 2.0 error
 type Error struct {
 	Code    int         `json:"code"`
@@ -93,6 +103,33 @@ type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description,omitempty"`
 	InputSchema InputSchema `json:"inputSchema"`
+
+	// OutputSchema describes the JSON the tool returns. When set, results
+	// that match it are also sent as structuredContent.
+	OutputSchema *Schema `json:"outputSchema,omitempty"`
+
+	// Timeout overrides DefaultToolCallTimeout for tools that legitimately
+	// run longer, such as long-running SSH commands. Not sent to clients.
+	Timeout time.Duration `json:"-"`
+
+	// Destructive marks a tool that changes the systems it reaches. Its
+	// calls pass the server's ToolApprover before the handler runs. Not
+	// sent to clients.
+	Destructive bool `json:"-"`
+
+	// WritesWhen narrows Destructive for tools whose arguments decide
+	// whether a call writes, such as a shell command. Nil means every call
+	// of a Destructive tool does.
+	WritesWhen func(args map[string]interface{}) bool `json:"-"`
+}
+
+// IsWriteCall reports whether a call with args needs approval under the
+// tool's Destructive and WritesWhen settings.
+func (t Tool) IsWriteCall(args map[string]interface{}) bool {
+	if !t.Destructive {
+		return false
+	}
+	return t.WritesWhen == nil || t.WritesWhen(args)
 }
 
 // InputSchema represents JSON schema for tool parameters
@@ -126,12 +163,31 @@ type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
 	Instance  string                 `json:"instance,omitempty"` // logical name hint from gateway_call
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta carries MCP request metadata. A ProgressToken asks the server
+// to send notifications/progress messages while the call runs.
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams represents notifications/progress params
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
 }
 
 // CallToolResult represents tools/call response
 type CallToolResult struct {
 	Content []Content `json:"content"`
 	IsError bool      `json:"isError,omitempty"`
+
+	// StructuredContent is the decoded result, present only when the tool
+	// declares an OutputSchema and the result validated against it.
+	StructuredContent map[string]interface{} `json:"structuredContent,omitempty"`
 }
 
 // Content represents tool result content (text or resource types per MCP spec)
@@ -178,6 +234,15 @@ func NewResponse(id interface{}, result interface{}) Response {
 	}
 }
 
+// NewNotification creates a JSON-RPC notification
+func NewNotification(method string, params interface{}) Notification {
+	return Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+}
+
 // ListToolsByTypeParams represents tools/list_by_type request params
 type ListToolsByTypeParams struct {
 	ToolType string `json:"tool_type"`
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type schemaTestResult struct {
+	Results []struct {
+		Server   string `json:"server"`
+		ExitCode int    `json:"exit_code"`
+	} `json:"results"`
+	Raw   json.RawMessage `json:"raw,omitempty"`
+	Error string          `json:"error,omitempty"`
+	skip  string
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(schemaTestResult{})
+	if s.Type != "object" || len(s.Required) != 1 || s.Required[0] != "results" {
+		t.Fatalf("schema = %+v, want an object requiring only results", s)
+	}
+	if _, ok := s.Properties["skip"]; ok {
+		t.Error("unexported field was included")
+	}
+	item := s.Properties["results"].Items
+	if item.Properties["exit_code"].Type != "integer" || item.Properties["server"].Type != "string" {
+		t.Errorf("item schema = %+v", item)
+	}
+	if s.Properties["raw"].Type != "" {
+		t.Errorf("raw JSON should accept any value, got type %q", s.Properties["raw"].Type)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := SchemaOf(schemaTestResult{})
+	cases := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"valid", `{"results":[{"server":"a","exit_code":0}],"extra":true}`, ""},
+		{"missing required", `{"error":"boom"}`, `$: missing required property "results"`},
+		{"null array", `{"results":null}`, "$.results: expected array, got null"},
+		{"wrong item type", `{"results":[{"server":"a","exit_code":"0"}]}`, "$.results[0].exit_code: expected integer, got string"},
+		{"fractional integer", `{"results":[{"server":"a","exit_code":1.5}]}`, "$.results[0].exit_code: expected integer"},
+	}
+	for _, tc := range cases {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tc.json), &v); err != nil {
+			t.Fatal(err)
+		}
+		err := s.Validate(v)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+
+	enum := &Schema{Type: "string", Enum: []string{"file", "dir"}}
+	if err := enum.Validate("socket"); err == nil {
+		t.Error("value outside enum was accepted")
+	}
+}
+
+func TestHandleCallTool_StructuredContent(t *testing.T) {
+	s := newTestServer()
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"result": {Type: "array", Items: &Schema{Type: "object"}}},
+		Required:   []string{"result"},
+	}
+	results := map[string]string{
+		"test.list":   `[{"hostid":"1"}]`,
+		"test.broken": `{"hosts":[]}`,
+	}
+	for name := range results {
+		s.RegisterTool(Tool{Name: name, InputSchema: InputSchema{Type: "object"}, OutputSchema: schema},
+			func(_ context.Context, _ string, _ map[string]interface{}) (interface{}, error) {
+				return results[name], nil
+			})
+	}
+
+	call := func(name string) CallToolResult {
+		t.Helper()
+		resp := sendJSONRPC(t, s, "tools/call", CallToolParams{Name: name})
+		if resp.Error != nil {
+			t.Fatalf("%s: %s", name, resp.Error.Message)
+		}
+		raw, _ := json.Marshal(resp.Result)
+		var result CallToolResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	listed := call("test.list")
+	hosts, ok := listed.StructuredContent["result"].([]interface{})
+	if !ok || len(hosts) != 1 {
+		t.Errorf("structuredContent = %+v, want the array wrapped under result", listed.StructuredContent)
+	}
+	if listed.Content[0].Text != results["test.list"] {
+		t.Errorf("text content = %q, want the raw result", listed.Content[0].Text)
+	}
+
+	broken := call("test.broken")
+	if broken.StructuredContent != nil {
+		t.Errorf("non-conforming result got structuredContent %+v", broken.StructuredContent)
+	}
+	if broken.IsError || broken.Content[0].Text != results["test.broken"] {
+		t.Errorf("non-conforming result should still be returned as text, got %+v", broken)
+	}
+}
@@ -7,17 +7,32 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/akmatori/mcp-gateway/internal/auth"
+	"github.com/akmatori/mcp-gateway/internal/metrics"
 )
 
+// DefaultToolCallTimeout bounds a tool call unless its Tool sets a longer Timeout.
+const DefaultToolCallTimeout = 5 * time.Minute
+
 // ToolHandler is a function that handles a tool call
 type ToolHandler func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error)
 
+// ToolInterceptor answers a tool call in place of the tool's handler. It
+// receives the tool name so one interceptor can serve every tool, and next,
+// the tool's own handler, for calls it lets through.
+type ToolInterceptor func(ctx context.Context, tool, incidentID string, args map[string]interface{}, next ToolHandler) (interface{}, error)
+
+// ToolApprover decides whether a write call to a Destructive tool may run.
+// It returns nil to let the call through and otherwise the error the caller
+// sees as the tool result. It may block while a human decides.
+type ToolApprover func(ctx context.Context, tool Tool, incidentID string, args map[string]interface{}) error
+
 // ToolDiscoverer provides tool listing and detail capabilities.
 type ToolDiscoverer interface {
 	ListToolsByType(toolType string) []ToolListItem
@@ -40,6 +55,9 @@ type Server struct {
 	instanceLookup  InstanceLookup
 	authorizer      *auth.Authorizer
 	proxyNamespaces map[string]bool
+	interceptor     ToolInterceptor
+	approver        ToolApprover
+	approvalWait    time.Duration
 }
 
 // NewServer creates a new MCP server
@@ -72,6 +90,27 @@ func (s *Server) SetAuthorizer(a *auth.Authorizer) {
 	s.authorizer = a
 }
 
+// SetToolInterceptor routes every tool call, including tools registered
+// later by connector or proxy reloads, to fn instead of the tool's handler.
+// Lookup, authorization and output-schema checks still apply. Used by
+// sandbox mode.
+func (s *Server) SetToolInterceptor(fn ToolInterceptor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interceptor = fn
+}
+
+// SetToolApprover gates every write call to a Destructive tool, including
+// tools registered later by connector or proxy reloads, on fn. maxWait is
+// the longest fn may block; it is added to those calls' timeout. Sandbox
+// fixtures answer calls without the gate, since they reach no real system.
+func (s *Server) SetToolApprover(fn ToolApprover, maxWait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approver = fn
+	s.approvalWait = maxWait
+}
+
 // AddProxyNamespace registers a namespace as belonging to an MCP proxy server.
 // Proxy namespaces bypass per-incident allowlist checks because they are
 // system-level tools not managed by the skill-based assignment system.
@@ -199,6 +238,20 @@ func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request, incidentID st
 	fmt.Fprintf(w, "event: open\ndata: {\"status\":\"connected\"}\n\n")
 	flusher.Flush()
 
+	// Tool calls may emit progress notifications from their own goroutines
+	// while the loop below writes responses, so all writes share a lock.
+	var writeMu sync.Mutex
+	ctx := withNotifier(r.Context(), func(n Notification) {
+		data, err := json.Marshal(n)
+		if err != nil {
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		flusher.Flush()
+	})
+
 	// Read messages from request body (for stdin-over-HTTP pattern)
 	scanner := bufio.NewScanner(r.Body)
 	for scanner.Scan() {
@@ -209,12 +262,16 @@ func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request, incidentID st
 
 		var req Request
 		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			writeMu.Lock()
 			s.sendSSEError(w, flusher, nil, ParseError, "Invalid JSON", err.Error())
+			writeMu.Unlock()
 			continue
 		}
 
-		resp := s.handleRequest(r.Context(), &req, incidentID)
+		resp := s.handleRequest(ctx, &req, incidentID)
+		writeMu.Lock()
 		s.sendSSEResponse(w, flusher, resp)
+		writeMu.Unlock()
 	}
 }
 
@@ -286,9 +343,16 @@ func (s *Server) handleCallTool(ctx context.Context, req *Request, incidentID st
 
 	s.mu.RLock()
 	handler, exists := s.handlers[params.Name]
+	tool := s.tools[params.Name]
+	timeout := tool.Timeout
+	outputSchema := tool.OutputSchema
+	interceptor := s.interceptor
+	approver, approvalWait := s.approver, s.approvalWait
 	s.mu.RUnlock()
 
 	if !exists {
+		// Unknown names come from the caller, so they share one series.
+		metrics.ToolCall("unknown", metrics.OutcomeNotFound, 0)
 		return NewErrorResponse(req.ID, MethodNotFound, fmt.Sprintf("Tool not found: %s", params.Name), nil)
 	}
 
@@ -319,6 +383,7 @@ func (s *Server) handleCallTool(ctx context.Context, req *Request, incidentID st
 			entries := s.authorizer.GetAllowlist(incidentID)
 
 			if !auth.IsAuthorizedFromEntries(entries, toolType, instanceID, logicalName) {
+				metrics.ToolCall(params.Name, metrics.OutcomeUnauthorized, 0)
 				return NewErrorResponse(req.ID, InvalidRequest,
 					fmt.Sprintf("Unauthorized: incident %s is not authorized to use tool %s", incidentID, params.Name),
 					nil)
@@ -363,20 +428,45 @@ func (s *Server) handleCallTool(ctx context.Context, req *Request, incidentID st
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	if timeout < DefaultToolCallTimeout {
+		timeout = DefaultToolCallTimeout
+	}
+	if approver != nil && tool.Destructive {
+		// The call may wait for a human before it starts.
+		timeout += approvalWait
+		handler = approvedHandler(tool, approver, handler)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
+	ctx = progressContext(ctx, &params)
 
-	s.logger.Printf("Calling tool: %s (incident: %s)", params.Name, incidentID)
+	// Call logs go through slog with the request context so they carry the
+	// request ID; tool loggers have no context and only see the incident.
+	slog.InfoContext(ctx, "calling tool", "tool", params.Name, "incident_uuid", incidentID)
 
-	result, err := handler(ctx, incidentID, params.Arguments)
+	start := time.Now()
+	var result interface{}
+	var err error
+	if interceptor != nil {
+		result, err = interceptor(ctx, params.Name, incidentID, params.Arguments, handler)
+	} else {
+		result, err = handler(ctx, incidentID, params.Arguments)
+	}
+	elapsed := time.Since(start)
 	if err != nil {
-		s.logger.Printf("Tool %s failed: %v", params.Name, err)
+		metrics.ToolCall(params.Name, metrics.OutcomeError, elapsed)
+		slog.WarnContext(ctx, "tool call failed", "tool", params.Name, "incident_uuid", incidentID,
+			"duration_ms", elapsed.Milliseconds(), "err", err)
 		return NewResponse(req.ID, CallToolResult{
 			Content: []Content{NewTextContent(fmt.Sprintf("Error: %v", err))},
 			IsError: true,
 		})
 	}
 
+	metrics.ToolCall(params.Name, metrics.OutcomeSuccess, elapsed)
+	slog.InfoContext(ctx, "tool call finished", "tool", params.Name, "incident_uuid", incidentID,
+		"duration_ms", elapsed.Milliseconds())
+
 	// Convert result to string if needed
 	var textResult string
 	switch v := result.(type) {
@@ -393,9 +483,21 @@ func (s *Server) handleCallTool(ctx context.Context, req *Request, incidentID st
 		}
 	}
 
-	return NewResponse(req.ID, CallToolResult{
+	callResult := CallToolResult{
 		Content: []Content{NewTextContent(textResult)},
-	})
+	}
+	// A result that breaks the tool's output contract is still returned as
+	// text; only the structured copy is withheld so consumers never see an
+	// unexpected shape.
+	if outputSchema != nil {
+		structured, err := structuredResult(outputSchema, textResult)
+		if err != nil {
+			s.logger.Printf("Tool %s result does not match its output schema: %v", params.Name, err)
+		} else {
+			callResult.StructuredContent = structured
+		}
+	}
+	return NewResponse(req.ID, callResult)
 }
 
 // handleListToolsByType handles the tools/list_by_type request
@@ -587,6 +689,19 @@ func (s *Server) sendSSEError(w http.ResponseWriter, flusher http.Flusher, id in
 	s.sendSSEResponse(w, flusher, resp)
 }
 
+// approvedHandler runs handler only for calls approver lets through, or
+// that do not write.
+func approvedHandler(tool Tool, approver ToolApprover, handler ToolHandler) ToolHandler {
+	return func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+		if tool.IsWriteCall(args) {
+			if err := approver(ctx, tool, incidentID, args); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, incidentID, args)
+	}
+}
+
 // ParseToolName parses a tool name into namespace (tool type) and action.
 // Splits on the last dot so multi-segment namespaces work correctly:
 //
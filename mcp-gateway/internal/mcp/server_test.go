@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -1734,3 +1735,100 @@ func TestAuthorization_NullAllowlistHeaderDoesNotBypass(t *testing.T) {
 		t.Errorf("expected error code %d, got %d", InvalidRequest, resp.Error.Code)
 	}
 }
+
+func TestToolInterceptor_AnswersInPlaceOfHandler(t *testing.T) {
+	s := newTestServer()
+	s.RegisterTool(Tool{
+		Name:        "zabbix.get_problems",
+		InputSchema: InputSchema{Type: "object"},
+	}, func(context.Context, string, map[string]interface{}) (interface{}, error) {
+		t.Fatal("real handler called")
+		return nil, nil
+	})
+	s.RegisterTool(Tool{
+		Name:        "incidents.list",
+		InputSchema: InputSchema{Type: "object"},
+	}, echoHandler)
+	s.SetToolInterceptor(func(ctx context.Context, tool, incidentID string, args map[string]interface{}, next ToolHandler) (interface{}, error) {
+		if tool == "incidents.list" {
+			return next(ctx, incidentID, args)
+		}
+		return "canned " + tool, nil
+	})
+
+	for tool, want := range map[string]string{
+		"zabbix.get_problems": "canned zabbix.get_problems",
+		"incidents.list":      "ok",
+	} {
+		resp := sendJSONRPC(t, s, "tools/call", CallToolParams{Name: tool})
+		if resp.Error != nil {
+			t.Fatalf("%s: %s", tool, resp.Error.Message)
+		}
+		resultBytes, _ := json.Marshal(resp.Result)
+		var result CallToolResult
+		if err := json.Unmarshal(resultBytes, &result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Content) != 1 || result.Content[0].Text != want {
+			t.Errorf("%s: content = %+v, want %q", tool, result.Content, want)
+		}
+	}
+}
+
+func TestToolApprover_GatesWriteCalls(t *testing.T) {
+	s := newTestServer()
+	ran := map[string]int{}
+	handler := func(name string) ToolHandler {
+		return func(context.Context, string, map[string]interface{}) (interface{}, error) {
+			ran[name]++
+			return "ok", nil
+		}
+	}
+	s.RegisterTool(Tool{Name: "k8s.get_pods", InputSchema: InputSchema{Type: "object"}}, handler("read"))
+	s.RegisterTool(Tool{Name: "k8s.restart_deployment", InputSchema: InputSchema{Type: "object"}, Destructive: true}, handler("restart"))
+	s.RegisterTool(Tool{
+		Name:        "ssh.execute_command",
+		InputSchema: InputSchema{Type: "object"},
+		Destructive: true,
+		WritesWhen: func(args map[string]interface{}) bool {
+			return args["command"] == "reboot"
+		},
+	}, handler("exec"))
+
+	var gated []string
+	s.SetToolApprover(func(_ context.Context, tool Tool, _ string, _ map[string]interface{}) error {
+		gated = append(gated, tool.Name)
+		return errors.New("rejected by bob")
+	}, time.Minute)
+
+	call := func(name string, args map[string]interface{}) CallToolResult {
+		t.Helper()
+		resp := sendJSONRPC(t, s, "tools/call", CallToolParams{Name: name, Arguments: args})
+		if resp.Error != nil {
+			t.Fatalf("%s: %s", name, resp.Error.Message)
+		}
+		resultBytes, _ := json.Marshal(resp.Result)
+		var result CallToolResult
+		if err := json.Unmarshal(resultBytes, &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if res := call("k8s.get_pods", nil); res.IsError || ran["read"] != 1 {
+		t.Errorf("read tool: %+v, ran %d", res, ran["read"])
+	}
+	if res := call("ssh.execute_command", map[string]interface{}{"command": "uptime"}); res.IsError || ran["exec"] != 1 {
+		t.Errorf("read-only command: %+v, ran %d", res, ran["exec"])
+	}
+	if res := call("ssh.execute_command", map[string]interface{}{"command": "reboot"}); !res.IsError || ran["exec"] != 1 {
+		t.Errorf("rejected command: %+v, ran %d", res, ran["exec"])
+	}
+	res := call("k8s.restart_deployment", nil)
+	if !res.IsError || ran["restart"] != 0 || !strings.Contains(res.Content[0].Text, "rejected by bob") {
+		t.Errorf("rejected restart: %+v, ran %d", res, ran["restart"])
+	}
+	if strings.Join(gated, ",") != "ssh.execute_command,k8s.restart_deployment" {
+		t.Errorf("gated = %v", gated)
+	}
+}
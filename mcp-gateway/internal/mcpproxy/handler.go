@@ -173,7 +173,7 @@ func (h *ProxyHandler) registerServer(ctx context.Context, reg ServerRegistratio
 	// Create rate limiter for this server if not exists
 	h.mu.Lock()
 	if _, exists := h.limiters[reg.InstanceID]; !exists {
-		h.limiters[reg.InstanceID] = ratelimit.New(DefaultProxyRatePerSecond, DefaultProxyBurstCapacity)
+		h.limiters[reg.InstanceID] = ratelimit.New(DefaultProxyRatePerSecond, DefaultProxyBurstCapacity).Named("mcp_proxy")
 	}
 
 	for _, tool := range tools {
@@ -233,7 +233,7 @@ func (h *ProxyHandler) CallTool(ctx context.Context, toolName string, args map[s
 	// which tools are read-only.
 	result, err := h.pool.CallTool(ctx, entry.instanceID, entry.originalName, args)
 	if err != nil {
-		h.logger.Error("proxy tool call failed",
+		h.logger.ErrorContext(ctx, "proxy tool call failed",
 			"tool", toolName,
 			"original_name", entry.originalName,
 			"instance_id", entry.instanceID,
@@ -282,20 +282,20 @@ func (h *ProxyHandler) findSystemRegistrationByID(instanceID uint) (ServerRegist
 // retrySystemCallOnce re-registers a system server and retries the tool call once.
 // Returns the call result on success, or an error if re-registration or the retry fails.
 func (h *ProxyHandler) retrySystemCallOnce(ctx context.Context, reg ServerRegistration, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error) {
-	h.logger.Info("attempting on-demand re-registration for system server",
+	h.logger.InfoContext(ctx, "attempting on-demand re-registration for system server",
 		"namespace", reg.NamespacePrefix,
 		"tool", toolName,
 	)
 
 	if err := h.registerServer(ctx, reg); err != nil {
-		h.logger.Warn("on-demand re-registration failed",
+		h.logger.WarnContext(ctx, "on-demand re-registration failed",
 			"namespace", reg.NamespacePrefix,
 			"error", err,
 		)
 		return nil, err
 	}
 
-	h.logger.Info("on-demand re-registration succeeded, retrying tool call",
+	h.logger.InfoContext(ctx, "on-demand re-registration succeeded, retrying tool call",
 		"namespace", reg.NamespacePrefix,
 		"tool", toolName,
 	)
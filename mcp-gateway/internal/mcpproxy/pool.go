@@ -17,6 +17,7 @@ import (
 
 	"github.com/akmatori/mcp-gateway/internal/cache"
 	"github.com/akmatori/mcp-gateway/internal/mcp"
+	"github.com/akmatori/mcp-gateway/internal/requestid"
 )
 
 const (
@@ -185,7 +186,7 @@ func NewPool(opts ...PoolOption) *MCPConnectionPool {
 	p := &MCPConnectionPool{
 		connections:          make(map[uint]*MCPConnection),
 		configs:              make(map[uint]MCPServerConfig),
-		schemaCache:          cache.New(DefaultSchemaCacheTTL, DefaultCleanupInterval),
+		schemaCache:          cache.New(DefaultSchemaCacheTTL, DefaultCleanupInterval).Named("mcp_proxy_schema").Shared(cache.JSON[[]mcp.Tool]()),
 		idleTimeout:          DefaultIdleTimeout,
 		stopCleanup:          make(chan struct{}),
 		stopRefresh:          make(chan struct{}),
@@ -330,7 +331,7 @@ func (p *MCPConnectionPool) CallTool(ctx context.Context, instanceID uint, toolN
 		if err != nil {
 			// Transient connect failures should be retried with backoff
 			// rather than failing immediately.
-			p.logger.Warn("idle reconnect failed, attempting retries",
+			p.logger.WarnContext(ctx, "idle reconnect failed, attempting retries",
 				"instance_id", instanceID,
 				"tool", toolName,
 				"error", err,
@@ -350,7 +351,7 @@ func (p *MCPConnectionPool) CallTool(ctx context.Context, instanceID uint, toolN
 		if !isTransientError(callErr) {
 			return nil, callErr
 		}
-		p.logger.Warn("transient error calling tool after idle reconnect, attempting retries",
+		p.logger.WarnContext(ctx, "transient error calling tool after idle reconnect, attempting retries",
 			"instance_id", instanceID,
 			"tool", toolName,
 			"error", callErr,
@@ -376,7 +377,7 @@ func (p *MCPConnectionPool) CallTool(ctx context.Context, instanceID uint, toolN
 		return nil, err
 	}
 
-	p.logger.Warn("transient error calling tool, attempting reconnect",
+	p.logger.WarnContext(ctx, "transient error calling tool, attempting reconnect",
 		"instance_id", instanceID,
 		"tool", toolName,
 		"error", err,
@@ -407,7 +408,7 @@ func (p *MCPConnectionPool) retryWithBackoff(ctx context.Context, instanceID uin
 		newConn, connErr := p.GetOrConnect(ctx, instanceID, config)
 		if connErr != nil {
 			lastErr = connErr
-			p.logger.Warn("reconnect attempt failed",
+			p.logger.WarnContext(ctx, "reconnect attempt failed",
 				"instance_id", instanceID,
 				"attempt", attempt,
 				"error", connErr,
@@ -419,7 +420,7 @@ func (p *MCPConnectionPool) retryWithBackoff(ctx context.Context, instanceID uin
 		// Retry the tool call
 		result, retryErr := newConn.callTool(ctx, toolName, args)
 		if retryErr == nil {
-			p.logger.Info("reconnect successful, tool call succeeded",
+			p.logger.InfoContext(ctx, "reconnect successful, tool call succeeded",
 				"instance_id", instanceID,
 				"attempt", attempt,
 			)
@@ -427,7 +428,7 @@ func (p *MCPConnectionPool) retryWithBackoff(ctx context.Context, instanceID uin
 		}
 
 		lastErr = retryErr
-		p.logger.Warn("tool call failed after reconnect",
+		p.logger.WarnContext(ctx, "tool call failed after reconnect",
 			"instance_id", instanceID,
 			"attempt", attempt,
 			"error", retryErr,
@@ -826,6 +827,9 @@ func (c *MCPConnection) sendSSERequest(ctx context.Context, method string, param
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json, text/event-stream")
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 
 	// Include MCP session ID if one was established during initialize
 	c.mu.RLock()
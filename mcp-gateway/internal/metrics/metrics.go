@@ -0,0 +1,158 @@
+// Package metrics holds the Prometheus collectors exported on the gateway's
+// /metrics endpoint.
+//
+// Collectors live in a dedicated registry rather than the global default so
+// tests and library code cannot leak series into the endpoint.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mcp_gateway"
+
+// Tool call outcomes.
+const (
+	OutcomeSuccess      = "success"
+	OutcomeError        = "error"
+	OutcomeUnauthorized = "unauthorized"
+	OutcomeNotFound     = "not_found"
+)
+
+// Registry is the registry served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "tools/call requests, by tool and outcome (success, error, unauthorized, not_found).",
+	}, []string{"tool", "outcome"})
+
+	toolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Tool handler latency, by tool and outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"tool", "outcome"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Lookups in named tool caches, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	rateLimitWaits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_waits_total",
+		Help:      "Calls that had to wait for a rate-limit token, by limiter.",
+	}, []string{"limiter"})
+
+	rateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Calls refused by a rate limiter or cancelled while waiting for a token, by limiter.",
+	}, []string{"limiter"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls,
+		toolCallDuration,
+		cacheLookups,
+		rateLimitWaits,
+		rateLimitRejections,
+		httpRequestDuration,
+	)
+}
+
+// ToolCall records one tools/call of tool ending in outcome. elapsed is the
+// handler's run time; calls rejected before the handler ran pass zero and
+// are counted without a latency sample.
+func ToolCall(tool, outcome string, elapsed time.Duration) {
+	toolCalls.WithLabelValues(tool, outcome).Inc()
+	if elapsed > 0 {
+		toolCallDuration.WithLabelValues(tool, outcome).Observe(elapsed.Seconds())
+	}
+}
+
+// CacheLookup records a hit or miss in the named cache.
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// RateLimitWait records a call that found limiter's bucket empty.
+func RateLimitWait(limiter string) {
+	rateLimitWaits.WithLabelValues(limiter).Inc()
+}
+
+// RateLimitRejection records a call that limiter turned away.
+func RateLimitRejection(limiter string) {
+	rateLimitRejections.WithLabelValues(limiter).Inc()
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// InstrumentHTTP records the latency of every request served by mux. The
+// route label is the matched ServeMux pattern, so /tools/{name} lookups share
+// one series; unmatched paths are labelled "unmatched".
+func InstrumentHTTP(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		httpRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(p)
+}
+
+// Flush lets the /sse endpoint stream through the recorder.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestToolCall_CountsOutcomesAndLatency(t *testing.T) {
+	before := testutil.ToFloat64(toolCalls.WithLabelValues("zabbix.get_hosts", OutcomeSuccess))
+	ToolCall("zabbix.get_hosts", OutcomeSuccess, 120*time.Millisecond)
+	ToolCall("zabbix.get_hosts", OutcomeUnauthorized, 0)
+
+	if got := testutil.ToFloat64(toolCalls.WithLabelValues("zabbix.get_hosts", OutcomeSuccess)) - before; got != 1 {
+		t.Errorf("success calls = %v, want 1", got)
+	}
+	if n := testutil.CollectAndCount(toolCallDuration, "mcp_gateway_tool_call_duration_seconds"); n == 0 {
+		t.Error("no latency series recorded for the successful call")
+	}
+}
+
+func TestHandler_ServesGatewayMetrics(t *testing.T) {
+	CacheLookup("zabbix_response", true)
+	RateLimitRejection("zabbix")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`mcp_gateway_cache_lookups_total{cache="zabbix_response",result="hit"}`,
+		`mcp_gateway_rate_limit_rejections_total{limiter="zabbix"}`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics lacks %s", want)
+		}
+	}
+}
+
+func TestInstrumentHTTP_LabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tools/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	h := InstrumentHTTP(mux, mux)
+
+	for _, name := range []string{"a", "b"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tools/"+name, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	if n := testutil.CollectAndCount(httpRequestDuration, "mcp_gateway_http_request_duration_seconds"); n != 2 {
+		t.Errorf("series = %d, want one for /tools/ and one for unmatched", n)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/metrics"
 )
 
 // Limiter implements a token bucket rate limiter
@@ -13,6 +15,7 @@ type Limiter struct {
 	maxTokens  float64
 	refillRate float64 // tokens per second
 	lastRefill time.Time
+	name       string // metrics label; empty disables wait/rejection counting
 }
 
 // New creates a new rate limiter with the specified rate (requests per second)
@@ -26,6 +29,13 @@ func New(ratePerSecond float64, burstCapacity int) *Limiter {
 	}
 }
 
+// Named sets the name the limiter reports waits and rejections under on
+// /metrics and returns the limiter. Unnamed limiters are not counted.
+func (l *Limiter) Named(name string) *Limiter {
+	l.name = name
+	return l
+}
+
 // refill adds tokens based on elapsed time since last refill
 func (l *Limiter) refill() {
 	now := time.Now()
@@ -49,13 +59,16 @@ func (l *Limiter) Allow() bool {
 		l.tokens--
 		return true
 	}
+	if l.name != "" {
+		metrics.RateLimitRejection(l.name)
+	}
 	return false
 }
 
 // Wait blocks until a token is available or the context is cancelled
 // Returns nil if a token was acquired, or the context error if cancelled
 func (l *Limiter) Wait(ctx context.Context) error {
-	for {
+	for waited := false; ; waited = true {
 		l.mu.Lock()
 		l.refill()
 
@@ -71,10 +84,18 @@ func (l *Limiter) Wait(ctx context.Context) error {
 		if waitTime < time.Millisecond {
 			waitTime = time.Millisecond
 		}
+		name := l.name
 		l.mu.Unlock()
 
+		if !waited && name != "" {
+			metrics.RateLimitWait(name)
+		}
+
 		select {
 		case <-ctx.Done():
+			if name != "" {
+				metrics.RateLimitRejection(name)
+			}
 			return ctx.Err()
 		case <-time.After(waitTime):
 			// Continue loop to try again
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/metrics"
 )
 
 func TestNew(t *testing.T) {
@@ -297,3 +299,49 @@ func TestLimiter_RefillAccuracy(t *testing.T) {
 		t.Errorf("Expected ~1 token after 100ms at 10/sec, got %f", tokens)
 	}
 }
+
+func TestLimiter_NamedCountsWaitsAndRejections(t *testing.T) {
+	waits := limiterCounter(t, "mcp_gateway_rate_limit_waits_total", "test_limiter")
+	rejections := limiterCounter(t, "mcp_gateway_rate_limit_rejections_total", "test_limiter")
+
+	l := New(0.001, 1).Named("test_limiter")
+	if !l.Allow() {
+		t.Fatal("first call should get the burst token")
+	}
+	if l.Allow() {
+		t.Fatal("second call should be refused")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("Wait should give up when the context expires")
+	}
+
+	if got := limiterCounter(t, "mcp_gateway_rate_limit_waits_total", "test_limiter") - waits; got != 1 {
+		t.Errorf("waits = %v, want 1", got)
+	}
+	if got := limiterCounter(t, "mcp_gateway_rate_limit_rejections_total", "test_limiter") - rejections; got != 2 {
+		t.Errorf("rejections = %v, want 2 (refused Allow and expired Wait)", got)
+	}
+}
+
+func limiterCounter(t *testing.T, family, limiter string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != family {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "limiter" && l.GetValue() == limiter {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"caller id kept", "req-123", true},
+		{"missing id generated", "", false},
+		{"id with spaces replaced", "bad id", false},
+		{"oversized id replaced", strings.Repeat("x", maxLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set(Header, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if seen == "" || rec.Header().Get(Header) != seen {
+				t.Fatalf("context id %q, response header %q", seen, rec.Header().Get(Header))
+			}
+			if (seen == tt.header) != tt.keep {
+				t.Errorf("id = %q, keep caller id = %v", seen, tt.keep)
+			}
+		})
+	}
+}
+
+func TestLogHandler_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(NewContext(context.Background(), "req-9"), "calling tool")
+	logger.Info("no request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines", len(lines))
+	}
+	var first, second map[string]interface{}
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	if first["request_id"] != "req-9" || first["component"] != "test" {
+		t.Errorf("first record = %v, want request_id and component", first)
+	}
+	if _, ok := second["request_id"]; ok {
+		t.Errorf("record without request context got request_id: %v", second)
+	}
+}
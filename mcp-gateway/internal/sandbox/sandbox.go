@@ -0,0 +1,165 @@
+// Package sandbox serves canned tool results so skill authors can develop
+// and demo investigations without access to real infrastructure.
+//
+// A fixture file is named after the tool it answers, e.g.
+// zabbix.get_problems.json, and holds a list of responses:
+//
+//	{
+//	  "responses": [
+//	    {"match": {"command": "df"}, "result": {...}},
+//	    {"match": {"command": "systemctl"}, "error": "permission denied"},
+//	    {"result": {...}}
+//	  ]
+//	}
+//
+// The first response whose match entries are all found in the call's
+// arguments wins. A match value is a case-insensitive substring of the
+// argument (non-string arguments are compared as JSON); a response without
+// match is the default. result is returned as the tool output; error makes
+// the call fail with that message instead.
+//
+// Built-in fixtures cover a small "disk filling up on web-01" scenario.
+// Files in an override directory replace the built-in fixture of the same
+// tool.
+package sandbox
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akmatori/mcp-gateway/internal/auth"
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+)
+
+//go:embed fixtures/*.json
+var builtinFixtures embed.FS
+
+// Response is one canned answer in a fixture file.
+type Response struct {
+	Match  map[string]string `json:"match,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+type fixtureFile struct {
+	Responses []Response `json:"responses"`
+}
+
+// Fixtures answers tool calls from fixture files.
+type Fixtures struct {
+	byTool map[string][]Response
+}
+
+// Load reads the built-in fixtures, then every *.json file in dir (when
+// non-empty), which take precedence.
+func Load(dir string) (*Fixtures, error) {
+	f := &Fixtures{byTool: make(map[string][]Response)}
+	builtin, err := fs.Sub(builtinFixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.loadFS(builtin); err != nil {
+		return nil, fmt.Errorf("built-in fixtures: %w", err)
+	}
+	if dir != "" {
+		if err := f.loadFS(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("fixtures in %s: %w", dir, err)
+		}
+	}
+	return f, nil
+}
+
+func (f *Fixtures) loadFS(fsys fs.FS) error {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var file fixtureFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if len(file.Responses) == 0 {
+			return fmt.Errorf("%s: no responses", name)
+		}
+		f.byTool[strings.TrimSuffix(filepath.Base(name), ".json")] = file.Responses
+	}
+	return nil
+}
+
+// Tools returns the names of the tools with a fixture, sorted.
+func (f *Fixtures) Tools() []string {
+	names := make([]string, 0, len(f.byTool))
+	for name := range f.byTool {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Responses returns the fixture responses of tool, or nil without one.
+func (f *Fixtures) Responses(tool string) []Response {
+	return f.byTool[tool]
+}
+
+// Intercept implements mcp.ToolInterceptor. Tools in credentialless
+// namespaces (incidents, proposals) only touch Akmatori's own database, so
+// without a fixture they run for real; every other tool without a fixture
+// gets a placeholder result rather than reaching out to a real system.
+func (f *Fixtures) Intercept(ctx context.Context, tool, incidentID string, args map[string]interface{}, next mcp.ToolHandler) (interface{}, error) {
+	responses, ok := f.byTool[tool]
+	if !ok {
+		if namespace, _ := mcp.ParseToolName(tool); auth.IsCredentiallessNamespace(namespace) {
+			return next(ctx, incidentID, args)
+		}
+		return map[string]interface{}{
+			"sandbox": true,
+			"tool":    tool,
+			"message": "Sandbox mode: no fixture for this tool. Add " + tool + ".json to the fixture directory to script its output.",
+		}, nil
+	}
+	for _, r := range responses {
+		if !matches(r.Match, args) {
+			continue
+		}
+		if r.Error != "" {
+			return nil, errors.New(r.Error)
+		}
+		return r.Result, nil
+	}
+	return map[string]interface{}{
+		"sandbox": true,
+		"tool":    tool,
+		"message": "Sandbox mode: no fixture response matches these arguments.",
+	}, nil
+}
+
+func matches(match map[string]string, args map[string]interface{}) bool {
+	for key, want := range match {
+		v, ok := args[key]
+		if !ok {
+			return false
+		}
+		got, isString := v.(string)
+		if !isString {
+			b, _ := json.Marshal(v)
+			got = string(b)
+		}
+		if !strings.Contains(strings.ToLower(got), strings.ToLower(want)) {
+			return false
+		}
+	}
+	return true
+}
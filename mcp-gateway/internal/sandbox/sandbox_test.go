@@ -0,0 +1,105 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func unexpectedNext(t *testing.T) func(context.Context, string, map[string]interface{}) (interface{}, error) {
+	return func(context.Context, string, map[string]interface{}) (interface{}, error) {
+		t.Fatal("real handler called")
+		return nil, nil
+	}
+}
+
+func TestIntercept_BuiltinFixtures(t *testing.T) {
+	f, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := f.Intercept(context.Background(), "ssh.execute_command", "inc-1",
+		map[string]interface{}{"command": "DF -h /var"}, unexpectedNext(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got.(json.RawMessage)), "/dev/sdb1") {
+		t.Errorf("df fixture not matched: %s", got)
+	}
+
+	got, err = f.Intercept(context.Background(), "zabbix.get_problems", "inc-1", nil, unexpectedNext(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var problems []map[string]interface{}
+	if err := json.Unmarshal(got.(json.RawMessage), &problems); err != nil || len(problems) == 0 {
+		t.Errorf("zabbix problems = %s, %v", got, err)
+	}
+}
+
+func TestIntercept_ToolsWithoutFixture(t *testing.T) {
+	f, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := f.Intercept(context.Background(), "jira.create_issue", "inc-1", nil, unexpectedNext(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if placeholder, ok := got.(map[string]interface{}); !ok || placeholder["sandbox"] != true {
+		t.Errorf("result = %v, want sandbox placeholder", got)
+	}
+
+	called := false
+	next := func(context.Context, string, map[string]interface{}) (interface{}, error) {
+		called = true
+		return "real", nil
+	}
+	if got, err := f.Intercept(context.Background(), "incidents.list", "inc-1", nil, next); err != nil || got != "real" || !called {
+		t.Errorf("incidents.list = %v, %v; want the real handler", got, err)
+	}
+}
+
+func TestLoad_OverrideDirectory(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `{"responses": [
+		{"match": {"servers": "db-01"}, "error": "connection refused"},
+		{"result": {"results": [], "summary": {"total": 0, "succeeded": 0, "failed": 0}}}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "ssh.execute_command.json"), []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Intercept(context.Background(), "ssh.execute_command", "inc-1",
+		map[string]interface{}{"command": "df -h", "servers": []interface{}{"db-01"}}, unexpectedNext(t))
+	if err == nil || err.Error() != "connection refused" {
+		t.Errorf("err = %v, want the scripted failure", err)
+	}
+	got, err := f.Intercept(context.Background(), "ssh.execute_command", "inc-1",
+		map[string]interface{}{"command": "df -h"}, unexpectedNext(t))
+	if err != nil || strings.Contains(string(got.(json.RawMessage)), "/dev/sdb1") {
+		t.Errorf("override did not replace the built-in fixture: %s, %v", got, err)
+	}
+	if len(f.Responses("zabbix.get_problems")) == 0 {
+		t.Error("built-in fixtures of other tools should still load")
+	}
+}
+
+func TestLoad_RejectsBadFixture(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zabbix.get_hosts.json"), []byte(`{"responses": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("expected an error for a fixture without responses")
+	}
+}
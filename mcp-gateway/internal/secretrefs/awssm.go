@@ -0,0 +1,170 @@
+package secretrefs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are static AWS credentials, as in AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSSecretsManagerBackend reads secrets with GetSecretValue. The reference
+// path is the secret name or ARN; a secret ARN selects its own region.
+// JSON object secrets expose their keys as fields, other secrets are a
+// single value.
+type AWSSecretsManagerBackend struct {
+	region   string
+	endpoint string // overrides https://secretsmanager.{region}.amazonaws.com
+	creds    AWSCredentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSSecretsManagerBackend creates a backend for region. endpoint
+// overrides the regional endpoint (VPC endpoints, tests) and may be empty.
+// A nil client uses one with a 30 second timeout.
+func NewAWSSecretsManagerBackend(region, endpoint string, creds AWSCredentials, client *http.Client) *AWSSecretsManagerBackend {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &AWSSecretsManagerBackend{
+		region:   region,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		creds:    creds,
+		client:   client,
+		now:      time.Now,
+	}
+}
+
+// Read implements Backend.
+func (a *AWSSecretsManagerBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	region := a.region
+	// arn:aws:secretsmanager:<region>:<account>:secret:<name>
+	if parts := strings.SplitN(path, ":", 6); len(parts) == 6 && parts[0] == "arn" && parts[2] == "secretsmanager" {
+		region = parts[3]
+	}
+	if region == "" {
+		return nil, fmt.Errorf("no AWS region for secret %s; set AWS_REGION or use an ARN", path)
+	}
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, a.creds, region, "secretsmanager", a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return nil, fmt.Errorf("secrets manager returned %d: %s %s", resp.StatusCode, e.Type, e.Message)
+	}
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decode secrets manager response: %w", err)
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(out.SecretString), &fields); err != nil || len(fields) == 0 {
+		fields = map[string]interface{}{"": out.SecretString}
+	}
+	return &Secret{Data: fields}, nil
+}
+
+// signV4 adds AWS Signature Version 4 headers to req, signing the Host
+// header, Content-Type and every X-Amz-* header.
+func signV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
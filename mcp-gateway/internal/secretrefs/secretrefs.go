@@ -0,0 +1,363 @@
+// Package secretrefs resolves references to external secret stores in tool
+// instance settings at call time.
+//
+// A settings value of the form <scheme>:<path>#<field> is replaced by the
+// field of the secret read from the store registered for scheme:
+//
+//	vault:secret/data/zabbix#token      HashiCorp Vault (KV v1/v2 or dynamic secrets)
+//	aws-sm:prod/zabbix#token            AWS Secrets Manager (JSON secret)
+//	aws-sm:prod/zabbix-token            AWS Secrets Manager (plain string secret)
+//
+// Secrets are cached; leased secrets are renewed in the background while the
+// lease allows and fetched again once it runs out.
+package secretrefs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long secrets without a lease (KV, Secrets
+	// Manager) are reused before they are read again.
+	DefaultCacheTTL = 5 * time.Minute
+	// IdleTTL is how long a secret stays cached, and its lease renewed,
+	// after its last use.
+	IdleTTL = 30 * time.Minute
+	// minLease is the shortest lease handed out; shorter ones could expire
+	// inside the tools' 5 minute config cache.
+	minLease = 10 * time.Minute
+)
+
+// Secret is a secret read from a store.
+type Secret struct {
+	// Data holds the secret's fields. A plain string secret is stored
+	// under the empty key.
+	Data map[string]interface{}
+	// LeaseID and LeaseDuration are set for leased (dynamic) secrets.
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// Backend reads secrets from one store.
+type Backend interface {
+	Read(ctx context.Context, path string) (*Secret, error)
+}
+
+// Renewer is implemented by backends whose leases can be renewed. Renew
+// returns the lease's new duration.
+type Renewer interface {
+	Renew(ctx context.Context, leaseID string) (time.Duration, error)
+}
+
+type cacheEntry struct {
+	secret    *Secret
+	refreshAt time.Time // renew or re-read after this
+	expiresAt time.Time // unusable after this
+	lastUsed  time.Time
+}
+
+// Resolver replaces secret references in settings. It implements
+// database.SecretResolver.
+type Resolver struct {
+	mu       sync.Mutex
+	backends map[string]Backend
+	cache    map[string]*cacheEntry // scheme:path -> secret
+
+	readMu   sync.Mutex
+	reading  map[string]*sync.Mutex
+	now      func() time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewResolver creates a resolver with no backends.
+func NewResolver() *Resolver {
+	return &Resolver{
+		backends: make(map[string]Backend),
+		cache:    make(map[string]*cacheEntry),
+		reading:  make(map[string]*sync.Mutex),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Register adds the backend for references starting with scheme + ":".
+func (r *Resolver) Register(scheme string, b Backend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[scheme] = b
+}
+
+// Resolve returns a copy of settings with every secret reference replaced by
+// its value. Settings without references are returned as they are.
+func (r *Resolver) Resolve(ctx context.Context, settings map[string]interface{}) (map[string]interface{}, error) {
+	if !hasReference(settings) {
+		return settings, nil
+	}
+	out, err := r.resolveValue(ctx, "", settings)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]interface{}), nil
+}
+
+func (r *Resolver) resolveValue(ctx context.Context, key string, v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			resolved, err := r.resolveValue(ctx, k, child)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			resolved, err := r.resolveValue(ctx, key, child)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		ref, ok := parseReference(t)
+		if !ok {
+			return t, nil
+		}
+		value, err := r.lookup(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("setting %s: %w", key, err)
+		}
+		return value, nil
+	}
+	return v, nil
+}
+
+// reference is a parsed <scheme>:<path>#<field>.
+type reference struct {
+	scheme, path, field string
+}
+
+var schemes = []string{"vault", "aws-sm"}
+
+func parseReference(s string) (reference, bool) {
+	scheme, rest, ok := strings.Cut(s, ":")
+	if !ok || rest == "" {
+		return reference{}, false
+	}
+	for _, known := range schemes {
+		if scheme == known {
+			path, field, _ := strings.Cut(rest, "#")
+			return reference{scheme: scheme, path: path, field: field}, path != ""
+		}
+	}
+	return reference{}, false
+}
+
+func hasReference(v interface{}) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for _, child := range t {
+			if hasReference(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if hasReference(child) {
+				return true
+			}
+		}
+	case string:
+		_, ok := parseReference(t)
+		return ok
+	}
+	return false
+}
+
+func (r *Resolver) lookup(ctx context.Context, ref reference) (interface{}, error) {
+	secret, err := r.secret(ctx, ref.scheme, ref.path)
+	if err != nil {
+		return nil, err
+	}
+	if ref.field == "" {
+		if v, ok := secret.Data[""]; ok {
+			return v, nil
+		}
+		if len(secret.Data) == 1 {
+			for _, v := range secret.Data {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%s:%s has %d fields; name one with #field", ref.scheme, ref.path, len(secret.Data))
+	}
+	v, ok := secret.Data[ref.field]
+	if !ok {
+		return nil, fmt.Errorf("%s:%s has no field %q", ref.scheme, ref.path, ref.field)
+	}
+	return v, nil
+}
+
+// secret returns the cached secret at scheme:path, reading it when it is
+// missing or due for a refresh.
+func (r *Resolver) secret(ctx context.Context, scheme, path string) (*Secret, error) {
+	key := scheme + ":" + path
+	// Serialize reads per secret so concurrent tool calls share one read
+	// (and one lease).
+	mu := r.readLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := r.now()
+	r.mu.Lock()
+	backend := r.backends[scheme]
+	entry := r.cache[key]
+	if entry != nil && now.Before(entry.refreshAt) {
+		entry.lastUsed = now
+		r.mu.Unlock()
+		return entry.secret, nil
+	}
+	r.mu.Unlock()
+	if backend == nil {
+		return nil, fmt.Errorf("secret store %q is not configured", scheme)
+	}
+
+	if entry != nil && r.renew(ctx, backend, key, entry) {
+		return entry.secret, nil
+	}
+	secret, err := backend.Read(ctx, path)
+	if err != nil {
+		if entry != nil && now.Before(entry.expiresAt) {
+			// Keep serving the cached secret while it is still valid.
+			slog.Warn("failed to refresh secret, using cached value", "secret", key, "err", err)
+			return entry.secret, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+	r.store(key, secret, now)
+	return secret, nil
+}
+
+// renew extends entry's lease if it has a renewable one, and reports
+// whether it did.
+func (r *Resolver) renew(ctx context.Context, backend Backend, key string, entry *cacheEntry) bool {
+	renewer, ok := backend.(Renewer)
+	if !ok || !entry.secret.Renewable || entry.secret.LeaseID == "" {
+		return false
+	}
+	d, err := renewer.Renew(ctx, entry.secret.LeaseID)
+	if err != nil || d < minLease {
+		// A lease at its maximum TTL stops extending; read a fresh one.
+		if err != nil {
+			slog.Warn("failed to renew secret lease", "secret", key, "err", err)
+		}
+		return false
+	}
+	now := r.now()
+	r.mu.Lock()
+	entry.refreshAt = now.Add(d * 2 / 3)
+	entry.expiresAt = now.Add(d)
+	entry.lastUsed = now
+	r.mu.Unlock()
+	return true
+}
+
+func (r *Resolver) store(key string, secret *Secret, now time.Time) {
+	entry := &cacheEntry{secret: secret, lastUsed: now}
+	if secret.LeaseDuration > 0 {
+		entry.refreshAt = now.Add(secret.LeaseDuration * 2 / 3)
+		entry.expiresAt = now.Add(secret.LeaseDuration)
+	} else {
+		entry.refreshAt = now.Add(DefaultCacheTTL)
+		entry.expiresAt = entry.refreshAt
+	}
+	r.mu.Lock()
+	r.cache[key] = entry
+	r.mu.Unlock()
+}
+
+func (r *Resolver) readLock(key string) *sync.Mutex {
+	r.readMu.Lock()
+	defer r.readMu.Unlock()
+	mu, ok := r.reading[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		r.reading[key] = mu
+	}
+	return mu
+}
+
+// Sweep renews leases due for renewal on secrets used within IdleTTL, so
+// tools holding the values in their config cache keep working, and drops
+// secrets that are idle or expired.
+func (r *Resolver) Sweep(ctx context.Context) {
+	now := r.now()
+	type due struct {
+		key   string
+		entry *cacheEntry
+	}
+	var renewals []due
+	r.mu.Lock()
+	for key, entry := range r.cache {
+		switch {
+		case now.Sub(entry.lastUsed) >= IdleTTL, !now.Before(entry.expiresAt):
+			delete(r.cache, key)
+		case !now.Before(entry.refreshAt) && entry.secret.Renewable:
+			renewals = append(renewals, due{key, entry})
+		}
+	}
+	r.mu.Unlock()
+
+	for _, d := range renewals {
+		scheme, _, _ := strings.Cut(d.key, ":")
+		r.mu.Lock()
+		backend := r.backends[scheme]
+		r.mu.Unlock()
+		if backend == nil {
+			continue
+		}
+		mu := r.readLock(d.key)
+		mu.Lock()
+		lastUsed := d.entry.lastUsed
+		if !r.renew(ctx, backend, d.key, d.entry) {
+			// Leave it to expire; the next use reads a fresh secret.
+			slog.Info("secret lease not renewed; it will be read again on next use", "secret", d.key)
+		}
+		// Background renewal is not a use.
+		r.mu.Lock()
+		d.entry.lastUsed = lastUsed
+		r.mu.Unlock()
+		mu.Unlock()
+	}
+}
+
+// Start runs Sweep every interval until Stop is called.
+func (r *Resolver) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				r.Sweep(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop ends the sweep loop.
+func (r *Resolver) Stop() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+}
@@ -0,0 +1,278 @@
+package secretrefs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+// fakeVault serves a KV v2 secret and a renewable dynamic secret.
+type fakeVault struct {
+	mu     sync.Mutex
+	reads  map[string]int
+	renews int
+	// renewTTL is the lease duration granted on renewal, in seconds.
+	renewTTL int
+}
+
+func (f *fakeVault) handler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("X-Vault-Token") != "root" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/zabbix":
+		f.reads[r.URL.Path]++
+		_, _ = w.Write([]byte(`{"lease_duration":2764800,"data":{"data":{"token":"zbx-token","user":"admin"},"metadata":{"version":3}}}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/database/creds/readonly":
+		f.reads[r.URL.Path]++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":       "database/creds/readonly/abc",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]interface{}{"username": "v-ro", "password": "pw"},
+		})
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/sys/leases/renew":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["lease_id"] != "database/creds/readonly/abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.renews++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"lease_id": body["lease_id"], "lease_duration": f.renewTTL, "renewable": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+	}
+}
+
+func (f *fakeVault) readCount(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads[path]
+}
+
+func newTestResolver(t *testing.T) (*Resolver, *fakeVault, *testClock) {
+	t.Helper()
+	fv := &fakeVault{reads: map[string]int{}, renewTTL: 3600}
+	server := httptest.NewServer(http.HandlerFunc(fv.handler))
+	t.Cleanup(server.Close)
+
+	clock := &testClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	r := NewResolver()
+	r.now = clock.now
+	r.Register("vault", NewVaultBackend(server.URL, "root", "", server.Client()))
+	return r, fv, clock
+}
+
+func TestResolve_VaultKV(t *testing.T) {
+	r, fv, clock := newTestResolver(t)
+	settings := map[string]interface{}{
+		"zabbix_url":   "https://zabbix.example.com",
+		"zabbix_token": "vault:secret/data/zabbix#token",
+		"nested":       map[string]interface{}{"user": "vault:secret/data/zabbix#user"},
+	}
+
+	got, err := r.Resolve(context.Background(), settings)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got["zabbix_token"] != "zbx-token" || got["zabbix_url"] != "https://zabbix.example.com" {
+		t.Errorf("resolved = %v", got)
+	}
+	if got["nested"].(map[string]interface{})["user"] != "admin" {
+		t.Errorf("nested = %v", got["nested"])
+	}
+	if settings["zabbix_token"] != "vault:secret/data/zabbix#token" {
+		t.Error("Resolve must not modify its input")
+	}
+	if n := fv.readCount("/v1/secret/data/zabbix"); n != 1 {
+		t.Errorf("reads = %d, want 1 for both references", n)
+	}
+
+	// Cached until DefaultCacheTTL, read again after.
+	clock.t = clock.t.Add(DefaultCacheTTL - time.Second)
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	if n := fv.readCount("/v1/secret/data/zabbix"); n != 1 {
+		t.Errorf("reads = %d, want cached", n)
+	}
+	clock.t = clock.t.Add(2 * time.Second)
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	if n := fv.readCount("/v1/secret/data/zabbix"); n != 2 {
+		t.Errorf("reads = %d, want 2 after the cache TTL", n)
+	}
+}
+
+func TestResolve_NoReferences(t *testing.T) {
+	r := NewResolver()
+	settings := map[string]interface{}{"url": "https://example.com", "note": "vaulted:x", "port": 8080.0}
+	got, err := r.Resolve(context.Background(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["note"] != "vaulted:x" || got["port"] != 8080.0 {
+		t.Errorf("resolved = %v", got)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	r, _, _ := newTestResolver(t)
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"vault:secret/data/zabbix#missing", `no field "missing"`},
+		{"vault:secret/data/zabbix", "name one with #field"},
+		{"vault:secret/data/nothing#token", "vault returned 404"},
+		{"aws-sm:prod/zabbix#token", `secret store "aws-sm" is not configured`},
+	}
+	for _, tt := range tests {
+		_, err := r.Resolve(context.Background(), map[string]interface{}{"token": tt.ref})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Resolve(%s) error = %v, want %q", tt.ref, err, tt.want)
+		}
+	}
+}
+
+func TestResolve_LeaseRenewal(t *testing.T) {
+	r, fv, clock := newTestResolver(t)
+	settings := map[string]interface{}{"password": "vault:database/creds/readonly#password"}
+
+	got, err := r.Resolve(context.Background(), settings)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got["password"] != "pw" {
+		t.Errorf("password = %v", got["password"])
+	}
+
+	// Before two thirds of the lease nothing is renewed.
+	clock.t = clock.t.Add(30 * time.Minute)
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	r.Sweep(context.Background())
+	if fv.renews != 0 {
+		t.Errorf("renews = %d, want 0", fv.renews)
+	}
+
+	// Past it the sweep renews the lease instead of reading a new secret.
+	clock.t = clock.t.Add(11 * time.Minute)
+	r.Sweep(context.Background())
+	if fv.renews != 1 {
+		t.Errorf("renews = %d, want 1", fv.renews)
+	}
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	if n := fv.readCount("/v1/database/creds/readonly"); n != 1 {
+		t.Errorf("reads = %d, want 1 while the lease is renewed", n)
+	}
+
+	// A lease at its max TTL stops extending; the next use reads a new one.
+	fv.mu.Lock()
+	fv.renewTTL = 60
+	fv.mu.Unlock()
+	clock.t = clock.t.Add(41 * time.Minute)
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	if n := fv.readCount("/v1/database/creds/readonly"); n != 2 {
+		t.Errorf("reads = %d, want 2 once renewal stops", n)
+	}
+}
+
+func TestSweep_DropsIdleSecrets(t *testing.T) {
+	r, fv, clock := newTestResolver(t)
+	settings := map[string]interface{}{"password": "vault:database/creds/readonly#password"}
+	if _, err := r.Resolve(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.t = clock.t.Add(IdleTTL)
+	r.Sweep(context.Background())
+	if fv.renews != 0 {
+		t.Errorf("renews = %d, idle secrets must not be renewed", fv.renews)
+	}
+	r.mu.Lock()
+	cached := len(r.cache)
+	r.mu.Unlock()
+	if cached != 0 {
+		t.Errorf("cache has %d entries, want 0", cached)
+	}
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	var auth, target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, target = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Target")
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req["SecretId"] {
+		case "prod/zabbix":
+			_, _ = w.Write([]byte(`{"Name":"prod/zabbix","SecretString":"{\"token\":\"zbx-token\"}"}`))
+		case "prod/pagerduty-key":
+			_, _ = w.Write([]byte(`{"Name":"prod/pagerduty-key","SecretString":"pd-key"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer server.Close()
+
+	r := NewResolver()
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+	r.Register("aws-sm", NewAWSSecretsManagerBackend("eu-west-1", server.URL, creds, server.Client()))
+
+	got, err := r.Resolve(context.Background(), map[string]interface{}{
+		"token":   "aws-sm:prod/zabbix#token",
+		"api_key": "aws-sm:prod/pagerduty-key",
+	})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got["token"] != "zbx-token" || got["api_key"] != "pd-key" {
+		t.Errorf("resolved = %v", got)
+	}
+	if target != "secretsmanager.GetSecretValue" {
+		t.Errorf("X-Amz-Target = %q", target)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	_, err = r.Resolve(context.Background(), map[string]interface{}{"token": "aws-sm:missing#token"})
+	if err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("expected ResourceNotFoundException, got %v", err)
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case from the AWS Signature Version 4 test suite.
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
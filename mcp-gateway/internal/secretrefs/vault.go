@@ -0,0 +1,118 @@
+package secretrefs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultBackend reads secrets from HashiCorp Vault with a token.
+//
+// The reference path is the API path below /v1, so KV v2 secrets include
+// the mount's data/ segment (secret/data/zabbix). Dynamic secrets such as
+// database/creds/readonly carry a lease that the resolver renews.
+type VaultBackend struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultBackend creates a backend for the Vault server at addr. namespace
+// is the Vault Enterprise namespace, empty for none. A nil client uses one
+// with a 30 second timeout.
+func NewVaultBackend(addr, token, namespace string, client *http.Client) *VaultBackend {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &VaultBackend{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    client,
+	}
+}
+
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// Read implements Backend.
+func (v *VaultBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	var resp vaultResponse
+	if err := v.do(ctx, http.MethodGet, "/v1/"+strings.TrimLeft(path, "/"), nil, &resp); err != nil {
+		return nil, err
+	}
+	data := resp.Data
+	// KV v2 wraps the secret in data.data next to data.metadata.
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("vault returned no data for %s", path)
+	}
+	secret := &Secret{Data: data, LeaseID: resp.LeaseID, Renewable: resp.Renewable}
+	// KV secrets report a lease duration without a lease; only real
+	// leases bound the cache.
+	if resp.LeaseID != "" {
+		secret.LeaseDuration = time.Duration(resp.LeaseDuration) * time.Second
+	}
+	return secret, nil
+}
+
+// Renew implements Renewer.
+func (v *VaultBackend) Renew(ctx context.Context, leaseID string) (time.Duration, error) {
+	body, err := json.Marshal(map[string]string{"lease_id": leaseID})
+	if err != nil {
+		return 0, err
+	}
+	var resp vaultResponse
+	if err := v.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &resp); err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+func (v *VaultBackend) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, v.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &e) == nil && len(e.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(e.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode vault response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package secrets decrypts tool credentials that the Akmatori API stores
+// encrypted with AES-256-GCM.
+//
+// The format matches the API's internal/secrets package: an encrypted value
+// is enc:v1:<key id>:<base64 of nonce and ciphertext>, and values without
+// the prefix are plaintext. The gateway only reads credentials, so this
+// package only decrypts.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length of an encryption key in bytes (AES-256).
+const KeySize = 32
+
+const prefix = "enc:v1:"
+
+// ErrNoKey is returned when an encrypted value is read without a keyring,
+// or with one that does not hold the key it was encrypted with.
+var ErrNoKey = errors.New("secret is encrypted with an unknown key")
+
+// Keyring decrypts values encrypted with any of its keys.
+type Keyring struct {
+	keys map[string]cipher.AEAD
+}
+
+// LoadKeyring builds a keyring from the base64 or hex encoded keys in
+// SECRETS_ENCRYPTION_KEY and SECRETS_ENCRYPTION_PREVIOUS_KEYS. It returns nil
+// when no key is set.
+func LoadKeyring(primary string, previous []string) (*Keyring, error) {
+	var encoded []string
+	if strings.TrimSpace(primary) != "" {
+		encoded = append(encoded, primary)
+	}
+	encoded = append(encoded, previous...)
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+	for _, s := range encoded {
+		key, err := parseKey(s)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(key)
+		k.keys[hex.EncodeToString(sum[:4])] = aead
+	}
+	return k, nil
+}
+
+func parseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, decode := range []func(string) ([]byte, error){
+		base64.StdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+		hex.DecodeString,
+	} {
+		if key, err := decode(s); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// Decrypt returns the plaintext of an encrypted value and returns any other
+// value unchanged. It is safe to call on a nil keyring, which fails only for
+// encrypted values.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted secret")
+	}
+	var aead cipher.AEAD
+	if k != nil {
+		aead = k.keys[id]
+	}
+	if aead == nil {
+		return "", fmt.Errorf("%w %s", ErrNoKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret with key %s: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// DecryptTree returns a copy of a decoded JSON value with every encrypted
+// string decrypted.
+func (k *Keyring) DecryptTree(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, child := range t {
+			opened, err := k.DecryptTree(child)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = opened
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			opened, err := k.DecryptTree(child)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = opened
+		}
+		return out, nil
+	case string:
+		return k.Decrypt(t)
+	}
+	return v, nil
+}
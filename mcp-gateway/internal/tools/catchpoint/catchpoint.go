@@ -53,8 +53,8 @@ type CatchpointTool struct {
 func NewCatchpointTool(logger *log.Logger, limiter *ratelimit.Limiter) *CatchpointTool {
 	return &CatchpointTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("catchpoint_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("catchpoint_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
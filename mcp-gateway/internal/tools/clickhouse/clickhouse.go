@@ -82,8 +82,8 @@ type ClickHouseTool struct {
 func NewClickHouseTool(logger *log.Logger, limiter *ratelimit.Limiter) *ClickHouseTool {
 	t := &ClickHouseTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(QueryCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("clickhouse_config"),
+		responseCache: cache.New(QueryCacheTTL, CacheCleanupTick).Named("clickhouse_response").Shared(cache.JSON[string]()),
 		rateLimiter:   limiter,
 	}
 	t.execQuery = t.executeQueryInternal
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -54,8 +55,8 @@ type GrafanaTool struct {
 func NewGrafanaTool(logger *log.Logger, limiter *ratelimit.Limiter) *GrafanaTool {
 	return &GrafanaTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("grafana_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("grafana_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
@@ -765,7 +766,9 @@ func (t *GrafanaTool) QueryLoki(ctx context.Context, incidentID string, args map
 }
 
 // CreateAnnotation creates an annotation on a Grafana dashboard or globally.
-// Requires text; optional: dashboard_id, panel_id, tags, time, time_end.
+// Requires text; optional: dashboard_id, dashboard_uid, panel_id, tags, time,
+// time_end. The annotation is tagged with the incident UUID so it can be
+// traced back to the investigation (and listed with incident_only).
 // This is a write operation - no caching (POST /api/annotations).
 func (t *GrafanaTool) CreateAnnotation(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
 	logicalName := extractLogicalName(args)
@@ -785,9 +788,10 @@ func (t *GrafanaTool) CreateAnnotation(ctx context.Context, incidentID string, a
 	if panelID, ok := args["panel_id"].(float64); ok && panelID > 0 {
 		reqBody["panelId"] = int(panelID)
 	}
-	if tags, ok := args["tags"]; ok {
-		reqBody["tags"] = tags
+	if dashUID, ok := args["dashboard_uid"].(string); ok && dashUID != "" {
+		reqBody["dashboardUID"] = dashUID
 	}
+	reqBody["tags"] = annotationTags(args["tags"], incidentID)
 	if ts, ok := args["time"].(float64); ok && ts > 0 {
 		reqBody["time"] = int64(ts)
 	}
@@ -803,7 +807,8 @@ func (t *GrafanaTool) CreateAnnotation(ctx context.Context, incidentID string, a
 }
 
 // GetAnnotations lists annotations with optional filters.
-// Supports from, to (epoch ms), dashboard_id, panel_id, tags, limit, type (annotation/alert).
+// Supports from, to (epoch ms), dashboard_id, panel_id, tags, limit, type (annotation/alert),
+// and incident_only to list only annotations written for the current incident.
 // GET /api/annotations
 func (t *GrafanaTool) GetAnnotations(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
 	logicalName := extractLogicalName(args)
@@ -839,6 +844,9 @@ func (t *GrafanaTool) GetAnnotations(ctx context.Context, incidentID string, arg
 	if annType, ok := args["type"].(string); ok && annType != "" {
 		params.Set("type", annType)
 	}
+	if incidentOnly, ok := args["incident_only"].(bool); ok && incidentOnly && incidentID != "" {
+		params.Add("tags", IncidentTagPrefix+incidentID)
+	}
 
 	body, err := t.cachedGet(ctx, incidentID, "/api/annotations", params, AnnotationsCacheTTL, logicalName)
 	if err != nil {
@@ -846,3 +854,172 @@ func (t *GrafanaTool) GetAnnotations(ctx context.Context, incidentID string, arg
 	}
 	return string(body), nil
 }
+
+// IncidentTagPrefix prefixes the tag that ties an annotation to the Akmatori
+// incident that wrote it.
+const IncidentTagPrefix = "akmatori_incident:"
+
+// annotationTags merges caller-supplied tags (an array or comma-separated
+// string) with the akmatori and incident tags, dropping blanks and duplicates.
+func annotationTags(raw interface{}, incidentID string) []string {
+	var tags []string
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	case []string:
+		tags = append(tags, v...)
+	case string:
+		tags = strings.Split(v, ",")
+	}
+	tags = append(tags, "akmatori")
+	if incidentID != "" {
+		tags = append(tags, IncidentTagPrefix+incidentID)
+	}
+
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// Panel render limits. Rendering is done server-side by the Grafana image
+// renderer, so very large images are slow and expensive.
+const (
+	DefaultRenderWidth  = 1000
+	DefaultRenderHeight = 500
+	MaxRenderWidth      = 3000
+	MaxRenderHeight     = 2000
+	maxInlineImageBytes = 2 * 1024 * 1024
+)
+
+// RenderResult describes a rendered panel snapshot.
+type RenderResult struct {
+	DashboardUID string `json:"dashboard_uid"`
+	PanelID      int    `json:"panel_id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	PanelURL     string `json:"panel_url"`
+	RenderURL    string `json:"render_url"`
+	ContentType  string `json:"content_type"`
+	SizeBytes    int    `json:"size_bytes"`
+	ImageBase64  string `json:"image_base64,omitempty"`
+}
+
+// RenderPanel renders a single dashboard panel to PNG through Grafana's image
+// renderer (GET /render/d-solo/{uid}). It returns deep links to the panel and
+// the rendered image; the PNG itself is only inlined (base64) when
+// include_image is true, since it is rarely useful to the model as text.
+// Requires dashboard_uid and panel_id; optional: from, to (Grafana time
+// expressions or epoch ms, default now-1h..now), width, height, theme.
+func (t *GrafanaTool) RenderPanel(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	logicalName := extractLogicalName(args)
+
+	uid, ok := args["dashboard_uid"].(string)
+	if !ok || uid == "" {
+		return "", fmt.Errorf("dashboard_uid is required%s", validation.SuggestParam("dashboard_uid", args))
+	}
+	panelID, ok := args["panel_id"].(float64)
+	if !ok || panelID <= 0 {
+		return "", fmt.Errorf("panel_id is required%s", validation.SuggestParam("panel_id", args))
+	}
+
+	from := renderTimeArg(args["from"], "now-1h")
+	to := renderTimeArg(args["to"], "now")
+	width := clampDimension(args["width"], DefaultRenderWidth, MaxRenderWidth)
+	height := clampDimension(args["height"], DefaultRenderHeight, MaxRenderHeight)
+
+	params := url.Values{}
+	params.Set("panelId", fmt.Sprintf("%d", int(panelID)))
+	params.Set("from", from)
+	params.Set("to", to)
+	params.Set("width", fmt.Sprintf("%d", width))
+	params.Set("height", fmt.Sprintf("%d", height))
+	if theme, ok := args["theme"].(string); ok && (theme == "light" || theme == "dark") {
+		params.Set("theme", theme)
+	}
+
+	config, err := t.getConfig(ctx, incidentID, logicalName)
+	if err != nil {
+		return "", err
+	}
+	if config.URL == "" {
+		return "", fmt.Errorf("grafana URL not configured")
+	}
+
+	renderPath := fmt.Sprintf("/render/d-solo/%s/_", url.PathEscape(uid))
+	body, err := t.doRequest(ctx, config, http.MethodGet, renderPath, params, nil)
+	if err != nil {
+		return "", err
+	}
+	contentType := http.DetectContentType(body)
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("grafana did not return an image (got %s); is the grafana-image-renderer plugin installed?", contentType)
+	}
+
+	panelParams := url.Values{}
+	panelParams.Set("viewPanel", fmt.Sprintf("%d", int(panelID)))
+	panelParams.Set("from", from)
+	panelParams.Set("to", to)
+
+	result := RenderResult{
+		DashboardUID: uid,
+		PanelID:      int(panelID),
+		From:         from,
+		To:           to,
+		PanelURL:     fmt.Sprintf("%s/d/%s?%s", config.URL, url.PathEscape(uid), panelParams.Encode()),
+		RenderURL:    fmt.Sprintf("%s%s?%s", config.URL, renderPath, params.Encode()),
+		ContentType:  contentType,
+		SizeBytes:    len(body),
+	}
+	if include, ok := args["include_image"].(bool); ok && include {
+		if len(body) > maxInlineImageBytes {
+			return "", fmt.Errorf("rendered image is %d bytes, above the %d byte inline limit; reduce width/height", len(body), maxInlineImageBytes)
+		}
+		result.ImageBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal render result: %w", err)
+	}
+	return string(out), nil
+}
+
+// renderTimeArg accepts a Grafana time expression ("now-6h") or epoch
+// milliseconds, falling back to def.
+func renderTimeArg(raw interface{}, def string) string {
+	switch v := raw.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	case float64:
+		if v > 0 {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	}
+	return def
+}
+
+// clampDimension returns a positive pixel size capped at max.
+func clampDimension(raw interface{}, def, max int) int {
+	v, ok := raw.(float64)
+	if !ok || v <= 0 {
+		return def
+	}
+	if int(v) > max {
+		return max
+	}
+	return int(v)
+}
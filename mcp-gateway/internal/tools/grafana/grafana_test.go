@@ -2216,3 +2216,147 @@ func TestGetAnnotations_Cached(t *testing.T) {
 		t.Errorf("expected 1 HTTP request (cache hit), got %d", counter.Load())
 	}
 }
+
+func TestCreateAnnotation_TagsIncident(t *testing.T) {
+	var receivedBody map[string]interface{}
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &receivedBody)
+		fmt.Fprint(w, `{"id":7}`)
+	})
+
+	_, err := tool.CreateAnnotation(context.Background(), "test-incident", map[string]interface{}{
+		"text":          "Rolled back api",
+		"dashboard_uid": "abc123",
+		"tags":          []interface{}{"deploy", "akmatori", " "},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if receivedBody["dashboardUID"] != "abc123" {
+		t.Errorf("expected dashboardUID=abc123, got %v", receivedBody["dashboardUID"])
+	}
+	tags, _ := receivedBody["tags"].([]interface{})
+	want := []string{"deploy", "akmatori", IncidentTagPrefix + "test-incident"}
+	if len(tags) != len(want) {
+		t.Fatalf("expected tags %v, got %v", want, tags)
+	}
+	for i, tag := range want {
+		if tags[i] != tag {
+			t.Errorf("tag[%d] = %v, want %q", i, tags[i], tag)
+		}
+	}
+}
+
+func TestGetAnnotations_IncidentOnly(t *testing.T) {
+	var receivedParams url.Values
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		receivedParams = r.URL.Query()
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := tool.GetAnnotations(context.Background(), "test-incident", map[string]interface{}{
+		"incident_only": true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := receivedParams["tags"]; len(got) != 1 || got[0] != IncidentTagPrefix+"test-incident" {
+		t.Errorf("expected incident tag filter, got %v", got)
+	}
+}
+
+// --- RenderPanel tests ---
+
+// pngHeader is enough of a PNG for http.DetectContentType.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestRenderPanel_Success(t *testing.T) {
+	var receivedPath string
+	var receivedParams url.Values
+	tool, server, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedParams = r.URL.Query()
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngHeader)
+	})
+
+	out, err := tool.RenderPanel(context.Background(), "test-incident", map[string]interface{}{
+		"dashboard_uid": "abc123",
+		"panel_id":      float64(4),
+		"from":          "now-6h",
+		"width":         float64(5000),
+		"theme":         "dark",
+		"include_image": true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if receivedPath != "/render/d-solo/abc123/_" {
+		t.Errorf("unexpected render path %s", receivedPath)
+	}
+	if receivedParams.Get("panelId") != "4" || receivedParams.Get("from") != "now-6h" || receivedParams.Get("to") != "now" {
+		t.Errorf("unexpected params %v", receivedParams)
+	}
+	if receivedParams.Get("width") != "3000" || receivedParams.Get("height") != "500" || receivedParams.Get("theme") != "dark" {
+		t.Errorf("expected clamped size and theme, got %v", receivedParams)
+	}
+
+	var result RenderResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if result.ContentType != "image/png" || result.SizeBytes != len(pngHeader) || result.ImageBase64 == "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.HasPrefix(result.PanelURL, server.URL+"/d/abc123?") || !strings.Contains(result.PanelURL, "viewPanel=4") {
+		t.Errorf("unexpected panel_url %s", result.PanelURL)
+	}
+}
+
+func TestRenderPanel_OmitsImageByDefault(t *testing.T) {
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngHeader)
+	})
+
+	out, err := tool.RenderPanel(context.Background(), "test-incident", map[string]interface{}{
+		"dashboard_uid": "abc123",
+		"panel_id":      float64(1),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "image_base64") {
+		t.Errorf("image should be omitted unless include_image is set: %s", out)
+	}
+}
+
+func TestRenderPanel_RendererMissing(t *testing.T) {
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html><body>Grafana</body></html>`)
+	})
+
+	_, err := tool.RenderPanel(context.Background(), "test-incident", map[string]interface{}{
+		"dashboard_uid": "abc123",
+		"panel_id":      float64(1),
+	})
+	if err == nil || !strings.Contains(err.Error(), "grafana-image-renderer") {
+		t.Fatalf("expected renderer hint, got %v", err)
+	}
+}
+
+func TestRenderPanel_MissingArgs(t *testing.T) {
+	tool := NewGrafanaTool(testLogger(), nil)
+	defer tool.Stop()
+
+	for _, args := range []map[string]interface{}{
+		{"panel_id": float64(1)},
+		{"dashboard_uid": "abc123"},
+	} {
+		if _, err := tool.RenderPanel(context.Background(), "test-incident", args); err == nil {
+			t.Errorf("RenderPanel(%v): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,685 @@
+package httpcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/akmatori/mcp-gateway/internal/cache"
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/ratelimit"
+	"github.com/akmatori/mcp-gateway/internal/validation"
+)
+
+// Cache and limit constants
+const (
+	ConfigCacheTTL      = 5 * time.Minute // Credentials cache TTL
+	CacheCleanupTick    = time.Minute     // Background cleanup interval
+	DefaultMaxBodyBytes = 64 * 1024       // Bytes of the response body read for assertions
+	maxBodyBytesLimit   = 1024 * 1024     // Upper bound for http_check_max_body_bytes
+	bodyExcerptBytes    = 1024            // Bytes of the body echoed back in the result
+	maxRedirects        = 5
+	maxRequestBodyBytes = 64 * 1024
+)
+
+// CheckConfig holds the probe settings of one http_check instance
+type CheckConfig struct {
+	// AllowedURLs lists what may be probed. An entry with a scheme is a URL
+	// prefix ("https://api.example.com/health"); otherwise it is a host
+	// pattern ("status.example.com", "*.example.com", "10.0.0.5:8080").
+	AllowedURLs     []string
+	Headers         map[string]string // Sent with every probe (e.g. Authorization)
+	VerifySSL       bool
+	FollowRedirects bool
+	Timeout         int
+	MaxBodyBytes    int
+	UseProxy        bool
+	ProxyURL        string
+}
+
+// HTTPCheckTool issues synthetic GET/POST/HEAD probes against allow-listed
+// URLs and reports status, latency, TLS certificate expiry and assertion
+// results. Probes are never cached: each call measures the endpoint live.
+type HTTPCheckTool struct {
+	logger      *log.Logger
+	configCache *cache.Cache
+	rateLimiter *ratelimit.Limiter
+}
+
+// NewHTTPCheckTool creates a new HTTP check tool with optional rate limiter
+func NewHTTPCheckTool(logger *log.Logger, limiter *ratelimit.Limiter) *HTTPCheckTool {
+	return &HTTPCheckTool{
+		logger:      logger,
+		configCache: cache.New(ConfigCacheTTL, CacheCleanupTick).Named("httpcheck_config"),
+		rateLimiter: limiter,
+	}
+}
+
+// Stop cleans up cache resources
+func (t *HTTPCheckTool) Stop() {
+	if t.configCache != nil {
+		t.configCache.Stop()
+	}
+}
+
+// configCacheKey returns the cache key for config/credentials
+func configCacheKey(incidentID string) string {
+	return fmt.Sprintf("creds:%s:http_check", incidentID)
+}
+
+// extractLogicalName extracts the optional logical_name from tool arguments.
+func extractLogicalName(args map[string]interface{}) string {
+	if v, ok := args["logical_name"].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// clampTimeout ensures timeout is within a safe range (1-60 seconds), defaulting to 10.
+func clampTimeout(timeout int) int {
+	if timeout <= 0 {
+		return 10
+	}
+	if timeout > 60 {
+		return 60
+	}
+	return timeout
+}
+
+// splitList splits a newline- or comma-separated setting into trimmed,
+// non-empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// parseHeaders parses "Name: value" lines.
+func parseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header line %q in http_check_headers, expected 'Name: value'", line)
+		}
+		headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// getConfig fetches the instance configuration from database with caching.
+func (t *HTTPCheckTool) getConfig(ctx context.Context, incidentID, logicalName string) (*CheckConfig, error) {
+	cacheKey := configCacheKey(incidentID)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("creds:logical:%s:%s", "http_check", logicalName)
+	}
+
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if config, ok := cached.(*CheckConfig); ok {
+			t.logger.Printf("Config cache hit for key %s", cacheKey)
+			return config, nil
+		}
+	}
+
+	creds, err := database.ResolveToolCredentials(ctx, incidentID, "http_check", nil, logicalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HTTP check credentials: %w", err)
+	}
+
+	config := &CheckConfig{
+		VerifySSL:       true,
+		FollowRedirects: true,
+		Timeout:         10,
+		MaxBodyBytes:    DefaultMaxBodyBytes,
+	}
+
+	settings := creds.Settings
+
+	if allowed, ok := settings["http_check_allowed_urls"].(string); ok {
+		config.AllowedURLs = splitList(allowed)
+	}
+	if raw, ok := settings["http_check_headers"].(string); ok && raw != "" {
+		if config.Headers, err = parseHeaders(raw); err != nil {
+			return nil, err
+		}
+	}
+	if verify, ok := settings["http_check_verify_ssl"].(bool); ok {
+		config.VerifySSL = verify
+	}
+	if follow, ok := settings["http_check_follow_redirects"].(bool); ok {
+		config.FollowRedirects = follow
+	}
+	if timeout, ok := settings["http_check_timeout"].(float64); ok {
+		config.Timeout = int(timeout)
+	}
+	config.Timeout = clampTimeout(config.Timeout)
+	if n, ok := settings["http_check_max_body_bytes"].(float64); ok && n > 0 {
+		config.MaxBodyBytes = int(n)
+	}
+	if config.MaxBodyBytes > maxBodyBytesLimit {
+		config.MaxBodyBytes = maxBodyBytesLimit
+	}
+
+	proxySettings := t.getCachedProxySettings(ctx)
+	if proxySettings != nil && proxySettings.ProxyURL != "" && proxySettings.HTTPCheckEnabled {
+		config.UseProxy = true
+		config.ProxyURL = proxySettings.ProxyURL
+	}
+
+	t.configCache.Set(cacheKey, config)
+	t.logger.Printf("Config cached for key %s", cacheKey)
+
+	return config, nil
+}
+
+// getCachedProxySettings fetches proxy settings with caching
+func (t *HTTPCheckTool) getCachedProxySettings(ctx context.Context) *database.ProxySettings {
+	cacheKey := "proxy:settings"
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if settings, ok := cached.(*database.ProxySettings); ok {
+			return settings
+		}
+	}
+
+	proxySettings, err := database.GetProxySettings(ctx)
+	if err != nil || proxySettings == nil {
+		return nil
+	}
+
+	t.configCache.Set(cacheKey, proxySettings)
+
+	return proxySettings
+}
+
+// urlAllowed reports whether u matches one of the allowlist entries.
+func urlAllowed(u *url.URL, allowed []string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	for _, entry := range allowed {
+		if strings.Contains(entry, "://") {
+			prefix, err := url.Parse(entry)
+			if err != nil {
+				continue
+			}
+			if prefix.Scheme == u.Scheme && strings.EqualFold(prefix.Host, u.Host) && pathHasPrefix(u.Path, prefix.Path) {
+				return true
+			}
+			continue
+		}
+		patternHost, patternPort := strings.ToLower(entry), ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			patternHost, patternPort = h, p
+		}
+		if patternPort != "" && patternPort != port {
+			continue
+		}
+		if strings.HasPrefix(patternHost, "*.") {
+			if strings.HasSuffix(host, patternHost[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == patternHost {
+			return true
+		}
+	}
+	return false
+}
+
+// pathHasPrefix matches whole path segments, so "/api" allows "/api/health"
+// but not "/apiv2". The path is cleaned first so "/api/../admin" cannot
+// escape the prefix.
+func pathHasPrefix(reqPath, prefix string) bool {
+	if prefix == "" || prefix == "/" {
+		return true
+	}
+	if reqPath != "" {
+		cleaned := path.Clean(reqPath)
+		if strings.HasSuffix(reqPath, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+		reqPath = cleaned
+	}
+	if !strings.HasPrefix(reqPath, prefix) {
+		return false
+	}
+	return len(reqPath) == len(prefix) || strings.HasSuffix(prefix, "/") || reqPath[len(prefix)] == '/'
+}
+
+// TLSInfo describes the certificate presented by the server.
+type TLSInfo struct {
+	Version         string    `json:"version"`
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	DNSNames        []string  `json:"dns_names,omitempty"`
+	NotBefore       time.Time `json:"not_before"`
+	NotAfter        time.Time `json:"not_after"`
+	DaysUntilExpiry int       `json:"days_until_expiry"`
+	Expired         bool      `json:"expired"`
+	VerifyError     string    `json:"verify_error,omitempty"`
+}
+
+// Timings breaks the probe latency down by phase, in milliseconds. Phases
+// that did not happen (e.g. DNS for an IP literal) are omitted.
+type Timings struct {
+	DNSMs          int64 `json:"dns_ms,omitempty"`
+	ConnectMs      int64 `json:"connect_ms,omitempty"`
+	TLSHandshakeMs int64 `json:"tls_handshake_ms,omitempty"`
+	FirstByteMs    int64 `json:"first_byte_ms,omitempty"`
+}
+
+// Assertion is the outcome of one expectation checked against the response.
+type Assertion struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ProbeResult is what the probe function returns. Transport failures are
+// reported in Error rather than as a tool error: an unreachable endpoint is
+// a finding, not a failed call.
+type ProbeResult struct {
+	URL           string            `json:"url"`
+	Method        string            `json:"method"`
+	OK            bool              `json:"ok"`
+	StatusCode    int               `json:"status_code,omitempty"`
+	LatencyMs     int64             `json:"latency_ms"`
+	Timings       Timings           `json:"timings"`
+	FinalURL      string            `json:"final_url,omitempty"`
+	Redirects     int               `json:"redirects,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	BodyBytes     int               `json:"body_bytes"`
+	BodyTruncated bool              `json:"body_truncated,omitempty"`
+	BodyExcerpt   string            `json:"body_excerpt,omitempty"`
+	TLS           *TLSInfo          `json:"tls,omitempty"`
+	Assertions    []Assertion       `json:"assertions"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// reportedHeaders are the response headers echoed back; the rest are noise
+// for an availability check.
+var reportedHeaders = []string{"Content-Type", "Content-Length", "Location", "Server", "Cache-Control", "Retry-After", "Via", "X-Cache"}
+
+// probeOptions are the validated per-call arguments.
+type probeOptions struct {
+	url          *url.URL
+	method       string
+	body         string
+	contentType  string
+	headers      map[string]string
+	expectStatus []int
+	contains     string
+	bodyRegex    *regexp.Regexp
+	maxLatency   time.Duration
+}
+
+func parseProbeArgs(args map[string]interface{}, allowed []string) (*probeOptions, error) {
+	rawURL, ok := args["url"].(string)
+	if !ok || rawURL == "" {
+		return nil, fmt.Errorf("url is required%s", validation.SuggestParam("url", args))
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("url must not embed credentials; configure http_check_headers instead")
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("no URLs are allow-listed for this HTTP check instance; set http_check_allowed_urls")
+	}
+	if !urlAllowed(u, allowed) {
+		return nil, fmt.Errorf("url %s is not in the allowlist for this HTTP check instance", u.Redacted())
+	}
+
+	opts := &probeOptions{url: u, method: http.MethodGet}
+	if m, ok := args["method"].(string); ok && m != "" {
+		opts.method = strings.ToUpper(m)
+	}
+	switch opts.method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+	default:
+		return nil, fmt.Errorf("method must be GET, HEAD or POST")
+	}
+
+	if body, ok := args["body"].(string); ok && body != "" {
+		if opts.method != http.MethodPost {
+			return nil, fmt.Errorf("body is only allowed with method POST")
+		}
+		if len(body) > maxRequestBodyBytes {
+			return nil, fmt.Errorf("body exceeds %d bytes", maxRequestBodyBytes)
+		}
+		opts.body = body
+		opts.contentType = "application/json"
+	}
+	if ct, ok := args["content_type"].(string); ok && ct != "" {
+		opts.contentType = ct
+	}
+	if h, ok := args["headers"].(map[string]interface{}); ok {
+		opts.headers = make(map[string]string, len(h))
+		for k, v := range h {
+			if s, ok := v.(string); ok {
+				opts.headers[k] = s
+			}
+		}
+	}
+
+	switch v := args["expect_status"].(type) {
+	case float64:
+		opts.expectStatus = []int{int(v)}
+	case []interface{}:
+		for _, item := range v {
+			if f, ok := item.(float64); ok {
+				opts.expectStatus = append(opts.expectStatus, int(f))
+			}
+		}
+	}
+	if s, ok := args["expect_body_contains"].(string); ok {
+		opts.contains = s
+	}
+	if s, ok := args["expect_body_regex"].(string); ok && s != "" {
+		if opts.bodyRegex, err = regexp.Compile(s); err != nil {
+			return nil, fmt.Errorf("invalid expect_body_regex: %w", err)
+		}
+	}
+	if ms, ok := args["max_latency_ms"].(float64); ok && ms > 0 {
+		opts.maxLatency = time.Duration(ms) * time.Millisecond
+	}
+	return opts, nil
+}
+
+// Probe issues one request to an allow-listed URL and reports what happened
+func (t *HTTPCheckTool) Probe(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	config, err := t.getConfig(ctx, incidentID, extractLogicalName(args))
+	if err != nil {
+		return "", err
+	}
+	opts, err := parseProbeArgs(args, config.AllowedURLs)
+	if err != nil {
+		return "", err
+	}
+
+	if t.rateLimiter != nil {
+		if err := t.rateLimiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("rate limit wait cancelled: %w", err)
+		}
+	}
+
+	result := t.probe(ctx, config, opts)
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(out), nil
+}
+
+func (t *HTTPCheckTool) probe(ctx context.Context, config *CheckConfig, opts *probeOptions) *ProbeResult {
+	result := &ProbeResult{URL: opts.url.String(), Method: opts.method, Assertions: []Assertion{}}
+	t.logger.Printf("HTTP check: %s %s", opts.method, opts.url.Redacted())
+
+	var tlsInfo *TLSInfo
+	// Verification runs in VerifyConnection instead of the default handshake
+	// check so the certificate is captured even when it is expired or
+	// otherwise invalid, which is exactly when its details matter.
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // Verified in VerifyConnection unless http_check_verify_ssl is off
+		MinVersion:         tls.VersionTLS12,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return nil
+			}
+			tlsInfo = describeCert(cs)
+			if !config.VerifySSL {
+				return nil
+			}
+			verifyOpts := x509.VerifyOptions{DNSName: cs.ServerName, Intermediates: x509.NewCertPool()}
+			for _, cert := range cs.PeerCertificates[1:] {
+				verifyOpts.Intermediates.AddCert(cert)
+			}
+			if _, err := cs.PeerCertificates[0].Verify(verifyOpts); err != nil {
+				tlsInfo.VerifyError = err.Error()
+				return err
+			}
+			return nil
+		},
+	}
+
+	// DisableKeepAlives prevents connection pool leakage and makes every probe
+	// pay the full connect cost, like a real first-time client would
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   tlsConfig,
+	}
+	// Handle proxy settings - MUST explicitly set Proxy to prevent env var usage
+	if config.UseProxy && config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			t.logger.Printf("Invalid proxy URL: %v, proceeding without proxy", err)
+			transport.Proxy = nil
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	} else {
+		transport.Proxy = nil
+	}
+
+	client := &http.Client{
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !config.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if !urlAllowed(req.URL, config.AllowedURLs) {
+				return fmt.Errorf("redirect to %s is not in the allowlist", req.URL.Redacted())
+			}
+			result.Redirects = len(via)
+			return nil
+		},
+	}
+
+	var body io.Reader
+	if opts.body != "" {
+		body = strings.NewReader(opts.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, opts.method, opts.url.String(), body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+	req.Header.Set("User-Agent", "akmatori-http-check/1.0")
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range opts.headers {
+		req.Header.Set(k, v)
+	}
+	if opts.contentType != "" {
+		req.Header.Set("Content-Type", opts.contentType)
+	}
+
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			result.Timings.DNSMs = time.Since(dnsStart).Milliseconds()
+		},
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(string, string, error) {
+			result.Timings.ConnectMs = time.Since(connectStart).Milliseconds()
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			result.Timings.TLSHandshakeMs = time.Since(tlsStart).Milliseconds()
+		},
+		GotFirstResponseByte: func() {
+			result.Timings.FirstByteMs = time.Since(start).Milliseconds()
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client.Do(req)
+	result.TLS = tlsInfo
+	if err != nil {
+		result.LatencyMs = time.Since(start).Milliseconds()
+		if tlsInfo != nil && tlsInfo.VerifyError != "" {
+			result.Error = "tls: " + tlsInfo.VerifyError
+		} else {
+			result.Error = describeTransportError(err)
+		}
+		return result
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, int64(config.MaxBodyBytes)+1))
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response body: %v", err)
+	}
+	if len(respBody) > config.MaxBodyBytes {
+		respBody = respBody[:config.MaxBodyBytes]
+		result.BodyTruncated = true
+	}
+
+	result.StatusCode = resp.StatusCode
+	if resp.Request != nil && resp.Request.URL.String() != result.URL {
+		result.FinalURL = resp.Request.URL.String()
+	}
+	result.Headers = map[string]string{}
+	for _, h := range reportedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			result.Headers[h] = v
+		}
+	}
+	result.BodyBytes = len(respBody)
+	result.BodyExcerpt = excerpt(respBody, bodyExcerptBytes)
+
+	result.Assertions = checkAssertions(opts, resp.StatusCode, respBody, time.Duration(result.LatencyMs)*time.Millisecond)
+	result.OK = result.Error == ""
+	for _, a := range result.Assertions {
+		if !a.Passed {
+			result.OK = false
+		}
+	}
+	return result
+}
+
+// checkAssertions evaluates the expectations. Without expect_status any
+// status below 400 passes.
+func checkAssertions(opts *probeOptions, status int, body []byte, latency time.Duration) []Assertion {
+	var out []Assertion
+
+	statusOK := status < 400
+	want := "< 400"
+	if len(opts.expectStatus) > 0 {
+		statusOK = false
+		for _, s := range opts.expectStatus {
+			if s == status {
+				statusOK = true
+			}
+		}
+		want = fmt.Sprint(opts.expectStatus)
+	}
+	out = append(out, Assertion{Name: "status", Passed: statusOK, Detail: fmt.Sprintf("got %d, want %s", status, want)})
+
+	if opts.contains != "" {
+		a := Assertion{Name: "body_contains", Passed: strings.Contains(string(body), opts.contains)}
+		if !a.Passed {
+			a.Detail = fmt.Sprintf("%q not found in the first %d bytes", opts.contains, len(body))
+		}
+		out = append(out, a)
+	}
+	if opts.bodyRegex != nil {
+		a := Assertion{Name: "body_regex", Passed: opts.bodyRegex.Match(body)}
+		if !a.Passed {
+			a.Detail = fmt.Sprintf("/%s/ did not match the first %d bytes", opts.bodyRegex, len(body))
+		}
+		out = append(out, a)
+	}
+	if opts.maxLatency > 0 {
+		out = append(out, Assertion{
+			Name:   "latency",
+			Passed: latency <= opts.maxLatency,
+			Detail: fmt.Sprintf("took %dms, limit %dms", latency.Milliseconds(), opts.maxLatency.Milliseconds()),
+		})
+	}
+	return out
+}
+
+func describeCert(cs tls.ConnectionState) *TLSInfo {
+	leaf := cs.PeerCertificates[0]
+	until := time.Until(leaf.NotAfter)
+	return &TLSInfo{
+		Version:         tls.VersionName(cs.Version),
+		Subject:         leaf.Subject.String(),
+		Issuer:          leaf.Issuer.String(),
+		DNSNames:        leaf.DNSNames,
+		NotBefore:       leaf.NotBefore,
+		NotAfter:        leaf.NotAfter,
+		DaysUntilExpiry: int(until.Hours() / 24),
+		Expired:         until < 0,
+	}
+}
+
+// describeTransportError turns a client error into a short, classified
+// message the agent can reason about (DNS vs refused vs timeout vs TLS).
+func describeTransportError(err error) string {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns: " + dnsErr.Error()
+	case isTimeout(err):
+		return "timeout: " + err.Error()
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return "connect: " + opErr.Err.Error()
+	}
+	return err.Error()
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// excerpt returns up to n bytes of body, trimmed to a rune boundary, or a
+// placeholder for binary content.
+func excerpt(body []byte, n int) string {
+	if len(body) > n {
+		body = body[:n]
+		// Drop a multi-byte rune cut in half by the limit.
+		for i := 0; i < utf8.UTFMax-1 && len(body) > 0 && !utf8.Valid(body); i++ {
+			body = body[:len(body)-1]
+		}
+	}
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("<%d bytes of binary data>", len(body))
+	}
+	return string(body)
+}
@@ -0,0 +1,245 @@
+package httpcheck
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func testLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+// newTestTool creates an HTTPCheckTool with a cached config, so getConfig
+// never touches the database.
+func newTestTool(t *testing.T, config *CheckConfig) *HTTPCheckTool {
+	t.Helper()
+	tool := NewHTTPCheckTool(testLogger(), nil)
+	if config.Timeout == 0 {
+		config.Timeout = 5
+	}
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	tool.configCache.Set(configCacheKey("test-incident"), config)
+	t.Cleanup(tool.Stop)
+	return tool
+}
+
+func probe(t *testing.T, tool *HTTPCheckTool, args map[string]interface{}) ProbeResult {
+	t.Helper()
+	out, err := tool.Probe(context.Background(), "test-incident", args)
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	var result ProbeResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	return result
+}
+
+func TestURLAllowed(t *testing.T) {
+	allowed := []string{"https://api.example.com/v1", "status.example.com", "*.internal.example.com", "10.0.0.5:8080"}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://api.example.com/v1", true},
+		{"https://api.example.com/v1/health", true},
+		{"https://api.example.com/v10", false},
+		{"https://api.example.com/v1/../admin", false},
+		{"http://api.example.com/v1/health", false},
+		{"https://API.example.com/v1/health", true},
+		{"http://status.example.com/anything", true},
+		{"https://status.example.com:8443/", true},
+		{"https://web.internal.example.com/", true},
+		{"https://internal.example.com/", false},
+		{"https://evil-internal.example.com/", false},
+		{"http://10.0.0.5:8080/healthz", true},
+		{"http://10.0.0.5:9090/healthz", false},
+		{"https://other.example.com/", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := urlAllowed(u, allowed); got != tt.want {
+			t.Errorf("urlAllowed(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestProbe_RejectsUnlistedAndInvalidArgs(t *testing.T) {
+	tool := newTestTool(t, &CheckConfig{AllowedURLs: []string{"status.example.com"}})
+	cases := []map[string]interface{}{
+		{},
+		{"url": "ftp://status.example.com/"},
+		{"url": "https://other.example.com/"},
+		{"url": "https://user:pw@status.example.com/"},
+		{"url": "https://status.example.com/", "method": "DELETE"},
+		{"url": "https://status.example.com/", "body": "{}"},
+		{"url": "https://status.example.com/", "expect_body_regex": "("},
+	}
+	for _, args := range cases {
+		if _, err := tool.Probe(context.Background(), "test-incident", args); err == nil {
+			t.Errorf("Probe(%v): expected error", args)
+		}
+	}
+
+	empty := newTestTool(t, &CheckConfig{})
+	_, err := empty.Probe(context.Background(), "test-incident", map[string]interface{}{"url": "https://status.example.com/"})
+	if err == nil || !strings.Contains(err.Error(), "http_check_allowed_urls") {
+		t.Errorf("expected empty allowlist error, got %v", err)
+	}
+}
+
+func TestProbe_SuccessWithAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("X-Probe") != "1" {
+			t.Errorf("unexpected request %s %v", r.Method, r.Header)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"healthy","version":"1.2.3"}`))
+	}))
+	defer server.Close()
+
+	tool := newTestTool(t, &CheckConfig{
+		AllowedURLs: []string{server.URL},
+		Headers:     map[string]string{"Authorization": "Bearer s3cret"},
+	})
+	result := probe(t, tool, map[string]interface{}{
+		"url":                  server.URL + "/health",
+		"method":               "post",
+		"body":                 `{"ping":true}`,
+		"headers":              map[string]interface{}{"X-Probe": "1"},
+		"expect_status":        []interface{}{float64(200), float64(202)},
+		"expect_body_contains": `"healthy"`,
+		"expect_body_regex":    `version":"1\.\d+`,
+		"max_latency_ms":       float64(5000),
+	})
+
+	if !result.OK || result.StatusCode != http.StatusAccepted || result.Error != "" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Assertions) != 4 {
+		t.Errorf("expected 4 assertions, got %+v", result.Assertions)
+	}
+	if result.Headers["Content-Type"] != "application/json" || !strings.Contains(result.BodyExcerpt, "healthy") {
+		t.Errorf("headers=%v excerpt=%q", result.Headers, result.BodyExcerpt)
+	}
+	if result.TLS != nil {
+		t.Error("plain HTTP probe should not report TLS")
+	}
+}
+
+func TestProbe_FailedAssertionsAndDefaultStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tool := newTestTool(t, &CheckConfig{AllowedURLs: []string{server.URL}})
+	result := probe(t, tool, map[string]interface{}{"url": server.URL, "expect_body_contains": "ok"})
+	if result.OK || result.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("result = %+v", result)
+	}
+	for _, a := range result.Assertions {
+		if a.Passed {
+			t.Errorf("assertion %s should fail: %+v", a.Name, a)
+		}
+	}
+}
+
+func TestProbe_TLSCertificateReportedEvenWhenUntrusted(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	verifying := newTestTool(t, &CheckConfig{AllowedURLs: []string{server.URL}, VerifySSL: true})
+	result := probe(t, verifying, map[string]interface{}{"url": server.URL})
+	if result.OK || !strings.HasPrefix(result.Error, "tls: ") {
+		t.Fatalf("expected TLS verification failure, got %+v", result)
+	}
+	if result.TLS == nil || result.TLS.VerifyError == "" || result.TLS.NotAfter.IsZero() {
+		t.Fatalf("expected certificate details, got %+v", result.TLS)
+	}
+
+	lenient := newTestTool(t, &CheckConfig{AllowedURLs: []string{server.URL}, VerifySSL: false})
+	result = probe(t, lenient, map[string]interface{}{"url": server.URL})
+	if !result.OK || result.TLS == nil || result.TLS.DaysUntilExpiry <= 0 || result.TLS.Expired {
+		t.Fatalf("expected successful probe with certificate details, got %+v", result)
+	}
+}
+
+func TestProbe_RedirectMustBeAllowListed(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("landed"))
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/final", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	tool := newTestTool(t, &CheckConfig{AllowedURLs: []string{origin.URL}, FollowRedirects: true})
+	result := probe(t, tool, map[string]interface{}{"url": origin.URL})
+	if result.OK || !strings.Contains(result.Error, "not in the allowlist") {
+		t.Fatalf("expected redirect to be blocked, got %+v", result)
+	}
+
+	tool = newTestTool(t, &CheckConfig{AllowedURLs: []string{origin.URL, target.URL}, FollowRedirects: true})
+	result = probe(t, tool, map[string]interface{}{"url": origin.URL})
+	if !result.OK || result.FinalURL != target.URL+"/final" || result.Redirects != 1 {
+		t.Fatalf("expected followed redirect, got %+v", result)
+	}
+
+	tool = newTestTool(t, &CheckConfig{AllowedURLs: []string{origin.URL}})
+	result = probe(t, tool, map[string]interface{}{"url": origin.URL, "expect_status": []interface{}{float64(302)}})
+	if !result.OK || result.StatusCode != http.StatusFound || result.Headers["Location"] == "" {
+		t.Fatalf("expected unfollowed redirect, got %+v", result)
+	}
+}
+
+func TestProbe_ConnectionFailureIsAResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	addr := server.URL
+	server.Close()
+
+	tool := newTestTool(t, &CheckConfig{AllowedURLs: []string{addr}})
+	result := probe(t, tool, map[string]interface{}{"url": addr})
+	if result.OK || !strings.HasPrefix(result.Error, "connect: ") {
+		t.Fatalf("expected connect error in result, got %+v", result)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders("authorization: Bearer x\n\nX-Env:  prod \n")
+	if err != nil {
+		t.Fatalf("parseHeaders: %v", err)
+	}
+	if headers["Authorization"] != "Bearer x" || headers["X-Env"] != "prod" {
+		t.Errorf("headers = %v", headers)
+	}
+	if _, err := parseHeaders("not a header"); err == nil {
+		t.Error("expected error for line without colon")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt([]byte("héllo"), 2); got != "h" {
+		t.Errorf("excerpt cut mid-rune = %q", got)
+	}
+	if got := excerpt([]byte{0xff, 0xfe, 0x00}, 10); !strings.Contains(got, "binary") {
+		t.Errorf("binary excerpt = %q", got)
+	}
+}
@@ -98,7 +98,7 @@ func New() *HTTPConnectorExecutor {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("httpconnector_response"),
 		rateLimiters:  make(map[string]*ratelimit.Limiter),
 	}
 }
@@ -107,7 +107,7 @@ func New() *HTTPConnectorExecutor {
 func NewWithClient(client *http.Client) *HTTPConnectorExecutor {
 	return &HTTPConnectorExecutor{
 		client:        client,
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("httpconnector_response"),
 		rateLimiters:  make(map[string]*ratelimit.Limiter),
 	}
 }
@@ -132,7 +132,7 @@ func (e *HTTPConnectorExecutor) getRateLimiter(instanceKey string) *ratelimit.Li
 	if limiter, ok = e.rateLimiters[instanceKey]; ok {
 		return limiter
 	}
-	limiter = ratelimit.New(10, 20) // 10 req/sec, burst 20
+	limiter = ratelimit.New(10, 20).Named("http_connector") // 10 req/sec, burst 20
 	e.rateLimiters[instanceKey] = limiter
 	return limiter
 }
@@ -154,6 +154,27 @@ type incidentDetail struct {
 	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
+// loadLog returns the start of an incident's log from its chunks, reading
+// only as many as needed to pass maxFullLog bytes.
+func (t *IncidentsTool) loadLog(ctx context.Context, incidentUUID string) (string, error) {
+	rows, err := t.db.WithContext(ctx).Model(&database.IncidentLogChunk{}).
+		Select("content").Where("incident_uuid = ?", incidentUUID).Order("seq").Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	for rows.Next() && b.Len() <= maxFullLog {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return "", err
+		}
+		b.WriteString(content)
+	}
+	return b.String(), rows.Err()
+}
+
 // Get returns the full incident record for the given uuid.
 // FullLog is truncated to 50,000 bytes if longer.
 // Internal fields (WorkingDir, Context, SlackChannelID, SlackMessageTS) are omitted.
@@ -176,7 +197,10 @@ func (t *IncidentsTool) Get(ctx context.Context, _ string, args map[string]inter
 		return nil, err
 	}
 
-	fullLog := inc.FullLog
+	fullLog, err := t.loadLog(ctx, inc.UUID)
+	if err != nil {
+		return nil, err
+	}
 	if len(fullLog) > maxFullLog {
 		// Drop any incomplete multi-byte sequence at the boundary; empty
 		// replacement keeps the result within maxFullLog bytes.
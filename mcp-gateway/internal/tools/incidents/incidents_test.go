@@ -19,7 +19,7 @@ func newTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Incident{}); err != nil {
+	if err := db.AutoMigrate(&database.Incident{}, &database.IncidentLogChunk{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	return db
@@ -41,13 +41,23 @@ func insertIncident(t *testing.T, db *gorm.DB, uuid, title, status, sourceKind,
 		SourceKind: sourceKind,
 		SourceUUID: sourceUUID,
 		StartedAt:  startedAt,
-		FullLog:    fullLog,
 		Response:   "some response",
 		TokensUsed: 100,
 	}
 	if err := db.Create(&inc).Error; err != nil {
 		t.Fatalf("insert: %v", err)
 	}
+	// The API splits logs into chunks; two keep the reassembly covered.
+	half := len(fullLog) / 2
+	for seq, part := range []string{fullLog[:half], fullLog[half:]} {
+		if part == "" {
+			continue
+		}
+		chunk := database.IncidentLogChunk{IncidentUUID: uuid, Seq: seq, StartOffset: seq * half, Length: len(part), Content: part}
+		if err := db.Create(&chunk).Error; err != nil {
+			t.Fatalf("insert log chunk: %v", err)
+		}
+	}
 }
 
 // ---- List tests ----
@@ -69,8 +69,8 @@ type JiraTool struct {
 func NewJiraTool(logger *log.Logger, limiter *ratelimit.Limiter) *JiraTool {
 	return &JiraTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("jira_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("jira_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
@@ -13,6 +14,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -39,14 +41,17 @@ const (
 
 // K8sConfig holds Kubernetes connection configuration
 type K8sConfig struct {
-	URL       string // Kubernetes API server URL (e.g. https://k8s.example.com)
-	Token     string // Bearer token for authentication
-	CACert    string // Optional CA certificate for TLS verification
-	VerifySSL bool
-	Timeout   int
-	UseProxy  bool
-	ProxyURL  string
-	NoProxy   string // Comma-separated hostnames to bypass proxy
+	URL         string // Kubernetes API server URL (e.g. https://k8s.example.com)
+	Token       string // Bearer token for authentication
+	CACert      string // Optional CA certificate for TLS verification
+	ClientCert  string // PEM client certificate (from kubeconfig client-certificate-data)
+	ClientKey   string // PEM client key (from kubeconfig client-key-data)
+	VerifySSL   bool
+	Timeout     int
+	AllowWrites bool // Enables restart_deployment; off by default
+	UseProxy    bool
+	ProxyURL    string
+	NoProxy     string // Comma-separated hostnames to bypass proxy
 }
 
 // K8sTool handles Kubernetes API operations
@@ -61,8 +66,8 @@ type K8sTool struct {
 func NewK8sTool(logger *log.Logger, limiter *ratelimit.Limiter) *K8sTool {
 	return &K8sTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("k8s_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("k8s_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
@@ -136,22 +141,50 @@ func (t *K8sTool) getConfig(ctx context.Context, incidentID string, logicalName
 		return nil, fmt.Errorf("failed to get Kubernetes credentials: %w", err)
 	}
 
+	config, err := t.buildConfigFromSettings(ctx, creds.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the config
+	t.configCache.Set(cacheKey, config)
+	t.logger.Printf("Config cached for key %s", cacheKey)
+
+	return config, nil
+}
+
+// buildConfigFromSettings turns tool instance settings into a K8sConfig.
+// Connection details come from, in order of increasing precedence, the
+// gateway's own service account (k8s_in_cluster), a pasted kubeconfig
+// (k8s_kubeconfig) and the explicit k8s_url / k8s_token / k8s_ca_cert fields.
+func (t *K8sTool) buildConfigFromSettings(ctx context.Context, settings map[string]interface{}) (*K8sConfig, error) {
 	config := &K8sConfig{
 		VerifySSL: true,
 		Timeout:   30,
 	}
 
-	settings := creds.Settings
+	if inCluster, ok := settings["k8s_in_cluster"].(bool); ok && inCluster {
+		if err := applyInCluster(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := settings["k8s_kubeconfig"].(string); ok && strings.TrimSpace(raw) != "" {
+		contextName, _ := settings["k8s_context"].(string)
+		if err := applyKubeconfig(config, raw, contextName); err != nil {
+			return nil, err
+		}
+	}
 
-	if u, ok := settings["k8s_url"].(string); ok {
+	if u, ok := settings["k8s_url"].(string); ok && u != "" {
 		config.URL = strings.TrimSuffix(u, "/")
 	}
 
-	if token, ok := settings["k8s_token"].(string); ok {
+	if token, ok := settings["k8s_token"].(string); ok && token != "" {
 		config.Token = token
 	}
 
-	if caCert, ok := settings["k8s_ca_cert"].(string); ok {
+	if caCert, ok := settings["k8s_ca_cert"].(string); ok && caCert != "" {
 		config.CACert = caCert
 	}
 
@@ -163,6 +196,10 @@ func (t *K8sTool) getConfig(ctx context.Context, incidentID string, logicalName
 		config.Timeout = int(timeout)
 	}
 
+	if allow, ok := settings["k8s_allow_writes"].(bool); ok {
+		config.AllowWrites = allow
+	}
+
 	config.Timeout = clampTimeout(config.Timeout)
 
 	// Fetch proxy settings from database (also cached)
@@ -173,13 +210,46 @@ func (t *K8sTool) getConfig(ctx context.Context, incidentID string, logicalName
 		config.NoProxy = proxySettings.NoProxy
 	}
 
-	// Cache the config
-	t.configCache.Set(cacheKey, config)
-	t.logger.Printf("Config cached for key %s", cacheKey)
-
 	return config, nil
 }
 
+// writesDisabledErr is returned when a mutating call hits an instance without k8s_allow_writes.
+func writesDisabledErr() error {
+	return fmt.Errorf("writes disabled for this Kubernetes instance; enable k8s_allow_writes to allow")
+}
+
+// verifyWriteGate re-reads the instance settings from the database before a
+// write so that turning k8s_allow_writes off takes effect immediately rather
+// than after the config cache expires. Without a database (unit tests) the
+// cached config decides.
+func (t *K8sTool) verifyWriteGate(ctx context.Context, incidentID, logicalName string, cached *K8sConfig) (*K8sConfig, error) {
+	if database.DB == nil {
+		if cached == nil || !cached.AllowWrites {
+			return cached, writesDisabledErr()
+		}
+		return cached, nil
+	}
+	creds, err := database.ResolveToolCredentials(ctx, incidentID, "kubernetes", nil, logicalName)
+	if err != nil {
+		return cached, fmt.Errorf("failed to verify Kubernetes write gate: %w", err)
+	}
+	fresh, err := t.buildConfigFromSettings(ctx, creds.Settings)
+	if err != nil {
+		return cached, err
+	}
+
+	cacheKey := configCacheKey(incidentID)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("creds:logical:%s:%s", "kubernetes", logicalName)
+	}
+	t.configCache.Set(cacheKey, fresh)
+
+	if !fresh.AllowWrites {
+		return fresh, writesDisabledErr()
+	}
+	return fresh, nil
+}
+
 // getCachedProxySettings fetches proxy settings with caching
 func (t *K8sTool) getCachedProxySettings(ctx context.Context) *database.ProxySettings {
 	cacheKey := "proxy:settings"
@@ -202,9 +272,19 @@ func (t *K8sTool) getCachedProxySettings(ctx context.Context) *database.ProxySet
 // doRequest performs an HTTP request to Kubernetes API with rate limiting.
 // An optional maxBytes parameter overrides the default 5 MB response size limit.
 func (t *K8sTool) doRequest(ctx context.Context, config *K8sConfig, method, path string, queryParams url.Values, maxBytes ...int) ([]byte, error) {
-	// Validate token before consuming rate limit budget
-	if config.Token == "" {
-		return nil, fmt.Errorf("kubernetes API token is required but not configured")
+	return t.doRequestWithBody(ctx, config, method, path, queryParams, nil, "", maxBytes...)
+}
+
+// doRequestWithBody is doRequest with a request body, used by the write methods.
+func (t *K8sTool) doRequestWithBody(ctx context.Context, config *K8sConfig, method, path string, queryParams url.Values, body []byte, contentType string, maxBytes ...int) ([]byte, error) {
+	// Validate credentials before consuming rate limit budget
+	if config.Token == "" && config.ClientCert == "" {
+		return nil, fmt.Errorf("kubernetes API token is required but not configured (set k8s_token, k8s_kubeconfig or k8s_in_cluster)")
+	}
+
+	tlsConfig, err := t.buildTLSConfig(config)
+	if err != nil {
+		return nil, err
 	}
 
 	// Apply rate limiting
@@ -240,36 +320,31 @@ func (t *K8sTool) doRequest(ctx context.Context, config *K8sConfig, method, path
 		transport.Proxy = nil
 	}
 
-	if !config.VerifySSL {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // User-opt-in via k8s_verify_ssl setting
-	} else if config.CACert != "" {
-		// Load custom CA certificate for clusters using private/internal CAs
-		certPool, err := x509.SystemCertPool()
-		if err != nil {
-			certPool = x509.NewCertPool()
-		}
-		if !certPool.AppendCertsFromPEM([]byte(config.CACert)) {
-			t.logger.Printf("Warning: failed to parse custom CA certificate, using system CAs only")
-		}
-		if transport.TLSClientConfig == nil {
-			transport.TLSClientConfig = &tls.Config{}
-		}
-		transport.TLSClientConfig.RootCAs = certPool
-	}
+	transport.TLSClientConfig = tlsConfig
 
 	client := &http.Client{
 		Timeout:   time.Duration(config.Timeout) * time.Second,
 		Transport: transport,
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Kubernetes uses Bearer token authentication
-	httpReq.Header.Set("Authorization", "Bearer "+config.Token)
+	// Bearer token authentication, unless the kubeconfig user authenticates
+	// with a client certificate only.
+	if config.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+config.Token)
+	}
 	httpReq.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
@@ -308,6 +383,36 @@ func (t *K8sTool) doRequest(ctx context.Context, config *K8sConfig, method, path
 	return respBody, nil
 }
 
+// buildTLSConfig returns the TLS settings for config, or nil to use Go's defaults.
+func (t *K8sTool) buildTLSConfig(config *K8sConfig) (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	if !config.VerifySSL {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // User-opt-in via k8s_verify_ssl setting
+	} else if config.CACert != "" {
+		// Load custom CA certificate for clusters using private/internal CAs
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM([]byte(config.CACert)) {
+			t.logger.Printf("Warning: failed to parse custom CA certificate, using system CAs only")
+		}
+		tlsConfig = &tls.Config{RootCAs: certPool}
+	}
+
+	if config.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCert), []byte(config.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig client certificate: %w", err)
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
 // newNoProxyFunc returns a proxy function that respects the no_proxy bypass list.
 // Hosts in noProxy (comma-separated) are connected to directly without the proxy.
 func newNoProxyFunc(proxyURL *url.URL, noProxy string) func(*http.Request) (*url.URL, error) {
@@ -1110,3 +1215,205 @@ func (t *K8sTool) APIRequest(ctx context.Context, incidentID string, args map[st
 	}
 	return string(body), nil
 }
+
+// describeKind maps a resource kind to its API location.
+type describeKind struct {
+	Kind       string // Kind as it appears in involvedObject.kind
+	APIPrefix  string // /api/v1 or /apis/{group}/{version}
+	Resource   string // Plural resource name
+	Namespaced bool
+}
+
+// describeKinds lists the kinds describe_resource accepts, keyed by every
+// name kubectl accepts for them (singular, plural and short name). Secrets
+// are deliberately absent.
+var describeKinds = func() map[string]describeKind {
+	kinds := []struct {
+		names []string
+		kind  describeKind
+	}{
+		{[]string{"pod", "pods", "po"}, describeKind{"Pod", "/api/v1", "pods", true}},
+		{[]string{"service", "services", "svc"}, describeKind{"Service", "/api/v1", "services", true}},
+		{[]string{"configmap", "configmaps", "cm"}, describeKind{"ConfigMap", "/api/v1", "configmaps", true}},
+		{[]string{"persistentvolumeclaim", "persistentvolumeclaims", "pvc"}, describeKind{"PersistentVolumeClaim", "/api/v1", "persistentvolumeclaims", true}},
+		{[]string{"persistentvolume", "persistentvolumes", "pv"}, describeKind{"PersistentVolume", "/api/v1", "persistentvolumes", false}},
+		{[]string{"node", "nodes", "no"}, describeKind{"Node", "/api/v1", "nodes", false}},
+		{[]string{"namespace", "namespaces", "ns"}, describeKind{"Namespace", "/api/v1", "namespaces", false}},
+		{[]string{"deployment", "deployments", "deploy"}, describeKind{"Deployment", "/apis/apps/v1", "deployments", true}},
+		{[]string{"statefulset", "statefulsets", "sts"}, describeKind{"StatefulSet", "/apis/apps/v1", "statefulsets", true}},
+		{[]string{"daemonset", "daemonsets", "ds"}, describeKind{"DaemonSet", "/apis/apps/v1", "daemonsets", true}},
+		{[]string{"replicaset", "replicasets", "rs"}, describeKind{"ReplicaSet", "/apis/apps/v1", "replicasets", true}},
+		{[]string{"job", "jobs"}, describeKind{"Job", "/apis/batch/v1", "jobs", true}},
+		{[]string{"cronjob", "cronjobs", "cj"}, describeKind{"CronJob", "/apis/batch/v1", "cronjobs", true}},
+		{[]string{"ingress", "ingresses", "ing"}, describeKind{"Ingress", "/apis/networking.k8s.io/v1", "ingresses", true}},
+		{[]string{"horizontalpodautoscaler", "horizontalpodautoscalers", "hpa"}, describeKind{"HorizontalPodAutoscaler", "/apis/autoscaling/v2", "horizontalpodautoscalers", true}},
+	}
+	m := make(map[string]describeKind)
+	for _, k := range kinds {
+		for _, name := range k.names {
+			m[name] = k.kind
+		}
+	}
+	return m
+}()
+
+// DescribeResource returns a resource together with the events that reference
+// it, roughly what `kubectl describe` shows. managedFields are dropped and
+// ConfigMap data is stripped as in get_configmaps.
+func (t *K8sTool) DescribeResource(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	logicalName := extractLogicalName(args)
+
+	kindArg, err := requireString(args, "kind")
+	if err != nil {
+		return "", err
+	}
+	name, err := requireString(args, "name")
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.ToLower(strings.TrimSpace(kindArg))
+	if key == "secret" || key == "secrets" {
+		return "", fmt.Errorf("access to secrets is not allowed for security reasons")
+	}
+	kind, ok := describeKinds[key]
+	if !ok {
+		supported := make([]string, 0, len(describeKinds))
+		for n, k := range describeKinds {
+			if n == strings.ToLower(k.Kind) {
+				supported = append(supported, n)
+			}
+		}
+		sort.Strings(supported)
+		return "", fmt.Errorf("unsupported kind %q (supported: %s)", kindArg, strings.Join(supported, ", "))
+	}
+
+	namespace := optionalString(args, "namespace")
+	var path, eventsPath string
+	if kind.Namespaced {
+		if namespace == "" {
+			return "", fmt.Errorf("namespace is required for kind %s", kind.Kind)
+		}
+		path = fmt.Sprintf("%s/namespaces/%s/%s/%s", kind.APIPrefix, url.PathEscape(namespace), kind.Resource, url.PathEscape(name))
+		eventsPath = fmt.Sprintf("/api/v1/namespaces/%s/events", url.PathEscape(namespace))
+	} else {
+		path = fmt.Sprintf("%s/%s/%s", kind.APIPrefix, kind.Resource, url.PathEscape(name))
+		eventsPath = "/api/v1/events"
+	}
+
+	var body []byte
+	if kind.Resource == "configmaps" {
+		body, err = t.cachedGetConfigMaps(ctx, incidentID, path, nil, ServiceCacheTTL, logicalName)
+	} else {
+		body, err = t.cachedGet(ctx, incidentID, path, nil, ResponseCacheTTL, logicalName)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var resource map[string]interface{}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", kind.Kind, err)
+	}
+	if meta, ok := resource["metadata"].(map[string]interface{}); ok {
+		delete(meta, "managedFields")
+	}
+
+	result := map[string]interface{}{
+		"resource": resource,
+		"events":   []interface{}{},
+	}
+
+	// Events are best-effort: RBAC often grants get on a resource but not
+	// list on events, and the object alone is still useful.
+	params := url.Values{}
+	params.Set("fieldSelector", fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind.Kind, name))
+	eventsBody, err := t.cachedGet(ctx, incidentID, eventsPath, params, EventCacheTTL, logicalName)
+	if err != nil {
+		result["events_error"] = err.Error()
+	} else {
+		var events struct {
+			Items []interface{} `json:"items"`
+		}
+		if err := json.Unmarshal(eventsBody, &events); err == nil && events.Items != nil {
+			result["events"] = events.Items
+		}
+	}
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(out), nil
+}
+
+// RestartDeployment triggers a rolling restart of a deployment the way
+// `kubectl rollout restart` does, by stamping the pod template with a
+// restartedAt annotation. It requires k8s_allow_writes on the instance.
+func (t *K8sTool) RestartDeployment(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	logicalName := extractLogicalName(args)
+
+	namespace, err := requireString(args, "namespace")
+	if err != nil {
+		return "", err
+	}
+	name, err := requireString(args, "name")
+	if err != nil {
+		return "", err
+	}
+
+	config, err := t.getConfig(ctx, incidentID, logicalName)
+	if err != nil {
+		return "", err
+	}
+	config, err = t.verifyWriteGate(ctx, incidentID, logicalName, config)
+	if err != nil {
+		return "", err
+	}
+	if config.URL == "" {
+		return "", fmt.Errorf("kubernetes API URL not configured")
+	}
+
+	restartedAt := time.Now().UTC().Format(time.RFC3339)
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						"kubectl.kubernetes.io/restartedAt": restartedAt,
+					},
+				},
+			},
+		},
+	})
+
+	path := fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments/%s", url.PathEscape(namespace), url.PathEscape(name))
+	t.logger.Printf("K8s restarting deployment %s/%s", namespace, name)
+	body, err := t.doRequestWithBody(ctx, config, http.MethodPatch, path, nil, patch, "application/strategic-merge-patch+json")
+	if err != nil {
+		return "", err
+	}
+
+	// Drop cached reads so the agent sees the rollout rather than the
+	// pre-restart state.
+	if logicalName != "" {
+		t.responseCache.DeleteByPrefix(fmt.Sprintf("logical:%s:", logicalName))
+	} else {
+		t.responseCache.DeleteByPrefix(fmt.Sprintf("incident:%s:", incidentID))
+	}
+
+	var updated struct {
+		Metadata struct {
+			Generation int64 `json:"generation"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal(body, &updated)
+
+	out, _ := json.Marshal(map[string]interface{}{
+		"namespace":    namespace,
+		"name":         name,
+		"restarted_at": restartedAt,
+		"generation":   updated.Metadata.Generation,
+	})
+	return string(out), nil
+}
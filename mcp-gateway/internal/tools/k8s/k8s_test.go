@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/akmatori/mcp-gateway/internal/database"
 	"github.com/akmatori/mcp-gateway/internal/ratelimit"
 )
 
@@ -2753,3 +2755,274 @@ func TestAPIRequest_ServiceNamedConfigmapsNotStripped(t *testing.T) {
 		t.Error("expected data to NOT be stripped for a service named configmaps")
 	}
 }
+
+// --- Kubeconfig / in-cluster auth tests ---
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com:6443/
+    certificate-authority-data: LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t
+- name: staging-cluster
+  cluster:
+    server: https://staging.example.com:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: prod
+  context: {cluster: prod-cluster, user: prod-sa}
+- name: staging
+  context: {cluster: staging-cluster, user: staging-oidc}
+users:
+- name: prod-sa
+  user:
+    token: prod-token
+- name: staging-oidc
+  user:
+    exec:
+      command: kubelogin
+`
+
+func TestApplyKubeconfig_CurrentContext(t *testing.T) {
+	config := &K8sConfig{VerifySSL: true}
+	if err := applyKubeconfig(config, testKubeconfig, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.URL != "https://prod.example.com:6443" {
+		t.Errorf("URL = %q", config.URL)
+	}
+	if config.Token != "prod-token" {
+		t.Errorf("Token = %q", config.Token)
+	}
+	if config.CACert != "-----BEGIN CERTIFICATE-----" {
+		t.Errorf("CACert = %q", config.CACert)
+	}
+	if !config.VerifySSL {
+		t.Error("VerifySSL should stay true")
+	}
+}
+
+func TestApplyKubeconfig_Errors(t *testing.T) {
+	cases := map[string]struct {
+		raw, context, want string
+	}{
+		"exec plugin":     {testKubeconfig, "staging", "not supported"},
+		"unknown context": {testKubeconfig, "dev", `context "dev" not found`},
+		"invalid yaml":    {"clusters: [", "", "invalid kubeconfig"},
+		"no context":      {"clusters: []", "", "no current-context"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := applyKubeconfig(&K8sConfig{}, tc.raw, tc.context)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestApplyInCluster(t *testing.T) {
+	dir := t.TempDir()
+	oldToken, oldCA := inClusterTokenPath, inClusterCACertPath
+	inClusterTokenPath, inClusterCACertPath = dir+"/token", dir+"/ca.crt"
+	t.Cleanup(func() { inClusterTokenPath, inClusterCACertPath = oldToken, oldCA })
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if err := applyInCluster(&K8sConfig{}); err == nil {
+		t.Error("expected error outside a pod")
+	}
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	if err := os.WriteFile(inClusterTokenPath, []byte("sa-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inClusterCACertPath, []byte("ca-pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &K8sConfig{}
+	if err := applyInCluster(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.URL != "https://10.0.0.1:443" || config.Token != "sa-token" || config.CACert != "ca-pem" {
+		t.Errorf("unexpected config: %+v", config)
+	}
+}
+
+func TestBuildConfigFromSettings_Precedence(t *testing.T) {
+	tool := NewK8sTool(testLogger(), nil)
+	defer tool.Stop()
+	tool.configCache.Set("proxy:settings", &database.ProxySettings{})
+
+	config, err := tool.buildConfigFromSettings(context.Background(), map[string]interface{}{
+		"k8s_kubeconfig":   testKubeconfig,
+		"k8s_token":        "override-token",
+		"k8s_allow_writes": true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.URL != "https://prod.example.com:6443" {
+		t.Errorf("URL should come from kubeconfig, got %q", config.URL)
+	}
+	if config.Token != "override-token" {
+		t.Errorf("explicit k8s_token should win, got %q", config.Token)
+	}
+	if !config.AllowWrites {
+		t.Error("expected AllowWrites")
+	}
+
+	config, err = tool.buildConfigFromSettings(context.Background(), map[string]interface{}{
+		"k8s_url":   "https://k8s.example.com",
+		"k8s_token": "token",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.AllowWrites {
+		t.Error("writes must be disabled by default")
+	}
+}
+
+func TestDoRequest_InvalidClientCertificate(t *testing.T) {
+	tool := NewK8sTool(testLogger(), nil)
+	defer tool.Stop()
+
+	config := &K8sConfig{URL: "http://localhost", ClientCert: "not a cert", ClientKey: "not a key", VerifySSL: true}
+	_, err := tool.doRequest(context.Background(), config, http.MethodGet, "/api/v1/namespaces", nil)
+	if err == nil || !strings.Contains(err.Error(), "client certificate") {
+		t.Errorf("expected client certificate error, got %v", err)
+	}
+}
+
+// --- DescribeResource tests ---
+
+func TestDescribeResource_ResourceAndEvents(t *testing.T) {
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/prod/deployments/api":
+			fmt.Fprint(w, `{"kind":"Deployment","metadata":{"name":"api","managedFields":[{"manager":"kubectl"}]}}`)
+		case "/api/v1/namespaces/prod/events":
+			if got := r.URL.Query().Get("fieldSelector"); got != "involvedObject.kind=Deployment,involvedObject.name=api" {
+				t.Errorf("fieldSelector = %q", got)
+			}
+			fmt.Fprint(w, `{"items":[{"reason":"ScalingReplicaSet"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	result, err := tool.DescribeResource(context.Background(), "test-incident", map[string]interface{}{
+		"kind": "deploy", "name": "api", "namespace": "prod",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(result, "managedFields") {
+		t.Error("managedFields should be removed")
+	}
+	if !strings.Contains(result, `"events":[{"reason":"ScalingReplicaSet"}]`) {
+		t.Errorf("expected events in result, got %s", result)
+	}
+}
+
+func TestDescribeResource_ClusterScopedAndEventsError(t *testing.T) {
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/nodes/node-1":
+			fmt.Fprint(w, `{"kind":"Node","metadata":{"name":"node-1"}}`)
+		case "/api/v1/events":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"forbidden"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	result, err := tool.DescribeResource(context.Background(), "test-incident", map[string]interface{}{
+		"kind": "Node", "name": "node-1",
+	})
+	if err != nil {
+		t.Fatalf("events failure should not fail the describe: %v", err)
+	}
+	if !strings.Contains(result, `"events_error"`) || !strings.Contains(result, `"node-1"`) {
+		t.Errorf("unexpected result %s", result)
+	}
+}
+
+func TestDescribeResource_Validation(t *testing.T) {
+	tool, _, counter := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	cases := map[string]struct {
+		args map[string]interface{}
+		want string
+	}{
+		"secret":            {map[string]interface{}{"kind": "Secret", "name": "db", "namespace": "prod"}, "secrets is not allowed"},
+		"unknown kind":      {map[string]interface{}{"kind": "widget", "name": "x"}, "unsupported kind"},
+		"missing namespace": {map[string]interface{}{"kind": "pod", "name": "x"}, "namespace is required"},
+		"missing name":      {map[string]interface{}{"kind": "pod"}, "name is required"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tool.DescribeResource(context.Background(), "test-incident", tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+	if counter.Load() != 0 {
+		t.Error("invalid arguments must not reach the API server")
+	}
+}
+
+// --- RestartDeployment tests ---
+
+func TestRestartDeployment_WritesDisabled(t *testing.T) {
+	tool, _, counter := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := tool.RestartDeployment(context.Background(), "test-incident", map[string]interface{}{
+		"namespace": "prod", "name": "api",
+	})
+	if err == nil || !strings.Contains(err.Error(), "k8s_allow_writes") {
+		t.Errorf("expected writes disabled error, got %v", err)
+	}
+	if counter.Load() != 0 {
+		t.Error("gated write must not reach the API server")
+	}
+}
+
+func TestRestartDeployment_PatchesTemplateAnnotation(t *testing.T) {
+	tool, _, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/apis/apps/v1/namespaces/prod/deployments/api" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/strategic-merge-patch+json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"kubectl.kubernetes.io/restartedAt"`) {
+			t.Errorf("patch body = %s", body)
+		}
+		fmt.Fprint(w, `{"metadata":{"name":"api","generation":7}}`)
+	})
+	getTestConfig(tool).AllowWrites = true
+	tool.responseCache.Set("incident:test-incident:stale", []byte("old"))
+
+	result, err := tool.RestartDeployment(context.Background(), "test-incident", map[string]interface{}{
+		"namespace": "prod", "name": "api",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, `"generation":7`) || !strings.Contains(result, `"restarted_at"`) {
+		t.Errorf("unexpected result %s", result)
+	}
+	if _, ok := tool.responseCache.Get("incident:test-incident:stale"); ok {
+		t.Error("expected cached reads to be invalidated after a restart")
+	}
+}
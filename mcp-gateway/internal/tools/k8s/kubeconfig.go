@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// In-cluster service account locations. Variables so tests can point them at
+// a temporary directory.
+var (
+	inClusterTokenPath  = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCACertPath = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// kubeconfig is the subset of a kubeconfig file the tool understands: a
+// server, CA and bearer token or client certificate. Exec and auth-provider
+// plugins are not supported since the gateway cannot run them.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  interface{} `yaml:"exec"`
+			AuthProvider          interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// applyKubeconfig fills config from the named context of a kubeconfig
+// document, or its current-context when contextName is empty.
+func applyKubeconfig(config *K8sConfig, raw, contextName string) error {
+	var kc kubeconfig
+	if err := yaml.Unmarshal([]byte(raw), &kc); err != nil {
+		return fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	if contextName == "" {
+		return fmt.Errorf("kubeconfig has no current-context; set k8s_context")
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	found = false
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		config.URL = strings.TrimSuffix(c.Cluster.Server, "/")
+		if c.Cluster.InsecureSkipTLSVerify {
+			config.VerifySSL = false
+		}
+		if c.Cluster.CertificateAuthorityData != "" {
+			ca, err := base64.StdEncoding.DecodeString(c.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return fmt.Errorf("invalid certificate-authority-data for cluster %q: %w", clusterName, err)
+			}
+			config.CACert = string(ca)
+		}
+		break
+	}
+	if !found {
+		return fmt.Errorf("cluster %q not found in kubeconfig", clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return fmt.Errorf("user %q uses an exec or auth-provider plugin, which is not supported; use a service account token instead", userName)
+		}
+		config.Token = u.User.Token
+		if u.User.ClientCertificateData != "" {
+			cert, err := base64.StdEncoding.DecodeString(u.User.ClientCertificateData)
+			if err != nil {
+				return fmt.Errorf("invalid client-certificate-data for user %q: %w", userName, err)
+			}
+			key, err := base64.StdEncoding.DecodeString(u.User.ClientKeyData)
+			if err != nil {
+				return fmt.Errorf("invalid client-key-data for user %q: %w", userName, err)
+			}
+			config.ClientCert = string(cert)
+			config.ClientKey = string(key)
+		}
+		return nil
+	}
+	return fmt.Errorf("user %q not found in kubeconfig", userName)
+}
+
+// applyInCluster fills config from the service account the gateway pod runs
+// as, the same way client-go's rest.InClusterConfig does.
+func applyInCluster(config *K8sConfig) error {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return fmt.Errorf("k8s_in_cluster is set but KUBERNETES_SERVICE_HOST/KUBERNETES_SERVICE_PORT are not; is the gateway running in a pod?")
+	}
+	token, err := os.ReadFile(inClusterTokenPath)
+	if err != nil {
+		return fmt.Errorf("failed to read service account token: %w", err)
+	}
+	config.URL = "https://" + net.JoinHostPort(host, port)
+	config.Token = strings.TrimSpace(string(token))
+	if ca, err := os.ReadFile(inClusterCACertPath); err == nil {
+		config.CACert = string(ca)
+	}
+	return nil
+}
@@ -0,0 +1,778 @@
+package logsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/cache"
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/ratelimit"
+	"github.com/akmatori/mcp-gateway/internal/validation"
+)
+
+// Cache TTL constants
+const (
+	ConfigCacheTTL   = 5 * time.Minute  // Credentials cache TTL
+	ResponseCacheTTL = 15 * time.Second // Default API response cache TTL
+	CacheCleanupTick = time.Minute      // Background cleanup interval
+	SearchTTL        = 15 * time.Second // Log search cache TTL
+	LabelsTTL        = 60 * time.Second // Loki label metadata cache TTL
+)
+
+// Backends supported by the log_search tool type
+const (
+	BackendLoki          = "loki"
+	BackendElasticsearch = "elasticsearch"
+)
+
+// Output limits. Per-instance settings may lower the result count and time
+// range but never raise them past these caps.
+const (
+	DefaultMaxResults   = 100
+	MaxResultsCap       = 1000
+	DefaultMaxRangeHour = 24
+	MaxRangeHourCap     = 24 * 7
+	DefaultLookback     = time.Hour
+	MaxLineChars        = 2000       // longer log lines are cut
+	MaxOutputBytes      = 256 * 1024 // total size of the entries returned
+)
+
+// LogConfig holds log backend connection configuration
+type LogConfig struct {
+	Backend      string // "loki" or "elasticsearch"
+	URL          string
+	AuthMethod   string // "none", "basic_auth", "bearer_token", "api_key"
+	Username     string
+	Password     string
+	BearerToken  string
+	APIKey       string
+	TenantID     string // Loki X-Scope-OrgID
+	DefaultIndex string // Elasticsearch index pattern
+	TimeField    string // Elasticsearch timestamp field
+	MaxResults   int
+	MaxRange     time.Duration
+	VerifySSL    bool
+	Timeout      int
+	UseProxy     bool
+	ProxyURL     string
+}
+
+// LogEntry is a single log line in the normalized output
+type LogEntry struct {
+	Timestamp string            `json:"timestamp"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Index     string            `json:"index,omitempty"`
+	ID        string            `json:"id,omitempty"`
+	Line      string            `json:"line"`
+}
+
+// SearchResult is the normalized response for both backends
+type SearchResult struct {
+	Backend   string     `json:"backend"`
+	Start     string     `json:"start"`
+	End       string     `json:"end"`
+	Total     int        `json:"total"`
+	Returned  int        `json:"returned"`
+	Truncated bool       `json:"truncated"`
+	Entries   []LogEntry `json:"entries"`
+}
+
+// LogSearchTool searches logs in Loki (LogQL) or Elasticsearch (query DSL)
+type LogSearchTool struct {
+	logger        *log.Logger
+	configCache   *cache.Cache // Cache for credentials (5 min TTL)
+	responseCache *cache.Cache // Cache for API responses (15-60 sec TTL)
+	rateLimiter   *ratelimit.Limiter
+	now           func() time.Time
+}
+
+// NewLogSearchTool creates a new log search tool with optional rate limiter
+func NewLogSearchTool(logger *log.Logger, limiter *ratelimit.Limiter) *LogSearchTool {
+	return &LogSearchTool{
+		logger:        logger,
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("logsearch_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("logsearch_response").Shared(cache.JSON[string]()),
+		rateLimiter:   limiter,
+		now:           time.Now,
+	}
+}
+
+// Stop cleans up cache resources
+func (t *LogSearchTool) Stop() {
+	if t.configCache != nil {
+		t.configCache.Stop()
+	}
+	if t.responseCache != nil {
+		t.responseCache.Stop()
+	}
+}
+
+// configCacheKey returns the cache key for config/credentials
+func configCacheKey(incidentID string) string {
+	return fmt.Sprintf("creds:%s:log_search", incidentID)
+}
+
+// responseCacheKey returns the cache key for API responses
+func responseCacheKey(path string, params interface{}) string {
+	paramsJSON, _ := json.Marshal(params)
+	hash := sha256.Sum256(paramsJSON)
+	return fmt.Sprintf("%s:%s", path, hex.EncodeToString(hash[:8]))
+}
+
+// extractLogicalName extracts the optional logical_name from tool arguments.
+func extractLogicalName(args map[string]interface{}) string {
+	if v, ok := args["logical_name"].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// clampTimeout ensures timeout is within a safe range (1-300 seconds), defaulting to 30.
+func clampTimeout(timeout int) int {
+	if timeout <= 0 {
+		return 30
+	}
+	if timeout > 300 {
+		return 300
+	}
+	return timeout
+}
+
+// clampInt bounds v to [1, max], using def when v is not positive.
+func clampInt(v, def, max int) int {
+	if v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// getConfig fetches log backend configuration from database with caching.
+func (t *LogSearchTool) getConfig(ctx context.Context, incidentID, logicalName string) (*LogConfig, error) {
+	cacheKey := configCacheKey(incidentID)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("creds:logical:%s:%s", "log_search", logicalName)
+	}
+
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if config, ok := cached.(*LogConfig); ok {
+			t.logger.Printf("Config cache hit for key %s", cacheKey)
+			return config, nil
+		}
+	}
+
+	creds, err := database.ResolveToolCredentials(ctx, incidentID, "log_search", nil, logicalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log search credentials: %w", err)
+	}
+
+	config := &LogConfig{
+		Backend:    BackendLoki,
+		AuthMethod: "none",
+		TimeField:  "@timestamp",
+		VerifySSL:  true,
+		Timeout:    30,
+	}
+
+	settings := creds.Settings
+	maxResults, maxRangeHours := 0, 0
+
+	if backend, ok := settings["logs_backend"].(string); ok && backend != "" {
+		config.Backend = backend
+	}
+	if u, ok := settings["logs_url"].(string); ok {
+		config.URL = strings.TrimSuffix(u, "/")
+	}
+	if method, ok := settings["logs_auth_method"].(string); ok && method != "" {
+		config.AuthMethod = method
+	}
+	if user, ok := settings["logs_username"].(string); ok {
+		config.Username = user
+	}
+	if pass, ok := settings["logs_password"].(string); ok {
+		config.Password = pass
+	}
+	if token, ok := settings["logs_bearer_token"].(string); ok {
+		config.BearerToken = token
+	}
+	if key, ok := settings["logs_api_key"].(string); ok {
+		config.APIKey = key
+	}
+	if tenant, ok := settings["logs_tenant_id"].(string); ok {
+		config.TenantID = tenant
+	}
+	if index, ok := settings["logs_default_index"].(string); ok {
+		config.DefaultIndex = index
+	}
+	if field, ok := settings["logs_time_field"].(string); ok && field != "" {
+		config.TimeField = field
+	}
+	if n, ok := settings["logs_max_results"].(float64); ok {
+		maxResults = int(n)
+	}
+	if h, ok := settings["logs_max_range_hours"].(float64); ok {
+		maxRangeHours = int(h)
+	}
+	if verify, ok := settings["logs_verify_ssl"].(bool); ok {
+		config.VerifySSL = verify
+	}
+	if timeout, ok := settings["logs_timeout"].(float64); ok {
+		config.Timeout = int(timeout)
+	}
+	config.Timeout = clampTimeout(config.Timeout)
+	config.MaxResults = clampInt(maxResults, DefaultMaxResults, MaxResultsCap)
+	config.MaxRange = time.Duration(clampInt(maxRangeHours, DefaultMaxRangeHour, MaxRangeHourCap)) * time.Hour
+
+	if config.Backend != BackendLoki && config.Backend != BackendElasticsearch {
+		return nil, fmt.Errorf("unknown logs_backend '%s' (expected 'loki' or 'elasticsearch')", config.Backend)
+	}
+
+	proxySettings := t.getCachedProxySettings(ctx)
+	if proxySettings != nil && proxySettings.ProxyURL != "" && proxySettings.LogSearchEnabled {
+		config.UseProxy = true
+		config.ProxyURL = proxySettings.ProxyURL
+	}
+
+	t.configCache.Set(cacheKey, config)
+	t.logger.Printf("Config cached for key %s", cacheKey)
+
+	return config, nil
+}
+
+// getCachedProxySettings fetches proxy settings with caching
+func (t *LogSearchTool) getCachedProxySettings(ctx context.Context) *database.ProxySettings {
+	cacheKey := "proxy:settings"
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if settings, ok := cached.(*database.ProxySettings); ok {
+			return settings
+		}
+	}
+
+	proxySettings, err := database.GetProxySettings(ctx)
+	if err != nil || proxySettings == nil {
+		return nil
+	}
+
+	t.configCache.Set(cacheKey, proxySettings)
+
+	return proxySettings
+}
+
+// resolveConfig loads the instance config and checks it serves the backend
+// the called function is written for.
+func (t *LogSearchTool) resolveConfig(ctx context.Context, incidentID string, args map[string]interface{}, backend string) (*LogConfig, error) {
+	config, err := t.getConfig(ctx, incidentID, extractLogicalName(args))
+	if err != nil {
+		return nil, err
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("log search URL not configured")
+	}
+	if config.Backend != backend {
+		return nil, fmt.Errorf("this log_search instance is backed by %s; use the %s functions instead", config.Backend, config.Backend)
+	}
+	return config, nil
+}
+
+// doRequest performs an HTTP request to the log backend with rate limiting
+func (t *LogSearchTool) doRequest(ctx context.Context, config *LogConfig, method, path string, params url.Values, body []byte) ([]byte, error) {
+	if t.rateLimiter != nil {
+		if err := t.rateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
+		}
+	}
+
+	fullURL := config.URL + path
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	t.logger.Printf("Log search API call: %s %s", method, path)
+
+	// DisableKeepAlives prevents connection pool leakage since we create a new transport per request
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+	}
+	if !config.VerifySSL {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // User-opt-in via logs_verify_ssl setting
+	}
+
+	// Handle proxy settings - MUST explicitly set Proxy to prevent env var usage
+	if config.UseProxy && config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			t.logger.Printf("Invalid proxy URL: %v, proceeding without proxy", err)
+			transport.Proxy = nil
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+			t.logger.Printf("Log search using proxy: %s", proxyURL.Host)
+		}
+	} else {
+		transport.Proxy = nil
+	}
+
+	client := &http.Client{
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		Transport: transport,
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if config.TenantID != "" {
+		httpReq.Header.Set("X-Scope-OrgID", config.TenantID)
+	}
+
+	switch config.AuthMethod {
+	case "bearer_token":
+		if config.BearerToken == "" {
+			return nil, fmt.Errorf("auth_method is 'bearer_token' but no token configured")
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+config.BearerToken)
+	case "basic_auth":
+		if config.Username == "" {
+			return nil, fmt.Errorf("auth_method is 'basic_auth' but no username configured")
+		}
+		httpReq.SetBasicAuth(config.Username, config.Password)
+	case "api_key":
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("auth_method is 'api_key' but no API key configured")
+		}
+		httpReq.Header.Set("Authorization", "ApiKey "+config.APIKey)
+	case "none":
+		// No auth
+	default:
+		return nil, fmt.Errorf("unknown auth_method '%s'", config.AuthMethod)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	const maxResponseBytes = 20 * 1024 * 1024 // 20 MB
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(respBody) > maxResponseBytes {
+		return nil, fmt.Errorf("response exceeds %d MB limit; narrow the query or time range", maxResponseBytes/(1024*1024))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, truncate(string(respBody), MaxLineChars))
+	}
+
+	return respBody, nil
+}
+
+// cached wraps a backend call with the response cache, keyed per incident
+// or logical name so instances never share results.
+func (t *LogSearchTool) cached(incidentID, logicalName, path string, key interface{}, ttl time.Duration, fetch func() (string, error)) (string, error) {
+	cacheKey := responseCacheKey(path, key)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("logical:%s:%s", logicalName, cacheKey)
+	} else {
+		cacheKey = fmt.Sprintf("incident:%s:%s", incidentID, cacheKey)
+	}
+
+	if cached, ok := t.responseCache.Get(cacheKey); ok {
+		if result, ok := cached.(string); ok {
+			t.logger.Printf("Response cache hit for %s", path)
+			return result, nil
+		}
+	}
+
+	result, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	t.responseCache.SetWithTTL(cacheKey, result, ttl)
+	t.logger.Printf("Response cached for %s (TTL: %v)", path, ttl)
+	return result, nil
+}
+
+// parseTime accepts "now", RFC3339, Unix seconds, or a duration ("15m",
+// "2h") meaning that long before now.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "now" {
+		return now, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		return ts, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(secs*float64(time.Second))), nil
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(s, "-")); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339, Unix seconds, or a duration like '30m')", s)
+}
+
+// timeRange resolves start/end arguments and enforces the instance's maximum
+// range. Omitted start defaults to DefaultLookback before end.
+func timeRange(args map[string]interface{}, now time.Time, maxRange time.Duration) (time.Time, time.Time, error) {
+	endStr, _ := args["end"].(string)
+	end, err := parseTime(endStr, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end: %w", err)
+	}
+	start := end.Add(-DefaultLookback)
+	if startStr, ok := args["start"].(string); ok && startStr != "" {
+		if start, err = parseTime(startStr, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start must be before end")
+	}
+	if end.Sub(start) > maxRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("time range %s exceeds the %s limit for this instance; narrow start/end", end.Sub(start), maxRange)
+	}
+	return start, end, nil
+}
+
+// resultLimit reads the optional "limit" argument, bounded by the instance
+// maximum.
+func resultLimit(args map[string]interface{}, max int) int {
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		return clampInt(int(v), max, max)
+	}
+	return max
+}
+
+// truncate shortens s to n runes, marking the cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "...[truncated]"
+}
+
+// finish caps entries to the output budget and marshals the result.
+func finish(result *SearchResult) (string, error) {
+	size := 0
+	for i, e := range result.Entries {
+		size += len(e.Line) + len(e.Timestamp) + 64
+		for k, v := range e.Labels {
+			size += len(k) + len(v)
+		}
+		if size > MaxOutputBytes {
+			result.Entries = result.Entries[:i]
+			result.Truncated = true
+			break
+		}
+	}
+	result.Returned = len(result.Entries)
+	if result.Entries == nil {
+		result.Entries = []LogEntry{}
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(out), nil
+}
+
+// lokiResponse is the Loki query_range response envelope for log queries
+type lokiResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+	Error string `json:"error,omitempty"`
+}
+
+// LokiQuery runs a LogQL log query over a time range
+func (t *LogSearchTool) LokiQuery(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return "", fmt.Errorf("query is required%s", validation.SuggestParam("query", args))
+	}
+	direction := "backward"
+	if d, ok := args["direction"].(string); ok && d != "" {
+		if d != "forward" && d != "backward" {
+			return "", fmt.Errorf("direction must be 'forward' or 'backward'")
+		}
+		direction = d
+	}
+
+	config, err := t.resolveConfig(ctx, incidentID, args, BackendLoki)
+	if err != nil {
+		return "", err
+	}
+	start, end, err := timeRange(args, t.now(), config.MaxRange)
+	if err != nil {
+		return "", err
+	}
+	limit := resultLimit(args, config.MaxResults)
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	// Fetch one extra line so truncation by limit can be reported.
+	params.Set("limit", strconv.Itoa(limit+1))
+	params.Set("direction", direction)
+
+	const path = "/loki/api/v1/query_range"
+	return t.cached(incidentID, extractLogicalName(args), path, params, SearchTTL, func() (string, error) {
+		body, err := t.doRequest(ctx, config, http.MethodGet, path, params, nil)
+		if err != nil {
+			return "", err
+		}
+		var resp lokiResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return "", fmt.Errorf("failed to parse Loki response: %w", err)
+		}
+		if resp.Status != "success" {
+			return "", fmt.Errorf("Loki API error: %s", resp.Error)
+		}
+		if resp.Data.ResultType != "streams" {
+			return "", fmt.Errorf("query returned %s, not log lines; use a log query (metric queries belong in prometheus.query)", resp.Data.ResultType)
+		}
+
+		type stamped struct {
+			ns    int64
+			entry LogEntry
+		}
+		var lines []stamped
+		for _, stream := range resp.Data.Result {
+			for _, v := range stream.Values {
+				ns, _ := strconv.ParseInt(v[0], 10, 64)
+				lines = append(lines, stamped{ns: ns, entry: LogEntry{
+					Timestamp: time.Unix(0, ns).UTC().Format(time.RFC3339Nano),
+					Labels:    stream.Stream,
+					Line:      truncate(v[1], MaxLineChars),
+				}})
+			}
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			if direction == "forward" {
+				return lines[i].ns < lines[j].ns
+			}
+			return lines[i].ns > lines[j].ns
+		})
+
+		result := &SearchResult{
+			Backend: BackendLoki,
+			Start:   start.UTC().Format(time.RFC3339),
+			End:     end.UTC().Format(time.RFC3339),
+			Total:   len(lines),
+		}
+		if len(lines) > limit {
+			lines = lines[:limit]
+			result.Truncated = true
+		}
+		for _, l := range lines {
+			result.Entries = append(result.Entries, l.entry)
+		}
+		return finish(result)
+	})
+}
+
+// LokiLabels lists Loki label names, or the values of one label when
+// label_name is given
+func (t *LogSearchTool) LokiLabels(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	config, err := t.resolveConfig(ctx, incidentID, args, BackendLoki)
+	if err != nil {
+		return "", err
+	}
+	start, end, err := timeRange(args, t.now(), config.MaxRange)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	path := "/loki/api/v1/labels"
+	if name, ok := args["label_name"].(string); ok && name != "" {
+		path = fmt.Sprintf("/loki/api/v1/label/%s/values", url.PathEscape(name))
+		if q, ok := args["query"].(string); ok && q != "" {
+			params.Set("query", q)
+		}
+	}
+
+	return t.cached(incidentID, extractLogicalName(args), path, params, LabelsTTL, func() (string, error) {
+		body, err := t.doRequest(ctx, config, http.MethodGet, path, params, nil)
+		if err != nil {
+			return "", err
+		}
+		var resp struct {
+			Status string   `json:"status"`
+			Data   []string `json:"data"`
+			Error  string   `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return "", fmt.Errorf("failed to parse Loki response: %w", err)
+		}
+		if resp.Status != "success" {
+			return "", fmt.Errorf("Loki API error: %s", resp.Error)
+		}
+		out, err := json.Marshal(resp.Data)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	})
+}
+
+// esResponse is the subset of an Elasticsearch _search response we read
+type esResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Index  string                 `json:"_index"`
+			ID     string                 `json:"_id"`
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// esQueryClause turns the query argument into a query DSL clause: objects
+// are used as-is, strings become a query_string query (Lucene syntax).
+func esQueryClause(v interface{}) (interface{}, error) {
+	switch q := v.(type) {
+	case nil:
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	case string:
+		q = strings.TrimSpace(q)
+		if q == "" {
+			return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+		}
+		if strings.HasPrefix(q, "{") {
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(q), &obj); err != nil {
+				return nil, fmt.Errorf("query looks like JSON but does not parse: %w", err)
+			}
+			return unwrapQuery(obj), nil
+		}
+		return map[string]interface{}{"query_string": map[string]interface{}{"query": q}}, nil
+	case map[string]interface{}:
+		return unwrapQuery(q), nil
+	default:
+		return nil, fmt.Errorf("query must be a query DSL object or a query string")
+	}
+}
+
+// unwrapQuery accepts both {"query": {...}} request bodies and bare clauses.
+func unwrapQuery(obj map[string]interface{}) interface{} {
+	if inner, ok := obj["query"].(map[string]interface{}); ok && len(obj) == 1 {
+		return inner
+	}
+	return obj
+}
+
+// ElasticsearchSearch runs a query DSL (or query string) search over an
+// index pattern within a time range
+func (t *LogSearchTool) ElasticsearchSearch(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	clause, err := esQueryClause(args["query"])
+	if err != nil {
+		return "", err
+	}
+
+	config, err := t.resolveConfig(ctx, incidentID, args, BackendElasticsearch)
+	if err != nil {
+		return "", err
+	}
+	index, _ := args["index"].(string)
+	if index == "" {
+		index = config.DefaultIndex
+	}
+	if index == "" {
+		return "", fmt.Errorf("index is required (no logs_default_index configured)%s", validation.SuggestParam("index", args))
+	}
+	start, end, err := timeRange(args, t.now(), config.MaxRange)
+	if err != nil {
+		return "", err
+	}
+	limit := resultLimit(args, config.MaxResults)
+
+	request := map[string]interface{}{
+		"size":             limit,
+		"track_total_hits": true,
+		"sort":             []interface{}{map[string]interface{}{config.TimeField: map[string]interface{}{"order": "desc", "unmapped_type": "date"}}},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": []interface{}{clause},
+				"filter": []interface{}{map[string]interface{}{
+					"range": map[string]interface{}{config.TimeField: map[string]interface{}{
+						"gte":    start.UTC().Format(time.RFC3339Nano),
+						"lte":    end.UTC().Format(time.RFC3339Nano),
+						"format": "strict_date_optional_time",
+					}},
+				}},
+			},
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode search request: %w", err)
+	}
+
+	path := fmt.Sprintf("/%s/_search", url.PathEscape(index))
+	return t.cached(incidentID, extractLogicalName(args), path, string(body), SearchTTL, func() (string, error) {
+		respBody, err := t.doRequest(ctx, config, http.MethodPost, path, nil, body)
+		if err != nil {
+			return "", err
+		}
+		var resp esResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return "", fmt.Errorf("failed to parse Elasticsearch response: %w", err)
+		}
+
+		result := &SearchResult{
+			Backend:   BackendElasticsearch,
+			Start:     start.UTC().Format(time.RFC3339),
+			End:       end.UTC().Format(time.RFC3339),
+			Total:     resp.Hits.Total.Value,
+			Truncated: resp.Hits.Total.Value > len(resp.Hits.Hits),
+		}
+		for _, hit := range resp.Hits.Hits {
+			ts, _ := hit.Source[config.TimeField].(string)
+			line, _ := json.Marshal(hit.Source)
+			result.Entries = append(result.Entries, LogEntry{
+				Timestamp: ts,
+				Index:     hit.Index,
+				ID:        hit.ID,
+				Line:      truncate(string(line), MaxLineChars),
+			})
+		}
+		return finish(result)
+	})
+}
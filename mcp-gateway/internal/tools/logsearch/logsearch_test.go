@@ -0,0 +1,284 @@
+package logsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fixedNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func testLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+// newTestTool creates a LogSearchTool whose config cache points at an
+// httptest server, so getConfig never touches the database.
+func newTestTool(t *testing.T, backend string, handler http.HandlerFunc, mutate ...func(*LogConfig)) (*LogSearchTool, *atomic.Int32) {
+	t.Helper()
+	counter := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.Add(1)
+		handler(w, r)
+	}))
+
+	tool := NewLogSearchTool(testLogger(), nil)
+	tool.now = func() time.Time { return fixedNow }
+	config := &LogConfig{
+		Backend:    backend,
+		URL:        server.URL,
+		AuthMethod: "none",
+		TimeField:  "@timestamp",
+		MaxResults: DefaultMaxResults,
+		MaxRange:   DefaultMaxRangeHour * time.Hour,
+		VerifySSL:  true,
+		Timeout:    5,
+	}
+	for _, m := range mutate {
+		m(config)
+	}
+	tool.configCache.Set(configCacheKey("test-incident"), config)
+
+	t.Cleanup(func() {
+		tool.Stop()
+		server.Close()
+	})
+	return tool, counter
+}
+
+func decodeResult(t *testing.T, out string) SearchResult {
+	t.Helper()
+	var result SearchResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode result: %v (%s)", err, out)
+	}
+	return result
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", fixedNow},
+		{"now", fixedNow},
+		{"2026-10-16T10:00:00Z", time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)},
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"30m", fixedNow.Add(-30 * time.Minute)},
+		{"-2h", fixedNow.Add(-2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.in, fixedNow)
+		if err != nil {
+			t.Errorf("parseTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseTime("yesterday", fixedNow); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	start, end, err := timeRange(map[string]interface{}{}, fixedNow, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !end.Equal(fixedNow) || !start.Equal(fixedNow.Add(-DefaultLookback)) {
+		t.Errorf("default range = %v..%v", start, end)
+	}
+
+	if _, _, err := timeRange(map[string]interface{}{"start": "48h"}, fixedNow, 24*time.Hour); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected range limit error, got %v", err)
+	}
+	if _, _, err := timeRange(map[string]interface{}{"start": "now", "end": "1h"}, fixedNow, 24*time.Hour); err == nil {
+		t.Error("expected error when start is after end")
+	}
+}
+
+func TestLokiQuery_NormalizesAndTruncates(t *testing.T) {
+	longLine := strings.Repeat("x", MaxLineChars+50)
+	tool, counter := newTestTool(t, BackendLoki, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/query_range" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("query") != `{app="api"} |= "error"` || q.Get("limit") != "3" || q.Get("direction") != "backward" {
+			t.Errorf("unexpected params %v", q)
+		}
+		wantStart := fixedNow.Add(-30 * time.Minute).UnixNano()
+		if q.Get("start") != strconv.FormatInt(wantStart, 10) {
+			t.Errorf("start = %s", q.Get("start"))
+		}
+		if r.Header.Get("X-Scope-OrgID") != "tenant-a" {
+			t.Errorf("X-Scope-OrgID = %q", r.Header.Get("X-Scope-OrgID"))
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"api","pod":"a"},"values":[["1760608800000000000","first"],["1760608860000000000","` + longLine + `"]]},
+			{"stream":{"app":"api","pod":"b"},"values":[["1760608830000000000","second"]]}
+		]}}`))
+	}, func(c *LogConfig) { c.TenantID = "tenant-a" })
+
+	args := map[string]interface{}{"query": `{app="api"} |= "error"`, "start": "30m", "limit": float64(2)}
+	out, err := tool.LokiQuery(context.Background(), "test-incident", args)
+	if err != nil {
+		t.Fatalf("LokiQuery: %v", err)
+	}
+	result := decodeResult(t, out)
+	if result.Total != 3 || result.Returned != 2 || !result.Truncated {
+		t.Errorf("total=%d returned=%d truncated=%v", result.Total, result.Returned, result.Truncated)
+	}
+	if !strings.HasSuffix(result.Entries[0].Line, "...[truncated]") || result.Entries[0].Labels["pod"] != "a" {
+		t.Errorf("newest entry should be the long line from pod a, got %+v", result.Entries[0])
+	}
+	if result.Entries[1].Line != "second" {
+		t.Errorf("entries not sorted newest first: %+v", result.Entries)
+	}
+
+	if _, err := tool.LokiQuery(context.Background(), "test-incident", args); err != nil {
+		t.Fatal(err)
+	}
+	if counter.Load() != 1 {
+		t.Errorf("expected cached second call, got %d requests", counter.Load())
+	}
+}
+
+func TestLokiQuery_Errors(t *testing.T) {
+	tool, counter := newTestTool(t, BackendLoki, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	})
+
+	if _, err := tool.LokiQuery(context.Background(), "test-incident", map[string]interface{}{}); err == nil {
+		t.Error("expected error for missing query")
+	}
+	if _, err := tool.LokiQuery(context.Background(), "test-incident", map[string]interface{}{"query": "{a=\"b\"}", "start": "7d"}); err == nil {
+		t.Error("expected error for unparseable start")
+	}
+	if _, err := tool.LokiQuery(context.Background(), "test-incident", map[string]interface{}{"query": "{a=\"b\"}", "start": "25h"}); err == nil {
+		t.Error("expected error for range beyond the limit")
+	}
+	if counter.Load() != 0 {
+		t.Error("invalid arguments must not reach Loki")
+	}
+
+	_, err := tool.LokiQuery(context.Background(), "test-incident", map[string]interface{}{"query": "rate({a=\"b\"}[5m])"})
+	if err == nil || !strings.Contains(err.Error(), "not log lines") {
+		t.Errorf("expected metric-query error, got %v", err)
+	}
+
+	if _, err := tool.ElasticsearchSearch(context.Background(), "test-incident", map[string]interface{}{"index": "logs"}); err == nil || !strings.Contains(err.Error(), "backed by loki") {
+		t.Errorf("expected backend mismatch error, got %v", err)
+	}
+}
+
+func TestLokiLabels(t *testing.T) {
+	tool, _ := newTestTool(t, BackendLoki, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/label/app/values" || r.URL.Query().Get("query") != `{namespace="prod"}` {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.URL.Query())
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":["api","worker"]}`))
+	})
+
+	out, err := tool.LokiLabels(context.Background(), "test-incident", map[string]interface{}{"label_name": "app", "query": `{namespace="prod"}`})
+	if err != nil {
+		t.Fatalf("LokiLabels: %v", err)
+	}
+	if out != `["api","worker"]` {
+		t.Errorf("result = %s", out)
+	}
+}
+
+func TestElasticsearchSearch_BuildsBoundedQuery(t *testing.T) {
+	tool, _ := newTestTool(t, BackendElasticsearch, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/logs-*/_search" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "ApiKey secret-key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req["size"] != float64(5) {
+			t.Errorf("size = %v, want 5 (instance cap)", req["size"])
+		}
+		boolQuery := req["query"].(map[string]interface{})["bool"].(map[string]interface{})
+		must := boolQuery["must"].([]interface{})[0].(map[string]interface{})
+		if must["query_string"].(map[string]interface{})["query"] != "level:error" {
+			t.Errorf("must = %v", must)
+		}
+		rng := boolQuery["filter"].([]interface{})[0].(map[string]interface{})["range"].(map[string]interface{})["ts"].(map[string]interface{})
+		if rng["gte"] != "2026-10-16T11:00:00Z" || rng["lte"] != "2026-10-16T12:00:00Z" {
+			t.Errorf("range = %v", rng)
+		}
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":42},"hits":[
+			{"_index":"logs-2026.10.16","_id":"1","_source":{"ts":"2026-10-16T11:59:00Z","level":"error","msg":"boom"}}
+		]}}`))
+	}, func(c *LogConfig) {
+		c.AuthMethod = "api_key"
+		c.APIKey = "secret-key"
+		c.DefaultIndex = "logs-*"
+		c.TimeField = "ts"
+		c.MaxResults = 5
+	})
+
+	out, err := tool.ElasticsearchSearch(context.Background(), "test-incident", map[string]interface{}{"query": "level:error", "limit": float64(500)})
+	if err != nil {
+		t.Fatalf("ElasticsearchSearch: %v", err)
+	}
+	result := decodeResult(t, out)
+	if result.Total != 42 || result.Returned != 1 || !result.Truncated {
+		t.Errorf("total=%d returned=%d truncated=%v", result.Total, result.Returned, result.Truncated)
+	}
+	e := result.Entries[0]
+	if e.Timestamp != "2026-10-16T11:59:00Z" || e.Index != "logs-2026.10.16" || !strings.Contains(e.Line, `"msg":"boom"`) {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestEsQueryClause(t *testing.T) {
+	clause, err := esQueryClause(`{"query":{"term":{"level":"error"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := clause.(map[string]interface{})["term"]; !ok {
+		t.Errorf("expected wrapped query to be unwrapped, got %v", clause)
+	}
+	clause, _ = esQueryClause(nil)
+	if _, ok := clause.(map[string]interface{})["match_all"]; !ok {
+		t.Errorf("expected match_all for empty query, got %v", clause)
+	}
+	if _, err := esQueryClause(`{"broken"`); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+	if _, err := esQueryClause(42.0); err == nil {
+		t.Error("expected error for a non-string, non-object query")
+	}
+}
+
+func TestDoRequest_HTTPErrorIsTruncated(t *testing.T) {
+	tool, _ := newTestTool(t, BackendElasticsearch, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(strings.Repeat("e", MaxLineChars*2)))
+	}, func(c *LogConfig) { c.DefaultIndex = "logs" })
+
+	_, err := tool.ElasticsearchSearch(context.Background(), "test-incident", map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP error 400") {
+		t.Fatalf("expected HTTP error, got %v", err)
+	}
+	if len(err.Error()) > MaxLineChars+100 {
+		t.Errorf("error body should be truncated, got %d chars", len(err.Error()))
+	}
+}
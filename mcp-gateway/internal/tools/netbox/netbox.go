@@ -54,8 +54,8 @@ type NetBoxTool struct {
 func NewNetBoxTool(logger *log.Logger, limiter *ratelimit.Limiter) *NetBoxTool {
 	return &NetBoxTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("netbox_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("netbox_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
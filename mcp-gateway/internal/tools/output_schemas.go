@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+	"github.com/akmatori/mcp-gateway/internal/tools/ssh"
+	"github.com/akmatori/mcp-gateway/internal/tools/zabbix"
+)
+
+// Output schemas advertised in tools/list and enforced on every call. They
+// are derived from the result types so a field added to a struct shows up in
+// the contract without a second edit.
+var (
+	sshExecuteOutputSchema      = mcp.SchemaOf(ssh.ExecuteResult{})
+	sshConnectivityOutputSchema = mcp.SchemaOf(ssh.ConnectivityResult{})
+	sshReadFileOutputSchema     = mcp.SchemaOf(ssh.FileContentResult{})
+	sshWriteFileOutputSchema    = mcp.SchemaOf(ssh.FileWriteResult{})
+	sshListDirOutputSchema      = mcp.SchemaOf(ssh.DirListingResult{})
+
+	zabbixHostsOutputSchema      = zabbixListSchema[zabbix.Host]()
+	zabbixProblemsOutputSchema   = zabbixListSchema[zabbix.Problem]()
+	zabbixHistoryOutputSchema    = zabbixListSchema[zabbix.HistoryRecord]()
+	zabbixItemsOutputSchema      = zabbixListSchema[zabbix.Item]()
+	zabbixTriggersOutputSchema   = zabbixListSchema[zabbix.Trigger]()
+	zabbixItemsBatchOutputSchema = mcp.SchemaOf(zabbix.BatchResponse{})
+)
+
+// zabbixListSchema describes a Zabbix *.get result. The API returns a bare
+// array, which the gateway wraps as {"result": [...]} for structuredContent.
+func zabbixListSchema[T any]() *mcp.Schema {
+	return mcp.SchemaOf(struct {
+		Result []T `json:"result"`
+	}{})
+}
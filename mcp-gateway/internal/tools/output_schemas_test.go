@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+	"github.com/akmatori/mcp-gateway/internal/sandbox"
+	"github.com/akmatori/mcp-gateway/internal/tools/ssh"
+	"github.com/akmatori/mcp-gateway/internal/tools/zabbix"
+)
+
+func validateJSON(t *testing.T, schema *mcp.Schema, v interface{}) error {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded.(map[string]interface{}); !ok {
+		decoded = map[string]interface{}{"result": decoded}
+	}
+	return schema.Validate(decoded)
+}
+
+func TestOutputSchemas_SSHResultsConform(t *testing.T) {
+	exec := ssh.ExecuteResult{Results: []ssh.ServerResult{{Server: "web-1", Success: true, Stdout: "ok"}}}
+	exec.Summary.Total, exec.Summary.Succeeded = 1, 1
+	if err := validateJSON(t, sshExecuteOutputSchema, exec); err != nil {
+		t.Errorf("execute result: %v", err)
+	}
+	if err := validateJSON(t, sshExecuteOutputSchema, ssh.ExecuteResult{Results: []ssh.ServerResult{}, Error: "no keys"}); err != nil {
+		t.Errorf("execute error result: %v", err)
+	}
+	// A nil slice marshals as null, which breaks the contract; producers
+	// must always send an array.
+	if err := validateJSON(t, sshExecuteOutputSchema, ssh.ExecuteResult{}); err == nil {
+		t.Error("execute result with null results was accepted")
+	}
+
+	conn := ssh.ConnectivityResult{Results: []ssh.ConnectivityServerResult{{Server: "web-1", Reachable: true}}}
+	if err := validateJSON(t, sshConnectivityOutputSchema, conn); err != nil {
+		t.Errorf("connectivity result: %v", err)
+	}
+	listing := ssh.DirListingResult{Server: "web-1", Path: "/var/log", Entries: []ssh.DirEntry{{Name: "syslog", Type: "file"}}}
+	if err := validateJSON(t, sshListDirOutputSchema, listing); err != nil {
+		t.Errorf("dir listing: %v", err)
+	}
+}
+
+func TestOutputSchemas_ZabbixResultsConform(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema *mcp.Schema
+		raw    string
+	}{
+		{"hosts", zabbixHostsOutputSchema, `[{"hostid":"10084","host":"db-01","name":"db-01","status":"0","available":"1","inventory":{}}]`},
+		{"problems", zabbixProblemsOutputSchema, `[{"eventid":"1","name":"CPU high","severity":"4","hosts":[{"hostid":"10084","host":"db-01"}],"tags":[{"tag":"service","value":"db"}]}]`},
+		{"history", zabbixHistoryOutputSchema, `[{"itemid":"23","clock":"1700000000","value":"0.42","ns":"0"}]`},
+		{"items", zabbixItemsOutputSchema, `[]`},
+		{"triggers", zabbixTriggersOutputSchema, `[{"triggerid":"7","description":"Disk full","priority":"5","hosts":[]}]`},
+		{"items batch", zabbixItemsBatchOutputSchema, `{"results":[{"pattern":"cpu","items":[],"count":0}],"total_items":0,"total_unique":0,"pattern_count":1}`},
+	}
+	for _, tc := range cases {
+		if err := validateJSON(t, tc.schema, json.RawMessage(tc.raw)); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+
+	// Zabbix encodes numbers as strings; a numeric severity means the
+	// upstream shape changed.
+	if err := validateJSON(t, zabbixProblemsOutputSchema, json.RawMessage(`[{"severity":4}]`)); err == nil {
+		t.Error("numeric severity was accepted")
+	}
+	var batch zabbix.BatchResponse
+	if err := validateJSON(t, zabbixItemsBatchOutputSchema, batch); err == nil {
+		t.Error("batch response with null results was accepted")
+	}
+}
+
+func TestOutputSchemas_AttachedToSSHAndZabbixTools(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", logger)
+	registry := NewRegistry(server, logger)
+	registry.registerSSHTools()
+	registry.registerZabbixTools()
+	defer registry.zabbixTool.Stop()
+
+	for name, tool := range server.Tools() {
+		if !strings.HasPrefix(name, "ssh.") && !strings.HasPrefix(name, "zabbix.") {
+			continue
+		}
+		// api_request proxies an arbitrary Zabbix method, so its shape is open.
+		if name == "zabbix.api_request" {
+			continue
+		}
+		if tool.OutputSchema == nil {
+			t.Errorf("%s has no output schema", name)
+		}
+	}
+}
+
+func TestOutputSchemas_SandboxFixturesConform(t *testing.T) {
+	fixtures, err := sandbox.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := map[string]*mcp.Schema{
+		"ssh.execute_command":   sshExecuteOutputSchema,
+		"ssh.test_connectivity": sshConnectivityOutputSchema,
+		"zabbix.get_hosts":      zabbixHostsOutputSchema,
+		"zabbix.get_problems":   zabbixProblemsOutputSchema,
+		"zabbix.get_history":    zabbixHistoryOutputSchema,
+		"zabbix.get_items":      zabbixItemsOutputSchema,
+	}
+	for tool, schema := range schemas {
+		for i, r := range fixtures.Responses(tool) {
+			if r.Error != "" {
+				continue
+			}
+			if err := validateJSON(t, schema, r.Result); err != nil {
+				t.Errorf("%s response %d: %v", tool, i, err)
+			}
+		}
+	}
+}
@@ -36,6 +36,7 @@ const (
 type PagerDutyConfig struct {
 	URL       string // Default: https://api.pagerduty.com
 	APIToken  string // PagerDuty REST API token (v2)
+	FromEmail string // Default requester for write operations (From header)
 	VerifySSL bool
 	Timeout   int
 	UseProxy  bool
@@ -54,8 +55,8 @@ type PagerDutyTool struct {
 func NewPagerDutyTool(logger *log.Logger, limiter *ratelimit.Limiter) *PagerDutyTool {
 	return &PagerDutyTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("pagerduty_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("pagerduty_response").Shared(cache.JSON[[]byte]()),
 		rateLimiter:   limiter,
 	}
 }
@@ -166,6 +167,10 @@ func (t *PagerDutyTool) getConfig(ctx context.Context, incidentID string, logica
 		config.APIToken = token
 	}
 
+	if from, ok := settings["pagerduty_from_email"].(string); ok {
+		config.FromEmail = strings.TrimSpace(from)
+	}
+
 	if verify, ok := settings["pagerduty_verify_ssl"].(bool); ok {
 		config.VerifySSL = verify
 	}
@@ -555,11 +560,6 @@ func (t *PagerDutyTool) updateIncidentStatus(ctx context.Context, incidentID str
 		return "", fmt.Errorf("incident_id is required%s", validation.SuggestParam("incident_id", args))
 	}
 
-	requesterEmail, ok := args["requester_email"].(string)
-	if !ok || requesterEmail == "" {
-		return "", fmt.Errorf("requester_email is required%s", validation.SuggestParam("requester_email", args))
-	}
-
 	reqBody := map[string]interface{}{
 		"incident": map[string]interface{}{
 			"type":   "incident_reference",
@@ -576,6 +576,10 @@ func (t *PagerDutyTool) updateIncidentStatus(ctx context.Context, incidentID str
 	if err != nil {
 		return "", err
 	}
+	requesterEmail, err := resolveRequesterEmail(args, config)
+	if err != nil {
+		return "", err
+	}
 
 	path := fmt.Sprintf("/incidents/%s", url.PathEscape(pdIncidentID))
 
@@ -590,6 +594,19 @@ func (t *PagerDutyTool) updateIncidentStatus(ctx context.Context, incidentID str
 	return string(respBody), nil
 }
 
+// resolveRequesterEmail returns the requester_email argument, falling back to
+// the instance's pagerduty_from_email. PagerDuty rejects writes without a
+// From header naming a valid user.
+func resolveRequesterEmail(args map[string]interface{}, config *PagerDutyConfig) (string, error) {
+	if email, ok := args["requester_email"].(string); ok && email != "" {
+		return email, nil
+	}
+	if config.FromEmail != "" {
+		return config.FromEmail, nil
+	}
+	return "", fmt.Errorf("requester_email is required (or set pagerduty_from_email on the tool instance)%s", validation.SuggestParam("requester_email", args))
+}
+
 // AcknowledgeIncident acknowledges a PagerDuty incident (write operation, NOT cached)
 func (t *PagerDutyTool) AcknowledgeIncident(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
 	return t.updateIncidentStatus(ctx, incidentID, args, "acknowledged")
@@ -609,11 +626,6 @@ func (t *PagerDutyTool) ReassignIncident(ctx context.Context, incidentID string,
 		return "", fmt.Errorf("incident_id is required%s", validation.SuggestParam("incident_id", args))
 	}
 
-	requesterEmail, ok := args["requester_email"].(string)
-	if !ok || requesterEmail == "" {
-		return "", fmt.Errorf("requester_email is required%s", validation.SuggestParam("requester_email", args))
-	}
-
 	assigneeIDs, ok := args["assignee_ids"].(string)
 	if !ok || assigneeIDs == "" {
 		return "", fmt.Errorf("assignee_ids is required (comma-separated user IDs)%s", validation.SuggestParam("assignee_ids", args))
@@ -665,6 +677,10 @@ func (t *PagerDutyTool) ReassignIncident(ctx context.Context, incidentID string,
 	if err != nil {
 		return "", err
 	}
+	requesterEmail, err := resolveRequesterEmail(args, config)
+	if err != nil {
+		return "", err
+	}
 
 	path := fmt.Sprintf("/incidents/%s", url.PathEscape(pdIncidentID))
 
@@ -687,11 +703,6 @@ func (t *PagerDutyTool) AddIncidentNote(ctx context.Context, incidentID string,
 		return "", fmt.Errorf("incident_id is required%s", validation.SuggestParam("incident_id", args))
 	}
 
-	requesterEmail, ok := args["requester_email"].(string)
-	if !ok || requesterEmail == "" {
-		return "", fmt.Errorf("requester_email is required%s", validation.SuggestParam("requester_email", args))
-	}
-
 	content, ok := args["content"].(string)
 	if !ok || content == "" {
 		return "", fmt.Errorf("content is required%s", validation.SuggestParam("content", args))
@@ -712,6 +723,10 @@ func (t *PagerDutyTool) AddIncidentNote(ctx context.Context, incidentID string,
 	if err != nil {
 		return "", err
 	}
+	requesterEmail, err := resolveRequesterEmail(args, config)
+	if err != nil {
+		return "", err
+	}
 
 	path := fmt.Sprintf("/incidents/%s/notes", url.PathEscape(pdIncidentID))
 
@@ -1058,6 +1058,35 @@ func TestAcknowledgeIncident_MissingIncidentID(t *testing.T) {
 	}
 }
 
+func TestAcknowledgeIncident_DefaultsToConfiguredFromEmail(t *testing.T) {
+	var receivedFrom string
+	tool, _ := newTestToolWithHeaders(t, func(w http.ResponseWriter, r *http.Request) {
+		receivedFrom = r.Header.Get("From")
+		fmt.Fprint(w, `{"incident":{"id":"P123","status":"acknowledged"}}`)
+	})
+	cached, _ := tool.configCache.Get(configCacheKey("test-incident"))
+	cached.(*PagerDutyConfig).FromEmail = "akmatori@example.com"
+
+	if _, err := tool.AcknowledgeIncident(context.Background(), "test-incident", map[string]interface{}{
+		"incident_id": "P123",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedFrom != "akmatori@example.com" {
+		t.Errorf("expected From header from config, got %q", receivedFrom)
+	}
+
+	if _, err := tool.AcknowledgeIncident(context.Background(), "test-incident", map[string]interface{}{
+		"incident_id":     "P123",
+		"requester_email": "oncall@example.com",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedFrom != "oncall@example.com" {
+		t.Errorf("explicit requester_email should win, got %q", receivedFrom)
+	}
+}
+
 func TestAcknowledgeIncident_MissingEmail(t *testing.T) {
 	tool, _ := newTestToolWithHeaders(t, func(w http.ResponseWriter, r *http.Request) {
 		t.Error("should not reach server")
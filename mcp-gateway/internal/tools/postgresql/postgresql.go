@@ -93,8 +93,8 @@ type PostgreSQLTool struct {
 func NewPostgreSQLTool(logger *log.Logger, limiter *ratelimit.Limiter) *PostgreSQLTool {
 	t := &PostgreSQLTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("postgresql_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("postgresql_response").Shared(cache.JSON[string]()),
 		rateLimiter:   limiter,
 	}
 	t.execQuery = t.executeReadOnly
@@ -437,6 +437,24 @@ func (t *PostgreSQLTool) connect(ctx context.Context, config *PGConfig) (*pgx.Co
 	return conn, nil
 }
 
+// ConnectAdmin opens a read-write connection from a tool instance's
+// settings. The ephemeral credential provider uses it to create and drop
+// roles; tool queries always go through the read-only connect path.
+func ConnectAdmin(ctx context.Context, settings map[string]interface{}) (*pgx.Conn, error) {
+	config := parseSettings(settings)
+	connConfig, err := buildConnConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	delete(connConfig.RuntimeParams, "default_transaction_read_only")
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL at %s:%d/%s: %w", config.Host, config.Port, config.Database, err)
+	}
+	return conn, nil
+}
+
 // executeReadOnly runs a query inside a read-only transaction with rate limiting.
 // Returns rows as []map[string]interface{} with column names as keys.
 func (t *PostgreSQLTool) executeReadOnly(ctx context.Context, config *PGConfig, query string, args ...interface{}) ([]map[string]interface{}, error) {
@@ -0,0 +1,505 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/cache"
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/ratelimit"
+	"github.com/akmatori/mcp-gateway/internal/validation"
+)
+
+// Cache TTL constants
+const (
+	ConfigCacheTTL   = 5 * time.Minute  // Credentials cache TTL
+	ResponseCacheTTL = 30 * time.Second // Default API response cache TTL
+	CacheCleanupTick = time.Minute      // Background cleanup interval
+	QueryTTL         = 15 * time.Second // Instant query cache TTL
+	QueryRangeTTL    = 30 * time.Second // Range query cache TTL
+	LabelValuesTTL   = 60 * time.Second // Label values cache TTL
+	SeriesTTL        = 30 * time.Second // Series cache TTL
+)
+
+// PromConfig holds Prometheus connection configuration
+type PromConfig struct {
+	URL         string
+	AuthMethod  string // "none", "bearer_token", "basic_auth"
+	BearerToken string
+	Username    string
+	Password    string
+	VerifySSL   bool
+	Timeout     int
+	UseProxy    bool
+	ProxyURL    string
+	// TLS is built from prom_ca_cert / prom_client_cert / prom_client_key;
+	// nil when none are set and SSL verification is on.
+	TLS *tls.Config
+}
+
+// PrometheusTool handles Prometheus HTTP API operations. Any server that
+// implements the Prometheus query API (Thanos, Mimir, VictoriaMetrics, ...)
+// works as well.
+type PrometheusTool struct {
+	logger        *log.Logger
+	configCache   *cache.Cache // Cache for credentials (5 min TTL)
+	responseCache *cache.Cache // Cache for API responses (15-60 sec TTL)
+	rateLimiter   *ratelimit.Limiter
+}
+
+// NewPrometheusTool creates a new Prometheus tool with optional rate limiter
+func NewPrometheusTool(logger *log.Logger, limiter *ratelimit.Limiter) *PrometheusTool {
+	return &PrometheusTool{
+		logger:        logger,
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("prometheus_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("prometheus_response").Shared(cache.JSON[json.RawMessage]()),
+		rateLimiter:   limiter,
+	}
+}
+
+// Stop cleans up cache resources
+func (t *PrometheusTool) Stop() {
+	if t.configCache != nil {
+		t.configCache.Stop()
+	}
+	if t.responseCache != nil {
+		t.responseCache.Stop()
+	}
+}
+
+// apiResponse is the standard Prometheus API response envelope
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ErrorType string          `json:"errorType,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Warnings  []string        `json:"warnings,omitempty"`
+}
+
+// configCacheKey returns the cache key for config/credentials
+func configCacheKey(incidentID string) string {
+	return fmt.Sprintf("creds:%s:prometheus", incidentID)
+}
+
+// responseCacheKey returns the cache key for API responses
+func responseCacheKey(path string, params interface{}) string {
+	paramsJSON, _ := json.Marshal(params)
+	hash := sha256.Sum256(paramsJSON)
+	return fmt.Sprintf("%s:%s", path, hex.EncodeToString(hash[:8]))
+}
+
+// extractLogicalName extracts the optional logical_name from tool arguments.
+func extractLogicalName(args map[string]interface{}) string {
+	if v, ok := args["logical_name"].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// clampTimeout ensures timeout is within a safe range (1-300 seconds), defaulting to 30.
+func clampTimeout(timeout int) int {
+	if timeout <= 0 {
+		return 30
+	}
+	if timeout > 300 {
+		return 300
+	}
+	return timeout
+}
+
+// buildTLSConfig returns the TLS settings for an instance: an optional
+// custom CA bundle and an optional client certificate for mTLS.
+func buildTLSConfig(verifySSL bool, caCert, clientCert, clientKey string) (*tls.Config, error) {
+	if verifySSL && caCert == "" && clientCert == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		InsecureSkipVerify: !verifySSL, //nolint:gosec // User-opt-in via prom_verify_ssl setting
+		MinVersion:         tls.VersionTLS12,
+	}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("prom_ca_cert does not contain a valid PEM certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid prom_client_cert/prom_client_key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// getConfig fetches Prometheus configuration from database with caching.
+func (t *PrometheusTool) getConfig(ctx context.Context, incidentID, logicalName string) (*PromConfig, error) {
+	cacheKey := configCacheKey(incidentID)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("creds:logical:%s:%s", "prometheus", logicalName)
+	}
+
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if config, ok := cached.(*PromConfig); ok {
+			t.logger.Printf("Config cache hit for key %s", cacheKey)
+			return config, nil
+		}
+	}
+
+	creds, err := database.ResolveToolCredentials(ctx, incidentID, "prometheus", nil, logicalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Prometheus credentials: %w", err)
+	}
+
+	config := &PromConfig{
+		AuthMethod: "none",
+		VerifySSL:  true,
+		Timeout:    30,
+	}
+
+	settings := creds.Settings
+
+	if u, ok := settings["prom_url"].(string); ok {
+		config.URL = strings.TrimSuffix(u, "/")
+	}
+	if method, ok := settings["prom_auth_method"].(string); ok && method != "" {
+		config.AuthMethod = method
+	}
+	if token, ok := settings["prom_bearer_token"].(string); ok {
+		config.BearerToken = token
+	}
+	if user, ok := settings["prom_username"].(string); ok {
+		config.Username = user
+	}
+	if pass, ok := settings["prom_password"].(string); ok {
+		config.Password = pass
+	}
+	if verify, ok := settings["prom_verify_ssl"].(bool); ok {
+		config.VerifySSL = verify
+	}
+	if timeout, ok := settings["prom_timeout"].(float64); ok {
+		config.Timeout = int(timeout)
+	}
+	config.Timeout = clampTimeout(config.Timeout)
+
+	caCert, _ := settings["prom_ca_cert"].(string)
+	clientCert, _ := settings["prom_client_cert"].(string)
+	clientKey, _ := settings["prom_client_key"].(string)
+	config.TLS, err = buildTLSConfig(config.VerifySSL, caCert, clientCert, clientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	proxySettings := t.getCachedProxySettings(ctx)
+	if proxySettings != nil && proxySettings.ProxyURL != "" && proxySettings.PrometheusEnabled {
+		config.UseProxy = true
+		config.ProxyURL = proxySettings.ProxyURL
+	}
+
+	t.configCache.Set(cacheKey, config)
+	t.logger.Printf("Config cached for key %s", cacheKey)
+
+	return config, nil
+}
+
+// getCachedProxySettings fetches proxy settings with caching
+func (t *PrometheusTool) getCachedProxySettings(ctx context.Context) *database.ProxySettings {
+	cacheKey := "proxy:settings"
+	if cached, ok := t.configCache.Get(cacheKey); ok {
+		if settings, ok := cached.(*database.ProxySettings); ok {
+			return settings
+		}
+	}
+
+	proxySettings, err := database.GetProxySettings(ctx)
+	if err != nil || proxySettings == nil {
+		return nil
+	}
+
+	t.configCache.Set(cacheKey, proxySettings)
+
+	return proxySettings
+}
+
+// doRequest performs an HTTP request to Prometheus with rate limiting
+func (t *PrometheusTool) doRequest(ctx context.Context, config *PromConfig, method, path string, params url.Values) ([]byte, error) {
+	if t.rateLimiter != nil {
+		if err := t.rateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
+		}
+	}
+
+	fullURL := config.URL + path
+	if method == http.MethodGet && len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	t.logger.Printf("Prometheus API call: %s %s", method, path)
+
+	// DisableKeepAlives prevents connection pool leakage since we create a new transport per request
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   config.TLS,
+	}
+
+	// Handle proxy settings - MUST explicitly set Proxy to prevent env var usage
+	if config.UseProxy && config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			t.logger.Printf("Invalid proxy URL: %v, proceeding without proxy", err)
+			transport.Proxy = nil
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+			t.logger.Printf("Prometheus using proxy: %s", proxyURL.Host)
+		}
+	} else {
+		transport.Proxy = nil
+	}
+
+	client := &http.Client{
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		Transport: transport,
+	}
+
+	var body io.Reader
+	if method == http.MethodPost && len(params) > 0 {
+		body = strings.NewReader(params.Encode())
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	switch config.AuthMethod {
+	case "bearer_token":
+		if config.BearerToken == "" {
+			return nil, fmt.Errorf("auth_method is 'bearer_token' but no token configured")
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+config.BearerToken)
+	case "basic_auth":
+		if config.Username == "" {
+			return nil, fmt.Errorf("auth_method is 'basic_auth' but no username configured")
+		}
+		httpReq.SetBasicAuth(config.Username, config.Password)
+	case "none":
+		// No auth
+	default:
+		return nil, fmt.Errorf("unknown auth_method '%s'", config.AuthMethod)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	const maxResponseBytes = 5 * 1024 * 1024 // 5 MB
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(respBody) > maxResponseBytes {
+		return nil, fmt.Errorf("response exceeds %d MB limit", maxResponseBytes/(1024*1024))
+	}
+
+	// Prometheus reports query errors as 400/422/503 with a JSON error body,
+	// so let parseResponse surface those before falling back to the status.
+	if resp.StatusCode != http.StatusOK {
+		var apiResp apiResponse
+		if json.Unmarshal(respBody, &apiResp) == nil && apiResp.Status == "error" {
+			return respBody, nil
+		}
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// parseResponse checks the Prometheus response status and extracts data.
+// Warnings are returned alongside the data so the agent sees partial results.
+func parseResponse(body []byte) (json.RawMessage, error) {
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if apiResp.Status == "" {
+		return nil, fmt.Errorf("not a Prometheus response: missing status field")
+	}
+	if apiResp.Status != "success" {
+		return nil, fmt.Errorf("Prometheus API error (%s): %s", apiResp.ErrorType, apiResp.Error)
+	}
+	if len(apiResp.Warnings) == 0 {
+		return apiResp.Data, nil
+	}
+	wrapped, err := json.Marshal(map[string]interface{}{
+		"data":     apiResp.Data,
+		"warnings": apiResp.Warnings,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wrapped, nil
+}
+
+// cachedRequest performs a cached HTTP request to Prometheus
+func (t *PrometheusTool) cachedRequest(ctx context.Context, incidentID, logicalName, method, path string, params url.Values, ttl time.Duration) (json.RawMessage, error) {
+	cacheKey := responseCacheKey(path, params)
+	if logicalName != "" {
+		cacheKey = fmt.Sprintf("logical:%s:%s", logicalName, cacheKey)
+	} else {
+		cacheKey = fmt.Sprintf("incident:%s:%s", incidentID, cacheKey)
+	}
+
+	if cached, ok := t.responseCache.Get(cacheKey); ok {
+		if result, ok := cached.(json.RawMessage); ok {
+			t.logger.Printf("Response cache hit for %s", path)
+			return result, nil
+		}
+	}
+
+	config, err := t.getConfig(ctx, incidentID, logicalName)
+	if err != nil {
+		return nil, err
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("Prometheus URL not configured")
+	}
+
+	body, err := t.doRequest(ctx, config, method, path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parseResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	t.responseCache.SetWithTTL(cacheKey, data, ttl)
+	t.logger.Printf("Response cached for %s (TTL: %v)", path, ttl)
+
+	return data, nil
+}
+
+// setOptional copies string arguments into params when present.
+func setOptional(params url.Values, args map[string]interface{}, keys ...string) {
+	for _, k := range keys {
+		if v, ok := args[k].(string); ok && v != "" {
+			params.Set(k, v)
+		}
+	}
+}
+
+// setLimit copies an optional numeric "limit" argument into params.
+func setLimit(params url.Values, args map[string]interface{}) {
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		params.Set("limit", fmt.Sprintf("%d", int(v)))
+	}
+}
+
+// addMatchers adds match[] selectors from a string or list argument.
+func addMatchers(params url.Values, v interface{}) {
+	switch m := v.(type) {
+	case string:
+		if m != "" {
+			params.Add("match[]", m)
+		}
+	case []interface{}:
+		for _, item := range m {
+			if s, ok := item.(string); ok && s != "" {
+				params.Add("match[]", s)
+			}
+		}
+	}
+}
+
+// Query executes a PromQL instant query
+func (t *PrometheusTool) Query(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return "", fmt.Errorf("query is required%s", validation.SuggestParam("query", args))
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	setOptional(params, args, "time", "timeout")
+
+	result, err := t.cachedRequest(ctx, incidentID, extractLogicalName(args), http.MethodPost, "/api/v1/query", params, QueryTTL)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// QueryRange executes a PromQL range query
+func (t *PrometheusTool) QueryRange(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	params := url.Values{}
+	for _, k := range []string{"query", "start", "end", "step"} {
+		v, ok := args[k].(string)
+		if !ok || v == "" {
+			return "", fmt.Errorf("%s is required%s", k, validation.SuggestParam(k, args))
+		}
+		params.Set(k, v)
+	}
+	setOptional(params, args, "timeout")
+
+	result, err := t.cachedRequest(ctx, incidentID, extractLogicalName(args), http.MethodPost, "/api/v1/query_range", params, QueryRangeTTL)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// Series finds series matching one or more selectors
+func (t *PrometheusTool) Series(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	params := url.Values{}
+	addMatchers(params, args["match"])
+	if len(params["match[]"]) == 0 {
+		return "", fmt.Errorf("match is required%s", validation.SuggestParam("match", args))
+	}
+	setOptional(params, args, "start", "end")
+	setLimit(params, args)
+
+	result, err := t.cachedRequest(ctx, incidentID, extractLogicalName(args), http.MethodPost, "/api/v1/series", params, SeriesTTL)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// LabelValues retrieves the values of a label, optionally filtered by selectors
+func (t *PrometheusTool) LabelValues(ctx context.Context, incidentID string, args map[string]interface{}) (string, error) {
+	labelName, ok := args["label_name"].(string)
+	if !ok || labelName == "" {
+		return "", fmt.Errorf("label_name is required%s", validation.SuggestParam("label_name", args))
+	}
+
+	params := url.Values{}
+	addMatchers(params, args["match"])
+	setOptional(params, args, "start", "end")
+	setLimit(params, args)
+
+	path := fmt.Sprintf("/api/v1/label/%s/values", url.PathEscape(labelName))
+	result, err := t.cachedRequest(ctx, incidentID, extractLogicalName(args), http.MethodGet, path, params, LabelValuesTTL)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
@@ -0,0 +1,231 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func testLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+// newTestTool creates a PrometheusTool whose config cache points at an
+// httptest server, so getConfig never touches the database.
+func newTestTool(t *testing.T, handler http.HandlerFunc, mutate ...func(*PromConfig)) (*PrometheusTool, *atomic.Int32) {
+	t.Helper()
+	counter := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.Add(1)
+		handler(w, r)
+	}))
+
+	tool := NewPrometheusTool(testLogger(), nil)
+	config := &PromConfig{URL: server.URL, AuthMethod: "none", VerifySSL: true, Timeout: 5}
+	for _, m := range mutate {
+		m(config)
+	}
+	tool.configCache.Set(configCacheKey("test-incident"), config)
+
+	t.Cleanup(func() {
+		tool.Stop()
+		server.Close()
+	})
+	return tool, counter
+}
+
+func TestQuery_PostsFormAndCaches(t *testing.T) {
+	tool, counter := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/query" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = r.ParseForm()
+		if r.PostForm.Get("query") != "up" || r.PostForm.Get("time") != "1700000000" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	})
+
+	args := map[string]interface{}{"query": "up", "time": "1700000000"}
+	for i := 0; i < 2; i++ {
+		got, err := tool.Query(context.Background(), "test-incident", args)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if got != `{"resultType":"vector","result":[]}` {
+			t.Errorf("result = %s", got)
+		}
+	}
+	if counter.Load() != 1 {
+		t.Errorf("expected second call to be served from cache, got %d requests", counter.Load())
+	}
+}
+
+func TestQuery_RequiresQuery(t *testing.T) {
+	tool, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {})
+	if _, err := tool.Query(context.Background(), "test-incident", map[string]interface{}{}); err == nil {
+		t.Error("expected error for missing query")
+	}
+}
+
+func TestQueryRange_RequiresAllParams(t *testing.T) {
+	tool, counter := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.URL.Path != "/api/v1/query_range" || r.PostForm.Get("step") != "1m" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	})
+
+	args := map[string]interface{}{"query": "up", "start": "1700000000", "end": "1700003600"}
+	if _, err := tool.QueryRange(context.Background(), "test-incident", args); err == nil || !strings.Contains(err.Error(), "step") {
+		t.Errorf("expected missing step error, got %v", err)
+	}
+	if counter.Load() != 0 {
+		t.Error("invalid arguments must not reach the server")
+	}
+
+	args["step"] = "1m"
+	if _, err := tool.QueryRange(context.Background(), "test-incident", args); err != nil {
+		t.Fatalf("QueryRange: %v", err)
+	}
+}
+
+func TestSeries_MultipleMatchersAndLimit(t *testing.T) {
+	tool, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if got := r.PostForm["match[]"]; len(got) != 2 || got[0] != `up{job="api"}` || got[1] != "node_load1" {
+			t.Errorf("match[] = %v", got)
+		}
+		if r.PostForm.Get("limit") != "50" {
+			t.Errorf("limit = %q", r.PostForm.Get("limit"))
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"api"}]}`))
+	})
+
+	args := map[string]interface{}{"match": []interface{}{`up{job="api"}`, "node_load1"}, "limit": float64(50)}
+	if _, err := tool.Series(context.Background(), "test-incident", args); err != nil {
+		t.Fatalf("Series: %v", err)
+	}
+	if _, err := tool.Series(context.Background(), "test-incident", map[string]interface{}{"match": []interface{}{}}); err == nil {
+		t.Error("expected error for empty match")
+	}
+}
+
+func TestLabelValues_GetWithEscapedName(t *testing.T) {
+	tool, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.EscapedPath() != "/api/v1/label/__name__/values" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("match[]") != "up" {
+			t.Errorf("match[] = %q", r.URL.Query().Get("match[]"))
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":["up","node_load1"]}`))
+	})
+
+	got, err := tool.LabelValues(context.Background(), "test-incident", map[string]interface{}{"label_name": "__name__", "match": "up"})
+	if err != nil {
+		t.Fatalf("LabelValues: %v", err)
+	}
+	if got != `["up","node_load1"]` {
+		t.Errorf("result = %s", got)
+	}
+}
+
+func TestQuery_APIErrorAndWarnings(t *testing.T) {
+	tool, _ := newTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("query") == "bad(" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"result":[]},"warnings":["partial response"]}`))
+	})
+
+	_, err := tool.Query(context.Background(), "test-incident", map[string]interface{}{"query": "bad("})
+	if err == nil || !strings.Contains(err.Error(), "bad_data") || !strings.Contains(err.Error(), "parse error") {
+		t.Errorf("expected Prometheus error to be surfaced, got %v", err)
+	}
+
+	got, err := tool.Query(context.Background(), "test-incident", map[string]interface{}{"query": "up"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !strings.Contains(got, `"warnings":["partial response"]`) {
+		t.Errorf("expected warnings in result, got %s", got)
+	}
+}
+
+func TestDoRequest_Auth(t *testing.T) {
+	var gotAuth string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"status":"success","data":[]}`))
+	}
+
+	tool, _ := newTestTool(t, handler, func(c *PromConfig) {
+		c.AuthMethod = "basic_auth"
+		c.Username = "prom"
+		c.Password = "secret"
+	})
+	if _, err := tool.LabelValues(context.Background(), "test-incident", map[string]interface{}{"label_name": "job"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Basic cHJvbTpzZWNyZXQ=" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+
+	tool, _ = newTestTool(t, handler, func(c *PromConfig) { c.AuthMethod = "bearer_token" })
+	if _, err := tool.LabelValues(context.Background(), "test-incident", map[string]interface{}{"label_name": "job"}); err == nil {
+		t.Error("expected error for bearer_token without a token")
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	if cfg, err := buildTLSConfig(true, "", "", ""); cfg != nil || err != nil {
+		t.Errorf("default TLS should be nil, got %v, %v", cfg, err)
+	}
+	if cfg, err := buildTLSConfig(false, "", "", ""); err != nil || cfg == nil || !cfg.InsecureSkipVerify {
+		t.Errorf("verify_ssl=false should skip verification, got %v, %v", cfg, err)
+	}
+	if _, err := buildTLSConfig(true, "not a pem", "", ""); err == nil {
+		t.Error("expected error for invalid CA PEM")
+	}
+	if _, err := buildTLSConfig(true, "", "cert without key", ""); err == nil {
+		t.Error("expected error for invalid client certificate")
+	}
+}
+
+func TestQuery_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"result":[]}}`))
+	}))
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	tlsConfig, err := buildTLSConfig(true, caPEM, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewPrometheusTool(testLogger(), nil)
+	defer tool.Stop()
+	tool.configCache.Set(configCacheKey("test-incident"), &PromConfig{URL: server.URL, AuthMethod: "none", VerifySSL: true, Timeout: 5, TLS: tlsConfig})
+
+	if _, err := tool.Query(context.Background(), "test-incident", map[string]interface{}{"query": "up"}); err != nil {
+		t.Errorf("Query over TLS with custom CA: %v", err)
+	}
+
+	// Without the CA the self-signed certificate is rejected.
+	tool.configCache.Set(configCacheKey("other-incident"), &PromConfig{URL: server.URL, AuthMethod: "none", VerifySSL: true, Timeout: 5})
+	if _, err := tool.Query(context.Background(), "other-incident", map[string]interface{}{"query": "up"}); err == nil {
+		t.Error("expected certificate verification failure without the CA")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"os"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,9 +23,12 @@ import (
 	"github.com/akmatori/mcp-gateway/internal/tools/incidents"
 	"github.com/akmatori/mcp-gateway/internal/tools/jira"
 	"github.com/akmatori/mcp-gateway/internal/tools/k8s"
+	"github.com/akmatori/mcp-gateway/internal/tools/httpcheck"
+	"github.com/akmatori/mcp-gateway/internal/tools/logsearch"
 	"github.com/akmatori/mcp-gateway/internal/tools/netbox"
 	"github.com/akmatori/mcp-gateway/internal/tools/pagerduty"
 	"github.com/akmatori/mcp-gateway/internal/tools/postgresql"
+	"github.com/akmatori/mcp-gateway/internal/tools/prometheus"
 	"github.com/akmatori/mcp-gateway/internal/tools/proposals"
 	"github.com/akmatori/mcp-gateway/internal/tools/ssh"
 	"github.com/akmatori/mcp-gateway/internal/tools/victoriametrics"
@@ -52,6 +57,12 @@ const (
 	K8sBurstCapacity         = 20 // burst capacity
 	JiraRatePerSecond        = 10 // requests per second
 	JiraBurstCapacity        = 20 // burst capacity
+	PrometheusRatePerSecond  = 10 // requests per second
+	PrometheusBurstCapacity  = 20 // burst capacity
+	LogSearchRatePerSecond   = 10 // requests per second
+	LogSearchBurstCapacity   = 20 // burst capacity
+	HTTPCheckRatePerSecond   = 5  // requests per second
+	HTTPCheckBurstCapacity   = 10 // burst capacity
 )
 
 // Registry manages tool registration
@@ -78,6 +89,12 @@ type Registry struct {
 	k8sLimit         *ratelimit.Limiter
 	jiraTool         *jira.JiraTool
 	jiraLimit        *ratelimit.Limiter
+	prometheusTool   *prometheus.PrometheusTool
+	prometheusLimit  *ratelimit.Limiter
+	logSearchTool    *logsearch.LogSearchTool
+	logSearchLimit   *ratelimit.Limiter
+	httpCheckTool    *httpcheck.HTTPCheckTool
+	httpCheckLimit   *ratelimit.Limiter
 	incidentsTool    *incidents.IncidentsTool
 	proposalsTool    *proposals.ProposalsTool
 
@@ -105,7 +122,7 @@ func (r *Registry) RegisterAllTools() {
 	r.logger.Println("Registering tools...")
 
 	// Create rate limiter for Zabbix: 10 req/sec, burst 20
-	r.zabbixLimit = ratelimit.New(ZabbixRatePerSecond, ZabbixBurstCapacity)
+	r.zabbixLimit = ratelimit.New(ZabbixRatePerSecond, ZabbixBurstCapacity).Named("zabbix")
 	r.logger.Printf("Zabbix rate limiter created: %d req/sec, burst %d", ZabbixRatePerSecond, ZabbixBurstCapacity)
 
 	// Register SSH tools
@@ -115,68 +132,89 @@ func (r *Registry) RegisterAllTools() {
 	r.registerZabbixTools()
 
 	// Create rate limiter for VictoriaMetrics: 10 req/sec, burst 20
-	r.vmLimit = ratelimit.New(VMRatePerSecond, VMBurstCapacity)
+	r.vmLimit = ratelimit.New(VMRatePerSecond, VMBurstCapacity).Named("victoriametrics")
 	r.logger.Printf("VictoriaMetrics rate limiter created: %d req/sec, burst %d", VMRatePerSecond, VMBurstCapacity)
 
 	// Register VictoriaMetrics tools with rate limiter
 	r.registerVictoriaMetricsTools()
 
 	// Create rate limiter for Catchpoint: 10 req/sec, burst 20
-	r.catchpointLimit = ratelimit.New(CatchpointRatePerSecond, CatchpointBurstCapacity)
+	r.catchpointLimit = ratelimit.New(CatchpointRatePerSecond, CatchpointBurstCapacity).Named("catchpoint")
 	r.logger.Printf("Catchpoint rate limiter created: %d req/sec, burst %d", CatchpointRatePerSecond, CatchpointBurstCapacity)
 
 	// Register Catchpoint tools with rate limiter
 	r.registerCatchpointTools()
 
 	// Create rate limiter for PostgreSQL: 10 req/sec, burst 20
-	r.postgresqlLimit = ratelimit.New(PostgreSQLRatePerSecond, PostgreSQLBurstCapacity)
+	r.postgresqlLimit = ratelimit.New(PostgreSQLRatePerSecond, PostgreSQLBurstCapacity).Named("postgresql")
 	r.logger.Printf("PostgreSQL rate limiter created: %d req/sec, burst %d", PostgreSQLRatePerSecond, PostgreSQLBurstCapacity)
 
 	// Register PostgreSQL tools with rate limiter
 	r.registerPostgreSQLTools()
 
 	// Create rate limiter for Grafana: 10 req/sec, burst 20
-	r.grafanaLimit = ratelimit.New(GrafanaRatePerSecond, GrafanaBurstCapacity)
+	r.grafanaLimit = ratelimit.New(GrafanaRatePerSecond, GrafanaBurstCapacity).Named("grafana")
 	r.logger.Printf("Grafana rate limiter created: %d req/sec, burst %d", GrafanaRatePerSecond, GrafanaBurstCapacity)
 
 	// Register Grafana tools with rate limiter
 	r.registerGrafanaTools()
 
 	// Create rate limiter for ClickHouse: 10 req/sec, burst 20
-	r.clickhouseLimit = ratelimit.New(ClickHouseRatePerSecond, ClickHouseBurstCapacity)
+	r.clickhouseLimit = ratelimit.New(ClickHouseRatePerSecond, ClickHouseBurstCapacity).Named("clickhouse")
 	r.logger.Printf("ClickHouse rate limiter created: %d req/sec, burst %d", ClickHouseRatePerSecond, ClickHouseBurstCapacity)
 
 	// Register ClickHouse tools with rate limiter
 	r.registerClickHouseTools()
 
 	// Create rate limiter for PagerDuty: 10 req/sec, burst 20
-	r.pagerdutyLimit = ratelimit.New(PagerDutyRatePerSecond, PagerDutyBurstCapacity)
+	r.pagerdutyLimit = ratelimit.New(PagerDutyRatePerSecond, PagerDutyBurstCapacity).Named("pagerduty")
 	r.logger.Printf("PagerDuty rate limiter created: %d req/sec, burst %d", PagerDutyRatePerSecond, PagerDutyBurstCapacity)
 
 	// Register PagerDuty tools with rate limiter
 	r.registerPagerDutyTools()
 
 	// Create rate limiter for NetBox: 10 req/sec, burst 20
-	r.netboxLimit = ratelimit.New(NetBoxRatePerSecond, NetBoxBurstCapacity)
+	r.netboxLimit = ratelimit.New(NetBoxRatePerSecond, NetBoxBurstCapacity).Named("netbox")
 	r.logger.Printf("NetBox rate limiter created: %d req/sec, burst %d", NetBoxRatePerSecond, NetBoxBurstCapacity)
 
 	// Register NetBox tools with rate limiter
 	r.registerNetBoxTools()
 
 	// Create rate limiter for Kubernetes: 10 req/sec, burst 20
-	r.k8sLimit = ratelimit.New(K8sRatePerSecond, K8sBurstCapacity)
+	r.k8sLimit = ratelimit.New(K8sRatePerSecond, K8sBurstCapacity).Named("k8s")
 	r.logger.Printf("Kubernetes rate limiter created: %d req/sec, burst %d", K8sRatePerSecond, K8sBurstCapacity)
 
 	// Register Kubernetes tools with rate limiter
 	r.registerK8sTools()
 
 	// Create rate limiter for Jira: 10 req/sec, burst 20
-	r.jiraLimit = ratelimit.New(JiraRatePerSecond, JiraBurstCapacity)
+	r.jiraLimit = ratelimit.New(JiraRatePerSecond, JiraBurstCapacity).Named("jira")
 	r.logger.Printf("Jira rate limiter created: %d req/sec, burst %d", JiraRatePerSecond, JiraBurstCapacity)
 
 	// Register Jira tools with rate limiter
 	r.registerJiraTools()
 
+	// Create rate limiter for Prometheus: 10 req/sec, burst 20
+	r.prometheusLimit = ratelimit.New(PrometheusRatePerSecond, PrometheusBurstCapacity).Named("prometheus")
+	r.logger.Printf("Prometheus rate limiter created: %d req/sec, burst %d", PrometheusRatePerSecond, PrometheusBurstCapacity)
+
+	// Register Prometheus tools with rate limiter
+	r.registerPrometheusTools()
+
+	// Create rate limiter for log search: 10 req/sec, burst 20
+	r.logSearchLimit = ratelimit.New(LogSearchRatePerSecond, LogSearchBurstCapacity).Named("logsearch")
+	r.logger.Printf("Log search rate limiter created: %d req/sec, burst %d", LogSearchRatePerSecond, LogSearchBurstCapacity)
+
+	// Register log search tools with rate limiter
+	r.registerLogSearchTools()
+
+	// Create rate limiter for HTTP checks: 5 req/sec, burst 10
+	r.httpCheckLimit = ratelimit.New(HTTPCheckRatePerSecond, HTTPCheckBurstCapacity).Named("httpcheck")
+	r.logger.Printf("HTTP check rate limiter created: %d req/sec, burst %d", HTTPCheckRatePerSecond, HTTPCheckBurstCapacity)
+
+	// Register HTTP check tools with rate limiter
+	r.registerHTTPCheckTools()
+
 	// Register Incidents tools (no rate limiter — local DB queries)
 	r.registerIncidentsTools()
 
@@ -218,6 +256,15 @@ func (r *Registry) Stop() {
 	if r.jiraTool != nil {
 		r.jiraTool.Stop()
 	}
+	if r.prometheusTool != nil {
+		r.prometheusTool.Stop()
+	}
+	if r.logSearchTool != nil {
+		r.logSearchTool.Stop()
+	}
+	if r.httpCheckTool != nil {
+		r.httpCheckTool.Stop()
+	}
 	if r.httpExecutor != nil {
 		r.httpExecutor.Stop()
 	}
@@ -241,6 +288,9 @@ var builtInToolNamespaces = map[string]bool{
 	"netbox":           true,
 	"kubernetes":       true,
 	"jira":             true,
+	"prometheus":       true,
+	"log_search":       true,
+	"http_check":       true,
 	"incidents":        true,
 	"proposals":        true,
 }
@@ -544,6 +594,7 @@ func (r *Registry) registerHTTPConnectorTools(conn database.HTTPConnector) int {
 				Name:        fullName,
 				Description: description,
 				InputSchema: inputSchema,
+				Destructive: !isReadOnly,
 			},
 			func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 				logicalName := extractLogicalName(args)
@@ -726,7 +777,7 @@ func (r *Registry) registerSSHTools() {
 	r.server.RegisterTool(
 		mcp.Tool{
 			Name:        "ssh.execute_command",
-			Description: "Execute a shell command on configured SSH servers in parallel",
+			Description: "Execute a shell command on configured SSH servers in parallel. Output is capped per stream (see stdout_truncated/stderr_truncated); a command that times out still returns the output it produced.",
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -739,15 +790,36 @@ func (r *Registry) registerSSHTools() {
 						Description: "Optional list of specific servers to target (defaults to all configured servers)",
 						Items:       &mcp.Items{Type: "string"},
 					},
+					"timeout_seconds": {
+						Type:        "integer",
+						Description: "Override the instance command timeout for a long-running command (max 600)",
+					},
+					"stream": {
+						Type:        "boolean",
+						Description: "Send output chunks as MCP progress notifications while the command runs (requires a progressToken)",
+					},
 				},
 				Required: []string{"command"},
 			},
+			OutputSchema: sshExecuteOutputSchema,
+			// Leave room for connection setup on top of the longest command.
+			Timeout:     (ssh.MaxCommandTimeout + 60) * time.Second,
+			Destructive: true,
+			WritesWhen: func(args map[string]interface{}) bool {
+				command, _ := args["command"].(string)
+				return ssh.IsWriteCommand(command)
+			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			logicalName := extractLogicalName(args)
 			command, _ := args["command"].(string)
 			servers := extractServers(args)
-			return sshTool.ExecuteCommand(ctx, incidentID, command, servers, nil, logicalName)
+			opts := ssh.ExecOptions{}
+			if v, ok := args["timeout_seconds"].(float64); ok {
+				opts.TimeoutSeconds = int(v)
+			}
+			opts.Stream, _ = args["stream"].(bool)
+			return sshTool.ExecuteCommand(ctx, incidentID, command, servers, opts, nil, logicalName)
 		},
 	)
 
@@ -766,6 +838,7 @@ func (r *Registry) registerSSHTools() {
 					},
 				},
 			},
+			OutputSchema: sshConnectivityOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			logicalName := extractLogicalName(args)
@@ -789,6 +862,7 @@ func (r *Registry) registerSSHTools() {
 					},
 				},
 			},
+			OutputSchema: sshExecuteOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			logicalName := extractLogicalName(args)
@@ -796,6 +870,136 @@ func (r *Registry) registerSSHTools() {
 			return sshTool.GetServerInfo(ctx, incidentID, servers, nil, logicalName)
 		},
 	)
+
+	// ssh.read_file
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "ssh.read_file",
+			Description: "Read a file from one server over SFTP. Returns up to 256KB by default; page through larger files with offset, or use tail for the end of a log. Binary content is returned base64-encoded.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"server": {
+						Type:        "string",
+						Description: "Hostname or address of the server to read from",
+					},
+					"path": {
+						Type:        "string",
+						Description: "Path of the file on the server (relative paths resolve from the login user's home directory)",
+					},
+					"offset": {
+						Type:        "integer",
+						Description: "Byte offset to start reading from (default 0)",
+					},
+					"max_bytes": {
+						Type:        "integer",
+						Description: "Maximum bytes to return (default 262144, max 4194304)",
+					},
+					"tail": {
+						Type:        "boolean",
+						Description: "Return the last max_bytes of the file instead of reading from offset",
+					},
+				},
+				Required: []string{"server", "path"},
+			},
+			OutputSchema: sshReadFileOutputSchema,
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			logicalName := extractLogicalName(args)
+			server, _ := args["server"].(string)
+			filePath, _ := args["path"].(string)
+			opts := ssh.ReadFileOptions{}
+			if v, ok := args["offset"].(float64); ok {
+				opts.Offset = int64(v)
+			}
+			if v, ok := args["max_bytes"].(float64); ok {
+				opts.MaxBytes = int64(v)
+			}
+			opts.Tail, _ = args["tail"].(bool)
+			return sshTool.ReadFile(ctx, incidentID, server, filePath, opts, nil, logicalName)
+		},
+	)
+
+	// ssh.write_file
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "ssh.write_file",
+			Description: "Write a file on one server over SFTP (up to 1MB). Only allowed on hosts with allow_write_commands enabled.",
+			Destructive: true,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"server": {
+						Type:        "string",
+						Description: "Hostname or address of the server to write to",
+					},
+					"path": {
+						Type:        "string",
+						Description: "Path of the file on the server",
+					},
+					"content": {
+						Type:        "string",
+						Description: "File content to write",
+					},
+					"mode": {
+						Type:        "string",
+						Description: "Octal permissions for the file, e.g. \"0640\" (default \"0644\")",
+					},
+					"append": {
+						Type:        "boolean",
+						Description: "Append to the file instead of replacing its content",
+					},
+				},
+				Required: []string{"server", "path", "content"},
+			},
+			OutputSchema: sshWriteFileOutputSchema,
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			logicalName := extractLogicalName(args)
+			server, _ := args["server"].(string)
+			filePath, _ := args["path"].(string)
+			content, _ := args["content"].(string)
+			opts := ssh.WriteFileOptions{}
+			if mode, ok := args["mode"].(string); ok && mode != "" {
+				parsed, err := strconv.ParseUint(mode, 8, 32)
+				if err != nil || parsed > 0o7777 {
+					return nil, fmt.Errorf("invalid mode %q: expected octal permissions such as 0644", mode)
+				}
+				opts.Mode = os.FileMode(parsed)
+			}
+			opts.Append, _ = args["append"].(bool)
+			return sshTool.WriteFile(ctx, incidentID, server, filePath, content, opts, nil, logicalName)
+		},
+	)
+
+	// ssh.list_dir
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "ssh.list_dir",
+			Description: "List a directory on one server over SFTP with entry type, size, permissions and modification time",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"server": {
+						Type:        "string",
+						Description: "Hostname or address of the server",
+					},
+					"path": {
+						Type:        "string",
+						Description: "Directory to list (default: the login user's home directory)",
+					},
+				},
+				Required: []string{"server"},
+			},
+			OutputSchema: sshListDirOutputSchema,
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			logicalName := extractLogicalName(args)
+			server, _ := args["server"].(string)
+			dirPath, _ := args["path"].(string)
+			return sshTool.ListDir(ctx, incidentID, server, dirPath, nil, logicalName)
+		},
+	)
 }
 
 // registerZabbixTools registers Zabbix-related tools
@@ -833,6 +1037,7 @@ func (r *Registry) registerZabbixTools() {
 					},
 				},
 			},
+			OutputSchema: zabbixHostsOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetHosts(ctx, incidentID, args)
@@ -868,6 +1073,7 @@ func (r *Registry) registerZabbixTools() {
 					},
 				},
 			},
+			OutputSchema: zabbixProblemsOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetProblems(ctx, incidentID, args)
@@ -917,6 +1123,7 @@ func (r *Registry) registerZabbixTools() {
 				},
 				Required: []string{"itemids"},
 			},
+			OutputSchema: zabbixHistoryOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetHistory(ctx, incidentID, args)
@@ -959,6 +1166,7 @@ func (r *Registry) registerZabbixTools() {
 					},
 				},
 			},
+			OutputSchema: zabbixItemsOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetItems(ctx, incidentID, args)
@@ -1000,6 +1208,7 @@ func (r *Registry) registerZabbixTools() {
 				},
 				Required: []string{"searches"},
 			},
+			OutputSchema: zabbixItemsBatchOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetItemsBatch(ctx, incidentID, args)
@@ -1035,6 +1244,7 @@ func (r *Registry) registerZabbixTools() {
 					},
 				},
 			},
+			OutputSchema: zabbixTriggersOutputSchema,
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			return r.zabbixTool.GetTriggers(ctx, incidentID, args)
@@ -1060,6 +1270,11 @@ func (r *Registry) registerZabbixTools() {
 				},
 				Required: []string{"method"},
 			},
+			Destructive: true,
+			WritesWhen: func(args map[string]interface{}) bool {
+				method, _ := args["method"].(string)
+				return !zabbix.IsReadMethod(method)
+			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
 			logicalName := extractLogicalName(args)
@@ -1236,6 +1451,313 @@ func (r *Registry) registerVictoriaMetricsTools() {
 	)
 }
 
+// registerPrometheusTools registers Prometheus query API tools
+func (r *Registry) registerPrometheusTools() {
+	r.prometheusTool = prometheus.NewPrometheusTool(r.logger, r.prometheusLimit)
+
+	// prometheus.query
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "prometheus.query",
+			Description: "Execute a PromQL instant query against Prometheus",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"query": {
+						Type:        "string",
+						Description: "PromQL query expression",
+					},
+					"time": {
+						Type:        "string",
+						Description: "Evaluation timestamp (RFC3339 or Unix timestamp). Defaults to current time.",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "Evaluation timeout (e.g., '30s')",
+					},
+				},
+				Required: []string{"query"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.prometheusTool.Query(ctx, incidentID, args)
+		},
+	)
+
+	// prometheus.query_range
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "prometheus.query_range",
+			Description: "Execute a PromQL range query against Prometheus",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"query": {
+						Type:        "string",
+						Description: "PromQL query expression",
+					},
+					"start": {
+						Type:        "string",
+						Description: "Start timestamp (RFC3339 or Unix timestamp)",
+					},
+					"end": {
+						Type:        "string",
+						Description: "End timestamp (RFC3339 or Unix timestamp)",
+					},
+					"step": {
+						Type:        "string",
+						Description: "Query resolution step width (e.g., '15s', '1m')",
+					},
+					"timeout": {
+						Type:        "string",
+						Description: "Evaluation timeout (e.g., '30s')",
+					},
+				},
+				Required: []string{"query", "start", "end", "step"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.prometheusTool.QueryRange(ctx, incidentID, args)
+		},
+	)
+
+	// prometheus.series
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "prometheus.series",
+			Description: "Find series matching one or more selectors in Prometheus",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"match": {
+						Type:        "array",
+						Description: "Series selectors (e.g., ['up{job=\"api\"}']); a single selector string is also accepted",
+						Items:       &mcp.Items{Type: "string"},
+					},
+					"start": {
+						Type:        "string",
+						Description: "Start timestamp for filtering",
+					},
+					"end": {
+						Type:        "string",
+						Description: "End timestamp for filtering",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of series to return",
+					},
+				},
+				Required: []string{"match"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.prometheusTool.Series(ctx, incidentID, args)
+		},
+	)
+
+	// prometheus.label_values
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "prometheus.label_values",
+			Description: "Get the values of a label from Prometheus",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"label_name": {
+						Type:        "string",
+						Description: "Label name to get values for (e.g., '__name__', 'job', 'instance')",
+					},
+					"match": {
+						Type:        "array",
+						Description: "Series selectors restricting which series the values come from",
+						Items:       &mcp.Items{Type: "string"},
+					},
+					"start": {
+						Type:        "string",
+						Description: "Start timestamp for filtering",
+					},
+					"end": {
+						Type:        "string",
+						Description: "End timestamp for filtering",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of values to return",
+					},
+				},
+				Required: []string{"label_name"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.prometheusTool.LabelValues(ctx, incidentID, args)
+		},
+	)
+}
+
+// registerLogSearchTools registers Loki and Elasticsearch log search tools
+func (r *Registry) registerLogSearchTools() {
+	r.logSearchTool = logsearch.NewLogSearchTool(r.logger, r.logSearchLimit)
+
+	timeProps := map[string]mcp.Property{
+		"start": {
+			Type:        "string",
+			Description: "Range start: RFC3339, Unix seconds, or a lookback duration like '30m'. Defaults to 1h before end.",
+		},
+		"end": {
+			Type:        "string",
+			Description: "Range end: RFC3339, Unix seconds, a lookback duration, or 'now' (default)",
+		},
+	}
+	withTime := func(props map[string]mcp.Property) map[string]mcp.Property {
+		for k, v := range timeProps {
+			props[k] = v
+		}
+		return props
+	}
+
+	// log_search.loki_query
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "log_search.loki_query",
+			Description: "Search log lines in Loki with a LogQL log query. The time range and result count are capped per instance; long lines are truncated.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: withTime(map[string]mcp.Property{
+					"query": {
+						Type:        "string",
+						Description: "LogQL log query (e.g., '{app=\"api\"} |= \"error\"')",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of log lines to return (capped by the instance setting)",
+					},
+					"direction": {
+						Type:        "string",
+						Description: "Sort order: 'backward' (newest first, default) or 'forward'",
+						Enum:        []string{"backward", "forward"},
+					},
+				}),
+				Required: []string{"query"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.logSearchTool.LokiQuery(ctx, incidentID, args)
+		},
+	)
+
+	// log_search.loki_labels
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "log_search.loki_labels",
+			Description: "List Loki label names, or the values of one label when label_name is given",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: withTime(map[string]mcp.Property{
+					"label_name": {
+						Type:        "string",
+						Description: "Label whose values to list; omit to list label names",
+					},
+					"query": {
+						Type:        "string",
+						Description: "Stream selector restricting label values (e.g., '{namespace=\"prod\"}')",
+					},
+				}),
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.logSearchTool.LokiLabels(ctx, incidentID, args)
+		},
+	)
+
+	// log_search.elasticsearch_search
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "log_search.elasticsearch_search",
+			Description: "Search log documents in Elasticsearch with a query DSL clause or Lucene query string, newest first. The time range and result count are capped per instance; long documents are truncated.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: withTime(map[string]mcp.Property{
+					"query": {
+						Type:        "string",
+						Description: "Lucene query string (e.g., 'level:error AND service:api') or a query DSL clause as JSON (e.g., '{\"match\": {\"level\": \"error\"}}'). Omit to match all documents in the range.",
+					},
+					"index": {
+						Type:        "string",
+						Description: "Index name or pattern (e.g., 'logs-*'). Defaults to the instance's default index.",
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "Maximum number of documents to return (capped by the instance setting)",
+					},
+				}),
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.logSearchTool.ElasticsearchSearch(ctx, incidentID, args)
+		},
+	)
+}
+
+// registerHTTPCheckTools registers the synthetic HTTP probe tool
+func (r *Registry) registerHTTPCheckTools() {
+	r.httpCheckTool = httpcheck.NewHTTPCheckTool(r.logger, r.httpCheckLimit)
+
+	// http_check.probe
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "http_check.probe",
+			Description: "Probe an allow-listed URL with GET, HEAD or POST and report status code, latency (with DNS/connect/TLS/first-byte breakdown), TLS certificate expiry, and assertion results. Connection failures are reported in the result's error field, not as a tool error.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"url": {
+						Type:        "string",
+						Description: "Absolute http(s) URL to probe; must match the instance's allowlist",
+					},
+					"method": {
+						Type:        "string",
+						Description: "HTTP method (default GET)",
+						Enum:        []string{"GET", "HEAD", "POST"},
+					},
+					"body": {
+						Type:        "string",
+						Description: "Request body for POST (sent as application/json unless content_type is set)",
+					},
+					"content_type": {
+						Type:        "string",
+						Description: "Content-Type of the request body",
+					},
+					"headers": {
+						Type:        "object",
+						Description: "Extra request headers as name/value pairs",
+					},
+					"expect_status": {
+						Type:        "array",
+						Description: "Acceptable status codes (default: any status below 400)",
+						Items:       &mcp.Items{Type: "integer"},
+					},
+					"expect_body_contains": {
+						Type:        "string",
+						Description: "Substring the response body must contain",
+					},
+					"expect_body_regex": {
+						Type:        "string",
+						Description: "Regular expression the response body must match",
+					},
+					"max_latency_ms": {
+						Type:        "integer",
+						Description: "Fail the latency assertion when the probe takes longer than this",
+					},
+				},
+				Required: []string{"url"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.httpCheckTool.Probe(ctx, incidentID, args)
+		},
+	)
+}
+
 // ListToolsByType lists registered tools filtered by tool type.
 // If toolType is empty, returns all tools.
 func (r *Registry) ListToolsByType(toolType string) []mcp.ToolListItem {
@@ -1344,7 +1866,7 @@ func BuildInstanceLookup() mcp.InstanceLookup {
 
 // GetToolCredentials is a helper to fetch credentials from database
 func GetToolCredentials(ctx context.Context, incidentID string, toolType string) (*database.ToolCredentials, error) {
-	return database.GetToolCredentialsForIncident(ctx, incidentID, toolType)
+	return database.ResolveToolCredentials(ctx, incidentID, toolType, nil, "")
 }
 
 // registerCatchpointTools registers all Catchpoint tool methods
@@ -1692,6 +2214,7 @@ func (r *Registry) registerCatchpointTools() {
 		mcp.Tool{
 			Name:        "catchpoint.acknowledge_alerts",
 			Description: "Acknowledge, assign, or drop test alerts",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2115,6 +2638,7 @@ func (r *Registry) registerGrafanaTools() {
 		mcp.Tool{
 			Name:        "grafana.silence_alert",
 			Description: "Create a silence in Grafana Alertmanager",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2301,7 +2825,8 @@ func (r *Registry) registerGrafanaTools() {
 	r.server.RegisterTool(
 		mcp.Tool{
 			Name:        "grafana.create_annotation",
-			Description: "Create an annotation on a Grafana dashboard or globally",
+			Description: "Create an annotation on a Grafana dashboard or globally. The annotation is tagged with the current incident UUID (akmatori_incident:<uuid>) so it links back to the investigation.",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2313,6 +2838,10 @@ func (r *Registry) registerGrafanaTools() {
 						Type:        "number",
 						Description: "Dashboard ID to attach the annotation to",
 					},
+					"dashboard_uid": {
+						Type:        "string",
+						Description: "Dashboard UID to attach the annotation to (alternative to dashboard_id)",
+					},
 					"panel_id": {
 						Type:        "number",
 						Description: "Panel ID to attach the annotation to",
@@ -2374,6 +2903,10 @@ func (r *Registry) registerGrafanaTools() {
 						Type:        "string",
 						Description: "Filter by type: annotation or alert",
 					},
+					"incident_only": {
+						Type:        "boolean",
+						Description: "Only return annotations written for the current incident",
+					},
 				},
 			},
 		},
@@ -2382,7 +2915,56 @@ func (r *Registry) registerGrafanaTools() {
 		},
 	)
 
-	r.logger.Println("Grafana tools registered (13 methods)")
+	// grafana.render_panel
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "grafana.render_panel",
+			Description: "Render a dashboard panel snapshot to PNG via the Grafana image renderer. Returns a panel deep link and render URL; set include_image to get the PNG as base64.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"dashboard_uid": {
+						Type:        "string",
+						Description: "Dashboard UID (required)",
+					},
+					"panel_id": {
+						Type:        "number",
+						Description: "Panel ID within the dashboard (required)",
+					},
+					"from": {
+						Type:        "string",
+						Description: "Start time: Grafana expression (e.g. now-6h) or epoch milliseconds. Default: now-1h",
+					},
+					"to": {
+						Type:        "string",
+						Description: "End time: Grafana expression or epoch milliseconds. Default: now",
+					},
+					"width": {
+						Type:        "number",
+						Description: "Image width in pixels (default 1000, max 3000)",
+					},
+					"height": {
+						Type:        "number",
+						Description: "Image height in pixels (default 500, max 2000)",
+					},
+					"theme": {
+						Type:        "string",
+						Description: "light or dark",
+					},
+					"include_image": {
+						Type:        "boolean",
+						Description: "Include the rendered PNG as base64 (max 2MB)",
+					},
+				},
+				Required: []string{"dashboard_uid", "panel_id"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.grafanaTool.RenderPanel(ctx, incidentID, args)
+		},
+	)
+
+	r.logger.Println("Grafana tools registered (14 methods)")
 }
 
 // registerClickHouseTools registers all ClickHouse tool methods
@@ -2866,6 +3448,7 @@ func (r *Registry) registerPagerDutyTools() {
 		mcp.Tool{
 			Name:        "pagerduty.acknowledge_incident",
 			Description: "Acknowledge a PagerDuty incident",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2875,10 +3458,10 @@ func (r *Registry) registerPagerDutyTools() {
 					},
 					"requester_email": {
 						Type:        "string",
-						Description: "Email address of the user acknowledging the incident (required)",
+						Description: "Email address of the user acknowledging the incident. Defaults to the instance's pagerduty_from_email",
 					},
 				},
-				Required: []string{"incident_id", "requester_email"},
+				Required: []string{"incident_id"},
 			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
@@ -2891,6 +3474,7 @@ func (r *Registry) registerPagerDutyTools() {
 		mcp.Tool{
 			Name:        "pagerduty.resolve_incident",
 			Description: "Resolve a PagerDuty incident",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2900,10 +3484,10 @@ func (r *Registry) registerPagerDutyTools() {
 					},
 					"requester_email": {
 						Type:        "string",
-						Description: "Email address of the user resolving the incident (required)",
+						Description: "Email address of the user resolving the incident. Defaults to the instance's pagerduty_from_email",
 					},
 				},
-				Required: []string{"incident_id", "requester_email"},
+				Required: []string{"incident_id"},
 			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
@@ -2916,6 +3500,7 @@ func (r *Registry) registerPagerDutyTools() {
 		mcp.Tool{
 			Name:        "pagerduty.reassign_incident",
 			Description: "Reassign a PagerDuty incident to different users or escalation policy",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2925,7 +3510,7 @@ func (r *Registry) registerPagerDutyTools() {
 					},
 					"requester_email": {
 						Type:        "string",
-						Description: "Email address of the user reassigning the incident (required)",
+						Description: "Email address of the user reassigning the incident. Defaults to the instance's pagerduty_from_email",
 					},
 					"assignee_ids": {
 						Type:        "string",
@@ -2936,7 +3521,7 @@ func (r *Registry) registerPagerDutyTools() {
 						Description: "Escalation policy ID to assign (optional)",
 					},
 				},
-				Required: []string{"incident_id", "requester_email", "assignee_ids"},
+				Required: []string{"incident_id", "assignee_ids"},
 			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
@@ -2949,6 +3534,7 @@ func (r *Registry) registerPagerDutyTools() {
 		mcp.Tool{
 			Name:        "pagerduty.add_incident_note",
 			Description: "Add a note to a PagerDuty incident",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -2958,14 +3544,14 @@ func (r *Registry) registerPagerDutyTools() {
 					},
 					"requester_email": {
 						Type:        "string",
-						Description: "Email address of the user adding the note (required)",
+						Description: "Email address of the user adding the note. Defaults to the instance's pagerduty_from_email",
 					},
 					"content": {
 						Type:        "string",
 						Description: "Note content text (required)",
 					},
 				},
-				Required: []string{"incident_id", "requester_email", "content"},
+				Required: []string{"incident_id", "content"},
 			},
 		},
 		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
@@ -2978,6 +3564,7 @@ func (r *Registry) registerPagerDutyTools() {
 		mcp.Tool{
 			Name:        "pagerduty.send_event",
 			Description: "Send an event via PagerDuty Events API v2 (trigger, acknowledge, or resolve)",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -4367,7 +4954,62 @@ func (r *Registry) registerK8sTools() {
 		},
 	)
 
-	r.logger.Println("Kubernetes tools registered (17 methods)")
+	// kubernetes.describe_resource
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "kubernetes.describe_resource",
+			Description: "Get a resource together with the events that reference it, similar to kubectl describe. Secrets are not supported.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"kind": {
+						Type:        "string",
+						Description: "Resource kind, e.g. pod, deployment, statefulset, daemonset, replicaset, job, cronjob, service, ingress, configmap, pvc, pv, node, namespace, hpa (required)",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Resource name (required)",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Kubernetes namespace (required for namespaced kinds)",
+					},
+				},
+				Required: []string{"kind", "name"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.k8sTool.DescribeResource(ctx, incidentID, args)
+		},
+	)
+
+	// kubernetes.restart_deployment (write)
+	r.server.RegisterTool(
+		mcp.Tool{
+			Name:        "kubernetes.restart_deployment",
+			Description: "Trigger a rolling restart of a deployment (like kubectl rollout restart). Requires k8s_allow_writes=true on the instance.",
+			Destructive: true,
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"namespace": {
+						Type:        "string",
+						Description: "Kubernetes namespace (required)",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Deployment name (required)",
+					},
+				},
+				Required: []string{"namespace", "name"},
+			},
+		},
+		func(ctx context.Context, incidentID string, args map[string]interface{}) (interface{}, error) {
+			return r.k8sTool.RestartDeployment(ctx, incidentID, args)
+		},
+	)
+
+	r.logger.Println("Kubernetes tools registered (19 methods)")
 }
 
 // registerJiraTools registers all Jira tool methods (9 read-only + 4 write).
@@ -4629,6 +5271,7 @@ func (r *Registry) registerJiraTools() {
 		mcp.Tool{
 			Name:        "jira.add_comment",
 			Description: "Add a comment to a Jira issue. Requires jira_allow_writes=true on the instance.",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -4654,6 +5297,7 @@ func (r *Registry) registerJiraTools() {
 		mcp.Tool{
 			Name:        "jira.transition_issue",
 			Description: "Move a Jira issue through a workflow transition. Requires jira_allow_writes=true on the instance.",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -4687,6 +5331,7 @@ func (r *Registry) registerJiraTools() {
 		mcp.Tool{
 			Name:        "jira.create_issue",
 			Description: "Create a new Jira issue. Requires jira_allow_writes=true on the instance.",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -4737,6 +5382,7 @@ func (r *Registry) registerJiraTools() {
 		mcp.Tool{
 			Name:        "jira.update_issue",
 			Description: "Update fields on an existing Jira issue. Requires jira_allow_writes=true on the instance.",
+			Destructive: true,
 			InputSchema: mcp.InputSchema{
 				Type: "object",
 				Properties: map[string]mcp.Property{
@@ -790,25 +790,26 @@ func TestRegisterPagerDutyTools_InputSchemas(t *testing.T) {
 		t.Error("get_incidents: expected 'statuses' property")
 	}
 
-	// acknowledge_incident requires incident_id and requester_email
+	// acknowledge_incident requires incident_id; requester_email falls back
+	// to the instance's pagerduty_from_email
 	ai := tools["pagerduty.acknowledge_incident"]
-	if len(ai.InputSchema.Required) != 2 {
-		t.Errorf("acknowledge_incident: expected 2 required params, got %d", len(ai.InputSchema.Required))
+	if len(ai.InputSchema.Required) != 1 {
+		t.Errorf("acknowledge_incident: expected 1 required param, got %d", len(ai.InputSchema.Required))
 	}
 
-	// reassign_incident requires incident_id, requester_email, assignee_ids
+	// reassign_incident requires incident_id, assignee_ids
 	ri := tools["pagerduty.reassign_incident"]
-	if len(ri.InputSchema.Required) != 3 {
-		t.Errorf("reassign_incident: expected 3 required params, got %d", len(ri.InputSchema.Required))
+	if len(ri.InputSchema.Required) != 2 {
+		t.Errorf("reassign_incident: expected 2 required params, got %d", len(ri.InputSchema.Required))
 	}
 	if _, ok := ri.InputSchema.Properties["escalation_policy_id"]; !ok {
 		t.Error("reassign_incident: expected 'escalation_policy_id' property")
 	}
 
-	// add_incident_note requires incident_id, requester_email, content
+	// add_incident_note requires incident_id, content
 	an := tools["pagerduty.add_incident_note"]
-	if len(an.InputSchema.Required) != 3 {
-		t.Errorf("add_incident_note: expected 3 required params, got %d", len(an.InputSchema.Required))
+	if len(an.InputSchema.Required) != 2 {
+		t.Errorf("add_incident_note: expected 2 required params, got %d", len(an.InputSchema.Required))
 	}
 
 	// send_event requires routing_key and event_action
@@ -1131,3 +1132,162 @@ func TestBuiltInToolNamespaces_IncludesIncidents(t *testing.T) {
 	}
 }
 
+
+func TestRegisterPrometheusTools_AllToolsRegistered(t *testing.T) {
+	stdLogger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", stdLogger)
+	registry := NewRegistry(server, stdLogger)
+
+	registry.prometheusLimit = ratelimit.New(PrometheusRatePerSecond, PrometheusBurstCapacity)
+	registry.registerPrometheusTools()
+	defer registry.Stop()
+
+	tools := server.Tools()
+	required := map[string][]string{
+		"prometheus.query":        {"query"},
+		"prometheus.query_range":  {"query", "start", "end", "step"},
+		"prometheus.series":       {"match"},
+		"prometheus.label_values": {"label_name"},
+	}
+	for name, want := range required {
+		tool, ok := tools[name]
+		if !ok {
+			t.Errorf("expected tool %q to be registered", name)
+			continue
+		}
+		if strings.Join(tool.InputSchema.Required, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected required %v, got %v", name, want, tool.InputSchema.Required)
+		}
+	}
+	if !builtInToolNamespaces["prometheus"] {
+		t.Error("prometheus must be a built-in namespace so proxy configs cannot shadow it")
+	}
+}
+
+func TestRegisterLogSearchTools_AllToolsRegistered(t *testing.T) {
+	stdLogger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", stdLogger)
+	registry := NewRegistry(server, stdLogger)
+
+	registry.logSearchLimit = ratelimit.New(LogSearchRatePerSecond, LogSearchBurstCapacity)
+	registry.registerLogSearchTools()
+	defer registry.Stop()
+
+	tools := server.Tools()
+	required := map[string][]string{
+		"log_search.loki_query":           {"query"},
+		"log_search.loki_labels":          nil,
+		"log_search.elasticsearch_search": nil,
+	}
+	for name, want := range required {
+		tool, ok := tools[name]
+		if !ok {
+			t.Errorf("expected tool %q to be registered", name)
+			continue
+		}
+		if strings.Join(tool.InputSchema.Required, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected required %v, got %v", name, want, tool.InputSchema.Required)
+		}
+		if _, ok := tool.InputSchema.Properties["start"]; !ok {
+			t.Errorf("%s: expected a start property", name)
+		}
+	}
+	if !builtInToolNamespaces["log_search"] {
+		t.Error("log_search must be a built-in namespace so proxy configs cannot shadow it")
+	}
+}
+
+func TestRegisterK8sTools_DescribeAndRestartRegistered(t *testing.T) {
+	stdLogger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", stdLogger)
+	registry := NewRegistry(server, stdLogger)
+
+	registry.k8sLimit = ratelimit.New(K8sRatePerSecond, K8sBurstCapacity)
+	registry.registerK8sTools()
+	defer registry.Stop()
+
+	tools := server.Tools()
+	required := map[string][]string{
+		"kubernetes.describe_resource":  {"kind", "name"},
+		"kubernetes.restart_deployment": {"namespace", "name"},
+	}
+	for name, want := range required {
+		tool, ok := tools[name]
+		if !ok {
+			t.Errorf("expected tool %q to be registered", name)
+			continue
+		}
+		if strings.Join(tool.InputSchema.Required, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected required %v, got %v", name, want, tool.InputSchema.Required)
+		}
+	}
+	if !strings.Contains(tools["kubernetes.restart_deployment"].Description, "k8s_allow_writes") {
+		t.Error("restart_deployment description must mention k8s_allow_writes")
+	}
+}
+
+func TestRegisterHTTPCheckTools_ProbeRegistered(t *testing.T) {
+	stdLogger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", stdLogger)
+	registry := NewRegistry(server, stdLogger)
+
+	registry.httpCheckLimit = ratelimit.New(HTTPCheckRatePerSecond, HTTPCheckBurstCapacity)
+	registry.registerHTTPCheckTools()
+	defer registry.Stop()
+
+	tool, ok := server.Tools()["http_check.probe"]
+	if !ok {
+		t.Fatal("expected tool http_check.probe to be registered")
+	}
+	if strings.Join(tool.InputSchema.Required, ",") != "url" {
+		t.Errorf("expected required [url], got %v", tool.InputSchema.Required)
+	}
+	if got := tool.InputSchema.Properties["method"].Enum; strings.Join(got, ",") != "GET,HEAD,POST" {
+		t.Errorf("unexpected method enum %v", got)
+	}
+	if !builtInToolNamespaces["http_check"] {
+		t.Error("http_check must be a built-in namespace so proxy configs cannot shadow it")
+	}
+}
+
+func TestRegisterAllTools_FlagsWriteTools(t *testing.T) {
+	stdLogger := log.New(io.Discard, "", 0)
+	server := mcp.NewServer("test", "1.0.0", stdLogger)
+	NewRegistry(server, stdLogger).RegisterAllTools()
+	tools := server.Tools()
+
+	for _, name := range []string{
+		"ssh.write_file",
+		"catchpoint.acknowledge_alerts",
+		"grafana.silence_alert",
+		"grafana.create_annotation",
+		"pagerduty.acknowledge_incident",
+		"pagerduty.resolve_incident",
+		"pagerduty.reassign_incident",
+		"pagerduty.add_incident_note",
+		"pagerduty.send_event",
+		"kubernetes.restart_deployment",
+		"jira.add_comment",
+		"jira.transition_issue",
+		"jira.create_issue",
+		"jira.update_issue",
+	} {
+		if !tools[name].IsWriteCall(nil) {
+			t.Errorf("%s must be gated as a write", name)
+		}
+	}
+	for _, name := range []string{"ssh.read_file", "kubernetes.get_pods", "jira.get_issue", "pagerduty.get_incidents"} {
+		if tools[name].Destructive {
+			t.Errorf("%s is read-only but flagged destructive", name)
+		}
+	}
+
+	exec := tools["ssh.execute_command"]
+	if exec.IsWriteCall(map[string]interface{}{"command": "df -h"}) || !exec.IsWriteCall(map[string]interface{}{"command": "systemctl restart nginx"}) {
+		t.Error("ssh.execute_command must only gate write commands")
+	}
+	zbx := tools["zabbix.api_request"]
+	if zbx.IsWriteCall(map[string]interface{}{"method": "host.get"}) || !zbx.IsWriteCall(map[string]interface{}{"method": "host.delete"}) {
+		t.Error("zabbix.api_request must only gate write methods")
+	}
+}
@@ -53,9 +53,13 @@ func intPtr(i int) *int {
 	return &i
 }
 
+// writeToolTypes have tools flagged Destructive, whose write calls wait for
+// human approval unless the instance turns require_write_approval off.
+var writeToolTypes = []string{"ssh", "zabbix", "grafana", "catchpoint", "pagerduty", "kubernetes", "jira"}
+
 // GetToolSchemas returns all tool type schemas
 func GetToolSchemas() map[string]ToolTypeSchema {
-	return map[string]ToolTypeSchema{
+	schemas := map[string]ToolTypeSchema{
 		"ssh":              getSSHSchema(),
 		"zabbix":           getZabbixSchema(),
 		"victoria_metrics": getVictoriaMetricsSchema(),
@@ -67,6 +71,31 @@ func GetToolSchemas() map[string]ToolTypeSchema {
 		"netbox":           getNetBoxSchema(),
 		"kubernetes":       getK8sSchema(),
 		"jira":             getJiraSchema(),
+		"prometheus":       getPrometheusSchema(),
+		"log_search":       getLogSearchSchema(),
+		"http_check":       getHTTPCheckSchema(),
+	}
+	for _, name := range writeToolTypes {
+		addApprovalSettings(schemas[name].SettingsSchema.Properties)
+	}
+	return schemas
+}
+
+// addApprovalSettings adds the settings read by the approval gate.
+func addApprovalSettings(properties map[string]PropertySchema) {
+	properties["require_write_approval"] = PropertySchema{
+		Type:        "boolean",
+		Description: "Hold write calls (commands that change hosts, restarts, acknowledgements, comments and the like) until a human approves them in the incident's Slack thread or the web UI. Rejecting stops the investigation.",
+		Default:     true,
+		Warning:     "Turning this off lets the agent perform writes without asking anyone.",
+	}
+	properties["approval_timeout_seconds"] = PropertySchema{
+		Type:        "integer",
+		Description: "How long a write waits for approval before it is dropped as not approved",
+		Default:     300,
+		Minimum:     intPtr(30),
+		Maximum:     intPtr(600),
+		Advanced:    true,
 	}
 }
 
@@ -80,8 +109,8 @@ func GetToolSchema(name string) (ToolTypeSchema, bool) {
 func getSSHSchema() ToolTypeSchema {
 	return ToolTypeSchema{
 		Name:        "ssh",
-		Description: "SSH remote command execution tool. Execute commands across multiple servers in parallel with per-host configuration, jumphost support, and read-only mode for security.",
-		Version:     "3.0.0",
+		Description: "SSH remote command execution tool. Execute commands across multiple servers in parallel and transfer files over SFTP, with per-host configuration, jumphost support, and read-only mode for security.",
+		Version:     "3.5.0",
 		SettingsSchema: SettingsSchema{
 			Type:     "object",
 			Required: []string{},
@@ -103,10 +132,15 @@ func getSSHSchema() ToolTypeSchema {
 							},
 							"private_key": {
 								Type:        "string",
-								Description: "SSH private key content (PEM format)",
+								Description: "SSH private key content (PEM or OpenSSH format)",
 								Secret:      true,
 								Format:      "textarea",
 							},
+							"passphrase": {
+								Type:        "string",
+								Description: "Passphrase for an encrypted private key",
+								Secret:      true,
+							},
 							"is_default": {
 								Type:        "boolean",
 								Description: "Whether this is the default key for all hosts",
@@ -173,6 +207,19 @@ func getSSHSchema() ToolTypeSchema {
 								Maximum:     intPtr(65535),
 								Advanced:    true,
 							},
+							"password": {
+								Type:        "string",
+								Description: "Password for the target host, tried after any SSH key or ssh-agent key (not used for the jumphost)",
+								Secret:      true,
+								Advanced:    true,
+							},
+							"forward_agent": {
+								Type:        "boolean",
+								Description: "Forward an SSH agent to commands on this host so they can authenticate onward; forwards the gateway's ssh-agent when ssh_agent_auth is on, otherwise only this host's SSH key",
+								Default:     false,
+								Advanced:    true,
+								Warning:     "Anyone with root on this host can use the forwarded keys while a command runs.",
+							},
 							"allow_write_commands": {
 								Type:        "boolean",
 								Description: "Allow write/destructive commands (WARNING: security risk)",
@@ -199,13 +246,43 @@ func getSSHSchema() ToolTypeSchema {
 					Maximum:     intPtr(60),
 					Advanced:    true,
 				},
+				"ssh_max_output_bytes": {
+					Type:        "integer",
+					Description: "Maximum stdout and stderr bytes kept per server for each command; output beyond this is dropped and flagged as truncated",
+					Default:     1048576,
+					Minimum:     intPtr(1024),
+					Maximum:     intPtr(16777216),
+					Advanced:    true,
+				},
+				"ssh_max_parallel": {
+					Type:        "integer",
+					Description: "Maximum number of hosts this instance runs commands on at once, across all concurrent calls",
+					Default:     10,
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(100),
+					Advanced:    true,
+				},
+				"ssh_connection_idle_timeout": {
+					Type:        "integer",
+					Description: "Seconds an SSH connection is kept open for reuse by later calls in the same incident; 0 opens a fresh connection for every command",
+					Default:     300,
+					Minimum:     intPtr(0),
+					Maximum:     intPtr(3600),
+					Advanced:    true,
+				},
 				"ssh_known_hosts_policy": {
 					Type:        "string",
 					Enum:        []string{"strict", "auto_add", "ignore"},
-					Description: "SSH known hosts verification policy",
+					Description: "Host key verification: auto_add pins each host's key on first connection and refuses later changes (trust on first use); strict only connects to hosts whose key is already pinned; ignore skips verification",
 					Default:     "auto_add",
 					Advanced:    true,
 				},
+				"ssh_agent_auth": {
+					Type:        "boolean",
+					Description: "Also authenticate with keys held by the ssh-agent whose socket is mounted into the gateway at SSH_AUTH_SOCK",
+					Default:     false,
+					Advanced:    true,
+				},
 				"ssh_debug": {
 					Type:        "boolean",
 					Description: "Enable debug logging",
@@ -259,6 +336,24 @@ func getSSHSchema() ToolTypeSchema {
 				Parameters:  "None",
 				Returns:     "JSON string with server info: {results: [{server, success, stdout, stderr}]}",
 			},
+			{
+				Name:        "read_file",
+				Description: "Read a file from one server over SFTP instead of cat-ing it through execute_command. Returns up to 256KB per call; use offset to page or tail for the end of a log.",
+				Parameters:  "server: str - Hostname or address; path: str - File path; offset: int - Optional byte offset; max_bytes: int - Optional bytes to return (default 262144, max 4194304); tail: bool - Optional, read the last max_bytes",
+				Returns:     "JSON string: {server, path, size, offset, bytes_read, truncated, encoding, content, mod_time} (encoding is utf-8 or base64)",
+			},
+			{
+				Name:        "write_file",
+				Description: "Write a file (up to 1MB) on one server over SFTP. Only allowed on hosts with allow_write_commands enabled.",
+				Parameters:  "server: str - Hostname or address; path: str - File path; content: str - File content; mode: str - Optional octal permissions (default 0644); append: bool - Optional, append instead of replacing",
+				Returns:     "JSON string: {server, path, bytes_written, appended}",
+			},
+			{
+				Name:        "list_dir",
+				Description: "List a directory on one server over SFTP (up to 1000 entries, sorted by name)",
+				Parameters:  "server: str - Hostname or address; path: str - Optional directory (defaults to the login user's home)",
+				Returns:     "JSON string: {server, path, entries: [{name, type, size, mode, mod_time}], total, truncated}",
+			},
 		},
 	}
 }
@@ -547,16 +642,22 @@ func getGrafanaSchema() ToolTypeSchema {
 			},
 			{
 				Name:        "create_annotation",
-				Description: "Create an annotation on a Grafana dashboard or globally",
-				Parameters:  "text (required), dashboard_id, panel_id, tags, time, time_end",
+				Description: "Create an annotation on a Grafana dashboard or globally, tagged with the incident UUID",
+				Parameters:  "text (required), dashboard_id, dashboard_uid, panel_id, tags, time, time_end",
 				Returns:     "JSON with annotation ID",
 			},
 			{
 				Name:        "get_annotations",
 				Description: "List annotations with optional filters",
-				Parameters:  "from, to, dashboard_id, panel_id, tags, limit, type (annotation|alert)",
+				Parameters:  "from, to, dashboard_id, panel_id, tags, limit, type (annotation|alert), incident_only",
 				Returns:     "JSON array of annotation objects",
 			},
+			{
+				Name:        "render_panel",
+				Description: "Render a dashboard panel snapshot via the Grafana image renderer",
+				Parameters:  "dashboard_uid (required), panel_id (required), from, to, width, height, theme, include_image",
+				Returns:     "JSON with panel_url, render_url, size_bytes and optional image_base64",
+			},
 		},
 	}
 }
@@ -925,6 +1026,15 @@ func getPagerDutySchema() ToolTypeSchema {
 					Description: "PagerDuty API base URL",
 					Default:     "https://api.pagerduty.com",
 				},
+				"pagerduty_from_email": {
+					Type:        "string",
+					Description: "Email of the PagerDuty user that acknowledges, resolves and annotates incidents (From header)",
+				},
+				"pagerduty_sync_incidents": {
+					Type:        "boolean",
+					Description: "Acknowledge PagerDuty incidents when Akmatori starts investigating them, post the findings as a note, and resolve them when the Akmatori incident closes",
+					Default:     false,
+				},
 				"pagerduty_verify_ssl": {
 					Type:        "boolean",
 					Description: "Verify SSL certificates",
@@ -993,25 +1103,25 @@ func getPagerDutySchema() ToolTypeSchema {
 			{
 				Name:        "acknowledge_incident",
 				Description: "Acknowledge an incident",
-				Parameters:  "incident_id (required), requester_email (required)",
+				Parameters:  "incident_id (required), requester_email (defaults to pagerduty_from_email)",
 				Returns:     "JSON updated incident object",
 			},
 			{
 				Name:        "resolve_incident",
 				Description: "Resolve an incident",
-				Parameters:  "incident_id (required), requester_email (required)",
+				Parameters:  "incident_id (required), requester_email (defaults to pagerduty_from_email)",
 				Returns:     "JSON updated incident object",
 			},
 			{
 				Name:        "reassign_incident",
 				Description: "Reassign an incident to a different user or escalation policy",
-				Parameters:  "incident_id (required), requester_email (required), assignee_ids, escalation_policy_id",
+				Parameters:  "incident_id (required), requester_email (defaults to pagerduty_from_email), assignee_ids, escalation_policy_id",
 				Returns:     "JSON updated incident object",
 			},
 			{
 				Name:        "add_incident_note",
 				Description: "Add a note to an incident",
-				Parameters:  "incident_id (required), requester_email (required), content (required)",
+				Parameters:  "incident_id (required), requester_email (defaults to pagerduty_from_email), content (required)",
 				Returns:     "JSON note object",
 			},
 			{
@@ -1027,20 +1137,41 @@ func getPagerDutySchema() ToolTypeSchema {
 func getK8sSchema() ToolTypeSchema {
 	return ToolTypeSchema{
 		Name:        "kubernetes",
-		Description: "Read-only Kubernetes cluster diagnostics. Query pods, nodes, deployments, services, events, and logs for incident investigation without making any mutations.",
+		Description: "Kubernetes cluster diagnostics. Query pods, nodes, deployments, services, events, and logs for incident investigation. Read-only unless k8s_allow_writes=true, which enables restart_deployment.",
 		Version:     "1.0.0",
 		SettingsSchema: SettingsSchema{
-			Type:     "object",
-			Required: []string{"k8s_url", "k8s_token"},
+			Type: "object",
 			Properties: map[string]PropertySchema{
 				"k8s_url": {
 					Type:        "string",
-					Description: "Kubernetes API server URL (e.g. https://k8s.example.com:6443)",
+					Description: "Kubernetes API server URL (e.g. https://k8s.example.com:6443). Overrides the server from k8s_kubeconfig or k8s_in_cluster.",
 				},
 				"k8s_token": {
 					Type:        "string",
-					Description: "Kubernetes Bearer token for authentication (service account token)",
+					Description: "Kubernetes Bearer token for authentication (service account token). Overrides the token from k8s_kubeconfig or k8s_in_cluster.",
+					Secret:      true,
+				},
+				"k8s_kubeconfig": {
+					Type:        "string",
+					Description: "Kubeconfig YAML with embedded credentials (token or client-certificate-data). Exec and auth-provider plugins are not supported.",
 					Secret:      true,
+					Format:      "textarea",
+				},
+				"k8s_context": {
+					Type:        "string",
+					Description: "Kubeconfig context to use (defaults to current-context)",
+					Advanced:    true,
+				},
+				"k8s_in_cluster": {
+					Type:        "boolean",
+					Description: "Authenticate with the gateway pod's own service account instead of a URL and token",
+					Default:     false,
+				},
+				"k8s_allow_writes": {
+					Type:        "boolean",
+					Description: "Allow write operations (restart_deployment). Disabled by default for safety.",
+					Default:     false,
+					Warning:     "Enabling this allows the agent to trigger rolling restarts of deployments in this cluster.",
 				},
 				"k8s_ca_cert": {
 					Type:        "string",
@@ -1180,6 +1311,19 @@ func getK8sSchema() ToolTypeSchema {
 				Parameters:  "path (required, must start with /api or /apis), params (optional query parameters)",
 				Returns:     "JSON response from the Kubernetes API",
 			},
+			{
+				Name:        "describe_resource",
+				Description: "Get a resource and the events that reference it, similar to kubectl describe (secrets are not supported)",
+				Parameters:  "kind (required, e.g. pod, deployment, node), name (required), namespace (required for namespaced kinds)",
+				Returns:     "JSON object with resource (managedFields removed) and events array",
+			},
+			// Write (requires k8s_allow_writes=true)
+			{
+				Name:        "restart_deployment",
+				Description: "Trigger a rolling restart of a deployment, like kubectl rollout restart. Requires k8s_allow_writes=true on the instance.",
+				Parameters:  "namespace (required), name (required)",
+				Returns:     "JSON object with namespace, name, restarted_at, and the deployment's new generation",
+			},
 		},
 	}
 }
@@ -1484,3 +1628,279 @@ func getJiraSchema() ToolTypeSchema {
 		},
 	}
 }
+
+func getPrometheusSchema() ToolTypeSchema {
+	return ToolTypeSchema{
+		Name:        "prometheus",
+		Description: "Prometheus HTTP API integration. Run PromQL instant and range queries, find series and explore label values on Prometheus or any compatible endpoint (Thanos, Mimir, VictoriaMetrics).",
+		Version:     "1.0.0",
+		SettingsSchema: SettingsSchema{
+			Type:     "object",
+			Required: []string{"prom_url"},
+			Properties: map[string]PropertySchema{
+				"prom_url": {
+					Type:        "string",
+					Description: "Prometheus base URL, including any path prefix (e.g., https://prometheus.example.com or https://thanos.example.com/query)",
+					Example:     "https://prometheus.example.com",
+				},
+				"prom_auth_method": {
+					Type:        "string",
+					Description: "Authentication method",
+					Enum:        []string{"none", "basic_auth", "bearer_token"},
+					Default:     "none",
+				},
+				"prom_username": {
+					Type:        "string",
+					Description: "Username for basic auth (if using basic_auth method)",
+				},
+				"prom_password": {
+					Type:        "string",
+					Description: "Password for basic auth (if using basic_auth method)",
+					Secret:      true,
+				},
+				"prom_bearer_token": {
+					Type:        "string",
+					Description: "Bearer token (if using bearer_token method)",
+					Secret:      true,
+				},
+				"prom_verify_ssl": {
+					Type:        "boolean",
+					Description: "Verify SSL certificates",
+					Default:     true,
+					Advanced:    true,
+				},
+				"prom_ca_cert": {
+					Type:        "string",
+					Description: "PEM-encoded CA certificate(s) used to verify the server, for private CAs",
+					Format:      "textarea",
+					Advanced:    true,
+				},
+				"prom_client_cert": {
+					Type:        "string",
+					Description: "PEM-encoded client certificate for mutual TLS",
+					Format:      "textarea",
+					Advanced:    true,
+				},
+				"prom_client_key": {
+					Type:        "string",
+					Description: "PEM-encoded private key for the client certificate",
+					Format:      "textarea",
+					Secret:      true,
+					Advanced:    true,
+				},
+				"prom_timeout": {
+					Type:        "integer",
+					Description: "API request timeout in seconds",
+					Default:     30,
+					Minimum:     intPtr(5),
+					Maximum:     intPtr(300),
+					Advanced:    true,
+				},
+			},
+		},
+		Functions: []ToolFunction{
+			{
+				Name:        "query",
+				Description: "Execute a PromQL instant query",
+				Parameters:  "query (required), time, timeout",
+				Returns:     "JSON with resultType and result array",
+			},
+			{
+				Name:        "query_range",
+				Description: "Execute a PromQL range query",
+				Parameters:  "query (required), start (required), end (required), step (required), timeout",
+				Returns:     "JSON with resultType and result array (matrix)",
+			},
+			{
+				Name:        "series",
+				Description: "Find series matching one or more selectors",
+				Parameters:  "match (required, string or array), start, end, limit",
+				Returns:     "JSON array of series label sets",
+			},
+			{
+				Name:        "label_values",
+				Description: "Get the values of a label, optionally filtered by selectors",
+				Parameters:  "label_name (required), match, start, end, limit",
+				Returns:     "JSON array of label values",
+			},
+		},
+	}
+}
+
+func getLogSearchSchema() ToolTypeSchema {
+	return ToolTypeSchema{
+		Name:        "log_search",
+		Description: "Log search over Loki (LogQL) or Elasticsearch (query DSL). Each instance points at one backend; queries are bounded by a maximum time range and result count, and long lines are truncated.",
+		Version:     "1.0.0",
+		SettingsSchema: SettingsSchema{
+			Type:     "object",
+			Required: []string{"logs_backend", "logs_url"},
+			Properties: map[string]PropertySchema{
+				"logs_backend": {
+					Type:        "string",
+					Description: "Log backend this instance queries",
+					Enum:        []string{"loki", "elasticsearch"},
+					Default:     "loki",
+				},
+				"logs_url": {
+					Type:        "string",
+					Description: "Base URL of Loki or Elasticsearch, including any path prefix",
+					Example:     "https://loki.example.com",
+				},
+				"logs_auth_method": {
+					Type:        "string",
+					Description: "Authentication method (api_key is Elasticsearch only)",
+					Enum:        []string{"none", "basic_auth", "bearer_token", "api_key"},
+					Default:     "none",
+				},
+				"logs_username": {
+					Type:        "string",
+					Description: "Username for basic auth (if using basic_auth method)",
+				},
+				"logs_password": {
+					Type:        "string",
+					Description: "Password for basic auth (if using basic_auth method)",
+					Secret:      true,
+				},
+				"logs_bearer_token": {
+					Type:        "string",
+					Description: "Bearer token (if using bearer_token method)",
+					Secret:      true,
+				},
+				"logs_api_key": {
+					Type:        "string",
+					Description: "Elasticsearch API key, base64 encoded id:key (if using api_key method)",
+					Secret:      true,
+				},
+				"logs_tenant_id": {
+					Type:        "string",
+					Description: "Loki tenant sent as X-Scope-OrgID (multi-tenant Loki only)",
+					Advanced:    true,
+				},
+				"logs_default_index": {
+					Type:        "string",
+					Description: "Elasticsearch index or pattern used when a search does not name one",
+					Example:     "logs-*",
+				},
+				"logs_time_field": {
+					Type:        "string",
+					Description: "Elasticsearch timestamp field used for range filtering and sorting",
+					Default:     "@timestamp",
+					Advanced:    true,
+				},
+				"logs_max_results": {
+					Type:        "integer",
+					Description: "Maximum log lines or documents returned per search",
+					Default:     100,
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(1000),
+					Advanced:    true,
+				},
+				"logs_max_range_hours": {
+					Type:        "integer",
+					Description: "Longest time range a single search may cover, in hours",
+					Default:     24,
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(168),
+					Advanced:    true,
+				},
+				"logs_verify_ssl": {
+					Type:        "boolean",
+					Description: "Verify SSL certificates",
+					Default:     true,
+					Advanced:    true,
+				},
+				"logs_timeout": {
+					Type:        "integer",
+					Description: "API request timeout in seconds",
+					Default:     30,
+					Minimum:     intPtr(5),
+					Maximum:     intPtr(300),
+					Advanced:    true,
+				},
+			},
+		},
+		Functions: []ToolFunction{
+			{
+				Name:        "loki_query",
+				Description: "Search log lines with a LogQL log query (Loki instances)",
+				Parameters:  "query (required), start, end, limit, direction",
+				Returns:     "JSON with time range, total, truncated flag and entries (timestamp, labels, line)",
+			},
+			{
+				Name:        "loki_labels",
+				Description: "List label names, or the values of one label (Loki instances)",
+				Parameters:  "label_name, query, start, end",
+				Returns:     "JSON array of label names or values",
+			},
+			{
+				Name:        "elasticsearch_search",
+				Description: "Search documents with a query DSL clause or query string, newest first (Elasticsearch instances)",
+				Parameters:  "query, index, start, end, limit",
+				Returns:     "JSON with time range, total, truncated flag and entries (timestamp, index, id, line)",
+			},
+		},
+	}
+}
+
+func getHTTPCheckSchema() ToolTypeSchema {
+	return ToolTypeSchema{
+		Name:        "http_check",
+		Description: "Synthetic HTTP probes against allow-listed URLs. Reports status code, latency breakdown, TLS certificate expiry, and body/status/latency assertion results so the agent can confirm whether a user-facing endpoint is really down.",
+		Version:     "1.0.0",
+		SettingsSchema: SettingsSchema{
+			Type:     "object",
+			Required: []string{"http_check_allowed_urls"},
+			Properties: map[string]PropertySchema{
+				"http_check_allowed_urls": {
+					Type:        "string",
+					Description: "URLs the agent may probe, one per line. Entries with a scheme are URL prefixes (https://api.example.com/health); entries without are host patterns (status.example.com, *.example.com, 10.0.0.5:8080).",
+					Format:      "textarea",
+					Example:     "https://www.example.com/\n*.api.example.com",
+				},
+				"http_check_headers": {
+					Type:        "string",
+					Description: "Headers sent with every probe, one 'Name: value' per line (e.g. an Authorization header for protected health endpoints)",
+					Secret:      true,
+					Format:      "textarea",
+				},
+				"http_check_follow_redirects": {
+					Type:        "boolean",
+					Description: "Follow up to 5 redirects; each target must also be allow-listed",
+					Default:     true,
+					Advanced:    true,
+				},
+				"http_check_max_body_bytes": {
+					Type:        "integer",
+					Description: "Bytes of the response body read for body assertions",
+					Default:     65536,
+					Minimum:     intPtr(1024),
+					Maximum:     intPtr(1048576),
+					Advanced:    true,
+				},
+				"http_check_verify_ssl": {
+					Type:        "boolean",
+					Description: "Fail probes whose TLS certificate does not verify. Certificate details are reported either way.",
+					Default:     true,
+					Advanced:    true,
+				},
+				"http_check_timeout": {
+					Type:        "integer",
+					Description: "Probe timeout in seconds",
+					Default:     10,
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(60),
+					Advanced:    true,
+				},
+			},
+		},
+		Functions: []ToolFunction{
+			{
+				Name:        "probe",
+				Description: "Issue a GET, HEAD or POST to an allow-listed URL and check the response",
+				Parameters:  "url (required), method, body, content_type, headers, expect_status, expect_body_contains, expect_body_regex, max_latency_ms",
+				Returns:     "JSON with ok, status_code, latency_ms, timings, tls (issuer, not_after, days_until_expiry), assertions, body_excerpt and error",
+			},
+		},
+	}
+}
@@ -468,7 +468,7 @@ func TestGrafanaSchema_Functions(t *testing.T) {
 		"search_dashboards", "get_dashboard", "get_dashboard_panels",
 		"get_alert_rules", "get_alert_instances", "get_alert_rule", "silence_alert",
 		"list_data_sources", "query_data_source", "query_prometheus", "query_loki",
-		"create_annotation", "get_annotations",
+		"create_annotation", "get_annotations", "render_panel",
 	}
 	if len(schema.Functions) != len(expectedFunctions) {
 		t.Fatalf("expected %d functions, got %d", len(expectedFunctions), len(schema.Functions))
@@ -616,7 +616,7 @@ func TestClickHouseSchema_Functions(t *testing.T) {
 func TestGetToolSchemas_AllPresent(t *testing.T) {
 	schemas := GetToolSchemas()
 
-	expected := []string{"ssh", "zabbix", "victoria_metrics", "catchpoint", "postgresql", "grafana", "clickhouse", "pagerduty", "netbox", "kubernetes", "jira"}
+	expected := []string{"ssh", "zabbix", "victoria_metrics", "catchpoint", "postgresql", "grafana", "clickhouse", "pagerduty", "netbox", "kubernetes", "jira", "prometheus", "log_search", "http_check"}
 	for _, name := range expected {
 		if _, ok := schemas[name]; !ok {
 			t.Errorf("missing schema: %s", name)
@@ -888,14 +888,10 @@ func TestGetToolSchema_Kubernetes(t *testing.T) {
 func TestK8sSchema_RequiredFields(t *testing.T) {
 	schema, _ := GetToolSchema("kubernetes")
 
-	expectedRequired := []string{"k8s_url", "k8s_token"}
-	if len(schema.SettingsSchema.Required) != len(expectedRequired) {
-		t.Fatalf("expected %d required fields, got %d", len(expectedRequired), len(schema.SettingsSchema.Required))
-	}
-	for i, field := range expectedRequired {
-		if schema.SettingsSchema.Required[i] != field {
-			t.Errorf("expected required[%d] = %q, got %q", i, field, schema.SettingsSchema.Required[i])
-		}
+	// URL + token, a kubeconfig, or in-cluster auth are alternatives, so no
+	// single field can be required.
+	if len(schema.SettingsSchema.Required) != 0 {
+		t.Errorf("expected no required fields, got %v", schema.SettingsSchema.Required)
 	}
 }
 
@@ -903,7 +899,7 @@ func TestK8sSchema_Settings(t *testing.T) {
 	schema, _ := GetToolSchema("kubernetes")
 	props := schema.SettingsSchema.Properties
 
-	expectedFields := []string{"k8s_url", "k8s_token", "k8s_ca_cert", "k8s_verify_ssl", "k8s_timeout"}
+	expectedFields := []string{"k8s_url", "k8s_token", "k8s_kubeconfig", "k8s_context", "k8s_in_cluster", "k8s_allow_writes", "k8s_ca_cert", "k8s_verify_ssl", "k8s_timeout"}
 	for _, field := range expectedFields {
 		if _, ok := props[field]; !ok {
 			t.Errorf("missing settings field: %s", field)
@@ -918,6 +914,9 @@ func TestK8sSchema_SecretFields(t *testing.T) {
 	if !props["k8s_token"].Secret {
 		t.Error("expected k8s_token to be marked as secret")
 	}
+	if !props["k8s_kubeconfig"].Secret {
+		t.Error("expected k8s_kubeconfig to be marked as secret")
+	}
 	if props["k8s_ca_cert"].Secret {
 		t.Error("expected k8s_ca_cert to NOT be marked as secret (CA certs are public)")
 	}
@@ -972,7 +971,8 @@ func TestK8sSchema_Functions(t *testing.T) {
 		"get_jobs", "get_cronjobs",
 		"get_nodes", "get_node_detail",
 		"get_services", "get_configmaps", "get_ingresses",
-		"api_request",
+		"api_request", "describe_resource",
+		"restart_deployment",
 	}
 	if len(schema.Functions) != len(expectedFunctions) {
 		t.Fatalf("expected %d functions, got %d", len(expectedFunctions), len(schema.Functions))
@@ -987,8 +987,21 @@ func TestK8sSchema_Functions(t *testing.T) {
 func TestK8sSchema_FunctionCount(t *testing.T) {
 	schema, _ := GetToolSchema("kubernetes")
 
-	if len(schema.Functions) != 17 {
-		t.Errorf("expected 17 functions (all Kubernetes tools), got %d", len(schema.Functions))
+	if len(schema.Functions) != 19 {
+		t.Errorf("expected 19 functions (18 read + 1 write), got %d", len(schema.Functions))
+	}
+}
+
+func TestK8sSchema_WriteGate(t *testing.T) {
+	schema, _ := GetToolSchema("kubernetes")
+
+	if schema.SettingsSchema.Properties["k8s_allow_writes"].Default != false {
+		t.Error("expected k8s_allow_writes to default to false")
+	}
+	for _, fn := range schema.Functions {
+		if fn.Name == "restart_deployment" && !strings.Contains(fn.Description, "k8s_allow_writes") {
+			t.Errorf("restart_deployment description must mention k8s_allow_writes, got: %q", fn.Description)
+		}
 	}
 }
 
@@ -1226,3 +1239,70 @@ func TestK8sSchema_DualBehaviorDescriptions(t *testing.T) {
 		}
 	}
 }
+
+func TestGetToolSchema_Prometheus(t *testing.T) {
+	schema, ok := GetToolSchema("prometheus")
+	if !ok {
+		t.Fatal("prometheus schema not found")
+	}
+	if len(schema.SettingsSchema.Required) != 1 || schema.SettingsSchema.Required[0] != "prom_url" {
+		t.Errorf("expected required [prom_url], got %v", schema.SettingsSchema.Required)
+	}
+	for _, key := range []string{"prom_password", "prom_bearer_token", "prom_client_key"} {
+		if !schema.SettingsSchema.Properties[key].Secret {
+			t.Errorf("expected %s to be secret", key)
+		}
+	}
+	expectedFuncs := []string{"query", "query_range", "series", "label_values"}
+	if len(schema.Functions) != len(expectedFuncs) {
+		t.Fatalf("expected %d functions, got %d", len(expectedFuncs), len(schema.Functions))
+	}
+	for i, name := range expectedFuncs {
+		if schema.Functions[i].Name != name {
+			t.Errorf("expected function[%d] = %q, got %q", i, name, schema.Functions[i].Name)
+		}
+	}
+}
+
+func TestGetToolSchema_LogSearch(t *testing.T) {
+	schema, ok := GetToolSchema("log_search")
+	if !ok {
+		t.Fatal("log_search schema not found")
+	}
+	if strings.Join(schema.SettingsSchema.Required, ",") != "logs_backend,logs_url" {
+		t.Errorf("expected required [logs_backend logs_url], got %v", schema.SettingsSchema.Required)
+	}
+	if got := schema.SettingsSchema.Properties["logs_backend"].Enum; strings.Join(got, ",") != "loki,elasticsearch" {
+		t.Errorf("unexpected logs_backend enum %v", got)
+	}
+	for _, key := range []string{"logs_password", "logs_bearer_token", "logs_api_key"} {
+		if !schema.SettingsSchema.Properties[key].Secret {
+			t.Errorf("expected %s to be secret", key)
+		}
+	}
+	expectedFuncs := []string{"loki_query", "loki_labels", "elasticsearch_search"}
+	if len(schema.Functions) != len(expectedFuncs) {
+		t.Fatalf("expected %d functions, got %d", len(expectedFuncs), len(schema.Functions))
+	}
+	for i, name := range expectedFuncs {
+		if schema.Functions[i].Name != name {
+			t.Errorf("expected function[%d] = %q, got %q", i, name, schema.Functions[i].Name)
+		}
+	}
+}
+
+func TestGetToolSchema_HTTPCheck(t *testing.T) {
+	schema, ok := GetToolSchema("http_check")
+	if !ok {
+		t.Fatal("http_check schema not found")
+	}
+	if strings.Join(schema.SettingsSchema.Required, ",") != "http_check_allowed_urls" {
+		t.Errorf("expected required [http_check_allowed_urls], got %v", schema.SettingsSchema.Required)
+	}
+	if !schema.SettingsSchema.Properties["http_check_headers"].Secret {
+		t.Error("expected http_check_headers to be secret")
+	}
+	if len(schema.Functions) != 1 || schema.Functions[0].Name != "probe" {
+		t.Errorf("expected a single probe function, got %+v", schema.Functions)
+	}
+}
@@ -0,0 +1,158 @@
+package ssh
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// agentSocketEnv names the ssh-agent socket used by ssh_agent_auth. The agent
+// runs outside the gateway; its socket has to be mounted into the container.
+const agentSocketEnv = "SSH_AUTH_SOCK"
+
+// authMethods returns the methods offered to hostConfig, tried by the server
+// in order: the host's private key, keys held by ssh-agent, then password.
+// done must be called once the handshake has finished.
+func (t *SSHTool) authMethods(hostConfig *SSHHostConfig, config *SSHConfig) ([]ssh.AuthMethod, func(), error) {
+	var methods []ssh.AuthMethod
+	done := func() {}
+
+	if len(config.Keys) > 0 {
+		key, err := t.keyForHost(hostConfig, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, err := parsePrivateKey(key.PrivateKey, key.Passphrase)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse private key %q: %w", key.Name, err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if config.AgentAuth {
+		conn, err := dialAgent()
+		switch {
+		case err == nil:
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			done = func() { conn.Close() }
+		case len(methods) == 0 && hostConfig.Password == "":
+			return nil, nil, err
+		default:
+			t.logger.Printf("ssh-agent unavailable for %s, trying other methods: %v", hostConfig.Hostname, err)
+		}
+	}
+
+	if hostConfig.Password != "" {
+		password := hostConfig.Password
+		methods = append(methods,
+			ssh.Password(password),
+			// Servers with PasswordAuthentication off often still accept the
+			// password through a keyboard-interactive prompt.
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	if len(methods) == 0 {
+		done()
+		return nil, nil, fmt.Errorf("no SSH authentication configured for host '%s': add an SSH key, set a password or enable ssh_agent_auth", hostConfig.Hostname)
+	}
+	return methods, done, nil
+}
+
+// dialAgent connects to the ssh-agent named by SSH_AUTH_SOCK.
+func dialAgent() (net.Conn, error) {
+	socket := os.Getenv(agentSocketEnv)
+	if socket == "" {
+		return nil, fmt.Errorf("ssh_agent_auth is enabled but %s is not set in the gateway environment", agentSocketEnv)
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent at %s: %w", socket, err)
+	}
+	return conn, nil
+}
+
+// forwardAgent serves agent requests from the remote side of client, so
+// commands run on a forward_agent host can authenticate onward (git, ssh to
+// a further host) without the key ever being copied there. With
+// ssh_agent_auth the gateway's ssh-agent is forwarded; otherwise an
+// in-memory agent holding only the host's configured key is.
+func (t *SSHTool) forwardAgent(client *ssh.Client, hostConfig *SSHHostConfig, config *SSHConfig) error {
+	if config.AgentAuth && os.Getenv(agentSocketEnv) != "" {
+		return agent.ForwardToRemote(client, os.Getenv(agentSocketEnv))
+	}
+	if len(config.Keys) == 0 {
+		return errors.New("agent forwarding needs an SSH key or ssh_agent_auth")
+	}
+	key, err := t.keyForHost(hostConfig, config)
+	if err != nil {
+		return err
+	}
+	raw, err := parseRawPrivateKey(key.PrivateKey, key.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to parse private key %q: %w", key.Name, err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: raw, Comment: key.Name}); err != nil {
+		return fmt.Errorf("failed to load key into forwarded agent: %w", err)
+	}
+	return agent.ForwardToAgent(client, keyring)
+}
+
+// parsePrivateKey parses a PEM or OpenSSH private key, decrypting it with
+// passphrase when one is set.
+func parsePrivateKey(keyData, passphrase string) (ssh.Signer, error) {
+	keyData = fixPEMKey(keyData)
+
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(keyData), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(keyData))
+	}
+	if err != nil {
+		return nil, describeKeyError(err)
+	}
+	return signer, nil
+}
+
+// parseRawPrivateKey is parsePrivateKey for callers that need the
+// underlying key rather than a signer.
+func parseRawPrivateKey(keyData, passphrase string) (interface{}, error) {
+	keyData = fixPEMKey(keyData)
+
+	var raw interface{}
+	var err error
+	if passphrase != "" {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(keyData), []byte(passphrase))
+	} else {
+		raw, err = ssh.ParseRawPrivateKey([]byte(keyData))
+	}
+	if err != nil {
+		return nil, describeKeyError(err)
+	}
+	return raw, nil
+}
+
+func describeKeyError(err error) error {
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing):
+		return errors.New("key is encrypted and no passphrase is configured")
+	case errors.Is(err, x509.IncorrectPasswordError):
+		return errors.New("passphrase does not decrypt the key")
+	}
+	return err
+}
@@ -0,0 +1,222 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// startAuthSSHServer runs a localhost SSH server that authenticates with
+// config and rejects every channel; connecting is all these tests need.
+func startAuthSSHServer(t *testing.T, config *ssh.ServerConfig) (string, int) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(nc, config)
+				if err != nil {
+					nc.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels in tests")
+				}
+			}()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func newTestKeyPair(t *testing.T) (ed25519.PrivateKey, ssh.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, sshPub
+}
+
+func testAuthConfig(hosts ...SSHHostConfig) *SSHConfig {
+	return &SSHConfig{
+		Hosts:             hosts,
+		Keys:              make(map[string]*SSHKey),
+		ConnectionTimeout: 5,
+		KnownHostsPolicy:  KnownHostsIgnore,
+	}
+}
+
+func TestParsePrivateKey_Passphrase(t *testing.T) {
+	priv, _ := newTestKeyPair(t)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := string(pem.EncodeToMemory(block))
+
+	if _, err := parsePrivateKey(encrypted, "correct horse"); err != nil {
+		t.Fatalf("correct passphrase rejected: %v", err)
+	}
+	if _, err := parseRawPrivateKey(encrypted, "correct horse"); err != nil {
+		t.Fatalf("raw parse with correct passphrase failed: %v", err)
+	}
+	if _, err := parsePrivateKey(encrypted, ""); err == nil || !strings.Contains(err.Error(), "no passphrase") {
+		t.Errorf("missing passphrase: err = %v", err)
+	}
+	if _, err := parsePrivateKey(encrypted, "wrong"); err == nil || !strings.Contains(err.Error(), "does not decrypt") {
+		t.Errorf("wrong passphrase: err = %v", err)
+	}
+}
+
+func TestAuthMethods_RequiresSomeCredential(t *testing.T) {
+	tool := NewSSHTool(log.New(&bytes.Buffer{}, "", 0))
+	host := SSHHostConfig{Hostname: "web-1", Address: "127.0.0.1"}
+
+	if _, _, err := tool.authMethods(&host, testAuthConfig(host)); err == nil || !strings.Contains(err.Error(), "no SSH authentication configured") {
+		t.Fatalf("err = %v, want missing-auth error", err)
+	}
+
+	host.Password = "s3cret"
+	methods, done, err := tool.authMethods(&host, testAuthConfig(host))
+	if err != nil {
+		t.Fatalf("password-only host rejected: %v", err)
+	}
+	done()
+	// Password plus its keyboard-interactive fallback.
+	if len(methods) != 2 {
+		t.Errorf("methods = %d, want 2", len(methods))
+	}
+
+	// An unreachable agent is skipped when another method remains, and is
+	// the error when it was the only one.
+	t.Setenv(agentSocketEnv, filepath.Join(t.TempDir(), "missing.sock"))
+	config := testAuthConfig(host)
+	config.AgentAuth = true
+	if _, done, err := tool.authMethods(&host, config); err != nil {
+		t.Errorf("agent failure should fall back to password: %v", err)
+	} else {
+		done()
+	}
+	host.Password = ""
+	if _, _, err := tool.authMethods(&host, config); err == nil || !strings.Contains(err.Error(), "ssh-agent") {
+		t.Errorf("err = %v, want ssh-agent error", err)
+	}
+}
+
+func TestConnectDirect_PasswordAuth(t *testing.T) {
+	addr, port := startAuthSSHServer(t, &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "ops" && string(password) == "s3cret" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	})
+	tool := NewSSHTool(log.New(&bytes.Buffer{}, "", 0))
+	host := SSHHostConfig{Hostname: "web-1", Address: addr, Port: port, User: "ops", Password: "s3cret"}
+
+	client, err := tool.connect(context.Background(), &host, testAuthConfig(host))
+	if err != nil {
+		t.Fatalf("password login failed: %v", err)
+	}
+	client.Close()
+
+	host.Password = "wrong"
+	if client, err := tool.connect(context.Background(), &host, testAuthConfig(host)); err == nil {
+		client.Close()
+		t.Fatal("expected wrong password to be rejected")
+	}
+}
+
+func TestConnectDirect_EncryptedKeyAndAgentAuth(t *testing.T) {
+	keyPriv, keyPub := newTestKeyPair(t)
+	agentPriv, agentPub := newTestKeyPair(t)
+	addr, port := startAuthSSHServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), keyPub.Marshal()) || bytes.Equal(key.Marshal(), agentPub.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	})
+	tool := NewSSHTool(log.New(&bytes.Buffer{}, "", 0))
+	host := SSHHostConfig{Hostname: "web-1", Address: addr, Port: port, User: "ops"}
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(keyPriv, "", []byte("pass"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := testAuthConfig(host)
+	config.Keys["k1"] = &SSHKey{ID: "k1", Name: "encrypted", PrivateKey: string(pem.EncodeToMemory(block)), Passphrase: "pass"}
+	config.DefaultKeyID = "k1"
+	client, err := tool.connect(context.Background(), &host, config)
+	if err != nil {
+		t.Fatalf("encrypted key login failed: %v", err)
+	}
+	client.Close()
+
+	// Serve an in-memory agent holding the second key on a unix socket.
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: agentPriv}); err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv(agentSocketEnv, socket)
+
+	agentConfig := testAuthConfig(host)
+	agentConfig.AgentAuth = true
+	client, err = tool.connect(context.Background(), &host, agentConfig)
+	if err != nil {
+		t.Fatalf("agent login failed: %v", err)
+	}
+	client.Close()
+}
@@ -0,0 +1,59 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+)
+
+// Change manifest actions, matching the main API's IncidentChangeAction.
+const (
+	changeCreated  = "created"
+	changeModified = "modified"
+	changeCommand  = "command"
+)
+
+// ChangeRecorder appends remote writes to an incident's change manifest.
+type ChangeRecorder interface {
+	Record(ctx context.Context, row *database.IncidentChange) error
+}
+
+// dbChangeRecorder writes to the main API's incident_changes table.
+type dbChangeRecorder struct{}
+
+func (dbChangeRecorder) Record(ctx context.Context, row *database.IncidentChange) error {
+	if database.DB == nil {
+		return errors.New("change recorder unavailable: no database connection")
+	}
+	return database.RecordIncidentChange(ctx, row)
+}
+
+// recordChange puts a write on incidentID's change manifest. Recording is
+// best-effort: the write already happened, so a failure is only logged.
+func (t *SSHTool) recordChange(ctx context.Context, incidentID string, row database.IncidentChange) {
+	if incidentID == "" {
+		return
+	}
+	recorder := t.changes
+	if recorder == nil {
+		recorder = dbChangeRecorder{}
+	}
+	row.IncidentUUID = incidentID
+	if err := recorder.Record(ctx, &row); err != nil {
+		t.logger.Printf("WARN: failed to record %s change on %s for incident %s: %v", row.Action, row.Location, incidentID, err)
+	}
+}
+
+// IsWriteCommand reports whether command is one read-only mode would block,
+// i.e. one that may change the host.
+func IsWriteCommand(command string) bool {
+	return NewCommandValidator().ValidateCommand(command, false) != nil
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+)
+
+type stubChangeRecorder struct {
+	rows []database.IncidentChange
+	err  error
+}
+
+func (s *stubChangeRecorder) Record(_ context.Context, row *database.IncidentChange) error {
+	s.rows = append(s.rows, *row)
+	return s.err
+}
+
+func TestRecordChange(t *testing.T) {
+	var logs strings.Builder
+	recorder := &stubChangeRecorder{}
+	tool := &SSHTool{logger: log.New(&logs, "", 0), changes: recorder}
+	ctx := context.Background()
+
+	tool.recordChange(ctx, "", database.IncidentChange{Location: "web-1", Action: changeCommand})
+	if len(recorder.rows) != 0 {
+		t.Fatalf("a call outside an incident must not be recorded: %+v", recorder.rows)
+	}
+
+	tool.recordChange(ctx, "inc-1", database.IncidentChange{Location: "web-1", Action: changeCommand, Detail: "systemctl restart nginx"})
+	if len(recorder.rows) != 1 || recorder.rows[0].IncidentUUID != "inc-1" || recorder.rows[0].Detail != "systemctl restart nginx" {
+		t.Fatalf("rows = %+v", recorder.rows)
+	}
+
+	recorder.err = errors.New("db down")
+	tool.recordChange(ctx, "inc-1", database.IncidentChange{Location: "web-1", Action: changeCreated, Path: "/tmp/x"})
+	if !strings.Contains(logs.String(), "db down") {
+		t.Errorf("recorder failure not logged: %q", logs.String())
+	}
+}
+
+func TestIsWriteCommand(t *testing.T) {
+	for cmd, want := range map[string]bool{
+		"uptime":                  false,
+		"kubectl get pods":        false,
+		"systemctl restart nginx": true,
+		"echo hi > /etc/motd":     true,
+		"rm -rf /tmp/cache":       true,
+	} {
+		if got := IsWriteCommand(cmd); got != want {
+			t.Errorf("IsWriteCommand(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
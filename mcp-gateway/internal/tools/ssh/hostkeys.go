@@ -0,0 +1,126 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Host key verification policies (ssh_known_hosts_policy).
+const (
+	// KnownHostsStrict only connects to hosts whose key is already pinned.
+	KnownHostsStrict = "strict"
+	// KnownHostsAutoAdd trusts a host on first use: its key is pinned on the
+	// first connection and any later change is refused.
+	KnownHostsAutoAdd = "auto_add"
+	// KnownHostsIgnore skips host key verification.
+	KnownHostsIgnore = "ignore"
+)
+
+// HostKeyStore persists pinned host keys per SSH tool instance.
+type HostKeyStore interface {
+	Lookup(ctx context.Context, toolInstanceID uint, host string) (*database.SSHKnownHost, error)
+	Pin(ctx context.Context, row *database.SSHKnownHost) error
+	Touch(ctx context.Context, id uint) error
+}
+
+// dbHostKeyStore keeps pinned keys in the main API's ssh_known_hosts table.
+type dbHostKeyStore struct{}
+
+var errHostKeyStoreUnavailable = errors.New("host key store unavailable: no database connection")
+
+func (dbHostKeyStore) Lookup(ctx context.Context, toolInstanceID uint, host string) (*database.SSHKnownHost, error) {
+	if database.DB == nil {
+		return nil, errHostKeyStoreUnavailable
+	}
+	return database.GetSSHKnownHost(ctx, toolInstanceID, host)
+}
+
+func (dbHostKeyStore) Pin(ctx context.Context, row *database.SSHKnownHost) error {
+	if database.DB == nil {
+		return errHostKeyStoreUnavailable
+	}
+	return database.PinSSHKnownHost(ctx, row)
+}
+
+func (dbHostKeyStore) Touch(ctx context.Context, id uint) error {
+	if database.DB == nil {
+		return errHostKeyStoreUnavailable
+	}
+	return database.TouchSSHKnownHost(ctx, id)
+}
+
+// hostKeyCallback builds the verification callback for config's policy.
+// Unknown policies are treated as auto_add, the schema default.
+func (t *SSHTool) hostKeyCallback(ctx context.Context, config *SSHConfig) ssh.HostKeyCallback {
+	if config.KnownHostsPolicy == KnownHostsIgnore {
+		return ssh.InsecureIgnoreHostKey()
+	}
+	strict := config.KnownHostsPolicy == KnownHostsStrict
+	store := t.hostKeys
+	if store == nil {
+		store = dbHostKeyStore{}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		host := knownhosts.Normalize(hostname)
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		pinned, err := store.Lookup(ctx, config.InstanceID, host)
+		if err != nil {
+			return fmt.Errorf("host key verification for %s failed: %w", host, err)
+		}
+		if pinned == nil {
+			if strict {
+				return fmt.Errorf("host key for %s (%s %s) is not pinned and ssh_known_hosts_policy is strict", host, key.Type(), fingerprint)
+			}
+			row := &database.SSHKnownHost{
+				ToolInstanceID: config.InstanceID,
+				Host:           host,
+				KeyType:        key.Type(),
+				PublicKey:      encodeHostKey(key),
+				Fingerprint:    fingerprint,
+				LastSeenAt:     time.Now(),
+			}
+			if err := store.Pin(ctx, row); err != nil {
+				return fmt.Errorf("failed to pin host key for %s: %w", host, err)
+			}
+			// A concurrent connection may have pinned a different key first;
+			// re-read so the stored pin is what gets enforced.
+			if pinned, err = store.Lookup(ctx, config.InstanceID, host); err != nil {
+				return fmt.Errorf("failed to confirm pinned host key for %s: %w", host, err)
+			}
+			if pinned == nil {
+				return fmt.Errorf("failed to confirm pinned host key for %s: pin not found after insert", host)
+			}
+			if pinned.ID == row.ID {
+				t.logger.Printf("Pinned host key for %s: %s %s", host, key.Type(), fingerprint)
+				return nil
+			}
+		}
+
+		want, err := base64.StdEncoding.DecodeString(pinned.PublicKey)
+		if err != nil || !bytes.Equal(want, key.Marshal()) {
+			return fmt.Errorf("host key for %s has changed: pinned %s %s, presented %s %s; if the change is expected, clear the pinned key for this host in the SSH tool settings",
+				host, pinned.KeyType, pinned.Fingerprint, key.Type(), fingerprint)
+		}
+		if err := store.Touch(ctx, pinned.ID); err != nil {
+			t.logger.Printf("Failed to update last-seen time for %s: %v", host, err)
+		}
+		return nil
+	}
+}
+
+// encodeHostKey returns the stored form of a host key: its wire format,
+// base64-encoded as in a known_hosts line.
+func encodeHostKey(key ssh.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key.Marshal())
+}
@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"golang.org/x/crypto/ssh"
+)
+
+// memoryHostKeyStore is an in-memory HostKeyStore keyed by instance and host.
+type memoryHostKeyStore struct {
+	mu      sync.Mutex
+	rows    map[string]*database.SSHKnownHost
+	nextID  uint
+	touched int
+}
+
+func newMemoryHostKeyStore() *memoryHostKeyStore {
+	return &memoryHostKeyStore{rows: make(map[string]*database.SSHKnownHost)}
+}
+
+func (m *memoryHostKeyStore) key(id uint, host string) string {
+	return fmt.Sprintf("%d|%s", id, host)
+}
+
+func (m *memoryHostKeyStore) Lookup(_ context.Context, id uint, host string) (*database.SSHKnownHost, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if row, ok := m.rows[m.key(id, host)]; ok {
+		copied := *row
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryHostKeyStore) Pin(_ context.Context, row *database.SSHKnownHost) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := m.key(row.ToolInstanceID, row.Host)
+	if _, ok := m.rows[k]; ok {
+		return nil
+	}
+	m.nextID++
+	row.ID = m.nextID
+	copied := *row
+	m.rows[k] = &copied
+	return nil
+}
+
+func (m *memoryHostKeyStore) Touch(context.Context, uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.touched++
+	return nil
+}
+
+func newHostPublicKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestHostKeyCallback_AutoAddPinsAndRejectsChanges(t *testing.T) {
+	store := newMemoryHostKeyStore()
+	tool := &SSHTool{logger: log.New(&strings.Builder{}, "", 0), hostKeys: store}
+	cb := tool.hostKeyCallback(context.Background(), &SSHConfig{KnownHostsPolicy: KnownHostsAutoAdd, InstanceID: 7})
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 5), Port: 2222}
+	original := newHostPublicKey(t)
+
+	if err := cb("web-1:2222", addr, original); err != nil {
+		t.Fatalf("first connection: %v", err)
+	}
+	pinned, _ := store.Lookup(context.Background(), 7, "[web-1]:2222")
+	if pinned == nil || pinned.Fingerprint != ssh.FingerprintSHA256(original) {
+		t.Fatalf("pinned = %+v, want key pinned under the known_hosts host form", pinned)
+	}
+
+	if err := cb("web-1:2222", addr, original); err != nil {
+		t.Fatalf("reconnect with same key: %v", err)
+	}
+	if store.touched != 1 {
+		t.Errorf("touched = %d, want last-seen updated on reconnect", store.touched)
+	}
+
+	err := cb("web-1:2222", addr, newHostPublicKey(t))
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("changed key error = %v, want rejection", err)
+	}
+
+	// Pins are per tool instance.
+	other := tool.hostKeyCallback(context.Background(), &SSHConfig{KnownHostsPolicy: KnownHostsAutoAdd, InstanceID: 8})
+	if err := other("web-1:2222", addr, newHostPublicKey(t)); err != nil {
+		t.Errorf("other instance first connection: %v", err)
+	}
+}
+
+func TestHostKeyCallback_Strict(t *testing.T) {
+	store := newMemoryHostKeyStore()
+	tool := &SSHTool{logger: log.New(&strings.Builder{}, "", 0), hostKeys: store}
+	cb := tool.hostKeyCallback(context.Background(), &SSHConfig{KnownHostsPolicy: KnownHostsStrict, InstanceID: 1})
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 6), Port: 22}
+	key := newHostPublicKey(t)
+
+	if err := cb("db-1:22", addr, key); err == nil || !strings.Contains(err.Error(), "not pinned") {
+		t.Fatalf("unknown host error = %v, want strict rejection", err)
+	}
+	if pinned, _ := store.Lookup(context.Background(), 1, "db-1"); pinned != nil {
+		t.Fatal("strict policy pinned an unknown host")
+	}
+
+	_ = store.Pin(context.Background(), &database.SSHKnownHost{
+		ToolInstanceID: 1, Host: "db-1", KeyType: key.Type(),
+		PublicKey: encodeHostKey(key), Fingerprint: ssh.FingerprintSHA256(key),
+	})
+	if err := cb("db-1:22", addr, key); err != nil {
+		t.Errorf("pinned host: %v", err)
+	}
+}
+
+func TestHostKeyCallback_Ignore(t *testing.T) {
+	store := newMemoryHostKeyStore()
+	tool := &SSHTool{logger: log.New(&strings.Builder{}, "", 0), hostKeys: store}
+	cb := tool.hostKeyCallback(context.Background(), &SSHConfig{KnownHostsPolicy: KnownHostsIgnore})
+
+	if err := cb("any:22", &net.TCPAddr{}, newHostPublicKey(t)); err != nil {
+		t.Fatalf("ignore policy: %v", err)
+	}
+	if len(store.rows) != 0 {
+		t.Error("ignore policy touched the store")
+	}
+}
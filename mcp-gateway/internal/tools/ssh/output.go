@@ -0,0 +1,66 @@
+package ssh
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Output limits for execute_command. The cap applies to stdout and stderr
+// separately; bytes beyond it are counted but not kept.
+const (
+	DefaultMaxOutputBytes = 1024 * 1024
+	MinMaxOutputBytes     = 1024
+	MaxMaxOutputBytes     = 16 * 1024 * 1024
+	// MaxCommandTimeout is the longest a single command may run, whether set
+	// by ssh_command_timeout or a per-call timeout_seconds.
+	MaxCommandTimeout = 600
+)
+
+// ExecOptions controls a single execute_command call.
+type ExecOptions struct {
+	// Stream sends stdout/stderr chunks as MCP progress notifications while
+	// the command runs, when the caller supplied a progress token.
+	Stream bool
+	// TimeoutSeconds overrides ssh_command_timeout for this call (0 = use
+	// the instance setting). Capped at MaxCommandTimeout.
+	TimeoutSeconds int
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the
+// rest. It is safe to read while the command is still writing, which is
+// how partial output is recovered when a command times out.
+type cappedBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	limit   int
+	total   int64
+	onWrite func([]byte)
+}
+
+func newCappedBuffer(limit int, onWrite func([]byte)) *cappedBuffer {
+	return &cappedBuffer{limit: limit, onWrite: onWrite}
+}
+
+// Write never fails, so a noisy command is not killed by a broken pipe
+// once the cap is reached.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	b.total += int64(len(p))
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(room, len(p))])
+	}
+	b.mu.Unlock()
+
+	if b.onWrite != nil && len(p) > 0 {
+		b.onWrite(p)
+	}
+	return len(p), nil
+}
+
+// snapshot returns the kept output, the total bytes written and whether
+// anything was dropped.
+func (b *cappedBuffer) snapshot() (string, int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String(), b.total, b.total > int64(b.buf.Len())
+}
@@ -0,0 +1,65 @@
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/akmatori/mcp-gateway/internal/mcp"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	var streamed strings.Builder
+	buf := newCappedBuffer(8, func(p []byte) { streamed.Write(p) })
+
+	for _, chunk := range []string{"hello ", "world", "!!!"} {
+		if n, err := buf.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+
+	out, total, truncated := buf.snapshot()
+	if out != "hello wo" || total != 14 || !truncated {
+		t.Errorf("snapshot = %q, %d, %v; want first 8 bytes of 14, truncated", out, total, truncated)
+	}
+	// Streaming is not subject to the cap.
+	if streamed.String() != "hello world!!!" {
+		t.Errorf("streamed = %q", streamed.String())
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	config := &SSHConfig{CommandTimeout: 30}
+	tests := []struct {
+		name string
+		opts ExecOptions
+		want int
+	}{
+		{"instance setting", ExecOptions{}, 30},
+		{"per-call override", ExecOptions{TimeoutSeconds: 300}, 300},
+		{"capped", ExecOptions{TimeoutSeconds: 5000}, MaxCommandTimeout},
+	}
+	for _, tt := range tests {
+		if got := commandTimeout(config, tt.opts); got != tt.want {
+			t.Errorf("%s: commandTimeout = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStreamChunk(t *testing.T) {
+	tool := NewSSHTool(nil)
+	if tool.streamChunk(context.Background(), ExecOptions{Stream: true}, "web-1", "stdout") != nil {
+		t.Error("expected no stream hook without a progress listener")
+	}
+
+	var messages []string
+	ctx := mcp.WithProgressReporter(context.Background(), func(m string) { messages = append(messages, m) })
+	if tool.streamChunk(ctx, ExecOptions{}, "web-1", "stdout") != nil {
+		t.Error("expected no stream hook when streaming was not requested")
+	}
+	hook := tool.streamChunk(ctx, ExecOptions{Stream: true}, "web-1", "stderr")
+	hook([]byte("disk full\n"))
+	if len(messages) != 1 || messages[0] != "[web-1 stderr] disk full\n" {
+		t.Errorf("messages = %q", messages)
+	}
+}
@@ -0,0 +1,204 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Pool and parallelism defaults (ssh_connection_idle_timeout, ssh_max_parallel).
+const (
+	DefaultIdleTimeout = 300
+	MaxIdleTimeout     = 3600
+	DefaultMaxParallel = 10
+	MaxMaxParallel     = 100
+)
+
+// poolKey identifies a reusable connection. Connections are never shared
+// across incidents or tool instances, so credentials and allowlists resolved
+// for one investigation cannot leak into another.
+type poolKey struct {
+	incidentID string
+	instanceID uint
+	address    string
+	port       int
+	user       string
+	keyID      string
+	jumphost   string
+	jumpPort   int
+	jumpUser   string
+}
+
+func newPoolKey(incidentID string, host *SSHHostConfig, config *SSHConfig) poolKey {
+	return poolKey{
+		incidentID: incidentID,
+		instanceID: config.InstanceID,
+		address:    stripBrackets(host.Address),
+		port:       host.Port,
+		user:       host.User,
+		keyID:      host.KeyID,
+		jumphost:   host.JumphostAddress,
+		jumpPort:   host.JumphostPort,
+		jumpUser:   host.JumphostUser,
+	}
+}
+
+// connPool keeps SSH connections open between calls so an investigation
+// that runs many commands against the same hosts pays the handshake once.
+// An SSH connection multiplexes sessions, so one pooled connection serves
+// concurrent commands. Connections are closed after sitting unused for the
+// instance's idle timeout.
+type connPool struct {
+	mu    sync.Mutex
+	conns map[poolKey]*pooledConn
+}
+
+type pooledConn struct {
+	client *ssh.Client
+	refs   int
+	timer  *time.Timer
+}
+
+func newConnPool() *connPool {
+	return &connPool{conns: make(map[poolKey]*pooledConn)}
+}
+
+// acquire returns a live connection for key, dialing one if none is pooled.
+// The caller must call the returned release exactly once; passing broken
+// closes the connection instead of returning it to the pool.
+func (p *connPool) acquire(key poolKey, idle time.Duration, dial func() (*ssh.Client, error)) (*ssh.Client, func(broken bool), error) {
+	if idle <= 0 {
+		client, err := dial()
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func(bool) { client.Close() }, nil
+	}
+
+	if pc := p.checkout(key); pc != nil {
+		// A pooled connection may have been dropped by the server or a
+		// middlebox since it was last used.
+		if _, _, err := pc.client.SendRequest("keepalive@openssh.com", true, nil); err == nil {
+			return pc.client, p.releaser(key, pc, idle), nil
+		}
+		p.evict(key, pc)
+	}
+
+	client, err := dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	pc := &pooledConn{client: client, refs: 1}
+	p.mu.Lock()
+	if _, exists := p.conns[key]; !exists {
+		p.conns[key] = pc
+	}
+	p.mu.Unlock()
+	return client, p.releaser(key, pc, idle), nil
+}
+
+// checkout takes a reference on the pooled connection for key, if any.
+func (p *connPool) checkout(key poolKey) *pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pc, ok := p.conns[key]
+	if !ok {
+		return nil
+	}
+	pc.refs++
+	if pc.timer != nil {
+		pc.timer.Stop()
+		pc.timer = nil
+	}
+	return pc
+}
+
+func (p *connPool) releaser(key poolKey, pc *pooledConn, idle time.Duration) func(broken bool) {
+	var once sync.Once
+	return func(broken bool) {
+		once.Do(func() {
+			if broken {
+				p.evict(key, pc)
+				return
+			}
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			pc.refs--
+			if pc.refs > 0 {
+				return
+			}
+			if p.conns[key] != pc {
+				// Evicted while in use, or a concurrent dial won the slot.
+				pc.client.Close()
+				return
+			}
+			pc.timer = time.AfterFunc(idle, func() { p.expire(key, pc) })
+		})
+	}
+}
+
+// expire closes pc if it is still pooled and unused.
+func (p *connPool) expire(key poolKey, pc *pooledConn) {
+	p.mu.Lock()
+	if p.conns[key] != pc || pc.refs > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.conns, key)
+	p.mu.Unlock()
+	pc.client.Close()
+}
+
+// evict removes pc from the pool and closes it. Other holders see their
+// sessions fail and retry on a fresh connection on their next call.
+func (p *connPool) evict(key poolKey, pc *pooledConn) {
+	p.mu.Lock()
+	if p.conns[key] == pc {
+		delete(p.conns, key)
+	}
+	if pc.timer != nil {
+		pc.timer.Stop()
+		pc.timer = nil
+	}
+	p.mu.Unlock()
+	pc.client.Close()
+}
+
+// size returns the number of pooled connections.
+func (p *connPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// instanceLimiter caps how many hosts one SSH tool instance works on at
+// once, across all concurrent calls.
+type instanceLimiter struct {
+	mu    sync.Mutex
+	slots map[uint]chan struct{}
+}
+
+func newInstanceLimiter() *instanceLimiter {
+	return &instanceLimiter{slots: make(map[uint]chan struct{})}
+}
+
+// acquire blocks until instanceID has a free slot or ctx ends. A changed
+// limit takes effect for calls that start after the change.
+func (l *instanceLimiter) acquire(ctx context.Context, instanceID uint, limit int) (func(), error) {
+	l.mu.Lock()
+	sem, ok := l.slots[instanceID]
+	if !ok || cap(sem) != limit {
+		sem = make(chan struct{}, limit)
+		l.slots[instanceID] = sem
+	}
+	l.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
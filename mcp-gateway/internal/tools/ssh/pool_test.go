@@ -0,0 +1,186 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSSHServer accepts unauthenticated SSH connections on localhost
+// and answers global requests, which is all the pool needs.
+func startTestSSHServer(t *testing.T) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(nc, config)
+				if err != nil {
+					return
+				}
+				go func() {
+					for req := range reqs {
+						if req.WantReply {
+							req.Reply(true, nil)
+						}
+					}
+				}()
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels in tests")
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func countingDialer(addr string, dials *atomic.Int32) func() (*ssh.Client, error) {
+	return func() (*ssh.Client, error) {
+		dials.Add(1)
+		return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+			User:            "test",
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         5 * time.Second,
+		})
+	}
+}
+
+func TestConnPool_ReusesConnectionWithinIncident(t *testing.T) {
+	addr := startTestSSHServer(t)
+	pool := newConnPool()
+	var dials atomic.Int32
+	dial := countingDialer(addr, &dials)
+	key := poolKey{incidentID: "inc-1", address: addr}
+
+	c1, release1, err := pool.acquire(key, time.Minute, dial)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	c2, release2, err := pool.acquire(key, time.Minute, dial)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if c1 != c2 || dials.Load() != 1 {
+		t.Fatalf("expected concurrent acquires to share one connection, got %d dials", dials.Load())
+	}
+	release1(false)
+	release2(false)
+
+	// Another incident gets its own connection.
+	other := key
+	other.incidentID = "inc-2"
+	_, release3, err := pool.acquire(other, time.Minute, dial)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release3(false)
+	if dials.Load() != 2 || pool.size() != 2 {
+		t.Errorf("dials = %d, pooled = %d; want 2 and 2", dials.Load(), pool.size())
+	}
+}
+
+func TestConnPool_BrokenAndIdleConnectionsAreClosed(t *testing.T) {
+	addr := startTestSSHServer(t)
+	pool := newConnPool()
+	var dials atomic.Int32
+	dial := countingDialer(addr, &dials)
+	key := poolKey{incidentID: "inc-1", address: addr}
+
+	_, release, err := pool.acquire(key, time.Minute, dial)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release(true)
+	if pool.size() != 0 {
+		t.Fatalf("broken connection was kept in the pool")
+	}
+
+	_, release, err = pool.acquire(key, 20*time.Millisecond, dial)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release(false)
+	if pool.size() != 1 {
+		t.Fatalf("released connection was not pooled")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.size() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pool.size() != 0 {
+		t.Error("idle connection was not closed after the idle timeout")
+	}
+	if dials.Load() != 2 {
+		t.Errorf("dials = %d, want 2", dials.Load())
+	}
+}
+
+func TestConnPool_DisabledWithZeroIdleTimeout(t *testing.T) {
+	addr := startTestSSHServer(t)
+	pool := newConnPool()
+	var dials atomic.Int32
+	dial := countingDialer(addr, &dials)
+
+	for range 2 {
+		_, release, err := pool.acquire(poolKey{address: addr}, 0, dial)
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release(false)
+	}
+	if dials.Load() != 2 || pool.size() != 0 {
+		t.Errorf("dials = %d, pooled = %d; want a fresh connection per call", dials.Load(), pool.size())
+	}
+}
+
+func TestInstanceLimiter(t *testing.T) {
+	limiter := newInstanceLimiter()
+	done, err := limiter.acquire(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, 1, 1); err == nil {
+		t.Fatal("second acquire on a full instance should wait for a slot")
+	}
+	// Instances do not share slots.
+	other, err := limiter.acquire(context.Background(), 2, 1)
+	if err != nil {
+		t.Fatalf("acquire other instance: %v", err)
+	}
+	other()
+
+	done()
+	again, err := limiter.acquire(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	again()
+}
@@ -0,0 +1,362 @@
+package ssh
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/pkg/sftp"
+)
+
+// File transfer limits. Reads default to a size that fits comfortably in an
+// agent turn; callers page through larger files with offset or tail.
+const (
+	DefaultReadBytes = 256 * 1024
+	MaxReadBytes     = 4 * 1024 * 1024
+	MaxWriteBytes    = 1024 * 1024
+	MaxDirEntries    = 1000
+	defaultWriteMode = 0o644
+)
+
+// ReadFileOptions controls which part of a remote file ReadFile returns.
+type ReadFileOptions struct {
+	Offset   int64 // Byte offset to start reading from
+	MaxBytes int64 // Bytes to read (default DefaultReadBytes, capped at MaxReadBytes)
+	Tail     bool  // Read the last MaxBytes of the file instead of starting at Offset
+}
+
+// WriteFileOptions controls how WriteFile creates or updates a remote file.
+type WriteFileOptions struct {
+	Mode   os.FileMode // Permissions for newly created files (default 0644)
+	Append bool        // Append to the file instead of truncating it
+}
+
+// FileContentResult is the result of a read_file call.
+type FileContentResult struct {
+	Server    string `json:"server"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Offset    int64  `json:"offset"`
+	BytesRead int    `json:"bytes_read"`
+	Truncated bool   `json:"truncated"`
+	Encoding  string `json:"encoding"` // "utf-8" or "base64"
+	Content   string `json:"content"`
+	ModTime   string `json:"mod_time,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// FileWriteResult is the result of a write_file call.
+type FileWriteResult struct {
+	Server       string `json:"server"`
+	Path         string `json:"path"`
+	BytesWritten int    `json:"bytes_written"`
+	Appended     bool   `json:"appended"`
+	Error        string `json:"error,omitempty"`
+}
+
+// DirEntry describes one entry of a list_dir result.
+type DirEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // "file", "dir", "symlink" or "other"
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode"`
+	ModTime string `json:"mod_time"`
+}
+
+// DirListingResult is the result of a list_dir call.
+type DirListingResult struct {
+	Server    string     `json:"server"`
+	Path      string     `json:"path"`
+	Entries   []DirEntry `json:"entries"`
+	Total     int        `json:"total"`
+	Truncated bool       `json:"truncated"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ReadFile reads a file from a single server over SFTP. Text files are
+// returned as-is; binary content is base64-encoded.
+// If instanceID is provided, credentials are resolved for that specific tool instance.
+func (t *SSHTool) ReadFile(ctx context.Context, incidentID, server, filePath string, opts ReadFileOptions, instanceID *uint, logicalName ...string) (string, error) {
+	result := FileContentResult{Server: server, Path: filePath}
+	if strings.TrimSpace(filePath) == "" {
+		result.Error = "path is required"
+		return t.jsonResult(result)
+	}
+
+	err := t.withSFTP(ctx, incidentID, server, instanceID, logicalName, nil, func(client *sftp.Client, host *SSHHostConfig) error {
+		result.Server = host.Hostname
+		return readRemoteFile(client, filePath, opts, &result)
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return t.jsonResult(result)
+}
+
+// WriteFile writes content to a file on a single server over SFTP. It is
+// only allowed on hosts with allow_write_commands enabled.
+// If instanceID is provided, credentials are resolved for that specific tool instance.
+func (t *SSHTool) WriteFile(ctx context.Context, incidentID, server, filePath, content string, opts WriteFileOptions, instanceID *uint, logicalName ...string) (string, error) {
+	result := FileWriteResult{Server: server, Path: filePath, Appended: opts.Append}
+	if strings.TrimSpace(filePath) == "" {
+		result.Error = "path is required"
+		return t.jsonResult(result)
+	}
+	if len(content) > MaxWriteBytes {
+		result.Error = fmt.Sprintf("content exceeds the %d byte write limit", MaxWriteBytes)
+		return t.jsonResult(result)
+	}
+
+	precheck := func(config *SSHConfig, host *SSHHostConfig) error {
+		result.Server = host.Hostname
+		if !host.AllowWriteCommands {
+			return fmt.Errorf("writing files is not allowed on %s: allow_write_commands is disabled for this host", host.Hostname)
+		}
+		return nil
+	}
+	err := t.withSFTP(ctx, incidentID, server, instanceID, logicalName, precheck, func(client *sftp.Client, host *SSHHostConfig) error {
+		_, statErr := client.Stat(filePath)
+		n, err := writeRemoteFile(client, filePath, content, opts)
+		result.BytesWritten = n
+		if n > 0 || err == nil {
+			change := database.IncidentChange{
+				Location:  host.Hostname,
+				Path:      filePath,
+				Action:    changeModified,
+				Tool:      "ssh.write_file",
+				SizeBytes: int64(n),
+			}
+			if statErr != nil {
+				change.Action = changeCreated
+			}
+			if opts.Append {
+				change.Detail = "appended"
+			} else {
+				change.SHA256 = sha256Hex(content)
+			}
+			t.recordChange(ctx, incidentID, change)
+		}
+		return err
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return t.jsonResult(result)
+}
+
+// ListDir lists a directory on a single server over SFTP, sorted by name.
+// If instanceID is provided, credentials are resolved for that specific tool instance.
+func (t *SSHTool) ListDir(ctx context.Context, incidentID, server, dirPath string, instanceID *uint, logicalName ...string) (string, error) {
+	if strings.TrimSpace(dirPath) == "" {
+		dirPath = "."
+	}
+	result := DirListingResult{Server: server, Path: dirPath, Entries: []DirEntry{}}
+
+	err := t.withSFTP(ctx, incidentID, server, instanceID, logicalName, nil, func(client *sftp.Client, host *SSHHostConfig) error {
+		result.Server = host.Hostname
+		return listRemoteDir(client, dirPath, &result)
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return t.jsonResult(result)
+}
+
+// withSFTP resolves a single target server, opens an SFTP session on it and
+// runs fn. precheck, when set, runs on the resolved host before connecting
+// and aborts on error. The SFTP session ends when fn returns; the connection
+// is closed instead of pooled if the command timeout (or ctx) expires first.
+func (t *SSHTool) withSFTP(ctx context.Context, incidentID, server string, instanceID *uint, logicalName []string, precheck func(*SSHConfig, *SSHHostConfig) error, fn func(*sftp.Client, *SSHHostConfig) error) error {
+	config, err := t.getConfig(ctx, incidentID, instanceID, logicalName...)
+	if err != nil {
+		return err
+	}
+	if len(config.Keys) == 0 {
+		return errors.New("SSH private key not configured")
+	}
+	if strings.TrimSpace(server) == "" {
+		return errors.New("server is required")
+	}
+	hosts, err := t.resolveTargetHosts([]string{server}, config)
+	if err != nil {
+		return err
+	}
+	host := &hosts[0]
+	if precheck != nil {
+		if err := precheck(config, host); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.CommandTimeout)*time.Second)
+	defer cancel()
+
+	conn, release, err := t.acquireConn(ctx, incidentID, host, config)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	// Hitting the deadline discards the connection, which unblocks any
+	// in-flight SFTP request; otherwise it goes back to the pool.
+	stop := context.AfterFunc(ctx, func() { release(true) })
+	defer func() {
+		if stop() {
+			release(false)
+		}
+	}()
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		return fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+	defer client.Close()
+
+	if err := fn(client, host); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("file transfer timed out: %w", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// readRemoteFile fills result with the requested window of filePath.
+func readRemoteFile(client *sftp.Client, filePath string, opts ReadFileOptions, result *FileContentResult) error {
+	f, err := client.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", filePath, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory; use list_dir", filePath)
+	}
+	result.Size = info.Size()
+	result.ModTime = info.ModTime().UTC().Format(time.RFC3339)
+
+	limit := opts.MaxBytes
+	if limit <= 0 {
+		limit = DefaultReadBytes
+	}
+	if limit > MaxReadBytes {
+		limit = MaxReadBytes
+	}
+	offset := opts.Offset
+	if opts.Tail {
+		offset = max(info.Size()-limit, 0)
+	}
+	if offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek %s: %w", filePath, err)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filePath, err)
+	}
+	result.Offset = offset
+	result.BytesRead = len(buf)
+	// Truncated reports content on either side of the returned window.
+	result.Truncated = offset > 0 || offset+int64(len(buf)) < info.Size()
+	if utf8.Valid(buf) {
+		result.Encoding = "utf-8"
+		result.Content = string(buf)
+	} else {
+		result.Encoding = "base64"
+		result.Content = base64.StdEncoding.EncodeToString(buf)
+	}
+	return nil
+}
+
+// writeRemoteFile writes content to filePath, creating it if necessary.
+func writeRemoteFile(client *sftp.Client, filePath, content string, opts WriteFileOptions) (int, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if opts.Append {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	f, err := client.OpenFile(filePath, flags)
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", filePath, err)
+	}
+	if opts.Append {
+		// SFTP writes carry an explicit offset, so O_APPEND alone is not
+		// honoured by every server.
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return 0, fmt.Errorf("seek %s: %w", filePath, err)
+		}
+	}
+	n, err := f.Write([]byte(content))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, fmt.Errorf("write %s: %w", filePath, err)
+	}
+
+	mode := opts.Mode
+	if mode == 0 {
+		mode = defaultWriteMode
+	}
+	if !opts.Append {
+		if err := client.Chmod(filePath, mode); err != nil {
+			return n, fmt.Errorf("chmod %s: %w", filePath, err)
+		}
+	}
+	return n, nil
+}
+
+// listRemoteDir fills result with the entries of dirPath.
+func listRemoteDir(client *sftp.Client, dirPath string, result *DirListingResult) error {
+	infos, err := client.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", dirPath, err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	result.Total = len(infos)
+	if len(infos) > MaxDirEntries {
+		infos = infos[:MaxDirEntries]
+		result.Truncated = true
+	}
+	result.Entries = make([]DirEntry, 0, len(infos))
+	for _, info := range infos {
+		result.Entries = append(result.Entries, DirEntry{
+			Name:    path.Base(info.Name()),
+			Type:    fileType(info.Mode()),
+			Size:    info.Size(),
+			Mode:    info.Mode().Perm().String(),
+			ModTime: info.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+	return nil
+}
+
+func fileType(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
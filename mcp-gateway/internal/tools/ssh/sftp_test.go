@@ -0,0 +1,166 @@
+package ssh
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// newPipeSFTPClient connects an SFTP client to an in-process server that
+// serves the local filesystem.
+func newPipeSFTPClient(t *testing.T) *sftp.Client {
+	t.Helper()
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverRead, serverWrite})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientRead, clientWrite)
+	if err != nil {
+		t.Fatalf("NewClientPipe: %v", err)
+	}
+	t.Cleanup(func() {
+		// Closing the server side first lets the client's reader see EOF.
+		server.Close()
+		client.Close()
+	})
+	return client
+}
+
+func TestReadRemoteFile(t *testing.T) {
+	client := newPipeSFTPClient(t)
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("line1\nline2\nline3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          ReadFileOptions
+		wantContent   string
+		wantOffset    int64
+		wantTruncated bool
+	}{
+		{"whole file", ReadFileOptions{}, "line1\nline2\nline3\n", 0, false},
+		{"window", ReadFileOptions{Offset: 6, MaxBytes: 6}, "line2\n", 6, true},
+		{"tail", ReadFileOptions{MaxBytes: 6, Tail: true}, "line3\n", 12, true},
+		{"tail larger than file", ReadFileOptions{MaxBytes: 100, Tail: true}, "line1\nline2\nline3\n", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result FileContentResult
+			if err := readRemoteFile(client, logPath, tt.opts, &result); err != nil {
+				t.Fatalf("readRemoteFile: %v", err)
+			}
+			if result.Content != tt.wantContent || result.Offset != tt.wantOffset || result.Truncated != tt.wantTruncated {
+				t.Errorf("got content=%q offset=%d truncated=%v", result.Content, result.Offset, result.Truncated)
+			}
+			if result.Size != 18 || result.Encoding != "utf-8" {
+				t.Errorf("size=%d encoding=%s", result.Size, result.Encoding)
+			}
+		})
+	}
+}
+
+func TestReadRemoteFile_BinaryAndErrors(t *testing.T) {
+	client := newPipeSFTPClient(t)
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "core.bin")
+	if err := os.WriteFile(binPath, []byte{0xff, 0xfe, 0x00, 0x01}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var result FileContentResult
+	if err := readRemoteFile(client, binPath, ReadFileOptions{}, &result); err != nil {
+		t.Fatalf("readRemoteFile: %v", err)
+	}
+	if result.Encoding != "base64" || result.Content != base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00, 0x01}) {
+		t.Errorf("binary result = %+v", result)
+	}
+
+	if err := readRemoteFile(client, dir, ReadFileOptions{}, &FileContentResult{}); err == nil || !strings.Contains(err.Error(), "list_dir") {
+		t.Errorf("expected directory error, got %v", err)
+	}
+	if err := readRemoteFile(client, filepath.Join(dir, "missing"), ReadFileOptions{}, &FileContentResult{}); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestWriteRemoteFile(t *testing.T) {
+	client := newPipeSFTPClient(t)
+	target := filepath.Join(t.TempDir(), "app.conf")
+
+	if _, err := writeRemoteFile(client, target, "a=1\n", WriteFileOptions{Mode: 0o600}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	n, err := writeRemoteFile(client, target, "b=2\n", WriteFileOptions{Append: true})
+	if err != nil || n != 4 {
+		t.Fatalf("append: n=%d err=%v", n, err)
+	}
+
+	data, _ := os.ReadFile(target)
+	if string(data) != "a=1\nb=2\n" {
+		t.Errorf("content = %q", data)
+	}
+	info, _ := os.Stat(target)
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if _, err := writeRemoteFile(client, target, "c=3\n", WriteFileOptions{}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	data, _ = os.ReadFile(target)
+	if string(data) != "c=3\n" {
+		t.Errorf("content after overwrite = %q", data)
+	}
+}
+
+func TestListRemoteDir(t *testing.T) {
+	client := newPipeSFTPClient(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.log"), []byte("xx"), 0o644)
+	os.Mkdir(filepath.Join(dir, "a-dir"), 0o755)
+	os.Symlink(filepath.Join(dir, "b.log"), filepath.Join(dir, "c-link"))
+
+	var result DirListingResult
+	if err := listRemoteDir(client, dir, &result); err != nil {
+		t.Fatalf("listRemoteDir: %v", err)
+	}
+	if result.Total != 3 || result.Truncated {
+		t.Fatalf("result = %+v", result)
+	}
+	want := []struct{ name, typ string }{{"a-dir", "dir"}, {"b.log", "file"}, {"c-link", "symlink"}}
+	for i, w := range want {
+		if result.Entries[i].Name != w.name || result.Entries[i].Type != w.typ {
+			t.Errorf("entry %d = %+v, want %s (%s)", i, result.Entries[i], w.name, w.typ)
+		}
+	}
+	if result.Entries[1].Size != 2 || result.Entries[1].Mode != "-rw-r--r--" {
+		t.Errorf("file entry = %+v", result.Entries[1])
+	}
+}
+
+func TestWriteFile_RejectsOversizedContent(t *testing.T) {
+	tool := NewSSHTool(nil)
+	out, err := tool.WriteFile(t.Context(), "", "web-1", "/tmp/x", strings.Repeat("x", MaxWriteBytes+1), WriteFileOptions{}, nil)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if !strings.Contains(out, "write limit") {
+		t.Errorf("result = %s", out)
+	}
+}
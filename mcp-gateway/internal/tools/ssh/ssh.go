@@ -12,17 +12,27 @@ import (
 	"time"
 
 	"github.com/akmatori/mcp-gateway/internal/database"
+	"github.com/akmatori/mcp-gateway/internal/mcp"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 // SSHTool handles SSH operations
 type SSHTool struct {
-	logger *log.Logger
+	logger   *log.Logger
+	hostKeys HostKeyStore   // nil = database-backed store
+	changes  ChangeRecorder // nil = database-backed recorder
+	pool     *connPool
+	limiter  *instanceLimiter
 }
 
 // NewSSHTool creates a new SSH tool
 func NewSSHTool(logger *log.Logger) *SSHTool {
-	return &SSHTool{logger: logger}
+	return &SSHTool{
+		logger:  logger,
+		pool:    newConnPool(),
+		limiter: newInstanceLimiter(),
+	}
 }
 
 // SSHKey holds an SSH private key with metadata
@@ -30,6 +40,7 @@ type SSHKey struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
 	PrivateKey string `json:"private_key"`
+	Passphrase string `json:"passphrase,omitempty"` // Decrypts an encrypted private key
 	IsDefault  bool   `json:"is_default"`
 	CreatedAt  string `json:"created_at"`
 }
@@ -45,6 +56,8 @@ type SSHHostConfig struct {
 	JumphostUser       string `json:"jumphost_user,omitempty"`        // Jumphost username
 	JumphostPort       int    `json:"jumphost_port,omitempty"`        // Jumphost port (default: 22)
 	AllowWriteCommands bool   `json:"allow_write_commands,omitempty"` // Allow write/destructive commands (default: false)
+	Password           string `json:"password,omitempty"`             // Password auth for the target host, tried after keys
+	ForwardAgent       bool   `json:"forward_agent,omitempty"`        // Forward an ssh-agent to commands run on this host
 }
 
 // SSHConfig holds SSH connection configuration
@@ -65,7 +78,14 @@ type SSHConfig struct {
 	// Global settings
 	CommandTimeout    int
 	ConnectionTimeout int
-	KnownHostsPolicy  string
+	KnownHostsPolicy  string // strict, auto_add or ignore; see hostkeys.go
+	AgentAuth         bool   // Also offer keys from the ssh-agent at SSH_AUTH_SOCK
+	MaxOutputBytes    int    // per-stream output cap for execute_command
+	MaxParallel       int    // hosts worked on at once per instance
+	IdleTimeout       int    // seconds a pooled connection may sit unused (0 = no pooling)
+
+	// Tool instance the settings came from; host keys are pinned per instance
+	InstanceID uint
 }
 
 // ServerResult represents the result of a command on a single server
@@ -77,6 +97,17 @@ type ServerResult struct {
 	ExitCode   int    `json:"exit_code"`
 	DurationMs int64  `json:"duration_ms"`
 	Error      string `json:"error,omitempty"`
+
+	// Output accounting. The *_bytes fields count everything the command
+	// wrote; when they exceed the output cap, the kept prefix is returned
+	// and the matching *_truncated flag is set.
+	StdoutBytes     int64 `json:"stdout_bytes"`
+	StderrBytes     int64 `json:"stderr_bytes"`
+	StdoutTruncated bool  `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool  `json:"stderr_truncated,omitempty"`
+	// TimedOut is set when the command was stopped at its timeout; Stdout
+	// and Stderr then hold whatever it printed before that.
+	TimedOut bool `json:"timed_out,omitempty"`
 }
 
 // ExecuteResult represents the overall execution result
@@ -90,13 +121,16 @@ type ExecuteResult struct {
 	Error string `json:"error,omitempty"`
 }
 
+// ConnectivityServerResult is the connectivity outcome for one server.
+type ConnectivityServerResult struct {
+	Server    string `json:"server"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
 // ConnectivityResult represents connectivity test result
 type ConnectivityResult struct {
-	Results []struct {
-		Server    string `json:"server"`
-		Reachable bool   `json:"reachable"`
-		Error     string `json:"error,omitempty"`
-	} `json:"results"`
+	Results []ConnectivityServerResult `json:"results"`
 	Summary struct {
 		Total       int `json:"total"`
 		Reachable   int `json:"reachable"`
@@ -120,8 +154,12 @@ func (t *SSHTool) getConfig(ctx context.Context, incidentID string, instanceID *
 	config := &SSHConfig{
 		CommandTimeout:    120,
 		ConnectionTimeout: 30,
-		KnownHostsPolicy:  "auto_add",
+		KnownHostsPolicy:  KnownHostsAutoAdd,
+		MaxOutputBytes:    DefaultMaxOutputBytes,
+		MaxParallel:       DefaultMaxParallel,
+		IdleTimeout:       DefaultIdleTimeout,
 		Keys:              make(map[string]*SSHKey),
+		InstanceID:        creds.InstanceID,
 	}
 
 	settings := creds.Settings
@@ -162,6 +200,9 @@ func (t *SSHTool) getConfig(ctx context.Context, incidentID string, instanceID *
 					key.PrivateKey = privateKey
 				}
 			}
+			if passphrase, ok := keyMap["passphrase"].(string); ok {
+				key.Passphrase = passphrase
+			}
 			if isDefault, ok := keyMap["is_default"].(bool); ok {
 				key.IsDefault = isDefault
 			}
@@ -181,10 +222,16 @@ func (t *SSHTool) getConfig(ctx context.Context, incidentID string, instanceID *
 	// Get global timeouts
 	config.CommandTimeout = getInt("ssh_command_timeout", 120)
 	config.ConnectionTimeout = getInt("ssh_connection_timeout", 30)
+	config.MaxOutputBytes = min(max(getInt("ssh_max_output_bytes", DefaultMaxOutputBytes), MinMaxOutputBytes), MaxMaxOutputBytes)
+	config.MaxParallel = min(max(getInt("ssh_max_parallel", DefaultMaxParallel), 1), MaxMaxParallel)
+	config.IdleTimeout = min(max(getInt("ssh_connection_idle_timeout", DefaultIdleTimeout), 0), MaxIdleTimeout)
 
 	if policy, ok := settings["ssh_known_hosts_policy"].(string); ok {
 		config.KnownHostsPolicy = policy
 	}
+	if agentAuth, ok := settings["ssh_agent_auth"].(bool); ok {
+		config.AgentAuth = agentAuth
+	}
 
 	// Parse ad-hoc connection settings
 	if allow, ok := settings["allow_adhoc_connections"].(bool); ok {
@@ -258,6 +305,14 @@ func (t *SSHTool) getConfig(ctx context.Context, incidentID string, instanceID *
 			host.JumphostPort = 22
 		}
 
+		// Alternative authentication
+		if password, ok := hostMap["password"].(string); ok {
+			host.Password = password
+		}
+		if forward, ok := hostMap["forward_agent"].(bool); ok {
+			host.ForwardAgent = forward
+		}
+
 		// Security settings
 		if allow, ok := hostMap["allow_write_commands"].(bool); ok {
 			host.AllowWriteCommands = allow
@@ -274,56 +329,77 @@ func (t *SSHTool) getConfig(ctx context.Context, incidentID string, instanceID *
 	return config, nil
 }
 
-// getKeyForHost returns the private key to use for a specific host
-func (t *SSHTool) getKeyForHost(hostConfig *SSHHostConfig, config *SSHConfig) (string, error) {
+// keyForHost returns the SSH key to use for a specific host
+func (t *SSHTool) keyForHost(hostConfig *SSHHostConfig, config *SSHConfig) (*SSHKey, error) {
 	// If using new multi-key format
 	if len(config.Keys) > 0 {
 		// Check for per-host key override
 		if hostConfig.KeyID != "" {
 			if key, ok := config.Keys[hostConfig.KeyID]; ok {
-				return key.PrivateKey, nil
+				return key, nil
 			}
-			return "", fmt.Errorf("SSH key with ID '%s' not found for host '%s'", hostConfig.KeyID, hostConfig.Hostname)
+			return nil, fmt.Errorf("SSH key with ID '%s' not found for host '%s'", hostConfig.KeyID, hostConfig.Hostname)
 		}
 
 		// Use default key
 		if config.DefaultKeyID != "" {
 			if key, ok := config.Keys[config.DefaultKeyID]; ok {
-				return key.PrivateKey, nil
+				return key, nil
 			}
 		}
 
 		// If no default set, use the first key
 		for _, key := range config.Keys {
-			return key.PrivateKey, nil
+			return key, nil
 		}
 
-		return "", fmt.Errorf("no SSH keys configured")
+		return nil, fmt.Errorf("no SSH keys configured")
 	}
 
-	return "", fmt.Errorf("SSH private key not configured")
+	return nil, fmt.Errorf("SSH private key not configured")
+}
+
+// acquireConn returns a connection to hostConfig, reusing one pooled for the
+// same incident and instance when possible. release must be called when the
+// caller is done; pass broken to discard the connection, e.g. after a
+// timeout left a command running on it.
+func (t *SSHTool) acquireConn(ctx context.Context, incidentID string, hostConfig *SSHHostConfig, config *SSHConfig) (*ssh.Client, func(broken bool), error) {
+	pool := t.pool
+	if pool == nil {
+		pool = newConnPool()
+	}
+	idle := time.Duration(config.IdleTimeout) * time.Second
+	return pool.acquire(newPoolKey(incidentID, hostConfig, config), idle, func() (*ssh.Client, error) {
+		return t.connect(ctx, hostConfig, config)
+	})
 }
 
 // connect establishes SSH connection (direct or via jumphost)
 func (t *SSHTool) connect(ctx context.Context, hostConfig *SSHHostConfig, config *SSHConfig) (*ssh.Client, error) {
+	var client *ssh.Client
+	var err error
 	if hostConfig.JumphostAddress != "" {
-		return t.connectViaJumphost(ctx, hostConfig, config)
+		client, err = t.connectViaJumphost(ctx, hostConfig, config)
+	} else {
+		client, err = t.connectDirect(ctx, hostConfig, config)
 	}
-	return t.connectDirect(ctx, hostConfig, config)
+	if err != nil || !hostConfig.ForwardAgent {
+		return client, err
+	}
+	if err := t.forwardAgent(client, hostConfig, config); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("agent forwarding: %w", err)
+	}
+	return client, nil
 }
 
 // connectDirect establishes a direct SSH connection
 func (t *SSHTool) connectDirect(ctx context.Context, hostConfig *SSHHostConfig, config *SSHConfig) (*ssh.Client, error) {
-	// Get the appropriate key for this host
-	privateKey, err := t.getKeyForHost(hostConfig, config)
+	auth, done, err := t.authMethods(hostConfig, config)
 	if err != nil {
 		return nil, err
 	}
-
-	signer, err := parsePrivateKey(privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
+	defer done()
 
 	user := hostConfig.User
 	if user == "" {
@@ -336,11 +412,9 @@ func (t *SSHTool) connectDirect(ctx context.Context, hostConfig *SSHHostConfig,
 	}
 
 	clientConfig := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // TODO: implement proper host key checking
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: t.hostKeyCallback(ctx, config),
 		Timeout:         time.Duration(config.ConnectionTimeout) * time.Second,
 	}
 
@@ -350,18 +424,21 @@ func (t *SSHTool) connectDirect(ctx context.Context, hostConfig *SSHHostConfig,
 	return ssh.Dial("tcp", addr, clientConfig)
 }
 
-// connectViaJumphost establishes SSH connection through a bastion host
+// connectViaJumphost establishes SSH connection through a bastion host. The
+// jumphost is offered the same keys as the target but not its password.
 func (t *SSHTool) connectViaJumphost(ctx context.Context, hostConfig *SSHHostConfig, config *SSHConfig) (*ssh.Client, error) {
-	// Get the appropriate key for this host
-	privateKey, err := t.getKeyForHost(hostConfig, config)
+	auth, done, err := t.authMethods(hostConfig, config)
 	if err != nil {
 		return nil, err
 	}
-
-	signer, err := parsePrivateKey(privateKey)
+	defer done()
+	jumpHost := *hostConfig
+	jumpHost.Password = ""
+	jumphostAuth, jumpDone, err := t.authMethods(&jumpHost, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, fmt.Errorf("jumphost: %w", err)
 	}
+	defer jumpDone()
 
 	// Jumphost connection config
 	jumphostUser := hostConfig.JumphostUser
@@ -378,11 +455,9 @@ func (t *SSHTool) connectViaJumphost(ctx context.Context, hostConfig *SSHHostCon
 	}
 
 	jumphostConfig := &ssh.ClientConfig{
-		User: jumphostUser,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // TODO: proper host key checking
+		User:            jumphostUser,
+		Auth:            jumphostAuth,
+		HostKeyCallback: t.hostKeyCallback(ctx, config),
 		Timeout:         time.Duration(config.ConnectionTimeout) * time.Second,
 	}
 
@@ -417,11 +492,9 @@ func (t *SSHTool) connectViaJumphost(ctx context.Context, hostConfig *SSHHostCon
 
 	// Step 3: Establish SSH client connection over the tunnel
 	targetConfig := &ssh.ClientConfig{
-		User: targetUser,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		User:            targetUser,
+		Auth:            auth,
+		HostKeyCallback: t.hostKeyCallback(ctx, config),
 		Timeout:         time.Duration(config.ConnectionTimeout) * time.Second,
 	}
 
@@ -496,20 +569,8 @@ func fixPEMKey(key string) string {
 	return header + "\n" + body + "\n" + footer + "\n"
 }
 
-// parsePrivateKey parses a PEM-encoded private key
-func parsePrivateKey(keyData string) (ssh.Signer, error) {
-	// Fix PEM key if it has spaces instead of newlines
-	keyData = fixPEMKey(keyData)
-
-	signer, err := ssh.ParsePrivateKey([]byte(keyData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-	return signer, nil
-}
-
 // executeOnServer executes a command on a single server using per-host config
-func (t *SSHTool) executeOnServer(ctx context.Context, hostConfig *SSHHostConfig, command string, config *SSHConfig) ServerResult {
+func (t *SSHTool) executeOnServer(ctx context.Context, incidentID string, hostConfig *SSHHostConfig, command string, config *SSHConfig, opts ExecOptions) ServerResult {
 	startTime := time.Now()
 
 	result := ServerResult{
@@ -525,38 +586,57 @@ func (t *SSHTool) executeOnServer(ctx context.Context, hostConfig *SSHHostConfig
 		return result
 	}
 
-	// Connect to server (direct or via jumphost)
-	conn, err := t.connect(ctx, hostConfig, config)
+	// Connect to server (direct or via jumphost), reusing a pooled connection
+	conn, release, err := t.acquireConn(ctx, incidentID, hostConfig, config)
 	if err != nil {
 		result.Error = fmt.Sprintf("Connection failed: %v", err)
 		result.DurationMs = time.Since(startTime).Milliseconds()
 		return result
 	}
-	defer conn.Close()
+	// A command read-only mode would have refused may change the host: put
+	// it on the incident's change manifest whatever its exit code.
+	if hostConfig.AllowWriteCommands && IsWriteCommand(command) {
+		defer t.recordChange(context.WithoutCancel(ctx), incidentID, database.IncidentChange{
+			Location: hostConfig.Hostname,
+			Action:   changeCommand,
+			Tool:     "ssh.execute_command",
+			Detail:   command,
+		})
+	}
+	broken := false
+	defer func() { release(broken) }()
 
 	// Create session
 	session, err := conn.NewSession()
 	if err != nil {
+		broken = true
 		result.Error = fmt.Sprintf("Session creation failed: %v", err)
 		result.DurationMs = time.Since(startTime).Milliseconds()
 		return result
 	}
 	defer session.Close()
 
+	if hostConfig.ForwardAgent {
+		if err := agent.RequestAgentForwarding(session); err != nil {
+			result.Error = fmt.Sprintf("Agent forwarding refused: %v", err)
+			result.DurationMs = time.Since(startTime).Milliseconds()
+			return result
+		}
+	}
+
+	stdout := newCappedBuffer(config.MaxOutputBytes, t.streamChunk(ctx, opts, hostConfig.Hostname, "stdout"))
+	stderr := newCappedBuffer(config.MaxOutputBytes, t.streamChunk(ctx, opts, hostConfig.Hostname, "stderr"))
+	session.Stdout = stdout
+	session.Stderr = stderr
+
 	// Execute command with timeout
 	type commandResult struct {
-		stdout   []byte
-		stderr   []byte
 		exitCode int
 		err      error
 	}
 
 	resultChan := make(chan commandResult, 1)
 	go func() {
-		var stdout, stderr strings.Builder
-		session.Stdout = &stdout
-		session.Stderr = &stderr
-
 		err := session.Run(command)
 
 		exitCode := 0
@@ -567,38 +647,89 @@ func (t *SSHTool) executeOnServer(ctx context.Context, hostConfig *SSHHostConfig
 			}
 		}
 
-		resultChan <- commandResult{
-			stdout:   []byte(stdout.String()),
-			stderr:   []byte(stderr.String()),
-			exitCode: exitCode,
-			err:      err,
-		}
+		resultChan <- commandResult{exitCode: exitCode, err: err}
 	}()
 
-	// Wait for result or timeout
+	collect := func() {
+		result.Stdout, result.StdoutBytes, result.StdoutTruncated = stdout.snapshot()
+		result.Stderr, result.StderrBytes, result.StderrTruncated = stderr.snapshot()
+		result.DurationMs = time.Since(startTime).Milliseconds()
+	}
+
+	// Wait for result or timeout. On timeout the output captured so far is
+	// still returned and the connection is discarded rather than pooled,
+	// which ends the command along with it.
+	timeout := commandTimeout(config, opts)
+	timer := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer timer.Stop()
 	select {
 	case <-ctx.Done():
-		result.Error = "Command timed out"
-		result.DurationMs = time.Since(startTime).Milliseconds()
+		broken = true
+		result.TimedOut = true
+		result.Error = "Command timed out; partial output returned"
+		collect()
 		return result
-	case <-time.After(time.Duration(config.CommandTimeout) * time.Second):
-		result.Error = "Command timed out"
-		result.DurationMs = time.Since(startTime).Milliseconds()
+	case <-timer.C:
+		broken = true
+		result.TimedOut = true
+		result.Error = fmt.Sprintf("Command timed out after %ds; partial output returned", timeout)
+		collect()
 		return result
 	case cmdResult := <-resultChan:
 		if cmdResult.err != nil {
+			broken = true
 			result.Error = fmt.Sprintf("Command execution failed: %v", cmdResult.err)
 		} else {
 			result.Success = cmdResult.exitCode == 0
 			result.ExitCode = cmdResult.exitCode
 		}
-		result.Stdout = string(cmdResult.stdout)
-		result.Stderr = string(cmdResult.stderr)
-		result.DurationMs = time.Since(startTime).Milliseconds()
+		collect()
 		return result
 	}
 }
 
+// executeLimited runs executeOnServer once the instance has a free
+// parallelism slot. Concurrent calls on one instance share its limit.
+func (t *SSHTool) executeLimited(ctx context.Context, incidentID string, host *SSHHostConfig, command string, config *SSHConfig, opts ExecOptions) ServerResult {
+	limiter := t.limiter
+	if limiter == nil {
+		return t.executeOnServer(ctx, incidentID, host, command, config, opts)
+	}
+	done, err := limiter.acquire(ctx, config.InstanceID, config.MaxParallel)
+	if err != nil {
+		return ServerResult{
+			Server:   host.Hostname,
+			ExitCode: -1,
+			Error:    fmt.Sprintf("Gave up waiting for a free connection slot: %v", err),
+		}
+	}
+	defer done()
+	return t.executeOnServer(ctx, incidentID, host, command, config, opts)
+}
+
+// commandTimeout returns the timeout in seconds for one execute_command call.
+func commandTimeout(config *SSHConfig, opts ExecOptions) int {
+	timeout := config.CommandTimeout
+	if opts.TimeoutSeconds > 0 {
+		timeout = opts.TimeoutSeconds
+	}
+	if timeout <= 0 {
+		timeout = 120
+	}
+	return min(timeout, MaxCommandTimeout)
+}
+
+// streamChunk returns the output hook that forwards a command's output as
+// progress notifications, or nil when streaming is off or nobody listens.
+func (t *SSHTool) streamChunk(ctx context.Context, opts ExecOptions, server, stream string) func([]byte) {
+	if !opts.Stream || !mcp.ProgressEnabled(ctx) {
+		return nil
+	}
+	return func(p []byte) {
+		mcp.ReportProgress(ctx, fmt.Sprintf("[%s %s] %s", server, stream, p))
+	}
+}
+
 // stripBrackets removes surrounding brackets from IPv6 literals (e.g. "[::1]" -> "::1")
 // so that net.JoinHostPort doesn't double-bracket them.
 func stripBrackets(host string) string {
@@ -663,7 +794,7 @@ func (t *SSHTool) resolveTargetHosts(servers []string, config *SSHConfig) ([]SSH
 
 // ExecuteCommand executes a command on all or specified servers.
 // If instanceID is provided, credentials are resolved for that specific tool instance.
-func (t *SSHTool) ExecuteCommand(ctx context.Context, incidentID string, command string, servers []string, instanceID *uint, logicalName ...string) (string, error) {
+func (t *SSHTool) ExecuteCommand(ctx context.Context, incidentID string, command string, servers []string, opts ExecOptions, instanceID *uint, logicalName ...string) (string, error) {
 	config, err := t.getConfig(ctx, incidentID, instanceID, logicalName...)
 	if err != nil {
 		return "", err
@@ -671,27 +802,33 @@ func (t *SSHTool) ExecuteCommand(ctx context.Context, incidentID string, command
 
 	// Validate keys
 	if len(config.Keys) == 0 {
-		return t.jsonResult(ExecuteResult{Error: "SSH private key not configured"})
+		return t.jsonResult(ExecuteResult{Results: []ServerResult{}, Error: "SSH private key not configured"})
 	}
 
 	// Resolve target hosts (supports ad-hoc connections)
 	targetHosts, err := t.resolveTargetHosts(servers, config)
 	if err != nil {
-		return t.jsonResult(ExecuteResult{Error: err.Error()})
+		return t.jsonResult(ExecuteResult{Results: []ServerResult{}, Error: err.Error()})
 	}
 
-	// Execute in parallel
-	var wg sync.WaitGroup
+	// Execute in parallel, at most MaxParallel hosts at a time per instance
 	results := make([]ServerResult, len(targetHosts))
-
+	next := make(chan int, len(targetHosts))
 	for i := range targetHosts {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for range min(config.MaxParallel, len(targetHosts)) {
 		wg.Add(1)
-		go func(idx int, host *SSHHostConfig) {
+		go func() {
 			defer wg.Done()
-			results[idx] = t.executeOnServer(ctx, host, command, config)
-		}(i, &targetHosts[i])
+			for idx := range next {
+				results[idx] = t.executeLimited(ctx, incidentID, &targetHosts[idx], command, config, opts)
+			}
+		}()
 	}
-
 	wg.Wait()
 
 	// Build result
@@ -717,27 +854,23 @@ func (t *SSHTool) TestConnectivity(ctx context.Context, incidentID string, serve
 	}
 
 	if len(config.Keys) == 0 {
-		return t.jsonResult(ConnectivityResult{Error: "SSH private key not configured"})
+		return t.jsonResult(ConnectivityResult{Results: []ConnectivityServerResult{}, Error: "SSH private key not configured"})
 	}
 
 	// Resolve target hosts (supports ad-hoc connections)
 	targetHosts, err := t.resolveTargetHosts(servers, config)
 	if err != nil {
-		return t.jsonResult(ConnectivityResult{Error: err.Error()})
+		return t.jsonResult(ConnectivityResult{Results: []ConnectivityServerResult{}, Error: err.Error()})
 	}
 
-	var result ConnectivityResult
+	result := ConnectivityResult{Results: []ConnectivityServerResult{}}
 	for i := range targetHosts {
 		host := &targetHosts[i]
 
 		// Try to establish connection (handles both direct and jumphost)
 		sshConn, err := t.connect(ctx, host, config)
 		if err != nil {
-			result.Results = append(result.Results, struct {
-				Server    string `json:"server"`
-				Reachable bool   `json:"reachable"`
-				Error     string `json:"error,omitempty"`
-			}{
+			result.Results = append(result.Results, ConnectivityServerResult{
 				Server:    host.Hostname,
 				Reachable: false,
 				Error:     err.Error(),
@@ -746,11 +879,7 @@ func (t *SSHTool) TestConnectivity(ctx context.Context, incidentID string, serve
 		}
 		sshConn.Close()
 
-		result.Results = append(result.Results, struct {
-			Server    string `json:"server"`
-			Reachable bool   `json:"reachable"`
-			Error     string `json:"error,omitempty"`
-		}{
+		result.Results = append(result.Results, ConnectivityServerResult{
 			Server:    host.Hostname,
 			Reachable: true,
 		})
@@ -776,7 +905,7 @@ func (t *SSHTool) GetServerInfo(ctx context.Context, incidentID string, servers
 		`echo "OS=$(cat /etc/os-release 2>/dev/null | grep PRETTY_NAME | cut -d'"' -f2 || uname -s)" && ` +
 		`echo "UPTIME=$(uptime -p 2>/dev/null || uptime | awk -F'up ' '{print $2}' | awk -F',' '{print $1}')"`
 
-	return t.ExecuteCommand(ctx, incidentID, infoCommand, servers, instanceID, logicalName...)
+	return t.ExecuteCommand(ctx, incidentID, infoCommand, servers, ExecOptions{}, instanceID, logicalName...)
 }
 
 // jsonResult converts a result to JSON string
@@ -195,11 +195,7 @@ func TestExecuteResult_JSONSerialization(t *testing.T) {
 
 func TestConnectivityResult_JSONSerialization(t *testing.T) {
 	result := ConnectivityResult{}
-	result.Results = []struct {
-		Server    string `json:"server"`
-		Reachable bool   `json:"reachable"`
-		Error     string `json:"error,omitempty"`
-	}{
+	result.Results = []ConnectivityServerResult{
 		{Server: "server1", Reachable: true},
 		{Server: "server2", Reachable: false, Error: "Connection timeout"},
 	}
@@ -57,8 +57,8 @@ type VictoriaMetricsTool struct {
 func NewVictoriaMetricsTool(logger *log.Logger, limiter *ratelimit.Limiter) *VictoriaMetricsTool {
 	return &VictoriaMetricsTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("victoriametrics_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("victoriametrics_response").Shared(cache.JSON[json.RawMessage]()),
 		rateLimiter:   limiter,
 	}
 }
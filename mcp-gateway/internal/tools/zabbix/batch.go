@@ -25,6 +25,14 @@ type BatchResult struct {
 	Count   int         `json:"count"`
 }
 
+// BatchResponse is the zabbix.get_items_batch result
+type BatchResponse struct {
+	Results      []BatchResult `json:"results"`
+	TotalItems   int           `json:"total_items"`
+	TotalUnique  int           `json:"total_unique"`
+	PatternCount int           `json:"pattern_count"`
+}
+
 // GetItemsBatch retrieves multiple items with deduplication
 // This is more efficient than multiple GetItems calls for investigations
 // that need items matching multiple patterns (e.g., cpu, memory, disk)
@@ -133,12 +141,7 @@ func (t *ZabbixTool) GetItemsBatch(ctx context.Context, incidentID string, args
 	}
 
 	// Build response
-	response := struct {
-		Results      []BatchResult `json:"results"`
-		TotalItems   int           `json:"total_items"`
-		TotalUnique  int           `json:"total_unique"`
-		PatternCount int           `json:"pattern_count"`
-	}{
+	response := BatchResponse{
 		Results:      results,
 		TotalItems:   len(seenItems),
 		TotalUnique:  len(seenItems),
@@ -0,0 +1,77 @@
+package zabbix
+
+// Result shapes of the Zabbix read tools. Handlers pass the API's JSON
+// through unchanged; these types document the fields the gateway requests by
+// default and back the tools' output schemas. Zabbix encodes every scalar as
+// a string, and callers can narrow the field list with "output", so all
+// fields are optional.
+
+// HostRef is the short host form returned by selectHosts.
+type HostRef struct {
+	HostID string `json:"hostid,omitempty"`
+	Host   string `json:"host,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Tag is a Zabbix tag/value pair.
+type Tag struct {
+	Tag   string `json:"tag,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Host is one element of the zabbix.get_hosts result.
+type Host struct {
+	HostID    string `json:"hostid,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Available string `json:"available,omitempty"`
+}
+
+// Problem is one element of the zabbix.get_problems result.
+type Problem struct {
+	EventID      string    `json:"eventid,omitempty"`
+	ObjectID     string    `json:"objectid,omitempty"`
+	Clock        string    `json:"clock,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Severity     string    `json:"severity,omitempty"`
+	Acknowledged string    `json:"acknowledged,omitempty"`
+	Suppressed   string    `json:"suppressed,omitempty"`
+	REventID     string    `json:"r_eventid,omitempty"`
+	OpData       string    `json:"opdata,omitempty"`
+	Hosts        []HostRef `json:"hosts,omitempty"`
+	Tags         []Tag     `json:"tags,omitempty"`
+}
+
+// HistoryRecord is one element of the zabbix.get_history result.
+type HistoryRecord struct {
+	ItemID string `json:"itemid,omitempty"`
+	Clock  string `json:"clock,omitempty"`
+	Value  string `json:"value,omitempty"`
+	NS     string `json:"ns,omitempty"`
+}
+
+// Item is one element of the zabbix.get_items result.
+type Item struct {
+	ItemID    string `json:"itemid,omitempty"`
+	HostID    string `json:"hostid,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key_,omitempty"`
+	ValueType string `json:"value_type,omitempty"`
+	LastValue string `json:"lastvalue,omitempty"`
+	Units     string `json:"units,omitempty"`
+	State     string `json:"state,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// Trigger is one element of the zabbix.get_triggers result.
+type Trigger struct {
+	TriggerID   string    `json:"triggerid,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Value       string    `json:"value,omitempty"`
+	State       string    `json:"state,omitempty"`
+	LastChange  string    `json:"lastchange,omitempty"`
+	Hosts       []HostRef `json:"hosts,omitempty"`
+}
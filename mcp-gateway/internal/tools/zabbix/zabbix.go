@@ -51,8 +51,8 @@ type ZabbixTool struct {
 func NewZabbixTool(logger *log.Logger, limiter *ratelimit.Limiter) *ZabbixTool {
 	return &ZabbixTool{
 		logger:        logger,
-		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick),
-		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick),
+		configCache:   cache.New(ConfigCacheTTL, CacheCleanupTick).Named("zabbix_config"),
+		responseCache: cache.New(ResponseCacheTTL, CacheCleanupTick).Named("zabbix_response").Shared(cache.JSON[json.RawMessage]()),
 		authCache:     make(map[string]authEntry),
 		rateLimiter:   limiter,
 	}
@@ -663,6 +663,12 @@ func (t *ZabbixTool) APIRequest(ctx context.Context, incidentID string, method s
 	return string(result), nil
 }
 
+// IsReadMethod reports whether a Zabbix API method only reads, e.g.
+// host.get or configuration.export. Every other method may change Zabbix.
+func IsReadMethod(method string) bool {
+	return strings.HasSuffix(method, ".get") || strings.HasSuffix(method, ".export") || method == "apiinfo.version"
+}
+
 // ClearCache clears all caches (useful for testing or forcing refresh)
 func (t *ZabbixTool) ClearCache() {
 	t.configCache.Clear()
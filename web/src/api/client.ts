@@ -3,8 +3,44 @@ import type {
   ToolType,
   ToolInstance,
   Incident,
+  IncidentLogChunk,
   Alert,
+  IncidentRelations,
+  SkillSnippet,
+  IncidentEvent,
+  IncidentReport,
+  ToolApproval,
+  ToolApprovalStatus,
+  RemediationPlan,
+  Silence,
+  SilenceRequest,
+  SilenceState,
+  SuppressedAlert,
+  RoutingRule,
+  RoutingRuleRequest,
+  MarketplaceIndex,
+  AuditLog,
+  AuditLogFilter,
+  UsageSummary,
+  UsageFilter,
+  AlertBaseline,
+  ComplianceReport,
+  ComplianceFilter,
+  SkillInstallResult,
+  SkillTestAlert,
+  SkillTestResult,
+  ExportSnippetRequest,
   EventFeedItem,
+  SearchResponse,
+  SearchResultType,
+  NotificationKind,
+  NotificationTemplate,
+  NotificationTemplateList,
+  NotificationTemplatePreview,
+  PromptTemplate,
+  PromptTemplateList,
+  PromptTemplatePreview,
+  TemplateVariableCatalog,
   Integration,
   CreateIntegrationRequest,
   UpdateIntegrationRequest,
@@ -13,6 +49,7 @@ import type {
   UpdateChannelRequest,
   ListChannelsFilter,
   LLMConfig,
+  LLMHealthResult,
   LLMSettingsListResponse,
   CreateLLMConfigRequest,
   UpdateLLMConfigRequest,
@@ -22,6 +59,16 @@ import type {
   GeneralSettingsUpdate,
   RetentionSettings,
   RetentionSettingsUpdate,
+  WorkspaceUsage,
+  RetentionCleanupResult,
+  VocabularySettings,
+  VocabularySettingsUpdate,
+  CORSSettings,
+  CORSSettingsUpdate,
+  ModelPrice,
+  ModelPriceInput,
+  BudgetSettings,
+  BudgetSettingsUpdate,
   FormattingRule,
   FormattingRuleCreate,
   FormattingRuleUpdate,
@@ -36,9 +83,13 @@ import type {
   AlertSourceInstance,
   CreateAlertSourceRequest,
   UpdateAlertSourceRequest,
+  AlertSourceConfigSnippet,
+  ProvisionZabbixRequest,
+  ProvisionZabbixResult,
   SSHKey,
   SSHKeyCreateRequest,
   SSHKeyUpdateRequest,
+  SSHKnownHost,
   Runbook,
   Memory,
   CronJob,
@@ -57,11 +108,16 @@ class ApiError extends Error {
   // requires_confirmation/firing_alert_count shape returned by
   // POST /api/incidents/{uuid}/close).
   body?: unknown;
+  // Machine-readable code from the error envelope (e.g. "not_found",
+  // "validation_error"), when the body had one.
+  code?: string;
 
   constructor(status: number, message: string, body?: unknown) {
     super(message);
     this.status = status;
     this.body = body;
+    const code = (body as { code?: unknown } | undefined)?.code;
+    if (typeof code === 'string') this.code = code;
     this.name = 'ApiError';
   }
 }
@@ -147,7 +203,13 @@ async function fetchApi<T>(endpoint: string, options?: RequestInit): Promise<T>
     try {
       const json = JSON.parse(text);
       body = json;
-      message = json.error || text || response.statusText;
+      message = json.message || json.error || text || response.statusText;
+      // Validation failures list the failing fields in details.
+      if (json.details && typeof json.details === 'object') {
+        const fields = Object.entries(json.details as Record<string, string>)
+          .map(([field, reason]) => `${field} ${reason}`);
+        if (fields.length > 0) message = `${message}: ${fields.join('; ')}`;
+      }
     } catch {
       message = text || response.statusText;
     }
@@ -211,6 +273,54 @@ export const skillsApi = {
     fetchApi<{ status: string; message: string }>('/api/skills/sync', {
       method: 'POST',
     }),
+
+  // Dry-run the skill against a synthetic alert. Held open until the run
+  // finishes, so this can take minutes.
+  test: (name: string, alert: SkillTestAlert, timeoutSeconds?: number) =>
+    fetchApi<SkillTestResult>(`/api/skills/${encodeURIComponent(name)}/test`, {
+      method: 'POST',
+      body: JSON.stringify({ alert, timeout_seconds: timeoutSeconds }),
+    }),
+
+  // tar.gz bundle of the skill's SKILL.md, scripts and referenced context
+  // files, for importing on another install.
+  getExportUrl: (name: string) => {
+    const token = localStorage.getItem(TOKEN_KEY);
+    const base = `${API_BASE_URL}/api/skills/${encodeURIComponent(name)}/export`;
+    return token ? `${base}?token=${encodeURIComponent(token)}` : base;
+  },
+
+  importBundle: async (file: File): Promise<SkillInstallResult> => {
+    const formData = new FormData();
+    formData.append('file', file);
+
+    const response = await fetch(`${API_BASE_URL}/api/skills/import`, {
+      method: 'POST',
+      body: formData,
+      headers: getAuthHeaders(),
+    });
+
+    if (response.status === 401) {
+      localStorage.removeItem(TOKEN_KEY);
+      localStorage.removeItem('aiops_auth_user');
+      window.location.href = '/login';
+      throw new ApiError(401, 'Session expired. Please log in again.');
+    }
+
+    if (!response.ok) {
+      const text = await response.text();
+      let message: string;
+      try {
+        const json = JSON.parse(text);
+        message = json.message || json.error || text || response.statusText;
+      } catch {
+        message = text || response.statusText;
+      }
+      throw new ApiError(response.status, message);
+    }
+
+    return response.json();
+  },
 };
 
 // Tool Types API
@@ -264,6 +374,21 @@ export const sshKeysApi = {
     }),
 };
 
+// Pinned SSH host keys (for SSH tool instances)
+export const sshKnownHostsApi = {
+  list: (toolId: number) => fetchApi<SSHKnownHost[]>(`/api/tools/${toolId}/known-hosts`),
+
+  clearAll: (toolId: number) =>
+    fetchApi<{ deleted: number }>(`/api/tools/${toolId}/known-hosts`, {
+      method: 'DELETE',
+    }),
+
+  delete: (toolId: number, knownHostId: number) =>
+    fetchApi<void>(`/api/tools/${toolId}/known-hosts/${knownHostId}`, {
+      method: 'DELETE',
+    }),
+};
+
 // Incidents API
 export const incidentsApi = {
   list: (from?: number, to?: number, page = 1, perPage = 50, trendWindow?: '1h' | '3h', status?: string) => {
@@ -277,10 +402,91 @@ export const incidentsApi = {
     return fetchApi<PaginatedResponse<Incident>>(`/api/incidents?${params.toString()}`);
   },
 
-  get: (uuid: string) => fetchApi<Incident>(`/api/incidents/${uuid}`),
+  // includeLog=false leaves full_log out; views page through it with
+  // getLogChunk instead.
+  get: (uuid: string, includeLog = true) =>
+    fetchApi<Incident>(`/api/incidents/${uuid}${includeLog ? '' : '?log=false'}`),
+
+  // Up to limit characters of the execution log starting at offset.
+  getLogChunk: (uuid: string, offset: number, limit?: number) =>
+    fetchApi<IncidentLogChunk>(
+      `/api/incidents/${uuid}/log?offset=${offset}${limit ? `&limit=${limit}` : ''}`
+    ),
 
   getAlerts: (uuid: string) => fetchApi<Alert[]>(`/api/incidents/${uuid}/alerts`),
 
+  getLinks: (uuid: string) => fetchApi<IncidentRelations>(`/api/incidents/${uuid}/links`),
+
+  // kind is relative to uuid: 'parent' files uuid under target, 'child' files
+  // target under uuid.
+  link: (uuid: string, targetUUID: string, kind: 'parent' | 'child' | 'related', reason?: string) =>
+    fetchApi<IncidentRelations>(`/api/incidents/${uuid}/links`, {
+      method: 'POST',
+      body: JSON.stringify({ target_uuid: targetUUID, kind, reason }),
+    }),
+
+  unlink: (uuid: string, targetUUID: string) =>
+    fetchApi<void>(`/api/incidents/${uuid}/links/${targetUUID}`, { method: 'DELETE' }),
+
+  getTimeline: (uuid: string) => fetchApi<IncidentEvent[]>(`/api/incidents/${uuid}/timeline`),
+
+  addTimelineNote: (uuid: string, text: string) =>
+    fetchApi<IncidentEvent>(`/api/incidents/${uuid}/timeline`, {
+      method: 'POST',
+      body: JSON.stringify({ text }),
+    }),
+
+  // Ask the agent a follow-up in the incident's session. The answer streams
+  // on the incident WebSocket and is appended to full_log.
+  sendMessage: (uuid: string, message: string) =>
+    fetchApi<{ uuid: string; status: string; message: string }>(`/api/incidents/${uuid}/message`, {
+      method: 'POST',
+      body: JSON.stringify({ message }),
+    }),
+
+  // The incident as an OpenAI fine-tuning conversation, secrets redacted.
+  // anonymize swaps hostnames, IPs and usernames for pseudonyms.
+  getConversation: (uuid: string, anonymize = false) =>
+    fetchApi<{ messages: { role: 'system' | 'user' | 'assistant'; content: string }[] }>(
+      `/api/incidents/${uuid}/conversation${anonymize ? '?anonymize=true' : ''}`
+    ),
+
+  getReport: (uuid: string) => fetchApi<IncidentReport>(`/api/incidents/${uuid}/report`),
+
+  generateReport: (uuid: string) =>
+    fetchApi<IncidentReport>(`/api/incidents/${uuid}/report`, { method: 'POST' }),
+
+  getReportMarkdownUrl: (uuid: string) => {
+    const token = localStorage.getItem(TOKEN_KEY);
+    const base = `${API_BASE_URL}/api/incidents/${uuid}/report?format=markdown`;
+    return token ? `${base}&token=${encodeURIComponent(token)}` : base;
+  },
+
+  // Plain-text execution log, downloaded as incident-<uuid>.log.
+  getLogDownloadUrl: (uuid: string, stripEmoji = false, anonymize = false) => {
+    const token = localStorage.getItem(TOKEN_KEY);
+    const base = `${API_BASE_URL}/api/incidents/${uuid}/log?download=true${stripEmoji ? '&strip_emoji=true' : ''}${anonymize ? '&anonymize=true' : ''}`;
+    return token ? `${base}&token=${encodeURIComponent(token)}` : base;
+  },
+
+  // Zip archive of the incident record, alerts, timeline, full log and
+  // workspace files, for compliance retention.
+  getExportUrl: (uuid: string) => {
+    const token = localStorage.getItem(TOKEN_KEY);
+    const base = `${API_BASE_URL}/api/incidents/${uuid}/export`;
+    return token ? `${base}?token=${encodeURIComponent(token)}` : base;
+  },
+
+  getSnippets: (uuid: string) => fetchApi<SkillSnippet[]>(`/api/incidents/${uuid}/snippets`),
+
+  // Promote a command or query from this incident into a skill's scripts or
+  // context references.
+  exportSnippet: (uuid: string, request: ExportSnippetRequest) =>
+    fetchApi<SkillSnippet>(`/api/incidents/${uuid}/snippets`, {
+      method: 'POST',
+      body: JSON.stringify(request),
+    }),
+
   create: (request: CreateIncidentRequest) =>
     fetchApi<CreateIncidentResponse>('/api/incidents', {
       method: 'POST',
@@ -302,6 +508,16 @@ export const incidentsApi = {
       method: 'POST',
       body: JSON.stringify({ confirm }),
     }),
+
+  cancel: (uuid: string) =>
+    fetchApi<{ status: string }>(`/api/incidents/${uuid}/cancel`, {
+      method: 'POST',
+    }),
+
+  retry: (uuid: string) =>
+    fetchApi<{ uuid: string; status: string; attempt: number }>(`/api/incidents/${uuid}/retry`, {
+      method: 'POST',
+    }),
 };
 
 // Self-improvement proposals API
@@ -361,6 +577,13 @@ export const llmSettingsApi = {
     fetchApi<LLMConfig>(`/api/settings/llm/${id}/activate`, {
       method: 'PUT',
     }),
+
+  // Lists the models of the config's endpoint to check connectivity and
+  // credentials without spending tokens.
+  health: (id: number) =>
+    fetchApi<LLMHealthResult>(`/api/settings/llm/${id}/health`, {
+      method: 'POST',
+    }),
 };
 
 // Proxy Settings API
@@ -383,6 +606,57 @@ export const retentionSettingsApi = {
       method: 'PUT',
       body: JSON.stringify(settings),
     }),
+
+  usage: () => fetchApi<WorkspaceUsage>('/api/settings/retention/usage'),
+
+  cleanup: () =>
+    fetchApi<RetentionCleanupResult>('/api/settings/retention/cleanup', {
+      method: 'POST',
+    }),
+};
+
+// Severity emoji and status vocabulary API
+export const vocabularySettingsApi = {
+  get: () => fetchApi<VocabularySettings>('/api/settings/vocabulary'),
+
+  update: (settings: VocabularySettingsUpdate) =>
+    fetchApi<VocabularySettings>('/api/settings/vocabulary', {
+      method: 'PUT',
+      body: JSON.stringify(settings),
+    }),
+};
+
+// CORS policy API
+export const corsSettingsApi = {
+  get: () => fetchApi<CORSSettings>('/api/settings/cors'),
+
+  update: (settings: CORSSettingsUpdate) =>
+    fetchApi<CORSSettings>('/api/settings/cors', {
+      method: 'PUT',
+      body: JSON.stringify(settings),
+    }),
+};
+
+// Model price table API (investigation cost estimates)
+export const modelPricesApi = {
+  list: () => fetchApi<ModelPrice[]>('/api/settings/model-prices'),
+
+  replace: (prices: ModelPriceInput[]) =>
+    fetchApi<ModelPrice[]>('/api/settings/model-prices', {
+      method: 'PUT',
+      body: JSON.stringify({ prices }),
+    }),
+};
+
+// LLM budget API (spend guardrails)
+export const budgetApi = {
+  get: () => fetchApi<BudgetSettings>('/api/settings/llm/budget'),
+
+  update: (data: BudgetSettingsUpdate) =>
+    fetchApi<BudgetSettings>('/api/settings/llm/budget', {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
 };
 
 // Formatting Rules API (per-flow output formats)
@@ -460,7 +734,7 @@ export const contextApi = {
       let message: string;
       try {
         const json = JSON.parse(text);
-        message = json.error || text || response.statusText;
+        message = json.message || json.error || text || response.statusText;
       } catch {
         message = text || response.statusText;
       }
@@ -488,6 +762,147 @@ export const contextApi = {
     }),
 };
 
+// Tool approvals API
+export const approvalsApi = {
+  list: (params: { status?: ToolApprovalStatus; incident_uuid?: string } = {}) => {
+    const query = new URLSearchParams();
+    if (params.status) query.set('status', params.status);
+    if (params.incident_uuid) query.set('incident_uuid', params.incident_uuid);
+    const qs = query.toString();
+    return fetchApi<ToolApproval[]>(`/api/approvals${qs ? `?${qs}` : ''}`);
+  },
+
+  approve: (uuid: string, reason?: string) =>
+    fetchApi<ToolApproval>(`/api/approvals/${uuid}/approve`, {
+      method: 'POST',
+      body: JSON.stringify({ reason: reason ?? '' }),
+    }),
+
+  reject: (uuid: string, reason?: string) =>
+    fetchApi<ToolApproval>(`/api/approvals/${uuid}/reject`, {
+      method: 'POST',
+      body: JSON.stringify({ reason: reason ?? '' }),
+    }),
+};
+
+// Remediation plans of two-phase investigations, keyed by incident UUID.
+export const remediationPlansApi = {
+  get: (incidentUuid: string) => fetchApi<RemediationPlan>(`/api/incidents/${incidentUuid}/plan`),
+
+  approve: (incidentUuid: string, reason?: string) =>
+    fetchApi<RemediationPlan>(`/api/incidents/${incidentUuid}/plan/approve`, {
+      method: 'POST',
+      body: JSON.stringify({ reason: reason ?? '' }),
+    }),
+
+  reject: (incidentUuid: string, reason?: string) =>
+    fetchApi<RemediationPlan>(`/api/incidents/${incidentUuid}/plan/reject`, {
+      method: 'POST',
+      body: JSON.stringify({ reason: reason ?? '' }),
+    }),
+};
+
+// Silences (maintenance windows) API
+export const silencesApi = {
+  list: (state?: SilenceState) =>
+    fetchApi<Silence[]>(`/api/silences${state ? `?state=${state}` : ''}`),
+
+  create: (data: SilenceRequest) =>
+    fetchApi<Silence>('/api/silences', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  update: (uuid: string, data: SilenceRequest) =>
+    fetchApi<Silence>(`/api/silences/${uuid}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
+
+  expire: (uuid: string) =>
+    fetchApi<Silence>(`/api/silences/${uuid}`, { method: 'DELETE' }),
+
+  suppressed: (uuid: string) => fetchApi<SuppressedAlert[]>(`/api/silences/${uuid}/suppressed`),
+};
+
+// Alert routing rules API
+export const routingRulesApi = {
+  list: () => fetchApi<RoutingRule[]>('/api/routing-rules'),
+
+  get: (uuid: string) => fetchApi<RoutingRule>(`/api/routing-rules/${uuid}`),
+
+  create: (data: RoutingRuleRequest) =>
+    fetchApi<RoutingRule>('/api/routing-rules', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  update: (uuid: string, data: RoutingRuleRequest) =>
+    fetchApi<RoutingRule>(`/api/routing-rules/${uuid}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
+
+  delete: (uuid: string) =>
+    fetchApi<void>(`/api/routing-rules/${uuid}`, { method: 'DELETE' }),
+};
+
+// Audit log API
+export const auditApi = {
+  list: (filter: AuditLogFilter = {}) => {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value));
+    }
+    const query = params.toString();
+    return fetchApi<AuditLog[]>(`/api/audit${query ? `?${query}` : ''}`);
+  },
+};
+
+// Token usage API
+export const usageApi = {
+  get: (filter: UsageFilter = {}) => {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value));
+    }
+    const query = params.toString();
+    return fetchApi<UsageSummary>(`/api/usage${query ? `?${query}` : ''}`);
+  },
+};
+
+// Agent write-action compliance report
+export const complianceApi = {
+  getActions: (filter: ComplianceFilter = {}) => {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value));
+    }
+    const query = params.toString();
+    return fetchApi<ComplianceReport>(`/api/compliance/actions${query ? `?${query}` : ''}`);
+  },
+
+  getCsvUrl: (filter: ComplianceFilter = {}) => {
+    const params = new URLSearchParams({ format: 'csv' });
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value));
+    }
+    const token = localStorage.getItem(TOKEN_KEY);
+    if (token) params.set('token', token);
+    return `${API_BASE_URL}/api/compliance/actions?${params.toString()}`;
+  },
+};
+
+// Skill marketplace API
+export const marketplaceApi = {
+  index: () => fetchApi<MarketplaceIndex>('/api/marketplace'),
+
+  installSkill: (name: string) =>
+    fetchApi<SkillInstallResult>(`/api/marketplace/skills/${encodeURIComponent(name)}/install`, {
+      method: 'POST',
+    }),
+};
+
 // Runbooks API
 export const runbooksApi = {
   list: () => fetchApi<Runbook[]>('/api/runbooks'),
@@ -629,6 +1044,22 @@ export const alertSourcesApi = {
     const baseUrl = API_BASE_URL || window.location.origin;
     return `${baseUrl}/webhook/alert/${uuid}`;
   },
+
+  // Sender configuration (Alertmanager, Grafana, Datadog) pointing at the
+  // same webhook URL the UI shows.
+  getConfigSnippet: (uuid: string) =>
+    fetchApi<AlertSourceConfigSnippet>(
+      `/api/alert-sources/${uuid}/config-snippet?base_url=${encodeURIComponent(API_BASE_URL || window.location.origin)}`,
+    ),
+
+  // Creates the webhook media type and trigger action on the Zabbix server
+  // using the Zabbix tool's credentials. Safe to repeat: existing objects
+  // are updated.
+  provisionZabbix: (uuid: string, data: ProvisionZabbixRequest = {}) =>
+    fetchApi<ProvisionZabbixResult>(`/api/alert-sources/${uuid}/provision/zabbix`, {
+      method: 'POST',
+      body: JSON.stringify({ akmatori_url: API_BASE_URL || window.location.origin, ...data }),
+    }),
 };
 
 // Cron Jobs API
@@ -699,8 +1130,85 @@ export const eventsApi = {
     ),
 };
 
+// Notification templates API
+export const notificationTemplatesApi = {
+  list: () => fetchApi<NotificationTemplateList>('/api/notification-templates'),
+
+  create: (data: { kind: NotificationKind; locale?: string; body: string; enabled?: boolean }) =>
+    fetchApi<NotificationTemplate>('/api/notification-templates', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  update: (id: number, data: { body?: string; enabled?: boolean }) =>
+    fetchApi<NotificationTemplate>(`/api/notification-templates/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
+
+  delete: (id: number) =>
+    fetchApi<void>(`/api/notification-templates/${id}`, { method: 'DELETE' }),
+
+  preview: (data: { kind: NotificationKind; locale?: string; body?: string }) =>
+    fetchApi<NotificationTemplatePreview>('/api/notification-templates/preview', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  variables: () => fetchApi<TemplateVariableCatalog>('/api/template-variables'),
+};
+
+// Investigation prompt templates API
+export const promptTemplatesApi = {
+  list: () => fetchApi<PromptTemplateList>('/api/settings/prompts'),
+
+  create: (data: { source_type?: string; skill?: string; body: string; enabled?: boolean }) =>
+    fetchApi<PromptTemplate>('/api/settings/prompts', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+
+  update: (id: number, data: { body?: string; enabled?: boolean }) =>
+    fetchApi<PromptTemplate>(`/api/settings/prompts/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
+
+  delete: (id: number) =>
+    fetchApi<void>(`/api/settings/prompts/${id}`, { method: 'DELETE' }),
+
+  preview: (data: { source_type?: string; skill?: string; body?: string }) =>
+    fetchApi<PromptTemplatePreview>('/api/settings/prompts/preview', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    }),
+};
+
+// Global search API
+export const searchApi = {
+  search: (q: string, params?: { types?: SearchResultType[]; limit?: number }) => {
+    const qs = new URLSearchParams({ q });
+    if (params?.types?.length) qs.set('types', params.types.join(','));
+    if (params?.limit) qs.set('limit', String(params.limit));
+    return fetchApi<SearchResponse>(`/api/search?${qs.toString()}`);
+  },
+};
+
 // Alerts API
 export const alertsApi = {
+  // CSV (or NDJSON) of every alert matching the filters, with its incident's
+  // title, source and severity.
+  getExportUrl: (params?: { from?: string; to?: string; source?: string; severity?: string; status?: string; format?: 'csv' | 'ndjson' }) => {
+    const qs = new URLSearchParams();
+    Object.entries(params ?? {}).forEach(([key, value]) => {
+      if (value) qs.set(key, value);
+    });
+    const token = localStorage.getItem(TOKEN_KEY);
+    if (token) qs.set('token', token);
+    const query = qs.toString();
+    return `${API_BASE_URL}/api/alerts/export${query ? `?${query}` : ''}`;
+  },
+
   unlink: (uuid: string) =>
     fetchApi<{ incident_uuid: string }>(`/api/alerts/${encodeURIComponent(uuid)}/unlink`, {
       method: 'POST',
@@ -715,6 +1223,10 @@ export const alertsApi = {
       body: JSON.stringify({ target_incident_uuid: targetIncidentUUID ?? '' }),
     }),
 
+  // Weekly volume baselines per alert name, most deviating first.
+  getBaselines: (deviatingOnly = false) =>
+    fetchApi<AlertBaseline[]>(`/api/analytics/alert-baselines${deviatingOnly ? '?deviating=true' : ''}`),
+
   // Manually mark a firing alert resolved.
   resolve: (uuid: string) =>
     fetchApi<{ status: string }>(`/api/alerts/${encodeURIComponent(uuid)}/resolve`, {
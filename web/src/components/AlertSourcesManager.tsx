@@ -10,10 +10,13 @@ import {
   Link2,
   Settings,
   AlertTriangle,
+  Wand2,
 } from 'lucide-react';
 import LoadingSpinner from './LoadingSpinner';
 import ErrorMessage from './ErrorMessage';
 import AlertSourceForm from './alerts/AlertSourceForm';
+import ConfigSnippetPanel from './alerts/ConfigSnippetPanel';
+import { hasConfigSnippet } from './alerts/alertSourceHelpers';
 import { useAlertSourceManagement } from '../hooks/useAlertSourceManagement';
 import { alertSourcesApi } from '../api/client';
 
@@ -23,6 +26,9 @@ const sourceTypeIcons: Record<string, string> = {
   pagerduty: 'PD',
   datadog: 'DD',
   zabbix: 'ZX',
+  victorops: 'VO',
+  cloudwatch: 'CW',
+  sentry: 'SE',
   slack_channel: 'SL',
 };
 
@@ -30,6 +36,7 @@ export default function AlertSourcesManager() {
   const {
     sources,
     sourceTypes,
+    skills,
     loading,
     error,
     editingSource,
@@ -46,6 +53,9 @@ export default function AlertSourcesManager() {
     handleCancel,
     toggleExpand,
     copyWebhookUrl,
+    provisioning,
+    provisionNotice,
+    provisionZabbix,
   } = useAlertSourceManagement();
 
   if (loading) {
@@ -76,6 +86,7 @@ export default function AlertSourcesManager() {
           formData={formData}
           setFormData={setFormData}
           sourceTypes={sourceTypes}
+          skills={skills}
           selectedType={selectedType}
           editingSource={editingSource}
           onSave={handleSave}
@@ -139,6 +150,16 @@ export default function AlertSourcesManager() {
 
                     {/* Actions */}
                     <div className="flex gap-2 ml-4 flex-shrink-0">
+                      {typeName === 'zabbix' && (
+                        <button
+                          onClick={() => provisionZabbix(source)}
+                          disabled={provisioning === source.uuid}
+                          className="btn btn-ghost p-2 text-primary-600 dark:text-primary-400 hover:bg-primary-50 dark:hover:bg-primary-900/20"
+                          title="Set up webhook in Zabbix"
+                        >
+                          <Wand2 className={`w-4 h-4 ${provisioning === source.uuid ? 'animate-pulse' : ''}`} />
+                        </button>
+                      )}
                       <button
                         onClick={() => handleEdit(source)}
                         className="btn btn-ghost p-2 text-primary-600 dark:text-primary-400 hover:bg-primary-50 dark:hover:bg-primary-900/20"
@@ -189,6 +210,13 @@ export default function AlertSourcesManager() {
                           )}
                         </button>
                       </div>
+                      <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+                        Last alert received:{' '}
+                        {source.last_alert_at ? new Date(source.last_alert_at).toLocaleString() : 'never'}
+                      </p>
+                      {provisionNotice?.uuid === source.uuid && (
+                        <p className="mt-1 text-xs text-green-600 dark:text-green-400">{provisionNotice.text}</p>
+                      )}
                     </div>
                   )}
 
@@ -244,6 +272,8 @@ export default function AlertSourcesManager() {
                       </div>
                     )}
 
+                    {hasConfigSnippet(typeName) && <ConfigSnippetPanel sourceUUID={source.uuid} />}
+
                     <div className="flex items-start gap-2 p-3 bg-blue-50 dark:bg-blue-900/20 rounded text-sm">
                       <AlertTriangle className="w-4 h-4 text-blue-500 flex-shrink-0 mt-0.5" />
                       <div className="text-blue-700 dark:text-blue-300">
@@ -0,0 +1,85 @@
+import { useEffect, useState } from 'react';
+import { ShieldAlert, Check, X } from 'lucide-react';
+import type { ToolApproval } from '../types';
+import { approvalsApi } from '../api/client';
+
+interface IncidentApprovalsProps {
+  incidentUUID: string;
+  // Poll for new requests only while the investigation can still make them.
+  active: boolean;
+}
+
+const POLL_INTERVAL_MS = 5000;
+
+// IncidentApprovals shows destructive tool calls the investigation is
+// waiting on, with Approve/Reject controls. Rejecting stops the
+// investigation.
+export default function IncidentApprovals({ incidentUUID, active }: IncidentApprovalsProps) {
+  const [pending, setPending] = useState<ToolApproval[]>([]);
+  const [busy, setBusy] = useState<string | null>(null);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    let cancelled = false;
+    const load = () =>
+      approvalsApi.list({ status: 'pending', incident_uuid: incidentUUID })
+        .then(data => { if (!cancelled) setPending(data); })
+        .catch(() => { /* approvals are optional; keep the last view */ });
+    load();
+    if (!active) return () => { cancelled = true; };
+    const timer = setInterval(load, POLL_INTERVAL_MS);
+    return () => { cancelled = true; clearInterval(timer); };
+  }, [incidentUUID, active]);
+
+  const decide = async (approval: ToolApproval, approve: boolean) => {
+    setBusy(approval.uuid);
+    setError('');
+    try {
+      await (approve ? approvalsApi.approve(approval.uuid) : approvalsApi.reject(approval.uuid));
+      setPending(prev => prev.filter(a => a.uuid !== approval.uuid));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setBusy(null);
+    }
+  };
+
+  if (pending.length === 0) return null;
+
+  return (
+    <div className="px-6 py-3 border-b border-amber-200 dark:border-amber-800 bg-amber-50 dark:bg-amber-900/20 shrink-0 space-y-2">
+      {pending.map(approval => (
+        <div key={approval.uuid} className="flex items-start gap-3 text-sm">
+          <ShieldAlert className="w-4 h-4 mt-0.5 text-amber-600 dark:text-amber-400 shrink-0" />
+          <div className="flex-1 min-w-0">
+            <div className="text-amber-800 dark:text-amber-200">
+              Approval needed: <span className="font-mono">{approval.tool_name}</span> on <span className="font-medium">{approval.target}</span>
+              <span className="ml-2 text-xs text-amber-600 dark:text-amber-400">
+                expires {new Date(approval.expires_at).toLocaleTimeString()}
+              </span>
+            </div>
+            <pre className="mt-1 text-xs font-mono text-gray-700 dark:text-gray-300 whitespace-pre-wrap break-all">{approval.detail}</pre>
+          </div>
+          <button
+            onClick={() => decide(approval, true)}
+            disabled={busy === approval.uuid}
+            className="flex items-center gap-1 px-2.5 py-1 rounded text-xs font-medium bg-green-600 text-white hover:bg-green-700 disabled:opacity-50"
+          >
+            <Check className="w-3 h-3" />
+            Approve
+          </button>
+          <button
+            onClick={() => decide(approval, false)}
+            disabled={busy === approval.uuid}
+            title="Rejecting also stops the investigation"
+            className="flex items-center gap-1 px-2.5 py-1 rounded text-xs font-medium bg-red-600 text-white hover:bg-red-700 disabled:opacity-50"
+          >
+            <X className="w-3 h-3" />
+            Reject
+          </button>
+        </div>
+      ))}
+      {error && <p className="text-xs text-red-500">{error}</p>}
+    </div>
+  );
+}
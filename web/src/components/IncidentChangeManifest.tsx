@@ -0,0 +1,51 @@
+import { FileDiff } from 'lucide-react';
+import type { IncidentChange } from '../types';
+
+interface IncidentChangeManifestProps {
+  changes: IncidentChange[];
+}
+
+const actionStyles: Record<IncidentChange['action'], string> = {
+  created: 'text-green-600 dark:text-green-400',
+  modified: 'text-amber-600 dark:text-amber-400',
+  deleted: 'text-red-600 dark:text-red-400',
+  command: 'text-purple-600 dark:text-purple-400',
+};
+
+// IncidentChangeManifest lists the files the agent changed in its workspace
+// and the writes it made on remote hosts, for audit and rollback planning.
+export default function IncidentChangeManifest({ changes }: IncidentChangeManifestProps) {
+  if (changes.length === 0) return null;
+
+  return (
+    <div className="mb-6">
+      <h3 className="flex items-center gap-2 text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
+        <FileDiff className="w-4 h-4" />
+        Changes ({changes.length})
+      </h3>
+      <div className="overflow-x-auto border border-gray-200 dark:border-gray-700 rounded-lg">
+        <table className="w-full text-sm">
+          <thead className="bg-gray-50 dark:bg-gray-800 text-xs text-gray-500 dark:text-gray-400">
+            <tr>
+              <th className="px-3 py-2 text-left font-medium">Where</th>
+              <th className="px-3 py-2 text-left font-medium">Action</th>
+              <th className="px-3 py-2 text-left font-medium">Path / command</th>
+            </tr>
+          </thead>
+          <tbody className="divide-y divide-gray-200 dark:divide-gray-700">
+            {changes.map(change => (
+              <tr key={change.id}>
+                <td className="px-3 py-2 text-gray-600 dark:text-gray-400 whitespace-nowrap">{change.location}</td>
+                <td className={`px-3 py-2 whitespace-nowrap font-medium ${actionStyles[change.action] ?? ''}`}>{change.action}</td>
+                <td className="px-3 py-2 font-mono text-xs text-gray-700 dark:text-gray-300 break-all" title={change.sha256 ? `sha256 ${change.sha256}` : undefined}>
+                  {change.action === 'command' ? change.detail : change.path}
+                  {change.detail === 'appended' && <span className="ml-2 text-gray-400">(appended)</span>}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      </div>
+    </div>
+  );
+}
@@ -1,12 +1,18 @@
 import { useState, useRef, useEffect, useMemo } from 'react';
-import { Terminal, MessageSquare, ChevronDown, ChevronRight, RefreshCw, Bell, Shuffle } from 'lucide-react';
+import { Terminal, MessageSquare, ChevronDown, ChevronRight, RefreshCw, Bell, Shuffle, Link2, BookmarkPlus, ListOrdered } from 'lucide-react';
 import { Link } from 'react-router-dom';
-import type { Incident, Alert } from '../types';
+import type { Incident, Alert, IncidentLinkRef } from '../types';
 import { incidentsApi, alertsApi } from '../api/client';
 import MoveIncidentModal from './MoveIncidentModal';
 import IncidentFeedbackStrip from './IncidentFeedbackStrip';
+import SaveSnippetModal from './SaveSnippetModal';
+import IncidentTimeline from './IncidentTimeline';
+import IncidentChangeManifest from './IncidentChangeManifest';
+import IncidentPostmortem from './IncidentPostmortem';
+import IncidentApprovals from './IncidentApprovals';
+import { useIncidentLog } from '../hooks/useIncidentLog';
 
-type TabType = 'reasoning' | 'response' | 'alerts';
+type TabType = 'reasoning' | 'response' | 'alerts' | 'timeline';
 
 interface IncidentDetailViewProps {
   incident: Incident;
@@ -28,8 +34,12 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
   const [moveResult, setMoveResult] = useState<{ incidentUUID: string; isNew: boolean } | null>(null);
   const [resolvingAlertUUID, setResolvingAlertUUID] = useState<string | null>(null);
   const [resolveError, setResolveError] = useState('');
+  const [snippetContent, setSnippetContent] = useState<string | null>(null);
+  const [snippetNotice, setSnippetNotice] = useState('');
   const alertsFetchedForRef = useRef<string | null>(null);
   const logContainerRef = useRef<HTMLDivElement | null>(null);
+  const { log: fullLog, loading: logLoading, error: logError } = useIncidentLog(
+    incident.uuid, incident.status === 'running' && autoRefresh, incident.status);
 
   // Reset alert fetch state and active tab when the viewed incident changes.
   useEffect(() => {
@@ -38,6 +48,7 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
     setAlertsError('');
     setMoveResult(null);
     setResolveError('');
+    setSnippetNotice('');
     setActiveTab(defaultTab(incident));
     // eslint-disable-next-line react-hooks/exhaustive-deps
   }, [incident.uuid]);
@@ -72,7 +83,7 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
     if (logContainerRef.current && activeTab === 'reasoning') {
       logContainerRef.current.scrollTop = logContainerRef.current.scrollHeight;
     }
-  }, [incident.full_log, activeTab]);
+  }, [fullLog, activeTab]);
 
   // Lazy-fetch alerts on first tab open. We track the fetched UUID rather than
   // a boolean so that a fetch cancelled mid-flight (tab/incident switch) does
@@ -100,9 +111,9 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
   }, [activeTab, incident.uuid]);
 
   const parsedLog = useMemo(() => {
-    if (!incident.full_log) return null;
+    if (!fullLog) return null;
 
-    const lines = incident.full_log.split('\n');
+    const lines = fullLog.split('\n');
     const entries: Array<{
       type: 'regular' | 'tool_call';
       content: string;
@@ -215,10 +226,43 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
 
     const toolCallCount = grouped.filter(e => e.type === 'tool_call').length;
     return { entries: grouped, toolCallCount };
-  }, [incident.full_log]);
+  }, [fullLog]);
+
+  const relations = incident.relations;
+  const relationGroups: { label: string; refs: IncidentLinkRef[] }[] = relations
+    ? [
+        { label: 'Parent', refs: relations.parent ? [relations.parent] : [] },
+        { label: 'Children', refs: relations.children },
+        { label: 'Related', refs: relations.related },
+      ].filter(g => g.refs.length > 0)
+    : [];
 
   return (
     <div className="flex flex-col min-h-0">
+      {/* Linked incidents (parent/child from cascading failures, or related) */}
+      {relationGroups.length > 0 && (
+        <div className="flex flex-wrap items-center gap-x-4 gap-y-1 px-6 py-2 border-b border-gray-200 dark:border-gray-700 text-xs text-gray-600 dark:text-gray-400 shrink-0">
+          <Link2 className="w-3.5 h-3.5" />
+          {relationGroups.map(group => (
+            <span key={group.label} className="flex items-center gap-1.5">
+              <span className="font-medium">{group.label}:</span>
+              {group.refs.map(ref => (
+                <Link
+                  key={ref.uuid}
+                  to={`/incidents/${ref.uuid}`}
+                  title={ref.reason || (ref.origin === 'correlator' ? 'Linked by the alert correlator' : undefined)}
+                  className="text-primary-600 dark:text-primary-400 hover:underline"
+                >
+                  {ref.title || ref.uuid.slice(0, 8)} ({ref.status})
+                </Link>
+              ))}
+            </span>
+          ))}
+        </div>
+      )}
+
+      <IncidentApprovals incidentUUID={incident.uuid} active={incident.status === 'pending' || incident.status === 'running'} />
+
       {/* Tab Navigation */}
       <div className="flex border-b border-gray-200 dark:border-gray-700 px-6 shrink-0">
         <button
@@ -264,6 +308,19 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
             </span>
           </button>
         )}
+        <button
+          onClick={() => setActiveTab('timeline')}
+          className={`px-4 py-3 text-sm font-medium border-b-2 transition-colors ${
+            activeTab === 'timeline'
+              ? 'border-primary-500 text-primary-600 dark:text-primary-400'
+              : 'border-transparent text-gray-500 hover:text-gray-700 dark:text-gray-400 dark:hover:text-gray-300'
+          }`}
+        >
+          <span className="flex items-center gap-2">
+            <ListOrdered className="w-4 h-4" />
+            Timeline
+          </span>
+        </button>
       </div>
 
       {/* Tab Content */}
@@ -282,6 +339,9 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
                   <span>Tool Calls ({parsedLog.toolCallCount})</span>
                 </button>
               )}
+              {snippetNotice && (
+                <span className="ml-4 text-xs text-green-400">{snippetNotice}</span>
+              )}
               {incident.status === 'running' && autoRefresh && (
                 <span className="ml-auto flex items-center gap-2 text-primary-400">
                   <RefreshCw className="w-3 h-3 animate-spin" />
@@ -296,8 +356,15 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
                     if (!showToolCalls) return null;
                     return (
                       <div key={index} className="my-3">
-                        <div className="text-gray-300 bg-gray-800/70 px-3 py-2 rounded border-l-2 border-blue-500">
+                        <div className="group relative text-gray-300 bg-gray-800/70 px-3 py-2 rounded border-l-2 border-blue-500">
                           {entry.content}
+                          <button
+                            onClick={() => setSnippetContent(entry.content.replace(/^(✅ Ran:|❌ Failed:)\s*/, ''))}
+                            title="Save as skill snippet"
+                            className="absolute top-1.5 right-1.5 hidden group-hover:block text-gray-500 hover:text-gray-200"
+                          >
+                            <BookmarkPlus className="w-4 h-4" />
+                          </button>
                         </div>
                         {entry.output && (
                           <div className="mt-1 text-gray-400 bg-gray-800/40 px-3 py-2 rounded border-l-2 border-gray-600 text-xs">
@@ -314,12 +381,21 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
                   return <div key={index}>{entry.content}</div>;
                 })}
               </div>
+            ) : logError ? (
+              `> Failed to load log: ${logError}`
+            ) : logLoading ? (
+              '> Loading log...'
             ) : (
               incident.status === 'pending'
                 ? '> Waiting for execution to start...'
                 : '> No log available yet'
             )}
           </div>
+        ) : activeTab === 'timeline' ? (
+          <div>
+            <IncidentChangeManifest changes={incident.changes ?? []} />
+            <IncidentTimeline incidentUUID={incident.uuid} refreshKey={`${incident.status}:${incident.updated_at}`} />
+          </div>
         ) : activeTab === 'response' ? (
           <div>
             <div className="bg-gray-50 dark:bg-gray-900 rounded-lg p-6 min-h-[200px]">
@@ -339,7 +415,10 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
               )}
             </div>
             {(incident.status === 'completed' || incident.status === 'monitor' || incident.status === 'failed') && (
-              <IncidentFeedbackStrip incidentUUID={incident.uuid} />
+              <>
+                <IncidentFeedbackStrip incidentUUID={incident.uuid} />
+                <IncidentPostmortem incidentUUID={incident.uuid} />
+              </>
             )}
           </div>
         ) : (
@@ -468,6 +547,17 @@ export default function IncidentDetailView({ incident, autoRefresh = false }: In
         )}
       </div>
 
+      {snippetContent !== null && (
+        <SaveSnippetModal
+          incidentUUID={incident.uuid}
+          initialContent={snippetContent}
+          onClose={() => setSnippetContent(null)}
+          onSaved={message => {
+            setSnippetContent(null);
+            setSnippetNotice(message);
+          }}
+        />
+      )}
       {moveTargetAlert && (
         <MoveIncidentModal
           alertUUID={moveTargetAlert.uuid}
@@ -0,0 +1,116 @@
+import { useEffect, useState } from 'react';
+import { Download, FileText, RefreshCw } from 'lucide-react';
+import type { IncidentReport } from '../types';
+import { incidentsApi, ApiError } from '../api/client';
+
+interface IncidentPostmortemProps {
+  incidentUUID: string;
+}
+
+// IncidentPostmortem shows the incident's generated postmortem, if any, with
+// controls to (re)generate it and download it as Markdown.
+export default function IncidentPostmortem({ incidentUUID }: IncidentPostmortemProps) {
+  const [report, setReport] = useState<IncidentReport | null>(null);
+  const [generating, setGenerating] = useState(false);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    let cancelled = false;
+    incidentsApi.getReport(incidentUUID)
+      .then(data => { if (!cancelled) setReport(data); })
+      .catch(err => {
+        // 404 just means no postmortem has been generated yet.
+        if (!cancelled && !(err instanceof ApiError && err.status === 404)) setError(String(err));
+      });
+    return () => { cancelled = true; };
+  }, [incidentUUID]);
+
+  const generate = async () => {
+    setGenerating(true);
+    setError('');
+    try {
+      setReport(await incidentsApi.generateReport(incidentUUID));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setGenerating(false);
+    }
+  };
+
+  return (
+    <div className="mt-6">
+      <div className="flex items-center gap-2 mb-3">
+        <h3 className="flex items-center gap-2 text-sm font-medium text-gray-700 dark:text-gray-300">
+          <FileText className="w-4 h-4" />
+          Postmortem
+        </h3>
+        <button
+          onClick={generate}
+          disabled={generating}
+          className="ml-auto flex items-center gap-1.5 px-3 py-1.5 rounded text-xs font-medium bg-primary-600 text-white hover:bg-primary-700 disabled:opacity-50"
+        >
+          <RefreshCw className={`w-3 h-3 ${generating ? 'animate-spin' : ''}`} />
+          {generating ? 'Generating...' : report ? 'Regenerate' : 'Generate postmortem'}
+        </button>
+        {report && (
+          <a
+            href={incidentsApi.getReportMarkdownUrl(incidentUUID)}
+            className="flex items-center gap-1.5 px-3 py-1.5 rounded text-xs font-medium border border-gray-300 dark:border-gray-600 text-gray-700 dark:text-gray-300 hover:bg-gray-50 dark:hover:bg-gray-800"
+          >
+            <Download className="w-3 h-3" />
+            Markdown
+          </a>
+        )}
+      </div>
+      {error && <p className="mb-3 text-sm text-red-500">{error}</p>}
+      {report && (
+        <div className="space-y-4 bg-gray-50 dark:bg-gray-900 rounded-lg p-6 text-sm text-gray-700 dark:text-gray-300">
+          {[
+            ['Summary', report.summary],
+            ['Impact', report.impact],
+            ['Root cause', report.root_cause],
+          ].map(([heading, body]) => (
+            <div key={heading}>
+              <h4 className="font-medium text-gray-900 dark:text-gray-100 mb-1">{heading}</h4>
+              <p className="whitespace-pre-wrap">{body || 'Not determined.'}</p>
+            </div>
+          ))}
+          {report.timeline.length > 0 && (
+            <div>
+              <h4 className="font-medium text-gray-900 dark:text-gray-100 mb-1">Timeline</h4>
+              <ul className="space-y-1">
+                {report.timeline.map((entry, i) => (
+                  <li key={i}>
+                    <span className="font-mono text-xs text-gray-500 mr-2">{entry.time}</span>
+                    {entry.event}
+                  </li>
+                ))}
+              </ul>
+            </div>
+          )}
+          {report.action_items.length > 0 && (
+            <div>
+              <h4 className="font-medium text-gray-900 dark:text-gray-100 mb-1">Action items</h4>
+              <ul className="list-disc pl-5 space-y-1">
+                {report.action_items.map((item, i) => (
+                  <li key={i}>
+                    {item.action}
+                    {(item.priority || item.owner) && (
+                      <span className="ml-2 text-xs text-gray-500">
+                        {[item.priority, item.owner].filter(Boolean).join(' · ')}
+                      </span>
+                    )}
+                  </li>
+                ))}
+              </ul>
+            </div>
+          )}
+          <p className="text-xs text-gray-500">
+            Generated {new Date(report.updated_at).toLocaleString()}
+            {report.generated_by ? ` by ${report.generated_by}` : ''}
+          </p>
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,109 @@
+import { useEffect, useState } from 'react';
+import { Activity, Bell, Terminal, MessageSquare, StickyNote, ShieldCheck } from 'lucide-react';
+import type { IncidentEvent, IncidentEventType } from '../types';
+import { incidentsApi } from '../api/client';
+
+interface IncidentTimelineProps {
+  incidentUUID: string;
+  // Changes to refetch, e.g. the incident's status while it is running.
+  refreshKey?: string;
+}
+
+const eventIcons: Record<IncidentEventType, typeof Activity> = {
+  status_change: Activity,
+  alert_attached: Bell,
+  command: Terminal,
+  slack_post: MessageSquare,
+  note: StickyNote,
+  approval: ShieldCheck,
+};
+
+// IncidentTimeline lists what happened when during an incident — status
+// changes, alerts attached, commands the agent ran, Slack posts and notes —
+// and lets operators add notes of their own.
+export default function IncidentTimeline({ incidentUUID, refreshKey }: IncidentTimelineProps) {
+  const [events, setEvents] = useState<IncidentEvent[] | null>(null);
+  const [error, setError] = useState('');
+  const [note, setNote] = useState('');
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    let cancelled = false;
+    incidentsApi.getTimeline(incidentUUID)
+      .then(data => { if (!cancelled) { setEvents(data); setError(''); } })
+      .catch(err => { if (!cancelled) setError(String(err)); });
+    return () => { cancelled = true; };
+  }, [incidentUUID, refreshKey]);
+
+  const addNote = async () => {
+    const text = note.trim();
+    if (!text || saving) return;
+    setSaving(true);
+    setError('');
+    try {
+      const event = await incidentsApi.addTimelineNote(incidentUUID, text);
+      setEvents(prev => [...(prev ?? []), event]);
+      setNote('');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to add note');
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  return (
+    <div>
+      {error && <p className="text-sm text-red-500 mb-3">{error}</p>}
+      {events === null ? (
+        !error && <p className="text-sm text-gray-500 dark:text-gray-400">Loading timeline…</p>
+      ) : events.length === 0 ? (
+        <p className="text-sm text-gray-500 dark:text-gray-400">No events recorded yet.</p>
+      ) : (
+        <ol className="relative border-l border-gray-200 dark:border-gray-700 ml-2">
+          {events.map(event => {
+            const Icon = eventIcons[event.type] ?? Activity;
+            const failed = event.type === 'command' && event.details?.failed === true;
+            const noteText = event.type === 'note' ? String(event.details?.text ?? event.summary) : '';
+            return (
+              <li key={event.id} className="mb-4 ml-5">
+                <span className="absolute -left-2.5 flex items-center justify-center w-5 h-5 rounded-full bg-white dark:bg-gray-800 ring-1 ring-gray-200 dark:ring-gray-700">
+                  <Icon className={`w-3 h-3 ${failed ? 'text-red-500' : 'text-primary-500'}`} />
+                </span>
+                <div className="flex items-baseline gap-2 text-xs text-gray-500 dark:text-gray-400">
+                  <time>{new Date(event.occurred_at).toLocaleString()}</time>
+                  <span>{event.actor}</span>
+                </div>
+                {event.type === 'note' ? (
+                  <p className="text-sm text-gray-700 dark:text-gray-300 whitespace-pre-wrap">{noteText}</p>
+                ) : (
+                  <p className={`text-sm break-all ${event.type === 'command' ? 'font-mono' : ''} ${failed ? 'text-red-600 dark:text-red-400' : 'text-gray-700 dark:text-gray-300'}`}>
+                    {event.summary}
+                  </p>
+                )}
+              </li>
+            );
+          })}
+        </ol>
+      )}
+
+      <div className="mt-4">
+        <textarea
+          value={note}
+          onChange={e => setNote(e.target.value)}
+          placeholder="Add a note, e.g. rolled back deploy 42 at 14:05"
+          rows={2}
+          className="w-full resize-none rounded-lg border border-gray-200 dark:border-gray-700 bg-white dark:bg-gray-900 px-3 py-2 text-sm text-gray-900 dark:text-gray-100 placeholder-gray-400 focus:outline-none focus:ring-2 focus:ring-primary-500"
+        />
+        <div className="mt-2 flex justify-end">
+          <button
+            onClick={addNote}
+            disabled={saving || !note.trim()}
+            className="px-4 py-2 text-sm rounded-lg bg-primary-500 text-white hover:bg-primary-600 disabled:opacity-40 disabled:cursor-not-allowed transition-colors"
+          >
+            {saving ? 'Saving…' : 'Add note'}
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
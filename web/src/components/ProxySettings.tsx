@@ -1,5 +1,5 @@
 import { useState, useEffect } from 'react';
-import { Save, Server, MessageSquare, Shield, Terminal, BarChart3, Activity, LayoutDashboard, Bell, Box, Network, Ticket } from 'lucide-react';
+import { Save, Server, MessageSquare, Shield, Terminal, BarChart3, Activity, LayoutDashboard, Bell, Box, Network, Ticket, LineChart, ScrollText, Globe } from 'lucide-react';
 import LoadingSpinner from './LoadingSpinner';
 import ErrorMessage, { SuccessMessage } from './ErrorMessage';
 import { proxySettingsApi } from '../api/client';
@@ -66,6 +66,9 @@ export default function ProxySettings() {
   const [netboxEnabled, setNetboxEnabled] = useState(false);
   const [kubernetesEnabled, setKubernetesEnabled] = useState(false);
   const [jiraEnabled, setJiraEnabled] = useState(false);
+  const [prometheusEnabled, setPrometheusEnabled] = useState(false);
+  const [logSearchEnabled, setLogSearchEnabled] = useState(false);
+  const [httpCheckEnabled, setHttpCheckEnabled] = useState(false);
 
   useEffect(() => {
     loadSettings();
@@ -87,6 +90,9 @@ export default function ProxySettings() {
       setNetboxEnabled(data.services.netbox?.enabled ?? false);
       setKubernetesEnabled(data.services.kubernetes?.enabled ?? false);
       setJiraEnabled(data.services.jira?.enabled ?? false);
+      setPrometheusEnabled(data.services.prometheus?.enabled ?? false);
+      setLogSearchEnabled(data.services.log_search?.enabled ?? false);
+      setHttpCheckEnabled(data.services.http_check?.enabled ?? false);
       setError(null);
     } catch (err) {
       setError('Failed to load proxy settings');
@@ -115,6 +121,9 @@ export default function ProxySettings() {
           netbox: { enabled: netboxEnabled },
           kubernetes: { enabled: kubernetesEnabled },
           jira: { enabled: jiraEnabled },
+          prometheus: { enabled: prometheusEnabled },
+          log_search: { enabled: logSearchEnabled },
+          http_check: { enabled: httpCheckEnabled },
         },
       };
 
@@ -274,6 +283,33 @@ export default function ProxySettings() {
             disabled={!hasProxy}
             onChange={setJiraEnabled}
           />
+          <ServiceToggle
+            name="Prometheus"
+            description="Metrics query API"
+            icon={LineChart}
+            enabled={prometheusEnabled}
+            supported={true}
+            disabled={!hasProxy}
+            onChange={setPrometheusEnabled}
+          />
+          <ServiceToggle
+            name="Log Search"
+            description="Loki and Elasticsearch"
+            icon={ScrollText}
+            enabled={logSearchEnabled}
+            supported={true}
+            disabled={!hasProxy}
+            onChange={setLogSearchEnabled}
+          />
+          <ServiceToggle
+            name="HTTP Check"
+            description="Synthetic endpoint probes"
+            icon={Globe}
+            enabled={httpCheckEnabled}
+            supported={true}
+            disabled={!hasProxy}
+            onChange={setHttpCheckEnabled}
+          />
           <ServiceToggle
             name="SSH"
             description="Remote server access"
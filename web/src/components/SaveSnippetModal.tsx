@@ -0,0 +1,163 @@
+import { useState, useEffect } from 'react';
+import { X, Loader2 } from 'lucide-react';
+import type { Skill } from '../types';
+import { incidentsApi, skillsApi } from '../api/client';
+
+interface SaveSnippetModalProps {
+  incidentUUID: string;
+  initialContent: string;
+  onClose: () => void;
+  onSaved: (message: string) => void;
+}
+
+// guessFilename suggests a script name from the command's leading word, e.g.
+// "psql -c ..." -> "psql-snippet.sh".
+const guessFilename = (content: string): string => {
+  const word = content.trim().split(/\s+/)[0]?.replace(/[^a-zA-Z0-9_-]/g, '') || 'snippet';
+  return `${word.toLowerCase()}-snippet.sh`;
+};
+
+// SaveSnippetModal promotes a command the agent ran during an incident into a
+// skill, either as a script or as a context reference linked from its prompt.
+export default function SaveSnippetModal({ incidentUUID, initialContent, onClose, onSaved }: SaveSnippetModalProps) {
+  const [skills, setSkills] = useState<Skill[]>([]);
+  const [skillName, setSkillName] = useState('');
+  const [kind, setKind] = useState<'script' | 'reference'>('script');
+  const [filename, setFilename] = useState(guessFilename(initialContent));
+  const [description, setDescription] = useState('');
+  const [content, setContent] = useState(initialContent);
+  const [overwrite, setOverwrite] = useState(false);
+  const [error, setError] = useState('');
+  const [submitting, setSubmitting] = useState(false);
+
+  useEffect(() => {
+    skillsApi.list()
+      .then(data => {
+        const editable = data.filter(s => !s.is_system);
+        setSkills(editable);
+        if (editable.length > 0) setSkillName(editable[0].name);
+      })
+      .catch(err => setError(err instanceof Error ? err.message : 'Failed to load skills'));
+  }, []);
+
+  useEffect(() => {
+    const handleEscape = (e: KeyboardEvent) => {
+      if (e.key === 'Escape' && !submitting) onClose();
+    };
+    document.addEventListener('keydown', handleEscape);
+    return () => document.removeEventListener('keydown', handleEscape);
+  }, [submitting, onClose]);
+
+  const handleSave = async () => {
+    setSubmitting(true);
+    setError('');
+    try {
+      const saved = await incidentsApi.exportSnippet(incidentUUID, {
+        skill_name: skillName,
+        kind,
+        filename,
+        content,
+        description: description || undefined,
+        overwrite: kind === 'script' ? overwrite : undefined,
+      });
+      onSaved(`Saved ${saved.filename} to ${saved.skill_name}`);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save snippet');
+      setSubmitting(false);
+    }
+  };
+
+  const inputClass = 'w-full px-3 py-2 rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-900 text-sm text-gray-800 dark:text-gray-100 focus:outline-none focus:ring-2 focus:ring-primary-500';
+
+  return (
+    <div className="fixed inset-0 z-50 overflow-y-auto" role="dialog" aria-modal="true" aria-labelledby="snippet-modal-title">
+      <div className="fixed inset-0 bg-black/50 transition-opacity" onClick={submitting ? undefined : onClose} />
+      <div className="flex min-h-full items-center justify-center p-4">
+        <div className="relative w-full max-w-lg bg-white dark:bg-gray-800 rounded-xl shadow-xl">
+          <div className="flex items-center justify-between px-6 py-4 border-b border-gray-200 dark:border-gray-700">
+            <h2 id="snippet-modal-title" className="text-base font-semibold text-gray-900 dark:text-gray-100">
+              Save as skill snippet
+            </h2>
+            <button
+              onClick={onClose}
+              disabled={submitting}
+              className="text-gray-400 hover:text-gray-600 dark:hover:text-gray-200 disabled:opacity-50"
+            >
+              <X className="w-5 h-5" />
+            </button>
+          </div>
+
+          <div className="p-6 space-y-4">
+            {error && (
+              <div className="px-3 py-2 rounded-lg bg-red-50 dark:bg-red-900/20 text-red-700 dark:text-red-300 text-sm">
+                {error}
+              </div>
+            )}
+
+            <div className="grid grid-cols-2 gap-3">
+              <label className="text-xs text-gray-500 dark:text-gray-400 space-y-1">
+                <span>Skill</span>
+                <select value={skillName} onChange={e => setSkillName(e.target.value)} className={inputClass}>
+                  {skills.map(s => <option key={s.name} value={s.name}>{s.name}</option>)}
+                </select>
+              </label>
+              <label className="text-xs text-gray-500 dark:text-gray-400 space-y-1">
+                <span>Save as</span>
+                <select value={kind} onChange={e => setKind(e.target.value as 'script' | 'reference')} className={inputClass}>
+                  <option value="script">Script</option>
+                  <option value="reference">Reference (context file)</option>
+                </select>
+              </label>
+            </div>
+
+            <label className="block text-xs text-gray-500 dark:text-gray-400 space-y-1">
+              <span>Filename</span>
+              <input value={filename} onChange={e => setFilename(e.target.value)} className={inputClass} />
+            </label>
+
+            <label className="block text-xs text-gray-500 dark:text-gray-400 space-y-1">
+              <span>Description</span>
+              <input
+                value={description}
+                onChange={e => setDescription(e.target.value)}
+                placeholder="What this checks and when to use it"
+                className={inputClass}
+              />
+            </label>
+
+            <label className="block text-xs text-gray-500 dark:text-gray-400 space-y-1">
+              <span>Content</span>
+              <textarea
+                value={content}
+                onChange={e => setContent(e.target.value)}
+                rows={8}
+                className={`${inputClass} font-mono text-xs`}
+              />
+            </label>
+
+            {kind === 'script' && (
+              <label className="flex items-center gap-2 text-sm text-gray-600 dark:text-gray-300">
+                <input type="checkbox" checked={overwrite} onChange={e => setOverwrite(e.target.checked)} />
+                Replace an existing script with the same name
+              </label>
+            )}
+
+            <div className="flex justify-end gap-2">
+              <button onClick={onClose} disabled={submitting} className="btn btn-secondary">
+                Cancel
+              </button>
+              <button
+                onClick={handleSave}
+                disabled={submitting || !skillName || !filename.trim() || !content.trim()}
+                className="btn btn-primary flex items-center gap-2"
+              >
+                {submitting && <Loader2 className="w-4 h-4 animate-spin" />}
+                Save
+              </button>
+            </div>
+          </div>
+        </div>
+      </div>
+    </div>
+  );
+}
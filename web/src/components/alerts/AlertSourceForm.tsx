@@ -1,5 +1,5 @@
 import { Save, X, Power, PowerOff } from 'lucide-react';
-import type { AlertSourceType } from '../../types';
+import type { AlertSourceType, Skill } from '../../types';
 import ChannelPicker from '../channels/ChannelPicker';
 import { visibleAlertSourceTypes, isWebhookSourceType } from './alertSourceHelpers';
 
@@ -13,10 +13,12 @@ interface AlertSourceFormProps {
     field_mappings: Record<string, string>;
     settings: Record<string, any>;
     notification_channel_uuid: string | null;
+    skill_names: string[];
     enabled: boolean;
   };
   setFormData: (data: any) => void;
   sourceTypes: AlertSourceType[];
+  skills: Skill[];
   selectedType: AlertSourceType | undefined;
   editingSource: any;
   onSave: () => void;
@@ -28,6 +30,7 @@ export default function AlertSourceForm({
   formData,
   setFormData,
   sourceTypes,
+  skills,
   selectedType,
   editingSource,
   onSave,
@@ -35,6 +38,17 @@ export default function AlertSourceForm({
 }: AlertSourceFormProps) {
   const pickerTypes = visibleAlertSourceTypes(sourceTypes);
 
+  const verification: Record<string, any> = formData.settings.webhook_verification || {};
+  const setVerification = (next: Record<string, any> | undefined) =>
+    setFormData({ ...formData, settings: { ...formData.settings, webhook_verification: next } });
+
+  const toggleSkill = (name: string, checked: boolean) => {
+    const next = checked
+      ? [...formData.skill_names, name]
+      : formData.skill_names.filter((n) => n !== name);
+    setFormData({ ...formData, skill_names: next });
+  };
+
   return (
     <div className="p-6 bg-gray-50 dark:bg-gray-900/50 rounded-lg border border-gray-200 dark:border-gray-700 animate-fade-in">
       <h3 className="text-lg font-semibold text-gray-900 dark:text-white mb-6">
@@ -108,12 +122,283 @@ export default function AlertSourceForm({
           </div>
         )}
 
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Allowed Source IPs
+            </label>
+            <input
+              type="text"
+              className="input-field"
+              placeholder="e.g., 10.0.0.0/8, 203.0.113.7 (empty allows any)"
+              value={
+                Array.isArray(formData.settings.allowed_cidrs)
+                  ? formData.settings.allowed_cidrs.join(', ')
+                  : formData.settings.allowed_cidrs || ''
+              }
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: { ...formData.settings, allowed_cidrs: e.target.value },
+                })
+              }
+            />
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Webhook requests from other addresses are rejected before the payload is parsed.
+            </p>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Webhook Verification
+            </label>
+            <div className="flex flex-wrap gap-3">
+              <select
+                className="input-field w-56"
+                value={verification.method || ''}
+                onChange={(e) => setVerification(e.target.value ? { method: e.target.value } : undefined)}
+              >
+                <option value="">Source default</option>
+                <option value="hmac">HMAC-SHA256 signature</option>
+                <option value="token">Static token</option>
+              </select>
+              {verification.method === 'hmac' && (
+                <>
+                  <select
+                    className="input-field w-40"
+                    value={verification.scheme || 'generic'}
+                    onChange={(e) => setVerification({ ...verification, scheme: e.target.value })}
+                  >
+                    <option value="grafana">Grafana</option>
+                    <option value="pagerduty">PagerDuty v3</option>
+                    <option value="sentry">Sentry</option>
+                    <option value="generic">Generic</option>
+                  </select>
+                  <input
+                    type="number"
+                    min={0}
+                    step={1}
+                    className="input-field w-48"
+                    placeholder="Tolerance (s), default 300"
+                    value={verification.tolerance_seconds ?? ''}
+                    onChange={(e) =>
+                      setVerification({
+                        ...verification,
+                        tolerance_seconds: e.target.value === '' ? undefined : parseInt(e.target.value, 10),
+                      })
+                    }
+                  />
+                  {['grafana', 'generic', undefined].includes(verification.scheme) && (
+                    <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300 cursor-pointer">
+                      <input
+                        type="checkbox"
+                        checked={verification.require_timestamp !== false}
+                        onChange={(e) =>
+                          setVerification({ ...verification, require_timestamp: e.target.checked ? undefined : false })
+                        }
+                      />
+                      Require signed timestamp
+                    </label>
+                  )}
+                </>
+              )}
+              {verification.method === 'token' && (
+                <input
+                  type="text"
+                  className="input-field w-56"
+                  placeholder="Header (default Authorization)"
+                  value={verification.header || ''}
+                  onChange={(e) => setVerification({ ...verification, header: e.target.value || undefined })}
+                />
+              )}
+            </div>
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Signatures and tokens are checked against the webhook secret. Signed timestamps outside the tolerance, and requests already received, are rejected as replays.
+            </p>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Silence Threshold (minutes)
+            </label>
+            <input
+              type="number"
+              min={0}
+              step={1}
+              className="input-field w-40"
+              placeholder="Off"
+              value={formData.settings.silence_threshold_minutes ?? ''}
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: {
+                    ...formData.settings,
+                    silence_threshold_minutes: e.target.value === '' ? undefined : parseInt(e.target.value, 10),
+                  },
+                })
+              }
+            />
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Opens a self-monitor incident when this source sends nothing for longer than this. Leave empty for sources that are normally quiet.
+            </p>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Enrichment Steps
+            </label>
+            <input
+              type="text"
+              className="input-field"
+              placeholder="All: cmdb, recent_changes, similar_incidents, runbook_match"
+              value={
+                Array.isArray(formData.settings.enrichment_steps)
+                  ? formData.settings.enrichment_steps.join(', ') || 'none'
+                  : formData.settings.enrichment_steps || ''
+              }
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: { ...formData.settings, enrichment_steps: e.target.value },
+                })
+              }
+            />
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Context gathered before the investigation starts, in this order. Leave empty for all steps, or enter "none" to skip enrichment.
+            </p>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div className="flex items-center gap-3">
+            <input
+              type="checkbox"
+              id="alert-source-plan-approval"
+              checked={formData.settings.plan_approval === true}
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: { ...formData.settings, plan_approval: e.target.checked || undefined },
+                })
+              }
+            />
+            <label htmlFor="alert-source-plan-approval" className="cursor-pointer">
+              <span className="text-sm text-gray-700 dark:text-gray-300">Require approval before remediation</span>
+              <span className="block text-xs text-gray-500 dark:text-gray-400">
+                The agent diagnoses with read-only actions and proposes a plan; it carries the plan out only once someone approves it in Slack or the UI.
+              </span>
+            </label>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div className="flex items-center gap-3">
+            <input
+              type="checkbox"
+              id="alert-source-fingerprint-dedup"
+              checked={formData.settings.fingerprint_dedup !== false}
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: { ...formData.settings, fingerprint_dedup: e.target.checked ? undefined : false },
+                })
+              }
+            />
+            <label htmlFor="alert-source-fingerprint-dedup" className="cursor-pointer">
+              <span className="text-sm text-gray-700 dark:text-gray-300">Deduplicate by fingerprint</span>
+              <span className="block text-xs text-gray-500 dark:text-gray-400">
+                An alert whose fingerprint already belongs to an open incident is attached to it without asking the correlator.
+              </span>
+            </label>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Grouping Key
+            </label>
+            <input
+              type="text"
+              className="input-field font-mono"
+              placeholder="e.g., {{.Host}}/{{.AlertName}}"
+              value={formData.settings.grouping_key_template || ''}
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: { ...formData.settings, grouping_key_template: e.target.value || undefined },
+                })
+              }
+            />
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Optional template that replaces the source's own fingerprint for deduplication, for systems that fingerprint the same alert inconsistently.
+            </p>
+          </div>
+        )}
+
+        {isWebhookSourceType(formData.source_type_name) && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Severity Inference
+            </label>
+            <select
+              className="input-field"
+              value={formData.settings.severity_inference || 'rules'}
+              onChange={(e) =>
+                setFormData({
+                  ...formData,
+                  settings: {
+                    ...formData.settings,
+                    severity_inference: e.target.value === 'rules' ? undefined : e.target.value,
+                  },
+                })
+              }
+            >
+              <option value="rules">Labels and keyword rules</option>
+              <option value="llm">Rules, then LLM</option>
+              <option value="off">Off</option>
+            </select>
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              How alerts that arrive without a usable severity get one before routing and notification.
+            </p>
+          </div>
+        )}
+
         <ChannelPicker
           label="Notification Channel"
           value={formData.notification_channel_uuid}
           onChange={(uuid) => setFormData({ ...formData, notification_channel_uuid: uuid })}
         />
 
+        {skills.length > 0 && (
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Skills
+            </label>
+            <div className="flex flex-wrap gap-x-4 gap-y-2">
+              {skills.map((skill) => (
+                <label key={skill.name} className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300 cursor-pointer">
+                  <input
+                    type="checkbox"
+                    checked={formData.skill_names.includes(skill.name)}
+                    onChange={(e) => toggleSkill(skill.name, e.target.checked)}
+                  />
+                  {skill.name}
+                </label>
+              ))}
+            </div>
+            <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+              Limits this source's investigations to the selected skills and their tools. Leave all unchecked to use every enabled skill.
+            </p>
+          </div>
+        )}
+
         <div className="flex items-center gap-3 p-4 rounded-lg bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700">
           <input
             type="checkbox"
@@ -0,0 +1,62 @@
+import { useEffect, useState } from 'react';
+import { Check, Copy } from 'lucide-react';
+import { alertSourcesApi } from '../../api/client';
+import type { AlertSourceConfigSnippet } from '../../types';
+
+interface ConfigSnippetPanelProps {
+  sourceUUID: string;
+}
+
+// ConfigSnippetPanel shows ready-to-paste sender configuration for an alert
+// source, with the webhook URL and secret already filled in.
+export default function ConfigSnippetPanel({ sourceUUID }: ConfigSnippetPanelProps) {
+  const [snippet, setSnippet] = useState<AlertSourceConfigSnippet | null>(null);
+  const [error, setError] = useState('');
+  const [copied, setCopied] = useState(false);
+
+  useEffect(() => {
+    let cancelled = false;
+    alertSourcesApi
+      .getConfigSnippet(sourceUUID)
+      .then((data) => !cancelled && setSnippet(data))
+      .catch((err) => !cancelled && setError(err instanceof Error ? err.message : 'Failed to load snippet'));
+    return () => {
+      cancelled = true;
+    };
+  }, [sourceUUID]);
+
+  const copy = async () => {
+    if (!snippet) return;
+    try {
+      await navigator.clipboard.writeText(snippet.snippet);
+      setCopied(true);
+      setTimeout(() => setCopied(false), 2000);
+    } catch (err) {
+      console.error('Failed to copy:', err);
+    }
+  };
+
+  if (error) {
+    return <p className="text-xs text-red-600 dark:text-red-400">{error}</p>;
+  }
+  if (!snippet) {
+    return null;
+  }
+
+  return (
+    <div>
+      <div className="flex items-center justify-between mb-2">
+        <h4 className="text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wide">
+          Sender Configuration ({snippet.format.toUpperCase()})
+        </h4>
+        <button onClick={copy} className={`copy-btn ${copied ? 'copied' : ''}`} title="Copy to clipboard">
+          {copied ? <Check className="w-4 h-4" /> : <Copy className="w-4 h-4" />}
+        </button>
+      </div>
+      <p className="text-xs text-gray-500 dark:text-gray-400 mb-2">{snippet.instructions}</p>
+      <pre className="font-mono text-xs text-primary-600 dark:text-primary-400 overflow-x-auto p-3 bg-white dark:bg-gray-800 rounded border border-gray-200 dark:border-gray-700">
+        {snippet.snippet}
+      </pre>
+    </div>
+  );
+}
@@ -3,6 +3,7 @@ import {
   SLACK_CHANNEL_TYPE_NAME,
   visibleAlertSourceTypes,
   isWebhookSourceType,
+  hasConfigSnippet,
 } from './alertSourceHelpers';
 import type { AlertSourceType } from '../../types';
 
@@ -56,3 +57,11 @@ describe('isWebhookSourceType', () => {
     expect(isWebhookSourceType('')).toBe(false);
   });
 });
+
+describe('hasConfigSnippet', () => {
+  it('offers sender configuration only for types the backend renders', () => {
+    expect(hasConfigSnippet('alertmanager')).toBe(true);
+    expect(hasConfigSnippet('datadog')).toBe(true);
+    expect(hasConfigSnippet('zabbix')).toBe(false);
+  });
+});
@@ -22,3 +22,11 @@ export function visibleAlertSourceTypes(types: AlertSourceType[]): AlertSourceTy
 export function isWebhookSourceType(typeName: string): boolean {
   return typeName !== '' && typeName !== SLACK_CHANNEL_TYPE_NAME;
 }
+
+// CONFIG_SNIPPET_TYPES are the source types GET
+// /api/alert-sources/{uuid}/config-snippet renders sender configuration for.
+const CONFIG_SNIPPET_TYPES = ['alertmanager', 'grafana', 'datadog'];
+
+export function hasConfigSnippet(typeName: string): boolean {
+  return CONFIG_SNIPPET_TYPES.includes(typeName);
+}
@@ -3,7 +3,7 @@ import { Plus, Save, X, Trash2, Edit2 } from 'lucide-react';
 import LoadingSpinner from '../LoadingSpinner';
 import ErrorMessage from '../ErrorMessage';
 import { channelsApi, integrationsApi } from '../../api/client';
-import type { Channel, Integration } from '../../types';
+import type { Channel, Integration, SlackProgressVerbosity } from '../../types';
 import {
   channelRoles,
   roleBadgeClass,
@@ -23,8 +23,22 @@ type FormState = {
   process_bot_messages: boolean;
   process_human_messages: boolean;
   enabled: boolean;
+  reaction_alert: string;
+  reaction_working: string;
+  reaction_success: string;
+  reaction_failure: string;
+  progress_verbosity: SlackProgressVerbosity | '';
 };
 
+// Slack reaction roles shown in the channel form, with the emoji used when
+// the field is left empty.
+const REACTION_FIELDS: { key: 'reaction_alert' | 'reaction_working' | 'reaction_success' | 'reaction_failure'; label: string; fallback: string }[] = [
+  { key: 'reaction_alert', label: 'Alert posted', fallback: 'rotating_light' },
+  { key: 'reaction_working', label: 'Investigating', fallback: 'hourglass_flowing_sand' },
+  { key: 'reaction_success', label: 'Succeeded', fallback: 'white_check_mark' },
+  { key: 'reaction_failure', label: 'Failed', fallback: 'x' },
+];
+
 const EMPTY_FORM: FormState = {
   integration_uuid: '',
   external_id: '',
@@ -36,6 +50,11 @@ const EMPTY_FORM: FormState = {
   process_bot_messages: true,
   process_human_messages: false,
   enabled: true,
+  reaction_alert: '',
+  reaction_working: '',
+  reaction_success: '',
+  reaction_failure: '',
+  progress_verbosity: '',
 };
 
 export default function ChannelsManager() {
@@ -92,6 +111,11 @@ export default function ChannelsManager() {
       process_bot_messages: row.process_bot_messages,
       process_human_messages: row.process_human_messages,
       enabled: row.enabled,
+      reaction_alert: row.reaction_alert ?? '',
+      reaction_working: row.reaction_working ?? '',
+      reaction_success: row.reaction_success ?? '',
+      reaction_failure: row.reaction_failure ?? '',
+      progress_verbosity: row.progress_verbosity ?? '',
     });
   };
 
@@ -101,6 +125,9 @@ export default function ChannelsManager() {
     setForm(EMPTY_FORM);
   };
 
+  const isSlackForm =
+    integrations.find((i) => i.uuid === form.integration_uuid)?.provider === 'slack';
+
   const save = async () => {
     try {
       setError(null);
@@ -124,6 +151,11 @@ export default function ChannelsManager() {
           process_bot_messages: form.process_bot_messages,
           process_human_messages: form.process_human_messages,
           enabled: form.enabled,
+          reaction_alert: form.reaction_alert.trim(),
+          reaction_working: form.reaction_working.trim(),
+          reaction_success: form.reaction_success.trim(),
+          reaction_failure: form.reaction_failure.trim(),
+          progress_verbosity: form.progress_verbosity,
         });
       } else if (editing) {
         await channelsApi.update(editing.uuid, {
@@ -136,6 +168,11 @@ export default function ChannelsManager() {
           process_bot_messages: form.process_bot_messages,
           process_human_messages: form.process_human_messages,
           enabled: form.enabled,
+          reaction_alert: form.reaction_alert.trim(),
+          reaction_working: form.reaction_working.trim(),
+          reaction_success: form.reaction_success.trim(),
+          reaction_failure: form.reaction_failure.trim(),
+          progress_verbosity: form.progress_verbosity,
         });
       }
       cancel();
@@ -275,6 +312,41 @@ export default function ChannelsManager() {
               )}
             </div>
 
+            {isSlackForm && form.can_post && (
+              <div className="space-y-3 p-3 rounded bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700">
+                <p className="text-sm font-medium text-gray-700 dark:text-gray-300">Slack reactions</p>
+                <div className="grid grid-cols-2 gap-3">
+                  {REACTION_FIELDS.map(({ key, label, fallback }) => (
+                    <label key={key} className="text-xs text-gray-500 dark:text-gray-400 space-y-1">
+                      <span>{label}</span>
+                      <input
+                        className="input-field"
+                        placeholder={fallback}
+                        value={form[key]}
+                        onChange={(e) => setForm({ ...form, [key]: e.target.value })}
+                      />
+                    </label>
+                  ))}
+                </div>
+                <p className="text-xs text-gray-500 dark:text-gray-400">
+                  Emoji names without colons. Leave empty for the default shown, or enter "none" to
+                  skip that reaction.
+                </p>
+                <label className="block text-xs text-gray-500 dark:text-gray-400 space-y-1">
+                  <span>Progress updates</span>
+                  <select
+                    className="input-field"
+                    value={form.progress_verbosity || 'status'}
+                    onChange={(e) => setForm({ ...form, progress_verbosity: e.target.value as SlackProgressVerbosity })}
+                  >
+                    <option value="off">Off — typing indicator and reactions only</option>
+                    <option value="status">Status — latest reasoning in the typing indicator</option>
+                    <option value="thread">Thread — also post periodic updates as replies</option>
+                  </select>
+                </label>
+              </div>
+            )}
+
             {form.can_listen && (
               <>
                 <div>
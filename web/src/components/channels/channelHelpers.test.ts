@@ -46,6 +46,11 @@ const makeChannel = (overrides: Partial<Channel>): Channel => ({
   process_bot_messages: true,
   process_human_messages: overrides.process_human_messages ?? false,
   enabled: overrides.enabled ?? true,
+  reaction_alert: '',
+  reaction_working: '',
+  reaction_success: '',
+  reaction_failure: '',
+  progress_verbosity: '',
   integration: overrides.integration ?? slackIntegration,
   created_at: '',
   updated_at: '',
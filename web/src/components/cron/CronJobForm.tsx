@@ -237,6 +237,23 @@ export default function CronJobForm({ isCreating, initial, onSave, onCancel }: C
           </p>
         </div>
 
+        <div>
+          <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+            Skills
+          </label>
+          <input
+            type="text"
+            className="input-field"
+            placeholder="e.g., cert-audit"
+            value={form.skill_names}
+            onChange={(e) => setForm({ ...form, skill_names: e.target.value })}
+          />
+          <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+            Comma-separated skills the run may use alongside cron-agent. Their assigned tools are
+            added to the allowlist above.
+          </p>
+        </div>
+
         <div className="flex items-center gap-3 p-4 rounded-lg bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700">
           <input
             type="checkbox"
@@ -8,6 +8,7 @@ import CronJobForm from './CronJobForm';
 import {
   lastRunBadge,
   formatRelativeTime,
+  parseSkillNames,
   type CronJobFormState,
 } from './cronJobHelpers';
 import { channelDisplayLabel, providerLabel } from '../channels/channelHelpers';
@@ -62,6 +63,7 @@ export default function CronJobsManager() {
         enabled: form.enabled,
         post_results: form.post_results,
         tool_instance_ids: form.tool_instance_ids,
+        skill_names: parseSkillNames(form.skill_names),
       });
     } else if (editing) {
       await cronJobsApi.update(editing.uuid, {
@@ -72,6 +74,7 @@ export default function CronJobsManager() {
         enabled: form.enabled,
         post_results: form.post_results,
         tool_instance_ids: form.tool_instance_ids,
+        skill_names: parseSkillNames(form.skill_names),
       });
     }
     cancel();
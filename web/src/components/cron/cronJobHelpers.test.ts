@@ -11,6 +11,7 @@ import {
   runStatusLabel,
   EMPTY_CRON_FORM,
   formStateFromJob,
+  parseSkillNames,
 } from './cronJobHelpers';
 import * as helpers from './cronJobHelpers';
 import type { CronJob } from '../../types';
@@ -33,6 +34,7 @@ const makeJob = (overrides: Partial<CronJob>): CronJob => ({
   updated_at: '',
   channel: overrides.channel ?? null,
   tools: overrides.tools ?? [],
+  skill_names: overrides.skill_names ?? [],
 });
 
 describe('SCHEDULE_PRESETS', () => {
@@ -256,6 +258,11 @@ describe('formStateFromJob', () => {
           process_bot_messages: true,
           process_human_messages: false,
           enabled: true,
+          reaction_alert: '',
+          reaction_working: '',
+          reaction_success: '',
+          reaction_failure: '',
+          progress_verbosity: '',
           created_at: '',
           updated_at: '',
         },
@@ -267,6 +274,13 @@ describe('formStateFromJob', () => {
     expect(state.tool_instance_ids).toEqual([]);
   });
 
+  it('joins pinned skill names for the text field', () => {
+    const state = formStateFromJob(makeJob({ skill_names: ['cert-audit', 'linux'] }));
+    expect(state.skill_names).toBe('cert-audit, linux');
+    expect(parseSkillNames(state.skill_names)).toEqual(['cert-audit', 'linux']);
+    expect(parseSkillNames(' , ')).toEqual([]);
+  });
+
   it('leaves channel_uuid null when the job has no channel association', () => {
     const state = formStateFromJob(makeJob({ channel: null }));
     expect(state.channel_uuid).toBeNull();
@@ -11,6 +11,8 @@ export interface CronJobFormState {
   enabled: boolean;
   post_results: boolean;
   tool_instance_ids: number[];
+  // Comma-separated skill names, split by parseSkillNames on save.
+  skill_names: string;
 }
 
 export const EMPTY_CRON_FORM: CronJobFormState = {
@@ -21,6 +23,7 @@ export const EMPTY_CRON_FORM: CronJobFormState = {
   enabled: true,
   post_results: true,
   tool_instance_ids: [],
+  skill_names: '',
 };
 
 // formStateFromJob lifts an existing CronJob row into the form's local state.
@@ -35,9 +38,19 @@ export function formStateFromJob(job: CronJob): CronJobFormState {
     enabled: job.enabled,
     post_results: job.post_results ?? true,
     tool_instance_ids: (job.tools ?? []).map((t) => t.id),
+    skill_names: (job.skill_names ?? []).join(', '),
   };
 }
 
+// parseSkillNames splits the form's comma-separated skill list into the
+// skill_names array the API expects, dropping blank entries.
+export function parseSkillNames(text: string): string[] {
+  return text
+    .split(',')
+    .map((s) => s.trim())
+    .filter((s) => s !== '');
+}
+
 // Schedule presets surfaced in the CronJobForm dropdown. The "advanced" entry
 // is sentinel-only; selecting it switches the form to a raw cron text input.
 export interface SchedulePreset {
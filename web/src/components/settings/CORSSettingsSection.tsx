@@ -0,0 +1,184 @@
+import { useState, useEffect } from 'react';
+import { Save, Info } from 'lucide-react';
+import LoadingSpinner from '../LoadingSpinner';
+import ErrorMessage from '../ErrorMessage';
+import { SuccessMessage } from '../ErrorMessage';
+import { corsSettingsApi } from '../../api/client';
+import type { CORSSettings } from '../../types';
+
+interface CORSSettingsSectionProps {
+  onStatusChange?: (status: 'configured' | 'disabled' | undefined) => void;
+}
+
+// One entry per line; blank lines are dropped.
+const toLines = (values: string[] | undefined) => (values ?? []).join('\n');
+const fromLines = (text: string) =>
+  text.split('\n').map((v) => v.trim()).filter((v) => v !== '');
+
+export default function CORSSettingsSection({ onStatusChange }: CORSSettingsSectionProps) {
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState(false);
+  const [enabled, setEnabled] = useState(false);
+  const [origins, setOrigins] = useState('');
+  const [headers, setHeaders] = useState('');
+  const [allowCredentials, setAllowCredentials] = useState(false);
+  const [effective, setEffective] = useState<CORSSettings['effective'] | null>(null);
+
+  useEffect(() => {
+    loadSettings();
+  }, []);
+
+  const applySettings = (data: CORSSettings) => {
+    setEnabled(data.enabled);
+    setOrigins(toLines(data.allowed_origins));
+    setHeaders(toLines(data.allowed_headers));
+    setAllowCredentials(data.allow_credentials);
+    setEffective(data.effective);
+    onStatusChange?.(data.enabled ? 'configured' : undefined);
+  };
+
+  const loadSettings = async () => {
+    try {
+      setLoading(true);
+      applySettings(await corsSettingsApi.get());
+      setError(null);
+    } catch (err) {
+      setError('Failed to load CORS settings');
+      console.error(err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      setSaving(true);
+      setError(null);
+      setSuccess(false);
+
+      applySettings(
+        await corsSettingsApi.update({
+          enabled,
+          allowed_origins: fromLines(origins),
+          allowed_headers: fromLines(headers),
+          allow_credentials: allowCredentials,
+        })
+      );
+      setSuccess(true);
+      setTimeout(() => setSuccess(false), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save CORS settings');
+      console.error(err);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (loading) {
+    return <LoadingSpinner />;
+  }
+
+  return (
+    <div className="space-y-5">
+      {error && <ErrorMessage message={error} />}
+      {success && <SuccessMessage message="CORS settings saved" />}
+
+      <div className="flex items-center justify-between">
+        <div>
+          <label className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+            Override environment policy
+          </label>
+          <p className="text-xs text-gray-500 dark:text-gray-400">
+            When off, the CORS_* environment variables apply
+          </p>
+        </div>
+        <button
+          type="button"
+          role="switch"
+          aria-checked={enabled}
+          onClick={() => setEnabled(!enabled)}
+          className={`relative inline-flex h-6 w-11 items-center rounded-full transition-colors ${
+            enabled ? 'bg-blue-600' : 'bg-gray-300 dark:bg-gray-600'
+          }`}
+        >
+          <span
+            className={`inline-block h-4 w-4 transform rounded-full bg-white transition-transform ${
+              enabled ? 'translate-x-6' : 'translate-x-1'
+            }`}
+          />
+        </button>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Allowed origins
+        </label>
+        <textarea
+          value={origins}
+          onChange={(e) => setOrigins(e.target.value)}
+          placeholder="https://ops.example.com"
+          rows={3}
+          disabled={!enabled}
+          className="input-field font-mono text-sm"
+        />
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          One origin per line. Leave empty to allow same-origin requests only; <code>*</code> allows any origin.
+        </p>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Allowed headers
+        </label>
+        <textarea
+          value={headers}
+          onChange={(e) => setHeaders(e.target.value)}
+          placeholder="Content-Type"
+          rows={3}
+          disabled={!enabled}
+          className="input-field font-mono text-sm"
+        />
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          One header per line. Leave empty for the default set.
+        </p>
+      </div>
+
+      <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+        <input
+          type="checkbox"
+          checked={allowCredentials}
+          onChange={(e) => setAllowCredentials(e.target.checked)}
+          disabled={!enabled}
+        />
+        Allow credentials (not with <code>*</code>)
+      </label>
+
+      {effective && (
+        <div className="text-xs text-gray-500 dark:text-gray-400 space-y-0.5">
+          <p>
+            In force (from {effective.source}):{' '}
+            {effective.allowed_origins.length > 0 ? effective.allowed_origins.join(', ') : 'same-origin only'}
+          </p>
+          <p>Methods: {effective.allowed_methods.join(', ')}</p>
+        </div>
+      )}
+
+      <div className="flex items-center justify-between pt-4 border-t border-gray-200 dark:border-gray-700">
+        <p className="text-xs text-gray-500 dark:text-gray-400 flex items-center gap-1.5">
+          <Info className="w-3.5 h-3.5" />
+          Other replicas pick up changes within a minute
+        </p>
+        <button
+          onClick={handleSave}
+          disabled={saving}
+          className="btn btn-primary"
+        >
+          <Save className="w-4 h-4" />
+          {saving ? 'Saving...' : 'Save'}
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -17,11 +17,18 @@ export default function GeneralSettingsSection({ onStatusChange }: GeneralSettin
   const [generalError, setGeneralError] = useState<string | null>(null);
   const [generalSuccess, setGeneralSuccess] = useState(false);
   const [instanceBaseUrl, setInstanceBaseUrl] = useState('');
+  const [shortLinksEnabled, setShortLinksEnabled] = useState(false);
 
   // Alert correlation fields
   const [correlationEnabled, setCorrelationEnabled] = useState(false);
   const [monitorWindowMinutes, setMonitorWindowMinutes] = useState(60);
   const [incidentMergeEnabled, setIncidentMergeEnabled] = useState(false);
+  const [autoCloseDays, setAutoCloseDays] = useState(0);
+
+  // Investigation confidence routing
+  const [reviewThreshold, setReviewThreshold] = useState(0.5);
+  const [reviewers, setReviewers] = useState('');
+  const [confidenceAutoClose, setConfidenceAutoClose] = useState(0);
 
   useEffect(() => {
     loadGeneralSettings();
@@ -33,9 +40,14 @@ export default function GeneralSettingsSection({ onStatusChange }: GeneralSettin
       const data = await generalSettingsApi.get();
       setGeneralSettings(data);
       setInstanceBaseUrl(data.base_url || '');
+      setShortLinksEnabled(data.short_links_enabled ?? false);
       setCorrelationEnabled(data.alert_correlation_enabled);
       setMonitorWindowMinutes(data.alert_monitor_window_minutes ?? 60);
       setIncidentMergeEnabled(data.incident_merge_enabled ?? false);
+      setAutoCloseDays(data.incident_auto_close_days ?? 0);
+      setReviewThreshold(data.confidence_review_threshold ?? 0.5);
+      setReviewers(data.confidence_reviewers || '');
+      setConfidenceAutoClose(data.confidence_auto_close_threshold ?? 0);
       setGeneralError(null);
       onStatusChange?.(data.base_url ? 'configured' : undefined);
     } catch (err) {
@@ -54,9 +66,14 @@ export default function GeneralSettingsSection({ onStatusChange }: GeneralSettin
 
       const updated = await generalSettingsApi.update({
         base_url: instanceBaseUrl,
+        short_links_enabled: shortLinksEnabled,
         alert_correlation_enabled: correlationEnabled,
         alert_monitor_window_minutes: monitorWindowMinutes,
         incident_merge_enabled: incidentMergeEnabled,
+        incident_auto_close_days: autoCloseDays,
+        confidence_review_threshold: reviewThreshold,
+        confidence_reviewers: reviewers,
+        confidence_auto_close_threshold: confidenceAutoClose,
       });
       setGeneralSettings(updated);
       onStatusChange?.(updated.base_url ? 'configured' : undefined);
@@ -93,6 +110,18 @@ export default function GeneralSettingsSection({ onStatusChange }: GeneralSettin
         <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
           External URL for accessing this Akmatori instance. Used in Slack message links.
         </p>
+        <div className="flex items-center gap-2 mt-3">
+          <input
+            id="short-links-enabled"
+            type="checkbox"
+            checked={shortLinksEnabled}
+            onChange={(e) => setShortLinksEnabled(e.target.checked)}
+            className="h-4 w-4 rounded border-gray-300 text-blue-600 focus:ring-blue-500"
+          />
+          <label htmlFor="short-links-enabled" className="text-sm text-gray-700 dark:text-gray-300">
+            Use short links (<code>/i/…</code>) in Slack, PagerDuty and notifications
+          </label>
+        </div>
       </div>
 
       {/* Alert Correlation */}
@@ -146,6 +175,81 @@ export default function GeneralSettingsSection({ onStatusChange }: GeneralSettin
         </div>
       </div>
 
+      {/* Stale incident auto-close */}
+      <div className="border-t border-gray-200 dark:border-gray-700 pt-4">
+        <h3 className="text-sm font-semibold text-gray-700 dark:text-gray-300 mb-3">Stale Incidents</h3>
+        <div className="w-1/3">
+          <label className="block text-xs font-medium text-gray-600 dark:text-gray-400 mb-1">
+            Auto-close after (days)
+          </label>
+          <input
+            type="number"
+            min={0}
+            max={365}
+            value={autoCloseDays}
+            onChange={(e) => setAutoCloseDays(Number(e.target.value))}
+            className="input-field text-sm"
+          />
+          <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+            Closes completed and monitored incidents with no new alerts or user activity for this long, with a note on the timeline. 0 turns it off.
+          </p>
+        </div>
+      </div>
+
+      {/* Investigation confidence */}
+      <div className="border-t border-gray-200 dark:border-gray-700 pt-4">
+        <h3 className="text-sm font-semibold text-gray-700 dark:text-gray-300 mb-3">Investigation Confidence</h3>
+        <p className="text-xs text-gray-500 dark:text-gray-400 mb-3">
+          Alert investigations end with a confidence score between 0 and 1. Set a threshold to 0 to turn it off.
+        </p>
+        <div className="grid grid-cols-3 gap-4">
+          <div>
+            <label className="block text-xs font-medium text-gray-600 dark:text-gray-400 mb-1">
+              Ask for review below
+            </label>
+            <input
+              type="number"
+              min={0}
+              max={1}
+              step={0.05}
+              value={reviewThreshold}
+              onChange={(e) => setReviewThreshold(Number(e.target.value))}
+              className="input-field text-sm"
+            />
+          </div>
+          <div>
+            <label className="block text-xs font-medium text-gray-600 dark:text-gray-400 mb-1">
+              Reviewers
+            </label>
+            <input
+              type="text"
+              maxLength={512}
+              value={reviewers}
+              onChange={(e) => setReviewers(e.target.value)}
+              placeholder="<!subteam^S0123> or <@U0123>"
+              className="input-field text-sm"
+            />
+          </div>
+          <div>
+            <label className="block text-xs font-medium text-gray-600 dark:text-gray-400 mb-1">
+              Auto-close at or above
+            </label>
+            <input
+              type="number"
+              min={0}
+              max={1}
+              step={0.05}
+              value={confidenceAutoClose}
+              onChange={(e) => setConfidenceAutoClose(Number(e.target.value))}
+              className="input-field text-sm"
+            />
+          </div>
+        </div>
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          Low-confidence results mention the reviewers in the Slack thread. Auto-close applies only to info and warning alerts that have resolved.
+        </p>
+      </div>
+
       <div className="flex items-center justify-between pt-4 border-t border-gray-200 dark:border-gray-700">
         <p className="text-xs text-gray-500 dark:text-gray-400 flex items-center gap-1.5">
           <Info className="w-3.5 h-3.5" />
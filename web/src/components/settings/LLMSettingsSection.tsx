@@ -1,10 +1,10 @@
 import { useState, useEffect } from 'react';
-import { Save, Plus, Trash2, Edit2, X, ChevronDown, ChevronRight } from 'lucide-react';
+import { Save, Plus, Trash2, Edit2, X, ChevronDown, ChevronRight, Activity } from 'lucide-react';
 import LoadingSpinner from '../LoadingSpinner';
 import ErrorMessage from '../ErrorMessage';
 import { SuccessMessage } from '../ErrorMessage';
 import { llmSettingsApi } from '../../api/client';
-import type { LLMConfig, LLMProvider, ThinkingLevel } from '../../types';
+import type { LLMConfig, LLMProvider, ThinkingLevel, UpdateLLMConfigRequest } from '../../types';
 import { MODEL_SUGGESTIONS } from './llmModelSuggestions';
 
 const THINKING_LEVELS: { value: ThinkingLevel; label: string }[] = [
@@ -26,6 +26,7 @@ const PROVIDER_OPTIONS: { value: LLMProvider; label: string }[] = [
   { value: 'minimax', label: 'MiniMax' },
   { value: 'ant-ling', label: 'Ant Ling' },
   { value: 'custom', label: 'Custom' },
+  { value: 'self-hosted', label: 'Self-hosted' },
 ];
 
 const PROVIDER_LABELS: Record<string, string> = Object.fromEntries(
@@ -45,6 +46,7 @@ interface FormState {
   model: string;
   thinkingLevel: ThinkingLevel;
   baseUrl: string;
+  contextWindow: string;
 }
 
 const emptyForm: FormState = {
@@ -54,6 +56,7 @@ const emptyForm: FormState = {
   model: 'gpt-5.5',
   thinkingLevel: 'medium',
   baseUrl: '',
+  contextWindow: '',
 };
 
 export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectionProps) {
@@ -69,6 +72,7 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
   const [form, setForm] = useState<FormState>(emptyForm);
   const [showAdvanced, setShowAdvanced] = useState(false);
   const [deleteConfirmId, setDeleteConfirmId] = useState<number | null>(null);
+  const [checkingId, setCheckingId] = useState<number | null>(null);
 
   useEffect(() => {
     loadConfigs();
@@ -113,11 +117,14 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
       model: config.model,
       thinkingLevel: config.thinking_level || 'medium',
       baseUrl: config.base_url || '',
+      contextWindow: config.context_window ? String(config.context_window) : '',
     });
     setFormMode('edit');
     setEditingId(config.id);
     setShowAdvanced(!!config.base_url || (config.thinking_level && config.thinking_level !== 'medium')
-      || config.provider === 'nvidia' || config.provider === 'minimax' || config.provider === 'ant-ling');
+      || !!config.context_window
+      || config.provider === 'nvidia' || config.provider === 'minimax' || config.provider === 'ant-ling'
+      || config.provider === 'self-hosted');
     setError(null);
   };
 
@@ -136,6 +143,10 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
       provider,
       model: recommended?.value ?? fallback,
     }));
+    // A self-hosted endpoint is unusable without its base URL.
+    if (provider === 'self-hosted') {
+      setShowAdvanced(true);
+    }
   };
 
   const handleSave = async () => {
@@ -151,14 +162,16 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
           model: form.model || undefined,
           thinking_level: form.thinkingLevel || undefined,
           base_url: form.baseUrl || undefined,
+          context_window: form.contextWindow ? Number(form.contextWindow) : undefined,
         });
         showSuccess('Configuration created');
       } else if (formMode === 'edit' && editingId) {
-        const updates: Record<string, string | undefined> = {};
+        const updates: UpdateLLMConfigRequest = {};
         updates.name = form.name;
         updates.model = form.model;
         updates.thinking_level = form.thinkingLevel;
         updates.base_url = form.baseUrl;
+        updates.context_window = form.contextWindow ? Number(form.contextWindow) : 0;
         if (form.apiKey && !form.apiKey.startsWith('****')) {
           updates.api_key = form.apiKey;
         }
@@ -204,6 +217,31 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
     }
   };
 
+  const handleHealthCheck = async (config: LLMConfig) => {
+    try {
+      setCheckingId(config.id);
+      setError(null);
+      const result = await llmSettingsApi.health(config.id);
+      if (!result.healthy) {
+        setError(`${config.name}: ${result.error || 'endpoint check failed'}`);
+        return;
+      }
+      const models = result.models?.length ?? 0;
+      let msg = `${config.name}: endpoint reachable in ${result.latency_ms} ms, ${models} model${models === 1 ? '' : 's'} listed`;
+      if (config.model && !result.model_available) {
+        msg += ` (${config.model} not among them)`;
+      }
+      if (result.context_window) {
+        msg += `, context window ${result.context_window.toLocaleString()} tokens`;
+      }
+      showSuccess(msg);
+    } catch (err: any) {
+      setError(err?.message || 'Failed to check endpoint');
+    } finally {
+      setCheckingId(null);
+    }
+  };
+
   const getApiKeyPlaceholder = (p: LLMProvider): string => {
     switch (p) {
       case 'openai': return 'sk-...';
@@ -213,12 +251,15 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
       case 'nvidia': return 'nvapi-...';
       case 'minimax': return 'Enter MiniMax API key';
       case 'ant-ling': return 'Enter Ant Ling API key';
+      case 'self-hosted': return 'Optional';
       default: return 'Enter API key';
     }
   };
 
+  const isSelfHosted = form.provider === 'self-hosted';
   const showBaseUrl = form.provider === 'custom' || form.provider === 'openrouter'
-    || form.provider === 'nvidia' || form.provider === 'minimax' || form.provider === 'ant-ling';
+    || form.provider === 'nvidia' || form.provider === 'minimax' || form.provider === 'ant-ling' || isSelfHosted;
+  const showContextWindow = form.provider === 'custom' || isSelfHosted;
 
   if (loading) {
     return <LoadingSpinner />;
@@ -287,7 +328,7 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
         {/* API Key */}
         <div>
           <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
-            API Key {isCreate && <span className="text-red-500">*</span>}
+            API Key {isCreate && !isSelfHosted && <span className="text-red-500">*</span>}
           </label>
           <input
             type="password"
@@ -309,7 +350,7 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
         {/* Model Selection */}
         <div>
           <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
-            Model
+            Model {isSelfHosted && <span className="text-red-500">*</span>}
           </label>
           {suggestions.length > 0 ? (
             <div>
@@ -361,7 +402,7 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
         >
           {showAdvanced ? <ChevronDown className="w-4 h-4" /> : <ChevronRight className="w-4 h-4" />}
           Advanced settings
-          {(form.thinkingLevel !== 'medium' || form.baseUrl) && (
+          {(form.thinkingLevel !== 'medium' || form.baseUrl || form.contextWindow) && (
             <span className="text-xs text-primary-600 dark:text-primary-400">(customized)</span>
           )}
         </button>
@@ -389,19 +430,41 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
             {showBaseUrl && (
               <div>
                 <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
-                  Base URL
+                  Base URL {isSelfHosted && <span className="text-red-500">*</span>}
                 </label>
                 <input
                   type="text"
                   value={form.baseUrl}
                   onChange={(e) => setForm(prev => ({ ...prev, baseUrl: e.target.value }))}
-                  placeholder={currentProvider === 'openrouter' ? 'https://openrouter.ai/api/v1' : 'https://your-endpoint.example.com/v1'}
+                  placeholder={currentProvider === 'openrouter' ? 'https://openrouter.ai/api/v1'
+                    : isSelfHosted ? 'http://vllm:8000/v1' : 'https://your-endpoint.example.com/v1'}
                   className="input-field"
                 />
                 <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
                   {currentProvider === 'openrouter'
                     ? 'OpenRouter API endpoint (defaults to https://openrouter.ai/api/v1)'
-                    : 'Custom OpenAI-compatible API endpoint'}
+                    : isSelfHosted
+                      ? 'OpenAI-compatible endpoint of your vLLM, Ollama or similar server, usually ending in /v1'
+                      : 'Custom OpenAI-compatible API endpoint'}
+                </p>
+              </div>
+            )}
+
+            {showContextWindow && (
+              <div>
+                <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+                  Context Window
+                </label>
+                <input
+                  type="number"
+                  min={0}
+                  value={form.contextWindow}
+                  onChange={(e) => setForm(prev => ({ ...prev, contextWindow: e.target.value }))}
+                  placeholder="128000"
+                  className="input-field"
+                />
+                <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+                  Tokens the model accepts; leave blank for 128k. Use Test connection to see what the server reports.
                 </p>
               </div>
             )}
@@ -415,7 +478,8 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
           </button>
           <button
             onClick={handleSave}
-            disabled={saving || !form.name.trim() || (isCreate && !form.apiKey)}
+            disabled={saving || !form.name.trim()
+              || (isSelfHosted ? !form.baseUrl.trim() || !form.model.trim() : isCreate && !form.apiKey)}
             className="btn btn-primary"
           >
             <Save className="w-4 h-4" />
@@ -456,6 +520,14 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
                 {activeConfig.base_url && ` \u00B7 Custom URL`}
               </p>
             </div>
+            <button
+              onClick={() => handleHealthCheck(activeConfig)}
+              disabled={checkingId !== null}
+              className="p-1.5 rounded text-gray-400 hover:text-gray-600 dark:hover:text-gray-300 hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
+              title="Test connection"
+            >
+              <Activity className={`w-4 h-4 ${checkingId === activeConfig.id ? 'animate-pulse' : ''}`} />
+            </button>
             <button
               onClick={() => openEditForm(activeConfig)}
               disabled={saving || formMode !== 'closed'}
@@ -516,6 +588,14 @@ export default function LLMSettingsSection({ onStatusChange }: LLMSettingsSectio
                   >
                     Activate
                   </button>
+                  <button
+                    onClick={() => handleHealthCheck(config)}
+                    disabled={checkingId !== null}
+                    className="p-1.5 rounded text-gray-400 hover:text-gray-600 dark:hover:text-gray-300 hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
+                    title="Test connection"
+                  >
+                    <Activity className={`w-4 h-4 ${checkingId === config.id ? 'animate-pulse' : ''}`} />
+                  </button>
                   <button
                     onClick={() => openEditForm(config)}
                     disabled={saving || formMode !== 'closed'}
@@ -0,0 +1,133 @@
+import { useState, useEffect } from 'react';
+import { Save, Info, Plus, Trash2 } from 'lucide-react';
+import LoadingSpinner from '../LoadingSpinner';
+import ErrorMessage from '../ErrorMessage';
+import { SuccessMessage } from '../ErrorMessage';
+import { modelPricesApi } from '../../api/client';
+import type { ModelPriceInput } from '../../types';
+
+interface ModelPricesSectionProps {
+  onStatusChange?: (status: 'configured' | 'disabled' | undefined) => void;
+}
+
+export default function ModelPricesSection({ onStatusChange }: ModelPricesSectionProps) {
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState(false);
+  const [rows, setRows] = useState<ModelPriceInput[]>([]);
+
+  useEffect(() => {
+    loadPrices();
+  }, []);
+
+  const loadPrices = async () => {
+    try {
+      setLoading(true);
+      const data = await modelPricesApi.list();
+      setRows(data.map((p) => ({ model: p.model, usd_per_million_tokens: p.usd_per_million_tokens })));
+      setError(null);
+      onStatusChange?.(data.length > 0 ? 'configured' : 'disabled');
+    } catch (err) {
+      setError('Failed to load model prices');
+      console.error(err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const updateRow = (index: number, patch: Partial<ModelPriceInput>) => {
+    setRows(rows.map((row, i) => (i === index ? { ...row, ...patch } : row)));
+  };
+
+  const handleSave = async () => {
+    try {
+      setSaving(true);
+      setError(null);
+      setSuccess(false);
+
+      const saved = await modelPricesApi.replace(rows.filter((row) => row.model.trim() !== ''));
+      setRows(saved.map((p) => ({ model: p.model, usd_per_million_tokens: p.usd_per_million_tokens })));
+      onStatusChange?.(saved.length > 0 ? 'configured' : 'disabled');
+      setSuccess(true);
+      setTimeout(() => setSuccess(false), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save model prices');
+      console.error(err);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (loading) {
+    return <LoadingSpinner />;
+  }
+
+  return (
+    <div className="space-y-5">
+      {error && <ErrorMessage message={error} />}
+      {success && <SuccessMessage message="Model prices saved" />}
+
+      <p className="text-xs text-gray-500 dark:text-gray-400">
+        Blended USD price per million tokens (input + output) for the active LLM model. Use an exact
+        model name, a prefix ending in <code>*</code> (e.g. <code>gpt-5*</code>), or <code>*</code> for
+        any other model. Investigations with no matching price show no cost estimate.
+      </p>
+
+      <div className="space-y-2">
+        {rows.map((row, index) => (
+          <div key={index} className="flex items-center gap-2">
+            <input
+              type="text"
+              value={row.model}
+              onChange={(e) => updateRow(index, { model: e.target.value })}
+              placeholder="Model or prefix*"
+              maxLength={100}
+              className="input-field flex-1"
+            />
+            <input
+              type="number"
+              min={0}
+              step={0.01}
+              value={row.usd_per_million_tokens}
+              onChange={(e) => updateRow(index, { usd_per_million_tokens: Math.max(0, parseFloat(e.target.value) || 0) })}
+              className="input-field w-36"
+              aria-label="USD per million tokens"
+            />
+            <button
+              type="button"
+              onClick={() => setRows(rows.filter((_, i) => i !== index))}
+              className="btn btn-ghost p-2"
+              title="Remove price"
+            >
+              <Trash2 className="w-4 h-4" />
+            </button>
+          </div>
+        ))}
+        <button
+          type="button"
+          onClick={() => setRows([...rows, { model: '', usd_per_million_tokens: 0 }])}
+          className="btn btn-secondary"
+        >
+          <Plus className="w-4 h-4" />
+          Add price
+        </button>
+      </div>
+
+      <div className="flex items-center justify-between pt-4 border-t border-gray-200 dark:border-gray-700">
+        <p className="text-xs text-gray-500 dark:text-gray-400 flex items-center gap-1.5">
+          <Info className="w-3.5 h-3.5" />
+          Applies to investigations that complete after saving
+        </p>
+        <button
+          onClick={handleSave}
+          disabled={saving}
+          className="btn btn-primary"
+        >
+          <Save className="w-4 h-4" />
+          {saving ? 'Saving...' : 'Save'}
+        </button>
+      </div>
+    </div>
+  );
+}
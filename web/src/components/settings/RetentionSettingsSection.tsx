@@ -1,9 +1,10 @@
 import { useState, useEffect } from 'react';
-import { Save, Info } from 'lucide-react';
+import { Save, Info, Trash2 } from 'lucide-react';
 import LoadingSpinner from '../LoadingSpinner';
 import ErrorMessage from '../ErrorMessage';
 import { SuccessMessage } from '../ErrorMessage';
 import { retentionSettingsApi } from '../../api/client';
+import type { WorkspaceUsage } from '../../types';
 
 interface RetentionSettingsSectionProps {
   onStatusChange?: (status: 'configured' | 'disabled' | undefined) => void;
@@ -17,6 +18,12 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
   const [enabled, setEnabled] = useState(true);
   const [retentionDays, setRetentionDays] = useState(90);
   const [cleanupIntervalHours, setCleanupIntervalHours] = useState(6);
+  const [workspaceMaxAgeDays, setWorkspaceMaxAgeDays] = useState(0);
+  const [workspaceMaxTotalMB, setWorkspaceMaxTotalMB] = useState(0);
+  const [archiveWorkspaces, setArchiveWorkspaces] = useState(false);
+  const [usage, setUsage] = useState<WorkspaceUsage | null>(null);
+  const [cleaning, setCleaning] = useState(false);
+  const [cleanupSummary, setCleanupSummary] = useState<string | null>(null);
 
   useEffect(() => {
     loadSettings();
@@ -29,8 +36,12 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
       setEnabled(data.enabled);
       setRetentionDays(data.retention_days);
       setCleanupIntervalHours(data.cleanup_interval_hours);
+      setWorkspaceMaxAgeDays(data.workspace_max_age_days);
+      setWorkspaceMaxTotalMB(data.workspace_max_total_mb);
+      setArchiveWorkspaces(data.archive_workspaces);
       setError(null);
       onStatusChange?.(data.enabled ? 'configured' : 'disabled');
+      loadUsage();
     } catch (err) {
       setError('Failed to load retention settings');
       console.error(err);
@@ -39,6 +50,41 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
     }
   };
 
+  const loadUsage = async () => {
+    try {
+      setUsage(await retentionSettingsApi.usage());
+    } catch (err) {
+      // Usage is informational; the settings stay editable without it.
+      console.error(err);
+    }
+  };
+
+  const handleCleanup = async () => {
+    try {
+      setCleaning(true);
+      setError(null);
+      setCleanupSummary(null);
+      const result = await retentionSettingsApi.cleanup();
+      if (result.skipped) {
+        setCleanupSummary('Cleanup skipped: automatic cleanup is disabled');
+      } else {
+        const freed = result.expired_bytes_freed + result.orphaned_bytes_freed + result.workspace_bytes_freed;
+        const removed = result.expired_dirs_deleted + result.orphaned_dirs_deleted + result.workspaces_pruned;
+        setCleanupSummary(
+          `Removed ${removed} workspace${removed === 1 ? '' : 's'} (${formatSize(freed)})` +
+            (result.workspaces_archived > 0 ? `, archived ${result.workspaces_archived}` : '') +
+            (result.errors.length > 0 ? `; ${result.errors.length} error${result.errors.length === 1 ? '' : 's'}: ${result.errors[0]}` : ''),
+        );
+      }
+      loadUsage();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to run cleanup');
+      console.error(err);
+    } finally {
+      setCleaning(false);
+    }
+  };
+
   const handleSave = async () => {
     try {
       setSaving(true);
@@ -49,6 +95,9 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
         enabled,
         retention_days: retentionDays,
         cleanup_interval_hours: cleanupIntervalHours,
+        workspace_max_age_days: workspaceMaxAgeDays,
+        workspace_max_total_mb: workspaceMaxTotalMB,
+        archive_workspaces: archiveWorkspaces,
       });
       onStatusChange?.(updated.enabled ? 'configured' : 'disabled');
       setSuccess(true);
@@ -132,6 +181,98 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
         </p>
       </div>
 
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Workspace max age (days)
+        </label>
+        <input
+          type="number"
+          min={0}
+          max={3650}
+          value={workspaceMaxAgeDays}
+          onChange={(e) => setWorkspaceMaxAgeDays(Math.min(3650, Math.max(0, parseInt(e.target.value) || 0)))}
+          disabled={!enabled}
+          className="input-field"
+        />
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          Delete working directories of finished incidents older than this, keeping the incident records. 0 keeps them until the incident itself expires.
+        </p>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Workspace size cap (MB)
+        </label>
+        <input
+          type="number"
+          min={0}
+          value={workspaceMaxTotalMB}
+          onChange={(e) => setWorkspaceMaxTotalMB(Math.max(0, parseInt(e.target.value) || 0))}
+          disabled={!enabled}
+          className="input-field"
+        />
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          When all workspaces together exceed this, the oldest finished ones are deleted first. 0 means no cap.
+        </p>
+      </div>
+
+      <div className="flex items-center justify-between">
+        <div>
+          <label className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+            Archive workspaces before deletion
+          </label>
+          <p className="text-xs text-gray-500 dark:text-gray-400">
+            Keep a .tar.gz of each workspace in the archives directory of the data dir
+          </p>
+        </div>
+        <button
+          type="button"
+          role="switch"
+          aria-checked={archiveWorkspaces}
+          onClick={() => setArchiveWorkspaces(!archiveWorkspaces)}
+          disabled={!enabled}
+          className={`relative inline-flex h-6 w-11 items-center rounded-full transition-colors ${
+            archiveWorkspaces ? 'bg-blue-600' : 'bg-gray-300 dark:bg-gray-600'
+          }`}
+        >
+          <span
+            className={`inline-block h-4 w-4 transform rounded-full bg-white transition-transform ${
+              archiveWorkspaces ? 'translate-x-6' : 'translate-x-1'
+            }`}
+          />
+        </button>
+      </div>
+
+      {usage && (
+        <div className="pt-4 border-t border-gray-200 dark:border-gray-700">
+          <div className="flex items-center justify-between mb-2">
+            <p className="text-sm font-medium text-gray-700 dark:text-gray-300">
+              Disk usage: {formatSize(usage.total_bytes)} in {usage.workspace_count} workspace{usage.workspace_count === 1 ? '' : 's'}
+              {usage.archive_count > 0 && `, ${formatSize(usage.archive_bytes)} in ${usage.archive_count} archive${usage.archive_count === 1 ? '' : 's'}`}
+            </p>
+            <button onClick={handleCleanup} disabled={cleaning} className="btn btn-secondary">
+              <Trash2 className="w-4 h-4" />
+              {cleaning ? 'Cleaning...' : 'Run cleanup now'}
+            </button>
+          </div>
+          {cleanupSummary && (
+            <p className="mb-2 text-xs text-gray-600 dark:text-gray-400">{cleanupSummary}</p>
+          )}
+          {usage.largest.length > 0 && (
+            <ul className="space-y-1 text-xs text-gray-500 dark:text-gray-400">
+              {usage.largest.map((w) => (
+                <li key={w.incident_uuid} className="flex justify-between font-mono">
+                  <span>
+                    {w.incident_uuid} {w.status ? `(${w.status})` : '(no incident)'}
+                  </span>
+                  <span>{formatSize(w.bytes)}</span>
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
+      )}
+
       <div className="flex items-center justify-between pt-4 border-t border-gray-200 dark:border-gray-700">
         <p className="text-xs text-gray-500 dark:text-gray-400 flex items-center gap-1.5">
           <Info className="w-3.5 h-3.5" />
@@ -149,3 +290,10 @@ export default function RetentionSettingsSection({ onStatusChange }: RetentionSe
     </div>
   );
 }
+
+function formatSize(bytes: number): string {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  if (bytes < 1024 * 1024 * 1024) return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+  return `${(bytes / (1024 * 1024 * 1024)).toFixed(1)} GB`;
+}
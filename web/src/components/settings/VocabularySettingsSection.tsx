@@ -0,0 +1,200 @@
+import { useState, useEffect } from 'react';
+import { Save, Info } from 'lucide-react';
+import LoadingSpinner from '../LoadingSpinner';
+import ErrorMessage from '../ErrorMessage';
+import { SuccessMessage } from '../ErrorMessage';
+import { vocabularySettingsApi } from '../../api/client';
+
+interface VocabularySettingsSectionProps {
+  onStatusChange?: (status: 'configured' | 'disabled' | undefined) => void;
+}
+
+const SEVERITIES: { key: string; builtIn: string }[] = [
+  { key: 'critical', builtIn: ':red_circle:' },
+  { key: 'high', builtIn: ':large_orange_circle:' },
+  { key: 'warning', builtIn: ':large_yellow_circle:' },
+  { key: 'info', builtIn: ':large_blue_circle:' },
+];
+
+const INCIDENT_STATUSES = [
+  'pending',
+  'running',
+  'diagnosed',
+  'completed',
+  'failed',
+  'monitor',
+  'closed',
+  'merged',
+  'budget_exceeded',
+  'cancelled',
+];
+
+// Investigation results shown in the Slack reply header.
+const RESULT_STATUSES = ['resolved', 'unresolved', 'escalate'];
+
+// Drop blank entries so clearing a field restores the built-in value.
+const compact = (m: Record<string, string>) =>
+  Object.fromEntries(Object.entries(m).filter(([, v]) => v.trim() !== ''));
+
+export default function VocabularySettingsSection({ onStatusChange }: VocabularySettingsSectionProps) {
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState(false);
+  const [severityEmoji, setSeverityEmoji] = useState<Record<string, string>>({});
+  const [statusLabels, setStatusLabels] = useState<Record<string, string>>({});
+  const [statusColors, setStatusColors] = useState<Record<string, string>>({});
+
+  useEffect(() => {
+    loadSettings();
+  }, []);
+
+  const reportStatus = (...maps: Record<string, string>[]) => {
+    onStatusChange?.(maps.some((m) => Object.keys(m).length > 0) ? 'configured' : undefined);
+  };
+
+  const loadSettings = async () => {
+    try {
+      setLoading(true);
+      const data = await vocabularySettingsApi.get();
+      setSeverityEmoji(data.severity_emoji ?? {});
+      setStatusLabels(data.status_labels ?? {});
+      setStatusColors(data.status_colors ?? {});
+      setError(null);
+      reportStatus(data.severity_emoji ?? {}, data.status_labels ?? {}, data.status_colors ?? {});
+    } catch (err) {
+      setError('Failed to load vocabulary settings');
+      console.error(err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleSave = async () => {
+    try {
+      setSaving(true);
+      setError(null);
+      setSuccess(false);
+
+      const updated = await vocabularySettingsApi.update({
+        severity_emoji: compact(severityEmoji),
+        status_labels: compact(statusLabels),
+        status_colors: compact(statusColors),
+      });
+      setSeverityEmoji(updated.severity_emoji ?? {});
+      setStatusLabels(updated.status_labels ?? {});
+      setStatusColors(updated.status_colors ?? {});
+      reportStatus(updated.severity_emoji ?? {}, updated.status_labels ?? {}, updated.status_colors ?? {});
+      setSuccess(true);
+      setTimeout(() => setSuccess(false), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save vocabulary settings');
+      console.error(err);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (loading) {
+    return <LoadingSpinner />;
+  }
+
+  return (
+    <div className="space-y-5">
+      {error && <ErrorMessage message={error} />}
+      {success && <SuccessMessage message="Vocabulary saved" />}
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Severity emoji
+        </label>
+        <div className="space-y-2">
+          {SEVERITIES.map(({ key, builtIn }) => (
+            <div key={key} className="flex items-center gap-2">
+              <span className="w-40 text-sm text-gray-600 dark:text-gray-400">{key}</span>
+              <input
+                type="text"
+                value={severityEmoji[key] ?? ''}
+                placeholder={builtIn}
+                onChange={(e) => setSeverityEmoji({ ...severityEmoji, [key]: e.target.value })}
+                className="input-field flex-1"
+              />
+            </div>
+          ))}
+        </div>
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          Used in Slack alert notifications. Slack emoji codes such as <code>:fire:</code> or plain emoji.
+        </p>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Incident statuses
+        </label>
+        <div className="space-y-2">
+          {INCIDENT_STATUSES.map((status) => (
+            <div key={status} className="flex items-center gap-2">
+              <span className="w-40 text-sm text-gray-600 dark:text-gray-400">{status}</span>
+              <input
+                type="text"
+                value={statusLabels[status] ?? ''}
+                placeholder="Display name"
+                onChange={(e) => setStatusLabels({ ...statusLabels, [status]: e.target.value })}
+                className="input-field flex-1"
+              />
+              <input
+                type="text"
+                value={statusColors[status] ?? ''}
+                placeholder="#rrggbb"
+                onChange={(e) => setStatusColors({ ...statusColors, [status]: e.target.value })}
+                className="input-field w-28"
+              />
+            </div>
+          ))}
+        </div>
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          Names and badge colors for incident statuses in the UI and the API (<code>status_label</code>,{' '}
+          <code>status_color</code>).
+        </p>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+          Investigation results
+        </label>
+        <div className="space-y-2">
+          {RESULT_STATUSES.map((status) => (
+            <div key={status} className="flex items-center gap-2">
+              <span className="w-40 text-sm text-gray-600 dark:text-gray-400">{status}</span>
+              <input
+                type="text"
+                value={statusLabels[status] ?? ''}
+                placeholder="Display name"
+                onChange={(e) => setStatusLabels({ ...statusLabels, [status]: e.target.value })}
+                className="input-field flex-1"
+              />
+            </div>
+          ))}
+        </div>
+        <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+          Heading of the agent's final result in Slack replies.
+        </p>
+      </div>
+
+      <div className="flex items-center justify-between pt-4 border-t border-gray-200 dark:border-gray-700">
+        <p className="text-xs text-gray-500 dark:text-gray-400 flex items-center gap-1.5">
+          <Info className="w-3.5 h-3.5" />
+          Leave a field empty to keep the built-in value
+        </p>
+        <button
+          onClick={handleSave}
+          disabled={saving}
+          className="btn btn-primary"
+        >
+          <Save className="w-4 h-4" />
+          {saving ? 'Saving...' : 'Save'}
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -54,4 +54,5 @@ export const MODEL_SUGGESTIONS: Record<LLMProvider, { value: string; label: stri
     { value: 'Ring-2.6-1T', label: 'Ring-2.6-1T' },
   ],
   custom: [],
+  'self-hosted': [],
 };
@@ -170,6 +170,33 @@ export default function SSHHostsSection({
                 </div>
               )}
 
+              {/* Password and Agent Forwarding */}
+              <div className="grid grid-cols-2 gap-4">
+                <div>
+                  <label className="block text-xs text-gray-500 dark:text-gray-400 mb-1">
+                    Password <span className="text-gray-400">(tried after keys)</span>
+                  </label>
+                  <input
+                    type="password"
+                    className="input-field"
+                    autoComplete="new-password"
+                    value={host.password || ''}
+                    onChange={(e) => onUpdateHost(index, 'password', e.target.value || undefined)}
+                  />
+                </div>
+                <div className="flex items-end">
+                  <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300 pb-2">
+                    <input
+                      type="checkbox"
+                      checked={host.forward_agent || false}
+                      onChange={(e) => onUpdateHost(index, 'forward_agent', e.target.checked)}
+                      className="w-4 h-4"
+                    />
+                    Forward SSH agent
+                  </label>
+                </div>
+              </div>
+
               {/* Jumphost Configuration */}
               <div className="bg-gray-50 dark:bg-gray-900/50 rounded-lg p-3">
                 <p className="text-xs font-medium text-gray-700 dark:text-gray-300 mb-3">
@@ -1,4 +1,4 @@
-import { Plus, Save, X, Trash2, Key, Star } from 'lucide-react';
+import { Plus, Save, X, Trash2, Key, Star, Lock } from 'lucide-react';
 import type { SSHKey } from '../../types';
 
 interface SSHKeysSectionProps {
@@ -12,6 +12,8 @@ interface SSHKeysSectionProps {
   setNewKeyName: (name: string) => void;
   newKeyValue: string;
   setNewKeyValue: (value: string) => void;
+  newKeyPassphrase: string;
+  setNewKeyPassphrase: (value: string) => void;
   newKeyIsDefault: boolean;
   setNewKeyIsDefault: (isDefault: boolean) => void;
   onAddSSHKey: () => void;
@@ -31,6 +33,8 @@ export default function SSHKeysSection({
   setNewKeyName,
   newKeyValue,
   setNewKeyValue,
+  newKeyPassphrase,
+  setNewKeyPassphrase,
   newKeyIsDefault,
   setNewKeyIsDefault,
   onAddSSHKey,
@@ -77,7 +81,7 @@ export default function SSHKeysSection({
             </div>
             <div>
               <label className="block text-xs text-gray-500 dark:text-gray-400 mb-1">
-                Private Key (PEM format) <span className="text-red-500">*</span>
+                Private Key (PEM or OpenSSH format) <span className="text-red-500">*</span>
               </label>
               <textarea
                 className="input-field min-h-[120px] font-mono text-sm"
@@ -86,6 +90,18 @@ export default function SSHKeysSection({
                 onChange={(e) => setNewKeyValue(e.target.value)}
               />
             </div>
+            <div>
+              <label className="block text-xs text-gray-500 dark:text-gray-400 mb-1">
+                Passphrase <span className="text-gray-400">(only for encrypted keys)</span>
+              </label>
+              <input
+                type="password"
+                className="input-field"
+                autoComplete="new-password"
+                value={newKeyPassphrase}
+                onChange={(e) => setNewKeyPassphrase(e.target.value)}
+              />
+            </div>
             <div className="flex items-center gap-2">
               <input
                 type="checkbox"
@@ -111,6 +127,7 @@ export default function SSHKeysSection({
                   setShowAddKey(false);
                   setNewKeyName('');
                   setNewKeyValue('');
+                  setNewKeyPassphrase('');
                   setNewKeyIsDefault(false);
                 }}
                 className="btn btn-sm btn-secondary"
@@ -151,6 +168,11 @@ export default function SSHKeysSection({
                 <tr key={key.id} className="hover:bg-gray-50 dark:hover:bg-gray-800/50">
                   <td className="px-4 py-2 text-gray-900 dark:text-white font-medium">
                     {key.name}
+                    {key.has_passphrase && (
+                      <span title="Encrypted with a passphrase">
+                        <Lock className="w-3 h-3 inline ml-1.5 text-gray-400" />
+                      </span>
+                    )}
                   </td>
                   <td className="px-4 py-2">
                     {key.is_default ? (
@@ -0,0 +1,114 @@
+import { useCallback, useEffect, useState } from 'react';
+import { ShieldCheck, Trash2 } from 'lucide-react';
+import type { SSHKnownHost } from '../../types';
+import { sshKnownHostsApi } from '../../api/client';
+
+interface SSHKnownHostsSectionProps {
+  toolId: number;
+}
+
+// SSHKnownHostsSection lists the host keys the gateway pinned for this SSH
+// instance. Clearing a pin lets the next connection trust whatever key the
+// host presents, e.g. after a server rebuild.
+export default function SSHKnownHostsSection({ toolId }: SSHKnownHostsSectionProps) {
+  const [hosts, setHosts] = useState<SSHKnownHost[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState('');
+
+  const load = useCallback(async () => {
+    try {
+      setError('');
+      setHosts(await sshKnownHostsApi.list(toolId));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to load pinned host keys');
+    } finally {
+      setLoading(false);
+    }
+  }, [toolId]);
+
+  useEffect(() => {
+    load();
+  }, [load]);
+
+  const remove = async (host: SSHKnownHost) => {
+    if (!confirm(`Forget the pinned key for ${host.host}?`)) return;
+    try {
+      await sshKnownHostsApi.delete(toolId, host.id);
+      load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to delete pinned host key');
+    }
+  };
+
+  const clearAll = async () => {
+    if (!confirm('Forget all pinned host keys for this instance?')) return;
+    try {
+      await sshKnownHostsApi.clearAll(toolId);
+      load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to clear pinned host keys');
+    }
+  };
+
+  return (
+    <div className="space-y-3 mb-6">
+      <div className="flex items-center justify-between">
+        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+          <ShieldCheck className="w-4 h-4 inline mr-1" />
+          Pinned Host Keys
+        </label>
+        {hosts.length > 0 && (
+          <button type="button" onClick={clearAll} className="btn btn-sm btn-secondary">
+            Clear all
+          </button>
+        )}
+      </div>
+
+      {error && <p className="text-sm text-red-600 dark:text-red-400">{error}</p>}
+
+      {loading ? (
+        <div className="text-center py-4 text-gray-500">Loading host keys...</div>
+      ) : hosts.length === 0 ? (
+        <p className="text-sm text-gray-500 dark:text-gray-400">
+          No host keys pinned yet. With the auto_add policy, each host's key is pinned on first connection.
+        </p>
+      ) : (
+        <div className="border border-gray-200 dark:border-gray-700 rounded-lg overflow-hidden">
+          <table className="w-full text-sm">
+            <thead className="bg-gray-50 dark:bg-gray-800">
+              <tr>
+                <th className="px-4 py-2 text-left text-gray-600 dark:text-gray-300">Host</th>
+                <th className="px-4 py-2 text-left text-gray-600 dark:text-gray-300">Fingerprint</th>
+                <th className="px-4 py-2 text-left text-gray-600 dark:text-gray-300">Last seen</th>
+                <th className="px-4 py-2 text-right text-gray-600 dark:text-gray-300">Actions</th>
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-gray-200 dark:divide-gray-700">
+              {hosts.map((host) => (
+                <tr key={host.id} className="hover:bg-gray-50 dark:hover:bg-gray-800/50">
+                  <td className="px-4 py-2 text-gray-900 dark:text-white font-medium">{host.host}</td>
+                  <td className="px-4 py-2 font-mono text-xs text-gray-600 dark:text-gray-300" title={host.key_type}>
+                    {host.fingerprint}
+                  </td>
+                  <td className="px-4 py-2 text-gray-500 dark:text-gray-400">
+                    {new Date(host.last_seen_at).toLocaleString()}
+                  </td>
+                  <td className="px-4 py-2 text-right">
+                    <button
+                      type="button"
+                      onClick={() => remove(host)}
+                      className="text-red-500 hover:text-red-700 p-1"
+                      title="Forget host key"
+                    >
+                      <Trash2 className="w-4 h-4" />
+                    </button>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      )}
+    </div>
+  );
+}
@@ -1,6 +1,7 @@
 import { Save, X, Power, PowerOff, ChevronDown, ChevronUp, AlertTriangle } from 'lucide-react';
 import type { ToolType, SSHKey } from '../../types';
 import SSHKeysSection from './SSHKeysSection';
+import SSHKnownHostsSection from './SSHKnownHostsSection';
 import SSHHostsSection from './SSHHostsSection';
 import { useState, useEffect } from 'react';
 
@@ -30,6 +31,8 @@ interface ToolFormSectionProps {
   setNewKeyName: (name: string) => void;
   newKeyValue: string;
   setNewKeyValue: (value: string) => void;
+  newKeyPassphrase: string;
+  setNewKeyPassphrase: (value: string) => void;
   newKeyIsDefault: boolean;
   setNewKeyIsDefault: (isDefault: boolean) => void;
   onAddSSHKey: () => void;
@@ -75,6 +78,8 @@ export default function ToolFormSection({
   setNewKeyName,
   newKeyValue,
   setNewKeyValue,
+  newKeyPassphrase,
+  setNewKeyPassphrase,
   newKeyIsDefault,
   setNewKeyIsDefault,
   onAddSSHKey,
@@ -262,6 +267,8 @@ export default function ToolFormSection({
                   setNewKeyName={setNewKeyName}
                   newKeyValue={newKeyValue}
                   setNewKeyValue={setNewKeyValue}
+                  newKeyPassphrase={newKeyPassphrase}
+                  setNewKeyPassphrase={setNewKeyPassphrase}
                   newKeyIsDefault={newKeyIsDefault}
                   setNewKeyIsDefault={setNewKeyIsDefault}
                   onAddSSHKey={onAddSSHKey}
@@ -285,6 +292,11 @@ export default function ToolFormSection({
                 />
               )}
 
+              {/* Pinned host keys - only once the instance exists */}
+              {selectedType.name === 'ssh' && editingToolId && !isCreating && (
+                <SSHKnownHostsSection toolId={editingToolId} />
+              )}
+
               {/* Basic (non-advanced) properties */}
               {(() => {
                 const { basicProps, advancedProps } = getSchemaProperties(selectedSchema.settings_schema);
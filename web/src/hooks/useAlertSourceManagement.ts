@@ -1,6 +1,6 @@
 import { useState, useEffect, useCallback } from 'react';
-import { alertSourceTypesApi, alertSourcesApi, channelsApi } from '../api/client';
-import type { AlertSourceType, AlertSourceInstance, Channel } from '../types';
+import { alertSourceTypesApi, alertSourcesApi, channelsApi, skillsApi } from '../api/client';
+import type { AlertSourceType, AlertSourceInstance, Channel, Skill } from '../types';
 import { visibleAlertSourceTypes } from '../components/alerts/alertSourceHelpers';
 
 interface AlertSourceFormData {
@@ -11,6 +11,7 @@ interface AlertSourceFormData {
   field_mappings: Record<string, string>;
   settings: Record<string, any>;
   notification_channel_uuid: string | null;
+  skill_names: string[];
   enabled: boolean;
 }
 
@@ -22,6 +23,7 @@ const EMPTY_FORM: AlertSourceFormData = {
   field_mappings: {},
   settings: {},
   notification_channel_uuid: null,
+  skill_names: [],
   enabled: true,
 };
 
@@ -29,26 +31,31 @@ export function useAlertSourceManagement() {
   const [sources, setSources] = useState<AlertSourceInstance[]>([]);
   const [sourceTypes, setSourceTypes] = useState<AlertSourceType[]>([]);
   const [channels, setChannels] = useState<Channel[]>([]);
+  const [skills, setSkills] = useState<Skill[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [editingSource, setEditingSource] = useState<AlertSourceInstance | null>(null);
   const [isCreating, setIsCreating] = useState(false);
   const [expandedSource, setExpandedSource] = useState<string | null>(null);
   const [copiedUrl, setCopiedUrl] = useState<string | null>(null);
+  const [provisioning, setProvisioning] = useState<string | null>(null);
+  const [provisionNotice, setProvisionNotice] = useState<{ uuid: string; text: string } | null>(null);
   const [formData, setFormData] = useState<AlertSourceFormData>(EMPTY_FORM);
 
   const loadData = useCallback(async () => {
     try {
       setLoading(true);
       setError('');
-      const [sourcesData, typesData, channelsData] = await Promise.all([
+      const [sourcesData, typesData, channelsData, skillsData] = await Promise.all([
         alertSourcesApi.list(),
         alertSourceTypesApi.list(),
         channelsApi.list().catch(() => [] as Channel[]),
+        skillsApi.list().catch(() => [] as Skill[]),
       ]);
       setSources(sourcesData);
       setSourceTypes(typesData);
       setChannels(channelsData);
+      setSkills(skillsData.filter((s) => !s.is_system));
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load data');
     } finally {
@@ -86,6 +93,7 @@ export function useAlertSourceManagement() {
         field_mappings: source.field_mappings || {},
         settings: source.settings || {},
         notification_channel_uuid: channelUUIDByID(source.notification_channel_id ?? null),
+        skill_names: (source.skills || []).map((s) => s.name),
         enabled: source.enabled,
       });
       setIsCreating(false);
@@ -111,6 +119,7 @@ export function useAlertSourceManagement() {
           field_mappings: formData.field_mappings,
           settings: formData.settings,
           notification_channel_uuid: formData.notification_channel_uuid,
+          skill_names: formData.skill_names,
         });
       } else if (editingSource) {
         await alertSourcesApi.update(editingSource.uuid, {
@@ -121,6 +130,7 @@ export function useAlertSourceManagement() {
           settings: formData.settings,
           enabled: formData.enabled,
           notification_channel_uuid: formData.notification_channel_uuid ?? '',
+          skill_names: formData.skill_names,
         });
       }
 
@@ -169,12 +179,34 @@ export function useAlertSourceManagement() {
     }
   }, []);
 
+  const provisionZabbix = useCallback(async (source: AlertSourceInstance) => {
+    if (!confirm(`Create or update the Akmatori webhook media type and action for "${source.name}" on the Zabbix server?`)) return;
+
+    try {
+      setError('');
+      setProvisionNotice(null);
+      setProvisioning(source.uuid);
+      const result = await alertSourcesApi.provisionZabbix(source.uuid);
+      const verb = (created: boolean) => (created ? 'created' : 'updated');
+      setProvisionNotice({
+        uuid: source.uuid,
+        text: `Zabbix ${result.zabbix_version}: media type "${result.media_type_name}" ${verb(result.media_type_created)}, ` +
+          `action "${result.action_name}" ${verb(result.action_created)}, notifying ${result.username}.`,
+      });
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to provision Zabbix');
+    } finally {
+      setProvisioning(null);
+    }
+  }, []);
+
   const selectedType = sourceTypes.find((t) => t.name === formData.source_type_name);
 
   return {
     sources,
     sourceTypes,
     channels,
+    skills,
     loading,
     error,
     editingSource,
@@ -191,5 +223,8 @@ export function useAlertSourceManagement() {
     handleCancel,
     toggleExpand,
     copyWebhookUrl,
+    provisioning,
+    provisionNotice,
+    provisionZabbix,
   };
 }
@@ -0,0 +1,74 @@
+import { useEffect, useRef, useState } from 'react';
+import { incidentsApi } from '../api/client';
+
+// Characters fetched per request while catching up on a log.
+const LOG_CHUNK_SIZE = 256 * 1024;
+const LOG_POLL_INTERVAL_MS = 2000;
+
+interface UseIncidentLogReturn {
+  log: string;
+  loading: boolean;
+  error: string;
+}
+
+/**
+ * Loads an incident's execution log chunk by chunk from
+ * /api/incidents/{uuid}/log?offset= so the incident itself can be fetched
+ * without it. While follow is set, only the new tail is polled for.
+ * Changing reloadKey (e.g. the incident status) reloads the log from the
+ * start, since a final status update may store a rewritten log; so does a
+ * log that shrank below the loaded offset.
+ */
+export function useIncidentLog(uuid: string, follow: boolean, reloadKey?: string): UseIncidentLogReturn {
+  const [log, setLog] = useState('');
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState('');
+  const loadedUUIDRef = useRef<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    let timer: number | undefined;
+    let offset = 0;
+    if (loadedUUIDRef.current !== uuid) {
+      loadedUUIDRef.current = uuid;
+      setLog('');
+      setLoading(true);
+    }
+
+    const load = async () => {
+      try {
+        for (;;) {
+          const chunk = await incidentsApi.getLogChunk(uuid, offset, LOG_CHUNK_SIZE);
+          if (cancelled) return;
+          if (chunk.size < chunk.offset) {
+            offset = 0;
+            continue;
+          }
+          // The chunk at offset 0 replaces whatever was shown before.
+          setLog(prev => (chunk.offset === 0 ? chunk.content : prev + chunk.content));
+          if (chunk.next_offset === chunk.offset || chunk.next_offset >= chunk.size) {
+            offset = chunk.next_offset;
+            break;
+          }
+          offset = chunk.next_offset;
+        }
+        setError('');
+      } catch (err) {
+        if (cancelled) return;
+        setError(err instanceof Error ? err.message : 'Failed to load log');
+      }
+      setLoading(false);
+      if (follow && !cancelled) {
+        timer = window.setTimeout(load, LOG_POLL_INTERVAL_MS);
+      }
+    };
+    load();
+
+    return () => {
+      cancelled = true;
+      if (timer) clearTimeout(timer);
+    };
+  }, [uuid, follow, reloadKey]);
+
+  return { log, loading, error };
+}
@@ -7,6 +7,7 @@ export function useSSHKeyManagement(setError: (error: string) => void) {
   const [showAddKey, setShowAddKey] = useState(false);
   const [newKeyName, setNewKeyName] = useState('');
   const [newKeyValue, setNewKeyValue] = useState('');
+  const [newKeyPassphrase, setNewKeyPassphrase] = useState('');
   const [newKeyIsDefault, setNewKeyIsDefault] = useState(false);
   const [sshKeysLoading, setSshKeysLoading] = useState(false);
 
@@ -38,17 +39,19 @@ export function useSSHKeyManagement(setError: (error: string) => void) {
       await sshKeysApi.create(toolId, {
         name: newKeyName,
         private_key: newKeyValue,
+        passphrase: newKeyPassphrase || undefined,
         is_default: newKeyIsDefault || sshKeys.length === 0,
       });
       setShowAddKey(false);
       setNewKeyName('');
       setNewKeyValue('');
+      setNewKeyPassphrase('');
       setNewKeyIsDefault(false);
       await loadSSHKeys(toolId);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to add SSH key');
     }
-  }, [newKeyName, newKeyValue, newKeyIsDefault, sshKeys.length, loadSSHKeys, setError]);
+  }, [newKeyName, newKeyValue, newKeyPassphrase, newKeyIsDefault, sshKeys.length, loadSSHKeys, setError]);
 
   const handleDeleteSSHKey = useCallback(async (toolId: number, keyId: string) => {
     if (!confirm('Are you sure you want to delete this SSH key?')) return;
@@ -78,6 +81,7 @@ export function useSSHKeyManagement(setError: (error: string) => void) {
     setShowAddKey(false);
     setNewKeyName('');
     setNewKeyValue('');
+    setNewKeyPassphrase('');
     setNewKeyIsDefault(false);
   }, []);
 
@@ -89,6 +93,8 @@ export function useSSHKeyManagement(setError: (error: string) => void) {
     setNewKeyName,
     newKeyValue,
     setNewKeyValue,
+    newKeyPassphrase,
+    setNewKeyPassphrase,
     newKeyIsDefault,
     setNewKeyIsDefault,
     sshKeysLoading,
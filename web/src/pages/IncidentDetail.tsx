@@ -1,6 +1,6 @@
 import { useEffect, useState, useRef } from 'react';
 import { useParams, Link } from 'react-router-dom';
-import { ArrowLeft, Activity, Clock, CheckCircle, AlertCircle, Timer, Zap, XCircle, GitMerge } from 'lucide-react';
+import { ArrowLeft, Activity, Clock, CheckCircle, AlertCircle, Timer, Zap, Wrench, DollarSign, Gauge, XCircle, GitMerge, Ban, RotateCcw, Archive } from 'lucide-react';
 import LoadingSpinner from '../components/LoadingSpinner';
 import ErrorMessage from '../components/ErrorMessage';
 import IncidentDetailView from '../components/IncidentDetailView';
@@ -20,6 +20,10 @@ const getStatusConfig = (status: string) => {
       return { class: 'badge-purple', icon: CheckCircle, label: 'Diagnosed' };
     case 'failed':
       return { class: 'badge-error', icon: AlertCircle, label: 'Failed' };
+    case 'budget_exceeded':
+      return { class: 'badge-warning', icon: AlertCircle, label: 'Over budget' };
+    case 'cancelled':
+      return { class: 'badge-default', icon: Ban, label: 'Cancelled' };
     case 'closed':
       return { class: 'badge-default', icon: XCircle, label: 'Closed' };
     case 'merged':
@@ -47,6 +51,11 @@ const formatTokens = (tokens: number): string => {
   return tokens.toLocaleString();
 };
 
+const formatCost = (usd: number): string => {
+  if (usd > 0 && usd < 0.005) return '<$0.01';
+  return `$${usd.toFixed(2)}`;
+};
+
 export default function IncidentDetail() {
   const { uuid } = useParams<{ uuid: string }>();
   const [incident, setIncident] = useState<Incident | null>(null);
@@ -56,6 +65,8 @@ export default function IncidentDetail() {
   const refreshIntervalRef = useRef<number | null>(null);
   const [closing, setClosing] = useState(false);
   const [closeError, setCloseError] = useState('');
+  const [cancelling, setCancelling] = useState(false);
+  const [retrying, setRetrying] = useState(false);
   const [confirmClose, setConfirmClose] = useState<{ firingAlertCount: number; inProgress: boolean } | null>(null);
 
   useEffect(() => {
@@ -63,7 +74,7 @@ export default function IncidentDetail() {
     const load = async () => {
       try {
         setLoading(true);
-        const data = await incidentsApi.get(uuid);
+        const data = await incidentsApi.get(uuid, false);
         setIncident(data);
         setError('');
       } catch (err) {
@@ -81,7 +92,7 @@ export default function IncidentDetail() {
 
     refreshIntervalRef.current = window.setInterval(async () => {
       try {
-        const updated = await incidentsApi.get(uuid);
+        const updated = await incidentsApi.get(uuid, false);
         setIncident(updated);
       } catch (err) {
         console.error('Failed to refresh incident:', err);
@@ -99,7 +110,7 @@ export default function IncidentDetail() {
   const refreshIncident = async () => {
     if (!uuid) return;
     try {
-      setIncident(await incidentsApi.get(uuid));
+      setIncident(await incidentsApi.get(uuid, false));
     } catch (err) {
       console.error('Failed to refresh incident:', err);
     }
@@ -126,6 +137,34 @@ export default function IncidentDetail() {
     }
   };
 
+  const handleCancelClick = async () => {
+    if (!uuid) return;
+    setCloseError('');
+    setCancelling(true);
+    try {
+      await incidentsApi.cancel(uuid);
+      await refreshIncident();
+    } catch (err) {
+      setCloseError(err instanceof Error ? err.message : 'Failed to cancel investigation');
+    } finally {
+      setCancelling(false);
+    }
+  };
+
+  const handleRetryClick = async () => {
+    if (!uuid) return;
+    setCloseError('');
+    setRetrying(true);
+    try {
+      await incidentsApi.retry(uuid);
+      await refreshIncident();
+    } catch (err) {
+      setCloseError(err instanceof Error ? err.message : 'Failed to retry investigation');
+    } finally {
+      setRetrying(false);
+    }
+  };
+
   if (loading) {
     return (
       <div className="flex items-center justify-center min-h-[400px]">
@@ -176,10 +215,33 @@ export default function IncidentDetail() {
               </div>
             </div>
             <div className="flex items-center gap-3">
-              <span className={`badge ${statusConfig.class} inline-flex items-center gap-1`}>
+              <span
+                className={`badge ${statusConfig.class} inline-flex items-center gap-1`}
+                style={incident.status_color ? { backgroundColor: incident.status_color, color: '#fff' } : undefined}
+              >
                 <StatusIcon className="w-3 h-3" />
-                {statusConfig.label}
+                {incident.status_label || statusConfig.label}
               </span>
+              {(incident.status === 'pending' || incident.status === 'running') && (
+                <button
+                  onClick={handleCancelClick}
+                  disabled={cancelling}
+                  className="inline-flex items-center gap-1 px-3 py-1.5 rounded-lg text-xs font-medium text-gray-600 dark:text-gray-300 border border-gray-300 dark:border-gray-600 hover:bg-gray-100 dark:hover:bg-gray-700 disabled:opacity-50 transition-colors"
+                >
+                  <Ban className="w-3.5 h-3.5" />
+                  {cancelling ? 'Cancelling…' : 'Cancel Investigation'}
+                </button>
+              )}
+              {(incident.status === 'failed' || incident.status === 'cancelled') && (
+                <button
+                  onClick={handleRetryClick}
+                  disabled={retrying}
+                  className="inline-flex items-center gap-1 px-3 py-1.5 rounded-lg text-xs font-medium text-gray-600 dark:text-gray-300 border border-gray-300 dark:border-gray-600 hover:bg-gray-100 dark:hover:bg-gray-700 disabled:opacity-50 transition-colors"
+                >
+                  <RotateCcw className="w-3.5 h-3.5" />
+                  {retrying ? 'Retrying…' : 'Retry Investigation'}
+                </button>
+              )}
               {incident.status !== 'closed' && (
                 <button
                   onClick={handleCloseClick}
@@ -190,6 +252,13 @@ export default function IncidentDetail() {
                   {closing ? 'Closing…' : 'Close Incident'}
                 </button>
               )}
+              <a
+                href={incidentsApi.getExportUrl(incident.uuid)}
+                className="inline-flex items-center gap-1 px-3 py-1.5 rounded-lg text-xs font-medium text-gray-600 dark:text-gray-300 border border-gray-300 dark:border-gray-600 hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
+              >
+                <Archive className="w-3.5 h-3.5" />
+                Export
+              </a>
             </div>
           </div>
 
@@ -225,8 +294,33 @@ export default function IncidentDetail() {
                     {formatTokens(incident.tokens_used)} tokens
                   </span>
                 )}
+                {(incident.tool_calls ?? 0) > 0 && (
+                  <span className="flex items-center gap-1.5">
+                    <Wrench className="w-4 h-4" />
+                    {incident.tool_calls} tool calls
+                  </span>
+                )}
+                {incident.estimated_cost_usd != null && (
+                  <span className="flex items-center gap-1.5" title="Estimated from the model price table">
+                    <DollarSign className="w-4 h-4" />
+                    {formatCost(incident.estimated_cost_usd)}
+                  </span>
+                )}
               </>
             )}
+            {incident.confidence != null && (
+              <span
+                className={`flex items-center gap-1.5 ${incident.confidence_action === 'review_requested' ? 'text-amber-600 dark:text-amber-400' : ''}`}
+                title={[
+                  incident.confidence_reason,
+                  incident.confidence_action === 'review_requested' ? 'Review requested' : '',
+                  incident.confidence_action === 'auto_closed' ? 'Auto-closed' : '',
+                ].filter(Boolean).join(' — ') || 'Confidence reported by the agent'}
+              >
+                <Gauge className="w-4 h-4" />
+                {Math.round(incident.confidence * 100)}% confidence
+              </span>
+            )}
           </div>
         </div>
 
@@ -1,5 +1,5 @@
 import { useEffect, useState, useRef, useCallback } from 'react';
-import { RefreshCw, X, Plus, MessageSquare, Activity, Clock, CheckCircle, AlertCircle, XCircle, Terminal, Zap, Timer, Bell, GitMerge } from 'lucide-react';
+import { RefreshCw, X, Plus, MessageSquare, Activity, Clock, CheckCircle, AlertCircle, XCircle, Terminal, Zap, Wrench, DollarSign, Timer, Bell, GitMerge, Ban } from 'lucide-react';
 import PageHeader from '../components/PageHeader';
 import LoadingSpinner from '../components/LoadingSpinner';
 import ErrorMessage from '../components/ErrorMessage';
@@ -191,7 +191,7 @@ export default function Incidents() {
     if (showModal && selectedIncident && selectedIncident.status === 'running' && autoRefresh) {
       refreshIntervalRef.current = window.setInterval(async () => {
         try {
-          const updated = await incidentsApi.get(selectedIncident.uuid);
+          const updated = await incidentsApi.get(selectedIncident.uuid, false);
           setSelectedIncident(updated);
           setIncidents(prev => prev.map(i => {
             if (i.uuid !== updated.uuid) return i;
@@ -246,6 +246,10 @@ export default function Incidents() {
         return { class: 'badge-purple', icon: Activity, label: 'Ongoing', subLabel: undefined };
       case 'failed':
         return { class: 'badge-error', icon: AlertCircle, label: 'Failed', subLabel: undefined };
+      case 'budget_exceeded':
+        return { class: 'badge-warning', icon: AlertCircle, label: 'Over budget', subLabel: undefined };
+      case 'cancelled':
+        return { class: 'badge-default', icon: Ban, label: 'Cancelled', subLabel: undefined };
       default:
         return { class: 'badge-default', icon: Clock, label: 'Pending', subLabel: undefined };
     }
@@ -300,11 +304,17 @@ export default function Incidents() {
     return tokens.toLocaleString();
   };
 
+  // Format an estimated cost in dollars, matching the Slack footer
+  const formatCost = (usd: number): string => {
+    if (usd > 0 && usd < 0.005) return '<$0.01';
+    return `$${usd.toFixed(2)}`;
+  };
+
   const openModal = useCallback(async (incident: Incident) => {
     setCloseIncidentError('');
     setConfirmCloseIncident(null);
     try {
-      const latest = await incidentsApi.get(incident.uuid);
+      const latest = await incidentsApi.get(incident.uuid, false);
       setSelectedIncident(latest);
     } catch {
       setSelectedIncident(incident);
@@ -326,7 +336,7 @@ export default function Incidents() {
   const refreshSelectedIncident = async () => {
     if (!selectedIncident) return;
     try {
-      setSelectedIncident(await incidentsApi.get(selectedIncident.uuid));
+      setSelectedIncident(await incidentsApi.get(selectedIncident.uuid, false));
     } catch (err) {
       console.error('Failed to refresh incident:', err);
     }
@@ -364,7 +374,7 @@ export default function Incidents() {
       // Fetch the full incident and add to list immediately, but only when in
       // open view — a new incident starts as pending and would be out of place
       // in the history view which filters for completed/failed.
-      const newIncident = await incidentsApi.get(response.uuid);
+      const newIncident = await incidentsApi.get(response.uuid, false);
       if (view === 'open') {
         setIncidents(prev => [newIncident, ...prev]);
       }
@@ -532,9 +542,12 @@ export default function Incidents() {
                         </td>
                         <td>
                           <div className="flex flex-col gap-0.5">
-                            <span className={`badge ${statusConfig.class} inline-flex items-center gap-1`}>
+                            <span
+                              className={`badge ${statusConfig.class} inline-flex items-center gap-1`}
+                              style={incident.status_color ? { backgroundColor: incident.status_color, color: '#fff' } : undefined}
+                            >
                               <StatusIcon className="w-3 h-3" />
-                              {statusConfig.label}
+                              {incident.status_label || statusConfig.label}
                             </span>
                             {statusConfig.subLabel && (
                               <span className="text-xs text-gray-400 dark:text-gray-500">
@@ -638,7 +651,14 @@ export default function Incidents() {
                   <h2 className="text-xl font-semibold text-gray-900 dark:text-white">
                     {selectedIncident.title || 'Incident Details'}
                   </h2>
-                  {selectedStatusConfig && <span className={`badge ${selectedStatusConfig.class}`}>{selectedStatusConfig.label}</span>}
+                  {selectedStatusConfig && (
+                    <span
+                      className={`badge ${selectedStatusConfig.class}`}
+                      style={selectedIncident.status_color ? { backgroundColor: selectedIncident.status_color, color: '#fff' } : undefined}
+                    >
+                      {selectedIncident.status_label || selectedStatusConfig.label}
+                    </span>
+                  )}
                   {selectedIncident.status !== 'closed' && (
                     <button
                       onClick={handleCloseIncidentClick}
@@ -698,6 +718,18 @@ export default function Incidents() {
                         {formatTokens(selectedIncident.tokens_used)} tokens
                       </span>
                     )}
+                    {(selectedIncident.tool_calls ?? 0) > 0 && (
+                      <span className="flex items-center gap-1.5">
+                        <Wrench className="w-4 h-4" />
+                        {selectedIncident.tool_calls} tool calls
+                      </span>
+                    )}
+                    {selectedIncident.estimated_cost_usd != null && (
+                      <span className="flex items-center gap-1.5" title="Estimated from the model price table">
+                        <DollarSign className="w-4 h-4" />
+                        {formatCost(selectedIncident.estimated_cost_usd)}
+                      </span>
+                    )}
                   </div>
                 )}
               </div>
@@ -13,6 +13,9 @@ import {
   Trash2,
   Sparkles,
   Hash,
+  DollarSign,
+  Tags,
+  ShieldCheck,
 } from 'lucide-react';
 import AlertSourcesManager from '../components/AlertSourcesManager';
 import ProxySettings from '../components/ProxySettings';
@@ -20,6 +23,9 @@ import LLMSettingsSection from '../components/settings/LLMSettingsSection';
 import GeneralSettingsSection from '../components/settings/GeneralSettingsSection';
 import RetentionSettingsSection from '../components/settings/RetentionSettingsSection';
 import FormattingRulesSection from '../components/settings/FormattingRulesSection';
+import ModelPricesSection from '../components/settings/ModelPricesSection';
+import VocabularySettingsSection from '../components/settings/VocabularySettingsSection';
+import CORSSettingsSection from '../components/settings/CORSSettingsSection';
 
 function SettingsSection({
   title,
@@ -120,6 +126,9 @@ export default function Settings() {
   const [generalStatus, setGeneralStatus] = useState<'configured' | undefined>();
   const [retentionStatus, setRetentionStatus] = useState<'configured' | 'disabled' | undefined>();
   const [formattingStatus, setFormattingStatus] = useState<'configured' | 'disabled' | undefined>();
+  const [pricingStatus, setPricingStatus] = useState<'configured' | 'disabled' | undefined>();
+  const [vocabularyStatus, setVocabularyStatus] = useState<'configured' | 'disabled' | undefined>();
+  const [corsStatus, setCorsStatus] = useState<'configured' | 'disabled' | undefined>();
 
   return (
     <div className="animate-fade-in max-w-3xl mx-auto">
@@ -150,6 +159,16 @@ export default function Settings() {
           <LLMSettingsSection onStatusChange={setLlmStatus} />
         </SettingsSection>
 
+        <SettingsSection
+          title="Model Pricing"
+          description="Price table for investigation cost estimates"
+          icon={DollarSign}
+          status={pricingStatus}
+          defaultExpanded={false}
+        >
+          <ModelPricesSection onStatusChange={setPricingStatus} />
+        </SettingsSection>
+
         <NavigationCard
           title="Integrations"
           description="Slack and other messaging providers (Telegram coming soon)"
@@ -191,6 +210,26 @@ export default function Settings() {
           <FormattingRulesSection onStatusChange={setFormattingStatus} />
         </SettingsSection>
 
+        <SettingsSection
+          title="Vocabulary"
+          description="Severity emoji and status names to match your incident process"
+          icon={Tags}
+          status={vocabularyStatus}
+          defaultExpanded={false}
+        >
+          <VocabularySettingsSection onStatusChange={setVocabularyStatus} />
+        </SettingsSection>
+
+        <SettingsSection
+          title="CORS"
+          description="Browser origins allowed to call the API from another domain"
+          icon={ShieldCheck}
+          status={corsStatus}
+          defaultExpanded={false}
+        >
+          <CORSSettingsSection onStatusChange={setCorsStatus} />
+        </SettingsSection>
+
         <SettingsSection
           title="Alert Sources"
           description="Webhook integrations for monitoring systems"
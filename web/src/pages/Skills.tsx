@@ -1,12 +1,12 @@
-import { useEffect, useState } from 'react';
-import { Plus, Edit2, Trash2, Save, X, Bot, Wrench, Power, PowerOff, Shield, RefreshCw, Eye } from 'lucide-react';
+import { useEffect, useRef, useState } from 'react';
+import { Plus, Edit2, Trash2, Save, X, Bot, Wrench, Power, PowerOff, Shield, RefreshCw, Eye, Upload, Download } from 'lucide-react';
 import PageHeader from '../components/PageHeader';
 import LoadingSpinner from '../components/LoadingSpinner';
 import ErrorMessage from '../components/ErrorMessage';
 import { SuccessMessage } from '../components/ErrorMessage';
 import PromptEditor from '../components/PromptEditor';
-import { skillsApi, toolsApi } from '../api/client';
-import type { Skill, ToolInstance } from '../types';
+import { llmSettingsApi, skillsApi, toolsApi } from '../api/client';
+import type { LLMConfig, Skill, ToolInstance } from '../types';
 
 // Modal component for creating/editing skills
 interface SkillModalProps {
@@ -15,6 +15,7 @@ interface SkillModalProps {
   isCreating: boolean;
   isViewOnly: boolean;
   toolInstances: ToolInstance[];
+  llmConfigs: LLMConfig[];
   onClose: () => void;
   onSave: (data: SkillFormData) => Promise<void>;
 }
@@ -24,15 +25,17 @@ interface SkillFormData {
   description: string;
   prompt: string;
   enabled: boolean;
+  llmSettingsId: number | null;
   toolIds: number[];
 }
 
-function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, onClose, onSave }: SkillModalProps) {
+function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, llmConfigs, onClose, onSave }: SkillModalProps) {
   const [formData, setFormData] = useState<SkillFormData>({
     name: '',
     description: '',
     prompt: '',
     enabled: true,
+    llmSettingsId: null,
     toolIds: [],
   });
   const [saving, setSaving] = useState(false);
@@ -45,6 +48,7 @@ function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, onCl
         description: skill.description || '',
         prompt: skill.prompt || '',
         enabled: skill.enabled,
+        llmSettingsId: skill.llm_settings_id ?? null,
         toolIds: skill.tools?.map(t => t.id) || [],
       });
     } else {
@@ -53,6 +57,7 @@ function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, onCl
         description: '',
         prompt: '',
         enabled: true,
+        llmSettingsId: null,
         toolIds: [],
       });
     }
@@ -205,6 +210,30 @@ function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, onCl
               )}
             </div>
 
+            {/* LLM configuration - only for existing, editable skills */}
+            {!isCreating && !isViewOnly && (
+              <div>
+                <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                  LLM Configuration
+                </label>
+                <select
+                  className="input-field"
+                  value={formData.llmSettingsId ?? ''}
+                  onChange={(e) => setFormData({ ...formData, llmSettingsId: e.target.value ? Number(e.target.value) : null })}
+                >
+                  <option value="">Active configuration</option>
+                  {llmConfigs.map(cfg => (
+                    <option key={cfg.id} value={cfg.id} disabled={!cfg.is_configured}>
+                      {cfg.name} ({cfg.model || cfg.provider}){cfg.is_configured ? '' : ' - no API key'}
+                    </option>
+                  ))}
+                </select>
+                <p className="mt-1 text-xs text-gray-500 dark:text-gray-400">
+                  Used by investigations scoped to this skill: alert sources and cron jobs pinned to it, and dry runs.
+                </p>
+              </div>
+            )}
+
             {/* Enabled Toggle - not for view only */}
             {!isViewOnly && (
               <div className="flex items-center gap-3 p-3 rounded-lg bg-gray-50 dark:bg-gray-900/50">
@@ -297,10 +326,13 @@ function SkillModal({ isOpen, skill, isCreating, isViewOnly, toolInstances, onCl
 export default function Skills() {
   const [skills, setSkills] = useState<Skill[]>([]);
   const [toolInstances, setToolInstances] = useState<ToolInstance[]>([]);
+  const [llmConfigs, setLLMConfigs] = useState<LLMConfig[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
   const [syncing, setSyncing] = useState(false);
+  const [importing, setImporting] = useState(false);
+  const importInput = useRef<HTMLInputElement>(null);
 
   // Modal state
   const [modalOpen, setModalOpen] = useState(false);
@@ -316,12 +348,14 @@ export default function Skills() {
     try {
       setLoading(true);
       setError('');
-      const [skillsData, toolsData] = await Promise.all([
+      const [skillsData, toolsData, llmData] = await Promise.all([
         skillsApi.list(),
         toolsApi.list(),
+        llmSettingsApi.list(),
       ]);
       setSkills(skillsData || []);
       setToolInstances(toolsData);
+      setLLMConfigs(llmData.configs || []);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load data');
     } finally {
@@ -344,6 +378,31 @@ export default function Skills() {
     }
   };
 
+  const handleImport = async (file: File) => {
+    try {
+      setImporting(true);
+      setError('');
+      const result = await skillsApi.importBundle(file);
+      const notes: string[] = [];
+      if (result.missing_tool_types?.length) {
+        notes.push(`needs tool types: ${result.missing_tool_types.join(', ')}`);
+      }
+      if (result.conflicting_references?.length) {
+        notes.push(`kept existing context files: ${result.conflicting_references.join(', ')}`);
+      }
+      setSuccess(`Imported ${result.skill.name} ${result.version}${notes.length ? ` (${notes.join('; ')})` : ''}`);
+      setTimeout(() => setSuccess(''), 5000);
+      await loadData();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to import skill');
+    } finally {
+      setImporting(false);
+      if (importInput.current) {
+        importInput.current.value = '';
+      }
+    }
+  };
+
   const handleCreate = () => {
     setEditingSkill(null);
     setIsCreating(true);
@@ -379,6 +438,7 @@ export default function Skills() {
       await skillsApi.update(editingSkill.name, {
         description: data.description,
         enabled: data.enabled,
+        llm_settings_id: data.llmSettingsId,
         prompt: data.prompt,
       });
       skillName = editingSkill.name;
@@ -436,6 +496,17 @@ export default function Skills() {
               <RefreshCw className={`w-4 h-4 ${syncing ? 'animate-spin' : ''}`} />
               {syncing ? 'Syncing...' : 'Sync'}
             </button>
+            <input
+              ref={importInput}
+              type="file"
+              accept=".tar.gz,.tgz,.zip"
+              className="hidden"
+              onChange={(e) => e.target.files?.[0] && handleImport(e.target.files[0])}
+            />
+            <button onClick={() => importInput.current?.click()} disabled={importing} className="btn btn-secondary">
+              <Upload className="w-4 h-4" />
+              {importing ? 'Importing...' : 'Import'}
+            </button>
             <button onClick={handleCreate} className="btn btn-primary">
               <Plus className="w-4 h-4" />
               New Skill
@@ -520,6 +591,13 @@ export default function Skills() {
                       </button>
                     ) : (
                       <>
+                        <a
+                          href={skillsApi.getExportUrl(skill.name)}
+                          className="p-2 text-gray-400 hover:text-primary-600 dark:hover:text-primary-400 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
+                          title="Export"
+                        >
+                          <Download className="w-4 h-4" />
+                        </a>
                         <button
                           onClick={() => handleEdit(skill)}
                           className="p-2 text-gray-400 hover:text-primary-600 dark:hover:text-primary-400 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
@@ -551,6 +629,7 @@ export default function Skills() {
         isCreating={isCreating}
         isViewOnly={isViewOnly}
         toolInstances={toolInstances}
+        llmConfigs={llmConfigs}
         onClose={closeModal}
         onSave={handleSave}
       />
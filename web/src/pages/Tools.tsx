@@ -82,6 +82,8 @@ export default function Tools() {
               setNewKeyName={ssh.setNewKeyName}
               newKeyValue={ssh.newKeyValue}
               setNewKeyValue={ssh.setNewKeyValue}
+              newKeyPassphrase={ssh.newKeyPassphrase}
+              setNewKeyPassphrase={ssh.setNewKeyPassphrase}
               newKeyIsDefault={ssh.newKeyIsDefault}
               setNewKeyIsDefault={ssh.setNewKeyIsDefault}
               onAddSSHKey={() => editingTool && ssh.handleAddSSHKey(editingTool.id)}
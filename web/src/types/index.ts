@@ -6,6 +6,8 @@ export interface Skill {
   prompt: string;
   is_system: boolean;
   enabled: boolean;
+  // LLM config for investigations scoped to this skill; null uses the active one.
+  llm_settings_id: number | null;
   created_at: string;
   updated_at: string;
   tools?: ToolInstance[];
@@ -32,7 +34,7 @@ export interface ToolInstance {
   tool_type?: ToolType;
 }
 
-export type IncidentStatus = 'pending' | 'running' | 'diagnosed' | 'completed' | 'failed' | 'monitor' | 'closed' | 'merged';
+export type IncidentStatus = 'pending' | 'running' | 'diagnosed' | 'completed' | 'failed' | 'monitor' | 'closed' | 'merged' | 'budget_exceeded' | 'cancelled';
 
 export interface Incident {
   id: number;
@@ -41,13 +43,21 @@ export interface Incident {
   source_id: string;
   title: string;  // LLM-generated title summarizing the incident
   status: IncidentStatus;
+  status_label?: string;  // Deployment's name for the status, when overridden in Settings
+  status_color?: string;  // #rrggbb badge color, when overridden in Settings
   context: Record<string, any>;
   session_id: string;
   working_dir: string;
-  full_log: string;
+  full_log: string;  // Empty in lists and when fetched without the log
   response: string;  // Final response/output to user
   tokens_used: number;  // Total tokens used (input + output)
   execution_time_ms: number;  // Execution time in milliseconds
+  tool_calls?: number;  // Tool executions the agent finished
+  attempts?: number;  // Investigation runs, 1 plus the number of retries
+  confidence?: number;  // 0-1, reported by the agent in alert investigation results
+  confidence_reason?: string;
+  confidence_action?: 'review_requested' | 'auto_closed';
+  estimated_cost_usd?: number;  // Priced from the model price table; absent when unpriced
   started_at: string;
   completed_at?: string;
   monitor_until?: string;
@@ -56,10 +66,390 @@ export interface Incident {
   first_seen?: string;
   last_seen?: string;
   trend?: number[];
+  relations?: IncidentRelations;
+  changes?: IncidentChange[];  // Change manifest; detail endpoint only
   created_at: string;
   updated_at: string;
 }
 
+// A window of an incident's execution log. Offsets and size count
+// characters; next_offset equals size once the whole log has been read.
+export interface IncidentLogChunk {
+  content: string;
+  offset: number;
+  next_offset: number;
+  size: number;
+}
+
+// IncidentLinkRef is one end of a parent/child or related incident link.
+export interface IncidentLinkRef {
+  uuid: string;
+  title: string;
+  status: IncidentStatus;
+  origin: 'manual' | 'correlator';
+  reason?: string;
+  created_at: string;
+}
+
+export interface IncidentRelations {
+  parent?: IncidentLinkRef;
+  children: IncidentLinkRef[];
+  related: IncidentLinkRef[];
+}
+
+// IncidentChange is one entry of an incident's change manifest: a file the
+// agent changed in its workspace, or a write on a remote host.
+export interface IncidentChange {
+  id: number;
+  incident_uuid: string;
+  location: string; // 'workspace' or a host name
+  path?: string;
+  action: 'created' | 'modified' | 'deleted' | 'command';
+  tool?: string;
+  detail?: string;
+  size_bytes: number;
+  sha256?: string;
+  created_at: string;
+}
+
+export type IncidentEventType = 'status_change' | 'alert_attached' | 'command' | 'slack_post' | 'note' | 'approval';
+
+// IncidentEvent is one entry on an incident's timeline. details carries the
+// type-specific fields (status, alert_name, command/failed, text, ...).
+export interface IncidentEvent {
+  id: number;
+  incident_uuid: string;
+  type: IncidentEventType;
+  summary: string;
+  details?: Record<string, unknown>;
+  actor: string;
+  occurred_at: string;
+  created_at: string;
+}
+
+// ToolApproval is a destructive tool call held by the gateway until a human
+// approves or rejects it. Rejecting cancels the investigation.
+export type ToolApprovalStatus = 'pending' | 'approved' | 'rejected' | 'expired';
+
+export interface ToolApproval {
+  uuid: string;
+  incident_uuid: string;
+  tool_name: string;
+  target: string;
+  detail: string;
+  status: ToolApprovalStatus;
+  decided_by?: string;
+  reason?: string;
+  decided_at?: string;
+  expires_at: string;
+  created_at: string;
+}
+
+// RemediationPlan is the fix a two-phase investigation proposed. The
+// incident stays diagnosed until the plan is approved or rejected.
+export type RemediationPlanStatus = 'pending' | 'approved' | 'rejected';
+
+export interface RemediationPlan {
+  uuid: string;
+  incident_uuid: string;
+  plan: string;
+  status: RemediationPlanStatus;
+  decided_by?: string;
+  reason?: string;
+  decided_at?: string;
+  created_at: string;
+}
+
+// Silence is a maintenance window: matching firing alerts are recorded as
+// suppressed instead of spawning an investigation.
+export interface Silence {
+  uuid: string;
+  comment: string;
+  created_by: string;
+  source_uuid?: string;
+  alert_name?: string;
+  target_host?: string;
+  labels?: Record<string, string>;
+  starts_at: string;
+  ends_at: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export type SilenceState = 'active' | 'pending' | 'expired';
+
+export interface SilenceRequest {
+  comment?: string;
+  source_uuid?: string;
+  alert_name?: string;
+  target_host?: string;
+  labels?: Record<string, string>;
+  starts_at?: string;
+  ends_at?: string;
+  duration_minutes?: number;
+}
+
+export interface SuppressedAlert {
+  id: number;
+  silence_uuid: string;
+  source_uuid: string;
+  alert_name: string;
+  target_host: string;
+  severity: string;
+  summary: string;
+  fingerprint: string;
+  target_labels?: Record<string, string>;
+  created_at: string;
+}
+
+export type RoutingSeverity = 'critical' | 'high' | 'warning' | 'info';
+export type RoutingAction = 'investigate' | 'record';
+
+// RoutingRule overrides how matching alerts from alert sources are handled.
+// Enabled rules are evaluated by position; the first match wins.
+export interface RoutingRule {
+  uuid: string;
+  name: string;
+  description: string;
+  enabled: boolean;
+  position: number;
+  source_uuid?: string;
+  severities?: RoutingSeverity[];
+  alert_name?: string;
+  target_host?: string;
+  labels?: Record<string, string>;
+  action: RoutingAction;
+  priority?: RoutingSeverity;
+  notification_channel_id?: number;
+  notification_channel?: Channel;
+  skills?: Skill[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface RoutingRuleRequest {
+  name: string;
+  description?: string;
+  enabled?: boolean;
+  position?: number;
+  source_uuid?: string;
+  severities?: RoutingSeverity[];
+  alert_name?: string;
+  target_host?: string;
+  labels?: Record<string, string>;
+  action?: RoutingAction;
+  priority?: RoutingSeverity | '';
+  notification_channel_uuid?: string;
+  skill_names?: string[];
+}
+
+// AuditLog is a login event or a configuration change. For changes,
+// details holds before/after snapshots and a field-level diff.
+export interface AuditLogChange {
+  field: string;
+  before: unknown;
+  after: unknown;
+}
+
+export interface AuditLog {
+  id: number;
+  action: string;
+  actor: string;
+  remote_ip: string;
+  target: string;
+  entity_type?: string;
+  outcome: 'success' | 'failure' | 'denied';
+  details?: {
+    method?: string;
+    path?: string;
+    status?: number;
+    request_id?: string;
+    before?: unknown;
+    after?: unknown;
+    changes?: AuditLogChange[];
+    [key: string]: unknown;
+  };
+  created_at: string;
+}
+
+export interface AuditLogFilter {
+  user?: string;
+  entity_type?: string;
+  entity_id?: string;
+  action?: string;
+  since?: string;
+  until?: string;
+  limit?: number;
+}
+
+// UsageTotals sums completed agent runs. estimated_cost_usd only covers
+// runs whose model had a price; the rest are counted in unpriced_tokens.
+export interface UsageTotals {
+  runs: number;
+  incidents: number;
+  tokens_used: number;
+  unpriced_tokens: number;
+  estimated_cost_usd: number;
+}
+
+export type UsagePeriod = 'day' | 'week';
+
+export interface UsageSummary {
+  period: UsagePeriod;
+  since: string;
+  until: string;
+  totals: UsageTotals;
+  buckets: (UsageTotals & { start: string })[];
+  by_skill: (UsageTotals & { skill: string })[];
+  by_model: (UsageTotals & { model: string })[];
+}
+
+export interface UsageFilter {
+  period?: UsagePeriod;
+  since?: string;
+  until?: string;
+}
+
+// AlertBaseline compares an alert name's last seven days with its weekly
+// average over the weeks before. baseline_weeks is 0 for a name first seen
+// this week.
+export interface AlertBaseline {
+  alert_name: string;
+  weekly_average: number;
+  baseline_weeks: number;
+  current_week: number;
+  ratio: number;
+  deviating: boolean;
+  computed_at: string;
+}
+
+// A write an agent executed on a remote host, with its approver when the
+// host required approval.
+export interface ComplianceAction {
+  executed_at: string;
+  incident_uuid: string;
+  incident_title: string;
+  tool: string;
+  target: string;
+  action: 'created' | 'modified' | 'deleted' | 'command';
+  path?: string;
+  detail?: string;
+  approval_uuid?: string;
+  approver?: string;
+  approved_at?: string;
+}
+
+export interface ComplianceReport {
+  since: string;
+  until: string;
+  total: number;
+  approved: number;
+  unapproved: number;
+  actions: ComplianceAction[];
+}
+
+export interface ComplianceFilter {
+  since?: string;
+  until?: string;
+}
+
+// MarketplaceSkill is a community skill bundle listed in the marketplace
+// index. Bundles install only when signed by a trusted key.
+export interface MarketplaceSkill {
+  name: string;
+  version: string;
+  description: string;
+  category?: string;
+  author?: string;
+  tool_types?: string[];
+  bundle_url: string;
+  sha256?: string;
+  signature: string;
+  installed: boolean;
+}
+
+export interface MarketplaceToolType {
+  name: string;
+  description: string;
+  homepage?: string;
+  installed: boolean;
+}
+
+export interface MarketplaceIndex {
+  skills: MarketplaceSkill[];
+  tool_types: MarketplaceToolType[];
+}
+
+export interface SkillInstallResult {
+  skill: Skill;
+  version: string;
+  missing_tool_types?: string[];
+  conflicting_references?: string[];
+}
+
+// Synthetic alert for POST /api/skills/:name/test. Only alert_name is
+// required; severity defaults to warning.
+export interface SkillTestAlert {
+  alert_name: string;
+  severity?: string;
+  target_host?: string;
+  target_service?: string;
+  summary?: string;
+  description?: string;
+  metric_name?: string;
+  metric_value?: string;
+  runbook_url?: string;
+}
+
+export interface SkillTestResult {
+  run_id: string;
+  skill: string;
+  status: 'completed' | 'failed' | 'timed_out';
+  output?: string;
+  error?: string;
+  tool_calls: { command: string; failed: boolean }[];
+  log: string;
+  tokens_used: number;
+  execution_time_ms: number;
+}
+
+// IncidentReport is the generated postmortem for an incident. Regenerating
+// replaces it.
+export interface IncidentReport {
+  id: number;
+  incident_uuid: string;
+  title: string;
+  summary: string;
+  impact: string;
+  root_cause: string;
+  timeline: { time: string; event: string }[];
+  action_items: { action: string; owner?: string; priority?: 'high' | 'medium' | 'low' }[];
+  generated_by?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// SkillSnippet records a command or query promoted from an incident into a
+// skill's scripts ('script') or context references ('reference').
+export interface SkillSnippet {
+  id: number;
+  skill_name: string;
+  kind: 'script' | 'reference';
+  filename: string;
+  incident_uuid: string;
+  description?: string;
+  created_at: string;
+}
+
+export interface ExportSnippetRequest {
+  skill_name: string;
+  kind: 'script' | 'reference';
+  filename: string;
+  content: string;
+  description?: string;
+  overwrite?: boolean;
+}
+
 export interface Alert {
   uuid: string;
   incident_uuid: string;
@@ -101,7 +491,7 @@ export interface CreateIncidentResponse {
   message: string;
 }
 
-export type LLMProvider = 'openai' | 'anthropic' | 'google' | 'openrouter' | 'custom' | 'nvidia' | 'minimax' | 'ant-ling';
+export type LLMProvider = 'openai' | 'anthropic' | 'google' | 'openrouter' | 'custom' | 'nvidia' | 'minimax' | 'ant-ling' | 'self-hosted';
 export type ThinkingLevel = 'off' | 'minimal' | 'low' | 'medium' | 'high' | 'xhigh' | 'max';
 
 export interface LLMConfig {
@@ -111,6 +501,7 @@ export interface LLMConfig {
   model: string;
   thinking_level: ThinkingLevel;
   base_url: string;
+  context_window: number;  // 0 uses the provider default
   api_key: string;  // Masked for display
   is_configured: boolean;
   enabled: boolean;
@@ -131,6 +522,7 @@ export interface CreateLLMConfigRequest {
   model?: string;
   thinking_level?: string;
   base_url?: string;
+  context_window?: number;
 }
 
 export interface UpdateLLMConfigRequest {
@@ -139,6 +531,18 @@ export interface UpdateLLMConfigRequest {
   model?: string;
   thinking_level?: string;
   base_url?: string;
+  context_window?: number;
+}
+
+// Result of probing an LLM configuration's endpoint
+export interface LLMHealthResult {
+  healthy: boolean;
+  endpoint: string;
+  latency_ms: number;
+  models: string[] | null;
+  model_available: boolean;
+  context_window: number;
+  error?: string;
 }
 
 // Proxy Settings types
@@ -161,6 +565,9 @@ export interface ProxySettings {
     netbox: ProxyServiceConfig;
     kubernetes: ProxyServiceConfig;
     jira: ProxyServiceConfig;
+    prometheus: ProxyServiceConfig;
+    log_search: ProxyServiceConfig;
+    http_check: ProxyServiceConfig;
     ssh: ProxyServiceConfig;
   };
 }
@@ -179,6 +586,9 @@ export interface ProxySettingsUpdate {
     netbox: { enabled: boolean };
     kubernetes: { enabled: boolean };
     jira: { enabled: boolean };
+    prometheus: { enabled: boolean };
+    log_search: { enabled: boolean };
+    http_check: { enabled: boolean };
   };
 }
 
@@ -362,11 +772,19 @@ export interface Channel {
   process_bot_messages: boolean;
   process_human_messages: boolean;
   enabled: boolean;
+  // Slack reaction overrides: emoji name, 'none' to suppress, '' for default.
+  reaction_alert: string;
+  reaction_working: string;
+  reaction_success: string;
+  reaction_failure: string;
+  progress_verbosity: SlackProgressVerbosity | '';
   created_at: string;
   updated_at: string;
   integration?: Integration;
 }
 
+export type SlackProgressVerbosity = 'off' | 'status' | 'thread';
+
 export interface CreateChannelRequest {
   integration_uuid: string;
   external_id: string;
@@ -378,6 +796,11 @@ export interface CreateChannelRequest {
   process_bot_messages?: boolean;
   process_human_messages?: boolean;
   enabled?: boolean;
+  reaction_alert?: string;
+  reaction_working?: string;
+  reaction_success?: string;
+  reaction_failure?: string;
+  progress_verbosity?: SlackProgressVerbosity | '';
 }
 
 export interface UpdateChannelRequest {
@@ -390,6 +813,11 @@ export interface UpdateChannelRequest {
   process_bot_messages?: boolean;
   process_human_messages?: boolean;
   enabled?: boolean;
+  reaction_alert?: string;
+  reaction_working?: string;
+  reaction_success?: string;
+  reaction_failure?: string;
+  progress_verbosity?: SlackProgressVerbosity | '';
 }
 
 export interface ListChannelsFilter {
@@ -424,8 +852,12 @@ export interface AlertSourceInstance {
   enabled: boolean;
   created_at: string;
   updated_at: string;
+  // When the source last delivered alerts that parsed; absent if never.
+  last_alert_at?: string;
   alert_source_type?: AlertSourceType;
   notification_channel?: Channel | null;
+  // Skills the source's investigations are limited to; empty = all enabled.
+  skills?: Skill[];
 }
 
 export interface CreateAlertSourceRequest {
@@ -436,6 +868,7 @@ export interface CreateAlertSourceRequest {
   field_mappings?: Record<string, string>;
   settings?: Record<string, any>;
   notification_channel_uuid?: string | null;
+  skill_names?: string[];
 }
 
 export interface UpdateAlertSourceRequest {
@@ -446,6 +879,38 @@ export interface UpdateAlertSourceRequest {
   settings?: Record<string, any>;
   enabled?: boolean;
   notification_channel_uuid?: string | null;
+  skill_names?: string[];
+}
+
+export interface AlertSourceConfigSnippet {
+  source_type: string;
+  webhook_url: string;
+  format: 'yaml' | 'json';
+  // Where the snippet goes in the sender's configuration.
+  instructions: string;
+  snippet: string;
+}
+
+export interface ProvisionZabbixRequest {
+  tool_instance_id?: number;
+  akmatori_url?: string;
+  zabbix_user?: string;
+  // Lowest trigger severity forwarded, 0 (Not classified) to 5 (Disaster).
+  min_severity?: number;
+}
+
+export interface ProvisionZabbixResult {
+  zabbix_version: string;
+  webhook_url: string;
+  media_type_id: string;
+  media_type_name: string;
+  media_type_created: boolean;
+  user_id: string;
+  username: string;
+  media_added: boolean;
+  action_id: string;
+  action_name: string;
+  action_created: boolean;
 }
 
 // Cron Jobs
@@ -481,6 +946,7 @@ export interface CronJob {
   updated_at: string;
   channel?: Channel | null;
   tools: CronJobTool[];
+  skill_names: string[];
 }
 
 export interface CreateCronJobRequest {
@@ -491,6 +957,7 @@ export interface CreateCronJobRequest {
   enabled?: boolean;
   post_results?: boolean;
   tool_instance_ids?: number[];
+  skill_names?: string[];
 }
 
 export interface UpdateCronJobRequest {
@@ -501,6 +968,7 @@ export interface UpdateCronJobRequest {
   enabled?: boolean;
   post_results?: boolean;
   tool_instance_ids?: number[];
+  skill_names?: string[];
 }
 
 // SSH Keys (for SSH tool management)
@@ -508,12 +976,14 @@ export interface SSHKey {
   id: string;
   name: string;
   is_default: boolean;
+  has_passphrase: boolean;
   created_at: string;
 }
 
 export interface SSHKeyCreateRequest {
   name: string;
   private_key: string;
+  passphrase?: string;  // For encrypted private keys
   is_default?: boolean;
 }
 
@@ -522,12 +992,27 @@ export interface SSHKeyUpdateRequest {
   is_default?: boolean;
 }
 
+// Host key pinned by the MCP gateway for an SSH tool instance
+export interface SSHKnownHost {
+  id: number;
+  tool_instance_id: number;
+  host: string;
+  key_type: string;
+  public_key: string;
+  fingerprint: string;
+  created_at: string;
+  last_seen_at: string;
+}
+
 // Retention Settings
 export interface RetentionSettings {
   id: number;
   enabled: boolean;
   retention_days: number;
   cleanup_interval_hours: number;
+  workspace_max_age_days: number;
+  workspace_max_total_mb: number;
+  archive_workspaces: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -536,8 +1021,135 @@ export interface RetentionSettingsUpdate {
   enabled?: boolean;
   retention_days?: number;
   cleanup_interval_hours?: number;
+  workspace_max_age_days?: number;
+  workspace_max_total_mb?: number;
+  archive_workspaces?: boolean;
+}
+
+// Disk used by incident workspaces and their archives. status is absent for
+// a workspace without an incident record.
+export interface WorkspaceUsage {
+  total_bytes: number;
+  workspace_count: number;
+  archive_bytes: number;
+  archive_count: number;
+  largest: {
+    incident_uuid: string;
+    bytes: number;
+    status?: string;
+    modified_at: string;
+  }[];
+}
+
+export interface RetentionCleanupResult {
+  skipped: boolean;
+  expired_incidents_deleted: number;
+  expired_alerts_deleted: number;
+  expired_dirs_deleted: number;
+  expired_bytes_freed: number;
+  orphaned_dirs_deleted: number;
+  orphaned_bytes_freed: number;
+  workspaces_pruned: number;
+  workspace_bytes_freed: number;
+  workspaces_archived: number;
+  errors: string[];
+}
+
+// Deployment-specific presentation of severities and statuses. Keys are the
+// built-in values; missing keys keep the built-in presentation.
+export interface VocabularySettings {
+  id: number;
+  severity_emoji: Record<string, string>;
+  status_labels: Record<string, string>;
+  status_colors: Record<string, string>;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface VocabularySettingsUpdate {
+  severity_emoji?: Record<string, string>;
+  status_labels?: Record<string, string>;
+  status_colors?: Record<string, string>;
+}
+
+// CORS policy for browsers calling the API from another origin. While
+// disabled the CORS_* environment variables apply; `effective` is the policy
+// in force either way.
+export interface CORSSettings {
+  id: number;
+  enabled: boolean;
+  allowed_origins: string[];
+  allowed_headers: string[];
+  allow_credentials: boolean;
+  effective: {
+    source: 'settings' | 'environment';
+    allowed_origins: string[];
+    allowed_methods: string[];
+    allowed_headers: string[];
+    allow_credentials: boolean;
+  };
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CORSSettingsUpdate {
+  enabled?: boolean;
+  allowed_origins?: string[];
+  allowed_headers?: string[];
+  allow_credentials?: boolean;
+}
+
+// Model price table used to estimate investigation cost. `model` is an exact
+// model name, a prefix ending in '*', or '*' for any model.
+export interface ModelPrice {
+  id: number;
+  model: string;
+  usd_per_million_tokens: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ModelPriceInput {
+  model: string;
+  usd_per_million_tokens: number;
+}
+
+// LLM spend budget. Zero leaves a limit off; once an enabled limit is
+// reached new investigations end as 'budget_exceeded'.
+export interface BudgetWindow {
+  start: string;
+  resets: string;
+  tokens_used: number;
+  cost_usd: number;
+  token_limit: number;
+  cost_limit_usd: number;
+}
+
+export interface BudgetStatus {
+  enabled: boolean;
+  exceeded: boolean;
+  reason?: string;
+  daily: BudgetWindow;
+  monthly: BudgetWindow;
+}
+
+export interface BudgetSettings {
+  id: number;
+  enabled: boolean;
+  daily_token_limit: number;
+  monthly_token_limit: number;
+  daily_cost_limit_usd: number;
+  monthly_cost_limit_usd: number;
+  notification_channel_uuid: string;
+  created_at: string;
+  updated_at: string;
+  status?: BudgetStatus;
 }
 
+export type BudgetSettingsUpdate = Partial<Pick<BudgetSettings,
+  'enabled' | 'daily_token_limit' | 'monthly_token_limit' |
+  'daily_cost_limit_usd' | 'monthly_cost_limit_usd' | 'notification_channel_uuid'>>;
+
 // Per-flow formatting rules (replaces the global formatting settings)
 export interface FormattingRule {
   id: number;
@@ -596,6 +1208,18 @@ export interface GeneralSettings {
   alert_correlation_enabled: boolean;
   alert_monitor_window_minutes: number;
   incident_merge_enabled: boolean;
+  // Selects notification template overrides for this locale ("" = default)
+  notification_locale: string;
+  // Close completed/monitor incidents idle for this many days (0 = off)
+  incident_auto_close_days: number;
+  // Outbound incident links use short /i/{code} URLs
+  short_links_enabled: boolean;
+  // Alert results below this confidence ask for a review (0 = off)
+  confidence_review_threshold: number;
+  // Slack mentions pinged by review requests
+  confidence_reviewers: string;
+  // Info/warning results at or above this confidence close resolved incidents (0 = off)
+  confidence_auto_close_threshold: number;
 }
 
 export interface GeneralSettingsUpdate {
@@ -603,6 +1227,92 @@ export interface GeneralSettingsUpdate {
   alert_correlation_enabled?: boolean;
   alert_monitor_window_minutes?: number;
   incident_merge_enabled?: boolean;
+  notification_locale?: string;
+  incident_auto_close_days?: number;
+  short_links_enabled?: boolean;
+  confidence_review_threshold?: number;
+  confidence_reviewers?: string;
+  confidence_auto_close_threshold?: number;
+}
+
+// Notification templates
+export type NotificationKind =
+  | 'alert_posted'
+  | 'alert_recurring'
+  | 'alert_merged'
+  | 'alert_cascading'
+  | 'alert_resolved'
+  | 'incident_create_failed';
+
+export interface NotificationKindInfo {
+  kind: NotificationKind;
+  description: string;
+  default_body: string;
+}
+
+export interface NotificationTemplate {
+  id: number;
+  kind: NotificationKind;
+  locale: string;
+  body: string;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface NotificationTemplateList {
+  kinds: NotificationKindInfo[];
+  templates: NotificationTemplate[];
+}
+
+export interface NotificationTemplatePreview {
+  kind: NotificationKind;
+  body: string;
+  rendered: string;
+}
+
+// Investigation prompt template for a source type and/or skill; '' matches any.
+export interface PromptTemplate {
+  id: number;
+  source_type: string;
+  skill: string;
+  body: string;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PromptVariable {
+  name: string;
+  field: string;
+  description: string;
+}
+
+export interface PromptTemplateList {
+  default_body: string;
+  variables: PromptVariable[];
+  templates: PromptTemplate[];
+}
+
+export interface PromptTemplatePreview {
+  body: string;
+  rendered: string;
+}
+
+export type TemplateScope = 'prompt' | 'notification';
+
+export interface TemplateVariable {
+  name: string;
+  type: string;
+  description: string;
+  example: string;
+  scopes: TemplateScope[];
+}
+
+export interface TemplateVariableCatalog {
+  variables: TemplateVariable[];
+  functions: string[];
+  sample: Record<string, unknown>;
 }
 
 // Pagination
@@ -628,6 +1338,8 @@ export interface SSHHostConfig {
   jumphost_user?: string;
   jumphost_port?: number;
   allow_write_commands?: boolean;
+  password?: string;       // Password auth, tried after keys
+  forward_agent?: boolean; // Forward an ssh-agent to commands on this host
 }
 
 // Events feed
@@ -648,3 +1360,21 @@ export interface EventFeedItem {
   incident_status?: string;
 }
 
+export type SearchResultType = 'incident' | 'alert' | 'skill' | 'context' | 'runbook';
+
+export interface SearchResult {
+  type: SearchResultType;
+  id: string;
+  title: string;
+  snippet?: string;
+  status?: string;
+  incident_uuid?: string;
+  timestamp: string;
+}
+
+export interface SearchResponse {
+  query: string;
+  results: SearchResult[];
+  counts: Partial<Record<SearchResultType, number>>;
+}
+